@@ -191,6 +191,8 @@ func MockResponse(funcName string, args json.RawMessage) (string, error) {
 		terminal.PrintKeyValue("Query", sploitusArgs.Query)
 		terminal.PrintKeyValue("Exploit type", exploitType)
 		terminal.PrintKeyValue("Sort", sploitusArgs.Sort)
+		terminal.PrintKeyValue("Language", sploitusArgs.Language)
+		terminal.PrintKeyValue("Platform", sploitusArgs.Platform)
 		terminal.PrintKeyValueFormat("Max results", "%d", sploitusArgs.MaxResults.Int())
 
 		var builder strings.Builder
@@ -249,6 +251,370 @@ func MockResponse(funcName string, args json.RawMessage) (string, error) {
 
 		resultObj = builder.String()
 
+	case tools.GithubToolName:
+		var githubArgs tools.GithubAction
+		if err := json.Unmarshal(args, &githubArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling github arguments: %w", err)
+		}
+
+		searchType := githubArgs.SearchType
+		if searchType == "" {
+			searchType = "repositories"
+		}
+
+		terminal.PrintMock("GitHub search:")
+		terminal.PrintKeyValue("Query", githubArgs.Query)
+		terminal.PrintKeyValue("Search type", searchType)
+		terminal.PrintKeyValue("Language", githubArgs.Language)
+		terminal.PrintKeyValue("Sort", githubArgs.Sort)
+		terminal.PrintKeyValueFormat("Max results", "%d", githubArgs.MaxResults.Int())
+
+		var builder strings.Builder
+		builder.WriteString("# GitHub Search Results\n\n")
+		builder.WriteString(fmt.Sprintf("**Query:** `%s`  \n", githubArgs.Query))
+		builder.WriteString(fmt.Sprintf("**Type:** %s  \n", searchType))
+		builder.WriteString(fmt.Sprintf("**Total matches on GitHub:** %d  \n", 42))
+		builder.WriteString("\n---\n\n")
+
+		for i := 1; i <= min(githubArgs.MaxResults.Int(), 3); i++ {
+			if searchType == "code" {
+				builder.WriteString(fmt.Sprintf("### %d. example/poc-%d/exploit.py\n\n", i, i))
+				builder.WriteString(fmt.Sprintf("**URL:** https://github.com/example/poc-%d/blob/main/exploit.py  \n", i))
+				builder.WriteString(fmt.Sprintf("**Raw URL:** https://raw.githubusercontent.com/example/poc-%d/main/exploit.py  \n", i))
+				builder.WriteString(fmt.Sprintf("**Repository:** https://github.com/example/poc-%d  \n", i))
+			} else {
+				builder.WriteString(fmt.Sprintf("### %d. example/poc-%d\n\n", i, i))
+				builder.WriteString(fmt.Sprintf("**URL:** https://github.com/example/poc-%d  \n", i))
+				builder.WriteString(fmt.Sprintf("**Clone URL:** https://github.com/example/poc-%d.git  \n", i))
+				builder.WriteString(fmt.Sprintf("**Stars:** %d  \n", 100/i))
+				builder.WriteString("**Last updated:** 2026-02-15  \n")
+				builder.WriteString(fmt.Sprintf("\nMock proof-of-concept for %s\n", githubArgs.Query))
+			}
+			builder.WriteString("\n---\n\n")
+		}
+
+		resultObj = builder.String()
+
+	case tools.ExploitDBToolName:
+		var exploitDBArgs tools.ExploitDBAction
+		if err := json.Unmarshal(args, &exploitDBArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling exploitdb arguments: %w", err)
+		}
+
+		terminal.PrintMock("ExploitDB search:")
+		terminal.PrintKeyValue("Query", exploitDBArgs.Query)
+		terminal.PrintKeyValueFormat("Max results", "%d", exploitDBArgs.MaxResults.Int())
+
+		var builder strings.Builder
+		builder.WriteString("# ExploitDB Search Results\n\n")
+		builder.WriteString(fmt.Sprintf("**Query:** `%s`  \n", exploitDBArgs.Query))
+		builder.WriteString(fmt.Sprintf("**Total matches in ExploitDB:** %d  \n", 3))
+		builder.WriteString("\n---\n\n")
+		builder.WriteString("## Exploits (showing up to 3)\n\n")
+
+		for i := 1; i <= min(exploitDBArgs.MaxResults.Int(), 3); i++ {
+			builder.WriteString(fmt.Sprintf("### %d. Mock exploit %d for %s\n\n", i, i, exploitDBArgs.Query))
+			builder.WriteString(fmt.Sprintf("**URL:** https://www.exploit-db.com/exploits/5000%d  \n", i))
+			builder.WriteString(fmt.Sprintf("**ID:** EDB-5000%d  \n", i))
+			builder.WriteString(fmt.Sprintf("**Path:** exploits/linux/remote/5000%d.py  \n", i))
+			builder.WriteString("**Platform:** linux  \n")
+			builder.WriteString("**Type:** remote  \n")
+			builder.WriteString("**Published:** 2026-02-15  \n")
+			builder.WriteString("\n---\n\n")
+		}
+
+		resultObj = builder.String()
+
+	case tools.ExploitSearchToolName:
+		var exploitSearchArgs tools.ExploitSearchAction
+		if err := json.Unmarshal(args, &exploitSearchArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling exploit search arguments: %w", err)
+		}
+
+		terminal.PrintMock("Exploit search:")
+		terminal.PrintKeyValue("Query", exploitSearchArgs.Query)
+		terminal.PrintKeyValueFormat("Max results", "%d", exploitSearchArgs.MaxResults.Int())
+
+		var builder strings.Builder
+		builder.WriteString("# Exploit Search Results\n\n")
+		builder.WriteString(fmt.Sprintf("**Query:** `%s`  \n", exploitSearchArgs.Query))
+		builder.WriteString("**Sources:** sploitus (1 of 10 matches), github (1 of 5 matches), exploitdb (1 of 1 matches)  \n")
+		builder.WriteString("\n---\n\n")
+		builder.WriteString("## Exploits (showing up to 3)\n\n")
+
+		for i, source := range []string{"sploitus", "github", "exploitdb"} {
+			builder.WriteString(fmt.Sprintf("### %d. Mock %s exploit for %s\n\n", i+1, source, exploitSearchArgs.Query))
+			builder.WriteString(fmt.Sprintf("**URL:** https://example.com/%s/%d  \n", source, i+1))
+			builder.WriteString(fmt.Sprintf("**Source:** %s  \n", source))
+			builder.WriteString("**Published:** 2026-02-15  \n")
+			builder.WriteString("\n---\n\n")
+		}
+
+		resultObj = builder.String()
+
+	case tools.VirusTotalToolName:
+		var virusTotalArgs tools.VirusTotalAction
+		if err := json.Unmarshal(args, &virusTotalArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling virustotal arguments: %w", err)
+		}
+
+		terminal.PrintMock("VirusTotal reputation:")
+		terminal.PrintKeyValue("Resource", virusTotalArgs.Resource)
+
+		var builder strings.Builder
+		builder.WriteString("# VirusTotal Report\n\n")
+		builder.WriteString(fmt.Sprintf("**Resource:** `%s` (file)  \n", virusTotalArgs.Resource))
+		builder.WriteString("**Detection ratio:** 2/70  \n")
+		builder.WriteString("**Categories:** harmless 0, malicious 1, suspicious 1, undetected 68  \n")
+		builder.WriteString("**Name:** mock-sample.bin  \n")
+		builder.WriteString("**First seen:** 2026-02-15T10:00:00Z  \n")
+		builder.WriteString("**Last seen:** 2026-03-01T12:00:00Z  \n")
+		builder.WriteString("**Community reputation:** -5  \n\n")
+		builder.WriteString("## Engine verdicts\n\n")
+		builder.WriteString("- **MockAV:** Trojan.Generic.Mock (malicious)\n")
+		builder.WriteString("- **MockHeuristic:** Suspicious.Packer (suspicious)\n")
+
+		resultObj = builder.String()
+
+	case tools.WaybackToolName:
+		var waybackArgs tools.WaybackAction
+		if err := json.Unmarshal(args, &waybackArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling wayback arguments: %w", err)
+		}
+
+		terminal.PrintMock("Wayback Machine history:")
+		terminal.PrintKeyValue("URL", waybackArgs.URL)
+		terminal.PrintKeyValue("Match type", waybackArgs.MatchType)
+		terminal.PrintKeyValueFormat("Max results", "%d", waybackArgs.MaxResults.Int())
+
+		var builder strings.Builder
+		builder.WriteString("# Wayback Machine URL History\n\n")
+		builder.WriteString(fmt.Sprintf("**URL:** `%s`  \n", waybackArgs.URL))
+		builder.WriteString("**Captures:** 5 (3 after collapsing near-identical consecutive captures)  \n")
+		builder.WriteString("\n| First capture | Last capture | Count | Status | MIME type | URL | Archive |\n")
+		builder.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+		for i, year := range []int{2019, 2021, 2024} {
+			builder.WriteString(fmt.Sprintf("| %d-03-15 10:00:00 | - | %d | 200 | text/html | %s | "+
+				"https://web.archive.org/web/%d0315100000/%s |\n", year, i+1, waybackArgs.URL, year, waybackArgs.URL))
+		}
+
+		resultObj = builder.String()
+
+	case tools.WhoisToolName:
+		var whoisArgs tools.WhoisAction
+		if err := json.Unmarshal(args, &whoisArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling whois arguments: %w", err)
+		}
+
+		terminal.PrintMock("WHOIS lookup:")
+		terminal.PrintKeyValue("Target", whoisArgs.Target)
+
+		var builder strings.Builder
+		builder.WriteString("# WHOIS Lookup\n\n")
+		builder.WriteString(fmt.Sprintf("**Target:** `%s` (domain)  \n", whoisArgs.Target))
+		builder.WriteString("**Source:** RDAP (https://rdap.example.com/domain/mock)  \n")
+		builder.WriteString(fmt.Sprintf("**Name:** %s  \n", whoisArgs.Target))
+		builder.WriteString("**Status:** client transfer prohibited  \n")
+		builder.WriteString("**Created:** 2015-03-11  \n")
+		builder.WriteString("**Expires:** 2027-03-11  \n")
+		builder.WriteString("**Registrar:** Mock Registrar LLC, IANA ID 9999  \n")
+		builder.WriteString("**Abuse:** abuse@registrar.example, +1.5555550100  \n")
+		builder.WriteString("\n## Nameservers\n\n- ns1.example.net\n- ns2.example.net\n")
+
+		resultObj = builder.String()
+
+	case tools.EPSSToolName:
+		var epssArgs tools.EPSSAction
+		if err := json.Unmarshal(args, &epssArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling epss arguments: %w", err)
+		}
+
+		terminal.PrintMock("EPSS scores:")
+		terminal.PrintKeyValue("CVEs", strings.Join(epssArgs.CVEs, ", "))
+
+		var builder strings.Builder
+		builder.WriteString("# EPSS Scores\n\n")
+		builder.WriteString("| # | CVE | EPSS | Percentile | Date |\n")
+		builder.WriteString("| --- | --- | --- | --- | --- |\n")
+		for i, cve := range epssArgs.CVEs {
+			builder.WriteString(fmt.Sprintf("| %d | %s | %.2f%% | %.1f%% | 2026-02-15 |\n",
+				i+1, strings.ToUpper(cve), 90.0/float64(i+1), 99.0-float64(i)))
+		}
+
+		resultObj = builder.String()
+
+	case tools.RobotsToolName:
+		var robotsArgs tools.RobotsAction
+		if err := json.Unmarshal(args, &robotsArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling robots arguments: %w", err)
+		}
+
+		terminal.PrintMock("robots.txt and sitemaps:")
+		terminal.PrintKeyValue("URL", robotsArgs.URL)
+		terminal.PrintKeyValue("Max URLs", fmt.Sprintf("%d", robotsArgs.MaxURLs))
+
+		base := strings.TrimSuffix(robotsArgs.URL, "/")
+		if !strings.Contains(base, "://") {
+			base = "https://" + base
+		}
+
+		var builder strings.Builder
+		builder.WriteString("# robots.txt and Sitemaps\n\n")
+		builder.WriteString(fmt.Sprintf("**Base URL:** %s  \n", base))
+		builder.WriteString("\n## robots.txt\n\n### User-agent: *\n\n")
+		builder.WriteString("- Disallow: `/admin/`\n- Disallow: `/backup/`\n- Allow: `/admin/public/`\n")
+		builder.WriteString(fmt.Sprintf("\n## Sitemaps\n\n- %s/sitemap.xml (urlset, 3 entries)\n", base))
+		builder.WriteString("\n## Sitemap URLs (3 found)\n\n")
+		for _, path := range []string{"/", "/login", "/api/docs"} {
+			builder.WriteString(fmt.Sprintf("- %s%s\n", base, path))
+		}
+
+		resultObj = builder.String()
+
+	case tools.HTTPHeadersToolName:
+		var headersArgs tools.HTTPHeadersAction
+		if err := json.Unmarshal(args, &headersArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling http headers arguments: %w", err)
+		}
+
+		method := headersArgs.Method
+		if method == "" {
+			method = "GET"
+		}
+
+		terminal.PrintMock("HTTP security headers:")
+		terminal.PrintKeyValue("URL", headersArgs.URL)
+		terminal.PrintKeyValue("Method", method)
+		terminal.PrintKeyValue("Follow redirects", fmt.Sprintf("%t", headersArgs.FollowRedirects))
+
+		var builder strings.Builder
+		builder.WriteString("# HTTP Security Headers\n\n")
+		builder.WriteString(fmt.Sprintf("**Request:** %s %s  \n", method, headersArgs.URL))
+		builder.WriteString("**Status:** HTTP/1.1 200 OK  \n")
+		builder.WriteString("\n## Security Headers\n\n**Issues:** 1 high, 2 medium, 1 low\n\n")
+		builder.WriteString("| Header | Status | Severity | Notes |\n| --- | --- | --- | --- |\n")
+		builder.WriteString("| Strict-Transport-Security | missing | high | browsers may connect over plain HTTP, enables SSL stripping attacks |\n")
+		builder.WriteString("| Content-Security-Policy | missing | medium | no policy restricts scripts and other resources, XSS is easier to exploit |\n")
+		builder.WriteString("| X-Frame-Options | missing | medium | pages can be framed by other sites, enables clickjacking |\n")
+		builder.WriteString("| X-Content-Type-Options | present | info | nosniff |\n")
+		builder.WriteString("| Server | weak | low | reveals server software version, check it for known vulnerabilities: 'nginx/1.18.0' |\n")
+		builder.WriteString("\n## Response Headers\n\n```\n")
+		builder.WriteString("Content-Type: text/html; charset=utf-8\nServer: nginx/1.18.0\n")
+		builder.WriteString("Set-Cookie: PHPSESSID=***REDACTED (26 chars)***; path=/\nX-Content-Type-Options: nosniff\n```\n")
+
+		resultObj = builder.String()
+
+	case tools.TLSInspectToolName:
+		var tlsArgs tools.TLSInspectAction
+		if err := json.Unmarshal(args, &tlsArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling tls inspect arguments: %w", err)
+		}
+
+		terminal.PrintMock("TLS inspection:")
+		terminal.PrintKeyValue("Target", tlsArgs.Target)
+		if tlsArgs.ServerName != "" {
+			terminal.PrintKeyValue("Server name", tlsArgs.ServerName)
+		}
+
+		var builder strings.Builder
+		builder.WriteString("# TLS Inspection\n\n")
+		builder.WriteString(fmt.Sprintf("**Target:** %s  \n", tlsArgs.Target))
+		builder.WriteString("**Protocol:** TLS 1.2  \n**Cipher suite:** TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA  \n")
+		builder.WriteString("**Legacy protocols:** TLS 1.0 supported, TLS 1.1 supported  \n")
+		builder.WriteString("**Chain:** not trusted: x509: certificate signed by unknown authority  \n")
+		builder.WriteString("\n## Findings\n\n")
+		builder.WriteString("- **medium:** deprecated protocol TLS 1.0 is still supported\n")
+		builder.WriteString("- **low:** negotiated cipher suite TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA uses CBC mode, prefer AEAD ciphers\n")
+		builder.WriteString("- **high:** certificate is self-signed and not trusted\n")
+		builder.WriteString("\n## Certificate Chain\n\n### #0 leaf\n\n")
+		builder.WriteString("- **Subject:** CN=localhost,O=Mock Corp\n- **Issuer:** CN=localhost,O=Mock Corp\n")
+		builder.WriteString("- **Valid:** 2026-01-01T00:00:00Z - 2027-01-01T00:00:00Z (78 days left)\n")
+		builder.WriteString("- **Key:** RSA 2048 bits\n- **Signature:** SHA256-RSA\n- **SANs:** localhost, 127.0.0.1\n")
+
+		resultObj = builder.String()
+
+	case tools.PortScanToolName:
+		var scanArgs tools.PortScanAction
+		if err := json.Unmarshal(args, &scanArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling port scan arguments: %w", err)
+		}
+
+		terminal.PrintMock("Port scan:")
+		terminal.PrintKeyValue("Target", scanArgs.Target)
+		if scanArgs.Ports != "" {
+			terminal.PrintKeyValue("Ports", scanArgs.Ports)
+		}
+
+		var builder strings.Builder
+		builder.WriteString("# Port Scan\n\n")
+		builder.WriteString(fmt.Sprintf("**Target:** %s  \n", scanArgs.Target))
+		builder.WriteString("**Ports:** 112 scanned: 3 open, 107 closed, 2 filtered  \n")
+		builder.WriteString("**Settings:** 50 parallel connections, 1s timeout per port  \n**Duration:** 2.314s  \n")
+		builder.WriteString("\n## Open Ports\n\n| Port | Service | Banner |\n| --- | --- | --- |\n")
+		builder.WriteString("| 22/tcp | ssh | `SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.6` |\n")
+		builder.WriteString("| 80/tcp | http | - |\n")
+		builder.WriteString("| 3306/tcp | mysql | `J...8.0.36.....` |\n")
+
+		resultObj = builder.String()
+
+	case tools.HTTPFuzzToolName:
+		var fuzzArgs tools.HTTPFuzzAction
+		if err := json.Unmarshal(args, &fuzzArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling http fuzz arguments: %w", err)
+		}
+
+		terminal.PrintMock("HTTP content discovery:")
+		terminal.PrintKeyValue("URL", fuzzArgs.URL)
+		terminal.PrintKeyValueFormat("Words", "%d", len(fuzzArgs.Words))
+
+		var builder strings.Builder
+		builder.WriteString("# HTTP Content Discovery\n\n")
+		builder.WriteString(fmt.Sprintf("**Base URL:** %s  \n", fuzzArgs.URL))
+		builder.WriteString("**Requests:** 152 sent: 147 hidden as not found or excluded, 0 failed  \n")
+		builder.WriteString("**Settings:** GET, 10 parallel requests, 20 requests per second  \n")
+		builder.WriteString("**Not found page:** 404, 162 bytes  \n**Duration:** 7.843s  \n")
+		builder.WriteString("\n## Discovered Endpoints\n")
+		builder.WriteString("\n### 200 OK (3)\n\n| Path | Size | Content-Type | Location |\n| --- | --- | --- | --- |\n")
+		builder.WriteString("| /.git/HEAD | 23 | text/plain | - |\n")
+		builder.WriteString("| /login | 4127 | text/html; charset=utf-8 | - |\n")
+		builder.WriteString("| /robots.txt | 68 | text/plain | - |\n")
+		builder.WriteString("\n### 301 Moved Permanently (1)\n\n| Path | Size | Content-Type | Location |\n| --- | --- | --- | --- |\n")
+		builder.WriteString("| /admin | 169 | text/html | /admin/ |\n")
+		builder.WriteString("\n### 403 Forbidden (1)\n\n| Path | Size | Content-Type | Location |\n| --- | --- | --- | --- |\n")
+		builder.WriteString("| /.htaccess | 199 | text/html | - |\n")
+
+		resultObj = builder.String()
+
+	case tools.HeadlessBrowserToolName:
+		var browserArgs tools.HeadlessBrowserAction
+		if err := json.Unmarshal(args, &browserArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling headless browser arguments: %w", err)
+		}
+
+		terminal.PrintMock("Headless browser:")
+		terminal.PrintKeyValue("URL", browserArgs.URL)
+		terminal.PrintKeyValue("Action", string(browserArgs.Action))
+		if browserArgs.Selector != "" {
+			terminal.PrintKeyValue("Selector", browserArgs.Selector)
+		}
+
+		var builder strings.Builder
+		builder.WriteString("# Headless Browser\n\n")
+		builder.WriteString(fmt.Sprintf("**URL:** %s  \n", browserArgs.URL))
+		builder.WriteString("**Title:** Sign in - Example App  \n")
+		builder.WriteString(fmt.Sprintf("**Action:** %s  \n", browserArgs.Action))
+		if browserArgs.Action == tools.HeadlessScreenshot {
+			builder.WriteString("\n## Screenshot\n\nartifact 'screenshot-example.com-20260101-120000.000.png' (image/png, 48213 bytes) " +
+				"is saved with id 1, download it from /api/v1/flows/1/artifacts/1/file or copy it to the container by the 'artifacts' tool\n")
+		} else {
+			builder.WriteString("\n## Text\n\nSign in to continue\nEmail\nPassword\nForgot password?\n")
+			builder.WriteString("\n## Links (2)\n\n")
+			builder.WriteString("- [Forgot password?](https://example.com/app/#/reset)\n")
+			builder.WriteString("- [Create account](https://example.com/app/#/register)\n")
+		}
+
+		resultObj = builder.String()
+
 	case tools.SearxngToolName:
 		var searchArgs tools.SearchAction
 		if err := json.Unmarshal(args, &searchArgs); err != nil {
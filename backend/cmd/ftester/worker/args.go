@@ -295,8 +295,21 @@ func getStructTypeForFunction(funcName string) (reflect.Type, error) {
 		tools.TavilyToolName:            &tools.SearchAction{},
 		tools.TraversaalToolName:        &tools.SearchAction{},
 		tools.PerplexityToolName:        &tools.SearchAction{},
-		tools.SearxngToolName:           &tools.SearchAction{},
+		tools.SearxngToolName:           &tools.SearxngAction{},
 		tools.SploitusToolName:          &tools.SploitusAction{},
+		tools.GithubToolName:            &tools.GithubAction{},
+		tools.ExploitDBToolName:         &tools.ExploitDBAction{},
+		tools.VirusTotalToolName:        &tools.VirusTotalAction{},
+		tools.WaybackToolName:           &tools.WaybackAction{},
+		tools.WhoisToolName:             &tools.WhoisAction{},
+		tools.EPSSToolName:              &tools.EPSSAction{},
+		tools.RobotsToolName:            &tools.RobotsAction{},
+		tools.HTTPHeadersToolName:       &tools.HTTPHeadersAction{},
+		tools.TLSInspectToolName:        &tools.TLSInspectAction{},
+		tools.PortScanToolName:          &tools.PortScanAction{},
+		tools.HTTPFuzzToolName:          &tools.HTTPFuzzAction{},
+		tools.HeadlessBrowserToolName:   &tools.HeadlessBrowserAction{},
+		tools.ExploitSearchToolName:     &tools.ExploitSearchAction{},
 		tools.MemoristToolName:          &tools.MemoristAction{},
 		tools.SearchInMemoryToolName:    &tools.SearchInMemoryAction{},
 		tools.SearchGuideToolName:       &tools.SearchGuideAction{},
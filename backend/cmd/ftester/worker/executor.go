@@ -113,6 +113,7 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 	// Get primary container for terminal/file operations (only when needed)
 	var containerID int64
 	var containerLID string
+	var commandGuard *tools.CommandGuard
 
 	requiresContainer := funcName == tools.TerminalToolName || funcName == tools.FileToolName
 	if requiresContainer {
@@ -122,6 +123,19 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 		}
 		containerID = cnt.ID
 		containerLID = cnt.LocalID.String
+
+		flow, err := te.db.GetFlow(ctx, te.flowID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get flow %d: %w", te.flowID, err)
+		}
+		var policy tools.CommandPolicy
+		if err := policy.Scan(flow.CommandPolicy); err != nil {
+			return nil, fmt.Errorf("failed to parse flow %d command policy: %w", te.flowID, err)
+		}
+		commandGuard, err = tools.NewCommandGuard(te.db, tools.GlobalCommandPolicy(te.cfg), policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create command guard: %w", err)
+		}
 	}
 
 	// Check which tool to create based on function name
@@ -135,6 +149,8 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			containerLID,
 			te.dockerClient,
 			te.proxies.GetTermLogProvider(),
+			tools.NewFlowEnvProvider(te.db, te.cfg, te.flowID),
+			commandGuard,
 		), nil
 
 	case tools.FileToolName:
@@ -147,6 +163,8 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			containerLID,
 			te.dockerClient,
 			te.proxies.GetTermLogProvider(),
+			tools.NewFlowEnvProvider(te.db, te.cfg, te.flowID),
+			commandGuard,
 		), nil
 
 	case tools.BrowserToolName:
@@ -224,6 +242,130 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			te.taskID,
 			te.subtaskID,
 			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.GithubToolName:
+		return tools.NewGithubTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.ExploitDBToolName:
+		return tools.NewExploitDBTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+		), nil
+
+	case tools.ExploitSearchToolName:
+		return tools.NewExploitSearchTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.VirusTotalToolName:
+		return tools.NewVirusTotalTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.WaybackToolName:
+		return tools.NewWaybackTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.WhoisToolName:
+		return tools.NewWhoisTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.EPSSToolName:
+		return tools.NewEPSSTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.RobotsToolName:
+		return tools.NewRobotsTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+
+	case tools.HTTPHeadersToolName:
+		return tools.NewHTTPHeadersTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+		), nil
+
+	case tools.TLSInspectToolName:
+		return tools.NewTLSInspectTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+		), nil
+	case tools.PortScanToolName:
+		return tools.NewPortScanTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+	case tools.HTTPFuzzToolName:
+		return tools.NewHTTPFuzzTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.proxies.GetSearchLogProvider(),
+			tools.GetSharedCache(te.cfg),
+		), nil
+	case tools.HeadlessBrowserToolName:
+		return tools.NewHeadlessBrowserTool(
+			te.cfg,
+			te.flowID,
+			te.taskID,
+			te.subtaskID,
+			te.db,
 		), nil
 
 	case tools.SearchInMemoryToolName:
@@ -186,6 +186,7 @@ func (t *tester) initFlowProviderController() error {
 	flowProvider, err := t.providers.LoadFlowProvider(
 		t.ctx,
 		t.providerName,
+		nil,
 		prompter,
 		t.flowExecutor,
 		t.flowID,
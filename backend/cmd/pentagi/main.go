@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -21,6 +22,7 @@ import (
 	obs "pentagi/pkg/observability"
 	"pentagi/pkg/providers"
 	router "pentagi/pkg/server"
+	"pentagi/pkg/tools"
 	"pentagi/pkg/version"
 
 	_ "github.com/lib/pq"
@@ -97,6 +99,13 @@ func main() {
 
 	log.Println("Migrations ran successfully")
 
+	// offline exploits index is loaded once and shared between all flows
+	if count, err := tools.LoadExploitDB(cfg); err != nil {
+		log.Printf("failed to load ExploitDB index, the tool is disabled: %v\n", err)
+	} else if count > 0 {
+		log.Printf("ExploitDB index loaded: %d exploits\n", count)
+	}
+
 	client, err := docker.NewDockerClient(ctx, queries, cfg)
 	if err != nil {
 		log.Fatalf("failed to initialize Docker client: %v", err)
@@ -113,17 +122,40 @@ func main() {
 		log.Fatalf("failed to load flows: %v", err)
 	}
 
-	r := router.NewRouter(queries, orm, cfg, providers, controller, subscriptions)
+	// background jobs are stopped after the server shutdown
+	bgCtx, bgCancel := context.WithCancel(ctx)
+	defer bgCancel()
+
+	// remove containers which were left after failed flow deletions
+	go docker.RunContainerReaper(bgCtx, client, time.Duration(cfg.DockerReaperInterval)*time.Second)
+
+	// start queued flows when their users have free slots of running flows
+	go controller.RunFlowsQueue(bgCtx, time.Duration(cfg.FlowsQueueInterval)*time.Second)
+
+	// remove resources of the flows which were deleted before the retention period
+	go controller.RunFlowsPurge(bgCtx, time.Duration(cfg.DockerReaperInterval)*time.Second)
+
+	// requests are canceled on shutdown to close SSE streams and GraphQL websockets which never end by themselves
+	connCtx, connCancel := context.WithCancel(ctx)
+	defer connCancel()
+
+	r := router.NewRouter(queries, orm, cfg, client, providers, controller, subscriptions)
+	srv := &http.Server{
+		Addr:        net.JoinHostPort(cfg.ServerHost, strconv.Itoa(cfg.ServerPort)),
+		Handler:     r.Handler(),
+		BaseContext: func(net.Listener) context.Context { return connCtx },
+	}
+	srv.RegisterOnShutdown(connCancel)
 
 	// Run the server in a separate goroutine
 	go func() {
-		listen := net.JoinHostPort(cfg.ServerHost, strconv.Itoa(cfg.ServerPort))
+		var err error
 		if cfg.ServerUseSSL && cfg.ServerSSLCrt != "" && cfg.ServerSSLKey != "" {
-			err = r.RunTLS(listen, cfg.ServerSSLCrt, cfg.ServerSSLKey)
+			err = srv.ListenAndServeTLS(cfg.ServerSSLCrt, cfg.ServerSSLKey)
 		} else {
-			err = r.Run(listen)
+			err = srv.ListenAndServe()
 		}
-		if err != nil {
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("HTTP server error: %v", err)
 		}
 	}()
@@ -132,5 +164,19 @@ func main() {
 	<-sigChan
 	log.Println("Shutting down...")
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, time.Duration(cfg.ShutdownGracePeriod)*time.Second)
+	defer shutdownCancel()
+
+	// stop accepting new flows and pause running ones before closing the connections
+	if err := controller.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to pause some flows: %v\n", err)
+	}
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shutdown HTTP server gracefully: %v\n", err)
+	}
+
+	bgCancel()
+
 	log.Println("Shutdown complete")
 }
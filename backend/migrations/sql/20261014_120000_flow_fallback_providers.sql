@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN fallback_providers JSON NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS fallback_providers;
+-- +goose StatementEnd
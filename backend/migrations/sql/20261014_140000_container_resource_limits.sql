@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE containers ADD COLUMN cpu_limit DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE containers ADD COLUMN memory_limit BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE containers ADD COLUMN pids_limit BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE containers DROP COLUMN IF EXISTS pids_limit;
+ALTER TABLE containers DROP COLUMN IF EXISTS memory_limit;
+ALTER TABLE containers DROP COLUMN IF EXISTS cpu_limit;
+-- +goose StatementEnd
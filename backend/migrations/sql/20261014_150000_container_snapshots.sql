@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE container_snapshots (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  image          TEXT          NOT NULL,
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  container_id   BIGINT        NOT NULL REFERENCES containers(id) ON DELETE CASCADE,
+  user_id        BIGINT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT container_snapshots_image_unique UNIQUE (image)
+);
+
+CREATE INDEX container_snapshots_flow_id_idx ON container_snapshots(flow_id);
+CREATE INDEX container_snapshots_user_id_idx ON container_snapshots(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS container_snapshots;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE orphaned_containers (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  local_id       TEXT          NOT NULL,
+  flow_id        BIGINT        NOT NULL,
+  container_id   BIGINT        NULL REFERENCES containers(id) ON DELETE SET NULL,
+  attempts       INTEGER       NOT NULL DEFAULT 0,
+  last_error     TEXT          NOT NULL DEFAULT '',
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT orphaned_containers_local_id_unique UNIQUE (local_id)
+);
+
+CREATE INDEX orphaned_containers_flow_id_idx ON orphaned_containers(flow_id);
+
+CREATE TRIGGER update_orphaned_containers_modified
+  BEFORE UPDATE ON orphaned_containers
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS orphaned_containers;
+-- +goose StatementEnd
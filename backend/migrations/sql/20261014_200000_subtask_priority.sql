@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subtasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX subtasks_task_id_priority_idx ON subtasks(task_id, priority);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS subtasks_task_id_priority_idx;
+
+ALTER TABLE subtasks DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE flow_templates (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  name           TEXT          NOT NULL,
+  shared         BOOLEAN       NOT NULL DEFAULT FALSE,
+  params         JSONB         NOT NULL DEFAULT '{}',
+  user_id        BIGINT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX flow_templates_user_id_idx ON flow_templates(user_id);
+CREATE INDEX flow_templates_shared_idx ON flow_templates(shared);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_templates;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE FLOW_ACCESS AS ENUM ('view','edit');
+
+CREATE TABLE flow_acls (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  user_id        BIGINT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  access         FLOW_ACCESS   NOT NULL DEFAULT 'view',
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT flow_acls_flow_id_user_id_unique UNIQUE (flow_id, user_id)
+);
+
+CREATE INDEX flow_acls_user_id_idx ON flow_acls(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_acls;
+DROP TYPE IF EXISTS FLOW_ACCESS;
+-- +goose StatementEnd
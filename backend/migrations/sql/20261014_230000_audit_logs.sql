@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_logs (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id        BIGINT        NOT NULL,
+  action         TEXT          NOT NULL,
+  flow_id        BIGINT        NOT NULL,
+  changes        JSONB         NOT NULL DEFAULT '{}',
+  metadata       JSONB         NOT NULL DEFAULT '{}',
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_logs_flow_id_idx ON audit_logs(flow_id);
+CREATE INDEX audit_logs_user_id_idx ON audit_logs(user_id);
+CREATE INDEX audit_logs_created_at_idx ON audit_logs(created_at);
+
+CREATE OR REPLACE FUNCTION prevent_audit_logs_change()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit log records are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER prevent_audit_logs_change
+  BEFORE UPDATE OR DELETE ON audit_logs
+  FOR EACH ROW EXECUTE PROCEDURE prevent_audit_logs_change();
+
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'audit.view');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM privileges WHERE name = 'audit.view';
+
+DROP TABLE IF EXISTS audit_logs;
+DROP FUNCTION IF EXISTS prevent_audit_logs_change();
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE model_prices (
+  id                   BIGINT             PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  provider             TEXT               NOT NULL,
+  model                TEXT               NOT NULL,
+  input_price_per_1k   DOUBLE PRECISION   NOT NULL,
+  output_price_per_1k  DOUBLE PRECISION   NOT NULL,
+  effective_from       TIMESTAMPTZ        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at           TIMESTAMPTZ        DEFAULT CURRENT_TIMESTAMP,
+  updated_at           TIMESTAMPTZ        DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT model_prices_non_negative CHECK (input_price_per_1k >= 0 AND output_price_per_1k >= 0),
+  CONSTRAINT model_prices_effective_unique UNIQUE (provider, model, effective_from)
+);
+
+CREATE INDEX model_prices_lookup_idx ON model_prices(provider, model, effective_from DESC);
+
+CREATE TRIGGER update_model_prices_modified
+  BEFORE UPDATE ON model_prices
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'settings.pricing.admin'),
+  (1, 'settings.pricing.view'),
+  (2, 'settings.pricing.view');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM privileges WHERE name IN ('settings.pricing.admin', 'settings.pricing.view');
+
+DROP TABLE IF EXISTS model_prices;
+-- +goose StatementEnd
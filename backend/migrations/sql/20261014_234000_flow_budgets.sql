@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN budget_max_cost DOUBLE PRECISION NULL CHECK (budget_max_cost > 0);
+ALTER TABLE flows ADD COLUMN budget_max_tokens BIGINT NULL CHECK (budget_max_tokens > 0);
+ALTER TABLE flows ADD COLUMN stop_reason TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS stop_reason;
+ALTER TABLE flows DROP COLUMN IF EXISTS budget_max_tokens;
+ALTER TABLE flows DROP COLUMN IF EXISTS budget_max_cost;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE flow_env (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  name           TEXT          NOT NULL,
+  value          TEXT          NOT NULL,
+  secret         BOOLEAN       NOT NULL DEFAULT false,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT flow_env_flow_id_name_unique UNIQUE (flow_id, name)
+);
+
+CREATE TRIGGER update_flow_env_modified
+  BEFORE UPDATE ON flow_env
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_env;
+-- +goose StatementEnd
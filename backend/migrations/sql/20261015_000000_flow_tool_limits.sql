@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN tool_calls_per_minute INTEGER NULL CHECK (tool_calls_per_minute > 0);
+ALTER TABLE flows ADD COLUMN tool_calls_max BIGINT NULL CHECK (tool_calls_max > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS tool_calls_max;
+ALTER TABLE flows DROP COLUMN IF EXISTS tool_calls_per_minute;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'settings.cache.admin'),
+  (1, 'settings.cache.view'),
+  (2, 'settings.cache.view');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM privileges WHERE name IN ('settings.cache.admin', 'settings.cache.view');
+-- +goose StatementEnd
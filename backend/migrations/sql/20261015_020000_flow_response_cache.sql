@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN response_cache TEXT NULL
+  CHECK (response_cache IN ('disabled', 'deterministic', 'forced'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS response_cache;
+-- +goose StatementEnd
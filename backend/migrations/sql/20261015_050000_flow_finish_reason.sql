@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE FLOW_FINISH_REASON AS ENUM (
+  'user_stop',
+  'user_finish',
+  'completed',
+  'budget_exceeded',
+  'error',
+  'provider_exhausted'
+);
+
+ALTER TABLE flows ADD COLUMN finish_reason FLOW_FINISH_REASON NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS finish_reason;
+DROP TYPE IF EXISTS FLOW_FINISH_REASON;
+-- +goose StatementEnd
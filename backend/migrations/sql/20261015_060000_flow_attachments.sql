@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE flow_attachments (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  name           TEXT          NOT NULL,
+  size           BIGINT        NOT NULL,
+  content_type   TEXT          NOT NULL,
+  checksum       TEXT          NOT NULL,
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  user_id        BIGINT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT flow_attachments_flow_id_name_unique UNIQUE (flow_id, name)
+);
+
+CREATE INDEX flow_attachments_flow_id_idx ON flow_attachments(flow_id);
+CREATE INDEX flow_attachments_user_id_idx ON flow_attachments(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_attachments;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE FLOW_INPUT_STATUS AS ENUM (
+  'queued',
+  'consumed',
+  'failed',
+  'canceled'
+);
+
+CREATE TABLE flow_inputs (
+  id             BIGINT              PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  input          TEXT                NOT NULL,
+  status         FLOW_INPUT_STATUS   NOT NULL DEFAULT 'queued',
+  error          TEXT                NULL,
+  flow_id        BIGINT              NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  created_at     TIMESTAMPTZ         DEFAULT CURRENT_TIMESTAMP,
+  processed_at   TIMESTAMPTZ         NULL
+);
+
+CREATE INDEX flow_inputs_flow_id_idx ON flow_inputs(flow_id);
+CREATE INDEX flow_inputs_status_idx ON flow_inputs(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_inputs;
+DROP TYPE IF EXISTS FLOW_INPUT_STATUS;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN awaiting_input BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE flows ADD COLUMN pending_question TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS pending_question;
+ALTER TABLE flows DROP COLUMN IF EXISTS awaiting_input;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE input_templates (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  name           TEXT          NOT NULL,
+  template       TEXT          NOT NULL,
+  shared         BOOLEAN       NOT NULL DEFAULT FALSE,
+  user_id        BIGINT        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX input_templates_user_id_idx ON input_templates(user_id);
+CREATE INDEX input_templates_shared_idx ON input_templates(shared);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS input_templates;
+-- +goose StatementEnd
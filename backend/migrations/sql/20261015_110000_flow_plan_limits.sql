@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN plan_max_subtasks INTEGER NULL CHECK (plan_max_subtasks > 0);
+ALTER TABLE flows ADD COLUMN plan_max_depth INTEGER NULL CHECK (plan_max_depth > 0);
+
+ALTER TYPE FLOW_FINISH_REASON ADD VALUE IF NOT EXISTS 'plan_limit_exceeded';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- enum values can't be dropped, so the reason is only replaced in the flows
+UPDATE flows SET finish_reason = 'completed' WHERE finish_reason = 'plan_limit_exceeded';
+
+ALTER TABLE flows DROP COLUMN IF EXISTS plan_max_depth;
+ALTER TABLE flows DROP COLUMN IF EXISTS plan_max_subtasks;
+-- +goose StatementEnd
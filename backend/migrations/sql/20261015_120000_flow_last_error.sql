@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN last_error TEXT NULL;
+ALTER TABLE flows ADD COLUMN last_error_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE flows ADD COLUMN last_error_at TIMESTAMPTZ NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS last_error_at;
+ALTER TABLE flows DROP COLUMN IF EXISTS last_error_count;
+ALTER TABLE flows DROP COLUMN IF EXISTS last_error;
+-- +goose StatementEnd
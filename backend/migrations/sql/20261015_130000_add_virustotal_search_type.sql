@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Add virustotal to the searchengine_type enum
+CREATE TYPE SEARCHENGINE_TYPE_NEW AS ENUM (
+  'google',
+  'tavily',
+  'traversaal',
+  'browser',
+  'duckduckgo',
+  'perplexity',
+  'searxng',
+  'sploitus',
+  'github',
+  'exploitdb',
+  'exploit_search',
+  'virustotal'
+);
+
+-- Update the searchlogs table to use the new enum type
+ALTER TABLE searchlogs
+    ALTER COLUMN engine TYPE SEARCHENGINE_TYPE_NEW USING engine::text::SEARCHENGINE_TYPE_NEW;
+
+-- Drop the old type and rename the new one
+DROP TYPE SEARCHENGINE_TYPE;
+ALTER TYPE SEARCHENGINE_TYPE_NEW RENAME TO SEARCHENGINE_TYPE;
+
+-- Ensure NOT NULL constraint is preserved
+ALTER TABLE searchlogs
+    ALTER COLUMN engine SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Remove search logs of virustotal engine before removing it from the enum
+DELETE FROM searchlogs WHERE engine = 'virustotal';
+
+-- Revert the changes by removing virustotal from the enum
+CREATE TYPE SEARCHENGINE_TYPE_NEW AS ENUM (
+  'google',
+  'tavily',
+  'traversaal',
+  'browser',
+  'duckduckgo',
+  'perplexity',
+  'searxng',
+  'sploitus',
+  'github',
+  'exploitdb',
+  'exploit_search'
+);
+
+-- Update the searchlogs table to use the reverted enum type
+ALTER TABLE searchlogs
+    ALTER COLUMN engine TYPE SEARCHENGINE_TYPE_NEW USING engine::text::SEARCHENGINE_TYPE_NEW;
+
+-- Drop the new type and rename the reverted one
+DROP TYPE SEARCHENGINE_TYPE;
+ALTER TYPE SEARCHENGINE_TYPE_NEW RENAME TO SEARCHENGINE_TYPE;
+
+-- Ensure NOT NULL constraint is preserved
+ALTER TABLE searchlogs
+    ALTER COLUMN engine SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE flow_memories (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  key            TEXT          NOT NULL,
+  value          TEXT          NOT NULL,
+  tags           JSONB         NOT NULL DEFAULT '[]',
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  task_id        BIGINT        NULL REFERENCES tasks(id) ON DELETE SET NULL,
+  subtask_id     BIGINT        NULL REFERENCES subtasks(id) ON DELETE SET NULL,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT flow_memories_flow_id_key_unique UNIQUE (flow_id, key)
+);
+
+CREATE INDEX flow_memories_flow_id_idx ON flow_memories(flow_id);
+CREATE INDEX flow_memories_tags_idx ON flow_memories USING GIN (tags);
+
+CREATE TRIGGER update_flow_memories_modified
+  BEFORE UPDATE ON flow_memories
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_memories;
+-- +goose StatementEnd
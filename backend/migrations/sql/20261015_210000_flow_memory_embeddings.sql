@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE flow_memory_embeddings (
+  memory_id      BIGINT        PRIMARY KEY REFERENCES flow_memories(id) ON DELETE CASCADE,
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  model          TEXT          NOT NULL,
+  dimensions     INTEGER       NOT NULL,
+  embedding      REAL[]        NOT NULL,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX flow_memory_embeddings_flow_id_model_idx ON flow_memory_embeddings(flow_id, model, dimensions);
+
+-- vectors are stored in the pgvector column to search them by the database when the extension is installed,
+-- otherwise they are kept as the plain array and compared by the application
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector') THEN
+    ALTER TABLE flow_memory_embeddings ALTER COLUMN embedding TYPE vector USING embedding::vector;
+  END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_memory_embeddings;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE flow_artifacts (
+  id             BIGINT        PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  name           TEXT          NOT NULL,
+  size           BIGINT        NOT NULL,
+  content_type   TEXT          NOT NULL,
+  checksum       TEXT          NOT NULL,
+  flow_id        BIGINT        NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  task_id        BIGINT        NULL REFERENCES tasks(id) ON DELETE SET NULL,
+  subtask_id     BIGINT        NULL REFERENCES subtasks(id) ON DELETE SET NULL,
+  created_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+  updated_at     TIMESTAMPTZ   DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT flow_artifacts_flow_id_name_unique UNIQUE (flow_id, name)
+);
+
+CREATE INDEX flow_artifacts_flow_id_idx ON flow_artifacts(flow_id);
+
+CREATE TRIGGER update_flow_artifacts_modified
+  BEFORE UPDATE ON flow_artifacts
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_artifacts;
+-- +goose StatementEnd
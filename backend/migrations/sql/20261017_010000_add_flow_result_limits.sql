@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows ADD COLUMN result_max_size INTEGER NULL CHECK (result_max_size > 0);
+ALTER TABLE flows ADD COLUMN result_max_source_size INTEGER NULL CHECK (result_max_source_size > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows DROP COLUMN IF EXISTS result_max_source_size;
+ALTER TABLE flows DROP COLUMN IF EXISTS result_max_size;
+-- +goose StatementEnd
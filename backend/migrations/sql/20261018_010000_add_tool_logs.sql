@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- tool_logs keeps the copy of the search tool logs, rows aren't bound to flows by the foreign keys
+-- because they are written asynchronously and must survive the flow deletion
+CREATE TABLE tool_logs (
+  id            BIGINT             PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  initiator     MSGCHAIN_TYPE      NOT NULL,
+  executor      MSGCHAIN_TYPE      NOT NULL,
+  engine        SEARCHENGINE_TYPE  NOT NULL,
+  query         TEXT               NOT NULL,
+  result        TEXT               NOT NULL DEFAULT '',
+  flow_id       BIGINT             NOT NULL,
+  task_id       BIGINT             NULL,
+  subtask_id    BIGINT             NULL,
+  created_at    TIMESTAMPTZ        DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX tool_logs_flow_id_idx ON tool_logs(flow_id);
+CREATE INDEX tool_logs_engine_idx ON tool_logs(engine);
+CREATE INDEX tool_logs_created_at_idx ON tool_logs(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tool_logs;
+-- +goose StatementEnd
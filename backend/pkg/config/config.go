@@ -22,19 +22,29 @@ type Config struct {
 	DataDir     string `env:"DATA_DIR" envDefault:"./data"`
 	AskUser     bool   `env:"ASK_USER" envDefault:"false"`
 
+	// Language of the flow when it's detected from the input with low confidence
+	FlowDefaultLanguage string `env:"FLOW_DEFAULT_LANGUAGE" envDefault:"English"`
+
 	// For communication with PentAGI Cloud API
 	InstallationID string `env:"INSTALLATION_ID"`
 	LicenseKey     string `env:"LICENSE_KEY"`
 
 	// Docker (terminal) settings
-	DockerInside                 bool   `env:"DOCKER_INSIDE" envDefault:"false"`
-	DockerNetAdmin               bool   `env:"DOCKER_NET_ADMIN" envDefault:"false"`
-	DockerSocket                 string `env:"DOCKER_SOCKET"`
-	DockerNetwork                string `env:"DOCKER_NETWORK"`
-	DockerPublicIP               string `env:"DOCKER_PUBLIC_IP" envDefault:"0.0.0.0"`
-	DockerWorkDir                string `env:"DOCKER_WORK_DIR"`
-	DockerDefaultImage           string `env:"DOCKER_DEFAULT_IMAGE" envDefault:"debian:latest"`
-	DockerDefaultImageForPentest string `env:"DOCKER_DEFAULT_IMAGE_FOR_PENTEST" envDefault:"vxcontrol/kali-linux"`
+	DockerInside                 bool    `env:"DOCKER_INSIDE" envDefault:"false"`
+	DockerNetAdmin               bool    `env:"DOCKER_NET_ADMIN" envDefault:"false"`
+	DockerSocket                 string  `env:"DOCKER_SOCKET"`
+	DockerNetwork                string  `env:"DOCKER_NETWORK"`
+	DockerPublicIP               string  `env:"DOCKER_PUBLIC_IP" envDefault:"0.0.0.0"`
+	DockerWorkDir                string  `env:"DOCKER_WORK_DIR"`
+	DockerDefaultImage           string  `env:"DOCKER_DEFAULT_IMAGE" envDefault:"debian:latest"`
+	DockerDefaultImageForPentest string  `env:"DOCKER_DEFAULT_IMAGE_FOR_PENTEST" envDefault:"vxcontrol/kali-linux"`
+	DockerCPULimit               float64 `env:"DOCKER_CPU_LIMIT" envDefault:"2"`
+	DockerMemoryLimit            int64   `env:"DOCKER_MEMORY_LIMIT" envDefault:"4096"`
+	DockerPidsLimit              int64   `env:"DOCKER_PIDS_LIMIT" envDefault:"1024"`
+	DockerMaxCPULimit            float64 `env:"DOCKER_MAX_CPU_LIMIT" envDefault:"8"`
+	DockerMaxMemoryLimit         int64   `env:"DOCKER_MAX_MEMORY_LIMIT" envDefault:"16384"`
+	DockerMaxPidsLimit           int64   `env:"DOCKER_MAX_PIDS_LIMIT" envDefault:"4096"`
+	DockerReaperInterval         int     `env:"DOCKER_REAPER_INTERVAL" envDefault:"300"`
 
 	// HTTP and GraphQL server settings
 	ServerPort   int    `env:"SERVER_PORT" envDefault:"8080"`
@@ -43,6 +53,27 @@ type Config struct {
 	ServerSSLKey string `env:"SERVER_SSL_KEY"`
 	ServerSSLCrt string `env:"SERVER_SSL_CRT"`
 
+	// Maximum size in bytes of the API request body (0 disables the limit)
+	ServerMaxBodySize int64 `env:"SERVER_MAX_BODY_SIZE" envDefault:"10485760"`
+
+	// API requests per minute of the user in each flow route group (0 disables the limit), admins use their own
+	// limit, the overrides of the route groups and tiers are e.g. "flows:60,flows.admin:600,logs:300"
+	APIRateLimitRPM      int            `env:"API_RATE_LIMIT_RPM" envDefault:"0"`
+	APIRateLimitAdminRPM int            `env:"API_RATE_LIMIT_ADMIN_RPM" envDefault:"0"`
+	APIRateLimits        map[string]int `env:"API_RATE_LIMITS"`
+
+	// Storage of the uploaded flow attachments, DATA_DIR/attachments is used if it's empty,
+	// the size of the single attachment overrides the request body limit of the upload endpoint
+	AttachmentsDir     string `env:"ATTACHMENTS_DIR"`
+	AttachmentsMaxSize int64  `env:"ATTACHMENTS_MAX_SIZE" envDefault:"52428800"`
+
+	// Storage of the artifacts saved by the agents, DATA_DIR/artifacts is used if it's empty
+	ArtifactsDir     string `env:"ARTIFACTS_DIR"`
+	ArtifactsMaxSize int64  `env:"ARTIFACTS_MAX_SIZE" envDefault:"10485760"`
+
+	// Graceful shutdown timeout in seconds to pause running flows and close connections
+	ShutdownGracePeriod int `env:"SHUTDOWN_GRACE_PERIOD" envDefault:"30"`
+
 	// Frontend static URL
 	StaticURL   *url.URL `env:"STATIC_URL"`
 	StaticDir   string   `env:"STATIC_DIR" envDefault:"./fe"`
@@ -51,10 +82,17 @@ type Config struct {
 	// Cookie signing salt
 	CookieSigningSalt string `env:"COOKIE_SIGNING_SALT"`
 
+	// Key to encrypt flow secrets in the database, cookie signing salt is used if it's empty,
+	// flows can't have secrets if both of them are empty
+	FlowSecretsKey string `env:"FLOW_SECRETS_KEY"`
+
 	// Scraper (browser)
 	ScraperPublicURL  string `env:"SCRAPER_PUBLIC_URL"`
 	ScraperPrivateURL string `env:"SCRAPER_PRIVATE_URL"`
 
+	// Headless browser with the remote devtools endpoint, e.g. http://chrome:9222 or ws://chrome:9222/devtools/browser/<id>
+	HeadlessBrowserURL string `env:"HEADLESS_BROWSER_URL"`
+
 	// OpenAI
 	OpenAIKey       string `env:"OPEN_AI_KEY"`
 	OpenAIServerURL string `env:"OPEN_AI_SERVER_URL" envDefault:"https://api.openai.com/v1"`
@@ -62,6 +100,8 @@ type Config struct {
 	// Anthropic
 	AnthropicAPIKey    string `env:"ANTHROPIC_API_KEY"`
 	AnthropicServerURL string `env:"ANTHROPIC_SERVER_URL" envDefault:"https://api.anthropic.com/v1"`
+	// AnthropicPromptCaching marks the system prompt, tools and messages history with cache breakpoints
+	AnthropicPromptCaching bool `env:"ANTHROPIC_PROMPT_CACHING" envDefault:"true"`
 
 	// Embedding provider
 	EmbeddingURL           string `env:"EMBEDDING_URL"`
@@ -71,6 +111,12 @@ type Config struct {
 	EmbeddingBatchSize     int    `env:"EMBEDDING_BATCH_SIZE" envDefault:"512"`
 	EmbeddingProvider      string `env:"EMBEDDING_PROVIDER" envDefault:"openai"`
 
+	// Flow memory semantic search, the provider and the model default to the embedding provider ones
+	FlowMemorySemanticSearch      bool   `env:"FLOW_MEMORY_SEMANTIC_SEARCH" envDefault:"false"`
+	FlowMemoryEmbeddingProvider   string `env:"FLOW_MEMORY_EMBEDDING_PROVIDER"`
+	FlowMemoryEmbeddingModel      string `env:"FLOW_MEMORY_EMBEDDING_MODEL"`
+	FlowMemoryEmbeddingDimensions int    `env:"FLOW_MEMORY_EMBEDDING_DIMENSIONS" envDefault:"0"`
+
 	// Summarizer
 	SummarizerPreserveLast   bool `env:"SUMMARIZER_PRESERVE_LAST" envDefault:"true"`
 	SummarizerUseQA          bool `env:"SUMMARIZER_USE_QA" envDefault:"true"`
@@ -132,6 +178,9 @@ type Config struct {
 	QwenServerURL string `env:"QWEN_SERVER_URL" envDefault:"https://dashscope-us.aliyuncs.com/compatible-mode/v1"`
 	QwenProvider  string `env:"QWEN_PROVIDER"`
 
+	// Mock provider with scripted responses from the fixture file to test flows without LLM calls
+	MockProviderFixture string `env:"MOCK_PROVIDER_FIXTURE_PATH"`
+
 	// DuckDuckGo search engine
 	DuckDuckGoEnabled    bool   `env:"DUCKDUCKGO_ENABLED" envDefault:"true"`
 	DuckDuckGoRegion     string `env:"DUCKDUCKGO_REGION"`
@@ -139,8 +188,76 @@ type Config struct {
 	DuckDuckGoTimeRange  string `env:"DUCKDUCKGO_TIME_RANGE"`
 
 	// Sploitus exploit aggregator (https://sploitus.com)
-	// service under cloudflare protection, IP should have good reputation to avoid being blocked
-	SploitusEnabled bool `env:"SPLOITUS_ENABLED" envDefault:"false"`
+	// service under cloudflare protection, IP should have good reputation to avoid being blocked,
+	// rate limited and server error responses are retried until max attempts, 1 disables retries,
+	// results with the same CVE or title are collapsed into one unless de-duplication is disabled
+	SploitusEnabled      bool `env:"SPLOITUS_ENABLED" envDefault:"false"`
+	SploitusMaxAttempts  int  `env:"SPLOITUS_MAX_ATTEMPTS" envDefault:"3"`
+	SploitusDedupEnabled bool `env:"SPLOITUS_DEDUP_ENABLED" envDefault:"true"`
+
+	// GitHub search API for exploits and PoCs, the tool is available only if the token is set
+	GithubSearchToken string `env:"GITHUB_SEARCH_TOKEN"`
+
+	// Offline ExploitDB index, path to files_exploits.csv from the exploitdb repository,
+	// the tool is available only if the file is found
+	ExploitDBPath string `env:"EXPLOITDB_PATH"`
+
+	// VirusTotal API for file hash and URL reputation, the tool is available only if the key is set
+	VirusTotalAPIKey string `env:"VIRUSTOTAL_API_KEY"`
+
+	// Wayback Machine CDX API for URL capture history, doesn't require an API key
+	WaybackEnabled bool `env:"WAYBACK_ENABLED" envDefault:"true"`
+
+	// RDAP and WHOIS lookups of domains and IP addresses, WHOIS is used if RDAP is not available
+	WhoisEnabled bool `env:"WHOIS_ENABLED" envDefault:"true"`
+
+	// EPSS scores from FIRST API for CVE prioritization, also annotates Sploitus exploits
+	EPSSEnabled bool `env:"EPSS_ENABLED" envDefault:"true"`
+
+	// robots.txt and sitemap discovery of target sites
+	RobotsEnabled bool `env:"ROBOTS_ENABLED" envDefault:"true"`
+
+	// HTTP security headers analysis of target URLs
+	HTTPHeadersEnabled bool `env:"HTTP_HEADERS_ENABLED" envDefault:"true"`
+
+	// TLS configuration and certificate chain inspection of target hosts
+	TLSInspectEnabled bool `env:"TLS_INSPECT_ENABLED" envDefault:"true"`
+
+	// TCP connect port scan of target hosts, max ports and parallel connections of the single scan
+	PortScanEnabled        bool `env:"PORT_SCAN_ENABLED" envDefault:"true"`
+	PortScanMaxPorts       int  `env:"PORT_SCAN_MAX_PORTS" envDefault:"1024"`
+	PortScanMaxConcurrency int  `env:"PORT_SCAN_MAX_CONCURRENCY" envDefault:"100"`
+
+	// HTTP content discovery of target sites, max requests, parallel requests and requests per second to the host
+	HTTPFuzzEnabled        bool `env:"HTTP_FUZZ_ENABLED" envDefault:"true"`
+	HTTPFuzzMaxRequests    int  `env:"HTTP_FUZZ_MAX_REQUESTS" envDefault:"1000"`
+	HTTPFuzzMaxConcurrency int  `env:"HTTP_FUZZ_MAX_CONCURRENCY" envDefault:"20"`
+	HTTPFuzzMaxRate        int  `env:"HTTP_FUZZ_MAX_RATE" envDefault:"50"`
+
+	// Text recognition of image artifacts and screenshots, the backend is tesseract or google_vision,
+	// tesseract languages are joined by "+" like "eng+rus"
+	OCREnabled            bool   `env:"OCR_ENABLED" envDefault:"false"`
+	OCRBackend            string `env:"OCR_BACKEND" envDefault:"tesseract"`
+	OCRTesseractPath      string `env:"OCR_TESSERACT_PATH" envDefault:"tesseract"`
+	OCRTesseractLanguages string `env:"OCR_TESSERACT_LANGUAGES" envDefault:"eng"`
+	OCRGoogleVisionAPIKey string `env:"OCR_GOOGLE_VISION_API_KEY"`
+
+	// Shared cache for tools results, Redis is used if URL is set, in-memory cache otherwise
+	ToolsCacheRedisURL   string `env:"TOOLS_CACHE_REDIS_URL"`
+	ToolsCacheMaxEntries int    `env:"TOOLS_CACHE_MAX_ENTRIES" envDefault:"10000"`
+
+	// Search tools results cache TTL in seconds (0 disables caching), TTL of the single tool
+	// may be overridden in form of "tavily:600,google:86400"
+	SearchCacheTTL      int            `env:"SEARCH_CACHE_TTL" envDefault:"3600"`
+	SearchCacheToolsTTL map[string]int `env:"SEARCH_CACHE_TOOLS_TTL"`
+
+	// Search tools logs backend: "flow" (searchlogs of the flow shown in the UI), "postgres" (tool_logs table
+	// written in the background through the buffer of SEARCH_LOG_BUFFER_SIZE logs) or "noop"
+	SearchLogBackend    string `env:"SEARCH_LOG_BACKEND" envDefault:"flow"`
+	SearchLogBufferSize int    `env:"SEARCH_LOG_BUFFER_SIZE" envDefault:"1024"`
+
+	// LLM responses cache TTL in seconds (0 disables caching), flows opt in to the cache on creation
+	LLMResponseCacheTTL int `env:"LLM_RESPONSE_CACHE_TTL" envDefault:"86400"`
 
 	// Google search engine
 	GoogleAPIKey string `env:"GOOGLE_API_KEY"`
@@ -222,6 +339,58 @@ type Config struct {
 
 	// Agent planning step for pentester, coder, installer
 	AgentPlanningStepEnabled bool `env:"AGENT_PLANNING_STEP_ENABLED" envDefault:"false"`
+
+	// LLM calls rate limiting per user, provider and model (0 disables the limit)
+	LLMRateLimitRPM     int `env:"LLM_RATE_LIMIT_RPM" envDefault:"0"`
+	LLMRateLimitTPM     int `env:"LLM_RATE_LIMIT_TPM" envDefault:"0"`
+	LLMRateLimitMaxWait int `env:"LLM_RATE_LIMIT_MAX_WAIT" envDefault:"60"`
+
+	// Default timeout in seconds of the single LLM call (0 disables the timeout), flows may override it
+	LLMCallTimeout int `env:"LLM_CALL_TIMEOUT" envDefault:"600"`
+
+	// Default prices in USD per 1K tokens for models which are missing in the pricing table and provider config
+	PricingDefaultInputPer1K  float64 `env:"PRICING_DEFAULT_INPUT_PER_1K" envDefault:"0"`
+	PricingDefaultOutputPer1K float64 `env:"PRICING_DEFAULT_OUTPUT_PER_1K" envDefault:"0"`
+
+	// Concurrently running flows limit per user (0 disables the limit) and interval in seconds to start queued flows
+	FlowsMaxRunning      int `env:"FLOWS_MAX_RUNNING" envDefault:"0"`
+	FlowsMaxRunningAdmin int `env:"FLOWS_MAX_RUNNING_ADMIN" envDefault:"0"`
+	FlowsQueueInterval   int `env:"FLOWS_QUEUE_INTERVAL" envDefault:"10"`
+
+	// Seconds to keep the containers, snapshots, attachments and artifacts of the deleted flow so it can be
+	// restored with them, the purge runs with the reaper interval (negative value disables the purge)
+	FlowsDeleteRetention int `env:"FLOWS_DELETE_RETENTION" envDefault:"86400"`
+
+	// Resume interrupted flows after the server restart, otherwise they are marked as failed
+	FlowsAutoResume bool `env:"FLOWS_AUTO_RESUME" envDefault:"true"`
+
+	// Default tool calls limits of the flow which is created without its own ones (0 disables the limit)
+	ToolCallsPerMinute int   `env:"TOOL_CALLS_PER_MINUTE" envDefault:"0"`
+	ToolCallsMax       int64 `env:"TOOL_CALLS_MAX" envDefault:"0"`
+
+	// Timeout overrides of the single tool call in seconds, e.g. "browser:300,whois:20" (0 disables the timeout)
+	ToolTimeouts map[string]int `env:"TOOL_TIMEOUTS"`
+
+	// Default size limits of the tool result in bytes of the flow which is created without its own ones
+	ResultMaxSize       int `env:"RESULT_MAX_SIZE" envDefault:"81920"`
+	ResultMaxSourceSize int `env:"RESULT_MAX_SOURCE_SIZE" envDefault:"51200"`
+
+	// Redaction of the secrets in the tool arguments and results before they are logged, the patterns file
+	// is YAML list of {name, regex} added to the default patterns, the strings of 32+ characters with the
+	// entropy not less than TOOL_REDACT_MIN_ENTROPY bits per character are redacted too (0 disables the
+	// entropy check), the results returned to the agent are redacted only if TOOL_REDACT_RESULTS is set
+	ToolRedactEnabled      bool    `env:"TOOL_REDACT_ENABLED" envDefault:"true"`
+	ToolRedactPatternsFile string  `env:"TOOL_REDACT_PATTERNS_FILE"`
+	ToolRedactMinEntropy   float64 `env:"TOOL_REDACT_MIN_ENTROPY" envDefault:"0"`
+	ToolRedactResults      bool    `env:"TOOL_REDACT_RESULTS" envDefault:"false"`
+
+	// Default planning limits of the task of the flow which is created without its own ones (0 disables the limit)
+	PlanMaxSubtasks int `env:"PLAN_MAX_SUBTASKS" envDefault:"0"`
+	PlanMaxDepth    int `env:"PLAN_MAX_DEPTH" envDefault:"18"`
+
+	// Terminal commands policy of all flows, rules are separated by semicolons, the flow policy can only narrow it
+	TerminalCommandAllow []string `env:"TERMINAL_COMMAND_ALLOW" envSeparator:";"`
+	TerminalCommandDeny  []string `env:"TERMINAL_COMMAND_DENY" envSeparator:";"`
 }
 
 func NewConfig() (*Config, error) {
@@ -295,6 +464,7 @@ func (c *Config) GetSecretPatterns() []patterns.Pattern {
 		{c.DatabaseURL, "Database URL"},
 		{c.LicenseKey, "License Key"},
 		{c.CookieSigningSalt, "Cookie Salt"},
+		{c.FlowSecretsKey, "Flow Secrets Key"},
 		{c.OpenAIKey, "OpenAI Key"},
 		{c.AnthropicAPIKey, "Anthropic Key"},
 		{c.EmbeddingKey, "Embedding Key"},
@@ -311,6 +481,7 @@ func (c *Config) GetSecretPatterns() []patterns.Pattern {
 		{c.QwenAPIKey, "Qwen Key"},
 		{c.GoogleAPIKey, "Google API Key"},
 		{c.GoogleCXKey, "Google CX Key"},
+		{c.OCRGoogleVisionAPIKey, "Google Vision Key"},
 		{c.OAuthGoogleClientID, "Google Client ID"},
 		{c.OAuthGoogleClientSecret, "Google Client Secret"},
 		{c.OAuthGithubClientID, "Github Client ID"},
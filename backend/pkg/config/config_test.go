@@ -47,6 +47,16 @@ func TestGetSecretPatterns_WithSecrets(t *testing.T) {
 	}
 }
 
+func TestGetSecretPatterns_FlowSecretsKey(t *testing.T) {
+	cfg := &Config{FlowSecretsKey: "flow-secrets-key-value"}
+
+	patterns := cfg.GetSecretPatterns()
+
+	if len(patterns) != 1 || patterns[0].Name != "Flow Secrets Key" {
+		t.Errorf("expected flow secrets key pattern, got %v", patterns)
+	}
+}
+
 func TestGetSecretPatterns_TrimsWhitespace(t *testing.T) {
 	cfg := &Config{
 		OpenAIKey:    "  sk-1234  ",
@@ -269,16 +279,21 @@ func clearConfigEnv(t *testing.T) {
 	t.Helper()
 
 	envVars := []string{
-		"DATABASE_URL", "DEBUG", "DATA_DIR", "ASK_USER", "INSTALLATION_ID", "LICENSE_KEY",
+		"DATABASE_URL", "DEBUG", "DATA_DIR", "ASK_USER", "FLOW_DEFAULT_LANGUAGE", "INSTALLATION_ID", "LICENSE_KEY",
 		"DOCKER_INSIDE", "DOCKER_NET_ADMIN", "DOCKER_SOCKET", "DOCKER_NETWORK",
 		"DOCKER_PUBLIC_IP", "DOCKER_WORK_DIR", "DOCKER_DEFAULT_IMAGE", "DOCKER_DEFAULT_IMAGE_FOR_PENTEST",
+		"DOCKER_CPU_LIMIT", "DOCKER_MEMORY_LIMIT", "DOCKER_PIDS_LIMIT",
+		"DOCKER_MAX_CPU_LIMIT", "DOCKER_MAX_MEMORY_LIMIT", "DOCKER_MAX_PIDS_LIMIT", "DOCKER_REAPER_INTERVAL",
 		"SERVER_PORT", "SERVER_HOST", "SERVER_USE_SSL", "SERVER_SSL_KEY", "SERVER_SSL_CRT",
-		"STATIC_URL", "STATIC_DIR", "CORS_ORIGINS", "COOKIE_SIGNING_SALT",
-		"SCRAPER_PUBLIC_URL", "SCRAPER_PRIVATE_URL",
+		"SERVER_MAX_BODY_SIZE", "API_RATE_LIMIT_RPM", "API_RATE_LIMIT_ADMIN_RPM", "API_RATE_LIMITS", "ATTACHMENTS_DIR", "ATTACHMENTS_MAX_SIZE", "ARTIFACTS_DIR", "ARTIFACTS_MAX_SIZE", "SHUTDOWN_GRACE_PERIOD",
+		"STATIC_URL", "STATIC_DIR", "CORS_ORIGINS", "COOKIE_SIGNING_SALT", "FLOW_SECRETS_KEY",
+		"SCRAPER_PUBLIC_URL", "SCRAPER_PRIVATE_URL", "HEADLESS_BROWSER_URL",
 		"OPEN_AI_KEY", "OPEN_AI_SERVER_URL",
-		"ANTHROPIC_API_KEY", "ANTHROPIC_SERVER_URL",
+		"ANTHROPIC_API_KEY", "ANTHROPIC_SERVER_URL", "ANTHROPIC_PROMPT_CACHING",
 		"EMBEDDING_URL", "EMBEDDING_KEY", "EMBEDDING_MODEL",
 		"EMBEDDING_STRIP_NEW_LINES", "EMBEDDING_BATCH_SIZE", "EMBEDDING_PROVIDER",
+		"FLOW_MEMORY_SEMANTIC_SEARCH", "FLOW_MEMORY_EMBEDDING_PROVIDER", "FLOW_MEMORY_EMBEDDING_MODEL",
+		"FLOW_MEMORY_EMBEDDING_DIMENSIONS",
 		"SUMMARIZER_PRESERVE_LAST", "SUMMARIZER_USE_QA", "SUMMARIZER_SUM_MSG_HUMAN_IN_QA",
 		"SUMMARIZER_LAST_SEC_BYTES", "SUMMARIZER_MAX_BP_BYTES",
 		"SUMMARIZER_MAX_QA_SECTIONS", "SUMMARIZER_MAX_QA_BYTES", "SUMMARIZER_KEEP_QA_SECTIONS",
@@ -294,8 +309,10 @@ func clearConfigEnv(t *testing.T) {
 		"GLM_API_KEY", "GLM_SERVER_URL", "GLM_PROVIDER",
 		"KIMI_API_KEY", "KIMI_SERVER_URL", "KIMI_PROVIDER",
 		"QWEN_API_KEY", "QWEN_SERVER_URL", "QWEN_PROVIDER",
+		"MOCK_PROVIDER_FIXTURE_PATH",
 		"DUCKDUCKGO_ENABLED", "DUCKDUCKGO_REGION", "DUCKDUCKGO_SAFESEARCH", "DUCKDUCKGO_TIME_RANGE",
-		"SPLOITUS_ENABLED",
+		"SPLOITUS_ENABLED", "SPLOITUS_MAX_ATTEMPTS", "SPLOITUS_DEDUP_ENABLED", "GITHUB_SEARCH_TOKEN", "EXPLOITDB_PATH", "VIRUSTOTAL_API_KEY", "WAYBACK_ENABLED", "WHOIS_ENABLED", "EPSS_ENABLED", "ROBOTS_ENABLED", "HTTP_HEADERS_ENABLED", "TLS_INSPECT_ENABLED", "PORT_SCAN_ENABLED", "PORT_SCAN_MAX_PORTS", "PORT_SCAN_MAX_CONCURRENCY", "HTTP_FUZZ_ENABLED", "HTTP_FUZZ_MAX_REQUESTS", "HTTP_FUZZ_MAX_CONCURRENCY", "HTTP_FUZZ_MAX_RATE", "OCR_ENABLED", "OCR_BACKEND", "OCR_TESSERACT_PATH", "OCR_TESSERACT_LANGUAGES", "OCR_GOOGLE_VISION_API_KEY", "TOOLS_CACHE_REDIS_URL", "TOOLS_CACHE_MAX_ENTRIES",
+		"SEARCH_CACHE_TTL", "SEARCH_CACHE_TOOLS_TTL", "SEARCH_LOG_BACKEND", "SEARCH_LOG_BUFFER_SIZE", "LLM_RESPONSE_CACHE_TTL",
 		"GOOGLE_API_KEY", "GOOGLE_CX_KEY", "GOOGLE_LR_KEY",
 		"OAUTH_GOOGLE_CLIENT_ID", "OAUTH_GOOGLE_CLIENT_SECRET",
 		"OAUTH_GITHUB_CLIENT_ID", "OAUTH_GITHUB_CLIENT_SECRET",
@@ -313,6 +330,13 @@ func clearConfigEnv(t *testing.T) {
 		"EXECUTION_MONITOR_ENABLED", "EXECUTION_MONITOR_SAME_TOOL_LIMIT", "EXECUTION_MONITOR_TOTAL_TOOL_LIMIT",
 		"MAX_GENERAL_AGENT_TOOL_CALLS", "MAX_LIMITED_AGENT_TOOL_CALLS",
 		"AGENT_PLANNING_STEP_ENABLED",
+		"LLM_RATE_LIMIT_RPM", "LLM_RATE_LIMIT_TPM", "LLM_RATE_LIMIT_MAX_WAIT", "LLM_CALL_TIMEOUT",
+		"PRICING_DEFAULT_INPUT_PER_1K", "PRICING_DEFAULT_OUTPUT_PER_1K",
+		"FLOWS_MAX_RUNNING", "FLOWS_MAX_RUNNING_ADMIN", "FLOWS_QUEUE_INTERVAL", "FLOWS_DELETE_RETENTION",
+		"FLOWS_AUTO_RESUME",
+		"TOOL_CALLS_PER_MINUTE", "TOOL_CALLS_MAX", "TOOL_TIMEOUTS", "RESULT_MAX_SIZE", "RESULT_MAX_SOURCE_SIZE",
+		"TOOL_REDACT_ENABLED", "TOOL_REDACT_PATTERNS_FILE", "TOOL_REDACT_MIN_ENTROPY", "TOOL_REDACT_RESULTS",
+		"TERMINAL_COMMAND_ALLOW", "TERMINAL_COMMAND_DENY",
 	}
 	for _, v := range envVars {
 		t.Setenv(v, "")
@@ -331,13 +355,29 @@ func TestNewConfig_Defaults(t *testing.T) {
 	assert.Equal(t, "0.0.0.0", config.ServerHost)
 	assert.Equal(t, false, config.Debug)
 	assert.Equal(t, "./data", config.DataDir)
+	assert.Equal(t, "English", config.FlowDefaultLanguage)
 	assert.Equal(t, false, config.ServerUseSSL)
+	assert.Equal(t, int64(10485760), config.ServerMaxBodySize)
+	assert.Equal(t, "", config.AttachmentsDir)
+	assert.Equal(t, int64(52428800), config.AttachmentsMaxSize)
+	assert.Equal(t, "", config.ArtifactsDir)
+	assert.Equal(t, int64(10485760), config.ArtifactsMaxSize)
+	assert.Equal(t, 30, config.ShutdownGracePeriod)
 	assert.Equal(t, "openai", config.EmbeddingProvider)
 	assert.Equal(t, 512, config.EmbeddingBatchSize)
 	assert.Equal(t, true, config.EmbeddingStripNewLines)
+	assert.Equal(t, false, config.FlowMemorySemanticSearch)
+	assert.Equal(t, 0, config.FlowMemoryEmbeddingDimensions)
 	assert.Equal(t, true, config.DuckDuckGoEnabled)
 	assert.Equal(t, "debian:latest", config.DockerDefaultImage)
 	assert.Equal(t, "vxcontrol/kali-linux", config.DockerDefaultImageForPentest)
+	assert.Equal(t, 2.0, config.DockerCPULimit)
+	assert.Equal(t, int64(4096), config.DockerMemoryLimit)
+	assert.Equal(t, int64(1024), config.DockerPidsLimit)
+	assert.Equal(t, 8.0, config.DockerMaxCPULimit)
+	assert.Equal(t, int64(16384), config.DockerMaxMemoryLimit)
+	assert.Equal(t, int64(4096), config.DockerMaxPidsLimit)
+	assert.Equal(t, 300, config.DockerReaperInterval)
 }
 
 func TestNewConfig_EnvOverride(t *testing.T) {
@@ -366,12 +406,14 @@ func TestNewConfig_ProviderDefaults(t *testing.T) {
 
 	assert.Equal(t, "https://api.openai.com/v1", config.OpenAIServerURL)
 	assert.Equal(t, "https://api.anthropic.com/v1", config.AnthropicServerURL)
+	assert.True(t, config.AnthropicPromptCaching)
 	assert.Equal(t, "https://generativelanguage.googleapis.com", config.GeminiServerURL)
 	assert.Equal(t, "us-east-1", config.BedrockRegion)
 	assert.Equal(t, "https://api.deepseek.com", config.DeepSeekServerURL)
 	assert.Equal(t, "https://api.z.ai/api/paas/v4", config.GLMServerURL)
 	assert.Equal(t, "https://api.moonshot.ai/v1", config.KimiServerURL)
 	assert.Equal(t, "https://dashscope-us.aliyuncs.com/compatible-mode/v1", config.QwenServerURL)
+	assert.Empty(t, config.MockProviderFixture)
 }
 
 func TestNewConfig_StaticURL(t *testing.T) {
@@ -427,6 +469,111 @@ func TestNewConfig_SearchEngineDefaults(t *testing.T) {
 	assert.Equal(t, "general", config.SearxngCategories)
 	assert.Equal(t, "0", config.SearxngSafeSearch)
 	assert.Equal(t, "lang_en", config.GoogleLRKey)
+	assert.Equal(t, 3600, config.SearchCacheTTL)
+	assert.Empty(t, config.SearchCacheToolsTTL)
+	assert.Equal(t, 86400, config.LLMResponseCacheTTL)
+}
+
+func TestNewConfig_SearchCacheToolsTTL(t *testing.T) {
+	clearConfigEnv(t)
+	t.Chdir(t.TempDir())
+	t.Setenv("SEARCH_CACHE_TOOLS_TTL", "tavily:600,google:0")
+
+	config, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"tavily": 600, "google": 0}, config.SearchCacheToolsTTL)
+}
+
+func TestNewConfig_SearchLog(t *testing.T) {
+	clearConfigEnv(t)
+	t.Chdir(t.TempDir())
+
+	config, err := NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "flow", config.SearchLogBackend)
+	assert.Equal(t, 1024, config.SearchLogBufferSize)
+
+	t.Setenv("SEARCH_LOG_BACKEND", "postgres")
+	t.Setenv("SEARCH_LOG_BUFFER_SIZE", "64")
+	config, err = NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", config.SearchLogBackend)
+	assert.Equal(t, 64, config.SearchLogBufferSize)
+}
+
+func TestNewConfig_ToolTimeouts(t *testing.T) {
+	clearConfigEnv(t)
+	t.Chdir(t.TempDir())
+
+	config, err := NewConfig()
+	require.NoError(t, err)
+	assert.Empty(t, config.ToolTimeouts)
+
+	t.Setenv("TOOL_TIMEOUTS", "browser:300,terminal:0")
+	config, err = NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"browser": 300, "terminal": 0}, config.ToolTimeouts)
+}
+
+func TestNewConfig_ResultSize(t *testing.T) {
+	clearConfigEnv(t)
+	t.Chdir(t.TempDir())
+
+	config, err := NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 80*1024, config.ResultMaxSize)
+	assert.Equal(t, 50*1024, config.ResultMaxSourceSize)
+
+	t.Setenv("RESULT_MAX_SIZE", "16384")
+	t.Setenv("RESULT_MAX_SOURCE_SIZE", "8192")
+	config, err = NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 16384, config.ResultMaxSize)
+	assert.Equal(t, 8192, config.ResultMaxSourceSize)
+}
+
+func TestNewConfig_APIRateLimit(t *testing.T) {
+	clearConfigEnv(t)
+	t.Chdir(t.TempDir())
+
+	config, err := NewConfig()
+	require.NoError(t, err)
+	assert.Zero(t, config.APIRateLimitRPM)
+	assert.Zero(t, config.APIRateLimitAdminRPM)
+	assert.Empty(t, config.APIRateLimits)
+
+	t.Setenv("API_RATE_LIMIT_RPM", "120")
+	t.Setenv("API_RATE_LIMIT_ADMIN_RPM", "1200")
+	t.Setenv("API_RATE_LIMITS", "flows:60,flows.admin:600,logs:0")
+	config, err = NewConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 120, config.APIRateLimitRPM)
+	assert.Equal(t, 1200, config.APIRateLimitAdminRPM)
+	assert.Equal(t, map[string]int{"flows": 60, "flows.admin": 600, "logs": 0}, config.APIRateLimits)
+}
+
+func TestNewConfig_ToolRedact(t *testing.T) {
+	clearConfigEnv(t)
+	t.Chdir(t.TempDir())
+
+	config, err := NewConfig()
+	require.NoError(t, err)
+	assert.True(t, config.ToolRedactEnabled)
+	assert.Empty(t, config.ToolRedactPatternsFile)
+	assert.Zero(t, config.ToolRedactMinEntropy)
+	assert.False(t, config.ToolRedactResults)
+
+	t.Setenv("TOOL_REDACT_ENABLED", "false")
+	t.Setenv("TOOL_REDACT_PATTERNS_FILE", "/opt/pentagi/conf/redact.yml")
+	t.Setenv("TOOL_REDACT_MIN_ENTROPY", "4.5")
+	t.Setenv("TOOL_REDACT_RESULTS", "true")
+	config, err = NewConfig()
+	require.NoError(t, err)
+	assert.False(t, config.ToolRedactEnabled)
+	assert.Equal(t, "/opt/pentagi/conf/redact.yml", config.ToolRedactPatternsFile)
+	assert.Equal(t, 4.5, config.ToolRedactMinEntropy)
+	assert.True(t, config.ToolRedactResults)
 }
 
 func TestEnsureInstallationID_GeneratesNewUUID(t *testing.T) {
@@ -561,6 +708,23 @@ func TestNewConfig_AgentSupervisionDefaults(t *testing.T) {
 	assert.Equal(t, 100, config.MaxGeneralAgentToolCalls)
 	assert.Equal(t, 20, config.MaxLimitedAgentToolCalls)
 	assert.Equal(t, false, config.AgentPlanningStepEnabled)
+	assert.Equal(t, 0, config.LLMRateLimitRPM)
+	assert.Equal(t, 0, config.LLMRateLimitTPM)
+	assert.Equal(t, 60, config.LLMRateLimitMaxWait)
+	assert.Equal(t, 600, config.LLMCallTimeout)
+	assert.Equal(t, 0.0, config.PricingDefaultInputPer1K)
+	assert.Equal(t, 0.0, config.PricingDefaultOutputPer1K)
+	assert.Equal(t, 0, config.FlowsMaxRunning)
+	assert.Equal(t, 0, config.FlowsMaxRunningAdmin)
+	assert.Equal(t, 10, config.FlowsQueueInterval)
+	assert.Equal(t, 86400, config.FlowsDeleteRetention)
+	assert.Equal(t, true, config.FlowsAutoResume)
+	assert.Equal(t, 0, config.ToolCallsPerMinute)
+	assert.Equal(t, int64(0), config.ToolCallsMax)
+	assert.Equal(t, 0, config.PlanMaxSubtasks)
+	assert.Equal(t, 18, config.PlanMaxDepth)
+	assert.Empty(t, config.TerminalCommandAllow)
+	assert.Empty(t, config.TerminalCommandDeny)
 }
 
 func TestNewConfig_AgentSupervisionOverride(t *testing.T) {
@@ -159,6 +159,30 @@ func NewAssistantWorker(ctx context.Context, awc newAssistantWorkerCtx) (Assista
 	if err != nil {
 		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to create flow tools executor", err)
 	}
+	toolLimits, err := getFlowToolCallLimits(ctx, awc.db, awc.cfg, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow tool calls limits", err)
+	}
+	executor.SetToolCallLimits(toolLimits)
+	resultLimits, err := getFlowResultLimits(ctx, awc.db, awc.cfg, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow tool result limits", err)
+	}
+	executor.SetResultSizeLimits(resultLimits)
+	commandPolicy, err := getFlowCommandPolicy(ctx, awc.db, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow command policy", err)
+	}
+	if err := executor.SetCommandPolicy(commandPolicy); err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to set command policy", err)
+	}
+	scope, err := getFlowScope(ctx, awc.db, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow scope", err)
+	}
+	if err := executor.SetFlowScope(scope); err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to set flow scope", err)
+	}
 	assistantProvider, err := awc.provs.NewAssistantProvider(ctx, awc.prvname, prompter, executor,
 		assistant.ID, awc.flowID, awc.userID, container.Image, awc.input, aslw.StreamFlowAssistantMsg)
 	if err != nil {
@@ -206,7 +230,7 @@ func NewAssistantWorker(ctx context.Context, awc newAssistantWorkerCtx) (Assista
 	executor.SetScreenshotProvider(workers.sw)
 	executor.SetAgentLogProvider(workers.alw)
 	executor.SetMsgLogProvider(aslw)
-	executor.SetSearchLogProvider(workers.slw)
+	executor.SetSearchLogProvider(workers.slp)
 	executor.SetTermLogProvider(workers.tlw)
 	executor.SetVectorStoreLogProvider(workers.vslw)
 	executor.SetGraphitiClient(awc.provs.GraphitiClient())
@@ -324,6 +348,30 @@ func LoadAssistantWorker(
 	if err != nil {
 		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to create flow tools executor", err)
 	}
+	toolLimits, err := getFlowToolCallLimits(ctx, awc.db, awc.cfg, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow tool calls limits", err)
+	}
+	executor.SetToolCallLimits(toolLimits)
+	resultLimits, err := getFlowResultLimits(ctx, awc.db, awc.cfg, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow tool result limits", err)
+	}
+	executor.SetResultSizeLimits(resultLimits)
+	commandPolicy, err := getFlowCommandPolicy(ctx, awc.db, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow command policy", err)
+	}
+	if err := executor.SetCommandPolicy(commandPolicy); err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to set command policy", err)
+	}
+	scope, err := getFlowScope(ctx, awc.db, awc.flowID)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to get flow scope", err)
+	}
+	if err := executor.SetFlowScope(scope); err != nil {
+		return nil, wrapErrorEndSpan(ctx, assistantSpan, "failed to set flow scope", err)
+	}
 	assistantProvider, err := awc.provs.LoadAssistantProvider(ctx, provider.ProviderName(assistant.ModelProviderName),
 		prompter, executor, assistant.ID, awc.flowID, awc.userID, container.Image, assistant.Language, assistant.Title,
 		assistant.ToolCallIDTemplate, aslw.StreamFlowAssistantMsg)
@@ -344,7 +392,7 @@ func LoadAssistantWorker(
 	executor.SetScreenshotProvider(workers.sw)
 	executor.SetAgentLogProvider(workers.alw)
 	executor.SetMsgLogProvider(aslw)
-	executor.SetSearchLogProvider(workers.slw)
+	executor.SetSearchLogProvider(workers.slp)
 	executor.SetTermLogProvider(workers.tlw)
 	executor.SetVectorStoreLogProvider(workers.vslw)
 
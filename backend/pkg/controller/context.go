@@ -29,6 +29,8 @@ type FlowContext struct {
 	TermLog    FlowTermLogWorker
 	MsgLog     FlowMsgLogWorker
 	Screenshot FlowScreenshotWorker
+
+	PlanLimits PlanLimits
 }
 
 type TaskContext struct {
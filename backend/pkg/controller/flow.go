@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,10 +24,20 @@ import (
 	"pentagi/pkg/tools"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const stopTaskTimeout = 5 * time.Second
 
+const (
+	// shutdownPausedReason is the stop reason of the flow which task was paused by the server shutdown
+	shutdownPausedReason = "paused by server shutdown"
+	// shutdownInterruptedReason is the stop reason of the flow which task wasn't paused within the grace period
+	shutdownInterruptedReason = "interrupted by server shutdown"
+	// restartInterruptedReason is the stop reason of the interrupted flow which isn't resumed after the restart
+	restartInterruptedReason = "interrupted by server restart, auto-resume is disabled"
+)
+
 type FlowWorker interface {
 	GetFlowID() int64
 	GetUserID() int64
@@ -39,25 +50,244 @@ type FlowWorker interface {
 	DeleteAssistant(ctx context.Context, assistantID int64) error
 	ListAssistants(ctx context.Context) []AssistantWorker
 	ListTasks(ctx context.Context) []TaskWorker
-	PutInput(ctx context.Context, input string) error
+	PutInput(ctx context.Context, input string) (int64, error)
+	// PutQueuedInput puts the input which was stored in the inputs history while the flow was queued
+	PutQueuedInput(ctx context.Context, inputID int64, input string) error
+	// Resume continues the task which was paused by the server shutdown without the user input
+	Resume(ctx context.Context) error
 	Finish(ctx context.Context) error
+	Archive(ctx context.Context) error
 	Stop(ctx context.Context) error
+	Pause(ctx context.Context) error
 	Rename(ctx context.Context, title string) error
+	UpdateMetadata(ctx context.Context, metadata FlowMetadata) error
+	GetBudget() FlowBudget
+	SetBudget(ctx context.Context, budget FlowBudget) error
+	CancelSubtask(ctx context.Context, taskID, subtaskID int64) error
+	SetSubtaskPriority(ctx context.Context, taskID, subtaskID int64, priority *int32) error
+}
+
+// FlowMetadata contains optional flow fields to update, nil fields are left untouched
+type FlowMetadata struct {
+	Title       *string
+	Language    *string
+	Description *string
+}
+
+// FlowBudget limits cumulative usage of the flow, nil fields are not limited
+type FlowBudget struct {
+	MaxCost   *float64
+	MaxTokens *int64
+}
+
+// IsLimited returns true if the budget has any limit
+func (fb FlowBudget) IsLimited() bool {
+	return fb.MaxCost != nil || fb.MaxTokens != nil
+}
+
+// exceededBy returns the reason to stop the flow if its usage exceeds the budget or empty string otherwise
+func (fb FlowBudget) exceededBy(usage database.GetFlowUsageStatsRow) string {
+	if fb.MaxCost != nil {
+		if cost := usage.TotalUsageCostIn + usage.TotalUsageCostOut; cost > *fb.MaxCost {
+			return fmt.Sprintf("cost budget exceeded: spent $%.4f of $%.4f", cost, *fb.MaxCost)
+		}
+	}
+	if fb.MaxTokens != nil {
+		if tokens := usage.TotalUsageIn + usage.TotalUsageOut; tokens > *fb.MaxTokens {
+			return fmt.Sprintf("token budget exceeded: used %d of %d tokens", tokens, *fb.MaxTokens)
+		}
+	}
+
+	return ""
+}
+
+func flowBudgetFromDB(flow database.Flow) FlowBudget {
+	return FlowBudget{
+		MaxCost:   database.NullFloat64ToFloat64(flow.BudgetMaxCost),
+		MaxTokens: database.NullInt64ToInt64(flow.BudgetMaxTokens),
+	}
+}
+
+// FlowToolLimits limits tool calls of the flow, nil fields use the defaults from the config
+type FlowToolLimits struct {
+	CallsPerMinute *int32
+	MaxCalls       *int64
+}
+
+// resolve returns the tool calls limits with the config defaults applied to the unset fields
+func (ftl FlowToolLimits) resolve(cfg *config.Config) tools.ToolCallLimits {
+	limits := tools.ToolCallLimits{
+		PerMinute: cfg.ToolCallsPerMinute,
+		MaxCalls:  cfg.ToolCallsMax,
+	}
+	if ftl.CallsPerMinute != nil {
+		limits.PerMinute = int(*ftl.CallsPerMinute)
+	}
+	if ftl.MaxCalls != nil {
+		limits.MaxCalls = *ftl.MaxCalls
+	}
+
+	return limits
+}
+
+func flowToolLimitsFromDB(flow database.Flow) FlowToolLimits {
+	return FlowToolLimits{
+		CallsPerMinute: database.NullInt32ToInt32(flow.ToolCallsPerMinute),
+		MaxCalls:       database.NullInt64ToInt64(flow.ToolCallsMax),
+	}
+}
+
+func flowCommandPolicyFromDB(flow database.Flow) (tools.CommandPolicy, error) {
+	var policy tools.CommandPolicy
+	if len(flow.CommandPolicy) != 0 {
+		if err := json.Unmarshal(flow.CommandPolicy, &policy); err != nil {
+			return policy, fmt.Errorf("failed to unmarshal flow %d command policy: %w", flow.ID, err)
+		}
+	}
+
+	return policy, nil
+}
+
+func flowScopeFromDB(flow database.Flow) (tools.FlowScope, error) {
+	var scope tools.FlowScope
+	if len(flow.Scope) != 0 {
+		if err := json.Unmarshal(flow.Scope, &scope); err != nil {
+			return scope, fmt.Errorf("failed to unmarshal flow %d scope: %w", flow.ID, err)
+		}
+	}
+
+	return scope, nil
+}
+
+// FlowPlanLimits limits the planning of the flow tasks, nil fields use the defaults from the config
+type FlowPlanLimits struct {
+	MaxSubtasks *int32
+	MaxDepth    *int32
+}
+
+// resolve returns the planning limits with the config defaults applied to the unset fields
+func (fpl FlowPlanLimits) resolve(cfg *config.Config) PlanLimits {
+	limits := PlanLimits{
+		MaxSubtasks: cfg.PlanMaxSubtasks,
+		MaxDepth:    cfg.PlanMaxDepth,
+	}
+	if fpl.MaxSubtasks != nil {
+		limits.MaxSubtasks = int(*fpl.MaxSubtasks)
+	}
+	if fpl.MaxDepth != nil {
+		limits.MaxDepth = int(*fpl.MaxDepth)
+	}
+
+	return limits
+}
+
+func flowPlanLimitsFromDB(flow database.Flow) FlowPlanLimits {
+	return FlowPlanLimits{
+		MaxSubtasks: database.NullInt32ToInt32(flow.PlanMaxSubtasks),
+		MaxDepth:    database.NullInt32ToInt32(flow.PlanMaxDepth),
+	}
+}
+
+// FlowResultLimits limits the size of the tool results of the flow, nil fields use the defaults from the config
+type FlowResultLimits struct {
+	MaxSize       *int32
+	MaxSourceSize *int32
+}
+
+// resolve returns the tool result limits with the config defaults applied to the unset fields
+func (frl FlowResultLimits) resolve(cfg *config.Config) tools.ResultSizeLimits {
+	limits := tools.ResultSizeLimits{
+		MaxResultSize: cfg.ResultMaxSize,
+		MaxSourceSize: cfg.ResultMaxSourceSize,
+	}
+	if frl.MaxSize != nil {
+		limits.MaxResultSize = int(*frl.MaxSize)
+	}
+	if frl.MaxSourceSize != nil {
+		limits.MaxSourceSize = int(*frl.MaxSourceSize)
+	}
+
+	return limits
+}
+
+func flowResultLimitsFromDB(flow database.Flow) FlowResultLimits {
+	return FlowResultLimits{
+		MaxSize:       database.NullInt32ToInt32(flow.ResultMaxSize),
+		MaxSourceSize: database.NullInt32ToInt32(flow.ResultMaxSourceSize),
+	}
+}
+
+// getFlowToolCallLimits returns the resolved tool calls limits of the flow to share them with its assistants
+func getFlowToolCallLimits(
+	ctx context.Context,
+	db database.Querier,
+	cfg *config.Config,
+	flowID int64,
+) (tools.ToolCallLimits, error) {
+	flow, err := db.GetFlow(ctx, flowID)
+	if err != nil {
+		return tools.ToolCallLimits{}, fmt.Errorf("failed to get flow %d: %w", flowID, err)
+	}
+
+	return flowToolLimitsFromDB(flow).resolve(cfg), nil
+}
+
+// getFlowResultLimits returns the resolved tool result limits of the flow to share them with its assistants
+func getFlowResultLimits(
+	ctx context.Context,
+	db database.Querier,
+	cfg *config.Config,
+	flowID int64,
+) (tools.ResultSizeLimits, error) {
+	flow, err := db.GetFlow(ctx, flowID)
+	if err != nil {
+		return tools.ResultSizeLimits{}, fmt.Errorf("failed to get flow %d: %w", flowID, err)
+	}
+
+	return flowResultLimitsFromDB(flow).resolve(cfg), nil
+}
+
+// getFlowCommandPolicy returns the terminal command policy of the flow for the executors created outside the flow worker
+func getFlowCommandPolicy(ctx context.Context, db database.Querier, flowID int64) (tools.CommandPolicy, error) {
+	flow, err := db.GetFlow(ctx, flowID)
+	if err != nil {
+		return tools.CommandPolicy{}, fmt.Errorf("failed to get flow %d: %w", flowID, err)
+	}
+
+	return flowCommandPolicyFromDB(flow)
+}
+
+// getFlowScope returns the network targets scope of the flow for the executors created outside the flow worker
+func getFlowScope(ctx context.Context, db database.Querier, flowID int64) (tools.FlowScope, error) {
+	flow, err := db.GetFlow(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow %d: %w", flowID, err)
+	}
+
+	return flowScopeFromDB(flow)
 }
 
 type flowWorker struct {
-	tc      TaskController
-	wg      *sync.WaitGroup
-	aws     map[int64]AssistantWorker
-	awsMX   *sync.Mutex
-	ctx     context.Context
-	cancel  context.CancelFunc
-	taskMX  *sync.Mutex
-	taskST  context.CancelFunc
-	taskWG  *sync.WaitGroup
-	input   chan flowInput
-	flowCtx *FlowContext
-	logger  *logrus.Entry
+	tc       TaskController
+	wg       *sync.WaitGroup
+	aws      map[int64]AssistantWorker
+	awsMX    *sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	taskMX   *sync.Mutex
+	taskST   context.CancelFunc
+	taskWG   *sync.WaitGroup
+	inputs   []flowInput
+	inputMX  *sync.Mutex
+	keepIn   bool // queued flow is paused by the shutdown and its input stays in the history
+	inputCh  chan struct{}
+	flowCtx  *FlowContext
+	logger   *logrus.Entry
+	budget   FlowBudget
+	budgetMX *sync.Mutex
+	stopping bool // budget stop is in progress
+	errorMX  *sync.Mutex
+	failing  bool // the flow has the provider error which wasn't cleared by a successful call
 }
 
 type newFlowWorkerCtx struct {
@@ -66,7 +296,26 @@ type newFlowWorkerCtx struct {
 	dryRun    bool
 	prvname   provider.ProviderName
 	prvtype   provider.ProviderType
+	fallbacks provider.ProvidersListNames
+	limits    docker.ContainerLimits
+	image     string
 	functions *tools.Functions
+	env       tools.FlowEnv
+	budget    FlowBudget
+	toolLimit FlowToolLimits
+	planLimit FlowPlanLimits
+	// resultLimit caps the tool results of the flow which are rendered by the tools themselves
+	resultLimit FlowResultLimits
+	// responseCache is stored with the flow and applied by the provider controller
+	responseCache providers.ResponseCacheMode
+	// providerTimeout overrides the default LLM call timeout in seconds, nil keeps the default
+	providerTimeout *int32
+	// systemPrompt replaces the default system prompt template of the primary agent, nil keeps the default
+	systemPrompt *string
+	// commandPolicy restricts the terminal commands of the flow in addition to the global policy
+	commandPolicy tools.CommandPolicy
+	// scope restricts the targets of the network tools of the flow, empty scope permits all targets
+	scope tools.FlowScope
 
 	flowWorkerCtx
 }
@@ -95,6 +344,7 @@ type flowProviderWorkers struct {
 	mlw  FlowMsgLogWorker
 	alw  FlowAgentLogWorker
 	slw  FlowSearchLogWorker
+	slp  tools.SearchLogProvider
 	tlw  FlowTermLogWorker
 	vslw FlowVectorStoreLogWorker
 	sw   FlowScreenshotWorker
@@ -102,9 +352,31 @@ type flowProviderWorkers struct {
 
 const flowInputTimeout = 1 * time.Second
 
+// budgetRaisedInput is passed to the task which was stopped by exceeded budget when the budget is raised
+const budgetRaisedInput = "continue after budget raise"
+
+// subtaskCanceledInput is passed to the task which was waiting for input of the canceled subtask
+// to continue with the next subtasks, it isn't delivered to any agent chain
+const subtaskCanceledInput = "continue after subtask cancellation"
+
+// inputCanceledStop is the reason to cancel the queued inputs which weren't consumed before the flow stop
+const inputCanceledStop = "flow was stopped before the input was consumed"
+
+// inputCanceledRestart is the reason to cancel the queued inputs which were lost by the server restart
+const inputCanceledRestart = "server was restarted before the input was consumed"
+
+// inputCanceledStarted is the reason to cancel the input of the queued flow which was started by another input
+const inputCanceledStarted = "flow was started by another input before it got a free slot"
+
+// flowLastErrorMaxLength limits the stored provider error, some providers put the whole HTML page into it
+const flowLastErrorMaxLength = 2048
+
+// flowInput is the queued input of the flow, id is the record in the inputs history
 type flowInput struct {
-	input string
-	done  chan error
+	id     int64
+	input  string
+	resume bool // continue the paused task without input, it isn't stored in the inputs history
+	done   chan error
 }
 
 func NewFlowWorker(
@@ -114,16 +386,45 @@ func NewFlowWorker(
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.NewFlowWorker")
 	defer span.End()
 
+	fallbacksBlob, err := json.Marshal(fwc.fallbacks)
+	if err != nil || fwc.fallbacks == nil {
+		fallbacksBlob = []byte("[]")
+	}
+
+	commandPolicyBlob, err := json.Marshal(fwc.commandPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal command policy: %w", err)
+	}
+
+	scopeBlob, err := json.Marshal(fwc.scope)
+	if err != nil || fwc.scope == nil {
+		scopeBlob = []byte("[]")
+	}
+
 	flow, err := fwc.db.CreateFlow(ctx, database.CreateFlowParams{
-		Title:              "untitled",
-		Status:             database.FlowStatusCreated,
-		Model:              "unknown",
-		ModelProviderName:  fwc.prvname.String(),
-		ModelProviderType:  database.ProviderType(fwc.prvtype),
-		Language:           "English",
-		ToolCallIDTemplate: cast.ToolCallIDTemplate,
-		Functions:          []byte("{}"),
-		UserID:             fwc.userID,
+		Title:               "untitled",
+		Status:              database.FlowStatusCreated,
+		Model:               "unknown",
+		ModelProviderName:   fwc.prvname.String(),
+		ModelProviderType:   database.ProviderType(fwc.prvtype),
+		Language:            "English",
+		ToolCallIDTemplate:  cast.ToolCallIDTemplate,
+		Functions:           []byte("{}"),
+		UserID:              fwc.userID,
+		FallbackProviders:   fallbacksBlob,
+		BudgetMaxCost:       database.Float64ToNullFloat64(fwc.budget.MaxCost),
+		BudgetMaxTokens:     database.Int64ToNullInt64(fwc.budget.MaxTokens),
+		ToolCallsPerMinute:  database.Int32ToNullInt32(fwc.toolLimit.CallsPerMinute),
+		ToolCallsMax:        database.Int64ToNullInt64(fwc.toolLimit.MaxCalls),
+		ResponseCache:       database.StringToNullString(fwc.responseCache.String()),
+		ProviderTimeout:     database.Int32ToNullInt32(fwc.providerTimeout),
+		SystemPrompt:        database.PtrStringToNullString(fwc.systemPrompt),
+		PlanMaxSubtasks:     database.Int32ToNullInt32(fwc.planLimit.MaxSubtasks),
+		PlanMaxDepth:        database.Int32ToNullInt32(fwc.planLimit.MaxDepth),
+		CommandPolicy:       commandPolicyBlob,
+		Scope:               scopeBlob,
+		ResultMaxSize:       database.Int32ToNullInt32(fwc.resultLimit.MaxSize),
+		ResultMaxSourceSize: database.Int32ToNullInt32(fwc.resultLimit.MaxSourceSize),
 	})
 	if err != nil {
 		logrus.WithError(err).Error("failed to create flow in DB")
@@ -137,6 +438,20 @@ func NewFlowWorker(
 		"provider_type": fwc.prvtype.String(),
 	})
 	logger.Info("flow created in DB")
+	span.SetAttributes(attribute.Int64("flow.id", flow.ID), attribute.Int64("user.id", fwc.userID))
+
+	for name, value := range fwc.env.Vars {
+		if err := storeFlowEnvVar(ctx, fwc.db, fwc.cfg, flow.ID, name, value, false); err != nil {
+			logger.WithError(err).Error("failed to store flow env")
+			return nil, err
+		}
+	}
+	for name, value := range fwc.env.Secrets {
+		if err := storeFlowEnvVar(ctx, fwc.db, fwc.cfg, flow.ID, name, value, true); err != nil {
+			logger.WithError(err).Error("failed to store flow secret")
+			return nil, err
+		}
+	}
 
 	user, err := fwc.db.GetUser(ctx, fwc.userID)
 	if err != nil {
@@ -144,7 +459,9 @@ func NewFlowWorker(
 		return nil, fmt.Errorf("failed to get user %d: %w", fwc.userID, err)
 	}
 
+	// langfuse trace shares ID with the flow root span to correlate them, it's generated if tracing is disabled
 	ctx, observation := obs.Observer.NewObservation(ctx,
+		langfuse.WithObservationTraceID(obs.TraceIDFromContext(ctx)),
 		langfuse.WithObservationTraceContext(
 			langfuse.WithTraceName(fmt.Sprintf("%d flow worker", flow.ID)),
 			langfuse.WithTraceUserID(user.Mail),
@@ -171,8 +488,17 @@ func NewFlowWorker(
 	if err != nil {
 		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to create flow tools executor", err)
 	}
+	executor.SetContainerLimits(fwc.limits)
+	executor.SetToolCallLimits(fwc.toolLimit.resolve(fwc.cfg))
+	executor.SetResultSizeLimits(fwc.resultLimit.resolve(fwc.cfg))
+	if err := executor.SetCommandPolicy(fwc.commandPolicy); err != nil {
+		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to set command policy", err)
+	}
+	if err := executor.SetFlowScope(fwc.scope); err != nil {
+		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to set flow scope", err)
+	}
 	flowProvider, err := fwc.provs.NewFlowProvider(
-		ctx, fwc.prvname, prompter, executor, flow.ID, fwc.userID, fwc.cfg.AskUser, fwc.input,
+		ctx, fwc.prvname, fwc.fallbacks, prompter, executor, flow.ID, fwc.userID, fwc.cfg.AskUser, fwc.input,
 	)
 	if err != nil {
 		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to get flow provider", err)
@@ -204,13 +530,19 @@ func NewFlowWorker(
 
 	flowProvider.SetAgentLogProvider(workers.alw)
 	flowProvider.SetMsgLogProvider(workers.mlw)
+	flowProvider.SetProviderSwitchHandler(newProviderSwitchHandler(fwc.db, pub, flow.ID))
+
+	// flow started from the container snapshot uses its image instead of the chosen one
+	if fwc.image != "" {
+		flowProvider.SetImage(fwc.image)
+	}
 
 	executor.SetImage(flowProvider.Image())
 	executor.SetEmbedder(flowProvider.Embedder())
 	executor.SetScreenshotProvider(workers.sw)
 	executor.SetAgentLogProvider(workers.alw)
 	executor.SetMsgLogProvider(workers.mlw)
-	executor.SetSearchLogProvider(workers.slw)
+	executor.SetSearchLogProvider(workers.slp)
 	executor.SetTermLogProvider(workers.tlw)
 	executor.SetVectorStoreLogProvider(workers.vslw)
 	executor.SetGraphitiClient(fwc.provs.GraphitiClient())
@@ -225,21 +557,26 @@ func NewFlowWorker(
 		MsgLog:     workers.mlw,
 		TermLog:    workers.tlw,
 		Screenshot: workers.sw,
+		PlanLimits: flowPlanLimitsFromDB(flow).resolve(fwc.cfg),
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(obs.ContextWithSpanContext(context.Background(), ctx))
 	ctx, _ = obs.Observer.NewObservation(ctx, langfuse.WithObservationTraceID(observation.TraceID()))
 	fw := &flowWorker{
-		tc:      NewTaskController(flowCtx),
-		wg:      &sync.WaitGroup{},
-		aws:     make(map[int64]AssistantWorker),
-		awsMX:   &sync.Mutex{},
-		ctx:     ctx,
-		cancel:  cancel,
-		taskMX:  &sync.Mutex{},
-		taskST:  func() {},
-		taskWG:  &sync.WaitGroup{},
-		input:   make(chan flowInput),
-		flowCtx: flowCtx,
+		tc:       NewTaskController(flowCtx),
+		wg:       &sync.WaitGroup{},
+		aws:      make(map[int64]AssistantWorker),
+		awsMX:    &sync.Mutex{},
+		ctx:      ctx,
+		cancel:   cancel,
+		taskMX:   &sync.Mutex{},
+		taskST:   func() {},
+		taskWG:   &sync.WaitGroup{},
+		inputMX:  &sync.Mutex{},
+		inputCh:  make(chan struct{}, 1),
+		flowCtx:  flowCtx,
+		budget:   fwc.budget,
+		budgetMX: &sync.Mutex{},
+		errorMX:  &sync.Mutex{},
 		logger: logrus.WithFields(logrus.Fields{
 			"flow_id":   flow.ID,
 			"user_id":   fwc.userID,
@@ -247,6 +584,10 @@ func NewFlowWorker(
 			"component": "worker",
 		}),
 	}
+	flowProvider.SetUsageHandler(fw.checkBudget)
+	flowProvider.SetAskUserHandler(fw.setPendingQuestion)
+	flowProvider.SetProviderErrorHandler(fw.trackProviderError)
+	flowProvider.SetStreamHandler(NewFlowMsgStreamWorker(flow.ID, pub).StreamMsg)
 
 	if err := executor.Prepare(ctx); err != nil {
 		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to prepare flow resources", err)
@@ -263,7 +604,7 @@ func NewFlowWorker(
 	go fw.worker()
 
 	if !fwc.dryRun {
-		if err := fw.PutInput(ctx, fwc.input); err != nil {
+		if _, err := fw.PutInput(ctx, fwc.input); err != nil {
 			return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to run flow worker", err)
 		}
 	}
@@ -273,12 +614,43 @@ func NewFlowWorker(
 	return fw, nil
 }
 
+// storeFlowEnvVar stores the flow env variable, the error never contains the value because it may be a secret
+func storeFlowEnvVar(
+	ctx context.Context,
+	db database.Querier,
+	cfg *config.Config,
+	flowID int64,
+	name, value string,
+	secret bool,
+) error {
+	if secret {
+		encrypted, err := tools.EncryptFlowSecret(cfg, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt flow %d secret '%s': %w", flowID, name, err)
+		}
+		value = encrypted
+	}
+
+	_, err := db.UpsertFlowEnv(ctx, database.UpsertFlowEnvParams{
+		FlowID: flowID,
+		Name:   name,
+		Value:  value,
+		Secret: secret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store flow %d env '%s': %w", flowID, name, err)
+	}
+
+	return nil
+}
+
 func LoadFlowWorker(ctx context.Context, flow database.Flow, fwc flowWorkerCtx) (FlowWorker, error) {
-	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.LoadFlowWorker")
+	ctx, span := obs.Observer.NewSpanWithParent(ctx, obs.SpanKindInternal, "controller.LoadFlowWorker", flow.TraceID.String, "")
 	defer span.End()
+	span.SetAttributes(attribute.Int64("flow.id", flow.ID), attribute.Int64("user.id", flow.UserID))
 
 	switch flow.Status {
-	case database.FlowStatusRunning, database.FlowStatusWaiting:
+	case database.FlowStatusRunning, database.FlowStatusWaiting, database.FlowStatusCreated:
 	default:
 		return nil, fmt.Errorf("flow %d has status %s: loading aborted: %w", flow.ID, flow.Status, ErrNothingToLoad)
 	}
@@ -336,8 +708,25 @@ func LoadFlowWorker(ctx context.Context, flow database.Flow, fwc flowWorkerCtx)
 	if err != nil {
 		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to create flow tools executor", err)
 	}
+	executor.SetToolCallLimits(flowToolLimitsFromDB(flow).resolve(fwc.cfg))
+	executor.SetResultSizeLimits(flowResultLimitsFromDB(flow).resolve(fwc.cfg))
+	commandPolicy, err := flowCommandPolicyFromDB(flow)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to get flow command policy", err)
+	}
+	if err := executor.SetCommandPolicy(commandPolicy); err != nil {
+		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to set command policy", err)
+	}
+	scope, err := flowScopeFromDB(flow)
+	if err != nil {
+		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to get flow scope", err)
+	}
+	if err := executor.SetFlowScope(scope); err != nil {
+		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to set flow scope", err)
+	}
+	fallbacks := remainingFallbackProviders(flow)
 	flowProvider, err := fwc.provs.LoadFlowProvider(
-		ctx, provider.ProviderName(flow.ModelProviderName),
+		ctx, provider.ProviderName(flow.ModelProviderName), fallbacks,
 		prompter, executor, flow.ID, flow.UserID, fwc.cfg.AskUser,
 		container.Image, flow.Language, flow.Title, flow.ToolCallIDTemplate,
 	)
@@ -353,13 +742,14 @@ func LoadFlowWorker(ctx context.Context, flow database.Flow, fwc flowWorkerCtx)
 
 	flowProvider.SetAgentLogProvider(workers.alw)
 	flowProvider.SetMsgLogProvider(workers.mlw)
+	flowProvider.SetProviderSwitchHandler(newProviderSwitchHandler(fwc.db, pub, flow.ID))
 
 	executor.SetImage(flowProvider.Image())
 	executor.SetEmbedder(flowProvider.Embedder())
 	executor.SetScreenshotProvider(workers.sw)
 	executor.SetAgentLogProvider(workers.alw)
 	executor.SetMsgLogProvider(workers.mlw)
-	executor.SetSearchLogProvider(workers.slw)
+	executor.SetSearchLogProvider(workers.slp)
 	executor.SetTermLogProvider(workers.tlw)
 	executor.SetVectorStoreLogProvider(workers.vslw)
 	executor.SetGraphitiClient(fwc.provs.GraphitiClient())
@@ -374,21 +764,27 @@ func LoadFlowWorker(ctx context.Context, flow database.Flow, fwc flowWorkerCtx)
 		MsgLog:     workers.mlw,
 		TermLog:    workers.tlw,
 		Screenshot: workers.sw,
+		PlanLimits: flowPlanLimitsFromDB(flow).resolve(fwc.cfg),
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(obs.ContextWithSpanContext(context.Background(), ctx))
 	ctx, _ = obs.Observer.NewObservation(ctx, langfuse.WithObservationTraceID(observation.TraceID()))
 	fw := &flowWorker{
-		tc:      NewTaskController(flowCtx),
-		wg:      &sync.WaitGroup{},
-		aws:     make(map[int64]AssistantWorker),
-		awsMX:   &sync.Mutex{},
-		ctx:     ctx,
-		cancel:  cancel,
-		taskMX:  &sync.Mutex{},
-		taskST:  func() {},
-		taskWG:  &sync.WaitGroup{},
-		input:   make(chan flowInput),
-		flowCtx: flowCtx,
+		tc:       NewTaskController(flowCtx),
+		wg:       &sync.WaitGroup{},
+		aws:      make(map[int64]AssistantWorker),
+		awsMX:    &sync.Mutex{},
+		ctx:      ctx,
+		cancel:   cancel,
+		taskMX:   &sync.Mutex{},
+		taskST:   func() {},
+		taskWG:   &sync.WaitGroup{},
+		inputMX:  &sync.Mutex{},
+		inputCh:  make(chan struct{}, 1),
+		flowCtx:  flowCtx,
+		budget:   flowBudgetFromDB(flow),
+		budgetMX: &sync.Mutex{},
+		errorMX:  &sync.Mutex{},
+		failing:  flow.LastError.Valid,
 		logger: logrus.WithFields(logrus.Fields{
 			"flow_id":   flow.ID,
 			"user_id":   flow.UserID,
@@ -396,6 +792,10 @@ func LoadFlowWorker(ctx context.Context, flow database.Flow, fwc flowWorkerCtx)
 			"component": "worker",
 		}),
 	}
+	flowProvider.SetUsageHandler(fw.checkBudget)
+	flowProvider.SetAskUserHandler(fw.setPendingQuestion)
+	flowProvider.SetProviderErrorHandler(fw.trackProviderError)
+	flowProvider.SetStreamHandler(NewFlowMsgStreamWorker(flow.ID, pub).StreamMsg)
 
 	if err := executor.Prepare(ctx); err != nil {
 		return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to prepare flow resources", err)
@@ -433,6 +833,18 @@ func LoadFlowWorker(ctx context.Context, flow database.Flow, fwc flowWorkerCtx)
 		}
 	}
 
+	// queue of the previous run is lost so its inputs are never consumed,
+	// except the input of the created flow which is put again by the flows queue
+	if flow.Status != database.FlowStatusCreated {
+		err = fwc.db.CancelFlowQueuedInputs(ctx, database.CancelFlowQueuedInputsParams{
+			Error:  database.StringToNullString(inputCanceledRestart),
+			FlowID: flow.ID,
+		})
+		if err != nil {
+			return nil, wrapErrorEndSpan(ctx, flowSpan, "failed to cancel queued flow inputs", err)
+		}
+	}
+
 	fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
 
 	fw.wg.Add(1)
@@ -483,6 +895,17 @@ func (fw *flowWorker) SetStatus(ctx context.Context, status database.FlowStatus)
 		return fmt.Errorf("failed to set flow %d status: %w", fw.flowCtx.FlowID, err)
 	}
 
+	// the flow works again so the reason of the previous finish is stale
+	if status == database.FlowStatusRunning && flow.FinishReason.Valid {
+		flow, err = fw.flowCtx.DB.UpdateFlowFinishReason(ctx, database.UpdateFlowFinishReasonParams{
+			FinishReason: database.NullFlowFinishReason{},
+			ID:           fw.flowCtx.FlowID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clear flow %d finish reason: %w", fw.flowCtx.FlowID, err)
+		}
+	}
+
 	containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID)
 	if err != nil {
 		return fmt.Errorf("failed to get flow %d containers: %w", fw.flowCtx.FlowID, err)
@@ -565,32 +988,158 @@ func (fw *flowWorker) ListTasks(ctx context.Context) []TaskWorker {
 	return fw.tc.ListTasks(ctx)
 }
 
-func (fw *flowWorker) PutInput(ctx context.Context, input string) error {
+// PutInput stores the input in the flow inputs history and queues it to the worker, inputs are consumed
+// one by one in the order of arrival, it returns the input id even if the flow is busy and the input waits
+func (fw *flowWorker) PutInput(ctx context.Context, input string) (int64, error) {
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.PutInput")
 	defer span.End()
 
-	flin := flowInput{input: input, done: make(chan error, 1)}
+	// the lock keeps the ids of the history in the same order as the queue
+	fw.inputMX.Lock()
+	if err := fw.ctx.Err(); err != nil {
+		fw.inputMX.Unlock()
+		return 0, fmt.Errorf("flow %d stopped: %w", fw.flowCtx.FlowID, err)
+	}
+
+	record, err := fw.flowCtx.DB.CreateFlowInput(ctx, database.CreateFlowInputParams{
+		Input:  input,
+		FlowID: fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		fw.inputMX.Unlock()
+		return 0, fmt.Errorf("failed to store flow %d input: %w", fw.flowCtx.FlowID, err)
+	}
+
+	flin := flowInput{id: record.ID, input: input, done: make(chan error, 1)}
+	fw.inputs = append(fw.inputs, flin)
+	fw.inputMX.Unlock()
+
+	return record.ID, fw.waitInput(ctx, flin)
+}
+
+// PutQueuedInput queues the input which is already stored in the inputs history, it's used by the flows queue
+// to start the flow which was created over the running flows limit
+func (fw *flowWorker) PutQueuedInput(ctx context.Context, inputID int64, input string) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.PutQueuedInput")
+	defer span.End()
+
+	fw.inputMX.Lock()
+	if err := fw.ctx.Err(); err != nil {
+		fw.inputMX.Unlock()
+		return fmt.Errorf("flow %d stopped: %w", fw.flowCtx.FlowID, err)
+	}
+
+	flin := flowInput{id: inputID, input: input, done: make(chan error, 1)}
+	fw.inputs = append(fw.inputs, flin)
+	fw.inputMX.Unlock()
+
+	return fw.waitInput(ctx, flin)
+}
+
+// Resume queues the continuation of the task which was paused by the server shutdown, it's processed
+// in order with the inputs but nothing is put to the agent chain and the inputs history
+func (fw *flowWorker) Resume(ctx context.Context) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.Resume")
+	defer span.End()
+
+	fw.inputMX.Lock()
+	if err := fw.ctx.Err(); err != nil {
+		fw.inputMX.Unlock()
+		return fmt.Errorf("flow %d stopped: %w", fw.flowCtx.FlowID, err)
+	}
+
+	flin := flowInput{resume: true, done: make(chan error, 1)}
+	fw.inputs = append(fw.inputs, flin)
+	fw.inputMX.Unlock()
+
+	return fw.waitInput(ctx, flin)
+}
+
+// waitInput notifies the worker about the queued input and waits for the early error of its delivery
+func (fw *flowWorker) waitInput(ctx context.Context, flin flowInput) error {
+	if err := fw.clearPendingQuestion(ctx); err != nil {
+		fw.logger.WithError(err).Error("failed to clear flow pending question")
+	}
+
+	select {
+	case fw.inputCh <- struct{}{}:
+	default: // worker is already notified
+	}
+
+	timer := time.NewTimer(flowInputTimeout)
+	defer timer.Stop()
+
 	select {
+	case err := <-flin.done:
+		return err // nil or error
+	case <-timer.C:
+		return nil // no early error, input is waiting in the queue
 	case <-fw.ctx.Done():
-		close(flin.done)
 		return fmt.Errorf("flow %d stopped: %w", fw.flowCtx.FlowID, fw.ctx.Err())
 	case <-ctx.Done():
-		close(flin.done)
-		return fmt.Errorf("flow %d input processing timeout: %w", fw.flowCtx.FlowID, ctx.Err())
-	case fw.input <- flin:
-		timer := time.NewTimer(flowInputTimeout)
-		defer timer.Stop()
+		return nil // input stays in the queue after the caller is gone
+	}
+}
 
-		select {
-		case err := <-flin.done:
-			return err // nil or error
-		case <-timer.C:
-			return nil // no early error
-		case <-fw.ctx.Done():
-			return fmt.Errorf("flow %d stopped: %w", fw.flowCtx.FlowID, fw.ctx.Err())
-		case <-ctx.Done():
-			return fmt.Errorf("flow %d input processing timeout: %w", fw.flowCtx.FlowID, ctx.Err())
-		}
+// nextInput pops the oldest input from the queue
+func (fw *flowWorker) nextInput() (flowInput, bool) {
+	fw.inputMX.Lock()
+	defer fw.inputMX.Unlock()
+
+	if len(fw.inputs) == 0 || fw.ctx.Err() != nil {
+		return flowInput{}, false
+	}
+
+	flin := fw.inputs[0]
+	fw.inputs = fw.inputs[1:]
+
+	return flin, true
+}
+
+// ackInput reports the result of the input delivery to the sender and stores it in the inputs history
+func (fw *flowWorker) ackInput(flin flowInput, err error) {
+	flin.done <- err
+	if flin.resume {
+		return
+	}
+
+	params := database.UpdateFlowInputStatusParams{
+		Status: database.FlowInputStatusConsumed,
+		ID:     flin.id,
+	}
+	if err != nil {
+		params.Status = database.FlowInputStatusFailed
+		params.Error = database.StringToNullString(err.Error())
+	}
+
+	if _, err := fw.flowCtx.DB.UpdateFlowInputStatus(context.WithoutCancel(fw.ctx), params); err != nil {
+		fw.logger.WithError(err).WithField("input_id", flin.id).Error("failed to update flow input status")
+	}
+}
+
+// cancelInputs drops the queued inputs which weren't consumed yet and marks them as canceled in the history
+func (fw *flowWorker) cancelInputs(ctx context.Context, reason string) {
+	fw.inputMX.Lock()
+	inputs := fw.inputs
+	fw.inputs = nil
+	keep := fw.keepIn
+	fw.inputMX.Unlock()
+
+	for _, flin := range inputs {
+		flin.done <- errors.New(reason)
+	}
+
+	// input of the queued flow is put again by the flows queue after the restart
+	if keep {
+		return
+	}
+
+	err := fw.flowCtx.DB.CancelFlowQueuedInputs(ctx, database.CancelFlowQueuedInputsParams{
+		Error:  database.StringToNullString(reason),
+		FlowID: fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		fw.logger.WithError(err).Error("failed to cancel queued flow inputs")
 	}
 }
 
@@ -598,6 +1147,20 @@ func (fw *flowWorker) Finish(ctx context.Context) error {
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.Finish")
 	defer span.End()
 
+	return fw.finishFlow(ctx, fw.flowCtx.Executor.Release)
+}
+
+// Archive finishes the flow like Finish but keeps its containers, so the deleted flow can be restored
+// with them until it's purged
+func (fw *flowWorker) Archive(ctx context.Context) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.Archive")
+	defer span.End()
+
+	return fw.finishFlow(ctx, fw.flowCtx.Executor.Detach)
+}
+
+// finishFlow stops the flow worker, its tasks and assistants, then releases the executor by the given function
+func (fw *flowWorker) finishFlow(ctx context.Context, release func(ctx context.Context) error) error {
 	if err := fw.finish(); err != nil {
 		return err
 	}
@@ -619,10 +1182,21 @@ func (fw *flowWorker) Finish(ctx context.Context) error {
 		}
 	}
 
-	if err := fw.flowCtx.Executor.Release(ctx); err != nil {
+	if err := release(ctx); err != nil {
 		return fmt.Errorf("failed to release flow %d resources: %w", fw.flowCtx.FlowID, err)
 	}
 
+	// the reason is published with the finished status below
+	if _, err := fw.flowCtx.DB.UpdateFlowFinishReason(ctx, database.UpdateFlowFinishReasonParams{
+		FinishReason: database.NullFlowFinishReason{
+			FlowFinishReason: database.FlowFinishReasonUserFinish,
+			Valid:            true,
+		},
+		ID: fw.flowCtx.FlowID,
+	}); err != nil {
+		return fmt.Errorf("failed to set flow %d finish reason: %w", fw.flowCtx.FlowID, err)
+	}
+
 	if err := fw.SetStatus(ctx, database.FlowStatusFinished); err != nil {
 		return fmt.Errorf("failed to set flow %d status: %w", fw.flowCtx.FlowID, err)
 	}
@@ -634,13 +1208,74 @@ func (fw *flowWorker) Stop(ctx context.Context) error {
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.Stop")
 	defer span.End()
 
+	return fw.stop(ctx, database.FlowFinishReasonUserStop)
+}
+
+// stop cancels the running task and records the reason why the flow stopped working
+func (fw *flowWorker) stop(ctx context.Context, reason database.FlowFinishReason) error {
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stopTaskTimeout)
+	defer cancel()
+
+	// inputs sent before the stop shouldn't start the flow again
+	fw.cancelInputs(stopCtx, inputCanceledStop)
+
+	if err := fw.stopTask(stopCtx); err != nil {
+		return fmt.Errorf("task stop timeout")
+	}
+
+	return fw.setFinishReason(stopCtx, reason)
+}
+
+// Pause stops the running task and the flow worker to shut down the server, the interrupted subtask
+// gets waiting status with consistent message chain so the flow is resumable after the restart,
+// if the task isn't stopped before the context is done the flow keeps running status
+func (fw *flowWorker) Pause(ctx context.Context) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.Pause")
+	defer span.End()
+
+	status, err := fw.GetStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d status: %w", fw.flowCtx.FlowID, err)
+	}
+
+	// queued flow waits for a free slot again after the restart so its input is kept
+	if status == database.FlowStatusCreated {
+		fw.inputMX.Lock()
+		fw.keepIn = true
+		fw.inputMX.Unlock()
+	}
+
+	// waiting flow has no running task and it's loaded as is after the restart
+	if status != database.FlowStatusRunning {
+		return fw.finish()
+	}
+
+	reason := shutdownPausedReason
+	stopErr := fw.stopTask(ctx)
+	if stopErr != nil {
+		reason = shutdownInterruptedReason
+	}
+
+	fw.logger.WithField("stop_reason", reason).Info("flow paused by server shutdown")
+
+	// grace period may be already over but the reason has to be stored anyway
+	if err := fw.setStopReason(context.WithoutCancel(ctx), reason); err != nil {
+		stopErr = errors.Join(stopErr, err)
+	}
+	if stopErr != nil {
+		return stopErr
+	}
+
+	return fw.finish()
+}
+
+// stopTask cancels the running task and waits for it until the context is done
+func (fw *flowWorker) stopTask(ctx context.Context) error {
 	fw.taskMX.Lock()
 	defer fw.taskMX.Unlock()
 
 	fw.taskST()
 	done := make(chan struct{})
-	timer := time.NewTimer(stopTaskTimeout)
-	defer timer.Stop()
 
 	go func() {
 		fw.taskWG.Wait()
@@ -648,13 +1283,47 @@ func (fw *flowWorker) Stop(ctx context.Context) error {
 	}()
 
 	select {
-	case <-timer.C:
-		return fmt.Errorf("task stop timeout")
+	case <-ctx.Done():
+		return ctx.Err()
 	case <-done:
 		return nil
 	}
 }
 
+func (fw *flowWorker) setStopReason(ctx context.Context, reason string) error {
+	flow, err := fw.flowCtx.DB.UpdateFlowStopReason(ctx, database.UpdateFlowStopReasonParams{
+		StopReason: database.StringToNullString(reason),
+		ID:         fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set flow %d stop reason: %w", fw.flowCtx.FlowID, err)
+	}
+
+	if containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID); err == nil {
+		fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
+	}
+
+	return nil
+}
+
+// setFinishReason records why the flow stopped working and notifies the subscribers,
+// the reason is cleared when the flow starts running again
+func (fw *flowWorker) setFinishReason(ctx context.Context, reason database.FlowFinishReason) error {
+	flow, err := fw.flowCtx.DB.UpdateFlowFinishReason(ctx, database.UpdateFlowFinishReasonParams{
+		FinishReason: database.NullFlowFinishReason{FlowFinishReason: reason, Valid: true},
+		ID:           fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set flow %d finish reason: %w", fw.flowCtx.FlowID, err)
+	}
+
+	if containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID); err == nil {
+		fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
+	}
+
+	return nil
+}
+
 func (fw *flowWorker) Rename(ctx context.Context, title string) error {
 	fw.flowCtx.Provider.SetTitle(title)
 
@@ -676,6 +1345,271 @@ func (fw *flowWorker) Rename(ctx context.Context, title string) error {
 	return nil
 }
 
+// UpdateMetadata sets the given fields of the flow metadata, nil fields are kept as is by the database
+func (fw *flowWorker) UpdateMetadata(ctx context.Context, metadata FlowMetadata) error {
+	flow, err := fw.flowCtx.DB.UpdateFlowMetadata(ctx, database.UpdateFlowMetadataParams{
+		Title:       database.PtrStringToNullString(metadata.Title),
+		Language:    database.PtrStringToNullString(metadata.Language),
+		Description: database.PtrStringToNullString(metadata.Description),
+		ID:          fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update flow %d metadata: %w", fw.flowCtx.FlowID, err)
+	}
+
+	fw.flowCtx.Provider.SetTitle(flow.Title)
+	fw.flowCtx.Provider.SetLanguage(flow.Language)
+
+	containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d containers: %w", fw.flowCtx.FlowID, err)
+	}
+
+	fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
+
+	return nil
+}
+
+func (fw *flowWorker) GetBudget() FlowBudget {
+	fw.budgetMX.Lock()
+	defer fw.budgetMX.Unlock()
+
+	return fw.budget
+}
+
+// SetBudget replaces the flow budget and resumes the flow if it was stopped by exceeded budget,
+// the new budget has to be above the current flow usage
+func (fw *flowWorker) SetBudget(ctx context.Context, budget FlowBudget) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.SetBudget")
+	defer span.End()
+
+	usage, err := fw.flowCtx.DB.GetFlowUsageStats(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d usage: %w", fw.flowCtx.FlowID, err)
+	}
+
+	if reason := budget.exceededBy(usage); reason != "" {
+		return fmt.Errorf("%s: %w", reason, ErrFlowBudgetExceeded)
+	}
+
+	flow, err := fw.flowCtx.DB.GetFlow(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d: %w", fw.flowCtx.FlowID, err)
+	}
+	stopped := flow.StopReason.Valid
+
+	fw.budgetMX.Lock()
+	flow, err = fw.flowCtx.DB.UpdateFlowBudget(ctx, database.UpdateFlowBudgetParams{
+		BudgetMaxCost:   database.Float64ToNullFloat64(budget.MaxCost),
+		BudgetMaxTokens: database.Int64ToNullInt64(budget.MaxTokens),
+		ID:              fw.flowCtx.FlowID,
+	})
+	if err == nil {
+		fw.budget = budget
+	}
+	fw.budgetMX.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update flow %d budget: %w", fw.flowCtx.FlowID, err)
+	}
+
+	containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d containers: %w", fw.flowCtx.FlowID, err)
+	}
+
+	fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
+
+	if !stopped || flow.Status != database.FlowStatusWaiting {
+		return nil
+	}
+
+	for _, task := range fw.tc.ListTasks(ctx) {
+		if !task.IsCompleted() && task.IsWaiting() {
+			fw.logger.WithField("task_id", task.GetTaskID()).Info("flow resumed after budget raise")
+			if _, err := fw.PutInput(ctx, budgetRaisedInput); err != nil {
+				return fmt.Errorf("failed to resume flow %d after budget raise: %w", fw.flowCtx.FlowID, err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// checkBudget records the stop reason and stops the flow if its usage exceeds the budget,
+// it's called after each agent turn
+func (fw *flowWorker) checkBudget(ctx context.Context) error {
+	fw.budgetMX.Lock()
+	defer fw.budgetMX.Unlock()
+
+	if fw.stopping || !fw.budget.IsLimited() {
+		return nil
+	}
+
+	usage, err := fw.flowCtx.DB.GetFlowUsageStats(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d usage: %w", fw.flowCtx.FlowID, err)
+	}
+
+	reason := fw.budget.exceededBy(usage)
+	if reason == "" {
+		return nil
+	}
+
+	fw.logger.WithField("stop_reason", reason).Warn("flow budget exceeded, stopping the flow")
+
+	if err := fw.setStopReason(ctx, reason); err != nil {
+		return err
+	}
+
+	// Stop waits for the running task which performs this turn, so it has to be called asynchronously
+	fw.stopping = true
+	go func() {
+		if err := fw.stop(fw.ctx, database.FlowFinishReasonBudgetExceeded); err != nil {
+			fw.logger.WithError(err).Error("failed to stop flow after budget exceeded")
+		}
+
+		fw.budgetMX.Lock()
+		fw.stopping = false
+		fw.budgetMX.Unlock()
+	}()
+
+	return nil
+}
+
+// setPendingQuestion marks the flow as awaiting the user input, the question is published
+// with the flow update when the flow switches to the waiting status
+func (fw *flowWorker) setPendingQuestion(ctx context.Context, question string) error {
+	_, err := fw.flowCtx.DB.UpdateFlowPendingQuestion(ctx, database.UpdateFlowPendingQuestionParams{
+		AwaitingInput:   true,
+		PendingQuestion: database.StringToNullString(question),
+		ID:              fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set flow %d pending question: %w", fw.flowCtx.FlowID, err)
+	}
+
+	return nil
+}
+
+// clearPendingQuestion resets the awaiting input flag after the user answered and notifies subscribers
+func (fw *flowWorker) clearPendingQuestion(ctx context.Context) error {
+	flow, err := fw.flowCtx.DB.GetFlow(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d: %w", fw.flowCtx.FlowID, err)
+	}
+	if !flow.AwaitingInput {
+		return nil
+	}
+
+	flow, err = fw.flowCtx.DB.UpdateFlowPendingQuestion(ctx, database.UpdateFlowPendingQuestionParams{
+		AwaitingInput: false,
+		ID:            fw.flowCtx.FlowID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear flow %d pending question: %w", fw.flowCtx.FlowID, err)
+	}
+
+	containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d containers: %w", fw.flowCtx.FlowID, err)
+	}
+
+	fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
+
+	return nil
+}
+
+// trackProviderError stores the last provider error of the flow, the repeated error increments the counter
+// instead of a new record, the next successful call clears it, every change is published with FlowUpdated
+func (fw *flowWorker) trackProviderError(ctx context.Context, callErr error) error {
+	fw.errorMX.Lock()
+	defer fw.errorMX.Unlock()
+
+	var (
+		flow database.Flow
+		err  error
+	)
+	switch {
+	case callErr != nil:
+		msg := callErr.Error()
+		if len(msg) > flowLastErrorMaxLength {
+			msg = strings.ToValidUTF8(msg[:flowLastErrorMaxLength], "")
+		}
+		flow, err = fw.flowCtx.DB.UpdateFlowLastError(ctx, database.UpdateFlowLastErrorParams{
+			LastError: database.StringToNullString(msg),
+			ID:        fw.flowCtx.FlowID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set flow %d last error: %w", fw.flowCtx.FlowID, err)
+		}
+		fw.failing = true
+	case fw.failing:
+		flow, err = fw.flowCtx.DB.ResetFlowLastError(ctx, fw.flowCtx.FlowID)
+		if err != nil {
+			return fmt.Errorf("failed to reset flow %d last error: %w", fw.flowCtx.FlowID, err)
+		}
+		fw.failing = false
+	default:
+		return nil
+	}
+
+	containers, err := fw.flowCtx.DB.GetFlowContainers(ctx, fw.flowCtx.FlowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d containers: %w", fw.flowCtx.FlowID, err)
+	}
+
+	fw.flowCtx.Publisher.FlowUpdated(ctx, flow, containers)
+
+	return nil
+}
+
+// CancelSubtask abandons the single subtask of the flow task and marks it as failed
+// without stopping the flow, the task continues with the next subtasks
+func (fw *flowWorker) CancelSubtask(ctx context.Context, taskID, subtaskID int64) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.flowWorker.CancelSubtask")
+	defer span.End()
+
+	task, err := fw.tc.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	resume, err := task.CancelSubtask(ctx, subtaskID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel subtask %d of task %d: %w", subtaskID, taskID, err)
+	}
+
+	fw.logger.WithFields(logrus.Fields{
+		"task_id":    taskID,
+		"subtask_id": subtaskID,
+		"resume":     resume,
+	}).Info("subtask canceled by user")
+
+	if resume {
+		if _, err := fw.PutInput(ctx, subtaskCanceledInput); err != nil {
+			return fmt.Errorf("failed to resume task %d after subtask cancellation: %w", taskID, err)
+		}
+	}
+
+	return nil
+}
+
+// SetSubtaskPriority changes the order of the planned subtask of the flow task which is respected
+// when the task picks the next subtask to run, nil priority moves the subtask to run next
+func (fw *flowWorker) SetSubtaskPriority(ctx context.Context, taskID, subtaskID int64, priority *int32) error {
+	task, err := fw.tc.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	if err := task.SetSubtaskPriority(ctx, subtaskID, priority); err != nil {
+		return fmt.Errorf("failed to set priority of subtask %d of task %d: %w", subtaskID, taskID, err)
+	}
+
+	return nil
+}
+
 func (fw *flowWorker) finish() error {
 	if err := fw.ctx.Err(); err != nil {
 		if errors.Is(err, context.Canceled) {
@@ -685,7 +1619,6 @@ func (fw *flowWorker) finish() error {
 	}
 
 	fw.cancel()
-	close(fw.input)
 	fw.wg.Wait()
 
 	return nil
@@ -693,6 +1626,7 @@ func (fw *flowWorker) finish() error {
 
 func (fw *flowWorker) worker() {
 	defer fw.wg.Done()
+	defer fw.cancelInputs(context.WithoutCancel(fw.ctx), inputCanceledStop)
 
 	_, observation := obs.Observer.NewObservation(fw.ctx)
 
@@ -731,20 +1665,28 @@ func (fw *flowWorker) worker() {
 		}
 	}
 
-	// process user input in regular job
-	for flin := range fw.input {
-		if task, err := fw.processInput(flin); err != nil {
-			if errors.Is(err, context.Canceled) {
-				getLogger(flin.input, task).Info("flow are going to be stopped by user")
-				return
-			} else {
-				getLogger(flin.input, task).WithError(err).Error("failed to process input")
+	// process queued user inputs in regular job
+	for {
+		select {
+		case <-fw.ctx.Done():
+			return
+		case <-fw.inputCh:
+		}
+
+		for flin, ok := fw.nextInput(); ok; flin, ok = fw.nextInput() {
+			if task, err := fw.processInput(flin); err != nil {
+				if errors.Is(err, context.Canceled) {
+					getLogger(flin.input, task).Info("flow are going to be stopped by user")
+					return
+				} else {
+					getLogger(flin.input, task).WithError(err).Error("failed to process input")
 
-				// anyway there need to set flow status to Waiting new user input even an error happened
-				_ = fw.SetStatus(fw.ctx, database.FlowStatusWaiting)
+					// anyway there need to set flow status to Waiting new user input even an error happened
+					_ = fw.SetStatus(fw.ctx, database.FlowStatusWaiting)
+				}
+			} else {
+				getLogger(flin.input, task).Info("user input processed")
 			}
-		} else {
-			getLogger(flin.input, task).Info("user input processed")
 		}
 	}
 }
@@ -752,26 +1694,43 @@ func (fw *flowWorker) worker() {
 func (fw *flowWorker) processInput(flin flowInput) (TaskWorker, error) {
 	for _, task := range fw.tc.ListTasks(fw.ctx) {
 		if !task.IsCompleted() && task.IsWaiting() {
+			if flin.resume {
+				if err := task.Resume(fw.ctx); err != nil {
+					err = fmt.Errorf("failed to resume task %d: %w", task.GetTaskID(), err)
+					fw.ackInput(flin, err)
+					return nil, err
+				}
+				fw.ackInput(flin, nil)
+				return task, fw.runTask("resume task after server restart", flin.input, task)
+			}
+
 			if err := task.PutInput(fw.ctx, flin.input); err != nil {
 				err = fmt.Errorf("failed to process input to task %d: %w", task.GetTaskID(), err)
-				flin.done <- err
+				fw.ackInput(flin, err)
 				return nil, err
 			} else {
-				flin.done <- nil
+				fw.ackInput(flin, nil)
 				return task, fw.runTask("put input to task and run", flin.input, task)
 			}
 		}
 	}
 
+	// paused task was finished or removed meanwhile, so there is nothing to resume
+	if flin.resume {
+		err := fmt.Errorf("flow %d has no paused task to resume", fw.flowCtx.FlowID)
+		fw.ackInput(flin, err)
+		return nil, err
+	}
+
 	// anyway there need to set flow status to Running to disable user input
 	_ = fw.SetStatus(fw.ctx, database.FlowStatusRunning)
 
 	if task, err := fw.tc.CreateTask(fw.ctx, flin.input, fw); err != nil {
 		err = fmt.Errorf("failed to create task for flow %d: %w", fw.flowCtx.FlowID, err)
-		flin.done <- err
+		fw.ackInput(flin, err)
 		return nil, err
 	} else {
-		flin.done <- nil
+		fw.ackInput(flin, nil)
 		spanName := fmt.Sprintf("perform task %d: %s", task.GetTaskID(), task.GetTitle())
 		return task, fw.runTask(spanName, flin.input, task)
 	}
@@ -812,6 +1771,9 @@ func (fw *flowWorker) runTask(spanName, input string, task TaskWorker) error {
 			langfuse.WithSpanStatus(err.Error()),
 			langfuse.WithSpanLevel(langfuse.ObservationLevelError),
 		)
+		if fw.ctx.Err() == nil {
+			fw.finishTask(taskErrorFinishReason(err))
+		}
 		return fmt.Errorf("failed to run task %d: %w", task.GetTaskID(), err)
 	}
 
@@ -830,9 +1792,38 @@ func (fw *flowWorker) runTask(spanName, input string, task TaskWorker) error {
 		)
 	}
 
+	switch {
+	case status != database.TaskStatusFailed && status != database.TaskStatusFinished:
+		// the task which is waiting for the user input isn't finished yet
+	case task.PlanLimitReason() != "":
+		// the task was wrapped up by the planning limits, its own status is kept in the task
+		fw.finishTask(database.FlowFinishReasonPlanLimitExceeded)
+	case status == database.TaskStatusFailed:
+		fw.finishTask(database.FlowFinishReasonError)
+	default:
+		fw.finishTask(database.FlowFinishReasonCompleted)
+	}
+
 	return nil
 }
 
+// finishTask records the finish reason of the flow after its task is done, the flow waits for
+// the next input so the failure to store the reason doesn't break it
+func (fw *flowWorker) finishTask(reason database.FlowFinishReason) {
+	if err := fw.setFinishReason(fw.ctx, reason); err != nil {
+		fw.logger.WithError(err).WithField("finish_reason", reason).Warn("failed to set flow finish reason")
+	}
+}
+
+// taskErrorFinishReason distinguishes the providers outage from other task errors
+func taskErrorFinishReason(err error) database.FlowFinishReason {
+	if providers.IsProvidersExhaustedError(err) {
+		return database.FlowFinishReasonProviderExhausted
+	}
+
+	return database.FlowFinishReasonError
+}
+
 func newFlowProviderWorkers(
 	ctx context.Context,
 	flowID int64,
@@ -873,6 +1864,7 @@ func newFlowProviderWorkers(
 		mlw:  mlw,
 		alw:  alw,
 		slw:  slw,
+		slp:  cnts.slc.FlowSearchLogProvider(flowID, slw),
 		tlw:  tlw,
 		vslw: vslw,
 		sw:   sw,
@@ -918,8 +1910,53 @@ func getFlowProviderWorkers(
 		mlw:  mlw,
 		alw:  alw,
 		slw:  slw,
+		slp:  cnts.slc.FlowSearchLogProvider(flowID, slw),
 		tlw:  tlw,
 		vslw: vslw,
 		sw:   sw,
 	}, nil
 }
+
+// newProviderSwitchHandler persists the active provider of the fallback chain on the flow
+// and notifies subscribers about the switch
+func newProviderSwitchHandler(
+	db database.Querier,
+	pub subscriptions.FlowPublisher,
+	flowID int64,
+) providers.ProviderSwitchHandler {
+	return func(ctx context.Context, prvname provider.ProviderName, prv provider.Provider) error {
+		flow, err := db.UpdateFlowProvider(ctx, database.UpdateFlowProviderParams{
+			ModelProviderName: prvname.String(),
+			ModelProviderType: database.ProviderType(prv.Type()),
+			Model:             prv.Model(pconfig.OptionsTypePrimaryAgent),
+			ID:                flowID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update flow %d provider: %w", flowID, err)
+		}
+
+		containers, err := db.GetFlowContainers(ctx, flowID)
+		if err != nil {
+			return fmt.Errorf("failed to get flow %d containers: %w", flowID, err)
+		}
+
+		pub.FlowUpdated(ctx, flow, containers)
+
+		return nil
+	}
+}
+
+// remainingFallbackProviders returns the part of the stored fallback chain which follows the active provider
+func remainingFallbackProviders(flow database.Flow) provider.ProvidersListNames {
+	var fallbacks provider.ProvidersListNames
+	if err := json.Unmarshal(flow.FallbackProviders, &fallbacks); err != nil {
+		return nil
+	}
+
+	active := provider.ProviderName(flow.ModelProviderName)
+	if idx := slices.Index(fallbacks, active); idx >= 0 {
+		return fallbacks[idx+1:]
+	}
+
+	return fallbacks
+}
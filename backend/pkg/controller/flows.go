@@ -1,37 +1,116 @@
 package controller
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"pentagi/pkg/config"
 	"pentagi/pkg/database"
 	"pentagi/pkg/docker"
 	"pentagi/pkg/graph/subscriptions"
+	obs "pentagi/pkg/observability"
 	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/tools"
 
 	"github.com/sirupsen/logrus"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 var (
-	ErrFlowNotFound       = fmt.Errorf("flow not found")
-	ErrFlowAlreadyStopped = fmt.Errorf("flow already stopped")
+	ErrFlowNotFound        = fmt.Errorf("flow not found")
+	ErrFlowAlreadyStopped  = fmt.Errorf("flow already stopped")
+	ErrFlowBudgetExceeded  = fmt.Errorf("flow budget exceeded")
+	ErrFlowsLimitExceeded  = fmt.Errorf("running flows limit exceeded")
+	ErrServerShuttingDown  = fmt.Errorf("server is shutting down")
+	ErrContainerNotFound   = fmt.Errorf("container not found")
+	ErrContainerNotRunning = fmt.Errorf("container is not running")
+	ErrTaskNotFound        = fmt.Errorf("task not found")
+	ErrSubtaskNotFound     = fmt.Errorf("subtask not found")
+	ErrSubtaskCompleted    = fmt.Errorf("subtask has already completed")
+	ErrSubtaskCanceled     = fmt.Errorf("subtask canceled by user")
+	ErrSubtaskNotPlanned   = fmt.Errorf("subtask is not planned")
 )
 
+const (
+	snapshotImageRepository = "pentagi-snapshot"
+	flowPurgeAuditAction    = "flow.purge"
+)
+
+// FlowContainersCleanup describes result of the best-effort flow containers removal
+type FlowContainersCleanup struct {
+	Removed  []int64
+	Orphaned []int64
+}
+
+// IsPartial returns true if some flow containers were left in docker
+func (fcc FlowContainersCleanup) IsPartial() bool {
+	return len(fcc.Orphaned) != 0
+}
+
+// FlowPurge describes the resources of the deleted flow which were removed by the purge
+type FlowPurge struct {
+	Containers  int
+	Snapshots   int
+	Attachments int
+	Artifacts   int
+}
+
+// IsEmpty returns true if the purge removed nothing
+func (fp FlowPurge) IsEmpty() bool {
+	return fp.Containers == 0 && fp.Snapshots == 0 && fp.Attachments == 0 && fp.Artifacts == 0
+}
+
+// FlowLaunch controls the start of the new flow if the user already runs the allowed number of flows
+type FlowLaunch struct {
+	Admin bool // the user is flows admin and has the admin limit of running flows
+	Queue bool // queue the flow to start when the user has a free slot instead of failing
+}
+
+// queuedFlow is the created flow which waits for a free slot of its user to get the first input,
+// the input is stored in the inputs history with queued status so the queue is restored after the restart
+type queuedFlow struct {
+	fw      FlowWorker
+	inputID int64
+	input   string
+	limit   int
+}
+
+// CreateFlowOptions describes the new flow, zero values of the optional fields keep the defaults
+type CreateFlowOptions struct {
+	UserID          int64
+	Input           string
+	ProviderName    provider.ProviderName
+	ProviderType    provider.ProviderType
+	Fallbacks       provider.ProvidersListNames
+	Limits          *docker.ContainerLimits // nil uses the default limits of the configuration
+	Image           string                  // empty image is chosen by the provider
+	Functions       *tools.Functions
+	Env             tools.FlowEnv
+	Budget          FlowBudget
+	ToolLimit       FlowToolLimits
+	PlanLimit       FlowPlanLimits
+	ResultLimit     FlowResultLimits
+	ResponseCache   providers.ResponseCacheMode
+	ProviderTimeout *int32  // LLM call timeout in seconds, nil keeps the default
+	SystemPrompt    *string // replaces the system prompt of the primary agent, nil keeps the default
+	CommandPolicy   tools.CommandPolicy
+	Scope           tools.FlowScope
+	Launch          FlowLaunch
+}
+
 type FlowController interface {
-	CreateFlow(
-		ctx context.Context,
-		userID int64,
-		input string,
-		prvname provider.ProviderName,
-		prvtype provider.ProviderType,
-		functions *tools.Functions,
-	) (FlowWorker, error)
+	CreateFlow(ctx context.Context, opts CreateFlowOptions) (FlowWorker, error)
 	CreateAssistant(
 		ctx context.Context,
 		userID int64,
@@ -43,28 +122,48 @@ type FlowController interface {
 		functions *tools.Functions,
 	) (AssistantWorker, error)
 	LoadFlows(ctx context.Context) error
+	RunFlowsQueue(ctx context.Context, interval time.Duration)
+	LoadFlow(ctx context.Context, flowID int64) (FlowWorker, error)
 	ListFlows(ctx context.Context) []FlowWorker
 	GetFlow(ctx context.Context, flowID int64) (FlowWorker, error)
 	StopFlow(ctx context.Context, flowID int64) error
 	FinishFlow(ctx context.Context, flowID int64) error
+	// ArchiveFlow finishes the flow which is being deleted but keeps its containers until the flow is purged
+	ArchiveFlow(ctx context.Context, flowID int64) error
+	// RunFlowsPurge periodically removes the resources of the flows deleted before the retention period
+	RunFlowsPurge(ctx context.Context, interval time.Duration)
 	RenameFlow(ctx context.Context, flowID int64, title string) error
+	SetFlowSecrets(ctx context.Context, flowID int64, secrets map[string]string) ([]database.FlowEnv, error)
+	SnapshotContainer(ctx context.Context, userID, flowID, containerID int64) (database.ContainerSnapshot, error)
+	DeleteFlowSnapshots(ctx context.Context, flowID int64) error
+	PutFlowAttachment(ctx context.Context, flowID int64, attachment database.FlowAttachment) error
+	DeleteFlowAttachments(ctx context.Context, flowID int64) error
+	DeleteFlowArtifacts(ctx context.Context, flowID int64) error
+	CleanupFlowContainers(ctx context.Context, flowID int64) (FlowContainersCleanup, error)
+	// LockFlowContainers serializes the changes of the flow containers, the returned function releases the lock
+	LockFlowContainers(flowID int64) func()
+	Shutdown(ctx context.Context) error
+	IsShuttingDown() bool
 }
 
 type flowController struct {
-	db     database.Querier
-	mx     *sync.Mutex
-	cfg    *config.Config
-	flows  map[int64]FlowWorker
-	docker docker.DockerClient
-	provs  providers.ProviderController
-	subs   subscriptions.SubscriptionsController
-	alc    AgentLogController
-	mlc    MsgLogController
-	aslc   AssistantLogController
-	slc    SearchLogController
-	tlc    TermLogController
-	vslc   VectorStoreLogController
-	sc     ScreenshotController
+	db       database.Querier
+	mx       *sync.Mutex
+	locks    *flowLocks
+	cfg      *config.Config
+	flows    map[int64]FlowWorker
+	queue    []queuedFlow
+	draining atomic.Bool // shutdown is in progress, new flows are rejected
+	docker   docker.DockerClient
+	provs    providers.ProviderController
+	subs     subscriptions.SubscriptionsController
+	alc      AgentLogController
+	mlc      MsgLogController
+	aslc     AssistantLogController
+	slc      SearchLogController
+	tlc      TermLogController
+	vslc     VectorStoreLogController
+	sc       ScreenshotController
 }
 
 func NewFlowController(
@@ -77,6 +176,7 @@ func NewFlowController(
 	return &flowController{
 		db:     db,
 		mx:     &sync.Mutex{},
+		locks:  newFlowLocks(),
 		cfg:    cfg,
 		flows:  make(map[int64]FlowWorker),
 		docker: docker,
@@ -85,68 +185,390 @@ func NewFlowController(
 		alc:    NewAgentLogController(db),
 		mlc:    NewMsgLogController(db),
 		aslc:   NewAssistantLogController(db),
-		slc:    NewSearchLogController(db),
+		slc:    NewSearchLogController(db, cfg),
 		tlc:    NewTermLogController(db),
 		vslc:   NewVectorStoreLogController(db),
 		sc:     NewScreenshotController(db),
 	}
 }
 
+// flowLocks keeps the mutex per flow while somebody holds or waits for it
+type flowLocks struct {
+	mx    sync.Mutex
+	locks map[int64]*flowLock
+}
+
+type flowLock struct {
+	mx   sync.Mutex
+	refs int
+}
+
+func newFlowLocks() *flowLocks {
+	return &flowLocks{
+		locks: make(map[int64]*flowLock),
+	}
+}
+
+func (fl *flowLocks) lock(flowID int64) func() {
+	fl.mx.Lock()
+	lock, ok := fl.locks[flowID]
+	if !ok {
+		lock = &flowLock{}
+		fl.locks[flowID] = lock
+	}
+	lock.refs++
+	fl.mx.Unlock()
+
+	lock.mx.Lock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lock.mx.Unlock()
+
+			fl.mx.Lock()
+			defer fl.mx.Unlock()
+			if lock.refs--; lock.refs == 0 {
+				delete(fl.locks, flowID)
+			}
+		})
+	}
+}
+
+func (fc *flowController) newFlowWorkerCtx() flowWorkerCtx {
+	return flowWorkerCtx{
+		db:     fc.db,
+		cfg:    fc.cfg,
+		docker: fc.docker,
+		provs:  fc.provs,
+		subs:   fc.subs,
+		flowProviderControllers: flowProviderControllers{
+			mlc:  fc.mlc,
+			aslc: fc.aslc,
+			alc:  fc.alc,
+			slc:  fc.slc,
+			tlc:  fc.tlc,
+			vslc: fc.vslc,
+			sc:   fc.sc,
+		},
+	}
+}
+
+// LoadFlows reconciles running and waiting flows after the server restart with their containers,
+// flows are resumed from the last subtask if it's possible otherwise they are marked as failed,
+// created flows with the stored queued input are put back to the flows queue
 func (fc *flowController) LoadFlows(ctx context.Context) error {
 	flows, err := fc.db.GetFlows(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load flows: %w", err)
 	}
 
+	var queue []queuedFlow
 	for _, flow := range flows {
-		fw, err := LoadFlowWorker(ctx, flow, flowWorkerCtx{
-			db:     fc.db,
-			cfg:    fc.cfg,
-			docker: fc.docker,
-			provs:  fc.provs,
-			subs:   fc.subs,
-			flowProviderControllers: flowProviderControllers{
-				mlc:  fc.mlc,
-				aslc: fc.aslc,
-				alc:  fc.alc,
-				slc:  fc.slc,
-				tlc:  fc.tlc,
-				vslc: fc.vslc,
-				sc:   fc.sc,
-			},
-		})
+		var qf queuedFlow
+		switch flow.Status {
+		case database.FlowStatusRunning, database.FlowStatusWaiting:
+		case database.FlowStatusCreated:
+			var ok bool
+			if qf, ok, err = fc.getQueuedFlow(ctx, flow); err != nil {
+				logrus.WithContext(ctx).WithError(err).WithField("flow_id", flow.ID).
+					Error("failed to get queued flow input")
+				continue
+			} else if !ok {
+				continue
+			}
+		default:
+			continue
+		}
+
+		logger := logrus.WithContext(ctx).WithField("flow_id", flow.ID)
+
+		if reason := fc.reconcileFlow(ctx, flow); reason != "" {
+			logger.WithField("stop_reason", reason).Warn("flow can't be resumed after restart")
+			if err := fc.failFlow(ctx, flow, reason); err != nil {
+				logger.WithError(err).Error("failed to mark flow as failed")
+			}
+			continue
+		}
+
+		// flow is resumed so the shutdown reason is stale, it's cleared before the worker publishes the flow
+		paused := flow.StopReason.String == shutdownPausedReason
+		if paused || flow.StopReason.String == shutdownInterruptedReason {
+			flow, err = fc.db.UpdateFlowStopReason(ctx, database.UpdateFlowStopReasonParams{
+				StopReason: sql.NullString{},
+				ID:         flow.ID,
+			})
+			if err != nil {
+				logger.WithError(err).Error("failed to clear flow stop reason")
+				continue
+			}
+		}
+
+		fw, err := LoadFlowWorker(ctx, flow, fc.newFlowWorkerCtx())
 		if err != nil {
 			if errors.Is(err, ErrNothingToLoad) {
 				continue
 			}
 
-			logrus.WithContext(ctx).WithError(err).Errorf("failed to load flow %d", flow.ID)
+			logger.WithError(err).Error("failed to load flow")
+			reason := fmt.Sprintf("failed to resume after server restart: %v", err)
+			if err := fc.failFlow(ctx, flow, reason); err != nil {
+				logger.WithError(err).Error("failed to mark flow as failed")
+			}
 			continue
 		}
 
 		fc.flows[flow.ID] = fw
+
+		if flow.Status == database.FlowStatusCreated {
+			qf.fw = fw
+			queue = append(queue, qf)
+			continue
+		}
+
+		// running tasks are continued by the worker itself but the paused one waits for the input
+		if paused {
+			if err := fw.Resume(ctx); err != nil {
+				logger.WithError(err).Error("failed to resume flow paused by server shutdown")
+			} else {
+				logger.Info("flow paused by server shutdown was resumed")
+			}
+		}
 	}
 
+	// flows are started in the order of queuing like before the restart
+	slices.SortFunc(queue, func(a, b queuedFlow) int {
+		return cmp.Compare(a.inputID, b.inputID)
+	})
+	fc.queue = append(fc.queue, queue...)
+
 	return nil
 }
 
-func (fc *flowController) CreateFlow(
-	ctx context.Context,
-	userID int64,
-	input string,
-	prvname provider.ProviderName,
-	prvtype provider.ProviderType,
-	functions *tools.Functions,
-) (FlowWorker, error) {
+// getQueuedFlow returns the stored input of the created flow which was queued before the restart
+// and the running flows limit of its user, created flow without queued input isn't queued
+func (fc *flowController) getQueuedFlow(ctx context.Context, flow database.Flow) (queuedFlow, bool, error) {
+	inputs, err := fc.db.GetFlowInputs(ctx, flow.ID)
+	if err != nil {
+		return queuedFlow{}, false, fmt.Errorf("failed to get flow %d inputs: %w", flow.ID, err)
+	}
+
+	idx := slices.IndexFunc(inputs, func(input database.FlowInput) bool {
+		return input.Status == database.FlowInputStatusQueued
+	})
+	if idx < 0 {
+		return queuedFlow{}, false, nil
+	}
+
+	user, err := fc.db.GetUser(ctx, flow.UserID)
+	if err != nil {
+		return queuedFlow{}, false, fmt.Errorf("failed to get user %d: %w", flow.UserID, err)
+	}
+
+	limit := fc.cfg.FlowsMaxRunning
+	if slices.Contains(user.Privileges, "flows.admin") {
+		limit = fc.cfg.FlowsMaxRunningAdmin
+	}
+
+	return queuedFlow{
+		inputID: inputs[idx].ID,
+		input:   inputs[idx].Input,
+		limit:   limit,
+	}, true, nil
+}
+
+// reconcileFlow returns the reason why the flow can't be resumed after the restart or empty string
+func (fc *flowController) reconcileFlow(ctx context.Context, flow database.Flow) string {
+	// waiting flow which wasn't paused by the shutdown waits for the user input and it's loaded as is
+	interrupted := flow.Status == database.FlowStatusRunning ||
+		flow.StopReason.String == shutdownPausedReason ||
+		flow.StopReason.String == shutdownInterruptedReason
+	if interrupted && !fc.cfg.FlowsAutoResume {
+		return restartInterruptedReason
+	}
+
+	container, err := fc.db.GetFlowPrimaryContainer(ctx, flow.ID)
+	if err != nil {
+		return fmt.Sprintf("primary container of the flow is not found: %v", err)
+	}
+
+	// the container which isn't running in the database is spawned again on the worker loading
+	if container.Status != database.ContainerStatusRunning {
+		return ""
+	}
+
+	// the container which was stopped or removed in Docker is marked as stopped to be spawned again
+	// on the worker loading from the same image, the state of its file system is lost
+	running, err := fc.docker.IsContainerRunning(ctx, container.LocalID.String)
+	if err == nil && running {
+		return ""
+	}
+
+	logger := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"flow_id":   flow.ID,
+		"container": container.Name,
+	})
+	if err != nil {
+		logger = logger.WithError(err)
+	}
+	logger.Warn("primary container of the flow is lost after server restart, it will be spawned again")
+
+	if _, err := fc.db.UpdateContainerStatus(ctx, database.UpdateContainerStatusParams{
+		Status: database.ContainerStatusStopped,
+		ID:     container.ID,
+	}); err != nil {
+		return fmt.Sprintf("failed to mark lost primary container '%s' as stopped: %v", container.Name, err)
+	}
+
+	return ""
+}
+
+// failFlow marks the unrecoverable flow as failed with the reason, removes its containers
+// and notifies the subscribers
+func (fc *flowController) failFlow(ctx context.Context, flow database.Flow, reason string) error {
+	if _, err := fc.db.UpdateFlowStatus(ctx, database.UpdateFlowStatusParams{
+		Status: database.FlowStatusFailed,
+		ID:     flow.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to set flow %d status to failed: %w", flow.ID, err)
+	}
+
+	if _, err := fc.db.UpdateFlowFinishReason(ctx, database.UpdateFlowFinishReasonParams{
+		FinishReason: database.NullFlowFinishReason{FlowFinishReason: database.FlowFinishReasonError, Valid: true},
+		ID:           flow.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to set flow %d finish reason: %w", flow.ID, err)
+	}
+
+	flow, err := fc.db.UpdateFlowStopReason(ctx, database.UpdateFlowStopReasonParams{
+		StopReason: database.StringToNullString(reason),
+		ID:         flow.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set flow %d stop reason: %w", flow.ID, err)
+	}
+
+	// inputs of the failed flow are never consumed, it includes the input of the queued flow
+	if err := fc.db.CancelFlowQueuedInputs(ctx, database.CancelFlowQueuedInputsParams{
+		Error:  database.StringToNullString(reason),
+		FlowID: flow.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to cancel flow %d queued inputs: %w", flow.ID, err)
+	}
+
+	containers, err := fc.db.GetFlowContainers(ctx, flow.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d containers: %w", flow.ID, err)
+	}
+
+	for _, container := range containers {
+		switch container.Status {
+		case database.ContainerStatusStarting, database.ContainerStatusRunning:
+			if err := fc.docker.DeleteContainer(ctx, container.LocalID.String, container.ID); err != nil {
+				logrus.WithContext(ctx).WithError(err).WithField("flow_id", flow.ID).
+					Warnf("failed to delete container '%s' of failed flow", container.Name)
+			}
+		}
+	}
+
+	if containers, err = fc.db.GetFlowContainers(ctx, flow.ID); err == nil {
+		fc.subs.NewFlowPublisher(flow.UserID, flow.ID).FlowUpdated(ctx, flow, containers)
+	}
+
+	return nil
+}
+
+// LoadFlow starts the worker of the running or waiting flow which has no worker yet,
+// it's used to bring back the flow which was restored after deletion
+func (fc *flowController) LoadFlow(ctx context.Context, flowID int64) (FlowWorker, error) {
+	fc.mx.Lock()
+	defer fc.mx.Unlock()
+
+	if fw, ok := fc.flows[flowID]; ok {
+		return fw, nil
+	}
+
+	if fc.draining.Load() {
+		return nil, fmt.Errorf("failed to load flow %d: %w", flowID, ErrServerShuttingDown)
+	}
+
+	flow, err := fc.db.GetFlow(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow %d: %w", flowID, err)
+	}
+
+	fw, err := LoadFlowWorker(ctx, flow, fc.newFlowWorkerCtx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load flow %d worker: %w", flowID, err)
+	}
+
+	fc.flows[flowID] = fw
+
+	return fw, nil
+}
+
+func (fc *flowController) CreateFlow(ctx context.Context, opts CreateFlowOptions) (FlowWorker, error) {
+	// flow trace outlives the request so it starts from the new root span linked to the request one,
+	// the flow stores its trace ID to continue the trace after the backend restart
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.CreateFlow",
+		oteltrace.WithNewRoot(),
+		oteltrace.WithLinks(oteltrace.LinkFromContext(ctx)),
+	)
+	defer span.End()
+
 	fc.mx.Lock()
 	defer fc.mx.Unlock()
 
+	if fc.draining.Load() {
+		return nil, fmt.Errorf("failed to create flow: %w", ErrServerShuttingDown)
+	}
+
+	resolvedLimits, err := docker.ResolveContainerLimits(fc.cfg, opts.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve container limits: %w", err)
+	}
+
+	limit := fc.cfg.FlowsMaxRunning
+	if opts.Launch.Admin {
+		limit = fc.cfg.FlowsMaxRunningAdmin
+	}
+
+	var queued bool
+	if limit > 0 {
+		running, err := fc.db.GetUserRunningFlowsCount(ctx, opts.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user %d running flows count: %w", opts.UserID, err)
+		}
+
+		// new queued flow can't overtake the flows which were queued before
+		if running >= int64(limit) || (opts.Launch.Queue && fc.hasQueuedFlows(opts.UserID)) {
+			if !opts.Launch.Queue {
+				return nil, fmt.Errorf("user %d runs %d of %d allowed flows: %w", opts.UserID, running, limit, ErrFlowsLimitExceeded)
+			}
+			queued = true
+		}
+	}
+
 	fw, err := NewFlowWorker(ctx, newFlowWorkerCtx{
-		userID:    userID,
-		input:     input,
-		prvname:   prvname,
-		prvtype:   prvtype,
-		functions: functions,
+		userID:          opts.UserID,
+		input:           opts.Input,
+		dryRun:          queued,
+		prvname:         opts.ProviderName,
+		prvtype:         opts.ProviderType,
+		fallbacks:       opts.Fallbacks,
+		limits:          resolvedLimits,
+		image:           opts.Image,
+		functions:       opts.Functions,
+		env:             opts.Env,
+		budget:          opts.Budget,
+		toolLimit:       opts.ToolLimit,
+		planLimit:       opts.PlanLimit,
+		resultLimit:     opts.ResultLimit,
+		responseCache:   opts.ResponseCache,
+		providerTimeout: opts.ProviderTimeout,
+		systemPrompt:    opts.SystemPrompt,
+		commandPolicy:   opts.CommandPolicy,
+		scope:           opts.Scope,
 		flowWorkerCtx: flowWorkerCtx{
 			db:     fc.db,
 			cfg:    fc.cfg,
@@ -170,9 +592,134 @@ func (fc *flowController) CreateFlow(
 
 	fc.flows[fw.GetFlowID()] = fw
 
+	if queued {
+		record, err := fc.db.CreateFlowInput(ctx, database.CreateFlowInputParams{
+			Input:  opts.Input,
+			FlowID: fw.GetFlowID(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store queued flow %d input: %w", fw.GetFlowID(), err)
+		}
+
+		fc.queue = append(fc.queue, queuedFlow{fw: fw, inputID: record.ID, input: opts.Input, limit: limit})
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"flow_id": fw.GetFlowID(),
+			"user_id": opts.UserID,
+			"limit":   limit,
+		}).Info("flow queued until the user has a free slot")
+	}
+
 	return fw, nil
 }
 
+func (fc *flowController) hasQueuedFlows(userID int64) bool {
+	return slices.ContainsFunc(fc.queue, func(qf queuedFlow) bool {
+		return qf.fw.GetUserID() == userID
+	})
+}
+
+// startQueuedFlows puts the first input to the queued flows in the order of queuing
+// while their users have free slots, flows which were finished or got input in other way are dropped
+func (fc *flowController) startQueuedFlows(ctx context.Context) error {
+	fc.mx.Lock()
+	defer fc.mx.Unlock()
+
+	// queued flows stay created with the stored input and are queued again after the restart
+	if fc.draining.Load() {
+		return nil
+	}
+
+	var errs []error
+	running := make(map[int64]int64)
+	queue := fc.queue[:0]
+	for _, qf := range fc.queue {
+		flowID, userID := qf.fw.GetFlowID(), qf.fw.GetUserID()
+		if _, ok := fc.flows[flowID]; !ok {
+			continue
+		}
+
+		status, err := qf.fw.GetStatus(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get flow %d status: %w", flowID, err))
+			queue = append(queue, qf)
+			continue
+		}
+		if status != database.FlowStatusCreated {
+			// finished flow cancels its queued input itself but the started one leaves it in the history
+			if status != database.FlowStatusFinished && status != database.FlowStatusFailed {
+				if err := fc.cancelQueuedInput(ctx, qf.inputID); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		count, ok := running[userID]
+		if !ok {
+			if count, err = fc.db.GetUserRunningFlowsCount(ctx, userID); err != nil {
+				errs = append(errs, fmt.Errorf("failed to get user %d running flows count: %w", userID, err))
+				queue = append(queue, qf)
+				continue
+			}
+		}
+
+		if count >= int64(qf.limit) {
+			running[userID] = count
+			queue = append(queue, qf)
+			continue
+		}
+
+		if err := qf.fw.PutQueuedInput(ctx, qf.inputID, qf.input); err != nil {
+			errs = append(errs, fmt.Errorf("failed to start queued flow %d: %w", flowID, err))
+			continue
+		}
+
+		running[userID] = count + 1
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"flow_id": flowID,
+			"user_id": userID,
+		}).Info("queued flow started")
+	}
+	fc.queue = queue
+
+	return errors.Join(errs...)
+}
+
+// cancelQueuedInput marks the stored input of the queued flow which was started by another input as canceled
+func (fc *flowController) cancelQueuedInput(ctx context.Context, inputID int64) error {
+	_, err := fc.db.UpdateFlowInputStatus(ctx, database.UpdateFlowInputStatusParams{
+		Status: database.FlowInputStatusCanceled,
+		Error:  database.StringToNullString(inputCanceledStarted),
+		ID:     inputID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel queued flow input %d: %w", inputID, err)
+	}
+
+	return nil
+}
+
+// RunFlowsQueue periodically starts the queued flows until the context is done
+func (fc *flowController) RunFlowsQueue(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fc.startQueuedFlows(ctx); err != nil {
+				logrus.WithContext(ctx).WithError(err).Warn("failed to start queued flows")
+			}
+		}
+	}
+}
+
 func (fc *flowController) CreateAssistant(
 	ctx context.Context,
 	userID int64,
@@ -186,6 +733,10 @@ func (fc *flowController) CreateAssistant(
 	fc.mx.Lock()
 	defer fc.mx.Unlock()
 
+	if fc.draining.Load() {
+		return nil, fmt.Errorf("failed to create assistant: %w", ErrServerShuttingDown)
+	}
+
 	var (
 		fw  FlowWorker
 		ok  bool
@@ -356,7 +907,10 @@ func (fc *flowController) FinishFlow(ctx context.Context, flowID int64) error {
 		return ErrFlowNotFound
 	}
 
+	// the flow worker removes its containers on finish
+	unlock := fc.locks.lock(flowID)
 	err := flow.Finish(ctx)
+	unlock()
 	if err != nil {
 		return fmt.Errorf("failed to finish flow %d: %w", flowID, err)
 	}
@@ -366,6 +920,27 @@ func (fc *flowController) FinishFlow(ctx context.Context, flowID int64) error {
 	return nil
 }
 
+func (fc *flowController) ArchiveFlow(ctx context.Context, flowID int64) error {
+	fc.mx.Lock()
+	defer fc.mx.Unlock()
+
+	flow, ok := fc.flows[flowID]
+	if !ok {
+		return ErrFlowNotFound
+	}
+
+	unlock := fc.locks.lock(flowID)
+	err := flow.Archive(ctx)
+	unlock()
+	if err != nil {
+		return fmt.Errorf("failed to archive flow %d: %w", flowID, err)
+	}
+
+	delete(fc.flows, flowID)
+
+	return nil
+}
+
 func (fc *flowController) RenameFlow(ctx context.Context, flowID int64, title string) error {
 	fc.mx.Lock()
 	defer fc.mx.Unlock()
@@ -377,3 +952,401 @@ func (fc *flowController) RenameFlow(ctx context.Context, flowID int64, title st
 
 	return flow.Rename(ctx, title)
 }
+
+// SetFlowSecrets replaces all secrets of the flow by the single statement, so the flow never gets
+// the mix of the old and new secrets, the running flow applies them to the next terminal command
+func (fc *flowController) SetFlowSecrets(
+	ctx context.Context,
+	flowID int64,
+	secrets map[string]string,
+) ([]database.FlowEnv, error) {
+	names := make([]string, 0, len(secrets))
+	values := make([]string, 0, len(secrets))
+	for name, value := range secrets {
+		encrypted, err := tools.EncryptFlowSecret(fc.cfg, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt flow %d secret '%s': %w", flowID, name, err)
+		}
+		names = append(names, name)
+		values = append(values, encrypted)
+	}
+
+	err := fc.db.ReplaceFlowSecrets(ctx, database.ReplaceFlowSecretsParams{
+		FlowID:       flowID,
+		Names:        names,
+		SecretValues: values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace flow %d secrets: %w", flowID, err)
+	}
+
+	env, err := fc.db.GetFlowEnv(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow %d env: %w", flowID, err)
+	}
+
+	return env, nil
+}
+
+func (fc *flowController) SnapshotContainer(
+	ctx context.Context,
+	userID, flowID, containerID int64,
+) (database.ContainerSnapshot, error) {
+	defer fc.locks.lock(flowID)()
+
+	containers, err := fc.db.GetFlowContainers(ctx, flowID)
+	if err != nil {
+		return database.ContainerSnapshot{}, fmt.Errorf("failed to get flow %d containers: %w", flowID, err)
+	}
+
+	idx := slices.IndexFunc(containers, func(cnt database.Container) bool {
+		return cnt.ID == containerID
+	})
+	if idx == -1 {
+		return database.ContainerSnapshot{}, ErrContainerNotFound
+	}
+
+	cnt := containers[idx]
+	if cnt.Status != database.ContainerStatusRunning || !cnt.LocalID.Valid {
+		return database.ContainerSnapshot{}, ErrContainerNotRunning
+	}
+
+	reference := fmt.Sprintf("%s:flow-%d-container-%d-%d",
+		snapshotImageRepository, flowID, containerID, time.Now().Unix())
+	if _, err := fc.docker.CommitContainer(ctx, cnt.LocalID.String, reference); err != nil {
+		return database.ContainerSnapshot{}, fmt.Errorf("failed to commit container %d: %w", containerID, err)
+	}
+
+	snapshot, err := fc.db.CreateContainerSnapshot(ctx, database.CreateContainerSnapshotParams{
+		Image:       reference,
+		FlowID:      flowID,
+		ContainerID: containerID,
+		UserID:      userID,
+	})
+	if err != nil {
+		if err := fc.docker.RemoveImage(ctx, reference); err != nil {
+			logrus.WithContext(ctx).WithError(err).Errorf("failed to remove snapshot image %s", reference)
+		}
+		return database.ContainerSnapshot{}, fmt.Errorf("failed to create container snapshot in DB: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+func (fc *flowController) DeleteFlowSnapshots(ctx context.Context, flowID int64) error {
+	_, err := fc.deleteFlowSnapshots(ctx, flowID)
+	return err
+}
+
+// deleteFlowSnapshots removes the snapshots of the flow and returns the number of the removed ones
+func (fc *flowController) deleteFlowSnapshots(ctx context.Context, flowID int64) (int, error) {
+	snapshots, err := fc.db.GetFlowContainerSnapshots(ctx, flowID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get flow %d snapshots: %w", flowID, err)
+	}
+
+	removed := 0
+
+	var errs []error
+	for _, snapshot := range snapshots {
+		// flows created from the snapshot run their containers from its image, so it's kept until they are gone
+		usage, err := fc.db.GetSnapshotImageUsageCount(ctx, database.GetSnapshotImageUsageCountParams{
+			Image:  snapshot.Image,
+			FlowID: flowID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get snapshot image %s usage: %w", snapshot.Image, err))
+			continue
+		}
+		if usage > 0 {
+			logrus.WithContext(ctx).WithFields(logrus.Fields{
+				"flow_id":     flowID,
+				"snapshot_id": snapshot.ID,
+				"image":       snapshot.Image,
+				"usage":       usage,
+			}).Info("snapshot image is used by other flows, keeping it")
+			continue
+		}
+
+		if err := fc.docker.RemoveImage(ctx, snapshot.Image); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove snapshot image %s: %w", snapshot.Image, err))
+			continue
+		}
+
+		if err := fc.db.DeleteContainerSnapshot(ctx, snapshot.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete snapshot %d: %w", snapshot.ID, err))
+			continue
+		}
+		removed++
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// PutFlowAttachment copies the stored attachment to the running primary container of the flow,
+// the flow which isn't running gets all attachments when its container is spawned
+func (fc *flowController) PutFlowAttachment(
+	ctx context.Context,
+	flowID int64,
+	attachment database.FlowAttachment,
+) error {
+	cnt, err := fc.db.GetFlowPrimaryContainer(ctx, flowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get flow %d primary container: %w", flowID, err)
+	}
+
+	if cnt.Status != database.ContainerStatusRunning || !cnt.LocalID.Valid {
+		return nil
+	}
+
+	attachments := []database.FlowAttachment{attachment}
+	return tools.CopyAttachmentsToContainer(ctx, fc.cfg, fc.docker, cnt.LocalID.String, attachments)
+}
+
+func (fc *flowController) DeleteFlowAttachments(ctx context.Context, flowID int64) error {
+	var errs []error
+	if err := fc.db.DeleteFlowAttachments(ctx, flowID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete flow %d attachments: %w", flowID, err))
+	}
+
+	if err := os.RemoveAll(tools.FlowAttachmentsDir(fc.cfg, flowID)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove flow %d attachments files: %w", flowID, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (fc *flowController) DeleteFlowArtifacts(ctx context.Context, flowID int64) error {
+	var errs []error
+	if err := fc.db.DeleteFlowArtifacts(ctx, flowID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete flow %d artifacts: %w", flowID, err))
+	}
+
+	if err := os.RemoveAll(tools.FlowArtifactsDir(fc.cfg, flowID)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove flow %d artifacts files: %w", flowID, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (fc *flowController) CleanupFlowContainers(ctx context.Context, flowID int64) (FlowContainersCleanup, error) {
+	var cleanup FlowContainersCleanup
+
+	defer fc.locks.lock(flowID)()
+
+	// the containers of the deleted flow are removed by the purge too
+	containers, err := fc.db.GetFlowContainersUnscoped(ctx, flowID)
+	if err != nil {
+		return cleanup, fmt.Errorf("failed to get flow %d containers: %w", flowID, err)
+	}
+
+	var errs []error
+	for _, cnt := range containers {
+		// container was torn down by flow worker or it was never started
+		if cnt.Status == database.ContainerStatusDeleted || !cnt.LocalID.Valid || cnt.LocalID.String == "" {
+			continue
+		}
+
+		err := fc.docker.DeleteContainer(ctx, cnt.LocalID.String, cnt.ID)
+		if err == nil {
+			cleanup.Removed = append(cleanup.Removed, cnt.ID)
+			continue
+		}
+
+		// the reaper will retry to remove this container later
+		cleanup.Orphaned = append(cleanup.Orphaned, cnt.ID)
+		_, dbErr := fc.db.CreateOrphanedContainer(ctx, database.CreateOrphanedContainerParams{
+			LocalID:     cnt.LocalID.String,
+			FlowID:      flowID,
+			ContainerID: database.Int64ToNullInt64(&cnt.ID),
+			LastError:   err.Error(),
+		})
+		if dbErr != nil {
+			errs = append(errs, fmt.Errorf("failed to record orphaned container %d: %w", cnt.ID, dbErr))
+		}
+	}
+
+	return cleanup, errors.Join(errs...)
+}
+
+// RunFlowsPurge periodically purges the deleted flows until the context is done
+func (fc *flowController) RunFlowsPurge(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || fc.cfg.FlowsDeleteRetention < 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fc.purgeDeletedFlows(ctx); err != nil {
+				logrus.WithContext(ctx).WithError(err).Warn("failed to purge deleted flows")
+			}
+		}
+	}
+}
+
+// purgeDeletedFlows removes the containers, snapshots, attachments and artifacts of the flows which were
+// deleted before the retention period, each purge is written to the flow audit log so the restore reports it
+func (fc *flowController) purgeDeletedFlows(ctx context.Context) error {
+	flows, err := fc.db.GetDeletedFlowsToPurge(ctx, int64(fc.cfg.FlowsDeleteRetention))
+	if err != nil {
+		return fmt.Errorf("failed to get deleted flows to purge: %w", err)
+	}
+
+	var errs []error
+	for _, flow := range flows {
+		purge, err := fc.purgeFlow(ctx, flow.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge flow %d: %w", flow.ID, err))
+		}
+
+		// the snapshot which is still used by other flows is kept, so the flow is checked again later
+		if purge.IsEmpty() {
+			continue
+		}
+
+		if err := fc.writePurgeAuditLog(ctx, flow, purge); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (fc *flowController) purgeFlow(ctx context.Context, flowID int64) (FlowPurge, error) {
+	var (
+		purge FlowPurge
+		errs  []error
+	)
+
+	cleanup, err := fc.CleanupFlowContainers(ctx, flowID)
+	purge.Containers = len(cleanup.Removed)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	purge.Snapshots, err = fc.deleteFlowSnapshots(ctx, flowID)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if attachments, err := fc.db.GetFlowAttachments(ctx, flowID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get flow %d attachments: %w", flowID, err))
+	} else if len(attachments) != 0 {
+		if err := fc.DeleteFlowAttachments(ctx, flowID); err != nil {
+			errs = append(errs, err)
+		} else {
+			purge.Attachments = len(attachments)
+		}
+	}
+
+	if artifacts, err := fc.db.GetFlowArtifacts(ctx, flowID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get flow %d artifacts: %w", flowID, err))
+	} else if len(artifacts) != 0 {
+		if err := fc.DeleteFlowArtifacts(ctx, flowID); err != nil {
+			errs = append(errs, err)
+		} else {
+			purge.Artifacts = len(artifacts)
+		}
+	}
+
+	return purge, errors.Join(errs...)
+}
+
+// writePurgeAuditLog stores the number of the removed resources as the changes of the flow on behalf of its owner
+func (fc *flowController) writePurgeAuditLog(ctx context.Context, flow database.Flow, purge FlowPurge) error {
+	changes := map[string]any{}
+	for name, removed := range map[string]int{
+		"containers":  purge.Containers,
+		"snapshots":   purge.Snapshots,
+		"attachments": purge.Attachments,
+		"artifacts":   purge.Artifacts,
+	} {
+		if removed != 0 {
+			changes[name] = map[string]any{"before": removed, "after": 0}
+		}
+	}
+
+	changesBlob, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flow %d purge changes: %w", flow.ID, err)
+	}
+
+	metadataBlob, err := json.Marshal(map[string]any{"retention": fc.cfg.FlowsDeleteRetention})
+	if err != nil {
+		return fmt.Errorf("failed to marshal flow %d purge metadata: %w", flow.ID, err)
+	}
+
+	_, err = fc.db.CreateAuditLog(ctx, database.CreateAuditLogParams{
+		UserID:   flow.UserID,
+		Action:   flowPurgeAuditAction,
+		FlowID:   flow.ID,
+		Changes:  changesBlob,
+		Metadata: metadataBlob,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write flow %d purge audit log: %w", flow.ID, err)
+	}
+
+	return nil
+}
+
+func (fc *flowController) LockFlowContainers(flowID int64) func() {
+	return fc.locks.lock(flowID)
+}
+
+// Shutdown rejects new flows and pauses all running flows concurrently until the context is done,
+// the flows which weren't paused in time are left running and continued after the restart
+func (fc *flowController) Shutdown(ctx context.Context) error {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "controller.Shutdown")
+	defer span.End()
+
+	fc.draining.Store(true)
+
+	// waits for the flows which are being created to pause them too
+	fc.mx.Lock()
+	flows := make([]FlowWorker, 0, len(fc.flows))
+	for _, fw := range fc.flows {
+		flows = append(flows, fw)
+	}
+	fc.mx.Unlock()
+
+	logrus.WithContext(ctx).WithField("flows", len(flows)).Info("pausing flows before shutdown")
+
+	var (
+		wg   sync.WaitGroup
+		mx   sync.Mutex
+		errs []error
+	)
+	for _, fw := range flows {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := fw.Pause(ctx); err != nil {
+				mx.Lock()
+				errs = append(errs, fmt.Errorf("failed to pause flow %d: %w", fw.GetFlowID(), err))
+				mx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the paused flows don't write the search logs anymore, so the buffered ones are flushed last
+	if err := fc.slc.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (fc *flowController) IsShuttingDown() bool {
+	return fc.draining.Load()
+}
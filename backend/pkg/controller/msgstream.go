@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/providers"
+)
+
+const (
+	streamFlushInterval = 100 * time.Millisecond
+	streamIdleTimeout   = 5 * time.Minute
+)
+
+type FlowMsgStreamWorker interface {
+	StreamMsg(ctx context.Context, chunk *providers.StreamMessageChunk) error
+}
+
+// msgStream is the buffered part of the agent answer which is not published yet
+type msgStream struct {
+	chunk    subscriptions.MessageChunk
+	content  strings.Builder
+	thinking strings.Builder
+	timer    *time.Timer
+	updated  time.Time
+}
+
+type flowMsgStreamWorker struct {
+	mx       *sync.Mutex
+	flowID   int64
+	interval time.Duration
+	streams  map[int64]*msgStream
+	pub      subscriptions.FlowPublisher
+}
+
+func NewFlowMsgStreamWorker(flowID int64, pub subscriptions.FlowPublisher) FlowMsgStreamWorker {
+	return &flowMsgStreamWorker{
+		mx:       &sync.Mutex{},
+		flowID:   flowID,
+		interval: streamFlushInterval,
+		streams:  make(map[int64]*msgStream),
+		pub:      pub,
+	}
+}
+
+// StreamMsg buffers deltas of the agent answer and publishes them as message chunks
+// once per flush interval to avoid events storm on fast providers
+func (msw *flowMsgStreamWorker) StreamMsg(ctx context.Context, chunk *providers.StreamMessageChunk) error {
+	if chunk == nil {
+		return nil
+	}
+
+	msw.mx.Lock()
+	defer msw.mx.Unlock()
+
+	switch chunk.Type {
+	case providers.StreamMessageChunkTypeContent:
+		stream := msw.getStream(chunk)
+		stream.content.WriteString(chunk.Content)
+		msw.scheduleFlush(ctx, stream)
+
+	case providers.StreamMessageChunkTypeThinking:
+		stream := msw.getStream(chunk)
+		if chunk.Thinking != nil {
+			stream.thinking.WriteString(chunk.Thinking.Content)
+		}
+		msw.scheduleFlush(ctx, stream)
+
+	case providers.StreamMessageChunkTypeFlush, providers.StreamMessageChunkTypeUpdate:
+		// update comes after the end of the successful stream, so it completes only the stream
+		// which wasn't finished by the provider, otherwise it's already closed and dropped
+		if stream, ok := msw.streams[chunk.StreamID]; ok {
+			msw.publish(ctx, stream, true)
+			delete(msw.streams, chunk.StreamID)
+		}
+	}
+
+	return nil
+}
+
+func (msw *flowMsgStreamWorker) getStream(chunk *providers.StreamMessageChunk) *msgStream {
+	now := time.Now()
+	if stream, ok := msw.streams[chunk.StreamID]; ok {
+		stream.updated = now
+		return stream
+	}
+
+	// streams of the failed calls are never completed by the provider
+	for streamID, stream := range msw.streams {
+		if now.Sub(stream.updated) > streamIdleTimeout {
+			if stream.timer != nil {
+				stream.timer.Stop()
+			}
+			delete(msw.streams, streamID)
+		}
+	}
+
+	stream := &msgStream{
+		chunk: subscriptions.MessageChunk{
+			FlowID:    msw.flowID,
+			TaskID:    chunk.TaskID,
+			SubtaskID: chunk.SubtaskID,
+			StreamID:  chunk.StreamID,
+			Type:      string(chunk.MsgType),
+		},
+		updated: now,
+	}
+	msw.streams[chunk.StreamID] = stream
+
+	return stream
+}
+
+func (msw *flowMsgStreamWorker) scheduleFlush(ctx context.Context, stream *msgStream) {
+	if stream.timer != nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	stream.timer = time.AfterFunc(msw.interval, func() {
+		msw.mx.Lock()
+		defer msw.mx.Unlock()
+
+		// the stream could be completed or replaced while the timer was waiting for the lock
+		if msw.streams[stream.chunk.StreamID] != stream {
+			return
+		}
+
+		stream.timer = nil
+		msw.publish(ctx, stream, false)
+	})
+}
+
+// publish sends the buffered deltas of the stream, it's called under the worker lock
+func (msw *flowMsgStreamWorker) publish(ctx context.Context, stream *msgStream, complete bool) {
+	if stream.timer != nil {
+		stream.timer.Stop()
+		stream.timer = nil
+	}
+
+	if !complete && stream.content.Len() == 0 && stream.thinking.Len() == 0 {
+		return
+	}
+
+	chunk := stream.chunk
+	chunk.Content = stream.content.String()
+	chunk.Thinking = stream.thinking.String()
+	chunk.Complete = complete
+	stream.content.Reset()
+	stream.thinking.Reset()
+
+	msw.pub.MessageChunk(ctx, chunk)
+}
@@ -5,28 +5,57 @@ import (
 	"fmt"
 	"sync"
 
+	"pentagi/pkg/config"
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/tools"
+
+	"github.com/sirupsen/logrus"
 )
 
 type SearchLogController interface {
 	NewFlowSearchLog(ctx context.Context, flowID int64, pub subscriptions.FlowPublisher) (FlowSearchLogWorker, error)
 	ListFlowsSearchLog(ctx context.Context) ([]FlowSearchLogWorker, error)
 	GetFlowSearchLog(ctx context.Context, flowID int64) (FlowSearchLogWorker, error)
+	// FlowSearchLogProvider returns the search log of the flow tools by the configured backend
+	FlowSearchLogProvider(flowID int64, slw FlowSearchLogWorker) tools.SearchLogProvider
+	// Close flushes the logs buffered by the backend
+	Close(ctx context.Context) error
 }
 
 type searchLogController struct {
-	db    database.Querier
-	mx    *sync.Mutex
-	flows map[int64]FlowSearchLogWorker
+	db       database.Querier
+	mx       *sync.Mutex
+	flows    map[int64]FlowSearchLogWorker
+	backend  string
+	toolLogs *tools.PostgresSearchLog
 }
 
-func NewSearchLogController(db database.Querier) SearchLogController {
-	return &searchLogController{
-		db:    db,
-		mx:    &sync.Mutex{},
-		flows: make(map[int64]FlowSearchLogWorker),
+func NewSearchLogController(db database.Querier, cfg *config.Config) SearchLogController {
+	slc := &searchLogController{
+		db:      db,
+		mx:      &sync.Mutex{},
+		flows:   make(map[int64]FlowSearchLogWorker),
+		backend: tools.SearchLogBackendFlow,
+	}
+
+	if cfg == nil {
+		return slc
 	}
+
+	switch cfg.SearchLogBackend {
+	case tools.SearchLogBackendFlow, "":
+	case tools.SearchLogBackendPostgres:
+		slc.backend = cfg.SearchLogBackend
+		slc.toolLogs = tools.NewPostgresSearchLog(db, cfg.SearchLogBufferSize)
+	case tools.SearchLogBackendNoop:
+		slc.backend = cfg.SearchLogBackend
+	default:
+		logrus.WithField("backend", cfg.SearchLogBackend).
+			Warnf("unknown search log backend, '%s' is used", tools.SearchLogBackendFlow)
+	}
+
+	return slc
 }
 
 func (slc *searchLogController) NewFlowSearchLog(
@@ -69,3 +98,25 @@ func (slc *searchLogController) GetFlowSearchLog(
 
 	return flw, nil
 }
+
+func (slc *searchLogController) FlowSearchLogProvider(
+	flowID int64,
+	slw FlowSearchLogWorker,
+) tools.SearchLogProvider {
+	switch slc.backend {
+	case tools.SearchLogBackendPostgres:
+		return slc.toolLogs.Provider(flowID)
+	case tools.SearchLogBackendNoop:
+		return tools.NoopSearchLogProvider{}
+	default:
+		return slw
+	}
+}
+
+func (slc *searchLogController) Close(ctx context.Context) error {
+	if slc.toolLogs == nil {
+		return nil
+	}
+
+	return slc.toolLogs.Close(ctx)
+}
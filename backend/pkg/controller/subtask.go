@@ -30,14 +30,20 @@ type SubtaskWorker interface {
 	GetResult(ctx context.Context) (string, error)
 	SetResult(ctx context.Context, result string) error
 	PutInput(ctx context.Context, input string) error
+	Resume(ctx context.Context) error
 	Run(ctx context.Context) error
 	Finish(ctx context.Context) error
+	Cancel(ctx context.Context) error
 }
 
+// subtaskCanceledResult is stored as the result of the subtask which was abandoned by user
+const subtaskCanceledResult = "subtask was canceled by user"
+
 type subtaskWorker struct {
 	mx         *sync.RWMutex
 	subtaskCtx *SubtaskContext
 	updater    TaskUpdater
+	cancel     context.CancelCauseFunc // interrupts the running agent chain, nil if subtask is not running
 	completed  bool
 	waiting    bool
 }
@@ -271,6 +277,25 @@ func (stw *subtaskWorker) PutInput(ctx context.Context, input string) error {
 	return nil
 }
 
+// Resume makes the waiting subtask which was paused by the server shutdown ready to run again without
+// the user input, its message chain was made consistent on the pause so the agent continues from it
+func (stw *subtaskWorker) Resume(ctx context.Context) error {
+	if stw.IsCompleted() {
+		return fmt.Errorf("subtask has already completed")
+	}
+
+	if !stw.IsWaiting() {
+		return fmt.Errorf("subtask is not waiting, run first")
+	}
+
+	stw.mx.Lock()
+	defer stw.mx.Unlock()
+
+	stw.waiting = false
+
+	return nil
+}
+
 func (stw *subtaskWorker) Run(ctx context.Context) error {
 	if stw.IsCompleted() {
 		return fmt.Errorf("subtask has already completed")
@@ -290,7 +315,25 @@ func (stw *subtaskWorker) Run(ctx context.Context) error {
 		msgChainID = stw.subtaskCtx.MsgChainID
 	)
 
-	performResult, err := stw.subtaskCtx.Provider.PerformAgentChain(ctx, taskID, subtaskID, msgChainID)
+	runCtx, cancel := context.WithCancelCause(ctx)
+	stw.setCancel(cancel)
+	defer func() {
+		stw.setCancel(nil)
+		cancel(nil)
+	}()
+
+	performResult, err := stw.subtaskCtx.Provider.PerformAgentChain(runCtx, taskID, subtaskID, msgChainID)
+	if errors.Is(context.Cause(runCtx), ErrSubtaskCanceled) {
+		// status and result of the canceled subtask are already stored by Cancel call,
+		// the chain has to be consistent anyway if user asks to continue the task later
+		if ctx.Err() != nil {
+			ctx = context.Background()
+		}
+		if err := stw.subtaskCtx.Provider.EnsureChainConsistency(ctx, msgChainID); err != nil {
+			return fmt.Errorf("failed to ensure chain consistency for canceled subtask %d: %w", subtaskID, err)
+		}
+		return nil
+	}
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			ctx = context.Background()
@@ -334,3 +377,35 @@ func (stw *subtaskWorker) Finish(ctx context.Context) error {
 
 	return nil
 }
+
+// Cancel abandons the subtask and marks it as failed, the running agent chain is interrupted
+// and Run returns without error so the task can continue with the next subtasks
+func (stw *subtaskWorker) Cancel(ctx context.Context) error {
+	if stw.IsCompleted() {
+		return fmt.Errorf("subtask %d: %w", stw.subtaskCtx.SubtaskID, ErrSubtaskCompleted)
+	}
+
+	if err := stw.SetResult(ctx, subtaskCanceledResult); err != nil {
+		return err
+	}
+
+	if err := stw.SetStatus(ctx, database.SubtaskStatusFailed); err != nil {
+		return err
+	}
+
+	stw.mx.RLock()
+	defer stw.mx.RUnlock()
+
+	if stw.cancel != nil {
+		stw.cancel(ErrSubtaskCanceled)
+	}
+
+	return nil
+}
+
+func (stw *subtaskWorker) setCancel(cancel context.CancelCauseFunc) {
+	stw.mx.Lock()
+	defer stw.mx.Unlock()
+
+	stw.cancel = cancel
+}
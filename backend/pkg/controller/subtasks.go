@@ -2,12 +2,15 @@ package controller
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"sort"
 	"sync"
 
 	"pentagi/pkg/database"
+	"pentagi/pkg/providers"
+	"pentagi/pkg/tools"
 )
 
 type NewSubtaskInfo struct {
@@ -15,6 +18,35 @@ type NewSubtaskInfo struct {
 	Description string
 }
 
+const (
+	planDepthLimitReason    = "task reached the limit of %d executed subtasks"
+	planSubtasksLimitReason = "task reached the limit of %d planned subtasks"
+)
+
+// PlanLimits are the resolved planning limits of the task, zero value disables the limit.
+// MaxSubtasks counts all subtasks created by the planner for the task including the ones replaced
+// by the refinement, MaxDepth counts the executed subtasks of the task
+type PlanLimits struct {
+	MaxSubtasks int
+	MaxDepth    int
+}
+
+// planLimit returns the number of subtasks which the planner may add to the task plan after executed
+// and created ones, the reason is set if the plan is restricted by the limits rather than the default size
+func (pl PlanLimits) planLimit(executed, created int) (int, string) {
+	limit, reason := max(providers.TasksNumberLimit-executed, 0), ""
+	if pl.MaxDepth > 0 && pl.MaxDepth-executed < limit {
+		limit = max(pl.MaxDepth-executed, 0)
+		reason = fmt.Sprintf(planDepthLimitReason, pl.MaxDepth)
+	}
+	if pl.MaxSubtasks > 0 && pl.MaxSubtasks-created < limit {
+		limit = max(pl.MaxSubtasks-created, 0)
+		reason = fmt.Sprintf(planSubtasksLimitReason, pl.MaxSubtasks)
+	}
+
+	return limit, reason
+}
+
 type SubtaskController interface {
 	LoadSubtasks(ctx context.Context, taskID int64, updater TaskUpdater) error
 	GenerateSubtasks(ctx context.Context) error
@@ -22,12 +54,16 @@ type SubtaskController interface {
 	PopSubtask(ctx context.Context, updater TaskUpdater) (SubtaskWorker, error)
 	ListSubtasks(ctx context.Context) []SubtaskWorker
 	GetSubtask(ctx context.Context, subtaskID int64) (SubtaskWorker, error)
+	SetSubtaskPriority(ctx context.Context, subtaskID int64, priority *int32) error
+	PlanLimitReason() string
 }
 
 type subtaskController struct {
 	mx       *sync.Mutex
 	taskCtx  *TaskContext
 	subtasks map[int64]SubtaskWorker
+	created  int    // subtasks created by the planner since the worker start or load
+	limited  string // reason of the first refused planning by the plan limits
 }
 
 func NewSubtaskController(taskCtx *TaskContext) SubtaskController {
@@ -51,6 +87,9 @@ func (stc *subtaskController) LoadSubtasks(ctx context.Context, taskID int64, up
 		return fmt.Errorf("no subtasks found for task %d: %w", taskID, ErrNothingToLoad)
 	}
 
+	// subtasks replaced by the refinement before the load are gone, so they aren't counted
+	stc.created = len(subtasks)
+
 	for _, subtask := range subtasks {
 		st, err := LoadSubtaskWorker(ctx, subtask, stc.taskCtx, updater)
 		if err != nil {
@@ -68,7 +107,8 @@ func (stc *subtaskController) LoadSubtasks(ctx context.Context, taskID int64, up
 }
 
 func (stc *subtaskController) GenerateSubtasks(ctx context.Context) error {
-	plan, err := stc.taskCtx.Provider.GenerateSubtasks(ctx, stc.taskCtx.TaskID)
+	limit, reason := stc.taskCtx.PlanLimits.planLimit(0, 0)
+	plan, err := stc.taskCtx.Provider.GenerateSubtasks(ctx, stc.taskCtx.TaskID, limit)
 	if err != nil {
 		return fmt.Errorf("failed to generate subtasks for task %d: %w", stc.taskCtx.TaskID, err)
 	}
@@ -77,6 +117,8 @@ func (stc *subtaskController) GenerateSubtasks(ctx context.Context) error {
 		return fmt.Errorf("no subtasks generated for task %d", stc.taskCtx.TaskID)
 	}
 
+	plan = stc.limitPlan(plan, limit, reason)
+
 	// TODO: change it to insert subtasks in transaction
 	for _, info := range plan {
 		_, err := stc.taskCtx.DB.CreateSubtask(ctx, database.CreateSubtaskParams{
@@ -99,7 +141,16 @@ func (stc *subtaskController) RefineSubtasks(ctx context.Context) error {
 		return fmt.Errorf("failed to get task %d subtasks: %w", stc.taskCtx.TaskID, err)
 	}
 
-	plan, err := stc.taskCtx.Provider.RefineSubtasks(ctx, stc.taskCtx.TaskID)
+	limit, reason := stc.taskCtx.PlanLimits.planLimit(len(stc.ListSubtasks(ctx)), stc.getCreated())
+	if limit == 0 {
+		// the planner isn't asked for the subtasks which would be refused anyway
+		if reason != "" {
+			stc.setLimited(reason)
+		}
+		return nil
+	}
+
+	plan, err := stc.taskCtx.Provider.RefineSubtasks(ctx, stc.taskCtx.TaskID, limit)
 	if err != nil {
 		return fmt.Errorf("failed to refine subtasks for task %d: %w", stc.taskCtx.TaskID, err)
 	}
@@ -108,9 +159,12 @@ func (stc *subtaskController) RefineSubtasks(ctx context.Context) error {
 		return nil // no subtasks refined
 	}
 
+	plan = stc.limitPlan(plan, limit, reason)
+
 	subtaskIDs := make([]int64, 0, len(subtasks))
 	for _, subtask := range subtasks {
-		if subtask.Status == database.SubtaskStatusCreated {
+		// subtasks prioritized by user are kept to run them before the refined plan
+		if subtask.Status == database.SubtaskStatusCreated && subtask.Priority == 0 {
 			subtaskIDs = append(subtaskIDs, subtask.ID)
 		}
 	}
@@ -154,6 +208,14 @@ func (stc *subtaskController) PopSubtask(ctx context.Context, updater TaskUpdate
 		return st, nil
 	}
 
+	// subtasks prioritized by user aren't limited by the plan, so the depth is checked before the run too
+	if depth := stc.taskCtx.PlanLimits.MaxDepth; depth > 0 && len(stc.subtasks) >= depth {
+		if stc.limited == "" {
+			stc.limited = fmt.Sprintf(planDepthLimitReason, depth)
+		}
+		return nil, nil
+	}
+
 	st, err := NewSubtaskWorker(ctx, stc.taskCtx, stdb.ID, stdb.Title, stdb.Description, updater)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subtask worker: %w", err)
@@ -186,8 +248,82 @@ func (stc *subtaskController) GetSubtask(ctx context.Context, subtaskID int64) (
 
 	subtask, ok := stc.subtasks[subtaskID]
 	if !ok {
-		return nil, fmt.Errorf("subtask not found")
+		return nil, fmt.Errorf("subtask %d: %w", subtaskID, ErrSubtaskNotFound)
 	}
 
 	return subtask, nil
 }
+
+// SetSubtaskPriority changes the order of the planned subtask, nil priority moves the subtask
+// above all its siblings, subtasks with the same priority are picked in creation order.
+// It's serialized with PopSubtask so the next picked subtask always sees the latest priorities
+func (stc *subtaskController) SetSubtaskPriority(ctx context.Context, subtaskID int64, priority *int32) error {
+	stc.mx.Lock()
+	defer stc.mx.Unlock()
+
+	subtask, err := stc.taskCtx.DB.GetSubtask(ctx, subtaskID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && subtask.TaskID != stc.taskCtx.TaskID) {
+		return fmt.Errorf("subtask %d: %w", subtaskID, ErrSubtaskNotFound)
+	} else if err != nil {
+		return fmt.Errorf("failed to get subtask %d: %w", subtaskID, err)
+	}
+
+	if subtask.Status != database.SubtaskStatusCreated {
+		return fmt.Errorf("subtask %d has status %s: %w", subtaskID, subtask.Status, ErrSubtaskNotPlanned)
+	}
+
+	if priority == nil {
+		_, err = stc.taskCtx.DB.BumpSubtaskPriority(ctx, subtaskID)
+	} else {
+		_, err = stc.taskCtx.DB.UpdateSubtaskPriority(ctx, database.UpdateSubtaskPriorityParams{
+			Priority: *priority,
+			ID:       subtaskID,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set subtask %d priority: %w", subtaskID, err)
+	}
+
+	return nil
+}
+
+// PlanLimitReason returns the reason why the planning of the task was stopped by the plan limits
+// or empty string if the task was planned without restrictions
+func (stc *subtaskController) PlanLimitReason() string {
+	stc.mx.Lock()
+	defer stc.mx.Unlock()
+
+	return stc.limited
+}
+
+// limitPlan cuts the plan to the limit, the reason is recorded if the planner exceeded the plan limits
+func (stc *subtaskController) limitPlan(plan []tools.SubtaskInfo, limit int, reason string) []tools.SubtaskInfo {
+	if len(plan) > limit {
+		plan = plan[:limit]
+		if reason != "" {
+			stc.setLimited(reason)
+		}
+	}
+
+	stc.mx.Lock()
+	stc.created += len(plan)
+	stc.mx.Unlock()
+
+	return plan
+}
+
+func (stc *subtaskController) getCreated() int {
+	stc.mx.Lock()
+	defer stc.mx.Unlock()
+
+	return stc.created
+}
+
+func (stc *subtaskController) setLimited(reason string) {
+	stc.mx.Lock()
+	defer stc.mx.Unlock()
+
+	if stc.limited == "" {
+		stc.limited = reason
+	}
+}
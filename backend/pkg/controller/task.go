@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"sync"
@@ -12,6 +13,9 @@ import (
 	"pentagi/pkg/tools"
 )
 
+// planLimitMessage explains the task wrap up by the plan limits, it's formatted with the limit reason
+const planLimitMessage = "Planning is stopped because the %s, the task is wrapped up with the results of the executed subtasks"
+
 type FlowUpdater interface {
 	SetStatus(ctx context.Context, status database.FlowStatus) error
 }
@@ -28,8 +32,12 @@ type TaskWorker interface {
 	GetResult(ctx context.Context) (string, error)
 	SetResult(ctx context.Context, result string) error
 	PutInput(ctx context.Context, input string) error
+	Resume(ctx context.Context) error
 	Run(ctx context.Context) error
 	Finish(ctx context.Context) error
+	CancelSubtask(ctx context.Context, subtaskID int64) (bool, error)
+	SetSubtaskPriority(ctx context.Context, subtaskID int64, priority *int32) error
+	PlanLimitReason() string
 }
 
 type taskWorker struct {
@@ -278,6 +286,25 @@ func (tw *taskWorker) PutInput(ctx context.Context, input string) error {
 	return nil
 }
 
+// Resume makes the waiting subtask of the task which was paused by the server shutdown ready to run again
+func (tw *taskWorker) Resume(ctx context.Context) error {
+	if !tw.IsWaiting() {
+		return fmt.Errorf("task is not waiting")
+	}
+
+	for _, st := range tw.stc.ListSubtasks(ctx) {
+		if !st.IsCompleted() && st.IsWaiting() {
+			if err := st.Resume(ctx); err != nil {
+				return fmt.Errorf("failed to resume subtask %d: %w", st.GetSubtaskID(), err)
+			} else {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
 func (tw *taskWorker) Run(ctx context.Context) error {
 	ctx = tools.PutAgentContext(ctx, database.MsgchainTypePrimaryAgent)
 
@@ -310,6 +337,20 @@ func (tw *taskWorker) Run(ctx context.Context) error {
 		}
 	}
 
+	if reason := tw.stc.PlanLimitReason(); reason != "" {
+		// the event explains to the user why the task is reported before its plan is done
+		_, err := tw.taskCtx.MsgLog.PutTaskMsg(
+			ctx,
+			database.MsglogTypeAdvice,
+			tw.taskCtx.TaskID,
+			"", // thinking is empty because this is system message
+			fmt.Sprintf(planLimitMessage, reason),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to put plan limit message for task %d: %w", tw.taskCtx.TaskID, err)
+		}
+	}
+
 	jobResult, err := tw.taskCtx.Provider.GetTaskResult(ctx, tw.taskCtx.TaskID)
 	if err != nil {
 		return fmt.Errorf("failed to get task %d result: %w", tw.taskCtx.TaskID, err)
@@ -366,3 +407,87 @@ func (tw *taskWorker) Finish(ctx context.Context) error {
 
 	return nil
 }
+
+// CancelSubtask abandons the subtask of the task and marks it as failed, planned subtasks which
+// have no worker yet are failed in DB directly so they are skipped by the task run,
+// returns true if the task was waiting for input of the canceled subtask and has to be run again
+func (tw *taskWorker) CancelSubtask(ctx context.Context, subtaskID int64) (bool, error) {
+	var resume bool
+
+	st, err := tw.stc.GetSubtask(ctx, subtaskID)
+	switch {
+	case err == nil:
+		resume = st.IsWaiting()
+		if err := st.Cancel(ctx); err != nil {
+			return false, err
+		}
+	case errors.Is(err, ErrSubtaskNotFound):
+		subtask, err := tw.taskCtx.DB.GetSubtask(ctx, subtaskID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && subtask.TaskID != tw.taskCtx.TaskID) {
+			return false, fmt.Errorf("subtask %d: %w", subtaskID, ErrSubtaskNotFound)
+		} else if err != nil {
+			return false, fmt.Errorf("failed to get subtask %d: %w", subtaskID, err)
+		}
+
+		if subtask.Status != database.SubtaskStatusCreated {
+			return false, fmt.Errorf("subtask %d: %w", subtaskID, ErrSubtaskCompleted)
+		}
+
+		_, err = tw.taskCtx.DB.UpdateSubtaskResult(ctx, database.UpdateSubtaskResultParams{
+			Result: subtaskCanceledResult,
+			ID:     subtaskID,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to set subtask %d result: %w", subtaskID, err)
+		}
+
+		_, err = tw.taskCtx.DB.UpdateSubtaskStatus(ctx, database.UpdateSubtaskStatusParams{
+			Status: database.SubtaskStatusFailed,
+			ID:     subtaskID,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to set subtask %d status: %w", subtaskID, err)
+		}
+	default:
+		return false, err
+	}
+
+	if err := tw.publishUpdated(ctx); err != nil {
+		return false, err
+	}
+
+	return resume && tw.IsWaiting(), nil
+}
+
+// SetSubtaskPriority changes the order of the planned subtask of the task,
+// nil priority moves the subtask to run next
+func (tw *taskWorker) SetSubtaskPriority(ctx context.Context, subtaskID int64, priority *int32) error {
+	if err := tw.stc.SetSubtaskPriority(ctx, subtaskID, priority); err != nil {
+		return err
+	}
+
+	return tw.publishUpdated(ctx)
+}
+
+// PlanLimitReason returns the reason why the task planning was stopped by the plan limits of the flow
+// or empty string if the task wasn't restricted
+func (tw *taskWorker) PlanLimitReason() string {
+	return tw.stc.PlanLimitReason()
+}
+
+// publishUpdated notifies subscribers about the changes of the task subtasks
+func (tw *taskWorker) publishUpdated(ctx context.Context) error {
+	task, err := tw.taskCtx.DB.GetTask(ctx, tw.taskCtx.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task %d: %w", tw.taskCtx.TaskID, err)
+	}
+
+	subtasks, err := tw.taskCtx.DB.GetTaskSubtasks(ctx, tw.taskCtx.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task %d subtasks: %w", tw.taskCtx.TaskID, err)
+	}
+
+	tw.taskCtx.Publisher.TaskUpdated(ctx, task, subtasks)
+
+	return nil
+}
@@ -103,7 +103,7 @@ func (tc *taskController) GetTask(ctx context.Context, taskID int64) (TaskWorker
 
 	task, ok := tc.tasks[taskID]
 	if !ok {
-		return nil, fmt.Errorf("task %d not found", taskID)
+		return nil, fmt.Errorf("task %d: %w", taskID, ErrTaskNotFound)
 	}
 
 	return task, nil
@@ -12,10 +12,10 @@ import (
 
 const createContainer = `-- name: CreateContainer :one
 INSERT INTO containers (
-  type, name, image, status, flow_id, local_id, local_dir
+  type, name, image, status, flow_id, local_id, local_dir, cpu_limit, memory_limit, pids_limit
 )
 VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
 ON CONFLICT ON CONSTRAINT containers_local_id_unique
 DO UPDATE SET
@@ -24,18 +24,24 @@ DO UPDATE SET
   image = EXCLUDED.image,
   status = EXCLUDED.status,
   flow_id = EXCLUDED.flow_id,
-  local_dir = EXCLUDED.local_dir
-RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at
+  local_dir = EXCLUDED.local_dir,
+  cpu_limit = EXCLUDED.cpu_limit,
+  memory_limit = EXCLUDED.memory_limit,
+  pids_limit = EXCLUDED.pids_limit
+RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at, cpu_limit, memory_limit, pids_limit
 `
 
 type CreateContainerParams struct {
-	Type     ContainerType   `json:"type"`
-	Name     string          `json:"name"`
-	Image    string          `json:"image"`
-	Status   ContainerStatus `json:"status"`
-	FlowID   int64           `json:"flow_id"`
-	LocalID  sql.NullString  `json:"local_id"`
-	LocalDir sql.NullString  `json:"local_dir"`
+	Type        ContainerType   `json:"type"`
+	Name        string          `json:"name"`
+	Image       string          `json:"image"`
+	Status      ContainerStatus `json:"status"`
+	FlowID      int64           `json:"flow_id"`
+	LocalID     sql.NullString  `json:"local_id"`
+	LocalDir    sql.NullString  `json:"local_dir"`
+	CpuLimit    float64         `json:"cpu_limit"`
+	MemoryLimit int64           `json:"memory_limit"`
+	PidsLimit   int64           `json:"pids_limit"`
 }
 
 func (q *Queries) CreateContainer(ctx context.Context, arg CreateContainerParams) (Container, error) {
@@ -47,6 +53,9 @@ func (q *Queries) CreateContainer(ctx context.Context, arg CreateContainerParams
 		arg.FlowID,
 		arg.LocalID,
 		arg.LocalDir,
+		arg.CpuLimit,
+		arg.MemoryLimit,
+		arg.PidsLimit,
 	)
 	var i Container
 	err := row.Scan(
@@ -60,13 +69,16 @@ func (q *Queries) CreateContainer(ctx context.Context, arg CreateContainerParams
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
 
 const getContainers = `-- name: GetContainers :many
 SELECT
-  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
 FROM containers c
 INNER JOIN flows f ON c.flow_id = f.id
 WHERE f.deleted_at IS NULL
@@ -93,6 +105,9 @@ func (q *Queries) GetContainers(ctx context.Context) ([]Container, error) {
 			&i.FlowID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CpuLimit,
+			&i.MemoryLimit,
+			&i.PidsLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -109,7 +124,7 @@ func (q *Queries) GetContainers(ctx context.Context) ([]Container, error) {
 
 const getFlowContainers = `-- name: GetFlowContainers :many
 SELECT
-  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
 FROM containers c
 INNER JOIN flows f ON c.flow_id = f.id
 WHERE c.flow_id = $1 AND f.deleted_at IS NULL
@@ -136,6 +151,54 @@ func (q *Queries) GetFlowContainers(ctx context.Context, flowID int64) ([]Contai
 			&i.FlowID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CpuLimit,
+			&i.MemoryLimit,
+			&i.PidsLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFlowContainersUnscoped = `-- name: GetFlowContainersUnscoped :many
+SELECT
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
+FROM containers c
+WHERE c.flow_id = $1
+ORDER BY c.created_at DESC
+`
+
+func (q *Queries) GetFlowContainersUnscoped(ctx context.Context, flowID int64) ([]Container, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowContainersUnscoped, flowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Container
+	for rows.Next() {
+		var i Container
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Name,
+			&i.Image,
+			&i.Status,
+			&i.LocalID,
+			&i.LocalDir,
+			&i.FlowID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CpuLimit,
+			&i.MemoryLimit,
+			&i.PidsLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -152,7 +215,7 @@ func (q *Queries) GetFlowContainers(ctx context.Context, flowID int64) ([]Contai
 
 const getFlowPrimaryContainer = `-- name: GetFlowPrimaryContainer :one
 SELECT
-  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
 FROM containers c
 INNER JOIN flows f ON c.flow_id = f.id
 WHERE c.flow_id = $1 AND c.type = 'primary' AND f.deleted_at IS NULL
@@ -174,13 +237,16 @@ func (q *Queries) GetFlowPrimaryContainer(ctx context.Context, flowID int64) (Co
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
 
 const getRunningContainers = `-- name: GetRunningContainers :many
 SELECT
-  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
 FROM containers c
 INNER JOIN flows f ON c.flow_id = f.id
 WHERE c.status = 'running' AND f.deleted_at IS NULL
@@ -207,6 +273,9 @@ func (q *Queries) GetRunningContainers(ctx context.Context) ([]Container, error)
 			&i.FlowID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CpuLimit,
+			&i.MemoryLimit,
+			&i.PidsLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -223,7 +292,7 @@ func (q *Queries) GetRunningContainers(ctx context.Context) ([]Container, error)
 
 const getUserContainers = `-- name: GetUserContainers :many
 SELECT
-  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
 FROM containers c
 INNER JOIN flows f ON c.flow_id = f.id
 INNER JOIN users u ON f.user_id = u.id
@@ -251,6 +320,9 @@ func (q *Queries) GetUserContainers(ctx context.Context, userID int64) ([]Contai
 			&i.FlowID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CpuLimit,
+			&i.MemoryLimit,
+			&i.PidsLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -267,7 +339,7 @@ func (q *Queries) GetUserContainers(ctx context.Context, userID int64) ([]Contai
 
 const getUserFlowContainers = `-- name: GetUserFlowContainers :many
 SELECT
-  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at
+  c.id, c.type, c.name, c.image, c.status, c.local_id, c.local_dir, c.flow_id, c.created_at, c.updated_at, c.cpu_limit, c.memory_limit, c.pids_limit
 FROM containers c
 INNER JOIN flows f ON c.flow_id = f.id
 INNER JOIN users u ON f.user_id = u.id
@@ -300,6 +372,9 @@ func (q *Queries) GetUserFlowContainers(ctx context.Context, arg GetUserFlowCont
 			&i.FlowID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CpuLimit,
+			&i.MemoryLimit,
+			&i.PidsLimit,
 		); err != nil {
 			return nil, err
 		}
@@ -318,7 +393,7 @@ const updateContainerImage = `-- name: UpdateContainerImage :one
 UPDATE containers
 SET image = $1
 WHERE id = $2
-RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at
+RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at, cpu_limit, memory_limit, pids_limit
 `
 
 type UpdateContainerImageParams struct {
@@ -340,6 +415,9 @@ func (q *Queries) UpdateContainerImage(ctx context.Context, arg UpdateContainerI
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
@@ -348,7 +426,7 @@ const updateContainerLocalDir = `-- name: UpdateContainerLocalDir :one
 UPDATE containers
 SET local_dir = $1
 WHERE id = $2
-RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at
+RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at, cpu_limit, memory_limit, pids_limit
 `
 
 type UpdateContainerLocalDirParams struct {
@@ -370,6 +448,9 @@ func (q *Queries) UpdateContainerLocalDir(ctx context.Context, arg UpdateContain
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
@@ -378,7 +459,7 @@ const updateContainerLocalID = `-- name: UpdateContainerLocalID :one
 UPDATE containers
 SET local_id = $1
 WHERE id = $2
-RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at
+RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at, cpu_limit, memory_limit, pids_limit
 `
 
 type UpdateContainerLocalIDParams struct {
@@ -400,6 +481,9 @@ func (q *Queries) UpdateContainerLocalID(ctx context.Context, arg UpdateContaine
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
@@ -408,7 +492,7 @@ const updateContainerStatus = `-- name: UpdateContainerStatus :one
 UPDATE containers
 SET status = $1
 WHERE id = $2
-RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at
+RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at, cpu_limit, memory_limit, pids_limit
 `
 
 type UpdateContainerStatusParams struct {
@@ -430,6 +514,9 @@ func (q *Queries) UpdateContainerStatus(ctx context.Context, arg UpdateContainer
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
@@ -438,7 +525,7 @@ const updateContainerStatusLocalID = `-- name: UpdateContainerStatusLocalID :one
 UPDATE containers
 SET status = $1, local_id = $2
 WHERE id = $3
-RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at
+RETURNING id, type, name, image, status, local_id, local_dir, flow_id, created_at, updated_at, cpu_limit, memory_limit, pids_limit
 `
 
 type UpdateContainerStatusLocalIDParams struct {
@@ -461,6 +548,9 @@ func (q *Queries) UpdateContainerStatusLocalID(ctx context.Context, arg UpdateCo
 		&i.FlowID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CpuLimit,
+		&i.MemoryLimit,
+		&i.PidsLimit,
 	)
 	return i, err
 }
@@ -2,6 +2,7 @@ package converter
 
 import (
 	"encoding/json"
+	"sort"
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/model"
@@ -34,16 +35,42 @@ func ConvertFlow(flow database.Flow, containers []database.Container) *model.Flo
 		Type: model.ProviderType(flow.ModelProviderType),
 	}
 	return &model.Flow{
-		ID:        flow.ID,
-		Title:     flow.Title,
-		Status:    model.StatusType(flow.Status),
-		Terminals: ConvertContainers(containers),
-		Provider:  provider,
-		CreatedAt: flow.CreatedAt.Time,
-		UpdatedAt: flow.UpdatedAt.Time,
+		ID:              flow.ID,
+		Title:           flow.Title,
+		Status:          model.StatusType(flow.Status),
+		Terminals:       ConvertContainers(containers),
+		Provider:        provider,
+		CreatedAt:       flow.CreatedAt.Time,
+		UpdatedAt:       flow.UpdatedAt.Time,
+		StopReason:      database.NullStringToPtrString(flow.StopReason),
+		FinishReason:    convertFinishReason(flow.FinishReason),
+		AwaitingInput:   flow.AwaitingInput,
+		PendingQuestion: database.NullStringToPtrString(flow.PendingQuestion),
+		LastError:       convertFlowLastError(flow),
+	}
+}
+
+func convertFlowLastError(flow database.Flow) *model.FlowLastError {
+	if !flow.LastError.Valid {
+		return nil
+	}
+
+	return &model.FlowLastError{
+		Message:   flow.LastError.String,
+		Count:     int(flow.LastErrorCount),
+		Timestamp: flow.LastErrorAt.Time,
 	}
 }
 
+func convertFinishReason(reason database.NullFlowFinishReason) *string {
+	if !reason.Valid {
+		return nil
+	}
+
+	value := string(reason.FlowFinishReason)
+	return &value
+}
+
 func ConvertContainers(containers []database.Container) []*model.Terminal {
 	gcontainers := make([]*model.Terminal, 0, len(containers))
 	for _, container := range containers {
@@ -530,11 +557,31 @@ func ConvertProvider(prv database.Provider, cfg *pconfig.ProviderConfig) *model.
 		Name:      prv.Name,
 		Type:      model.ProviderType(prv.Type),
 		Agents:    ConvertProviderConfigToGqlModel(cfg),
+		Endpoint:  convertProviderEndpoint(cfg.GetEndpoint()),
 		CreatedAt: prv.CreatedAt.Time,
 		UpdatedAt: prv.UpdatedAt.Time,
 	}
 }
 
+// convertProviderEndpoint returns only the header names because the values usually contain credentials
+func convertProviderEndpoint(endpoint *pconfig.EndpointConfig) *model.ProviderEndpoint {
+	if endpoint == nil {
+		return nil
+	}
+
+	headerNames := make([]string, 0, len(endpoint.Headers))
+	for name := range endpoint.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	return &model.ProviderEndpoint{
+		BaseURL:            endpoint.BaseURL,
+		HeaderNames:        headerNames,
+		InsecureSkipVerify: endpoint.InsecureSkipVerify,
+	}
+}
+
 func ConvertProviderConfigToGqlModel(cfg *pconfig.ProviderConfig) *model.AgentsConfig {
 	if cfg == nil {
 		return nil
@@ -621,6 +668,13 @@ func ConvertAgentConfigToGqlModel(ac *pconfig.AgentConfig) *model.AgentConfig {
 }
 
 func ConvertAgentsConfigFromGqlModel(cfg *model.AgentsConfig) *pconfig.ProviderConfig {
+	return ConvertProviderConfigFromGqlModel(cfg, nil)
+}
+
+func ConvertProviderConfigFromGqlModel(
+	cfg *model.AgentsConfig,
+	endpoint *model.ProviderEndpointInput,
+) *pconfig.ProviderConfig {
 	if cfg == nil {
 		return nil
 	}
@@ -639,6 +693,7 @@ func ConvertAgentsConfigFromGqlModel(cfg *model.AgentsConfig) *pconfig.ProviderC
 		Coder:        ConvertAgentConfigFromGqlModel(cfg.Coder),
 		Installer:    ConvertAgentConfigFromGqlModel(cfg.Installer),
 		Pentester:    ConvertAgentConfigFromGqlModel(cfg.Pentester),
+		Endpoint:     convertProviderEndpointFromGqlModel(endpoint),
 	}
 
 	rawConfig, err := json.Marshal(pc)
@@ -651,6 +706,27 @@ func ConvertAgentsConfigFromGqlModel(cfg *model.AgentsConfig) *pconfig.ProviderC
 	return pc
 }
 
+func convertProviderEndpointFromGqlModel(endpoint *model.ProviderEndpointInput) *pconfig.EndpointConfig {
+	if endpoint == nil {
+		return nil
+	}
+
+	result := &pconfig.EndpointConfig{
+		BaseURL:            endpoint.BaseURL,
+		InsecureSkipVerify: endpoint.InsecureSkipVerify != nil && *endpoint.InsecureSkipVerify,
+	}
+	if len(endpoint.Headers) != 0 {
+		result.Headers = make(map[string]string, len(endpoint.Headers))
+		for _, header := range endpoint.Headers {
+			if header != nil {
+				result.Headers[header.Name] = header.Value
+			}
+		}
+	}
+
+	return result
+}
+
 func ConvertAgentConfigFromGqlModel(ac *model.AgentConfig) *pconfig.AgentConfig {
 	if ac == nil {
 		return nil
@@ -1,7 +1,11 @@
 package converter
 
 import (
+	"database/sql"
 	"testing"
+	"time"
+
+	"pentagi/pkg/database"
 )
 
 func TestIsAgentTool(t *testing.T) {
@@ -56,3 +60,59 @@ func TestIsAgentTool(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertFlowFinishReason(t *testing.T) {
+	flow := ConvertFlow(database.Flow{ID: 1, Status: database.FlowStatusWaiting}, nil)
+	if flow.FinishReason != nil {
+		t.Errorf("expected empty finish reason, got %q", *flow.FinishReason)
+	}
+
+	flow = ConvertFlow(database.Flow{
+		ID:     1,
+		Status: database.FlowStatusWaiting,
+		FinishReason: database.NullFlowFinishReason{
+			FlowFinishReason: database.FlowFinishReasonBudgetExceeded,
+			Valid:            true,
+		},
+	}, nil)
+	if flow.FinishReason == nil || *flow.FinishReason != "budget_exceeded" {
+		t.Errorf("expected budget_exceeded finish reason, got %v", flow.FinishReason)
+	}
+}
+
+func TestConvertFlowPendingQuestion(t *testing.T) {
+	flow := ConvertFlow(database.Flow{ID: 1, Status: database.FlowStatusRunning}, nil)
+	if flow.AwaitingInput || flow.PendingQuestion != nil {
+		t.Errorf("expected flow not awaiting input, got %v %v", flow.AwaitingInput, flow.PendingQuestion)
+	}
+
+	flow = ConvertFlow(database.Flow{
+		ID:              1,
+		Status:          database.FlowStatusWaiting,
+		AwaitingInput:   true,
+		PendingQuestion: database.StringToNullString("Which host is in scope?"),
+	}, nil)
+	if !flow.AwaitingInput || flow.PendingQuestion == nil || *flow.PendingQuestion != "Which host is in scope?" {
+		t.Errorf("expected pending question, got %v %v", flow.AwaitingInput, flow.PendingQuestion)
+	}
+}
+
+func TestConvertFlowLastError(t *testing.T) {
+	flow := ConvertFlow(database.Flow{ID: 1, Status: database.FlowStatusRunning}, nil)
+	if flow.LastError != nil {
+		t.Errorf("expected no last error, got %v", flow.LastError)
+	}
+
+	at := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	flow = ConvertFlow(database.Flow{
+		ID:             1,
+		Status:         database.FlowStatusRunning,
+		LastError:      database.StringToNullString("rate limited"),
+		LastErrorCount: 5,
+		LastErrorAt:    sql.NullTime{Time: at, Valid: true},
+	}, nil)
+	if flow.LastError == nil || flow.LastError.Message != "rate limited" || flow.LastError.Count != 5 ||
+		!flow.LastError.Timestamp.Equal(at) {
+		t.Errorf("expected last error 'rate limited' x5 at %v, got %+v", at, flow.LastError)
+	}
+}
@@ -53,6 +53,34 @@ func NullInt64ToInt64(i sql.NullInt64) *int64 {
 	return nil
 }
 
+func Int32ToNullInt32(i *int32) sql.NullInt32 {
+	if i == nil {
+		return sql.NullInt32{Valid: false}
+	}
+	return sql.NullInt32{Int32: *i, Valid: true}
+}
+
+func NullInt32ToInt32(i sql.NullInt32) *int32 {
+	if i.Valid {
+		return &i.Int32
+	}
+	return nil
+}
+
+func Float64ToNullFloat64(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{Valid: false}
+	}
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
+
+func NullFloat64ToFloat64(f sql.NullFloat64) *float64 {
+	if f.Valid {
+		return &f.Float64
+	}
+	return nil
+}
+
 func TimeToNullTime(t time.Time) sql.NullTime {
 	return sql.NullTime{Time: t, Valid: !t.IsZero()}
 }
@@ -14,24 +14,40 @@ import (
 
 const createFlow = `-- name: CreateFlow :one
 INSERT INTO flows (
-  title, status, model, model_provider_name, model_provider_type, language, tool_call_id_template, functions, user_id
+  title, status, model, model_provider_name, model_provider_type, language, tool_call_id_template, functions, user_id, fallback_providers,
+  budget_max_cost, budget_max_tokens, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, system_prompt,
+  plan_max_subtasks, plan_max_depth, command_policy, scope, result_max_size, result_max_source_size
 )
 VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 )
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 type CreateFlowParams struct {
-	Title              string          `json:"title"`
-	Status             FlowStatus      `json:"status"`
-	Model              string          `json:"model"`
-	ModelProviderName  string          `json:"model_provider_name"`
-	ModelProviderType  ProviderType    `json:"model_provider_type"`
-	Language           string          `json:"language"`
-	ToolCallIDTemplate string          `json:"tool_call_id_template"`
-	Functions          json.RawMessage `json:"functions"`
-	UserID             int64           `json:"user_id"`
+	Title               string          `json:"title"`
+	Status              FlowStatus      `json:"status"`
+	Model               string          `json:"model"`
+	ModelProviderName   string          `json:"model_provider_name"`
+	ModelProviderType   ProviderType    `json:"model_provider_type"`
+	Language            string          `json:"language"`
+	ToolCallIDTemplate  string          `json:"tool_call_id_template"`
+	Functions           json.RawMessage `json:"functions"`
+	UserID              int64           `json:"user_id"`
+	FallbackProviders   json.RawMessage `json:"fallback_providers"`
+	BudgetMaxCost       sql.NullFloat64 `json:"budget_max_cost"`
+	BudgetMaxTokens     sql.NullInt64   `json:"budget_max_tokens"`
+	ToolCallsPerMinute  sql.NullInt32   `json:"tool_calls_per_minute"`
+	ToolCallsMax        sql.NullInt64   `json:"tool_calls_max"`
+	ResponseCache       sql.NullString  `json:"response_cache"`
+	ProviderTimeout     sql.NullInt32   `json:"provider_timeout"`
+	SystemPrompt        sql.NullString  `json:"system_prompt"`
+	PlanMaxSubtasks     sql.NullInt32   `json:"plan_max_subtasks"`
+	PlanMaxDepth        sql.NullInt32   `json:"plan_max_depth"`
+	CommandPolicy       json.RawMessage `json:"command_policy"`
+	Scope               json.RawMessage `json:"scope"`
+	ResultMaxSize       sql.NullInt32   `json:"result_max_size"`
+	ResultMaxSourceSize sql.NullInt32   `json:"result_max_source_size"`
 }
 
 func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, error) {
@@ -45,6 +61,20 @@ func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, e
 		arg.ToolCallIDTemplate,
 		arg.Functions,
 		arg.UserID,
+		arg.FallbackProviders,
+		arg.BudgetMaxCost,
+		arg.BudgetMaxTokens,
+		arg.ToolCallsPerMinute,
+		arg.ToolCallsMax,
+		arg.ResponseCache,
+		arg.ProviderTimeout,
+		arg.SystemPrompt,
+		arg.PlanMaxSubtasks,
+		arg.PlanMaxDepth,
+		arg.CommandPolicy,
+		arg.Scope,
+		arg.ResultMaxSize,
+		arg.ResultMaxSourceSize,
 	)
 	var i Flow
 	err := row.Scan(
@@ -62,6 +92,28 @@ func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, e
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -70,7 +122,7 @@ const deleteFlow = `-- name: DeleteFlow :one
 UPDATE flows
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 func (q *Queries) DeleteFlow(ctx context.Context, id int64) (Flow, error) {
@@ -91,13 +143,110 @@ func (q *Queries) DeleteFlow(ctx context.Context, id int64) (Flow, error) {
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
 
+const getDeletedFlowsToPurge = `-- name: GetDeletedFlowsToPurge :many
+SELECT
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.fallback_providers, f.description, f.budget_max_cost, f.budget_max_tokens, f.stop_reason, f.tool_calls_per_minute, f.tool_calls_max, f.response_cache, f.provider_timeout, f.finish_reason, f.awaiting_input, f.pending_question, f.system_prompt, f.plan_max_subtasks, f.plan_max_depth, f.last_error, f.last_error_count, f.last_error_at, f.command_policy, f.scope, f.result_max_size, f.result_max_source_size
+FROM flows f
+WHERE f.deleted_at IS NOT NULL
+  AND f.deleted_at < NOW() - ($1::bigint * INTERVAL '1 second')
+  AND (
+    EXISTS (SELECT 1 FROM containers c WHERE c.flow_id = f.id AND c.status <> 'deleted')
+    OR EXISTS (SELECT 1 FROM container_snapshots cs WHERE cs.flow_id = f.id)
+    OR EXISTS (SELECT 1 FROM flow_attachments fa WHERE fa.flow_id = f.id)
+    OR EXISTS (SELECT 1 FROM flow_artifacts fr WHERE fr.flow_id = f.id)
+  )
+ORDER BY f.deleted_at ASC
+`
+
+func (q *Queries) GetDeletedFlowsToPurge(ctx context.Context, retentionSeconds int64) ([]Flow, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedFlowsToPurge, retentionSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Flow
+	for rows.Next() {
+		var i Flow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Title,
+			&i.Model,
+			&i.ModelProviderName,
+			&i.Language,
+			&i.Functions,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.TraceID,
+			&i.ModelProviderType,
+			&i.ToolCallIDTemplate,
+			&i.FallbackProviders,
+			&i.Description,
+			&i.BudgetMaxCost,
+			&i.BudgetMaxTokens,
+			&i.StopReason,
+			&i.ToolCallsPerMinute,
+			&i.ToolCallsMax,
+			&i.ResponseCache,
+			&i.ProviderTimeout,
+			&i.FinishReason,
+			&i.AwaitingInput,
+			&i.PendingQuestion,
+			&i.SystemPrompt,
+			&i.PlanMaxSubtasks,
+			&i.PlanMaxDepth,
+			&i.LastError,
+			&i.LastErrorCount,
+			&i.LastErrorAt,
+			&i.CommandPolicy,
+			&i.Scope,
+			&i.ResultMaxSize,
+			&i.ResultMaxSourceSize,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFlow = `-- name: GetFlow :one
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.fallback_providers, f.description, f.budget_max_cost, f.budget_max_tokens, f.stop_reason, f.tool_calls_per_minute, f.tool_calls_max, f.response_cache, f.provider_timeout, f.finish_reason, f.awaiting_input, f.pending_question, f.system_prompt, f.plan_max_subtasks, f.plan_max_depth, f.last_error, f.last_error_count, f.last_error_at, f.command_policy, f.scope, f.result_max_size, f.result_max_source_size
 FROM flows f
 WHERE f.id = $1 AND f.deleted_at IS NULL
 `
@@ -120,6 +269,28 @@ func (q *Queries) GetFlow(ctx context.Context, id int64) (Flow, error) {
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -154,7 +325,7 @@ func (q *Queries) GetFlowStats(ctx context.Context, id int64) (GetFlowStatsRow,
 
 const getFlows = `-- name: GetFlows :many
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.fallback_providers, f.description, f.budget_max_cost, f.budget_max_tokens, f.stop_reason, f.tool_calls_per_minute, f.tool_calls_max, f.response_cache, f.provider_timeout, f.finish_reason, f.awaiting_input, f.pending_question, f.system_prompt, f.plan_max_subtasks, f.plan_max_depth, f.last_error, f.last_error_count, f.last_error_at, f.command_policy, f.scope, f.result_max_size, f.result_max_source_size
 FROM flows f
 WHERE f.deleted_at IS NULL
 ORDER BY f.created_at DESC
@@ -184,6 +355,28 @@ func (q *Queries) GetFlows(ctx context.Context) ([]Flow, error) {
 			&i.TraceID,
 			&i.ModelProviderType,
 			&i.ToolCallIDTemplate,
+			&i.FallbackProviders,
+			&i.Description,
+			&i.BudgetMaxCost,
+			&i.BudgetMaxTokens,
+			&i.StopReason,
+			&i.ToolCallsPerMinute,
+			&i.ToolCallsMax,
+			&i.ResponseCache,
+			&i.ProviderTimeout,
+			&i.FinishReason,
+			&i.AwaitingInput,
+			&i.PendingQuestion,
+			&i.SystemPrompt,
+			&i.PlanMaxSubtasks,
+			&i.PlanMaxDepth,
+			&i.LastError,
+			&i.LastErrorCount,
+			&i.LastErrorAt,
+			&i.CommandPolicy,
+			&i.Scope,
+			&i.ResultMaxSize,
+			&i.ResultMaxSourceSize,
 		); err != nil {
 			return nil, err
 		}
@@ -360,9 +553,40 @@ func (q *Queries) GetFlowsStatsByDayLastWeek(ctx context.Context, userID int64)
 	return items, nil
 }
 
+const getRetainedFlowIDs = `-- name: GetRetainedFlowIDs :many
+SELECT
+  f.id
+FROM flows f
+WHERE f.deleted_at IS NULL
+  OR EXISTS (SELECT 1 FROM containers c WHERE c.flow_id = f.id AND c.status <> 'deleted')
+`
+
+func (q *Queries) GetRetainedFlowIDs(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getRetainedFlowIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserFlow = `-- name: GetUserFlow :one
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.fallback_providers, f.description, f.budget_max_cost, f.budget_max_tokens, f.stop_reason, f.tool_calls_per_minute, f.tool_calls_max, f.response_cache, f.provider_timeout, f.finish_reason, f.awaiting_input, f.pending_question, f.system_prompt, f.plan_max_subtasks, f.plan_max_depth, f.last_error, f.last_error_count, f.last_error_at, f.command_policy, f.scope, f.result_max_size, f.result_max_source_size
 FROM flows f
 INNER JOIN users u ON f.user_id = u.id
 WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
@@ -391,13 +615,35 @@ func (q *Queries) GetUserFlow(ctx context.Context, arg GetUserFlowParams) (Flow,
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
 
 const getUserFlows = `-- name: GetUserFlows :many
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.fallback_providers, f.description, f.budget_max_cost, f.budget_max_tokens, f.stop_reason, f.tool_calls_per_minute, f.tool_calls_max, f.response_cache, f.provider_timeout, f.finish_reason, f.awaiting_input, f.pending_question, f.system_prompt, f.plan_max_subtasks, f.plan_max_depth, f.last_error, f.last_error_count, f.last_error_at, f.command_policy, f.scope, f.result_max_size, f.result_max_source_size
 FROM flows f
 INNER JOIN users u ON f.user_id = u.id
 WHERE f.user_id = $1 AND f.deleted_at IS NULL
@@ -428,6 +674,28 @@ func (q *Queries) GetUserFlows(ctx context.Context, userID int64) ([]Flow, error
 			&i.TraceID,
 			&i.ModelProviderType,
 			&i.ToolCallIDTemplate,
+			&i.FallbackProviders,
+			&i.Description,
+			&i.BudgetMaxCost,
+			&i.BudgetMaxTokens,
+			&i.StopReason,
+			&i.ToolCallsPerMinute,
+			&i.ToolCallsMax,
+			&i.ResponseCache,
+			&i.ProviderTimeout,
+			&i.FinishReason,
+			&i.AwaitingInput,
+			&i.PendingQuestion,
+			&i.SystemPrompt,
+			&i.PlanMaxSubtasks,
+			&i.PlanMaxDepth,
+			&i.LastError,
+			&i.LastErrorCount,
+			&i.LastErrorAt,
+			&i.CommandPolicy,
+			&i.Scope,
+			&i.ResultMaxSize,
+			&i.ResultMaxSourceSize,
 		); err != nil {
 			return nil, err
 		}
@@ -442,6 +710,20 @@ func (q *Queries) GetUserFlows(ctx context.Context, userID int64) ([]Flow, error
 	return items, nil
 }
 
+const getUserRunningFlowsCount = `-- name: GetUserRunningFlowsCount :one
+SELECT
+  COUNT(f.id)::bigint AS running_flows_count
+FROM flows f
+WHERE f.user_id = $1 AND f.status = 'running' AND f.deleted_at IS NULL
+`
+
+func (q *Queries) GetUserRunningFlowsCount(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserRunningFlowsCount, userID)
+	var running_flows_count int64
+	err := row.Scan(&running_flows_count)
+	return running_flows_count, err
+}
+
 const getUserTotalFlowsStats = `-- name: GetUserTotalFlowsStats :one
 SELECT
   COALESCE(COUNT(DISTINCT f.id), 0)::bigint AS total_flows_count,
@@ -475,11 +757,62 @@ func (q *Queries) GetUserTotalFlowsStats(ctx context.Context, userID int64) (Get
 	return i, err
 }
 
+const resetFlowLastError = `-- name: ResetFlowLastError :one
+UPDATE flows
+SET last_error = NULL, last_error_count = 0, last_error_at = NULL
+WHERE id = $1
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+func (q *Queries) ResetFlowLastError(ctx context.Context, id int64) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, resetFlowLastError, id)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
 const updateFlow = `-- name: UpdateFlow :one
 UPDATE flows
 SET title = $1, model = $2, language = $3, tool_call_id_template = $4, functions = $5, trace_id = $6
 WHERE id = $7
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 type UpdateFlowParams struct {
@@ -518,6 +851,85 @@ func (q *Queries) UpdateFlow(ctx context.Context, arg UpdateFlowParams) (Flow, e
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowBudget = `-- name: UpdateFlowBudget :one
+UPDATE flows
+SET budget_max_cost = $1, budget_max_tokens = $2, stop_reason = NULL
+WHERE id = $3
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowBudgetParams struct {
+	BudgetMaxCost   sql.NullFloat64 `json:"budget_max_cost"`
+	BudgetMaxTokens sql.NullInt64   `json:"budget_max_tokens"`
+	ID              int64           `json:"id"`
+}
+
+func (q *Queries) UpdateFlowBudget(ctx context.Context, arg UpdateFlowBudgetParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowBudget, arg.BudgetMaxCost, arg.BudgetMaxTokens, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -526,7 +938,7 @@ const updateFlowLanguage = `-- name: UpdateFlowLanguage :one
 UPDATE flows
 SET language = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 type UpdateFlowLanguageParams struct {
@@ -552,6 +964,329 @@ func (q *Queries) UpdateFlowLanguage(ctx context.Context, arg UpdateFlowLanguage
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowLastError = `-- name: UpdateFlowLastError :one
+UPDATE flows
+SET
+  last_error_count = CASE WHEN last_error = $1 THEN last_error_count + 1 ELSE 1 END,
+  last_error = $1,
+  last_error_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowLastErrorParams struct {
+	LastError sql.NullString `json:"last_error"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFlowLastError(ctx context.Context, arg UpdateFlowLastErrorParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowLastError, arg.LastError, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowFinishReason = `-- name: UpdateFlowFinishReason :one
+UPDATE flows
+SET finish_reason = $1
+WHERE id = $2
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowFinishReasonParams struct {
+	FinishReason NullFlowFinishReason `json:"finish_reason"`
+	ID           int64                `json:"id"`
+}
+
+func (q *Queries) UpdateFlowFinishReason(ctx context.Context, arg UpdateFlowFinishReasonParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowFinishReason, arg.FinishReason, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowMetadata = `-- name: UpdateFlowMetadata :one
+UPDATE flows
+SET
+  title = COALESCE($1, title),
+  language = COALESCE($2, language),
+  description = COALESCE($3, description)
+WHERE id = $4
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowMetadataParams struct {
+	Title       sql.NullString `json:"title"`
+	Language    sql.NullString `json:"language"`
+	Description sql.NullString `json:"description"`
+	ID          int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFlowMetadata(ctx context.Context, arg UpdateFlowMetadataParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowMetadata,
+		arg.Title,
+		arg.Language,
+		arg.Description,
+		arg.ID,
+	)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowPendingQuestion = `-- name: UpdateFlowPendingQuestion :one
+UPDATE flows
+SET awaiting_input = $1, pending_question = $2
+WHERE id = $3
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowPendingQuestionParams struct {
+	AwaitingInput   bool           `json:"awaiting_input"`
+	PendingQuestion sql.NullString `json:"pending_question"`
+	ID              int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFlowPendingQuestion(ctx context.Context, arg UpdateFlowPendingQuestionParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowPendingQuestion, arg.AwaitingInput, arg.PendingQuestion, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowProvider = `-- name: UpdateFlowProvider :one
+UPDATE flows
+SET model_provider_name = $1, model_provider_type = $2, model = $3
+WHERE id = $4
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowProviderParams struct {
+	ModelProviderName string       `json:"model_provider_name"`
+	ModelProviderType ProviderType `json:"model_provider_type"`
+	Model             string       `json:"model"`
+	ID                int64        `json:"id"`
+}
+
+func (q *Queries) UpdateFlowProvider(ctx context.Context, arg UpdateFlowProviderParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowProvider,
+		arg.ModelProviderName,
+		arg.ModelProviderType,
+		arg.Model,
+		arg.ID,
+	)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -560,7 +1295,7 @@ const updateFlowStatus = `-- name: UpdateFlowStatus :one
 UPDATE flows
 SET status = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 type UpdateFlowStatusParams struct {
@@ -586,6 +1321,84 @@ func (q *Queries) UpdateFlowStatus(ctx context.Context, arg UpdateFlowStatusPara
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
+	)
+	return i, err
+}
+
+const updateFlowStopReason = `-- name: UpdateFlowStopReason :one
+UPDATE flows
+SET stop_reason = $1
+WHERE id = $2
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
+`
+
+type UpdateFlowStopReasonParams struct {
+	StopReason sql.NullString `json:"stop_reason"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFlowStopReason(ctx context.Context, arg UpdateFlowStopReasonParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowStopReason, arg.StopReason, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -594,7 +1407,7 @@ const updateFlowTitle = `-- name: UpdateFlowTitle :one
 UPDATE flows
 SET title = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 type UpdateFlowTitleParams struct {
@@ -620,6 +1433,28 @@ func (q *Queries) UpdateFlowTitle(ctx context.Context, arg UpdateFlowTitleParams
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -628,7 +1463,7 @@ const updateFlowToolCallIDTemplate = `-- name: UpdateFlowToolCallIDTemplate :one
 UPDATE flows
 SET tool_call_id_template = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, fallback_providers, description, budget_max_cost, budget_max_tokens, stop_reason, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, finish_reason, awaiting_input, pending_question, system_prompt, plan_max_subtasks, plan_max_depth, last_error, last_error_count, last_error_at, command_policy, scope, result_max_size, result_max_source_size
 `
 
 type UpdateFlowToolCallIDTemplateParams struct {
@@ -654,6 +1489,28 @@ func (q *Queries) UpdateFlowToolCallIDTemplate(ctx context.Context, arg UpdateFl
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.FallbackProviders,
+		&i.Description,
+		&i.BudgetMaxCost,
+		&i.BudgetMaxTokens,
+		&i.StopReason,
+		&i.ToolCallsPerMinute,
+		&i.ToolCallsMax,
+		&i.ResponseCache,
+		&i.ProviderTimeout,
+		&i.FinishReason,
+		&i.AwaitingInput,
+		&i.PendingQuestion,
+		&i.SystemPrompt,
+		&i.PlanMaxSubtasks,
+		&i.PlanMaxDepth,
+		&i.LastError,
+		&i.LastErrorCount,
+		&i.LastErrorAt,
+		&i.CommandPolicy,
+		&i.Scope,
+		&i.ResultMaxSize,
+		&i.ResultMaxSourceSize,
 	)
 	return i, err
 }
@@ -9,6 +9,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type AssistantStatus string
@@ -143,6 +144,97 @@ func (ns NullContainerType) Value() (driver.Value, error) {
 	return string(ns.ContainerType), nil
 }
 
+type FlowFinishReason string
+
+const (
+	FlowFinishReasonUserStop          FlowFinishReason = "user_stop"
+	FlowFinishReasonUserFinish        FlowFinishReason = "user_finish"
+	FlowFinishReasonCompleted         FlowFinishReason = "completed"
+	FlowFinishReasonBudgetExceeded    FlowFinishReason = "budget_exceeded"
+	FlowFinishReasonError             FlowFinishReason = "error"
+	FlowFinishReasonProviderExhausted FlowFinishReason = "provider_exhausted"
+	FlowFinishReasonPlanLimitExceeded FlowFinishReason = "plan_limit_exceeded"
+)
+
+func (e *FlowFinishReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FlowFinishReason(s)
+	case string:
+		*e = FlowFinishReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FlowFinishReason: %T", src)
+	}
+	return nil
+}
+
+type NullFlowFinishReason struct {
+	FlowFinishReason FlowFinishReason `json:"flow_finish_reason"`
+	Valid            bool             `json:"valid"` // Valid is true if FlowFinishReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFlowFinishReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.FlowFinishReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FlowFinishReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFlowFinishReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FlowFinishReason), nil
+}
+
+type FlowInputStatus string
+
+const (
+	FlowInputStatusQueued   FlowInputStatus = "queued"
+	FlowInputStatusConsumed FlowInputStatus = "consumed"
+	FlowInputStatusFailed   FlowInputStatus = "failed"
+	FlowInputStatusCanceled FlowInputStatus = "canceled"
+)
+
+func (e *FlowInputStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FlowInputStatus(s)
+	case string:
+		*e = FlowInputStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FlowInputStatus: %T", src)
+	}
+	return nil
+}
+
+type NullFlowInputStatus struct {
+	FlowInputStatus FlowInputStatus `json:"flow_input_status"`
+	Valid           bool            `json:"valid"` // Valid is true if FlowInputStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFlowInputStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.FlowInputStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FlowInputStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFlowInputStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FlowInputStatus), nil
+}
+
 type FlowStatus string
 
 const (
@@ -429,6 +521,7 @@ const (
 	ProviderTypeGlm       ProviderType = "glm"
 	ProviderTypeKimi      ProviderType = "kimi"
 	ProviderTypeQwen      ProviderType = "qwen"
+	ProviderTypeMock      ProviderType = "mock"
 )
 
 func (e *ProviderType) Scan(src interface{}) error {
@@ -469,14 +562,26 @@ func (ns NullProviderType) Value() (driver.Value, error) {
 type SearchengineType string
 
 const (
-	SearchengineTypeGoogle     SearchengineType = "google"
-	SearchengineTypeTavily     SearchengineType = "tavily"
-	SearchengineTypeTraversaal SearchengineType = "traversaal"
-	SearchengineTypeBrowser    SearchengineType = "browser"
-	SearchengineTypeDuckduckgo SearchengineType = "duckduckgo"
-	SearchengineTypePerplexity SearchengineType = "perplexity"
-	SearchengineTypeSearxng    SearchengineType = "searxng"
-	SearchengineTypeSploitus   SearchengineType = "sploitus"
+	SearchengineTypeGoogle        SearchengineType = "google"
+	SearchengineTypeTavily        SearchengineType = "tavily"
+	SearchengineTypeTraversaal    SearchengineType = "traversaal"
+	SearchengineTypeBrowser       SearchengineType = "browser"
+	SearchengineTypeDuckduckgo    SearchengineType = "duckduckgo"
+	SearchengineTypePerplexity    SearchengineType = "perplexity"
+	SearchengineTypeSearxng       SearchengineType = "searxng"
+	SearchengineTypeSploitus      SearchengineType = "sploitus"
+	SearchengineTypeGithub        SearchengineType = "github"
+	SearchengineTypeExploitdb     SearchengineType = "exploitdb"
+	SearchengineTypeExploitSearch SearchengineType = "exploit_search"
+	SearchengineTypeVirustotal    SearchengineType = "virustotal"
+	SearchengineTypeWayback       SearchengineType = "wayback"
+	SearchengineTypeWhois         SearchengineType = "whois"
+	SearchengineTypeEpss          SearchengineType = "epss"
+	SearchengineTypeRobots        SearchengineType = "robots"
+	SearchengineTypeHttpHeaders   SearchengineType = "http_headers"
+	SearchengineTypeTlsInspect    SearchengineType = "tls_inspect"
+	SearchengineTypePortScan      SearchengineType = "port_scan"
+	SearchengineTypeHttpFuzz      SearchengineType = "http_fuzz"
 )
 
 func (e *SearchengineType) Scan(src interface{}) error {
@@ -916,34 +1021,154 @@ type Assistantlog struct {
 	Thinking     sql.NullString     `json:"thinking"`
 }
 
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	UserID    int64           `json:"user_id"`
+	Action    string          `json:"action"`
+	FlowID    int64           `json:"flow_id"`
+	Changes   json.RawMessage `json:"changes"`
+	Metadata  json.RawMessage `json:"metadata"`
+	CreatedAt sql.NullTime    `json:"created_at"`
+}
+
 type Container struct {
+	ID          int64           `json:"id"`
+	Type        ContainerType   `json:"type"`
+	Name        string          `json:"name"`
+	Image       string          `json:"image"`
+	Status      ContainerStatus `json:"status"`
+	LocalID     sql.NullString  `json:"local_id"`
+	LocalDir    sql.NullString  `json:"local_dir"`
+	FlowID      int64           `json:"flow_id"`
+	CreatedAt   sql.NullTime    `json:"created_at"`
+	UpdatedAt   sql.NullTime    `json:"updated_at"`
+	CpuLimit    float64         `json:"cpu_limit"`
+	MemoryLimit int64           `json:"memory_limit"`
+	PidsLimit   int64           `json:"pids_limit"`
+}
+
+type ContainerSnapshot struct {
+	ID          int64        `json:"id"`
+	Image       string       `json:"image"`
+	FlowID      int64        `json:"flow_id"`
+	ContainerID int64        `json:"container_id"`
+	UserID      int64        `json:"user_id"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type Flow struct {
+	ID                  int64                `json:"id"`
+	Status              FlowStatus           `json:"status"`
+	Title               string               `json:"title"`
+	Model               string               `json:"model"`
+	ModelProviderName   string               `json:"model_provider_name"`
+	Language            string               `json:"language"`
+	Functions           json.RawMessage      `json:"functions"`
+	UserID              int64                `json:"user_id"`
+	CreatedAt           sql.NullTime         `json:"created_at"`
+	UpdatedAt           sql.NullTime         `json:"updated_at"`
+	DeletedAt           sql.NullTime         `json:"deleted_at"`
+	TraceID             sql.NullString       `json:"trace_id"`
+	ModelProviderType   ProviderType         `json:"model_provider_type"`
+	ToolCallIDTemplate  string               `json:"tool_call_id_template"`
+	FallbackProviders   json.RawMessage      `json:"fallback_providers"`
+	Description         string               `json:"description"`
+	BudgetMaxCost       sql.NullFloat64      `json:"budget_max_cost"`
+	BudgetMaxTokens     sql.NullInt64        `json:"budget_max_tokens"`
+	StopReason          sql.NullString       `json:"stop_reason"`
+	ToolCallsPerMinute  sql.NullInt32        `json:"tool_calls_per_minute"`
+	ToolCallsMax        sql.NullInt64        `json:"tool_calls_max"`
+	ResponseCache       sql.NullString       `json:"response_cache"`
+	ProviderTimeout     sql.NullInt32        `json:"provider_timeout"`
+	FinishReason        NullFlowFinishReason `json:"finish_reason"`
+	AwaitingInput       bool                 `json:"awaiting_input"`
+	PendingQuestion     sql.NullString       `json:"pending_question"`
+	SystemPrompt        sql.NullString       `json:"system_prompt"`
+	PlanMaxSubtasks     sql.NullInt32        `json:"plan_max_subtasks"`
+	PlanMaxDepth        sql.NullInt32        `json:"plan_max_depth"`
+	LastError           sql.NullString       `json:"last_error"`
+	LastErrorCount      int32                `json:"last_error_count"`
+	LastErrorAt         sql.NullTime         `json:"last_error_at"`
+	CommandPolicy       json.RawMessage      `json:"command_policy"`
+	Scope               json.RawMessage      `json:"scope"`
+	ResultMaxSize       sql.NullInt32        `json:"result_max_size"`
+	ResultMaxSourceSize sql.NullInt32        `json:"result_max_source_size"`
+}
+
+type FlowArtifact struct {
+	ID          int64         `json:"id"`
+	Name        string        `json:"name"`
+	Size        int64         `json:"size"`
+	ContentType string        `json:"content_type"`
+	Checksum    string        `json:"checksum"`
+	FlowID      int64         `json:"flow_id"`
+	TaskID      sql.NullInt64 `json:"task_id"`
+	SubtaskID   sql.NullInt64 `json:"subtask_id"`
+	CreatedAt   sql.NullTime  `json:"created_at"`
+	UpdatedAt   sql.NullTime  `json:"updated_at"`
+}
+
+type FlowAttachment struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Size        int64        `json:"size"`
+	ContentType string       `json:"content_type"`
+	Checksum    string       `json:"checksum"`
+	FlowID      int64        `json:"flow_id"`
+	UserID      int64        `json:"user_id"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type FlowEnv struct {
+	ID        int64        `json:"id"`
+	FlowID    int64        `json:"flow_id"`
+	Name      string       `json:"name"`
+	Value     string       `json:"value"`
+	Secret    bool         `json:"secret"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+type FlowInput struct {
+	ID          int64           `json:"id"`
+	Input       string          `json:"input"`
+	Status      FlowInputStatus `json:"status"`
+	Error       sql.NullString  `json:"error"`
+	FlowID      int64           `json:"flow_id"`
+	CreatedAt   sql.NullTime    `json:"created_at"`
+	ProcessedAt sql.NullTime    `json:"processed_at"`
+}
+
+type FlowMemory struct {
 	ID        int64           `json:"id"`
-	Type      ContainerType   `json:"type"`
-	Name      string          `json:"name"`
-	Image     string          `json:"image"`
-	Status    ContainerStatus `json:"status"`
-	LocalID   sql.NullString  `json:"local_id"`
-	LocalDir  sql.NullString  `json:"local_dir"`
+	Key       string          `json:"key"`
+	Value     string          `json:"value"`
+	Tags      json.RawMessage `json:"tags"`
 	FlowID    int64           `json:"flow_id"`
+	TaskID    sql.NullInt64   `json:"task_id"`
+	SubtaskID sql.NullInt64   `json:"subtask_id"`
 	CreatedAt sql.NullTime    `json:"created_at"`
 	UpdatedAt sql.NullTime    `json:"updated_at"`
 }
 
-type Flow struct {
-	ID                 int64           `json:"id"`
-	Status             FlowStatus      `json:"status"`
-	Title              string          `json:"title"`
-	Model              string          `json:"model"`
-	ModelProviderName  string          `json:"model_provider_name"`
-	Language           string          `json:"language"`
-	Functions          json.RawMessage `json:"functions"`
-	UserID             int64           `json:"user_id"`
-	CreatedAt          sql.NullTime    `json:"created_at"`
-	UpdatedAt          sql.NullTime    `json:"updated_at"`
-	DeletedAt          sql.NullTime    `json:"deleted_at"`
-	TraceID            sql.NullString  `json:"trace_id"`
-	ModelProviderType  ProviderType    `json:"model_provider_type"`
-	ToolCallIDTemplate string          `json:"tool_call_id_template"`
+type FlowMemoryEmbedding struct {
+	MemoryID   int64        `json:"memory_id"`
+	FlowID     int64        `json:"flow_id"`
+	Model      string       `json:"model"`
+	Dimensions int32        `json:"dimensions"`
+	Embedding  []float32    `json:"embedding"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
+type ModelPrice struct {
+	ID               int64        `json:"id"`
+	Provider         string       `json:"provider"`
+	Model            string       `json:"model"`
+	InputPricePer1k  float64      `json:"input_price_per_1k"`
+	OutputPricePer1k float64      `json:"output_price_per_1k"`
+	EffectiveFrom    time.Time    `json:"effective_from"`
+	CreatedAt        sql.NullTime `json:"created_at"`
+	UpdatedAt        sql.NullTime `json:"updated_at"`
 }
 
 type Msgchain struct {
@@ -979,6 +1204,17 @@ type Msglog struct {
 	Thinking     sql.NullString     `json:"thinking"`
 }
 
+type OrphanedContainer struct {
+	ID          int64         `json:"id"`
+	LocalID     string        `json:"local_id"`
+	FlowID      int64         `json:"flow_id"`
+	ContainerID sql.NullInt64 `json:"container_id"`
+	Attempts    int32         `json:"attempts"`
+	LastError   string        `json:"last_error"`
+	CreatedAt   sql.NullTime  `json:"created_at"`
+	UpdatedAt   sql.NullTime  `json:"updated_at"`
+}
+
 type Privilege struct {
 	ID     int64  `json:"id"`
 	RoleID int64  `json:"role_id"`
@@ -1043,6 +1279,7 @@ type Subtask struct {
 	CreatedAt   sql.NullTime  `json:"created_at"`
 	UpdatedAt   sql.NullTime  `json:"updated_at"`
 	Context     string        `json:"context"`
+	Priority    int32         `json:"priority"`
 }
 
 type Task struct {
@@ -1067,6 +1304,19 @@ type Termlog struct {
 	SubtaskID   sql.NullInt64 `json:"subtask_id"`
 }
 
+type ToolLog struct {
+	ID        int64            `json:"id"`
+	Initiator MsgchainType     `json:"initiator"`
+	Executor  MsgchainType     `json:"executor"`
+	Engine    SearchengineType `json:"engine"`
+	Query     string           `json:"query"`
+	Result    string           `json:"result"`
+	FlowID    int64            `json:"flow_id"`
+	TaskID    sql.NullInt64    `json:"task_id"`
+	SubtaskID sql.NullInt64    `json:"subtask_id"`
+	CreatedAt sql.NullTime     `json:"created_at"`
+}
+
 type Toolcall struct {
 	ID              int64           `json:"id"`
 	CallID          string          `json:"call_id"`
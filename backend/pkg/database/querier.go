@@ -11,14 +11,20 @@ import (
 
 type Querier interface {
 	AddFavoriteFlow(ctx context.Context, arg AddFavoriteFlowParams) (UserPreference, error)
+	BumpSubtaskPriority(ctx context.Context, id int64) (Subtask, error)
+	CancelFlowQueuedInputs(ctx context.Context, arg CancelFlowQueuedInputsParams) error
 	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error)
 	CreateAgentLog(ctx context.Context, arg CreateAgentLogParams) (Agentlog, error)
 	CreateAssistant(ctx context.Context, arg CreateAssistantParams) (Assistant, error)
 	CreateAssistantLog(ctx context.Context, arg CreateAssistantLogParams) (Assistantlog, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateContainer(ctx context.Context, arg CreateContainerParams) (Container, error)
+	CreateContainerSnapshot(ctx context.Context, arg CreateContainerSnapshotParams) (ContainerSnapshot, error)
 	CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, error)
+	CreateFlowInput(ctx context.Context, arg CreateFlowInputParams) (FlowInput, error)
 	CreateMsgChain(ctx context.Context, arg CreateMsgChainParams) (Msgchain, error)
 	CreateMsgLog(ctx context.Context, arg CreateMsgLogParams) (Msglog, error)
+	CreateOrphanedContainer(ctx context.Context, arg CreateOrphanedContainerParams) (OrphanedContainer, error)
 	CreateProvider(ctx context.Context, arg CreateProviderParams) (Provider, error)
 	CreateResultAssistantLog(ctx context.Context, arg CreateResultAssistantLogParams) (Assistantlog, error)
 	CreateResultMsgLog(ctx context.Context, arg CreateResultMsgLogParams) (Msglog, error)
@@ -27,6 +33,7 @@ type Querier interface {
 	CreateSubtask(ctx context.Context, arg CreateSubtaskParams) (Subtask, error)
 	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	CreateTermLog(ctx context.Context, arg CreateTermLogParams) (Termlog, error)
+	CreateToolLog(ctx context.Context, arg CreateToolLogParams) error
 	CreateToolcall(ctx context.Context, arg CreateToolcallParams) (Toolcall, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateUserPreferences(ctx context.Context, arg CreateUserPreferencesParams) (UserPreference, error)
@@ -34,9 +41,14 @@ type Querier interface {
 	CreateVectorStoreLog(ctx context.Context, arg CreateVectorStoreLogParams) (Vecstorelog, error)
 	DeleteAPIToken(ctx context.Context, id int64) (ApiToken, error)
 	DeleteAssistant(ctx context.Context, id int64) (Assistant, error)
+	DeleteContainerSnapshot(ctx context.Context, id int64) error
 	DeleteFavoriteFlow(ctx context.Context, arg DeleteFavoriteFlowParams) (UserPreference, error)
 	DeleteFlow(ctx context.Context, id int64) (Flow, error)
 	DeleteFlowAssistantLog(ctx context.Context, id int64) error
+	DeleteFlowArtifacts(ctx context.Context, flowID int64) error
+	DeleteFlowAttachments(ctx context.Context, flowID int64) error
+	DeleteFlowMemoryEmbedding(ctx context.Context, memoryID int64) error
+	DeleteOrphanedContainer(ctx context.Context, id int64) error
 	DeletePrompt(ctx context.Context, id int64) error
 	DeleteProvider(ctx context.Context, id int64) (Provider, error)
 	DeleteSubtask(ctx context.Context, id int64) error
@@ -60,6 +72,7 @@ type Querier interface {
 	GetCallToolcall(ctx context.Context, callID string) (Toolcall, error)
 	GetContainerTermLogs(ctx context.Context, containerID int64) ([]Termlog, error)
 	GetContainers(ctx context.Context) ([]Container, error)
+	GetDeletedFlowsToPurge(ctx context.Context, retentionSeconds int64) ([]Flow, error)
 	GetFlow(ctx context.Context, id int64) (Flow, error)
 	GetFlowAgentLog(ctx context.Context, arg GetFlowAgentLogParams) (Agentlog, error)
 	GetFlowAgentLogs(ctx context.Context, flowID int64) ([]Agentlog, error)
@@ -67,7 +80,19 @@ type Querier interface {
 	GetFlowAssistantLog(ctx context.Context, id int64) (Assistantlog, error)
 	GetFlowAssistantLogs(ctx context.Context, arg GetFlowAssistantLogsParams) ([]Assistantlog, error)
 	GetFlowAssistants(ctx context.Context, flowID int64) ([]Assistant, error)
+	GetFlowArtifact(ctx context.Context, arg GetFlowArtifactParams) (FlowArtifact, error)
+	GetFlowArtifacts(ctx context.Context, flowID int64) ([]FlowArtifact, error)
+	GetFlowAttachment(ctx context.Context, arg GetFlowAttachmentParams) (FlowAttachment, error)
+	GetFlowAttachments(ctx context.Context, flowID int64) ([]FlowAttachment, error)
+	GetFlowContainerSnapshots(ctx context.Context, flowID int64) ([]ContainerSnapshot, error)
 	GetFlowContainers(ctx context.Context, flowID int64) ([]Container, error)
+	GetFlowContainersUnscoped(ctx context.Context, flowID int64) ([]Container, error)
+	GetFlowEnv(ctx context.Context, flowID int64) ([]FlowEnv, error)
+	GetFlowInputs(ctx context.Context, flowID int64) ([]FlowInput, error)
+	GetFlowMemories(ctx context.Context, arg GetFlowMemoriesParams) ([]FlowMemory, error)
+	GetFlowMemoriesEmbeddings(ctx context.Context, arg GetFlowMemoriesEmbeddingsParams) ([]GetFlowMemoriesEmbeddingsRow, error)
+	GetFlowMemory(ctx context.Context, arg GetFlowMemoryParams) (FlowMemory, error)
+	GetFlowMemoryVectorSupport(ctx context.Context) (bool, error)
 	GetFlowMsgChains(ctx context.Context, flowID int64) ([]Msgchain, error)
 	GetFlowMsgLogs(ctx context.Context, flowID int64) ([]Msglog, error)
 	GetFlowPrimaryContainer(ctx context.Context, flowID int64) (Container, error)
@@ -104,18 +129,22 @@ type Querier interface {
 	GetFlowsStatsByDayLastMonth(ctx context.Context, userID int64) ([]GetFlowsStatsByDayLastMonthRow, error)
 	// Get flows stats by day for the last week
 	GetFlowsStatsByDayLastWeek(ctx context.Context, userID int64) ([]GetFlowsStatsByDayLastWeekRow, error)
+	GetModelPrices(ctx context.Context, arg GetModelPricesParams) ([]ModelPrice, error)
 	GetMsgChain(ctx context.Context, id int64) (Msgchain, error)
 	// Get all msgchains for a flow (including task and subtask level)
 	GetMsgchainsForFlow(ctx context.Context, flowID int64) ([]GetMsgchainsForFlowRow, error)
+	GetOrphanedContainers(ctx context.Context) ([]OrphanedContainer, error)
 	GetPrompts(ctx context.Context) ([]Prompt, error)
 	GetProvider(ctx context.Context, id int64) (Provider, error)
 	GetProviders(ctx context.Context) ([]Provider, error)
 	GetProvidersByType(ctx context.Context, type_ ProviderType) ([]Provider, error)
+	GetRetainedFlowIDs(ctx context.Context) ([]int64, error)
 	GetRole(ctx context.Context, id int64) (GetRoleRow, error)
 	GetRoleByName(ctx context.Context, name string) (GetRoleByNameRow, error)
 	GetRoles(ctx context.Context) ([]GetRolesRow, error)
 	GetRunningContainers(ctx context.Context) ([]Container, error)
 	GetScreenshot(ctx context.Context, id int64) (Screenshot, error)
+	GetSnapshotImageUsageCount(ctx context.Context, arg GetSnapshotImageUsageCountParams) (int64, error)
 	GetSubtask(ctx context.Context, id int64) (Subtask, error)
 	GetSubtaskAgentLogs(ctx context.Context, subtaskID sql.NullInt64) ([]Agentlog, error)
 	GetSubtaskMsgChains(ctx context.Context, subtaskID sql.NullInt64) ([]Msgchain, error)
@@ -202,11 +231,16 @@ type Querier interface {
 	GetUserProviders(ctx context.Context, userID int64) ([]Provider, error)
 	GetUserProvidersByType(ctx context.Context, arg GetUserProvidersByTypeParams) ([]Provider, error)
 	// Get total count of flows, tasks, subtasks, and assistants for a user
+	GetUserRunningFlowsCount(ctx context.Context, userID int64) (int64, error)
 	GetUserTotalFlowsStats(ctx context.Context, userID int64) (GetUserTotalFlowsStatsRow, error)
 	// Get total toolcalls stats for a user
 	GetUserTotalToolcallsStats(ctx context.Context, userID int64) (GetUserTotalToolcallsStatsRow, error)
 	GetUserTotalUsageStats(ctx context.Context, userID int64) (GetUserTotalUsageStatsRow, error)
 	GetUsers(ctx context.Context) ([]GetUsersRow, error)
+	ReplaceFlowSecrets(ctx context.Context, arg ReplaceFlowSecretsParams) error
+	ResetFlowLastError(ctx context.Context, id int64) (Flow, error)
+	SearchFlowMemories(ctx context.Context, arg SearchFlowMemoriesParams) ([]FlowMemory, error)
+	SearchFlowMemoriesByVector(ctx context.Context, arg SearchFlowMemoriesByVectorParams) ([]SearchFlowMemoriesByVectorRow, error)
 	UpdateAPIToken(ctx context.Context, arg UpdateAPITokenParams) (ApiToken, error)
 	UpdateAssistant(ctx context.Context, arg UpdateAssistantParams) (Assistant, error)
 	UpdateAssistantLanguage(ctx context.Context, arg UpdateAssistantLanguageParams) (Assistant, error)
@@ -224,18 +258,28 @@ type Querier interface {
 	UpdateContainerStatus(ctx context.Context, arg UpdateContainerStatusParams) (Container, error)
 	UpdateContainerStatusLocalID(ctx context.Context, arg UpdateContainerStatusLocalIDParams) (Container, error)
 	UpdateFlow(ctx context.Context, arg UpdateFlowParams) (Flow, error)
+	UpdateFlowBudget(ctx context.Context, arg UpdateFlowBudgetParams) (Flow, error)
+	UpdateFlowInputStatus(ctx context.Context, arg UpdateFlowInputStatusParams) (FlowInput, error)
 	UpdateFlowLanguage(ctx context.Context, arg UpdateFlowLanguageParams) (Flow, error)
+	UpdateFlowLastError(ctx context.Context, arg UpdateFlowLastErrorParams) (Flow, error)
+	UpdateFlowFinishReason(ctx context.Context, arg UpdateFlowFinishReasonParams) (Flow, error)
+	UpdateFlowMetadata(ctx context.Context, arg UpdateFlowMetadataParams) (Flow, error)
+	UpdateFlowPendingQuestion(ctx context.Context, arg UpdateFlowPendingQuestionParams) (Flow, error)
+	UpdateFlowProvider(ctx context.Context, arg UpdateFlowProviderParams) (Flow, error)
 	UpdateFlowStatus(ctx context.Context, arg UpdateFlowStatusParams) (Flow, error)
+	UpdateFlowStopReason(ctx context.Context, arg UpdateFlowStopReasonParams) (Flow, error)
 	UpdateFlowTitle(ctx context.Context, arg UpdateFlowTitleParams) (Flow, error)
 	UpdateFlowToolCallIDTemplate(ctx context.Context, arg UpdateFlowToolCallIDTemplateParams) (Flow, error)
 	UpdateMsgChain(ctx context.Context, arg UpdateMsgChainParams) (Msgchain, error)
 	UpdateMsgChainUsage(ctx context.Context, arg UpdateMsgChainUsageParams) (Msgchain, error)
 	UpdateMsgLogResult(ctx context.Context, arg UpdateMsgLogResultParams) (Msglog, error)
+	UpdateOrphanedContainerAttempt(ctx context.Context, arg UpdateOrphanedContainerAttemptParams) (OrphanedContainer, error)
 	UpdatePrompt(ctx context.Context, arg UpdatePromptParams) (Prompt, error)
 	UpdateProvider(ctx context.Context, arg UpdateProviderParams) (Provider, error)
 	UpdateSubtaskContext(ctx context.Context, arg UpdateSubtaskContextParams) (Subtask, error)
 	UpdateSubtaskFailedResult(ctx context.Context, arg UpdateSubtaskFailedResultParams) (Subtask, error)
 	UpdateSubtaskFinishedResult(ctx context.Context, arg UpdateSubtaskFinishedResultParams) (Subtask, error)
+	UpdateSubtaskPriority(ctx context.Context, arg UpdateSubtaskPriorityParams) (Subtask, error)
 	UpdateSubtaskResult(ctx context.Context, arg UpdateSubtaskResultParams) (Subtask, error)
 	UpdateSubtaskStatus(ctx context.Context, arg UpdateSubtaskStatusParams) (Subtask, error)
 	UpdateTaskFailedResult(ctx context.Context, arg UpdateTaskFailedResultParams) (Task, error)
@@ -255,6 +299,10 @@ type Querier interface {
 	UpdateUserProvider(ctx context.Context, arg UpdateUserProviderParams) (Provider, error)
 	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
+	UpsertFlowArtifact(ctx context.Context, arg UpsertFlowArtifactParams) (FlowArtifact, error)
+	UpsertFlowEnv(ctx context.Context, arg UpsertFlowEnvParams) (FlowEnv, error)
+	UpsertFlowMemory(ctx context.Context, arg UpsertFlowMemoryParams) (FlowMemory, error)
+	UpsertFlowMemoryEmbedding(ctx context.Context, arg UpsertFlowMemoryEmbeddingParams) error
 	UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) (UserPreference, error)
 }
 
@@ -11,6 +11,35 @@ import (
 	"github.com/lib/pq"
 )
 
+const bumpSubtaskPriority = `-- name: BumpSubtaskPriority :one
+UPDATE subtasks
+SET priority = (
+  SELECT COALESCE(MAX(ps.priority), 0) + 1
+  FROM subtasks ps
+  WHERE ps.task_id = subtasks.task_id
+)
+WHERE id = $1
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
+`
+
+func (q *Queries) BumpSubtaskPriority(ctx context.Context, id int64) (Subtask, error) {
+	row := q.db.QueryRowContext(ctx, bumpSubtaskPriority, id)
+	var i Subtask
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Description,
+		&i.Result,
+		&i.TaskID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Context,
+		&i.Priority,
+	)
+	return i, err
+}
+
 const createSubtask = `-- name: CreateSubtask :one
 INSERT INTO subtasks (
   status,
@@ -20,7 +49,7 @@ INSERT INTO subtasks (
 ) VALUES (
   $1, $2, $3, $4
 )
-RETURNING id, status, title, description, result, task_id, created_at, updated_at, context
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
 `
 
 type CreateSubtaskParams struct {
@@ -48,6 +77,7 @@ func (q *Queries) CreateSubtask(ctx context.Context, arg CreateSubtaskParams) (S
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -74,7 +104,7 @@ func (q *Queries) DeleteSubtasks(ctx context.Context, ids []int64) error {
 
 const getFlowSubtask = `-- name: GetFlowSubtask :one
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -99,13 +129,14 @@ func (q *Queries) GetFlowSubtask(ctx context.Context, arg GetFlowSubtaskParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
 
 const getFlowSubtasks = `-- name: GetFlowSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -132,6 +163,7 @@ func (q *Queries) GetFlowSubtasks(ctx context.Context, flowID int64) ([]Subtask,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -148,7 +180,7 @@ func (q *Queries) GetFlowSubtasks(ctx context.Context, flowID int64) ([]Subtask,
 
 const getFlowTaskSubtasks = `-- name: GetFlowTaskSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -180,6 +212,7 @@ func (q *Queries) GetFlowTaskSubtasks(ctx context.Context, arg GetFlowTaskSubtas
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -196,7 +229,7 @@ func (q *Queries) GetFlowTaskSubtasks(ctx context.Context, arg GetFlowTaskSubtas
 
 const getSubtask = `-- name: GetSubtask :one
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 WHERE s.id = $1
 `
@@ -214,13 +247,14 @@ func (q *Queries) GetSubtask(ctx context.Context, id int64) (Subtask, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
 
 const getTaskCompletedSubtasks = `-- name: GetTaskCompletedSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -247,6 +281,7 @@ func (q *Queries) GetTaskCompletedSubtasks(ctx context.Context, taskID int64) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -263,12 +298,12 @@ func (q *Queries) GetTaskCompletedSubtasks(ctx context.Context, taskID int64) ([
 
 const getTaskPlannedSubtasks = `-- name: GetTaskPlannedSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
 WHERE s.task_id = $1 AND (s.status = 'created' OR s.status = 'waiting') AND f.deleted_at IS NULL
-ORDER BY s.id ASC
+ORDER BY (s.status = 'waiting') DESC, s.priority DESC, s.id ASC
 `
 
 func (q *Queries) GetTaskPlannedSubtasks(ctx context.Context, taskID int64) ([]Subtask, error) {
@@ -290,6 +325,7 @@ func (q *Queries) GetTaskPlannedSubtasks(ctx context.Context, taskID int64) ([]S
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -306,7 +342,7 @@ func (q *Queries) GetTaskPlannedSubtasks(ctx context.Context, taskID int64) ([]S
 
 const getTaskSubtasks = `-- name: GetTaskSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -333,6 +369,7 @@ func (q *Queries) GetTaskSubtasks(ctx context.Context, taskID int64) ([]Subtask,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -349,7 +386,7 @@ func (q *Queries) GetTaskSubtasks(ctx context.Context, taskID int64) ([]Subtask,
 
 const getUserFlowSubtasks = `-- name: GetUserFlowSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -382,6 +419,7 @@ func (q *Queries) GetUserFlowSubtasks(ctx context.Context, arg GetUserFlowSubtas
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -398,7 +436,7 @@ func (q *Queries) GetUserFlowSubtasks(ctx context.Context, arg GetUserFlowSubtas
 
 const getUserFlowTaskSubtasks = `-- name: GetUserFlowTaskSubtasks :many
 SELECT
-  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context
+  s.id, s.status, s.title, s.description, s.result, s.task_id, s.created_at, s.updated_at, s.context, s.priority
 FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
@@ -432,6 +470,7 @@ func (q *Queries) GetUserFlowTaskSubtasks(ctx context.Context, arg GetUserFlowTa
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Context,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -450,7 +489,7 @@ const updateSubtaskContext = `-- name: UpdateSubtaskContext :one
 UPDATE subtasks
 SET context = $1
 WHERE id = $2
-RETURNING id, status, title, description, result, task_id, created_at, updated_at, context
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
 `
 
 type UpdateSubtaskContextParams struct {
@@ -471,6 +510,7 @@ func (q *Queries) UpdateSubtaskContext(ctx context.Context, arg UpdateSubtaskCon
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -479,7 +519,7 @@ const updateSubtaskFailedResult = `-- name: UpdateSubtaskFailedResult :one
 UPDATE subtasks
 SET status = 'failed', result = $1
 WHERE id = $2
-RETURNING id, status, title, description, result, task_id, created_at, updated_at, context
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
 `
 
 type UpdateSubtaskFailedResultParams struct {
@@ -500,6 +540,7 @@ func (q *Queries) UpdateSubtaskFailedResult(ctx context.Context, arg UpdateSubta
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -508,7 +549,7 @@ const updateSubtaskFinishedResult = `-- name: UpdateSubtaskFinishedResult :one
 UPDATE subtasks
 SET status = 'finished', result = $1
 WHERE id = $2
-RETURNING id, status, title, description, result, task_id, created_at, updated_at, context
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
 `
 
 type UpdateSubtaskFinishedResultParams struct {
@@ -529,6 +570,37 @@ func (q *Queries) UpdateSubtaskFinishedResult(ctx context.Context, arg UpdateSub
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
+	)
+	return i, err
+}
+
+const updateSubtaskPriority = `-- name: UpdateSubtaskPriority :one
+UPDATE subtasks
+SET priority = $1
+WHERE id = $2
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
+`
+
+type UpdateSubtaskPriorityParams struct {
+	Priority int32 `json:"priority"`
+	ID       int64 `json:"id"`
+}
+
+func (q *Queries) UpdateSubtaskPriority(ctx context.Context, arg UpdateSubtaskPriorityParams) (Subtask, error) {
+	row := q.db.QueryRowContext(ctx, updateSubtaskPriority, arg.Priority, arg.ID)
+	var i Subtask
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Description,
+		&i.Result,
+		&i.TaskID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -537,7 +609,7 @@ const updateSubtaskResult = `-- name: UpdateSubtaskResult :one
 UPDATE subtasks
 SET result = $1
 WHERE id = $2
-RETURNING id, status, title, description, result, task_id, created_at, updated_at, context
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
 `
 
 type UpdateSubtaskResultParams struct {
@@ -558,6 +630,7 @@ func (q *Queries) UpdateSubtaskResult(ctx context.Context, arg UpdateSubtaskResu
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -566,7 +639,7 @@ const updateSubtaskStatus = `-- name: UpdateSubtaskStatus :one
 UPDATE subtasks
 SET status = $1
 WHERE id = $2
-RETURNING id, status, title, description, result, task_id, created_at, updated_at, context
+RETURNING id, status, title, description, result, task_id, created_at, updated_at, context, priority
 `
 
 type UpdateSubtaskStatusParams struct {
@@ -587,6 +660,7 @@ func (q *Queries) UpdateSubtaskStatus(ctx context.Context, arg UpdateSubtaskStat
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Context,
+		&i.Priority,
 	)
 	return i, err
 }
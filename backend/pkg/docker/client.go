@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -39,17 +40,25 @@ const (
 	limitContainerPortsNumber   = 2000
 )
 
+const (
+	// containerInstallationLabel marks containers spawned by this PentAGI installation
+	containerInstallationLabel = "pentagi.installation_id"
+	// containerFlowLabel keeps id of the flow which the container is spawned for
+	containerFlowLabel = "pentagi.flow_id"
+)
+
 type dockerClient struct {
-	db       database.Querier
-	logger   *logrus.Logger
-	dataDir  string
-	hostDir  string
-	client   *client.Client
-	inside   bool
-	defImage string
-	socket   string
-	network  string
-	publicIP string
+	db        database.Querier
+	logger    *logrus.Logger
+	dataDir   string
+	hostDir   string
+	client    *client.Client
+	inside    bool
+	defImage  string
+	socket    string
+	network   string
+	publicIP  string
+	installID string
 }
 
 type DockerClient interface {
@@ -63,8 +72,12 @@ type DockerClient interface {
 	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
 	CopyToContainer(ctx context.Context, containerID string, dstPath string, content io.Reader, options container.CopyToContainerOptions) error
 	CopyFromContainer(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, container.PathStat, error)
+	CommitContainer(ctx context.Context, containerID string, reference string) (string, error)
+	RemoveImage(ctx context.Context, reference string) error
+	ReapContainers(ctx context.Context) error
 	Cleanup(ctx context.Context) error
 	GetDefaultImage() string
+	Ping(ctx context.Context) error
 }
 
 func GetPrimaryContainerPorts(flowID int64) []int {
@@ -138,16 +151,17 @@ func NewDockerClient(ctx context.Context, db database.Querier, cfg *config.Confi
 	}).Debug("Docker client initialized")
 
 	return &dockerClient{
-		db:       db,
-		client:   cli,
-		dataDir:  dataDir,
-		hostDir:  hostDir,
-		logger:   logger,
-		inside:   inside,
-		defImage: defImage,
-		socket:   socket,
-		network:  netName,
-		publicIP: publicIP,
+		db:        db,
+		client:    cli,
+		dataDir:   dataDir,
+		hostDir:   hostDir,
+		logger:    logger,
+		inside:    inside,
+		defImage:  defImage,
+		socket:    socket,
+		network:   netName,
+		publicIP:  publicIP,
+		installID: cfg.InstallationID,
 	}, nil
 }
 
@@ -183,14 +197,22 @@ func (dc *dockerClient) SpawnContainer(
 	})
 	logger.Info("spawning container")
 
+	if hostConfig == nil {
+		hostConfig = &container.HostConfig{}
+	}
+
+	limits := containerLimitsFromHostConfig(hostConfig)
 	dbContainer, err := dc.db.CreateContainer(ctx, database.CreateContainerParams{
-		Type:     containerType,
-		Name:     containerName,
-		Image:    config.Image,
-		Status:   database.ContainerStatusStarting,
-		FlowID:   flowID,
-		LocalID:  database.StringToNullString(fmt.Sprintf("tmp-id-%d", flowID)),
-		LocalDir: database.StringToNullString(hostDir),
+		Type:        containerType,
+		Name:        containerName,
+		Image:       config.Image,
+		Status:      database.ContainerStatusStarting,
+		FlowID:      flowID,
+		LocalID:     database.StringToNullString(fmt.Sprintf("tmp-id-%d", flowID)),
+		LocalDir:    database.StringToNullString(hostDir),
+		CpuLimit:    limits.CPUs,
+		MemoryLimit: limits.MemoryMB,
+		PidsLimit:   limits.Pids,
 	})
 	if err != nil {
 		return database.Container{}, fmt.Errorf("failed to create container in database: %w", err)
@@ -240,9 +262,12 @@ func (dc *dockerClient) SpawnContainer(
 	config.Hostname = fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(containerName)))
 	config.WorkingDir = WorkFolderPathInContainer
 
-	if hostConfig == nil {
-		hostConfig = &container.HostConfig{}
+	// labels allow the reaper to find containers of this installation on the shared docker host
+	if config.Labels == nil {
+		config.Labels = map[string]string{}
 	}
+	config.Labels[containerInstallationLabel] = dc.installID
+	config.Labels[containerFlowLabel] = strconv.FormatInt(flowID, 10)
 
 	// prevent containers from auto-starting after OS or docker daemon restart
 	// because on startup they create docker.sock directory for DinD if it's enabled
@@ -476,6 +501,13 @@ func (dc *dockerClient) Cleanup(ctx context.Context) error {
 		if err != nil {
 			logger.WithError(err).Errorf("failed to update flow status to failed")
 		}
+		_, err = dc.db.UpdateFlowFinishReason(ctx, database.UpdateFlowFinishReasonParams{
+			FinishReason: database.NullFlowFinishReason{FlowFinishReason: database.FlowFinishReasonError, Valid: true},
+			ID:           flowID,
+		})
+		if err != nil {
+			logger.WithError(err).Errorf("failed to update flow finish reason")
+		}
 	}
 
 	for _, flow := range flows {
@@ -514,10 +546,60 @@ func (dc *dockerClient) IsContainerRunning(ctx context.Context, containerID stri
 	return containerInfo.State.Running, err
 }
 
+// CommitContainer saves the current state of the container filesystem as the image with the reference tag
+func (dc *dockerClient) CommitContainer(ctx context.Context, containerID string, reference string) (string, error) {
+	logger := dc.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"local_id":  containerID,
+		"reference": reference,
+	})
+	logger.Info("committing container")
+
+	resp, err := dc.client.ContainerCommit(ctx, containerID, container.CommitOptions{
+		Reference: reference,
+		Comment:   "PentAGI container snapshot",
+		Pause:     true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to commit container: %w", err)
+	}
+
+	logger.WithField("image_id", resp.ID).Info("container committed")
+
+	return resp.ID, nil
+}
+
+// RemoveImage deletes the image by reference, missing image is not an error, the image which is still
+// used by any container isn't forced out and the removal fails instead
+func (dc *dockerClient) RemoveImage(ctx context.Context, reference string) error {
+	logger := dc.logger.WithContext(ctx).WithField("reference", reference)
+	logger.Info("removing image")
+
+	options := image.RemoveOptions{
+		PruneChildren: true,
+	}
+	if _, err := dc.client.ImageRemove(ctx, reference, options); err != nil {
+		if client.IsErrNotFound(err) {
+			logger.WithError(err).Warn("image not found")
+			return nil
+		}
+		return fmt.Errorf("failed to remove image: %w", err)
+	}
+
+	return nil
+}
+
 func (dc *dockerClient) GetDefaultImage() string {
 	return dc.defImage
 }
 
+func (dc *dockerClient) Ping(ctx context.Context) error {
+	if _, err := dc.client.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping docker daemon: %w", err)
+	}
+
+	return nil
+}
+
 func (dc *dockerClient) ContainerExecCreate(
 	ctx context.Context,
 	container string,
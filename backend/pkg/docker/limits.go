@@ -0,0 +1,119 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const bytesInMegabyte = 1024 * 1024
+
+var ErrContainerLimitsExceeded = errors.New("container resource limits exceeded")
+
+// ContainerLimits contains resource limits of the flow container, zero value means unlimited
+type ContainerLimits struct {
+	CPUs     float64
+	MemoryMB int64
+	Pids     int64
+}
+
+// DefaultContainerLimits returns limits which are applied when user didn't request them explicitly
+func DefaultContainerLimits(cfg *config.Config) ContainerLimits {
+	if cfg == nil {
+		return ContainerLimits{}
+	}
+
+	return ContainerLimits{
+		CPUs:     max(cfg.DockerCPULimit, 0),
+		MemoryMB: max(cfg.DockerMemoryLimit, 0),
+		Pids:     max(cfg.DockerPidsLimit, 0),
+	}
+}
+
+// ResolveContainerLimits fills unset requested limits from defaults and checks them against
+// admin configured maximums, zero maximum means the value is not bounded
+func ResolveContainerLimits(cfg *config.Config, requested *ContainerLimits) (ContainerLimits, error) {
+	limits := DefaultContainerLimits(cfg)
+	if requested == nil {
+		return limits, nil
+	}
+
+	if requested.CPUs < 0 || requested.MemoryMB < 0 || requested.Pids < 0 {
+		return ContainerLimits{}, fmt.Errorf("%w: negative values are not allowed", ErrContainerLimitsExceeded)
+	}
+
+	if requested.CPUs != 0 {
+		limits.CPUs = requested.CPUs
+	}
+	if requested.MemoryMB != 0 {
+		limits.MemoryMB = requested.MemoryMB
+	}
+	if requested.Pids != 0 {
+		limits.Pids = requested.Pids
+	}
+
+	if cfg == nil {
+		return limits, nil
+	}
+
+	if cfg.DockerMaxCPULimit > 0 && limits.CPUs > cfg.DockerMaxCPULimit {
+		return ContainerLimits{}, fmt.Errorf("%w: cpus %g is greater than maximum %g",
+			ErrContainerLimitsExceeded, limits.CPUs, cfg.DockerMaxCPULimit)
+	}
+	if cfg.DockerMaxMemoryLimit > 0 && limits.MemoryMB > cfg.DockerMaxMemoryLimit {
+		return ContainerLimits{}, fmt.Errorf("%w: memory %d MB is greater than maximum %d MB",
+			ErrContainerLimitsExceeded, limits.MemoryMB, cfg.DockerMaxMemoryLimit)
+	}
+	if cfg.DockerMaxPidsLimit > 0 && limits.Pids > cfg.DockerMaxPidsLimit {
+		return ContainerLimits{}, fmt.Errorf("%w: pids %d is greater than maximum %d",
+			ErrContainerLimitsExceeded, limits.Pids, cfg.DockerMaxPidsLimit)
+	}
+
+	return limits, nil
+}
+
+// ContainerLimitsFromDB restores limits which were stored for the previously spawned container
+func ContainerLimitsFromDB(cnt database.Container) ContainerLimits {
+	return ContainerLimits{
+		CPUs:     cnt.CpuLimit,
+		MemoryMB: cnt.MemoryLimit,
+		Pids:     cnt.PidsLimit,
+	}
+}
+
+// Apply sets limits to the docker host config resources
+func (l ContainerLimits) Apply(hostConfig *container.HostConfig) {
+	if hostConfig == nil {
+		return
+	}
+
+	if l.CPUs > 0 {
+		hostConfig.NanoCPUs = int64(l.CPUs * 1e9)
+	}
+	if l.MemoryMB > 0 {
+		hostConfig.Memory = l.MemoryMB * bytesInMegabyte
+		// disable swap usage above the memory limit
+		hostConfig.MemorySwap = hostConfig.Memory
+	}
+	if l.Pids > 0 {
+		pids := l.Pids
+		hostConfig.PidsLimit = &pids
+	}
+}
+
+// containerLimitsFromHostConfig reads limits back from the docker host config to store them
+func containerLimitsFromHostConfig(hostConfig *container.HostConfig) ContainerLimits {
+	limits := ContainerLimits{
+		CPUs:     float64(hostConfig.NanoCPUs) / 1e9,
+		MemoryMB: hostConfig.Memory / bytesInMegabyte,
+	}
+	if hostConfig.PidsLimit != nil {
+		limits.Pids = max(*hostConfig.PidsLimit, 0)
+	}
+
+	return limits
+}
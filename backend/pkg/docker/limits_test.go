@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"pentagi/pkg/config"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestResolveContainerLimits(t *testing.T) {
+	cfg := &config.Config{
+		DockerCPULimit:       2,
+		DockerMemoryLimit:    4096,
+		DockerPidsLimit:      1024,
+		DockerMaxCPULimit:    8,
+		DockerMaxMemoryLimit: 16384,
+		DockerMaxPidsLimit:   0,
+	}
+
+	tests := []struct {
+		name      string
+		requested *ContainerLimits
+		want      ContainerLimits
+		wantErr   bool
+	}{
+		{
+			name: "defaults when unset",
+			want: ContainerLimits{CPUs: 2, MemoryMB: 4096, Pids: 1024},
+		},
+		{
+			name:      "partial request keeps other defaults",
+			requested: &ContainerLimits{MemoryMB: 8192},
+			want:      ContainerLimits{CPUs: 2, MemoryMB: 8192, Pids: 1024},
+		},
+		{
+			name:      "zero maximum is not bounded",
+			requested: &ContainerLimits{Pids: 100000},
+			want:      ContainerLimits{CPUs: 2, MemoryMB: 4096, Pids: 100000},
+		},
+		{
+			name:      "cpus over maximum",
+			requested: &ContainerLimits{CPUs: 16},
+			wantErr:   true,
+		},
+		{
+			name:      "memory over maximum",
+			requested: &ContainerLimits{MemoryMB: 32768},
+			wantErr:   true,
+		},
+		{
+			name:      "negative value",
+			requested: &ContainerLimits{CPUs: -1},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveContainerLimits(cfg, tt.requested)
+			if tt.wantErr {
+				if !errors.Is(err, ErrContainerLimitsExceeded) {
+					t.Fatalf("expected ErrContainerLimitsExceeded, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerLimitsApply(t *testing.T) {
+	limits := ContainerLimits{CPUs: 1.5, MemoryMB: 512, Pids: 256}
+
+	hostConfig := &container.HostConfig{}
+	limits.Apply(hostConfig)
+
+	if hostConfig.NanoCPUs != 1_500_000_000 {
+		t.Errorf("unexpected NanoCPUs: %d", hostConfig.NanoCPUs)
+	}
+	if hostConfig.Memory != 512*bytesInMegabyte || hostConfig.MemorySwap != hostConfig.Memory {
+		t.Errorf("unexpected memory settings: %d, swap %d", hostConfig.Memory, hostConfig.MemorySwap)
+	}
+	if hostConfig.PidsLimit == nil || *hostConfig.PidsLimit != 256 {
+		t.Errorf("unexpected PidsLimit: %v", hostConfig.PidsLimit)
+	}
+
+	if got := containerLimitsFromHostConfig(hostConfig); got != limits {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got, limits)
+	}
+
+	empty := &container.HostConfig{}
+	ContainerLimits{}.Apply(empty)
+	if empty.NanoCPUs != 0 || empty.Memory != 0 || empty.PidsLimit != nil {
+		t.Errorf("zero limits must not change host config: %+v", empty.Resources)
+	}
+}
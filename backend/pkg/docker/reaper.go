@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/sirupsen/logrus"
+)
+
+// flowIDFromContainerLabels returns flow id from the container labels if it's spawned for the flow
+// by the installation, containers of other installations on the same docker host are skipped
+func flowIDFromContainerLabels(labels map[string]string, installID string) (int64, bool) {
+	if installID == "" || labels[containerInstallationLabel] != installID {
+		return 0, false
+	}
+
+	flowID, err := strconv.ParseInt(labels[containerFlowLabel], 10, 64)
+	if err != nil || flowID <= 0 {
+		return 0, false
+	}
+
+	return flowID, true
+}
+
+// ReapContainers retries removal of recorded orphaned containers and removes docker containers
+// of this installation whose flow doesn't exist in the database anymore or was deleted and purged
+func (dc *dockerClient) ReapContainers(ctx context.Context) error {
+	logger := dc.logger.WithContext(ctx).WithField("docker", "reaper")
+
+	var errs []error
+	if err := dc.reapOrphanedContainers(ctx, logger); err != nil {
+		errs = append(errs, err)
+	}
+	if err := dc.reapUnknownContainers(ctx, logger); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (dc *dockerClient) reapOrphanedContainers(ctx context.Context, logger *logrus.Entry) error {
+	orphans, err := dc.db.GetOrphanedContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get orphaned containers: %w", err)
+	}
+
+	for _, orphan := range orphans {
+		logger := logger.WithFields(logrus.Fields{
+			"local_id": orphan.LocalID,
+			"flow_id":  orphan.FlowID,
+			"attempts": orphan.Attempts,
+		})
+
+		if err := dc.removeContainer(ctx, orphan.LocalID); err != nil {
+			logger.WithError(err).Warn("failed to remove orphaned container")
+			_, err := dc.db.UpdateOrphanedContainerAttempt(ctx, database.UpdateOrphanedContainerAttemptParams{
+				LastError: err.Error(),
+				ID:        orphan.ID,
+			})
+			if err != nil {
+				logger.WithError(err).Error("failed to update orphaned container attempt")
+			}
+			continue
+		}
+
+		if orphan.ContainerID.Valid {
+			_, err := dc.db.UpdateContainerStatus(ctx, database.UpdateContainerStatusParams{
+				Status: database.ContainerStatusDeleted,
+				ID:     orphan.ContainerID.Int64,
+			})
+			if err != nil {
+				logger.WithError(err).Warn("failed to update container status to deleted")
+			}
+		}
+
+		if err := dc.db.DeleteOrphanedContainer(ctx, orphan.ID); err != nil {
+			logger.WithError(err).Error("failed to delete orphaned container record")
+			continue
+		}
+
+		logger.Info("orphaned container removed")
+	}
+
+	return nil
+}
+
+func (dc *dockerClient) reapUnknownContainers(ctx context.Context, logger *logrus.Entry) error {
+	if dc.installID == "" {
+		return nil
+	}
+
+	// containers must be listed before flows to avoid removing container of the just created flow
+	containers, err := dc.client.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", containerInstallationLabel+"="+dc.installID)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	// the deleted flow keeps its containers until it's purged
+	flowIDs, err := dc.db.GetRetainedFlowIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get retained flows: %w", err)
+	}
+
+	existingFlows := make(map[int64]struct{}, len(flowIDs))
+	for _, flowID := range flowIDs {
+		existingFlows[flowID] = struct{}{}
+	}
+
+	for _, cnt := range containers {
+		// the label filter is checked again in case docker daemon ignores it
+		flowID, ok := flowIDFromContainerLabels(cnt.Labels, dc.installID)
+		if !ok {
+			continue
+		}
+		if _, ok := existingFlows[flowID]; ok {
+			continue
+		}
+
+		var name string
+		if len(cnt.Names) != 0 {
+			name = strings.TrimPrefix(cnt.Names[0], "/")
+		}
+
+		logger := logger.WithFields(logrus.Fields{
+			"local_id": cnt.ID,
+			"name":     name,
+			"flow_id":  flowID,
+		})
+
+		if err := dc.removeContainer(ctx, cnt.ID); err != nil {
+			logger.WithError(err).Warn("failed to remove container of deleted flow")
+			continue
+		}
+
+		logger.Info("container of deleted flow removed")
+	}
+
+	return nil
+}
+
+// removeContainer force removes docker container, missing container is not an error
+func (dc *dockerClient) removeContainer(ctx context.Context, containerID string) error {
+	options := container.RemoveOptions{
+		RemoveVolumes: true,
+		Force:         true,
+	}
+	if err := dc.client.ContainerRemove(ctx, containerID, options); err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+
+	return nil
+}
+
+// RunContainerReaper periodically reconciles docker containers against the database until ctx is done,
+// zero or negative interval disables the reaper
+func RunContainerReaper(ctx context.Context, dc DockerClient, interval time.Duration) {
+	if dc == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dc.ReapContainers(ctx); err != nil {
+				logrus.WithContext(ctx).WithError(err).Warn("failed to reap containers")
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package docker
+
+import "testing"
+
+func TestFlowIDFromContainerLabels(t *testing.T) {
+	const installID = "8f0c2a7e-4d1b-4c39-9a65-2f1e0b7d3c41"
+
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		installID string
+		flowID    int64
+		ok        bool
+	}{
+		{
+			name:      "own flow container",
+			labels:    map[string]string{containerInstallationLabel: installID, containerFlowLabel: "42"},
+			installID: installID,
+			flowID:    42,
+			ok:        true,
+		},
+		{
+			name:      "other installation",
+			labels:    map[string]string{containerInstallationLabel: "other", containerFlowLabel: "42"},
+			installID: installID,
+		},
+		{
+			name:      "unlabeled container",
+			labels:    map[string]string{},
+			installID: installID,
+		},
+		{
+			name:      "invalid flow id",
+			labels:    map[string]string{containerInstallationLabel: installID, containerFlowLabel: "x"},
+			installID: installID,
+		},
+		{
+			name:      "missing flow id",
+			labels:    map[string]string{containerInstallationLabel: installID},
+			installID: installID,
+		},
+		{
+			name:   "empty installation id",
+			labels: map[string]string{containerInstallationLabel: "", containerFlowLabel: "42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flowID, ok := flowIDFromContainerLabels(tt.labels, tt.installID)
+			if ok != tt.ok || flowID != tt.flowID {
+				t.Errorf("got (%d, %v), want (%d, %v)", flowID, ok, tt.flowID, tt.ok)
+			}
+		})
+	}
+}
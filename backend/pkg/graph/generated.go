@@ -218,13 +218,18 @@ type ComplexityRoot struct {
 	}
 
 	Flow struct {
-		CreatedAt func(childComplexity int) int
-		ID        func(childComplexity int) int
-		Provider  func(childComplexity int) int
-		Status    func(childComplexity int) int
-		Terminals func(childComplexity int) int
-		Title     func(childComplexity int) int
-		UpdatedAt func(childComplexity int) int
+		AwaitingInput   func(childComplexity int) int
+		CreatedAt       func(childComplexity int) int
+		FinishReason    func(childComplexity int) int
+		ID              func(childComplexity int) int
+		LastError       func(childComplexity int) int
+		PendingQuestion func(childComplexity int) int
+		Provider        func(childComplexity int) int
+		Status          func(childComplexity int) int
+		StopReason      func(childComplexity int) int
+		Terminals       func(childComplexity int) int
+		Title           func(childComplexity int) int
+		UpdatedAt       func(childComplexity int) int
 	}
 
 	FlowAssistant struct {
@@ -241,6 +246,12 @@ type ComplexityRoot struct {
 		TotalToolcallsCount  func(childComplexity int) int
 	}
 
+	FlowLastError struct {
+		Count     func(childComplexity int) int
+		Message   func(childComplexity int) int
+		Timestamp func(childComplexity int) int
+	}
+
 	FlowStats struct {
 		TotalAssistantsCount func(childComplexity int) int
 		TotalSubtasksCount   func(childComplexity int) int
@@ -303,7 +314,7 @@ type ComplexityRoot struct {
 		CreateAssistant    func(childComplexity int, flowID int64, modelProvider string, input string, useAgents bool) int
 		CreateFlow         func(childComplexity int, modelProvider string, input string) int
 		CreatePrompt       func(childComplexity int, typeArg model.PromptType, template string) int
-		CreateProvider     func(childComplexity int, name string, typeArg model.ProviderType, agents model.AgentsConfig) int
+		CreateProvider     func(childComplexity int, name string, typeArg model.ProviderType, agents model.AgentsConfig, endpoint *model.ProviderEndpointInput, force *bool) int
 		DeleteAPIToken     func(childComplexity int, tokenID string) int
 		DeleteAssistant    func(childComplexity int, flowID int64, assistantID int64) int
 		DeleteFavoriteFlow func(childComplexity int, flowID int64) int
@@ -319,7 +330,7 @@ type ComplexityRoot struct {
 		TestProvider       func(childComplexity int, typeArg model.ProviderType, agents model.AgentsConfig) int
 		UpdateAPIToken     func(childComplexity int, tokenID string, input model.UpdateAPITokenInput) int
 		UpdatePrompt       func(childComplexity int, promptID int64, template string) int
-		UpdateProvider     func(childComplexity int, providerID int64, name string, agents model.AgentsConfig) int
+		UpdateProvider     func(childComplexity int, providerID int64, name string, agents model.AgentsConfig, endpoint *model.ProviderEndpointInput, force *bool) int
 		ValidatePrompt     func(childComplexity int, typeArg model.PromptType, template string) int
 	}
 
@@ -342,12 +353,20 @@ type ComplexityRoot struct {
 	}
 
 	ProviderConfig struct {
-		Agents    func(childComplexity int) int
-		CreatedAt func(childComplexity int) int
-		ID        func(childComplexity int) int
-		Name      func(childComplexity int) int
-		Type      func(childComplexity int) int
-		UpdatedAt func(childComplexity int) int
+		Agents          func(childComplexity int) int
+		AvailableModels func(childComplexity int) int
+		CreatedAt       func(childComplexity int) int
+		Endpoint        func(childComplexity int) int
+		ID              func(childComplexity int) int
+		Name            func(childComplexity int) int
+		Type            func(childComplexity int) int
+		UpdatedAt       func(childComplexity int) int
+	}
+
+	ProviderEndpoint struct {
+		BaseURL            func(childComplexity int) int
+		HeaderNames        func(childComplexity int) int
+		InsecureSkipVerify func(childComplexity int) int
 	}
 
 	ProviderTestResult struct {
@@ -641,8 +660,8 @@ type MutationResolver interface {
 	DeleteAssistant(ctx context.Context, flowID int64, assistantID int64) (model.ResultType, error)
 	TestAgent(ctx context.Context, typeArg model.ProviderType, agentType model.AgentConfigType, agent model.AgentConfig) (*model.AgentTestResult, error)
 	TestProvider(ctx context.Context, typeArg model.ProviderType, agents model.AgentsConfig) (*model.ProviderTestResult, error)
-	CreateProvider(ctx context.Context, name string, typeArg model.ProviderType, agents model.AgentsConfig) (*model.ProviderConfig, error)
-	UpdateProvider(ctx context.Context, providerID int64, name string, agents model.AgentsConfig) (*model.ProviderConfig, error)
+	CreateProvider(ctx context.Context, name string, typeArg model.ProviderType, agents model.AgentsConfig, endpoint *model.ProviderEndpointInput, force *bool) (*model.ProviderConfig, error)
+	UpdateProvider(ctx context.Context, providerID int64, name string, agents model.AgentsConfig, endpoint *model.ProviderEndpointInput, force *bool) (*model.ProviderConfig, error)
 	DeleteProvider(ctx context.Context, providerID int64) (model.ResultType, error)
 	ValidatePrompt(ctx context.Context, typeArg model.PromptType, template string) (*model.PromptValidationResult, error)
 	CreatePrompt(ctx context.Context, typeArg model.PromptType, template string) (*model.UserPrompt, error)
@@ -1527,6 +1546,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.DefaultProvidersConfig.Qwen(childComplexity), true
 
+	case "Flow.awaitingInput":
+		if e.complexity.Flow.AwaitingInput == nil {
+			break
+		}
+
+		return e.complexity.Flow.AwaitingInput(childComplexity), true
+
 	case "Flow.createdAt":
 		if e.complexity.Flow.CreatedAt == nil {
 			break
@@ -1534,6 +1560,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Flow.CreatedAt(childComplexity), true
 
+	case "Flow.finishReason":
+		if e.complexity.Flow.FinishReason == nil {
+			break
+		}
+
+		return e.complexity.Flow.FinishReason(childComplexity), true
+
 	case "Flow.id":
 		if e.complexity.Flow.ID == nil {
 			break
@@ -1541,6 +1574,20 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Flow.ID(childComplexity), true
 
+	case "Flow.lastError":
+		if e.complexity.Flow.LastError == nil {
+			break
+		}
+
+		return e.complexity.Flow.LastError(childComplexity), true
+
+	case "Flow.pendingQuestion":
+		if e.complexity.Flow.PendingQuestion == nil {
+			break
+		}
+
+		return e.complexity.Flow.PendingQuestion(childComplexity), true
+
 	case "Flow.provider":
 		if e.complexity.Flow.Provider == nil {
 			break
@@ -1555,6 +1602,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Flow.Status(childComplexity), true
 
+	case "Flow.stopReason":
+		if e.complexity.Flow.StopReason == nil {
+			break
+		}
+
+		return e.complexity.Flow.StopReason(childComplexity), true
+
 	case "Flow.terminals":
 		if e.complexity.Flow.Terminals == nil {
 			break
@@ -1632,6 +1686,27 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.FlowExecutionStats.TotalToolcallsCount(childComplexity), true
 
+	case "FlowLastError.count":
+		if e.complexity.FlowLastError.Count == nil {
+			break
+		}
+
+		return e.complexity.FlowLastError.Count(childComplexity), true
+
+	case "FlowLastError.message":
+		if e.complexity.FlowLastError.Message == nil {
+			break
+		}
+
+		return e.complexity.FlowLastError.Message(childComplexity), true
+
+	case "FlowLastError.timestamp":
+		if e.complexity.FlowLastError.Timestamp == nil {
+			break
+		}
+
+		return e.complexity.FlowLastError.Timestamp(childComplexity), true
+
 	case "FlowStats.totalAssistantsCount":
 		if e.complexity.FlowStats.TotalAssistantsCount == nil {
 			break
@@ -1952,7 +2027,7 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 			return 0, false
 		}
 
-		return e.complexity.Mutation.CreateProvider(childComplexity, args["name"].(string), args["type"].(model.ProviderType), args["agents"].(model.AgentsConfig)), true
+		return e.complexity.Mutation.CreateProvider(childComplexity, args["name"].(string), args["type"].(model.ProviderType), args["agents"].(model.AgentsConfig), args["endpoint"].(*model.ProviderEndpointInput), args["force"].(*bool)), true
 
 	case "Mutation.deleteAPIToken":
 		if e.complexity.Mutation.DeleteAPIToken == nil {
@@ -2144,7 +2219,7 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 			return 0, false
 		}
 
-		return e.complexity.Mutation.UpdateProvider(childComplexity, args["providerId"].(int64), args["name"].(string), args["agents"].(model.AgentsConfig)), true
+		return e.complexity.Mutation.UpdateProvider(childComplexity, args["providerId"].(int64), args["name"].(string), args["agents"].(model.AgentsConfig), args["endpoint"].(*model.ProviderEndpointInput), args["force"].(*bool)), true
 
 	case "Mutation.validatePrompt":
 		if e.complexity.Mutation.ValidatePrompt == nil {
@@ -2228,6 +2303,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.ProviderConfig.Agents(childComplexity), true
 
+	case "ProviderConfig.availableModels":
+		if e.complexity.ProviderConfig.AvailableModels == nil {
+			break
+		}
+
+		return e.complexity.ProviderConfig.AvailableModels(childComplexity), true
+
 	case "ProviderConfig.createdAt":
 		if e.complexity.ProviderConfig.CreatedAt == nil {
 			break
@@ -2235,6 +2317,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.ProviderConfig.CreatedAt(childComplexity), true
 
+	case "ProviderConfig.endpoint":
+		if e.complexity.ProviderConfig.Endpoint == nil {
+			break
+		}
+
+		return e.complexity.ProviderConfig.Endpoint(childComplexity), true
+
 	case "ProviderConfig.id":
 		if e.complexity.ProviderConfig.ID == nil {
 			break
@@ -2263,6 +2352,27 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.ProviderConfig.UpdatedAt(childComplexity), true
 
+	case "ProviderEndpoint.baseUrl":
+		if e.complexity.ProviderEndpoint.BaseURL == nil {
+			break
+		}
+
+		return e.complexity.ProviderEndpoint.BaseURL(childComplexity), true
+
+	case "ProviderEndpoint.headerNames":
+		if e.complexity.ProviderEndpoint.HeaderNames == nil {
+			break
+		}
+
+		return e.complexity.ProviderEndpoint.HeaderNames(childComplexity), true
+
+	case "ProviderEndpoint.insecureSkipVerify":
+		if e.complexity.ProviderEndpoint.InsecureSkipVerify == nil {
+			break
+		}
+
+		return e.complexity.ProviderEndpoint.InsecureSkipVerify(childComplexity), true
+
 	case "ProviderTestResult.adviser":
 		if e.complexity.ProviderTestResult.Adviser == nil {
 			break
@@ -3880,6 +3990,8 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 		ec.unmarshalInputAgentsConfigInput,
 		ec.unmarshalInputCreateAPITokenInput,
 		ec.unmarshalInputModelPriceInput,
+		ec.unmarshalInputProviderEndpointHeaderInput,
+		ec.unmarshalInputProviderEndpointInput,
 		ec.unmarshalInputReasoningConfigInput,
 		ec.unmarshalInputUpdateAPITokenInput,
 	)
@@ -4441,6 +4553,16 @@ func (ec *executionContext) field_Mutation_createProvider_args(ctx context.Conte
 		return nil, err
 	}
 	args["agents"] = arg2
+	arg3, err := ec.field_Mutation_createProvider_argsEndpoint(ctx, rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args["endpoint"] = arg3
+	arg4, err := ec.field_Mutation_createProvider_argsForce(ctx, rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args["force"] = arg4
 	return args, nil
 }
 func (ec *executionContext) field_Mutation_createProvider_argsName(
@@ -4509,6 +4631,50 @@ func (ec *executionContext) field_Mutation_createProvider_argsAgents(
 	return zeroVal, nil
 }
 
+func (ec *executionContext) field_Mutation_createProvider_argsEndpoint(
+	ctx context.Context,
+	rawArgs map[string]interface{},
+) (*model.ProviderEndpointInput, error) {
+	// We won't call the directive if the argument is null.
+	// Set call_argument_directives_with_null to true to call directives
+	// even if the argument is null.
+	_, ok := rawArgs["endpoint"]
+	if !ok {
+		var zeroVal *model.ProviderEndpointInput
+		return zeroVal, nil
+	}
+
+	ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField("endpoint"))
+	if tmp, ok := rawArgs["endpoint"]; ok {
+		return ec.unmarshalOProviderEndpointInput2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointInput(ctx, tmp)
+	}
+
+	var zeroVal *model.ProviderEndpointInput
+	return zeroVal, nil
+}
+
+func (ec *executionContext) field_Mutation_createProvider_argsForce(
+	ctx context.Context,
+	rawArgs map[string]interface{},
+) (*bool, error) {
+	// We won't call the directive if the argument is null.
+	// Set call_argument_directives_with_null to true to call directives
+	// even if the argument is null.
+	_, ok := rawArgs["force"]
+	if !ok {
+		var zeroVal *bool
+		return zeroVal, nil
+	}
+
+	ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField("force"))
+	if tmp, ok := rawArgs["force"]; ok {
+		return ec.unmarshalOBoolean2ᚖbool(ctx, tmp)
+	}
+
+	var zeroVal *bool
+	return zeroVal, nil
+}
+
 func (ec *executionContext) field_Mutation_deleteAPIToken_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -5250,6 +5416,16 @@ func (ec *executionContext) field_Mutation_updateProvider_args(ctx context.Conte
 		return nil, err
 	}
 	args["agents"] = arg2
+	arg3, err := ec.field_Mutation_updateProvider_argsEndpoint(ctx, rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args["endpoint"] = arg3
+	arg4, err := ec.field_Mutation_updateProvider_argsForce(ctx, rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args["force"] = arg4
 	return args, nil
 }
 func (ec *executionContext) field_Mutation_updateProvider_argsProviderID(
@@ -5318,6 +5494,50 @@ func (ec *executionContext) field_Mutation_updateProvider_argsAgents(
 	return zeroVal, nil
 }
 
+func (ec *executionContext) field_Mutation_updateProvider_argsEndpoint(
+	ctx context.Context,
+	rawArgs map[string]interface{},
+) (*model.ProviderEndpointInput, error) {
+	// We won't call the directive if the argument is null.
+	// Set call_argument_directives_with_null to true to call directives
+	// even if the argument is null.
+	_, ok := rawArgs["endpoint"]
+	if !ok {
+		var zeroVal *model.ProviderEndpointInput
+		return zeroVal, nil
+	}
+
+	ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField("endpoint"))
+	if tmp, ok := rawArgs["endpoint"]; ok {
+		return ec.unmarshalOProviderEndpointInput2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointInput(ctx, tmp)
+	}
+
+	var zeroVal *model.ProviderEndpointInput
+	return zeroVal, nil
+}
+
+func (ec *executionContext) field_Mutation_updateProvider_argsForce(
+	ctx context.Context,
+	rawArgs map[string]interface{},
+) (*bool, error) {
+	// We won't call the directive if the argument is null.
+	// Set call_argument_directives_with_null to true to call directives
+	// even if the argument is null.
+	_, ok := rawArgs["force"]
+	if !ok {
+		var zeroVal *bool
+		return zeroVal, nil
+	}
+
+	ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField("force"))
+	if tmp, ok := rawArgs["force"]; ok {
+		return ec.unmarshalOBoolean2ᚖbool(ctx, tmp)
+	}
+
+	var zeroVal *bool
+	return zeroVal, nil
+}
+
 func (ec *executionContext) field_Mutation_validatePrompt_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -11713,6 +11933,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_openai(_ context
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -11771,6 +11995,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_anthropic(_ cont
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -11826,6 +12054,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_gemini(_ context
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -11881,6 +12113,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_bedrock(_ contex
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -11936,6 +12172,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_ollama(_ context
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -11991,6 +12231,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_custom(_ context
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -12046,6 +12290,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_deepseek(_ conte
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -12101,6 +12349,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_glm(_ context.Co
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -12156,6 +12408,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_kimi(_ context.C
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -12211,6 +12467,10 @@ func (ec *executionContext) fieldContext_DefaultProvidersConfig_qwen(_ context.C
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -12547,6 +12807,222 @@ func (ec *executionContext) fieldContext_Flow_updatedAt(_ context.Context, field
 	return fc, nil
 }
 
+func (ec *executionContext) _Flow_stopReason(ctx context.Context, field graphql.CollectedField, obj *model.Flow) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Flow_stopReason(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.StopReason, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Flow_stopReason(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Flow",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Flow_finishReason(ctx context.Context, field graphql.CollectedField, obj *model.Flow) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Flow_finishReason(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.FinishReason, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Flow_finishReason(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Flow",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Flow_awaitingInput(ctx context.Context, field graphql.CollectedField, obj *model.Flow) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Flow_awaitingInput(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.AwaitingInput, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Flow_awaitingInput(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Flow",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Flow_pendingQuestion(ctx context.Context, field graphql.CollectedField, obj *model.Flow) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Flow_pendingQuestion(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PendingQuestion, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Flow_pendingQuestion(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Flow",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Flow_lastError(ctx context.Context, field graphql.CollectedField, obj *model.Flow) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Flow_lastError(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.LastError, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.FlowLastError)
+	fc.Result = res
+	return ec.marshalOFlowLastError2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐFlowLastError(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Flow_lastError(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Flow",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "message":
+				return ec.fieldContext_FlowLastError_message(ctx, field)
+			case "count":
+				return ec.fieldContext_FlowLastError_count(ctx, field)
+			case "timestamp":
+				return ec.fieldContext_FlowLastError_timestamp(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type FlowLastError", field.Name)
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _FlowAssistant_flow(ctx context.Context, field graphql.CollectedField, obj *model.FlowAssistant) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_FlowAssistant_flow(ctx, field)
 	if err != nil {
@@ -12600,6 +13076,16 @@ func (ec *executionContext) fieldContext_FlowAssistant_flow(_ context.Context, f
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -12945,6 +13431,138 @@ func (ec *executionContext) fieldContext_FlowExecutionStats_tasks(_ context.Cont
 	return fc, nil
 }
 
+func (ec *executionContext) _FlowLastError_message(ctx context.Context, field graphql.CollectedField, obj *model.FlowLastError) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FlowLastError_message(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Message, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_FlowLastError_message(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "FlowLastError",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _FlowLastError_count(ctx context.Context, field graphql.CollectedField, obj *model.FlowLastError) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FlowLastError_count(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Count, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_FlowLastError_count(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "FlowLastError",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _FlowLastError_timestamp(ctx context.Context, field graphql.CollectedField, obj *model.FlowLastError) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_FlowLastError_timestamp(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Timestamp, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_FlowLastError_timestamp(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "FlowLastError",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Time does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _FlowStats_totalTasksCount(ctx context.Context, field graphql.CollectedField, obj *model.FlowStats) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_FlowStats_totalTasksCount(ctx, field)
 	if err != nil {
@@ -14497,6 +15115,16 @@ func (ec *executionContext) fieldContext_Mutation_createFlow(ctx context.Context
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -15190,7 +15818,7 @@ func (ec *executionContext) _Mutation_createProvider(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().CreateProvider(rctx, fc.Args["name"].(string), fc.Args["type"].(model.ProviderType), fc.Args["agents"].(model.AgentsConfig))
+		return ec.resolvers.Mutation().CreateProvider(rctx, fc.Args["name"].(string), fc.Args["type"].(model.ProviderType), fc.Args["agents"].(model.AgentsConfig), fc.Args["endpoint"].(*model.ProviderEndpointInput), fc.Args["force"].(*bool))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -15223,6 +15851,10 @@ func (ec *executionContext) fieldContext_Mutation_createProvider(ctx context.Con
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -15259,7 +15891,7 @@ func (ec *executionContext) _Mutation_updateProvider(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().UpdateProvider(rctx, fc.Args["providerId"].(int64), fc.Args["name"].(string), fc.Args["agents"].(model.AgentsConfig))
+		return ec.resolvers.Mutation().UpdateProvider(rctx, fc.Args["providerId"].(int64), fc.Args["name"].(string), fc.Args["agents"].(model.AgentsConfig), fc.Args["endpoint"].(*model.ProviderEndpointInput), fc.Args["force"].(*bool))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -15292,6 +15924,10 @@ func (ec *executionContext) fieldContext_Mutation_updateProvider(ctx context.Con
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -16545,6 +17181,96 @@ func (ec *executionContext) fieldContext_ProviderConfig_agents(_ context.Context
 	return fc, nil
 }
 
+func (ec *executionContext) _ProviderConfig_endpoint(ctx context.Context, field graphql.CollectedField, obj *model.ProviderConfig) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Endpoint, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.ProviderEndpoint)
+	fc.Result = res
+	return ec.marshalOProviderEndpoint2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpoint(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ProviderConfig_endpoint(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ProviderConfig",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "baseUrl":
+				return ec.fieldContext_ProviderEndpoint_baseUrl(ctx, field)
+			case "headerNames":
+				return ec.fieldContext_ProviderEndpoint_headerNames(ctx, field)
+			case "insecureSkipVerify":
+				return ec.fieldContext_ProviderEndpoint_insecureSkipVerify(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type ProviderEndpoint", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ProviderConfig_availableModels(ctx context.Context, field graphql.CollectedField, obj *model.ProviderConfig) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ProviderConfig_availableModels(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.AvailableModels, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*int)
+	fc.Result = res
+	return ec.marshalOInt2ᚖint(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ProviderConfig_availableModels(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ProviderConfig",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _ProviderConfig_createdAt(ctx context.Context, field graphql.CollectedField, obj *model.ProviderConfig) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 	if err != nil {
@@ -16633,6 +17359,138 @@ func (ec *executionContext) fieldContext_ProviderConfig_updatedAt(_ context.Cont
 	return fc, nil
 }
 
+func (ec *executionContext) _ProviderEndpoint_baseUrl(ctx context.Context, field graphql.CollectedField, obj *model.ProviderEndpoint) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ProviderEndpoint_baseUrl(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.BaseURL, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ProviderEndpoint_baseUrl(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ProviderEndpoint",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ProviderEndpoint_headerNames(ctx context.Context, field graphql.CollectedField, obj *model.ProviderEndpoint) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ProviderEndpoint_headerNames(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.HeaderNames, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]string)
+	fc.Result = res
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ProviderEndpoint_headerNames(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ProviderEndpoint",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ProviderEndpoint_insecureSkipVerify(ctx context.Context, field graphql.CollectedField, obj *model.ProviderEndpoint) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ProviderEndpoint_insecureSkipVerify(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.InsecureSkipVerify, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ProviderEndpoint_insecureSkipVerify(_ context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ProviderEndpoint",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _ProviderTestResult_simple(ctx context.Context, field graphql.CollectedField, obj *model.ProviderTestResult) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_ProviderTestResult_simple(ctx, field)
 	if err != nil {
@@ -17535,6 +18393,10 @@ func (ec *executionContext) fieldContext_ProvidersConfig_userDefined(_ context.C
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -18761,6 +19623,16 @@ func (ec *executionContext) fieldContext_Query_flows(_ context.Context, field gr
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -18821,6 +19693,16 @@ func (ec *executionContext) fieldContext_Query_flow(ctx context.Context, field g
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -21896,6 +22778,16 @@ func (ec *executionContext) fieldContext_Subscription_flowCreated(_ context.Cont
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -21970,6 +22862,16 @@ func (ec *executionContext) fieldContext_Subscription_flowDeleted(_ context.Cont
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -22044,6 +22946,16 @@ func (ec *executionContext) fieldContext_Subscription_flowUpdated(_ context.Cont
 				return ec.fieldContext_Flow_createdAt(ctx, field)
 			case "updatedAt":
 				return ec.fieldContext_Flow_updatedAt(ctx, field)
+			case "stopReason":
+				return ec.fieldContext_Flow_stopReason(ctx, field)
+			case "finishReason":
+				return ec.fieldContext_Flow_finishReason(ctx, field)
+			case "awaitingInput":
+				return ec.fieldContext_Flow_awaitingInput(ctx, field)
+			case "pendingQuestion":
+				return ec.fieldContext_Flow_pendingQuestion(ctx, field)
+			case "lastError":
+				return ec.fieldContext_Flow_lastError(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Flow", field.Name)
 		},
@@ -23360,6 +24272,10 @@ func (ec *executionContext) fieldContext_Subscription_providerCreated(_ context.
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -23432,6 +24348,10 @@ func (ec *executionContext) fieldContext_Subscription_providerUpdated(_ context.
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -23504,6 +24424,10 @@ func (ec *executionContext) fieldContext_Subscription_providerDeleted(_ context.
 				return ec.fieldContext_ProviderConfig_type(ctx, field)
 			case "agents":
 				return ec.fieldContext_ProviderConfig_agents(ctx, field)
+			case "endpoint":
+				return ec.fieldContext_ProviderConfig_endpoint(ctx, field)
+			case "availableModels":
+				return ec.fieldContext_ProviderConfig_availableModels(ctx, field)
 			case "createdAt":
 				return ec.fieldContext_ProviderConfig_createdAt(ctx, field)
 			case "updatedAt":
@@ -29726,6 +30650,85 @@ func (ec *executionContext) unmarshalInputModelPriceInput(ctx context.Context, o
 	return it, nil
 }
 
+func (ec *executionContext) unmarshalInputProviderEndpointHeaderInput(ctx context.Context, obj interface{}) (model.ProviderEndpointHeaderInput, error) {
+	var it model.ProviderEndpointHeaderInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"name", "value"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "name":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Name = data
+		case "value":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("value"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Value = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputProviderEndpointInput(ctx context.Context, obj interface{}) (model.ProviderEndpointInput, error) {
+	var it model.ProviderEndpointInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	if _, present := asMap["insecureSkipVerify"]; !present {
+		asMap["insecureSkipVerify"] = false
+	}
+
+	fieldsInOrder := [...]string{"baseUrl", "headers", "insecureSkipVerify"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "baseUrl":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("baseUrl"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.BaseURL = data
+		case "headers":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("headers"))
+			data, err := ec.unmarshalOProviderEndpointHeaderInput2ᚕᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointHeaderInputᚄ(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Headers = data
+		case "insecureSkipVerify":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("insecureSkipVerify"))
+			data, err := ec.unmarshalOBoolean2ᚖbool(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.InsecureSkipVerify = data
+		}
+	}
+
+	return it, nil
+}
+
 func (ec *executionContext) unmarshalInputReasoningConfigInput(ctx context.Context, obj interface{}) (model.ReasoningConfig, error) {
 	var it model.ReasoningConfig
 	asMap := map[string]interface{}{}
@@ -30950,6 +31953,19 @@ func (ec *executionContext) _Flow(ctx context.Context, sel ast.SelectionSet, obj
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "stopReason":
+			out.Values[i] = ec._Flow_stopReason(ctx, field, obj)
+		case "finishReason":
+			out.Values[i] = ec._Flow_finishReason(ctx, field, obj)
+		case "awaitingInput":
+			out.Values[i] = ec._Flow_awaitingInput(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "pendingQuestion":
+			out.Values[i] = ec._Flow_pendingQuestion(ctx, field, obj)
+		case "lastError":
+			out.Values[i] = ec._Flow_lastError(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -31081,6 +32097,55 @@ func (ec *executionContext) _FlowExecutionStats(ctx context.Context, sel ast.Sel
 	return out
 }
 
+var flowLastErrorImplementors = []string{"FlowLastError"}
+
+func (ec *executionContext) _FlowLastError(ctx context.Context, sel ast.SelectionSet, obj *model.FlowLastError) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, flowLastErrorImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("FlowLastError")
+		case "message":
+			out.Values[i] = ec._FlowLastError_message(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "count":
+			out.Values[i] = ec._FlowLastError_count(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "timestamp":
+			out.Values[i] = ec._FlowLastError_timestamp(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
 var flowStatsImplementors = []string{"FlowStats"}
 
 func (ec *executionContext) _FlowStats(ctx context.Context, sel ast.SelectionSet, obj *model.FlowStats) graphql.Marshaler {
@@ -31841,6 +32906,10 @@ func (ec *executionContext) _ProviderConfig(ctx context.Context, sel ast.Selecti
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "endpoint":
+			out.Values[i] = ec._ProviderConfig_endpoint(ctx, field, obj)
+		case "availableModels":
+			out.Values[i] = ec._ProviderConfig_availableModels(ctx, field, obj)
 		case "createdAt":
 			out.Values[i] = ec._ProviderConfig_createdAt(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
@@ -31874,6 +32943,55 @@ func (ec *executionContext) _ProviderConfig(ctx context.Context, sel ast.Selecti
 	return out
 }
 
+var providerEndpointImplementors = []string{"ProviderEndpoint"}
+
+func (ec *executionContext) _ProviderEndpoint(ctx context.Context, sel ast.SelectionSet, obj *model.ProviderEndpoint) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, providerEndpointImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("ProviderEndpoint")
+		case "baseUrl":
+			out.Values[i] = ec._ProviderEndpoint_baseUrl(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "headerNames":
+			out.Values[i] = ec._ProviderEndpoint_headerNames(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "insecureSkipVerify":
+			out.Values[i] = ec._ProviderEndpoint_insecureSkipVerify(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
 var providerTestResultImplementors = []string{"ProviderTestResult"}
 
 func (ec *executionContext) _ProviderTestResult(ctx context.Context, sel ast.SelectionSet, obj *model.ProviderTestResult) graphql.Marshaler {
@@ -35409,6 +36527,11 @@ func (ec *executionContext) marshalNProviderConfig2ᚖpentagiᚋpkgᚋgraphᚋmo
 	return ec._ProviderConfig(ctx, sel, v)
 }
 
+func (ec *executionContext) unmarshalNProviderEndpointHeaderInput2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointHeaderInput(ctx context.Context, v interface{}) (*model.ProviderEndpointHeaderInput, error) {
+	res, err := ec.unmarshalInputProviderEndpointHeaderInput(ctx, v)
+	return &res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) marshalNProviderTestResult2pentagiᚋpkgᚋgraphᚋmodelᚐProviderTestResult(ctx context.Context, sel ast.SelectionSet, v model.ProviderTestResult) graphql.Marshaler {
 	return ec._ProviderTestResult(ctx, sel, &v)
 }
@@ -36490,6 +37613,13 @@ func (ec *executionContext) marshalOFlow2ᚕᚖpentagiᚋpkgᚋgraphᚋmodelᚐF
 	return ret
 }
 
+func (ec *executionContext) marshalOFlowLastError2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐFlowLastError(ctx context.Context, sel ast.SelectionSet, v *model.FlowLastError) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._FlowLastError(ctx, sel, v)
+}
+
 func (ec *executionContext) unmarshalOID2ᚖint64(ctx context.Context, v interface{}) (*int64, error) {
 	if v == nil {
 		return nil, nil
@@ -36701,6 +37831,41 @@ func (ec *executionContext) marshalOProviderConfig2ᚖpentagiᚋpkgᚋgraphᚋmo
 	return ec._ProviderConfig(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalOProviderEndpoint2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpoint(ctx context.Context, sel ast.SelectionSet, v *model.ProviderEndpoint) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._ProviderEndpoint(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalOProviderEndpointHeaderInput2ᚕᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointHeaderInputᚄ(ctx context.Context, v interface{}) ([]*model.ProviderEndpointHeaderInput, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var vSlice []interface{}
+	if v != nil {
+		vSlice = graphql.CoerceList(v)
+	}
+	var err error
+	res := make([]*model.ProviderEndpointHeaderInput, len(vSlice))
+	for i := range vSlice {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithIndex(i))
+		res[i], err = ec.unmarshalNProviderEndpointHeaderInput2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointHeaderInput(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) unmarshalOProviderEndpointInput2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐProviderEndpointInput(ctx context.Context, v interface{}) (*model.ProviderEndpointInput, error) {
+	if v == nil {
+		return nil, nil
+	}
+	res, err := ec.unmarshalInputProviderEndpointInput(ctx, v)
+	return &res, graphql.ErrorOnPath(ctx, err)
+}
+
 func (ec *executionContext) marshalOReasoningConfig2ᚖpentagiᚋpkgᚋgraphᚋmodelᚐReasoningConfig(ctx context.Context, sel ast.SelectionSet, v *model.ReasoningConfig) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
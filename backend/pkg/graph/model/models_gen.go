@@ -182,13 +182,18 @@ type DefaultProvidersConfig struct {
 }
 
 type Flow struct {
-	ID        int64       `json:"id"`
-	Title     string      `json:"title"`
-	Status    StatusType  `json:"status"`
-	Terminals []*Terminal `json:"terminals,omitempty"`
-	Provider  *Provider   `json:"provider"`
-	CreatedAt time.Time   `json:"createdAt"`
-	UpdatedAt time.Time   `json:"updatedAt"`
+	ID              int64          `json:"id"`
+	Title           string         `json:"title"`
+	Status          StatusType     `json:"status"`
+	Terminals       []*Terminal    `json:"terminals,omitempty"`
+	Provider        *Provider      `json:"provider"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	StopReason      *string        `json:"stopReason,omitempty"`
+	FinishReason    *string        `json:"finishReason,omitempty"`
+	AwaitingInput   bool           `json:"awaitingInput"`
+	PendingQuestion *string        `json:"pendingQuestion,omitempty"`
+	LastError       *FlowLastError `json:"lastError,omitempty"`
 }
 
 type FlowAssistant struct {
@@ -205,6 +210,12 @@ type FlowExecutionStats struct {
 	Tasks                []*TaskExecutionStats `json:"tasks"`
 }
 
+type FlowLastError struct {
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 type FlowStats struct {
 	TotalTasksCount      int `json:"totalTasksCount"`
 	TotalSubtasksCount   int `json:"totalSubtasksCount"`
@@ -282,12 +293,31 @@ type Provider struct {
 }
 
 type ProviderConfig struct {
-	ID        int64         `json:"id"`
-	Name      string        `json:"name"`
-	Type      ProviderType  `json:"type"`
-	Agents    *AgentsConfig `json:"agents"`
-	CreatedAt time.Time     `json:"createdAt"`
-	UpdatedAt time.Time     `json:"updatedAt"`
+	ID              int64             `json:"id"`
+	Name            string            `json:"name"`
+	Type            ProviderType      `json:"type"`
+	Agents          *AgentsConfig     `json:"agents"`
+	Endpoint        *ProviderEndpoint `json:"endpoint,omitempty"`
+	AvailableModels *int              `json:"availableModels,omitempty"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+type ProviderEndpoint struct {
+	BaseURL            string   `json:"baseUrl"`
+	HeaderNames        []string `json:"headerNames"`
+	InsecureSkipVerify bool     `json:"insecureSkipVerify"`
+}
+
+type ProviderEndpointHeaderInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ProviderEndpointInput struct {
+	BaseURL            string                         `json:"baseUrl"`
+	Headers            []*ProviderEndpointHeaderInput `json:"headers,omitempty"`
+	InsecureSkipVerify *bool                          `json:"insecureSkipVerify,omitempty"`
 }
 
 type ProviderTestResult struct {
@@ -880,6 +910,7 @@ const (
 	ProviderTypeGlm       ProviderType = "glm"
 	ProviderTypeKimi      ProviderType = "kimi"
 	ProviderTypeQwen      ProviderType = "qwen"
+	ProviderTypeMock      ProviderType = "mock"
 )
 
 var AllProviderType = []ProviderType{
@@ -893,11 +924,12 @@ var AllProviderType = []ProviderType{
 	ProviderTypeGlm,
 	ProviderTypeKimi,
 	ProviderTypeQwen,
+	ProviderTypeMock,
 }
 
 func (e ProviderType) IsValid() bool {
 	switch e {
-	case ProviderTypeOpenai, ProviderTypeAnthropic, ProviderTypeGemini, ProviderTypeBedrock, ProviderTypeOllama, ProviderTypeCustom, ProviderTypeDeepseek, ProviderTypeGlm, ProviderTypeKimi, ProviderTypeQwen:
+	case ProviderTypeOpenai, ProviderTypeAnthropic, ProviderTypeGemini, ProviderTypeBedrock, ProviderTypeOllama, ProviderTypeCustom, ProviderTypeDeepseek, ProviderTypeGlm, ProviderTypeKimi, ProviderTypeQwen, ProviderTypeMock:
 		return true
 	}
 	return false
@@ -1,11 +1,15 @@
 package graph
 
 import (
+	"context"
+
 	"pentagi/pkg/config"
 	"pentagi/pkg/controller"
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/providers"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/server/auth"
 	"pentagi/pkg/templates"
 
@@ -26,3 +30,23 @@ type Resolver struct {
 	Controller      controller.FlowController
 	Subscriptions   subscriptions.SubscriptionsController
 }
+
+// validateProvider probes the provider configuration before it's saved unless the save is forced for offline setups,
+// it returns the number of models available in the validated provider
+func (r *Resolver) validateProvider(
+	ctx context.Context,
+	prvtype provider.ProviderType,
+	cfg *pconfig.ProviderConfig,
+	force *bool,
+) (*int, error) {
+	if force != nil && *force {
+		return nil, nil
+	}
+
+	availableModels, err := r.ProvidersCtrl.ValidateProvider(ctx, prvtype, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &availableModels, nil
+}
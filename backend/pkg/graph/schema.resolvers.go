@@ -14,6 +14,7 @@ import (
 	"pentagi/pkg/database"
 	"pentagi/pkg/database/converter"
 	"pentagi/pkg/graph/model"
+	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/anthropic"
 	"pentagi/pkg/providers/bedrock"
 	"pentagi/pkg/providers/deepseek"
@@ -34,7 +35,7 @@ import (
 
 // CreateFlow is the resolver for the createFlow field.
 func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string, input string) (*model.Flow, error) {
-	uid, _, err := validatePermission(ctx, "flows.create")
+	uid, admin, err := validatePermission(ctx, "flows.create")
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +61,22 @@ func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string,
 	}
 	prvtype := prv.Type()
 
-	fw, err := r.Controller.CreateFlow(ctx, uid, input, prvname, prvtype, nil)
+	prvModels, err := r.ProvidersCtrl.GetProviderModels(ctx, prvname, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err := providers.CheckProviderModels(prvname, prv, prvModels); err != nil {
+		return nil, err
+	}
+
+	fw, err := r.Controller.CreateFlow(ctx, controller.CreateFlowOptions{
+		UserID:        uid,
+		Input:         input,
+		ProviderName:  prvname,
+		ProviderType:  prvtype,
+		ResponseCache: providers.ResponseCacheDisabled,
+		Launch:        controller.FlowLaunch{Admin: admin},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +114,7 @@ func (r *mutationResolver) PutUserInput(ctx context.Context, flowID int64, input
 		return model.ResultTypeError, err
 	}
 
-	if err := fw.PutInput(ctx, input); err != nil {
+	if _, err := fw.PutInput(ctx, input); err != nil {
 		return model.ResultTypeError, err
 	}
 
@@ -156,11 +172,8 @@ func (r *mutationResolver) DeleteFlow(ctx context.Context, flowID int64) (model.
 		"flow": flowID,
 	}).Debug("delete flow")
 
-	if fw, err := r.Controller.GetFlow(ctx, flowID); err == nil {
-		if err := fw.Finish(ctx); err != nil {
-			return model.ResultTypeError, err
-		}
-	} else if !errors.Is(err, controller.ErrFlowNotFound) {
+	// containers, snapshots, attachments and artifacts are kept to restore the flow until it's purged
+	if err := r.Controller.ArchiveFlow(ctx, flowID); err != nil && !errors.Is(err, controller.ErrFlowNotFound) {
 		return model.ResultTypeError, err
 	}
 
@@ -169,14 +182,18 @@ func (r *mutationResolver) DeleteFlow(ctx context.Context, flowID int64) (model.
 		return model.ResultTypeError, err
 	}
 
+	unlock := r.Controller.LockFlowContainers(flow.ID)
 	containers, err := r.DB.GetFlowContainers(ctx, flow.ID)
 	if err != nil {
+		unlock()
 		return model.ResultTypeError, err
 	}
 
 	if _, err := r.DB.DeleteFlow(ctx, flow.ID); err != nil {
+		unlock()
 		return model.ResultTypeError, err
 	}
+	unlock()
 
 	publisher := r.Subscriptions.NewFlowPublisher(flow.UserID, flow.ID)
 	publisher.FlowUpdated(ctx, flow, containers)
@@ -442,7 +459,7 @@ func (r *mutationResolver) TestProvider(ctx context.Context, typeArg model.Provi
 }
 
 // CreateProvider is the resolver for the createProvider field.
-func (r *mutationResolver) CreateProvider(ctx context.Context, name string, typeArg model.ProviderType, agents model.AgentsConfig) (*model.ProviderConfig, error) {
+func (r *mutationResolver) CreateProvider(ctx context.Context, name string, typeArg model.ProviderType, agents model.AgentsConfig, endpoint *model.ProviderEndpointInput, force *bool) (*model.ProviderConfig, error) {
 	uid, _, err := validatePermission(ctx, "settings.providers.edit")
 	if err != nil {
 		return nil, err
@@ -454,8 +471,13 @@ func (r *mutationResolver) CreateProvider(ctx context.Context, name string, type
 		"type": typeArg.String(),
 	}).Debug("create provider")
 
-	cfg := converter.ConvertAgentsConfigFromGqlModel(&agents)
+	cfg := converter.ConvertProviderConfigFromGqlModel(&agents, endpoint)
 	prvname, prvtype := provider.ProviderName(name), provider.ProviderType(typeArg)
+	availableModels, err := r.validateProvider(ctx, prvtype, cfg, force)
+	if err != nil {
+		return nil, err
+	}
+
 	prv, err := r.ProvidersCtrl.CreateProvider(ctx, uid, prvname, prvtype, cfg)
 	if err != nil {
 		return nil, err
@@ -463,11 +485,14 @@ func (r *mutationResolver) CreateProvider(ctx context.Context, name string, type
 
 	r.Subscriptions.NewFlowPublisher(uid, 0).ProviderCreated(ctx, prv, cfg)
 
-	return converter.ConvertProvider(prv, cfg), nil
+	result := converter.ConvertProvider(prv, cfg)
+	result.AvailableModels = availableModels
+
+	return result, nil
 }
 
 // UpdateProvider is the resolver for the updateProvider field.
-func (r *mutationResolver) UpdateProvider(ctx context.Context, providerID int64, name string, agents model.AgentsConfig) (*model.ProviderConfig, error) {
+func (r *mutationResolver) UpdateProvider(ctx context.Context, providerID int64, name string, agents model.AgentsConfig, endpoint *model.ProviderEndpointInput, force *bool) (*model.ProviderConfig, error) {
 	uid, _, err := validatePermission(ctx, "settings.providers.edit")
 	if err != nil {
 		return nil, err
@@ -479,8 +504,22 @@ func (r *mutationResolver) UpdateProvider(ctx context.Context, providerID int64,
 		"name":     name,
 	}).Debug("update provider")
 
-	cfg := converter.ConvertAgentsConfigFromGqlModel(&agents)
+	current, err := r.DB.GetUserProvider(ctx, database.GetUserProviderParams{
+		ID:     providerID,
+		UserID: uid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := converter.ConvertProviderConfigFromGqlModel(&agents, endpoint)
+	providers.RestoreEndpointHeaders(cfg, current.Config)
 	prvname := provider.ProviderName(name)
+	availableModels, err := r.validateProvider(ctx, provider.ProviderType(current.Type), cfg, force)
+	if err != nil {
+		return nil, err
+	}
+
 	prv, err := r.ProvidersCtrl.UpdateProvider(ctx, uid, providerID, prvname, cfg)
 	if err != nil {
 		return nil, err
@@ -488,7 +527,10 @@ func (r *mutationResolver) UpdateProvider(ctx context.Context, providerID int64,
 
 	r.Subscriptions.NewFlowPublisher(uid, 0).ProviderUpdated(ctx, prv, cfg)
 
-	return converter.ConvertProvider(prv, cfg), nil
+	result := converter.ConvertProvider(prv, cfg)
+	result.AvailableModels = availableModels
+
+	return result, nil
 }
 
 // DeleteProvider is the resolver for the deleteProvider field.
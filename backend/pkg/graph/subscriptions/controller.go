@@ -16,8 +16,9 @@ const (
 )
 
 type SubscriptionsController interface {
-	NewFlowSubscriber(userID, flowID int64) FlowSubscriber
-	NewFlowPublisher(userID, flowID int64) FlowPublisher
+	// NewFlowSubscriber returns subscriber which receives events of listed types only or all events by default
+	NewFlowSubscriber(userID, flowID int64, types ...EventType) FlowSubscriber
+	NewFlowPublisher(userID, flowID int64, opts ...FlowPublisherOption) FlowPublisher
 }
 
 type FlowContext interface {
@@ -55,6 +56,13 @@ type FlowSubscriber interface {
 	APITokenUpdated(ctx context.Context) (<-chan *model.APIToken, error)
 	APITokenDeleted(ctx context.Context) (<-chan *model.APIToken, error)
 	SettingsUserUpdated(ctx context.Context) (<-chan *model.UserPreferences, error)
+	// FlowEvents returns sequenced events of the flow replaying the ones published after lastSeq if it's set
+	FlowEvents(ctx context.Context, lastSeq *uint64) (<-chan FlowEvent, error)
+	// UserFlowsEvents returns sequenced creation events of the subscriber's own flows,
+	// the ones published after lastSeq are replayed if it's set
+	UserFlowsEvents(ctx context.Context, lastSeq *uint64) (<-chan FlowEvent, error)
+	// UserFlowsEventsAdmin returns sequenced creation events of the flows of all users
+	UserFlowsEventsAdmin(ctx context.Context, lastSeq *uint64) (<-chan FlowEvent, error)
 	FlowContext
 }
 
@@ -71,6 +79,7 @@ type FlowPublisher interface {
 	TerminalLogAdded(ctx context.Context, terminalLog database.Termlog)
 	MessageLogAdded(ctx context.Context, messageLog database.Msglog)
 	MessageLogUpdated(ctx context.Context, messageLog database.Msglog)
+	MessageChunk(ctx context.Context, chunk MessageChunk)
 	AgentLogAdded(ctx context.Context, agentLog database.Agentlog)
 	SearchLogAdded(ctx context.Context, searchLog database.Searchlog)
 	VectorStoreLogAdded(ctx context.Context, vectorStoreLog database.Vecstorelog)
@@ -114,80 +123,99 @@ type controller struct {
 	apiTokenUpdated     Channel[*model.APIToken]
 	apiTokenDeleted     Channel[*model.APIToken]
 	settingsUserUpdated Channel[*model.UserPreferences]
+	flowEvents          *flowEvents
+	userFlowsEvents     *flowEvents
 }
 
 func NewSubscriptionsController() SubscriptionsController {
 	return &controller{
-		flowCreatedAdmin:    NewChannel[*model.Flow](),
-		flowCreated:         NewChannel[*model.Flow](),
-		flowDeletedAdmin:    NewChannel[*model.Flow](),
-		flowDeleted:         NewChannel[*model.Flow](),
-		flowUpdatedAdmin:    NewChannel[*model.Flow](),
-		flowUpdated:         NewChannel[*model.Flow](),
-		taskCreated:         NewChannel[*model.Task](),
-		taskUpdated:         NewChannel[*model.Task](),
-		assistantCreated:    NewChannel[*model.Assistant](),
-		assistantUpdated:    NewChannel[*model.Assistant](),
-		assistantDeleted:    NewChannel[*model.Assistant](),
-		screenshotAdded:     NewChannel[*model.Screenshot](),
-		terminalLogAdded:    NewChannel[*model.TerminalLog](),
-		messageLogAdded:     NewChannel[*model.MessageLog](),
-		messageLogUpdated:   NewChannel[*model.MessageLog](),
-		agentLogAdded:       NewChannel[*model.AgentLog](),
-		searchLogAdded:      NewChannel[*model.SearchLog](),
-		vecStoreLogAdded:    NewChannel[*model.VectorStoreLog](),
-		assistantLogAdded:   NewChannel[*model.AssistantLog](),
-		assistantLogUpdated: NewChannel[*model.AssistantLog](),
-		providerCreated:     NewChannel[*model.ProviderConfig](),
-		providerUpdated:     NewChannel[*model.ProviderConfig](),
-		providerDeleted:     NewChannel[*model.ProviderConfig](),
-		apiTokenCreated:     NewChannel[*model.APIToken](),
-		apiTokenUpdated:     NewChannel[*model.APIToken](),
-		apiTokenDeleted:     NewChannel[*model.APIToken](),
-		settingsUserUpdated: NewChannel[*model.UserPreferences](),
+		flowCreatedAdmin:    NewChannel[*model.Flow](EventFlowCreated),
+		flowCreated:         NewChannel[*model.Flow](EventFlowCreated),
+		flowDeletedAdmin:    NewChannel[*model.Flow](EventFlowDeleted),
+		flowDeleted:         NewChannel[*model.Flow](EventFlowDeleted),
+		flowUpdatedAdmin:    NewChannel[*model.Flow](EventFlowUpdated),
+		flowUpdated:         NewChannel[*model.Flow](EventFlowUpdated),
+		taskCreated:         NewChannel[*model.Task](EventTaskCreated),
+		taskUpdated:         NewChannel[*model.Task](EventTaskUpdated),
+		assistantCreated:    NewChannel[*model.Assistant](EventAssistantCreated),
+		assistantUpdated:    NewChannel[*model.Assistant](EventAssistantUpdated),
+		assistantDeleted:    NewChannel[*model.Assistant](EventAssistantDeleted),
+		screenshotAdded:     NewChannel[*model.Screenshot](EventScreenshotAdded),
+		terminalLogAdded:    NewChannel[*model.TerminalLog](EventTerminalLogAdded),
+		messageLogAdded:     NewChannel[*model.MessageLog](EventMessageLogAdded),
+		messageLogUpdated:   NewChannel[*model.MessageLog](EventMessageLogUpdated),
+		agentLogAdded:       NewChannel[*model.AgentLog](EventAgentLogAdded),
+		searchLogAdded:      NewChannel[*model.SearchLog](EventSearchLogAdded),
+		vecStoreLogAdded:    NewChannel[*model.VectorStoreLog](EventVectorStoreLogAdded),
+		assistantLogAdded:   NewChannel[*model.AssistantLog](EventAssistantLogAdded),
+		assistantLogUpdated: NewChannel[*model.AssistantLog](EventAssistantLogUpdated),
+		providerCreated:     NewChannel[*model.ProviderConfig](EventProviderCreated),
+		providerUpdated:     NewChannel[*model.ProviderConfig](EventProviderUpdated),
+		providerDeleted:     NewChannel[*model.ProviderConfig](EventProviderDeleted),
+		apiTokenCreated:     NewChannel[*model.APIToken](EventAPITokenCreated),
+		apiTokenUpdated:     NewChannel[*model.APIToken](EventAPITokenUpdated),
+		apiTokenDeleted:     NewChannel[*model.APIToken](EventAPITokenDeleted),
+		settingsUserUpdated: NewChannel[*model.UserPreferences](EventSettingsUserUpdated),
+		flowEvents:          newFlowEvents(),
+		userFlowsEvents:     newFlowEvents(),
 	}
 }
 
-func (s *controller) NewFlowPublisher(userID, flowID int64) FlowPublisher {
-	return &flowPublisher{
+func (s *controller) NewFlowPublisher(userID, flowID int64, opts ...FlowPublisherOption) FlowPublisher {
+	p := &flowPublisher{
 		userID: userID,
 		flowID: flowID,
 		ctrl:   s,
+		mx:     &sync.Mutex{},
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
-func (s *controller) NewFlowSubscriber(userID, flowID int64) FlowSubscriber {
+func (s *controller) NewFlowSubscriber(userID, flowID int64, types ...EventType) FlowSubscriber {
 	return &flowSubscriber{
 		userID: userID,
 		flowID: flowID,
+		filter: NewEventFilter(types...),
 		ctrl:   s,
 	}
 }
 
 type Channel[T any] interface {
-	Subscribe(ctx context.Context, id int64) <-chan T
+	Subscribe(ctx context.Context, id int64, filter EventFilter) <-chan T
 	Publish(ctx context.Context, id int64, data T)
 	Broadcast(ctx context.Context, data T)
 }
 
-func NewChannel[T any]() Channel[T] {
+// NewChannel returns channel which tags all published data by the event type
+func NewChannel[T any](eventType EventType) Channel[T] {
 	return &channel[T]{
-		mx:   &sync.RWMutex{},
-		subs: make(map[int64][]chan T),
+		mx:        &sync.RWMutex{},
+		eventType: eventType,
+		subs:      make(map[int64][]subscription[T]),
 	}
 }
 
+type subscription[T any] struct {
+	ch     chan T
+	filter EventFilter
+}
+
 type channel[T any] struct {
-	mx   *sync.RWMutex
-	subs map[int64][]chan T
+	mx        *sync.RWMutex
+	eventType EventType
+	subs      map[int64][]subscription[T]
 }
 
-func (c *channel[T]) Subscribe(ctx context.Context, id int64) <-chan T {
+func (c *channel[T]) Subscribe(ctx context.Context, id int64, filter EventFilter) <-chan T {
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
 	ch := make(chan T, defChannelLen)
-	c.subs[id] = append(c.subs[id], ch)
+	c.subs[id] = append(c.subs[id], subscription[T]{ch: ch, filter: filter})
 
 	go func() {
 		<-ctx.Done()
@@ -197,7 +225,7 @@ func (c *channel[T]) Subscribe(ctx context.Context, id int64) <-chan T {
 
 		if subs, ok := c.subs[id]; ok {
 			for i, sub := range subs {
-				if sub == ch {
+				if sub.ch == ch {
 					c.subs[id] = append(subs[:i], subs[i+1:]...)
 					break
 				}
@@ -218,9 +246,14 @@ func (c *channel[T]) Publish(ctx context.Context, id int64, data T) {
 	c.mx.RLock()
 	defer c.mx.RUnlock()
 
-	for _, ch := range c.subs[id] {
+	for _, sub := range c.subs[id] {
+		// suppressed events are dropped before fan-out to not hold the publisher
+		if !sub.filter.Allows(c.eventType) {
+			continue
+		}
+
 		select {
-		case ch <- data:
+		case sub.ch <- data:
 		case <-ctx.Done():
 			return
 		}
@@ -232,9 +265,13 @@ func (c *channel[T]) Broadcast(ctx context.Context, data T) {
 	defer c.mx.RUnlock()
 
 	for _, subs := range c.subs {
-		for _, ch := range subs {
+		for _, sub := range subs {
+			if !sub.filter.Allows(c.eventType) {
+				continue
+			}
+
 			select {
-			case ch <- data:
+			case sub.ch <- data:
 			case <-ctx.Done():
 				return
 			}
@@ -0,0 +1,363 @@
+package subscriptions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter(t *testing.T) {
+	all := NewEventFilter()
+	assert.True(t, all.Allows(EventMessageLogAdded))
+	assert.True(t, all.Allows(EventTaskUpdated))
+
+	filter := NewEventFilter(EventTaskUpdated, EventFlowDeleted)
+	assert.True(t, filter.Allows(EventTaskUpdated))
+	assert.True(t, filter.Allows(EventFlowDeleted))
+	assert.False(t, filter.Allows(EventMessageLogAdded))
+	assert.False(t, filter.Allows(EventTaskCreated))
+
+	assert.NoError(t, EventSettingsUserUpdated.Valid())
+	assert.Error(t, EventType("message_added").Valid())
+}
+
+func TestFilteredSubscriberNeverSeesSuppressedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	subscriber := ctrl.NewFlowSubscriber(1, 1, EventTaskUpdated)
+
+	messagesCh, err := subscriber.MessageLogAdded(ctx)
+	require.NoError(t, err)
+	terminalCh, err := subscriber.TerminalLogAdded(ctx)
+	require.NoError(t, err)
+	tasksCh, err := subscriber.TaskUpdated(ctx)
+	require.NoError(t, err)
+
+	pub := ctrl.NewFlowPublisher(1, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// more events than the channel buffer, suppressed ones must not block the publisher
+		for i := 0; i < 2*defChannelLen; i++ {
+			pub.MessageLogAdded(ctx, database.Msglog{ID: int64(i), FlowID: 1})
+			pub.TerminalLogAdded(ctx, database.Termlog{ID: int64(i), FlowID: 1})
+		}
+		pub.TaskUpdated(ctx, database.Task{ID: 7, FlowID: 1}, []database.Subtask{{ID: 9, TaskID: 7}})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(defSendTimeout):
+		t.Fatal("publisher was blocked by suppressed events")
+	}
+
+	select {
+	case task := <-tasksCh:
+		assert.Equal(t, int64(7), task.ID)
+		require.Len(t, task.Subtasks, 1)
+	default:
+		t.Fatal("allowed event was not delivered")
+	}
+
+	assert.Empty(t, messagesCh)
+	assert.Empty(t, terminalCh)
+
+	cancel()
+	for range messagesCh {
+		t.Fatal("suppressed message log event was delivered")
+	}
+	for range terminalCh {
+		t.Fatal("suppressed terminal log event was delivered")
+	}
+}
+
+func TestFilteredSubscriberBroadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	filtered := ctrl.NewFlowSubscriber(1, 0, EventFlowDeleted)
+	unfiltered := ctrl.NewFlowSubscriber(2, 0)
+
+	filteredCh, err := filtered.FlowUpdatedAdmin(ctx)
+	require.NoError(t, err)
+	unfilteredCh, err := unfiltered.FlowUpdatedAdmin(ctx)
+	require.NoError(t, err)
+	deletedCh, err := filtered.FlowDeletedAdmin(ctx)
+	require.NoError(t, err)
+
+	pub := ctrl.NewFlowPublisher(3, 5)
+	pub.FlowUpdated(ctx, database.Flow{ID: 5}, nil)
+	pub.FlowDeleted(ctx, database.Flow{ID: 5}, nil)
+
+	assert.Empty(t, filteredCh)
+	require.Len(t, unfilteredCh, 1)
+	assert.Equal(t, int64(5), (<-unfilteredCh).ID)
+	require.Len(t, deletedCh, 1)
+	assert.Equal(t, int64(5), (<-deletedCh).ID)
+}
+
+func TestUnfilteredSubscriberReceivesAllEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	subscriber := ctrl.NewFlowSubscriber(1, 1)
+
+	messagesCh, err := subscriber.MessageLogAdded(ctx)
+	require.NoError(t, err)
+	tasksCh, err := subscriber.TaskCreated(ctx)
+	require.NoError(t, err)
+
+	pub := ctrl.NewFlowPublisher(1, 1)
+	pub.MessageLogAdded(ctx, database.Msglog{ID: 1, FlowID: 1})
+	pub.TaskCreated(ctx, database.Task{ID: 2, FlowID: 1}, nil)
+	// events of other flows are never delivered
+	ctrl.NewFlowPublisher(1, 2).TaskCreated(ctx, database.Task{ID: 3, FlowID: 2}, nil)
+
+	require.Len(t, messagesCh, 1)
+	assert.Equal(t, int64(1), (<-messagesCh).ID)
+	require.Len(t, tasksCh, 1)
+	assert.Equal(t, int64(2), (<-tasksCh).ID)
+}
+
+func receiveFlowEvents(t *testing.T, ch <-chan FlowEvent, count int) []FlowEvent {
+	t.Helper()
+
+	events := make([]FlowEvent, 0, count)
+	for len(events) < count {
+		select {
+		case event := <-ch:
+			events = append(events, event)
+		case <-time.After(defSendTimeout):
+			t.Fatalf("received %d of %d flow events", len(events), count)
+		}
+	}
+
+	return events
+}
+
+func TestFlowEventsReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	pub := ctrl.NewFlowPublisher(1, 1)
+	pub.TaskCreated(ctx, database.Task{ID: 1, FlowID: 1}, nil)
+	pub.MessageLogAdded(ctx, database.Msglog{ID: 2, FlowID: 1})
+	pub.TaskUpdated(ctx, database.Task{ID: 1, FlowID: 1}, nil)
+	ctrl.NewFlowPublisher(1, 2).TaskCreated(ctx, database.Task{ID: 3, FlowID: 2}, nil)
+
+	lastSeq := uint64(1)
+	eventsCh, err := ctrl.NewFlowSubscriber(1, 1).FlowEvents(ctx, &lastSeq)
+	require.NoError(t, err)
+	filteredCh, err := ctrl.NewFlowSubscriber(1, 1, EventTaskUpdated).FlowEvents(ctx, &lastSeq)
+	require.NoError(t, err)
+	liveCh, err := ctrl.NewFlowSubscriber(1, 1).FlowEvents(ctx, nil)
+	require.NoError(t, err)
+
+	pub.FlowUpdated(ctx, database.Flow{ID: 1}, nil)
+
+	events := receiveFlowEvents(t, eventsCh, 3)
+	assert.Equal(t, FlowEvent{Seq: 2, Type: EventMessageLogAdded, Data: events[0].Data}, events[0])
+	assert.Equal(t, uint64(3), events[1].Seq)
+	assert.Equal(t, EventTaskUpdated, events[1].Type)
+	assert.Equal(t, uint64(4), events[2].Seq)
+	assert.Equal(t, EventFlowUpdated, events[2].Type)
+
+	events = receiveFlowEvents(t, filteredCh, 1)
+	assert.Equal(t, uint64(3), events[0].Seq)
+
+	events = receiveFlowEvents(t, liveCh, 1)
+	assert.Equal(t, uint64(4), events[0].Seq)
+
+	assert.Empty(t, eventsCh)
+	assert.Empty(t, filteredCh)
+	assert.Empty(t, liveCh)
+}
+
+func TestFlowEventsMessageChunk(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	pub := ctrl.NewFlowPublisher(1, 1)
+	pub.TaskCreated(ctx, database.Task{ID: 1, FlowID: 1}, nil)
+
+	eventsCh, err := ctrl.NewFlowSubscriber(1, 1, EventMessageChunk).FlowEvents(ctx, nil)
+	require.NoError(t, err)
+
+	chunk := MessageChunk{FlowID: 1, StreamID: 5, Type: "answer", Content: "hel"}
+	pub.MessageChunk(ctx, chunk)
+	events := receiveFlowEvents(t, eventsCh, 1)
+	assert.Equal(t, FlowEvent{Seq: 1, Type: EventMessageChunk, Data: chunk}, events[0])
+
+	// chunks aren't replayed and don't take sequence numbers of the flow events
+	pub.TaskUpdated(ctx, database.Task{ID: 1, FlowID: 1}, nil)
+	lastSeq := uint64(0)
+	replayCh, err := ctrl.NewFlowSubscriber(1, 1).FlowEvents(ctx, &lastSeq)
+	require.NoError(t, err)
+	events = receiveFlowEvents(t, replayCh, 2)
+	assert.Equal(t, EventTaskCreated, events[0].Type)
+	assert.Equal(t, uint64(2), events[1].Seq)
+	assert.Equal(t, EventTaskUpdated, events[1].Type)
+	assert.Empty(t, replayCh)
+
+	// the slow subscriber misses chunks instead of blocking the provider stream
+	for i := 0; i < 2*defChannelLen; i++ {
+		pub.MessageChunk(ctx, chunk)
+	}
+	assert.Len(t, eventsCh, defChannelLen)
+}
+
+func TestFlowEventsResyncRequired(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	pub := ctrl.NewFlowPublisher(1, 1)
+	for i := 1; i <= defReplayLen+10; i++ {
+		pub.TerminalLogAdded(ctx, database.Termlog{ID: int64(i), FlowID: 1})
+	}
+
+	// the oldest buffered event still can be replayed after wrapping of the ring buffer
+	lastSeq := uint64(10)
+	eventsCh, err := ctrl.NewFlowSubscriber(1, 1).FlowEvents(ctx, &lastSeq)
+	require.NoError(t, err)
+	events := receiveFlowEvents(t, eventsCh, defReplayLen)
+	for i, event := range events {
+		require.Equal(t, lastSeq+uint64(i)+1, event.Seq)
+	}
+	assert.Empty(t, eventsCh)
+
+	for _, lastSeq := range []uint64{9, defReplayLen + 11} {
+		eventsCh, err := ctrl.NewFlowSubscriber(1, 1, EventTaskUpdated).FlowEvents(ctx, &lastSeq)
+		require.NoError(t, err)
+		events := receiveFlowEvents(t, eventsCh, 1)
+		assert.Equal(t, FlowEvent{Seq: defReplayLen + 10, Type: EventResyncRequired}, events[0])
+		assert.Empty(t, eventsCh)
+	}
+
+	// nothing is missed if the subscriber has received the last event
+	lastSeq = defReplayLen + 10
+	eventsCh, err = ctrl.NewFlowSubscriber(1, 1).FlowEvents(ctx, &lastSeq)
+	require.NoError(t, err)
+	assert.Empty(t, eventsCh)
+}
+
+func TestFlowPublisherCoalescing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	updatedCh, err := ctrl.NewFlowSubscriber(1, 1).FlowUpdated(ctx)
+	require.NoError(t, err)
+	eventsCh, err := ctrl.NewFlowSubscriber(1, 1).FlowEvents(ctx, nil)
+	require.NoError(t, err)
+
+	window := 100 * time.Millisecond
+	pub := ctrl.NewFlowPublisher(1, 1, WithFlowUpdatedCoalescing(window))
+
+	terms := make([]database.Container, 0, 3)
+	for i := 1; i <= 3*defChannelLen; i++ {
+		if i%defChannelLen == 0 {
+			terms = append(terms, database.Container{ID: int64(i), Name: "term", FlowID: 1})
+		}
+		pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "burst"}, terms)
+	}
+	pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "latest"}, terms)
+	// the caller reuses the containers slice after publishing
+	terms[0].Name = "changed"
+
+	select {
+	case flow := <-updatedCh:
+		assert.Equal(t, "latest", flow.Title)
+		require.Len(t, flow.Terminals, 3)
+		assert.Equal(t, "term", flow.Terminals[0].Name)
+		assert.Equal(t, int64(3*defChannelLen), flow.Terminals[2].ID)
+	case <-time.After(defSendTimeout):
+		t.Fatal("coalesced flow update was not published")
+	}
+
+	time.Sleep(2 * window)
+	assert.Empty(t, updatedCh)
+	events := receiveFlowEvents(t, eventsCh, 1)
+	assert.Equal(t, uint64(1), events[0].Seq)
+	assert.Empty(t, eventsCh)
+}
+
+func TestFlowPublisherCoalescingFlowDeleted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	subscriber := ctrl.NewFlowSubscriber(1, 1)
+	updatedCh, err := subscriber.FlowUpdated(ctx)
+	require.NoError(t, err)
+	deletedCh, err := subscriber.FlowDeleted(ctx)
+	require.NoError(t, err)
+
+	window := 50 * time.Millisecond
+	pub := ctrl.NewFlowPublisher(1, 1, WithFlowUpdatedCoalescing(window))
+	pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "update"}, nil)
+	pub.FlowDeleted(ctx, database.Flow{ID: 1, Title: "deleted"}, nil)
+
+	// the deletion is never delayed and the outdated update is dropped
+	require.Len(t, deletedCh, 1)
+	assert.Equal(t, "deleted", (<-deletedCh).Title)
+	time.Sleep(2 * window)
+	assert.Empty(t, updatedCh)
+
+	// publishers without coalescing send every update
+	pub = ctrl.NewFlowPublisher(1, 1)
+	pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "first"}, nil)
+	pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "second"}, nil)
+	assert.Len(t, updatedCh, 2)
+}
+
+func TestUserFlowsEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := NewSubscriptionsController()
+	ctrl.NewFlowPublisher(1, 1).FlowCreated(ctx, database.Flow{ID: 1, UserID: 1}, nil)
+
+	userCh, err := ctrl.NewFlowSubscriber(1, 0).UserFlowsEvents(ctx, nil)
+	require.NoError(t, err)
+	adminCh, err := ctrl.NewFlowSubscriber(3, 0).UserFlowsEventsAdmin(ctx, nil)
+	require.NoError(t, err)
+
+	ctrl.NewFlowPublisher(2, 2).FlowCreated(ctx, database.Flow{ID: 2, UserID: 2}, nil)
+	ctrl.NewFlowPublisher(1, 3).FlowCreated(ctx, database.Flow{ID: 3, UserID: 1}, nil)
+	// other events of the flows don't get into the user flows stream
+	ctrl.NewFlowPublisher(1, 3).TaskCreated(ctx, database.Task{ID: 1, FlowID: 3}, nil)
+
+	events := receiveFlowEvents(t, userCh, 1)
+	assert.Equal(t, uint64(2), events[0].Seq)
+	assert.Equal(t, EventFlowCreated, events[0].Type)
+	assert.Equal(t, int64(3), events[0].Data.(*model.Flow).ID)
+	assert.Empty(t, userCh)
+
+	events = receiveFlowEvents(t, adminCh, 2)
+	assert.Equal(t, int64(2), events[0].Data.(*model.Flow).ID)
+	assert.Equal(t, uint64(3), events[1].Seq)
+	assert.Equal(t, int64(3), events[1].Data.(*model.Flow).ID)
+	assert.Empty(t, adminCh)
+
+	// the flows created while the user was disconnected are replayed
+	lastSeq := uint64(0)
+	replayCh, err := ctrl.NewFlowSubscriber(1, 0).UserFlowsEvents(ctx, &lastSeq)
+	require.NoError(t, err)
+	events = receiveFlowEvents(t, replayCh, 2)
+	assert.Equal(t, int64(1), events[0].Data.(*model.Flow).ID)
+	assert.Equal(t, int64(3), events[1].Data.(*model.Flow).ID)
+	assert.Empty(t, replayCh)
+}
@@ -0,0 +1,95 @@
+package subscriptions
+
+import "fmt"
+
+// EventType is the kind of event published to the subscribers, every channel carries events of one type
+type EventType string
+
+const (
+	EventFlowCreated         EventType = "flow_created"
+	EventFlowDeleted         EventType = "flow_deleted"
+	EventFlowUpdated         EventType = "flow_updated"
+	EventTaskCreated         EventType = "task_created"
+	EventTaskUpdated         EventType = "task_updated"
+	EventAssistantCreated    EventType = "assistant_created"
+	EventAssistantUpdated    EventType = "assistant_updated"
+	EventAssistantDeleted    EventType = "assistant_deleted"
+	EventScreenshotAdded     EventType = "screenshot_added"
+	EventTerminalLogAdded    EventType = "terminal_log_added"
+	EventMessageLogAdded     EventType = "message_log_added"
+	EventMessageLogUpdated   EventType = "message_log_updated"
+	EventMessageChunk        EventType = "message_chunk"
+	EventAgentLogAdded       EventType = "agent_log_added"
+	EventSearchLogAdded      EventType = "search_log_added"
+	EventVectorStoreLogAdded EventType = "vector_store_log_added"
+	EventAssistantLogAdded   EventType = "assistant_log_added"
+	EventAssistantLogUpdated EventType = "assistant_log_updated"
+	EventProviderCreated     EventType = "provider_created"
+	EventProviderUpdated     EventType = "provider_updated"
+	EventProviderDeleted     EventType = "provider_deleted"
+	EventAPITokenCreated     EventType = "api_token_created"
+	EventAPITokenUpdated     EventType = "api_token_updated"
+	EventAPITokenDeleted     EventType = "api_token_deleted"
+	EventSettingsUserUpdated EventType = "settings_user_updated"
+	// EventResyncRequired is sent instead of replay when missed events are not buffered anymore
+	EventResyncRequired EventType = "resync_required"
+)
+
+func (t EventType) String() string {
+	return string(t)
+}
+
+// Valid is function to control input/output data
+func (t EventType) Valid() error {
+	switch t {
+	case EventFlowCreated, EventFlowDeleted, EventFlowUpdated,
+		EventTaskCreated, EventTaskUpdated,
+		EventAssistantCreated, EventAssistantUpdated, EventAssistantDeleted,
+		EventScreenshotAdded, EventTerminalLogAdded,
+		EventMessageLogAdded, EventMessageLogUpdated, EventMessageChunk,
+		EventAgentLogAdded, EventSearchLogAdded, EventVectorStoreLogAdded,
+		EventAssistantLogAdded, EventAssistantLogUpdated,
+		EventProviderCreated, EventProviderUpdated, EventProviderDeleted,
+		EventAPITokenCreated, EventAPITokenUpdated, EventAPITokenDeleted,
+		EventSettingsUserUpdated, EventResyncRequired:
+		return nil
+	default:
+		return fmt.Errorf("invalid EventType: %s", t)
+	}
+}
+
+// EventFilter is the set of event types delivered to the subscriber, empty filter passes all events
+type EventFilter map[EventType]struct{}
+
+func NewEventFilter(types ...EventType) EventFilter {
+	filter := make(EventFilter, len(types))
+	for _, t := range types {
+		filter[t] = struct{}{}
+	}
+
+	return filter
+}
+
+// Allows returns true if events of the type must be delivered to the subscriber
+func (f EventFilter) Allows(t EventType) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	_, ok := f[t]
+	return ok
+}
+
+// MessageChunk is the part of the agent message which is being streamed by the provider,
+// content and thinking are appended to the previous chunks of the same stream
+type MessageChunk struct {
+	FlowID    int64  `json:"flow_id"`
+	TaskID    *int64 `json:"task_id,omitempty"`
+	SubtaskID *int64 `json:"subtask_id,omitempty"`
+	StreamID  int64  `json:"stream_id"`
+	Type      string `json:"type"`
+	Content   string `json:"content,omitempty"`
+	Thinking  string `json:"thinking,omitempty"`
+	// Complete marks the last chunk of the stream, the whole message is delivered by message log events
+	Complete bool `json:"complete"`
+}
@@ -2,16 +2,40 @@ package subscriptions
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/database/converter"
 	"pentagi/pkg/providers/pconfig"
 )
 
+type FlowPublisherOption func(p *flowPublisher)
+
+// WithFlowUpdatedCoalescing debounces FlowUpdated events over the window and publishes
+// only the latest flow state, other events are published immediately
+func WithFlowUpdatedCoalescing(window time.Duration) FlowPublisherOption {
+	return func(p *flowPublisher) {
+		p.coalesce = window
+	}
+}
+
+// pendingFlowUpdate is the latest flow state waiting for the end of the coalescing window
+type pendingFlowUpdate struct {
+	ctx   context.Context
+	flow  database.Flow
+	terms []database.Container
+}
+
 type flowPublisher struct {
-	flowID int64
-	userID int64
-	ctrl   *controller
+	flowID   int64
+	userID   int64
+	ctrl     *controller
+	coalesce time.Duration
+	mx       *sync.Mutex
+	pending  *pendingFlowUpdate
+	timer    *time.Timer
 }
 
 func (p *flowPublisher) GetFlowID() int64 {
@@ -34,74 +58,162 @@ func (p *flowPublisher) FlowCreated(ctx context.Context, flow database.Flow, ter
 	flowModel := converter.ConvertFlow(flow, terms)
 	p.ctrl.flowCreated.Publish(ctx, p.userID, flowModel)
 	p.ctrl.flowCreatedAdmin.Broadcast(ctx, flowModel)
+	p.ctrl.flowEvents.Publish(ctx, flow.ID, EventFlowCreated, flowModel)
+	p.ctrl.userFlowsEvents.Publish(ctx, p.userID, EventFlowCreated, flowModel)
+	p.ctrl.userFlowsEvents.Publish(ctx, allUsersFlowsID, EventFlowCreated, flowModel)
 }
 
 func (p *flowPublisher) FlowDeleted(ctx context.Context, flow database.Flow, terms []database.Container) {
+	// pending update is outdated by the deletion, so it's dropped and the deletion is published right away,
+	// the lock keeps the deletion after the update which is being flushed now
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.pending != nil && p.pending.flow.ID == flow.ID {
+		p.timer.Stop()
+		p.timer, p.pending = nil, nil
+	}
+
 	flowModel := converter.ConvertFlow(flow, terms)
 	p.ctrl.flowDeleted.Publish(ctx, p.userID, flowModel)
 	p.ctrl.flowDeletedAdmin.Broadcast(ctx, flowModel)
+	p.ctrl.flowEvents.Publish(ctx, flow.ID, EventFlowDeleted, flowModel)
 }
 
 func (p *flowPublisher) FlowUpdated(ctx context.Context, flow database.Flow, terms []database.Container) {
+	if p.coalesce <= 0 {
+		p.publishFlowUpdated(ctx, flow, terms)
+		return
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	// updates of another flow can't be merged, so the pending one is published first
+	if p.pending != nil && p.pending.flow.ID != flow.ID {
+		p.timer.Stop()
+		pending := p.pending
+		p.timer, p.pending = nil, nil
+		p.publishFlowUpdated(pending.ctx, pending.flow, pending.terms)
+	}
+
+	// the caller may reuse the containers slice until the window ends
+	p.pending = &pendingFlowUpdate{ctx: context.WithoutCancel(ctx), flow: flow, terms: slices.Clone(terms)}
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.coalesce, p.flushFlowUpdated)
+	}
+}
+
+// flushFlowUpdated publishes the latest flow state at the end of the coalescing window
+func (p *flowPublisher) flushFlowUpdated() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	pending := p.pending
+	p.timer, p.pending = nil, nil
+	if pending == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(pending.ctx, defSendTimeout)
+	defer cancel()
+
+	p.publishFlowUpdated(ctx, pending.flow, pending.terms)
+}
+
+func (p *flowPublisher) publishFlowUpdated(ctx context.Context, flow database.Flow, terms []database.Container) {
 	flowModel := converter.ConvertFlow(flow, terms)
 	p.ctrl.flowUpdated.Publish(ctx, p.userID, flowModel)
 	p.ctrl.flowUpdatedAdmin.Broadcast(ctx, flowModel)
+	p.ctrl.flowEvents.Publish(ctx, flow.ID, EventFlowUpdated, flowModel)
 }
 
 func (p *flowPublisher) TaskCreated(ctx context.Context, task database.Task, subtasks []database.Subtask) {
-	p.ctrl.taskCreated.Publish(ctx, p.flowID, converter.ConvertTask(task, subtasks))
+	taskModel := converter.ConvertTask(task, subtasks)
+	p.ctrl.taskCreated.Publish(ctx, p.flowID, taskModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventTaskCreated, taskModel)
 }
 
 func (p *flowPublisher) TaskUpdated(ctx context.Context, task database.Task, subtasks []database.Subtask) {
-	p.ctrl.taskUpdated.Publish(ctx, p.flowID, converter.ConvertTask(task, subtasks))
+	taskModel := converter.ConvertTask(task, subtasks)
+	p.ctrl.taskUpdated.Publish(ctx, p.flowID, taskModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventTaskUpdated, taskModel)
 }
 
 func (p *flowPublisher) AssistantCreated(ctx context.Context, assistant database.Assistant) {
-	p.ctrl.assistantCreated.Publish(ctx, p.flowID, converter.ConvertAssistant(assistant))
+	assistantModel := converter.ConvertAssistant(assistant)
+	p.ctrl.assistantCreated.Publish(ctx, p.flowID, assistantModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventAssistantCreated, assistantModel)
 }
 
 func (p *flowPublisher) AssistantUpdated(ctx context.Context, assistant database.Assistant) {
-	p.ctrl.assistantUpdated.Publish(ctx, p.flowID, converter.ConvertAssistant(assistant))
+	assistantModel := converter.ConvertAssistant(assistant)
+	p.ctrl.assistantUpdated.Publish(ctx, p.flowID, assistantModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventAssistantUpdated, assistantModel)
 }
 
 func (p *flowPublisher) AssistantDeleted(ctx context.Context, assistant database.Assistant) {
-	p.ctrl.assistantDeleted.Publish(ctx, p.flowID, converter.ConvertAssistant(assistant))
+	assistantModel := converter.ConvertAssistant(assistant)
+	p.ctrl.assistantDeleted.Publish(ctx, p.flowID, assistantModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventAssistantDeleted, assistantModel)
 }
 
 func (p *flowPublisher) ScreenshotAdded(ctx context.Context, screenshot database.Screenshot) {
-	p.ctrl.screenshotAdded.Publish(ctx, p.flowID, converter.ConvertScreenshot(screenshot))
+	screenshotModel := converter.ConvertScreenshot(screenshot)
+	p.ctrl.screenshotAdded.Publish(ctx, p.flowID, screenshotModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventScreenshotAdded, screenshotModel)
 }
 
 func (p *flowPublisher) TerminalLogAdded(ctx context.Context, terminalLog database.Termlog) {
-	p.ctrl.terminalLogAdded.Publish(ctx, p.flowID, converter.ConvertTerminalLog(terminalLog))
+	terminalLogModel := converter.ConvertTerminalLog(terminalLog)
+	p.ctrl.terminalLogAdded.Publish(ctx, p.flowID, terminalLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventTerminalLogAdded, terminalLogModel)
 }
 
 func (p *flowPublisher) MessageLogAdded(ctx context.Context, messageLog database.Msglog) {
-	p.ctrl.messageLogAdded.Publish(ctx, p.flowID, converter.ConvertMessageLog(messageLog))
+	messageLogModel := converter.ConvertMessageLog(messageLog)
+	p.ctrl.messageLogAdded.Publish(ctx, p.flowID, messageLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventMessageLogAdded, messageLogModel)
 }
 
 func (p *flowPublisher) MessageLogUpdated(ctx context.Context, messageLog database.Msglog) {
-	p.ctrl.messageLogUpdated.Publish(ctx, p.flowID, converter.ConvertMessageLog(messageLog))
+	messageLogModel := converter.ConvertMessageLog(messageLog)
+	p.ctrl.messageLogUpdated.Publish(ctx, p.flowID, messageLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventMessageLogUpdated, messageLogModel)
+}
+
+func (p *flowPublisher) MessageChunk(ctx context.Context, chunk MessageChunk) {
+	p.ctrl.flowEvents.PublishTransient(ctx, p.flowID, EventMessageChunk, chunk)
 }
 
 func (p *flowPublisher) AgentLogAdded(ctx context.Context, agentLog database.Agentlog) {
-	p.ctrl.agentLogAdded.Publish(ctx, p.flowID, converter.ConvertAgentLog(agentLog))
+	agentLogModel := converter.ConvertAgentLog(agentLog)
+	p.ctrl.agentLogAdded.Publish(ctx, p.flowID, agentLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventAgentLogAdded, agentLogModel)
 }
 
 func (p *flowPublisher) SearchLogAdded(ctx context.Context, searchLog database.Searchlog) {
-	p.ctrl.searchLogAdded.Publish(ctx, p.flowID, converter.ConvertSearchLog(searchLog))
+	searchLogModel := converter.ConvertSearchLog(searchLog)
+	p.ctrl.searchLogAdded.Publish(ctx, p.flowID, searchLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventSearchLogAdded, searchLogModel)
 }
 
 func (p *flowPublisher) VectorStoreLogAdded(ctx context.Context, vectorStoreLog database.Vecstorelog) {
-	p.ctrl.vecStoreLogAdded.Publish(ctx, p.flowID, converter.ConvertVectorStoreLog(vectorStoreLog))
+	vectorStoreLogModel := converter.ConvertVectorStoreLog(vectorStoreLog)
+	p.ctrl.vecStoreLogAdded.Publish(ctx, p.flowID, vectorStoreLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventVectorStoreLogAdded, vectorStoreLogModel)
 }
 
 func (p *flowPublisher) AssistantLogAdded(ctx context.Context, assistantLog database.Assistantlog) {
-	p.ctrl.assistantLogAdded.Publish(ctx, p.flowID, converter.ConvertAssistantLog(assistantLog, false))
+	assistantLogModel := converter.ConvertAssistantLog(assistantLog, false)
+	p.ctrl.assistantLogAdded.Publish(ctx, p.flowID, assistantLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventAssistantLogAdded, assistantLogModel)
 }
 
 func (p *flowPublisher) AssistantLogUpdated(ctx context.Context, assistantLog database.Assistantlog, appendPart bool) {
-	p.ctrl.assistantLogUpdated.Publish(ctx, p.flowID, converter.ConvertAssistantLog(assistantLog, appendPart))
+	assistantLogModel := converter.ConvertAssistantLog(assistantLog, appendPart)
+	p.ctrl.assistantLogUpdated.Publish(ctx, p.flowID, assistantLogModel)
+	p.ctrl.flowEvents.Publish(ctx, p.flowID, EventAssistantLogUpdated, assistantLogModel)
 }
 
 func (p *flowPublisher) ProviderCreated(ctx context.Context, provider database.Provider, cfg *pconfig.ProviderConfig) {
@@ -0,0 +1,190 @@
+package subscriptions
+
+import (
+	"context"
+	"sync"
+)
+
+// defReplayLen is the number of recent events kept per flow to replay them after reconnect
+const defReplayLen = 512
+
+// allUsersFlowsID is the key of the user flows events stream which gets the flows of all users,
+// it never matches the user id because they start from one
+const allUsersFlowsID int64 = 0
+
+// FlowEvent is the flow scoped event with its sequence number, sequence numbers are increased
+// by one for every event of the flow so the subscriber can resume the stream after reconnect
+type FlowEvent struct {
+	Seq  uint64    `json:"seq"`
+	Type EventType `json:"type"`
+	Data any       `json:"data,omitempty"`
+}
+
+type flowEventsSub struct {
+	ch     chan FlowEvent
+	filter EventFilter
+}
+
+// flowEventsBuffer is the ring buffer of recent events of the flow
+type flowEventsBuffer struct {
+	mx     sync.Mutex
+	seq    uint64
+	events []FlowEvent
+	next   int
+	size   int
+	subs   map[*flowEventsSub]struct{}
+}
+
+// after returns buffered events published after the seq,
+// false is returned if some of them were already evicted from the buffer
+func (b *flowEventsBuffer) after(seq uint64) ([]FlowEvent, bool) {
+	// the sequence of the flow was started again, e.g. after the server restart
+	if seq > b.seq {
+		return nil, false
+	}
+
+	missed := int(b.seq - seq)
+	if missed > b.size {
+		return nil, false
+	}
+
+	events := make([]FlowEvent, 0, missed)
+	for i := len(b.events) - missed; i < len(b.events); i++ {
+		events = append(events, b.events[(b.next+i)%len(b.events)])
+	}
+
+	return events, true
+}
+
+func (b *flowEventsBuffer) push(event FlowEvent) {
+	b.events[b.next] = event
+	b.next = (b.next + 1) % len(b.events)
+	b.size = min(b.size+1, len(b.events))
+}
+
+// flowEvents numbers and buffers events of every flow and fans them out to the flow subscribers
+type flowEvents struct {
+	mx    *sync.Mutex
+	flows map[int64]*flowEventsBuffer
+}
+
+func newFlowEvents() *flowEvents {
+	return &flowEvents{
+		mx:    &sync.Mutex{},
+		flows: make(map[int64]*flowEventsBuffer),
+	}
+}
+
+func (fe *flowEvents) buffer(flowID int64) *flowEventsBuffer {
+	fe.mx.Lock()
+	defer fe.mx.Unlock()
+
+	b, ok := fe.flows[flowID]
+	if !ok {
+		b = &flowEventsBuffer{
+			events: make([]FlowEvent, defReplayLen),
+			subs:   make(map[*flowEventsSub]struct{}),
+		}
+		fe.flows[flowID] = b
+	}
+
+	return b
+}
+
+// Subscribe returns live events of the flow, if lastSeq is set the events published after it
+// are replayed first or the resync required event is sent if they are not buffered anymore
+func (fe *flowEvents) Subscribe(ctx context.Context, flowID int64, lastSeq *uint64, filter EventFilter) <-chan FlowEvent {
+	b := fe.buffer(flowID)
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	var replay []FlowEvent
+	if lastSeq != nil {
+		events, ok := b.after(*lastSeq)
+		if !ok {
+			events = []FlowEvent{{Seq: b.seq, Type: EventResyncRequired}}
+		}
+		for _, event := range events {
+			if event.Type == EventResyncRequired || filter.Allows(event.Type) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	// replayed events are put before registration so they always go ahead of live ones
+	sub := &flowEventsSub{ch: make(chan FlowEvent, defChannelLen+len(replay)), filter: filter}
+	for _, event := range replay {
+		sub.ch <- event
+	}
+	b.subs[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		delete(b.subs, sub)
+		close(sub.ch)
+	}()
+
+	return sub.ch
+}
+
+// Publish assigns the next sequence number of the flow to the event and delivers it to the subscribers,
+// the flow buffer is dropped after deletion so reconnected subscribers are asked to resync
+func (fe *flowEvents) Publish(ctx context.Context, flowID int64, eventType EventType, data any) {
+	b := fe.buffer(flowID)
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.seq++
+	event := FlowEvent{Seq: b.seq, Type: eventType, Data: data}
+	b.push(event)
+
+	for sub := range b.subs {
+		if !sub.filter.Allows(eventType) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	if eventType == EventFlowDeleted {
+		fe.mx.Lock()
+		if fe.flows[flowID] == b {
+			delete(fe.flows, flowID)
+		}
+		fe.mx.Unlock()
+	}
+}
+
+// PublishTransient delivers the event to the current subscribers without buffering it for replay,
+// the event gets the sequence number of the last buffered one so it doesn't break resuming of the stream,
+// slow subscribers miss the event instead of blocking the publisher
+func (fe *flowEvents) PublishTransient(ctx context.Context, flowID int64, eventType EventType, data any) {
+	b := fe.buffer(flowID)
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	event := FlowEvent{Seq: b.seq, Type: eventType, Data: data}
+	for sub := range b.subs {
+		if !sub.filter.Allows(eventType) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
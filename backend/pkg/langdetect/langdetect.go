@@ -0,0 +1,188 @@
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MinConfidence is the confidence below which the detected language shouldn't be trusted
+const MinConfidence = 0.5
+
+// minStopwords is the number of matched stopwords to trust the latin script language completely
+const minStopwords = 3
+
+// Result contains the detected language name and the confidence of the detection in the range [0, 1]
+type Result struct {
+	Language   string
+	Confidence float64
+}
+
+// IsConfident returns true if the language was detected with enough confidence to use it
+func (r Result) IsConfident() bool {
+	return r.Language != "" && r.Confidence >= MinConfidence
+}
+
+// stopwords are the most frequent short words of the latin script languages
+var stopwords = map[string][]string{
+	"English": {
+		"the", "and", "is", "are", "of", "to", "in", "for", "with", "on", "this", "that", "it", "be",
+		"from", "at", "all", "please", "what", "how", "can", "you", "find", "check", "any", "if",
+	},
+	"Spanish": {
+		"el", "la", "los", "las", "de", "del", "que", "y", "en", "un", "una", "es", "por", "para",
+		"con", "no", "se", "al", "lo", "como", "más", "está", "todos",
+	},
+	"French": {
+		"le", "la", "les", "des", "du", "de", "et", "est", "un", "une", "que", "pour", "dans", "sur",
+		"avec", "pas", "ce", "il", "vous", "sont", "au", "tous", "toutes",
+	},
+	"German": {
+		"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "von", "für",
+		"auf", "dem", "sich", "auch", "es", "ich", "sie", "bitte", "alle",
+	},
+	"Italian": {
+		"il", "di", "che", "e", "la", "un", "una", "per", "non", "con", "sono", "gli", "del", "della",
+		"è", "le", "alla", "questo", "anche", "tutte", "tutti",
+	},
+	"Portuguese": {
+		"o", "a", "os", "as", "de", "do", "da", "que", "e", "em", "um", "uma", "para", "com", "não",
+		"por", "é", "no", "na", "você", "são", "todas", "todos",
+	},
+	"Dutch": {
+		"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "met", "voor", "zijn", "ik",
+		"je", "aan", "ook", "er", "maar", "alle",
+	},
+	"Turkish": {
+		"ve", "bir", "bu", "da", "de", "için", "ile", "ne", "mi", "çok", "olarak", "gibi", "daha",
+		"ama", "var", "değil", "lütfen", "tüm",
+	},
+	"Polish": {
+		"i", "w", "z", "na", "się", "nie", "to", "do", "że", "jest", "jak", "po", "co", "ale", "od",
+		"dla", "tak", "przez", "wszystkie",
+	},
+}
+
+// letters are the characters which are used by the single latin script language of the list
+var letters = map[rune]string{
+	'ñ': "Spanish", '¿': "Spanish", '¡': "Spanish",
+	'ß': "German", 'ä': "German",
+	'ğ': "Turkish", 'ş': "Turkish", 'ı': "Turkish",
+	'ą': "Polish", 'ę': "Polish", 'ł': "Polish", 'ś': "Polish", 'ź': "Polish", 'ż': "Polish", 'ć': "Polish", 'ń': "Polish",
+	'ã': "Portuguese", 'õ': "Portuguese",
+	'œ': "French", 'ê': "French", 'è': "French", 'ë': "French", 'î': "French", 'û': "French",
+	'ì': "Italian", 'ò': "Italian",
+}
+
+// ukrainianLetters are the cyrillic letters which are not used in russian
+const ukrainianLetters = "іїєґ"
+
+var stopwordsIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect guesses the language of the text by the unicode scripts of its letters and, for the latin
+// script, by the frequent words, it returns the empty result if the text has no letters
+func Detect(text string) Result {
+	var total, latin, cyrillic, ukrainian, han, kana, hangul, arabic, devanagari int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if strings.ContainsRune(ukrainianLetters, unicode.ToLower(r)) {
+				ukrainian++
+			}
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Devanagari, r):
+			devanagari++
+		}
+	}
+	if total == 0 {
+		return Result{}
+	}
+
+	share := func(count int) float64 {
+		return float64(count) / float64(total)
+	}
+
+	// japanese text mixes kana with kanji, so any kana makes the han characters japanese
+	switch {
+	case kana > 0 && share(kana+han) >= 0.5:
+		return Result{Language: "Japanese", Confidence: share(kana + han)}
+	case share(han) >= 0.5:
+		return Result{Language: "Chinese", Confidence: share(han)}
+	case share(hangul) >= 0.5:
+		return Result{Language: "Korean", Confidence: share(hangul)}
+	case share(arabic) >= 0.5:
+		return Result{Language: "Arabic", Confidence: share(arabic)}
+	case share(devanagari) >= 0.5:
+		return Result{Language: "Hindi", Confidence: share(devanagari)}
+	case share(cyrillic) >= 0.5 && ukrainian > 0:
+		return Result{Language: "Ukrainian", Confidence: share(cyrillic)}
+	case share(cyrillic) >= 0.5:
+		return Result{Language: "Russian", Confidence: share(cyrillic)}
+	case latin == 0:
+		return Result{}
+	}
+
+	language, confidence := detectLatin(text)
+	return Result{Language: language, Confidence: confidence * share(latin)}
+}
+
+// detectLatin scores the latin script languages by the stopwords and the specific letters of the words,
+// the confidence is lowered if the best language is close to the next one or there are a few matches
+func detectLatin(text string) (string, float64) {
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '¿' && r != '¡'
+	})
+	for _, word := range words {
+		for _, language := range stopwordsIndex[word] {
+			scores[language]++
+		}
+		seen := make(map[string]bool)
+		for _, r := range word {
+			if language, ok := letters[r]; ok && !seen[language] {
+				seen[language] = true
+				scores[language]++
+			}
+		}
+	}
+
+	var best, second int
+	var language string
+	for name, score := range scores {
+		switch {
+		case score > best || score == best && name < language:
+			best, second, language = score, max(best, second), name
+		case score > second:
+			second = score
+		}
+	}
+	if best == 0 {
+		return "", 0
+	}
+
+	margin := float64(best-second) / float64(best)
+	support := min(1, float64(best)/minStopwords)
+
+	return language, (0.5 + 0.5*margin) * support
+}
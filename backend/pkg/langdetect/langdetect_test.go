@@ -0,0 +1,36 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Find all open ports on the target host and check if the web server is vulnerable":         "English",
+		"Найди все открытые порты на хосте и проверь уязвимости веб-сервера":                       "Russian",
+		"Знайди всі відкриті порти на хості та перевір вразливості веб-сервера":                    "Ukrainian",
+		"扫描目标主机的所有开放端口并检查网络服务器的漏洞":                                                                 "Chinese",
+		"ターゲットホストの開いているポートをすべて見つけてください":                                                            "Japanese",
+		"대상 호스트의 모든 열린 포트를 찾고 웹 서버의 취약점을 확인하세요":                                                    "Korean",
+		"Finde alle offenen Ports auf dem Host und prüfe, ob der Webserver verwundbar ist":         "German",
+		"Trouvez tous les ports ouverts sur la cible et vérifiez si le serveur est vulnérable":     "French",
+		"Encuentra todos los puertos abiertos en el host y comprueba si el servidor es vulnerable": "Spanish",
+		"Znajdź wszystkie otwarte porty na hoście i sprawdź, czy serwer jest podatny":              "Polish",
+	}
+	for text, expected := range cases {
+		result := Detect(text)
+		if result.Language != expected || !result.IsConfident() {
+			t.Errorf("Detect(%q) = %s (%.2f), want confident %s", text, result.Language, result.Confidence, expected)
+		}
+	}
+}
+
+func TestDetectLowConfidence(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{"", "10.0.0.1", "nmap -sV 10.0.0.0/24", "scan example.com"} {
+		if result := Detect(text); result.IsConfident() {
+			t.Errorf("Detect(%q) = %s (%.2f), want low confidence", text, result.Language, result.Confidence)
+		}
+	}
+}
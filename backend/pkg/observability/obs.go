@@ -410,6 +410,36 @@ func (obs *observer) SpanContextFromContext(ctx context.Context) oteltrace.SpanC
 	return oteltrace.SpanContextFromContext(ctx)
 }
 
+// TraceIDFromContext returns hex ID of the current trace or empty string if tracing is disabled
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.TraceID().IsValid() {
+		return sc.TraceID().String()
+	}
+
+	return ""
+}
+
+// ContextWithSpanContext binds the detached context (e.g. of the background worker)
+// to the current span of the parent one so new spans continue its trace
+func ContextWithSpanContext(ctx, parent context.Context) context.Context {
+	sc := oteltrace.SpanContextFromContext(parent)
+	if !sc.IsValid() {
+		return ctx
+	}
+
+	return oteltrace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// SetSpanError records the error to the span and marks it as failed, nil error is ignored
+func SetSpanError(span oteltrace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
 func (obs *observer) makeAttrs(entry *logrus.Entry, span oteltrace.Span) []attribute.KeyValue {
 	attrs := make([]attribute.KeyValue, 0, len(entry.Data)+2+3)
 
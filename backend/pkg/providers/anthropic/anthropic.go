@@ -22,6 +22,15 @@ const AnthropicAgentModel = "claude-sonnet-4-20250514"
 
 const AnthropicToolCallIDTemplate = "toolu_{r:24:b}"
 
+// PromptCacheStrategy places the cache breakpoints after the tools, the system prompt and the last message,
+// so the stable prefix of the chain is read from the cache on the next turn
+var PromptCacheStrategy = anthropic.CacheStrategy{
+	CacheTools:    true,
+	CacheSystem:   true,
+	CacheMessages: true,
+	TTL:           "5m",
+}
+
 func BuildProviderConfig(configData []byte) (*pconfig.ProviderConfig, error) {
 	defaultOptions := []llms.CallOption{
 		llms.WithModel(AnthropicAgentModel),
@@ -74,19 +83,18 @@ func New(cfg *config.Config, providerConfig *pconfig.ProviderConfig) (provider.P
 		return nil, err
 	}
 
-	client, err := anthropic.New(
+	opts := []anthropic.Option{
 		anthropic.WithToken(cfg.AnthropicAPIKey),
 		anthropic.WithModel(AnthropicAgentModel),
 		anthropic.WithBaseURL(baseURL),
 		anthropic.WithHTTPClient(httpClient),
+	}
+	if cfg.AnthropicPromptCaching {
 		// Enable prompt caching for cost optimization (90% savings on cached reads)
-		anthropic.WithDefaultCacheStrategy(anthropic.CacheStrategy{
-			CacheTools:    true,
-			CacheSystem:   true,
-			CacheMessages: true,
-			TTL:           "5m",
-		}),
-	)
+		opts = append(opts, anthropic.WithDefaultCacheStrategy(PromptCacheStrategy))
+	}
+
+	client, err := anthropic.New(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +183,11 @@ func (p *anthropicProvider) CallWithTools(
 }
 
 func (p *anthropicProvider) GetUsage(info map[string]any) pconfig.CallUsage {
-	return pconfig.NewCallUsage(info)
+	usage := pconfig.NewCallUsage(info)
+	// prompt tokens are the sum of uncached, cache read and cache creation input tokens
+	usage.InputWithCacheWrite = true
+
+	return usage
 }
 
 func (p *anthropicProvider) GetToolCallIDTemplate(ctx context.Context, prompter templates.Prompter) (string, error) {
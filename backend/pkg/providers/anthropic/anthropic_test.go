@@ -1,11 +1,19 @@
 package anthropic
 
 import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"pentagi/pkg/config"
 	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/providers/provider"
+
+	"github.com/vxcontrol/langchaingo/llms"
 )
 
 func TestConfigLoading(t *testing.T) {
@@ -104,3 +112,63 @@ func TestModelsLoading(t *testing.T) {
 		}
 	}
 }
+
+func TestPromptCaching(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		var body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{
+				"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-sonnet-4-20250514",
+				"content": [{"type": "text", "text": "pong"}], "stop_reason": "end_turn",
+				"usage": {"input_tokens": 100, "cache_creation_input_tokens": 2000,
+					"cache_read_input_tokens": 8000, "output_tokens": 10}
+			}`)
+		}))
+
+		cfg := &config.Config{
+			AnthropicAPIKey:        "test-key",
+			AnthropicServerURL:     server.URL,
+			AnthropicPromptCaching: enabled,
+		}
+
+		providerConfig, err := DefaultProviderConfig()
+		if err != nil {
+			t.Fatalf("Failed to create provider config: %v", err)
+		}
+
+		prov, err := New(cfg, providerConfig)
+		if err != nil {
+			t.Fatalf("Failed to create provider: %v", err)
+		}
+
+		chain := []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeSystem, "You are a penetration tester."),
+			llms.TextParts(llms.ChatMessageTypeHuman, "ping"),
+		}
+		resp, err := prov.CallEx(t.Context(), pconfig.OptionsTypeSimple, chain, nil)
+		server.Close()
+		if err != nil {
+			t.Fatalf("Failed to call provider: %v", err)
+		}
+
+		if cached := strings.Contains(body, "cache_control"); cached != enabled {
+			t.Errorf("Expected cache breakpoints in request to be %v, got %v", enabled, cached)
+		}
+
+		usage := prov.GetUsage(resp.Choices[0].GenerationInfo)
+		if usage.Input != 10100 || usage.CacheRead != 8000 || usage.CacheWrite != 2000 {
+			t.Errorf("Unexpected usage: %s", usage.String())
+		}
+
+		// uncached and cache write tokens must not be charged twice
+		price := &pconfig.PriceInfo{Input: 3.0, Output: 15.0, CacheRead: 0.3, CacheWrite: 3.75}
+		usage.UpdateCost(price)
+		expected := (100*3.0 + 8000*0.3 + 2000*3.75) / 1e6
+		if math.Abs(usage.CostInput-expected) > 1e-9 {
+			t.Errorf("Expected input cost %f, got %f", expected, usage.CostInput)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package custom
 
 import (
 	"context"
+	"crypto/tls"
+	"net/http"
 	"os"
 
 	"pentagi/pkg/config"
@@ -65,6 +67,15 @@ func New(cfg *config.Config, providerConfig *pconfig.ProviderConfig) (provider.P
 		return nil, err
 	}
 
+	// the provider configured by the user can target its own OpenAI-compatible server
+	if endpoint := providerConfig.GetEndpoint(); endpoint != nil {
+		if err := endpoint.Validate(); err != nil {
+			return nil, err
+		}
+		baseURL = endpoint.BaseURL
+		httpClient = newEndpointHTTPClient(httpClient, endpoint)
+	}
+
 	opts := []openai.Option{
 		openai.WithToken(baseKey),
 		openai.WithModel(baseModel),
@@ -103,6 +114,45 @@ func New(cfg *config.Config, providerConfig *pconfig.ProviderConfig) (provider.P
 	}, nil
 }
 
+// endpointTransport adds the custom headers of the endpoint to every request, they replace the default ones
+type endpointTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// newEndpointHTTPClient copies the client to apply the TLS verification toggle and the headers of the endpoint
+func newEndpointHTTPClient(client *http.Client, endpoint *pconfig.EndpointConfig) *http.Client {
+	result := *client
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	if endpoint.InsecureSkipVerify {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+	result.Transport = transport
+
+	if len(endpoint.Headers) != 0 {
+		result.Transport = &endpointTransport{headers: endpoint.Headers, base: transport}
+	}
+
+	return &result
+}
+
 func (p *customProvider) Type() provider.ProviderType {
 	return provider.ProviderCustom
 }
@@ -266,3 +266,51 @@ func TestProviderModelsIntegration(t *testing.T) {
 
 // TestPatchProviderConfigWithProviderName test removed - config patching is no longer used.
 // Prefix handling is now done at runtime via ModelWithPrefix() method.
+
+func TestProviderEndpoint(t *testing.T) {
+	var requests, unexpected int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/models" || r.Header.Get("X-Api-Key") != "endpoint-secret" {
+			unexpected++
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"data": [{"id": "local-model", "supported_parameters": ["tools"]}]}`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		LLMServerKey:   "test-key",
+		LLMServerURL:   "http://127.0.0.1:1/v1", // the env server must not be used
+		LLMServerModel: "local-model",
+	}
+
+	providerConfig, err := DefaultProviderConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create provider config: %v", err)
+	}
+	providerConfig.Endpoint = &pconfig.EndpointConfig{
+		BaseURL: server.URL + "/v1",
+		Headers: map[string]string{"X-Api-Key": "endpoint-secret"},
+	}
+
+	prov, err := New(cfg, providerConfig)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	if requests == 0 {
+		t.Fatal("Expected models to be requested from the endpoint")
+	}
+	if unexpected != 0 {
+		t.Errorf("Expected all requests to target the endpoint with custom headers, got %d unexpected", unexpected)
+	}
+	if models := prov.GetModels(); len(models) != 1 || models[0].Name != "local-model" {
+		t.Errorf("Expected the endpoint models, got %v", models)
+	}
+
+	providerConfig.Endpoint = &pconfig.EndpointConfig{BaseURL: "ftp://" + server.Listener.Addr().String()}
+	if _, err := New(cfg, providerConfig); err == nil {
+		t.Error("Expected error for the endpoint with invalid base URL")
+	}
+}
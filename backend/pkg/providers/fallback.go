@@ -0,0 +1,266 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sync"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/templates"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+// fallbackSwitchThreshold is the number of consecutive retryable errors of the active provider
+// after which the chain switches to the next one, it matches the agent chain retries budget
+const fallbackSwitchThreshold = maxRetriesToCallAgentChain
+
+// retryableStatusCodeRegex matches the transient HTTP status codes only next to the status keyword,
+// e.g. "unexpected status code: 529", "status 502" or "HTTP 429", the bare numbers are ignored
+var retryableStatusCodeRegex = regexp.MustCompile(
+	`(?i)\b(?:status(?:[ _]?code)?|http(?:/\d(?:\.\d)?)?)["']?\s*[:=]?\s*(?:429|500|502|503|504|529)\b`,
+)
+
+var retryableProviderErrorRegex = regexp.MustCompile(
+	`(?i)(rate.?limit|too many requests|overloaded|service unavailable|bad gateway|gateway timeout|timed? ?out|deadline exceeded)`,
+)
+
+// ProviderSwitchHandler is called when the fallback chain switches the active provider
+type ProviderSwitchHandler func(ctx context.Context, prvname provider.ProviderName, prv provider.Provider) error
+
+// FallbackProvider is a provider which delegates calls to the active provider of an ordered chain
+type FallbackProvider interface {
+	provider.Provider
+	ActiveName() provider.ProviderName
+	SetSwitchHandler(handler ProviderSwitchHandler)
+}
+
+type fallbackProvider struct {
+	mx       *sync.RWMutex
+	names    provider.ProvidersListNames
+	chain    []provider.Provider
+	current  int
+	failures int
+	onSwitch ProviderSwitchHandler
+}
+
+func newFallbackProvider(names provider.ProvidersListNames, chain []provider.Provider) (*fallbackProvider, error) {
+	if len(names) == 0 || len(names) != len(chain) {
+		return nil, fmt.Errorf("invalid fallback chain: %d names for %d providers", len(names), len(chain))
+	}
+
+	return &fallbackProvider{
+		mx:    &sync.RWMutex{},
+		names: names,
+		chain: chain,
+	}, nil
+}
+
+// isRetryableProviderError reports whether the error is transient (rate limit, 5xx, timeout)
+func isRetryableProviderError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrProviderTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var llmErr *llms.Error
+	if errors.As(err, &llmErr) {
+		switch llmErr.Code {
+		case llms.ErrCodeRateLimit, llms.ErrCodeTimeout, llms.ErrCodeProviderUnavailable:
+			return true
+		}
+	}
+
+	msg := err.Error()
+	return retryableStatusCodeRegex.MatchString(msg) || retryableProviderErrorRegex.MatchString(msg)
+}
+
+// IsProvidersExhaustedError reports whether the error of the agent chain is caused by the transient
+// provider errors which remained after all retries and fallback providers, only the innermost errors
+// are checked because the wrapping messages may contain arbitrary text of the agent
+func IsProvidersExhaustedError(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			if IsProvidersExhaustedError(err) {
+				return true
+			}
+		}
+		return false
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return IsProvidersExhaustedError(inner)
+		}
+	}
+
+	return isRetryableProviderError(err)
+}
+
+func (fb *fallbackProvider) ActiveName() provider.ProviderName {
+	fb.mx.RLock()
+	defer fb.mx.RUnlock()
+
+	return fb.names[fb.current]
+}
+
+func (fb *fallbackProvider) SetSwitchHandler(handler ProviderSwitchHandler) {
+	fb.mx.Lock()
+	defer fb.mx.Unlock()
+
+	fb.onSwitch = handler
+}
+
+func (fb *fallbackProvider) active() (int, provider.Provider) {
+	fb.mx.RLock()
+	defer fb.mx.RUnlock()
+
+	return fb.current, fb.chain[fb.current]
+}
+
+// registerResult updates failures counter of the provider which served the call and
+// returns true if the chain switched to the next provider and the call should be repeated
+func (fb *fallbackProvider) registerResult(ctx context.Context, idx int, err error) bool {
+	fb.mx.Lock()
+
+	if idx != fb.current {
+		// another call already switched the chain, just repeat on the new active provider
+		fb.mx.Unlock()
+		return err != nil && isRetryableProviderError(err)
+	}
+
+	if err == nil || !isRetryableProviderError(err) {
+		fb.failures = 0
+		fb.mx.Unlock()
+		return false
+	}
+
+	fb.failures++
+	if fb.failures < fallbackSwitchThreshold || fb.current+1 >= len(fb.chain) {
+		fb.mx.Unlock()
+		return false
+	}
+
+	fb.current++
+	fb.failures = 0
+	name, prv, onSwitch := fb.names[fb.current], fb.chain[fb.current], fb.onSwitch
+	fb.mx.Unlock()
+
+	logrus.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+		"from_provider": fb.names[idx].String(),
+		"to_provider":   name.String(),
+	}).Warn("provider returned retryable errors, switching to fallback provider")
+
+	if onSwitch != nil {
+		if herr := onSwitch(ctx, name, prv); herr != nil {
+			logrus.WithContext(ctx).WithError(herr).Error("failed to handle provider switch")
+		}
+	}
+
+	return true
+}
+
+func (fb *fallbackProvider) Type() provider.ProviderType {
+	_, prv := fb.active()
+	return prv.Type()
+}
+
+func (fb *fallbackProvider) Model(opt pconfig.ProviderOptionsType) string {
+	_, prv := fb.active()
+	return prv.Model(opt)
+}
+
+func (fb *fallbackProvider) ModelWithPrefix(opt pconfig.ProviderOptionsType) string {
+	_, prv := fb.active()
+	return prv.ModelWithPrefix(opt)
+}
+
+func (fb *fallbackProvider) GetUsage(info map[string]any) pconfig.CallUsage {
+	_, prv := fb.active()
+	return prv.GetUsage(info)
+}
+
+func (fb *fallbackProvider) Call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	prompt string,
+) (string, error) {
+	for {
+		idx, prv := fb.active()
+		result, err := prv.Call(ctx, opt, prompt)
+		if !fb.registerResult(ctx, idx, err) {
+			return result, err
+		}
+	}
+}
+
+func (fb *fallbackProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	for {
+		idx, prv := fb.active()
+		resp, err := prv.CallEx(ctx, opt, chain, streamCb)
+		if !fb.registerResult(ctx, idx, err) {
+			return resp, err
+		}
+	}
+}
+
+func (fb *fallbackProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	for {
+		idx, prv := fb.active()
+		resp, err := prv.CallWithTools(ctx, opt, chain, tools, streamCb)
+		if !fb.registerResult(ctx, idx, err) {
+			return resp, err
+		}
+	}
+}
+
+func (fb *fallbackProvider) GetRawConfig() []byte {
+	_, prv := fb.active()
+	return prv.GetRawConfig()
+}
+
+func (fb *fallbackProvider) GetProviderConfig() *pconfig.ProviderConfig {
+	_, prv := fb.active()
+	return prv.GetProviderConfig()
+}
+
+func (fb *fallbackProvider) GetPriceInfo(opt pconfig.ProviderOptionsType) *pconfig.PriceInfo {
+	_, prv := fb.active()
+	return prv.GetPriceInfo(opt)
+}
+
+func (fb *fallbackProvider) GetModels() pconfig.ModelsConfig {
+	_, prv := fb.active()
+	return prv.GetModels()
+}
+
+func (fb *fallbackProvider) GetToolCallIDTemplate(ctx context.Context, prompter templates.Prompter) (string, error) {
+	_, prv := fb.active()
+	return prv.GetToolCallIDTemplate(ctx, prompter)
+}
@@ -0,0 +1,225 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vxcontrol/langchaingo/llms"
+)
+
+// failingProvider returns the configured error from Call until calls counter reaches failN
+type failingProvider struct {
+	*mock.Provider
+	err   error
+	failN int
+	calls int
+}
+
+func (p *failingProvider) Call(ctx context.Context, opt pconfig.ProviderOptionsType, prompt string) (string, error) {
+	p.calls++
+	if p.failN < 0 || p.calls <= p.failN {
+		return "", p.err
+	}
+	return p.Provider.Call(ctx, opt, prompt)
+}
+
+func newFailingProvider(model string, err error, failN int) *failingProvider {
+	return &failingProvider{
+		Provider: mock.NewProvider(provider.ProviderOpenAI, model),
+		err:      err,
+		failN:    failN,
+	}
+}
+
+func TestIsRetryableProviderError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), true},
+		{"rate limit", errors.New("API returned 429 Too Many Requests"), true},
+		{"overloaded", errors.New("anthropic: overloaded_error"), true},
+		{"bad gateway", errors.New("status 502: bad gateway"), true},
+		{"invalid request", errors.New("status 400: invalid request"), false},
+		{"unauthorized", errors.New("status 401: invalid api key"), false},
+		{"status code", errors.New("API returned unexpected status code: 529"), true},
+		{"http status", errors.New("HTTP 503"), true},
+		{"typed rate limit", llms.NewError(llms.ErrCodeRateLimit, "openai", "slow down"), true},
+		{"typed auth", llms.NewError(llms.ErrCodeAuthentication, "openai", "invalid key"), false},
+		{"bare number", errors.New("context length 4500 exceeded at token 502"), false},
+		{"port in message", errors.New("dial tcp 10.0.0.1:500: connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryableProviderError(tt.err))
+		})
+	}
+}
+
+func TestIsProvidersExhaustedError(t *testing.T) {
+	providerErr := errors.New("API returned 429 Too Many Requests")
+	chainErr := fmt.Errorf("failed to call agent chain: max retries reached, 3: %w",
+		errors.Join(providerErr, providerErr, errors.New("reflector failed")))
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"agent chain retries", fmt.Errorf("failed to run task 1: %w", chainErr), true},
+		{"timeout", fmt.Errorf("subtask failed: %w", &ProviderTimeoutError{Provider: "openai"}), true},
+		{"canceled", fmt.Errorf("failed to run task 1: %w", context.Canceled), false},
+		{"invalid request", fmt.Errorf("failed: %w", errors.New("status 400: invalid request")), false},
+		{"wrapper text", fmt.Errorf("port 502 is filtered: %w", errors.New("invalid tool call")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsProvidersExhaustedError(tt.err))
+		})
+	}
+}
+
+func TestFallbackProviderSwitchesAfterThreshold(t *testing.T) {
+	primary := newFailingProvider("primary-model", errors.New("503 service unavailable"), -1)
+	secondary := newFailingProvider("secondary-model", nil, 0)
+
+	fb, err := newFallbackProvider(
+		provider.ProvidersListNames{"primary", "secondary"},
+		[]provider.Provider{primary, secondary},
+	)
+	require.NoError(t, err)
+
+	var switched []provider.ProviderName
+	fb.SetSwitchHandler(func(ctx context.Context, prvname provider.ProviderName, prv provider.Provider) error {
+		switched = append(switched, prvname)
+		assert.Equal(t, "secondary-model", prv.Model(pconfig.OptionsTypeSimple))
+		return nil
+	})
+
+	for i := 1; i < fallbackSwitchThreshold; i++ {
+		_, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+		require.Error(t, err)
+		assert.Equal(t, provider.ProviderName("primary"), fb.ActiveName())
+	}
+
+	result, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "Mock response", result)
+	assert.Equal(t, provider.ProviderName("secondary"), fb.ActiveName())
+	assert.Equal(t, "secondary-model", fb.Model(pconfig.OptionsTypeSimple))
+	assert.Equal(t, []provider.ProviderName{"secondary"}, switched)
+	assert.Equal(t, fallbackSwitchThreshold, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+}
+
+func TestFallbackProviderKeepsActiveOnPermanentErrors(t *testing.T) {
+	primary := newFailingProvider("primary-model", errors.New("status 400: invalid request"), -1)
+	secondary := newFailingProvider("secondary-model", nil, 0)
+
+	fb, err := newFallbackProvider(
+		provider.ProvidersListNames{"primary", "secondary"},
+		[]provider.Provider{primary, secondary},
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < fallbackSwitchThreshold*2; i++ {
+		_, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, provider.ProviderName("primary"), fb.ActiveName())
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFallbackProviderResetsFailuresOnSuccess(t *testing.T) {
+	primary := newFailingProvider("primary-model", errors.New("rate limit exceeded"), fallbackSwitchThreshold-1)
+	secondary := newFailingProvider("secondary-model", nil, 0)
+
+	fb, err := newFallbackProvider(
+		provider.ProvidersListNames{"primary", "secondary"},
+		[]provider.Provider{primary, secondary},
+	)
+	require.NoError(t, err)
+
+	for i := 1; i < fallbackSwitchThreshold; i++ {
+		_, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+		require.Error(t, err)
+	}
+
+	_, err = fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+	require.NoError(t, err)
+
+	primary.calls, primary.failN = 0, fallbackSwitchThreshold-1
+	for i := 1; i < fallbackSwitchThreshold; i++ {
+		_, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, provider.ProviderName("primary"), fb.ActiveName())
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFallbackProviderLastInChain(t *testing.T) {
+	primary := newFailingProvider("primary-model", errors.New("504 gateway timeout"), -1)
+
+	fb, err := newFallbackProvider(provider.ProvidersListNames{"primary"}, []provider.Provider{primary})
+	require.NoError(t, err)
+
+	for i := 0; i < fallbackSwitchThreshold+1; i++ {
+		_, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, provider.ProviderName("primary"), fb.ActiveName())
+	assert.Equal(t, fallbackSwitchThreshold+1, primary.calls)
+}
+
+func TestNewFallbackProviderInvalidChain(t *testing.T) {
+	_, err := newFallbackProvider(nil, nil)
+	assert.Error(t, err)
+
+	_, err = newFallbackProvider(provider.ProvidersListNames{"a", "b"}, []provider.Provider{mock.NewProvider(provider.ProviderOpenAI, "m")})
+	assert.Error(t, err)
+}
+
+func TestFlowProviderReportsCallResults(t *testing.T) {
+	prv := newFailingProvider("gpt-4", errors.New("API returned 429 Too Many Requests"), 2)
+	fp := newFlowProvider()
+	fp.Provider = prv
+
+	var results []error
+	fp.SetProviderErrorHandler(func(ctx context.Context, err error) error {
+		results = append(results, err)
+		return nil
+	})
+
+	for range 3 {
+		_, _ = fp.Call(t.Context(), pconfig.OptionsTypeSimple, "test")
+	}
+	require.Len(t, results, 3)
+	assert.EqualError(t, results[0], "API returned 429 Too Many Requests")
+	assert.EqualError(t, results[1], "API returned 429 Too Many Requests")
+	assert.NoError(t, results[2])
+
+	// calls aborted by the caller aren't provider failures
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	prv.failN = -1
+	prv.err = ctx.Err()
+	_, _ = fp.Call(ctx, pconfig.OptionsTypeSimple, "test")
+	assert.Len(t, results, 3)
+}
@@ -179,6 +179,11 @@ func (fp *flowProvider) getTasksInfo(ctx context.Context, taskID int64) (*tasksI
 		return nil, wrapErrorEndEvaluatorSpan(ctx, evaluator, "failed to get flow subtasks", err)
 	}
 
+	info.Attachments, err = fp.db.GetFlowAttachments(ctx, fp.flowID)
+	if err != nil {
+		return nil, wrapErrorEndEvaluatorSpan(ctx, evaluator, "failed to get flow attachments", err)
+	}
+
 	evaluator.End(
 		langfuse.WithEvaluatorOutput(map[string]any{
 			"task":           info.Task,
@@ -662,6 +667,7 @@ func (fp *flowProvider) prepareExecutionContext(ctx context.Context, taskID, sub
 		"CompletedSubtasks": subtasksInfo.Completed,
 		"Subtask":           subtasksInfo.Subtask,
 		"PlannedSubtasks":   subtasksInfo.Planned,
+		"Attachments":       tasksInfo.Attachments,
 	})
 	if err != nil {
 		return "", wrapErrorEndEvaluatorSpan(ctx, evaluator, "failed to render execution context", err)
@@ -716,6 +722,7 @@ func (fp *flowProvider) getExecutionContextByTask(ctx context.Context, taskID in
 		"CompletedSubtasks": subtasksInfo.Completed,
 		"Subtask":           subtasksInfo.Subtask,
 		"PlannedSubtasks":   subtasksInfo.Planned,
+		"Attachments":       tasksInfo.Attachments,
 	})
 	if err != nil {
 		return fp.getExecutionContextByFlow(ctx)
@@ -739,6 +746,11 @@ func (fp *flowProvider) getExecutionContextByFlow(ctx context.Context) (string,
 		return "", fmt.Errorf("failed to get flow subtasks: %w", err)
 	}
 
+	attachments, err := fp.db.GetFlowAttachments(ctx, fp.flowID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get flow attachments: %w", err)
+	}
+
 	for tid := len(tasks) - 1; tid >= 0; tid-- {
 		taskID := tasks[tid].ID
 
@@ -749,6 +761,7 @@ func (fp *flowProvider) getExecutionContextByFlow(ctx context.Context) (string,
 			"CompletedSubtasks": subtasksInfo.Completed,
 			"Subtask":           subtasksInfo.Subtask,
 			"PlannedSubtasks":   subtasksInfo.Planned,
+			"Attachments":       attachments,
 		})
 		if err != nil {
 			continue
@@ -763,6 +776,7 @@ func (fp *flowProvider) getExecutionContextByFlow(ctx context.Context) (string,
 		"CompletedSubtasks": subtasksInfo.Completed,
 		"Subtask":           subtasksInfo.Subtask,
 		"PlannedSubtasks":   subtasksInfo.Planned,
+		"Attachments":       attachments,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to render execution context: %w", err)
@@ -0,0 +1,344 @@
+package mock
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/templates"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed config.yml
+var configFS embed.FS
+
+const MockAgentModel = "mock"
+
+const MockToolCallIDTemplate = "call_{r:24:h}"
+
+// Fixture is the script of the mock provider, scenarios are checked in order and the first one
+// which matches the request is used, the default answer is returned if nothing matches
+type Fixture struct {
+	Default   string     `yaml:"default"`
+	Scenarios []Scenario `yaml:"scenarios"`
+}
+
+// Scenario is the scripted conversation of the agent, the step is chosen by the number of the agent
+// answers after the last human message, so the first step is returned for the new request, the second one
+// after the tool call result and so on, the last step is repeated when the steps are over
+type Scenario struct {
+	Name  string                      `yaml:"name"`
+	Agent pconfig.ProviderOptionsType `yaml:"agent"` // empty matches all agent types
+	Match string                      `yaml:"match"` // case insensitive substring of the human messages
+	Steps []Step                      `yaml:"steps"`
+}
+
+type Step struct {
+	Content   string     `yaml:"content"`
+	ToolCalls []ToolCall `yaml:"tool_calls"`
+}
+
+type ToolCall struct {
+	Name      string         `yaml:"name"`
+	Arguments map[string]any `yaml:"arguments"`
+}
+
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock fixture file: %w", err)
+	}
+
+	return LoadFixtureData(data)
+}
+
+func LoadFixtureData(data []byte) (*Fixture, error) {
+	var fixture Fixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse mock fixture: %w", err)
+	}
+
+	for idx, scenario := range fixture.Scenarios {
+		if len(scenario.Steps) == 0 {
+			return nil, fmt.Errorf("mock fixture scenario %d '%s' has no steps", idx, scenario.Name)
+		}
+		for _, step := range scenario.Steps {
+			if step.Content == "" && len(step.ToolCalls) == 0 {
+				return nil, fmt.Errorf("mock fixture scenario %d '%s' has empty step", idx, scenario.Name)
+			}
+			for _, call := range step.ToolCalls {
+				if call.Name == "" {
+					return nil, fmt.Errorf("mock fixture scenario %d '%s' has tool call without name", idx, scenario.Name)
+				}
+			}
+		}
+	}
+
+	if fixture.Default == "" {
+		fixture.Default = "Mock response"
+	}
+
+	return &fixture, nil
+}
+
+func BuildProviderConfig(configData []byte) (*pconfig.ProviderConfig, error) {
+	defaultOptions := []llms.CallOption{
+		llms.WithModel(MockAgentModel),
+		llms.WithN(1),
+	}
+
+	providerConfig, err := pconfig.LoadConfigData(configData, defaultOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return providerConfig, nil
+}
+
+func DefaultProviderConfig() (*pconfig.ProviderConfig, error) {
+	configData, err := configFS.ReadFile("config.yml")
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildProviderConfig(configData)
+}
+
+// mockProvider returns scripted deterministic responses from the fixture without network calls,
+// it's used to test flows end-to-end including the tool calls loop
+type mockProvider struct {
+	fixture        *Fixture
+	providerConfig *pconfig.ProviderConfig
+}
+
+func New(cfg *config.Config, providerConfig *pconfig.ProviderConfig) (provider.Provider, error) {
+	if cfg.MockProviderFixture == "" {
+		return nil, fmt.Errorf("missing MOCK_PROVIDER_FIXTURE_PATH environment variable")
+	}
+
+	fixture, err := LoadFixture(cfg.MockProviderFixture)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithFixture(fixture, providerConfig), nil
+}
+
+func NewWithFixture(fixture *Fixture, providerConfig *pconfig.ProviderConfig) provider.Provider {
+	return &mockProvider{
+		fixture:        fixture,
+		providerConfig: providerConfig,
+	}
+}
+
+func (p *mockProvider) Type() provider.ProviderType {
+	return provider.ProviderMock
+}
+
+func (p *mockProvider) GetRawConfig() []byte {
+	return p.providerConfig.GetRawConfig()
+}
+
+func (p *mockProvider) GetProviderConfig() *pconfig.ProviderConfig {
+	return p.providerConfig
+}
+
+func (p *mockProvider) GetPriceInfo(opt pconfig.ProviderOptionsType) *pconfig.PriceInfo {
+	return p.providerConfig.GetPriceInfoForType(opt)
+}
+
+func (p *mockProvider) GetModels() pconfig.ModelsConfig {
+	return pconfig.ModelsConfig{}
+}
+
+func (p *mockProvider) Model(opt pconfig.ProviderOptionsType) string {
+	model := MockAgentModel
+	opts := llms.CallOptions{Model: &model}
+	for _, option := range p.providerConfig.GetOptionsForType(opt) {
+		option(&opts)
+	}
+
+	return opts.GetModel()
+}
+
+func (p *mockProvider) ModelWithPrefix(opt pconfig.ProviderOptionsType) string {
+	return p.Model(opt)
+}
+
+func (p *mockProvider) Call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	prompt string,
+) (string, error) {
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, prompt)}
+	return p.respond(opt, chain, false).Content, nil
+}
+
+func (p *mockProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	return p.generate(ctx, opt, chain, false, streamCb)
+}
+
+func (p *mockProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	return p.generate(ctx, opt, chain, len(tools) != 0, streamCb)
+}
+
+func (p *mockProvider) GetUsage(info map[string]any) pconfig.CallUsage {
+	return pconfig.NewCallUsage(info)
+}
+
+func (p *mockProvider) GetToolCallIDTemplate(ctx context.Context, prompter templates.Prompter) (string, error) {
+	return MockToolCallIDTemplate, nil
+}
+
+func (p *mockProvider) generate(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	withTools bool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	choice := p.respond(opt, chain, withTools)
+
+	if streamCb != nil {
+		if choice.Content != "" {
+			if err := streaming.CallWithText(ctx, streamCb, choice.Content); err != nil {
+				return nil, err
+			}
+		}
+		if err := streaming.CallWithDone(ctx, streamCb); err != nil {
+			return nil, err
+		}
+	}
+
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+// respond builds the choice of the matched scenario step, tool calls are dropped if the request has no tools
+func (p *mockProvider) respond(
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	withTools bool,
+) *llms.ContentChoice {
+	human, answers := chainState(chain)
+
+	choice := &llms.ContentChoice{Content: p.fixture.Default}
+	if scenario := p.fixture.match(opt, human); scenario != nil {
+		step := scenario.Steps[min(answers, len(scenario.Steps)-1)]
+		choice.Content = step.Content
+		if withTools {
+			for idx, call := range step.ToolCalls {
+				args, _ := json.Marshal(call.Arguments)
+				choice.ToolCalls = append(choice.ToolCalls, llms.ToolCall{
+					ID:   toolCallID(len(chain), idx, call.Name),
+					Type: "function",
+					FunctionCall: &llms.FunctionCall{
+						Name:      call.Name,
+						Arguments: string(args),
+					},
+				})
+			}
+		}
+		if choice.Content == "" && len(choice.ToolCalls) == 0 {
+			choice.Content = p.fixture.Default
+		}
+	}
+
+	// tokens are estimated from the text length to make the usage and budgets deterministic
+	choice.GenerationInfo = map[string]any{
+		"PromptTokens":     estimateTokens(chainText(chain)),
+		"CompletionTokens": estimateTokens(choice.Content),
+	}
+
+	return choice
+}
+
+func (f *Fixture) match(opt pconfig.ProviderOptionsType, human string) *Scenario {
+	human = strings.ToLower(human)
+	for idx := range f.Scenarios {
+		scenario := &f.Scenarios[idx]
+		if scenario.Agent != "" && scenario.Agent != opt {
+			continue
+		}
+		if scenario.Match != "" && !strings.Contains(human, strings.ToLower(scenario.Match)) {
+			continue
+		}
+		return scenario
+	}
+
+	return nil
+}
+
+// chainState returns the text of the human messages and the number of the agent answers after the last one
+func chainState(chain []llms.MessageContent) (string, int) {
+	var (
+		human   []string
+		answers int
+	)
+
+	for _, msg := range chain {
+		switch msg.Role {
+		case llms.ChatMessageTypeHuman:
+			human = append(human, messageText(msg))
+			answers = 0
+		case llms.ChatMessageTypeAI:
+			answers++
+		}
+	}
+
+	return strings.Join(human, "\n"), answers
+}
+
+func chainText(chain []llms.MessageContent) string {
+	parts := make([]string, 0, len(chain))
+	for _, msg := range chain {
+		parts = append(parts, messageText(msg))
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func messageText(msg llms.MessageContent) string {
+	var parts []string
+	for _, part := range msg.Parts {
+		switch p := part.(type) {
+		case llms.TextContent:
+			parts = append(parts, p.Text)
+		case llms.ToolCallResponse:
+			parts = append(parts, p.Content)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+func estimateTokens(text string) int64 {
+	return int64(len(text)+3) / 4
+}
+
+// toolCallID returns deterministic id in the format of MockToolCallIDTemplate which is unique within the chain
+func toolCallID(chainLen, idx int, name string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d:%d:%s", chainLen, idx, name))
+	return "call_" + hex.EncodeToString(sum[:])[:24]
+}
@@ -0,0 +1,173 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+func newTestProvider(t *testing.T) provider.Provider {
+	t.Helper()
+
+	providerConfig, err := DefaultProviderConfig()
+	if err != nil {
+		t.Fatalf("Failed to create provider config: %v", err)
+	}
+
+	prov, err := New(&config.Config{MockProviderFixture: "testdata/flow.yml"}, providerConfig)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	return prov
+}
+
+func TestConfigLoading(t *testing.T) {
+	prov := newTestProvider(t)
+
+	if prov.Type() != provider.ProviderMock {
+		t.Errorf("Expected provider type %v, got %v", provider.ProviderMock, prov.Type())
+	}
+
+	for _, agentType := range pconfig.AllAgentTypes {
+		if model := prov.Model(agentType); model != MockAgentModel {
+			t.Errorf("Agent type %v should have model %s, got %s", agentType, MockAgentModel, model)
+		}
+
+		priceInfo := prov.GetPriceInfo(agentType)
+		if priceInfo != nil && (priceInfo.Input != 0 || priceInfo.Output != 0) {
+			t.Errorf("Agent type %v should be free, got %+v", agentType, priceInfo)
+		}
+	}
+
+	if _, err := New(&config.Config{}, nil); err == nil {
+		t.Error("Expected error without fixture path")
+	}
+}
+
+func TestToolCallsLoop(t *testing.T) {
+	prov := newTestProvider(t)
+	ctx := t.Context()
+	tools := []llms.Tool{{Type: "function", Function: &llms.FunctionDefinition{Name: "terminal"}}}
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "You are the pentester"),
+		llms.TextParts(llms.ChatMessageTypeHuman, "Scan the target 127.0.0.1"),
+	}
+
+	resp, err := prov.CallWithTools(ctx, pconfig.OptionsTypePrimaryAgent, chain, tools, nil)
+	if err != nil {
+		t.Fatalf("CallWithTools() unexpected error: %v", err)
+	}
+	choice := resp.Choices[0]
+	if choice.Content != "Running the scan" || len(choice.ToolCalls) != 1 {
+		t.Fatalf("Expected scripted tool call, got %+v", choice)
+	}
+
+	call := choice.ToolCalls[0]
+	var args map[string]any
+	if err := json.Unmarshal([]byte(call.FunctionCall.Arguments), &args); err != nil {
+		t.Fatalf("Tool call arguments should be JSON: %v", err)
+	}
+	if call.FunctionCall.Name != "terminal" || args["input"] != "nmap -sV 127.0.0.1" {
+		t.Errorf("Unexpected tool call %s(%s)", call.FunctionCall.Name, call.FunctionCall.Arguments)
+	}
+	if !strings.HasPrefix(call.ID, "call_") || len(call.ID) != len("call_")+24 {
+		t.Errorf("Tool call id %q doesn't match template %s", call.ID, MockToolCallIDTemplate)
+	}
+
+	usage := prov.GetUsage(choice.GenerationInfo)
+	if usage.Input == 0 || usage.Output == 0 {
+		t.Errorf("Expected estimated usage, got %+v", usage)
+	}
+
+	// the same request gets the same response
+	again, err := prov.CallWithTools(ctx, pconfig.OptionsTypePrimaryAgent, chain, tools, nil)
+	if err != nil {
+		t.Fatalf("CallWithTools() unexpected error: %v", err)
+	}
+	if again.Choices[0].ToolCalls[0].ID != call.ID {
+		t.Error("Expected deterministic tool call id")
+	}
+
+	chain = append(chain,
+		llms.MessageContent{Role: llms.ChatMessageTypeAI, Parts: []llms.ContentPart{call}},
+		llms.MessageContent{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{
+			llms.ToolCallResponse{ToolCallID: call.ID, Name: "terminal", Content: "22/tcp open ssh"},
+		}},
+	)
+
+	var streamed string
+	streamCb := func(ctx context.Context, chunk streaming.Chunk) error {
+		streamed += chunk.Content
+		return nil
+	}
+	resp, err = prov.CallWithTools(ctx, pconfig.OptionsTypePrimaryAgent, chain, tools, streamCb)
+	if err != nil {
+		t.Fatalf("CallWithTools() unexpected error: %v", err)
+	}
+	choice = resp.Choices[0]
+	if len(choice.ToolCalls) != 1 || choice.ToolCalls[0].FunctionCall.Name != "done" {
+		t.Fatalf("Expected final done call after the tool result, got %+v", choice)
+	}
+	if choice.ToolCalls[0].ID == call.ID {
+		t.Error("Expected unique tool call id within the chain")
+	}
+	if streamed != "" {
+		t.Errorf("Expected no streamed text for the step without content, got %q", streamed)
+	}
+}
+
+func TestUnmatchedRequests(t *testing.T) {
+	prov := newTestProvider(t)
+	ctx := t.Context()
+
+	title, err := prov.Call(ctx, pconfig.OptionsTypeSimple, "generate the flow title")
+	if err != nil || title != "Mock flow" {
+		t.Errorf("Expected scripted title, got %q, err %v", title, err)
+	}
+
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Scan the target")}
+	resp, err := prov.CallEx(ctx, pconfig.OptionsTypePrimaryAgent, chain, nil)
+	if err != nil {
+		t.Fatalf("CallEx() unexpected error: %v", err)
+	}
+	if resp.Choices[0].Content != "Running the scan" || len(resp.Choices[0].ToolCalls) != 0 {
+		t.Errorf("Expected tool calls to be dropped without tools, got %+v", resp.Choices[0])
+	}
+
+	resp, err = prov.CallEx(ctx, pconfig.OptionsTypeAdviser, chain, nil)
+	if err != nil {
+		t.Fatalf("CallEx() unexpected error: %v", err)
+	}
+	if resp.Choices[0].Content != "Mock response" {
+		t.Errorf("Expected default answer, got %q", resp.Choices[0].Content)
+	}
+}
+
+func TestLoadFixtureDataValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid yaml", "scenarios: ["},
+		{"no steps", "scenarios:\n  - name: empty\n"},
+		{"empty step", "scenarios:\n  - steps:\n      - content: \"\"\n"},
+		{"tool call without name", "scenarios:\n  - steps:\n      - tool_calls:\n          - arguments: {a: 1}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadFixtureData([]byte(tt.data)); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+)
+
+const (
+	// modelsCacheTTL is period to keep the provider models list, it's short because user providers
+	// can load the list from the remote endpoint which may change it
+	modelsCacheTTL = 5 * time.Minute
+	// modelsHintLimit limits the number of available models in the unsupported model error
+	modelsHintLimit = 10
+)
+
+var ErrUnsupportedModel = errors.New("model is not supported by provider")
+
+var ErrFunctionsNotSupported = errors.New("model doesn't support function calling")
+
+// FunctionsEmulator is implemented by providers which can emulate function calling in the prompt
+// for models without native tools support
+type FunctionsEmulator interface {
+	EmulatesFunctions(model string) bool
+}
+
+// FunctionsSupport describes the function calling capability of the provider model
+type FunctionsSupport struct {
+	// Native is false only if the provider reports that the model has no tools support
+	Native bool `json:"native"`
+	// Emulation is true if the provider can emulate function calling in the prompt for the model
+	Emulation bool `json:"emulation"`
+}
+
+// Supported returns true if the model can call functions natively or by the allowed emulation
+func (fs FunctionsSupport) Supported(allowEmulation bool) bool {
+	return fs.Native || (allowEmulation && fs.Emulation)
+}
+
+type modelsKey struct {
+	userID  int64
+	prvname provider.ProviderName
+}
+
+type modelsEntry struct {
+	models  pconfig.ModelsConfig
+	expires time.Time
+}
+
+// modelsCatalog caches available models of the providers per user because user providers
+// with the same name may have different configs
+type modelsCatalog struct {
+	now func() time.Time
+
+	mx    sync.Mutex
+	cache map[modelsKey]modelsEntry
+}
+
+func newModelsCatalog() *modelsCatalog {
+	return &modelsCatalog{
+		now:   time.Now,
+		cache: make(map[modelsKey]modelsEntry),
+	}
+}
+
+func (mc *modelsCatalog) get(userID int64, prvname provider.ProviderName) (pconfig.ModelsConfig, bool) {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	key := modelsKey{userID: userID, prvname: prvname}
+	entry, ok := mc.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if !mc.now().Before(entry.expires) {
+		delete(mc.cache, key)
+		return nil, false
+	}
+
+	return entry.models, true
+}
+
+func (mc *modelsCatalog) set(userID int64, prvname provider.ProviderName, models pconfig.ModelsConfig) {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	mc.cache[modelsKey{userID: userID, prvname: prvname}] = modelsEntry{
+		models:  models,
+		expires: mc.now().Add(modelsCacheTTL),
+	}
+}
+
+// forget drops cached models of the user provider after its config was changed
+func (mc *modelsCatalog) forget(userID int64, prvname provider.ProviderName) {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	delete(mc.cache, modelsKey{userID: userID, prvname: prvname})
+}
+
+// CheckProviderModels returns error if any agent of the provider is configured with a model
+// which is absent in the models list, the empty list means that available models are unknown
+func CheckProviderModels(prvname provider.ProviderName, prv provider.Provider, models pconfig.ModelsConfig) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(models))
+	for _, model := range models {
+		names = append(names, model.Name)
+	}
+
+	for _, opt := range pconfig.AllAgentTypes {
+		model := prv.Model(opt)
+		if model == "" || slices.Contains(names, model) {
+			continue
+		}
+
+		hint := names
+		if len(hint) > modelsHintLimit {
+			hint = append(slices.Clone(hint[:modelsHintLimit]), "...")
+		}
+
+		return fmt.Errorf("%w: model '%s' of agent '%s' is not available in provider '%s', available models: %s",
+			ErrUnsupportedModel, model, opt, prvname, strings.Join(hint, ", "))
+	}
+
+	return nil
+}
+
+// ModelFunctionsSupport returns the function calling capability of the provider model,
+// models which are absent in the list or have no tools flag are treated as supporting functions
+func ModelFunctionsSupport(prv provider.Provider, models pconfig.ModelsConfig, model string) FunctionsSupport {
+	support := FunctionsSupport{Native: true}
+	if emulator, ok := prv.(FunctionsEmulator); ok {
+		support.Emulation = emulator.EmulatesFunctions(model)
+	}
+
+	idx := slices.IndexFunc(models, func(m pconfig.ModelConfig) bool { return m.Name == model })
+	if idx != -1 && models[idx].Tools != nil {
+		support.Native = *models[idx].Tools
+	}
+
+	return support
+}
+
+// CheckProviderFunctions returns error if any agent which calls tools is configured with a model
+// which can't call functions, simple agents only generate text and aren't checked
+func CheckProviderFunctions(
+	prvname provider.ProviderName,
+	prv provider.Provider,
+	models pconfig.ModelsConfig,
+	allowEmulation bool,
+) error {
+	for _, opt := range pconfig.AllAgentTypes {
+		if opt == pconfig.OptionsTypeSimple || opt == pconfig.OptionsTypeSimpleJSON {
+			continue
+		}
+
+		model := prv.Model(opt)
+		if model == "" {
+			continue
+		}
+
+		support := ModelFunctionsSupport(prv, models, model)
+		if support.Supported(allowEmulation) {
+			continue
+		}
+
+		hint := "choose a model with tools support"
+		if support.Emulation {
+			hint = "enable functions compatibility mode to emulate tools in the prompt or " + hint
+		}
+
+		return fmt.Errorf("%w: model '%s' of agent '%s' in provider '%s' can't call the flow functions, %s",
+			ErrFunctionsNotSupported, model, opt, prvname, hint)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelsCatalog(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	mc := newModelsCatalog()
+	mc.now = clock.Now
+
+	_, ok := mc.get(1, "openai")
+	assert.False(t, ok)
+
+	models := pconfig.ModelsConfig{{Name: "gpt"}}
+	mc.set(1, "openai", models)
+
+	cached, ok := mc.get(1, "openai")
+	require.True(t, ok)
+	assert.Equal(t, models, cached)
+
+	_, ok = mc.get(2, "openai")
+	assert.False(t, ok, "user providers with the same name are cached separately")
+
+	clock.now = clock.now.Add(modelsCacheTTL)
+	_, ok = mc.get(1, "openai")
+	assert.False(t, ok, "expired models are dropped")
+
+	mc.set(1, "openai", models)
+	mc.forget(1, "openai")
+	_, ok = mc.get(1, "openai")
+	assert.False(t, ok)
+}
+
+func TestCheckProviderModels(t *testing.T) {
+	prv := mock.NewProvider(provider.ProviderOpenAI, "gpt")
+
+	assert.NoError(t, CheckProviderModels("openai", prv, nil), "unknown models list is not checked")
+	assert.NoError(t, CheckProviderModels("openai", prv, pconfig.ModelsConfig{{Name: "other"}, {Name: "gpt"}}))
+
+	models := make(pconfig.ModelsConfig, 0, modelsHintLimit+1)
+	for i := 0; i <= modelsHintLimit; i++ {
+		models = append(models, pconfig.ModelConfig{Name: "other"})
+	}
+	err := CheckProviderModels("openai", prv, models)
+	assert.ErrorIs(t, err, ErrUnsupportedModel)
+	assert.ErrorContains(t, err, "model 'gpt' of agent 'simple' is not available in provider 'openai'")
+	assert.ErrorContains(t, err, ", ...")
+}
+
+type emulatingProvider struct {
+	provider.Provider
+}
+
+func (p *emulatingProvider) EmulatesFunctions(model string) bool {
+	return model == "gpt"
+}
+
+func TestCheckProviderFunctions(t *testing.T) {
+	prv := mock.NewProvider(provider.ProviderOpenAI, "gpt")
+	noTools, withTools := false, true
+
+	assert.NoError(t, CheckProviderFunctions("openai", prv, nil, false), "unknown models support functions")
+	assert.NoError(t, CheckProviderFunctions("openai", prv, pconfig.ModelsConfig{{Name: "gpt"}}, false))
+	assert.NoError(t, CheckProviderFunctions("openai", prv, pconfig.ModelsConfig{{Name: "gpt", Tools: &withTools}}, false))
+
+	models := pconfig.ModelsConfig{{Name: "gpt", Tools: &noTools}}
+	err := CheckProviderFunctions("openai", prv, models, true)
+	assert.ErrorIs(t, err, ErrFunctionsNotSupported)
+	assert.ErrorContains(t, err, "model 'gpt' of agent 'primary_agent' in provider 'openai'")
+	assert.NotContains(t, err.Error(), "compatibility mode", "provider can't emulate functions")
+
+	emulating := &emulatingProvider{Provider: prv}
+	assert.Equal(t, FunctionsSupport{Native: false, Emulation: true}, ModelFunctionsSupport(emulating, models, "gpt"))
+
+	err = CheckProviderFunctions("openai", emulating, models, false)
+	assert.ErrorIs(t, err, ErrFunctionsNotSupported)
+	assert.ErrorContains(t, err, "enable functions compatibility mode")
+	assert.NoError(t, CheckProviderFunctions("openai", emulating, models, true))
+}
@@ -3,8 +3,11 @@ package pconfig
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/vxcontrol/langchaingo/llms"
@@ -12,6 +15,14 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	// UpstreamPromptCostKey and UpstreamCompletionCostKey contain the cost calculated by the provider (OpenRouter)
+	UpstreamPromptCostKey     = "UpstreamInferencePromptCost"
+	UpstreamCompletionCostKey = "UpstreamInferenceCompletionsCost"
+	// ResponseCacheHitKey marks the response which was returned from the provider responses cache
+	ResponseCacheHitKey = "ResponseCacheHit"
+)
+
 type CallUsage struct {
 	Input      int64   `json:"input" yaml:"input"`
 	Output     int64   `json:"output" yaml:"output"`
@@ -19,6 +30,11 @@ type CallUsage struct {
 	CacheWrite int64   `json:"cache_write" yaml:"cache_write"`
 	CostInput  float64 `json:"cost_input" yaml:"cost_input"`
 	CostOutput float64 `json:"cost_output" yaml:"cost_output"`
+	// ResponseCached is set if the response was served from the cache, such usage costs nothing
+	ResponseCached bool `json:"response_cached,omitempty" yaml:"response_cached,omitempty"`
+	// InputWithCacheWrite is set if the input tokens include the cache write tokens (Anthropic),
+	// such tokens are charged only at the cache write price
+	InputWithCacheWrite bool `json:"-" yaml:"-"`
 }
 
 func NewCallUsage(info map[string]any) CallUsage {
@@ -69,8 +85,9 @@ func (c *CallUsage) Fill(info map[string]any) {
 	c.Output = c.getInt64(info, "CompletionTokens")
 	c.CacheRead = c.getInt64(info, "CacheReadInputTokens")
 	c.CacheWrite = c.getInt64(info, "CacheCreationInputTokens")
-	c.CostInput = c.getFloat64(info, "UpstreamInferencePromptCost")
-	c.CostOutput = c.getFloat64(info, "UpstreamInferenceCompletionsCost")
+	c.CostInput = c.getFloat64(info, UpstreamPromptCostKey)
+	c.CostOutput = c.getFloat64(info, UpstreamCompletionCostKey)
+	c.ResponseCached, _ = info[ResponseCacheHitKey].(bool)
 }
 
 func (c *CallUsage) Merge(other CallUsage) {
@@ -92,10 +109,16 @@ func (c *CallUsage) Merge(other CallUsage) {
 	if other.CostOutput > 0 {
 		c.CostOutput = other.CostOutput
 	}
+	if other.ResponseCached {
+		c.ResponseCached = true
+	}
+	if other.InputWithCacheWrite {
+		c.InputWithCacheWrite = true
+	}
 }
 
 func (c *CallUsage) UpdateCost(price *PriceInfo) {
-	if price == nil {
+	if price == nil || c.ResponseCached {
 		return
 	}
 
@@ -112,11 +135,14 @@ func (c *CallUsage) UpdateCost(price *PriceInfo) {
 	}
 
 	// Calculation with cache
-	uncachedTokens := max(float64(c.Input-c.CacheRead), 0.0)
+	uncachedTokens := c.Input - c.CacheRead
+	if c.InputWithCacheWrite {
+		uncachedTokens -= c.CacheWrite
+	}
 	cacheReadCost := float64(c.CacheRead) * price.CacheRead / 1e6
 	cacheWriteCost := float64(c.CacheWrite) * price.CacheWrite / 1e6
 
-	c.CostInput = uncachedTokens*price.Input/1e6 + cacheReadCost + cacheWriteCost
+	c.CostInput = float64(max(uncachedTokens, 0))*price.Input/1e6 + cacheReadCost + cacheWriteCost
 	c.CostOutput = float64(c.Output) * price.Output / 1e6
 }
 
@@ -169,11 +195,13 @@ var AllAgentTypes = []ProviderOptionsType{
 }
 
 type ModelConfig struct {
-	Name        string     `json:"name,omitempty" yaml:"name,omitempty"`
-	Description *string    `json:"description,omitempty" yaml:"description,omitempty"`
-	ReleaseDate *time.Time `json:"release_date,omitempty" yaml:"release_date,omitempty"`
-	Thinking    *bool      `json:"thinking,omitempty" yaml:"thinking,omitempty"`
-	Price       *PriceInfo `json:"price,omitempty" yaml:"price,omitempty"`
+	Name          string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Description   *string    `json:"description,omitempty" yaml:"description,omitempty"`
+	ReleaseDate   *time.Time `json:"release_date,omitempty" yaml:"release_date,omitempty"`
+	Thinking      *bool      `json:"thinking,omitempty" yaml:"thinking,omitempty"`
+	ContextWindow *int       `json:"context_window,omitempty" yaml:"context_window,omitempty"`
+	Tools         *bool      `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Price         *PriceInfo `json:"price,omitempty" yaml:"price,omitempty"`
 }
 
 type ModelsConfig []ModelConfig
@@ -227,10 +255,53 @@ type ProviderConfig struct {
 	Coder          *AgentConfig      `json:"coder,omitempty" yaml:"coder,omitempty"`
 	Installer      *AgentConfig      `json:"installer,omitempty" yaml:"installer,omitempty"`
 	Pentester      *AgentConfig      `json:"pentester,omitempty" yaml:"pentester,omitempty"`
+	Endpoint       *EndpointConfig   `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
 	defaultOptions []llms.CallOption `json:"-" yaml:"-"`
 	rawConfig      []byte            `json:"-" yaml:"-"`
 }
 
+// EndpointConfig points the OpenAI-compatible custom provider to its own server instead of the one from the env
+type EndpointConfig struct {
+	BaseURL            string            `json:"base_url" yaml:"base_url"`
+	Headers            map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+}
+
+// headerNameRegex matches the token characters allowed in the HTTP header name
+var headerNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// Validate checks that the base URL is the absolute http(s) URL and the headers can be sent as is
+func (ec *EndpointConfig) Validate() error {
+	if ec == nil {
+		return nil
+	}
+
+	baseURL, err := url.Parse(ec.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint base URL: %w", err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return fmt.Errorf("invalid endpoint base URL: scheme must be http or https")
+	}
+	if baseURL.Host == "" {
+		return fmt.Errorf("invalid endpoint base URL: host is required")
+	}
+	if baseURL.User != nil || baseURL.RawQuery != "" || baseURL.Fragment != "" {
+		return fmt.Errorf("invalid endpoint base URL: credentials, query and fragment are not allowed")
+	}
+
+	for name, value := range ec.Headers {
+		if !headerNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid endpoint header name '%s'", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid endpoint header '%s' value: line breaks are not allowed", name)
+		}
+	}
+
+	return nil
+}
+
 const EmptyProviderConfigRaw = `{
   "simple": {},
   "simple_json": {},
@@ -670,6 +741,14 @@ func (ac *AgentConfig) MarshalYAML() (any, error) {
 	return ac.marshalMap(), nil
 }
 
+// GetEndpoint returns the own endpoint of the provider or nil if it uses the server from the env
+func (pc *ProviderConfig) GetEndpoint() *EndpointConfig {
+	if pc == nil || pc.Endpoint == nil || pc.Endpoint.BaseURL == "" {
+		return nil
+	}
+	return pc.Endpoint
+}
+
 func (pc *ProviderConfig) SetDefaultOptions(defaultOptions []llms.CallOption) {
 	if pc == nil {
 		return
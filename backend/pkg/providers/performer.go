@@ -23,6 +23,8 @@ import (
 	"github.com/vxcontrol/langchaingo/llms"
 	"github.com/vxcontrol/langchaingo/llms/reasoning"
 	"github.com/vxcontrol/langchaingo/llms/streaming"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -104,7 +106,26 @@ func (fp *flowProvider) performAgentChain(
 		}
 	}
 
+	// each agent turn has own span, the previous one is ended on the next turn or on the chain exit
+	chainCtx, turnSpan := ctx, oteltrace.Span(nil)
+	defer func() {
+		if turnSpan != nil {
+			turnSpan.End()
+		}
+	}()
+
 	for iteration := 0; ; iteration++ {
+		if turnSpan != nil {
+			turnSpan.End()
+		}
+		ctx, turnSpan = obs.Observer.NewSpan(chainCtx, obs.SpanKindInternal, "providers.flowProvider.agentTurn")
+		turnSpan.SetAttributes(
+			attribute.Int64("flow.id", fp.flowID),
+			attribute.String("agent.type", string(optAgentType)),
+			attribute.Int64("agent.msg_chain_id", chainID),
+			attribute.Int("agent.iteration", iteration),
+		)
+
 		if iteration >= maxCallsLimit {
 			msg := fmt.Sprintf("agent chain exceeded maximum iterations (%d)", maxCallsLimit)
 			logger.WithField("iteration", iteration).Error(msg)
@@ -344,6 +365,15 @@ func (fp *flowProvider) execToolCall(
 				return "", err
 			}
 
+			// the arguments are valid, the agent has to narrow the call instead of fixing it
+			var timeoutErr *tools.ToolTimeoutError
+			if errors.As(err, &timeoutErr) {
+				logger.WithError(err).Warn("tool call is timed out")
+				response = fmt.Sprintf("%s, the result isn't received: retry the call with the narrower arguments "+
+					"or use another tool", timeoutErr.Error())
+				break
+			}
+
 			logger.WithError(err).Warn("failed to exec function")
 
 			funcExecErr := err
@@ -479,25 +509,31 @@ func (fp *flowProvider) callWithRetries(
 						return nil
 					}
 					return fp.streamCb(ctx, &StreamMessageChunk{
-						Type:     StreamMessageChunkTypeThinking,
-						MsgType:  msgType,
-						Thinking: chunk.Reasoning,
-						StreamID: result.streamID,
+						Type:      StreamMessageChunkTypeThinking,
+						MsgType:   msgType,
+						Thinking:  chunk.Reasoning,
+						StreamID:  result.streamID,
+						TaskID:    taskID,
+						SubtaskID: subtaskID,
 					})
 				case streaming.ChunkTypeText:
 					return fp.streamCb(ctx, &StreamMessageChunk{
-						Type:     StreamMessageChunkTypeContent,
-						MsgType:  msgType,
-						Content:  chunk.Content,
-						StreamID: result.streamID,
+						Type:      StreamMessageChunkTypeContent,
+						MsgType:   msgType,
+						Content:   chunk.Content,
+						StreamID:  result.streamID,
+						TaskID:    taskID,
+						SubtaskID: subtaskID,
 					})
 				case streaming.ChunkTypeToolCall:
 					// skip tool call chunks (we don't need them for now)
 				case streaming.ChunkTypeDone:
 					return fp.streamCb(ctx, &StreamMessageChunk{
-						Type:     StreamMessageChunkTypeFlush,
-						MsgType:  msgType,
-						StreamID: result.streamID,
+						Type:      StreamMessageChunkTypeFlush,
+						MsgType:   msgType,
+						StreamID:  result.streamID,
+						TaskID:    taskID,
+						SubtaskID: subtaskID,
 					})
 				}
 				return nil
@@ -514,6 +550,7 @@ func (fp *flowProvider) callWithRetries(
 			errs = append(errs, err)
 			logger.WithFields(logrus.Fields{
 				"retry_iteration": idx,
+				"timeout":         errors.Is(err, ErrProviderTimeout),
 				"error":           err.Error()[:min(200, len(err.Error()))],
 			}).Warn("agent chain call failed, will retry")
 		}
@@ -528,11 +565,13 @@ func (fp *flowProvider) callWithRetries(
 
 	if fp.streamCb != nil && result.streamID != 0 {
 		fp.streamCb(ctx, &StreamMessageChunk{
-			Type:     StreamMessageChunkTypeUpdate,
-			MsgType:  msgType,
-			Content:  result.content,
-			Thinking: result.thinking,
-			StreamID: result.streamID,
+			Type:      StreamMessageChunkTypeUpdate,
+			MsgType:   msgType,
+			Content:   result.content,
+			Thinking:  result.thinking,
+			StreamID:  result.streamID,
+			TaskID:    taskID,
+			SubtaskID: subtaskID,
 		})
 		// don't update stream by ID if we got content separately from tool calls
 		// because we stored thinking and content into standalone messages
@@ -901,6 +940,13 @@ func (fp *flowProvider) updateMsgChainUsage(
 		return fmt.Errorf("failed to update msg chain usage in DB: %w", err)
 	}
 
+	if fp.usageCb != nil {
+		if err := fp.usageCb(ctx); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("msg_chain_id", chainID).
+				Error("failed to handle agent turn usage")
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,165 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// priceCacheTTL is period to keep model prices before reading the pricing table again
+	priceCacheTTL = time.Minute
+	// priceLookupTimeout limits reading the pricing table because the lookup is done on every LLM call
+	priceLookupTimeout = 5 * time.Second
+)
+
+type priceKey struct {
+	prvtype provider.ProviderType
+	model   string
+}
+
+type priceEntry struct {
+	prices  []database.ModelPrice // ordered by effective_from descending
+	expires time.Time
+}
+
+// priceBook looks up model prices in the pricing table, the prices are stored per 1K tokens
+// and converted to per 1M tokens which is used by the provider configs
+type priceBook struct {
+	db       database.Querier
+	fallback pconfig.PriceInfo
+	now      func() time.Time
+
+	mx     sync.Mutex
+	cache  map[priceKey]priceEntry
+	warned map[priceKey]struct{}
+}
+
+func newPriceBook(db database.Querier, inputPer1K, outputPer1K float64) *priceBook {
+	return &priceBook{
+		db: db,
+		fallback: pconfig.PriceInfo{
+			Input:  inputPer1K * 1e3,
+			Output: outputPer1K * 1e3,
+		},
+		now:    time.Now,
+		cache:  make(map[priceKey]priceEntry),
+		warned: make(map[priceKey]struct{}),
+	}
+}
+
+// lookup returns the price of the model effective at the time or nil if the table has no such price
+func (pb *priceBook) lookup(prvtype provider.ProviderType, model string, at time.Time) *pconfig.PriceInfo {
+	if pb == nil || pb.db == nil {
+		return nil
+	}
+
+	prices, ok := pb.prices(priceKey{prvtype: prvtype, model: model})
+	if !ok {
+		return nil
+	}
+
+	for _, price := range prices {
+		if !price.EffectiveFrom.After(at) {
+			return &pconfig.PriceInfo{
+				Input:  price.InputPricePer1k * 1e3,
+				Output: price.OutputPricePer1k * 1e3,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (pb *priceBook) prices(key priceKey) ([]database.ModelPrice, bool) {
+	pb.mx.Lock()
+	defer pb.mx.Unlock()
+
+	now := pb.now()
+	if entry, ok := pb.cache[key]; ok && now.Before(entry.expires) {
+		return entry.prices, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), priceLookupTimeout)
+	defer cancel()
+
+	prices, err := pb.db.GetModelPrices(ctx, database.GetModelPricesParams{
+		Provider: string(key.prvtype),
+		Model:    key.model,
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"provider": key.prvtype,
+			"model":    key.model,
+		}).Error("failed to get model prices")
+		return nil, false
+	}
+
+	pb.cache[key] = priceEntry{prices: prices, expires: now.Add(priceCacheTTL)}
+
+	return prices, true
+}
+
+// defaultPrice returns the configured default price and warns once per model that its price is unknown
+func (pb *priceBook) defaultPrice(prvtype provider.ProviderType, model string) *pconfig.PriceInfo {
+	if pb == nil {
+		return nil
+	}
+
+	pb.mx.Lock()
+	defer pb.mx.Unlock()
+
+	key := priceKey{prvtype: prvtype, model: model}
+	if _, ok := pb.warned[key]; !ok {
+		pb.warned[key] = struct{}{}
+		logrus.WithFields(logrus.Fields{
+			"provider": prvtype,
+			"model":    model,
+		}).Warn("model price is unknown, the default price is used to calculate cost")
+	}
+
+	price := pb.fallback
+	return &price
+}
+
+// pricedProvider sets the price of wrapped provider by the pricing table one effective at the flow creation time,
+// it falls back to the provider config price and to the default price at last
+type pricedProvider struct {
+	book      *priceBook
+	createdAt time.Time
+
+	provider.Provider
+}
+
+func newPricedProvider(book *priceBook, createdAt time.Time, prv provider.Provider) provider.Provider {
+	if book == nil {
+		return prv
+	}
+
+	return &pricedProvider{
+		book:      book,
+		createdAt: createdAt,
+		Provider:  prv,
+	}
+}
+
+func (pp *pricedProvider) GetPriceInfo(opt pconfig.ProviderOptionsType) *pconfig.PriceInfo {
+	prvtype, model := pp.Provider.Type(), pp.Provider.Model(opt)
+	if price := pp.book.lookup(prvtype, model, pp.createdAt); price != nil {
+		return price
+	}
+
+	// ollama runs local inference without pricing, so missing price is expected for it
+	price := pp.Provider.GetPriceInfo(opt)
+	if price != nil || prvtype == provider.ProviderOllama {
+		return price
+	}
+
+	return pp.book.defaultPrice(prvtype, model)
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pricesQuerier serves the pricing table from memory
+type pricesQuerier struct {
+	database.Querier
+	prices []database.ModelPrice
+	err    error
+	calls  int
+}
+
+func (q *pricesQuerier) GetModelPrices(
+	_ context.Context,
+	arg database.GetModelPricesParams,
+) ([]database.ModelPrice, error) {
+	q.calls++
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	var prices []database.ModelPrice
+	for _, price := range q.prices {
+		if price.Provider == arg.Provider && price.Model == arg.Model {
+			prices = append(prices, price)
+		}
+	}
+
+	return prices, nil
+}
+
+// unpricedProvider has no price in the provider config
+type unpricedProvider struct {
+	provider.Provider
+}
+
+func (p *unpricedProvider) GetPriceInfo(opt pconfig.ProviderOptionsType) *pconfig.PriceInfo {
+	return nil
+}
+
+func newTestPriceBook(q *pricesQuerier) (*priceBook, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	pb := newPriceBook(q, 0.001, 0.002)
+	pb.now = clock.Now
+	return pb, clock
+}
+
+func TestPriceBookLookup(t *testing.T) {
+	q := &pricesQuerier{prices: []database.ModelPrice{
+		{Provider: "openai", Model: "gpt", InputPricePer1k: 0.003, OutputPricePer1k: 0.012,
+			EffectiveFrom: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Provider: "openai", Model: "gpt", InputPricePer1k: 0.005, OutputPricePer1k: 0.015,
+			EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	pb, clock := newTestPriceBook(q)
+
+	// historical flows are costed by the price effective at their creation time
+	price := pb.lookup("openai", "gpt", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.NotNil(t, price)
+	assert.InDelta(t, 5.0, price.Input, 1e-9)
+	assert.InDelta(t, 15.0, price.Output, 1e-9)
+
+	price = pb.lookup("openai", "gpt", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NotNil(t, price)
+	assert.InDelta(t, 3.0, price.Input, 1e-9)
+
+	assert.Nil(t, pb.lookup("openai", "gpt", time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, q.calls, "prices of the model are cached")
+
+	clock.now = clock.now.Add(priceCacheTTL)
+	assert.Nil(t, pb.lookup("openai", "other", clock.now))
+	pb.lookup("openai", "gpt", clock.now)
+	assert.Equal(t, 3, q.calls)
+}
+
+func TestPricedProvider(t *testing.T) {
+	q := &pricesQuerier{prices: []database.ModelPrice{
+		{Provider: "openai", Model: "gpt", InputPricePer1k: 0.003, OutputPricePer1k: 0.012,
+			EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	pb, _ := newTestPriceBook(q)
+	createdAt := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	prv := newPricedProvider(pb, createdAt, mock.NewProvider(provider.ProviderOpenAI, "gpt"))
+	price := prv.GetPriceInfo(pconfig.OptionsTypePrimaryAgent)
+	require.NotNil(t, price)
+	assert.InDelta(t, 3.0, price.Input, 1e-9)
+
+	// the provider config price is used if the table has no price of the model
+	prv = newPricedProvider(pb, createdAt, mock.NewProvider(provider.ProviderOpenAI, "other"))
+	assert.Equal(t, &pconfig.PriceInfo{Input: 0.01, Output: 0.02}, prv.GetPriceInfo(pconfig.OptionsTypePrimaryAgent))
+
+	unpriced := &unpricedProvider{mock.NewProvider(provider.ProviderAnthropic, "claude")}
+	prv = newPricedProvider(pb, createdAt, unpriced)
+	price = prv.GetPriceInfo(pconfig.OptionsTypePrimaryAgent)
+	require.NotNil(t, price)
+	assert.InDelta(t, 1.0, price.Input, 1e-9)
+	assert.InDelta(t, 2.0, price.Output, 1e-9)
+	assert.Contains(t, pb.warned, priceKey{prvtype: provider.ProviderAnthropic, model: "claude"})
+
+	local := &unpricedProvider{mock.NewProvider(provider.ProviderOllama, "llama")}
+	assert.Nil(t, newPricedProvider(pb, createdAt, local).GetPriceInfo(pconfig.OptionsTypePrimaryAgent))
+
+	assert.Same(t, unpriced, newPricedProvider(nil, createdAt, unpriced))
+}
+
+func TestPriceBookLookupError(t *testing.T) {
+	q := &pricesQuerier{err: errors.New("connection refused")}
+	pb, _ := newTestPriceBook(q)
+
+	assert.Nil(t, pb.lookup("openai", "gpt", time.Now()))
+	assert.Nil(t, pb.lookup("openai", "gpt", time.Now()))
+	assert.Equal(t, 2, q.calls, "failed lookups are not cached")
+}
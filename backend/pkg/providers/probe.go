@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/tools"
+)
+
+const (
+	probeTimeout   = 30 * time.Second
+	probePrompt    = "Reply with the single word: pong"
+	probeMaxErrLen = 512
+)
+
+// ErrProviderValidation is returned when the provider configuration doesn't pass the live probe before it's saved
+var ErrProviderValidation = errors.New("provider validation failed")
+
+// probeSecretsRegex matches credentials which may leak into upstream error messages,
+// e.g. "api_key=...", "Authorization: Bearer ..." or "Incorrect API key provided: ..."
+var probeSecretsRegex = regexp.MustCompile(
+	`(?i)((?:api[_-]?key|key|token|secret|password|authorization)(?:\s+provided)?["']?\s*[=:]\s*["']?(?:bearer\s+)?)[^\s&"',}]+`,
+)
+
+// ProbeProvider performs a minimal completion call to check that the provider is reachable and accepts the credentials
+func ProbeProvider(ctx context.Context, prv provider.Provider) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	_, err := prv.Call(ctx, pconfig.OptionsTypeSimple, probePrompt)
+	return err
+}
+
+// SanitizeProviderError removes credentials from upstream error message and limits its length,
+// the common secret formats are masked by the same patterns as the tool results
+func SanitizeProviderError(err error) string {
+	msg := probeSecretsRegex.ReplaceAllString(err.Error(), "${1}[REDACTED]")
+	msg = tools.RedactDefaultSecrets(msg)
+	if len(msg) > probeMaxErrLen {
+		msg = tools.CutRunes(msg, probeMaxErrLen) + "..."
+	}
+
+	return msg
+}
+
+// ValidateProvider builds the provider from the configuration and probes it with the same call as the health check,
+// it returns the number of models available in the provider, the error never contains the configured credentials
+func (pc *providerController) ValidateProvider(
+	ctx context.Context,
+	prvtype provider.ProviderType,
+	config *pconfig.ProviderConfig,
+) (int, error) {
+	if err := checkProviderEndpoint(prvtype, config); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrProviderValidation, err.Error())
+	}
+
+	patchedConfig, err := pc.patchProviderConfig(prvtype, config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to patch provider config: %w", err)
+	}
+
+	prv, err := pc.buildProviderFromConfig(prvtype, patchedConfig)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrProviderValidation, pc.sanitizeError(err))
+	}
+
+	if err := ProbeProvider(ctx, prv); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return 0, fmt.Errorf("%w: provider probe timed out", ErrProviderValidation)
+		}
+		return 0, fmt.Errorf("%w: %s", ErrProviderValidation, pc.sanitizeError(err))
+	}
+
+	return len(prv.GetModels()), nil
+}
+
+// sanitizeError redacts the secrets of the config before the common credential patterns and the length limit
+func (pc *providerController) sanitizeError(err error) string {
+	msg := err.Error()
+	if pc.cfg != nil {
+		for _, pattern := range pc.cfg.GetSecretPatterns() {
+			if re, err := regexp.Compile(pattern.Regex); err == nil {
+				msg = re.ReplaceAllString(msg, "[REDACTED]")
+			}
+		}
+	}
+
+	return SanitizeProviderError(errors.New(msg))
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeProvider(t *testing.T) {
+	require.NoError(t, ProbeProvider(t.Context(), newFailingProvider("gpt", nil, 0)))
+
+	err := ProbeProvider(t.Context(), newFailingProvider("gpt", errors.New("unauthorized"), -1))
+	assert.EqualError(t, err, "unauthorized")
+}
+
+func TestSanitizeProviderError(t *testing.T) {
+	msg := SanitizeProviderError(errors.New(`401: {"error": "invalid", "api_key": "sk-abc123"} Authorization: Bearer tok`))
+	assert.NotContains(t, msg, "sk-abc123")
+	assert.NotContains(t, msg, "tok\"")
+	assert.Contains(t, msg, "[REDACTED]")
+
+	msg = SanitizeProviderError(errors.New("Incorrect API key provided: sk-short. Also ghp_" + strings.Repeat("a", 36)))
+	assert.NotContains(t, msg, "sk-short")
+	assert.NotContains(t, msg, "ghp_")
+
+	msg = SanitizeProviderError(errors.New(strings.Repeat("я", probeMaxErrLen)))
+	assert.True(t, utf8.ValidString(msg))
+	assert.LessOrEqual(t, len(msg), probeMaxErrLen+len("..."))
+}
+
+func TestValidateProvider(t *testing.T) {
+	const key = "sk-live-0123456789abcdef"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided ` + key + `","type":"invalid_request_error"}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{LLMServerURL: server.URL, LLMServerKey: key, LLMServerModel: "gpt-test"}
+	pc := &providerController{
+		cfg: cfg,
+		defaultConfigs: provider.ProvidersConfig{
+			provider.ProviderCustom: &pconfig.ProviderConfig{},
+		},
+	}
+
+	_, err := pc.ValidateProvider(t.Context(), provider.ProviderCustom, &pconfig.ProviderConfig{})
+	require.ErrorIs(t, err, ErrProviderValidation)
+	assert.NotContains(t, err.Error(), key)
+
+	_, err = pc.ValidateProvider(t.Context(), provider.ProviderOllama, nil)
+	assert.Error(t, err)
+}
+
+func TestCheckProviderEndpoint(t *testing.T) {
+	endpoint := &pconfig.EndpointConfig{BaseURL: "https://llm.example.com/v1"}
+
+	require.NoError(t, checkProviderEndpoint(provider.ProviderCustom, &pconfig.ProviderConfig{Endpoint: endpoint}))
+	assert.Error(t, checkProviderEndpoint(provider.ProviderOpenAI, &pconfig.ProviderConfig{Endpoint: endpoint}))
+	assert.Error(t, checkProviderEndpoint(provider.ProviderCustom, &pconfig.ProviderConfig{
+		Endpoint: &pconfig.EndpointConfig{BaseURL: "llm.example.com"},
+	}))
+
+	// the endpoint without base URL is dropped for any provider type
+	config := &pconfig.ProviderConfig{Endpoint: &pconfig.EndpointConfig{}}
+	require.NoError(t, checkProviderEndpoint(provider.ProviderOpenAI, config))
+	assert.Nil(t, config.Endpoint)
+}
+
+func TestRestoreEndpointHeaders(t *testing.T) {
+	stored := []byte(`{"endpoint": {"base_url": "https://llm.example.com/v1", "headers": {"X-Api-Key": "secret"}}}`)
+	config := &pconfig.ProviderConfig{Endpoint: &pconfig.EndpointConfig{
+		BaseURL: "https://llm.example.com/v2",
+		Headers: map[string]string{"X-Api-Key": "", "X-Org": "", "X-Team": "red"},
+	}}
+
+	RestoreEndpointHeaders(config, stored)
+	assert.Equal(t, map[string]string{"X-Api-Key": "secret", "X-Org": "", "X-Team": "red"}, config.Endpoint.Headers)
+}
@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -65,10 +66,21 @@ type StreamMessageChunk struct {
 	Result       string
 	ResultFormat database.MsglogResultFormat
 	StreamID     int64
+	TaskID       *int64
+	SubtaskID    *int64
 }
 
 type StreamMessageHandler func(ctx context.Context, chunk *StreamMessageChunk) error
 
+// UsageHandler is called after the usage of the agent turn was stored to the msg chain
+type UsageHandler func(ctx context.Context) error
+
+// AskUserHandler is called when the primary agent asks the user a question and the flow is going to wait for input
+type AskUserHandler func(ctx context.Context, question string) error
+
+// ProviderErrorHandler is called after each LLM call of the flow, err is nil if the call succeeded
+type ProviderErrorHandler func(ctx context.Context, err error) error
+
 type FlowProvider interface {
 	ID() int64
 	DB() database.Querier
@@ -82,13 +94,21 @@ type FlowProvider interface {
 	Executor() tools.FlowToolsExecutor
 	Prompter() templates.Prompter
 
+	SetImage(image string)
 	SetTitle(title string)
+	SetLanguage(language string)
 	SetAgentLogProvider(agentLog tools.AgentLogProvider)
 	SetMsgLogProvider(msgLog tools.MsgLogProvider)
+	SetProviderSwitchHandler(handler ProviderSwitchHandler)
+	SetUsageHandler(handler UsageHandler)
+	SetAskUserHandler(handler AskUserHandler)
+	SetProviderErrorHandler(handler ProviderErrorHandler)
+	SetStreamHandler(handler StreamMessageHandler)
 
 	GetTaskTitle(ctx context.Context, input string) (string, error)
-	GenerateSubtasks(ctx context.Context, taskID int64) ([]tools.SubtaskInfo, error)
-	RefineSubtasks(ctx context.Context, taskID int64) ([]tools.SubtaskInfo, error)
+	// GenerateSubtasks and RefineSubtasks ask the planner for no more than limit subtasks in the plan
+	GenerateSubtasks(ctx context.Context, taskID int64, limit int) ([]tools.SubtaskInfo, error)
+	RefineSubtasks(ctx context.Context, taskID int64, limit int) ([]tools.SubtaskInfo, error)
 	GetTaskResult(ctx context.Context, taskID int64) (*tools.TaskResult, error)
 
 	PrepareAgentChain(ctx context.Context, taskID, subtaskID int64) (int64, error)
@@ -111,9 +131,10 @@ type FlowProviderHandlers interface {
 }
 
 type tasksInfo struct {
-	Task     database.Task
-	Tasks    []database.Task
-	Subtasks []database.Subtask
+	Task        database.Task
+	Tasks       []database.Task
+	Subtasks    []database.Subtask
+	Attachments []database.FlowAttachment
 }
 
 type subtasksInfo struct {
@@ -147,6 +168,9 @@ type flowProvider struct {
 	agentLog tools.AgentLogProvider
 	msgLog   tools.MsgLogProvider
 	streamCb StreamMessageHandler
+	usageCb  UsageHandler
+	askCb    AskUserHandler
+	errCb    ProviderErrorHandler
 
 	summarizer csum.Summarizer
 
@@ -171,6 +195,92 @@ func (fp *flowProvider) SetMsgLogProvider(msgLog tools.MsgLogProvider) {
 	fp.msgLog = msgLog
 }
 
+func (fp *flowProvider) SetProviderSwitchHandler(handler ProviderSwitchHandler) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	if fb, ok := fp.Provider.(FallbackProvider); ok {
+		fb.SetSwitchHandler(handler)
+	}
+}
+
+func (fp *flowProvider) SetUsageHandler(handler UsageHandler) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	fp.usageCb = handler
+}
+
+func (fp *flowProvider) SetAskUserHandler(handler AskUserHandler) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	fp.askCb = handler
+}
+
+func (fp *flowProvider) SetProviderErrorHandler(handler ProviderErrorHandler) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	fp.errCb = handler
+}
+
+// SetStreamHandler enables streaming of the agents answers, it must be set before the flow is running
+func (fp *flowProvider) SetStreamHandler(handler StreamMessageHandler) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	fp.streamCb = handler
+}
+
+// Call, CallEx and CallWithTools report the result of each LLM call of the flow to the provider error handler
+func (fp *flowProvider) Call(ctx context.Context, opt pconfig.ProviderOptionsType, prompt string) (string, error) {
+	result, err := fp.Provider.Call(ctx, opt, prompt)
+	fp.reportCallResult(ctx, err)
+
+	return result, err
+}
+
+func (fp *flowProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	resp, err := fp.Provider.CallEx(ctx, opt, chain, streamCb)
+	fp.reportCallResult(ctx, err)
+
+	return resp, err
+}
+
+func (fp *flowProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	resp, err := fp.Provider.CallWithTools(ctx, opt, chain, tools, streamCb)
+	fp.reportCallResult(ctx, err)
+
+	return resp, err
+}
+
+// reportCallResult skips the calls aborted by the caller context because they say nothing about the provider
+func (fp *flowProvider) reportCallResult(ctx context.Context, err error) {
+	fp.mx.RLock()
+	errCb := fp.errCb
+	fp.mx.RUnlock()
+
+	if errCb == nil || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return
+	}
+
+	if err := errCb(ctx, err); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("flow_id", fp.flowID).Error("failed to handle provider call result")
+	}
+}
+
 func (fp *flowProvider) ID() int64 {
 	fp.mx.RLock()
 	defer fp.mx.RUnlock()
@@ -192,6 +302,13 @@ func (fp *flowProvider) Image() string {
 	return fp.image
 }
 
+func (fp *flowProvider) SetImage(image string) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	fp.image = image
+}
+
 func (fp *flowProvider) Title() string {
 	fp.mx.RLock()
 	defer fp.mx.RUnlock()
@@ -213,6 +330,13 @@ func (fp *flowProvider) Language() string {
 	return fp.language
 }
 
+func (fp *flowProvider) SetLanguage(language string) {
+	fp.mx.Lock()
+	defer fp.mx.Unlock()
+
+	fp.language = language
+}
+
 func (fp *flowProvider) ToolCallIDTemplate() string {
 	fp.mx.RLock()
 	defer fp.mx.RUnlock()
@@ -278,7 +402,7 @@ func (fp *flowProvider) GetTaskTitle(ctx context.Context, input string) (string,
 	return title, nil
 }
 
-func (fp *flowProvider) GenerateSubtasks(ctx context.Context, taskID int64) ([]tools.SubtaskInfo, error) {
+func (fp *flowProvider) GenerateSubtasks(ctx context.Context, taskID int64, limit int) ([]tools.SubtaskInfo, error) {
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.flowProvider.GenerateSubtasks")
 	defer span.End()
 
@@ -292,9 +416,10 @@ func (fp *flowProvider) GenerateSubtasks(ctx context.Context, taskID int64) ([]t
 
 	generatorContext := map[string]map[string]any{
 		"user": {
-			"Task":     tasksInfo.Task,
-			"Tasks":    tasksInfo.Tasks,
-			"Subtasks": tasksInfo.Subtasks,
+			"Task":        tasksInfo.Task,
+			"Tasks":       tasksInfo.Tasks,
+			"Subtasks":    tasksInfo.Subtasks,
+			"Attachments": tasksInfo.Attachments,
 		},
 		"system": {
 			"SubtaskListToolName":     tools.SubtaskListToolName,
@@ -307,7 +432,7 @@ func (fp *flowProvider) GenerateSubtasks(ctx context.Context, taskID int64) ([]t
 			"DockerImage":             fp.image,
 			"Lang":                    fp.language,
 			"CurrentTime":             getCurrentTime(),
-			"N":                       TasksNumberLimit,
+			"N":                       limit,
 			"ToolPlaceholder":         ToolPlaceholder,
 		},
 	}
@@ -359,7 +484,7 @@ func (fp *flowProvider) GenerateSubtasks(ctx context.Context, taskID int64) ([]t
 	return subtasks, nil
 }
 
-func (fp *flowProvider) RefineSubtasks(ctx context.Context, taskID int64) ([]tools.SubtaskInfo, error) {
+func (fp *flowProvider) RefineSubtasks(ctx context.Context, taskID int64, limit int) ([]tools.SubtaskInfo, error) {
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.flowProvider.RefineSubtasks")
 	defer span.End()
 
@@ -397,7 +522,7 @@ func (fp *flowProvider) RefineSubtasks(ctx context.Context, taskID int64) ([]too
 			"DockerImage":             fp.image,
 			"Lang":                    fp.language,
 			"CurrentTime":             getCurrentTime(),
-			"N":                       max(TasksNumberLimit-len(subtasksInfo.Completed), 0),
+			"N":                       limit,
 			"ToolPlaceholder":         ToolPlaceholder,
 		},
 	}
@@ -814,6 +939,12 @@ func (fp *flowProvider) PerformAgentChain(ctx context.Context, taskID, subtaskID
 					return "", fmt.Errorf("failed to unmarshal ask user result: %w", err)
 				}
 
+				if fp.askCb != nil {
+					if err := fp.askCb(ctx, askUser.Message); err != nil {
+						loggerFunc.WithError(err).Error("failed to handle ask user question")
+					}
+				}
+
 				executorAgent.End(
 					langfuse.WithAgentOutput(askUser.Message),
 					langfuse.WithAgentStatus("ask user handler"),
@@ -42,6 +42,7 @@ type modelInfo struct {
 	ID                  string       `json:"id"`
 	Created             *int64       `json:"created,omitempty"`
 	Description         string       `json:"description,omitempty"`
+	ContextLength       *int         `json:"context_length,omitempty"`
 	SupportedParameters []string     `json:"supported_parameters,omitempty"`
 	Pricing             *pricingInfo `json:"pricing,omitempty"`
 }
@@ -174,6 +175,11 @@ func parseFullModels(models []modelInfo, prefix string) pconfig.ModelsConfig {
 			modelConfig.Thinking = &thinking
 		}
 
+		// Parse context window size if available
+		if model.ContextLength != nil && *model.ContextLength > 0 {
+			modelConfig.ContextWindow = model.ContextLength
+		}
+
 		// Check for tool support - skip models without tool/structured output support
 		if len(model.SupportedParameters) > 0 {
 			hasTools := slices.Contains(model.SupportedParameters, "tools")
@@ -181,6 +187,7 @@ func parseFullModels(models []modelInfo, prefix string) pconfig.ModelsConfig {
 			if !hasTools && !hasStructuredOutputs {
 				continue
 			}
+			modelConfig.Tools = &hasTools
 		}
 
 		// Parse pricing if available
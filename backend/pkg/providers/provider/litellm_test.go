@@ -446,6 +446,52 @@ func TestLoadModelsFromHTTP_SkipModelsWithoutTools(t *testing.T) {
 	}
 }
 
+func TestLoadModelsFromHTTP_Capabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := `{
+			"data": [
+				{
+					"id": "model-with-tools",
+					"context_length": 128000,
+					"supported_parameters": ["tools"]
+				},
+				{
+					"id": "model-with-structured-outputs",
+					"supported_parameters": ["structured_outputs"]
+				},
+				{
+					"id": "model-unknown"
+				}
+			]
+		}`
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	models, err := LoadModelsFromHTTP(server.URL, "", client, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(models) != 3 {
+		t.Fatalf("Expected 3 models, got %d", len(models))
+	}
+
+	if models[0].ContextWindow == nil || *models[0].ContextWindow != 128000 {
+		t.Errorf("Expected context window 128000, got %v", models[0].ContextWindow)
+	}
+	if models[0].Tools == nil || !*models[0].Tools {
+		t.Errorf("Expected model with tools support, got %v", models[0].Tools)
+	}
+	if models[1].Tools == nil || *models[1].Tools {
+		t.Errorf("Expected model without tools support, got %v", models[1].Tools)
+	}
+	if models[2].ContextWindow != nil || models[2].Tools != nil {
+		t.Errorf("Expected unknown capabilities, got %v and %v", models[2].ContextWindow, models[2].Tools)
+	}
+}
+
 func TestLoadModelsFromHTTP_Errors(t *testing.T) {
 	tests := []struct {
 		name        string
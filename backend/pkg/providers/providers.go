@@ -26,6 +26,7 @@ import (
 	"pentagi/pkg/providers/gemini"
 	"pentagi/pkg/providers/glm"
 	"pentagi/pkg/providers/kimi"
+	"pentagi/pkg/providers/mock"
 	"pentagi/pkg/providers/ollama"
 	"pentagi/pkg/providers/openai"
 	"pentagi/pkg/providers/pconfig"
@@ -48,6 +49,7 @@ type ProviderController interface {
 	NewFlowProvider(
 		ctx context.Context,
 		prvname provider.ProviderName,
+		fallbacks provider.ProvidersListNames,
 		prompter templates.Prompter,
 		executor tools.FlowToolsExecutor,
 		flowID, userID int64,
@@ -57,6 +59,7 @@ type ProviderController interface {
 	LoadFlowProvider(
 		ctx context.Context,
 		prvname provider.ProviderName,
+		fallbacks provider.ProvidersListNames,
 		prompter templates.Prompter,
 		executor tools.FlowToolsExecutor,
 		flowID, userID int64,
@@ -95,6 +98,17 @@ type ProviderController interface {
 		ctx context.Context,
 		userID int64,
 	) (provider.Providers, error)
+	GetProviderModels(
+		ctx context.Context,
+		prvname provider.ProviderName,
+		userID int64,
+	) (pconfig.ModelsConfig, error)
+	GetFunctionsSupport(
+		ctx context.Context,
+		prvname provider.ProviderName,
+		model string,
+		userID int64,
+	) (FunctionsSupport, error)
 
 	NewProvider(prv database.Provider) (provider.Provider, error)
 	CreateProvider(
@@ -128,6 +142,14 @@ type ProviderController interface {
 		prvtype provider.ProviderType,
 		config *pconfig.ProviderConfig,
 	) (tester.ProviderTestResults, error)
+	ValidateProvider(
+		ctx context.Context,
+		prvtype provider.ProviderType,
+		config *pconfig.ProviderConfig,
+	) (int, error)
+
+	GetRateLimiterState(userID int64) []RateLimiterState
+	ClearResponseCache(ctx context.Context) error
 }
 
 type providerController struct {
@@ -147,6 +169,13 @@ type providerController struct {
 
 	defaultConfigs provider.ProvidersConfig
 
+	limiter   *rateLimiter
+	pricing   *priceBook
+	models    *modelsCatalog
+	responses *responseCache
+
+	callTimeout time.Duration
+
 	provider.Providers
 }
 
@@ -227,6 +256,12 @@ func NewProviderController(
 		defaultConfigs[provider.ProviderQwen] = config
 	}
 
+	if config, err := mock.DefaultProviderConfig(); err != nil {
+		return nil, fmt.Errorf("failed to create mock provider config: %w", err)
+	} else {
+		defaultConfigs[provider.ProviderMock] = config
+	}
+
 	if cfg.OpenAIKey != "" {
 		p, err := openai.New(cfg, defaultConfigs[provider.ProviderOpenAI])
 		if err != nil {
@@ -320,6 +355,15 @@ func NewProviderController(
 		providers[provider.DefaultProviderNameQwen] = p
 	}
 
+	if cfg.MockProviderFixture != "" {
+		p, err := mock.New(cfg, defaultConfigs[provider.ProviderMock])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mock provider: %w", err)
+		}
+
+		providers[provider.DefaultProviderNameMock] = p
+	}
+
 	summarizerAgent := csum.NewSummarizer(csum.SummarizerConfig{
 		PreserveLast:   cfg.SummarizerPreserveLast,
 		UseQA:          cfg.SummarizerUseQA,
@@ -369,6 +413,17 @@ func NewProviderController(
 
 		defaultConfigs: defaultConfigs,
 
+		limiter: newRateLimiter(
+			cfg.LLMRateLimitRPM,
+			cfg.LLMRateLimitTPM,
+			time.Duration(cfg.LLMRateLimitMaxWait)*time.Second,
+		),
+		pricing:   newPriceBook(db, cfg.PricingDefaultInputPer1K, cfg.PricingDefaultOutputPer1K),
+		models:    newModelsCatalog(),
+		responses: newResponseCache(cfg, tools.GetSharedCache(cfg)),
+
+		callTimeout: time.Duration(cfg.LLMCallTimeout) * time.Second,
+
 		Providers: providers,
 	}, nil
 }
@@ -376,6 +431,7 @@ func NewProviderController(
 func (pc *providerController) NewFlowProvider(
 	ctx context.Context,
 	prvname provider.ProviderName,
+	fallbacks provider.ProvidersListNames,
 	prompter templates.Prompter,
 	executor tools.FlowToolsExecutor,
 	flowID, userID int64,
@@ -385,10 +441,12 @@ func (pc *providerController) NewFlowProvider(
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.NewFlowProvider")
 	defer span.End()
 
-	prv, err := pc.GetProvider(ctx, prvname, userID)
+	settings := pc.flowSettings(ctx, flowID)
+	prv, err := pc.getFlowProvider(ctx, prvname, fallbacks, userID, settings)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
+	prompter = settings.flowPrompter(prompter)
 
 	imageTmpl, err := prompter.RenderTemplate(templates.PromptTypeImageChooser, map[string]any{
 		"DefaultImage":           pc.docker.GetDefaultImage(),
@@ -438,6 +496,9 @@ func (pc *providerController) NewFlowProvider(
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine tool call ID template: %w", err)
 	}
+	if err := templates.ValidateToolCallIDTemplate(tcIDTemplate); err != nil {
+		return nil, fmt.Errorf("failed to determine tool call ID template: %w", err)
+	}
 
 	fp := &flowProvider{
 		db:              pc.db,
@@ -474,6 +535,7 @@ func (pc *providerController) NewFlowProvider(
 func (pc *providerController) LoadFlowProvider(
 	ctx context.Context,
 	prvname provider.ProviderName,
+	fallbacks provider.ProvidersListNames,
 	prompter templates.Prompter,
 	executor tools.FlowToolsExecutor,
 	flowID, userID int64,
@@ -483,10 +545,12 @@ func (pc *providerController) LoadFlowProvider(
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.LoadFlowProvider")
 	defer span.End()
 
-	prv, err := pc.GetProvider(ctx, prvname, userID)
+	settings := pc.flowSettings(ctx, flowID)
+	prv, err := pc.getFlowProvider(ctx, prvname, fallbacks, userID, settings)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
+	prompter = settings.flowPrompter(prompter)
 
 	fp := &flowProvider{
 		db:              pc.db,
@@ -540,7 +604,7 @@ func (pc *providerController) NewAssistantProvider(
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.NewAssistantProvider")
 	defer span.End()
 
-	prv, err := pc.GetProvider(ctx, prvname, userID)
+	prv, err := pc.getLimitedProvider(ctx, prvname, userID, pc.flowSettings(ctx, flowID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
@@ -578,6 +642,9 @@ func (pc *providerController) NewAssistantProvider(
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine tool call ID template: %w", err)
 	}
+	if err := templates.ValidateToolCallIDTemplate(tcIDTemplate); err != nil {
+		return nil, fmt.Errorf("failed to determine tool call ID template: %w", err)
+	}
 
 	ap := &assistantProvider{
 		id:         assistantID,
@@ -626,7 +693,7 @@ func (pc *providerController) LoadAssistantProvider(
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.LoadAssistantProvider")
 	defer span.End()
 
-	prv, err := pc.GetProvider(ctx, prvname, userID)
+	prv, err := pc.getLimitedProvider(ctx, prvname, userID, pc.flowSettings(ctx, flowID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
@@ -701,6 +768,8 @@ func (pc *providerController) GetProvider(
 		return pc.Providers.Get(provider.DefaultProviderNameKimi)
 	case provider.DefaultProviderNameQwen:
 		return pc.Providers.Get(provider.DefaultProviderNameQwen)
+	case provider.DefaultProviderNameMock:
+		return pc.Providers.Get(provider.DefaultProviderNameMock)
 	}
 
 	// Lookup user defined providers by name and build it
@@ -715,6 +784,121 @@ func (pc *providerController) GetProvider(
 	return pc.NewProvider(prv)
 }
 
+// getFlowProvider returns provider by name or fallback chain of providers if fallbacks are set
+func (pc *providerController) getFlowProvider(
+	ctx context.Context,
+	prvname provider.ProviderName,
+	fallbacks provider.ProvidersListNames,
+	userID int64,
+	settings flowProviderSettings,
+) (provider.Provider, error) {
+	prv, err := pc.getLimitedProvider(ctx, prvname, userID, settings)
+	if err != nil || len(fallbacks) == 0 {
+		return prv, err
+	}
+
+	names := provider.ProvidersListNames{prvname}
+	chain := []provider.Provider{prv}
+	for _, name := range fallbacks {
+		if names.Contains(name) {
+			continue
+		}
+
+		fprv, err := pc.getLimitedProvider(ctx, name, userID, settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fallback provider '%s': %w", name, err)
+		}
+		names = append(names, name)
+		chain = append(chain, fprv)
+	}
+
+	if len(chain) == 1 {
+		return prv, nil
+	}
+
+	return newFallbackProvider(names, chain)
+}
+
+// getLimitedProvider returns provider by name which shares rate limiter budget with other user's flows,
+// aborts calls longer than the flow call timeout, calculates cost by the prices effective at the flow
+// creation time and serves cached responses if the flow opted in to the responses cache
+func (pc *providerController) getLimitedProvider(
+	ctx context.Context,
+	prvname provider.ProviderName,
+	userID int64,
+	settings flowProviderSettings,
+) (provider.Provider, error) {
+	prv, err := pc.GetProvider(ctx, prvname, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the timeout wraps the raw provider so waiting for the rate limiter isn't counted
+	prv = newTimeoutProvider(settings.callTimeout, prvname, prv)
+	prv = newPricedProvider(pc.pricing, settings.createdAt, prv)
+	prv = newRateLimitedProvider(pc.limiter, prvname, userID, prv)
+
+	return newCachedProvider(pc.responses, settings.responseCache, prvname, userID, prv), nil
+}
+
+// flowProviderSettings contains the flow fields which change the behaviour of its providers
+type flowProviderSettings struct {
+	createdAt     time.Time
+	responseCache ResponseCacheMode
+	callTimeout   time.Duration
+	systemPrompt  string
+}
+
+// flowPrompter replaces the primary agent template by the system prompt of the flow if it's set
+func (s flowProviderSettings) flowPrompter(prompter templates.Prompter) templates.Prompter {
+	if s.systemPrompt == "" {
+		return prompter
+	}
+
+	return templates.NewOverridePrompter(prompter, templates.PromptsMap{
+		templates.PromptTypePrimaryAgent: s.systemPrompt,
+	})
+}
+
+// flowSettings returns the flow creation time to choose model prices, its responses cache mode,
+// provider call timeout and system prompt override, new flows use the current time, the disabled cache
+// and the default timeout
+func (pc *providerController) flowSettings(ctx context.Context, flowID int64) flowProviderSettings {
+	settings := flowProviderSettings{
+		createdAt:     time.Now(),
+		responseCache: ResponseCacheDisabled,
+		callTimeout:   pc.callTimeout,
+	}
+
+	flow, err := pc.db.GetFlow(ctx, flowID)
+	if err != nil {
+		return settings
+	}
+
+	if flow.CreatedAt.Valid {
+		settings.createdAt = flow.CreatedAt.Time
+	}
+	if flow.ResponseCache.Valid {
+		settings.responseCache = ResponseCacheMode(flow.ResponseCache.String)
+	}
+	if flow.ProviderTimeout.Valid {
+		settings.callTimeout = time.Duration(flow.ProviderTimeout.Int32) * time.Second
+	}
+	if flow.SystemPrompt.Valid {
+		settings.systemPrompt = flow.SystemPrompt.String
+	}
+
+	return settings
+}
+
+func (pc *providerController) GetRateLimiterState(userID int64) []RateLimiterState {
+	return pc.limiter.state(userID)
+}
+
+func (pc *providerController) ClearResponseCache(ctx context.Context) error {
+	return pc.responses.clear(ctx)
+}
+
 func (pc *providerController) GetProviders(
 	ctx context.Context,
 	userID int64,
@@ -743,14 +927,62 @@ func (pc *providerController) GetProviders(
 	return providersMap, nil
 }
 
+// GetProviderModels returns available models of the provider, the list is cached for a short time
+// because building of user providers can request the models from the remote endpoint
+func (pc *providerController) GetProviderModels(
+	ctx context.Context,
+	prvname provider.ProviderName,
+	userID int64,
+) (pconfig.ModelsConfig, error) {
+	if models, ok := pc.models.get(userID, prvname); ok {
+		return models, nil
+	}
+
+	prv, err := pc.GetProvider(ctx, prvname, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	models := prv.GetModels()
+	pc.models.set(userID, prvname, models)
+
+	return models, nil
+}
+
+// GetFunctionsSupport returns whether the model of the provider can call functions natively
+// or by the prompt emulation, the empty model means the primary agent one
+func (pc *providerController) GetFunctionsSupport(
+	ctx context.Context,
+	prvname provider.ProviderName,
+	model string,
+	userID int64,
+) (FunctionsSupport, error) {
+	prv, err := pc.GetProvider(ctx, prvname, userID)
+	if err != nil {
+		return FunctionsSupport{}, err
+	}
+
+	models, err := pc.GetProviderModels(ctx, prvname, userID)
+	if err != nil {
+		return FunctionsSupport{}, err
+	}
+
+	if model == "" {
+		model = prv.Model(pconfig.OptionsTypePrimaryAgent)
+	}
+
+	return ModelFunctionsSupport(prv, models, model), nil
+}
+
 func (pc *providerController) NewProvider(prv database.Provider) (provider.Provider, error) {
 	if len(prv.Config) == 0 {
 		prv.Config = []byte(pconfig.EmptyProviderConfigRaw)
 	}
 
-	// Check if the provider type is available via check default one
+	// Check if the provider type is available via check default one,
+	// the custom provider with its own endpoint doesn't depend on the server from the env
 	providerType := provider.ProviderType(prv.Type)
-	if !pc.ListTypes().Contains(providerType) {
+	if !pc.ListTypes().Contains(providerType) && !(providerType == provider.ProviderCustom && hasEndpoint(prv.Config)) {
 		return nil, fmt.Errorf("provider type '%s' is not available", prv.Type)
 	}
 
@@ -835,6 +1067,10 @@ func (pc *providerController) CreateProvider(
 		result database.Provider
 	)
 
+	if err = checkProviderEndpoint(prvtype, config); err != nil {
+		return result, err
+	}
+
 	if config, err = pc.patchProviderConfig(prvtype, config); err != nil {
 		return result, fmt.Errorf("failed to patch provider config: %w", err)
 	}
@@ -881,6 +1117,11 @@ func (pc *providerController) UpdateProvider(
 	}
 	prvtype := provider.ProviderType(prv.Type)
 
+	RestoreEndpointHeaders(config, prv.Config)
+	if err = checkProviderEndpoint(prvtype, config); err != nil {
+		return result, err
+	}
+
 	if config, err = pc.patchProviderConfig(prvtype, config); err != nil {
 		return result, fmt.Errorf("failed to patch provider config: %w", err)
 	}
@@ -900,6 +1141,9 @@ func (pc *providerController) UpdateProvider(
 		return result, fmt.Errorf("failed to update provider: %w", err)
 	}
 
+	pc.models.forget(userID, provider.ProviderName(prv.Name))
+	pc.models.forget(userID, prvname)
+
 	return result, nil
 }
 
@@ -919,6 +1163,8 @@ func (pc *providerController) DeleteProvider(
 		return result, fmt.Errorf("failed to delete provider: %w", err)
 	}
 
+	pc.models.forget(userID, provider.ProviderName(result.Name))
+
 	return result, nil
 }
 
@@ -1155,6 +1401,55 @@ func (pc *providerController) buildProviderFromConfig(
 	}
 }
 
+// checkProviderEndpoint allows the own endpoint only for the OpenAI-compatible custom provider and validates it
+func checkProviderEndpoint(prvtype provider.ProviderType, config *pconfig.ProviderConfig) error {
+	endpoint := config.GetEndpoint()
+	if endpoint == nil {
+		if config != nil {
+			config.Endpoint = nil
+		}
+		return nil
+	}
+
+	if prvtype != provider.ProviderCustom {
+		return fmt.Errorf("provider type '%s' doesn't support custom endpoint", prvtype)
+	}
+
+	return endpoint.Validate()
+}
+
+// storedEndpoint returns the own endpoint of the stored provider config or nil
+func storedEndpoint(rawConfig []byte) *pconfig.EndpointConfig {
+	var config struct {
+		Endpoint *pconfig.EndpointConfig `json:"endpoint"`
+	}
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		return nil
+	}
+
+	return (&pconfig.ProviderConfig{Endpoint: config.Endpoint}).GetEndpoint()
+}
+
+// hasEndpoint returns true if the stored provider config has its own endpoint
+func hasEndpoint(rawConfig []byte) bool {
+	return storedEndpoint(rawConfig) != nil
+}
+
+// RestoreEndpointHeaders puts back the stored values of the endpoint headers which are updated with empty value,
+// the values aren't returned to the client so it can't send them back
+func RestoreEndpointHeaders(config *pconfig.ProviderConfig, rawConfig []byte) {
+	endpoint, stored := config.GetEndpoint(), storedEndpoint(rawConfig)
+	if endpoint == nil || stored == nil {
+		return
+	}
+
+	for name, value := range endpoint.Headers {
+		if storedValue, ok := stored.Headers[name]; ok && value == "" {
+			endpoint.Headers[name] = storedValue
+		}
+	}
+}
+
 func newAtomicInt64(seed int64) *atomic.Int64 {
 	var number atomic.Int64
 
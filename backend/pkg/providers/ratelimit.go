@@ -0,0 +1,347 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+// rateLimitCharsPerToken is used to estimate tokens usage when provider doesn't return it
+const rateLimitCharsPerToken = 4
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitError is returned when the call can't get budget from the limiter within max wait time
+type RateLimitError struct {
+	Provider   provider.ProviderName
+	Model      string
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s for provider '%s' model '%s': %s per minute budget is exhausted, retry after %s",
+		ErrRateLimited, e.Provider, e.Model, e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimiterState is a snapshot of the limiter bucket for observability
+type RateLimiterState struct {
+	Provider          provider.ProviderName
+	Model             string
+	RequestsLimit     int
+	RequestsAvailable float64
+	TokensLimit       int
+	TokensAvailable   float64
+	Waiting           int
+	Rejected          int64
+	UpdatedAt         time.Time
+}
+
+type rateLimitKey struct {
+	userID  int64
+	prvname provider.ProviderName
+	model   string
+}
+
+type rateLimitBucket struct {
+	requests float64
+	tokens   float64
+	waiting  int
+	rejected int64
+	updated  time.Time
+}
+
+// rateLimiter is a token-bucket limiter of requests and tokens per minute
+// shared across all flows of the user for each provider and model
+type rateLimiter struct {
+	mx      *sync.Mutex
+	rpm     int
+	tpm     int
+	maxWait time.Duration
+	buckets map[rateLimitKey]*rateLimitBucket
+	now     func() time.Time
+}
+
+func newRateLimiter(rpm, tpm int, maxWait time.Duration) *rateLimiter {
+	return &rateLimiter{
+		mx:      &sync.Mutex{},
+		rpm:     max(rpm, 0),
+		tpm:     max(tpm, 0),
+		maxWait: max(maxWait, 0),
+		buckets: make(map[rateLimitKey]*rateLimitBucket),
+		now:     time.Now,
+	}
+}
+
+func (rl *rateLimiter) enabled() bool {
+	return rl != nil && (rl.rpm > 0 || rl.tpm > 0)
+}
+
+// bucket returns refilled bucket for the key, it must be called under the lock
+func (rl *rateLimiter) bucket(key rateLimitKey, now time.Time) *rateLimitBucket {
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &rateLimitBucket{
+			requests: float64(rl.rpm),
+			tokens:   float64(rl.tpm),
+			updated:  now,
+		}
+		rl.buckets[key] = b
+		return b
+	}
+
+	elapsed := now.Sub(b.updated).Minutes()
+	if elapsed > 0 {
+		b.requests = math.Min(float64(rl.rpm), b.requests+elapsed*float64(rl.rpm))
+		b.tokens = math.Min(float64(rl.tpm), b.tokens+elapsed*float64(rl.tpm))
+		b.updated = now
+	}
+
+	return b
+}
+
+// reserve takes one request from the bucket or returns time to wait and exhausted limit name
+func (rl *rateLimiter) reserve(b *rateLimitBucket) (time.Duration, string) {
+	var (
+		wait  time.Duration
+		limit string
+	)
+
+	if rl.rpm > 0 && b.requests < 1 {
+		wait = time.Duration((1 - b.requests) / float64(rl.rpm) * float64(time.Minute))
+		limit = "requests"
+	}
+
+	// tokens are charged after the call, so the bucket may be overdrawn by the previous calls
+	if rl.tpm > 0 && b.tokens <= 0 {
+		if twait := time.Duration((1 - b.tokens) / float64(rl.tpm) * float64(time.Minute)); twait > wait {
+			wait, limit = twait, "tokens"
+		}
+	}
+
+	if limit == "" && rl.rpm > 0 {
+		b.requests--
+	}
+
+	return wait, limit
+}
+
+// acquire blocks until the call gets budget or returns RateLimitError if it can't get it within max wait
+func (rl *rateLimiter) acquire(ctx context.Context, key rateLimitKey) error {
+	if !rl.enabled() {
+		return nil
+	}
+
+	deadline := rl.now().Add(rl.maxWait)
+	for {
+		rl.mx.Lock()
+		now := rl.now()
+		b := rl.bucket(key, now)
+		wait, limit := rl.reserve(b)
+		if limit == "" {
+			rl.mx.Unlock()
+			return nil
+		}
+
+		if now.Add(wait).After(deadline) {
+			b.rejected++
+			rl.mx.Unlock()
+			return &RateLimitError{
+				Provider:   key.prvname,
+				Model:      key.model,
+				Limit:      limit,
+				RetryAfter: wait,
+			}
+		}
+
+		b.waiting++
+		rl.mx.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			rl.release(key)
+			return ctx.Err()
+		case <-timer.C:
+			rl.release(key)
+		}
+	}
+}
+
+func (rl *rateLimiter) release(key rateLimitKey) {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+
+	if b, ok := rl.buckets[key]; ok && b.waiting > 0 {
+		b.waiting--
+	}
+}
+
+// charge takes used tokens from the bucket after the call is completed
+func (rl *rateLimiter) charge(key rateLimitKey, tokens int64) {
+	if !rl.enabled() || rl.tpm == 0 || tokens <= 0 {
+		return
+	}
+
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+
+	b := rl.bucket(key, rl.now())
+	b.tokens -= float64(tokens)
+}
+
+// state returns snapshot of all user's buckets sorted by provider name and model
+func (rl *rateLimiter) state(userID int64) []RateLimiterState {
+	if !rl.enabled() {
+		return []RateLimiterState{}
+	}
+
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+
+	now := rl.now()
+	states := make([]RateLimiterState, 0)
+	for key := range rl.buckets {
+		if key.userID != userID {
+			continue
+		}
+
+		b := rl.bucket(key, now)
+		states = append(states, RateLimiterState{
+			Provider:          key.prvname,
+			Model:             key.model,
+			RequestsLimit:     rl.rpm,
+			RequestsAvailable: b.requests,
+			TokensLimit:       rl.tpm,
+			TokensAvailable:   b.tokens,
+			Waiting:           b.waiting,
+			Rejected:          b.rejected,
+			UpdatedAt:         b.updated,
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Provider != states[j].Provider {
+			return states[i].Provider < states[j].Provider
+		}
+		return states[i].Model < states[j].Model
+	})
+
+	return states
+}
+
+// rateLimitedProvider applies the shared limiter to the calls of wrapped provider
+type rateLimitedProvider struct {
+	limiter *rateLimiter
+	userID  int64
+	prvname provider.ProviderName
+
+	provider.Provider
+}
+
+func newRateLimitedProvider(
+	limiter *rateLimiter,
+	prvname provider.ProviderName,
+	userID int64,
+	prv provider.Provider,
+) provider.Provider {
+	if !limiter.enabled() {
+		return prv
+	}
+
+	return &rateLimitedProvider{
+		limiter:  limiter,
+		userID:   userID,
+		prvname:  prvname,
+		Provider: prv,
+	}
+}
+
+func (rp *rateLimitedProvider) key(opt pconfig.ProviderOptionsType) rateLimitKey {
+	return rateLimitKey{
+		userID:  rp.userID,
+		prvname: rp.prvname,
+		model:   rp.Provider.Model(opt),
+	}
+}
+
+func (rp *rateLimitedProvider) chargeResponse(key rateLimitKey, resp *llms.ContentResponse) {
+	if resp == nil {
+		return
+	}
+
+	var usage pconfig.CallUsage
+	for _, choice := range resp.Choices {
+		if choice != nil {
+			usage.Merge(rp.Provider.GetUsage(choice.GenerationInfo))
+		}
+	}
+
+	rp.limiter.charge(key, usage.Input+usage.Output)
+}
+
+func (rp *rateLimitedProvider) Call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	prompt string,
+) (string, error) {
+	key := rp.key(opt)
+	if err := rp.limiter.acquire(ctx, key); err != nil {
+		return "", err
+	}
+
+	result, err := rp.Provider.Call(ctx, opt, prompt)
+	rp.limiter.charge(key, int64((len(prompt)+len(result))/rateLimitCharsPerToken))
+
+	return result, err
+}
+
+func (rp *rateLimitedProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	key := rp.key(opt)
+	if err := rp.limiter.acquire(ctx, key); err != nil {
+		return nil, err
+	}
+
+	resp, err := rp.Provider.CallEx(ctx, opt, chain, streamCb)
+	rp.chargeResponse(key, resp)
+
+	return resp, err
+}
+
+func (rp *rateLimitedProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	key := rp.key(opt)
+	if err := rp.limiter.acquire(ctx, key); err != nil {
+		return nil, err
+	}
+
+	resp, err := rp.Provider.CallWithTools(ctx, opt, chain, tools, streamCb)
+	rp.chargeResponse(key, resp)
+
+	return resp, err
+}
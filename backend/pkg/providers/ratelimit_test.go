@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestRateLimiter(rpm, tpm int, maxWait time.Duration) (*rateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rl := newRateLimiter(rpm, tpm, maxWait)
+	rl.now = clock.Now
+	return rl, clock
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	rl := newRateLimiter(0, 0, time.Minute)
+	assert.False(t, rl.enabled())
+
+	prv := mock.NewProvider(provider.ProviderOpenAI, "gpt")
+	assert.Same(t, prv, newRateLimitedProvider(rl, "openai", 1, prv))
+	assert.Empty(t, rl.state(1))
+}
+
+func TestRateLimiterRequestsBudget(t *testing.T) {
+	rl, clock := newTestRateLimiter(2, 0, 0)
+	key := rateLimitKey{userID: 1, prvname: "openai", model: "gpt"}
+
+	require.NoError(t, rl.acquire(t.Context(), key))
+	require.NoError(t, rl.acquire(t.Context(), key))
+
+	err := rl.acquire(t.Context(), key)
+	var rlErr *RateLimitError
+	require.ErrorAs(t, err, &rlErr)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.True(t, isRetryableProviderError(err))
+	assert.Equal(t, "requests", rlErr.Limit)
+	assert.Equal(t, provider.ProviderName("openai"), rlErr.Provider)
+	assert.Equal(t, 30*time.Second, rlErr.RetryAfter)
+
+	// other model and other user have own buckets
+	require.NoError(t, rl.acquire(t.Context(), rateLimitKey{userID: 1, prvname: "openai", model: "other"}))
+	require.NoError(t, rl.acquire(t.Context(), rateLimitKey{userID: 2, prvname: "openai", model: "gpt"}))
+
+	clock.now = clock.now.Add(30 * time.Second)
+	require.NoError(t, rl.acquire(t.Context(), key))
+
+	states := rl.state(1)
+	require.Len(t, states, 2)
+	assert.Equal(t, "gpt", states[0].Model)
+	assert.Equal(t, int64(1), states[0].Rejected)
+	assert.Equal(t, 2, states[0].RequestsLimit)
+	assert.InDelta(t, 0, states[0].RequestsAvailable, 0.001)
+	assert.Equal(t, "other", states[1].Model)
+}
+
+func TestRateLimiterTokensBudget(t *testing.T) {
+	rl, clock := newTestRateLimiter(0, 1000, 0)
+	key := rateLimitKey{userID: 1, prvname: "anthropic", model: "claude"}
+
+	require.NoError(t, rl.acquire(t.Context(), key))
+	rl.charge(key, 1500)
+
+	err := rl.acquire(t.Context(), key)
+	var rlErr *RateLimitError
+	require.ErrorAs(t, err, &rlErr)
+	assert.Equal(t, "tokens", rlErr.Limit)
+
+	clock.now = clock.now.Add(time.Minute)
+	require.NoError(t, rl.acquire(t.Context(), key))
+
+	states := rl.state(1)
+	require.Len(t, states, 1)
+	assert.InDelta(t, 500, states[0].TokensAvailable, 0.001)
+}
+
+func TestRateLimiterWaitsForBudget(t *testing.T) {
+	rl := newRateLimiter(600, 0, time.Second)
+	key := rateLimitKey{userID: 1, prvname: "openai", model: "gpt"}
+
+	for i := 0; i < 600; i++ {
+		require.NoError(t, rl.acquire(t.Context(), key))
+	}
+
+	start := time.Now()
+	require.NoError(t, rl.acquire(t.Context(), key))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 0, rl.state(1)[0].Waiting)
+}
+
+func TestRateLimiterContextCanceled(t *testing.T) {
+	rl := newRateLimiter(1, 0, time.Hour)
+	key := rateLimitKey{userID: 1, prvname: "openai", model: "gpt"}
+	require.NoError(t, rl.acquire(t.Context(), key))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	err := rl.acquire(ctx, key)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 0, rl.state(1)[0].Waiting)
+}
+
+func TestRateLimitedProviderCall(t *testing.T) {
+	rl, _ := newTestRateLimiter(1, 0, 0)
+	prv := newRateLimitedProvider(rl, "openai", 1, mock.NewProvider(provider.ProviderOpenAI, "gpt"))
+
+	result, err := prv.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "Mock response", result)
+
+	_, err = prv.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestFallbackProviderSwitchesOnRateLimit(t *testing.T) {
+	rl, _ := newTestRateLimiter(1, 0, 0)
+	primary := newRateLimitedProvider(rl, "primary", 1, mock.NewProvider(provider.ProviderOpenAI, "primary-model"))
+	secondary := mock.NewProvider(provider.ProviderAnthropic, "secondary-model")
+
+	fb, err := newFallbackProvider(
+		provider.ProvidersListNames{"primary", "secondary"},
+		[]provider.Provider{primary, secondary},
+	)
+	require.NoError(t, err)
+
+	_, err = fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+	require.NoError(t, err)
+
+	for i := 1; i < fallbackSwitchThreshold; i++ {
+		_, err := fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+		require.ErrorIs(t, err, ErrRateLimited)
+	}
+
+	_, err = fb.Call(t.Context(), pconfig.OptionsTypeSimple, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, provider.ProviderName("secondary"), fb.ActiveName())
+}
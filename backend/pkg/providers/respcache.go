@@ -0,0 +1,324 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"pentagi/pkg/config"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/tools"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// responseCacheNamespace is the namespace of the shared tools cache to keep LLM completions
+const responseCacheNamespace = "llm:responses"
+
+// ResponseCacheMode controls which provider responses of the flow are cached
+type ResponseCacheMode string
+
+const (
+	// ResponseCacheDisabled never uses the cache, it's the default for all flows
+	ResponseCacheDisabled ResponseCacheMode = "disabled"
+	// ResponseCacheDeterministic caches only calls with zero temperature
+	ResponseCacheDeterministic ResponseCacheMode = "deterministic"
+	// ResponseCacheForced caches all calls regardless of the temperature
+	ResponseCacheForced ResponseCacheMode = "forced"
+)
+
+func (m ResponseCacheMode) String() string {
+	return string(m)
+}
+
+// responseCache keeps provider completions in the shared cache keyed by the hash of the request
+type responseCache struct {
+	cache  tools.Cache
+	ttl    time.Duration
+	hits   otelmetric.Int64Counter
+	misses otelmetric.Int64Counter
+}
+
+func newResponseCache(cfg *config.Config, cache tools.Cache) *responseCache {
+	rc := &responseCache{cache: cache}
+	if cfg != nil {
+		rc.ttl = time.Duration(cfg.LLMResponseCacheTTL) * time.Second
+	}
+
+	var err error
+	if rc.hits, err = obs.Observer.NewInt64Counter("providers.response_cache.hits"); err != nil {
+		logrus.WithError(err).Warn("failed to create response cache hits counter")
+	}
+	if rc.misses, err = obs.Observer.NewInt64Counter("providers.response_cache.misses"); err != nil {
+		logrus.WithError(err).Warn("failed to create response cache misses counter")
+	}
+
+	return rc
+}
+
+func (rc *responseCache) enabled() bool {
+	return rc != nil && rc.cache != nil && rc.ttl > 0
+}
+
+func (rc *responseCache) get(ctx context.Context, key string) (*llms.ContentResponse, bool) {
+	data, err := rc.cache.Get(ctx, responseCacheNamespace, key)
+	if err != nil {
+		if !errors.Is(err, tools.ErrCacheMiss) {
+			logrus.WithContext(ctx).WithError(err).Warn("failed to get provider response from cache")
+		}
+		return nil, false
+	}
+
+	var resp llms.ContentResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("failed to decode cached provider response")
+		return nil, false
+	}
+
+	return &resp, true
+}
+
+func (rc *responseCache) set(ctx context.Context, key string, resp *llms.ContentResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("failed to encode provider response to cache")
+		return
+	}
+
+	if err := rc.cache.Set(ctx, responseCacheNamespace, key, data, rc.ttl); err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("failed to set provider response to cache")
+	}
+}
+
+// clear drops all cached completions of all users
+func (rc *responseCache) clear(ctx context.Context) error {
+	if rc == nil || rc.cache == nil {
+		return nil
+	}
+
+	if err := rc.cache.Clear(ctx, responseCacheNamespace); err != nil {
+		return fmt.Errorf("failed to clear provider responses cache: %w", err)
+	}
+
+	return nil
+}
+
+func (rc *responseCache) record(ctx context.Context, counter otelmetric.Int64Counter, prvname provider.ProviderName, model string) {
+	if counter != nil {
+		counter.Add(ctx, 1, otelmetric.WithAttributes(
+			attribute.String("provider", prvname.String()),
+			attribute.String("model", model),
+		))
+	}
+}
+
+// responseCacheKey is the request fields which define the completion, the cache is isolated per user
+type responseCacheKey struct {
+	UserID      int64                 `json:"user_id"`
+	Provider    provider.ProviderName `json:"provider"`
+	Model       string                `json:"model"`
+	Temperature *float64              `json:"temperature"`
+	Chain       []llms.MessageContent `json:"chain"`
+	Tools       []llms.Tool           `json:"tools,omitempty"`
+}
+
+func (k responseCacheKey) hash() (string, error) {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cachedProvider returns stored completions for the exact same requests of the wrapped provider,
+// hits are marked in the generation info to account them with zero cost
+type cachedProvider struct {
+	cache   *responseCache
+	mode    ResponseCacheMode
+	userID  int64
+	prvname provider.ProviderName
+
+	provider.Provider
+}
+
+func newCachedProvider(
+	cache *responseCache,
+	mode ResponseCacheMode,
+	prvname provider.ProviderName,
+	userID int64,
+	prv provider.Provider,
+) provider.Provider {
+	if !cache.enabled() || (mode != ResponseCacheDeterministic && mode != ResponseCacheForced) {
+		return prv
+	}
+
+	return &cachedProvider{
+		cache:    cache,
+		mode:     mode,
+		userID:   userID,
+		prvname:  prvname,
+		Provider: prv,
+	}
+}
+
+// temperature returns the temperature configured for the agent type or nil if it's not set,
+// in this case the provider uses its own default which isn't deterministic
+func (cp *cachedProvider) temperature(opt pconfig.ProviderOptionsType) *float64 {
+	var opts llms.CallOptions
+	for _, option := range cp.Provider.GetProviderConfig().GetOptionsForType(opt) {
+		option(&opts)
+	}
+
+	return opts.Temperature
+}
+
+// key returns the cache key of the request or empty string if the request must not be cached
+func (cp *cachedProvider) key(
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+) string {
+	temperature := cp.temperature(opt)
+	if cp.mode != ResponseCacheForced && (temperature == nil || *temperature != 0) {
+		return ""
+	}
+
+	key, err := responseCacheKey{
+		UserID:      cp.userID,
+		Provider:    cp.prvname,
+		Model:       cp.Provider.Model(opt),
+		Temperature: temperature,
+		Chain:       chain,
+		Tools:       tools,
+	}.hash()
+	if err != nil {
+		logrus.WithError(err).Warn("failed to build provider response cache key")
+		return ""
+	}
+
+	return key
+}
+
+func (cp *cachedProvider) call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	call func() (*llms.ContentResponse, error),
+) (*llms.ContentResponse, error) {
+	key := cp.key(opt, chain, tools)
+	if key == "" {
+		return call()
+	}
+
+	model := cp.Provider.Model(opt)
+	if resp, ok := cp.cache.get(ctx, key); ok {
+		cp.cache.record(ctx, cp.cache.hits, cp.prvname, model)
+		return markCachedResponse(resp), nil
+	}
+
+	cp.cache.record(ctx, cp.cache.misses, cp.prvname, model)
+
+	resp, err := call()
+	if err == nil && isCacheableResponse(resp) {
+		cp.cache.set(ctx, key, resp)
+	}
+
+	return resp, err
+}
+
+func (cp *cachedProvider) Call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	prompt string,
+) (string, error) {
+	key := cp.key(opt, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, prompt)}, nil)
+	if key == "" {
+		return cp.Provider.Call(ctx, opt, prompt)
+	}
+
+	model := cp.Provider.Model(opt)
+	if resp, ok := cp.cache.get(ctx, key); ok && len(resp.Choices) == 1 {
+		cp.cache.record(ctx, cp.cache.hits, cp.prvname, model)
+		return resp.Choices[0].Content, nil
+	}
+
+	cp.cache.record(ctx, cp.cache.misses, cp.prvname, model)
+
+	result, err := cp.Provider.Call(ctx, opt, prompt)
+	if err == nil && result != "" {
+		cp.cache.set(ctx, key, &llms.ContentResponse{
+			Choices: []*llms.ContentChoice{{Content: result}},
+		})
+	}
+
+	return result, err
+}
+
+func (cp *cachedProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	return cp.call(ctx, opt, chain, nil, func() (*llms.ContentResponse, error) {
+		return cp.Provider.CallEx(ctx, opt, chain, streamCb)
+	})
+}
+
+func (cp *cachedProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	return cp.call(ctx, opt, chain, tools, func() (*llms.ContentResponse, error) {
+		return cp.Provider.CallWithTools(ctx, opt, chain, tools, streamCb)
+	})
+}
+
+func isCacheableResponse(resp *llms.ContentResponse) bool {
+	if resp == nil || len(resp.Choices) == 0 {
+		return false
+	}
+
+	for _, choice := range resp.Choices {
+		if choice == nil || (choice.Content == "" && len(choice.ToolCalls) == 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// markCachedResponse flags every choice as the cache hit and drops the upstream cost,
+// so the tokens are still tracked in the usage but the call costs nothing
+func markCachedResponse(resp *llms.ContentResponse) *llms.ContentResponse {
+	for _, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		info := make(map[string]any, len(choice.GenerationInfo)+1)
+		maps.Copy(info, choice.GenerationInfo)
+		delete(info, pconfig.UpstreamPromptCostKey)
+		delete(info, pconfig.UpstreamCompletionCostKey)
+		info[pconfig.ResponseCacheHitKey] = true
+		choice.GenerationInfo = info
+	}
+
+	return resp
+}
@@ -0,0 +1,144 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+	"pentagi/pkg/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+const testResponseCacheConfig = `
+simple:
+  model: gpt
+  temperature: 0
+primary_agent:
+  model: gpt
+  temperature: 0.7
+`
+
+type countingProvider struct {
+	cfg   *pconfig.ProviderConfig
+	calls int
+
+	provider.Provider
+}
+
+func newCountingProvider(t *testing.T) *countingProvider {
+	t.Helper()
+
+	cfg, err := pconfig.LoadConfigData([]byte(testResponseCacheConfig), nil)
+	require.NoError(t, err)
+
+	return &countingProvider{
+		cfg:      cfg,
+		Provider: mock.NewProvider(provider.ProviderOpenAI, "gpt"),
+	}
+}
+
+func (p *countingProvider) GetProviderConfig() *pconfig.ProviderConfig {
+	return p.cfg
+}
+
+func (p *countingProvider) GetUsage(info map[string]any) pconfig.CallUsage {
+	return pconfig.NewCallUsage(info)
+}
+
+func (p *countingProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	p.calls++
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{
+			Content: "answer",
+			GenerationInfo: map[string]any{
+				"PromptTokens":                100,
+				"CompletionTokens":            50,
+				pconfig.UpstreamPromptCostKey: 0.01,
+			},
+		}},
+	}, nil
+}
+
+func newTestResponseCache(ttl int) *responseCache {
+	return newResponseCache(&config.Config{LLMResponseCacheTTL: ttl}, tools.NewMemoryCache(100))
+}
+
+func TestCachedProviderDisabled(t *testing.T) {
+	prv := newCountingProvider(t)
+
+	assert.Same(t, prv, newCachedProvider(newTestResponseCache(60), ResponseCacheDisabled, "openai", 1, prv))
+	assert.Same(t, prv, newCachedProvider(newTestResponseCache(60), "", "openai", 1, prv))
+	assert.Same(t, prv, newCachedProvider(newTestResponseCache(0), ResponseCacheForced, "openai", 1, prv))
+}
+
+func TestCachedProviderDeterministic(t *testing.T) {
+	prv := newCountingProvider(t)
+	cached := newCachedProvider(newTestResponseCache(60), ResponseCacheDeterministic, "openai", 1, prv)
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "scan 10.0.0.1")}
+
+	first, err := cached.CallEx(t.Context(), pconfig.OptionsTypeSimple, chain, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, first.Choices[0].GenerationInfo, pconfig.ResponseCacheHitKey)
+
+	second, err := cached.CallEx(t.Context(), pconfig.OptionsTypeSimple, chain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, prv.calls)
+	assert.Equal(t, "answer", second.Choices[0].Content)
+
+	usage := cached.GetUsage(second.Choices[0].GenerationInfo)
+	usage.UpdateCost(&pconfig.PriceInfo{Input: 1, Output: 2})
+	assert.True(t, usage.ResponseCached)
+	assert.Equal(t, int64(100), usage.Input)
+	assert.Equal(t, int64(50), usage.Output)
+	assert.Zero(t, usage.CostInput)
+	assert.Zero(t, usage.CostOutput)
+
+	// other messages miss the cache
+	other := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "scan 10.0.0.2")}
+	_, err = cached.CallEx(t.Context(), pconfig.OptionsTypeSimple, other, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, prv.calls)
+
+	// non-zero temperature is never cached in deterministic mode
+	for range 2 {
+		_, err = cached.CallEx(t.Context(), pconfig.OptionsTypePrimaryAgent, chain, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, prv.calls)
+}
+
+func TestCachedProviderForced(t *testing.T) {
+	prv := newCountingProvider(t)
+	rc := newTestResponseCache(60)
+	cached := newCachedProvider(rc, ResponseCacheForced, "openai", 1, prv)
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "find exploits")}
+
+	for range 2 {
+		_, err := cached.CallEx(t.Context(), pconfig.OptionsTypePrimaryAgent, chain, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, prv.calls)
+
+	// the cache is isolated per user
+	otherUser := newCachedProvider(rc, ResponseCacheForced, "openai", 2, prv)
+	_, err := otherUser.CallEx(t.Context(), pconfig.OptionsTypePrimaryAgent, chain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, prv.calls)
+
+	require.NoError(t, rc.clear(t.Context()))
+	_, err = cached.CallEx(t.Context(), pconfig.OptionsTypePrimaryAgent, chain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, prv.calls)
+}
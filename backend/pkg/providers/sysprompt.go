@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+)
+
+const (
+	// systemPromptMaxShare is the part of the model context window which the system prompt override may take,
+	// the rest is left for the execution context, tool calls and their results
+	systemPromptMaxShare = 0.5
+	// systemPromptBytesPerToken is the rough size of the token to estimate the prompt size without the tokenizer
+	systemPromptBytesPerToken = 4
+)
+
+var ErrSystemPromptTooLarge = errors.New("system prompt leaves no room in the model context window")
+
+// EstimatePromptTokens returns the rough number of tokens in the prompt, it counts bytes instead of
+// characters to not underestimate the non-latin text
+func EstimatePromptTokens(prompt string) int {
+	return (len(prompt) + systemPromptBytesPerToken - 1) / systemPromptBytesPerToken
+}
+
+// CheckSystemPrompt returns error if the system prompt override of the primary agent takes more than the half
+// of its model context window, models with unknown context window aren't checked
+func CheckSystemPrompt(
+	prvname provider.ProviderName,
+	prv provider.Provider,
+	models pconfig.ModelsConfig,
+	prompt string,
+) error {
+	model := prv.Model(pconfig.OptionsTypePrimaryAgent)
+	idx := slices.IndexFunc(models, func(m pconfig.ModelConfig) bool { return m.Name == model })
+	if idx == -1 || models[idx].ContextWindow == nil || *models[idx].ContextWindow <= 0 {
+		return nil
+	}
+
+	contextWindow := *models[idx].ContextWindow
+	limit := int(float64(contextWindow) * systemPromptMaxShare)
+	if tokens := EstimatePromptTokens(prompt); tokens > limit {
+		return fmt.Errorf("%w: system prompt takes about %d tokens of %d in context window of model '%s' "+
+			"in provider '%s', the limit is %d tokens", ErrSystemPromptTooLarge, tokens, contextWindow, model, prvname, limit)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatePromptTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimatePromptTokens(""))
+	assert.Equal(t, 1, EstimatePromptTokens("abc"))
+	assert.Equal(t, 2, EstimatePromptTokens("abcde"))
+	assert.Equal(t, 3, EstimatePromptTokens("日本語の"), "non-latin text is estimated by bytes")
+}
+
+func TestCheckSystemPrompt(t *testing.T) {
+	prv := mock.NewProvider(provider.ProviderOpenAI, "gpt")
+	window := 1000
+	prompt := strings.Repeat("a", 4*500)
+
+	assert.NoError(t, CheckSystemPrompt("openai", prv, nil, prompt), "unknown models list is not checked")
+	assert.NoError(t, CheckSystemPrompt("openai", prv, pconfig.ModelsConfig{{Name: "gpt"}}, prompt+"a"),
+		"unknown context window is not checked")
+
+	models := pconfig.ModelsConfig{{Name: "other"}, {Name: "gpt", ContextWindow: &window}}
+	assert.NoError(t, CheckSystemPrompt("openai", prv, models, prompt), "half of the window is allowed")
+
+	err := CheckSystemPrompt("openai", prv, models, prompt+"a")
+	assert.ErrorIs(t, err, ErrSystemPromptTooLarge)
+	assert.ErrorContains(t, err, "about 501 tokens of 1000 in context window of model 'gpt' in provider 'openai'")
+}
@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// ErrProviderTimeout is returned when the provider call exceeds its per-call timeout
+var ErrProviderTimeout = errors.New("provider call timed out")
+
+const (
+	callErrorReasonTimeout = "timeout"
+	callErrorReasonError   = "error"
+)
+
+// ProviderTimeoutError describes the provider call which was aborted by the per-call timeout,
+// it matches ErrProviderTimeout and context.DeadlineExceeded by errors.Is
+type ProviderTimeoutError struct {
+	Provider provider.ProviderName
+	Model    string
+	Timeout  time.Duration
+}
+
+func (e *ProviderTimeoutError) Error() string {
+	return fmt.Sprintf("%s: provider '%s' model '%s' didn't respond in %s",
+		ErrProviderTimeout.Error(), e.Provider, e.Model, e.Timeout)
+}
+
+func (e *ProviderTimeoutError) Is(target error) bool {
+	return target == ErrProviderTimeout || target == context.DeadlineExceeded
+}
+
+// newCallErrorsCounter returns the counter of failed provider calls by the reason,
+// the meter returns the same instrument for all wrapped providers
+func newCallErrorsCounter() otelmetric.Int64Counter {
+	counter, err := obs.Observer.NewInt64Counter("providers.calls.errors")
+	if err != nil {
+		logrus.WithError(err).Warn("failed to create provider call errors counter")
+		return nil
+	}
+	return counter
+}
+
+// timeoutProvider aborts the calls of wrapped provider which take longer than the timeout,
+// the deadline is derived from the caller context so the cancellation still propagates
+type timeoutProvider struct {
+	timeout time.Duration
+	prvname provider.ProviderName
+	errors  otelmetric.Int64Counter
+
+	provider.Provider
+}
+
+func newTimeoutProvider(timeout time.Duration, prvname provider.ProviderName, prv provider.Provider) provider.Provider {
+	if timeout <= 0 {
+		return prv
+	}
+
+	return &timeoutProvider{
+		timeout:  timeout,
+		prvname:  prvname,
+		errors:   newCallErrorsCounter(),
+		Provider: prv,
+	}
+}
+
+// wrapError replaces the error of the call which hit the own deadline by ProviderTimeoutError,
+// errors after the caller context cancellation are returned as is
+func (tp *timeoutProvider) wrapError(
+	ctx, callCtx context.Context,
+	opt pconfig.ProviderOptionsType,
+	err error,
+) error {
+	if err == nil {
+		return nil
+	}
+
+	reason := callErrorReasonError
+	model := tp.Provider.Model(opt)
+	if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		reason = callErrorReasonTimeout
+		err = &ProviderTimeoutError{Provider: tp.prvname, Model: model, Timeout: tp.timeout}
+	}
+
+	if tp.errors != nil {
+		tp.errors.Add(ctx, 1, otelmetric.WithAttributes(
+			attribute.String("provider", tp.prvname.String()),
+			attribute.String("model", model),
+			attribute.String("reason", reason),
+		))
+	}
+
+	return err
+}
+
+func (tp *timeoutProvider) Call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	prompt string,
+) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, tp.timeout)
+	defer cancel()
+
+	result, err := tp.Provider.Call(callCtx, opt, prompt)
+	return result, tp.wrapError(ctx, callCtx, opt, err)
+}
+
+func (tp *timeoutProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	callCtx, cancel := context.WithTimeout(ctx, tp.timeout)
+	defer cancel()
+
+	resp, err := tp.Provider.CallEx(callCtx, opt, chain, streamCb)
+	return resp, tp.wrapError(ctx, callCtx, opt, err)
+}
+
+func (tp *timeoutProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	callCtx, cancel := context.WithTimeout(ctx, tp.timeout)
+	defer cancel()
+
+	resp, err := tp.Provider.CallWithTools(callCtx, opt, chain, tools, streamCb)
+	return resp, tp.wrapError(ctx, callCtx, opt, err)
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+// slowProvider answers after the delay or fails with the context error like the HTTP client does
+type slowProvider struct {
+	delay time.Duration
+
+	provider.Provider
+}
+
+func (p *slowProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	select {
+	case <-time.After(p.delay):
+		return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "answer"}}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func newSlowProvider(delay time.Duration) *slowProvider {
+	return &slowProvider{
+		delay:    delay,
+		Provider: mock.NewProvider(provider.ProviderOpenAI, "gpt"),
+	}
+}
+
+func TestTimeoutProviderDisabled(t *testing.T) {
+	prv := newSlowProvider(0)
+
+	assert.Same(t, prv, newTimeoutProvider(0, "openai", prv))
+}
+
+func TestTimeoutProviderAbortsAtDeadline(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	prv := newTimeoutProvider(timeout, "openai", newSlowProvider(time.Minute))
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "scan 10.0.0.1")}
+
+	start := time.Now()
+	resp, err := prv.CallEx(t.Context(), pconfig.OptionsTypePrimaryAgent, chain, nil)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.GreaterOrEqual(t, elapsed, timeout)
+	assert.Less(t, elapsed, 10*timeout, "call should abort at the deadline")
+
+	assert.ErrorIs(t, err, ErrProviderTimeout)
+	assert.True(t, isRetryableProviderError(err))
+
+	var timeoutErr *ProviderTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, provider.ProviderName("openai"), timeoutErr.Provider)
+	assert.Equal(t, timeout, timeoutErr.Timeout)
+}
+
+func TestTimeoutProviderParentCanceled(t *testing.T) {
+	prv := newTimeoutProvider(time.Minute, "openai", newSlowProvider(time.Minute))
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "scan 10.0.0.1")}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := prv.CallEx(ctx, pconfig.OptionsTypePrimaryAgent, chain, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrProviderTimeout)
+	assert.False(t, isRetryableProviderError(err))
+}
+
+func TestTimeoutProviderFastCall(t *testing.T) {
+	prv := newTimeoutProvider(time.Minute, "openai", newSlowProvider(0))
+	chain := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "scan 10.0.0.1")}
+
+	resp, err := prv.CallEx(t.Context(), pconfig.OptionsTypePrimaryAgent, chain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "answer", resp.Choices[0].Content)
+}
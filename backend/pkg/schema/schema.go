@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
-	"slices"
-	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/xeipuuv/gojsonschema"
@@ -205,12 +203,6 @@ func (t Type) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON is a JSON interface function to parse JSON data bytes array and to get struct object
 func (t *Type) UnmarshalJSON(input []byte) error {
-	var excludeKeys []string
-	tp := reflect.TypeOf(Type{})
-	for i := 0; i < tp.NumField(); i++ {
-		field := tp.Field(i)
-		excludeKeys = append(excludeKeys, strings.Split(field.Tag.Get("json"), ",")[0])
-	}
 	type tn Type
 	if err := json.Unmarshal(input, (*tn)(t)); err != nil {
 		return err
@@ -219,9 +211,19 @@ func (t *Type) UnmarshalJSON(input []byte) error {
 	if err := json.Unmarshal(input, &raw); err != nil {
 		return err
 	}
+	// known properties with zero values (e.g. minimum: 0) are omitted on marshaling,
+	// so they are kept as extended ones to get the same schema back
+	data, err := json.Marshal((*tn)(t))
+	if err != nil {
+		return err
+	}
+	known := make(map[string]interface{})
+	if err := json.Unmarshal(data, &known); err != nil {
+		return err
+	}
 	t.ExtProps = make(map[string]interface{})
 	for k, v := range raw {
-		if !slices.Contains(excludeKeys, k) {
+		if _, ok := known[k]; !ok {
 			t.ExtProps[k] = v
 		}
 	}
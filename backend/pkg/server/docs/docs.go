@@ -255,6 +255,128 @@ const docTemplate = `{
                 }
             }
         },
+        "/audit/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Retrieve audit logs list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id to filter audit logs",
+                        "name": "flow_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "audit logs list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.auditLogs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting audit logs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting audit logs",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/authorize": {
             "get": {
                 "produces": [
@@ -509,6 +631,140 @@ const docTemplate = `{
                 }
             }
         },
+        "/cache/providers": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Invalidate cached responses of the LLM providers",
+                "responses": {
+                    "200": {
+                        "description": "providers cache invalidated successful",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "invalidating providers cache not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on invalidating providers cache",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cache/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Counters are kept in memory of the server instance since its start",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Retrieve search results cache counters per tool",
+                "responses": {
+                    "200": {
+                        "description": "search cache counters received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SearchCacheStats"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting search cache counters not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cache/search/{toolName}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Invalidate cached results of the search tool",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "sploitus",
+                        "description": "search tool name",
+                        "name": "toolName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "search cache invalidated successful",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "invalidating search cache not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "search tool not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on invalidating search cache",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/containers/": {
             "get": {
                 "security": [
@@ -624,7 +880,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/": {
+        "/flow-templates/": {
             "get": {
                 "security": [
                     {
@@ -635,9 +891,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Flows"
+                    "FlowTemplates"
                 ],
-                "summary": "Retrieve flows list",
+                "summary": "Retrieve flow templates list",
                 "parameters": [
                     {
                         "type": "array",
@@ -701,7 +957,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "flows list received successful",
+                        "description": "flow templates list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -711,7 +967,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.flows"
+                                            "$ref": "#/definitions/services.flowTemplates"
                                         }
                                     }
                                 }
@@ -725,13 +981,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting flows not permitted",
+                        "description": "getting flow templates not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flows",
+                        "description": "internal error on getting flow templates",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -751,23 +1007,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Flows"
+                    "FlowTemplates"
                 ],
-                "summary": "Create new flow with custom functions",
+                "summary": "Create new flow template",
                 "parameters": [
                     {
-                        "description": "flow model to create",
+                        "description": "flow template to create",
                         "name": "json",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateFlow"
+                            "$ref": "#/definitions/models.SaveFlowTemplate"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "flow created successful",
+                        "description": "flow template created successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -777,7 +1033,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Flow"
+                                            "$ref": "#/definitions/models.FlowTemplate"
                                         }
                                     }
                                 }
@@ -785,19 +1041,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid flow request data",
+                        "description": "invalid flow template request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "creating flow not permitted",
+                        "description": "creating flow template not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on creating flow",
+                        "description": "internal error on creating flow template",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -805,7 +1061,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}": {
+        "/flow-templates/{templateID}": {
             "get": {
                 "security": [
                     {
@@ -816,22 +1072,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Flows"
+                    "FlowTemplates"
                 ],
-                "summary": "Retrieve flow by id",
+                "summary": "Retrieve flow template by id",
                 "parameters": [
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "flow id",
-                        "name": "flowID",
+                        "description": "flow template id",
+                        "name": "templateID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow received successful",
+                        "description": "flow template received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -841,27 +1097,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Flow"
+                                            "$ref": "#/definitions/models.FlowTemplate"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "invalid flow template request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "getting flow not permitted",
+                        "description": "getting flow template not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow not found",
+                        "description": "flow template not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow",
+                        "description": "internal error on getting flow template",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -881,31 +1143,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Flows"
+                    "FlowTemplates"
                 ],
-                "summary": "Patch flow",
+                "summary": "Update flow template",
                 "parameters": [
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "flow id",
-                        "name": "flowID",
+                        "description": "flow template id",
+                        "name": "templateID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "flow model to patch",
+                        "description": "flow template to update",
                         "name": "json",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.PatchFlow"
+                            "$ref": "#/definitions/models.SaveFlowTemplate"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow patched successful",
+                        "description": "flow template updated successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -915,7 +1177,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Flow"
+                                            "$ref": "#/definitions/models.FlowTemplate"
                                         }
                                     }
                                 }
@@ -923,19 +1185,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid flow request data",
+                        "description": "invalid flow template request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "patching flow not permitted",
+                        "description": "updating flow template not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow template not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on patching flow",
+                        "description": "internal error on updating flow template",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -948,23 +1216,26 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Flows"
+                    "FlowTemplates"
                 ],
-                "summary": "Delete flow by id",
+                "summary": "Delete flow template",
                 "parameters": [
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "flow id",
-                        "name": "flowID",
+                        "description": "flow template id",
+                        "name": "templateID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow deleted successful",
+                        "description": "flow template deleted successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -974,27 +1245,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Flow"
+                                            "$ref": "#/definitions/models.FlowTemplate"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "invalid flow template request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "deleting flow not permitted",
+                        "description": "deleting flow template not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow not found",
+                        "description": "flow template not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on deleting flow",
+                        "description": "internal error on deleting flow template",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1002,29 +1279,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/agentlogs/": {
+        "/flows/": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Flows are streamed as CSV without grouping if it's requested by Accept header or format param",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Agentlogs"
+                    "Flows"
                 ],
-                "summary": "Retrieve agentlogs list by flow id",
+                "summary": "Retrieve flows list",
                 "parameters": [
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "flow id",
-                        "name": "flowID",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "array",
                         "items": {
@@ -1083,11 +1354,41 @@ const docTemplate = `{
                         "name": "type",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include deleted flows to find the restorable ones, admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2026-01-02T00:00:00+02:00",
+                        "description": "inclusive lower bound of flow creation time in RFC3339 with timezone",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2026-01-03T00:00:00+02:00",
+                        "description": "exclusive upper bound of flow creation time in RFC3339 with timezone",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "flows list format",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "agentlogs list received successful",
+                        "description": "flows list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1097,7 +1398,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.agentlogs"
+                                            "$ref": "#/definitions/services.flows"
                                         }
                                     }
                                 }
@@ -1111,106 +1412,181 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting agentlogs not permitted",
+                        "description": "getting flows not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting agentlogs",
+                        "description": "internal error on getting flows",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/flows/{flowID}/assistantlogs/": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Assistantlogs"
+                    "Flows"
                 ],
-                "summary": "Retrieve assistantlogs list by flow id",
+                "summary": "Create new flow with custom functions",
                 "parameters": [
                     {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "flow id",
-                        "name": "flowID",
-                        "in": "path",
-                        "required": true
+                        "description": "flow model to create",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateFlow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "flow created successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Flow"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
+                    "400": {
+                        "description": "invalid flow request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
+                    "403": {
+                        "description": "creating flow not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
+                    "429": {
+                        "description": "user already runs the maximum number of flows",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on creating flow",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     },
+                    "503": {
+                        "description": "server is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams flow_created events of the user flows or of the flows of all users for flows admin,\nevery event id is the sequence number of the user flows events,\nevents after last_seq (or Last-Event-ID header) are replayed first if they are still buffered\notherwise the resync_required event is sent and the flows list must be fetched again",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Flows"
+                ],
+                "summary": "Subscribe to flows creation events",
+                "parameters": [
                     {
-                        "maximum": 1000,
-                        "minimum": -1,
                         "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
+                        "description": "Sequence number of the last received event to replay the missed ones",
+                        "name": "last_seq",
                         "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "flows events stream opened successful",
+                        "schema": {
                             "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
-                        "in": "query"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid flows request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     },
+                    "403": {
+                        "description": "subscribing to flows events not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on subscribing to flows events",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/status": {
+            "post": {
+                "security": [
                     {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
-                        "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the map of the flow id to its status, flows which aren't available for the user are omitted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flows"
+                ],
+                "summary": "Retrieve statuses of flows by ids",
+                "parameters": [
+                    {
+                        "description": "ids of the flows",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FlowsStatusRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "assistantlogs list received successful",
+                        "description": "flows statuses received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1220,7 +1596,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.assistantlogs"
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "$ref": "#/definitions/models.FlowStatusInfo"
+                                            }
                                         }
                                     }
                                 }
@@ -1228,19 +1607,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid query request data",
+                        "description": "invalid flows status request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting assistantlogs not permitted",
+                        "description": "getting flows statuses not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting assistantlogs",
+                        "description": "internal error on getting flows statuses",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1248,7 +1627,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/assistants/": {
+        "/flows/{flowID}": {
             "get": {
                 "security": [
                     {
@@ -1259,9 +1638,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Assistants"
+                    "Flows"
                 ],
-                "summary": "Retrieve assistants list",
+                "summary": "Retrieve flow by id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1272,68 +1651,15 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 1000,
-                        "minimum": -1,
-                        "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
                         "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
+                        "description": "entity tag of the flow received before",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "assistants list received successful",
+                        "description": "flow received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1343,39 +1669,43 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.assistants"
+                                            "$ref": "#/definitions/models.Flow"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "invalid query request data",
+                    "304": {
+                        "description": "flow wasn't changed"
+                    },
+                    "403": {
+                        "description": "getting flow not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "getting assistants not permitted",
+                    "404": {
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting assistants",
+                        "description": "internal error on getting flow",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "The budget action replaces the flow usage limits and resumes the flow stopped by exceeded budget,\nthe input action queues the input and returns its id in the inputs history by the header",
                 "consumes": [
                     "application/json"
                 ],
@@ -1383,9 +1713,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Assistants"
+                    "Flows"
                 ],
-                "summary": "Create new assistant with custom functions",
+                "summary": "Patch flow",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1396,18 +1726,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "assistant model to create",
+                        "description": "flow model to patch",
                         "name": "json",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateAssistant"
+                            "$ref": "#/definitions/models.PatchFlow"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "assistant created successful",
+                    "200": {
+                        "description": "flow patched successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1417,48 +1747,49 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.AssistantFlow"
+                                            "$ref": "#/definitions/models.Flow"
                                         }
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "X-Flow-Input-ID": {
+                                "type": "integer",
+                                "description": "id of the queued input for the input action"
+                            }
                         }
                     },
                     "400": {
-                        "description": "invalid assistant request data",
+                        "description": "invalid flow request data or flow usage exceeds the budget",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "creating assistant not permitted",
+                        "description": "patching flow not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on creating assistant",
+                        "description": "internal error on patching flow",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/flows/{flowID}/assistants/{assistantID}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Assistants"
+                    "Flows"
                 ],
-                "summary": "Retrieve flow assistant by id",
+                "summary": "Delete flow by id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1467,19 +1798,75 @@ const docTemplate = `{
                         "name": "flowID",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "flow deleted successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Flow"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
+                    "403": {
+                        "description": "deleting flow not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on deleting flow or flow containers were partially cleaned up",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/{flowID}/acl": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flows"
+                ],
+                "summary": "Retrieve flow access list by flow id",
+                "parameters": [
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "assistant id",
-                        "name": "assistantID",
+                        "description": "flow id",
+                        "name": "flowID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow assistant received successful",
+                        "description": "flow access list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1489,39 +1876,48 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Assistant"
+                                            "$ref": "#/definitions/services.flowACLs"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "invalid flow request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "getting flow assistant not permitted",
+                        "description": "getting flow access list not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow assistant not found",
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow assistant",
+                        "description": "internal error on getting flow access list",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/flows/{flowID}/acl/{userID}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Shared users get the flow with their own role privileges, edit access is required to change it",
                 "consumes": [
                     "application/json"
                 ],
@@ -1529,9 +1925,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Assistants"
+                    "Flows"
                 ],
-                "summary": "Patch assistant",
+                "summary": "Grant access to the flow for the user",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1544,24 +1940,24 @@ const docTemplate = `{
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "assistant id",
-                        "name": "assistantID",
+                        "description": "user id to share the flow with",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "assistant model to patch",
+                        "description": "flow access level",
                         "name": "json",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.PatchAssistant"
+                            "$ref": "#/definitions/models.GrantFlowAccess"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "assistant patched successful",
+                        "description": "flow access granted successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1571,7 +1967,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.AssistantFlow"
+                                            "$ref": "#/definitions/models.FlowACL"
                                         }
                                     }
                                 }
@@ -1579,19 +1975,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid assistant request data",
+                        "description": "invalid flow access request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "patching assistant not permitted",
+                        "description": "sharing flow not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow or user not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on patching assistant",
+                        "description": "internal error on granting flow access",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1604,10 +2006,13 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Assistants"
+                    "Flows"
                 ],
-                "summary": "Delete assistant by id",
+                "summary": "Revoke access to the flow from the user",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1620,45 +2025,39 @@ const docTemplate = `{
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "assistant id",
-                        "name": "assistantID",
+                        "description": "user id to revoke the flow access from",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "assistant deleted successful",
+                        "description": "flow access revoked successful",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.AssistantFlow"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid flow access request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "deleting assistant not permitted",
+                        "description": "revoking flow access not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "assistant not found",
+                        "description": "flow or flow access not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on deleting assistant",
+                        "description": "internal error on revoking flow access",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1666,7 +2065,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/containers/": {
+        "/flows/{flowID}/agentlogs/": {
             "get": {
                 "security": [
                     {
@@ -1677,9 +2076,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Containers"
+                    "Agentlogs"
                 ],
-                "summary": "Retrieve containers list by flow id",
+                "summary": "Retrieve agentlogs list by flow id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1751,7 +2150,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "containers list received successful",
+                        "description": "agentlogs list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1761,7 +2160,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.containers"
+                                            "$ref": "#/definitions/services.agentlogs"
                                         }
                                     }
                                 }
@@ -1775,13 +2174,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting containers not permitted",
+                        "description": "getting agentlogs not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting containers",
+                        "description": "internal error on getting agentlogs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1789,7 +2188,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/containers/{containerID}": {
+        "/flows/{flowID}/artifacts/": {
             "get": {
                 "security": [
                     {
@@ -1800,9 +2199,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Containers"
+                    "Flows"
                 ],
-                "summary": "Retrieve container info by id and flow id",
+                "summary": "Retrieve flow artifacts list",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1811,19 +2210,11 @@ const docTemplate = `{
                         "name": "flowID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "container id",
-                        "name": "containerID",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "container info received successful",
+                        "description": "flow artifacts received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -1833,27 +2224,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Container"
+                                            "$ref": "#/definitions/services.flowArtifacts"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "invalid flow artifacts request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "getting container not permitted",
+                        "description": "getting flow artifacts not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "container not found",
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting container",
+                        "description": "internal error on getting flow artifacts",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1861,7 +2258,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/graph": {
+        "/flows/{flowID}/artifacts/{artifactID}/file": {
             "get": {
                 "security": [
                     {
@@ -1869,12 +2266,13 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
+                    "application/octet-stream",
                     "application/json"
                 ],
                 "tags": [
                     "Flows"
                 ],
-                "summary": "Retrieve flow graph by id",
+                "summary": "Retrieve flow artifact file by id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -1883,41 +2281,43 @@ const docTemplate = `{
                         "name": "flowID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "artifact id",
+                        "name": "artifactID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow graph received successful",
+                        "description": "flow artifact file",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.FlowTasksSubtasks"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid flow artifact request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting flow graph not permitted",
+                        "description": "getting flow artifact not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow graph not found",
+                        "description": "flow or artifact not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow graph",
+                        "description": "internal error on getting flow artifact",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1925,7 +2325,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/msglogs/": {
+        "/flows/{flowID}/assistantlogs/": {
             "get": {
                 "security": [
                     {
@@ -1936,9 +2336,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Msglogs"
+                    "Assistantlogs"
                 ],
-                "summary": "Retrieve msglogs list by flow id",
+                "summary": "Retrieve assistantlogs list by flow id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2010,7 +2410,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "msglogs list received successful",
+                        "description": "assistantlogs list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2020,7 +2420,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.msglogs"
+                                            "$ref": "#/definitions/services.assistantlogs"
                                         }
                                     }
                                 }
@@ -2034,13 +2434,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting msglogs not permitted",
+                        "description": "getting assistantlogs not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting msglogs",
+                        "description": "internal error on getting assistantlogs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -2048,7 +2448,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/screenshots/": {
+        "/flows/{flowID}/assistants/": {
             "get": {
                 "security": [
                     {
@@ -2059,9 +2459,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Screenshots"
+                    "Assistants"
                 ],
-                "summary": "Retrieve screenshots list by flow id",
+                "summary": "Retrieve assistants list",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2133,7 +2533,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "screenshots list received successful",
+                        "description": "assistants list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2143,7 +2543,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.screenshots"
+                                            "$ref": "#/definitions/services.assistants"
                                         }
                                     }
                                 }
@@ -2157,34 +2557,35 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting screenshots not permitted",
+                        "description": "getting assistants not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting screenshots",
+                        "description": "internal error on getting assistants",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/flows/{flowID}/screenshots/{screenshotID}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Screenshots"
+                    "Assistants"
                 ],
-                "summary": "Retrieve screenshot info by id and flow id",
+                "summary": "Create new assistant with custom functions",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2195,17 +2596,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "screenshot id",
-                        "name": "screenshotID",
-                        "in": "path",
-                        "required": true
+                        "description": "assistant model to create",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAssistant"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "screenshot info received successful",
+                    "201": {
+                        "description": "assistant created successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2215,27 +2617,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Screenshot"
+                                            "$ref": "#/definitions/models.AssistantFlow"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "403": {
-                        "description": "getting screenshot not permitted",
+                    "400": {
+                        "description": "invalid assistant request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "screenshot not found",
+                    "403": {
+                        "description": "creating assistant not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting screenshot",
+                        "description": "internal error on creating assistant",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "server is shutting down",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -2243,7 +2651,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/screenshots/{screenshotID}/file": {
+        "/flows/{flowID}/assistants/{assistantID}": {
             "get": {
                 "security": [
                     {
@@ -2251,13 +2659,12 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "image/png",
                     "application/json"
                 ],
                 "tags": [
-                    "Screenshots"
+                    "Assistants"
                 ],
-                "summary": "Retrieve screenshot file by id and flow id",
+                "summary": "Retrieve flow assistant by id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2270,48 +2677,67 @@ const docTemplate = `{
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "screenshot id",
-                        "name": "screenshotID",
+                        "description": "assistant id",
+                        "name": "assistantID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "screenshot file",
+                        "description": "flow assistant received successful",
                         "schema": {
-                            "type": "file"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Assistant"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "403": {
-                        "description": "getting screenshot not permitted",
+                        "description": "getting flow assistant not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow assistant not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting screenshot",
+                        "description": "internal error on getting flow assistant",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/flows/{flowID}/searchlogs/": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Searchlogs"
+                    "Assistants"
                 ],
-                "summary": "Retrieve searchlogs list by flow id",
+                "summary": "Patch assistant",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2322,68 +2748,26 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
+                        "minimum": 0,
                         "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
+                        "description": "assistant id",
+                        "name": "assistantID",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "maximum": 1000,
-                        "minimum": -1,
-                        "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
-                        "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
+                        "description": "assistant model to patch",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PatchAssistant"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "searchlogs list received successful",
+                        "description": "assistant patched successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2393,7 +2777,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.searchlogs"
+                                            "$ref": "#/definitions/models.AssistantFlow"
                                         }
                                     }
                                 }
@@ -2401,40 +2785,35 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid query request data",
+                        "description": "invalid assistant request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting searchlogs not permitted",
+                        "description": "patching assistant not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting searchlogs",
+                        "description": "internal error on patching assistant",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/flows/{flowID}/subtasks/": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Subtasks"
+                    "Assistants"
                 ],
-                "summary": "Retrieve flow subtasks list",
+                "summary": "Delete assistant by id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2445,68 +2824,17 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 1000,
-                        "minimum": -1,
+                        "minimum": 0,
                         "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
-                        "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
+                        "description": "assistant id",
+                        "name": "assistantID",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow subtasks list received successful",
+                        "description": "assistant deleted successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2516,27 +2844,27 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.subtasks"
+                                            "$ref": "#/definitions/models.AssistantFlow"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "invalid query request data",
+                    "403": {
+                        "description": "deleting assistant not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "getting flow subtasks not permitted",
+                    "404": {
+                        "description": "assistant not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow subtasks",
+                        "description": "internal error on deleting assistant",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -2544,7 +2872,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/tasks/": {
+        "/flows/{flowID}/attachments/": {
             "get": {
                 "security": [
                     {
@@ -2555,9 +2883,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Flows"
                 ],
-                "summary": "Retrieve flow tasks list",
+                "summary": "Retrieve flow attachments list",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2566,70 +2894,11 @@ const docTemplate = `{
                         "name": "flowID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 1000,
-                        "minimum": -1,
-                        "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
-                        "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow tasks list received successful",
+                        "description": "flow attachments received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2639,7 +2908,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.tasks"
+                                            "$ref": "#/definitions/services.flowAttachments"
                                         }
                                     }
                                 }
@@ -2647,40 +2916,48 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid query request data",
+                        "description": "invalid flow attachments request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting flow tasks not permitted",
+                        "description": "getting flow attachments not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow tasks",
+                        "description": "internal error on getting flow attachments",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/flows/{flowID}/tasks/{taskID}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "The file is copied to the flow container and listed in the agents context, the name must be unique in the flow",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Flows"
                 ],
-                "summary": "Retrieve flow task by id",
+                "summary": "Upload flow attachment",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2691,17 +2968,16 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "task id",
-                        "name": "taskID",
-                        "in": "path",
+                        "type": "file",
+                        "description": "attachment content",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "flow task received successful",
+                    "201": {
+                        "description": "flow attachment uploaded successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2711,27 +2987,45 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Task"
+                                            "$ref": "#/definitions/models.FlowAttachment"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "invalid flow attachment request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "403": {
-                        "description": "getting flow task not permitted",
+                        "description": "uploading flow attachment not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow task not found",
+                        "description": "flow not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "flow attachment with the same name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "flow attachment is too large",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow task",
+                        "description": "internal error on uploading flow attachment",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -2739,7 +3033,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/tasks/{taskID}/graph": {
+        "/flows/{flowID}/attachments/{attachmentID}/file": {
             "get": {
                 "security": [
                     {
@@ -2747,12 +3041,13 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
+                    "application/octet-stream",
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Flows"
                 ],
-                "summary": "Retrieve flow task graph by id",
+                "summary": "Retrieve flow attachment file by id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2765,45 +3060,39 @@ const docTemplate = `{
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "task id",
-                        "name": "taskID",
+                        "description": "attachment id",
+                        "name": "attachmentID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow task graph received successful",
+                        "description": "flow attachment file",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.FlowTasksSubtasks"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid flow attachment request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting flow task graph not permitted",
+                        "description": "getting flow attachment not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow task graph not found",
+                        "description": "flow or attachment not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow task graph",
+                        "description": "internal error on getting flow attachment",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -2811,7 +3100,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/tasks/{taskID}/subtasks/": {
+        "/flows/{flowID}/containers/": {
             "get": {
                 "security": [
                     {
@@ -2822,9 +3111,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Subtasks"
+                    "Containers"
                 ],
-                "summary": "Retrieve flow task subtasks list",
+                "summary": "Retrieve containers list by flow id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2834,14 +3123,6 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "task id",
-                        "name": "taskID",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "array",
                         "items": {
@@ -2904,7 +3185,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow task subtasks list received successful",
+                        "description": "containers list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2914,7 +3195,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.subtasks"
+                                            "$ref": "#/definitions/services.containers"
                                         }
                                     }
                                 }
@@ -2928,13 +3209,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting flow task subtasks not permitted",
+                        "description": "getting containers not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow subtasks",
+                        "description": "internal error on getting containers",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -2942,7 +3223,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID}": {
+        "/flows/{flowID}/containers/{containerID}": {
             "get": {
                 "security": [
                     {
@@ -2953,9 +3234,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Subtasks"
+                    "Containers"
                 ],
-                "summary": "Retrieve flow task subtask by id",
+                "summary": "Retrieve container info by id and flow id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -2968,23 +3249,15 @@ const docTemplate = `{
                     {
                         "minimum": 0,
                         "type": "integer",
-                        "description": "task id",
-                        "name": "taskID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "subtask id",
-                        "name": "subtaskID",
+                        "description": "container id",
+                        "name": "containerID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "flow task subtask received successful",
+                        "description": "container info received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -2994,7 +3267,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Subtask"
+                                            "$ref": "#/definitions/models.Container"
                                         }
                                     }
                                 }
@@ -3002,19 +3275,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting flow task subtask not permitted",
+                        "description": "getting container not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow task subtask not found",
+                        "description": "container not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow task subtask",
+                        "description": "internal error on getting container",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3022,20 +3295,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/termlogs/": {
+        "/flows/{flowID}/containers/{containerID}/logs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Returns captured terminal logs of the container, with follow=true the response\nis upgraded to server-sent events and new logs are streamed while the container is running",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Termlogs"
+                    "Containers"
                 ],
-                "summary": "Retrieve termlogs list by flow id",
+                "summary": "Retrieve container logs by id and flow id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -3046,68 +3321,23 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
+                        "minimum": 0,
                         "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
+                        "description": "container id",
+                        "name": "containerID",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "maximum": 1000,
-                        "minimum": -1,
-                        "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
+                        "type": "boolean",
+                        "default": false,
+                        "name": "follow",
                         "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
-                        "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "termlogs list received successful",
+                        "description": "container logs received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -3117,7 +3347,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.termlogs"
+                                            "$ref": "#/definitions/services.containerLogs"
                                         }
                                     }
                                 }
@@ -3125,19 +3355,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid query request data",
+                        "description": "invalid container logs request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting termlogs not permitted",
+                        "description": "getting container logs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting termlogs",
+                        "description": "internal error on getting container logs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3145,22 +3381,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/usage": {
-            "get": {
+        "/flows/{flowID}/containers/{containerID}/snapshot": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get comprehensive analytics for a single flow including all breakdowns",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Flows",
-                    "Usage"
+                    "Flows"
                 ],
-                "summary": "Retrieve analytics for specific flow",
+                "summary": "Create snapshot of the flow container",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -3169,11 +3403,19 @@ const docTemplate = `{
                         "name": "flowID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "container id",
+                        "name": "containerID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "flow analytics received successful",
+                    "201": {
+                        "description": "container snapshot created successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -3183,7 +3425,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.FlowUsageResponse"
+                                            "$ref": "#/definitions/models.ContainerSnapshot"
                                         }
                                     }
                                 }
@@ -3191,25 +3433,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid flow id",
+                        "description": "invalid snapshot request data or container is not running",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting flow analytics not permitted",
+                        "description": "creating snapshot not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "flow not found",
+                        "description": "flow or container not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting flow analytics",
+                        "description": "internal error on creating snapshot",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3217,20 +3459,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/flows/{flowID}/vecstorelogs/": {
+        "/flows/{flowID}/events": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Streams flow_updated, flow_deleted, task_created and task_updated events of the flow\nor only requested types of them, task events contain the task subtasks,\nthe stream is closed after the flow is deleted, every event id is the flow event sequence number,\nevents after last_seq (or Last-Event-ID header) are replayed first if they are still buffered\notherwise the resync_required event is sent and the flow must be fetched again,\nmessage_chunk events are streamed on request only, they carry parts of the agent answers\nappended to the previous chunks of the same stream_id and aren't replayed after reconnect",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Vecstorelogs"
+                    "Flows"
                 ],
-                "summary": "Retrieve vecstorelogs list by flow id",
+                "summary": "Subscribe to flow events by flow id",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -3241,98 +3484,50 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Number of page (since 1)",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 1000,
-                        "minimum": -1,
                         "type": "integer",
-                        "default": 5,
-                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
-                        "name": "pageSize",
+                        "description": "Sequence number of the last received event to replay the missed ones",
+                        "name": "last_seq",
                         "in": "query"
                     },
                     {
+                        "maxItems": 5,
                         "type": "array",
                         "items": {
                             "type": "string"
                         },
                         "collectionFormat": "multi",
-                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
-                        "name": "sort[]",
+                        "description": "Event types to stream (repeat the param for several types), all flow events are streamed by default",
+                        "name": "types",
                         "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "sort",
-                            "filter",
-                            "init",
-                            "page",
-                            "size"
-                        ],
-                        "type": "string",
-                        "default": "init",
-                        "description": "Type of request",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "vecstorelogs list received successful",
+                        "description": "flow events stream opened successful",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/services.vecstorelogs"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "invalid query request data",
+                        "description": "invalid flow request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting vecstorelogs not permitted",
+                        "description": "subscribing to flow events not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting vecstorelogs",
+                        "description": "internal error on subscribing to flow events",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3340,87 +3535,105 @@ const docTemplate = `{
                 }
             }
         },
-        "/graphql": {
-            "post": {
+        "/flows/{flowID}/functions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns functions which were sent on the flow creation, the result can be modified\nand sent as is to create a new flow, it's available only for owners of the flow\nbecause functions may contain the access token of the external functions",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "GraphQL"
+                    "Flows"
                 ],
-                "summary": "Perform graphql requests",
+                "summary": "Retrieve flow custom functions by id",
                 "parameters": [
                     {
-                        "description": "graphql request",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/graphql.RawParams"
-                        }
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "graphql response",
+                        "description": "flow functions received successful",
                         "schema": {
-                            "$ref": "#/definitions/graphql.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/tools.Functions"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "invalid graphql request data",
+                    "403": {
+                        "description": "getting flow functions not permitted",
                         "schema": {
-                            "$ref": "#/definitions/graphql.Response"
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "unauthorized",
+                    "404": {
+                        "description": "flow not found",
                         "schema": {
-                            "$ref": "#/definitions/graphql.Response"
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on graphql request",
+                        "description": "internal error on getting flow functions",
                         "schema": {
-                            "$ref": "#/definitions/graphql.Response"
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/info": {
+        "/flows/{flowID}/graph": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "The entity tag of the graph depends on the flow, its tasks and subtasks and the part of them\navailable for the user, so the request with If-None-Match gets 304 until something changes\nProgress is the percentage of completed subtasks of the task and the flow, it's returned\nonly with subtasks and it's empty while there are no subtasks",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Public"
+                    "Flows"
                 ],
-                "summary": "Retrieve current user and system settings",
+                "summary": "Retrieve flow graph by id",
                 "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "boolean arg to refresh current cookie, use explicit false",
-                        "name": "refresh_cookie",
-                        "in": "query"
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "entity tag of the flow graph received before",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "info received successful",
+                        "description": "flow graph received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -3430,27 +3643,30 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.info"
+                                            "$ref": "#/definitions/models.FlowTasksSubtasks"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "304": {
+                        "description": "flow graph wasn't changed"
+                    },
                     "403": {
-                        "description": "getting info not permitted",
+                        "description": "getting flow graph not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "user not found",
+                        "description": "flow graph not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting information about system and config",
+                        "description": "internal error on getting flow graph",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3458,21 +3674,100 @@ const docTemplate = `{
                 }
             }
         },
-        "/msglogs/": {
+        "/flows/{flowID}/inputs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Inputs are consumed by the flow one by one in the order of their ids,\nqueued inputs wait for the running task and canceled ones were dropped by the flow stop",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Msglogs"
+                    "Flows"
                 ],
-                "summary": "Retrieve msglogs list",
+                "summary": "Retrieve flow inputs history by flow id",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "flow inputs received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.flowInputs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid flow request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting flow inputs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting flow inputs",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/{flowID}/memories/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flows"
+                ],
+                "summary": "Retrieve flow memories list by flow id",
                 "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -3535,7 +3830,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "msglogs list received successful",
+                        "description": "flow memories list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -3545,7 +3840,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.msglogs"
+                                            "$ref": "#/definitions/services.flowMemories"
                                         }
                                     }
                                 }
@@ -3559,13 +3854,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting msglogs not permitted",
+                        "description": "getting flow memories not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting msglogs",
+                        "description": "internal error on getting flow memories",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3573,7 +3874,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/prompts/": {
+        "/flows/{flowID}/messages": {
             "get": {
                 "security": [
                     {
@@ -3584,10 +3885,113 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Prompts"
+                    "Flows"
                 ],
-                "summary": "Retrieve prompts list",
+                "summary": "Retrieve flow agents messages by flow id",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Amount of messages to return (max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Maximum length of message content, thinking and tool result (0 means unlimited)",
+                        "name": "max_content",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of messages to skip from the beginning of the flow conversation",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "flow messages received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.flowMessages"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting flow messages not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting flow messages",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/{flowID}/msglogs/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Msglogs"
+                ],
+                "summary": "Retrieve msglogs list by flow id",
                 "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -3650,7 +4054,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "prompts list received successful",
+                        "description": "msglogs list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -3660,7 +4064,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.prompts"
+                                            "$ref": "#/definitions/services.msglogs"
                                         }
                                     }
                                 }
@@ -3674,13 +4078,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting prompts not permitted",
+                        "description": "getting msglogs not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting prompts",
+                        "description": "internal error on getting msglogs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3688,220 +4092,129 @@ const docTemplate = `{
                 }
             }
         },
-        "/prompts/{promptType}": {
+        "/flows/{flowID}/report": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Report contains tasks and subtasks available for the user, large outputs are truncated with the link to the full text",
                 "produces": [
-                    "application/json"
+                    "text/markdown",
+                    "text/html"
                 ],
                 "tags": [
-                    "Prompts"
+                    "Flows"
                 ],
-                "summary": "Retrieve prompt by type",
+                "summary": "Export flow report by id",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "prompt type",
-                        "name": "promptType",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "md",
+                            "html"
+                        ],
+                        "type": "string",
+                        "default": "md",
+                        "description": "Format of the report, markdown is used by default",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "prompt received successful",
+                        "description": "flow report exported successful",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Prompt"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "invalid prompt request data",
+                        "description": "invalid report request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting prompt not permitted",
+                        "description": "exporting flow report not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "prompt not found",
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting prompt",
+                        "description": "internal error on exporting flow report",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/flows/{flowID}/report.sarif": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Results are found in tasks and subtasks available for the user, rules are CVEs or finding types",
                 "produces": [
-                    "application/json"
+                    "application/sarif+json"
                 ],
                 "tags": [
-                    "Prompts"
+                    "Flows"
                 ],
-                "summary": "Update prompt",
+                "summary": "Export flow findings by id in SARIF 2.1.0 format",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "prompt type",
-                        "name": "promptType",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "prompt model to update",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.PatchPrompt"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "prompt updated successful",
+                        "description": "flow findings exported successful",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Prompt"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "201": {
-                        "description": "prompt created successful",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Prompt"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "invalid prompt request data",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "updating prompt not permitted",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "prompt not found",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "internal error on updating prompt",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Prompts"
-                ],
-                "summary": "Delete prompt by type",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "prompt type",
-                        "name": "promptType",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "prompt deleted successful",
-                        "schema": {
-                            "$ref": "#/definitions/SuccessResponse"
+                            "$ref": "#/definitions/report.SARIFLog"
                         }
                     },
                     "400": {
-                        "description": "invalid prompt request data",
+                        "description": "invalid report request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "deleting prompt not permitted",
+                        "description": "exporting flow findings not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "prompt not found",
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on deleting prompt",
+                        "description": "internal error on exporting flow findings",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3909,13 +4222,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/prompts/{promptType}/default": {
+        "/flows/{flowID}/restore": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Flow gets back its status before deletion, running flows become waiting because their tasks were finished on deletion.\nThe flow keeps its containers, snapshots, attachments and artifacts until it's purged after the retention period, the purged ones are reported in the response",
                 "consumes": [
                     "application/json"
                 ],
@@ -3923,39 +4237,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Prompts"
+                    "Flows"
                 ],
-                "summary": "Reset prompt by type to default value",
+                "summary": "Restore deleted flow by id",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "prompt type",
-                        "name": "promptType",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "flow restoring options",
+                        "name": "json",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreFlow"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "prompt reset successful",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.Prompt"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "201": {
-                        "description": "prompt created with default value successful",
+                        "description": "flow restored successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -3965,7 +4270,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.Prompt"
+                                            "$ref": "#/definitions/models.RestoredFlow"
                                         }
                                     }
                                 }
@@ -3973,25 +4278,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid prompt request data",
+                        "description": "invalid flow request data or flow containers were already removed",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "updating prompt not permitted",
+                        "description": "restoring flow not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "prompt not found",
+                        "description": "deleted flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on resetting prompt",
+                        "description": "internal error on restoring flow",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -3999,7 +4304,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/providers/": {
+        "/flows/{flowID}/screenshots/": {
             "get": {
                 "security": [
                     {
@@ -4010,47 +4315,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Providers"
-                ],
-                "summary": "Retrieve providers list",
-                "responses": {
-                    "200": {
-                        "description": "providers list received successful",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.ProviderInfo"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "403": {
-                        "description": "getting providers not permitted",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/roles/": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Roles"
+                    "Screenshots"
                 ],
-                "summary": "Retrieve roles list",
+                "summary": "Retrieve screenshots list by flow id",
                 "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -4113,7 +4389,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "roles list received successful",
+                        "description": "screenshots list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4123,7 +4399,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.roles"
+                                            "$ref": "#/definitions/services.screenshots"
                                         }
                                     }
                                 }
@@ -4137,13 +4413,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting roles not permitted",
+                        "description": "getting screenshots not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting roles",
+                        "description": "internal error on getting screenshots",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4151,27 +4427,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles/{roleID}": {
+        "/flows/{flowID}/screenshots/{screenshotID}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Roles"
+                    "Screenshots"
                 ],
-                "summary": "Retrieve role by id",
+                "summary": "Retrieve screenshot info by id and flow id",
                 "parameters": [
                     {
+                        "minimum": 0,
                         "type": "integer",
-                        "description": "role id",
-                        "name": "id",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "screenshot id",
+                        "name": "screenshotID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "role received successful",
+                        "description": "screenshot info received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4181,27 +4471,27 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.RolePrivileges"
+                                            "$ref": "#/definitions/models.Screenshot"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "invalid query request data",
+                    "403": {
+                        "description": "getting screenshot not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "getting role not permitted",
+                    "404": {
+                        "description": "screenshot not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting role",
+                        "description": "internal error on getting screenshot",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4209,7 +4499,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/screenshots/": {
+        "/flows/{flowID}/screenshots/{screenshotID}/file": {
             "get": {
                 "security": [
                     {
@@ -4217,33 +4507,96 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
+                    "image/png",
                     "application/json"
                 ],
                 "tags": [
                     "Screenshots"
                 ],
-                "summary": "Retrieve screenshots list",
+                "summary": "Retrieve screenshot file by id and flow id",
                 "parameters": [
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
-                        "name": "filters[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Field to group results by",
-                        "name": "group",
-                        "in": "query"
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "minimum": 1,
+                        "minimum": 0,
                         "type": "integer",
-                        "default": 1,
+                        "description": "screenshot id",
+                        "name": "screenshotID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "screenshot file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "getting screenshot not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting screenshot",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/{flowID}/searchlogs/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Searchlogs"
+                ],
+                "summary": "Retrieve searchlogs list by flow id",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
                         "description": "Number of page (since 1)",
                         "name": "page",
                         "in": "query",
@@ -4286,7 +4639,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "screenshots list received successful",
+                        "description": "searchlogs list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4296,7 +4649,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.screenshots"
+                                            "$ref": "#/definitions/services.searchlogs"
                                         }
                                     }
                                 }
@@ -4310,13 +4663,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting screenshots not permitted",
+                        "description": "getting searchlogs not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting screenshots",
+                        "description": "internal error on getting searchlogs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4324,7 +4677,93 @@ const docTemplate = `{
                 }
             }
         },
-        "/searchlogs/": {
+        "/flows/{flowID}/secrets": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The secrets replace all previous ones and are applied to the next terminal command of the flow",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Flows"
+                ],
+                "summary": "Rotate flow secrets",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new secrets of the flow",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FlowSecrets"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "flow secrets rotated successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FlowEnvVar"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid flow secrets request data or secrets key is not set",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "rotating flow secrets not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on rotating flow secrets",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flows/{flowID}/subtasks/": {
             "get": {
                 "security": [
                     {
@@ -4335,10 +4774,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Searchlogs"
+                    "Subtasks"
                 ],
-                "summary": "Retrieve searchlogs list",
+                "summary": "Retrieve flow subtasks list",
                 "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -4401,7 +4848,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "searchlogs list received successful",
+                        "description": "flow subtasks list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4411,7 +4858,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.searchlogs"
+                                            "$ref": "#/definitions/services.subtasks"
                                         }
                                     }
                                 }
@@ -4425,13 +4872,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting searchlogs not permitted",
+                        "description": "getting flow subtasks not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting searchlogs",
+                        "description": "internal error on getting flow subtasks",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4439,7 +4886,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/termlogs/": {
+        "/flows/{flowID}/tasks/": {
             "get": {
                 "security": [
                     {
@@ -4450,10 +4897,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Termlogs"
+                    "Tasks"
                 ],
-                "summary": "Retrieve termlogs list",
+                "summary": "Retrieve flow tasks list",
                 "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -4516,7 +4971,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "termlogs list received successful",
+                        "description": "flow tasks list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4526,7 +4981,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.termlogs"
+                                            "$ref": "#/definitions/services.tasks"
                                         }
                                     }
                                 }
@@ -4540,13 +4995,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting termlogs not permitted",
+                        "description": "getting flow tasks not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting termlogs",
+                        "description": "internal error on getting flow tasks",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4554,18 +5009,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/tokens": {
+        "/flows/{flowID}/tasks/{taskID}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "Tasks"
+                ],
+                "summary": "Retrieve flow task by id",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List API tokens",
                 "responses": {
                     "200": {
-                        "description": "tokens retrieved successful",
+                        "description": "flow task received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4575,7 +5053,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.tokens"
+                                            "$ref": "#/definitions/models.Task"
                                         }
                                     }
                                 }
@@ -4583,44 +5061,61 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "listing tokens not permitted",
+                        "description": "getting flow task not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow task not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on listing tokens",
+                        "description": "internal error on getting flow task",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/flows/{flowID}/tasks/{taskID}/graph": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "Tasks"
                 ],
-                "summary": "Create new API token for automation",
+                "summary": "Retrieve flow task graph by id",
                 "parameters": [
                     {
-                        "description": "Token creation request",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateAPITokenRequest"
-                        }
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "token created successful",
+                    "200": {
+                        "description": "flow task graph received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4630,27 +5125,27 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.APITokenWithSecret"
+                                            "$ref": "#/definitions/models.FlowTasksSubtasks"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "invalid token request or default salt",
+                    "403": {
+                        "description": "getting flow task graph not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "creating token not permitted",
+                    "404": {
+                        "description": "flow task graph not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on creating token",
+                        "description": "internal error on getting flow task graph",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4658,27 +5153,100 @@ const docTemplate = `{
                 }
             }
         },
-        "/tokens/{tokenID}": {
+        "/flows/{flowID}/tasks/{taskID}/subtasks/": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "Subtasks"
                 ],
-                "summary": "Get API token details",
+                "summary": "Retrieve flow task subtasks list",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Token ID",
-                        "name": "tokenID",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "token retrieved successful",
+                        "description": "flow task subtasks list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4688,65 +5256,77 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.APIToken"
+                                            "$ref": "#/definitions/services.subtasks"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "403": {
-                        "description": "accessing token not permitted",
+                    "400": {
+                        "description": "invalid query request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "token not found",
+                    "403": {
+                        "description": "getting flow task subtasks not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting token",
+                        "description": "internal error on getting flow subtasks",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "Subtasks"
                 ],
-                "summary": "Update API token",
+                "summary": "Retrieve flow task subtask by id",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Token ID",
-                        "name": "tokenID",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Token update request",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateAPITokenRequest"
-                        }
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "subtask id",
+                        "name": "subtaskID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "token updated successful",
+                        "description": "flow task subtask received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4756,33 +5336,27 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.APIToken"
+                                            "$ref": "#/definitions/models.Subtask"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "invalid update request",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
                     "403": {
-                        "description": "updating token not permitted",
+                        "description": "getting flow task subtask not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "token not found",
+                        "description": "flow task subtask not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on updating token",
+                        "description": "internal error on getting flow task subtask",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4790,43 +5364,83 @@ const docTemplate = `{
                 }
             },
             "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "Subtasks"
                 ],
-                "summary": "Delete API token",
+                "summary": "Cancel flow task subtask by id",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Token ID",
-                        "name": "tokenID",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "subtask id",
+                        "name": "subtaskID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "token deleted successful",
+                        "description": "flow task subtask canceled successful",
                         "schema": {
-                            "$ref": "#/definitions/SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Subtask"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid subtask request data or subtask has already completed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "deleting token not permitted",
+                        "description": "canceling flow task subtask not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "token not found",
+                        "description": "flow task subtask not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on deleting token",
+                        "description": "internal error on canceling flow task subtask",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4834,87 +5448,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/usage": {
-            "get": {
+        "/flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID}/priority": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get comprehensive analytics for all user's flows including usage, toolcalls, and structural stats",
-                "produces": [
+                "description": "Subtasks with higher priority run first, empty priority moves the subtask to run next",
+                "consumes": [
                     "application/json"
                 ],
-                "tags": [
-                    "Usage"
-                ],
-                "summary": "Retrieve system-wide analytics",
-                "responses": {
-                    "200": {
-                        "description": "analytics received successful",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.SystemUsageResponse"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "403": {
-                        "description": "getting analytics not permitted",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "internal error on getting analytics",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/usage/{period}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get time-series analytics data for week, month, or quarter",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Usage"
+                    "Subtasks"
                 ],
-                "summary": "Retrieve analytics for specific time period",
+                "summary": "Change priority of the planned flow task subtask",
                 "parameters": [
                     {
-                        "enum": [
-                            "week",
-                            "month",
-                            "quarter"
-                        ],
-                        "type": "string",
-                        "description": "period",
-                        "name": "period",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "subtask id",
+                        "name": "subtaskID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "subtask priority",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PrioritizeSubtask"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "period analytics received successful",
+                        "description": "flow task subtask priority changed successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4924,7 +5513,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.PeriodUsageResponse"
+                                            "$ref": "#/definitions/models.Subtask"
                                         }
                                     }
                                 }
@@ -4932,19 +5521,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid period parameter",
+                        "description": "invalid subtask request data or subtask is not planned",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "getting analytics not permitted",
+                        "description": "changing flow task subtask priority not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow task subtask not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting analytics",
+                        "description": "internal error on changing flow task subtask priority",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -4952,18 +5547,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/": {
+        "/flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID}/tools": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Subtasks"
+                ],
+                "summary": "Retrieve tool calls of the flow task subtask with the repeated calls grouped",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "task id",
+                        "name": "taskID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "subtask id",
+                        "name": "subtaskID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Retrieve current user information",
                 "responses": {
                     "200": {
-                        "description": "user info received successful",
+                        "description": "flow task subtask tool calls received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -4973,84 +5599,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.UserRolePrivileges"
+                                            "$ref": "#/definitions/services.subtaskToolcalls"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "403": {
-                        "description": "getting current user not permitted",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "current user not found",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "internal error on getting current user",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/user/password": {
-            "put": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Update password for current user (account)",
-                "parameters": [
-                    {
-                        "description": "container to validate and update account password",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Password"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "account password updated successful",
-                        "schema": {
-                            "$ref": "#/definitions/SuccessResponse"
-                        }
-                    },
                     "400": {
-                        "description": "invalid account password form data",
+                        "description": "invalid subtask request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "updating account password not permitted",
+                        "description": "getting flow task subtask tool calls not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "current user not found",
+                        "description": "flow task subtask not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on updating account password",
+                        "description": "internal error on getting flow task subtask tool calls",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -5058,16 +5633,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/": {
+        "/flows/{flowID}/termlogs/": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Termlogs"
                 ],
-                "summary": "Retrieve users list by filters",
+                "summary": "Retrieve termlogs list by flow id",
                 "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -5130,7 +5718,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "users list received successful",
+                        "description": "termlogs list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -5140,7 +5728,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.users"
+                                            "$ref": "#/definitions/services.termlogs"
                                         }
                                     }
                                 }
@@ -5154,44 +5742,49 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting users not permitted",
+                        "description": "getting termlogs not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting users",
+                        "description": "internal error on getting termlogs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/flows/{flowID}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get comprehensive analytics for a single flow including all breakdowns",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Flows",
+                    "Usage"
                 ],
-                "summary": "Create new user",
+                "summary": "Retrieve analytics for specific flow",
                 "parameters": [
                     {
-                        "description": "user model to create from",
-                        "name": "json",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserPassword"
-                        }
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "user created successful",
+                    "200": {
+                        "description": "flow analytics received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -5201,7 +5794,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.UserRole"
+                                            "$ref": "#/definitions/models.FlowUsageResponse"
                                         }
                                     }
                                 }
@@ -5209,19 +5802,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid user request data",
+                        "description": "invalid flow id",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "creating user not permitted",
+                        "description": "getting flow analytics not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "flow not found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on creating user",
+                        "description": "internal error on getting flow analytics",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -5229,29 +5828,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{hash}": {
+        "/flows/{flowID}/vecstorelogs/": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Vecstorelogs"
                 ],
-                "summary": "Retrieve user by hash",
+                "summary": "Retrieve vecstorelogs list by flow id",
                 "parameters": [
                     {
-                        "maxLength": 32,
-                        "minLength": 32,
-                        "type": "string",
-                        "description": "hash in hex format (md5)",
-                        "name": "hash",
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "flow id",
+                        "name": "flowID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "user received successful",
+                        "description": "vecstorelogs list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -5261,34 +5923,41 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.UserRolePrivileges"
+                                            "$ref": "#/definitions/services.vecstorelogs"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "403": {
-                        "description": "getting user not permitted",
+                    "400": {
+                        "description": "invalid query request data",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "user not found",
+                    "403": {
+                        "description": "getting vecstorelogs not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting user",
+                        "description": "internal error on getting vecstorelogs",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -5296,102 +5965,91 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "GraphQL"
                 ],
-                "summary": "Update user",
+                "summary": "Perform graphql requests",
                 "parameters": [
                     {
-                        "maxLength": 32,
-                        "minLength": 32,
-                        "type": "string",
-                        "description": "user hash in hex format (md5)",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "user model to update",
+                        "description": "graphql request",
                         "name": "json",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserPassword"
+                            "$ref": "#/definitions/graphql.RawParams"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "user updated successful",
+                        "description": "graphql response",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.UserRole"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/graphql.Response"
                         }
                     },
                     "400": {
-                        "description": "invalid user request data",
+                        "description": "invalid graphql request data",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/graphql.Response"
                         }
                     },
                     "403": {
-                        "description": "updating user not permitted",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "user not found",
+                        "description": "unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/graphql.Response"
                         }
                     },
                     "500": {
-                        "description": "internal error on updating user",
+                        "description": "internal error on graphql request",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/graphql.Response"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/info": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Public"
                 ],
-                "summary": "Delete user by hash",
+                "summary": "Retrieve current user and system settings",
                 "parameters": [
                     {
-                        "maxLength": 32,
-                        "minLength": 32,
-                        "type": "string",
-                        "description": "hash in hex format (md5)",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "boolean arg to refresh current cookie, use explicit false",
+                        "name": "refresh_cookie",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "user deleted successful",
+                        "description": "info received successful",
                         "schema": {
-                            "$ref": "#/definitions/SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.info"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "403": {
-                        "description": "deleting user not permitted",
+                        "description": "getting info not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -5403,7 +6061,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "internal error on deleting user",
+                        "description": "internal error on getting information about system and config",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -5411,7 +6069,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/vecstorelogs/": {
+        "/input-templates/": {
             "get": {
                 "security": [
                     {
@@ -5422,9 +6080,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vecstorelogs"
+                    "InputTemplates"
                 ],
-                "summary": "Retrieve vecstorelogs list",
+                "summary": "Retrieve input templates list",
                 "parameters": [
                     {
                         "type": "array",
@@ -5488,7 +6146,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "vecstorelogs list received successful",
+                        "description": "input templates list received successful",
                         "schema": {
                             "allOf": [
                                 {
@@ -5498,7 +6156,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.vecstorelogs"
+                                            "$ref": "#/definitions/services.inputTemplates"
                                         }
                                     }
                                 }
@@ -5512,330 +6170,4636 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "getting vecstorelogs not permitted",
+                        "description": "getting input templates not permitted",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error on getting vecstorelogs",
+                        "description": "internal error on getting input templates",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "string",
-                    "example": "Internal"
-                },
-                "error": {
-                    "type": "string",
-                    "example": "original server error message"
-                },
-                "msg": {
-                    "type": "string",
-                    "example": "internal server error"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "error"
-                }
-            }
-        },
-        "SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "object"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "success"
-                }
-            }
-        },
-        "gqlerror.Error": {
-            "type": "object",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "InputTemplates"
+                ],
+                "summary": "Create new input template",
+                "parameters": [
+                    {
+                        "description": "input template to create",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveInputTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "input template created successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.InputTemplate"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input template request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "creating input template not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on creating input template",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/input-templates/{templateID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "InputTemplates"
+                ],
+                "summary": "Retrieve input template by id",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "input template id",
+                        "name": "templateID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "input template received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.InputTemplate"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input template request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting input template not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "input template not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting input template",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "InputTemplates"
+                ],
+                "summary": "Update input template",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "input template id",
+                        "name": "templateID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "input template to update",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveInputTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "input template updated successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.InputTemplate"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input template request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating input template not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "input template not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on updating input template",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "InputTemplates"
+                ],
+                "summary": "Delete input template",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "input template id",
+                        "name": "templateID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "input template deleted successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.InputTemplate"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input template request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "deleting input template not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "input template not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on deleting input template",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "backend is alive",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.HealthCheck"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Retrieve privileges and capabilities of the current user",
+                "responses": {
+                    "200": {
+                        "description": "user permissions received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserPermissions"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "current user role not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting current user permissions",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/model-prices/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ModelPrices"
+                ],
+                "summary": "Retrieve model prices list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "model prices list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.modelPrices"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting model prices not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting model prices",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The price is used to calculate cost of the flows created since effective_from time, it's now by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ModelPrices"
+                ],
+                "summary": "Create new model price",
+                "parameters": [
+                    {
+                        "description": "model price to create",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveModelPrice"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "model price created successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ModelPrice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid model price request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "creating model price not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on creating model price",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/model-prices/{priceID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cost of the flows which was already calculated is not changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ModelPrices"
+                ],
+                "summary": "Update model price",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "model price id",
+                        "name": "priceID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "model price to update",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveModelPrice"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "model price updated successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ModelPrice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid model price request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating model price not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "model price not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on updating model price",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ModelPrices"
+                ],
+                "summary": "Delete model price",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "model price id",
+                        "name": "priceID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "model price deleted successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ModelPrice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid model price request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "deleting model price not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "model price not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on deleting model price",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/msglogs/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Msglogs"
+                ],
+                "summary": "Retrieve msglogs list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "msglogs list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.msglogs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting msglogs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting msglogs",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prompts/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Retrieve prompts list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prompts list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.prompts"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting prompts not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting prompts",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prompts/{promptType}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Retrieve prompt by type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "prompt type",
+                        "name": "promptType",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prompt received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Prompt"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid prompt request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting prompt not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "prompt not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting prompt",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Update prompt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "prompt type",
+                        "name": "promptType",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "prompt model to update",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PatchPrompt"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prompt updated successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Prompt"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "201": {
+                        "description": "prompt created successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Prompt"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid prompt request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating prompt not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "prompt not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on updating prompt",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Delete prompt by type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "prompt type",
+                        "name": "promptType",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prompt deleted successful",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid prompt request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "deleting prompt not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "prompt not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on deleting prompt",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prompts/{promptType}/default": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prompts"
+                ],
+                "summary": "Reset prompt by type to default value",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "prompt type",
+                        "name": "promptType",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "prompt reset successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Prompt"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "201": {
+                        "description": "prompt created with default value successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Prompt"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid prompt request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating prompt not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "prompt not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on resetting prompt",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/providers/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "Retrieve providers list",
+                "responses": {
+                    "200": {
+                        "description": "providers list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ProviderInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting providers not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/providers/rate-limits": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "Retrieve providers rate limiter state",
+                "responses": {
+                    "200": {
+                        "description": "providers rate limits received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ProviderRateLimit"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting providers rate limits not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/providers/{name}/health": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "Check provider health by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "provider name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "provider health received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ProviderHealth"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting provider health not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "provider not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/providers/{name}/models": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "Retrieve provider models list by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "provider name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "provider models received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ProviderModel"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting provider models not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "provider not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks database, docker daemon, at least one default provider and that flows are accepted, each check is time-bounded",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "backend is ready",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Readiness"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "some dependencies are not healthy",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ErrorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/models.Readiness"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Retrieve roles list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "roles list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.roles"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting roles not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting roles",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{roleID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Retrieve role by id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "role received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RolePrivileges"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting role not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting role",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/screenshots/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Screenshots"
+                ],
+                "summary": "Retrieve screenshots list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "screenshots list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.screenshots"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting screenshots not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting screenshots",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/searchlogs/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Searchlogs"
+                ],
+                "summary": "Retrieve searchlogs list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "searchlogs list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.searchlogs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting searchlogs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting searchlogs",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Search tasks and subtasks by text across flows",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Restrict the search to a single flow",
+                        "name": "flow_id",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Amount items per page (max 100)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 256,
+                        "minLength": 2,
+                        "type": "string",
+                        "description": "Text to search in titles, descriptions and results of tasks and subtasks (case insensitive)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tasks search results received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.tasksSearch"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "searching tasks not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on searching tasks",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/termlogs/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Termlogs"
+                ],
+                "summary": "Retrieve termlogs list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "termlogs list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.termlogs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting termlogs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting termlogs",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "List API tokens",
+                "responses": {
+                    "200": {
+                        "description": "tokens retrieved successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.tokens"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "listing tokens not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on listing tokens",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Create new API token for automation",
+                "parameters": [
+                    {
+                        "description": "Token creation request",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAPITokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "token created successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.APITokenWithSecret"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid token request or default salt",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "creating token not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on creating token",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/{tokenID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Get API token details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token retrieved successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.APIToken"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "accessing token not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "token not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting token",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Update API token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Token update request",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateAPITokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token updated successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.APIToken"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid update request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating token not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "token not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on updating token",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Delete API token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "token deleted successful",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "deleting token not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "token not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on deleting token",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get comprehensive analytics for all user's flows including usage, toolcalls, and structural stats",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "Retrieve system-wide analytics",
+                "responses": {
+                    "200": {
+                        "description": "analytics received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SystemUsageResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting analytics not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting analytics",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/usage/{period}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get time-series analytics data for week, month, or quarter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "Retrieve analytics for specific time period",
+                "parameters": [
+                    {
+                        "enum": [
+                            "week",
+                            "month",
+                            "quarter"
+                        ],
+                        "type": "string",
+                        "description": "period",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "period analytics received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PeriodUsageResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid period parameter",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting analytics not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting analytics",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Retrieve current user information",
+                "responses": {
+                    "200": {
+                        "description": "user info received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserRolePrivileges"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting current user not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "current user not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting current user",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update password for current user (account)",
+                "parameters": [
+                    {
+                        "description": "container to validate and update account password",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Password"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "account password updated successful",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid account password form data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating account password not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "current user not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on updating account password",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Retrieve users list by filters",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "users list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.users"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting users not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting users",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create new user",
+                "parameters": [
+                    {
+                        "description": "user model to create from",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "user created successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserRole"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid user request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "creating user not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on creating user",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{hash}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Retrieve user by hash",
+                "parameters": [
+                    {
+                        "maxLength": 32,
+                        "minLength": 32,
+                        "type": "string",
+                        "description": "hash in hex format (md5)",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserRolePrivileges"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "getting user not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting user",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "maxLength": 32,
+                        "minLength": 32,
+                        "type": "string",
+                        "description": "user hash in hex format (md5)",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user model to update",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user updated successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserRole"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid user request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "updating user not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on updating user",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete user by hash",
+                "parameters": [
+                    {
+                        "maxLength": 32,
+                        "minLength": 32,
+                        "type": "string",
+                        "description": "hash in hex format (md5)",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user deleted successful",
+                        "schema": {
+                            "$ref": "#/definitions/SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "deleting user not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on deleting user",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vecstorelogs/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vecstorelogs"
+                ],
+                "summary": "Retrieve vecstorelogs list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filtering result on server e.g. {\"value\":[...],\"field\":\"...\",\"operator\":\"...\"}\n  field is the unique identifier of the table column, different for each endpoint\n  value should be integer or string or array type, \"value\":123 or \"value\":\"string\" or \"value\":[123,456]\n  operator value should be one of \u003c,\u003c=,\u003e=,\u003e,=,!=,like,not like,in\n  default operator value is 'like' or '=' if field is 'id' or '*_id' or '*_at'",
+                        "name": "filters[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field to group results by",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of page (since 1)",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": -1,
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Amount items per page (min -1, max 1000, -1 means unlimited)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Sorting result on server e.g. {\"prop\":\"...\",\"order\":\"...\"}\n  field order is \"ascending\" or \"descending\" value\n  order is required if prop is not empty",
+                        "name": "sort[]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sort",
+                            "filter",
+                            "init",
+                            "page",
+                            "size"
+                        ],
+                        "type": "string",
+                        "default": "init",
+                        "description": "Type of request",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "vecstorelogs list received successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.vecstorelogs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query request data",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "getting vecstorelogs not permitted",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error on getting vecstorelogs",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "Internal"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "original server error message"
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "internal"
+                },
+                "msg": {
+                    "type": "string",
+                    "example": "internal server error"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "error"
+                }
+            }
+        },
+        "SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "gqlerror.Error": {
+            "type": "object",
+            "properties": {
+                "extensions": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gqlerror.Location"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "gqlerror.Location": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "graphql.RawParams": {
+            "type": "object",
+            "properties": {
+                "extensions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "headers": {
+                    "$ref": "#/definitions/http.Header"
+                },
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "graphql.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gqlerror.Error"
+                    }
+                },
+                "extensions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "hasNext": {
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "http.Header": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "array",
+                "items": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIToken": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "status",
+                "token_id",
+                "ttl",
+                "updated_at"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "role_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                },
+                "token_id": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "type": "integer",
+                    "maximum": 94608000,
+                    "minimum": 60
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.APITokenWithSecret": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "status",
+                "token",
+                "token_id",
+                "ttl",
+                "updated_at"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "role_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "token_id": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "type": "integer",
+                    "maximum": 94608000,
+                    "minimum": 60
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.AgentTypeUsageStats": {
+            "type": "object",
+            "required": [
+                "agent_type",
+                "stats"
+            ],
+            "properties": {
+                "agent_type": {
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/models.UsageStats"
+                }
+            }
+        },
+        "models.Agentlog": {
+            "type": "object",
+            "required": [
+                "executor",
+                "flow_id",
+                "initiator",
+                "task"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "executor": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "initiator": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "subtask_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "task": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.Assistant": {
+            "type": "object",
+            "required": [
+                "flow_id",
+                "language",
+                "model",
+                "model_provider_name",
+                "model_provider_type",
+                "status",
+                "title",
+                "tool_call_id_template",
+                "trace_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "functions": {
+                    "$ref": "#/definitions/tools.Functions"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "language": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_name": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_type": {
+                    "type": "string"
+                },
+                "msgchain_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "tool_call_id_template": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "trace_id": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "use_agents": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.AssistantFlow": {
+            "type": "object",
+            "required": [
+                "flow_id",
+                "language",
+                "model",
+                "model_provider_name",
+                "model_provider_type",
+                "status",
+                "title",
+                "tool_call_id_template",
+                "trace_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "flow": {
+                    "$ref": "#/definitions/models.Flow"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "functions": {
+                    "$ref": "#/definitions/tools.Functions"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "language": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_name": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_type": {
+                    "type": "string"
+                },
+                "msgchain_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "tool_call_id_template": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "trace_id": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "use_agents": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Assistantlog": {
+            "type": "object",
+            "required": [
+                "assistant_id",
+                "flow_id",
+                "result_format",
+                "type"
+            ],
+            "properties": {
+                "assistant_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "message": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "result_format": {
+                    "type": "string"
+                },
+                "thinking": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {}
+            }
+        },
+        "models.AuditChanges": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/models.AuditChange"
+            }
+        },
+        "models.AuditLog": {
+            "type": "object",
+            "required": [
+                "action",
+                "flow_id",
+                "user_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "changes": {
+                    "$ref": "#/definitions/models.AuditChanges"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.AuditMetadata"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.AuditMetadata": {
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "token_id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuthCallback": {
+            "type": "object",
+            "required": [
+                "code",
+                "id_token",
+                "scope",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Container": {
+            "type": "object",
+            "required": [
+                "flow_id",
+                "image",
+                "local_dir",
+                "local_id",
+                "name",
+                "status",
+                "type"
+            ],
+            "properties": {
+                "cpu_limit": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "image": {
+                    "type": "string"
+                },
+                "local_dir": {
+                    "type": "string"
+                },
+                "local_id": {
+                    "type": "string"
+                },
+                "memory_limit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pids_limit": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ContainerResources": {
+            "type": "object",
+            "properties": {
+                "cpus": {
+                    "type": "number",
+                    "example": 2
+                },
+                "memory_mb": {
+                    "type": "integer",
+                    "example": 4096
+                },
+                "pids": {
+                    "type": "integer",
+                    "example": 1024
+                }
+            }
+        },
+        "models.ContainerSnapshot": {
+            "type": "object",
+            "required": [
+                "container_id",
+                "flow_id",
+                "image",
+                "user_id"
+            ],
+            "properties": {
+                "container_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "image": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.CreateAPITokenRequest": {
+            "type": "object",
+            "required": [
+                "ttl"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "ttl": {
+                    "description": "from 1 minute to 3 years",
+                    "type": "integer",
+                    "maximum": 94608000,
+                    "minimum": 60
+                }
+            }
+        },
+        "models.CreateAssistant": {
+            "type": "object",
+            "required": [
+                "input",
+                "provider"
+            ],
+            "properties": {
+                "functions": {
+                    "$ref": "#/definitions/tools.Functions"
+                },
+                "input": {
+                    "type": "string",
+                    "example": "user input for running assistant"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "openai"
+                },
+                "use_agents": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.CreateFlow": {
+            "type": "object",
+            "required": [
+                "fallback_providers",
+                "provider"
+            ],
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/models.FlowBudget"
+                },
+                "command_policy": {
+                    "$ref": "#/definitions/tools.CommandPolicy"
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "TARGET_SCOPE": "10.0.0.0/24"
+                    }
+                },
+                "fallback_providers": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "anthropic",
+                        "gemini"
+                    ]
+                },
+                "functions": {
+                    "$ref": "#/definitions/tools.Functions"
+                },
+                "functions_compat_mode": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "input": {
+                    "type": "string",
+                    "example": "user input for first task in the flow"
+                },
+                "input_template_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "language": {
+                    "type": "string",
+                    "example": "English"
+                },
+                "plan_limits": {
+                    "$ref": "#/definitions/models.FlowPlanLimits"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "openai"
+                },
+                "provider_timeout": {
+                    "type": "integer",
+                    "maximum": 86400,
+                    "minimum": 0,
+                    "example": 300
+                },
+                "queue": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "resources": {
+                    "$ref": "#/definitions/models.ContainerResources"
+                },
+                "response_cache": {
+                    "type": "string",
+                    "enum": [
+                        "disabled",
+                        "deterministic",
+                        "forced"
+                    ],
+                    "example": "deterministic"
+                },
+                "result_limits": {
+                    "$ref": "#/definitions/models.FlowResultLimits"
+                },
+                "scope": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.0/24",
+                        "*.example.com"
+                    ]
+                },
+                "secrets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "API_TOKEN": "secret"
+                    }
+                },
+                "snapshot_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "system_prompt": {
+                    "type": "string",
+                    "maxLength": 65536,
+                    "minLength": 1,
+                    "example": "You are a web application security expert..."
+                },
+                "template_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "tool_limits": {
+                    "$ref": "#/definitions/models.FlowToolLimits"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "domain": "example.com"
+                    }
+                }
+            }
+        },
+        "models.DailyFlowsStats": {
+            "type": "object",
+            "required": [
+                "date",
+                "stats"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/models.FlowsStats"
+                }
+            }
+        },
+        "models.DailyToolcallsStats": {
+            "type": "object",
+            "required": [
+                "date",
+                "stats"
+            ],
             "properties": {
-                "extensions": {
-                    "type": "object",
-                    "additionalProperties": true
+                "date": {
+                    "type": "string"
                 },
-                "locations": {
+                "stats": {
+                    "$ref": "#/definitions/models.ToolcallsStats"
+                }
+            }
+        },
+        "models.DailyUsageStats": {
+            "type": "object",
+            "required": [
+                "date",
+                "stats"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/models.UsageStats"
+                }
+            }
+        },
+        "models.Flow": {
+            "type": "object",
+            "required": [
+                "language",
+                "model",
+                "model_provider_name",
+                "model_provider_type",
+                "status",
+                "title",
+                "tool_call_id_template",
+                "trace_id",
+                "user_id"
+            ],
+            "properties": {
+                "awaiting_input": {
+                    "type": "boolean"
+                },
+                "budget_max_cost": {
+                    "type": "number"
+                },
+                "budget_max_tokens": {
+                    "type": "integer"
+                },
+                "command_policy": {
+                    "$ref": "#/definitions/tools.CommandPolicy"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "fallback_providers": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gqlerror.Location"
+                        "type": "string"
                     }
                 },
-                "message": {
+                "finish_reason": {
+                    "type": "string",
+                    "enum": [
+                        "user_stop",
+                        "user_finish",
+                        "completed",
+                        "budget_exceeded",
+                        "error",
+                        "provider_exhausted",
+                        "plan_limit_exceeded"
+                    ]
+                },
+                "functions": {
+                    "$ref": "#/definitions/tools.Functions"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "language": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "last_error_count": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "model": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_name": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_type": {
+                    "type": "string"
+                },
+                "pending_question": {
+                    "type": "string"
+                },
+                "plan_max_depth": {
+                    "type": "integer"
+                },
+                "plan_max_subtasks": {
+                    "type": "integer"
+                },
+                "provider_timeout": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "response_cache": {
+                    "type": "string",
+                    "enum": [
+                        "disabled",
+                        "deterministic",
+                        "forced"
+                    ]
+                },
+                "result_max_size": {
+                    "type": "integer"
+                },
+                "result_max_source_size": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "stop_reason": {
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "tool_call_id_template": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "tool_calls_max": {
+                    "type": "integer"
+                },
+                "tool_calls_per_minute": {
+                    "type": "integer"
+                },
+                "trace_id": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.FlowACL": {
+            "type": "object",
+            "required": [
+                "access",
+                "flow_id",
+                "user_id"
+            ],
+            "properties": {
+                "access": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.FlowArtifact": {
+            "type": "object",
+            "required": [
+                "checksum",
+                "content_type",
+                "flow_id",
+                "name"
+            ],
+            "properties": {
+                "checksum": {
+                    "type": "string"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "size": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "subtask_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FlowAttachment": {
+            "type": "object",
+            "required": [
+                "checksum",
+                "content_type",
+                "flow_id",
+                "name",
+                "user_id"
+            ],
+            "properties": {
+                "checksum": {
+                    "type": "string"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "path": {
                     "type": "string"
                 },
-                "path": {
-                    "type": "array",
-                    "items": {}
+                "size": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "gqlerror.Location": {
+        "models.FlowBudget": {
             "type": "object",
             "properties": {
-                "column": {
-                    "type": "integer"
+                "max_cost": {
+                    "type": "number",
+                    "example": 10
                 },
-                "line": {
-                    "type": "integer"
+                "max_tokens": {
+                    "type": "integer",
+                    "example": 5000000
                 }
             }
         },
-        "graphql.RawParams": {
+        "models.FlowEnvVar": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "extensions": {
-                    "type": "object",
-                    "additionalProperties": {}
-                },
-                "headers": {
-                    "$ref": "#/definitions/http.Header"
+                "name": {
+                    "type": "string",
+                    "example": "API_TOKEN"
                 },
-                "operationName": {
-                    "type": "string"
+                "secret": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "query": {
+                "updated_at": {
                     "type": "string"
                 },
-                "variables": {
-                    "type": "object",
-                    "additionalProperties": {}
+                "value": {
+                    "type": "string",
+                    "example": "[REDACTED]"
                 }
             }
         },
-        "graphql.Response": {
+        "models.FlowExecutionStats": {
             "type": "object",
+            "required": [
+                "flow_title"
+            ],
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "errors": {
+                "flow_title": {
+                    "type": "string"
+                },
+                "tasks": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gqlerror.Error"
+                        "$ref": "#/definitions/models.TaskExecutionStats"
                     }
                 },
-                "extensions": {
-                    "type": "object",
-                    "additionalProperties": {}
-                },
-                "hasNext": {
-                    "type": "boolean"
+                "total_assistants_count": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "label": {
-                    "type": "string"
+                "total_duration_seconds": {
+                    "type": "number",
+                    "minimum": 0
                 },
-                "path": {
-                    "type": "array",
-                    "items": {}
+                "total_toolcalls_count": {
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "http.Header": {
+        "models.FlowInput": {
             "type": "object",
-            "additionalProperties": {
-                "type": "array",
-                "items": {
+            "required": [
+                "flow_id",
+                "input",
+                "status"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "input": {
+                    "type": "string"
+                },
+                "processed_at": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "consumed",
+                        "failed",
+                        "canceled"
+                    ]
                 }
             }
         },
-        "models.APIToken": {
+        "models.FlowMemory": {
             "type": "object",
             "required": [
-                "created_at",
-                "status",
-                "token_id",
-                "ttl",
-                "updated_at"
+                "flow_id",
+                "key"
             ],
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "deleted_at": {
-                    "type": "string"
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 100
+                "key": {
+                    "type": "string"
                 },
-                "role_id": {
+                "subtask_id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "status": {
-                    "type": "string"
-                },
-                "token_id": {
-                    "type": "string"
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "ttl": {
+                "task_id": {
                     "type": "integer",
-                    "maximum": 94608000,
-                    "minimum": 60
+                    "minimum": 0
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "value": {
+                    "type": "string"
                 }
             }
         },
-        "models.APITokenWithSecret": {
+        "models.FlowMessage": {
             "type": "object",
             "required": [
-                "created_at",
-                "status",
-                "token",
-                "token_id",
-                "ttl",
-                "updated_at"
+                "flow_id",
+                "result_format",
+                "role",
+                "type"
             ],
             "properties": {
-                "created_at": {
+                "content": {
                     "type": "string"
                 },
-                "deleted_at": {
+                "created_at": {
                     "type": "string"
                 },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "name": {
+                "result": {
+                    "type": "string"
+                },
+                "result_format": {
+                    "type": "string"
+                },
+                "role": {
                     "type": "string",
-                    "maxLength": 100
+                    "enum": [
+                        "human",
+                        "ai",
+                        "tool"
+                    ],
+                    "example": "tool"
                 },
-                "role_id": {
+                "subtask_id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "status": {
-                    "type": "string"
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "token": {
+                "thinking": {
                     "type": "string"
                 },
-                "token_id": {
+                "truncated": {
+                    "type": "boolean"
+                },
+                "type": {
                     "type": "string"
+                }
+            }
+        },
+        "models.FlowPlanLimits": {
+            "type": "object",
+            "properties": {
+                "max_depth": {
+                    "type": "integer",
+                    "example": 10
                 },
-                "ttl": {
+                "max_subtasks": {
                     "type": "integer",
-                    "maximum": 94608000,
-                    "minimum": 60
+                    "example": 30
+                }
+            }
+        },
+        "models.FlowPurged": {
+            "type": "object",
+            "properties": {
+                "artifacts": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3
                 },
-                "updated_at": {
-                    "type": "string"
+                "attachments": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
                 },
-                "user_id": {
+                "containers": {
                     "type": "integer",
-                    "minimum": 0
+                    "minimum": 0,
+                    "example": 1
+                },
+                "snapshots": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
                 }
             }
         },
-        "models.AgentTypeUsageStats": {
+        "models.FlowResultLimits": {
             "type": "object",
-            "required": [
-                "agent_type",
-                "stats"
-            ],
             "properties": {
-                "agent_type": {
-                    "type": "string"
+                "max_size": {
+                    "type": "integer",
+                    "maximum": 4194304,
+                    "minimum": 4096,
+                    "example": 81920
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.UsageStats"
+                "max_source_size": {
+                    "type": "integer",
+                    "maximum": 4194304,
+                    "minimum": 1024,
+                    "example": 51200
                 }
             }
         },
-        "models.Agentlog": {
+        "models.FlowSecrets": {
             "type": "object",
-            "required": [
-                "executor",
-                "flow_id",
-                "initiator",
-                "task"
-            ],
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "executor": {
-                    "type": "string"
-                },
-                "flow_id": {
+                "secrets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "API_TOKEN": "secret"
+                    }
+                }
+            }
+        },
+        "models.FlowStats": {
+            "type": "object",
+            "properties": {
+                "total_assistants_count": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "id": {
+                "total_subtasks_count": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "initiator": {
-                    "type": "string"
-                },
-                "result": {
-                    "type": "string"
-                },
-                "subtask_id": {
+                "total_tasks_count": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.FlowStatusInfo": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "active_container_count": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "task": {
+                "status": {
                     "type": "string"
                 },
-                "task_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.Assistant": {
+        "models.FlowTasksSubtasks": {
             "type": "object",
             "required": [
-                "flow_id",
                 "language",
                 "model",
                 "model_provider_name",
                 "model_provider_type",
                 "status",
+                "tasks",
                 "title",
                 "tool_call_id_template",
-                "trace_id"
+                "trace_id",
+                "user_id"
             ],
             "properties": {
+                "awaiting_input": {
+                    "type": "boolean"
+                },
+                "budget_max_cost": {
+                    "type": "number"
+                },
+                "budget_max_tokens": {
+                    "type": "integer"
+                },
+                "command_policy": {
+                    "$ref": "#/definitions/tools.CommandPolicy"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "deleted_at": {
                     "type": "string"
                 },
-                "flow_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "fallback_providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finish_reason": {
+                    "type": "string",
+                    "enum": [
+                        "user_stop",
+                        "user_finish",
+                        "completed",
+                        "budget_exceeded",
+                        "error",
+                        "provider_exhausted",
+                        "plan_limit_exceeded"
+                    ]
                 },
                 "functions": {
                     "$ref": "#/definitions/tools.Functions"
@@ -5848,6 +10812,16 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 70
                 },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "last_error_count": {
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "model": {
                     "type": "string",
                     "maxLength": 70
@@ -5859,13 +10833,60 @@ const docTemplate = `{
                 "model_provider_type": {
                     "type": "string"
                 },
-                "msgchain_id": {
+                "pending_question": {
+                    "type": "string"
+                },
+                "plan_max_depth": {
+                    "type": "integer"
+                },
+                "plan_max_subtasks": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 50
+                },
+                "provider_timeout": {
                     "type": "integer",
                     "minimum": 0
                 },
+                "response_cache": {
+                    "type": "string",
+                    "enum": [
+                        "disabled",
+                        "deterministic",
+                        "forced"
+                    ]
+                },
+                "result_max_size": {
+                    "type": "integer"
+                },
+                "result_max_source_size": {
+                    "type": "integer"
+                },
+                "scope": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "status": {
                     "type": "string"
                 },
+                "stop_reason": {
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskSubtasks"
+                    }
+                },
                 "title": {
                     "type": "string"
                 },
@@ -5873,6 +10894,12 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 70
                 },
+                "tool_calls_max": {
+                    "type": "integer"
+                },
+                "tool_calls_per_minute": {
+                    "type": "integer"
+                },
                 "trace_id": {
                     "type": "string",
                     "maxLength": 70
@@ -5880,772 +10907,1054 @@ const docTemplate = `{
                 "updated_at": {
                     "type": "string"
                 },
-                "use_agents": {
-                    "type": "boolean"
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "models.AssistantFlow": {
+        "models.FlowTemplate": {
             "type": "object",
             "required": [
-                "flow_id",
-                "language",
-                "model",
-                "model_provider_name",
-                "model_provider_type",
-                "status",
-                "title",
-                "tool_call_id_template",
-                "trace_id"
+                "name",
+                "user_id"
             ],
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "deleted_at": {
-                    "type": "string"
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "flow": {
-                    "$ref": "#/definitions/models.Flow"
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
                 },
-                "flow_id": {
+                "params": {
+                    "$ref": "#/definitions/models.FlowTemplateParams"
+                },
+                "shared": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer",
                     "minimum": 0
+                }
+            }
+        },
+        "models.FlowTemplateParams": {
+            "type": "object",
+            "required": [
+                "fallback_providers",
+                "provider"
+            ],
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/models.FlowBudget"
+                },
+                "command_policy": {
+                    "$ref": "#/definitions/tools.CommandPolicy"
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "TARGET_SCOPE": "10.0.0.0/24"
+                    }
+                },
+                "fallback_providers": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "anthropic",
+                        "gemini"
+                    ]
                 },
                 "functions": {
                     "$ref": "#/definitions/tools.Functions"
                 },
-                "id": {
+                "functions_compat_mode": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "input": {
+                    "type": "string",
+                    "example": "user input for first task in the flow"
+                },
+                "input_template_id": {
                     "type": "integer",
-                    "minimum": 0
+                    "minimum": 1,
+                    "example": 1
                 },
                 "language": {
                     "type": "string",
-                    "maxLength": 70
+                    "example": "English"
                 },
-                "model": {
-                    "type": "string",
-                    "maxLength": 70
+                "plan_limits": {
+                    "$ref": "#/definitions/models.FlowPlanLimits"
                 },
-                "model_provider_name": {
+                "provider": {
                     "type": "string",
-                    "maxLength": 70
-                },
-                "model_provider_type": {
-                    "type": "string"
+                    "example": "openai"
                 },
-                "msgchain_id": {
+                "provider_timeout": {
                     "type": "integer",
-                    "minimum": 0
+                    "maximum": 86400,
+                    "minimum": 0,
+                    "example": 300
                 },
-                "status": {
-                    "type": "string"
+                "queue": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "title": {
-                    "type": "string"
+                "resources": {
+                    "$ref": "#/definitions/models.ContainerResources"
                 },
-                "tool_call_id_template": {
+                "response_cache": {
                     "type": "string",
-                    "maxLength": 70
+                    "enum": [
+                        "disabled",
+                        "deterministic",
+                        "forced"
+                    ],
+                    "example": "deterministic"
                 },
-                "trace_id": {
+                "result_limits": {
+                    "$ref": "#/definitions/models.FlowResultLimits"
+                },
+                "scope": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.0/24",
+                        "*.example.com"
+                    ]
+                },
+                "secrets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "API_TOKEN": "secret"
+                    }
+                },
+                "snapshot_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "system_prompt": {
                     "type": "string",
-                    "maxLength": 70
+                    "maxLength": 65536,
+                    "minLength": 1,
+                    "example": "You are a web application security expert..."
                 },
-                "updated_at": {
-                    "type": "string"
+                "template_id": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
                 },
-                "use_agents": {
-                    "type": "boolean"
+                "tool_limits": {
+                    "$ref": "#/definitions/models.FlowToolLimits"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "domain": "example.com"
+                    }
                 }
             }
         },
-        "models.Assistantlog": {
+        "models.FlowToolLimits": {
             "type": "object",
-            "required": [
-                "assistant_id",
-                "flow_id",
-                "result_format",
-                "type"
-            ],
             "properties": {
-                "assistant_id": {
+                "calls_per_minute": {
                     "type": "integer",
-                    "minimum": 0
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "flow_id": {
-                    "type": "integer",
-                    "minimum": 0
+                    "example": 20
                 },
-                "id": {
+                "max_calls": {
                     "type": "integer",
-                    "minimum": 0
-                },
-                "message": {
-                    "type": "string"
-                },
-                "result": {
-                    "type": "string"
-                },
-                "result_format": {
-                    "type": "string"
-                },
-                "thinking": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
+                    "example": 1000
                 }
             }
         },
-        "models.AuthCallback": {
+        "models.FlowUsageResponse": {
             "type": "object",
             "required": [
-                "code",
-                "id_token",
-                "scope",
-                "state"
+                "flow_stats_by_flow",
+                "toolcalls_stats_by_flow",
+                "usage_stats_by_flow"
             ],
             "properties": {
-                "code": {
-                    "type": "string"
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "id_token": {
-                    "type": "string"
+                "flow_stats_by_flow": {
+                    "$ref": "#/definitions/models.FlowStats"
+                },
+                "toolcalls_stats_by_flow": {
+                    "$ref": "#/definitions/models.ToolcallsStats"
+                },
+                "toolcalls_stats_by_function_for_flow": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FunctionToolcallsStats"
+                    }
                 },
-                "scope": {
-                    "type": "string"
+                "usage_stats_by_agent_type_for_flow": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AgentTypeUsageStats"
+                    }
                 },
-                "state": {
-                    "type": "string"
+                "usage_stats_by_flow": {
+                    "$ref": "#/definitions/models.UsageStats"
                 }
             }
         },
-        "models.Container": {
+        "models.FlowsStats": {
             "type": "object",
-            "required": [
-                "flow_id",
-                "image",
-                "local_dir",
-                "local_id",
-                "name",
-                "status",
-                "type"
-            ],
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "flow_id": {
+                "total_assistants_count": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "id": {
+                "total_flows_count": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "image": {
-                    "type": "string"
-                },
-                "local_dir": {
-                    "type": "string"
-                },
-                "local_id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
+                "total_subtasks_count": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "updated_at": {
-                    "type": "string"
+                "total_tasks_count": {
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "models.CreateAPITokenRequest": {
+        "models.FlowsStatusRequest": {
             "type": "object",
             "required": [
-                "ttl"
+                "ids"
             ],
             "properties": {
-                "name": {
-                    "type": "string",
-                    "maxLength": 100
-                },
-                "ttl": {
-                    "description": "from 1 minute to 3 years",
-                    "type": "integer",
-                    "maximum": 94608000,
-                    "minimum": 60
+                "ids": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
                 }
             }
         },
-        "models.CreateAssistant": {
+        "models.FunctionToolcallsStats": {
             "type": "object",
             "required": [
-                "input",
-                "provider"
+                "function_name"
             ],
             "properties": {
-                "functions": {
-                    "$ref": "#/definitions/tools.Functions"
+                "avg_duration_seconds": {
+                    "type": "number",
+                    "minimum": 0
                 },
-                "input": {
-                    "type": "string",
-                    "example": "user input for running assistant"
+                "function_name": {
+                    "type": "string"
                 },
-                "provider": {
-                    "type": "string",
-                    "example": "openai"
+                "is_agent": {
+                    "type": "boolean"
                 },
-                "use_agents": {
-                    "type": "boolean",
-                    "example": true
+                "total_count": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "total_duration_seconds": {
+                    "type": "number",
+                    "minimum": 0
                 }
             }
         },
-        "models.CreateFlow": {
+        "models.GrantFlowAccess": {
             "type": "object",
             "required": [
-                "input",
-                "provider"
+                "access"
             ],
             "properties": {
-                "functions": {
-                    "$ref": "#/definitions/tools.Functions"
-                },
-                "input": {
-                    "type": "string",
-                    "example": "user input for first task in the flow"
-                },
-                "provider": {
+                "access": {
                     "type": "string",
-                    "example": "openai"
+                    "default": "view",
+                    "enum": [
+                        "view",
+                        "edit"
+                    ]
                 }
             }
         },
-        "models.DailyFlowsStats": {
+        "models.HealthCheck": {
             "type": "object",
             "required": [
-                "date",
-                "stats"
+                "name",
+                "status"
             ],
             "properties": {
-                "date": {
-                    "type": "string"
+                "cached": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.FlowsStats"
+                "error": {
+                    "type": "string",
+                    "example": "check timed out"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error"
+                    ],
+                    "example": "ok"
                 }
             }
         },
-        "models.DailyToolcallsStats": {
+        "models.InputTemplate": {
             "type": "object",
             "required": [
-                "date",
-                "stats"
+                "name",
+                "template",
+                "user_id"
             ],
             "properties": {
-                "date": {
+                "created_at": {
                     "type": "string"
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.ToolcallsStats"
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "shared": {
+                    "type": "boolean"
+                },
+                "template": {
+                    "type": "string",
+                    "maxLength": 65536
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "variables": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.DailyUsageStats": {
+        "models.Login": {
             "type": "object",
             "required": [
-                "date",
-                "stats"
+                "mail",
+                "password"
             ],
             "properties": {
-                "date": {
-                    "type": "string"
+                "mail": {
+                    "type": "string",
+                    "maxLength": 50
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.UsageStats"
+                "password": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 4
                 }
             }
         },
-        "models.Flow": {
+        "models.ModelPrice": {
             "type": "object",
             "required": [
-                "language",
+                "effective_from",
                 "model",
-                "model_provider_name",
-                "model_provider_type",
-                "status",
-                "title",
-                "tool_call_id_template",
-                "trace_id",
-                "user_id"
+                "provider"
             ],
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "deleted_at": {
+                "effective_from": {
                     "type": "string"
                 },
-                "functions": {
-                    "$ref": "#/definitions/tools.Functions"
-                },
                 "id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "language": {
-                    "type": "string",
-                    "maxLength": 70
+                "input_price_per_1k": {
+                    "type": "number",
+                    "minimum": 0
                 },
                 "model": {
                     "type": "string",
-                    "maxLength": 70
+                    "maxLength": 200
                 },
-                "model_provider_name": {
-                    "type": "string",
-                    "maxLength": 70
+                "output_price_per_1k": {
+                    "type": "number",
+                    "minimum": 0
                 },
-                "model_provider_type": {
+                "provider": {
                     "type": "string"
                 },
-                "status": {
+                "updated_at": {
                     "type": "string"
-                },
-                "title": {
+                }
+            }
+        },
+        "models.ModelUsageStats": {
+            "type": "object",
+            "required": [
+                "model",
+                "provider",
+                "stats"
+            ],
+            "properties": {
+                "model": {
                     "type": "string"
                 },
-                "tool_call_id_template": {
-                    "type": "string",
-                    "maxLength": 70
-                },
-                "trace_id": {
-                    "type": "string",
-                    "maxLength": 70
-                },
-                "updated_at": {
+                "provider": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "stats": {
+                    "$ref": "#/definitions/models.UsageStats"
                 }
             }
         },
-        "models.FlowExecutionStats": {
+        "models.Msglog": {
             "type": "object",
             "required": [
-                "flow_title"
+                "flow_id",
+                "message",
+                "result_format",
+                "type"
             ],
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
                 "flow_id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "flow_title": {
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "message": {
                     "type": "string"
                 },
-                "tasks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.TaskExecutionStats"
-                    }
+                "result": {
+                    "type": "string"
                 },
-                "total_assistants_count": {
-                    "type": "integer",
-                    "minimum": 0
+                "result_format": {
+                    "type": "string"
                 },
-                "total_duration_seconds": {
-                    "type": "number",
+                "subtask_id": {
+                    "type": "integer",
                     "minimum": 0
                 },
-                "total_toolcalls_count": {
+                "task_id": {
                     "type": "integer",
                     "minimum": 0
+                },
+                "thinking": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "models.FlowStats": {
+        "models.Password": {
             "type": "object",
+            "required": [
+                "current_password",
+                "password"
+            ],
             "properties": {
-                "total_assistants_count": {
-                    "type": "integer",
-                    "minimum": 0
+                "confirm_password": {
+                    "type": "string"
                 },
-                "total_subtasks_count": {
-                    "type": "integer",
-                    "minimum": 0
+                "current_password": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 5
                 },
-                "total_tasks_count": {
-                    "type": "integer",
-                    "minimum": 0
+                "password": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
-        "models.FlowTasksSubtasks": {
+        "models.PatchAssistant": {
             "type": "object",
             "required": [
-                "language",
-                "model",
-                "model_provider_name",
-                "model_provider_type",
-                "status",
-                "tasks",
-                "title",
-                "tool_call_id_template",
-                "trace_id",
-                "user_id"
+                "action"
             ],
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "deleted_at": {
-                    "type": "string"
-                },
-                "functions": {
-                    "$ref": "#/definitions/tools.Functions"
-                },
-                "id": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "language": {
+                "action": {
                     "type": "string",
-                    "maxLength": 70
+                    "default": "stop",
+                    "enum": [
+                        "stop",
+                        "input"
+                    ]
                 },
-                "model": {
+                "input": {
                     "type": "string",
-                    "maxLength": 70
+                    "example": "user input for waiting assistant"
                 },
-                "model_provider_name": {
+                "use_agents": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.PatchFlow": {
+            "type": "object",
+            "required": [
+                "action",
+                "title"
+            ],
+            "properties": {
+                "action": {
                     "type": "string",
-                    "maxLength": 70
-                },
-                "model_provider_type": {
-                    "type": "string"
+                    "default": "stop",
+                    "enum": [
+                        "stop",
+                        "finish",
+                        "input",
+                        "rename",
+                        "metadata",
+                        "budget"
+                    ]
                 },
-                "status": {
-                    "type": "string"
+                "budget": {
+                    "$ref": "#/definitions/models.FlowBudget"
                 },
-                "tasks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.TaskSubtasks"
-                    }
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "free text flow description"
                 },
-                "title": {
-                    "type": "string"
+                "input": {
+                    "type": "string",
+                    "example": "user input for waiting flow"
                 },
-                "tool_call_id_template": {
+                "language": {
                     "type": "string",
-                    "maxLength": 70
+                    "example": "English"
                 },
-                "trace_id": {
+                "name": {
                     "type": "string",
-                    "maxLength": 70
+                    "example": "new flow name"
                 },
-                "updated_at": {
+                "title": {
+                    "type": "string",
+                    "example": "new flow title"
+                }
+            }
+        },
+        "models.PatchPrompt": {
+            "type": "object",
+            "required": [
+                "prompt"
+            ],
+            "properties": {
+                "prompt": {
                     "type": "string"
-                },
-                "user_id": {
-                    "type": "integer",
-                    "minimum": 0
                 }
             }
         },
-        "models.FlowUsageResponse": {
+        "models.PeriodUsageResponse": {
             "type": "object",
             "required": [
-                "flow_stats_by_flow",
-                "toolcalls_stats_by_flow",
-                "usage_stats_by_flow"
+                "period"
             ],
             "properties": {
-                "flow_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "flows_execution_stats_by_period": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowExecutionStats"
+                    }
                 },
-                "flow_stats_by_flow": {
-                    "$ref": "#/definitions/models.FlowStats"
+                "flows_stats_by_period": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyFlowsStats"
+                    }
                 },
-                "toolcalls_stats_by_flow": {
-                    "$ref": "#/definitions/models.ToolcallsStats"
+                "period": {
+                    "type": "string"
                 },
-                "toolcalls_stats_by_function_for_flow": {
+                "toolcalls_stats_by_period": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.FunctionToolcallsStats"
+                        "$ref": "#/definitions/models.DailyToolcallsStats"
                     }
                 },
-                "usage_stats_by_agent_type_for_flow": {
+                "usage_stats_by_period": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.AgentTypeUsageStats"
+                        "$ref": "#/definitions/models.DailyUsageStats"
                     }
-                },
-                "usage_stats_by_flow": {
-                    "$ref": "#/definitions/models.UsageStats"
                 }
             }
         },
-        "models.FlowsStats": {
+        "models.PrioritizeSubtask": {
             "type": "object",
             "properties": {
-                "total_assistants_count": {
+                "priority": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": -1000,
+                    "example": 10
+                }
+            }
+        },
+        "models.Privilege": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "total_flows_count": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "role_id": {
                     "type": "integer",
                     "minimum": 0
+                }
+            }
+        },
+        "models.Prompt": {
+            "type": "object",
+            "required": [
+                "prompt",
+                "type"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "total_subtasks_count": {
+                "id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "total_tasks_count": {
+                "prompt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer",
                     "minimum": 0
                 }
             }
         },
-        "models.FunctionToolcallsStats": {
+        "models.ProviderHealth": {
             "type": "object",
             "required": [
-                "function_name"
+                "checked_at",
+                "name",
+                "status",
+                "type"
             ],
             "properties": {
-                "avg_duration_seconds": {
-                    "type": "number",
-                    "minimum": 0
+                "cached": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "function_name": {
+                "checked_at": {
                     "type": "string"
                 },
-                "is_agent": {
-                    "type": "boolean"
+                "error": {
+                    "type": "string",
+                    "example": "401 unauthorized"
                 },
-                "total_count": {
+                "latency_ms": {
                     "type": "integer",
-                    "minimum": 0
+                    "minimum": 0,
+                    "example": 420
+                },
+                "name": {
+                    "type": "string",
+                    "example": "my openai provider"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error"
+                    ],
+                    "example": "ok"
                 },
-                "total_duration_seconds": {
-                    "type": "number",
-                    "minimum": 0
+                "type": {
+                    "type": "string",
+                    "example": "openai"
                 }
             }
         },
-        "models.Login": {
+        "models.ProviderInfo": {
             "type": "object",
             "required": [
-                "mail",
-                "password"
+                "name",
+                "type"
             ],
             "properties": {
-                "mail": {
+                "name": {
                     "type": "string",
-                    "maxLength": 50
+                    "example": "my openai provider"
                 },
-                "password": {
+                "type": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 4
+                    "example": "openai"
                 }
             }
         },
-        "models.ModelUsageStats": {
+        "models.ProviderModel": {
             "type": "object",
             "required": [
-                "model",
-                "provider",
-                "stats"
+                "name"
             ],
             "properties": {
-                "model": {
-                    "type": "string"
+                "context_window": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1047576
                 },
-                "provider": {
+                "description": {
+                    "type": "string",
+                    "example": "flagship model"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "gpt-4.1"
+                },
+                "release_date": {
                     "type": "string"
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.UsageStats"
+                "thinking": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "tools": {
+                    "type": "boolean",
+                    "example": true
                 }
             }
         },
-        "models.Msglog": {
+        "models.ProviderRateLimit": {
             "type": "object",
             "required": [
-                "flow_id",
-                "message",
-                "result_format",
-                "type"
+                "model",
+                "name",
+                "updated_at"
             ],
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "model": {
+                    "type": "string",
+                    "example": "gpt-4.1"
                 },
-                "flow_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "name": {
+                    "type": "string",
+                    "example": "my openai provider"
                 },
-                "id": {
+                "rejected": {
                     "type": "integer",
-                    "minimum": 0
-                },
-                "message": {
-                    "type": "string"
-                },
-                "result": {
-                    "type": "string"
+                    "minimum": 0,
+                    "example": 0
                 },
-                "result_format": {
-                    "type": "string"
+                "requests_available": {
+                    "type": "number",
+                    "example": 42.5
                 },
-                "subtask_id": {
+                "requests_limit": {
                     "type": "integer",
-                    "minimum": 0
+                    "minimum": 0,
+                    "example": 60
                 },
-                "task_id": {
+                "tokens_available": {
+                    "type": "number",
+                    "example": 81234
+                },
+                "tokens_limit": {
                     "type": "integer",
-                    "minimum": 0
+                    "minimum": 0,
+                    "example": 100000
                 },
-                "thinking": {
+                "updated_at": {
                     "type": "string"
                 },
-                "type": {
-                    "type": "string"
+                "waiting": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
                 }
             }
         },
-        "models.Password": {
+        "models.ProviderUsageStats": {
             "type": "object",
             "required": [
-                "current_password",
-                "password"
+                "provider",
+                "stats"
             ],
             "properties": {
-                "confirm_password": {
+                "provider": {
                     "type": "string"
                 },
-                "current_password": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 5
-                },
-                "password": {
-                    "type": "string",
-                    "maxLength": 100
+                "stats": {
+                    "$ref": "#/definitions/models.UsageStats"
                 }
             }
         },
-        "models.PatchAssistant": {
+        "models.Readiness": {
             "type": "object",
             "required": [
-                "action"
+                "checked_at",
+                "checks",
+                "status"
             ],
             "properties": {
-                "action": {
-                    "type": "string",
-                    "default": "stop",
-                    "enum": [
-                        "stop",
-                        "input"
-                    ]
+                "checked_at": {
+                    "type": "string"
                 },
-                "input": {
-                    "type": "string",
-                    "example": "user input for waiting assistant"
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HealthCheck"
+                    }
                 },
-                "use_agents": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error"
+                    ],
+                    "example": "ok"
+                }
+            }
+        },
+        "models.RestoreFlow": {
+            "type": "object",
+            "properties": {
+                "reattach": {
                     "type": "boolean",
-                    "example": true
+                    "default": false
                 }
             }
         },
-        "models.PatchFlow": {
+        "models.RestoredFlow": {
             "type": "object",
             "required": [
-                "action"
+                "language",
+                "model",
+                "model_provider_name",
+                "model_provider_type",
+                "status",
+                "title",
+                "tool_call_id_template",
+                "trace_id",
+                "user_id"
             ],
             "properties": {
-                "action": {
+                "awaiting_input": {
+                    "type": "boolean"
+                },
+                "budget_max_cost": {
+                    "type": "number"
+                },
+                "budget_max_tokens": {
+                    "type": "integer"
+                },
+                "command_policy": {
+                    "$ref": "#/definitions/tools.CommandPolicy"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "fallback_providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finish_reason": {
                     "type": "string",
-                    "default": "stop",
                     "enum": [
-                        "stop",
-                        "finish",
-                        "input",
-                        "rename"
+                        "user_stop",
+                        "user_finish",
+                        "completed",
+                        "budget_exceeded",
+                        "error",
+                        "provider_exhausted",
+                        "plan_limit_exceeded"
                     ]
                 },
-                "input": {
+                "functions": {
+                    "$ref": "#/definitions/tools.Functions"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "language": {
                     "type": "string",
-                    "example": "user input for waiting flow"
+                    "maxLength": 70
                 },
-                "name": {
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "last_error_count": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "model": {
                     "type": "string",
-                    "example": "new flow name"
-                }
-            }
-        },
-        "models.PatchPrompt": {
-            "type": "object",
-            "required": [
-                "prompt"
-            ],
-            "properties": {
-                "prompt": {
+                    "maxLength": 70
+                },
+                "model_provider_name": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "model_provider_type": {
                     "type": "string"
-                }
-            }
-        },
-        "models.PeriodUsageResponse": {
-            "type": "object",
-            "required": [
-                "period"
-            ],
-            "properties": {
-                "flows_execution_stats_by_period": {
+                },
+                "pending_question": {
+                    "type": "string"
+                },
+                "plan_max_depth": {
+                    "type": "integer"
+                },
+                "plan_max_subtasks": {
+                    "type": "integer"
+                },
+                "provider_timeout": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "purged": {
+                    "$ref": "#/definitions/models.FlowPurged"
+                },
+                "response_cache": {
+                    "type": "string",
+                    "enum": [
+                        "disabled",
+                        "deterministic",
+                        "forced"
+                    ]
+                },
+                "result_max_size": {
+                    "type": "integer"
+                },
+                "result_max_source_size": {
+                    "type": "integer"
+                },
+                "scope": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.FlowExecutionStats"
+                        "type": "string"
                     }
                 },
-                "flows_stats_by_period": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.DailyFlowsStats"
-                    }
+                "status": {
+                    "type": "string"
+                },
+                "stop_reason": {
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "tool_call_id_template": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "tool_calls_max": {
+                    "type": "integer"
+                },
+                "tool_calls_per_minute": {
+                    "type": "integer"
+                },
+                "trace_id": {
+                    "type": "string",
+                    "maxLength": 70
                 },
-                "period": {
+                "updated_at": {
                     "type": "string"
                 },
-                "toolcalls_stats_by_period": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.DailyToolcallsStats"
-                    }
-                },
-                "usage_stats_by_period": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.DailyUsageStats"
-                    }
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
                 }
             }
         },
-        "models.Privilege": {
+        "models.Role": {
             "type": "object",
             "required": [
                 "name"
@@ -6657,111 +11966,106 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string",
-                    "maxLength": 70
-                },
-                "role_id": {
-                    "type": "integer",
-                    "minimum": 0
+                    "maxLength": 50
                 }
             }
         },
-        "models.Prompt": {
+        "models.RolePrivileges": {
             "type": "object",
             "required": [
-                "prompt",
-                "type"
+                "name",
+                "privileges"
             ],
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "prompt": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
                 },
-                "user_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "privileges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Privilege"
+                    }
                 }
             }
         },
-        "models.ProviderInfo": {
+        "models.SaveFlowTemplate": {
             "type": "object",
             "required": [
                 "name",
-                "type"
+                "params"
             ],
             "properties": {
                 "name": {
                     "type": "string",
-                    "example": "my openai provider"
+                    "maxLength": 100,
+                    "example": "external web perimeter"
                 },
-                "type": {
-                    "type": "string",
-                    "example": "openai"
-                }
-            }
-        },
-        "models.ProviderUsageStats": {
-            "type": "object",
-            "required": [
-                "provider",
-                "stats"
-            ],
-            "properties": {
-                "provider": {
-                    "type": "string"
+                "params": {
+                    "$ref": "#/definitions/models.CreateFlow"
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.UsageStats"
+                "shared": {
+                    "type": "boolean",
+                    "default": false
                 }
             }
         },
-        "models.Role": {
+        "models.SaveInputTemplate": {
             "type": "object",
             "required": [
-                "name"
+                "name",
+                "template"
             ],
             "properties": {
-                "id": {
-                    "type": "integer",
-                    "minimum": 0
-                },
                 "name": {
                     "type": "string",
-                    "maxLength": 50
+                    "maxLength": 100,
+                    "example": "web recon"
+                },
+                "shared": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "template": {
+                    "type": "string",
+                    "maxLength": 65536,
+                    "example": "Enumerate subdomains of {{domain}} and check them for {{vuln_class}}"
                 }
             }
         },
-        "models.RolePrivileges": {
+        "models.SaveModelPrice": {
             "type": "object",
             "required": [
-                "name",
-                "privileges"
+                "model",
+                "provider"
             ],
             "properties": {
-                "id": {
-                    "type": "integer",
-                    "minimum": 0
+                "effective_from": {
+                    "type": "string",
+                    "example": "2026-01-01T00:00:00Z"
                 },
-                "name": {
+                "input_price_per_1k": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.002
+                },
+                "model": {
                     "type": "string",
-                    "maxLength": 50
+                    "maxLength": 200,
+                    "example": "gpt-4.1"
                 },
-                "privileges": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Privilege"
-                    }
+                "output_price_per_1k": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.008
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "openai"
                 }
             }
         },
@@ -6800,6 +12104,33 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SearchCacheStats": {
+            "type": "object",
+            "required": [
+                "tool"
+            ],
+            "properties": {
+                "hits": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 12
+                },
+                "misses": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 30
+                },
+                "tool": {
+                    "type": "string",
+                    "example": "tavily"
+                },
+                "ttl_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3600
+                }
+            }
+        },
         "models.Searchlog": {
             "type": "object",
             "required": [
@@ -6868,6 +12199,9 @@ const docTemplate = `{
                     "type": "integer",
                     "minimum": 0
                 },
+                "priority": {
+                    "type": "integer"
+                },
                 "result": {
                     "type": "string"
                 },
@@ -7018,19 +12352,163 @@ const docTemplate = `{
                 }
             }
         },
-        "models.TaskSubtasks": {
+        "models.TaskSearchMatch": {
+            "type": "object",
+            "required": [
+                "status",
+                "type"
+            ],
+            "properties": {
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subtask_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "task",
+                        "subtask"
+                    ],
+                    "example": "subtask"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TaskSubtasks": {
+            "type": "object",
+            "required": [
+                "flow_id",
+                "input",
+                "status",
+                "subtasks",
+                "title"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "input": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 50
+                },
+                "result": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subtasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Subtask"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Termlog": {
+            "type": "object",
+            "required": [
+                "container_id",
+                "flow_id",
+                "text",
+                "type"
+            ],
+            "properties": {
+                "container_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "subtask_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Toolcall": {
             "type": "object",
             "required": [
+                "call_id",
                 "flow_id",
-                "input",
-                "status",
-                "subtasks",
-                "title"
+                "name",
+                "status"
             ],
             "properties": {
+                "args": {
+                    "$ref": "#/definitions/models.ToolcallArgs"
+                },
+                "call_id": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "duration_seconds": {
+                    "type": "number",
+                    "minimum": 0
+                },
                 "flow_id": {
                     "type": "integer",
                     "minimum": 0
@@ -7039,65 +12517,61 @@ const docTemplate = `{
                     "type": "integer",
                     "minimum": 0
                 },
-                "input": {
-                    "type": "string"
-                },
-                "result": {
+                "name": {
                     "type": "string"
                 },
                 "status": {
                     "type": "string"
                 },
-                "subtasks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Subtask"
-                    }
+                "subtask_id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
-                "title": {
-                    "type": "string"
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.Termlog": {
+        "models.ToolcallArgs": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "models.ToolcallGroup": {
             "type": "object",
             "required": [
-                "container_id",
-                "flow_id",
-                "text",
-                "type"
+                "name"
             ],
             "properties": {
-                "container_id": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "created_at": {
-                    "type": "string"
+                "args": {
+                    "$ref": "#/definitions/models.ToolcallArgs"
                 },
-                "flow_id": {
+                "count": {
                     "type": "integer",
-                    "minimum": 0
+                    "minimum": 1
                 },
-                "id": {
-                    "type": "integer",
+                "duration_seconds": {
+                    "type": "number",
                     "minimum": 0
                 },
-                "subtask_id": {
+                "failed": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "task_id": {
+                "finished": {
                     "type": "integer",
                     "minimum": 0
                 },
-                "text": {
+                "first_called_at": {
                     "type": "string"
                 },
-                "type": {
+                "last_called_at": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string"
                 }
             }
@@ -7251,6 +12725,44 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UserPermissions": {
+            "type": "object",
+            "required": [
+                "capabilities",
+                "privileges",
+                "role_name",
+                "type"
+            ],
+            "properties": {
+                "capabilities": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "privileges": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "role_name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
         "models.UserRole": {
             "type": "object",
             "required": [
@@ -7314,86 +12826,281 @@ const docTemplate = `{
                 "hash": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "mail": {
-                    "type": "string",
-                    "maxLength": 50
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 70
-                },
-                "password_change_required": {
-                    "type": "boolean"
-                },
-                "provider": {
-                    "type": "string"
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "mail": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 70
+                },
+                "password_change_required": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.RolePrivileges"
+                },
+                "role_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Vecstorelog": {
+            "type": "object",
+            "required": [
+                "action",
+                "executor",
+                "filter",
+                "flow_id",
+                "initiator",
+                "query"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "executor": {
+                    "type": "string"
+                },
+                "filter": {
+                    "type": "string"
+                },
+                "flow_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "initiator": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "subtask_id": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "task_id": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "report.SARIFArtifactLocation": {
+            "type": "object",
+            "properties": {
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.SARIFAutomationDetails": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.SARIFDriver": {
+            "type": "object",
+            "properties": {
+                "informationUri": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.SARIFRule"
+                    }
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.SARIFLocation": {
+            "type": "object",
+            "properties": {
+                "logicalLocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.SARIFLogicalLocation"
+                    }
+                },
+                "physicalLocation": {
+                    "$ref": "#/definitions/report.SARIFPhysicalLocation"
+                }
+            }
+        },
+        "report.SARIFLog": {
+            "type": "object",
+            "properties": {
+                "$schema": {
+                    "type": "string"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.SARIFRun"
+                    }
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.SARIFLogicalLocation": {
+            "type": "object",
+            "properties": {
+                "fullyQualifiedName": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.SARIFMessage": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.SARIFPhysicalLocation": {
+            "type": "object",
+            "properties": {
+                "artifactLocation": {
+                    "$ref": "#/definitions/report.SARIFArtifactLocation"
+                }
+            }
+        },
+        "report.SARIFPropertyBagWithTag": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "report.SARIFResult": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string"
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.SARIFLocation"
+                    }
                 },
-                "role": {
-                    "$ref": "#/definitions/models.RolePrivileges"
+                "message": {
+                    "$ref": "#/definitions/report.SARIFMessage"
                 },
-                "role_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {}
                 },
-                "status": {
+                "ruleId": {
                     "type": "string"
                 },
-                "type": {
-                    "type": "string"
+                "ruleIndex": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Vecstorelog": {
+        "report.SARIFRule": {
             "type": "object",
-            "required": [
-                "action",
-                "executor",
-                "filter",
-                "flow_id",
-                "initiator",
-                "query"
-            ],
             "properties": {
-                "action": {
-                    "type": "string"
+                "defaultConfiguration": {
+                    "$ref": "#/definitions/report.SARIFRuleConfiguration"
                 },
-                "created_at": {
+                "helpUri": {
                     "type": "string"
                 },
-                "executor": {
+                "id": {
                     "type": "string"
                 },
-                "filter": {
+                "name": {
                     "type": "string"
                 },
-                "flow_id": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "id": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "initiator": {
-                    "type": "string"
+                "properties": {
+                    "$ref": "#/definitions/report.SARIFPropertyBagWithTag"
                 },
-                "query": {
+                "shortDescription": {
+                    "$ref": "#/definitions/report.SARIFMessage"
+                }
+            }
+        },
+        "report.SARIFRuleConfiguration": {
+            "type": "object",
+            "properties": {
+                "level": {
                     "type": "string"
+                }
+            }
+        },
+        "report.SARIFRun": {
+            "type": "object",
+            "properties": {
+                "automationDetails": {
+                    "$ref": "#/definitions/report.SARIFAutomationDetails"
                 },
-                "result": {
-                    "type": "string"
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {}
                 },
-                "subtask_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.SARIFResult"
+                    }
                 },
-                "task_id": {
-                    "type": "integer",
-                    "minimum": 0
+                "tool": {
+                    "$ref": "#/definitions/report.SARIFTool"
+                }
+            }
+        },
+        "report.SARIFTool": {
+            "type": "object",
+            "properties": {
+                "driver": {
+                    "$ref": "#/definitions/report.SARIFDriver"
                 }
             }
         },
@@ -7439,6 +13146,34 @@ const docTemplate = `{
                 }
             }
         },
+        "services.auditLogs": {
+            "type": "object",
+            "properties": {
+                "audit_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditLog"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.containerLogs": {
+            "type": "object",
+            "properties": {
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Termlog"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "services.containers": {
             "type": "object",
             "properties": {
@@ -7453,6 +13188,107 @@ const docTemplate = `{
                 }
             }
         },
+        "services.flowACLs": {
+            "type": "object",
+            "properties": {
+                "flow_acls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowACL"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.flowArtifacts": {
+            "type": "object",
+            "properties": {
+                "flow_artifacts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowArtifact"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.flowAttachments": {
+            "type": "object",
+            "properties": {
+                "flow_attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowAttachment"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.flowInputs": {
+            "type": "object",
+            "properties": {
+                "inputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowInput"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.flowMemories": {
+            "type": "object",
+            "properties": {
+                "flow_memories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowMemory"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.flowMessages": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowMessage"
+                    }
+                },
+                "system_prompt": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.flowTemplates": {
+            "type": "object",
+            "properties": {
+                "flow_templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FlowTemplate"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "services.flows": {
             "type": "object",
             "properties": {
@@ -7505,6 +13341,34 @@ const docTemplate = `{
                 }
             }
         },
+        "services.inputTemplates": {
+            "type": "object",
+            "properties": {
+                "input_templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InputTemplate"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.modelPrices": {
+            "type": "object",
+            "properties": {
+                "model_prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ModelPrice"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "services.msglogs": {
             "type": "object",
             "properties": {
@@ -7575,6 +13439,26 @@ const docTemplate = `{
                 }
             }
         },
+        "services.subtaskToolcalls": {
+            "type": "object",
+            "properties": {
+                "grouped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ToolcallGroup"
+                    }
+                },
+                "toolcalls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Toolcall"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "services.subtasks": {
             "type": "object",
             "properties": {
@@ -7603,6 +13487,20 @@ const docTemplate = `{
                 }
             }
         },
+        "services.tasksSearch": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskSearchMatch"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "services.termlogs": {
             "type": "object",
             "properties": {
@@ -7659,6 +13557,23 @@ const docTemplate = `{
                 }
             }
         },
+        "tools.CommandPolicy": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "tools.DisableFunction": {
             "type": "object",
             "required": [
@@ -7693,7 +13608,8 @@ const docTemplate = `{
                     }
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 64
                 },
                 "schema": {
                     "type": "object"
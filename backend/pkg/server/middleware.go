@@ -1,6 +1,9 @@
 package router
 
 import (
+	"fmt"
+	"net/http"
+
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/response"
 
@@ -34,3 +37,32 @@ func noCacheMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// multipartFormOverhead is added to the route limits of file uploads to fit the form boundaries and headers
+const multipartFormOverhead = 1 << 20
+
+// bodySizeLimitMiddleware rejects requests with the declared body larger than the limit and
+// cuts the rest of bodies at the limit, so handlers get http.MaxBytesError on reading them,
+// routeLimits overrides the limit for the routes by their full path
+func bodySizeLimitMiddleware(defaultLimit int64, routeLimits map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultLimit
+		if routeLimit, ok := routeLimits[c.FullPath()]; ok {
+			limit = routeLimit
+		}
+
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			err := fmt.Errorf("request body size %d exceeds the limit of %d bytes", c.Request.ContentLength, limit)
+			response.Error(c, response.ErrPayloadTooLarge, err)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionFlowCreate   AuditAction = "flow.create"
+	AuditActionFlowStop     AuditAction = "flow.stop"
+	AuditActionFlowFinish   AuditAction = "flow.finish"
+	AuditActionFlowInput    AuditAction = "flow.input"
+	AuditActionFlowRename   AuditAction = "flow.rename"
+	AuditActionFlowMetadata AuditAction = "flow.metadata"
+	AuditActionFlowBudget   AuditAction = "flow.budget"
+	AuditActionFlowDelete   AuditAction = "flow.delete"
+	AuditActionFlowRestore  AuditAction = "flow.restore"
+	AuditActionFlowPurge    AuditAction = "flow.purge"
+	AuditActionFlowSecrets  AuditAction = "flow.secrets"
+	AuditActionFlowAttach   AuditAction = "flow.attach"
+
+	AuditActionFlowCommandDenied AuditAction = "flow.command_denied"
+	AuditActionFlowScopeDenied   AuditAction = "flow.scope_denied"
+)
+
+func (s AuditAction) String() string {
+	return string(s)
+}
+
+// Valid is function to control input/output data
+func (s AuditAction) Valid() error {
+	switch s {
+	case AuditActionFlowCreate, AuditActionFlowStop, AuditActionFlowFinish, AuditActionFlowInput,
+		AuditActionFlowRename, AuditActionFlowMetadata, AuditActionFlowBudget, AuditActionFlowDelete,
+		AuditActionFlowRestore, AuditActionFlowPurge, AuditActionFlowSecrets, AuditActionFlowAttach,
+		AuditActionFlowCommandDenied, AuditActionFlowScopeDenied:
+		return nil
+	default:
+		return fmt.Errorf("invalid AuditAction: %s", s)
+	}
+}
+
+// Validate is function to use callback to control input/output data
+func (s AuditAction) Validate(db *gorm.DB) {
+	if err := s.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// AuditChange is model to contain the field value before and after the mutation
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges is model to contain changed fields of the mutated entity stored as JSON
+type AuditChanges map[string]AuditChange
+
+// Value implements driver.Valuer interface for database write
+func (ac AuditChanges) Value() (driver.Value, error) {
+	if ac == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]AuditChange(ac))
+}
+
+// Scan implements sql.Scanner interface for database read
+func (ac *AuditChanges) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, ac)
+	case string:
+		return json.Unmarshal([]byte(v), ac)
+	default:
+		return fmt.Errorf("failed to scan AuditChanges: expected []byte, got %T", value)
+	}
+}
+
+// AuditMetadata is model to contain the request details of the mutation stored as JSON
+type AuditMetadata struct {
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	UserType  string `json:"user_type,omitempty"`
+	TokenID   string `json:"token_id,omitempty"`
+}
+
+// Value implements driver.Valuer interface for database write
+func (am AuditMetadata) Value() (driver.Value, error) {
+	return json.Marshal(am)
+}
+
+// Scan implements sql.Scanner interface for database read
+func (am *AuditMetadata) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, am)
+	case string:
+		return json.Unmarshal([]byte(v), am)
+	default:
+		return fmt.Errorf("failed to scan AuditMetadata: expected []byte, got %T", value)
+	}
+}
+
+// AuditLog is model to contain the immutable record of the mutation made by the user
+// nolint:lll
+type AuditLog struct {
+	ID        uint64        `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	UserID    uint64        `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	Action    AuditAction   `form:"action" json:"action" validate:"valid,required" gorm:"type:TEXT;NOT NULL"`
+	FlowID    uint64        `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	Changes   AuditChanges  `form:"changes" json:"changes" validate:"omitempty" gorm:"type:JSONB;NOT NULL;default:'{}'"`
+	Metadata  AuditMetadata `form:"metadata" json:"metadata" validate:"omitempty" gorm:"type:JSONB;NOT NULL;default:'{}'"`
+	CreatedAt time.Time     `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (al *AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// Valid is function to control input/output data
+func (al AuditLog) Valid() error {
+	return validate.Struct(al)
+}
+
+// Validate is function to use callback to control input/output data
+func (al AuditLog) Validate(db *gorm.DB) {
+	if err := al.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+// SearchCacheStats is model to contain hit and miss counters of the search results cache of the tool
+// nolint:lll
+type SearchCacheStats struct {
+	Tool       string `form:"tool" json:"tool" validate:"required" example:"tavily"`
+	TTLSeconds int64  `form:"ttl_seconds" json:"ttl_seconds" validate:"min=0" example:"3600"`
+	Hits       int64  `form:"hits" json:"hits" validate:"min=0" example:"12"`
+	Misses     int64  `form:"misses" json:"misses" validate:"min=0" example:"30"`
+}
+
+// Valid is function to control input/output data
+func (scs SearchCacheStats) Valid() error {
+	return validate.Struct(scs)
+}
@@ -42,6 +42,16 @@ func (s ContainerStatus) Validate(db *gorm.DB) {
 	}
 }
 
+// IsTerminal returns true if container can't produce new logs anymore
+func (s ContainerStatus) IsTerminal() bool {
+	switch s {
+	case ContainerStatusStopped, ContainerStatusDeleted, ContainerStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
 type ContainerType string
 
 const (
@@ -73,16 +83,19 @@ func (t ContainerType) Validate(db *gorm.DB) {
 // Container is model to contain container information
 // nolint:lll
 type Container struct {
-	ID        uint64          `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
-	Type      ContainerType   `form:"type" json:"type" validate:"valid,required" gorm:"type:CONTAINER_TYPE;NOT NULL;default:'primary'"`
-	Name      string          `form:"name" json:"name" validate:"required" gorm:"type:TEXT;NOT NULL;default:MD5(RANDOM()::text)"`
-	Image     string          `form:"image" json:"image" validate:"required" gorm:"type:TEXT;NOT NULL"`
-	Status    ContainerStatus `form:"status" json:"status" validate:"valid,required" gorm:"type:CONTAINER_STATUS;NOT NULL;default:'starting'"`
-	LocalID   string          `form:"local_id" json:"local_id" validate:"required" gorm:"type:TEXT;NOT NULL"`
-	LocalDir  string          `form:"local_dir" json:"local_dir" validate:"required" gorm:"type:TEXT;NOT NULL"`
-	FlowID    uint64          `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
-	CreatedAt time.Time       `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
-	UpdatedAt time.Time       `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	ID          uint64          `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Type        ContainerType   `form:"type" json:"type" validate:"valid,required" gorm:"type:CONTAINER_TYPE;NOT NULL;default:'primary'"`
+	Name        string          `form:"name" json:"name" validate:"required" gorm:"type:TEXT;NOT NULL;default:MD5(RANDOM()::text)"`
+	Image       string          `form:"image" json:"image" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Status      ContainerStatus `form:"status" json:"status" validate:"valid,required" gorm:"type:CONTAINER_STATUS;NOT NULL;default:'starting'"`
+	LocalID     string          `form:"local_id" json:"local_id" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	LocalDir    string          `form:"local_dir" json:"local_dir" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	FlowID      uint64          `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CPULimit    float64         `form:"cpu_limit" json:"cpu_limit" validate:"min=0" gorm:"column:cpu_limit;type:DOUBLE PRECISION;NOT NULL;default:0"`
+	MemoryLimit int64           `form:"memory_limit" json:"memory_limit" validate:"min=0" gorm:"type:BIGINT;NOT NULL;default:0"`
+	PidsLimit   int64           `form:"pids_limit" json:"pids_limit" validate:"min=0" gorm:"type:BIGINT;NOT NULL;default:0"`
+	CreatedAt   time.Time       `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time       `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
 }
 
 // TableName returns the table name string to guaranty use correct table
@@ -101,3 +114,55 @@ func (c Container) Validate(db *gorm.DB) {
 		db.AddError(err)
 	}
 }
+
+// ContainerSnapshot is model to contain container snapshot information
+// nolint:lll
+type ContainerSnapshot struct {
+	ID          uint64    `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Image       string    `form:"image" json:"image" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	FlowID      uint64    `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	ContainerID uint64    `form:"container_id" json:"container_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	UserID      uint64    `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CreatedAt   time.Time `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (cs *ContainerSnapshot) TableName() string {
+	return "container_snapshots"
+}
+
+// Valid is function to control input/output data
+func (cs ContainerSnapshot) Valid() error {
+	return validate.Struct(cs)
+}
+
+// Validate is function to use callback to control input/output data
+func (cs ContainerSnapshot) Validate(db *gorm.DB) {
+	if err := cs.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// ContainerResources is model to contain requested resource limits of the flow container
+// nolint:lll
+type ContainerResources struct {
+	CPUs     float64 `form:"cpus,omitempty" json:"cpus,omitempty" validate:"omitempty,gt=0" example:"2"`
+	MemoryMB int64   `form:"memory_mb,omitempty" json:"memory_mb,omitempty" validate:"omitempty,gt=0" example:"4096"`
+	Pids     int64   `form:"pids,omitempty" json:"pids,omitempty" validate:"omitempty,gt=0" example:"1024"`
+}
+
+// Valid is function to control input/output data
+func (cr ContainerResources) Valid() error {
+	return validate.Struct(cr)
+}
+
+// ContainerLogsQuery is model to contain query params of container logs request
+// nolint:lll
+type ContainerLogsQuery struct {
+	Follow bool `form:"follow" json:"follow" validate:"omitempty" default:"false"`
+}
+
+// Valid is function to control input/output data
+func (clq ContainerLogsQuery) Valid() error {
+	return validate.Struct(clq)
+}
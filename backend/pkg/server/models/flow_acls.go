@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type FlowAccess string
+
+const (
+	FlowAccessView FlowAccess = "view"
+	FlowAccessEdit FlowAccess = "edit"
+)
+
+func (s FlowAccess) String() string {
+	return string(s)
+}
+
+// Valid is function to control input/output data
+func (s FlowAccess) Valid() error {
+	switch s {
+	case FlowAccessView, FlowAccessEdit:
+		return nil
+	default:
+		return fmt.Errorf("invalid FlowAccess: %s", s)
+	}
+}
+
+// Validate is function to use callback to control input/output data
+func (s FlowAccess) Validate(db *gorm.DB) {
+	if err := s.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// FlowACL is model to contain access of the user to the flow shared by its owner
+// nolint:lll
+type FlowACL struct {
+	ID        uint64     `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	FlowID    uint64     `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	UserID    uint64     `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	Access    FlowAccess `form:"access" json:"access" validate:"valid,required" gorm:"type:FLOW_ACCESS;NOT NULL;default:'view'"`
+	CreatedAt time.Time  `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (fa *FlowACL) TableName() string {
+	return "flow_acls"
+}
+
+// Valid is function to control input/output data
+func (fa FlowACL) Valid() error {
+	return validate.Struct(fa)
+}
+
+// Validate is function to use callback to control input/output data
+func (fa FlowACL) Validate(db *gorm.DB) {
+	if err := fa.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// GrantFlowAccess is model to contain access level granted to the user on the flow
+// nolint:lll
+type GrantFlowAccess struct {
+	Access FlowAccess `form:"access" json:"access" validate:"valid,required" enums:"view,edit" default:"view"`
+}
+
+// Valid is function to control input/output data
+func (gfa GrantFlowAccess) Valid() error {
+	return validate.Struct(gfa)
+}
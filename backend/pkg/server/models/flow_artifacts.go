@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// FlowArtifact is model to contain information of the output saved by the agents to the flow,
+// the content is kept in the artifacts storage and outlives the flow containers
+// nolint:lll
+type FlowArtifact struct {
+	ID          uint64    `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Name        string    `form:"name" json:"name" validate:"required,max=255" gorm:"type:TEXT;NOT NULL"`
+	Size        int64     `form:"size" json:"size" validate:"min=0" gorm:"type:BIGINT;NOT NULL"`
+	ContentType string    `form:"content_type" json:"content_type" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Checksum    string    `form:"checksum" json:"checksum" validate:"required,len=64,hexadecimal" gorm:"type:TEXT;NOT NULL"`
+	URL         string    `form:"url" json:"url" validate:"omitempty" gorm:"-"`
+	FlowID      uint64    `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	TaskID      *uint64   `form:"task_id,omitempty" json:"task_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT"`
+	SubtaskID   *uint64   `form:"subtask_id,omitempty" json:"subtask_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT"`
+	CreatedAt   time.Time `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (fa *FlowArtifact) TableName() string {
+	return "flow_artifacts"
+}
+
+// Valid is function to control input/output data
+func (fa FlowArtifact) Valid() error {
+	return validate.Struct(fa)
+}
+
+// Validate is function to use callback to control input/output data
+func (fa FlowArtifact) Validate(db *gorm.DB) {
+	if err := fa.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// FlowAttachment is model to contain information of the file uploaded to the flow context,
+// the content is kept in the attachments storage and copied to the flow container
+// nolint:lll
+type FlowAttachment struct {
+	ID          uint64    `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Name        string    `form:"name" json:"name" validate:"required,max=255" gorm:"type:TEXT;NOT NULL"`
+	Size        int64     `form:"size" json:"size" validate:"min=0" gorm:"type:BIGINT;NOT NULL"`
+	ContentType string    `form:"content_type" json:"content_type" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Checksum    string    `form:"checksum" json:"checksum" validate:"required,len=64,hexadecimal" gorm:"type:TEXT;NOT NULL"`
+	Path        string    `form:"path" json:"path" validate:"omitempty" gorm:"-"`
+	FlowID      uint64    `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	UserID      uint64    `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CreatedAt   time.Time `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (fa *FlowAttachment) TableName() string {
+	return "flow_attachments"
+}
+
+// Valid is function to control input/output data
+func (fa FlowAttachment) Valid() error {
+	return validate.Struct(fa)
+}
+
+// Validate is function to use callback to control input/output data
+func (fa FlowAttachment) Validate(db *gorm.DB) {
+	if err := fa.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
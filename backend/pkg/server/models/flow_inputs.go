@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type FlowInputStatus string
+
+const (
+	FlowInputStatusQueued   FlowInputStatus = "queued"
+	FlowInputStatusConsumed FlowInputStatus = "consumed"
+	FlowInputStatusFailed   FlowInputStatus = "failed"
+	FlowInputStatusCanceled FlowInputStatus = "canceled"
+)
+
+func (s FlowInputStatus) String() string {
+	return string(s)
+}
+
+// Valid is function to control input/output data
+func (s FlowInputStatus) Valid() error {
+	switch s {
+	case FlowInputStatusQueued,
+		FlowInputStatusConsumed,
+		FlowInputStatusFailed,
+		FlowInputStatusCanceled:
+		return nil
+	default:
+		return fmt.Errorf("invalid FlowInputStatus: %s", s)
+	}
+}
+
+// Validate is function to use callback to control input/output data
+func (s FlowInputStatus) Validate(db *gorm.DB) {
+	if err := s.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// FlowInput is model to contain the input sent to the flow and the result of its delivery,
+// inputs are consumed by the flow in the order of their ids
+// nolint:lll
+type FlowInput struct {
+	ID          uint64          `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Input       string          `form:"input" json:"input" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Status      FlowInputStatus `form:"status" json:"status" validate:"valid,required" gorm:"type:FLOW_INPUT_STATUS;NOT NULL;default:'queued'" enums:"queued,consumed,failed,canceled"`
+	Error       *string         `form:"error,omitempty" json:"error,omitempty" validate:"omitempty" gorm:"type:TEXT"`
+	FlowID      uint64          `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CreatedAt   time.Time       `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	ProcessedAt *time.Time      `form:"processed_at,omitempty" json:"processed_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (fi *FlowInput) TableName() string {
+	return "flow_inputs"
+}
+
+// Valid is function to control input/output data
+func (fi FlowInput) Valid() error {
+	return validate.Struct(fi)
+}
+
+// Validate is function to use callback to control input/output data
+func (fi FlowInput) Validate(db *gorm.DB) {
+	if err := fi.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
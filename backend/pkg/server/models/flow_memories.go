@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// FlowMemoryTags is model to contain tags of the flow memory fact stored as JSON array
+type FlowMemoryTags []string
+
+// Value implements driver.Valuer interface for database write
+func (t FlowMemoryTags) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements sql.Scanner interface for database read
+func (t *FlowMemoryTags) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("failed to scan FlowMemoryTags: expected []byte, got %T", value)
+	}
+}
+
+// FlowMemory is model to contain the fact stored by the agents to the flow memory
+// nolint:lll
+type FlowMemory struct {
+	ID        uint64         `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Key       string         `form:"key" json:"key" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Value     string         `form:"value" json:"value" validate:"omitempty" gorm:"type:TEXT;NOT NULL"`
+	Tags      FlowMemoryTags `form:"tags" json:"tags" validate:"omitempty" gorm:"type:JSONB;NOT NULL;default:'[]'"`
+	FlowID    uint64         `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	TaskID    *uint64        `form:"task_id,omitempty" json:"task_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT"`
+	SubtaskID *uint64        `form:"subtask_id,omitempty" json:"subtask_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT"`
+	CreatedAt time.Time      `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time      `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (fm *FlowMemory) TableName() string {
+	return "flow_memories"
+}
+
+// Valid is function to control input/output data
+func (fm FlowMemory) Valid() error {
+	return validate.Struct(fm)
+}
+
+// Validate is function to use callback to control input/output data
+func (fm FlowMemory) Validate(db *gorm.DB) {
+	if err := fm.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
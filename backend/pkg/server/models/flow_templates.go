@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// FlowTemplateParams is model to contain flow creation params stored in the template as JSON
+type FlowTemplateParams CreateFlow
+
+// CreateFlow returns flow creation params to pre-populate the flow creation request
+func (p FlowTemplateParams) CreateFlow() CreateFlow {
+	cf := CreateFlow(p)
+	cf.TemplateID = nil
+	return cf
+}
+
+// Valid is function to control input/output data
+func (p FlowTemplateParams) Valid() error {
+	if p.TemplateID != nil {
+		return errors.New("flow template can't refer to another template")
+	}
+	return CreateFlow(p).Valid()
+}
+
+// Value implements driver.Valuer interface for database write
+func (p FlowTemplateParams) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner interface for database read
+func (p *FlowTemplateParams) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("failed to scan FlowTemplateParams: expected []byte, got %T", value)
+	}
+}
+
+// FlowTemplate is model to contain saved flow creation params information
+// nolint:lll
+type FlowTemplate struct {
+	ID        uint64             `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Name      string             `form:"name" json:"name" validate:"required,max=100" gorm:"type:TEXT;NOT NULL"`
+	Shared    bool               `form:"shared" json:"shared" validate:"omitempty" gorm:"type:BOOLEAN;NOT NULL;default:false"`
+	Params    FlowTemplateParams `form:"params" json:"params" validate:"valid" gorm:"type:JSONB;NOT NULL"`
+	UserID    uint64             `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CreatedAt time.Time          `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time          `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (ft *FlowTemplate) TableName() string {
+	return "flow_templates"
+}
+
+// Valid is function to control input/output data
+func (ft FlowTemplate) Valid() error {
+	return validate.Struct(ft)
+}
+
+// Validate is function to use callback to control input/output data
+func (ft FlowTemplate) Validate(db *gorm.DB) {
+	if err := ft.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// SaveFlowTemplate is model to contain flow template creation and update payload
+// nolint:lll
+type SaveFlowTemplate struct {
+	Name   string     `form:"name" json:"name" validate:"required,max=100" example:"external web perimeter"`
+	Shared bool       `form:"shared" json:"shared" validate:"omitempty" default:"false"`
+	Params CreateFlow `form:"params" json:"params" validate:"required"`
+}
+
+// Valid is function to control input/output data, the params are checked as the flow creation request
+// so broken templates can't be stored
+func (sft SaveFlowTemplate) Valid() error {
+	if err := validate.Struct(sft); err != nil {
+		return err
+	}
+	return FlowTemplateParams(sft.Params).Valid()
+}
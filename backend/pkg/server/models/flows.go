@@ -1,7 +1,9 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"pentagi/pkg/tools"
@@ -44,23 +46,108 @@ func (s FlowStatus) Validate(db *gorm.DB) {
 	}
 }
 
+// FlowFinishReason is the reason why the flow stopped working last time, it's cleared when the flow runs again
+type FlowFinishReason string
+
+const (
+	FlowFinishReasonUserStop          FlowFinishReason = "user_stop"
+	FlowFinishReasonUserFinish        FlowFinishReason = "user_finish"
+	FlowFinishReasonCompleted         FlowFinishReason = "completed"
+	FlowFinishReasonBudgetExceeded    FlowFinishReason = "budget_exceeded"
+	FlowFinishReasonError             FlowFinishReason = "error"
+	FlowFinishReasonProviderExhausted FlowFinishReason = "provider_exhausted"
+	FlowFinishReasonPlanLimitExceeded FlowFinishReason = "plan_limit_exceeded"
+)
+
+func (s FlowFinishReason) String() string {
+	return string(s)
+}
+
+// Valid is function to control input/output data
+func (s FlowFinishReason) Valid() error {
+	switch s {
+	case FlowFinishReasonUserStop,
+		FlowFinishReasonUserFinish,
+		FlowFinishReasonCompleted,
+		FlowFinishReasonBudgetExceeded,
+		FlowFinishReasonError,
+		FlowFinishReasonProviderExhausted,
+		FlowFinishReasonPlanLimitExceeded:
+		return nil
+	default:
+		return fmt.Errorf("invalid FlowFinishReason: %s", s)
+	}
+}
+
+// Validate is function to use callback to control input/output data
+func (s FlowFinishReason) Validate(db *gorm.DB) {
+	if err := s.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// FlowLanguageAuto asks to detect the language of the new flow from its input
+const FlowLanguageAuto = "auto"
+
+// SupportedFlowLanguages is the list of languages which can be set to the flow explicitly
+var SupportedFlowLanguages = []string{
+	"English",
+	"Russian",
+	"Chinese",
+	"Spanish",
+	"German",
+	"French",
+	"Italian",
+	"Portuguese",
+	"Japanese",
+	"Korean",
+	"Arabic",
+	"Turkish",
+	"Ukrainian",
+	"Polish",
+	"Dutch",
+	"Hindi",
+}
+
 // Flow is model to contain flow information
 // nolint:lll
 type Flow struct {
-	ID                 uint64           `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
-	Status             FlowStatus       `form:"status" json:"status" validate:"valid,required" gorm:"type:FLOW_STATUS;NOT NULL;default:'created'"`
-	Title              string           `form:"title" json:"title" validate:"required" gorm:"type:TEXT;NOT NULL;default:'untitled'"`
-	Model              string           `form:"model" json:"model" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
-	ModelProviderName  string           `form:"model_provider_name" json:"model_provider_name" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
-	ModelProviderType  ProviderType     `form:"model_provider_type" json:"model_provider_type" validate:"valid,required" gorm:"type:PROVIDER_TYPE;NOT NULL"`
-	Language           string           `form:"language" json:"language" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
-	Functions          *tools.Functions `form:"functions,omitempty" json:"functions,omitempty" validate:"omitempty,valid" gorm:"type:JSON;NOT NULL;default:'{}'"`
-	ToolCallIDTemplate string           `form:"tool_call_id_template" json:"tool_call_id_template" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
-	TraceID            *string          `form:"trace_id" json:"trace_id" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
-	UserID             uint64           `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
-	CreatedAt          time.Time        `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
-	UpdatedAt          time.Time        `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
-	DeletedAt          *time.Time       `form:"deleted_at,omitempty" json:"deleted_at,omitempty" validate:"omitempty" sql:"index" gorm:"type:TIMESTAMPTZ"`
+	ID                  uint64              `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Status              FlowStatus          `form:"status" json:"status" validate:"valid,required" gorm:"type:FLOW_STATUS;NOT NULL;default:'created'"`
+	Title               string              `form:"title" json:"title" validate:"required" gorm:"type:TEXT;NOT NULL;default:'untitled'"`
+	Model               string              `form:"model" json:"model" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
+	ModelProviderName   string              `form:"model_provider_name" json:"model_provider_name" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
+	ModelProviderType   ProviderType        `form:"model_provider_type" json:"model_provider_type" validate:"valid,required" gorm:"type:PROVIDER_TYPE;NOT NULL"`
+	Language            string              `form:"language" json:"language" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
+	Description         string              `form:"description" json:"description" validate:"max=2000" gorm:"type:TEXT;NOT NULL;default:''"`
+	Functions           *tools.Functions    `form:"functions,omitempty" json:"functions,omitempty" validate:"omitempty,valid" gorm:"type:JSON;NOT NULL;default:'{}'"`
+	ToolCallIDTemplate  string              `form:"tool_call_id_template" json:"tool_call_id_template" validate:"max=70,required,tcid" gorm:"type:TEXT;NOT NULL"`
+	FallbackProviders   json.RawMessage     `form:"fallback_providers,omitempty" json:"fallback_providers,omitempty" validate:"omitempty" gorm:"type:JSON;NOT NULL;default:'[]'" swaggertype:"array,string"`
+	TraceID             *string             `form:"trace_id" json:"trace_id" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
+	BudgetMaxCost       *float64            `form:"budget_max_cost,omitempty" json:"budget_max_cost,omitempty" validate:"omitnil,gt=0" gorm:"type:DOUBLE PRECISION"`
+	BudgetMaxTokens     *int64              `form:"budget_max_tokens,omitempty" json:"budget_max_tokens,omitempty" validate:"omitnil,gt=0" gorm:"type:BIGINT"`
+	StopReason          *string             `form:"stop_reason,omitempty" json:"stop_reason,omitempty" validate:"omitempty" gorm:"type:TEXT"`
+	ToolCallsPerMinute  *int32              `form:"tool_calls_per_minute,omitempty" json:"tool_calls_per_minute,omitempty" validate:"omitnil,gt=0" gorm:"type:INTEGER"`
+	ToolCallsMax        *int64              `form:"tool_calls_max,omitempty" json:"tool_calls_max,omitempty" validate:"omitnil,gt=0" gorm:"type:BIGINT"`
+	ResponseCache       *string             `form:"response_cache,omitempty" json:"response_cache,omitempty" validate:"omitnil,oneof=disabled deterministic forced" gorm:"type:TEXT"`
+	ProviderTimeout     *int32              `form:"provider_timeout,omitempty" json:"provider_timeout,omitempty" validate:"omitnil,min=0" gorm:"type:INTEGER"`
+	FinishReason        *FlowFinishReason   `form:"finish_reason,omitempty" json:"finish_reason,omitempty" validate:"omitnil,valid" gorm:"type:FLOW_FINISH_REASON" enums:"user_stop,user_finish,completed,budget_exceeded,error,provider_exhausted,plan_limit_exceeded"`
+	AwaitingInput       bool                `form:"awaiting_input" json:"awaiting_input" validate:"omitempty" gorm:"type:BOOLEAN;NOT NULL;default:false"`
+	PendingQuestion     *string             `form:"pending_question,omitempty" json:"pending_question,omitempty" validate:"omitempty" gorm:"type:TEXT"`
+	SystemPrompt        *string             `form:"system_prompt,omitempty" json:"system_prompt,omitempty" validate:"omitempty" gorm:"type:TEXT"`
+	PlanMaxSubtasks     *int32              `form:"plan_max_subtasks,omitempty" json:"plan_max_subtasks,omitempty" validate:"omitnil,gt=0" gorm:"type:INTEGER"`
+	PlanMaxDepth        *int32              `form:"plan_max_depth,omitempty" json:"plan_max_depth,omitempty" validate:"omitnil,gt=0" gorm:"type:INTEGER"`
+	LastError           *string             `form:"last_error,omitempty" json:"last_error,omitempty" validate:"omitempty" gorm:"type:TEXT"`
+	LastErrorCount      int32               `form:"last_error_count" json:"last_error_count" validate:"min=0" gorm:"type:INTEGER;NOT NULL;default:0"`
+	LastErrorAt         *time.Time          `form:"last_error_at,omitempty" json:"last_error_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ"`
+	CommandPolicy       tools.CommandPolicy `form:"command_policy" json:"command_policy" validate:"valid" gorm:"type:JSONB;NOT NULL;default:'{}'"`
+	Scope               tools.FlowScope     `form:"scope" json:"scope" validate:"valid" gorm:"type:JSONB;NOT NULL;default:'[]'"`
+	ResultMaxSize       *int32              `form:"result_max_size,omitempty" json:"result_max_size,omitempty" validate:"omitnil,gt=0" gorm:"type:INTEGER"`
+	ResultMaxSourceSize *int32              `form:"result_max_source_size,omitempty" json:"result_max_source_size,omitempty" validate:"omitnil,gt=0" gorm:"type:INTEGER"`
+	UserID              uint64              `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CreatedAt           time.Time           `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt           time.Time           `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	DeletedAt           *time.Time          `form:"deleted_at,omitempty" json:"deleted_at,omitempty" validate:"omitempty" sql:"index" gorm:"type:TIMESTAMPTZ"`
 }
 
 // TableName returns the table name string to guaranty use correct table
@@ -83,9 +170,29 @@ func (f Flow) Validate(db *gorm.DB) {
 // CreateFlow is model to contain flow creation paylaod
 // nolint:lll
 type CreateFlow struct {
-	Input     string           `form:"input" json:"input" validate:"required" example:"user input for first task in the flow"`
-	Provider  string           `form:"provider" json:"provider" validate:"required" example:"openai"`
-	Functions *tools.Functions `form:"functions,omitempty" json:"functions,omitempty" validate:"omitempty,valid"`
+	Input               string               `form:"input" json:"input" validate:"required_without=InputTemplateID,excluded_with=InputTemplateID" example:"user input for first task in the flow"`
+	Provider            string               `form:"provider" json:"provider" validate:"required" example:"openai"`
+	Fallbacks           []string             `form:"fallback_providers,omitempty" json:"fallback_providers,omitempty" validate:"omitempty,max=5,unique,dive,required,max=70" example:"anthropic,gemini"`
+	Resources           *ContainerResources  `form:"resources,omitempty" json:"resources,omitempty" validate:"omitempty,valid"`
+	SnapshotID          *uint64              `form:"snapshot_id,omitempty" json:"snapshot_id,omitempty" validate:"omitnil,min=1" example:"1"`
+	Functions           *tools.Functions     `form:"functions,omitempty" json:"functions,omitempty" validate:"omitempty,valid"`
+	Env                 map[string]string    `form:"env,omitempty" json:"env,omitempty" validate:"omitempty,max=100,dive,keys,envname,max=128,endkeys,max=4096" example:"TARGET_SCOPE:10.0.0.0/24"`
+	Secrets             map[string]string    `form:"secrets,omitempty" json:"secrets,omitempty" validate:"omitempty,max=100,dive,keys,envname,max=128,endkeys,max=16384" example:"API_TOKEN:secret"`
+	Language            *string              `form:"language,omitempty" json:"language,omitempty" validate:"omitnil,flang=auto" example:"English"`
+	TemplateID          *uint64              `form:"template_id,omitempty" json:"template_id,omitempty" validate:"omitnil,min=1" example:"1"`
+	InputTemplateID     *uint64              `form:"input_template_id,omitempty" json:"input_template_id,omitempty" validate:"omitnil,min=1" example:"1"`
+	Variables           map[string]string    `form:"variables,omitempty" json:"variables,omitempty" validate:"excluded_without=InputTemplateID,omitempty,max=100,dive,keys,max=64,endkeys,max=16384" example:"domain:example.com"`
+	Budget              *FlowBudget          `form:"budget,omitempty" json:"budget,omitempty" validate:"omitempty,valid"`
+	ToolLimits          *FlowToolLimits      `form:"tool_limits,omitempty" json:"tool_limits,omitempty" validate:"omitempty,valid"`
+	PlanLimits          *FlowPlanLimits      `form:"plan_limits,omitempty" json:"plan_limits,omitempty" validate:"omitempty,valid"`
+	ResultLimits        *FlowResultLimits    `form:"result_limits,omitempty" json:"result_limits,omitempty" validate:"omitempty,valid"`
+	ResponseCache       string               `form:"response_cache,omitempty" json:"response_cache,omitempty" validate:"omitempty,oneof=disabled deterministic forced" example:"deterministic"`
+	ProviderTimeout     *int32               `form:"provider_timeout,omitempty" json:"provider_timeout,omitempty" validate:"omitnil,min=0,max=86400" example:"300"`
+	SystemPrompt        *string              `form:"system_prompt,omitempty" json:"system_prompt,omitempty" validate:"omitnil,min=1,max=65536" example:"You are a web application security expert..."`
+	CommandPolicy       *tools.CommandPolicy `form:"command_policy,omitempty" json:"command_policy,omitempty" validate:"omitempty,valid"`
+	Scope               tools.FlowScope      `form:"scope,omitempty" json:"scope,omitempty" validate:"omitempty,max=1000,valid" example:"10.0.0.0/24,*.example.com"`
+	FunctionsCompatMode bool                 `form:"functions_compat_mode,omitempty" json:"functions_compat_mode,omitempty" example:"false"`
+	Queue               bool                 `form:"queue,omitempty" json:"queue,omitempty" example:"false"`
 }
 
 // Valid is function to control input/output data
@@ -93,12 +200,120 @@ func (cf CreateFlow) Valid() error {
 	return validate.Struct(cf)
 }
 
+// HasFunctions returns true if the flow adds custom functions which require tools support of the models
+func (cf CreateFlow) HasFunctions() bool {
+	return cf.Functions != nil && len(cf.Functions.Function) != 0
+}
+
+// FlowsStatusRequest is model to contain ids of the flows to get their statuses in bulk
+// nolint:lll
+type FlowsStatusRequest struct {
+	IDs []uint64 `form:"ids" json:"ids" validate:"required,min=1,max=500,dive,min=1" example:"1,2,3"`
+}
+
+// Valid is function to control input/output data
+func (fsr FlowsStatusRequest) Valid() error {
+	return validate.Struct(fsr)
+}
+
+// FlowStatusInfo is model to contain the lightweight state of the flow for dashboards
+// nolint:lll
+type FlowStatusInfo struct {
+	Status               FlowStatus `form:"status" json:"status" validate:"valid,required"`
+	UpdatedAt            time.Time  `form:"updated_at" json:"updated_at" validate:"omitempty"`
+	ActiveContainerCount uint64     `form:"active_container_count" json:"active_container_count" validate:"min=0"`
+}
+
+// FlowSecrets is model to contain the new secrets of the flow which replace all previous ones
+// nolint:lll
+type FlowSecrets struct {
+	Secrets map[string]string `form:"secrets" json:"secrets" validate:"max=100,dive,keys,envname,max=128,endkeys,max=16384" example:"API_TOKEN:secret"`
+}
+
+// Valid is function to control input/output data
+func (fs FlowSecrets) Valid() error {
+	return validate.Struct(fs)
+}
+
+const FlowSecretRedacted = "[REDACTED]"
+
+// FlowEnvVar is model to contain the flow environment variable, value of the secret is never returned
+// nolint:lll
+type FlowEnvVar struct {
+	Name      string    `form:"name" json:"name" validate:"envname,required" example:"API_TOKEN"`
+	Value     string    `form:"value" json:"value" validate:"omitempty" example:"[REDACTED]"`
+	Secret    bool      `form:"secret" json:"secret" example:"true"`
+	UpdatedAt time.Time `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty"`
+}
+
+// Valid is function to control input/output data
+func (fev FlowEnvVar) Valid() error {
+	return validate.Struct(fev)
+}
+
+// FlowBudget is model to contain limits of the flow usage, the flow is stopped when any of them is exceeded
+// nolint:lll
+type FlowBudget struct {
+	MaxCost   *float64 `form:"max_cost,omitempty" json:"max_cost,omitempty" validate:"omitnil,gt=0" example:"10"`
+	MaxTokens *int64   `form:"max_tokens,omitempty" json:"max_tokens,omitempty" validate:"omitnil,gt=0" example:"5000000"`
+}
+
+// Valid is function to control input/output data
+func (fb FlowBudget) Valid() error {
+	return validate.Struct(fb)
+}
+
+// FlowToolLimits is model to contain limits of the flow tool calls, the call over the limit is throttled
+// nolint:lll
+type FlowToolLimits struct {
+	CallsPerMinute *int32 `form:"calls_per_minute,omitempty" json:"calls_per_minute,omitempty" validate:"omitnil,gt=0" example:"20"`
+	MaxCalls       *int64 `form:"max_calls,omitempty" json:"max_calls,omitempty" validate:"omitnil,gt=0" example:"1000"`
+}
+
+// Valid is function to control input/output data
+func (ftl FlowToolLimits) Valid() error {
+	return validate.Struct(ftl)
+}
+
+// FlowPlanLimits is model to contain limits of the flow task planning, the task is wrapped up when any of them is reached
+// nolint:lll
+type FlowPlanLimits struct {
+	MaxSubtasks *int32 `form:"max_subtasks,omitempty" json:"max_subtasks,omitempty" validate:"omitnil,gt=0" example:"30"`
+	MaxDepth    *int32 `form:"max_depth,omitempty" json:"max_depth,omitempty" validate:"omitnil,gt=0" example:"10"`
+}
+
+// Valid is function to control input/output data
+func (fpl FlowPlanLimits) Valid() error {
+	return validate.Struct(fpl)
+}
+
+// FlowResultLimits is model to contain size limits of the flow tool results in bytes, the result over the limit is truncated
+// nolint:lll
+type FlowResultLimits struct {
+	MaxSize       *int32 `form:"max_size,omitempty" json:"max_size,omitempty" validate:"omitnil,min=4096,max=4194304" example:"81920"`
+	MaxSourceSize *int32 `form:"max_source_size,omitempty" json:"max_source_size,omitempty" validate:"omitnil,min=1024,max=4194304" example:"51200"`
+}
+
+// Valid is function to control input/output data
+func (frl FlowResultLimits) Valid() error {
+	return validate.Struct(frl)
+}
+
 // PatchFlow is model to contain flow patching paylaod
 // nolint:lll
 type PatchFlow struct {
-	Action string  `form:"action" json:"action" validate:"required,oneof=stop finish input rename" enums:"stop,finish,input,rename" default:"stop"`
-	Input  *string `form:"input,omitempty" json:"input,omitempty" validate:"required_if=Action input" example:"user input for waiting flow"`
-	Name   *string `form:"name,omitempty" json:"name,omitempty" validate:"required_if=Action rename" example:"new flow name"`
+	Action      string      `form:"action" json:"action" validate:"required,oneof=stop finish input rename metadata budget" enums:"stop,finish,input,rename,metadata,budget" default:"stop"`
+	Input       *string     `form:"input,omitempty" json:"input,omitempty" validate:"required_if=Action input" example:"user input for waiting flow"`
+	Name        *string     `form:"name,omitempty" json:"name,omitempty" validate:"required_if=Action rename" example:"new flow name"`
+	Title       *string     `form:"title,omitempty" json:"title,omitempty" validate:"omitnil,required" example:"new flow title"`
+	Language    *string     `form:"language,omitempty" json:"language,omitempty" validate:"omitnil,flang" example:"English"`
+	Description *string     `form:"description,omitempty" json:"description,omitempty" validate:"omitnil,max=2000" example:"free text flow description"`
+	Budget      *FlowBudget `form:"budget,omitempty" json:"budget,omitempty" validate:"required_if=Action budget,omitempty,valid"`
+}
+
+// HasMetadata returns true if at least one metadata field is set
+func (pf PatchFlow) HasMetadata() bool {
+	return pf.Title != nil || pf.Language != nil || pf.Description != nil
 }
 
 // Valid is function to control input/output data
@@ -106,11 +321,61 @@ func (pf PatchFlow) Valid() error {
 	return validate.Struct(pf)
 }
 
+// RestoreFlow is model to contain deleted flow restoring payload
+// nolint:lll
+type RestoreFlow struct {
+	Reattach bool `form:"reattach" json:"reattach" validate:"omitempty" default:"false"`
+}
+
+// Valid is function to control input/output data
+func (rf RestoreFlow) Valid() error {
+	return validate.Struct(rf)
+}
+
+// RestoredFlow is model to contain the restored flow and its resources which were purged after the deletion
+type RestoredFlow struct {
+	Flow
+	Purged *FlowPurged `json:"purged,omitempty"`
+}
+
+// FlowPurged is model to contain the number of the flow resources removed by the purge of the deleted flow
+// nolint:lll
+type FlowPurged struct {
+	Containers  int `form:"containers" json:"containers" validate:"min=0" example:"1"`
+	Snapshots   int `form:"snapshots" json:"snapshots" validate:"min=0" example:"0"`
+	Attachments int `form:"attachments" json:"attachments" validate:"min=0" example:"2"`
+	Artifacts   int `form:"artifacts" json:"artifacts" validate:"min=0" example:"3"`
+}
+
+// FlowEventsQuery is model to contain params of the flow events stream
+// nolint:lll
+type FlowEventsQuery struct {
+	// Event types to stream (repeat the param for several types), all flow events are streamed by default
+	Types []string `form:"types" json:"types,omitempty" binding:"omitempty,max=5,dive,oneof=flow_updated flow_deleted task_created task_updated message_chunk" enums:"flow_updated,flow_deleted,task_created,task_updated,message_chunk"`
+	// Sequence number of the last received event to replay the missed ones
+	LastSeq *uint64 `form:"last_seq" json:"last_seq,omitempty" binding:"omitempty"`
+}
+
+// FlowsEventsQuery is model to contain params of the user flows events stream
+// nolint:lll
+type FlowsEventsQuery struct {
+	// Sequence number of the last received event to replay the missed ones
+	LastSeq *uint64 `form:"last_seq" json:"last_seq,omitempty" binding:"omitempty"`
+}
+
+// FlowReportQuery is model to contain params of the flow report export
+// nolint:lll
+type FlowReportQuery struct {
+	// Format of the report, markdown is used by default
+	Format string `form:"format" json:"format,omitempty" binding:"omitempty,oneof=md html" enums:"md,html" default:"md"`
+}
+
 // FlowTasksSubtasks is model to contain flow, linded tasks and linked subtasks information
 // nolint:lll
 type FlowTasksSubtasks struct {
-	Tasks []TaskSubtasks `form:"tasks" json:"tasks" validate:"required" gorm:"foreignkey:FlowID;association_autoupdate:false;association_autocreate:false"`
-	Flow  `form:"" json:""`
+	Tasks    []TaskSubtasks `form:"tasks" json:"tasks" validate:"required" gorm:"foreignkey:FlowID;association_autoupdate:false;association_autocreate:false"`
+	Progress *float64       `form:"progress,omitempty" json:"progress,omitempty" validate:"omitnil,min=0,max=100" gorm:"-" example:"50"`
+	Flow     `form:"" json:""`
 }
 
 // TableName returns the table name string to guaranty use correct table
@@ -128,6 +393,29 @@ func (fts FlowTasksSubtasks) Valid() error {
 	return fts.Flow.Valid()
 }
 
+// SetProgress fills the percentage of completed subtasks of every task and of the whole flow,
+// the progress stays empty if there are no subtasks yet, it must be called only if subtasks are loaded
+func (fts *FlowTasksSubtasks) SetProgress() {
+	var completed, total int
+	for i := range fts.Tasks {
+		taskCompleted, taskTotal := fts.Tasks[i].SubtasksProgress()
+		fts.Tasks[i].Progress = progressPercent(taskCompleted, taskTotal)
+		completed += taskCompleted
+		total += taskTotal
+	}
+
+	fts.Progress = progressPercent(completed, total)
+}
+
+func progressPercent(completed, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+
+	percent := math.Round(float64(completed)*1000/float64(total)) / 10
+	return &percent
+}
+
 // Validate is function to use callback to control input/output data
 func (fts FlowTasksSubtasks) Validate(db *gorm.DB) {
 	if err := fts.Valid(); err != nil {
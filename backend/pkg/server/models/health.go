@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// HealthCheck is model to contain result of the single dependency check
+// nolint:lll
+type HealthCheck struct {
+	Name      string `form:"name" json:"name" validate:"required" example:"database"`
+	Status    string `form:"status" json:"status" validate:"oneof=ok error,required" example:"ok"`
+	LatencyMs int64  `form:"latency_ms" json:"latency_ms" validate:"min=0" example:"3"`
+	Error     string `form:"error,omitempty" json:"error,omitempty" validate:"omitempty" example:"check timed out"`
+	Cached    bool   `form:"cached" json:"cached" example:"false"`
+}
+
+// Valid is function to control input/output data
+func (hc HealthCheck) Valid() error {
+	return validate.Struct(hc)
+}
+
+// Readiness is model to contain readiness probe result with all dependency checks
+// nolint:lll
+type Readiness struct {
+	Status    string        `form:"status" json:"status" validate:"oneof=ok error,required" example:"ok"`
+	Checks    []HealthCheck `form:"checks" json:"checks" validate:"required"`
+	CheckedAt time.Time     `form:"checked_at" json:"checked_at" validate:"required"`
+}
+
+// Valid is function to control input/output data
+func (r Readiness) Valid() error {
+	return validate.Struct(r)
+}
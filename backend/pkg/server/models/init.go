@@ -2,11 +2,17 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
+	"pentagi/pkg/templates"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/xeipuuv/gojsonschema"
 )
@@ -16,6 +22,8 @@ const (
 	clDateRegexString   = "^[0-9]{2}[.-][0-9]{2}[.-][0-9]{4}$"
 	semverRegexString   = "^[0-9]+\\.[0-9]+(\\.[0-9]+)?$"
 	semverexRegexString = "^(v)?[0-9]+\\.[0-9]+(\\.[0-9]+)?(\\.[0-9]+)?(-[a-zA-Z0-9]+)?$"
+	funcNameRegexString = "^[a-zA-Z0-9_\\-]+$"
+	envNameRegexString  = "^[a-zA-Z_][a-zA-Z0-9_]*$"
 )
 
 var (
@@ -131,6 +139,111 @@ func oauthMinScope() validator.Func {
 	}
 }
 
+func flowLanguageValidatorString() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+
+		switch field.Kind() {
+		case reflect.String:
+			// flang=auto allows to leave the language empty or auto to detect it
+			if value := field.String(); fl.Param() == FlowLanguageAuto && (value == "" || value == FlowLanguageAuto) {
+				return true
+			}
+			return slices.Contains(SupportedFlowLanguages, field.String())
+		default:
+			return false
+		}
+	}
+}
+
+func toolCallIDTemplateValidatorString() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+
+		switch field.Kind() {
+		case reflect.String:
+			return templates.ValidateToolCallIDTemplate(field.String()) == nil
+		default:
+			return false
+		}
+	}
+}
+
+// createFlowValidator checks custom functions of the new flow which are sent to LLM as tools,
+// it isn't applied to the stored flows to keep working flows which were created before
+func createFlowValidator() validator.StructLevelFunc {
+	funcNameRegex := regexp.MustCompile(funcNameRegexString)
+	return func(sl validator.StructLevel) {
+		cf, ok := sl.Current().Interface().(CreateFlow)
+		if !ok {
+			return
+		}
+
+		for name := range cf.Secrets {
+			if _, ok := cf.Env[name]; ok {
+				sl.ReportError(name, "Secrets["+name+"]", "Secrets", "unique", "env")
+			}
+		}
+
+		if cf.Functions == nil {
+			return
+		}
+
+		names := make(map[string]struct{}, len(cf.Functions.Function))
+		for idx, fn := range cf.Functions.Function {
+			prefix := fmt.Sprintf("Functions.Function[%d].", idx)
+			reportStructErrors(sl, prefix, validate.Struct(fn))
+
+			if fn.Name != "" && !funcNameRegex.MatchString(fn.Name) {
+				sl.ReportError(fn.Name, prefix+"Name", "Name", "fname", "")
+			}
+			if _, ok := names[fn.Name]; ok && fn.Name != "" {
+				sl.ReportError(fn.Name, prefix+"Name", "Name", "unique", "")
+			}
+			names[fn.Name] = struct{}{}
+
+			if fn.Schema.Description == "" {
+				sl.ReportError(fn.Schema.Description, prefix+"Schema.Description", "Description", "required", "")
+			}
+			if fn.Schema.Type.Type != "object" {
+				sl.ReportError(fn.Schema.Type.Type, prefix+"Schema.Type", "Type", "eq", "object")
+			}
+			for _, property := range fn.Schema.Required {
+				if _, ok := fn.Schema.Properties[property]; !ok {
+					sl.ReportError(property, prefix+"Schema.Required", "Required", "property", property)
+				}
+			}
+		}
+
+		disabled := make(map[string]struct{}, len(cf.Functions.Disabled))
+		for idx, fn := range cf.Functions.Disabled {
+			prefix := fmt.Sprintf("Functions.Disabled[%d].", idx)
+			reportStructErrors(sl, prefix, validate.Struct(fn))
+
+			if _, ok := disabled[fn.Name]; ok && fn.Name != "" {
+				sl.ReportError(fn.Name, prefix+"Name", "Name", "unique", "")
+			}
+			disabled[fn.Name] = struct{}{}
+		}
+	}
+}
+
+// reportStructErrors moves validation errors of the nested struct to the current struct level
+func reportStructErrors(sl validator.StructLevel, prefix string, err error) {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return
+	}
+
+	for _, verr := range verrs {
+		field := verr.Namespace()
+		if _, path, ok := strings.Cut(field, "."); ok {
+			field = path
+		}
+		sl.ReportError(verr.Value(), prefix+field, verr.StructField(), verr.Tag(), verr.Param())
+	}
+}
+
 func deepValidator() validator.Func {
 	return func(fl validator.FieldLevel) bool {
 		if iv, ok := fl.Field().Interface().(IValid); ok {
@@ -143,6 +256,105 @@ func deepValidator() validator.Func {
 	}
 }
 
+// FieldError is the failed validation rule of the model field
+type FieldError struct {
+	Field   string `json:"field" example:"Functions.Function[0].URL"`
+	Rule    string `json:"rule" example:"url"`
+	Param   string `json:"param,omitempty" example:""`
+	Message string `json:"message" example:"Functions.Function[0].URL: must be a valid URL"`
+}
+
+func newFieldError(field, rule, param string) FieldError {
+	message := describeRule(rule, param)
+	if field != "" {
+		message = field + ": " + message
+	}
+
+	return FieldError{Field: field, Rule: rule, Param: param, Message: message}
+}
+
+// describeRule returns the human readable reason of the failed rule
+func describeRule(rule, param string) string {
+	switch rule {
+	case "required":
+		return "is required"
+	case "flang":
+		return "unsupported value"
+	case "url":
+		return "must be a valid URL"
+	case "envname":
+		return "must be a valid environment variable name"
+	case "fname":
+		return "must be a valid function name"
+	case "oneof":
+		return "must be one of: " + param
+	case "eq":
+		return "must be equal to " + param
+	case "min", "gte":
+		return "must be at least " + param
+	case "max", "lte":
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "len":
+		return "must have length " + param
+	case "unique":
+		if param != "" {
+			return "must be unique, the value is already used in " + param
+		}
+		return "must be unique"
+	case "property":
+		return fmt.Sprintf("required property '%s' is not defined", param)
+	case "type":
+		return "expected value of type " + param
+	case "json":
+		return "malformed JSON at offset " + param
+	}
+
+	if param != "" {
+		return fmt.Sprintf("failed on the '%s=%s' rule", rule, param)
+	}
+	return fmt.Sprintf("failed on the '%s' rule", rule)
+}
+
+// GetFieldErrors returns failed validation rules of the model fields and JSON decoding errors
+// with the position or the field which has the wrong type, or nil if it's another error
+func GetFieldErrors(err error) []FieldError {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return []FieldError{newFieldError("", "json", strconv.FormatInt(syntaxErr.Offset, 10))}
+	case errors.As(err, &typeErr):
+		return []FieldError{newFieldError(typeErr.Field, "type", typeErr.Type.String())}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return []FieldError{{Rule: "json", Message: "malformed JSON: unexpected end of input"}}
+	case errors.Is(err, io.EOF):
+		return []FieldError{{Rule: "json", Message: "request body is empty"}}
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+
+	fieldErrors := make([]FieldError, 0, len(verrs))
+	for _, verr := range verrs {
+		// namespace starts with the model name which is useless for the client
+		field := verr.Namespace()
+		if _, path, ok := strings.Cut(field, "."); ok {
+			field = path
+		}
+		fieldErrors = append(fieldErrors, newFieldError(field, verr.Tag(), verr.Param()))
+	}
+
+	return fieldErrors
+}
+
 func getMapKeys(kvmap interface{}) string {
 	kl := []interface{}{}
 	val := reflect.ValueOf(kvmap)
@@ -214,7 +426,11 @@ func init() {
 	_ = validate.RegisterValidation("stpass", strongPasswordValidatorString())
 	_ = validate.RegisterValidation("vmail", emailValidatorString())
 	_ = validate.RegisterValidation("oauth_min_scope", oauthMinScope())
+	_ = validate.RegisterValidation("flang", flowLanguageValidatorString())
+	_ = validate.RegisterValidation("tcid", toolCallIDTemplateValidatorString())
+	_ = validate.RegisterValidation("envname", templateValidatorString(envNameRegexString))
 	_ = validate.RegisterValidation("valid", deepValidator())
+	validate.RegisterStructValidation(createFlowValidator(), CreateFlow{})
 
 	// Check validation interface for all models
 	_, _ = reflect.ValueOf(Login{}).Interface().(IValid)
@@ -224,8 +440,28 @@ func init() {
 	_, _ = reflect.ValueOf(Password{}).Interface().(IValid)
 
 	_, _ = reflect.ValueOf(Role{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(UserPermissions{}).Interface().(IValid)
 	_, _ = reflect.ValueOf(Prompt{}).Interface().(IValid)
 	_, _ = reflect.ValueOf(Assistant{}).Interface().(IValid)
 	_, _ = reflect.ValueOf(Flow{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowBudget{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowToolLimits{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowPlanLimits{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowResultLimits{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(SearchCacheStats{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(HealthCheck{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(Readiness{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowSecrets{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowEnvVar{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowTemplate{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(InputTemplate{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowACL{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(AuditLog{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowMemory{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(Toolcall{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(ToolcallGroup{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(FlowArtifact{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(ModelPrice{}).Interface().(IValid)
 	_, _ = reflect.ValueOf(Provider{}).Interface().(IValid)
+	_, _ = reflect.ValueOf(ProviderModel{}).Interface().(IValid)
 }
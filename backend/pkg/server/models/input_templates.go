@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// inputTemplatePlaceholderRegex matches {{name}} placeholders of the input template, spaces around the name are allowed
+var inputTemplatePlaceholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+var errMalformedPlaceholder = errors.New("input template contains malformed placeholder, " +
+	"use {{name}} where name consists of letters, digits and underscores")
+
+// InputTemplate is model to contain reusable flow input with {{placeholders}} information
+// nolint:lll
+type InputTemplate struct {
+	ID        uint64    `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Name      string    `form:"name" json:"name" validate:"required,max=100" gorm:"type:TEXT;NOT NULL"`
+	Template  string    `form:"template" json:"template" validate:"required,max=65536" gorm:"type:TEXT;NOT NULL"`
+	Variables []string  `form:"variables" json:"variables" validate:"omitempty" gorm:"-"`
+	Shared    bool      `form:"shared" json:"shared" validate:"omitempty" gorm:"type:BOOLEAN;NOT NULL;default:false"`
+	UserID    uint64    `form:"user_id" json:"user_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	CreatedAt time.Time `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (it *InputTemplate) TableName() string {
+	return "input_templates"
+}
+
+// Valid is function to control input/output data
+func (it InputTemplate) Valid() error {
+	return validate.Struct(it)
+}
+
+// Validate is function to use callback to control input/output data
+func (it InputTemplate) Validate(db *gorm.DB) {
+	if err := it.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// Placeholders returns unique names of the template placeholders in order of their first appearance
+func (it InputTemplate) Placeholders() []string {
+	names := make([]string, 0)
+	for _, match := range inputTemplatePlaceholderRegex.FindAllStringSubmatch(it.Template, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+
+	return names
+}
+
+// Render substitutes the variables into the template placeholders, it fails if any placeholder has no value
+// or the variable isn't used by the template so that typos don't produce the input with literal placeholders
+func (it InputTemplate) Render(vars map[string]string) (string, error) {
+	placeholders := it.Placeholders()
+
+	var missing, unknown []string
+	for _, name := range placeholders {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name := range vars {
+		if !slices.Contains(placeholders, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+
+	switch {
+	case len(missing) != 0:
+		return "", fmt.Errorf("missing values of input template variables: %s", strings.Join(missing, ", "))
+	case len(unknown) != 0:
+		return "", fmt.Errorf("unknown input template variables: %s", strings.Join(unknown, ", "))
+	}
+
+	return inputTemplatePlaceholderRegex.ReplaceAllStringFunc(it.Template, func(placeholder string) string {
+		return vars[inputTemplatePlaceholderRegex.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// SaveInputTemplate is model to contain input template creation and update payload
+// nolint:lll
+type SaveInputTemplate struct {
+	Name     string `form:"name" json:"name" validate:"required,max=100" example:"web recon"`
+	Template string `form:"template" json:"template" validate:"required,max=65536" example:"Enumerate subdomains of {{domain}} and check them for {{vuln_class}}"`
+	Shared   bool   `form:"shared" json:"shared" validate:"omitempty" default:"false"`
+}
+
+// Valid is function to control input/output data, the template mustn't contain broken placeholders
+// which would be left in the flow input as is
+func (sit SaveInputTemplate) Valid() error {
+	if err := validate.Struct(sit); err != nil {
+		return err
+	}
+
+	rest := inputTemplatePlaceholderRegex.ReplaceAllString(sit.Template, "")
+	if strings.Contains(rest, "{{") {
+		return errMalformedPlaceholder
+	}
+
+	return nil
+}
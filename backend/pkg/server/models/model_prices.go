@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ModelPrice is model to contain the price of the provider model effective since the time
+// nolint:lll
+type ModelPrice struct {
+	ID               uint64       `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Provider         ProviderType `form:"provider" json:"provider" validate:"valid,required" gorm:"type:TEXT;NOT NULL"`
+	Model            string       `form:"model" json:"model" validate:"required,max=200" gorm:"type:TEXT;NOT NULL"`
+	InputPricePer1K  float64      `form:"input_price_per_1k" json:"input_price_per_1k" validate:"min=0" gorm:"column:input_price_per_1k;type:DOUBLE PRECISION;NOT NULL"`
+	OutputPricePer1K float64      `form:"output_price_per_1k" json:"output_price_per_1k" validate:"min=0" gorm:"column:output_price_per_1k;type:DOUBLE PRECISION;NOT NULL"`
+	EffectiveFrom    time.Time    `form:"effective_from" json:"effective_from" validate:"required" gorm:"type:TIMESTAMPTZ;NOT NULL;default:CURRENT_TIMESTAMP"`
+	CreatedAt        time.Time    `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt        time.Time    `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (mp *ModelPrice) TableName() string {
+	return "model_prices"
+}
+
+// Valid is function to control input/output data
+func (mp ModelPrice) Valid() error {
+	return validate.Struct(mp)
+}
+
+// Validate is function to use callback to control input/output data
+func (mp ModelPrice) Validate(db *gorm.DB) {
+	if err := mp.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// SaveModelPrice is model to contain model price creation and update payload
+// nolint:lll
+type SaveModelPrice struct {
+	Provider         ProviderType `form:"provider" json:"provider" validate:"valid,required" example:"openai"`
+	Model            string       `form:"model" json:"model" validate:"required,max=200" example:"gpt-4.1"`
+	InputPricePer1K  float64      `form:"input_price_per_1k" json:"input_price_per_1k" validate:"min=0" example:"0.002"`
+	OutputPricePer1K float64      `form:"output_price_per_1k" json:"output_price_per_1k" validate:"min=0" example:"0.008"`
+	EffectiveFrom    *time.Time   `form:"effective_from,omitempty" json:"effective_from,omitempty" validate:"omitempty" example:"2026-01-01T00:00:00Z"`
+}
+
+// Valid is function to control input/output data
+func (smp SaveModelPrice) Valid() error {
+	return validate.Struct(smp)
+}
@@ -1,7 +1,9 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
@@ -65,3 +67,37 @@ func (ml MsgchainType) Validate(db *gorm.DB) {
 		db.AddError(err)
 	}
 }
+
+// Msgchain is model to contain LLM conversation chain of the agent
+// nolint:lll
+type Msgchain struct {
+	ID            uint64          `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	Type          MsgchainType    `form:"type" json:"type" validate:"valid,required" gorm:"type:MSGCHAIN_TYPE;NOT NULL;default:primary_agent"`
+	Model         string          `form:"model" json:"model" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
+	ModelProvider string          `form:"model_provider" json:"model_provider" validate:"max=70,required" gorm:"type:TEXT;NOT NULL"`
+	UsageIn       uint64          `form:"usage_in" json:"usage_in" validate:"min=0" gorm:"type:BIGINT;NOT NULL;default:0"`
+	UsageOut      uint64          `form:"usage_out" json:"usage_out" validate:"min=0" gorm:"type:BIGINT;NOT NULL;default:0"`
+	Chain         json.RawMessage `form:"chain" json:"chain" validate:"required" gorm:"type:JSON;NOT NULL"`
+	FlowID        uint64          `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	TaskID        *uint64         `form:"task_id,omitempty" json:"task_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT;NULL"`
+	SubtaskID     *uint64         `form:"subtask_id,omitempty" json:"subtask_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT;NULL"`
+	CreatedAt     time.Time       `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time       `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (mc *Msgchain) TableName() string {
+	return "msgchains"
+}
+
+// Valid is function to control input/output data
+func (mc Msgchain) Valid() error {
+	return validate.Struct(mc)
+}
+
+// Validate is function to use callback to control input/output data
+func (mc Msgchain) Validate(db *gorm.DB) {
+	if err := mc.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
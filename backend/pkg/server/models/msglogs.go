@@ -109,3 +109,37 @@ func (ml Msglog) Validate(db *gorm.DB) {
 		db.AddError(err)
 	}
 }
+
+// FlowMessagesQuery is model to contain paging params of flow messages list
+// nolint:lll
+type FlowMessagesQuery struct {
+	// Number of messages to skip from the beginning of the flow conversation
+	Offset int `form:"offset" json:"offset" binding:"min=0" default:"0" minimum:"0"`
+	// Amount of messages to return (max 1000)
+	Limit int `form:"limit" json:"limit" binding:"omitempty,min=1,max=1000" default:"100" minimum:"1" maximum:"1000"`
+	// Maximum length of message content, thinking and tool result (0 means unlimited)
+	MaxContent int `form:"max_content" json:"max_content" binding:"min=0" default:"0" minimum:"0"`
+}
+
+// FlowMessage is model to contain single message of the flow conversation which is built from the message log,
+// role is "human" for the user input, "tool" for the agent actions with the tool result and "ai" for the rest
+// nolint:lll
+type FlowMessage struct {
+	ID           uint64             `form:"id" json:"id" validate:"min=0,numeric"`
+	Type         MsglogType         `form:"type" json:"type" validate:"valid,required"`
+	Role         string             `form:"role" json:"role" validate:"required,oneof=human ai tool" example:"tool"`
+	Content      string             `form:"content" json:"content" validate:"omitempty"`
+	Thinking     string             `form:"thinking,omitempty" json:"thinking,omitempty" validate:"omitempty"`
+	Result       string             `form:"result,omitempty" json:"result,omitempty" validate:"omitempty"`
+	ResultFormat MsglogResultFormat `form:"result_format" json:"result_format" validate:"valid,required"`
+	Truncated    bool               `form:"truncated" json:"truncated"`
+	FlowID       uint64             `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required"`
+	TaskID       *uint64            `form:"task_id,omitempty" json:"task_id,omitempty" validate:"omitnil,min=0"`
+	SubtaskID    *uint64            `form:"subtask_id,omitempty" json:"subtask_id,omitempty" validate:"omitnil,min=0"`
+	CreatedAt    time.Time          `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty"`
+}
+
+// Valid is function to control input/output data
+func (fm FlowMessage) Valid() error {
+	return validate.Struct(fm)
+}
@@ -107,3 +107,55 @@ type ProviderInfo struct {
 func (p ProviderInfo) Valid() error {
 	return validate.Struct(p)
 }
+
+// ProviderModel is model to contain information about the model available in the provider
+// nolint:lll
+type ProviderModel struct {
+	Name          string     `form:"name" json:"name" validate:"required" example:"gpt-4.1"`
+	Description   *string    `form:"description,omitempty" json:"description,omitempty" validate:"omitnil" example:"flagship model"`
+	ReleaseDate   *time.Time `form:"release_date,omitempty" json:"release_date,omitempty" validate:"omitnil"`
+	Thinking      *bool      `form:"thinking,omitempty" json:"thinking,omitempty" validate:"omitnil" example:"false"`
+	ContextWindow *int       `form:"context_window,omitempty" json:"context_window,omitempty" validate:"omitnil,min=1" example:"1047576"`
+	Tools         *bool      `form:"tools,omitempty" json:"tools,omitempty" validate:"omitnil" example:"true"`
+}
+
+// Valid is function to control input/output data
+func (p ProviderModel) Valid() error {
+	return validate.Struct(p)
+}
+
+// ProviderHealth is model to contain provider live probe result
+// nolint:lll
+type ProviderHealth struct {
+	Name      string       `form:"name" json:"name" validate:"required" example:"my openai provider"`
+	Type      ProviderType `form:"type" json:"type" validate:"valid,required" example:"openai"`
+	Status    string       `form:"status" json:"status" validate:"oneof=ok error,required" example:"ok"`
+	LatencyMs int64        `form:"latency_ms" json:"latency_ms" validate:"min=0" example:"420"`
+	Error     string       `form:"error,omitempty" json:"error,omitempty" validate:"omitempty" example:"401 unauthorized"`
+	Cached    bool         `form:"cached" json:"cached" example:"false"`
+	CheckedAt time.Time    `form:"checked_at" json:"checked_at" validate:"required"`
+}
+
+// Valid is function to control input/output data
+func (p ProviderHealth) Valid() error {
+	return validate.Struct(p)
+}
+
+// ProviderRateLimit is model to contain current state of the provider model rate limiter
+// nolint:lll
+type ProviderRateLimit struct {
+	Name              string    `form:"name" json:"name" validate:"required" example:"my openai provider"`
+	Model             string    `form:"model" json:"model" validate:"required" example:"gpt-4.1"`
+	RequestsLimit     int       `form:"requests_limit" json:"requests_limit" validate:"min=0" example:"60"`
+	RequestsAvailable float64   `form:"requests_available" json:"requests_available" example:"42.5"`
+	TokensLimit       int       `form:"tokens_limit" json:"tokens_limit" validate:"min=0" example:"100000"`
+	TokensAvailable   float64   `form:"tokens_available" json:"tokens_available" example:"81234"`
+	Waiting           int       `form:"waiting" json:"waiting" validate:"min=0" example:"0"`
+	Rejected          int64     `form:"rejected" json:"rejected" validate:"min=0" example:"0"`
+	UpdatedAt         time.Time `form:"updated_at" json:"updated_at" validate:"required"`
+}
+
+// Valid is function to control input/output data
+func (p ProviderRateLimit) Valid() error {
+	return validate.Struct(p)
+}
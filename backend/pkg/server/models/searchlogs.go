@@ -10,13 +10,25 @@ import (
 type SearchEngineType string
 
 const (
-	SearchEngineTypeGoogle     SearchEngineType = "google"
-	SearchEngineTypeDuckduckgo SearchEngineType = "duckduckgo"
-	SearchEngineTypeTavily     SearchEngineType = "tavily"
-	SearchEngineTypeTraversaal SearchEngineType = "traversaal"
-	SearchEngineTypePerplexity SearchEngineType = "perplexity"
-	SearchEngineTypeBrowser    SearchEngineType = "browser"
-	SearchEngineTypeSploitus   SearchEngineType = "sploitus"
+	SearchEngineTypeGoogle        SearchEngineType = "google"
+	SearchEngineTypeDuckduckgo    SearchEngineType = "duckduckgo"
+	SearchEngineTypeTavily        SearchEngineType = "tavily"
+	SearchEngineTypeTraversaal    SearchEngineType = "traversaal"
+	SearchEngineTypePerplexity    SearchEngineType = "perplexity"
+	SearchEngineTypeBrowser       SearchEngineType = "browser"
+	SearchEngineTypeSploitus      SearchEngineType = "sploitus"
+	SearchEngineTypeGithub        SearchEngineType = "github"
+	SearchEngineTypeExploitDB     SearchEngineType = "exploitdb"
+	SearchEngineTypeExploitSearch SearchEngineType = "exploit_search"
+	SearchEngineTypeVirusTotal    SearchEngineType = "virustotal"
+	SearchEngineTypeWayback       SearchEngineType = "wayback"
+	SearchEngineTypeWhois         SearchEngineType = "whois"
+	SearchEngineTypeEPSS          SearchEngineType = "epss"
+	SearchEngineTypeRobots        SearchEngineType = "robots"
+	SearchEngineTypeHTTPHeaders   SearchEngineType = "http_headers"
+	SearchEngineTypeTLSInspect    SearchEngineType = "tls_inspect"
+	SearchEngineTypePortScan      SearchEngineType = "port_scan"
+	SearchEngineTypeHTTPFuzz      SearchEngineType = "http_fuzz"
 )
 
 func (s SearchEngineType) String() string {
@@ -32,7 +44,19 @@ func (s SearchEngineType) Valid() error {
 		SearchEngineTypeTraversaal,
 		SearchEngineTypePerplexity,
 		SearchEngineTypeBrowser,
-		SearchEngineTypeSploitus:
+		SearchEngineTypeSploitus,
+		SearchEngineTypeGithub,
+		SearchEngineTypeExploitDB,
+		SearchEngineTypeExploitSearch,
+		SearchEngineTypeVirusTotal,
+		SearchEngineTypeWayback,
+		SearchEngineTypeWhois,
+		SearchEngineTypeEPSS,
+		SearchEngineTypeRobots,
+		SearchEngineTypeHTTPHeaders,
+		SearchEngineTypeTLSInspect,
+		SearchEngineTypePortScan,
+		SearchEngineTypeHTTPFuzz:
 		return nil
 	default:
 		return fmt.Errorf("invalid SearchEngineType: %s", s)
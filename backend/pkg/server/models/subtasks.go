@@ -35,6 +35,11 @@ func (s SubtaskStatus) Valid() error {
 	}
 }
 
+// Completed returns true if the subtask won't be run anymore, failed subtasks are completed too
+func (s SubtaskStatus) Completed() bool {
+	return s == SubtaskStatusFinished || s == SubtaskStatusFailed
+}
+
 // Validate is function to use callback to control input/output data
 func (s SubtaskStatus) Validate(db *gorm.DB) {
 	if err := s.Valid(); err != nil {
@@ -52,6 +57,7 @@ type Subtask struct {
 	Context     string        `form:"context" json:"context" validate:"omitempty" gorm:"type:TEXT;NOT NULL;default:''"`
 	Result      string        `form:"result" json:"result" validate:"omitempty" gorm:"type:TEXT;NOT NULL;default:''"`
 	TaskID      uint64        `form:"task_id" json:"task_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	Priority    int32         `form:"priority" json:"priority" validate:"omitempty" gorm:"type:INTEGER;NOT NULL;default:0"`
 	CreatedAt   time.Time     `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
 	UpdatedAt   time.Time     `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
 }
@@ -72,3 +78,14 @@ func (s Subtask) Validate(db *gorm.DB) {
 		db.AddError(err)
 	}
 }
+
+// PrioritizeSubtask is model to contain the new order of the planned subtask
+// nolint:lll
+type PrioritizeSubtask struct {
+	Priority *int32 `form:"priority,omitempty" json:"priority,omitempty" validate:"omitnil,min=-1000,max=1000" example:"10"`
+}
+
+// Valid is function to control input/output data
+func (ps PrioritizeSubtask) Valid() error {
+	return validate.Struct(ps)
+}
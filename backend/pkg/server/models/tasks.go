@@ -76,6 +76,7 @@ func (t Task) Validate(db *gorm.DB) {
 // nolint:lll
 type TaskSubtasks struct {
 	Subtasks []Subtask `form:"subtasks" json:"subtasks" validate:"required" gorm:"foreignkey:TaskID;association_autoupdate:false;association_autocreate:false"`
+	Progress *float64  `form:"progress,omitempty" json:"progress,omitempty" validate:"omitnil,min=0,max=100" gorm:"-" example:"50"`
 	Task     `form:"" json:""`
 }
 
@@ -94,9 +95,53 @@ func (ts TaskSubtasks) Valid() error {
 	return ts.Task.Valid()
 }
 
+// SubtasksProgress returns the number of completed subtasks and the total number of them
+func (ts TaskSubtasks) SubtasksProgress() (int, int) {
+	var completed int
+	for _, subtask := range ts.Subtasks {
+		if subtask.Status.Completed() {
+			completed++
+		}
+	}
+
+	return completed, len(ts.Subtasks)
+}
+
 // Validate is function to use callback to control input/output data
 func (ts TaskSubtasks) Validate(db *gorm.DB) {
 	if err := ts.Valid(); err != nil {
 		db.AddError(err)
 	}
 }
+
+// TasksSearchQuery is model to contain params of the tasks and subtasks full-text search
+// nolint:lll
+type TasksSearchQuery struct {
+	// Text to search in titles, descriptions and results of tasks and subtasks (case insensitive)
+	Query string `form:"q" json:"q" binding:"required,min=2,max=256" minLength:"2" maxLength:"256"`
+	// Restrict the search to a single flow
+	FlowID *uint64 `form:"flow_id" json:"flow_id,omitempty" binding:"omitempty,min=0" minimum:"0"`
+	// Number of page (since 1)
+	Page int `form:"page" json:"page" binding:"omitempty,min=1" default:"1" minimum:"1"`
+	// Amount items per page (max 100)
+	Size int `form:"pageSize" json:"pageSize" binding:"omitempty,min=1,max=100" default:"20" minimum:"1" maximum:"100"`
+}
+
+// TaskSearchMatch is model to contain a single task or subtask matched by the search
+// nolint:lll
+type TaskSearchMatch struct {
+	Type      string    `form:"type" json:"type" validate:"oneof=task subtask,required" example:"subtask"`
+	ID        uint64    `form:"id" json:"id" validate:"min=0,numeric"`
+	TaskID    uint64    `form:"task_id" json:"task_id" validate:"min=0,numeric"`
+	SubtaskID *uint64   `form:"subtask_id,omitempty" json:"subtask_id,omitempty" validate:"omitnil,min=0"`
+	FlowID    uint64    `form:"flow_id" json:"flow_id" validate:"min=0,numeric"`
+	Title     string    `form:"title" json:"title" validate:"omitempty"`
+	Status    string    `form:"status" json:"status" validate:"required"`
+	Snippet   string    `form:"snippet" json:"snippet" validate:"omitempty"`
+	UpdatedAt time.Time `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty"`
+}
+
+// Valid is function to control input/output data
+func (m TaskSearchMatch) Valid() error {
+	return validate.Struct(m)
+}
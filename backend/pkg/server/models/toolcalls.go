@@ -0,0 +1,118 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type ToolcallStatus string
+
+const (
+	ToolcallStatusReceived ToolcallStatus = "received"
+	ToolcallStatusRunning  ToolcallStatus = "running"
+	ToolcallStatusFinished ToolcallStatus = "finished"
+	ToolcallStatusFailed   ToolcallStatus = "failed"
+)
+
+func (s ToolcallStatus) String() string {
+	return string(s)
+}
+
+// Valid is function to control input/output data
+func (s ToolcallStatus) Valid() error {
+	switch s {
+	case ToolcallStatusReceived,
+		ToolcallStatusRunning,
+		ToolcallStatusFinished,
+		ToolcallStatusFailed:
+		return nil
+	default:
+		return fmt.Errorf("invalid ToolcallStatus: %s", s)
+	}
+}
+
+// Validate is function to use callback to control input/output data
+func (s ToolcallStatus) Validate(db *gorm.DB) {
+	if err := s.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// ToolcallArgs is model to contain arguments of the tool call stored as JSON object
+type ToolcallArgs map[string]any
+
+// Value implements driver.Valuer interface for database write
+func (ta ToolcallArgs) Value() (driver.Value, error) {
+	if ta == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]any(ta))
+}
+
+// Scan implements sql.Scanner interface for database read
+func (ta *ToolcallArgs) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, ta)
+	case string:
+		return json.Unmarshal([]byte(v), ta)
+	default:
+		return fmt.Errorf("failed to scan ToolcallArgs: expected []byte, got %T", value)
+	}
+}
+
+// Toolcall is model to contain the tool call made by the agent, the result isn't included
+// nolint:lll
+type Toolcall struct {
+	ID              uint64         `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	CallID          string         `form:"call_id" json:"call_id" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Status          ToolcallStatus `form:"status" json:"status" validate:"valid,required" gorm:"type:TOOLCALL_STATUS;NOT NULL;default:'received'"`
+	Name            string         `form:"name" json:"name" validate:"required" gorm:"type:TEXT;NOT NULL"`
+	Args            ToolcallArgs   `form:"args" json:"args" validate:"omitempty" gorm:"type:JSON;NOT NULL"`
+	FlowID          uint64         `form:"flow_id" json:"flow_id" validate:"min=0,numeric,required" gorm:"type:BIGINT;NOT NULL"`
+	TaskID          *uint64        `form:"task_id,omitempty" json:"task_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT"`
+	SubtaskID       *uint64        `form:"subtask_id,omitempty" json:"subtask_id,omitempty" validate:"omitnil,min=0" gorm:"type:BIGINT"`
+	DurationSeconds float64        `form:"duration_seconds" json:"duration_seconds" validate:"min=0" gorm:"type:DOUBLE PRECISION;NOT NULL;default:0"`
+	CreatedAt       time.Time      `form:"created_at,omitempty" json:"created_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+	UpdatedAt       time.Time      `form:"updated_at,omitempty" json:"updated_at,omitempty" validate:"omitempty" gorm:"type:TIMESTAMPTZ;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (tc *Toolcall) TableName() string {
+	return "toolcalls"
+}
+
+// Valid is function to control input/output data
+func (tc Toolcall) Valid() error {
+	return validate.Struct(tc)
+}
+
+// Validate is function to use callback to control input/output data
+func (tc Toolcall) Validate(db *gorm.DB) {
+	if err := tc.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// ToolcallGroup is model to contain the repeated identical tool calls of the subtask,
+// the calls are identical if they have the same name and the same (redacted) arguments
+// nolint:lll
+type ToolcallGroup struct {
+	Name            string       `json:"name" validate:"required"`
+	Args            ToolcallArgs `json:"args" validate:"omitempty"`
+	Count           uint64       `json:"count" validate:"min=1"`
+	Finished        uint64       `json:"finished" validate:"min=0"`
+	Failed          uint64       `json:"failed" validate:"min=0"`
+	DurationSeconds float64      `json:"duration_seconds" validate:"min=0"`
+	FirstCalledAt   time.Time    `json:"first_called_at"`
+	LastCalledAt    time.Time    `json:"last_called_at"`
+}
+
+// Valid is function to control input/output data
+func (tg ToolcallGroup) Valid() error {
+	return validate.Struct(tg)
+}
@@ -233,6 +233,22 @@ func (urp UserRolePrivileges) Validate(db *gorm.DB) {
 	}
 }
 
+// UserPermissions is model to contain privileges of the current user and capabilities derived from them
+// nolint:lll
+type UserPermissions struct {
+	UserID       uint64          `form:"user_id" json:"user_id" validate:"min=0,numeric"`
+	RoleID       uint64          `form:"role_id" json:"role_id" validate:"min=0,numeric"`
+	RoleName     string          `form:"role_name" json:"role_name" validate:"max=50,required"`
+	Type         UserType        `form:"type" json:"type" validate:"valid,required"`
+	Privileges   []string        `form:"privileges" json:"privileges" validate:"required,dive,max=70"`
+	Capabilities map[string]bool `form:"capabilities" json:"capabilities" validate:"required"`
+}
+
+// Valid is function to control input/output data
+func (up UserPermissions) Valid() error {
+	return validate.Struct(up)
+}
+
 // UserPreferencesOptions is model to contain user preferences as JSON
 type UserPreferencesOptions struct {
 	FavoriteFlows []int64 `json:"favoriteFlows"`
@@ -0,0 +1,156 @@
+package router
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/server/auth"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// flow route groups limited by the API rate limiter, they are the keys of API_RATE_LIMITS
+const (
+	apiRateLimitGroupFlows      = "flows"
+	apiRateLimitGroupTasks      = "tasks"
+	apiRateLimitGroupSubtasks   = "subtasks"
+	apiRateLimitGroupAssistants = "assistants"
+	apiRateLimitGroupLogs       = "logs"
+)
+
+const (
+	// apiRateLimitAdminPrivilege moves the user to the admin tier of the limits
+	apiRateLimitAdminPrivilege = "flows.admin"
+	// apiRateLimitAdminSuffix is added to the route group name to override the limit of the admin tier
+	apiRateLimitAdminSuffix = ".admin"
+	// apiRateLimitSweepInterval is the interval to drop the buckets of the idle users
+	apiRateLimitSweepInterval = 10 * time.Minute
+)
+
+type apiRateLimitKey struct {
+	uid   uint64
+	group string
+}
+
+type apiRateLimitBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// apiRateLimiter is a token-bucket limiter of the API requests per minute of the user in the route group,
+// the bucket holds the requests of the whole minute and it's refilled continuously
+type apiRateLimiter struct {
+	mx       *sync.Mutex
+	userRPM  int
+	adminRPM int
+	groups   map[string]int
+	buckets  map[apiRateLimitKey]*apiRateLimitBucket
+	swept    time.Time
+	now      func() time.Time
+}
+
+func newAPIRateLimiter(cfg *config.Config) *apiRateLimiter {
+	groups := make(map[string]int, len(cfg.APIRateLimits))
+	for group, rpm := range cfg.APIRateLimits {
+		groups[group] = max(rpm, 0)
+	}
+
+	return &apiRateLimiter{
+		mx:       &sync.Mutex{},
+		userRPM:  max(cfg.APIRateLimitRPM, 0),
+		adminRPM: max(cfg.APIRateLimitAdminRPM, 0),
+		groups:   groups,
+		buckets:  make(map[apiRateLimitKey]*apiRateLimitBucket),
+		swept:    time.Now(),
+		now:      time.Now,
+	}
+}
+
+// limit returns the requests per minute of the route group for the tier, zero means no limit
+func (rl *apiRateLimiter) limit(group string, admin bool) int {
+	key, rpm := group, rl.userRPM
+	if admin {
+		key, rpm = group+apiRateLimitAdminSuffix, rl.adminRPM
+	}
+
+	if groupRPM, ok := rl.groups[key]; ok {
+		return groupRPM
+	}
+
+	return rpm
+}
+
+// allow takes one request from the bucket of the key or returns the time to wait for the next one
+func (rl *apiRateLimiter) allow(key apiRateLimitKey, rpm int) (bool, time.Duration) {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &apiRateLimitBucket{tokens: float64(rpm), updated: now}
+		rl.buckets[key] = b
+	} else if elapsed := now.Sub(b.updated).Minutes(); elapsed > 0 {
+		b.tokens = math.Min(float64(rpm), b.tokens+elapsed*float64(rpm))
+		b.updated = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / float64(rpm) * float64(time.Minute))
+	return false, wait
+}
+
+// sweep drops the buckets which are refilled up to the limit, they are equal to the new ones,
+// it must be called under the lock
+func (rl *apiRateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.swept) < apiRateLimitSweepInterval {
+		return
+	}
+
+	rl.swept = now
+	for key, b := range rl.buckets {
+		if now.Sub(b.updated) >= time.Minute {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// middleware limits the requests of the authorized user to the route group, the exceeded request
+// is rejected with 429 and Retry-After header in seconds, it must be used after the auth middleware
+func (rl *apiRateLimiter) middleware(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.IsAborted() {
+			return
+		}
+
+		uid := c.GetUint64("uid")
+		admin := auth.LookupPerm(c.GetStringSlice("prm"), apiRateLimitAdminPrivilege)
+		rpm := rl.limit(group, admin)
+		if uid == 0 || rpm == 0 {
+			c.Next()
+			return
+		}
+
+		allowed, wait := rl.allow(apiRateLimitKey{uid: uid, group: group}, rpm)
+		if !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			err := fmt.Errorf("user %d exceeded %d requests per minute of '%s' routes", uid, rpm, group)
+			response.Error(c, response.ErrTooManyRequests, err)
+			return
+		}
+
+		c.Next()
+	}
+}
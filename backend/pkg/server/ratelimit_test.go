@@ -0,0 +1,156 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestAPIRateLimiter(cfg *config.Config) (*apiRateLimiter, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)}
+	rl := newAPIRateLimiter(cfg)
+	rl.now = clock.Now
+	rl.swept = clock.now
+	return rl, clock
+}
+
+// newRateLimitTestRouter emulates the auth middleware by the uid and prm headers
+func newRateLimitTestRouter(rl *apiRateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if uid := c.GetHeader("X-Test-Uid"); uid != "" {
+			c.Set("uid", map[string]uint64{"1": 1, "2": 2}[uid])
+		}
+		if prm := c.GetHeader("X-Test-Prm"); prm != "" {
+			c.Set("prm", []string{prm})
+		}
+		c.Next()
+	})
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.Group("/", rl.middleware(apiRateLimitGroupFlows)).GET("/flows/", ok)
+	router.Group("/", rl.middleware(apiRateLimitGroupLogs)).GET("/flows/:flowID/msglogs/", ok)
+
+	return router
+}
+
+func doRateLimitRequest(router *gin.Engine, path, uid, prm string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Test-Uid", uid)
+	req.Header.Set("X-Test-Prm", prm)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIRateLimitMiddleware(t *testing.T) {
+	rl, clock := newTestAPIRateLimiter(&config.Config{APIRateLimitRPM: 3})
+	router := newRateLimitTestRouter(rl)
+
+	for i := 0; i < 3; i++ {
+		w := doRateLimitRequest(router, "/flows/", "1", "flows.view")
+		require.Equal(t, http.StatusOK, w.Code, "request %d", i)
+	}
+
+	w := doRateLimitRequest(router, "/flows/", "1", "flows.view")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"error_code":"too_many_requests"`)
+
+	// another user and another route group have their own buckets
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "2", "flows.view").Code)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/1/msglogs/", "1", "msglogs.view").Code)
+
+	// the bucket is refilled by one request per 20 seconds
+	clock.now = clock.now.Add(15 * time.Second)
+	w = doRateLimitRequest(router, "/flows/", "1", "flows.view")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "1", "flows.view").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "/flows/", "1", "flows.view").Code)
+
+	// the bucket is never refilled over the limit
+	clock.now = clock.now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "1", "flows.view").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "/flows/", "1", "flows.view").Code)
+}
+
+func TestAPIRateLimitTiers(t *testing.T) {
+	rl, _ := newTestAPIRateLimiter(&config.Config{
+		APIRateLimitRPM:      1,
+		APIRateLimitAdminRPM: 0,
+		APIRateLimits:        map[string]int{"logs": 2, "logs.admin": 4},
+	})
+	router := newRateLimitTestRouter(rl)
+
+	// the admin tier has no limit of the flows and its own limit of the logs
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "1", "flows.admin").Code)
+	}
+	for i := 0; i < 4; i++ {
+		require.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/1/msglogs/", "1", "flows.admin").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "/flows/1/msglogs/", "1", "flows.admin").Code)
+
+	// the user tier uses the override of the logs and the default limit of the flows
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/1/msglogs/", "2", "flows.view").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "/flows/1/msglogs/", "2", "flows.view").Code)
+	assert.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "2", "flows.view").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRateLimitRequest(router, "/flows/", "2", "flows.view").Code)
+}
+
+func TestAPIRateLimitDisabled(t *testing.T) {
+	rl, _ := newTestAPIRateLimiter(&config.Config{APIRateLimits: map[string]int{"flows": -5}})
+	router := newRateLimitTestRouter(rl)
+
+	for i := 0; i < 100; i++ {
+		require.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "1", "flows.view").Code)
+	}
+
+	// the request without the user isn't limited, the auth middleware rejects it before
+	rl, _ = newTestAPIRateLimiter(&config.Config{APIRateLimitRPM: 1})
+	router = newRateLimitTestRouter(rl)
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, doRateLimitRequest(router, "/flows/", "", "").Code)
+	}
+}
+
+func TestAPIRateLimitSweep(t *testing.T) {
+	rl, clock := newTestAPIRateLimiter(&config.Config{APIRateLimitRPM: 2})
+
+	allowed, _ := rl.allow(apiRateLimitKey{uid: 1, group: apiRateLimitGroupFlows}, 2)
+	require.True(t, allowed)
+	clock.now = clock.now.Add(apiRateLimitSweepInterval - time.Second)
+	allowed, _ = rl.allow(apiRateLimitKey{uid: 2, group: apiRateLimitGroupFlows}, 2)
+	require.True(t, allowed)
+	require.Len(t, rl.buckets, 2)
+
+	// the idle bucket is dropped, the recent one is kept
+	clock.now = clock.now.Add(time.Second)
+	allowed, _ = rl.allow(apiRateLimitKey{uid: 3, group: apiRateLimitGroupFlows}, 2)
+	require.True(t, allowed)
+	assert.Len(t, rl.buckets, 2)
+	assert.NotContains(t, rl.buckets, apiRateLimitKey{uid: 1, group: apiRateLimitGroupFlows})
+}
@@ -2,6 +2,7 @@ package rdb
 
 import (
 	"crypto/md5" //nolint:gosec
+	"database/sql"
 	"encoding/hex"
 	"errors"
 	"slices"
@@ -310,6 +311,15 @@ func (q *TableQuery) Query(db *gorm.DB, result any,
 	return uint64(total), err
 }
 
+// Rows is function to iterate over table data according with input params without grouping and counting
+func (q *TableQuery) Rows(db *gorm.DB, funcs ...func(*gorm.DB) *gorm.DB) (*sql.Rows, error) {
+	return ApplyToChainDB(
+		ApplyToChainDB(db.Table(q.Table()), funcs...).Scopes(q.DataFilter()),
+		q.Ordering(),
+		q.Paginate(),
+	).Rows()
+}
+
 // QueryGrouped is function to retrieve grouped data according with input params
 func (q *TableQuery) QueryGrouped(db *gorm.DB, result any,
 	funcs ...func(*gorm.DB) *gorm.DB) (uint64, error) {
@@ -0,0 +1,318 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"pentagi/pkg/server/models"
+)
+
+//go:embed templates/*.tmpl
+var reportTemplates embed.FS
+
+// Format is the output format of the flow report
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+)
+
+// ContentType returns the HTTP content type of the report format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// Valid is function to control input/output data
+func (f Format) Valid() error {
+	switch f {
+	case FormatMarkdown, FormatHTML:
+		return nil
+	default:
+		return fmt.Errorf("invalid report Format: %s", f)
+	}
+}
+
+const (
+	defMaxOutputLen      = 4000
+	defCollapseOutputLen = 600
+	summaryLeadLen       = 300
+)
+
+var (
+	cveRegexp = regexp.MustCompile(`\bCVE-\d{4}-\d{4,7}\b`)
+	urlRegexp = regexp.MustCompile(`https?://[^\s<>"'()\[\]{}]+`)
+)
+
+// exploitHosts are the sources of the exploits and advisories to list in the report references
+var exploitHosts = []string{
+	"exploit-db.com",
+	"packetstormsecurity.com",
+	"sploitus.com",
+	"github.com",
+	"gitlab.com",
+	"rapid7.com",
+	"vulners.com",
+	"nvd.nist.gov",
+	"cve.mitre.org",
+}
+
+// Options is the report rendering options
+type Options struct {
+	// BaseURL is the API prefix to link the full task and subtask results from the truncated ones
+	BaseURL string
+	// MaxOutputLen is the max length of the result in the report, longer ones are truncated
+	MaxOutputLen int
+}
+
+// Output is the task or subtask text which can be collapsed or truncated in the report
+type Output struct {
+	Text      string
+	Collapsed bool
+	Truncated bool
+	Link      string
+}
+
+// Subtask is the subtask section of the report
+type Subtask struct {
+	ID          uint64
+	Title       string
+	Status      string
+	Description string
+	Result      Output
+}
+
+// Task is the per-task findings section of the report
+type Task struct {
+	ID       uint64
+	Title    string
+	Status   string
+	Input    Output
+	Result   Output
+	Subtasks []Subtask
+}
+
+// Highlight is the lead of the task result in the executive summary
+type Highlight struct {
+	Title  string
+	Status string
+	Lead   string
+}
+
+// StatusCount is the amount of tasks having the status
+type StatusCount struct {
+	Status string
+	Count  int
+}
+
+// Summary is the executive summary section of the report
+type Summary struct {
+	Tasks      int
+	Subtasks   int
+	Statuses   []StatusCount
+	Highlights []Highlight
+}
+
+// Reference is the vulnerability or exploit reference found in the flow results
+type Reference struct {
+	Name string
+	URL  string
+}
+
+// Report is the pentest report assembled from the flow graph
+type Report struct {
+	Flow        models.Flow
+	GeneratedAt time.Time
+	Summary     Summary
+	Tasks       []Task
+	References  []Reference
+}
+
+// New assembles the report from the flow with its tasks and subtasks
+func New(flow models.FlowTasksSubtasks, opts Options) Report {
+	if opts.MaxOutputLen <= 0 {
+		opts.MaxOutputLen = defMaxOutputLen
+	}
+
+	report := Report{
+		Flow:        flow.Flow,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	refs := newReferences()
+	statuses := map[string]int{}
+	for _, task := range flow.Tasks {
+		status := task.Status.String()
+		if _, ok := statuses[status]; !ok {
+			report.Summary.Statuses = append(report.Summary.Statuses, StatusCount{Status: status})
+		}
+		statuses[status]++
+
+		taskLink := fmt.Sprintf("%s/flows/%d/tasks/%d", opts.BaseURL, flow.ID, task.ID)
+		reportTask := Task{
+			ID:     task.ID,
+			Title:  task.Title,
+			Status: status,
+			Input:  newOutput(task.Input, taskLink, opts.MaxOutputLen),
+			Result: newOutput(task.Result, taskLink, opts.MaxOutputLen),
+		}
+		refs.add(task.Input, task.Result)
+
+		for _, subtask := range task.Subtasks {
+			subtaskLink := fmt.Sprintf("%s/subtasks/%d", taskLink, subtask.ID)
+			reportTask.Subtasks = append(reportTask.Subtasks, Subtask{
+				ID:          subtask.ID,
+				Title:       subtask.Title,
+				Status:      subtask.Status.String(),
+				Description: subtask.Description,
+				Result:      newOutput(subtask.Result, subtaskLink, opts.MaxOutputLen),
+			})
+			refs.add(subtask.Description, subtask.Result)
+		}
+
+		report.Tasks = append(report.Tasks, reportTask)
+		report.Summary.Tasks++
+		report.Summary.Subtasks += len(task.Subtasks)
+		report.Summary.Highlights = append(report.Summary.Highlights, Highlight{
+			Title:  task.Title,
+			Status: status,
+			Lead:   leadParagraph(task.Result, summaryLeadLen),
+		})
+	}
+
+	for i := range report.Summary.Statuses {
+		report.Summary.Statuses[i].Count = statuses[report.Summary.Statuses[i].Status]
+	}
+	report.References = refs.list
+
+	return report
+}
+
+// Render writes the report in the format, HTML one is self-contained with inline styles
+func Render(w io.Writer, format Format, report Report) error {
+	switch format {
+	case FormatMarkdown:
+		tmpl, err := texttemplate.New("report.md.tmpl").
+			Funcs(texttemplate.FuncMap{"inc": inc, "oneline": oneline}).
+			ParseFS(reportTemplates, "templates/report.md.tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to parse markdown report template: %w", err)
+		}
+		return tmpl.Execute(w, report)
+	case FormatHTML:
+		tmpl, err := htmltemplate.New("report.html.tmpl").
+			Funcs(htmltemplate.FuncMap{"inc": inc}).
+			ParseFS(reportTemplates, "templates/report.html.tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to parse html report template: %w", err)
+		}
+		return tmpl.Execute(w, report)
+	default:
+		return fmt.Errorf("invalid report Format: %s", format)
+	}
+}
+
+func newOutput(text, link string, maxLen int) Output {
+	text = strings.TrimSpace(text)
+	output := Output{
+		Text:      text,
+		Collapsed: utf8.RuneCountInString(text) > defCollapseOutputLen,
+		Link:      link,
+	}
+
+	if utf8.RuneCountInString(text) > maxLen {
+		output.Text = string([]rune(text)[:maxLen])
+		output.Truncated = true
+		// code block cut in the middle must be closed to keep the rest of the report intact
+		if strings.Count(output.Text, "```")%2 == 1 {
+			output.Text += "\n```"
+		}
+	}
+
+	return output
+}
+
+// leadParagraph returns the first non-empty paragraph of the text limited by length
+func leadParagraph(text string, maxLen int) string {
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		paragraph = oneline(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if utf8.RuneCountInString(paragraph) > maxLen {
+			return string([]rune(paragraph)[:maxLen]) + "..."
+		}
+		return paragraph
+	}
+
+	return ""
+}
+
+type references struct {
+	seen map[string]struct{}
+	list []Reference
+}
+
+func newReferences() *references {
+	return &references{seen: map[string]struct{}{}}
+}
+
+// add collects CVE identifiers and links to the known exploit sources in order of appearance
+func (r *references) add(texts ...string) {
+	for _, text := range texts {
+		for _, cve := range cveRegexp.FindAllString(text, -1) {
+			r.push(Reference{Name: cve, URL: "https://nvd.nist.gov/vuln/detail/" + cve})
+		}
+		for _, url := range urlRegexp.FindAllString(text, -1) {
+			url = strings.TrimRight(url, ".,;:!?`*")
+			if isExploitURL(url) {
+				r.push(Reference{Name: url, URL: url})
+			}
+		}
+	}
+}
+
+func (r *references) push(ref Reference) {
+	if _, ok := r.seen[ref.URL]; ok {
+		return
+	}
+	r.seen[ref.URL] = struct{}{}
+	r.list = append(r.list, ref)
+}
+
+func isExploitURL(url string) bool {
+	host := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	if idx := strings.IndexAny(host, "/?#"); idx != -1 {
+		host = host[:idx]
+	}
+	host = strings.ToLower(host)
+
+	for _, exploitHost := range exploitHosts {
+		if host == exploitHost || strings.HasSuffix(host, "."+exploitHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inc(i int) int {
+	return i + 1
+}
+
+// oneline collapses whitespaces of the text to use it inside of the markdown table or list
+func oneline(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFlowGraph() models.FlowTasksSubtasks {
+	return models.FlowTasksSubtasks{
+		Flow: models.Flow{
+			ID:                7,
+			Status:            models.FlowStatusFinished,
+			Title:             "Perimeter <scan>",
+			Model:             "gpt-4.1",
+			ModelProviderName: "openai",
+			Language:          "English",
+			Description:       "External perimeter assessment",
+			CreatedAt:         time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		Tasks: []models.TaskSubtasks{
+			{
+				Task: models.Task{
+					ID:     3,
+					Status: models.TaskStatusFinished,
+					Title:  "Scan web server",
+					Input:  "Scan 10.0.0.1",
+					Result: "Apache 2.4.49 is vulnerable to CVE-2021-41773.\n\nSee https://www.exploit-db.com/exploits/50383.",
+				},
+				Subtasks: []models.Subtask{
+					{
+						ID:          11,
+						Status:      models.SubtaskStatusFinished,
+						Title:       "Run nmap",
+						Description: "Run nmap against the host",
+						Result:      "```\n" + strings.Repeat("80/tcp open http\n", 400) + "```",
+					},
+				},
+			},
+			{
+				Task: models.Task{
+					ID:     4,
+					Status: models.TaskStatusFailed,
+					Title:  "Check FTP",
+					Input:  "Check anonymous FTP login, docs at https://example.com/ftp",
+				},
+			},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	rep := New(testFlowGraph(), Options{BaseURL: "/api/v1", MaxOutputLen: 1000})
+
+	assert.Equal(t, 2, rep.Summary.Tasks)
+	assert.Equal(t, 1, rep.Summary.Subtasks)
+	assert.Equal(t, []StatusCount{{Status: "finished", Count: 1}, {Status: "failed", Count: 1}}, rep.Summary.Statuses)
+	require.Len(t, rep.Summary.Highlights, 2)
+	assert.Equal(t, "Apache 2.4.49 is vulnerable to CVE-2021-41773.", rep.Summary.Highlights[0].Lead)
+	assert.Empty(t, rep.Summary.Highlights[1].Lead)
+
+	// only vulnerability ids and known exploit sources are referenced
+	assert.Equal(t, []Reference{
+		{Name: "CVE-2021-41773", URL: "https://nvd.nist.gov/vuln/detail/CVE-2021-41773"},
+		{Name: "https://www.exploit-db.com/exploits/50383", URL: "https://www.exploit-db.com/exploits/50383"},
+	}, rep.References)
+
+	output := rep.Tasks[0].Subtasks[0].Result
+	assert.True(t, output.Collapsed)
+	assert.True(t, output.Truncated)
+	assert.Equal(t, "/api/v1/flows/7/tasks/3/subtasks/11", output.Link)
+	assert.True(t, strings.HasSuffix(output.Text, "\n```"), "cut code block must be closed")
+
+	input := rep.Tasks[1].Input
+	assert.False(t, input.Collapsed)
+	assert.False(t, input.Truncated)
+}
+
+func TestRender(t *testing.T) {
+	rep := New(testFlowGraph(), Options{BaseURL: "/api/v1", MaxOutputLen: 1000})
+
+	var md bytes.Buffer
+	require.NoError(t, Render(&md, FormatMarkdown, rep))
+	for _, part := range []string{
+		"# Penetration Test Report: Perimeter <scan>",
+		"## Executive Summary",
+		"External perimeter assessment",
+		"The flow ran 2 task(s) with 1 subtask(s) in total: 1 finished, 1 failed.",
+		"- **Scan web server** (finished): Apache 2.4.49",
+		"## 1. Scan web server",
+		"#### Run nmap (finished)",
+		"the full text is available at `/api/v1/flows/7/tasks/3/subtasks/11`",
+		"## 2. Check FTP",
+		"_No result was reported for this task._",
+		"- [CVE-2021-41773](https://nvd.nist.gov/vuln/detail/CVE-2021-41773)",
+	} {
+		assert.Contains(t, md.String(), part)
+	}
+
+	var html bytes.Buffer
+	require.NoError(t, Render(&html, FormatHTML, rep))
+	for _, part := range []string{
+		"<style>",
+		"Penetration Test Report: Perimeter &lt;scan&gt;",
+		"<details><summary>Show output</summary>",
+		`<a href="https://www.exploit-db.com/exploits/50383">`,
+		"<code>/api/v1/flows/7/tasks/3/subtasks/11</code>",
+	} {
+		assert.Contains(t, html.String(), part)
+	}
+	assert.NotContains(t, html.String(), "<scan>")
+	assert.NotContains(t, html.String(), "<link", "html report must be self-contained")
+
+	assert.Error(t, Render(&bytes.Buffer{}, Format("pdf"), rep))
+}
@@ -0,0 +1,391 @@
+package report
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/version"
+)
+
+const (
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifInformationURI = "https://github.com/vxcontrol/pentagi"
+	sarifMaxLocations   = 10
+	sarifContextBefore  = 200
+	sarifContextAfter   = 400
+)
+
+var (
+	cvssRegexp = regexp.MustCompile(`(?i)\bCVSS(?:v?[234](?:\.\d)?)?(?:\s+(?:base\s+)?score)?[\s:=]*(\d{1,2}(?:\.\d)?)(?:[^/\d.]|\.(?:\s|$)|$)`)
+	hostRegexp = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d{1,5})?\b`)
+
+	severityRegexps = []struct {
+		level  string
+		regexp *regexp.Regexp
+	}{
+		{"error", regexp.MustCompile(`(?i)\b(?:critical|high)\b`)},
+		{"warning", regexp.MustCompile(`(?i)\b(?:medium|moderate)\b`)},
+		{"note", regexp.MustCompile(`(?i)\b(?:low|informational)\b`)},
+	}
+)
+
+// findingTypes are the rules of the findings which don't refer to any CVE, the first matched one is used
+var findingTypes = []struct {
+	rule   SARIFRule
+	regexp *regexp.Regexp
+}{
+	{
+		rule:   newFindingRule("PENTAGI-SQLI", "SqlInjection", "SQL injection"),
+		regexp: regexp.MustCompile(`(?i)\bsql[\s-]*injection|\bsqli\b`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-XSS", "CrossSiteScripting", "Cross-site scripting"),
+		regexp: regexp.MustCompile(`(?i)cross[\s-]*site[\s-]*scripting|\bxss\b`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-RCE", "RemoteCodeExecution", "Remote code or command execution"),
+		regexp: regexp.MustCompile(`(?i)remote[\s-]*code[\s-]*execution|command[\s-]*injection|\brce\b`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-PATH-TRAVERSAL", "PathTraversal", "Path traversal or local file inclusion"),
+		regexp: regexp.MustCompile(`(?i)(?:path|directory)[\s-]*traversal|local[\s-]*file[\s-]*inclusion|\blfi\b`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-SSRF", "ServerSideRequestForgery", "Server-side request forgery"),
+		regexp: regexp.MustCompile(`(?i)server[\s-]*side[\s-]*request[\s-]*forgery|\bssrf\b`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-AUTH", "BrokenAuthentication", "Weak credentials or authentication bypass"),
+		regexp: regexp.MustCompile(`(?i)default[\s-]*credentials|weak[\s-]*password|authentication[\s-]*bypass|anonymous[\s-]*(?:login|access)\s+(?:is\s+)?(?:allowed|enabled)`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-INFO-DISCLOSURE", "InformationDisclosure", "Sensitive information disclosure"),
+		regexp: regexp.MustCompile(`(?i)information[\s-]*disclosure|sensitive[\s-]*(?:data|information)[\s-]*(?:exposure|exposed|leak)`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-MISCONFIGURATION", "SecurityMisconfiguration", "Security misconfiguration"),
+		regexp: regexp.MustCompile(`(?i)\bmisconfigur(?:ation|ed)\b`),
+	},
+	{
+		rule:   newFindingRule("PENTAGI-VULNERABILITY", "Vulnerability", "Vulnerability reported by the flow"),
+		regexp: regexp.MustCompile(`(?i)\bvulnerab(?:le|ility|ilities)\b`),
+	},
+}
+
+// SARIFLog is the root object of the SARIF 2.1.0 log
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the single flow analysis run
+type SARIFRun struct {
+	Tool              SARIFTool               `json:"tool"`
+	AutomationDetails *SARIFAutomationDetails `json:"automationDetails,omitempty"`
+	Results           []SARIFResult           `json:"results"`
+	Properties        map[string]any          `json:"properties,omitempty"`
+}
+
+// SARIFAutomationDetails identifies the flow which produced the run
+type SARIFAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// SARIFTool describes the analysis tool
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver describes the tool component with the rules of the results
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes the CVE or the finding type
+type SARIFRule struct {
+	ID                   string                   `json:"id"`
+	Name                 string                   `json:"name,omitempty"`
+	ShortDescription     *SARIFMessage            `json:"shortDescription,omitempty"`
+	HelpURI              string                   `json:"helpUri,omitempty"`
+	DefaultConfiguration *SARIFRuleConfiguration  `json:"defaultConfiguration,omitempty"`
+	Properties           *SARIFPropertyBagWithTag `json:"properties,omitempty"`
+}
+
+// SARIFRuleConfiguration is the default level of the rule results
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFPropertyBagWithTag is the property bag of the rule
+type SARIFPropertyBagWithTag struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+// SARIFMessage is the plain text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is the single finding of the flow
+type SARIFResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    SARIFMessage    `json:"message"`
+	Locations  []SARIFLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+// SARIFLocation is the affected host or URL or the logical location of the finding in the flow
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation points at the affected host or URL
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is the URI of the affected host or URL
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation is the task or subtask which reported the finding
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func newFindingRule(id, name, description string) SARIFRule {
+	return SARIFRule{
+		ID:               id,
+		Name:             name,
+		ShortDescription: &SARIFMessage{Text: description},
+		Properties:       &SARIFPropertyBagWithTag{Tags: []string{"security"}},
+	}
+}
+
+type sarifBuilder struct {
+	log   SARIFLog
+	rules map[string]int
+}
+
+// NewSARIF maps CVEs and typed findings of the flow tasks and subtasks into SARIF 2.1.0 results,
+// a finding of the subtask isn't repeated from the result of its task
+func NewSARIF(flow models.FlowTasksSubtasks) SARIFLog {
+	b := sarifBuilder{
+		log: SARIFLog{
+			Schema:  sarifSchema,
+			Version: sarifVersion,
+			Runs: []SARIFRun{{
+				Tool: SARIFTool{Driver: SARIFDriver{
+					Name:           version.GetBinaryName(),
+					Version:        version.GetBinaryVersion(),
+					InformationURI: sarifInformationURI,
+					Rules:          []SARIFRule{},
+				}},
+				AutomationDetails: &SARIFAutomationDetails{ID: fmt.Sprintf("pentagi/flow-%d/", flow.ID)},
+				Results:           []SARIFResult{},
+				Properties: map[string]any{
+					"flowId":     flow.ID,
+					"flowTitle":  flow.Title,
+					"flowStatus": flow.Status.String(),
+				},
+			}},
+		},
+		rules: map[string]int{},
+	}
+
+	for _, task := range flow.Tasks {
+		taskName := fmt.Sprintf("flow-%d/task-%d", flow.ID, task.ID)
+		reported := map[string]struct{}{}
+
+		for _, subtask := range task.Subtasks {
+			b.addFindings(reported, subtask.Title, subtask.Result, []string{subtask.Description, task.Input},
+				SARIFLogicalLocation{
+					Name:               subtask.Title,
+					FullyQualifiedName: fmt.Sprintf("%s/subtask-%d", taskName, subtask.ID),
+					Kind:               "resource",
+				},
+				map[string]any{"flowId": flow.ID, "taskId": task.ID, "subtaskId": subtask.ID},
+			)
+		}
+
+		b.addFindings(reported, task.Title, task.Result, []string{task.Input},
+			SARIFLogicalLocation{Name: task.Title, FullyQualifiedName: taskName, Kind: "resource"},
+			map[string]any{"flowId": flow.ID, "taskId": task.ID},
+		)
+	}
+
+	return b.log
+}
+
+func (b *sarifBuilder) addFindings(
+	reported map[string]struct{},
+	title, text string,
+	fallbacks []string,
+	logical SARIFLogicalLocation,
+	properties map[string]any,
+) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	locations := textLocations(text)
+	for _, fallback := range fallbacks {
+		if len(locations) != 0 {
+			break
+		}
+		locations = textLocations(fallback)
+	}
+	if len(locations) == 0 {
+		locations = []SARIFLocation{{LogicalLocations: []SARIFLogicalLocation{logical}}}
+	}
+
+	add := func(rule SARIFRule, context string) {
+		if _, ok := reported[rule.ID]; ok {
+			return
+		}
+		reported[rule.ID] = struct{}{}
+
+		level, score := findingSeverity(context)
+		resultProperties := make(map[string]any, len(properties)+1)
+		for key, value := range properties {
+			resultProperties[key] = value
+		}
+		if score != "" {
+			resultProperties["security-severity"] = score
+		}
+
+		b.log.Runs[0].Results = append(b.log.Runs[0].Results, SARIFResult{
+			RuleID:     rule.ID,
+			RuleIndex:  b.ruleIndex(rule),
+			Level:      level,
+			Message:    SARIFMessage{Text: fmt.Sprintf("%s: %s", oneline(title), leadParagraph(context, summaryLeadLen))},
+			Locations:  locations,
+			Properties: resultProperties,
+		})
+	}
+
+	cves := cveRegexp.FindAllStringIndex(text, -1)
+	for _, idx := range cves {
+		cve := text[idx[0]:idx[1]]
+		add(SARIFRule{
+			ID:               cve,
+			Name:             strings.ReplaceAll(cve, "-", ""),
+			ShortDescription: &SARIFMessage{Text: "Vulnerability " + cve},
+			HelpURI:          "https://nvd.nist.gov/vuln/detail/" + cve,
+			Properties:       &SARIFPropertyBagWithTag{Tags: []string{"security", "cve"}},
+		}, textWindow(text, idx[0], idx[1]))
+	}
+	if len(cves) != 0 {
+		return
+	}
+
+	for _, findingType := range findingTypes {
+		if idx := findingType.regexp.FindStringIndex(text); idx != nil {
+			add(findingType.rule, textWindow(text, idx[0], idx[1]))
+			return
+		}
+	}
+}
+
+func (b *sarifBuilder) ruleIndex(rule SARIFRule) int {
+	if idx, ok := b.rules[rule.ID]; ok {
+		return idx
+	}
+
+	driver := &b.log.Runs[0].Tool.Driver
+	driver.Rules = append(driver.Rules, rule)
+	b.rules[rule.ID] = len(driver.Rules) - 1
+
+	return b.rules[rule.ID]
+}
+
+// findingSeverity returns the SARIF level and the CVSS score if it's mentioned near the finding
+func findingSeverity(context string) (string, string) {
+	if match := cvssRegexp.FindStringSubmatch(context); match != nil {
+		if score, err := strconv.ParseFloat(match[1], 64); err == nil && score >= 0 && score <= 10 {
+			switch {
+			case score >= 7.0:
+				return "error", strconv.FormatFloat(score, 'f', 1, 64)
+			case score >= 4.0:
+				return "warning", strconv.FormatFloat(score, 'f', 1, 64)
+			default:
+				return "note", strconv.FormatFloat(score, 'f', 1, 64)
+			}
+		}
+	}
+
+	for _, severity := range severityRegexps {
+		if severity.regexp.MatchString(context) {
+			return severity.level, ""
+		}
+	}
+
+	return "warning", ""
+}
+
+// textLocations returns affected URLs and hosts mentioned in the text except the exploit references
+func textLocations(text string) []SARIFLocation {
+	var uris []string
+	for _, url := range urlRegexp.FindAllString(text, -1) {
+		url = strings.TrimRight(url, ".,;:!?`*")
+		if !isExploitURL(url) && !slices.Contains(uris, url) {
+			uris = append(uris, url)
+		}
+	}
+
+	// hosts which are parts of the found URLs are not repeated
+	for _, host := range hostRegexp.FindAllString(text, -1) {
+		address := host
+		if ip, _, err := net.SplitHostPort(host); err == nil {
+			address = ip
+		}
+		if net.ParseIP(address) == nil {
+			continue
+		}
+
+		uri := "//" + host
+		if !slices.ContainsFunc(uris, func(known string) bool { return strings.Contains(known, "//"+host) }) {
+			uris = append(uris, uri)
+		}
+	}
+
+	locations := make([]SARIFLocation, 0, min(len(uris), sarifMaxLocations))
+	for _, uri := range uris[:min(len(uris), sarifMaxLocations)] {
+		locations = append(locations, SARIFLocation{
+			PhysicalLocation: &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}},
+		})
+	}
+
+	return locations
+}
+
+func textWindow(text string, start, end int) string {
+	start = max(0, start-sarifContextBefore)
+	end = min(len(text), end+sarifContextAfter)
+
+	// window bounds are moved to the lines bounds to keep the message readable
+	start = strings.LastIndex(text[:start], "\n") + 1
+	if idx := strings.Index(text[end:], "\n"); idx != -1 {
+		end += idx
+	} else {
+		end = len(text)
+	}
+
+	return text[start:end]
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func testSARIFFlowGraph() models.FlowTasksSubtasks {
+	flow := testFlowGraph()
+	flow.Tasks = append(flow.Tasks,
+		models.TaskSubtasks{
+			Task: models.Task{
+				ID:     5,
+				Status: models.TaskStatusFinished,
+				Title:  "Test login form",
+				Input:  "Test the login form of http://10.0.0.2/login.php",
+				Result: "The login form is vulnerable to SQL injection, CVSS score 9.8. Dump of users is attached.",
+			},
+			Subtasks: []models.Subtask{
+				{
+					ID:          12,
+					Status:      models.SubtaskStatusFinished,
+					Title:       "Run sqlmap",
+					Description: "Run sqlmap against the login form",
+					Result:      "sqlmap confirmed SQL injection in parameter `user` of http://10.0.0.2/login.php, CVSS score 9.8.",
+				},
+			},
+		},
+		models.TaskSubtasks{
+			Task: models.Task{
+				ID:     6,
+				Status: models.TaskStatusFinished,
+				Title:  "Review password policy",
+				Input:  "Review the password policy of the organization",
+				Result: "Low severity: weak password policy allows 6 characters passwords.",
+			},
+		},
+	)
+
+	return flow
+}
+
+func TestNewSARIF(t *testing.T) {
+	log := NewSARIF(testSARIFFlowGraph())
+
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, "pentagi/flow-7/", run.AutomationDetails.ID)
+
+	require.Len(t, run.Tool.Driver.Rules, 3)
+	assert.Equal(t, "CVE-2021-41773", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2021-41773", run.Tool.Driver.Rules[0].HelpURI)
+	assert.Equal(t, "PENTAGI-SQLI", run.Tool.Driver.Rules[1].ID)
+	assert.Equal(t, "PENTAGI-AUTH", run.Tool.Driver.Rules[2].ID)
+
+	// the finding of the subtask isn't repeated from its task result
+	require.Len(t, run.Results, 3)
+
+	cve := run.Results[0]
+	assert.Equal(t, "CVE-2021-41773", cve.RuleID)
+	assert.Equal(t, "warning", cve.Level)
+	assert.Equal(t, uint64(3), cve.Properties["taskId"])
+	require.Len(t, cve.Locations, 1)
+	assert.Equal(t, "//10.0.0.1", cve.Locations[0].PhysicalLocation.ArtifactLocation.URI,
+		"exploit references are not locations, the target of the task is used")
+
+	sqli := run.Results[1]
+	assert.Equal(t, "PENTAGI-SQLI", sqli.RuleID)
+	assert.Equal(t, 1, sqli.RuleIndex)
+	assert.Equal(t, "error", sqli.Level)
+	assert.Equal(t, "9.8", sqli.Properties["security-severity"])
+	assert.Equal(t, uint64(12), sqli.Properties["subtaskId"])
+	require.Len(t, sqli.Locations, 1)
+	assert.Equal(t, "http://10.0.0.2/login.php", sqli.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+
+	auth := run.Results[2]
+	assert.Equal(t, "note", auth.Level)
+	require.Len(t, auth.Locations, 1)
+	assert.Nil(t, auth.Locations[0].PhysicalLocation)
+	assert.Equal(t, []SARIFLogicalLocation{{
+		Name:               "Review password policy",
+		FullyQualifiedName: "flow-7/task-6",
+		Kind:               "resource",
+	}}, auth.Locations[0].LogicalLocations)
+}
+
+func TestNewSARIF_Schema(t *testing.T) {
+	schema, err := os.ReadFile("testdata/sarif-schema-2.1.0.json")
+	require.NoError(t, err)
+
+	sl := gojsonschema.NewSchemaLoader()
+	sl.Draft = gojsonschema.Draft7
+	validator, err := sl.Compile(gojsonschema.NewBytesLoader(schema))
+	require.NoError(t, err)
+
+	for name, flow := range map[string]models.FlowTasksSubtasks{
+		"findings": testSARIFFlowGraph(),
+		"empty":    {Flow: models.Flow{ID: 8, Status: models.FlowStatusCreated}},
+	} {
+		doc, err := json.Marshal(NewSARIF(flow))
+		require.NoError(t, err, name)
+
+		result, err := validator.Validate(gojsonschema.NewBytesLoader(doc))
+		require.NoError(t, err, name)
+		assert.True(t, result.Valid(), "%s: %v", name, result.Errors())
+	}
+}
+
+func TestFindingSeverity(t *testing.T) {
+	tests := []struct {
+		text  string
+		level string
+		score string
+	}{
+		{text: "CVSS 7.5 path traversal", level: "error", score: "7.5"},
+		{text: "CVSSv3 base score: 5.3", level: "warning", score: "5.3"},
+		{text: "CVSS score 2.1.", level: "note", score: "2.1"},
+		{text: "vector CVSS:3.1/AV:N/AC:L rated as medium", level: "warning"},
+		{text: "critical issue", level: "error"},
+		{text: "nothing about severity", level: "warning"},
+	}
+
+	for _, tt := range tests {
+		level, score := findingSeverity(tt.text)
+		assert.Equal(t, tt.level, level, tt.text)
+		assert.Equal(t, tt.score, score, tt.text)
+	}
+}
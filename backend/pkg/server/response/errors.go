@@ -12,6 +12,9 @@ var ErrLocalUserRequired = NewHttpError(403, "LocalUserRequired", "local user re
 var ErrPrivilegesRequired = NewHttpError(403, "PrivilegesRequired", "some privileges required")
 var ErrAdminRequired = NewHttpError(403, "AdminRequired", "admin required")
 var ErrSuperRequired = NewHttpError(403, "SuperRequired", "super admin required")
+var ErrNotReady = NewHttpError(503, "NotReady", "service is not ready")
+var ErrPayloadTooLarge = NewHttpError(413, "PayloadTooLarge", "request body is too large")
+var ErrTooManyRequests = NewHttpError(429, "TooManyRequests", "too many requests")
 
 // auth
 
@@ -72,6 +75,8 @@ var ErrScreenshotsInvalidData = NewHttpError(500, "Screenshots.InvalidData", "in
 var ErrContainersInvalidRequest = NewHttpError(400, "Containers.InvalidRequest", "invalid container request data")
 var ErrContainersNotFound = NewHttpError(404, "Containers.NotFound", "container not found")
 var ErrContainersInvalidData = NewHttpError(500, "Containers.InvalidData", "invalid container data")
+var ErrContainersNotRunning = NewHttpError(400, "Containers.NotRunning", "container is not running")
+var ErrContainersSnapshotNotFound = NewHttpError(404, "Containers.SnapshotNotFound", "container snapshot not found")
 
 // agentlogs
 
@@ -108,6 +113,55 @@ var ErrVecstorelogsInvalidData = NewHttpError(500, "Vecstorelogs.InvalidData", "
 var ErrFlowsInvalidRequest = NewHttpError(400, "Flows.InvalidRequest", "invalid flow request data")
 var ErrFlowsNotFound = NewHttpError(404, "Flows.NotFound", "flow not found")
 var ErrFlowsInvalidData = NewHttpError(500, "Flows.InvalidData", "invalid flow data")
+var ErrFlowsResourceLimitsExceeded = NewHttpError(400, "Flows.ResourceLimitsExceeded", "requested container resources exceed the allowed maximum")
+var ErrFlowsPartialCleanup = NewHttpError(500, "Flows.PartialCleanup", "flow deletion failed, flow containers were partially cleaned up")
+var ErrFlowsContainersReaped = NewHttpError(400, "Flows.ContainersReaped", "flow containers were already removed and can't be reattached")
+var ErrFlowsBudgetExceeded = NewHttpError(400, "Flows.BudgetExceeded", "flow usage already exceeds the requested budget")
+var ErrFlowsRunningLimitExceeded = NewHttpError(429, "Flows.RunningLimitExceeded", "user already runs the maximum number of flows")
+var ErrFlowsServerShuttingDown = NewHttpError(503, "Flows.ServerShuttingDown", "server is shutting down and doesn't accept new flows")
+var ErrFlowsSecretsKeyNotSet = NewHttpError(400, "Flows.SecretsKeyNotSet", "flow secrets can't be stored without the configured secrets key")
+
+// flow templates
+
+var ErrFlowTemplatesInvalidRequest = NewHttpError(400, "FlowTemplates.InvalidRequest", "invalid flow template request data")
+var ErrFlowTemplatesNotFound = NewHttpError(404, "FlowTemplates.NotFound", "flow template not found")
+var ErrFlowTemplatesInvalidData = NewHttpError(500, "FlowTemplates.InvalidData", "invalid flow template data")
+
+// input templates
+
+var ErrInputTemplatesInvalidRequest = NewHttpError(400, "InputTemplates.InvalidRequest", "invalid input template request data")
+var ErrInputTemplatesNotFound = NewHttpError(404, "InputTemplates.NotFound", "input template not found")
+var ErrInputTemplatesInvalidData = NewHttpError(500, "InputTemplates.InvalidData", "invalid input template data")
+
+// flow acls
+
+var ErrFlowACLsInvalidRequest = NewHttpError(400, "FlowACLs.InvalidRequest", "invalid flow access request data")
+var ErrFlowACLsNotFound = NewHttpError(404, "FlowACLs.NotFound", "flow access not found")
+var ErrFlowACLsUserNotFound = NewHttpError(404, "FlowACLs.UserNotFound", "user to share the flow with not found")
+var ErrFlowACLsInvalidData = NewHttpError(500, "FlowACLs.InvalidData", "invalid flow access data")
+
+// flow attachments
+
+var ErrFlowAttachmentsInvalidRequest = NewHttpError(400, "FlowAttachments.InvalidRequest", "invalid flow attachment request data")
+var ErrFlowAttachmentsNotFound = NewHttpError(404, "FlowAttachments.NotFound", "flow attachment not found")
+var ErrFlowAttachmentsAlreadyExists = NewHttpError(409, "FlowAttachments.AlreadyExists", "flow attachment with the same name already exists")
+var ErrFlowAttachmentsInvalidData = NewHttpError(500, "FlowAttachments.InvalidData", "invalid flow attachment data")
+
+// flow memories
+
+var ErrFlowMemoriesInvalidRequest = NewHttpError(400, "FlowMemories.InvalidRequest", "invalid flow memory request data")
+var ErrFlowMemoriesInvalidData = NewHttpError(500, "FlowMemories.InvalidData", "invalid flow memory data")
+
+// flow artifacts
+
+var ErrFlowArtifactsInvalidRequest = NewHttpError(400, "FlowArtifacts.InvalidRequest", "invalid flow artifact request data")
+var ErrFlowArtifactsNotFound = NewHttpError(404, "FlowArtifacts.NotFound", "flow artifact not found")
+var ErrFlowArtifactsInvalidData = NewHttpError(500, "FlowArtifacts.InvalidData", "invalid flow artifact data")
+
+// audit logs
+
+var ErrAuditLogsInvalidRequest = NewHttpError(400, "AuditLogs.InvalidRequest", "invalid audit log request data")
+var ErrAuditLogsInvalidData = NewHttpError(500, "AuditLogs.InvalidData", "invalid audit log data")
 
 // tasks
 
@@ -120,6 +174,9 @@ var ErrTasksInvalidData = NewHttpError(500, "Tasks.InvalidData", "invalid task d
 var ErrSubtasksInvalidRequest = NewHttpError(400, "Subtasks.InvalidRequest", "invalid subtask request data")
 var ErrSubtasksNotFound = NewHttpError(404, "Subtasks.NotFound", "subtask not found")
 var ErrSubtasksInvalidData = NewHttpError(500, "Subtasks.InvalidData", "invalid subtask data")
+var ErrSubtasksAlreadyCompleted = NewHttpError(400, "Subtasks.AlreadyCompleted", "subtask has already completed")
+var ErrSubtasksNotRunning = NewHttpError(400, "Subtasks.NotRunning", "flow of the subtask is not running")
+var ErrSubtasksNotPlanned = NewHttpError(400, "Subtasks.NotPlanned", "subtask is not planned to run")
 
 // assistants
 
@@ -127,6 +184,20 @@ var ErrAssistantsInvalidRequest = NewHttpError(400, "Assistants.InvalidRequest",
 var ErrAssistantsNotFound = NewHttpError(404, "Assistants.NotFound", "assistant not found")
 var ErrAssistantsInvalidData = NewHttpError(500, "Assistants.InvalidData", "invalid assistant data")
 
+// providers
+
+var ErrProvidersNotFound = NewHttpError(404, "Providers.NotFound", "provider not found")
+
+// model prices
+
+var ErrModelPricesInvalidRequest = NewHttpError(400, "ModelPrices.InvalidRequest", "invalid model price request data")
+var ErrModelPricesNotFound = NewHttpError(404, "ModelPrices.NotFound", "model price not found")
+var ErrModelPricesInvalidData = NewHttpError(500, "ModelPrices.InvalidData", "invalid model price data")
+
+// cache
+
+var ErrSearchCacheToolNotFound = NewHttpError(404, "SearchCache.ToolNotFound", "search tool not found")
+
 // tokens
 
 var ErrTokenCreationDisabled = NewHttpError(400, "Token.CreationDisabled", "token creation is disabled with default configuration")
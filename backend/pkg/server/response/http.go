@@ -2,6 +2,8 @@ package response
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
 
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/version"
@@ -11,15 +13,22 @@ import (
 )
 
 type HttpError struct {
-	message  string
-	code     string
-	httpCode int
+	message   string
+	code      string
+	errorCode string
+	httpCode  int
 }
 
 func (h *HttpError) Code() string {
 	return h.code
 }
 
+// ErrorCode returns stable machine readable code of the error in form of "flows.invalid_request",
+// it's derived from the code and documented in docs/api_errors.md
+func (h *HttpError) ErrorCode() string {
+	return h.errorCode
+}
+
 func (h *HttpError) HttpCode() int {
 	return h.httpCode
 }
@@ -29,7 +38,40 @@ func (h *HttpError) Msg() string {
 }
 
 func NewHttpError(httpCode int, code, message string) *HttpError {
-	return &HttpError{httpCode: httpCode, message: message, code: code}
+	return &HttpError{httpCode: httpCode, message: message, code: code, errorCode: toErrorCode(code)}
+}
+
+// toErrorCode converts the dot separated CamelCase code to the snake_case one, acronyms and
+// their plurals are kept as a single word, e.g. "Internal.DBNotFound" becomes "internal.db_not_found"
+func toErrorCode(code string) string {
+	parts := strings.Split(code, ".")
+	for idx, part := range parts {
+		runes := []rune(part)
+		var sb strings.Builder
+		for i, r := range runes {
+			if i > 0 && unicode.IsUpper(r) {
+				prev := runes[i-1]
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && startsWord(runes, i)) {
+					sb.WriteRune('_')
+				}
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		}
+		parts[idx] = sb.String()
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// startsWord reports whether the upper case rune after the acronym starts the next word,
+// the trailing lower case "s" is the plural of the acronym like in "ACLs"
+func startsWord(runes []rune, i int) bool {
+	if i+1 >= len(runes) || !unicode.IsLower(runes[i+1]) {
+		return false
+	}
+
+	plural := runes[i+1] == 's' && (i+2 == len(runes) || unicode.IsUpper(runes[i+2]))
+	return !plural
 }
 
 func (h *HttpError) Error() string {
@@ -37,10 +79,20 @@ func (h *HttpError) Error() string {
 }
 
 func Error(c *gin.Context, err *HttpError, original error) {
+	ErrorWithDetails(c, err, original, nil)
+}
+
+// ErrorWithDetails writes error response with additional machine readable details
+func ErrorWithDetails(c *gin.Context, err *HttpError, original error, details any) {
 	body := gin.H{
-		"status": "error",
-		"code":   err.Code(),
-		"msg":    err.Msg(),
+		"status":     "error",
+		"code":       err.Code(),
+		"error_code": err.ErrorCode(),
+		"msg":        err.Msg(),
+	}
+
+	if details != nil {
+		body["details"] = details
 	}
 
 	if version.IsDevelopMode() && original != nil {
@@ -68,8 +120,10 @@ type successResp struct {
 
 //lint:ignore U1000 errorResp
 type errorResp struct {
-	Status string `json:"status" example:"error"`
-	Code   string `json:"code" example:"Internal"`
-	Msg    string `json:"msg,omitempty" example:"internal server error"`
-	Error  string `json:"error,omitempty" example:"original server error message"`
+	Status    string `json:"status" example:"error"`
+	Code      string `json:"code" example:"Internal"`
+	ErrorCode string `json:"error_code" example:"internal"`
+	Msg       string `json:"msg,omitempty" example:"internal server error"`
+	Error     string `json:"error,omitempty" example:"original server error message"`
+	Details   any    `json:"details,omitempty" swaggertype:"object"`
 } // @name ErrorResponse
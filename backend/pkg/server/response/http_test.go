@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"pentagi/pkg/version"
@@ -24,9 +25,27 @@ func TestNewHttpError(t *testing.T) {
 	err := NewHttpError(404, "NotFound", "resource not found")
 	assert.Equal(t, 404, err.HttpCode())
 	assert.Equal(t, "NotFound", err.Code())
+	assert.Equal(t, "not_found", err.ErrorCode())
 	assert.Equal(t, "resource not found", err.Msg())
 }
 
+func TestToErrorCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Internal":                               "internal",
+		"Internal.DBNotFound":                    "internal.db_not_found",
+		"Internal.DBEncryptorNotFound":           "internal.db_encryptor_not_found",
+		"Flows.InvalidRequest":                   "flows.invalid_request",
+		"FlowACLs.UserNotFound":                  "flow_acls.user_not_found",
+		"Users.ChangePasswordCurrentUser.Failed": "users.change_password_current_user.failed",
+	}
+
+	for code, expected := range tests {
+		assert.Equal(t, expected, toErrorCode(code), code)
+	}
+}
+
 func TestHttpError_Error(t *testing.T) {
 	t.Parallel()
 
@@ -62,6 +81,9 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrPrivilegesRequired", ErrPrivilegesRequired, 403, "PrivilegesRequired"},
 		{"ErrAdminRequired", ErrAdminRequired, 403, "AdminRequired"},
 		{"ErrSuperRequired", ErrSuperRequired, 403, "SuperRequired"},
+		{"ErrNotReady", ErrNotReady, 503, "NotReady"},
+		{"ErrPayloadTooLarge", ErrPayloadTooLarge, 413, "PayloadTooLarge"},
+		{"ErrTooManyRequests", ErrTooManyRequests, 429, "TooManyRequests"},
 
 		// Auth errors
 		{"ErrAuthInvalidLoginRequest", ErrAuthInvalidLoginRequest, 400, "Auth.InvalidLoginRequest"},
@@ -115,6 +137,8 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrContainersInvalidRequest", ErrContainersInvalidRequest, 400, "Containers.InvalidRequest"},
 		{"ErrContainersNotFound", ErrContainersNotFound, 404, "Containers.NotFound"},
 		{"ErrContainersInvalidData", ErrContainersInvalidData, 500, "Containers.InvalidData"},
+		{"ErrContainersNotRunning", ErrContainersNotRunning, 400, "Containers.NotRunning"},
+		{"ErrContainersSnapshotNotFound", ErrContainersSnapshotNotFound, 404, "Containers.SnapshotNotFound"},
 
 		// Agentlogs errors
 		{"ErrAgentlogsInvalidRequest", ErrAgentlogsInvalidRequest, 400, "Agentlogs.InvalidRequest"},
@@ -144,6 +168,34 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrFlowsInvalidRequest", ErrFlowsInvalidRequest, 400, "Flows.InvalidRequest"},
 		{"ErrFlowsNotFound", ErrFlowsNotFound, 404, "Flows.NotFound"},
 		{"ErrFlowsInvalidData", ErrFlowsInvalidData, 500, "Flows.InvalidData"},
+		{"ErrFlowsResourceLimitsExceeded", ErrFlowsResourceLimitsExceeded, 400, "Flows.ResourceLimitsExceeded"},
+		{"ErrFlowsPartialCleanup", ErrFlowsPartialCleanup, 500, "Flows.PartialCleanup"},
+		{"ErrFlowsContainersReaped", ErrFlowsContainersReaped, 400, "Flows.ContainersReaped"},
+		{"ErrFlowsBudgetExceeded", ErrFlowsBudgetExceeded, 400, "Flows.BudgetExceeded"},
+		{"ErrFlowsRunningLimitExceeded", ErrFlowsRunningLimitExceeded, 429, "Flows.RunningLimitExceeded"},
+		{"ErrFlowsSecretsKeyNotSet", ErrFlowsSecretsKeyNotSet, 400, "Flows.SecretsKeyNotSet"},
+		{"ErrFlowsServerShuttingDown", ErrFlowsServerShuttingDown, 503, "Flows.ServerShuttingDown"},
+		{"ErrFlowTemplatesInvalidRequest", ErrFlowTemplatesInvalidRequest, 400, "FlowTemplates.InvalidRequest"},
+		{"ErrFlowTemplatesNotFound", ErrFlowTemplatesNotFound, 404, "FlowTemplates.NotFound"},
+		{"ErrFlowTemplatesInvalidData", ErrFlowTemplatesInvalidData, 500, "FlowTemplates.InvalidData"},
+		{"ErrInputTemplatesInvalidRequest", ErrInputTemplatesInvalidRequest, 400, "InputTemplates.InvalidRequest"},
+		{"ErrInputTemplatesNotFound", ErrInputTemplatesNotFound, 404, "InputTemplates.NotFound"},
+		{"ErrInputTemplatesInvalidData", ErrInputTemplatesInvalidData, 500, "InputTemplates.InvalidData"},
+		{"ErrFlowACLsInvalidRequest", ErrFlowACLsInvalidRequest, 400, "FlowACLs.InvalidRequest"},
+		{"ErrFlowACLsNotFound", ErrFlowACLsNotFound, 404, "FlowACLs.NotFound"},
+		{"ErrFlowACLsUserNotFound", ErrFlowACLsUserNotFound, 404, "FlowACLs.UserNotFound"},
+		{"ErrFlowACLsInvalidData", ErrFlowACLsInvalidData, 500, "FlowACLs.InvalidData"},
+		{"ErrFlowAttachmentsInvalidRequest", ErrFlowAttachmentsInvalidRequest, 400, "FlowAttachments.InvalidRequest"},
+		{"ErrFlowAttachmentsNotFound", ErrFlowAttachmentsNotFound, 404, "FlowAttachments.NotFound"},
+		{"ErrFlowAttachmentsAlreadyExists", ErrFlowAttachmentsAlreadyExists, 409, "FlowAttachments.AlreadyExists"},
+		{"ErrFlowAttachmentsInvalidData", ErrFlowAttachmentsInvalidData, 500, "FlowAttachments.InvalidData"},
+		{"ErrFlowMemoriesInvalidRequest", ErrFlowMemoriesInvalidRequest, 400, "FlowMemories.InvalidRequest"},
+		{"ErrFlowMemoriesInvalidData", ErrFlowMemoriesInvalidData, 500, "FlowMemories.InvalidData"},
+		{"ErrFlowArtifactsInvalidRequest", ErrFlowArtifactsInvalidRequest, 400, "FlowArtifacts.InvalidRequest"},
+		{"ErrFlowArtifactsNotFound", ErrFlowArtifactsNotFound, 404, "FlowArtifacts.NotFound"},
+		{"ErrFlowArtifactsInvalidData", ErrFlowArtifactsInvalidData, 500, "FlowArtifacts.InvalidData"},
+		{"ErrAuditLogsInvalidRequest", ErrAuditLogsInvalidRequest, 400, "AuditLogs.InvalidRequest"},
+		{"ErrAuditLogsInvalidData", ErrAuditLogsInvalidData, 500, "AuditLogs.InvalidData"},
 
 		// Tasks errors
 		{"ErrTasksInvalidRequest", ErrTasksInvalidRequest, 400, "Tasks.InvalidRequest"},
@@ -154,12 +206,26 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrSubtasksInvalidRequest", ErrSubtasksInvalidRequest, 400, "Subtasks.InvalidRequest"},
 		{"ErrSubtasksNotFound", ErrSubtasksNotFound, 404, "Subtasks.NotFound"},
 		{"ErrSubtasksInvalidData", ErrSubtasksInvalidData, 500, "Subtasks.InvalidData"},
+		{"ErrSubtasksAlreadyCompleted", ErrSubtasksAlreadyCompleted, 400, "Subtasks.AlreadyCompleted"},
+		{"ErrSubtasksNotRunning", ErrSubtasksNotRunning, 400, "Subtasks.NotRunning"},
+		{"ErrSubtasksNotPlanned", ErrSubtasksNotPlanned, 400, "Subtasks.NotPlanned"},
 
 		// Assistants errors
 		{"ErrAssistantsInvalidRequest", ErrAssistantsInvalidRequest, 400, "Assistants.InvalidRequest"},
 		{"ErrAssistantsNotFound", ErrAssistantsNotFound, 404, "Assistants.NotFound"},
 		{"ErrAssistantsInvalidData", ErrAssistantsInvalidData, 500, "Assistants.InvalidData"},
 
+		// Providers errors
+		{"ErrProvidersNotFound", ErrProvidersNotFound, 404, "Providers.NotFound"},
+
+		// Model prices errors
+		{"ErrModelPricesInvalidRequest", ErrModelPricesInvalidRequest, 400, "ModelPrices.InvalidRequest"},
+		{"ErrModelPricesNotFound", ErrModelPricesNotFound, 404, "ModelPrices.NotFound"},
+		{"ErrModelPricesInvalidData", ErrModelPricesInvalidData, 500, "ModelPrices.InvalidData"},
+
+		// Cache errors
+		{"ErrSearchCacheToolNotFound", ErrSearchCacheToolNotFound, 404, "SearchCache.ToolNotFound"},
+
 		// Tokens errors
 		{"ErrTokenCreationDisabled", ErrTokenCreationDisabled, 400, "Token.CreationDisabled"},
 		{"ErrTokenNotFound", ErrTokenNotFound, 404, "Token.NotFound"},
@@ -168,6 +234,18 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrTokenInvalidData", ErrTokenInvalidData, 500, "Token.InvalidData"},
 	}
 
+	doc, err := os.ReadFile("../../../docs/api_errors.md")
+	require.NoError(t, err)
+
+	// error codes are the documented contract, so they must be unique and listed in the docs
+	errorCodes := make(map[string]string, len(tests))
+	for _, tt := range tests {
+		prev, ok := errorCodes[tt.err.ErrorCode()]
+		assert.False(t, ok, "error code %s of %s is already used by %s", tt.err.ErrorCode(), tt.name, prev)
+		errorCodes[tt.err.ErrorCode()] = tt.name
+		assert.Contains(t, string(doc), "| `"+tt.err.ErrorCode()+"` ", "error code of %s is not documented", tt.name)
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
@@ -225,6 +303,7 @@ func TestErrorResponse(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "error", body["status"])
 	assert.Equal(t, "Internal", body["code"])
+	assert.Equal(t, "internal", body["error_code"])
 	assert.Equal(t, "internal server error", body["msg"])
 }
 
@@ -398,6 +477,7 @@ func TestErrorResponse_ResponseStructure(t *testing.T) {
 	// Verify required fields
 	assert.Equal(t, "error", body["status"])
 	assert.Equal(t, "Users.NotFound", body["code"])
+	assert.Equal(t, "users.not_found", body["error_code"])
 	assert.Equal(t, "user not found", body["msg"])
 
 	// Verify error field is not present in non-dev mode
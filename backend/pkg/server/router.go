@@ -16,6 +16,7 @@ import (
 	"pentagi/pkg/config"
 	"pentagi/pkg/controller"
 	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
 	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/providers"
 	"pentagi/pkg/server/auth"
@@ -74,6 +75,7 @@ func NewRouter(
 	db *database.Queries,
 	orm *gorm.DB,
 	cfg *config.Config,
+	dockerClient docker.DockerClient,
 	providers providers.ProviderController,
 	controller controller.FlowController,
 	subscriptions subscriptions.SubscriptionsController,
@@ -127,10 +129,15 @@ func NewRouter(
 	userService := services.NewUserService(orm, userCache)
 	roleService := services.NewRoleService(orm)
 	providerService := services.NewProviderService(providers)
-	flowService := services.NewFlowService(orm, providers, controller, subscriptions)
+	healthService := services.NewHealthService(orm, dockerClient, providers, controller)
+	flowService := services.NewFlowService(orm, cfg, providers, controller, subscriptions)
+	flowTemplateService := services.NewFlowTemplateService(orm)
+	inputTemplateService := services.NewInputTemplateService(orm)
+	modelPriceService := services.NewModelPriceService(orm)
+	cacheService := services.NewCacheService(cfg, providers)
 	taskService := services.NewTaskService(orm)
-	subtaskService := services.NewSubtaskService(orm)
-	containerService := services.NewContainerService(orm)
+	subtaskService := services.NewSubtaskService(orm, controller)
+	containerService := services.NewContainerService(orm, subscriptions)
 	assistantService := services.NewAssistantService(orm, providers, controller, subscriptions)
 	agentlogService := services.NewAgentlogService(orm)
 	assistantlogService := services.NewAssistantlogService(orm)
@@ -139,8 +146,12 @@ func NewRouter(
 	vecstorelogService := services.NewVecstorelogService(orm)
 	termlogService := services.NewTermlogService(orm)
 	screenshotService := services.NewScreenshotService(orm, cfg.DataDir)
+	flowAttachmentService := services.NewFlowAttachmentService(orm, cfg, controller)
+	flowMemoryService := services.NewFlowMemoryService(orm)
+	flowArtifactService := services.NewFlowArtifactService(orm, cfg)
 	promptService := services.NewPromptService(orm)
 	analyticsService := services.NewAnalyticsService(orm)
+	auditService := services.NewAuditService(orm)
 	tokenService := services.NewTokenService(orm, cfg.CookieSigningSalt, tokenCache, subscriptions)
 	graphqlService := services.NewGraphqlService(
 		db, cfg, baseURL, cfg.CorsOrigins, tokenCache, providers, controller, subscriptions,
@@ -183,8 +194,16 @@ func NewRouter(
 	cookieStore := cookie.NewStore(auth.MakeCookieStoreKey(cfg.CookieSigningSalt)...)
 	router.Use(sessions.Sessions("auth", cookieStore))
 
+	// probes are mounted to the root to be used by orchestrators without auth and base URL
+	router.GET("/livez", healthService.GetLiveness)
+	router.GET("/readyz", healthService.GetReadiness)
+
 	api := router.Group(baseURL)
 	api.Use(noCacheMiddleware())
+	api.Use(bodySizeLimitMiddleware(cfg.ServerMaxBodySize, map[string]int64{
+		// attachments are limited by their own size setting plus the multipart form overhead
+		baseURL + "/flows/:flowID/attachments/": cfg.AttachmentsMaxSize + multipartFormOverhead,
+	}))
 
 	// Special case for local user own password change
 	changePasswordGroup := api.Group("/user")
@@ -217,23 +236,39 @@ func NewRouter(
 	privateGroup := api.Group("/")
 	privateGroup.Use(authMiddleware.AuthTokenRequired)
 	{
+		// flow route groups have their own per-user rate limits
+		apiRateLimiter := newAPIRateLimiter(cfg)
+		flowsGroup := privateGroup.Group("/", apiRateLimiter.middleware(apiRateLimitGroupFlows))
+		tasksGroup := privateGroup.Group("/", apiRateLimiter.middleware(apiRateLimitGroupTasks))
+		subtasksGroup := privateGroup.Group("/", apiRateLimiter.middleware(apiRateLimitGroupSubtasks))
+		assistantsGroup := privateGroup.Group("/", apiRateLimiter.middleware(apiRateLimitGroupAssistants))
+		logsGroup := privateGroup.Group("/", apiRateLimiter.middleware(apiRateLimitGroupLogs))
+
 		setGraphqlGroup(privateGroup, graphqlService)
 
 		setProvidersGroup(privateGroup, providerService)
-		setFlowsGroup(privateGroup, flowService)
-		setTasksGroup(privateGroup, taskService)
-		setSubtasksGroup(privateGroup, subtaskService)
-		setContainersGroup(privateGroup, containerService)
-		setAssistantsGroup(privateGroup, assistantService)
-		setAgentlogsGroup(privateGroup, agentlogService)
-		setAssistantlogsGroup(privateGroup, assistantlogService)
-		setMsglogsGroup(privateGroup, msglogService)
-		setTermlogsGroup(privateGroup, termlogService)
-		setSearchlogsGroup(privateGroup, searchlogService)
-		setVecstorelogsGroup(privateGroup, vecstorelogService)
-		setScreenshotsGroup(privateGroup, screenshotService)
+		setFlowsGroup(flowsGroup, flowService)
+		setFlowTemplatesGroup(privateGroup, flowTemplateService)
+		setInputTemplatesGroup(privateGroup, inputTemplateService)
+		setModelPricesGroup(privateGroup, modelPriceService)
+		setCacheGroup(privateGroup, cacheService)
+		setTasksGroup(tasksGroup, taskService)
+		setSubtasksGroup(subtasksGroup, subtaskService)
+		setContainersGroup(flowsGroup, containerService)
+		setAssistantsGroup(assistantsGroup, assistantService)
+		setAgentlogsGroup(logsGroup, agentlogService)
+		setAssistantlogsGroup(logsGroup, assistantlogService)
+		setMsglogsGroup(logsGroup, msglogService)
+		setTermlogsGroup(logsGroup, termlogService)
+		setSearchlogsGroup(logsGroup, searchlogService)
+		setVecstorelogsGroup(logsGroup, vecstorelogService)
+		setScreenshotsGroup(logsGroup, screenshotService)
+		setFlowAttachmentsGroup(flowsGroup, flowAttachmentService)
+		setFlowMemoriesGroup(flowsGroup, flowMemoryService)
+		setFlowArtifactsGroup(flowsGroup, flowArtifactService)
 		setPromptsGroup(privateGroup, promptService)
 		setAnalyticsGroup(privateGroup, analyticsService)
+		setAuditGroup(privateGroup, auditService)
 	}
 
 	privateUserGroup := api.Group("/")
@@ -309,6 +344,9 @@ func setProvidersGroup(parent *gin.RouterGroup, svc *services.ProviderService) {
 	providersGroup := parent.Group("/providers")
 	{
 		providersGroup.GET("/", svc.GetProviders)
+		providersGroup.GET("/rate-limits", svc.GetProviderRateLimits)
+		providersGroup.GET("/:name/health", svc.GetProviderHealth)
+		providersGroup.GET("/:name/models", svc.GetProviderModels)
 	}
 }
 
@@ -329,6 +367,13 @@ func setSubtasksGroup(parent *gin.RouterGroup, svc *services.SubtaskService) {
 	{
 		flowTaskSubtasksViewGroup.GET("/", svc.GetFlowTaskSubtasks)
 		flowTaskSubtasksViewGroup.GET("/:subtaskID", svc.GetFlowTaskSubtask)
+		flowTaskSubtasksViewGroup.GET("/:subtaskID/tools", svc.GetFlowTaskSubtaskTools)
+	}
+
+	flowTaskSubtasksEditGroup := parent.Group("/flows/:flowID/tasks/:taskID/subtasks")
+	{
+		flowTaskSubtasksEditGroup.PUT("/:subtaskID/priority", svc.PrioritizeFlowTaskSubtask)
+		flowTaskSubtasksEditGroup.DELETE("/:subtaskID", svc.CancelFlowTaskSubtask)
 	}
 }
 
@@ -339,6 +384,11 @@ func setTasksGroup(parent *gin.RouterGroup, svc *services.TaskService) {
 		flowTaskViewGroup.GET("/:taskID", svc.GetFlowTask)
 		flowTaskViewGroup.GET("/:taskID/graph", svc.GetFlowTaskGraph)
 	}
+
+	tasksViewGroup := parent.Group("/tasks")
+	{
+		tasksViewGroup.GET("/search", svc.SearchTasks)
+	}
 }
 
 func setFlowsGroup(parent *gin.RouterGroup, svc *services.FlowService) {
@@ -355,13 +405,80 @@ func setFlowsGroup(parent *gin.RouterGroup, svc *services.FlowService) {
 	flowEditGroup := parent.Group("/flows")
 	{
 		flowEditGroup.PUT("/:flowID", svc.PatchFlow)
+		flowEditGroup.POST("/:flowID/containers/:containerID/snapshot", svc.SnapshotFlowContainer)
+		flowEditGroup.PUT("/:flowID/secrets", svc.SetFlowSecrets)
+		flowEditGroup.PUT("/:flowID/acl/:userID", svc.GrantFlowAccess)
+		flowEditGroup.DELETE("/:flowID/acl/:userID", svc.RevokeFlowAccess)
+		flowEditGroup.POST("/:flowID/restore", svc.RestoreFlow)
 	}
 
 	flowsViewGroup := parent.Group("/flows")
 	{
 		flowsViewGroup.GET("/", svc.GetFlows)
+		flowsViewGroup.POST("/status", svc.GetFlowsStatus)
+		flowsViewGroup.GET("/events", svc.GetFlowsEvents)
 		flowsViewGroup.GET("/:flowID", svc.GetFlow)
 		flowsViewGroup.GET("/:flowID/graph", svc.GetFlowGraph)
+		flowsViewGroup.GET("/:flowID/functions", svc.GetFlowFunctions)
+		flowsViewGroup.GET("/:flowID/report", svc.GetFlowReport)
+		flowsViewGroup.GET("/:flowID/report.sarif", svc.GetFlowReportSARIF)
+		flowsViewGroup.GET("/:flowID/messages", svc.GetFlowMessages)
+		flowsViewGroup.GET("/:flowID/inputs", svc.GetFlowInputs)
+		flowsViewGroup.GET("/:flowID/events", svc.GetFlowEvents)
+		flowsViewGroup.GET("/:flowID/acl", svc.GetFlowACL)
+	}
+}
+
+func setFlowTemplatesGroup(parent *gin.RouterGroup, svc *services.FlowTemplateService) {
+	flowTemplatesEditGroup := parent.Group("/flow-templates")
+	{
+		flowTemplatesEditGroup.POST("/", svc.CreateFlowTemplate)
+		flowTemplatesEditGroup.PUT("/:templateID", svc.PatchFlowTemplate)
+		flowTemplatesEditGroup.DELETE("/:templateID", svc.DeleteFlowTemplate)
+	}
+
+	flowTemplatesViewGroup := parent.Group("/flow-templates")
+	{
+		flowTemplatesViewGroup.GET("/", svc.GetFlowTemplates)
+		flowTemplatesViewGroup.GET("/:templateID", svc.GetFlowTemplate)
+	}
+}
+
+func setInputTemplatesGroup(parent *gin.RouterGroup, svc *services.InputTemplateService) {
+	inputTemplatesEditGroup := parent.Group("/input-templates")
+	{
+		inputTemplatesEditGroup.POST("/", svc.CreateInputTemplate)
+		inputTemplatesEditGroup.PUT("/:templateID", svc.PatchInputTemplate)
+		inputTemplatesEditGroup.DELETE("/:templateID", svc.DeleteInputTemplate)
+	}
+
+	inputTemplatesViewGroup := parent.Group("/input-templates")
+	{
+		inputTemplatesViewGroup.GET("/", svc.GetInputTemplates)
+		inputTemplatesViewGroup.GET("/:templateID", svc.GetInputTemplate)
+	}
+}
+
+func setModelPricesGroup(parent *gin.RouterGroup, svc *services.ModelPriceService) {
+	modelPricesEditGroup := parent.Group("/model-prices")
+	{
+		modelPricesEditGroup.POST("/", svc.CreateModelPrice)
+		modelPricesEditGroup.PUT("/:priceID", svc.PatchModelPrice)
+		modelPricesEditGroup.DELETE("/:priceID", svc.DeleteModelPrice)
+	}
+
+	modelPricesViewGroup := parent.Group("/model-prices")
+	{
+		modelPricesViewGroup.GET("/", svc.GetModelPrices)
+	}
+}
+
+func setCacheGroup(parent *gin.RouterGroup, svc *services.CacheService) {
+	cacheGroup := parent.Group("/cache")
+	{
+		cacheGroup.GET("/search", svc.GetSearchCacheStats)
+		cacheGroup.DELETE("/search/:toolName", svc.DeleteSearchCache)
+		cacheGroup.DELETE("/providers", svc.DeleteProvidersCache)
 	}
 }
 
@@ -375,6 +492,7 @@ func setContainersGroup(parent *gin.RouterGroup, svc *services.ContainerService)
 	{
 		flowContainersViewGroup.GET("/", svc.GetFlowContainers)
 		flowContainersViewGroup.GET("/:containerID", svc.GetFlowContainer)
+		flowContainersViewGroup.GET("/:containerID/logs", svc.GetFlowContainerLogs)
 	}
 }
 
@@ -487,6 +605,34 @@ func setScreenshotsGroup(parent *gin.RouterGroup, svc *services.ScreenshotServic
 	}
 }
 
+func setFlowAttachmentsGroup(parent *gin.RouterGroup, svc *services.FlowAttachmentService) {
+	flowAttachmentsEditGroup := parent.Group("/flows/:flowID/attachments")
+	{
+		flowAttachmentsEditGroup.POST("/", svc.UploadFlowAttachment)
+	}
+
+	flowAttachmentsViewGroup := parent.Group("/flows/:flowID/attachments")
+	{
+		flowAttachmentsViewGroup.GET("/", svc.GetFlowAttachments)
+		flowAttachmentsViewGroup.GET("/:attachmentID/file", svc.GetFlowAttachmentFile)
+	}
+}
+
+func setFlowMemoriesGroup(parent *gin.RouterGroup, svc *services.FlowMemoryService) {
+	flowMemoriesViewGroup := parent.Group("/flows/:flowID/memories")
+	{
+		flowMemoriesViewGroup.GET("/", svc.GetFlowMemories)
+	}
+}
+
+func setFlowArtifactsGroup(parent *gin.RouterGroup, svc *services.FlowArtifactService) {
+	flowArtifactsViewGroup := parent.Group("/flows/:flowID/artifacts")
+	{
+		flowArtifactsViewGroup.GET("/", svc.GetFlowArtifacts)
+		flowArtifactsViewGroup.GET("/:artifactID/file", svc.GetFlowArtifactFile)
+	}
+}
+
 func setPromptsGroup(parent *gin.RouterGroup, svc *services.PromptService) {
 	promptsViewGroup := parent.Group("/prompts")
 	{
@@ -536,6 +682,11 @@ func setUsersGroup(parent *gin.RouterGroup, svc *services.UserService) {
 	{
 		userViewGroup.GET("/", svc.GetCurrentUser)
 	}
+
+	meViewGroup := parent.Group("/me")
+	{
+		meViewGroup.GET("/permissions", svc.GetCurrentUserPermissions)
+	}
 }
 
 func setAnalyticsGroup(parent *gin.RouterGroup, svc *services.AnalyticsService) {
@@ -553,6 +704,13 @@ func setAnalyticsGroup(parent *gin.RouterGroup, svc *services.AnalyticsService)
 	}
 }
 
+func setAuditGroup(parent *gin.RouterGroup, svc *services.AuditService) {
+	auditViewGroup := parent.Group("/audit")
+	{
+		auditViewGroup.GET("/", svc.GetAuditLogs)
+	}
+}
+
 func setTokensGroup(parent *gin.RouterGroup, svc *services.TokenService) {
 	tokensGroup := parent.Group("/tokens")
 	{
@@ -70,7 +70,7 @@ func (s *TokenService) CreateToken(c *gin.Context) {
 	var req models.CreateAPITokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrTokenInvalidRequest, err)
+		respondBindError(c, response.ErrTokenInvalidRequest, err)
 		return
 	}
 	if err := req.Valid(); err != nil {
@@ -263,7 +263,7 @@ func (s *TokenService) UpdateToken(c *gin.Context) {
 	var req models.UpdateAPITokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrTokenInvalidRequest, err)
+		respondBindError(c, response.ErrTokenInvalidRequest, err)
 		return
 	}
 	if err := req.Valid(); err != nil {
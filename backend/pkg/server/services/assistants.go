@@ -239,6 +239,7 @@ func (s *AssistantService) GetFlowAssistant(c *gin.Context) {
 // @Failure 400 {object} response.errorResp "invalid assistant request data"
 // @Failure 403 {object} response.errorResp "creating assistant not permitted"
 // @Failure 500 {object} response.errorResp "internal error on creating assistant"
+// @Failure 503 {object} response.errorResp "server is shutting down"
 // @Router /flows/{flowID}/assistants/ [post]
 func (s *AssistantService) CreateFlowAssistant(c *gin.Context) {
 	var (
@@ -250,7 +251,7 @@ func (s *AssistantService) CreateFlowAssistant(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&createAssistant); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrAssistantsInvalidRequest, err)
+		respondBindError(c, response.ErrAssistantsInvalidRequest, err)
 		return
 	}
 
@@ -296,7 +297,11 @@ func (s *AssistantService) CreateFlowAssistant(c *gin.Context) {
 	)
 	if err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error creating assistant")
-		response.Error(c, response.ErrInternal, err)
+		if errors.Is(err, controller.ErrServerShuttingDown) {
+			response.Error(c, response.ErrFlowsServerShuttingDown, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
 		return
 	}
 
@@ -335,7 +340,7 @@ func (s *AssistantService) PatchAssistant(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&patchAssistant); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrAssistantsInvalidRequest, err)
+		respondBindError(c, response.ErrAssistantsInvalidRequest, err)
 		return
 	}
 
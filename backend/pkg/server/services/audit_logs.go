@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type auditLogs struct {
+	AuditLogs []models.AuditLog `json:"audit_logs"`
+	Total     uint64            `json:"total"`
+}
+
+type auditLogsGrouped struct {
+	Grouped []string `json:"grouped"`
+	Total   uint64   `json:"total"`
+}
+
+var auditLogsSQLMappers = map[string]any{
+	"id":         "{{table}}.id",
+	"user_id":    "{{table}}.user_id",
+	"action":     "{{table}}.action",
+	"flow_id":    "{{table}}.flow_id",
+	"created_at": "{{table}}.created_at",
+	"data":       "({{table}}.action)",
+}
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+// flowAuditChanges returns the mutable flow fields which differ between the flow states,
+// zero before state means the flow was created
+func flowAuditChanges(before, after models.Flow) models.AuditChanges {
+	fields := []struct {
+		name          string
+		before, after any
+	}{
+		{"status", before.Status, after.Status},
+		{"title", before.Title, after.Title},
+		{"model", before.Model, after.Model},
+		{"model_provider_name", before.ModelProviderName, after.ModelProviderName},
+		{"model_provider_type", before.ModelProviderType, after.ModelProviderType},
+		{"language", before.Language, after.Language},
+		{"description", before.Description, after.Description},
+		{"budget_max_cost", before.BudgetMaxCost, after.BudgetMaxCost},
+		{"budget_max_tokens", before.BudgetMaxTokens, after.BudgetMaxTokens},
+		{"stop_reason", before.StopReason, after.StopReason},
+		{"finish_reason", before.FinishReason, after.FinishReason},
+		{"command_policy", before.CommandPolicy, after.CommandPolicy},
+		{"scope", before.Scope, after.Scope},
+		{"deleted_at", before.DeletedAt, after.DeletedAt},
+	}
+
+	changes := make(models.AuditChanges)
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.before, field.after) {
+			changes[field.name] = models.AuditChange{Before: field.before, After: field.after}
+		}
+	}
+
+	return changes
+}
+
+// writeAuditLog records the flow mutation made by the calling user with the request details,
+// db should be the mutation transaction if the mutation is made by the service itself
+func writeAuditLog(c *gin.Context, db *gorm.DB, action models.AuditAction, flowID uint64, changes models.AuditChanges) error {
+	auditLog := models.AuditLog{
+		UserID:  c.GetUint64("uid"),
+		Action:  action,
+		FlowID:  flowID,
+		Changes: changes,
+	}
+	if c.Request != nil {
+		auditLog.Metadata = models.AuditMetadata{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Method:    c.Request.Method,
+			Path:      c.FullPath(),
+			UserType:  c.GetString("tid"),
+			TokenID:   c.GetString("uuid"),
+		}
+	}
+
+	if err := auditLog.Valid(); err != nil {
+		return err
+	}
+
+	return db.Create(&auditLog).Error
+}
+
+// GetAuditLogs is a function to return audit log of the flow mutations
+// @Summary Retrieve audit logs list
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param request query rdb.TableQuery true "query table params"
+// @Param flow_id query int false "flow id to filter audit logs" minimum(0)
+// @Success 200 {object} response.successResp{data=auditLogs} "audit logs list received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting audit logs not permitted"
+// @Failure 500 {object} response.errorResp "internal error on getting audit logs"
+// @Router /audit/ [get]
+func (s *AuditService) GetAuditLogs(c *gin.Context) {
+	var (
+		err   error
+		query rdb.TableQuery
+		resp  auditLogs
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrAuditLogsInvalidRequest, err)
+		return
+	}
+
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "audit.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db
+	}
+	if value := c.Query("flow_id"); value != "" {
+		flowID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+			response.Error(c, response.ErrAuditLogsInvalidRequest, err)
+			return
+		}
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("audit_logs.flow_id = ?", flowID)
+		}
+	}
+
+	query.Init("audit_logs", auditLogsSQLMappers)
+
+	if query.Group != "" {
+		if _, ok := auditLogsSQLMappers[query.Group]; !ok {
+			logger.FromContext(c).Errorf("error finding audit logs grouped: group field not found")
+			response.Error(c, response.ErrAuditLogsInvalidRequest, errors.New("group field not found"))
+			return
+		}
+
+		var respGrouped auditLogsGrouped
+		if respGrouped.Total, err = query.QueryGrouped(s.db, &respGrouped.Grouped, scope); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error finding audit logs grouped")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+
+		response.Success(c, http.StatusOK, respGrouped)
+		return
+	}
+
+	if resp.Total, err = query.Query(s.db, &resp.AuditLogs, scope); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding audit logs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := 0; i < len(resp.AuditLogs); i++ {
+		if err = resp.AuditLogs[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating audit log data '%d'", resp.AuditLogs[i].ID)
+			response.Error(c, response.ErrAuditLogsInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
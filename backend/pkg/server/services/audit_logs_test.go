@@ -0,0 +1,182 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditLogsTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE flows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'running',
+			title TEXT NOT NULL DEFAULT 'untitled',
+			model TEXT NOT NULL DEFAULT 'gpt-4.1',
+			model_provider_name TEXT NOT NULL DEFAULT 'openai',
+			model_provider_type TEXT NOT NULL DEFAULT 'openai',
+			language TEXT NOT NULL DEFAULT 'English',
+			description TEXT NOT NULL DEFAULT '',
+			tool_call_id_template TEXT NOT NULL DEFAULT 'call_{r:24:x}',
+			trace_id TEXT NOT NULL DEFAULT 'trace',
+			finish_reason TEXT,
+			stop_reason TEXT,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE audit_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			flow_id INTEGER NOT NULL,
+			changes BLOB NOT NULL DEFAULT '{}',
+			metadata BLOB NOT NULL DEFAULT '{}',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec("INSERT INTO flows (id, title, user_id) VALUES (1, 'Scan perimeter', 1), (2, 'Foreign flow', 2)")
+
+	return db
+}
+
+func newAuditTestContext(method, target string, privs []string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("tid", models.UserTypeAPI.String())
+	c.Set("uuid", "token-uuid")
+	c.Set("prm", privs)
+	c.Request, _ = http.NewRequest(method, target, nil)
+	c.Request.Header.Set("User-Agent", "pentagi-test")
+	c.Request.RemoteAddr = "10.0.0.5:4321"
+
+	return c, w
+}
+
+func TestFlowAuditChanges(t *testing.T) {
+	before := models.Flow{ID: 1, Status: models.FlowStatusRunning, Title: "Scan", Language: "English"}
+	after := before
+	after.Status = models.FlowStatusFinished
+	after.Title = "Scan perimeter"
+
+	changes := flowAuditChanges(before, after)
+	require.Len(t, changes, 2)
+	assert.Equal(t, models.AuditChange{Before: models.FlowStatusRunning, After: models.FlowStatusFinished}, changes["status"])
+	assert.Equal(t, models.AuditChange{Before: "Scan", After: "Scan perimeter"}, changes["title"])
+
+	assert.Empty(t, flowAuditChanges(before, before))
+
+	created := flowAuditChanges(models.Flow{}, before)
+	assert.Contains(t, created, "status")
+	assert.Contains(t, created, "language")
+	assert.NotContains(t, created, "description")
+	assert.NotContains(t, created, "deleted_at")
+}
+
+func TestDeleteFlowWritesAuditLog(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	createContainersTestTable(db)
+	svc := &FlowService{db: db}
+
+	var flow models.Flow
+	require.NoError(t, db.Where("id = 1").Take(&flow).Error)
+
+	c, _ := newAuditTestContext(http.MethodDelete, "/flows/1", []string{"flows.delete"})
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ? AND user_id = ?", flow.ID, uint64(1))
+	}
+	_, err := svc.deleteFlow(c, flow, scope)
+	require.NoError(t, err)
+
+	assert.True(t, db.Where("id = 1").Take(&models.Flow{}).RecordNotFound())
+
+	var auditLog models.AuditLog
+	require.NoError(t, db.Where("flow_id = 1").Take(&auditLog).Error)
+	assert.Equal(t, models.AuditActionFlowDelete, auditLog.Action)
+	assert.Equal(t, uint64(1), auditLog.UserID)
+	require.Contains(t, auditLog.Changes, "deleted_at")
+	assert.Nil(t, auditLog.Changes["deleted_at"].Before)
+	assert.NotNil(t, auditLog.Changes["deleted_at"].After)
+	assert.Equal(t, "10.0.0.5", auditLog.Metadata.IP)
+	assert.Equal(t, "pentagi-test", auditLog.Metadata.UserAgent)
+	assert.Equal(t, http.MethodDelete, auditLog.Metadata.Method)
+	assert.Equal(t, models.UserTypeAPI.String(), auditLog.Metadata.UserType)
+	assert.Equal(t, "token-uuid", auditLog.Metadata.TokenID)
+
+	// audit log is rolled back together with the mutation
+	db.Exec("DROP TABLE audit_logs")
+	var foreign models.Flow
+	require.NoError(t, db.Where("id = 2").Take(&foreign).Error)
+	scope = func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", foreign.ID)
+	}
+	_, err = svc.deleteFlow(c, foreign, scope)
+	assert.Error(t, err)
+	assert.False(t, db.Where("id = 2").Take(&models.Flow{}).RecordNotFound())
+}
+
+func TestGetAuditLogs(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	svc := NewAuditService(db)
+
+	c, _ := newAuditTestContext(http.MethodPut, "/flows/1", nil)
+	rename := models.AuditChanges{"title": {Before: "untitled", After: "Scan perimeter"}}
+	require.NoError(t, writeAuditLog(c, db, models.AuditActionFlowRename, 1, rename))
+	require.NoError(t, writeAuditLog(c, db, models.AuditActionFlowStop, 1, nil))
+	require.NoError(t, writeAuditLog(c, db, models.AuditActionFlowInput, 2, nil))
+
+	doGetAuditLogs := func(rawQuery string, privs []string) (*httptest.ResponseRecorder, auditLogs) {
+		c, w := newAuditTestContext(http.MethodGet, "/audit/?page=1&pageSize=-1&type=init"+rawQuery, privs)
+		svc.GetAuditLogs(c)
+
+		var resp struct {
+			Data auditLogs `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp.Data
+	}
+
+	w, resp := doGetAuditLogs("", []string{"audit.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(3), resp.Total)
+
+	w, resp = doGetAuditLogs("&flow_id=1", []string{"audit.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, uint64(2), resp.Total)
+	actions := []models.AuditAction{resp.AuditLogs[0].Action, resp.AuditLogs[1].Action}
+	assert.ElementsMatch(t, []models.AuditAction{models.AuditActionFlowRename, models.AuditActionFlowStop}, actions)
+	for _, auditLog := range resp.AuditLogs {
+		if auditLog.Action == models.AuditActionFlowRename {
+			assert.Equal(t, "Scan perimeter", auditLog.Changes["title"].After)
+		}
+	}
+
+	w, _ = doGetAuditLogs("&flow_id=abc", []string{"audit.view"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = doGetAuditLogs("&flow_id=1", []string{"flows.admin"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondBindError writes the error of the request body decoding or validation, the body over
+// the size limit gets the payload too large error and the failed fields are returned in details
+func respondBindError(c *gin.Context, httpErr *response.HttpError, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		response.Error(c, response.ErrPayloadTooLarge, err)
+		return
+	}
+
+	if fieldErrors := models.GetFieldErrors(err); len(fieldErrors) != 0 {
+		response.ErrorWithDetails(c, httpErr, err, fieldErrors)
+		return
+	}
+
+	response.Error(c, httpErr, err)
+}
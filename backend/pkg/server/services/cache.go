@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/providers"
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/response"
+	"pentagi/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheService struct {
+	search tools.SearchCache
+	provs  providers.ProviderController
+}
+
+func NewCacheService(cfg *config.Config, provs providers.ProviderController) *CacheService {
+	return &CacheService{
+		search: tools.GetSharedSearchCache(cfg),
+		provs:  provs,
+	}
+}
+
+// GetSearchCacheStats is a function to return hit and miss counters of the search results cache
+// @Summary Retrieve search results cache counters per tool
+// @Description Counters are kept in memory of the server instance since its start
+// @Tags Cache
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.successResp{data=[]models.SearchCacheStats} "search cache counters received successful"
+// @Failure 403 {object} response.errorResp "getting search cache counters not permitted"
+// @Router /cache/search [get]
+func (s *CacheService) GetSearchCacheStats(c *gin.Context) {
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.cache.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	stats := s.search.Stats()
+	resp := make([]models.SearchCacheStats, 0, len(stats))
+	for _, st := range stats {
+		resp = append(resp, models.SearchCacheStats{
+			Tool:       st.Tool,
+			TTLSeconds: int64(st.TTL.Seconds()),
+			Hits:       st.Hits,
+			Misses:     st.Misses,
+		})
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// DeleteSearchCache is a function to drop all cached results of the search tool
+// @Summary Invalidate cached results of the search tool
+// @Tags Cache
+// @Produce json
+// @Security BearerAuth
+// @Param toolName path string true "search tool name" example(sploitus)
+// @Success 200 {object} response.successResp "search cache invalidated successful"
+// @Failure 403 {object} response.errorResp "invalidating search cache not permitted"
+// @Failure 404 {object} response.errorResp "search tool not found"
+// @Failure 500 {object} response.errorResp "internal error on invalidating search cache"
+// @Router /cache/search/{toolName} [delete]
+func (s *CacheService) DeleteSearchCache(c *gin.Context) {
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.cache.admin") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	toolName := c.Param("toolName")
+	if tools.GetToolType(toolName) != tools.SearchNetworkToolType {
+		err := fmt.Errorf("tool '%s' is not a search tool", toolName)
+		logger.FromContext(c).WithError(err).Errorf("error invalidating search cache")
+		response.Error(c, response.ErrSearchCacheToolNotFound, err)
+		return
+	}
+
+	if err := s.search.Invalidate(c, toolName); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error invalidating search cache of '%s'", toolName)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, struct{}{})
+}
+
+// DeleteProvidersCache is a function to drop all cached LLM responses
+// @Summary Invalidate cached responses of the LLM providers
+// @Tags Cache
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.successResp "providers cache invalidated successful"
+// @Failure 403 {object} response.errorResp "invalidating providers cache not permitted"
+// @Failure 500 {object} response.errorResp "internal error on invalidating providers cache"
+// @Router /cache/providers [delete]
+func (s *CacheService) DeleteProvidersCache(c *gin.Context) {
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.cache.admin") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err := s.provs.ClearResponseCache(c); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error invalidating providers cache")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, struct{}{})
+}
@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"slices"
 	"strconv"
+	"time"
 
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/rdb"
@@ -39,13 +42,23 @@ var containersSQLMappers = map[string]any{
 	"data":       "({{table}}.type || ' ' || {{table}}.name || ' ' || {{table}}.status || ' ' || {{table}}.local_id || ' ' || {{table}}.local_dir)",
 }
 
+type containerLogs struct {
+	Logs  []models.Termlog `json:"logs"`
+	Total uint64           `json:"total"`
+}
+
+// containerLogsStatusInterval is period to check container status while following its logs
+var containerLogsStatusInterval = 2 * time.Second
+
 type ContainerService struct {
 	db *gorm.DB
+	ss subscriptions.SubscriptionsController
 }
 
-func NewContainerService(db *gorm.DB) *ContainerService {
+func NewContainerService(db *gorm.DB, ss subscriptions.SubscriptionsController) *ContainerService {
 	return &ContainerService{
 		db: db,
+		ss: ss,
 	}
 }
 
@@ -285,3 +298,228 @@ func (s *ContainerService) GetFlowContainer(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, resp)
 }
+
+// GetFlowContainerLogs is a function to return container commands and output history
+// @Summary Retrieve container logs by id and flow id
+// @Description Returns captured terminal logs of the container, with follow=true the response
+// @Description is upgraded to server-sent events and new logs are streamed while the container is running
+// @Tags Containers
+// @Produce json,text/event-stream
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param containerID path int true "container id" minimum(0)
+// @Param request query models.ContainerLogsQuery false "container logs query params"
+// @Success 200 {object} response.successResp{data=containerLogs} "container logs received successful"
+// @Failure 400 {object} response.errorResp "invalid container logs request data"
+// @Failure 403 {object} response.errorResp "getting container logs not permitted"
+// @Failure 404 {object} response.errorResp "container not found"
+// @Failure 500 {object} response.errorResp "internal error on getting container logs"
+// @Router /flows/{flowID}/containers/{containerID}/logs [get]
+func (s *ContainerService) GetFlowContainerLogs(c *gin.Context) {
+	var (
+		err         error
+		containerID uint64
+		flowID      uint64
+		query       models.ContainerLogsQuery
+		cnt         models.Container
+		resp        containerLogs
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrContainersInvalidRequest, err)
+		return
+	}
+	if containerID, err = strconv.ParseUint(c.Param("containerID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing container id")
+		response.Error(c, response.ErrContainersInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrContainersInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "containers.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("f.id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "containers.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("f.id = ? AND f.user_id = ?", flowID, uid)
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	err = s.db.Model(&cnt).
+		Joins("INNER JOIN flows f ON f.id = flow_id").
+		Scopes(scope).
+		Where("containers.id = ?", containerID).
+		Take(&cnt).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting container by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrContainersNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if !query.Follow {
+		if resp.Logs, err = s.getContainerLogs(cnt.ID, 0); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error finding container logs")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+		resp.Total = uint64(len(resp.Logs))
+
+		response.Success(c, http.StatusOK, resp)
+		return
+	}
+
+	s.followContainerLogs(c, cnt)
+}
+
+func (s *ContainerService) getContainerLogs(containerID, afterID uint64) ([]models.Termlog, error) {
+	var logs []models.Termlog
+
+	err := s.db.
+		Where("container_id = ? AND id > ?", containerID, afterID).
+		Order("created_at ASC").
+		Order("id ASC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (s *ContainerService) getContainerStatus(containerID uint64) (models.ContainerStatus, error) {
+	var cnt models.Container
+
+	if err := s.db.Select("status").Where("id = ?", containerID).Take(&cnt).Error; err != nil {
+		return "", err
+	}
+
+	return cnt.Status, nil
+}
+
+// followContainerLogs streams container history and new logs as server-sent events until
+// the container reaches a terminal status or the client goes away
+func (s *ContainerService) followContainerLogs(c *gin.Context, cnt models.Container) {
+	ctx := c.Request.Context()
+
+	// subscribe before reading history to not lose logs which are written in between
+	subscriber := s.ss.NewFlowSubscriber(int64(c.GetUint64("uid")), int64(cnt.FlowID))
+	logsCh, err := subscriber.TerminalLogAdded(ctx)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error subscribing to container logs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	history, err := s.getContainerLogs(cnt.ID, 0)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding container logs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	var lastID uint64
+	send := func(log models.Termlog) {
+		lastID = log.ID
+		c.SSEvent("log", log)
+		c.Writer.Flush()
+	}
+	finish := func(status models.ContainerStatus) {
+		c.SSEvent("end", gin.H{"status": status})
+		c.Writer.Flush()
+	}
+
+	for _, log := range history {
+		send(log)
+	}
+	// send headers to the client even if there is no history yet
+	c.Writer.Flush()
+
+	if cnt.Status.IsTerminal() {
+		finish(cnt.Status)
+		return
+	}
+
+	ticker := time.NewTicker(containerLogsStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case log, ok := <-logsCh:
+			if !ok {
+				return
+			}
+			if uint64(log.Terminal) != cnt.ID || uint64(log.ID) <= lastID {
+				continue
+			}
+			send(convertTerminalLogToTermlog(log))
+		case <-ticker.C:
+			status, err := s.getContainerStatus(cnt.ID)
+			if err != nil {
+				logger.FromContext(c).WithError(err).Errorf("error getting container status")
+				return
+			}
+			if !status.IsTerminal() {
+				continue
+			}
+
+			// flush logs which were written right before the container was stopped
+			tail, err := s.getContainerLogs(cnt.ID, lastID)
+			if err != nil {
+				logger.FromContext(c).WithError(err).Errorf("error finding container logs")
+				return
+			}
+			for _, log := range tail {
+				send(log)
+			}
+			finish(status)
+			return
+		}
+	}
+}
+
+func convertTerminalLogToTermlog(log *model.TerminalLog) models.Termlog {
+	termlog := models.Termlog{
+		ID:          uint64(log.ID),
+		Type:        models.TermlogType(log.Type),
+		Text:        log.Text,
+		ContainerID: uint64(log.Terminal),
+		FlowID:      uint64(log.FlowID),
+		CreatedAt:   log.CreatedAt,
+	}
+	if log.TaskID != nil {
+		taskID := uint64(*log.TaskID)
+		termlog.TaskID = &taskID
+	}
+	if log.SubtaskID != nil {
+		subtaskID := uint64(*log.SubtaskID)
+		termlog.SubtaskID = &subtaskID
+	}
+
+	return termlog
+}
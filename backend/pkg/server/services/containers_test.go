@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/graph/subscriptions"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupContainerLogsTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE flows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			deleted_at DATETIME
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE containers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL DEFAULT 'primary',
+			name TEXT NOT NULL,
+			image TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'starting',
+			local_id TEXT NOT NULL DEFAULT '',
+			local_dir TEXT NOT NULL DEFAULT '',
+			flow_id INTEGER NOT NULL,
+			cpu_limit REAL NOT NULL DEFAULT 0,
+			memory_limit INTEGER NOT NULL DEFAULT 0,
+			pids_limit INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE termlogs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			text TEXT NOT NULL,
+			container_id INTEGER NOT NULL,
+			flow_id INTEGER NOT NULL,
+			task_id INTEGER NULL,
+			subtask_id INTEGER NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec("INSERT INTO flows (id, user_id) VALUES (1, 1), (2, 2)")
+	db.Exec(`INSERT INTO containers (id, name, image, status, flow_id) VALUES
+		(1, 'pentagi-terminal-1', 'kali', 'running', 1),
+		(2, 'pentagi-terminal-2', 'kali', 'stopped', 2)`)
+	db.Exec(`INSERT INTO termlogs (id, type, text, container_id, flow_id, created_at) VALUES
+		(2, 'stdout', 'root', 1, 1, '2026-01-01 10:00:01'),
+		(1, 'stdin', 'whoami', 1, 1, '2026-01-01 10:00:00'),
+		(3, 'stdin', 'id', 2, 2, '2026-01-01 10:00:00')`)
+
+	return db
+}
+
+// notifySubscriptions signals when the terminal logs subscription is created
+type notifySubscriptions struct {
+	subscriptions.SubscriptionsController
+	ready chan struct{}
+}
+
+func (ns *notifySubscriptions) NewFlowSubscriber(userID, flowID int64, types ...subscriptions.EventType) subscriptions.FlowSubscriber {
+	return &notifySubscriber{
+		FlowSubscriber: ns.SubscriptionsController.NewFlowSubscriber(userID, flowID, types...),
+		ready:          ns.ready,
+	}
+}
+
+type notifySubscriber struct {
+	subscriptions.FlowSubscriber
+	ready chan struct{}
+}
+
+func (ns *notifySubscriber) TerminalLogAdded(ctx context.Context) (<-chan *model.TerminalLog, error) {
+	defer close(ns.ready)
+	return ns.FlowSubscriber.TerminalLogAdded(ctx)
+}
+
+func newContainerLogsContext(flowID, containerID, rawQuery string, privs []string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}, {Key: "containerID", Value: containerID}}
+	c.Request, _ = http.NewRequest("GET", "/flows/"+flowID+"/containers/"+containerID+"/logs?"+rawQuery, nil)
+
+	return c, w
+}
+
+func TestGetFlowContainerLogs(t *testing.T) {
+	db := setupContainerLogsTestDB(t)
+	defer db.Close()
+
+	service := NewContainerService(db, nil)
+
+	c, w := newContainerLogsContext("1", "1", "", []string{"containers.view"})
+	service.GetFlowContainerLogs(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Data containerLogs `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, uint64(2), resp.Data.Total)
+	assert.Equal(t, "whoami", resp.Data.Logs[0].Text)
+	assert.Equal(t, "root", resp.Data.Logs[1].Text)
+
+	c, w = newContainerLogsContext("2", "2", "", []string{"containers.view"})
+	service.GetFlowContainerLogs(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = newContainerLogsContext("1", "2", "", []string{"containers.admin"})
+	service.GetFlowContainerLogs(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = newContainerLogsContext("1", "1", "", []string{"termlogs.view"})
+	service.GetFlowContainerLogs(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetFlowContainerLogs_FollowStoppedContainer(t *testing.T) {
+	db := setupContainerLogsTestDB(t)
+	defer db.Close()
+
+	service := NewContainerService(db, subscriptions.NewSubscriptionsController())
+
+	c, w := newContainerLogsContext("2", "2", "follow=true", []string{"containers.admin"})
+	service.GetFlowContainerLogs(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, body, "event:log\ndata:")
+	assert.Contains(t, body, `"text":"id"`)
+	assert.True(t, strings.HasSuffix(body, "event:end\ndata:{\"status\":\"stopped\"}\n\n"), body)
+}
+
+func TestGetFlowContainerLogs_FollowRunningContainer(t *testing.T) {
+	db := setupContainerLogsTestDB(t)
+	defer db.Close()
+
+	interval := containerLogsStatusInterval
+	containerLogsStatusInterval = 10 * time.Millisecond
+	defer func() { containerLogsStatusInterval = interval }()
+
+	subs := subscriptions.NewSubscriptionsController()
+	ready := make(chan struct{})
+	service := NewContainerService(db, &notifySubscriptions{SubscriptionsController: subs, ready: ready})
+
+	c, w := newContainerLogsContext("1", "1", "follow=true", []string{"containers.view"})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.GetFlowContainerLogs(c)
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not created")
+	}
+
+	pub := subs.NewFlowPublisher(1, 1)
+	// logs of other containers in the flow must be skipped
+	pub.TerminalLogAdded(context.Background(), database.Termlog{
+		ID: 10, Type: database.TermlogTypeStdout, Text: "other", ContainerID: 5, FlowID: 1,
+	})
+	pub.TerminalLogAdded(context.Background(), database.Termlog{
+		ID: 11, Type: database.TermlogTypeStdin, Text: "uname -a", ContainerID: 1, FlowID: 1,
+		CreatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+
+	// the log which was written right before stop must be sent from database
+	db.Exec(`INSERT INTO termlogs (id, type, text, container_id, flow_id) VALUES (12, 'stdout', 'Linux', 1, 1)`)
+	db.Exec(`UPDATE containers SET status = 'stopped' WHERE id = 1`)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not finished after container stop")
+	}
+
+	body := w.Body.String()
+	assert.NotContains(t, body, `"text":"other"`)
+	order := []string{`"text":"whoami"`, `"text":"root"`, `"text":"uname -a"`, `"text":"Linux"`, "event:end"}
+	last := -1
+	for _, part := range order {
+		idx := strings.Index(body, part)
+		require.Greater(t, idx, last, "unexpected position of %s in %s", part, body)
+		last = idx
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type flowACLs struct {
+	FlowACLs []models.FlowACL `json:"flow_acls"`
+	Total    uint64           `json:"total"`
+}
+
+// sharedFlows returns the subquery of flow ids shared with the user on any of the access levels
+// or on any level if they are not set
+func (s *FlowService) sharedFlows(uid uint64, access ...models.FlowAccess) any {
+	return sharedFlowsQuery(s.db, uid, access...)
+}
+
+// sharedFlowsQuery is the subquery of flow ids shared with the user for the services out of FlowService
+func sharedFlowsQuery(db *gorm.DB, uid uint64, access ...models.FlowAccess) any {
+	query := db.Table("flow_acls").Select("flow_acls.flow_id").Where("flow_acls.user_id = ?", uid)
+	if len(access) != 0 {
+		levels := make([]string, 0, len(access))
+		for _, level := range access {
+			levels = append(levels, level.String())
+		}
+		query = query.Where("flow_acls.access IN (?)", levels)
+	}
+
+	return query.SubQuery()
+}
+
+// isFlowShared returns true if the flow is shared with the user on any access level
+func (s *FlowService) isFlowShared(flowID, uid uint64) bool {
+	var count uint64
+	err := s.db.Model(&models.FlowACL{}).
+		Where("flow_id = ? AND user_id = ?", flowID, uid).
+		Count(&count).Error
+
+	return err == nil && count != 0
+}
+
+// flowShareScope returns the flow if the user is its owner or flows admin,
+// shared users can't share the flow further
+func flowShareScope(flowID, uid uint64, privs []string) func(db *gorm.DB) *gorm.DB {
+	if slices.Contains(privs, "flows.admin") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND user_id = ?", flowID, uid)
+		}
+	}
+
+	return nil
+}
+
+// getSharedFlow returns the flow which can be shared by the calling user or writes the error response
+func (s *FlowService) getSharedFlow(c *gin.Context) (models.Flow, bool) {
+	var (
+		err    error
+		flow   models.Flow
+		flowID uint64
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return flow, false
+	}
+
+	scope := flowShareScope(flowID, c.GetUint64("uid"), c.GetStringSlice("prm"))
+	if scope == nil {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return flow, false
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return flow, false
+	}
+
+	return flow, true
+}
+
+// GetFlowACL is a function to return users the flow is shared with
+// @Summary Retrieve flow access list by flow id
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Success 200 {object} response.successResp{data=flowACLs} "flow access list received successful"
+// @Failure 400 {object} response.errorResp "invalid flow request data"
+// @Failure 403 {object} response.errorResp "getting flow access list not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow access list"
+// @Router /flows/{flowID}/acl [get]
+func (s *FlowService) GetFlowACL(c *gin.Context) {
+	var resp flowACLs
+
+	flow, ok := s.getSharedFlow(c)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Where("flow_id = ?", flow.ID).Order("id ASC").Find(&resp.FlowACLs).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding flow access list")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	resp.Total = uint64(len(resp.FlowACLs))
+
+	for i := 0; i < len(resp.FlowACLs); i++ {
+		if err := resp.FlowACLs[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow access data '%d'", resp.FlowACLs[i].ID)
+			response.Error(c, response.ErrFlowACLsInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GrantFlowAccess is a function to share the flow with the user or to change the user access level
+// @Summary Grant access to the flow for the user
+// @Description Shared users get the flow with their own role privileges, edit access is required to change it
+// @Tags Flows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param userID path int true "user id to share the flow with" minimum(0)
+// @Param json body models.GrantFlowAccess true "flow access level"
+// @Success 200 {object} response.successResp{data=models.FlowACL} "flow access granted successful"
+// @Failure 400 {object} response.errorResp "invalid flow access request data"
+// @Failure 403 {object} response.errorResp "sharing flow not permitted"
+// @Failure 404 {object} response.errorResp "flow or user not found"
+// @Failure 500 {object} response.errorResp "internal error on granting flow access"
+// @Router /flows/{flowID}/acl/{userID} [put]
+func (s *FlowService) GrantFlowAccess(c *gin.Context) {
+	var (
+		err    error
+		acl    models.FlowACL
+		grant  models.GrantFlowAccess
+		user   models.User
+		userID uint64
+	)
+
+	if err = c.ShouldBindJSON(&grant); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowACLsInvalidRequest, err)
+		return
+	}
+
+	if err = grant.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow access data")
+		response.Error(c, response.ErrFlowACLsInvalidRequest, err)
+		return
+	}
+
+	if userID, err = strconv.ParseUint(c.Param("userID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing user id")
+		response.Error(c, response.ErrFlowACLsInvalidRequest, err)
+		return
+	}
+
+	flow, ok := s.getSharedFlow(c)
+	if !ok {
+		return
+	}
+
+	if userID == flow.UserID {
+		logger.FromContext(c).Errorf("error sharing flow with its owner")
+		response.Error(c, response.ErrFlowACLsInvalidRequest, nil)
+		return
+	}
+
+	if err = s.db.Take(&user, "id = ?", userID).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding user to share the flow with")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowACLsUserNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	err = s.db.Where("flow_id = ? AND user_id = ?", flow.ID, userID).Take(&acl).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		logger.FromContext(c).WithError(err).Errorf("error finding flow access")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err == nil {
+		err = s.db.Model(&acl).Update("access", grant.Access).Error
+	} else {
+		acl = models.FlowACL{FlowID: flow.ID, UserID: userID, Access: grant.Access}
+		err = s.db.Create(&acl).Error
+	}
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error saving flow access")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, acl)
+}
+
+// RevokeFlowAccess is a function to stop sharing the flow with the user
+// @Summary Revoke access to the flow from the user
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param userID path int true "user id to revoke the flow access from" minimum(0)
+// @Success 200 {object} response.successResp "flow access revoked successful"
+// @Failure 400 {object} response.errorResp "invalid flow access request data"
+// @Failure 403 {object} response.errorResp "revoking flow access not permitted"
+// @Failure 404 {object} response.errorResp "flow or flow access not found"
+// @Failure 500 {object} response.errorResp "internal error on revoking flow access"
+// @Router /flows/{flowID}/acl/{userID} [delete]
+func (s *FlowService) RevokeFlowAccess(c *gin.Context) {
+	var (
+		err    error
+		userID uint64
+	)
+
+	if userID, err = strconv.ParseUint(c.Param("userID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing user id")
+		response.Error(c, response.ErrFlowACLsInvalidRequest, err)
+		return
+	}
+
+	flow, ok := s.getSharedFlow(c)
+	if !ok {
+		return
+	}
+
+	result := s.db.Where("flow_id = ? AND user_id = ?", flow.ID, userID).Delete(&models.FlowACL{})
+	if result.Error != nil {
+		logger.FromContext(c).WithError(result.Error).Errorf("error deleting flow access")
+		response.Error(c, response.ErrInternal, result.Error)
+		return
+	} else if result.RowsAffected == 0 {
+		logger.FromContext(c).Errorf("error deleting flow access: not found")
+		response.Error(c, response.ErrFlowACLsNotFound, nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, struct{}{})
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFlowACLsTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL DEFAULT ''
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE flows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'finished',
+			title TEXT NOT NULL DEFAULT 'untitled',
+			model TEXT NOT NULL DEFAULT 'gpt-4.1',
+			model_provider_name TEXT NOT NULL DEFAULT 'openai',
+			model_provider_type TEXT NOT NULL DEFAULT 'openai',
+			language TEXT NOT NULL DEFAULT 'English',
+			tool_call_id_template TEXT NOT NULL DEFAULT 'call_{r:24:x}',
+			trace_id TEXT NOT NULL DEFAULT 'trace',
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE flow_acls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			access TEXT NOT NULL DEFAULT 'view',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (flow_id, user_id)
+		)
+	`)
+
+	db.Exec("INSERT INTO users (id, name) VALUES (1, 'owner'), (2, 'viewer'), (3, 'editor')")
+	db.Exec(`INSERT INTO flows (id, title, user_id) VALUES
+		(1, 'shared for view', 1), (2, 'shared for edit', 1), (3, 'private', 1), (4, 'own', 2)`)
+	db.Exec(`INSERT INTO flow_acls (flow_id, user_id, access) VALUES
+		(1, 2, 'view'), (2, 2, 'edit'), (2, 3, 'view')`)
+
+	return db
+}
+
+func doFlowACLRequest(
+	t *testing.T,
+	handler gin.HandlerFunc,
+	method, flowID, userID, body string,
+	uid uint64,
+	privs []string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uid)
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}, {Key: "userID", Value: userID}}
+	c.Request, _ = http.NewRequest(method, "/flows/"+flowID+"/acl/"+userID, bytes.NewBufferString(body))
+	c.Request.URL.RawQuery = "page=1&pageSize=-1&type=init"
+
+	handler(c)
+
+	return w
+}
+
+func TestFlowACLs_ViewScopes(t *testing.T) {
+	db := setupFlowACLsTestDB(t)
+	defer db.Close()
+
+	service := NewFlowService(db, nil, nil, nil, nil)
+	viewer := []string{"flows.view", "flows.edit"}
+
+	w := doFlowACLRequest(t, service.GetFlows, http.MethodGet, "", "", "", 2, viewer)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Data flows `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	var ids []uint64
+	for _, flow := range list.Data.Flows {
+		ids = append(ids, flow.ID)
+	}
+	assert.ElementsMatch(t, []uint64{1, 2, 4}, ids)
+
+	for flowID, code := range map[string]int{"1": http.StatusOK, "2": http.StatusOK, "3": http.StatusNotFound} {
+		w = doFlowACLRequest(t, service.GetFlow, http.MethodGet, flowID, "", "", 2, viewer)
+		assert.Equal(t, code, w.Code, flowID)
+	}
+
+	// edit access is granted explicitly only
+	var editable []uint64
+	require.NoError(t, db.Model(&models.Flow{}).
+		Where("user_id = ? OR id IN (?)", 2, service.sharedFlows(2, models.FlowAccessEdit)).
+		Order("id").Pluck("id", &editable).Error)
+	assert.Equal(t, []uint64{2, 4}, editable)
+
+	w = doFlowACLRequest(t, service.GetFlow, http.MethodGet, "1", "", "", 3, viewer)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFlowACLs_GrantAndRevoke(t *testing.T) {
+	db := setupFlowACLsTestDB(t)
+	defer db.Close()
+
+	service := NewFlowService(db, nil, nil, nil, nil)
+	owner := []string{"flows.view"}
+
+	w := doFlowACLRequest(t, service.GrantFlowAccess, http.MethodPut, "3", "3", `{"access":"edit"}`, 1, owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, service.isFlowShared(3, 3))
+
+	// repeated grant changes the access level
+	w = doFlowACLRequest(t, service.GrantFlowAccess, http.MethodPut, "3", "3", `{"access":"view"}`, 1, owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var acls []models.FlowACL
+	require.NoError(t, db.Where("flow_id = 3").Find(&acls).Error)
+	require.Len(t, acls, 1)
+	assert.Equal(t, models.FlowAccessView, acls[0].Access)
+
+	w = doFlowACLRequest(t, service.GetFlowACL, http.MethodGet, "2", "", "", 1, owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Data flowACLs `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, uint64(2), list.Data.Total)
+
+	invalid := map[string]struct {
+		flowID, userID, body string
+		code                 int
+	}{
+		"unknown access":   {"3", "2", `{"access":"owner"}`, http.StatusBadRequest},
+		"share with owner": {"3", "1", `{"access":"view"}`, http.StatusBadRequest},
+		"unknown user":     {"3", "42", `{"access":"view"}`, http.StatusNotFound},
+		"foreign flow":     {"4", "3", `{"access":"view"}`, http.StatusNotFound},
+	}
+	for name, tc := range invalid {
+		w = doFlowACLRequest(t, service.GrantFlowAccess, http.MethodPut, tc.flowID, tc.userID, tc.body, 1, owner)
+		assert.Equal(t, tc.code, w.Code, name)
+	}
+
+	// shared users can't share the flow further, flows admin can
+	w = doFlowACLRequest(t, service.GrantFlowAccess, http.MethodPut, "2", "3", `{"access":"edit"}`, 2, owner)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doFlowACLRequest(t, service.GrantFlowAccess, http.MethodPut, "4", "3", `{"access":"edit"}`, 1, []string{"flows.admin"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doFlowACLRequest(t, service.GetFlowACL, http.MethodGet, "1", "", "", 1, []string{"tasks.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doFlowACLRequest(t, service.RevokeFlowAccess, http.MethodDelete, "1", "2", "", 2, owner)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doFlowACLRequest(t, service.RevokeFlowAccess, http.MethodDelete, "1", "2", "", 1, owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, service.isFlowShared(1, 2))
+	w = doFlowACLRequest(t, service.RevokeFlowAccess, http.MethodDelete, "1", "2", "", 1, owner)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
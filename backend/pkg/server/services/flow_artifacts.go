@@ -0,0 +1,162 @@
+package services
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/response"
+	"pentagi/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type flowArtifacts struct {
+	FlowArtifacts []models.FlowArtifact `json:"flow_artifacts"`
+	Total         uint64                `json:"total"`
+}
+
+type FlowArtifactService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewFlowArtifactService(db *gorm.DB, cfg *config.Config) *FlowArtifactService {
+	return &FlowArtifactService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// getFlow returns the flow which is visible to the user or writes the error response
+func (s *FlowArtifactService) getFlow(c *gin.Context) (models.Flow, bool) {
+	var flow models.Flow
+
+	flowID, err := strconv.ParseUint(c.Param("flowID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowArtifactsInvalidRequest, err)
+		return flow, false
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, sharedFlowsQuery(s.db, uid))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return flow, false
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return flow, false
+	}
+
+	return flow, true
+}
+
+// GetFlowArtifacts is a function to return the artifacts saved by the agents to the flow
+// @Summary Retrieve flow artifacts list
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Success 200 {object} response.successResp{data=flowArtifacts} "flow artifacts received successful"
+// @Failure 400 {object} response.errorResp "invalid flow artifacts request data"
+// @Failure 403 {object} response.errorResp "getting flow artifacts not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow artifacts"
+// @Router /flows/{flowID}/artifacts/ [get]
+func (s *FlowArtifactService) GetFlowArtifacts(c *gin.Context) {
+	var resp flowArtifacts
+
+	flow, ok := s.getFlow(c)
+	if !ok {
+		return
+	}
+
+	err := s.db.Model(&resp.FlowArtifacts).
+		Where("flow_id = ?", flow.ID).
+		Order("created_at ASC, id ASC").
+		Find(&resp.FlowArtifacts).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding flow artifacts")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := range resp.FlowArtifacts {
+		if err = resp.FlowArtifacts[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow artifact data '%d'", resp.FlowArtifacts[i].ID)
+			response.Error(c, response.ErrFlowArtifactsInvalidData, err)
+			return
+		}
+		resp.FlowArtifacts[i].URL = tools.FlowArtifactURL(int64(flow.ID), int64(resp.FlowArtifacts[i].ID))
+	}
+	resp.Total = uint64(len(resp.FlowArtifacts))
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetFlowArtifactFile is a function to download the content of the flow artifact
+// @Summary Retrieve flow artifact file by id
+// @Tags Flows
+// @Produce octet-stream,json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param artifactID path int true "artifact id" minimum(0)
+// @Success 200 {file} file "flow artifact file"
+// @Failure 400 {object} response.errorResp "invalid flow artifact request data"
+// @Failure 403 {object} response.errorResp "getting flow artifact not permitted"
+// @Failure 404 {object} response.errorResp "flow or artifact not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow artifact"
+// @Router /flows/{flowID}/artifacts/{artifactID}/file [get]
+func (s *FlowArtifactService) GetFlowArtifactFile(c *gin.Context) {
+	var artifact models.FlowArtifact
+
+	artifactID, err := strconv.ParseUint(c.Param("artifactID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing artifact id")
+		response.Error(c, response.ErrFlowArtifactsInvalidRequest, err)
+		return
+	}
+
+	flow, ok := s.getFlow(c)
+	if !ok {
+		return
+	}
+
+	err = s.db.Model(&artifact).
+		Where("id = ? AND flow_id = ?", artifactID, flow.ID).
+		Take(&artifact).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow artifact by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowArtifactsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	c.Header("Content-Type", artifact.ContentType)
+	c.FileAttachment(tools.FlowArtifactPath(s.cfg, int64(flow.ID), int64(artifact.ID)), artifact.Name)
+}
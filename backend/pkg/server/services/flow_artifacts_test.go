@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testArtifactChecksum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func setupFlowArtifactsTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := setupFlowACLsTestDB(t)
+
+	db.Exec(`
+		CREATE TABLE flow_artifacts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			content_type TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			flow_id INTEGER NOT NULL,
+			task_id INTEGER,
+			subtask_id INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (flow_id, name)
+		)
+	`)
+
+	db.Exec(`INSERT INTO flow_artifacts (name, size, content_type, checksum, flow_id, task_id) VALUES
+		('nmap.xml', 4, 'text/xml', ?, 1, 7),
+		('exploit.py', 4, 'text/x-python', ?, 1, NULL),
+		('own.txt', 4, 'text/plain', ?, 4, NULL)`,
+		testArtifactChecksum, testArtifactChecksum, testArtifactChecksum)
+
+	return db
+}
+
+func doFlowArtifactFileRequest(
+	t *testing.T,
+	service *FlowArtifactService,
+	flowID, artifactID string,
+	uid uint64,
+	privs []string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uid)
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}, {Key: "artifactID", Value: artifactID}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/flows/"+flowID+"/artifacts/"+artifactID+"/file", nil)
+
+	service.GetFlowArtifactFile(c)
+
+	return w
+}
+
+func TestGetFlowArtifacts(t *testing.T) {
+	db := setupFlowArtifactsTestDB(t)
+	defer db.Close()
+
+	service := NewFlowArtifactService(db, &config.Config{ArtifactsDir: t.TempDir()})
+
+	cases := map[string]struct {
+		flowID string
+		uid    uint64
+		privs  []string
+		code   int
+		total  uint64
+	}{
+		"owner":           {"1", 1, []string{"flows.view"}, http.StatusOK, 2},
+		"shared for view": {"1", 2, []string{"flows.view"}, http.StatusOK, 2},
+		"not shared":      {"1", 3, []string{"flows.view"}, http.StatusNotFound, 0},
+		"admin":           {"4", 1, []string{"flows.admin"}, http.StatusOK, 1},
+		"no privilege":    {"1", 1, []string{"flows.edit"}, http.StatusForbidden, 0},
+		"invalid flow id": {"abc", 1, []string{"flows.view"}, http.StatusBadRequest, 0},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := doFlowACLRequest(t, service.GetFlowArtifacts, http.MethodGet, tc.flowID, "", "", tc.uid, tc.privs)
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data flowArtifacts `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tc.total, resp.Data.Total)
+			for _, item := range resp.Data.FlowArtifacts {
+				assert.Equal(t, tc.flowID, strconv.FormatUint(item.FlowID, 10), "artifacts of the other flows leaked")
+				assert.Equal(t, tools.FlowArtifactURL(int64(item.FlowID), int64(item.ID)), item.URL)
+			}
+		})
+	}
+}
+
+func TestGetFlowArtifactFile(t *testing.T) {
+	db := setupFlowArtifactsTestDB(t)
+	defer db.Close()
+
+	cfg := &config.Config{ArtifactsDir: t.TempDir()}
+	service := NewFlowArtifactService(db, cfg)
+
+	path := tools.FlowArtifactPath(cfg, 1, 1)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("test"), 0644))
+
+	w := doFlowArtifactFileRequest(t, service, "1", "1", 2, []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "test", w.Body.String())
+	assert.Equal(t, "text/xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "nmap.xml")
+
+	// the artifact of the other flow isn't found by the accessible flow id
+	w = doFlowArtifactFileRequest(t, service, "1", "3", 1, []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	w = doFlowArtifactFileRequest(t, service, "1", "1", 3, []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	w = doFlowArtifactFileRequest(t, service, "1", "abc", 1, []string{"flows.view"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
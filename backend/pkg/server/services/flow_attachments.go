@@ -0,0 +1,393 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/controller"
+	"pentagi/pkg/database"
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/response"
+	"pentagi/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// flowAttachmentFormField is the multipart form field of the uploaded attachment content
+const flowAttachmentFormField = "file"
+
+// flowAttachmentMaxNameLength limits the attachment name which is used as the file name in the container
+const flowAttachmentMaxNameLength = 255
+
+const flowAttachmentDefaultContentType = "application/octet-stream"
+
+type flowAttachments struct {
+	FlowAttachments []models.FlowAttachment `json:"flow_attachments"`
+	Total           uint64                  `json:"total"`
+}
+
+type FlowAttachmentService struct {
+	db  *gorm.DB
+	cfg *config.Config
+	fc  controller.FlowController
+}
+
+func NewFlowAttachmentService(db *gorm.DB, cfg *config.Config, fc controller.FlowController) *FlowAttachmentService {
+	return &FlowAttachmentService{
+		db:  db,
+		cfg: cfg,
+		fc:  fc,
+	}
+}
+
+// flowScope returns the scope of the flow by the user privileges, view privilege is used to read
+// the attachments and edit one to upload them, nil is returned if the user has no privilege
+func (s *FlowAttachmentService) flowScope(c *gin.Context, flowID uint64, edit bool) func(db *gorm.DB) *gorm.DB {
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+
+	if slices.Contains(privs, "flows.admin") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	}
+
+	if edit && slices.Contains(privs, "flows.edit") {
+		return func(db *gorm.DB) *gorm.DB {
+			shared := sharedFlowsQuery(s.db, uid, models.FlowAccessEdit)
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, shared)
+		}
+	}
+
+	if !edit && slices.Contains(privs, "flows.view") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, sharedFlowsQuery(s.db, uid))
+		}
+	}
+
+	return nil
+}
+
+// getFlow returns the flow accessible by the user or writes the error response
+func (s *FlowAttachmentService) getFlow(c *gin.Context, edit bool) (models.Flow, bool) {
+	var flow models.Flow
+
+	flowID, err := strconv.ParseUint(c.Param("flowID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return flow, false
+	}
+
+	scope := s.flowScope(c, flowID, edit)
+	if scope == nil {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return flow, false
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return flow, false
+	}
+
+	return flow, true
+}
+
+// GetFlowAttachments is a function to return the files attached to the flow
+// @Summary Retrieve flow attachments list
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Success 200 {object} response.successResp{data=flowAttachments} "flow attachments received successful"
+// @Failure 400 {object} response.errorResp "invalid flow attachments request data"
+// @Failure 403 {object} response.errorResp "getting flow attachments not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow attachments"
+// @Router /flows/{flowID}/attachments/ [get]
+func (s *FlowAttachmentService) GetFlowAttachments(c *gin.Context) {
+	var resp flowAttachments
+
+	flow, ok := s.getFlow(c, false)
+	if !ok {
+		return
+	}
+
+	err := s.db.Model(&resp.FlowAttachments).
+		Where("flow_id = ?", flow.ID).
+		Order("created_at ASC, id ASC").
+		Find(&resp.FlowAttachments).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding flow attachments")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := range resp.FlowAttachments {
+		resp.FlowAttachments[i].Path = tools.AttachmentPathInContainer(resp.FlowAttachments[i].Name)
+	}
+	resp.Total = uint64(len(resp.FlowAttachments))
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetFlowAttachmentFile is a function to download the content of the flow attachment
+// @Summary Retrieve flow attachment file by id
+// @Tags Flows
+// @Produce octet-stream,json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param attachmentID path int true "attachment id" minimum(0)
+// @Success 200 {file} file "flow attachment file"
+// @Failure 400 {object} response.errorResp "invalid flow attachment request data"
+// @Failure 403 {object} response.errorResp "getting flow attachment not permitted"
+// @Failure 404 {object} response.errorResp "flow or attachment not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow attachment"
+// @Router /flows/{flowID}/attachments/{attachmentID}/file [get]
+func (s *FlowAttachmentService) GetFlowAttachmentFile(c *gin.Context) {
+	var attachment models.FlowAttachment
+
+	attachmentID, err := strconv.ParseUint(c.Param("attachmentID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing attachment id")
+		response.Error(c, response.ErrFlowAttachmentsInvalidRequest, err)
+		return
+	}
+
+	flow, ok := s.getFlow(c, false)
+	if !ok {
+		return
+	}
+
+	err = s.db.Model(&attachment).
+		Where("id = ? AND flow_id = ?", attachmentID, flow.ID).
+		Take(&attachment).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow attachment by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowAttachmentsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.FileAttachment(tools.FlowAttachmentPath(s.cfg, int64(flow.ID), int64(attachment.ID)), attachment.Name)
+}
+
+// UploadFlowAttachment is a function to attach the file to the flow context
+// @Summary Upload flow attachment
+// @Description The file is copied to the flow container and listed in the agents context, the name must be unique in the flow
+// @Tags Flows
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param file formData file true "attachment content"
+// @Success 201 {object} response.successResp{data=models.FlowAttachment} "flow attachment uploaded successful"
+// @Failure 400 {object} response.errorResp "invalid flow attachment request data"
+// @Failure 403 {object} response.errorResp "uploading flow attachment not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 409 {object} response.errorResp "flow attachment with the same name already exists"
+// @Failure 413 {object} response.errorResp "flow attachment is too large"
+// @Failure 500 {object} response.errorResp "internal error on uploading flow attachment"
+// @Router /flows/{flowID}/attachments/ [post]
+func (s *FlowAttachmentService) UploadFlowAttachment(c *gin.Context) {
+	flow, ok := s.getFlow(c, true)
+	if !ok {
+		return
+	}
+
+	part, err := flowAttachmentPart(c.Request)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error reading multipart form")
+		s.respondUploadError(c, err)
+		return
+	}
+	defer part.Close()
+
+	name, err := flowAttachmentName(part.FileName())
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating attachment name")
+		response.Error(c, response.ErrFlowAttachmentsInvalidRequest, err)
+		return
+	}
+
+	var count uint64
+	err = s.db.Model(&models.FlowAttachment{}).
+		Where("flow_id = ? AND name = ?", flow.ID, name).
+		Count(&count).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error checking flow attachment name")
+		response.Error(c, response.ErrInternal, err)
+		return
+	} else if count != 0 {
+		err = fmt.Errorf("attachment '%s' already exists in the flow %d", name, flow.ID)
+		response.Error(c, response.ErrFlowAttachmentsAlreadyExists, err)
+		return
+	}
+
+	dir := tools.FlowAttachmentsDir(s.cfg, int64(flow.ID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating flow attachments directory")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	// content is uploaded to the temporary file which gets the name by the attachment id after commit
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating flow attachment file")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(part, s.cfg.AttachmentsMaxSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size > s.cfg.AttachmentsMaxSize {
+		err = &http.MaxBytesError{Limit: s.cfg.AttachmentsMaxSize}
+	}
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error writing flow attachment file")
+		s.respondUploadError(c, err)
+		return
+	}
+
+	contentType := part.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = flowAttachmentDefaultContentType
+	}
+
+	attachment := models.FlowAttachment{
+		Name:        name,
+		Size:        size,
+		ContentType: contentType,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		FlowID:      flow.ID,
+		UserID:      c.GetUint64("uid"),
+	}
+	if err := s.db.Create(&attachment).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating flow attachment")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), tools.FlowAttachmentPath(s.cfg, int64(flow.ID), int64(attachment.ID))); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error storing flow attachment file")
+		if err := s.db.Delete(&attachment).Error; err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error deleting flow attachment")
+		}
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	attachment.Path = tools.AttachmentPathInContainer(attachment.Name)
+
+	if s.fc != nil {
+		if err := s.fc.PutFlowAttachment(c, int64(flow.ID), convertFlowAttachmentToDatabase(attachment)); err != nil {
+			logger.FromContext(c).WithError(err).Warnf("error copying flow attachment to container")
+		}
+	}
+
+	changes := models.AuditChanges{
+		"attachment": models.AuditChange{Before: nil, After: attachment.Name},
+		"checksum":   models.AuditChange{Before: nil, After: attachment.Checksum},
+	}
+	if err := writeAuditLog(c, s.db, models.AuditActionFlowAttach, flow.ID, changes); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error writing flow audit log")
+	}
+
+	response.Success(c, http.StatusCreated, attachment)
+}
+
+func (s *FlowAttachmentService) respondUploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = fmt.Errorf("attachment exceeds the limit of %d bytes", s.cfg.AttachmentsMaxSize)
+		response.Error(c, response.ErrPayloadTooLarge, err)
+		return
+	}
+
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Error(c, response.ErrFlowAttachmentsInvalidRequest, err)
+}
+
+// flowAttachmentPart returns the file part of the multipart form, it's read as a stream
+// to store the large attachments without buffering them in memory
+func flowAttachmentPart(req *http.Request) (*multipart.Part, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("multipart form field '%s' not found", flowAttachmentFormField)
+		} else if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == flowAttachmentFormField && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// flowAttachmentName returns the base name of the uploaded file which is safe to use as the file name
+func flowAttachmentName(filename string) (string, error) {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	switch {
+	case name == "." || name == ".." || name == "/":
+		return "", fmt.Errorf("invalid attachment name '%s'", filename)
+	case len(name) > flowAttachmentMaxNameLength:
+		return "", fmt.Errorf("attachment name is longer than %d bytes", flowAttachmentMaxNameLength)
+	case strings.IndexFunc(name, unicode.IsControl) != -1:
+		return "", fmt.Errorf("attachment name contains control characters")
+	}
+
+	return name, nil
+}
+
+func convertFlowAttachmentToDatabase(attachment models.FlowAttachment) database.FlowAttachment {
+	return database.FlowAttachment{
+		ID:          int64(attachment.ID),
+		Name:        attachment.Name,
+		Size:        attachment.Size,
+		ContentType: attachment.ContentType,
+		Checksum:    attachment.Checksum,
+		FlowID:      int64(attachment.FlowID),
+		UserID:      int64(attachment.UserID),
+		CreatedAt:   database.TimeToNullTime(attachment.CreatedAt),
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFlowAttachmentsTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := setupFlowACLsTestDB(t)
+
+	db.Exec(`
+		CREATE TABLE flow_attachments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			content_type TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (flow_id, name)
+		)
+	`)
+
+	return db
+}
+
+func newFlowAttachmentsTestService(t *testing.T, db *gorm.DB, maxSize int64) *FlowAttachmentService {
+	t.Helper()
+
+	cfg := &config.Config{AttachmentsDir: t.TempDir(), AttachmentsMaxSize: maxSize}
+	return NewFlowAttachmentService(db, cfg, nil)
+}
+
+func doFlowAttachmentUpload(
+	t *testing.T,
+	service *FlowAttachmentService,
+	flowID, filename, content string,
+	uid uint64,
+	privs []string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(flowAttachmentFormField, filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uid)
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/flows/"+flowID+"/attachments/", &body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	service.UploadFlowAttachment(c)
+
+	return w
+}
+
+func TestFlowAttachments_Upload(t *testing.T) {
+	db := setupFlowAttachmentsTestDB(t)
+	defer db.Close()
+
+	service := newFlowAttachmentsTestService(t, db, 1024)
+	editor := []string{"flows.view", "flows.edit"}
+	content := "10.0.0.0/24\nexample.com\n"
+
+	w := doFlowAttachmentUpload(t, service, "2", `C:\scope\targets.txt`, content, 2, editor)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp struct {
+		Data models.FlowAttachment `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	checksum := sha256.Sum256([]byte(content))
+	assert.Equal(t, "targets.txt", resp.Data.Name)
+	assert.Equal(t, int64(len(content)), resp.Data.Size)
+	assert.Equal(t, hex.EncodeToString(checksum[:]), resp.Data.Checksum)
+	assert.Equal(t, "/work/attachments/targets.txt", resp.Data.Path)
+	assert.Equal(t, uint64(2), resp.Data.UserID)
+
+	stored, err := os.ReadFile(tools.FlowAttachmentPath(service.cfg, 2, int64(resp.Data.ID)))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(stored))
+
+	// the name is unique in the flow
+	w = doFlowAttachmentUpload(t, service, "2", "targets.txt", "other", 2, editor)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = doFlowAttachmentUpload(t, service, "2", "large.bin", strings.Repeat("x", 1025), 2, editor)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+
+	var count int
+	require.NoError(t, db.Model(&models.FlowAttachment{}).Count(&count).Error)
+	assert.Equal(t, 1, count)
+
+	entries, err := os.ReadDir(tools.FlowAttachmentsDir(service.cfg, 2))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should be removed")
+}
+
+func TestFlowAttachments_Scopes(t *testing.T) {
+	db := setupFlowAttachmentsTestDB(t)
+	defer db.Close()
+
+	service := newFlowAttachmentsTestService(t, db, 1024)
+	editor := []string{"flows.view", "flows.edit"}
+
+	cases := map[string]struct {
+		flowID string
+		uid    uint64
+		privs  []string
+		code   int
+	}{
+		"shared for view":   {"1", 2, editor, http.StatusNotFound},
+		"private":           {"3", 2, editor, http.StatusNotFound},
+		"view privilege":    {"4", 2, []string{"flows.view"}, http.StatusForbidden},
+		"admin":             {"3", 2, []string{"flows.admin"}, http.StatusCreated},
+		"invalid flow id":   {"abc", 2, editor, http.StatusBadRequest},
+		"unknown flow":      {"42", 2, []string{"flows.admin"}, http.StatusNotFound},
+		"own flow uploaded": {"4", 2, editor, http.StatusCreated},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := doFlowAttachmentUpload(t, service, tc.flowID, "notes.md", "# notes", tc.uid, tc.privs)
+			assert.Equal(t, tc.code, w.Code, w.Body.String())
+		})
+	}
+
+	w := doFlowAttachmentUpload(t, service, "4", "..", "data", 2, editor)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// viewer of the shared flow lists attachments uploaded by the owner
+	w = doFlowAttachmentUpload(t, service, "1", "owner.txt", "data", 1, editor)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = doFlowACLRequest(t, service.GetFlowAttachments, http.MethodGet, "1", "", "", 2, []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Data flowAttachments `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, uint64(1), list.Data.Total)
+	assert.Equal(t, "owner.txt", list.Data.FlowAttachments[0].Name)
+	assert.Equal(t, "/work/attachments/owner.txt", list.Data.FlowAttachments[0].Path)
+
+	w = doFlowACLRequest(t, service.GetFlowAttachments, http.MethodGet, "1", "", "", 3, []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFlowAttachmentName(t *testing.T) {
+	valid := map[string]string{
+		"report.pdf":          "report.pdf",
+		"../../etc/passwd":    "passwd",
+		`C:\Users\scope.txt`:  "scope.txt",
+		"dir/sub/targets.txt": "targets.txt",
+	}
+	for filename, expected := range valid {
+		name, err := flowAttachmentName(filename)
+		require.NoError(t, err, filename)
+		assert.Equal(t, expected, name)
+	}
+
+	for _, filename := range []string{"", ".", "..", "/", "a\nb", strings.Repeat("a", 256)} {
+		_, err := flowAttachmentName(filename)
+		assert.Error(t, err, filename)
+	}
+}
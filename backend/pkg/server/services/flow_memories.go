@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type flowMemories struct {
+	FlowMemories []models.FlowMemory `json:"flow_memories"`
+	Total        uint64              `json:"total"`
+}
+
+type flowMemoriesGrouped struct {
+	Grouped []string `json:"grouped"`
+	Total   uint64   `json:"total"`
+}
+
+var flowMemoriesSQLMappers = map[string]any{
+	"id":         "{{table}}.id",
+	"key":        "{{table}}.key",
+	"value":      "{{table}}.value",
+	"flow_id":    "{{table}}.flow_id",
+	"task_id":    "{{table}}.task_id",
+	"subtask_id": "{{table}}.subtask_id",
+	"created_at": "{{table}}.created_at",
+	"updated_at": "{{table}}.updated_at",
+	"data":       "({{table}}.key || ' ' || {{table}}.value || ' ' || CAST({{table}}.tags AS TEXT))",
+}
+
+type FlowMemoryService struct {
+	db *gorm.DB
+}
+
+func NewFlowMemoryService(db *gorm.DB) *FlowMemoryService {
+	return &FlowMemoryService{
+		db: db,
+	}
+}
+
+// GetFlowMemories is a function to return the facts stored by the agents to the flow memory
+// @Summary Retrieve flow memories list by flow id
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param request query rdb.TableQuery true "query table params"
+// @Success 200 {object} response.successResp{data=flowMemories} "flow memories list received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting flow memories not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow memories"
+// @Router /flows/{flowID}/memories/ [get]
+func (s *FlowMemoryService) GetFlowMemories(c *gin.Context) {
+	var (
+		err    error
+		flow   models.Flow
+		flowID uint64
+		query  rdb.TableQuery
+		resp   flowMemories
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowMemoriesInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrFlowMemoriesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, sharedFlowsQuery(s.db, uid))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	// memories are always limited by the flow to keep the facts of the other flows out of the response
+	flowScope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("flow_memories.flow_id = ?", flow.ID)
+	}
+
+	query.Init("flow_memories", flowMemoriesSQLMappers)
+
+	if query.Group != "" {
+		if _, ok := flowMemoriesSQLMappers[query.Group]; !ok {
+			logger.FromContext(c).Errorf("error finding flow memories grouped: group field not found")
+			response.Error(c, response.ErrFlowMemoriesInvalidRequest, errors.New("group field not found"))
+			return
+		}
+
+		var respGrouped flowMemoriesGrouped
+		if respGrouped.Total, err = query.QueryGrouped(s.db, &respGrouped.Grouped, flowScope); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error finding flow memories grouped")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+
+		response.Success(c, http.StatusOK, respGrouped)
+		return
+	}
+
+	if resp.Total, err = query.Query(s.db, &resp.FlowMemories, flowScope); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding flow memories")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := 0; i < len(resp.FlowMemories); i++ {
+		if err = resp.FlowMemories[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow memory data '%d'", resp.FlowMemories[i].ID)
+			response.Error(c, response.ErrFlowMemoriesInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFlowMemoriesTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := setupFlowACLsTestDB(t)
+
+	db.Exec(`
+		CREATE TABLE flow_memories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key TEXT NOT NULL,
+			value TEXT NOT NULL,
+			tags TEXT NOT NULL DEFAULT '[]',
+			flow_id INTEGER NOT NULL,
+			task_id INTEGER,
+			subtask_id INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (flow_id, key)
+		)
+	`)
+
+	db.Exec(`INSERT INTO flow_memories (key, value, tags, flow_id, task_id) VALUES
+		('creds.ssh.admin', 'admin:Winter2024!', '["creds","ssh"]', 1, 7),
+		('target.10.0.0.5.ports', '22/tcp open', '["recon"]', 1, NULL),
+		('own.fact', 'fact of the own flow', '[]', 4, NULL)`)
+
+	return db
+}
+
+func TestGetFlowMemories(t *testing.T) {
+	db := setupFlowMemoriesTestDB(t)
+	defer db.Close()
+
+	service := NewFlowMemoryService(db)
+
+	cases := map[string]struct {
+		flowID string
+		uid    uint64
+		privs  []string
+		code   int
+		total  uint64
+	}{
+		"owner":           {"1", 1, []string{"flows.view"}, http.StatusOK, 2},
+		"shared for view": {"1", 2, []string{"flows.view"}, http.StatusOK, 2},
+		"not shared":      {"1", 3, []string{"flows.view"}, http.StatusNotFound, 0},
+		"private":         {"3", 2, []string{"flows.view"}, http.StatusNotFound, 0},
+		"admin":           {"4", 1, []string{"flows.admin"}, http.StatusOK, 1},
+		"no privilege":    {"1", 1, []string{"flows.edit"}, http.StatusForbidden, 0},
+		"invalid flow id": {"abc", 1, []string{"flows.view"}, http.StatusBadRequest, 0},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := doFlowACLRequest(t, service.GetFlowMemories, http.MethodGet, tc.flowID, "", "", tc.uid, tc.privs)
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Data flowMemories `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tc.total, resp.Data.Total)
+			for _, item := range resp.Data.FlowMemories {
+				assert.Equal(t, tc.flowID, strconv.FormatUint(item.FlowID, 10), "memories of the other flows leaked")
+			}
+		})
+	}
+
+	w := doFlowACLRequest(t, service.GetFlowMemories, http.MethodGet, "1", "", "", 1, []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data flowMemories `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.FlowMemories, 2)
+
+	byKey := make(map[string][]string)
+	for _, item := range resp.Data.FlowMemories {
+		byKey[item.Key] = item.Tags
+	}
+	assert.Equal(t, []string{"creds", "ssh"}, byKey["creds.ssh.admin"])
+	assert.Equal(t, []string{"recon"}, byKey["target.10.0.0.5.ports"])
+}
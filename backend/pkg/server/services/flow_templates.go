@@ -0,0 +1,420 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type flowTemplates struct {
+	FlowTemplates []models.FlowTemplate `json:"flow_templates"`
+	Total         uint64                `json:"total"`
+}
+
+var flowTemplatesSQLMappers = map[string]any{
+	"id":         "{{table}}.id",
+	"name":       "{{table}}.name",
+	"shared":     "{{table}}.shared",
+	"user_id":    "{{table}}.user_id",
+	"created_at": "{{table}}.created_at",
+	"updated_at": "{{table}}.updated_at",
+	"data":       "({{table}}.name)",
+}
+
+var errInvalidFlowTemplate = errors.New("invalid flow template")
+
+type FlowTemplateService struct {
+	db *gorm.DB
+}
+
+func NewFlowTemplateService(db *gorm.DB) *FlowTemplateService {
+	return &FlowTemplateService{
+		db: db,
+	}
+}
+
+// flowTemplatesViewScope returns own and shared templates of the user or all templates for flows admin
+func flowTemplatesViewScope(uid uint64, privs []string) func(db *gorm.DB) *gorm.DB {
+	if slices.Contains(privs, "flows.admin") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("flow_templates.user_id = ? OR flow_templates.shared = ?", uid, true)
+	}
+}
+
+// flowTemplatesEditScope returns own templates of the user or all templates for flows admin
+func flowTemplatesEditScope(uid uint64, privs []string) func(db *gorm.DB) *gorm.DB {
+	if slices.Contains(privs, "flows.admin") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("flow_templates.user_id = ?", uid)
+	}
+}
+
+// applyFlowTemplate pre-populates the flow creation params by the template,
+// every field set in the request body overrides the template one entirely
+func applyFlowTemplate(params models.FlowTemplateParams, body []byte) (models.CreateFlow, error) {
+	var createFlow models.CreateFlow
+
+	data, err := json.Marshal(params.CreateFlow())
+	if err != nil {
+		return createFlow, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return createFlow, err
+	}
+
+	overrides := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, &overrides); err != nil {
+		return createFlow, err
+	}
+	maps.Copy(fields, overrides)
+	delete(fields, "template_id")
+
+	if data, err = json.Marshal(fields); err != nil {
+		return createFlow, err
+	}
+	if err := json.Unmarshal(data, &createFlow); err != nil {
+		return createFlow, err
+	}
+
+	return createFlow, nil
+}
+
+// GetFlowTemplates is a function to return flow templates list
+// @Summary Retrieve flow templates list
+// @Tags FlowTemplates
+// @Produce json
+// @Security BearerAuth
+// @Param request query rdb.TableQuery true "query table params"
+// @Success 200 {object} response.successResp{data=flowTemplates} "flow templates list received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting flow templates not permitted"
+// @Failure 500 {object} response.errorResp "internal error on getting flow templates"
+// @Router /flow-templates/ [get]
+func (s *FlowTemplateService) GetFlowTemplates(c *gin.Context) {
+	var (
+		err   error
+		query rdb.TableQuery
+		resp  flowTemplates
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	query.Init("flow_templates", flowTemplatesSQLMappers)
+
+	if resp.Total, err = query.Query(s.db, &resp.FlowTemplates, flowTemplatesViewScope(uid, privs)); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding flow templates")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := 0; i < len(resp.FlowTemplates); i++ {
+		if err = resp.FlowTemplates[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow template data '%d'", resp.FlowTemplates[i].ID)
+			response.Error(c, response.ErrFlowTemplatesInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetFlowTemplate is a function to return flow template by id
+// @Summary Retrieve flow template by id
+// @Tags FlowTemplates
+// @Produce json
+// @Security BearerAuth
+// @Param templateID path int true "flow template id" minimum(0)
+// @Success 200 {object} response.successResp{data=models.FlowTemplate} "flow template received successful"
+// @Failure 400 {object} response.errorResp "invalid flow template request data"
+// @Failure 403 {object} response.errorResp "getting flow template not permitted"
+// @Failure 404 {object} response.errorResp "flow template not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow template"
+// @Router /flow-templates/{templateID} [get]
+func (s *FlowTemplateService) GetFlowTemplate(c *gin.Context) {
+	var (
+		err        error
+		templateID uint64
+		resp       models.FlowTemplate
+	)
+
+	if templateID, err = strconv.ParseUint(c.Param("templateID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow template id")
+		response.Error(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	err = s.db.Model(&resp).
+		Scopes(flowTemplatesViewScope(uid, privs)).
+		Where("flow_templates.id = ?", templateID).
+		Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow template by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowTemplatesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = resp.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow template data '%d'", templateID)
+		response.Error(c, response.ErrFlowTemplatesInvalidData, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// CreateFlowTemplate is a function to save new flow template
+// @Summary Create new flow template
+// @Tags FlowTemplates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param json body models.SaveFlowTemplate true "flow template to create"
+// @Success 201 {object} response.successResp{data=models.FlowTemplate} "flow template created successful"
+// @Failure 400 {object} response.errorResp "invalid flow template request data"
+// @Failure 403 {object} response.errorResp "creating flow template not permitted"
+// @Failure 500 {object} response.errorResp "internal error on creating flow template"
+// @Router /flow-templates/ [post]
+func (s *FlowTemplateService) CreateFlowTemplate(c *gin.Context) {
+	var (
+		err      error
+		template models.SaveFlowTemplate
+	)
+
+	if err = c.ShouldBindJSON(&template); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+	if template.Shared && !slices.Contains(privs, "flows.admin") {
+		logger.FromContext(c).Errorf("error sharing flow template: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = template.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow template data")
+		response.Error(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	resp := models.FlowTemplate{
+		Name:   template.Name,
+		Shared: template.Shared,
+		Params: models.FlowTemplateParams(template.Params),
+		UserID: uid,
+	}
+	if err = s.db.Create(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating flow template")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp)
+}
+
+// PatchFlowTemplate is a function to replace flow template params
+// @Summary Update flow template
+// @Tags FlowTemplates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param templateID path int true "flow template id" minimum(0)
+// @Param json body models.SaveFlowTemplate true "flow template to update"
+// @Success 200 {object} response.successResp{data=models.FlowTemplate} "flow template updated successful"
+// @Failure 400 {object} response.errorResp "invalid flow template request data"
+// @Failure 403 {object} response.errorResp "updating flow template not permitted"
+// @Failure 404 {object} response.errorResp "flow template not found"
+// @Failure 500 {object} response.errorResp "internal error on updating flow template"
+// @Router /flow-templates/{templateID} [put]
+func (s *FlowTemplateService) PatchFlowTemplate(c *gin.Context) {
+	var (
+		err        error
+		templateID uint64
+		template   models.SaveFlowTemplate
+		resp       models.FlowTemplate
+	)
+
+	if templateID, err = strconv.ParseUint(c.Param("templateID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow template id")
+		response.Error(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindJSON(&template); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = template.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow template data")
+		response.Error(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	scope := flowTemplatesEditScope(uid, privs)
+	err = s.db.Model(&resp).Scopes(scope).Where("flow_templates.id = ?", templateID).Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow template by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowTemplatesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	// only flows admin can share the template, the owner is allowed to keep or revoke sharing
+	if template.Shared && !resp.Shared && !slices.Contains(privs, "flows.admin") {
+		logger.FromContext(c).Errorf("error sharing flow template: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	resp.Name = template.Name
+	resp.Shared = template.Shared
+	resp.Params = models.FlowTemplateParams(template.Params)
+	if err = s.db.Save(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error updating flow template by id '%d'", templateID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// DeleteFlowTemplate is a function to delete flow template, flows created from it are left untouched
+// @Summary Delete flow template
+// @Tags FlowTemplates
+// @Produce json
+// @Security BearerAuth
+// @Param templateID path int true "flow template id" minimum(0)
+// @Success 200 {object} response.successResp{data=models.FlowTemplate} "flow template deleted successful"
+// @Failure 400 {object} response.errorResp "invalid flow template request data"
+// @Failure 403 {object} response.errorResp "deleting flow template not permitted"
+// @Failure 404 {object} response.errorResp "flow template not found"
+// @Failure 500 {object} response.errorResp "internal error on deleting flow template"
+// @Router /flow-templates/{templateID} [delete]
+func (s *FlowTemplateService) DeleteFlowTemplate(c *gin.Context) {
+	var (
+		err        error
+		templateID uint64
+		resp       models.FlowTemplate
+	)
+
+	if templateID, err = strconv.ParseUint(c.Param("templateID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow template id")
+		response.Error(c, response.ErrFlowTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	scope := flowTemplatesEditScope(uid, privs)
+	err = s.db.Model(&resp).Scopes(scope).Where("flow_templates.id = ?", templateID).Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow template by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowTemplatesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = s.db.Where("id = ?", resp.ID).Delete(&models.FlowTemplate{}).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error deleting flow template by id '%d'", templateID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// getFlowTemplate returns the flow template available to the user to create the flow from
+func getFlowTemplate(db *gorm.DB, templateID, uid uint64, privs []string) (models.FlowTemplate, error) {
+	var template models.FlowTemplate
+
+	err := db.Model(&template).
+		Scopes(flowTemplatesViewScope(uid, privs)).
+		Where("flow_templates.id = ?", templateID).
+		Take(&template).Error
+	if err != nil {
+		return template, err
+	}
+
+	if err = template.Valid(); err != nil {
+		return template, errors.Join(errInvalidFlowTemplate, err)
+	}
+
+	return template, nil
+}
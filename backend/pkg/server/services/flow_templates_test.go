@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/tools"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFlowTemplatesTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE flow_templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
+			params BLOB NOT NULL DEFAULT '{}',
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec(`INSERT INTO flow_templates (id, name, shared, params, user_id) VALUES
+		(1, 'own', FALSE, '{"input":"scan the perimeter","provider":"openai"}', 1),
+		(2, 'foreign', FALSE, '{"input":"foreign input","provider":"openai"}', 2),
+		(3, 'global', TRUE, '{"input":"global input","provider":"anthropic","language":"German"}', 2)`)
+
+	return db
+}
+
+func doFlowTemplateRequest(
+	t *testing.T,
+	handler gin.HandlerFunc,
+	method, templateID, body string,
+	privs []string,
+) (*httptest.ResponseRecorder, json.RawMessage) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "templateID", Value: templateID}}
+	c.Request, _ = http.NewRequest(method, "/flow-templates/"+templateID, bytes.NewBufferString(body))
+	if method == http.MethodGet {
+		c.Request.URL.RawQuery = "page=1&pageSize=-1&type=init"
+	}
+
+	handler(c)
+
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if w.Code < http.StatusBadRequest {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp.Data
+}
+
+func TestGetFlowTemplates_Scoping(t *testing.T) {
+	db := setupFlowTemplatesTestDB(t)
+	defer db.Close()
+	svc := NewFlowTemplateService(db)
+
+	names := func(data json.RawMessage) []string {
+		var list flowTemplates
+		require.NoError(t, json.Unmarshal(data, &list))
+		var result []string
+		for _, template := range list.FlowTemplates {
+			result = append(result, template.Name)
+		}
+		return result
+	}
+
+	w, data := doFlowTemplateRequest(t, svc.GetFlowTemplates, http.MethodGet, "", "", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"own", "global"}, names(data))
+
+	w, data = doFlowTemplateRequest(t, svc.GetFlowTemplates, http.MethodGet, "", "", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"own", "foreign", "global"}, names(data))
+
+	w, _ = doFlowTemplateRequest(t, svc.GetFlowTemplate, http.MethodGet, "2", "", []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, data = doFlowTemplateRequest(t, svc.GetFlowTemplate, http.MethodGet, "3", "", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var template models.FlowTemplate
+	require.NoError(t, json.Unmarshal(data, &template))
+	assert.Equal(t, "anthropic", template.Params.Provider)
+	require.NotNil(t, template.Params.Language)
+	assert.Equal(t, "German", *template.Params.Language)
+
+	w, _ = doFlowTemplateRequest(t, svc.GetFlowTemplates, http.MethodGet, "", "", []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateFlowTemplate(t *testing.T) {
+	db := setupFlowTemplatesTestDB(t)
+	defer db.Close()
+	svc := NewFlowTemplateService(db)
+
+	body := `{"name":"web","params":{"input":"check web app","provider":"openai","language":"French",` +
+		`"functions":{"disabled":[{"name":"browser"}]}}}`
+	w, data := doFlowTemplateRequest(t, svc.CreateFlowTemplate, http.MethodPost, "", body, []string{"flows.create"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var template models.FlowTemplate
+	require.NoError(t, json.Unmarshal(data, &template))
+	assert.Equal(t, uint64(1), template.UserID)
+	assert.False(t, template.Shared)
+
+	var stored models.FlowTemplate
+	require.NoError(t, db.Where("id = ?", template.ID).Take(&stored).Error)
+	assert.Equal(t, "check web app", stored.Params.Input)
+	require.NotNil(t, stored.Params.Functions)
+	assert.Equal(t, "browser", stored.Params.Functions.Disabled[0].Name)
+
+	invalid := map[string]string{
+		"missing input":   `{"name":"broken","params":{"provider":"openai"}}`,
+		"unknown lang":    `{"name":"broken","params":{"input":"x","provider":"openai","language":"Klingon"}}`,
+		"nested template": `{"name":"broken","params":{"input":"x","provider":"openai","template_id":1}}`,
+		"missing name":    `{"params":{"input":"x","provider":"openai"}}`,
+	}
+	for name, body := range invalid {
+		w, _ := doFlowTemplateRequest(t, svc.CreateFlowTemplate, http.MethodPost, "", body, []string{"flows.create"})
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	shared := `{"name":"shared","shared":true,"params":{"input":"x","provider":"openai"}}`
+	w, _ = doFlowTemplateRequest(t, svc.CreateFlowTemplate, http.MethodPost, "", shared, []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doFlowTemplateRequest(t, svc.CreateFlowTemplate, http.MethodPost, "", shared, []string{"flows.create", "flows.admin"})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestPatchAndDeleteFlowTemplate(t *testing.T) {
+	db := setupFlowTemplatesTestDB(t)
+	defer db.Close()
+	svc := NewFlowTemplateService(db)
+
+	body := `{"name":"renamed","params":{"input":"new input","provider":"openai"}}`
+	w, _ := doFlowTemplateRequest(t, svc.PatchFlowTemplate, http.MethodPut, "1", body, []string{"flows.create"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var stored models.FlowTemplate
+	require.NoError(t, db.Where("id = 1").Take(&stored).Error)
+	assert.Equal(t, "renamed", stored.Name)
+	assert.Equal(t, "new input", stored.Params.Input)
+
+	// shared templates of other users can be used but not changed
+	w, _ = doFlowTemplateRequest(t, svc.PatchFlowTemplate, http.MethodPut, "3", body, []string{"flows.create"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w, _ = doFlowTemplateRequest(t, svc.DeleteFlowTemplate, http.MethodDelete, "3", "", []string{"flows.create"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	shared := `{"name":"renamed","shared":true,"params":{"input":"new input","provider":"openai"}}`
+	w, _ = doFlowTemplateRequest(t, svc.PatchFlowTemplate, http.MethodPut, "1", shared, []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doFlowTemplateRequest(t, svc.PatchFlowTemplate, http.MethodPut, "2", shared, []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var foreign models.FlowTemplate
+	require.NoError(t, db.Where("id = 2").Take(&foreign).Error)
+	assert.True(t, foreign.Shared)
+	assert.Equal(t, uint64(2), foreign.UserID)
+
+	w, _ = doFlowTemplateRequest(t, svc.DeleteFlowTemplate, http.MethodDelete, "1", "", []string{"flows.create"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, db.Where("id = 1").Take(&models.FlowTemplate{}).RecordNotFound())
+}
+
+func TestApplyFlowTemplate(t *testing.T) {
+	language := "German"
+	params := models.FlowTemplateParams{
+		Input:     "template input",
+		Provider:  "openai",
+		Fallbacks: []string{"anthropic"},
+		Language:  &language,
+		Functions: &tools.Functions{Disabled: []tools.DisableFunction{{Name: "browser"}}},
+	}
+
+	createFlow, err := applyFlowTemplate(params, []byte(`{"template_id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, "template input", createFlow.Input)
+	assert.Equal(t, []string{"anthropic"}, createFlow.Fallbacks)
+	require.NotNil(t, createFlow.Language)
+	assert.Equal(t, "German", *createFlow.Language)
+	assert.Nil(t, createFlow.TemplateID)
+	require.NoError(t, createFlow.Valid())
+
+	// fields of the request body replace the template ones entirely
+	body := `{"template_id":1,"input":"custom input","functions":{"token":"secret"},"language":null}`
+	createFlow, err = applyFlowTemplate(params, []byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, "custom input", createFlow.Input)
+	assert.Equal(t, "openai", createFlow.Provider)
+	assert.Nil(t, createFlow.Language)
+	require.NotNil(t, createFlow.Functions)
+	assert.Empty(t, createFlow.Functions.Disabled)
+
+	_, err = applyFlowTemplate(params, []byte(`[]`))
+	assert.Error(t, err)
+}
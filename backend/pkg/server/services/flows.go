@@ -1,23 +1,38 @@
 package services
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"pentagi/pkg/config"
 	"pentagi/pkg/controller"
 	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
 	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/langdetect"
 	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/report"
 	"pentagi/pkg/server/response"
+	"pentagi/pkg/templates"
+	"pentagi/pkg/templates/validator"
+	"pentagi/pkg/tools"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/jinzhu/gorm"
 )
 
@@ -26,11 +41,47 @@ type flows struct {
 	Total uint64        `json:"total"`
 }
 
+type flowMessages struct {
+	Messages     []models.FlowMessage `json:"messages"`
+	Total        uint64               `json:"total"`
+	SystemPrompt *string              `json:"system_prompt,omitempty"`
+}
+
+type flowInputs struct {
+	Inputs []models.FlowInput `json:"inputs"`
+	Total  uint64             `json:"total"`
+}
+
 type flowsGrouped struct {
 	Grouped []string `json:"grouped"`
 	Total   uint64   `json:"total"`
 }
 
+const defaultFlowMessagesLimit = 100
+
+// flowInputIDHeader returns the id of the queued input in the inputs history to the input action of PatchFlow
+const flowInputIDHeader = "X-Flow-Input-ID"
+
+const (
+	mimeCSV = "text/csv"
+	// flowsCSVFlushRows is amount of rows to send to the client at once while streaming flows as CSV
+	flowsCSVFlushRows = 100
+)
+
+var flowsCSVHeader = []string{"id", "status", "title", "model", "provider", "language", "created_at", "updated_at"}
+
+// flowEventsHeartbeatInterval is period to send comments to the flow events stream
+// to keep the idle connection open through proxies
+var flowEventsHeartbeatInterval = 30 * time.Second
+
+// flowEventsTypes are the event types streamed by default
+var flowEventsTypes = []subscriptions.EventType{
+	subscriptions.EventFlowUpdated,
+	subscriptions.EventFlowDeleted,
+	subscriptions.EventTaskCreated,
+	subscriptions.EventTaskUpdated,
+}
+
 var flowsSQLMappers = map[string]any{
 	"id":                  "{{table}}.id",
 	"status":              "{{table}}.status",
@@ -41,36 +92,45 @@ var flowsSQLMappers = map[string]any{
 	"language":            "{{table}}.language",
 	"created_at":          "{{table}}.created_at",
 	"updated_at":          "{{table}}.updated_at",
+	"deleted_at":          "{{table}}.deleted_at",
 	"data":                "({{table}}.status || ' ' || {{table}}.title || ' ' || {{table}}.model || ' ' || {{table}}.model_provider || ' ' || {{table}}.language)",
 }
 
 type FlowService struct {
-	db *gorm.DB
-	pc providers.ProviderController
-	fc controller.FlowController
-	ss subscriptions.SubscriptionsController
+	db  *gorm.DB
+	cfg *config.Config
+	pc  providers.ProviderController
+	fc  controller.FlowController
+	ss  subscriptions.SubscriptionsController
 }
 
 func NewFlowService(
 	db *gorm.DB,
+	cfg *config.Config,
 	pc providers.ProviderController,
 	fc controller.FlowController,
 	ss subscriptions.SubscriptionsController,
 ) *FlowService {
 	return &FlowService{
-		db: db,
-		pc: pc,
-		fc: fc,
-		ss: ss,
+		db:  db,
+		cfg: cfg,
+		pc:  pc,
+		fc:  fc,
+		ss:  ss,
 	}
 }
 
 // GetFlows is a function to return flows list
 // @Summary Retrieve flows list
+// @Description Flows are streamed as CSV without grouping if it's requested by Accept header or format param
 // @Tags Flows
-// @Produce json
+// @Produce json,text/csv
 // @Security BearerAuth
 // @Param request query rdb.TableQuery true "query table params"
+// @Param include_deleted query bool false "include deleted flows to find the restorable ones, admin only"
+// @Param created_after query string false "inclusive lower bound of flow creation time in RFC3339 with timezone" example(2026-01-02T00:00:00+02:00)
+// @Param created_before query string false "exclusive upper bound of flow creation time in RFC3339 with timezone" example(2026-01-03T00:00:00+02:00)
+// @Param format query string false "flows list format" Enums(json, csv)
 // @Success 200 {object} response.successResp{data=flows} "flows list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting flows not permitted"
@@ -89,16 +149,51 @@ func (s *FlowService) GetFlows(c *gin.Context) {
 		return
 	}
 
+	includeDeleted := false
+	if value := c.Query("include_deleted"); value != "" {
+		if includeDeleted, err = strconv.ParseBool(value); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error parsing include deleted flag")
+			response.Error(c, response.ErrFlowsInvalidRequest, err)
+			return
+		}
+	}
+
+	createdAfter, err := parseFlowsTimeBound(c, "created_after")
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing created after bound")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+	createdBefore, err := parseFlowsTimeBound(c, "created_before")
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing created before bound")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+	if createdAfter != nil && createdBefore != nil && !createdAfter.Before(*createdBefore) {
+		logger.FromContext(c).Errorf("error parsing creation time bounds: empty range")
+		response.Error(c, response.ErrFlowsInvalidRequest, errors.New("created_after must be earlier than created_before"))
+		return
+	}
+
 	uid := c.GetUint64("uid")
 	privs := c.GetStringSlice("prm")
 	var scope func(db *gorm.DB) *gorm.DB
 	if slices.Contains(privs, "flows.admin") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db
+			if includeDeleted {
+				return db.Unscoped()
+			}
+			// table query counts rows without the model so deleted flows are filtered explicitly
+			return db.Where("deleted_at IS NULL")
 		}
+	} else if includeDeleted {
+		logger.FromContext(c).Errorf("error filtering deleted flows: admin permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
 	} else if slices.Contains(privs, "flows.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("user_id = ?", uid)
+			return db.Where("(user_id = ? OR id IN (?)) AND deleted_at IS NULL", uid, s.sharedFlows(uid))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -106,8 +201,28 @@ func (s *FlowService) GetFlows(c *gin.Context) {
 		return
 	}
 
+	if createdAfter != nil || createdBefore != nil {
+		scope = flowsCreatedScope(scope, createdAfter, createdBefore)
+	}
+
 	query.Init("flows", flowsSQLMappers)
 
+	switch c.Query("format") {
+	case "csv":
+		s.streamFlowsCSV(c, &query, scope)
+		return
+	case "json":
+	case "":
+		if c.NegotiateFormat(binding.MIMEJSON, mimeCSV) == mimeCSV {
+			s.streamFlowsCSV(c, &query, scope)
+			return
+		}
+	default:
+		logger.FromContext(c).Errorf("error parsing flows list format: unknown format")
+		response.Error(c, response.ErrFlowsInvalidRequest, errors.New("unknown format"))
+		return
+	}
+
 	if query.Group != "" {
 		if _, ok := flowsSQLMappers[query.Group]; !ok {
 			logger.FromContext(c).Errorf("error finding flows grouped: group field not found")
@@ -143,13 +258,115 @@ func (s *FlowService) GetFlows(c *gin.Context) {
 	response.Success(c, http.StatusOK, resp)
 }
 
+// parseFlowsTimeBound parses the optional creation time bound of flows list, the timezone is required
+// to not depend on the server one and the result is converted to UTC to compare with the stored timestamps
+func parseFlowsTimeBound(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	bound, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be RFC3339 timestamp with timezone, e.g. 2026-01-02T15:04:05+02:00 "+
+			"or 2026-01-02T13:04:05Z: %w", name, err)
+	}
+
+	bound = bound.UTC()
+	return &bound, nil
+}
+
+// flowsCreatedScope restricts the scope to flows created in [after, before) range so that adjacent ranges
+// don't overlap, any bound may be nil
+func flowsCreatedScope(scope func(db *gorm.DB) *gorm.DB, after, before *time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = scope(db)
+		if after != nil {
+			db = db.Where("created_at >= ?", *after)
+		}
+		if before != nil {
+			db = db.Where("created_at < ?", *before)
+		}
+		return db
+	}
+}
+
+// streamFlowsCSV writes the flows list as CSV row by row, the error after the header row is only logged
+// because the response status is already sent
+func (s *FlowService) streamFlowsCSV(c *gin.Context, query *rdb.TableQuery, scope func(db *gorm.DB) *gorm.DB) {
+	rows, err := query.Rows(s.db, scope)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding flows")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Disposition", `attachment; filename="flows.csv"`)
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err = w.Write(flowsCSVHeader); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error writing flows csv header")
+		return
+	}
+
+	for count := 1; rows.Next(); count++ {
+		var flow models.Flow
+		if err = s.db.ScanRows(rows, &flow); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error scanning flow row")
+			return
+		}
+
+		err = w.Write([]string{
+			strconv.FormatUint(flow.ID, 10),
+			flow.Status.String(),
+			csvSafeCell(flow.Title),
+			csvSafeCell(flow.Model),
+			csvSafeCell(flow.ModelProviderName),
+			csvSafeCell(flow.Language),
+			flow.CreatedAt.UTC().Format(time.RFC3339),
+			flow.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error writing flow '%d' csv row", flow.ID)
+			return
+		}
+
+		if count%flowsCSVFlushRows == 0 {
+			w.Flush()
+			c.Writer.Flush()
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error iterating flows rows")
+	}
+
+	w.Flush()
+	if err = w.Error(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error flushing flows csv")
+	}
+}
+
+// csvSafeCell prefixes cells which spreadsheets would evaluate as a formula
+func csvSafeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
 // GetFlow is a function to return flow by id
 // @Summary Retrieve flow by id
 // @Tags Flows
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
+// @Param If-None-Match header string false "entity tag of the flow received before"
 // @Success 200 {object} response.successResp{data=models.Flow} "flow received successful"
+// @Success 304 "flow wasn't changed"
 // @Failure 403 {object} response.errorResp "getting flow not permitted"
 // @Failure 404 {object} response.errorResp "flow not found"
 // @Failure 500 {object} response.errorResp "internal error on getting flow"
@@ -176,7 +393,7 @@ func (s *FlowService) GetFlow(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -194,32 +411,232 @@ func (s *FlowService) GetFlow(c *gin.Context) {
 		return
 	}
 
+	if checkNotModified(c, weakETag("flow", resp.ID, resp.UpdatedAt.UnixNano(), resp.DeletedAt)) {
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetFlowsStatus is a function to return statuses of the flows by ids in bulk
+// @Summary Retrieve statuses of flows by ids
+// @Description Returns the map of the flow id to its status, flows which aren't available for the user are omitted
+// @Tags Flows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param json body models.FlowsStatusRequest true "ids of the flows"
+// @Success 200 {object} response.successResp{data=map[string]models.FlowStatusInfo} "flows statuses received successful"
+// @Failure 400 {object} response.errorResp "invalid flows status request data"
+// @Failure 403 {object} response.errorResp "getting flows statuses not permitted"
+// @Failure 500 {object} response.errorResp "internal error on getting flows statuses"
+// @Router /flows/status [post]
+func (s *FlowService) GetFlowsStatus(c *gin.Context) {
+	var (
+		err  error
+		req  models.FlowsStatusRequest
+		rows []struct {
+			ID                   uint64
+			Status               models.FlowStatus
+			UpdatedAt            time.Time
+			ActiveContainerCount uint64
+		}
+	)
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	if err = req.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flows status request")
+		respondBindError(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("flows.user_id = ? OR flows.id IN (?)", uid, s.sharedFlows(uid))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	activeStatuses := []string{
+		models.ContainerStatusStarting.String(),
+		models.ContainerStatusRunning.String(),
+	}
+	err = s.db.Table("flows").
+		Select("flows.id, flows.status, flows.updated_at, COUNT(containers.id) AS active_container_count").
+		Joins("LEFT JOIN containers ON containers.flow_id = flows.id AND containers.status IN (?)", activeStatuses).
+		Where("flows.id IN (?) AND flows.deleted_at IS NULL", req.IDs).
+		Scopes(scope).
+		Group("flows.id, flows.status, flows.updated_at").
+		Scan(&rows).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flows statuses")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	resp := make(map[uint64]models.FlowStatusInfo, len(rows))
+	for _, row := range rows {
+		resp[row.ID] = models.FlowStatusInfo{
+			Status:               row.Status,
+			UpdatedAt:            row.UpdatedAt,
+			ActiveContainerCount: row.ActiveContainerCount,
+		}
+	}
+
 	response.Success(c, http.StatusOK, resp)
 }
 
+// GetFlowFunctions is a function to return custom functions of the flow by id
+// @Summary Retrieve flow custom functions by id
+// @Description Returns functions which were sent on the flow creation, the result can be modified
+// @Description and sent as is to create a new flow, it's available only for owners of the flow
+// @Description because functions may contain the access token of the external functions
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Success 200 {object} response.successResp{data=tools.Functions} "flow functions received successful"
+// @Failure 403 {object} response.errorResp "getting flow functions not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow functions"
+// @Router /flows/{flowID}/functions [get]
+func (s *FlowService) GetFlowFunctions(c *gin.Context) {
+	var (
+		err    error
+		flowID uint64
+		resp   models.Flow
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		// shared flows are excluded because functions contain secrets of the owner
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND user_id = ?", flowID, uid)
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&resp).Scopes(scope).Take(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if resp.Functions == nil {
+		resp.Functions = &tools.Functions{}
+	}
+
+	response.Success(c, http.StatusOK, resp.Functions)
+}
+
 // GetFlowGraph is a function to return flow graph by id
 // @Summary Retrieve flow graph by id
+// @Description The entity tag of the graph depends on the flow, its tasks and subtasks and the part of them
+// @Description available for the user, so the request with If-None-Match gets 304 until something changes
+// @Description Progress is the percentage of completed subtasks of the task and the flow, it's returned
+// @Description only with subtasks and it's empty while there are no subtasks
 // @Tags Flows
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
+// @Param If-None-Match header string false "entity tag of the flow graph received before"
 // @Success 200 {object} response.successResp{data=models.FlowTasksSubtasks} "flow graph received successful"
+// @Success 304 "flow graph wasn't changed"
 // @Failure 403 {object} response.errorResp "getting flow graph not permitted"
 // @Failure 404 {object} response.errorResp "flow graph not found"
 // @Failure 500 {object} response.errorResp "internal error on getting flow graph"
 // @Router /flows/{flowID}/graph [get]
 func (s *FlowService) GetFlowGraph(c *gin.Context) {
+	resp, access, ok := s.getFlowGraphHead(c)
+	if !ok {
+		return
+	}
+
+	etag, err := s.flowGraphETag(resp.Flow, access)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow graph state")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if checkNotModified(c, etag) {
+		return
+	}
+
+	if !s.loadFlowGraph(c, &resp, access) {
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// flowGraphAccess is the part of the flow graph which is available for the user
+type flowGraphAccess int
+
+const (
+	flowGraphAccessFlow flowGraphAccess = iota
+	flowGraphAccessTasks
+	flowGraphAccessSubtasks
+)
+
+// getFlowGraph returns the flow with tasks and subtasks available for the calling user
+// or writes the error response
+func (s *FlowService) getFlowGraph(c *gin.Context) (models.FlowTasksSubtasks, bool) {
+	resp, access, ok := s.getFlowGraphHead(c)
+	if !ok {
+		return resp, false
+	}
+
+	return resp, s.loadFlowGraph(c, &resp, access)
+}
+
+// getFlowGraphHead returns the flow without tasks and the part of the graph available for the calling user
+// or writes the error response
+func (s *FlowService) getFlowGraphHead(c *gin.Context) (models.FlowTasksSubtasks, flowGraphAccess, bool) {
 	var (
 		err    error
 		flowID uint64
 		resp   models.FlowTasksSubtasks
-		tids   []uint64
 	)
 
 	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
 		response.Error(c, response.ErrFlowsInvalidRequest, err)
-		return
+		return resp, flowGraphAccessFlow, false
 	}
 
 	uid := c.GetUint64("uid")
@@ -231,12 +648,12 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
 		response.Error(c, response.ErrNotPermitted, nil)
-		return
+		return resp, flowGraphAccessFlow, false
 	}
 
 	err = s.db.Model(&resp).
@@ -249,35 +666,44 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 		} else {
 			response.Error(c, response.ErrInternal, err)
 		}
-		return
+		return resp, flowGraphAccessFlow, false
 	}
 
+	// tasks of the flows shared with the user are available with the same privileges as own ones
+	isOwner := resp.UserID == uid || s.isFlowShared(flowID, uid)
 	isTasksAdmin := slices.Contains(privs, "tasks.admin")
 	isTasksView := slices.Contains(privs, "tasks.view")
-	if !(resp.UserID == uid && isTasksView) && !(resp.UserID != uid && isTasksAdmin) {
-		response.Success(c, http.StatusOK, resp)
-		return
+	if !(isOwner && isTasksView) && !(!isOwner && isTasksAdmin) {
+		return resp, flowGraphAccessFlow, true
 	}
 
-	if resp.UserID != uid && !slices.Contains(privs, "tasks.admin") {
-		response.Success(c, http.StatusOK, resp)
-		return
+	isSubtasksAdmin := slices.Contains(privs, "subtasks.admin")
+	isSubtasksView := slices.Contains(privs, "subtasks.view")
+	if !(isOwner && isSubtasksView) && !(!isOwner && isSubtasksAdmin) {
+		return resp, flowGraphAccessTasks, true
+	}
+
+	return resp, flowGraphAccessSubtasks, true
+}
+
+// loadFlowGraph fills tasks and subtasks of the flow according to the access or writes the error response
+func (s *FlowService) loadFlowGraph(c *gin.Context, resp *models.FlowTasksSubtasks, access flowGraphAccess) bool {
+	if access < flowGraphAccessTasks {
+		return true
 	}
 
-	err = s.db.Model(&resp).Association("tasks").Find(&resp.Tasks).Error
+	err := s.db.Model(resp).Association("tasks").Find(&resp.Tasks).Error
 	if err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error on getting flow tasks")
 		response.Error(c, response.ErrInternal, err)
-		return
+		return false
 	}
 
-	isSubtasksAdmin := slices.Contains(privs, "subtasks.admin")
-	isSubtasksView := slices.Contains(privs, "subtasks.view")
-	if !(resp.UserID == uid && isSubtasksView) && !(resp.UserID != uid && isSubtasksAdmin) {
-		response.Success(c, http.StatusOK, resp)
-		return
+	if access < flowGraphAccessSubtasks {
+		return true
 	}
 
+	var tids []uint64
 	for _, task := range resp.Tasks {
 		tids = append(tids, task.ID)
 	}
@@ -287,7 +713,7 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 	if err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error on getting flow subtasks")
 		response.Error(c, response.ErrInternal, err)
-		return
+		return false
 	}
 
 	tasksSubtasks := map[uint64][]models.Subtask{}
@@ -299,116 +725,1211 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 		resp.Tasks[i].Subtasks = tasksSubtasks[resp.Tasks[i].ID]
 	}
 
+	// progress is derived from subtasks so it's available only with them
+	resp.SetProgress()
+
 	if err = resp.Valid(); err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error validating flow data '%d'", flowID)
+		logger.FromContext(c).WithError(err).Errorf("error validating flow data '%d'", resp.ID)
 		response.Error(c, response.ErrFlowsInvalidData, err)
-		return
+		return false
 	}
 
-	response.Success(c, http.StatusOK, resp)
+	return true
 }
 
-// CreateFlow is a function to create new flow with custom functions
-// @Summary Create new flow with custom functions
+// flowGraphETag returns the entity tag of the flow graph part available for the user, it's built from
+// the number and the last update time of the tasks and subtasks without loading them
+func (s *FlowService) flowGraphETag(flow models.Flow, access flowGraphAccess) (string, error) {
+	parts := []any{"graph", flow.ID, flow.UpdatedAt.UnixNano(), flow.DeletedAt, access}
+
+	if access >= flowGraphAccessTasks {
+		var (
+			count     int64
+			updatedAt any
+		)
+		row := s.db.Table("tasks").
+			Select("COUNT(*), MAX(updated_at)").
+			Where("flow_id = ?", flow.ID).
+			Row()
+		if err := row.Scan(&count, &updatedAt); err != nil {
+			return "", fmt.Errorf("failed to get flow tasks state: %w", err)
+		}
+		parts = append(parts, count, updatedAt)
+	}
+
+	if access >= flowGraphAccessSubtasks {
+		var (
+			count     int64
+			updatedAt any
+		)
+		row := s.db.Table("subtasks").
+			Select("COUNT(*), MAX(subtasks.updated_at)").
+			Joins("JOIN tasks ON tasks.id = subtasks.task_id").
+			Where("tasks.flow_id = ?", flow.ID).
+			Row()
+		if err := row.Scan(&count, &updatedAt); err != nil {
+			return "", fmt.Errorf("failed to get flow subtasks state: %w", err)
+		}
+		parts = append(parts, count, updatedAt)
+	}
+
+	return weakETag(parts...), nil
+}
+
+// GetFlowReport is a function to export the flow as a pentest report
+// @Summary Export flow report by id
+// @Description Report contains tasks and subtasks available for the user, large outputs are truncated with the link to the full text
 // @Tags Flows
-// @Accept json
-// @Produce json
+// @Produce text/markdown,text/html
 // @Security BearerAuth
-// @Param json body models.CreateFlow true "flow model to create"
-// @Success 201 {object} response.successResp{data=models.Flow} "flow created successful"
-// @Failure 400 {object} response.errorResp "invalid flow request data"
-// @Failure 403 {object} response.errorResp "creating flow not permitted"
-// @Failure 500 {object} response.errorResp "internal error on creating flow"
-// @Router /flows/ [post]
-func (s *FlowService) CreateFlow(c *gin.Context) {
-	var (
-		err        error
-		flow       models.Flow
-		createFlow models.CreateFlow
-	)
+// @Param flowID path int true "flow id" minimum(0)
+// @Param request query models.FlowReportQuery true "report format"
+// @Success 200 {string} string "flow report exported successful"
+// @Failure 400 {object} response.errorResp "invalid report request data"
+// @Failure 403 {object} response.errorResp "exporting flow report not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on exporting flow report"
+// @Router /flows/{flowID}/report [get]
+func (s *FlowService) GetFlowReport(c *gin.Context) {
+	var query models.FlowReportQuery
 
-	if err := c.ShouldBindJSON(&createFlow); err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
 		response.Error(c, response.ErrFlowsInvalidRequest, err)
 		return
 	}
 
-	privs := c.GetStringSlice("prm")
-	if !slices.Contains(privs, "flows.create") {
-		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
-		response.Error(c, response.ErrNotPermitted, nil)
+	flow, ok := s.getFlowGraph(c)
+	if !ok {
 		return
 	}
 
-	if err := createFlow.Valid(); err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error validating flow data")
-		response.Error(c, response.ErrFlowsInvalidData, err)
-		return
+	format := report.Format(query.Format)
+	if format == "" {
+		format = report.FormatMarkdown
+	}
+
+	var buf bytes.Buffer
+	rep := report.New(flow, report.Options{
+		BaseURL: strings.TrimSuffix(c.FullPath(), "/flows/:flowID/report"),
+	})
+	if err := report.Render(&buf, format, rep); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error rendering flow report")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	filename := fmt.Sprintf("flow-%d-report.%s", flow.ID, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, format.ContentType(), buf.Bytes())
+}
+
+// GetFlowReportSARIF is a function to export the flow findings as a SARIF log
+// @Summary Export flow findings by id in SARIF 2.1.0 format
+// @Description Results are found in tasks and subtasks available for the user, rules are CVEs or finding types
+// @Tags Flows
+// @Produce application/sarif+json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Success 200 {object} report.SARIFLog "flow findings exported successful"
+// @Failure 400 {object} response.errorResp "invalid report request data"
+// @Failure 403 {object} response.errorResp "exporting flow findings not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on exporting flow findings"
+// @Router /flows/{flowID}/report.sarif [get]
+func (s *FlowService) GetFlowReportSARIF(c *gin.Context) {
+	flow, ok := s.getFlowGraph(c)
+	if !ok {
+		return
+	}
+
+	data, err := json.MarshalIndent(report.NewSARIF(flow), "", "  ")
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error marshalling flow findings")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	filename := fmt.Sprintf("flow-%d-report.sarif", flow.ID)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/sarif+json", data)
+}
+
+// GetFlowMessages is a function to return the conversation messages of the flow from its message log
+// @Summary Retrieve flow agents messages by flow id
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param request query models.FlowMessagesQuery true "paging and truncation params"
+// @Success 200 {object} response.successResp{data=flowMessages} "flow messages received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting flow messages not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow messages"
+// @Router /flows/{flowID}/messages [get]
+func (s *FlowService) GetFlowMessages(c *gin.Context) {
+	var (
+		err     error
+		flowID  uint64
+		flow    models.Flow
+		query   models.FlowMessagesQuery
+		msglogs []models.Msglog
+		resp    flowMessages
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultFlowMessagesLimit
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	flowMsglogs := s.db.Model(&models.Msglog{}).Where("flow_id = ?", flowID)
+	if err = flowMsglogs.Count(&resp.Total).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on counting flow msglogs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	err = flowMsglogs.
+		Order("created_at ASC, id ASC").
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Find(&msglogs).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow msglogs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	resp.SystemPrompt = flow.SystemPrompt
+	resp.Messages = make([]models.FlowMessage, 0, len(msglogs))
+	for _, msglog := range msglogs {
+		resp.Messages = append(resp.Messages, convertMsglogToFlowMessage(msglog, query.MaxContent))
+	}
+
+	for i := 0; i < len(resp.Messages); i++ {
+		if err = resp.Messages[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow message data '%d'", i)
+			response.Error(c, response.ErrFlowsInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetFlowInputs is a function to return the history of inputs sent to the flow
+// @Summary Retrieve flow inputs history by flow id
+// @Description Inputs are consumed by the flow one by one in the order of their ids,
+// @Description queued inputs wait for the running task and canceled ones were dropped by the flow stop
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Success 200 {object} response.successResp{data=flowInputs} "flow inputs received successful"
+// @Failure 400 {object} response.errorResp "invalid flow request data"
+// @Failure 403 {object} response.errorResp "getting flow inputs not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow inputs"
+// @Router /flows/{flowID}/inputs [get]
+func (s *FlowService) GetFlowInputs(c *gin.Context) {
+	var (
+		err    error
+		flowID uint64
+		count  uint64
+		resp   flowInputs
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&models.Flow{}).Scopes(scope).Count(&count).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow by id")
+		response.Error(c, response.ErrInternal, err)
+		return
+	} else if count == 0 {
+		logger.FromContext(c).Errorf("error on getting flow by id: not found")
+		response.Error(c, response.ErrFlowsNotFound, nil)
+		return
+	}
+
+	if err = s.db.Where("flow_id = ?", flowID).Order("id ASC").Find(&resp.Inputs).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow inputs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	resp.Total = uint64(len(resp.Inputs))
+
+	for i := 0; i < len(resp.Inputs); i++ {
+		if err = resp.Inputs[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow input data '%d'", resp.Inputs[i].ID)
+			response.Error(c, response.ErrFlowsInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetFlowEvents is a function to stream flow updates as server-sent events
+// @Summary Subscribe to flow events by flow id
+// @Description Streams flow_updated, flow_deleted, task_created and task_updated events of the flow
+// @Description or only requested types of them, task events contain the task subtasks,
+// @Description the stream is closed after the flow is deleted, every event id is the flow event sequence number,
+// @Description events after last_seq (or Last-Event-ID header) are replayed first if they are still buffered
+// @Description otherwise the resync_required event is sent and the flow must be fetched again,
+// @Description message_chunk events are streamed on request only, they carry parts of the agent answers
+// @Description appended to the previous chunks of the same stream_id and aren't replayed after reconnect
+// @Tags Flows
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param request query models.FlowEventsQuery false "flow events filter"
+// @Success 200 {string} string "flow events stream opened successful"
+// @Failure 400 {object} response.errorResp "invalid flow request data"
+// @Failure 403 {object} response.errorResp "subscribing to flow events not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on subscribing to flow events"
+// @Router /flows/{flowID}/events [get]
+func (s *FlowService) GetFlowEvents(c *gin.Context) {
+	var (
+		err    error
+		flow   models.Flow
+		flowID uint64
+		query  models.FlowEventsQuery
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if query.LastSeq, err = lastEventSeq(c, query.LastSeq); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing last event id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	types := make([]subscriptions.EventType, 0, len(flowEventsTypes))
+	for _, t := range query.Types {
+		types = append(types, subscriptions.EventType(t))
+	}
+	if len(types) == 0 {
+		types = append(types, flowEventsTypes...)
+	}
+	filter := subscriptions.NewEventFilter(types...)
+	// deletion is always received to close the stream even if the event isn't requested
+	types = append(types, subscriptions.EventFlowDeleted)
+
+	ctx := c.Request.Context()
+	subscriber := s.ss.NewFlowSubscriber(int64(uid), int64(flow.ID), types...)
+
+	eventsCh, err := subscriber.FlowEvents(ctx, query.LastSeq)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error subscribing to flow events")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	send := openEventStream(c)
+
+	heartbeat := time.NewTicker(flowEventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-eventsCh:
+			if !ok {
+				return
+			}
+			if event.Type == subscriptions.EventFlowDeleted {
+				if filter.Allows(event.Type) {
+					send(event)
+				}
+				return
+			}
+			send(event)
+		}
+	}
+}
+
+// GetFlowsEvents is a function to stream creation of the flows as server-sent events
+// @Summary Subscribe to flows creation events
+// @Description Streams flow_created events of the user flows or of the flows of all users for flows admin,
+// @Description every event id is the sequence number of the user flows events,
+// @Description events after last_seq (or Last-Event-ID header) are replayed first if they are still buffered
+// @Description otherwise the resync_required event is sent and the flows list must be fetched again
+// @Tags Flows
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param request query models.FlowsEventsQuery false "flows events params"
+// @Success 200 {string} string "flows events stream opened successful"
+// @Failure 400 {object} response.errorResp "invalid flows request data"
+// @Failure 403 {object} response.errorResp "subscribing to flows events not permitted"
+// @Failure 500 {object} response.errorResp "internal error on subscribing to flows events"
+// @Router /flows/events [get]
+func (s *FlowService) GetFlowsEvents(c *gin.Context) {
+	var (
+		err      error
+		query    models.FlowsEventsQuery
+		eventsCh <-chan subscriptions.FlowEvent
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	isAdmin := slices.Contains(privs, "flows.admin")
+	if !isAdmin && !slices.Contains(privs, "flows.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if query.LastSeq, err = lastEventSeq(c, query.LastSeq); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing last event id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	// new flows aren't shared with anybody yet, so the user sees only own ones like in the flows list
+	subscriber := s.ss.NewFlowSubscriber(int64(uid), 0)
+	if isAdmin {
+		eventsCh, err = subscriber.UserFlowsEventsAdmin(ctx, query.LastSeq)
+	} else {
+		eventsCh, err = subscriber.UserFlowsEvents(ctx, query.LastSeq)
+	}
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error subscribing to flows events")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	send := openEventStream(c)
+
+	heartbeat := time.NewTicker(flowEventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-eventsCh:
+			if !ok {
+				return
+			}
+			send(event)
+		}
+	}
+}
+
+// lastEventSeq returns the sequence number of the last received event from the query
+// or from the header which browsers send on reconnect
+func lastEventSeq(c *gin.Context, lastSeq *uint64) (*uint64, error) {
+	if lastSeq != nil {
+		return lastSeq, nil
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		return nil, nil
+	}
+
+	seq, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &seq, nil
+}
+
+// openEventStream writes the server-sent events headers and returns the function to send sequenced events
+func openEventStream(c *gin.Context) func(event subscriptions.FlowEvent) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	// send headers to the client before the first event
+	c.Writer.Flush()
+
+	return func(event subscriptions.FlowEvent) {
+		// the sequence number is the event id to resume the stream from it
+		c.Writer.WriteString("id:" + strconv.FormatUint(event.Seq, 10) + "\n")
+		c.SSEvent(event.Type.String(), event.Data)
+		c.Writer.Flush()
+	}
+}
+
+// CreateFlow is a function to create new flow with custom functions
+// @Summary Create new flow with custom functions
+// @Tags Flows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param json body models.CreateFlow true "flow model to create"
+// @Success 201 {object} response.successResp{data=models.Flow} "flow created successful"
+// @Failure 400 {object} response.errorResp "invalid flow request data"
+// @Failure 403 {object} response.errorResp "creating flow not permitted"
+// @Failure 429 {object} response.errorResp "user already runs the maximum number of flows"
+// @Failure 500 {object} response.errorResp "internal error on creating flow"
+// @Failure 503 {object} response.errorResp "server is shutting down"
+// @Router /flows/ [post]
+func (s *FlowService) CreateFlow(c *gin.Context) {
+	var (
+		err        error
+		flow       models.Flow
+		createFlow models.CreateFlow
+	)
+
+	if err := c.ShouldBindBodyWith(&createFlow, binding.JSON); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if createFlow.TemplateID != nil {
+		template, err := getFlowTemplate(s.db, *createFlow.TemplateID, uid, privs)
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error getting flow template by id")
+			if gorm.IsRecordNotFoundError(err) {
+				response.Error(c, response.ErrFlowTemplatesNotFound, err)
+			} else if errors.Is(err, errInvalidFlowTemplate) {
+				response.Error(c, response.ErrFlowTemplatesInvalidData, err)
+			} else {
+				response.Error(c, response.ErrInternal, err)
+			}
+			return
+		}
+
+		body, _ := c.Get(gin.BodyBytesKey)
+		data, _ := body.([]byte)
+		if createFlow, err = applyFlowTemplate(template.Params, data); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error applying flow template")
+			respondBindError(c, response.ErrFlowsInvalidRequest, err)
+			return
+		}
+	}
+
+	if err := createFlow.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow data")
+		respondBindError(c, response.ErrFlowsInvalidData, err)
+		return
+	}
+
+	if len(createFlow.Secrets) != 0 && !tools.FlowSecretsEnabled(s.cfg) {
+		logger.FromContext(c).Errorf("error storing flow secrets: flow secrets key is not set")
+		response.Error(c, response.ErrFlowsSecretsKeyNotSet, tools.ErrFlowSecretsKeyNotSet)
+		return
+	}
+
+	if createFlow.InputTemplateID != nil {
+		template, err := getInputTemplate(s.db, *createFlow.InputTemplateID, uid, privs)
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error getting input template by id")
+			if gorm.IsRecordNotFoundError(err) {
+				response.Error(c, response.ErrInputTemplatesNotFound, err)
+			} else if errors.Is(err, errInvalidInputTemplate) {
+				response.Error(c, response.ErrInputTemplatesInvalidData, err)
+			} else {
+				response.Error(c, response.ErrInternal, err)
+			}
+			return
+		}
+
+		if createFlow.Input, err = template.Render(createFlow.Variables); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error rendering input template")
+			response.Error(c, response.ErrFlowsInvalidRequest, err)
+			return
+		}
+	}
+
+	if createFlow.SystemPrompt != nil {
+		if err := validator.ValidatePrompt(templates.PromptTypePrimaryAgent, *createFlow.SystemPrompt); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating flow system prompt")
+			response.Error(c, response.ErrFlowsInvalidRequest, err)
+			return
+		}
 	}
 
-	uid := c.GetUint64("uid")
 	prvname := provider.ProviderName(createFlow.Provider)
 
-	prv, err := s.pc.GetProvider(c, prvname, int64(uid))
-	if err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error getting provider: not found")
-		response.Error(c, response.ErrInternal, err)
+	prv, err := s.pc.GetProvider(c, prvname, int64(uid))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting provider: not found")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	prvtype := prv.Type()
+
+	if err := s.checkProviderModels(c, prvname, prv, int64(uid), createFlow); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating provider models")
+		response.Error(c, providerModelsHttpError(err), err)
+		return
+	}
+
+	fallbacks := make(provider.ProvidersListNames, 0, len(createFlow.Fallbacks))
+	for _, name := range createFlow.Fallbacks {
+		fallback := provider.ProviderName(name)
+		if fallback == prvname {
+			continue
+		}
+		fprv, err := s.pc.GetProvider(c, fallback, int64(uid))
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error getting fallback provider '%s': not found", name)
+			response.Error(c, response.ErrFlowsInvalidRequest, err)
+			return
+		}
+		if err := s.checkProviderModels(c, fallback, fprv, int64(uid), createFlow); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating fallback provider '%s' models", name)
+			response.Error(c, providerModelsHttpError(err), err)
+			return
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	var limits *docker.ContainerLimits
+	if res := createFlow.Resources; res != nil {
+		limits = &docker.ContainerLimits{
+			CPUs:     res.CPUs,
+			MemoryMB: res.MemoryMB,
+			Pids:     res.Pids,
+		}
+	}
+
+	var image string
+	if createFlow.SnapshotID != nil {
+		var snapshot models.ContainerSnapshot
+		scope := func(db *gorm.DB) *gorm.DB {
+			return db.Where("container_snapshots.id = ? AND container_snapshots.user_id = ?", *createFlow.SnapshotID, uid)
+		}
+		if slices.Contains(privs, "flows.admin") {
+			scope = func(db *gorm.DB) *gorm.DB {
+				return db.Where("container_snapshots.id = ?", *createFlow.SnapshotID)
+			}
+		}
+
+		err = s.db.Model(&snapshot).
+			Joins("INNER JOIN flows f ON f.id = container_snapshots.flow_id").
+			Where("f.deleted_at IS NULL").
+			Scopes(scope).
+			Take(&snapshot).Error
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error getting container snapshot by id")
+			if gorm.IsRecordNotFoundError(err) {
+				response.Error(c, response.ErrContainersSnapshotNotFound, err)
+			} else {
+				response.Error(c, response.ErrInternal, err)
+			}
+			return
+		}
+		image = snapshot.Image
+	}
+
+	var budget controller.FlowBudget
+	if b := createFlow.Budget; b != nil {
+		budget = controller.FlowBudget{
+			MaxCost:   b.MaxCost,
+			MaxTokens: b.MaxTokens,
+		}
+	}
+
+	var toolLimit controller.FlowToolLimits
+	if tl := createFlow.ToolLimits; tl != nil {
+		toolLimit = controller.FlowToolLimits{
+			CallsPerMinute: tl.CallsPerMinute,
+			MaxCalls:       tl.MaxCalls,
+		}
+	}
+
+	var planLimit controller.FlowPlanLimits
+	if pl := createFlow.PlanLimits; pl != nil {
+		planLimit = controller.FlowPlanLimits{
+			MaxSubtasks: pl.MaxSubtasks,
+			MaxDepth:    pl.MaxDepth,
+		}
+	}
+
+	var resultLimit controller.FlowResultLimits
+	if rl := createFlow.ResultLimits; rl != nil {
+		resultLimit = controller.FlowResultLimits{
+			MaxSize:       rl.MaxSize,
+			MaxSourceSize: rl.MaxSourceSize,
+		}
+	}
+
+	launch := controller.FlowLaunch{
+		Admin: slices.Contains(privs, "flows.admin"),
+		Queue: createFlow.Queue,
+	}
+
+	env := tools.FlowEnv{
+		Vars:    createFlow.Env,
+		Secrets: createFlow.Secrets,
+	}
+
+	var commandPolicy tools.CommandPolicy
+	if createFlow.CommandPolicy != nil {
+		commandPolicy = *createFlow.CommandPolicy
+	}
+
+	fw, err := s.fc.CreateFlow(c, controller.CreateFlowOptions{
+		UserID:          int64(uid),
+		Input:           createFlow.Input,
+		ProviderName:    prvname,
+		ProviderType:    prvtype,
+		Fallbacks:       fallbacks,
+		Limits:          limits,
+		Image:           image,
+		Functions:       createFlow.Functions,
+		Env:             env,
+		Budget:          budget,
+		ToolLimit:       toolLimit,
+		PlanLimit:       planLimit,
+		ResultLimit:     resultLimit,
+		ResponseCache:   providers.ResponseCacheMode(createFlow.ResponseCache),
+		ProviderTimeout: createFlow.ProviderTimeout,
+		SystemPrompt:    createFlow.SystemPrompt,
+		CommandPolicy:   commandPolicy,
+		Scope:           createFlow.Scope,
+		Launch:          launch,
+	})
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating flow")
+		if errors.Is(err, docker.ErrContainerLimitsExceeded) {
+			response.Error(c, response.ErrFlowsResourceLimitsExceeded, err)
+		} else if errors.Is(err, controller.ErrFlowsLimitExceeded) {
+			response.Error(c, response.ErrFlowsRunningLimitExceeded, err)
+		} else if errors.Is(err, controller.ErrServerShuttingDown) {
+			response.Error(c, response.ErrFlowsServerShuttingDown, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	// explicit language replaces the one detected from the flow input, auto language is detected locally
+	language, detection := createFlow.Language, ""
+	if language != nil && (*language == "" || *language == models.FlowLanguageAuto) {
+		var detected string
+		detected, detection = s.detectFlowLanguage(createFlow.Input)
+		language = &detected
+		logger.FromContext(c).WithField("language", detected).Infof("flow language %s", detection)
+	}
+	if language != nil {
+		if err := fw.UpdateMetadata(c, controller.FlowMetadata{Language: language}); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error setting flow language")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+	}
+
+	err = s.db.Model(&flow).Where("id = ?", fw.GetFlowID()).Take(&flow).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	// flow is created by the flow controller so the audit log can't share its transaction
+	changes := flowAuditChanges(models.Flow{}, flow)
+	if detection != "" {
+		changes["language_detection"] = models.AuditChange{Before: nil, After: detection}
+	}
+	if err = writeAuditLog(c, s.db, models.AuditActionFlowCreate, flow.ID, changes); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error writing flow audit log")
+	}
+
+	response.Success(c, http.StatusCreated, flow)
+}
+
+// detectFlowLanguage returns the language of the flow input and the note how it was chosen,
+// the default language from the config is used if the detection isn't confident
+func (s *FlowService) detectFlowLanguage(input string) (string, string) {
+	result := langdetect.Detect(input)
+	if result.IsConfident() {
+		return result.Language, fmt.Sprintf("detected with confidence %.2f", result.Confidence)
+	}
+
+	language := "English"
+	if s.cfg != nil && slices.Contains(models.SupportedFlowLanguages, s.cfg.FlowDefaultLanguage) {
+		language = s.cfg.FlowDefaultLanguage
+	}
+
+	return language, fmt.Sprintf("default language is used, detection confidence %.2f is too low", result.Confidence)
+}
+
+// checkProviderModels rejects the provider if its agents use models which the provider doesn't serve
+// or which can't call the custom functions of the flow, it's cheaper to fail here than on the first
+// agent call after the flow was created
+func (s *FlowService) checkProviderModels(
+	c *gin.Context,
+	prvname provider.ProviderName,
+	prv provider.Provider,
+	uid int64,
+	createFlow models.CreateFlow,
+) error {
+	prvModels, err := s.pc.GetProviderModels(c, prvname, uid)
+	if err != nil {
+		return fmt.Errorf("failed to get provider '%s' models: %w", prvname, err)
+	}
+
+	if err := providers.CheckProviderModels(prvname, prv, prvModels); err != nil {
+		return err
+	}
+
+	if createFlow.SystemPrompt != nil {
+		if err := providers.CheckSystemPrompt(prvname, prv, prvModels, *createFlow.SystemPrompt); err != nil {
+			return err
+		}
+	}
+
+	if !createFlow.HasFunctions() {
+		return nil
+	}
+
+	return providers.CheckProviderFunctions(prvname, prv, prvModels, createFlow.FunctionsCompatMode)
+}
+
+// providerModelsHttpError returns the client error for the system prompt which doesn't fit the model
+func providerModelsHttpError(err error) *response.HttpError {
+	if errors.Is(err, providers.ErrSystemPromptTooLarge) {
+		return response.ErrFlowsInvalidRequest
+	}
+
+	return response.ErrFlowsInvalidData
+}
+
+// PatchFlow is a function to patch flow
+// @Summary Patch flow
+// @Description The budget action replaces the flow usage limits and resumes the flow stopped by exceeded budget,
+// @Description the input action queues the input and returns its id in the inputs history by the header
+// @Tags Flows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param json body models.PatchFlow true "flow model to patch"
+// @Success 200 {object} response.successResp{data=models.Flow} "flow patched successful"
+// @Header 200 {integer} X-Flow-Input-ID "id of the queued input for the input action"
+// @Failure 400 {object} response.errorResp "invalid flow request data or flow usage exceeds the budget"
+// @Failure 403 {object} response.errorResp "patching flow not permitted"
+// @Failure 500 {object} response.errorResp "internal error on patching flow"
+// @Router /flows/{flowID} [put]
+func (s *FlowService) PatchFlow(c *gin.Context) {
+	var (
+		err       error
+		flow      models.Flow
+		flowID    uint64
+		inputID   int64
+		patchFlow models.PatchFlow
+	)
+
+	if err := c.ShouldBindJSON(&patchFlow); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	if err := patchFlow.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow data")
+		respondBindError(c, response.ErrFlowsInvalidData, err)
+		return
+	}
+
+	flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.edit") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid, models.FlowAccessEdit))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	fw, err := s.fc.GetFlow(c, int64(flow.ID))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id in flow controller")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	before := flow
+	var action models.AuditAction
+	switch patchFlow.Action {
+	case "stop":
+		action = models.AuditActionFlowStop
+		if err := fw.Stop(c); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error stopping flow")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+	case "finish":
+		action = models.AuditActionFlowFinish
+		if err := fw.Finish(c); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error finishing flow")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+	case "input":
+		action = models.AuditActionFlowInput
+		if patchFlow.Input == nil || *patchFlow.Input == "" {
+			logger.FromContext(c).Errorf("error sending input to flow: input is empty")
+			response.Error(c, response.ErrFlowsInvalidRequest, nil)
+			return
+		}
+
+		if inputID, err = fw.PutInput(c, *patchFlow.Input); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error sending input to flow")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+		c.Header(flowInputIDHeader, strconv.FormatInt(inputID, 10))
+	case "rename":
+		action = models.AuditActionFlowRename
+		if patchFlow.Name == nil || *patchFlow.Name == "" {
+			logger.FromContext(c).Errorf("error renaming flow: name is empty")
+			response.Error(c, response.ErrFlowsInvalidRequest, nil)
+			return
+		}
+		if err := fw.Rename(c, *patchFlow.Name); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error renaming flow")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+	case "metadata":
+		action = models.AuditActionFlowMetadata
+		if !patchFlow.HasMetadata() {
+			logger.FromContext(c).Errorf("error updating flow metadata: no fields to update")
+			response.Error(c, response.ErrFlowsInvalidRequest, nil)
+			return
+		}
+		if err := fw.UpdateMetadata(c, controller.FlowMetadata{
+			Title:       patchFlow.Title,
+			Language:    patchFlow.Language,
+			Description: patchFlow.Description,
+		}); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error updating flow metadata")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+	case "budget":
+		action = models.AuditActionFlowBudget
+		if patchFlow.Budget == nil {
+			logger.FromContext(c).Errorf("error setting flow budget: budget is empty")
+			response.Error(c, response.ErrFlowsInvalidRequest, nil)
+			return
+		}
+		err := fw.SetBudget(c, controller.FlowBudget{
+			MaxCost:   patchFlow.Budget.MaxCost,
+			MaxTokens: patchFlow.Budget.MaxTokens,
+		})
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error setting flow budget")
+			if errors.Is(err, controller.ErrFlowBudgetExceeded) {
+				response.Error(c, response.ErrFlowsBudgetExceeded, err)
+			} else {
+				response.Error(c, response.ErrInternal, err)
+			}
+			return
+		}
+	default:
+		logger.FromContext(c).Errorf("error filtering flow action")
+		response.Error(c, response.ErrFlowsInvalidRequest, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	// flow is changed by the flow controller so the audit log can't share its transaction
+	changes := flowAuditChanges(before, flow)
+	if action == models.AuditActionFlowInput {
+		changes["input"] = models.AuditChange{Before: nil, After: *patchFlow.Input}
+		changes["input_id"] = models.AuditChange{Before: nil, After: inputID}
+	}
+	if err = writeAuditLog(c, s.db, action, flow.ID, changes); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error writing flow audit log")
+	}
+
+	response.Success(c, http.StatusOK, flow)
+}
+
+// SnapshotFlowContainer is a function to commit the running flow container to the image
+// @Summary Create snapshot of the flow container
+// @Tags Flows
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param containerID path int true "container id" minimum(0)
+// @Success 201 {object} response.successResp{data=models.ContainerSnapshot} "container snapshot created successful"
+// @Failure 400 {object} response.errorResp "invalid snapshot request data or container is not running"
+// @Failure 403 {object} response.errorResp "creating snapshot not permitted"
+// @Failure 404 {object} response.errorResp "flow or container not found"
+// @Failure 500 {object} response.errorResp "internal error on creating snapshot"
+// @Router /flows/{flowID}/containers/{containerID}/snapshot [post]
+func (s *FlowService) SnapshotFlowContainer(c *gin.Context) {
+	var (
+		err         error
+		flow        models.Flow
+		flowID      uint64
+		containerID uint64
+		resp        models.ContainerSnapshot
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+	if containerID, err = strconv.ParseUint(c.Param("containerID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing container id")
+		response.Error(c, response.ErrContainersInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "flows.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "flows.edit") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid, models.FlowAccessEdit))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
 		return
 	}
-	prvtype := prv.Type()
 
-	fw, err := s.fc.CreateFlow(c, int64(uid), createFlow.Input, prvname, prvtype, createFlow.Functions)
+	// snapshot is owned by the flow owner even if it was created by admin
+	snapshot, err := s.fc.SnapshotContainer(c, int64(flow.UserID), int64(flow.ID), int64(containerID))
 	if err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error creating flow")
-		response.Error(c, response.ErrInternal, err)
+		logger.FromContext(c).WithError(err).Errorf("error creating container snapshot")
+		switch {
+		case errors.Is(err, controller.ErrContainerNotFound):
+			response.Error(c, response.ErrContainersNotFound, err)
+		case errors.Is(err, controller.ErrContainerNotRunning):
+			response.Error(c, response.ErrContainersNotRunning, err)
+		default:
+			response.Error(c, response.ErrInternal, err)
+		}
 		return
 	}
 
-	err = s.db.Model(&flow).Where("id = ?", fw.GetFlowID()).Take(&flow).Error
-	if err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
+	if err = s.db.Model(&resp).Where("id = ?", snapshot.ID).Take(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting container snapshot by id")
 		response.Error(c, response.ErrInternal, err)
 		return
 	}
 
-	response.Success(c, http.StatusCreated, flow)
+	response.Success(c, http.StatusCreated, resp)
 }
 
-// PatchFlow is a function to patch flow
-// @Summary Patch flow
+// SetFlowSecrets is a function to rotate secrets of the flow
+// @Summary Rotate flow secrets
+// @Description The secrets replace all previous ones and are applied to the next terminal command of the flow
 // @Tags Flows
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param json body models.PatchFlow true "flow model to patch"
-// @Success 200 {object} response.successResp{data=models.Flow} "flow patched successful"
-// @Failure 400 {object} response.errorResp "invalid flow request data"
-// @Failure 403 {object} response.errorResp "patching flow not permitted"
-// @Failure 500 {object} response.errorResp "internal error on patching flow"
-// @Router /flows/{flowID} [put]
-func (s *FlowService) PatchFlow(c *gin.Context) {
+// @Param json body models.FlowSecrets true "new secrets of the flow"
+// @Success 200 {object} response.successResp{data=[]models.FlowEnvVar} "flow secrets rotated successful"
+// @Failure 400 {object} response.errorResp "invalid flow secrets request data or secrets key is not set"
+// @Failure 403 {object} response.errorResp "rotating flow secrets not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on rotating flow secrets"
+// @Router /flows/{flowID}/secrets [put]
+func (s *FlowService) SetFlowSecrets(c *gin.Context) {
 	var (
-		err       error
-		flow      models.Flow
-		flowID    uint64
-		patchFlow models.PatchFlow
+		err     error
+		flow    models.Flow
+		flowID  uint64
+		secrets models.FlowSecrets
 	)
 
-	if err := c.ShouldBindJSON(&patchFlow); err != nil {
+	if err := c.ShouldBindJSON(&secrets); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		respondBindError(c, response.ErrFlowsInvalidRequest, err)
 		return
 	}
 
-	if err := patchFlow.Valid(); err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error validating flow data")
+	if err := secrets.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow secrets")
 		response.Error(c, response.ErrFlowsInvalidData, err)
 		return
 	}
 
-	flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64)
-	if err != nil {
+	// the empty secrets only remove the previous ones, so they don't need the key
+	if len(secrets.Secrets) != 0 && !tools.FlowSecretsEnabled(s.cfg) {
+		logger.FromContext(c).Errorf("error storing flow secrets: flow secrets key is not set")
+		response.Error(c, response.ErrFlowsSecretsKeyNotSet, tools.ErrFlowSecretsKeyNotSet)
+		return
+	}
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
 		response.Error(c, response.ErrFlowsInvalidRequest, err)
 		return
@@ -423,7 +1944,7 @@ func (s *FlowService) PatchFlow(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.edit") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ? AND (user_id = ? OR id IN (?))", flowID, uid, s.sharedFlows(uid, models.FlowAccessEdit))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -441,66 +1962,38 @@ func (s *FlowService) PatchFlow(c *gin.Context) {
 		return
 	}
 
-	fw, err := s.fc.GetFlow(c, int64(flow.ID))
+	rows, err := s.fc.SetFlowSecrets(c, int64(flow.ID), secrets.Secrets)
 	if err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error getting flow by id in flow controller")
+		logger.FromContext(c).WithError(err).Errorf("error setting flow secrets")
 		response.Error(c, response.ErrInternal, err)
 		return
 	}
 
-	switch patchFlow.Action {
-	case "stop":
-		if err := fw.Stop(c); err != nil {
-			logger.FromContext(c).WithError(err).Errorf("error stopping flow")
-			response.Error(c, response.ErrInternal, err)
-			return
-		}
-	case "finish":
-		if err := fw.Finish(c); err != nil {
-			logger.FromContext(c).WithError(err).Errorf("error finishing flow")
-			response.Error(c, response.ErrInternal, err)
-			return
-		}
-	case "input":
-		if patchFlow.Input == nil || *patchFlow.Input == "" {
-			logger.FromContext(c).Errorf("error sending input to flow: input is empty")
-			response.Error(c, response.ErrFlowsInvalidRequest, nil)
-			return
-		}
-
-		if err := fw.PutInput(c, *patchFlow.Input); err != nil {
-			logger.FromContext(c).WithError(err).Errorf("error sending input to flow")
-			response.Error(c, response.ErrInternal, err)
-			return
-		}
-	case "rename":
-		if patchFlow.Name == nil || *patchFlow.Name == "" {
-			logger.FromContext(c).Errorf("error renaming flow: name is empty")
-			response.Error(c, response.ErrFlowsInvalidRequest, nil)
-			return
+	// values of the secrets are never returned and never written to the audit log
+	resp := make([]models.FlowEnvVar, 0, len(rows))
+	names := make([]string, 0, len(secrets.Secrets))
+	for _, row := range rows {
+		envVar := models.FlowEnvVar{
+			Name:      row.Name,
+			Value:     row.Value,
+			Secret:    row.Secret,
+			UpdatedAt: row.UpdatedAt.Time,
 		}
-		if err := fw.Rename(c, *patchFlow.Name); err != nil {
-			logger.FromContext(c).WithError(err).Errorf("error renaming flow")
-			response.Error(c, response.ErrInternal, err)
-			return
+		if row.Secret {
+			envVar.Value = models.FlowSecretRedacted
+			names = append(names, row.Name)
 		}
-	default:
-		logger.FromContext(c).Errorf("error filtering flow action")
-		response.Error(c, response.ErrFlowsInvalidRequest, nil)
-		return
+		resp = append(resp, envVar)
 	}
 
-	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
-		if gorm.IsRecordNotFoundError(err) {
-			response.Error(c, response.ErrFlowsNotFound, err)
-		} else {
-			response.Error(c, response.ErrInternal, err)
-		}
-		return
+	changes := models.AuditChanges{
+		"secrets": models.AuditChange{Before: nil, After: names},
+	}
+	if err = writeAuditLog(c, s.db, models.AuditActionFlowSecrets, flow.ID, changes); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error writing flow audit log")
 	}
 
-	response.Success(c, http.StatusOK, flow)
+	response.Success(c, http.StatusOK, resp)
 }
 
 // DeleteFlow is a function to delete flow by id
@@ -511,7 +2004,7 @@ func (s *FlowService) PatchFlow(c *gin.Context) {
 // @Success 200 {object} response.successResp{data=models.Flow} "flow deleted successful"
 // @Failure 403 {object} response.errorResp "deleting flow not permitted"
 // @Failure 404 {object} response.errorResp "flow not found"
-// @Failure 500 {object} response.errorResp "internal error on deleting flow"
+// @Failure 500 {object} response.errorResp "internal error on deleting flow or flow containers were partially cleaned up"
 // @Router /flows/{flowID} [delete]
 func (s *FlowService) DeleteFlow(c *gin.Context) {
 	var (
@@ -554,27 +2047,20 @@ func (s *FlowService) DeleteFlow(c *gin.Context) {
 		return
 	}
 
-	if err := s.fc.FinishFlow(c, int64(flow.ID)); err != nil {
+	// containers, snapshots, attachments and artifacts are kept to restore the flow until it's purged
+	if err := s.fc.ArchiveFlow(c, int64(flow.ID)); err != nil && !errors.Is(err, controller.ErrFlowNotFound) {
 		logger.FromContext(c).WithError(err).Errorf("error stopping flow")
 		response.Error(c, response.ErrInternal, err)
 		return
 	}
 
-	var containers []models.Container
-	err = s.db.Model(&containers).Where("flow_id = ?", flow.ID).Find(&containers).Error
+	// containers snapshot is taken with the deletion so subscribers get the state of the deleted flow
+	unlock := s.fc.LockFlowContainers(int64(flow.ID))
+	containers, err := s.deleteFlow(c, flow, scope)
+	unlock()
 	if err != nil {
-		logger.FromContext(c).WithError(err).Errorf("error getting flow containers")
-		response.Error(c, response.ErrInternal, err)
-		return
-	}
-
-	if err = s.db.Scopes(scope).Delete(&flow).Error; err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error deleting flow by id")
-		if gorm.IsRecordNotFoundError(err) {
-			response.Error(c, response.ErrFlowsNotFound, err)
-		} else {
-			response.Error(c, response.ErrInternal, err)
-		}
+		s.cleanupFlowContainers(c, flow.ID, err)
 		return
 	}
 
@@ -599,6 +2085,308 @@ func (s *FlowService) DeleteFlow(c *gin.Context) {
 	response.Success(c, http.StatusOK, flow)
 }
 
+// RestoreFlow is a function to restore the deleted flow by id
+// @Summary Restore deleted flow by id
+// @Description Flow gets back its status before deletion, running flows become waiting because their tasks were finished on deletion.
+// @Description The flow keeps its containers, snapshots, attachments and artifacts until it's purged after the retention period, the purged ones are reported in the response
+// @Tags Flows
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param json body models.RestoreFlow false "flow restoring options"
+// @Success 200 {object} response.successResp{data=models.RestoredFlow} "flow restored successful"
+// @Failure 400 {object} response.errorResp "invalid flow request data or flow containers were already removed"
+// @Failure 403 {object} response.errorResp "restoring flow not permitted"
+// @Failure 404 {object} response.errorResp "deleted flow not found"
+// @Failure 500 {object} response.errorResp "internal error on restoring flow"
+// @Router /flows/{flowID}/restore [post]
+func (s *FlowService) RestoreFlow(c *gin.Context) {
+	var (
+		err         error
+		flow        models.Flow
+		flowID      uint64
+		restoreFlow models.RestoreFlow
+	)
+
+	if err = c.ShouldBindJSON(&restoreFlow); err != nil && !errors.Is(err, io.EOF) {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	if err = restoreFlow.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating flow restoring data")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	err = s.db.Unscoped().Model(&flow).Where("id = ? AND deleted_at IS NOT NULL", flowID).Take(&flow).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting deleted flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	var containers []models.Container
+	err = s.db.Model(&containers).Where("flow_id = ?", flow.ID).Find(&containers).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow containers")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if restoreFlow.Reattach {
+		reaped := len(containers) == 0
+		for _, container := range containers {
+			if container.Status != models.ContainerStatusRunning {
+				reaped = true
+			}
+		}
+		if reaped {
+			logger.FromContext(c).Errorf("error reattaching flow containers: containers were already removed")
+			response.Error(c, response.ErrFlowsContainersReaped, nil)
+			return
+		}
+	}
+
+	// flow tasks were finished on deletion so the active flow can only wait for the new input
+	status := s.flowStatusBeforeDelete(flow)
+	active := slices.Contains([]models.FlowStatus{
+		models.FlowStatusCreated, models.FlowStatusRunning, models.FlowStatusWaiting,
+	}, status)
+	if active {
+		status = models.FlowStatusWaiting
+	}
+
+	restored, err := s.restoreFlow(c, flow, status)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error restoring flow by id")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	// flow worker reattaches the running primary container or spawns it again from the same image
+	if active {
+		if _, err := s.fc.LoadFlow(c, int64(flow.ID)); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error loading restored flow worker")
+			err := s.db.Model(&restored).Updates(map[string]any{
+				"status":        models.FlowStatusFailed,
+				"finish_reason": models.FlowFinishReasonError,
+			}).Error
+			if err != nil {
+				logger.FromContext(c).WithError(err).Errorf("error setting restored flow status")
+			}
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+	} else if s.ss != nil {
+		flowDB, err := convertFlowToDatabase(restored)
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error converting flow to database")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+
+		containersDB := make([]database.Container, 0, len(containers))
+		for _, container := range containers {
+			containersDB = append(containersDB, convertContainerToDatabase(container))
+		}
+
+		publisher := s.ss.NewFlowPublisher(int64(restored.UserID), int64(restored.ID))
+		publisher.FlowCreated(c, flowDB, containersDB)
+	}
+
+	response.Success(c, http.StatusOK, models.RestoredFlow{
+		Flow:   restored,
+		Purged: s.flowPurgedAfterDelete(flow),
+	})
+}
+
+// flowPurgedAfterDelete sums the resources removed by the purges of the flow after its last deletion
+// according to the audit log, nil is returned if the flow wasn't purged
+func (s *FlowService) flowPurgedAfterDelete(flow models.Flow) *models.FlowPurged {
+	var deleteLog models.AuditLog
+	err := s.db.Where("flow_id = ? AND action = ?", flow.ID, models.AuditActionFlowDelete).
+		Order("id DESC").
+		Take(&deleteLog).Error
+	if err != nil {
+		return nil
+	}
+
+	var purgeLogs []models.AuditLog
+	err = s.db.Where("flow_id = ? AND action = ? AND id > ?", flow.ID, models.AuditActionFlowPurge, deleteLog.ID).
+		Find(&purgeLogs).Error
+	if err != nil || len(purgeLogs) == 0 {
+		return nil
+	}
+
+	removed := func(changes models.AuditChanges, name string) int {
+		if before, ok := changes[name].Before.(float64); ok {
+			return int(before)
+		}
+		return 0
+	}
+
+	var purged models.FlowPurged
+	for _, purgeLog := range purgeLogs {
+		purged.Containers += removed(purgeLog.Changes, "containers")
+		purged.Snapshots += removed(purgeLog.Changes, "snapshots")
+		purged.Attachments += removed(purgeLog.Changes, "attachments")
+		purged.Artifacts += removed(purgeLog.Changes, "artifacts")
+	}
+
+	return &purged
+}
+
+// flowStatusBeforeDelete returns the flow status before it was finished by deletion
+// according to the audit log or the current status if the log has no record of it
+func (s *FlowService) flowStatusBeforeDelete(flow models.Flow) models.FlowStatus {
+	var auditLog models.AuditLog
+	err := s.db.Where("flow_id = ? AND action = ?", flow.ID, models.AuditActionFlowDelete).
+		Order("id DESC").
+		Take(&auditLog).Error
+	if err != nil {
+		return flow.Status
+	}
+
+	if change, ok := auditLog.Changes["status"]; ok {
+		if before, ok := change.Before.(string); ok && models.FlowStatus(before).Valid() == nil {
+			return models.FlowStatus(before)
+		}
+	}
+
+	return flow.Status
+}
+
+// restoreFlow clears the deletion mark of the flow, sets its status and writes the audit log
+// in the same transaction, the finish and stop reasons were left by the deletion so they are cleared too
+func (s *FlowService) restoreFlow(c *gin.Context, flow models.Flow, status models.FlowStatus) (models.Flow, error) {
+	var restored models.Flow
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return restored, tx.Error
+	}
+
+	err := tx.Unscoped().Model(&models.Flow{}).
+		Where("id = ?", flow.ID).
+		Updates(map[string]any{
+			"deleted_at":    nil,
+			"status":        status,
+			"finish_reason": nil,
+			"stop_reason":   nil,
+		}).Error
+	if err != nil {
+		tx.Rollback()
+		return restored, err
+	}
+
+	if err := tx.Where("id = ?", flow.ID).Take(&restored).Error; err != nil {
+		tx.Rollback()
+		return restored, err
+	}
+
+	changes := flowAuditChanges(flow, restored)
+	if err := writeAuditLog(c, tx, models.AuditActionFlowRestore, flow.ID, changes); err != nil {
+		tx.Rollback()
+		return restored, err
+	}
+
+	return restored, tx.Commit().Error
+}
+
+// deleteFlow marks the flow as deleted and writes the audit log in the same transaction,
+// the before state is the flow taken before it was finished by the flow controller
+func (s *FlowService) deleteFlow(
+	c *gin.Context,
+	flow models.Flow,
+	scope func(db *gorm.DB) *gorm.DB,
+) ([]models.Container, error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	// container rows are locked until commit so status updates from outside of the controller
+	// can't change them between the snapshot and the deletion
+	query := tx.Where("flow_id = ?", flow.ID)
+	if tx.Dialect().GetName() == "postgres" {
+		query = query.Set("gorm:query_option", "FOR UPDATE")
+	}
+
+	var containers []models.Container
+	if err := query.Find(&containers).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Scopes(scope).Delete(&flow).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var deleted models.Flow
+	if err := tx.Unscoped().Where("id = ?", flow.ID).Take(&deleted).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	changes := flowAuditChanges(flow, deleted)
+	if err := writeAuditLog(c, tx, models.AuditActionFlowDelete, flow.ID, changes); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return containers, nil
+}
+
+// flowContainersCleanup is the details of the partial flow deletion error response
+type flowContainersCleanup struct {
+	Removed  []int64 `json:"removed"`
+	Orphaned []int64 `json:"orphaned"`
+}
+
+// cleanupFlowContainers removes flow containers when flow deletion failed after the flow was finished,
+// containers which can't be removed are recorded as orphaned to be removed by the reaper later
+func (s *FlowService) cleanupFlowContainers(c *gin.Context, flowID uint64, original error) {
+	cleanup, err := s.fc.CleanupFlowContainers(c, int64(flowID))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error cleaning up flow containers")
+	}
+
+	if cleanup.IsPartial() {
+		logger.FromContext(c).WithField("orphaned", cleanup.Orphaned).Warnf("flow containers were orphaned")
+	}
+
+	response.ErrorWithDetails(c, response.ErrFlowsPartialCleanup, original, flowContainersCleanup{
+		Removed:  append([]int64{}, cleanup.Removed...),
+		Orphaned: append([]int64{}, cleanup.Orphaned...),
+	})
+}
+
 func convertFlowToDatabase(flow models.Flow) (database.Flow, error) {
 	functions, err := json.Marshal(flow.Functions)
 	if err != nil {
@@ -620,20 +2408,81 @@ func convertFlowToDatabase(flow models.Flow) (database.Flow, error) {
 		TraceID:            database.PtrStringToNullString(flow.TraceID),
 		ModelProviderType:  database.ProviderType(flow.ModelProviderType),
 		ToolCallIDTemplate: flow.ToolCallIDTemplate,
+		FallbackProviders:  flow.FallbackProviders,
+		Description:        flow.Description,
+		FinishReason:       convertFinishReasonToDatabase(flow.FinishReason),
 	}, nil
 }
 
+func convertFinishReasonToDatabase(reason *models.FlowFinishReason) database.NullFlowFinishReason {
+	if reason == nil {
+		return database.NullFlowFinishReason{}
+	}
+
+	return database.NullFlowFinishReason{FlowFinishReason: database.FlowFinishReason(*reason), Valid: true}
+}
+
 func convertContainerToDatabase(container models.Container) database.Container {
 	return database.Container{
-		ID:        int64(container.ID),
-		Type:      database.ContainerType(container.Type),
-		Name:      container.Name,
-		Image:     container.Image,
-		Status:    database.ContainerStatus(container.Status),
-		LocalID:   database.StringToNullString(container.LocalID),
-		LocalDir:  database.StringToNullString(container.LocalDir),
-		FlowID:    int64(container.FlowID),
-		CreatedAt: database.TimeToNullTime(container.CreatedAt),
-		UpdatedAt: database.TimeToNullTime(container.UpdatedAt),
+		ID:          int64(container.ID),
+		Type:        database.ContainerType(container.Type),
+		Name:        container.Name,
+		Image:       container.Image,
+		Status:      database.ContainerStatus(container.Status),
+		LocalID:     database.StringToNullString(container.LocalID),
+		LocalDir:    database.StringToNullString(container.LocalDir),
+		FlowID:      int64(container.FlowID),
+		CreatedAt:   database.TimeToNullTime(container.CreatedAt),
+		UpdatedAt:   database.TimeToNullTime(container.UpdatedAt),
+		CpuLimit:    container.CPULimit,
+		MemoryLimit: container.MemoryLimit,
+		PidsLimit:   container.PidsLimit,
+	}
+}
+
+// convertMsglogToFlowMessage returns the message log record as the conversation message with the role of its author
+func convertMsglogToFlowMessage(msglog models.Msglog, maxContent int) models.FlowMessage {
+	message := models.FlowMessage{
+		ID:           msglog.ID,
+		Type:         msglog.Type,
+		Role:         "ai",
+		ResultFormat: msglog.ResultFormat,
+		FlowID:       msglog.FlowID,
+		TaskID:       msglog.TaskID,
+		SubtaskID:    msglog.SubtaskID,
+		CreatedAt:    msglog.CreatedAt,
+	}
+
+	switch msglog.Type {
+	case models.MsglogTypeInput:
+		message.Role = "human"
+	case models.MsglogTypeTerminal, models.MsglogTypeBrowser, models.MsglogTypeFile, models.MsglogTypeSearch:
+		message.Role = "tool"
+	}
+
+	truncate := func(text string) string {
+		result, truncated := truncateFlowMessageContent(text, maxContent)
+		message.Truncated = message.Truncated || truncated
+		return result
 	}
+
+	message.Content = truncate(msglog.Message)
+	message.Thinking = truncate(msglog.Thinking)
+	message.Result = truncate(msglog.Result)
+
+	return message
+}
+
+// truncateFlowMessageContent cuts text to maxContent bytes without breaking UTF-8 runes
+func truncateFlowMessageContent(text string, maxContent int) (string, bool) {
+	if maxContent <= 0 || len(text) <= maxContent {
+		return text, false
+	}
+
+	cut := maxContent
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s... [truncated %d bytes]", text[:cut], len(text)-cut), true
 }
@@ -0,0 +1,1971 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/controller"
+	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
+	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/report"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFlowMessagesTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE flows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			system_prompt TEXT NULL,
+			deleted_at DATETIME
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE flow_acls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			access TEXT NOT NULL DEFAULT 'view'
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE msglogs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			message TEXT NOT NULL,
+			thinking TEXT NULL,
+			result TEXT NOT NULL DEFAULT '',
+			result_format TEXT NOT NULL DEFAULT 'plain',
+			flow_id INTEGER NOT NULL,
+			task_id INTEGER NULL,
+			subtask_id INTEGER NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec("INSERT INTO flows (id, user_id, system_prompt) VALUES (1, 1, 'You are a primary agent'), (2, 2, NULL)")
+
+	insertMsglog := func(id int, msgType, message, thinking, result, format string, taskID, subtaskID any, createdAt string) {
+		db.Exec(`INSERT INTO msglogs (id, type, message, thinking, result, result_format, flow_id, task_id, subtask_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?, ?)`,
+			id, msgType, message, thinking, result, format, taskID, subtaskID, createdAt)
+	}
+
+	// inserted out of order to check chronological ordering, the rows of the same time are ordered by id
+	insertMsglog(4, "answer", "Port 22 is open", "", "", "markdown", 1, 1, "2026-01-01 10:05:00")
+	insertMsglog(3, "terminal", "Scan the host", "nmap is the fastest",
+		strings.Repeat("PORT STATE SERVICE\n", 100), "terminal", 1, 1, "2026-01-01 10:05:00")
+	insertMsglog(2, "thoughts", "Plan the scan", "", "", "plain", 1, nil, "2026-01-01 10:01:00")
+	insertMsglog(1, "input", "Scan 10.0.0.1", "", "", "plain", 1, nil, "2026-01-01 10:00:00")
+
+	return db
+}
+
+func doGetFlowMessages(t *testing.T, db *gorm.DB, flowID, rawQuery string, privs []string) (*httptest.ResponseRecorder, flowMessages) {
+	t.Helper()
+
+	service := NewFlowService(db, nil, nil, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+	c.Request, _ = http.NewRequest("GET", "/flows/"+flowID+"/messages?"+rawQuery, nil)
+
+	service.GetFlowMessages(c)
+
+	var resp struct {
+		Data flowMessages `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp.Data
+}
+
+func TestGetFlowMessages(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	w, resp := doGetFlowMessages(t, db, "1", "", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, uint64(4), resp.Total)
+	require.Len(t, resp.Messages, 4)
+	require.NotNil(t, resp.SystemPrompt)
+	assert.Equal(t, "You are a primary agent", *resp.SystemPrompt)
+
+	ids := make([]uint64, 0, len(resp.Messages))
+	for _, message := range resp.Messages {
+		ids = append(ids, message.ID)
+	}
+	assert.Equal(t, []uint64{1, 2, 3, 4}, ids)
+
+	input := resp.Messages[0]
+	assert.Equal(t, models.MsglogTypeInput, input.Type)
+	assert.Equal(t, "human", input.Role)
+	assert.Equal(t, "Scan 10.0.0.1", input.Content)
+	require.NotNil(t, input.TaskID)
+	assert.Nil(t, input.SubtaskID)
+
+	assert.Equal(t, "ai", resp.Messages[1].Role)
+
+	toolCall := resp.Messages[2]
+	assert.Equal(t, models.MsglogTypeTerminal, toolCall.Type)
+	assert.Equal(t, "tool", toolCall.Role)
+	assert.Equal(t, "nmap is the fastest", toolCall.Thinking)
+	assert.Equal(t, models.MsglogResultFormatTerminal, toolCall.ResultFormat)
+	assert.True(t, strings.HasPrefix(toolCall.Result, "PORT STATE"))
+	require.NotNil(t, toolCall.SubtaskID)
+	assert.Equal(t, uint64(1), *toolCall.SubtaskID)
+	assert.False(t, toolCall.Truncated)
+	assert.Equal(t, time.Date(2026, 1, 1, 10, 5, 0, 0, time.UTC), toolCall.CreatedAt.UTC())
+}
+
+func TestGetFlowMessages_Paging(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	w, resp := doGetFlowMessages(t, db, "1", "offset=1&limit=2", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(4), resp.Total)
+	require.Len(t, resp.Messages, 2)
+	assert.Equal(t, uint64(2), resp.Messages[0].ID)
+	assert.Equal(t, uint64(3), resp.Messages[1].ID)
+
+	w, resp = doGetFlowMessages(t, db, "1", "offset=10", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(4), resp.Total)
+	assert.Empty(t, resp.Messages)
+
+	w, _ = doGetFlowMessages(t, db, "1", "limit=1001", []string{"flows.view"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetFlowMessages_MaxContent(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	w, resp := doGetFlowMessages(t, db, "1", "max_content=16", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, resp.Messages, 4)
+
+	toolCall := resp.Messages[2]
+	assert.True(t, toolCall.Truncated)
+	assert.True(t, strings.HasPrefix(toolCall.Result, "PORT STATE"))
+	assert.Contains(t, toolCall.Result, "... [truncated 1884 bytes]")
+	assert.Contains(t, toolCall.Thinking, "... [truncated 3 bytes]")
+
+	assert.Equal(t, "Scan 10.0.0.1", resp.Messages[0].Content)
+	assert.False(t, resp.Messages[0].Truncated)
+}
+
+func TestGetFlowMessages_Scoping(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	w, _ := doGetFlowMessages(t, db, "2", "", []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, resp := doGetFlowMessages(t, db, "2", "", []string{"flows.admin"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint64(0), resp.Total)
+	assert.Nil(t, resp.SystemPrompt, "flow without override uses the default system prompt")
+
+	w, _ = doGetFlowMessages(t, db, "1", "", []string{"msglogs.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestTruncateFlowMessageContent(t *testing.T) {
+	text, truncated := truncateFlowMessageContent("short", 0)
+	assert.Equal(t, "short", text)
+	assert.False(t, truncated)
+
+	text, truncated = truncateFlowMessageContent("привет", 3)
+	assert.True(t, truncated)
+	assert.Equal(t, "п... [truncated 10 bytes]", text)
+}
+
+// flowEventsSubscriptions signals when the subscription of the flow events stream is created
+type flowEventsSubscriptions struct {
+	subscriptions.SubscriptionsController
+	ready chan struct{}
+}
+
+func (ts *flowEventsSubscriptions) NewFlowSubscriber(userID, flowID int64, types ...subscriptions.EventType) subscriptions.FlowSubscriber {
+	return &flowEventsSubscriber{
+		FlowSubscriber: ts.SubscriptionsController.NewFlowSubscriber(userID, flowID, types...),
+		ready:          ts.ready,
+	}
+}
+
+type flowEventsSubscriber struct {
+	subscriptions.FlowSubscriber
+	ready chan struct{}
+}
+
+func (ts *flowEventsSubscriber) FlowEvents(ctx context.Context, lastSeq *uint64) (<-chan subscriptions.FlowEvent, error) {
+	defer close(ts.ready)
+	return ts.FlowSubscriber.FlowEvents(ctx, lastSeq)
+}
+
+func newFlowEventsContext(flowID, rawQuery string, privs []string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+	c.Request, _ = http.NewRequest("GET", "/flows/"+flowID+"/events?"+rawQuery, nil)
+
+	return c, w
+}
+
+// streamFlowEvents runs the flow events handler in background until the publish callback finishes the stream
+func streamFlowEvents(
+	t *testing.T,
+	db *gorm.DB,
+	subs subscriptions.SubscriptionsController,
+	flowID, rawQuery string,
+	privs []string,
+	publish func(subs subscriptions.SubscriptionsController)) string {
+	t.Helper()
+
+	ready := make(chan struct{})
+	service := NewFlowService(db, nil, nil, nil, &flowEventsSubscriptions{SubscriptionsController: subs, ready: ready})
+
+	c, w := newFlowEventsContext(flowID, rawQuery, privs)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.GetFlowEvents(c)
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not created")
+	}
+
+	publish(subs)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flow events stream was not closed after flow deletion")
+	}
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	return w.Body.String()
+}
+
+func TestGetFlowEvents(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	interval := flowEventsHeartbeatInterval
+	flowEventsHeartbeatInterval = 10 * time.Millisecond
+	defer func() { flowEventsHeartbeatInterval = interval }()
+
+	body := streamFlowEvents(t, db, subscriptions.NewSubscriptionsController(), "1", "", []string{"flows.view"}, func(subs subscriptions.SubscriptionsController) {
+		ctx := context.Background()
+		pub := subs.NewFlowPublisher(1, 1)
+		pub.TaskCreated(ctx, database.Task{ID: 7, Title: "scan", FlowID: 1}, nil)
+		pub.TaskUpdated(ctx, database.Task{ID: 7, Title: "scan", FlowID: 1}, []database.Subtask{{ID: 9, TaskID: 7}})
+		// events of other flows of the same user must be skipped
+		subs.NewFlowPublisher(1, 3).FlowUpdated(ctx, database.Flow{ID: 3, Title: "other flow"}, nil)
+		pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "renamed"}, nil)
+
+		time.Sleep(50 * time.Millisecond)
+		pub.FlowDeleted(ctx, database.Flow{ID: 1, Title: "renamed"}, nil)
+	})
+
+	assert.Contains(t, body, "event:task_created\ndata:")
+	assert.Contains(t, body, "event:task_updated\ndata:")
+	assert.Contains(t, body, `"subtasks":[{"id":9`)
+	assert.Contains(t, body, "event:flow_updated\ndata:")
+	assert.Contains(t, body, ": heartbeat\n\n")
+	assert.NotContains(t, body, "other flow")
+	assert.True(t, strings.HasSuffix(body, "\n\n"), body)
+	assert.Equal(t, strings.LastIndex(body, "event:"), strings.Index(body, "event:flow_deleted"), body)
+}
+
+func TestGetFlowEvents_TypesFilter(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	body := streamFlowEvents(t, db, subscriptions.NewSubscriptionsController(), "1", "types=task_updated", []string{"flows.view"}, func(subs subscriptions.SubscriptionsController) {
+		ctx := context.Background()
+		pub := subs.NewFlowPublisher(1, 1)
+		pub.TaskCreated(ctx, database.Task{ID: 7, Title: "scan", FlowID: 1}, nil)
+		pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "renamed"}, nil)
+		pub.TaskUpdated(ctx, database.Task{ID: 7, Title: "scan", FlowID: 1}, nil)
+		pub.FlowDeleted(ctx, database.Flow{ID: 1, Title: "renamed"}, nil)
+	})
+
+	// the stream is still closed on flow deletion but the suppressed event is not sent
+	assert.Equal(t, 1, strings.Count(body, "event:"), body)
+	assert.Contains(t, body, "event:task_updated\ndata:")
+}
+
+func TestGetFlowEvents_Replay(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	subs := subscriptions.NewSubscriptionsController()
+	pub := subs.NewFlowPublisher(1, 1)
+	pub.TaskCreated(ctx, database.Task{ID: 7, Title: "missed 1", FlowID: 1}, nil)
+	pub.MessageLogAdded(ctx, database.Msglog{ID: 3, FlowID: 1})
+	pub.TaskUpdated(ctx, database.Task{ID: 7, Title: "missed 2", FlowID: 1}, nil)
+
+	body := streamFlowEvents(t, db, subs, "1", "last_seq=1", []string{"flows.view"}, func(subs subscriptions.SubscriptionsController) {
+		pub.FlowUpdated(ctx, database.Flow{ID: 1, Title: "live"}, nil)
+		pub.FlowDeleted(ctx, database.Flow{ID: 1, Title: "live"}, nil)
+	})
+
+	// events are replayed after the last sequence number in publishing order with their sequence numbers
+	assert.NotContains(t, body, "missed 1")
+	assert.NotContains(t, body, "message_log_added")
+	replayed, live := strings.Index(body, "id:3\nevent:task_updated\ndata:"), strings.Index(body, "id:4\nevent:flow_updated\ndata:")
+	require.NotEqual(t, -1, replayed, body)
+	require.NotEqual(t, -1, live, body)
+	assert.Less(t, replayed, live)
+	assert.Contains(t, body, "id:5\nevent:flow_deleted\ndata:")
+}
+
+func TestGetFlowEvents_ResyncRequired(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	subs := subscriptions.NewSubscriptionsController()
+	pub := subs.NewFlowPublisher(1, 1)
+	pub.TaskCreated(ctx, database.Task{ID: 7, FlowID: 1}, nil)
+
+	body := streamFlowEvents(t, db, subs, "1", "", []string{"flows.view"}, func(subs subscriptions.SubscriptionsController) {
+		pub.FlowDeleted(ctx, database.Flow{ID: 1}, nil)
+	})
+	// without last_seq only live events are streamed
+	assert.Equal(t, 1, strings.Count(body, "event:"), body)
+
+	// the flow sequence is started again after deletion so older sequence numbers require resync
+	body = streamFlowEvents(t, db, subs, "1", "last_seq=10", []string{"flows.view"}, func(subs subscriptions.SubscriptionsController) {
+		pub.FlowDeleted(ctx, database.Flow{ID: 1}, nil)
+	})
+	assert.True(t, strings.HasPrefix(body, "id:0\nevent:resync_required\n"), body)
+
+	c, w := newFlowEventsContext("1", "", []string{"flows.view"})
+	c.Request.Header.Set("Last-Event-ID", "abc")
+	NewFlowService(db, nil, nil, nil, subs).GetFlowEvents(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetFlowEvents_AdminForeignFlow(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	body := streamFlowEvents(t, db, subscriptions.NewSubscriptionsController(), "2", "", []string{"flows.admin"}, func(subs subscriptions.SubscriptionsController) {
+		pub := subs.NewFlowPublisher(2, 2)
+		pub.FlowUpdated(context.Background(), database.Flow{ID: 2, Title: "foreign"}, nil)
+		pub.FlowDeleted(context.Background(), database.Flow{ID: 2, Title: "foreign"}, nil)
+	})
+
+	assert.Contains(t, body, "event:flow_updated\ndata:")
+	assert.Contains(t, body, "event:flow_deleted\ndata:")
+}
+
+func TestGetFlowEvents_Scoping(t *testing.T) {
+	db := setupFlowMessagesTestDB(t)
+	defer db.Close()
+
+	service := NewFlowService(db, nil, nil, nil, subscriptions.NewSubscriptionsController())
+
+	c, w := newFlowEventsContext("2", "", []string{"flows.view"})
+	service.GetFlowEvents(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = newFlowEventsContext("1", "", []string{"tasks.view"})
+	service.GetFlowEvents(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	c, w = newFlowEventsContext("abc", "", []string{"flows.view"})
+	service.GetFlowEvents(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	c, w = newFlowEventsContext("1", "types=message_log_added", []string{"flows.view"})
+	service.GetFlowEvents(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// syncRecorder guards the recorded body which is read while the events stream is being written
+type syncRecorder struct {
+	*httptest.ResponseRecorder
+	mx sync.Mutex
+}
+
+func (r *syncRecorder) Write(b []byte) (int, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.ResponseRecorder.Write(b)
+}
+
+func (r *syncRecorder) WriteString(str string) (int, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.ResponseRecorder.WriteString(str)
+}
+
+func (r *syncRecorder) String() string {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.Body.String()
+}
+
+// userFlowsEventsSubscriptions signals when the subscription of the user flows events stream is created
+type userFlowsEventsSubscriptions struct {
+	subscriptions.SubscriptionsController
+	ready chan struct{}
+}
+
+func (ts *userFlowsEventsSubscriptions) NewFlowSubscriber(userID, flowID int64, types ...subscriptions.EventType) subscriptions.FlowSubscriber {
+	return &userFlowsEventsSubscriber{
+		FlowSubscriber: ts.SubscriptionsController.NewFlowSubscriber(userID, flowID, types...),
+		ready:          ts.ready,
+	}
+}
+
+type userFlowsEventsSubscriber struct {
+	subscriptions.FlowSubscriber
+	ready chan struct{}
+}
+
+func (ts *userFlowsEventsSubscriber) UserFlowsEvents(ctx context.Context, lastSeq *uint64) (<-chan subscriptions.FlowEvent, error) {
+	defer close(ts.ready)
+	return ts.FlowSubscriber.UserFlowsEvents(ctx, lastSeq)
+}
+
+func (ts *userFlowsEventsSubscriber) UserFlowsEventsAdmin(ctx context.Context, lastSeq *uint64) (<-chan subscriptions.FlowEvent, error) {
+	defer close(ts.ready)
+	return ts.FlowSubscriber.UserFlowsEventsAdmin(ctx, lastSeq)
+}
+
+// streamFlowsEvents runs the user flows events handler until the body contains the expected number of events
+func streamFlowsEvents(
+	t *testing.T,
+	subs subscriptions.SubscriptionsController,
+	rawQuery string,
+	privs []string,
+	count int,
+	publish func(pub func(userID, flowID int64)),
+) string {
+	t.Helper()
+
+	ready := make(chan struct{})
+	service := NewFlowService(nil, nil, nil, nil, &userFlowsEventsSubscriptions{SubscriptionsController: subs, ready: ready})
+
+	gin.SetMode(gin.TestMode)
+	w := &syncRecorder{ResponseRecorder: httptest.NewRecorder()}
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.Request, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/flows/events?"+rawQuery, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.GetFlowsEvents(c)
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not created")
+	}
+
+	publish(func(userID, flowID int64) {
+		flow := database.Flow{ID: flowID, UserID: userID, Title: fmt.Sprintf("flow %d", flowID)}
+		subs.NewFlowPublisher(userID, flowID).FlowCreated(context.Background(), flow, nil)
+	})
+
+	require.Eventually(t, func() bool {
+		return strings.Count(w.String(), "event:flow_created") >= count
+	}, 5*time.Second, 10*time.Millisecond, w.String())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flows events stream was not closed after disconnect")
+	}
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	return w.String()
+}
+
+func TestGetFlowsEvents(t *testing.T) {
+	body := streamFlowsEvents(t, subscriptions.NewSubscriptionsController(), "", []string{"flows.view"}, 2,
+		func(pub func(userID, flowID int64)) {
+			pub(1, 5)
+			pub(2, 6)
+			pub(1, 7)
+		})
+
+	assert.Contains(t, body, "id:1\nevent:flow_created\ndata:")
+	assert.Contains(t, body, `"title":"flow 5"`)
+	assert.Contains(t, body, "id:2\nevent:flow_created\ndata:")
+	assert.Contains(t, body, `"title":"flow 7"`)
+	assert.NotContains(t, body, `"title":"flow 6"`)
+
+	// admin sees the flows of all users
+	body = streamFlowsEvents(t, subscriptions.NewSubscriptionsController(), "", []string{"flows.admin"}, 3,
+		func(pub func(userID, flowID int64)) {
+			pub(1, 5)
+			pub(2, 6)
+			pub(1, 7)
+		})
+
+	assert.Contains(t, body, `"title":"flow 6"`)
+	assert.Contains(t, body, "id:3\nevent:flow_created\ndata:")
+}
+
+func TestGetFlowsEvents_Replay(t *testing.T) {
+	subs := subscriptions.NewSubscriptionsController()
+	subs.NewFlowPublisher(1, 5).FlowCreated(context.Background(), database.Flow{ID: 5, UserID: 1, Title: "missed 5"}, nil)
+	subs.NewFlowPublisher(1, 6).FlowCreated(context.Background(), database.Flow{ID: 6, UserID: 1, Title: "missed 6"}, nil)
+
+	body := streamFlowsEvents(t, subs, "last_seq=1", []string{"flows.view"}, 2, func(pub func(userID, flowID int64)) {
+		pub(1, 7)
+	})
+
+	assert.NotContains(t, body, "missed 5")
+	assert.Less(t, strings.Index(body, "missed 6"), strings.Index(body, `"title":"flow 7"`))
+}
+
+func TestGetFlowsEvents_Errors(t *testing.T) {
+	service := NewFlowService(nil, nil, nil, nil, subscriptions.NewSubscriptionsController())
+
+	c, w := newAuditTestContext(http.MethodGet, "/flows/events", []string{"tasks.view"})
+	service.GetFlowsEvents(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	c, w = newAuditTestContext(http.MethodGet, "/flows/events", []string{"flows.view"})
+	c.Request.Header.Set("Last-Event-ID", "abc")
+	service.GetFlowsEvents(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// createContainersTestTable creates containers table with all columns of the container model
+func createContainersTestTable(db *gorm.DB) {
+	db.Exec(`
+		CREATE TABLE containers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL DEFAULT 'primary',
+			name TEXT NOT NULL,
+			image TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'starting',
+			local_id TEXT NOT NULL DEFAULT '',
+			local_dir TEXT NOT NULL DEFAULT '',
+			flow_id INTEGER NOT NULL,
+			cpu_limit REAL NOT NULL DEFAULT 0,
+			memory_limit INTEGER NOT NULL DEFAULT 0,
+			pids_limit INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+}
+
+func setupRestoreFlowTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := setupAuditLogsTestDB(t)
+	createContainersTestTable(db)
+
+	db.Exec(`UPDATE flows SET status = 'finished', finish_reason = 'user_finish', stop_reason = 'stopped by user',
+		deleted_at = '2026-01-02 00:00:00' WHERE id = 1`)
+	db.Exec(`INSERT INTO containers (name, image, status, local_id, flow_id)
+		VALUES ('pentagi-terminal-1', 'kali', 'deleted', 'local-1', 1)`)
+
+	return db
+}
+
+func doRestoreFlow(t *testing.T, svc *FlowService, flowID, body string, privs []string) (*httptest.ResponseRecorder, models.Flow) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/flows/"+flowID+"/restore", strings.NewReader(body))
+
+	svc.RestoreFlow(c)
+
+	var resp struct {
+		Data models.Flow `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp.Data
+}
+
+func TestRestoreFlow(t *testing.T) {
+	db := setupRestoreFlowTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+
+	// the flow was failed before its deletion finished it
+	c, _ := newAuditTestContext(http.MethodDelete, "/flows/1", nil)
+	deleted := models.AuditChanges{"status": {Before: models.FlowStatusFailed, After: models.FlowStatusFinished}}
+	require.NoError(t, writeAuditLog(c, db, models.AuditActionFlowDelete, 1, deleted))
+
+	w, _ := doRestoreFlow(t, svc, "1", "", []string{"flows.delete", "flows.edit"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doRestoreFlow(t, svc, "2", "", []string{"flows.admin"})
+	assert.Equal(t, http.StatusNotFound, w.Code, "not deleted flow can't be restored")
+
+	w, _ = doRestoreFlow(t, svc, "1", `{"reattach":true}`, []string{"flows.admin"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "reaped containers can't be reattached")
+	assert.True(t, db.Where("id = 1").Take(&models.Flow{}).RecordNotFound())
+
+	w, flow := doRestoreFlow(t, svc, "1", "", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.FlowStatusFailed, flow.Status)
+	assert.Nil(t, flow.DeletedAt)
+
+	var restored models.Flow
+	require.NoError(t, db.Where("id = 1").Take(&restored).Error)
+	assert.Equal(t, models.FlowStatusFailed, restored.Status)
+	assert.Nil(t, restored.FinishReason)
+	assert.Nil(t, restored.StopReason)
+
+	var auditLog models.AuditLog
+	require.NoError(t, db.Where("flow_id = 1 AND action = ?", models.AuditActionFlowRestore).Take(&auditLog).Error)
+	assert.Contains(t, auditLog.Changes, "status")
+	assert.Contains(t, auditLog.Changes, "deleted_at")
+
+	w, _ = doRestoreFlow(t, svc, "1", "", []string{"flows.admin"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// purgeTestQuerier serves the queries of the flows purge from the test database
+type purgeTestQuerier struct {
+	database.Querier
+	db          *gorm.DB
+	attachments []database.FlowAttachment
+	artifacts   []database.FlowArtifact
+}
+
+func (q *purgeTestQuerier) GetDeletedFlowsToPurge(ctx context.Context, retentionSeconds int64) ([]database.Flow, error) {
+	var flows []models.Flow
+	if err := q.db.Unscoped().Where("deleted_at IS NOT NULL").Find(&flows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]database.Flow, 0, len(flows))
+	for _, flow := range flows {
+		result = append(result, database.Flow{ID: int64(flow.ID), UserID: int64(flow.UserID)})
+	}
+
+	return result, nil
+}
+
+func (q *purgeTestQuerier) GetFlowContainersUnscoped(ctx context.Context, flowID int64) ([]database.Container, error) {
+	var containers []models.Container
+	if err := q.db.Where("flow_id = ?", flowID).Find(&containers).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]database.Container, 0, len(containers))
+	for _, container := range containers {
+		result = append(result, convertContainerToDatabase(container))
+	}
+
+	return result, nil
+}
+
+func (q *purgeTestQuerier) GetFlowContainerSnapshots(ctx context.Context, flowID int64) ([]database.ContainerSnapshot, error) {
+	return nil, nil
+}
+
+func (q *purgeTestQuerier) GetFlowAttachments(ctx context.Context, flowID int64) ([]database.FlowAttachment, error) {
+	return q.attachments, nil
+}
+
+func (q *purgeTestQuerier) DeleteFlowAttachments(ctx context.Context, flowID int64) error {
+	q.attachments = nil
+	return nil
+}
+
+func (q *purgeTestQuerier) GetFlowArtifacts(ctx context.Context, flowID int64) ([]database.FlowArtifact, error) {
+	return q.artifacts, nil
+}
+
+func (q *purgeTestQuerier) DeleteFlowArtifacts(ctx context.Context, flowID int64) error {
+	q.artifacts = nil
+	return nil
+}
+
+func (q *purgeTestQuerier) CreateAuditLog(ctx context.Context, arg database.CreateAuditLogParams) (database.AuditLog, error) {
+	auditLog := models.AuditLog{UserID: uint64(arg.UserID), Action: models.AuditAction(arg.Action), FlowID: uint64(arg.FlowID)}
+	if err := json.Unmarshal(arg.Changes, &auditLog.Changes); err != nil {
+		return database.AuditLog{}, err
+	}
+
+	return database.AuditLog{}, q.db.Create(&auditLog).Error
+}
+
+// purgeTestDocker removes the containers only in the test database
+type purgeTestDocker struct {
+	docker.DockerClient
+	db      *gorm.DB
+	removed []string
+}
+
+func (d *purgeTestDocker) DeleteContainer(ctx context.Context, containerID string, dbID int64) error {
+	d.removed = append(d.removed, containerID)
+	return d.db.Exec("UPDATE containers SET status = 'deleted' WHERE id = ?", dbID).Error
+}
+
+func TestDeleteAndRestoreFlow(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	// the purge goroutine must share the same in-memory database
+	db.DB().SetMaxOpenConns(1)
+	createContainersTestTable(db)
+
+	db.Exec("UPDATE flows SET status = 'failed' WHERE id = 1")
+	db.Exec(`INSERT INTO containers (id, name, image, status, local_id, flow_id)
+		VALUES (1, 'pentagi-terminal-1', 'kali', 'running', 'local-1', 1)`)
+
+	cfg := &config.Config{DataDir: t.TempDir(), FlowsDeleteRetention: 0}
+	querier := &purgeTestQuerier{
+		db:          db,
+		attachments: []database.FlowAttachment{{ID: 1, Name: "scope.txt", FlowID: 1}},
+		artifacts:   []database.FlowArtifact{{ID: 1, Name: "report.md", FlowID: 1}, {ID: 2, Name: "hosts.txt", FlowID: 1}},
+	}
+	dockerClient := &purgeTestDocker{db: db}
+	fc := controller.NewFlowController(querier, cfg, dockerClient, nil, nil)
+	svc := &FlowService{db: db, fc: fc, cfg: cfg}
+
+	deleteFlow := func() {
+		c, w := newAuditTestContext(http.MethodDelete, "/flows/1", []string{"flows.delete"})
+		c.Params = gin.Params{{Key: "flowID", Value: "1"}}
+		svc.DeleteFlow(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	restoreFlow := func(body string) (*httptest.ResponseRecorder, models.RestoredFlow) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", []string{"flows.admin"})
+		c.Params = gin.Params{{Key: "flowID", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/flows/1/restore", strings.NewReader(body))
+		svc.RestoreFlow(c)
+
+		var resp struct {
+			Data models.RestoredFlow `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp.Data
+	}
+
+	// the deleted flow keeps its resources, so its running container is reattached
+	deleteFlow()
+	assert.Empty(t, dockerClient.removed)
+	assert.Len(t, querier.attachments, 1)
+	assert.Len(t, querier.artifacts, 2)
+
+	w, restored := restoreFlow(`{"reattach":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.FlowStatusFailed, restored.Status)
+	assert.Nil(t, restored.Purged)
+
+	// the purge removes the resources after the retention period and the restore reports them
+	deleteFlow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fc.RunFlowsPurge(ctx, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return !db.Where("flow_id = 1 AND action = ?", models.AuditActionFlowPurge).Take(&models.AuditLog{}).RecordNotFound()
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	assert.Equal(t, []string{"local-1"}, dockerClient.removed)
+	assert.Empty(t, querier.attachments)
+	assert.Empty(t, querier.artifacts)
+
+	w, _ = restoreFlow(`{"reattach":true}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "purged containers can't be reattached")
+
+	w, restored = restoreFlow("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, restored.Purged)
+	assert.Equal(t, models.FlowPurged{Containers: 1, Attachments: 1, Artifacts: 2}, *restored.Purged)
+}
+
+func TestGetFlows_IncludeDeleted(t *testing.T) {
+	db := setupRestoreFlowTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+
+	doGetFlows := func(rawQuery string, privs []string) (*httptest.ResponseRecorder, flows) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", privs)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/flows/?page=1&pageSize=-1&type=init"+rawQuery, nil)
+
+		svc.GetFlows(c)
+
+		var resp struct {
+			Data flows `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp.Data
+	}
+
+	w, resp := doGetFlows("", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(1), resp.Total)
+	require.Len(t, resp.Flows, 1)
+
+	w, resp = doGetFlows("&include_deleted=true", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, uint64(2), resp.Total)
+	for _, flow := range resp.Flows {
+		assert.Equal(t, flow.ID == 1, flow.DeletedAt != nil)
+	}
+
+	w, _ = doGetFlows("&include_deleted=true", []string{"flows.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doGetFlows("&include_deleted=maybe", []string{"flows.admin"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetFlows_CreatedRange(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	// timestamps are stored in the same form as the driver binds the bounds
+	db.Exec(`INSERT INTO flows (id, title, status, user_id, created_at) VALUES
+		(10, 'january', 'finished', 1, '2026-01-01 22:00:00+00:00'),
+		(11, 'boundary', 'running', 1, '2026-01-02 00:00:00+00:00'),
+		(12, 'later', 'finished', 1, '2026-01-02 12:00:00+00:00'),
+		(13, 'next day', 'finished', 1, '2026-01-03 00:00:00+00:00')`)
+	db.Exec("DELETE FROM flows WHERE id < 10")
+	svc := &FlowService{db: db}
+
+	doGetFlows := func(rawQuery string) (*httptest.ResponseRecorder, []string) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", []string{"flows.admin"})
+		c.Request, _ = http.NewRequest(http.MethodGet, "/flows/?page=1&pageSize=-1&type=init&"+rawQuery, nil)
+
+		svc.GetFlows(c)
+
+		var resp struct {
+			Data flows `json:"data"`
+		}
+		var titles []string
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			for _, flow := range resp.Data.Flows {
+				titles = append(titles, flow.Title)
+			}
+		}
+		return w, titles
+	}
+
+	w, titles := doGetFlows("created_after=2026-01-02T00:00:00Z&created_before=2026-01-03T00:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"boundary", "later"}, titles, "lower bound is inclusive, upper is exclusive")
+
+	// the same day in UTC+02:00 starts two hours earlier
+	w, titles = doGetFlows(url.Values{
+		"created_after":  {"2026-01-02T00:00:00+02:00"},
+		"created_before": {"2026-01-03T00:00:00+02:00"},
+	}.Encode())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"january", "boundary", "later"}, titles)
+
+	// the range composes with the table query filters, text ones need postgres so numeric one is used
+	filter := url.QueryEscape(`{"field":"id","value":11,"operator":"!="}`)
+	w, titles = doGetFlows("created_after=2026-01-02T00:00:00Z&filters[]=" + filter)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"later", "next day"}, titles)
+
+	invalid := map[string]string{
+		"no timezone":   "created_after=2026-01-02T00:00:00",
+		"date only":     "created_before=2026-01-02",
+		"empty range":   "created_after=2026-01-02T00:00:00Z&created_before=2026-01-02T02:00:00%2B02:00",
+		"reverse range": "created_after=2026-01-03T00:00:00Z&created_before=2026-01-02T00:00:00Z",
+	}
+	for name, rawQuery := range invalid {
+		w, _ := doGetFlows(rawQuery)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		assert.Contains(t, w.Body.String(), "flows.invalid_request", name)
+	}
+}
+
+func TestGetFlows_CSV(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	db.Exec(`
+		CREATE TABLE flow_acls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			access TEXT NOT NULL DEFAULT 'view'
+		)
+	`)
+	db.Exec(`INSERT INTO flows (id, title, user_id, created_at, updated_at) VALUES
+		(3, 'Scan "dmz", internal', 1, '2026-01-02 10:00:00', '2026-01-02 11:00:00'),
+		(4, '=HYPERLINK("http://evil")', 2, '2026-01-03 10:00:00', '2026-01-03 11:00:00')`)
+	svc := &FlowService{db: db}
+
+	doGetFlowsCSV := func(rawQuery, accept string, privs []string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", privs)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/flows/?page=1&pageSize=-1&type=init"+rawQuery, nil)
+		if accept != "" {
+			c.Request.Header.Set("Accept", accept)
+		}
+
+		svc.GetFlows(c)
+		return w
+	}
+
+	readCSV := func(w *httptest.ResponseRecorder) [][]string {
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	w := doGetFlowsCSV("&format=csv&sort[]={\"prop\":\"id\",\"order\":\"descending\"}", "", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="flows.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Body.String(), `"Scan ""dmz"", internal"`)
+
+	// flows of other users are not exported
+	records := readCSV(w)
+	require.Len(t, records, 3)
+	assert.Equal(t, flowsCSVHeader, records[0])
+	assert.Equal(t, []string{
+		"3", "running", `Scan "dmz", internal`, "gpt-4.1", "openai", "English",
+		"2026-01-02T10:00:00Z", "2026-01-02T11:00:00Z",
+	}, records[1])
+	assert.Equal(t, "1", records[2][0])
+
+	// grouping is ignored, filters are applied
+	w = doGetFlowsCSV("&group=status&filters[]={\"field\":\"id\",\"value\":3,\"operator\":\"=\"}", "text/csv", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	records = readCSV(w)
+	require.Len(t, records, 2)
+	assert.Equal(t, "3", records[1][0])
+
+	// formula-like cells are escaped
+	w = doGetFlowsCSV("&format=csv&filters[]={\"field\":\"id\",\"value\":4,\"operator\":\"=\"}", "", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	records = readCSV(w)
+	require.Len(t, records, 2)
+	assert.Equal(t, `'=HYPERLINK("http://evil")`, records[1][2])
+
+	w = doGetFlowsCSV("", "application/json, text/csv;q=0.5", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	w = doGetFlowsCSV("&format=xml", "", []string{"flows.view"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetFlowReport(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	db.Exec(`
+		CREATE TABLE flow_acls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			access TEXT NOT NULL DEFAULT 'view'
+		)
+	`)
+	svc := &FlowService{db: db}
+
+	doGetFlowReport := func(flowID, rawQuery string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", []string{"flows.view"})
+		c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/flows/"+flowID+"/report?"+rawQuery, nil)
+
+		svc.GetFlowReport(c)
+		return w
+	}
+
+	w := doGetFlowReport("1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="flow-1-report.md"`, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Body.String(), "# Penetration Test Report: Scan perimeter")
+	assert.Contains(t, w.Body.String(), "No tasks are available for this flow.")
+
+	w = doGetFlowReport("1", "format=html")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<title>Penetration Test Report: Scan perimeter</title>")
+
+	w = doGetFlowReport("1", "format=pdf")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doGetFlowReport("2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetFlowReportSARIF(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	db.Exec(`
+		CREATE TABLE flow_acls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			access TEXT NOT NULL DEFAULT 'view'
+		)
+	`)
+	svc := &FlowService{db: db}
+
+	doGetFlowReportSARIF := func(flowID string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", []string{"flows.view"})
+		c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/flows/"+flowID+"/report.sarif", nil)
+
+		svc.GetFlowReportSARIF(c)
+		return w
+	}
+
+	w := doGetFlowReportSARIF("1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/sarif+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="flow-1-report.sarif"`, w.Header().Get("Content-Disposition"))
+
+	var log report.SARIFLog
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	assert.Empty(t, log.Runs[0].Results)
+
+	w = doGetFlowReportSARIF("2")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doGetFlowReportSARIF("flow")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetFlowFunctions(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+
+	// the schema keeps zero values and unknown keywords which must be sent back as is
+	functions := `{"token":"secret","functions":[{"name":"lookup_host","url":"https://example.com/lookup",` +
+		`"context":["agent"],"schema":{"type":"object","properties":{"port":{"type":"integer","minimum":0,` +
+		`"x-order":1}},"required":["port"],"additionalProperties":false}}]}`
+	db.Exec("ALTER TABLE flows ADD COLUMN functions TEXT NOT NULL DEFAULT '{}'")
+	db.Exec("UPDATE flows SET functions = ? WHERE id = 1", functions)
+
+	doGetFlowFunctions := func(flowID string, privs []string) *httptest.ResponseRecorder {
+		c, w := newAuditTestContext(http.MethodGet, "/flows/"+flowID+"/functions", privs)
+		c.Params = gin.Params{{Key: "flowID", Value: flowID}}
+		svc.GetFlowFunctions(c)
+		return w
+	}
+
+	w := doGetFlowFunctions("1", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.JSONEq(t, functions, string(resp.Data))
+
+	w = doGetFlowFunctions("2", []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code, "foreign flow must be hidden")
+
+	w = doGetFlowFunctions("2", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.JSONEq(t, `{}`, string(resp.Data))
+
+	w = doGetFlowFunctions("1", []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateFlow_FunctionsFieldErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	body := `{"input":"scan the host","provider":"openai","functions":{"functions":[` +
+		`{"name":"bad name","url":"https://example.com/fn","schema":{"type":"object","description":"bad name"}},` +
+		`{"name":"plain_value","url":"not-url","schema":{"type":"string"}},` +
+		`{"name":"lookup","url":"https://example.com/lookup","schema":{"type":"object","description":"lookup",` +
+		`"properties":{"host":{"type":"string"}},"required":["host","port"]}},` +
+		`{"name":"lookup","url":"https://example.com/lookup","schema":{"type":"object","description":"lookup"}}` +
+		`],"disabled":[{"name":"terminal"},{"name":"terminal","context":["hacker"]}]}}`
+	c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+	c.Request.Body = io.NopCloser(strings.NewReader(body))
+	svc.CreateFlow(c)
+	require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+
+	var resp struct {
+		Code    string              `json:"code"`
+		Details []models.FieldError `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Flows.InvalidData", resp.Code)
+	assert.ElementsMatch(t, []models.FieldError{
+		{Field: "Functions.Function[0].Name", Rule: "fname",
+			Message: "Functions.Function[0].Name: must be a valid function name"},
+		{Field: "Functions.Function[1].URL", Rule: "url",
+			Message: "Functions.Function[1].URL: must be a valid URL"},
+		{Field: "Functions.Function[1].Schema.Description", Rule: "required",
+			Message: "Functions.Function[1].Schema.Description: is required"},
+		{Field: "Functions.Function[1].Schema.Type", Rule: "eq", Param: "object",
+			Message: "Functions.Function[1].Schema.Type: must be equal to object"},
+		{Field: "Functions.Function[2].Schema.Required", Rule: "property", Param: "port",
+			Message: "Functions.Function[2].Schema.Required: required property 'port' is not defined"},
+		{Field: "Functions.Function[3].Name", Rule: "unique",
+			Message: "Functions.Function[3].Name: must be unique"},
+		{Field: "Functions.Disabled[1].Context[0]", Rule: "oneof", Param: "agent adviser coder searcher generator memorist enricher reporter assistant",
+			Message: "Functions.Disabled[1].Context[0]: must be one of: agent adviser coder searcher generator memorist enricher reporter assistant"},
+		{Field: "Functions.Disabled[1].Name", Rule: "unique",
+			Message: "Functions.Disabled[1].Name: must be unique"},
+	}, resp.Details)
+}
+
+func TestCreateFlow_BindErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	tests := []struct {
+		name     string
+		body     string
+		limit    int64
+		wantCode int
+		wantErr  string
+		wantMsg  string
+	}{
+		{"malformed json", `{"input":"scan",}`, 0, http.StatusBadRequest, "flows.invalid_request", "malformed JSON at offset 17"},
+		{"wrong type", `{"input":"scan","budget":{"max_cost":"ten"}}`, 0, http.StatusBadRequest, "flows.invalid_request",
+			"budget.max_cost: expected value of type float64"},
+		{"empty body", ``, 0, http.StatusBadRequest, "flows.invalid_request", "request body is empty"},
+		{"too large", `{"input":"` + strings.Repeat("a", 128) + `"}`, 64, http.StatusRequestEntityTooLarge, "payload_too_large", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(tt.body))
+			if tt.limit != 0 {
+				c.Request.Body = http.MaxBytesReader(w, c.Request.Body, tt.limit)
+			}
+			svc.CreateFlow(c)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+
+			var resp struct {
+				ErrorCode string              `json:"error_code"`
+				Details   []models.FieldError `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantErr, resp.ErrorCode)
+			if tt.wantMsg != "" {
+				require.Len(t, resp.Details, 1)
+				assert.Contains(t, resp.Details[0].Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCreateFlow_ValidFunctions(t *testing.T) {
+	createFlow := models.CreateFlow{Input: "scan the host", Provider: "openai"}
+	require.NoError(t, json.Unmarshal([]byte(`{"functions":[{"name":"lookup_host","url":"https://example.com/lookup",`+
+		`"schema":{"type":"object","description":"lookup host","properties":{"port":{"type":"integer","minimum":0}},`+
+		`"required":["port"]}}],"disabled":[{"name":"terminal","context":["agent"]}]}`), &createFlow.Functions))
+
+	assert.NoError(t, createFlow.Valid())
+}
+
+func TestCreateFlow_EnvFieldErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	body := `{"input":"scan the host","provider":"openai",` +
+		`"env":{"TARGET":"10.0.0.1","1BAD":"value"},"secrets":{"TARGET":"secret","API_TOKEN":"token"}}`
+	c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+	c.Request.Body = io.NopCloser(strings.NewReader(body))
+	svc.CreateFlow(c)
+	require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "secret\"")
+
+	var resp struct {
+		Code    string              `json:"code"`
+		Details []models.FieldError `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Flows.InvalidData", resp.Code)
+	assert.ElementsMatch(t, []models.FieldError{
+		{Field: "Env[1BAD]", Rule: "envname",
+			Message: "Env[1BAD]: must be a valid environment variable name"},
+		{Field: "Secrets[TARGET]", Rule: "unique", Param: "env",
+			Message: "Secrets[TARGET]: must be unique, the value is already used in env"},
+	}, resp.Details)
+}
+
+func TestCreateFlow_SystemPromptErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	tests := []struct {
+		name     string
+		prompt   string
+		wantCode int
+	}{
+		{"empty", `""`, http.StatusInternalServerError},
+		{"broken template", `"You are {{.Lang"`, http.StatusBadRequest},
+		{"unknown variable", `"You are {{.Unknown}}"`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"input":"scan the host","provider":"openai","system_prompt":` + tt.prompt + `}`
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(body))
+			svc.CreateFlow(c)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+// containersLockController is the flow controller which only serializes the flow containers changes
+type containersLockController struct {
+	controller.FlowController
+	mx sync.Mutex
+}
+
+func (fc *containersLockController) ArchiveFlow(ctx context.Context, flowID int64) error {
+	return nil
+}
+
+func (fc *containersLockController) LockFlowContainers(flowID int64) func() {
+	fc.mx.Lock()
+	return fc.mx.Unlock
+}
+
+func TestCreateFlow_PlanLimitsErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	for name, limits := range map[string]string{
+		"zero subtasks":  `{"max_subtasks":0}`,
+		"negative depth": `{"max_depth":-1}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := `{"input":"scan the host","provider":"openai","plan_limits":` + limits + `}`
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(body))
+			svc.CreateFlow(c)
+			assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "flows.invalid_data")
+		})
+	}
+}
+
+func TestCreateFlow_ResultLimitsErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	for name, limits := range map[string]string{
+		"too small size":   `{"max_size":1024}`,
+		"negative source":  `{"max_source_size":-1}`,
+		"too large size":   `{"max_size":8388608}`,
+		"too small source": `{"max_size":8192,"max_source_size":512}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := `{"input":"scan the host","provider":"openai","result_limits":` + limits + `}`
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(body))
+			svc.CreateFlow(c)
+			assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "flows.invalid_data")
+		})
+	}
+}
+
+func TestCreateFlow_CommandPolicyErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	for name, policy := range map[string]string{
+		"invalid regex": `{"deny":["/[a-/"]}`,
+		"empty rule":    `{"allow":["nmap"," "]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := `{"input":"scan the host","provider":"openai","command_policy":` + policy + `}`
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(body))
+			svc.CreateFlow(c)
+			assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "flows.invalid_data")
+		})
+	}
+}
+
+func TestCreateFlow_ScopeErrors(t *testing.T) {
+	svc := &FlowService{}
+
+	for name, scope := range map[string]string{
+		"invalid cidr":     `["10.0.0.0/33"]`,
+		"url":              `["https://example.com"]`,
+		"inner wildcard":   `["a.*.example.com"]`,
+		"short ip address": `["127.1"]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := `{"input":"scan the host","provider":"openai","scope":` + scope + `}`
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(body))
+			svc.CreateFlow(c)
+			assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "flows.invalid_data")
+		})
+	}
+}
+
+func TestCreateFlow_SecretsKeyNotSet(t *testing.T) {
+	svc := &FlowService{cfg: &config.Config{}}
+
+	body := `{"input":"scan the host","provider":"openai","secrets":{"API_TOKEN":"token"}}`
+	c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+	c.Request.Body = io.NopCloser(strings.NewReader(body))
+	svc.CreateFlow(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "flows.secrets_key_not_set")
+}
+
+func TestDeleteFlow_ContainersSnapshotRace(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		db := setupAuditLogsTestDB(t)
+		// all goroutines must share the same in-memory database
+		db.DB().SetMaxOpenConns(1)
+		createContainersTestTable(db)
+		db.Exec(`INSERT INTO containers (id, name, image, status, local_id, local_dir, flow_id)
+			VALUES (1, 'pentagi-terminal-1', 'kali', 'running', 'abc', '/tmp', 1)`)
+
+		fc := &containersLockController{}
+		subs := subscriptions.NewSubscriptionsController()
+		svc := &FlowService{db: db, fc: fc, ss: subs}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		deleted, err := subs.NewFlowSubscriber(1, 1).FlowDeleted(ctx)
+		require.NoError(t, err)
+
+		// the controller flips the container status until it sees the flow deleted
+		last := database.ContainerStatusRunning
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; ; i++ {
+				unlock := fc.LockFlowContainers(1)
+				if db.Where("id = 1").Take(&models.Flow{}).RecordNotFound() {
+					unlock()
+					return
+				}
+				status := database.ContainerStatusRunning
+				if i%2 == 0 {
+					status = database.ContainerStatusStopped
+				}
+				db.Exec("UPDATE containers SET status = ? WHERE flow_id = 1", status)
+				last = status
+				unlock()
+			}
+		}()
+
+		c, w := newAuditTestContext(http.MethodDelete, "/flows/1", []string{"flows.delete"})
+		c.Params = gin.Params{{Key: "flowID", Value: "1"}}
+		svc.DeleteFlow(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("container updates were not stopped after flow deletion")
+		}
+
+		select {
+		case flow := <-deleted:
+			require.Len(t, flow.Terminals, 1)
+			assert.Equal(t, last == database.ContainerStatusRunning, flow.Terminals[0].Connected,
+				"round %d: deleted flow is published with stale container status", round)
+		case <-time.After(5 * time.Second):
+			t.Fatal("flow deletion was not published")
+		}
+
+		cancel()
+		db.Close()
+	}
+}
+
+func TestSetFlowSecrets_Validation(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	svc := &FlowService{db: db, cfg: &config.Config{FlowSecretsKey: "test-flow-secrets-key"}}
+	noKeySvc := &FlowService{db: db, cfg: &config.Config{}}
+
+	tests := []struct {
+		name     string
+		svc      *FlowService
+		privs    []string
+		body     string
+		wantCode int
+	}{
+		{"not permitted", svc, []string{"flows.view"}, `{"secrets":{"API_TOKEN":"token"}}`, http.StatusForbidden},
+		{"invalid json", svc, []string{"flows.edit"}, `{"secrets":`, http.StatusBadRequest},
+		{"invalid secret name", svc, []string{"flows.edit"}, `{"secrets":{"API-TOKEN":"token"}}`, http.StatusInternalServerError},
+		{"flow not found", svc, []string{"flows.admin"}, `{"secrets":{"API_TOKEN":"token"}}`, http.StatusNotFound},
+		{"secrets key not set", noKeySvc, []string{"flows.admin"}, `{"secrets":{"API_TOKEN":"token"}}`, http.StatusBadRequest},
+		{"clearing without key", noKeySvc, []string{"flows.admin"}, `{"secrets":{}}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := tt.svc
+			c, w := newAuditTestContext(http.MethodPut, "/flows/100/secrets", tt.privs)
+			c.Params = gin.Params{{Key: "flowID", Value: "100"}}
+			c.Request.Body = io.NopCloser(strings.NewReader(tt.body))
+			svc.SetFlowSecrets(c)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.NotContains(t, w.Body.String(), "token\"")
+		})
+	}
+}
+
+func setupFlowGraphTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := setupAuditLogsTestDB(t)
+
+	db.Exec(`
+		CREATE TABLE flow_acls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			flow_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			access TEXT NOT NULL DEFAULT 'view'
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'created',
+			title TEXT NOT NULL DEFAULT 'untitled',
+			input TEXT NOT NULL,
+			result TEXT NOT NULL DEFAULT '',
+			flow_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE subtasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'created',
+			title TEXT NOT NULL,
+			description TEXT NOT NULL,
+			context TEXT NOT NULL DEFAULT '',
+			result TEXT NOT NULL DEFAULT '',
+			task_id INTEGER NOT NULL,
+			priority INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec("UPDATE flows SET updated_at = '2026-01-01 09:00:00'")
+	db.Exec(`INSERT INTO tasks (id, status, title, input, flow_id, updated_at) VALUES
+		(1, 'running', 'Scan web server', 'Scan 10.0.0.1 for open ports', 1, '2026-01-01 10:00:00')`)
+	db.Exec(`INSERT INTO subtasks (id, status, title, description, task_id, updated_at) VALUES
+		(1, 'running', 'Run nmap', 'Run nmap against the host', 1, '2026-01-01 10:30:00')`)
+
+	return db
+}
+
+func doConditionalGet(
+	t *testing.T,
+	handler gin.HandlerFunc,
+	target, ifNoneMatch string,
+	privs []string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "flowID", Value: "1"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, target, nil)
+	if ifNoneMatch != "" {
+		c.Request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	handler(c)
+	// the status of the aborted context is written by the engine after handlers
+	c.Writer.WriteHeaderNow()
+
+	return w
+}
+
+func TestGetFlow_ETag(t *testing.T) {
+	db := setupFlowGraphTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+	privs := []string{"flows.view"}
+
+	w := doConditionalGet(t, svc.GetFlow, "/flows/1", "", privs)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	etag := w.Header().Get("ETag")
+	require.True(t, strings.HasPrefix(etag, `W/"`), etag)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+
+	// unchanged flow
+	w = doConditionalGet(t, svc.GetFlow, "/flows/1", etag, privs)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = doConditionalGet(t, svc.GetFlow, "/flows/1", `"other", `+strings.TrimPrefix(etag, "W/"), privs)
+	assert.Equal(t, http.StatusNotModified, w.Code, "weak comparison should match the strong form")
+
+	w = doConditionalGet(t, svc.GetFlow, "/flows/1", "*", privs)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// changed flow
+	db.Exec("UPDATE flows SET status = 'finished', updated_at = '2026-01-01 12:00:00' WHERE id = 1")
+	w = doConditionalGet(t, svc.GetFlow, "/flows/1", etag, privs)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"finished"`)
+
+	// the foreign flow isn't revealed by the entity tag
+	w = doConditionalGet(t, svc.GetFlow, "/flows/1", "*", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestGetFlowGraph_ETag(t *testing.T) {
+	db := setupFlowGraphTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+	privs := []string{"flows.view", "tasks.view", "subtasks.view"}
+
+	w := doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", "", privs)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Run nmap")
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", etag, privs)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// graph entity tag differs from the flow one
+	w = doConditionalGet(t, svc.GetFlow, "/flows/1", etag, []string{"flows.view"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// changed subtask
+	db.Exec("UPDATE subtasks SET status = 'finished', updated_at = '2026-01-01 11:00:00' WHERE id = 1")
+	w = doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", etag, privs)
+	require.Equal(t, http.StatusOK, w.Code)
+	subtaskETag := w.Header().Get("ETag")
+	assert.NotEqual(t, etag, subtaskETag)
+
+	// new task without the flow update
+	db.Exec(`INSERT INTO tasks (id, status, title, input, flow_id, updated_at) VALUES
+		(2, 'created', 'Check FTP', 'Check anonymous FTP login', 1, '2026-01-01 10:00:00')`)
+	w = doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", subtaskETag, privs)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Check FTP")
+	assert.NotEqual(t, subtaskETag, w.Header().Get("ETag"))
+}
+
+func TestGetFlowGraph_ETagPermissions(t *testing.T) {
+	db := setupFlowGraphTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+
+	etags := map[string]string{}
+	for name, privs := range map[string][]string{
+		"flow":     {"flows.view"},
+		"tasks":    {"flows.view", "tasks.view"},
+		"subtasks": {"flows.view", "tasks.view", "subtasks.view"},
+	} {
+		w := doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", "", privs)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		etags[name] = w.Header().Get("ETag")
+
+		// the cached graph of the user with wider visibility isn't reused
+		for other, etag := range etags {
+			if other == name {
+				continue
+			}
+			w = doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", etag, privs)
+			assert.Equal(t, http.StatusOK, w.Code, "%s graph must not match %s entity tag", name, other)
+		}
+	}
+
+	assert.Len(t, etags, 3)
+	assert.NotEqual(t, etags["flow"], etags["tasks"])
+	assert.NotEqual(t, etags["tasks"], etags["subtasks"])
+	assert.NotEqual(t, etags["flow"], etags["subtasks"])
+}
+
+func TestGetFlowsStatus(t *testing.T) {
+	db := setupFlowGraphTestDB(t)
+	defer db.Close()
+
+	db.Exec(`
+		CREATE TABLE containers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'starting',
+			flow_id INTEGER NOT NULL
+		)
+	`)
+	db.Exec(`INSERT INTO flows (id, status, title, user_id, updated_at, deleted_at) VALUES
+		(3, 'finished', 'Shared flow', 2, '2026-01-01 09:00:00', NULL),
+		(4, 'finished', 'Deleted flow', 1, '2026-01-01 09:00:00', '2026-01-02 00:00:00')`)
+	db.Exec("INSERT INTO flow_acls (flow_id, user_id, access) VALUES (3, 1, 'view')")
+	db.Exec(`INSERT INTO containers (status, flow_id) VALUES
+		('running', 1), ('starting', 1), ('stopped', 1), ('running', 2), ('failed', 3)`)
+	svc := &FlowService{db: db}
+
+	doGetFlowsStatus := func(body string, privs []string) (*httptest.ResponseRecorder, map[string]models.FlowStatusInfo) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("uid", uint64(1))
+		c.Set("prm", privs)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/flows/status", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		svc.GetFlowsStatus(c)
+
+		var resp struct {
+			Data map[string]models.FlowStatusInfo `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp.Data
+	}
+
+	// foreign, deleted and missing flows are omitted
+	w, resp := doGetFlowsStatus(`{"ids":[1,2,3,4,100]}`, []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, resp, 2)
+	assert.Equal(t, models.FlowStatus("running"), resp["1"].Status)
+	assert.Equal(t, uint64(2), resp["1"].ActiveContainerCount)
+	assert.Equal(t, 2026, resp["1"].UpdatedAt.Year())
+	assert.Equal(t, models.FlowStatus("finished"), resp["3"].Status)
+	assert.Equal(t, uint64(0), resp["3"].ActiveContainerCount)
+
+	w, resp = doGetFlowsStatus(`{"ids":[1,2,3,4]}`, []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, resp, 3)
+	assert.Equal(t, uint64(1), resp["2"].ActiveContainerCount)
+
+	w, resp = doGetFlowsStatus(`{"ids":[2]}`, []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, resp)
+
+	w, _ = doGetFlowsStatus(`{"ids":[]}`, []string{"flows.view"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = doGetFlowsStatus(`{"ids":[1]}`, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetFlowGraph_Progress(t *testing.T) {
+	db := setupFlowGraphTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+
+	db.Exec(`INSERT INTO tasks (id, status, title, input, flow_id) VALUES
+		(2, 'created', 'Check FTP', 'Check anonymous FTP login', 1)`)
+	db.Exec(`INSERT INTO subtasks (status, title, description, task_id) VALUES
+		('finished', 'Run nikto', 'Run nikto against the host', 1),
+		('failed', 'Run sqlmap', 'Run sqlmap against the login form', 1)`)
+
+	getGraph := func(privs []string) models.FlowTasksSubtasks {
+		w := doConditionalGet(t, svc.GetFlowGraph, "/flows/1/graph", "", privs)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data models.FlowTasksSubtasks `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	graph := getGraph([]string{"flows.view", "tasks.view", "subtasks.view"})
+	require.Len(t, graph.Tasks, 2)
+	require.NotNil(t, graph.Progress)
+	assert.Equal(t, 66.7, *graph.Progress)
+	require.NotNil(t, graph.Tasks[0].Progress)
+	assert.Equal(t, 66.7, *graph.Tasks[0].Progress)
+	assert.Nil(t, graph.Tasks[1].Progress, "task without subtasks has no progress")
+
+	// progress isn't derived from hidden subtasks
+	graph = getGraph([]string{"flows.view", "tasks.view"})
+	require.Len(t, graph.Tasks, 2)
+	assert.Nil(t, graph.Progress)
+	assert.Nil(t, graph.Tasks[0].Progress)
+	assert.Empty(t, graph.Tasks[0].Subtasks)
+
+	db.Exec("DELETE FROM subtasks")
+	graph = getGraph([]string{"flows.view", "tasks.view", "subtasks.view"})
+	assert.Nil(t, graph.Progress, "flow without subtasks has no progress")
+}
+
+func TestGetFlowInputs(t *testing.T) {
+	db := setupFlowACLsTestDB(t)
+	defer db.Close()
+	svc := NewFlowService(db, nil, nil, nil, nil)
+
+	db.Exec(`
+		CREATE TABLE flow_inputs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			input TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'queued',
+			error TEXT,
+			flow_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			processed_at DATETIME
+		)
+	`)
+	db.Exec(`INSERT INTO flow_inputs (input, status, error, flow_id, processed_at) VALUES
+		('scan 10.0.0.1', 'consumed', NULL, 1, CURRENT_TIMESTAMP),
+		('use nmap only', 'failed', 'failed to process input', 1, CURRENT_TIMESTAMP),
+		('check ftp', 'queued', NULL, 1, NULL),
+		('foreign', 'consumed', NULL, 4, CURRENT_TIMESTAMP)`)
+
+	// viewer of the shared flow gets the history in the order of consumption
+	w := doFlowACLRequest(t, svc.GetFlowInputs, http.MethodGet, "1", "", "", 2, []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Data flowInputs `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, uint64(3), resp.Data.Total)
+
+	statuses := make([]models.FlowInputStatus, 0, len(resp.Data.Inputs))
+	for _, input := range resp.Data.Inputs {
+		statuses = append(statuses, input.Status)
+	}
+	assert.Equal(t, []models.FlowInputStatus{
+		models.FlowInputStatusConsumed,
+		models.FlowInputStatusFailed,
+		models.FlowInputStatusQueued,
+	}, statuses)
+	require.NotNil(t, resp.Data.Inputs[1].Error)
+	assert.Equal(t, "failed to process input", *resp.Data.Inputs[1].Error)
+	assert.NotNil(t, resp.Data.Inputs[0].ProcessedAt)
+	assert.Nil(t, resp.Data.Inputs[2].ProcessedAt)
+
+	w = doFlowACLRequest(t, svc.GetFlowInputs, http.MethodGet, "3", "", "", 2, []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doFlowACLRequest(t, svc.GetFlowInputs, http.MethodGet, "1", "", "", 2, []string{"flows.edit"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDetectFlowLanguage(t *testing.T) {
+	svc := &FlowService{cfg: &config.Config{FlowDefaultLanguage: "German"}}
+
+	language, note := svc.detectFlowLanguage("Найди все открытые порты на целевом хосте")
+	assert.Equal(t, "Russian", language)
+	assert.Contains(t, note, "detected with confidence")
+
+	language, note = svc.detectFlowLanguage("nmap -sV 10.0.0.0/24")
+	assert.Equal(t, "German", language)
+	assert.Contains(t, note, "default language is used")
+
+	svc.cfg.FlowDefaultLanguage = "Klingon"
+	language, _ = svc.detectFlowLanguage("10.0.0.1")
+	assert.Equal(t, "English", language)
+
+	for _, value := range []string{"", models.FlowLanguageAuto, "French"} {
+		createFlow := models.CreateFlow{Input: "scan", Provider: "openai", Language: &value}
+		assert.NoError(t, createFlow.Valid(), value)
+	}
+	unsupported := "Klingon"
+	createFlow := models.CreateFlow{Input: "scan", Provider: "openai", Language: &unsupported}
+	assert.Error(t, createFlow.Valid())
+
+	// auto language is accepted only by the new flow
+	auto := models.FlowLanguageAuto
+	patchFlow := models.PatchFlow{Action: "metadata", Language: &auto}
+	assert.Error(t, patchFlow.Valid())
+}
+
+// metadataFlowController returns the flow worker which applies the metadata as the flow metadata query does
+type metadataFlowController struct {
+	controller.FlowController
+	worker *metadataFlowWorker
+}
+
+func (fc *metadataFlowController) GetFlow(ctx context.Context, flowID int64) (controller.FlowWorker, error) {
+	fc.worker.flowID = flowID
+	return fc.worker, nil
+}
+
+type metadataFlowWorker struct {
+	controller.FlowWorker
+	db       *gorm.DB
+	flowID   int64
+	metadata []controller.FlowMetadata
+}
+
+func (fw *metadataFlowWorker) UpdateMetadata(ctx context.Context, metadata controller.FlowMetadata) error {
+	fw.metadata = append(fw.metadata, metadata)
+	return fw.db.Exec(`UPDATE flows SET
+		title = COALESCE(?, title), language = COALESCE(?, language), description = COALESCE(?, description)
+		WHERE id = ?`, metadata.Title, metadata.Language, metadata.Description, fw.flowID).Error
+}
+
+func doPatchFlowMetadata(t *testing.T, svc *FlowService, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	c, w := newAuditTestContext(http.MethodPut, "/flows/1", []string{"flows.admin"})
+	c.Params = gin.Params{{Key: "flowID", Value: "1"}}
+	c.Request.Body = io.NopCloser(strings.NewReader(body))
+	svc.PatchFlow(c)
+
+	return w
+}
+
+func TestPatchFlowMetadata(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+	db.Exec("UPDATE flows SET language = 'French', description = 'external perimeter' WHERE id = 1")
+
+	worker := &metadataFlowWorker{db: db}
+	svc := &FlowService{db: db, fc: &metadataFlowController{worker: worker}}
+
+	w := doPatchFlowMetadata(t, svc, `{"action":"metadata","description":"internal network"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// omitted fields aren't passed to the update and stay untouched
+	require.Len(t, worker.metadata, 1)
+	assert.Nil(t, worker.metadata[0].Title)
+	assert.Nil(t, worker.metadata[0].Language)
+	require.NotNil(t, worker.metadata[0].Description)
+
+	var flow models.Flow
+	require.NoError(t, db.Where("id = 1").Take(&flow).Error)
+	assert.Equal(t, "Scan perimeter", flow.Title)
+	assert.Equal(t, "French", flow.Language)
+	assert.Equal(t, "internal network", flow.Description)
+
+	var resp struct {
+		Data models.Flow `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "internal network", resp.Data.Description)
+	assert.Equal(t, "French", resp.Data.Language)
+
+	// the explicit empty description is set
+	w = doPatchFlowMetadata(t, svc, `{"action":"metadata","title":"Scan DMZ","description":""}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, db.Where("id = 1").Take(&flow).Error)
+	assert.Equal(t, "Scan DMZ", flow.Title)
+	assert.Equal(t, "French", flow.Language)
+	assert.Empty(t, flow.Description)
+}
+
+func TestPatchFlowMetadata_Invalid(t *testing.T) {
+	db := setupAuditLogsTestDB(t)
+	defer db.Close()
+
+	worker := &metadataFlowWorker{db: db}
+	svc := &FlowService{db: db, fc: &metadataFlowController{worker: worker}}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unsupported language", `{"action":"metadata","language":"Klingon"}`},
+		{"auto language", `{"action":"metadata","language":"auto","title":"Scan DMZ"}`},
+		{"no fields", `{"action":"metadata"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doPatchFlowMetadata(t, svc, tt.body)
+			assert.NotEqual(t, http.StatusOK, w.Code, w.Body.String())
+		})
+	}
+
+	// the rejected request doesn't change the flow
+	assert.Empty(t, worker.metadata)
+	var flow models.Flow
+	require.NoError(t, db.Where("id = 1").Take(&flow).Error)
+	assert.Equal(t, "Scan perimeter", flow.Title)
+	assert.Equal(t, "English", flow.Language)
+}
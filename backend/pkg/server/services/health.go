@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"pentagi/pkg/controller"
+	"pentagi/pkg/docker"
+	"pentagi/pkg/providers"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	readinessCheckTimeout     = 5 * time.Second
+	readinessProviderTimeout  = 15 * time.Second
+	readinessProviderCacheTTL = time.Minute
+)
+
+var errReadinessCheckTimeout = errors.New("check timed out")
+
+// readinessCheck returns cached flag when the result was taken without the dependency call
+type readinessCheck struct {
+	name    string
+	timeout time.Duration
+	check   func(ctx context.Context) (bool, error)
+}
+
+type HealthService struct {
+	checks []readinessCheck
+}
+
+func NewHealthService(
+	db *gorm.DB,
+	dockerClient docker.DockerClient,
+	providers providers.ProviderController,
+	fc controller.FlowController,
+) *HealthService {
+	prvCheck := &providersReadiness{providers: providers}
+
+	return &HealthService{
+		checks: []readinessCheck{
+			{
+				name:    "database",
+				timeout: readinessCheckTimeout,
+				check: func(ctx context.Context) (bool, error) {
+					return false, db.DB().PingContext(ctx)
+				},
+			},
+			{
+				name:    "docker",
+				timeout: readinessCheckTimeout,
+				check: func(ctx context.Context) (bool, error) {
+					return false, dockerClient.Ping(ctx)
+				},
+			},
+			{
+				name:    "providers",
+				timeout: readinessProviderTimeout,
+				check:   prvCheck.check,
+			},
+			{
+				name:    "flows",
+				timeout: readinessCheckTimeout,
+				check: func(ctx context.Context) (bool, error) {
+					if fc.IsShuttingDown() {
+						return false, controller.ErrServerShuttingDown
+					}
+					return false, nil
+				},
+			},
+		},
+	}
+}
+
+// GetLiveness is a function to return liveness of the backend process without dependency checks
+// @Summary Liveness probe
+// @Tags Health
+// @Produce json
+// @Success 200 {object} response.successResp{data=models.HealthCheck} "backend is alive"
+// @Router /livez [get]
+func (s *HealthService) GetLiveness(c *gin.Context) {
+	response.Success(c, http.StatusOK, models.HealthCheck{Name: "live", Status: "ok"})
+}
+
+// GetReadiness is a function to check all dependencies required to create flows
+// @Summary Readiness probe
+// @Description Checks database, docker daemon, at least one default provider and that flows are accepted, each check is time-bounded
+// @Tags Health
+// @Produce json
+// @Success 200 {object} response.successResp{data=models.Readiness} "backend is ready"
+// @Failure 503 {object} response.errorResp{details=models.Readiness} "some dependencies are not healthy"
+// @Router /readyz [get]
+func (s *HealthService) GetReadiness(c *gin.Context) {
+	readiness := s.checkReadiness(c)
+	if readiness.Status != "ok" {
+		response.ErrorWithDetails(c, response.ErrNotReady, nil, readiness)
+		return
+	}
+
+	response.Success(c, http.StatusOK, readiness)
+}
+
+func (s *HealthService) checkReadiness(ctx context.Context) models.Readiness {
+	readiness := models.Readiness{
+		Status:    "ok",
+		Checks:    make([]models.HealthCheck, len(s.checks)),
+		CheckedAt: time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for idx, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			readiness.Checks[idx] = runReadinessCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range readiness.Checks {
+		if check.Status != "ok" {
+			readiness.Status = "error"
+		}
+	}
+
+	return readiness
+}
+
+// runReadinessCheck doesn't wait for the check after its timeout so a hung dependency can't hang the probe
+func runReadinessCheck(ctx context.Context, check readinessCheck) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout)
+	defer cancel()
+
+	type checkResult struct {
+		cached bool
+		err    error
+	}
+
+	start := time.Now()
+	resultCh := make(chan checkResult, 1)
+	go func() {
+		cached, err := check.check(ctx)
+		resultCh <- checkResult{cached: cached, err: err}
+	}()
+
+	var result checkResult
+	select {
+	case result = <-resultCh:
+	case <-ctx.Done():
+		result.err = errReadinessCheckTimeout
+	}
+	if errors.Is(result.err, context.DeadlineExceeded) {
+		result.err = errReadinessCheckTimeout
+	}
+
+	health := models.HealthCheck{
+		Name:      check.name,
+		Status:    "ok",
+		LatencyMs: time.Since(start).Milliseconds(),
+		Cached:    result.cached,
+	}
+	if result.err != nil {
+		health.Status = "error"
+		health.Error = providers.SanitizeProviderError(result.err)
+	}
+
+	return health
+}
+
+// providersReadiness probes default providers and caches the success to avoid completion calls on every probe
+type providersReadiness struct {
+	providers providers.ProviderController
+	mx        sync.Mutex
+	okUntil   time.Time
+}
+
+func (pr *providersReadiness) check(ctx context.Context) (bool, error) {
+	pr.mx.Lock()
+	defer pr.mx.Unlock()
+
+	if time.Now().Before(pr.okUntil) {
+		return true, nil
+	}
+
+	prvs := pr.providers.DefaultProviders()
+	if len(prvs) == 0 {
+		return false, errors.New("no providers configured")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	healthCh := make(chan models.ProviderHealth, len(prvs))
+	for prvname, prv := range prvs {
+		go func() {
+			healthCh <- probeProvider(ctx, prvname, prv)
+		}()
+	}
+
+	var lastErr string
+	for range prvs {
+		select {
+		case health := <-healthCh:
+			if health.Status == "ok" {
+				pr.okUntil = time.Now().Add(readinessProviderCacheTTL)
+				return false, nil
+			}
+			lastErr = fmt.Sprintf("%s: %s", health.Name, health.Error)
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	return false, fmt.Errorf("no reachable providers, last error: %s", lastErr)
+}
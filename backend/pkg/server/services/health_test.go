@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticReadinessCheck(name string, err error) readinessCheck {
+	return readinessCheck{
+		name:    name,
+		timeout: time.Second,
+		check: func(ctx context.Context) (bool, error) {
+			return false, err
+		},
+	}
+}
+
+func TestGetLiveness(t *testing.T) {
+	svc := &HealthService{checks: []readinessCheck{staticReadinessCheck("database", errors.New("down"))}}
+
+	c, w := newAuditTestContext(http.MethodGet, "/livez", nil)
+	svc.GetLiveness(c)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestGetReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []readinessCheck
+		wantCode   int
+		wantStatus []string
+	}{
+		{
+			name: "all healthy",
+			checks: []readinessCheck{
+				staticReadinessCheck("database", nil),
+				staticReadinessCheck("docker", nil),
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: []string{"ok", "ok"},
+		},
+		{
+			name: "one unhealthy",
+			checks: []readinessCheck{
+				staticReadinessCheck("database", nil),
+				staticReadinessCheck("docker", errors.New("connection refused")),
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: []string{"ok", "error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &HealthService{checks: tt.checks}
+
+			c, w := newAuditTestContext(http.MethodGet, "/readyz", nil)
+			svc.GetReadiness(c)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+
+			var resp struct {
+				Data    *models.Readiness `json:"data"`
+				Details *models.Readiness `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			readiness := resp.Data
+			if readiness == nil {
+				readiness = resp.Details
+			}
+			require.NotNil(t, readiness)
+			require.Len(t, readiness.Checks, len(tt.wantStatus))
+			for idx, status := range tt.wantStatus {
+				assert.Equal(t, tt.checks[idx].name, readiness.Checks[idx].Name)
+				assert.Equal(t, status, readiness.Checks[idx].Status)
+			}
+		})
+	}
+}
+
+func TestRunReadinessCheckTimeout(t *testing.T) {
+	hung := make(chan struct{})
+	defer close(hung)
+
+	check := readinessCheck{
+		name:    "docker",
+		timeout: 50 * time.Millisecond,
+		check: func(ctx context.Context) (bool, error) {
+			<-hung // ignores context to simulate hung dependency
+			return false, nil
+		},
+	}
+
+	start := time.Now()
+	health := runReadinessCheck(t.Context(), check)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, "error", health.Status)
+	assert.Equal(t, errReadinessCheckTimeout.Error(), health.Error)
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type inputTemplates struct {
+	InputTemplates []models.InputTemplate `json:"input_templates"`
+	Total          uint64                 `json:"total"`
+}
+
+var inputTemplatesSQLMappers = map[string]any{
+	"id":         "{{table}}.id",
+	"name":       "{{table}}.name",
+	"template":   "{{table}}.template",
+	"shared":     "{{table}}.shared",
+	"user_id":    "{{table}}.user_id",
+	"created_at": "{{table}}.created_at",
+	"updated_at": "{{table}}.updated_at",
+	"data":       "({{table}}.name || ' ' || {{table}}.template)",
+}
+
+var errInvalidInputTemplate = errors.New("invalid input template")
+
+type InputTemplateService struct {
+	db *gorm.DB
+}
+
+func NewInputTemplateService(db *gorm.DB) *InputTemplateService {
+	return &InputTemplateService{
+		db: db,
+	}
+}
+
+// inputTemplatesViewScope returns own and shared templates of the user or all templates for flows admin
+func inputTemplatesViewScope(uid uint64, privs []string) func(db *gorm.DB) *gorm.DB {
+	if slices.Contains(privs, "flows.admin") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("input_templates.user_id = ? OR input_templates.shared = ?", uid, true)
+	}
+}
+
+// inputTemplatesEditScope returns own templates of the user or all templates for flows admin
+func inputTemplatesEditScope(uid uint64, privs []string) func(db *gorm.DB) *gorm.DB {
+	if slices.Contains(privs, "flows.admin") {
+		return func(db *gorm.DB) *gorm.DB {
+			return db
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("input_templates.user_id = ?", uid)
+	}
+}
+
+// GetInputTemplates is a function to return input templates list
+// @Summary Retrieve input templates list
+// @Tags InputTemplates
+// @Produce json
+// @Security BearerAuth
+// @Param request query rdb.TableQuery true "query table params"
+// @Success 200 {object} response.successResp{data=inputTemplates} "input templates list received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting input templates not permitted"
+// @Failure 500 {object} response.errorResp "internal error on getting input templates"
+// @Router /input-templates/ [get]
+func (s *InputTemplateService) GetInputTemplates(c *gin.Context) {
+	var (
+		err   error
+		query rdb.TableQuery
+		resp  inputTemplates
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	query.Init("input_templates", inputTemplatesSQLMappers)
+
+	if resp.Total, err = query.Query(s.db, &resp.InputTemplates, inputTemplatesViewScope(uid, privs)); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding input templates")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := 0; i < len(resp.InputTemplates); i++ {
+		if err = resp.InputTemplates[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating input template data '%d'", resp.InputTemplates[i].ID)
+			response.Error(c, response.ErrInputTemplatesInvalidData, err)
+			return
+		}
+		resp.InputTemplates[i].Variables = resp.InputTemplates[i].Placeholders()
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// GetInputTemplate is a function to return input template by id
+// @Summary Retrieve input template by id
+// @Tags InputTemplates
+// @Produce json
+// @Security BearerAuth
+// @Param templateID path int true "input template id" minimum(0)
+// @Success 200 {object} response.successResp{data=models.InputTemplate} "input template received successful"
+// @Failure 400 {object} response.errorResp "invalid input template request data"
+// @Failure 403 {object} response.errorResp "getting input template not permitted"
+// @Failure 404 {object} response.errorResp "input template not found"
+// @Failure 500 {object} response.errorResp "internal error on getting input template"
+// @Router /input-templates/{templateID} [get]
+func (s *InputTemplateService) GetInputTemplate(c *gin.Context) {
+	var (
+		err        error
+		templateID uint64
+	)
+
+	if templateID, err = strconv.ParseUint(c.Param("templateID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing input template id")
+		response.Error(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	resp, err := getInputTemplate(s.db, templateID, uid, privs)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting input template by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrInputTemplatesNotFound, err)
+		} else if errors.Is(err, errInvalidInputTemplate) {
+			response.Error(c, response.ErrInputTemplatesInvalidData, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// CreateInputTemplate is a function to save new input template
+// @Summary Create new input template
+// @Tags InputTemplates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param json body models.SaveInputTemplate true "input template to create"
+// @Success 201 {object} response.successResp{data=models.InputTemplate} "input template created successful"
+// @Failure 400 {object} response.errorResp "invalid input template request data"
+// @Failure 403 {object} response.errorResp "creating input template not permitted"
+// @Failure 500 {object} response.errorResp "internal error on creating input template"
+// @Router /input-templates/ [post]
+func (s *InputTemplateService) CreateInputTemplate(c *gin.Context) {
+	var (
+		err      error
+		template models.SaveInputTemplate
+	)
+
+	if err = c.ShouldBindJSON(&template); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+	if template.Shared && !slices.Contains(privs, "flows.admin") {
+		logger.FromContext(c).Errorf("error sharing input template: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = template.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating input template data")
+		response.Error(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	resp := models.InputTemplate{
+		Name:     template.Name,
+		Template: template.Template,
+		Shared:   template.Shared,
+		UserID:   uid,
+	}
+	if err = s.db.Create(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating input template")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	resp.Variables = resp.Placeholders()
+
+	response.Success(c, http.StatusCreated, resp)
+}
+
+// PatchInputTemplate is a function to replace input template
+// @Summary Update input template
+// @Tags InputTemplates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param templateID path int true "input template id" minimum(0)
+// @Param json body models.SaveInputTemplate true "input template to update"
+// @Success 200 {object} response.successResp{data=models.InputTemplate} "input template updated successful"
+// @Failure 400 {object} response.errorResp "invalid input template request data"
+// @Failure 403 {object} response.errorResp "updating input template not permitted"
+// @Failure 404 {object} response.errorResp "input template not found"
+// @Failure 500 {object} response.errorResp "internal error on updating input template"
+// @Router /input-templates/{templateID} [put]
+func (s *InputTemplateService) PatchInputTemplate(c *gin.Context) {
+	var (
+		err        error
+		templateID uint64
+		template   models.SaveInputTemplate
+		resp       models.InputTemplate
+	)
+
+	if templateID, err = strconv.ParseUint(c.Param("templateID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing input template id")
+		response.Error(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindJSON(&template); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = template.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating input template data")
+		response.Error(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	scope := inputTemplatesEditScope(uid, privs)
+	err = s.db.Model(&resp).Scopes(scope).Where("input_templates.id = ?", templateID).Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting input template by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrInputTemplatesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	// only flows admin can share the template, the owner is allowed to keep or revoke sharing
+	if template.Shared && !resp.Shared && !slices.Contains(privs, "flows.admin") {
+		logger.FromContext(c).Errorf("error sharing input template: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	resp.Name = template.Name
+	resp.Template = template.Template
+	resp.Shared = template.Shared
+	if err = s.db.Save(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error updating input template by id '%d'", templateID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	resp.Variables = resp.Placeholders()
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// DeleteInputTemplate is a function to delete input template, flows created from it are left untouched
+// @Summary Delete input template
+// @Tags InputTemplates
+// @Produce json
+// @Security BearerAuth
+// @Param templateID path int true "input template id" minimum(0)
+// @Success 200 {object} response.successResp{data=models.InputTemplate} "input template deleted successful"
+// @Failure 400 {object} response.errorResp "invalid input template request data"
+// @Failure 403 {object} response.errorResp "deleting input template not permitted"
+// @Failure 404 {object} response.errorResp "input template not found"
+// @Failure 500 {object} response.errorResp "internal error on deleting input template"
+// @Router /input-templates/{templateID} [delete]
+func (s *InputTemplateService) DeleteInputTemplate(c *gin.Context) {
+	var (
+		err        error
+		templateID uint64
+		resp       models.InputTemplate
+	)
+
+	if templateID, err = strconv.ParseUint(c.Param("templateID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing input template id")
+		response.Error(c, response.ErrInputTemplatesInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "flows.admin") && !slices.Contains(privs, "flows.create") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	scope := inputTemplatesEditScope(uid, privs)
+	err = s.db.Model(&resp).Scopes(scope).Where("input_templates.id = ?", templateID).Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting input template by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrInputTemplatesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = s.db.Where("id = ?", resp.ID).Delete(&models.InputTemplate{}).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error deleting input template by id '%d'", templateID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	resp.Variables = resp.Placeholders()
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// getInputTemplate returns the input template available to the user to render the flow input from
+func getInputTemplate(db *gorm.DB, templateID, uid uint64, privs []string) (models.InputTemplate, error) {
+	var template models.InputTemplate
+
+	err := db.Model(&template).
+		Scopes(inputTemplatesViewScope(uid, privs)).
+		Where("input_templates.id = ?", templateID).
+		Take(&template).Error
+	if err != nil {
+		return template, err
+	}
+
+	if err = template.Valid(); err != nil {
+		return template, errors.Join(errInvalidInputTemplate, err)
+	}
+	template.Variables = template.Placeholders()
+
+	return template, nil
+}
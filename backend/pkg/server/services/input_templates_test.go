@@ -0,0 +1,248 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInputTemplatesTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE input_templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			template TEXT NOT NULL,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec(`INSERT INTO input_templates (id, name, template, shared, user_id) VALUES
+		(1, 'own', 'scan {{ host }} ports {{ports}} of {{host}}', FALSE, 1),
+		(2, 'foreign', 'foreign {{target}}', FALSE, 2),
+		(3, 'global', 'enumerate subdomains of {{domain}}', TRUE, 2)`)
+
+	return db
+}
+
+func doInputTemplateRequest(
+	t *testing.T,
+	handler gin.HandlerFunc,
+	method, templateID, body string,
+	privs []string,
+) (*httptest.ResponseRecorder, json.RawMessage) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "templateID", Value: templateID}}
+	c.Request, _ = http.NewRequest(method, "/input-templates/"+templateID, bytes.NewBufferString(body))
+	if method == http.MethodGet {
+		c.Request.URL.RawQuery = "page=1&pageSize=-1&type=init"
+	}
+
+	handler(c)
+
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if w.Code < http.StatusBadRequest {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp.Data
+}
+
+func TestGetInputTemplates_Scoping(t *testing.T) {
+	db := setupInputTemplatesTestDB(t)
+	defer db.Close()
+	svc := NewInputTemplateService(db)
+
+	names := func(data json.RawMessage) []string {
+		var list inputTemplates
+		require.NoError(t, json.Unmarshal(data, &list))
+		var result []string
+		for _, template := range list.InputTemplates {
+			result = append(result, template.Name)
+		}
+		return result
+	}
+
+	w, data := doInputTemplateRequest(t, svc.GetInputTemplates, http.MethodGet, "", "", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"own", "global"}, names(data))
+
+	w, data = doInputTemplateRequest(t, svc.GetInputTemplates, http.MethodGet, "", "", []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.ElementsMatch(t, []string{"own", "foreign", "global"}, names(data))
+
+	w, _ = doInputTemplateRequest(t, svc.GetInputTemplate, http.MethodGet, "2", "", []string{"flows.view"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, data = doInputTemplateRequest(t, svc.GetInputTemplate, http.MethodGet, "1", "", []string{"flows.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var template models.InputTemplate
+	require.NoError(t, json.Unmarshal(data, &template))
+	assert.Equal(t, []string{"host", "ports"}, template.Variables)
+
+	w, _ = doInputTemplateRequest(t, svc.GetInputTemplates, http.MethodGet, "", "", []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateInputTemplate(t *testing.T) {
+	db := setupInputTemplatesTestDB(t)
+	defer db.Close()
+	svc := NewInputTemplateService(db)
+
+	body := `{"name":"web","template":"check {{url}} for {{vuln_class}}"}`
+	w, data := doInputTemplateRequest(t, svc.CreateInputTemplate, http.MethodPost, "", body, []string{"flows.create"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var template models.InputTemplate
+	require.NoError(t, json.Unmarshal(data, &template))
+	assert.Equal(t, uint64(1), template.UserID)
+	assert.False(t, template.Shared)
+	assert.Equal(t, []string{"url", "vuln_class"}, template.Variables)
+
+	var stored models.InputTemplate
+	require.NoError(t, db.Where("id = ?", template.ID).Take(&stored).Error)
+	assert.Equal(t, "check {{url}} for {{vuln_class}}", stored.Template)
+
+	invalid := map[string]string{
+		"missing template":      `{"name":"broken"}`,
+		"missing name":          `{"template":"scan {{host}}"}`,
+		"malformed placeholder": `{"name":"broken","template":"scan {{target-host}}"}`,
+		"unclosed placeholder":  `{"name":"broken","template":"scan {{host"}`,
+	}
+	for name, body := range invalid {
+		w, _ := doInputTemplateRequest(t, svc.CreateInputTemplate, http.MethodPost, "", body, []string{"flows.create"})
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	shared := `{"name":"shared","shared":true,"template":"scan {{host}}"}`
+	w, _ = doInputTemplateRequest(t, svc.CreateInputTemplate, http.MethodPost, "", shared, []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doInputTemplateRequest(t, svc.CreateInputTemplate, http.MethodPost, "", shared, []string{"flows.create", "flows.admin"})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestPatchAndDeleteInputTemplate(t *testing.T) {
+	db := setupInputTemplatesTestDB(t)
+	defer db.Close()
+	svc := NewInputTemplateService(db)
+
+	body := `{"name":"renamed","template":"scan {{host}}"}`
+	w, _ := doInputTemplateRequest(t, svc.PatchInputTemplate, http.MethodPut, "1", body, []string{"flows.create"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var stored models.InputTemplate
+	require.NoError(t, db.Where("id = 1").Take(&stored).Error)
+	assert.Equal(t, "renamed", stored.Name)
+	assert.Equal(t, "scan {{host}}", stored.Template)
+
+	// shared templates of other users can be used but not changed
+	w, _ = doInputTemplateRequest(t, svc.PatchInputTemplate, http.MethodPut, "3", body, []string{"flows.create"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w, _ = doInputTemplateRequest(t, svc.DeleteInputTemplate, http.MethodDelete, "3", "", []string{"flows.create"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	shared := `{"name":"renamed","shared":true,"template":"scan {{host}}"}`
+	w, _ = doInputTemplateRequest(t, svc.PatchInputTemplate, http.MethodPut, "1", shared, []string{"flows.create"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doInputTemplateRequest(t, svc.PatchInputTemplate, http.MethodPut, "2", shared, []string{"flows.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var foreign models.InputTemplate
+	require.NoError(t, db.Where("id = 2").Take(&foreign).Error)
+	assert.True(t, foreign.Shared)
+	assert.Equal(t, uint64(2), foreign.UserID)
+
+	w, _ = doInputTemplateRequest(t, svc.DeleteInputTemplate, http.MethodDelete, "1", "", []string{"flows.create"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, db.Where("id = 1").Take(&models.InputTemplate{}).RecordNotFound())
+}
+
+func TestInputTemplateRender(t *testing.T) {
+	template := models.InputTemplate{Template: "scan {{ host }} ports {{ports}} of {{host}}, keep {{literal braces"}
+
+	input, err := template.Render(map[string]string{"host": "10.0.0.1", "ports": "1-1024"})
+	require.NoError(t, err)
+	assert.Equal(t, "scan 10.0.0.1 ports 1-1024 of 10.0.0.1, keep {{literal braces", input)
+
+	// values aren't rendered again, so placeholders in them are kept as is
+	input, err = template.Render(map[string]string{"host": "{{ports}}", "ports": ""})
+	require.NoError(t, err)
+	assert.Equal(t, "scan {{ports}} ports  of {{ports}}, keep {{literal braces", input)
+
+	_, err = template.Render(map[string]string{"host": "10.0.0.1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing values of input template variables: ports")
+
+	_, err = template.Render(map[string]string{"host": "10.0.0.1", "ports": "80", "prots": "80"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown input template variables: prots")
+
+	input, err = models.InputTemplate{Template: "plain input"}.Render(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "plain input", input)
+}
+
+func TestCreateFlow_InputTemplateErrors(t *testing.T) {
+	db := setupInputTemplatesTestDB(t)
+	defer db.Close()
+	svc := &FlowService{db: db}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{"missing variable", `{"input_template_id":1,"variables":{"host":"10.0.0.1"},"provider":"openai"}`,
+			http.StatusBadRequest, "flows.invalid_request"},
+		{"unknown variable", `{"input_template_id":3,"variables":{"domain":"a.com","host":"b"},"provider":"openai"}`,
+			http.StatusBadRequest, "flows.invalid_request"},
+		{"foreign template", `{"input_template_id":2,"variables":{"target":"x"},"provider":"openai"}`,
+			http.StatusNotFound, "input_templates.not_found"},
+		{"input with template", `{"input":"scan","input_template_id":3,"variables":{"domain":"a.com"},"provider":"openai"}`,
+			http.StatusInternalServerError, "flows.invalid_data"},
+		{"variables without template", `{"input":"scan","variables":{"domain":"a.com"},"provider":"openai"}`,
+			http.StatusInternalServerError, "flows.invalid_data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newAuditTestContext(http.MethodPost, "/flows/", []string{"flows.create"})
+			c.Request.Body = io.NopCloser(strings.NewReader(tt.body))
+			svc.CreateFlow(c)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+
+			var resp struct {
+				ErrorCode string `json:"error_code"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantErr, resp.ErrorCode)
+		})
+	}
+}
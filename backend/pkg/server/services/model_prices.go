@@ -0,0 +1,311 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+type modelPrices struct {
+	ModelPrices []models.ModelPrice `json:"model_prices"`
+	Total       uint64              `json:"total"`
+}
+
+var modelPricesSQLMappers = map[string]any{
+	"id":                  "{{table}}.id",
+	"provider":            "{{table}}.provider",
+	"model":               "{{table}}.model",
+	"input_price_per_1k":  "{{table}}.input_price_per_1k",
+	"output_price_per_1k": "{{table}}.output_price_per_1k",
+	"effective_from":      "{{table}}.effective_from",
+	"created_at":          "{{table}}.created_at",
+	"updated_at":          "{{table}}.updated_at",
+	"data":                "({{table}}.provider || ' ' || {{table}}.model)",
+}
+
+var errModelPriceExists = errors.New("model price effective from this time already exists")
+
+type ModelPriceService struct {
+	db *gorm.DB
+}
+
+func NewModelPriceService(db *gorm.DB) *ModelPriceService {
+	return &ModelPriceService{
+		db: db,
+	}
+}
+
+// isModelPriceExists returns true if other price of the model is effective from the same time
+func (s *ModelPriceService) isModelPriceExists(price models.ModelPrice) (bool, error) {
+	var count uint64
+	err := s.db.Model(&models.ModelPrice{}).
+		Where("provider = ? AND model = ? AND effective_from = ? AND id <> ?",
+			price.Provider, price.Model, price.EffectiveFrom, price.ID).
+		Count(&count).Error
+
+	return count != 0, err
+}
+
+// GetModelPrices is a function to return model prices list
+// @Summary Retrieve model prices list
+// @Tags ModelPrices
+// @Produce json
+// @Security BearerAuth
+// @Param request query rdb.TableQuery true "query table params"
+// @Success 200 {object} response.successResp{data=modelPrices} "model prices list received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting model prices not permitted"
+// @Failure 500 {object} response.errorResp "internal error on getting model prices"
+// @Router /model-prices/ [get]
+func (s *ModelPriceService) GetModelPrices(c *gin.Context) {
+	var (
+		err   error
+		query rdb.TableQuery
+		resp  modelPrices
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.pricing.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	query.Init("model_prices", modelPricesSQLMappers)
+
+	if resp.Total, err = query.Query(s.db, &resp.ModelPrices); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding model prices")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := 0; i < len(resp.ModelPrices); i++ {
+		if err = resp.ModelPrices[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating model price data '%d'", resp.ModelPrices[i].ID)
+			response.Error(c, response.ErrModelPricesInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// CreateModelPrice is a function to add the model price
+// @Summary Create new model price
+// @Description The price is used to calculate cost of the flows created since effective_from time, it's now by default
+// @Tags ModelPrices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param json body models.SaveModelPrice true "model price to create"
+// @Success 201 {object} response.successResp{data=models.ModelPrice} "model price created successful"
+// @Failure 400 {object} response.errorResp "invalid model price request data"
+// @Failure 403 {object} response.errorResp "creating model price not permitted"
+// @Failure 500 {object} response.errorResp "internal error on creating model price"
+// @Router /model-prices/ [post]
+func (s *ModelPriceService) CreateModelPrice(c *gin.Context) {
+	var (
+		err   error
+		price models.SaveModelPrice
+	)
+
+	if err = c.ShouldBindJSON(&price); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.pricing.admin") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = price.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating model price data")
+		response.Error(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	resp := models.ModelPrice{
+		Provider:         price.Provider,
+		Model:            price.Model,
+		InputPricePer1K:  price.InputPricePer1K,
+		OutputPricePer1K: price.OutputPricePer1K,
+		EffectiveFrom:    time.Now().UTC(),
+	}
+	if price.EffectiveFrom != nil {
+		resp.EffectiveFrom = price.EffectiveFrom.UTC()
+	}
+
+	if exists, err := s.isModelPriceExists(resp); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error checking model price uniqueness")
+		response.Error(c, response.ErrInternal, err)
+		return
+	} else if exists {
+		logger.FromContext(c).Errorf("error creating model price: %v", errModelPriceExists)
+		response.Error(c, response.ErrModelPricesInvalidRequest, errModelPriceExists)
+		return
+	}
+
+	if err = s.db.Create(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error creating model price")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp)
+}
+
+// PatchModelPrice is a function to replace the model price
+// @Summary Update model price
+// @Description Cost of the flows which was already calculated is not changed
+// @Tags ModelPrices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param priceID path int true "model price id" minimum(0)
+// @Param json body models.SaveModelPrice true "model price to update"
+// @Success 200 {object} response.successResp{data=models.ModelPrice} "model price updated successful"
+// @Failure 400 {object} response.errorResp "invalid model price request data"
+// @Failure 403 {object} response.errorResp "updating model price not permitted"
+// @Failure 404 {object} response.errorResp "model price not found"
+// @Failure 500 {object} response.errorResp "internal error on updating model price"
+// @Router /model-prices/{priceID} [put]
+func (s *ModelPriceService) PatchModelPrice(c *gin.Context) {
+	var (
+		err     error
+		priceID uint64
+		price   models.SaveModelPrice
+		resp    models.ModelPrice
+	)
+
+	if priceID, err = strconv.ParseUint(c.Param("priceID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing model price id")
+		response.Error(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	if err = c.ShouldBindJSON(&price); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.pricing.admin") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = price.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating model price data")
+		response.Error(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	if err = s.db.Where("id = ?", priceID).Take(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting model price by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrModelPricesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	resp.Provider = price.Provider
+	resp.Model = price.Model
+	resp.InputPricePer1K = price.InputPricePer1K
+	resp.OutputPricePer1K = price.OutputPricePer1K
+	if price.EffectiveFrom != nil {
+		resp.EffectiveFrom = price.EffectiveFrom.UTC()
+	}
+
+	if exists, err := s.isModelPriceExists(resp); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error checking model price uniqueness")
+		response.Error(c, response.ErrInternal, err)
+		return
+	} else if exists {
+		logger.FromContext(c).Errorf("error updating model price: %v", errModelPriceExists)
+		response.Error(c, response.ErrModelPricesInvalidRequest, errModelPriceExists)
+		return
+	}
+
+	if err = s.db.Save(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error updating model price by id '%d'", priceID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// DeleteModelPrice is a function to delete the model price
+// @Summary Delete model price
+// @Tags ModelPrices
+// @Produce json
+// @Security BearerAuth
+// @Param priceID path int true "model price id" minimum(0)
+// @Success 200 {object} response.successResp{data=models.ModelPrice} "model price deleted successful"
+// @Failure 400 {object} response.errorResp "invalid model price request data"
+// @Failure 403 {object} response.errorResp "deleting model price not permitted"
+// @Failure 404 {object} response.errorResp "model price not found"
+// @Failure 500 {object} response.errorResp "internal error on deleting model price"
+// @Router /model-prices/{priceID} [delete]
+func (s *ModelPriceService) DeleteModelPrice(c *gin.Context) {
+	var (
+		err     error
+		priceID uint64
+		resp    models.ModelPrice
+	)
+
+	if priceID, err = strconv.ParseUint(c.Param("priceID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing model price id")
+		response.Error(c, response.ErrModelPricesInvalidRequest, err)
+		return
+	}
+
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "settings.pricing.admin") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Where("id = ?", priceID).Take(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting model price by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrModelPricesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = s.db.Where("id = ?", resp.ID).Delete(&models.ModelPrice{}).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error deleting model price by id '%d'", priceID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupModelPricesTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE model_prices (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			input_price_per_1k REAL NOT NULL,
+			output_price_per_1k REAL NOT NULL,
+			effective_from DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec(`INSERT INTO model_prices (id, provider, model, input_price_per_1k, output_price_per_1k, effective_from) VALUES
+		(1, 'openai', 'gpt-4o', 0.005, 0.015, '2026-01-01 00:00:00+00:00'),
+		(2, 'anthropic', 'claude', 0.003, 0.015, '2026-01-01 00:00:00+00:00')`)
+
+	return db
+}
+
+func doModelPriceRequest(
+	t *testing.T,
+	handler gin.HandlerFunc,
+	method, priceID, body string,
+	privs []string,
+) (*httptest.ResponseRecorder, json.RawMessage) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Params = gin.Params{{Key: "priceID", Value: priceID}}
+	c.Request, _ = http.NewRequest(method, "/model-prices/"+priceID, bytes.NewBufferString(body))
+	if method == http.MethodGet {
+		c.Request.URL.RawQuery = "page=1&pageSize=-1&type=init"
+	}
+
+	handler(c)
+
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if w.Code < http.StatusBadRequest {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp.Data
+}
+
+func TestGetModelPrices(t *testing.T) {
+	db := setupModelPricesTestDB(t)
+	defer db.Close()
+	svc := NewModelPriceService(db)
+
+	w, data := doModelPriceRequest(t, svc.GetModelPrices, http.MethodGet, "", "", []string{"settings.pricing.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var list modelPrices
+	require.NoError(t, json.Unmarshal(data, &list))
+	assert.Equal(t, uint64(2), list.Total)
+	require.Len(t, list.ModelPrices, 2)
+
+	w, _ = doModelPriceRequest(t, svc.GetModelPrices, http.MethodGet, "", "", []string{"flows.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateModelPrice(t *testing.T) {
+	db := setupModelPricesTestDB(t)
+	defer db.Close()
+	svc := NewModelPriceService(db)
+	admin := []string{"settings.pricing.admin", "settings.pricing.view"}
+
+	body := `{"provider":"openai","model":"gpt-4o","input_price_per_1k":0.0025,"output_price_per_1k":0.01,` +
+		`"effective_from":"2026-06-01T00:00:00Z"}`
+	w, data := doModelPriceRequest(t, svc.CreateModelPrice, http.MethodPost, "", body, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var price models.ModelPrice
+	require.NoError(t, json.Unmarshal(data, &price))
+	assert.Equal(t, "gpt-4o", price.Model)
+	assert.InDelta(t, 0.0025, price.InputPricePer1K, 1e-9)
+
+	// the same model can't have two prices effective from the same time
+	w, _ = doModelPriceRequest(t, svc.CreateModelPrice, http.MethodPost, "", body, admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	invalid := map[string]string{
+		"unknown provider": `{"provider":"unknown","model":"x","input_price_per_1k":1,"output_price_per_1k":1}`,
+		"missing model":    `{"provider":"openai","input_price_per_1k":1,"output_price_per_1k":1}`,
+		"negative price":   `{"provider":"openai","model":"x","input_price_per_1k":-1,"output_price_per_1k":1}`,
+	}
+	for name, body := range invalid {
+		w, _ := doModelPriceRequest(t, svc.CreateModelPrice, http.MethodPost, "", body, admin)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	w, _ = doModelPriceRequest(t, svc.CreateModelPrice, http.MethodPost, "", body, []string{"settings.pricing.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestPatchAndDeleteModelPrice(t *testing.T) {
+	db := setupModelPricesTestDB(t)
+	defer db.Close()
+	svc := NewModelPriceService(db)
+	admin := []string{"settings.pricing.admin", "settings.pricing.view"}
+
+	body := `{"provider":"anthropic","model":"claude","input_price_per_1k":0.004,"output_price_per_1k":0.02}`
+	w, _ := doModelPriceRequest(t, svc.PatchModelPrice, http.MethodPut, "2", body, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var stored models.ModelPrice
+	require.NoError(t, db.Where("id = ?", 2).Take(&stored).Error)
+	assert.InDelta(t, 0.004, stored.InputPricePer1K, 1e-9)
+	assert.Equal(t, 2026, stored.EffectiveFrom.Year(), "effective time is kept if it's not set")
+
+	w, _ = doModelPriceRequest(t, svc.PatchModelPrice, http.MethodPut, "42", body, admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = doModelPriceRequest(t, svc.DeleteModelPrice, http.MethodDelete, "1", "", []string{"settings.pricing.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = doModelPriceRequest(t, svc.DeleteModelPrice, http.MethodDelete, "1", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, db.Where("id = ?", 1).Take(&models.ModelPrice{}).RecordNotFound())
+
+	w, _ = doModelPriceRequest(t, svc.DeleteModelPrice, http.MethodDelete, "1", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
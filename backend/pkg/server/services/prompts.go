@@ -198,7 +198,7 @@ func (s *PromptService) PatchPrompt(c *gin.Context) {
 
 	if err = c.ShouldBindJSON(&prompt); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrPromptsInvalidRequest, err)
+		respondBindError(c, response.ErrPromptsInvalidRequest, err)
 		return
 	} else if err = prompt.Valid(); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error validating prompt JSON")
@@ -1,10 +1,17 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"slices"
+	"sync"
+	"time"
 
 	"pentagi/pkg/providers"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/response"
@@ -12,8 +19,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const providerHealthCacheTTL = 30 * time.Second
+
+type providerHealthEntry struct {
+	health    models.ProviderHealth
+	expiresAt time.Time
+}
+
 type ProviderService struct {
 	providers providers.ProviderController
+	health    sync.Map
 }
 
 func NewProviderService(providers providers.ProviderController) *ProviderService {
@@ -55,3 +70,171 @@ func (s *ProviderService) GetProviders(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, providerInfos)
 }
+
+// GetProviderRateLimits is a function to return current state of the providers rate limiter
+// @Summary Retrieve providers rate limiter state
+// @Tags Providers
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.successResp{data=[]models.ProviderRateLimit} "providers rate limits received successful"
+// @Failure 403 {object} response.errorResp "getting providers rate limits not permitted"
+// @Router /providers/rate-limits [get]
+func (s *ProviderService) GetProviderRateLimits(c *gin.Context) {
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "providers.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	states := s.providers.GetRateLimiterState(int64(c.GetUint64("uid")))
+	rateLimits := make([]models.ProviderRateLimit, 0, len(states))
+	for _, state := range states {
+		rateLimits = append(rateLimits, models.ProviderRateLimit{
+			Name:              state.Provider.String(),
+			Model:             state.Model,
+			RequestsLimit:     state.RequestsLimit,
+			RequestsAvailable: state.RequestsAvailable,
+			TokensLimit:       state.TokensLimit,
+			TokensAvailable:   state.TokensAvailable,
+			Waiting:           state.Waiting,
+			Rejected:          state.Rejected,
+			UpdatedAt:         state.UpdatedAt,
+		})
+	}
+
+	response.Success(c, http.StatusOK, rateLimits)
+}
+
+// GetProviderHealth is a function to perform live probe of the provider
+// @Summary Check provider health by name
+// @Tags Providers
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "provider name"
+// @Success 200 {object} response.successResp{data=models.ProviderHealth} "provider health received successful"
+// @Failure 403 {object} response.errorResp "getting provider health not permitted"
+// @Failure 404 {object} response.errorResp "provider not found"
+// @Router /providers/{name}/health [get]
+func (s *ProviderService) GetProviderHealth(c *gin.Context) {
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "providers.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	prvname := provider.ProviderName(c.Param("name"))
+	cacheKey := fmt.Sprintf("%d:%s", uid, prvname)
+
+	if entry, ok := s.health.Load(cacheKey); ok {
+		cached := entry.(providerHealthEntry)
+		if time.Now().Before(cached.expiresAt) {
+			health := cached.health
+			health.Cached = true
+			response.Success(c, http.StatusOK, health)
+			return
+		}
+		s.health.Delete(cacheKey)
+	}
+
+	prv, err := s.providers.GetProvider(c, prvname, int64(uid))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting provider: not found")
+		response.Error(c, response.ErrProvidersNotFound, err)
+		return
+	}
+
+	health := probeProvider(c, prvname, prv)
+	s.evictExpiredHealth()
+	s.health.Store(cacheKey, providerHealthEntry{
+		health:    health,
+		expiresAt: time.Now().Add(providerHealthCacheTTL),
+	})
+
+	response.Success(c, http.StatusOK, health)
+}
+
+// GetProviderModels is a function to return models available in the provider
+// @Summary Retrieve provider models list by name
+// @Tags Providers
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "provider name"
+// @Success 200 {object} response.successResp{data=[]models.ProviderModel} "provider models received successful"
+// @Failure 403 {object} response.errorResp "getting provider models not permitted"
+// @Failure 404 {object} response.errorResp "provider not found"
+// @Router /providers/{name}/models [get]
+func (s *ProviderService) GetProviderModels(c *gin.Context) {
+	privs := c.GetStringSlice("prm")
+	if !slices.Contains(privs, "providers.view") {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	prvname := provider.ProviderName(c.Param("name"))
+
+	prvModels, err := s.providers.GetProviderModels(c, prvname, int64(uid))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting provider: not found")
+		response.Error(c, response.ErrProvidersNotFound, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, convertProviderModels(prvModels))
+}
+
+func convertProviderModels(prvModels pconfig.ModelsConfig) []models.ProviderModel {
+	result := make([]models.ProviderModel, 0, len(prvModels))
+	for _, model := range prvModels {
+		result = append(result, models.ProviderModel{
+			Name:          model.Name,
+			Description:   model.Description,
+			ReleaseDate:   model.ReleaseDate,
+			Thinking:      model.Thinking,
+			ContextWindow: model.ContextWindow,
+			Tools:         model.Tools,
+		})
+	}
+
+	return result
+}
+
+// evictExpiredHealth drops the cached probes of all users and providers which are expired,
+// otherwise the entries of the providers which are not checked again would be kept forever
+func (s *ProviderService) evictExpiredHealth() {
+	now := time.Now()
+	s.health.Range(func(key, value any) bool {
+		if entry, ok := value.(providerHealthEntry); !ok || !now.Before(entry.expiresAt) {
+			s.health.Delete(key)
+		}
+		return true
+	})
+}
+
+// probeProvider performs a minimal completion call to check that the provider is reachable
+func probeProvider(ctx context.Context, prvname provider.ProviderName, prv provider.Provider) models.ProviderHealth {
+	start := time.Now()
+	err := providers.ProbeProvider(ctx, prv)
+	health := models.ProviderHealth{
+		Name:      prvname.String(),
+		Type:      models.ProviderType(prv.Type()),
+		Status:    "ok",
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+
+	if err != nil {
+		health.Status = "error"
+		if errors.Is(err, context.DeadlineExceeded) {
+			health.Error = "provider probe timed out"
+		} else {
+			health.Error = providers.SanitizeProviderError(err)
+		}
+	}
+
+	return health
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"pentagi/pkg/providers"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubHealthProvider struct {
+	provider.Provider
+	err   error
+	calls int
+}
+
+func (p *stubHealthProvider) Type() provider.ProviderType {
+	return provider.ProviderCustom
+}
+
+func (p *stubHealthProvider) Call(ctx context.Context, opt pconfig.ProviderOptionsType, prompt string) (string, error) {
+	p.calls++
+	return "pong", p.err
+}
+
+type stubHealthProviderController struct {
+	providers.ProviderController
+	providers map[provider.ProviderName]provider.Provider
+}
+
+func (pc *stubHealthProviderController) GetProvider(
+	ctx context.Context,
+	prvname provider.ProviderName,
+	userID int64,
+) (provider.Provider, error) {
+	if prv, ok := pc.providers[prvname]; ok {
+		return prv, nil
+	}
+
+	return nil, fmt.Errorf("provider '%s' not found", prvname)
+}
+
+func getProviderHealth(t *testing.T, svc *ProviderService, name string) (int, models.ProviderHealth) {
+	t.Helper()
+
+	c, w := newAuditTestContext(http.MethodGet, "/providers/"+name+"/health", []string{"providers.view"})
+	c.Params = gin.Params{{Key: "name", Value: name}}
+	svc.GetProviderHealth(c)
+
+	var resp struct {
+		Data models.ProviderHealth `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w.Code, resp.Data
+}
+
+func TestGetProviderHealth(t *testing.T) {
+	healthy := &stubHealthProvider{}
+	failing := &stubHealthProvider{err: errors.New("401: Incorrect API key provided: sk-abc123")}
+	svc := NewProviderService(&stubHealthProviderController{
+		providers: map[provider.ProviderName]provider.Provider{
+			"healthy": healthy,
+			"failing": failing,
+		},
+	})
+
+	code, health := getProviderHealth(t, svc, "healthy")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", health.Status)
+	assert.Equal(t, "healthy", health.Name)
+	assert.False(t, health.Cached)
+
+	code, health = getProviderHealth(t, svc, "healthy")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, health.Cached)
+	assert.Equal(t, 1, healthy.calls)
+
+	code, health = getProviderHealth(t, svc, "failing")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "error", health.Status)
+	assert.NotContains(t, health.Error, "sk-abc123")
+	assert.Contains(t, health.Error, "[REDACTED]")
+
+	code, _ = getProviderHealth(t, svc, "unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	c, w := newAuditTestContext(http.MethodGet, "/providers/healthy/health", nil)
+	c.Params = gin.Params{{Key: "name", Value: "healthy"}}
+	svc.GetProviderHealth(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetProviderHealthEvictsExpired(t *testing.T) {
+	svc := NewProviderService(&stubHealthProviderController{
+		providers: map[provider.ProviderName]provider.Provider{"healthy": &stubHealthProvider{}},
+	})
+	svc.health.Store("1:removed", providerHealthEntry{expiresAt: time.Now().Add(-time.Second)})
+
+	code, _ := getProviderHealth(t, svc, "healthy")
+	require.Equal(t, http.StatusOK, code)
+
+	_, ok := svc.health.Load("1:removed")
+	assert.False(t, ok)
+	_, ok = svc.health.Load("1:healthy")
+	assert.True(t, ok)
+}
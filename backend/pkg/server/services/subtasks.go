@@ -1,11 +1,15 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 
+	"pentagi/pkg/controller"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/rdb"
@@ -25,6 +29,12 @@ type subtasksGrouped struct {
 	Total   uint64   `json:"total"`
 }
 
+type subtaskToolcalls struct {
+	Toolcalls []models.Toolcall      `json:"toolcalls"`
+	Grouped   []models.ToolcallGroup `json:"grouped"`
+	Total     uint64                 `json:"total"`
+}
+
 var subtasksSQLMappers = map[string]any{
 	"id":          "{{table}}.id",
 	"status":      "{{table}}.status",
@@ -33,6 +43,7 @@ var subtasksSQLMappers = map[string]any{
 	"context":     "{{table}}.context",
 	"result":      "{{table}}.result",
 	"task_id":     "{{table}}.task_id",
+	"priority":    "{{table}}.priority",
 	"created_at":  "{{table}}.created_at",
 	"updated_at":  "{{table}}.updated_at",
 	"data":        "({{table}}.status || ' ' || {{table}}.title || ' ' || {{table}}.description || ' ' || {{table}}.context || ' ' || {{table}}.result)",
@@ -40,11 +51,13 @@ var subtasksSQLMappers = map[string]any{
 
 type SubtaskService struct {
 	db *gorm.DB
+	fc controller.FlowController
 }
 
-func NewSubtaskService(db *gorm.DB) *SubtaskService {
+func NewSubtaskService(db *gorm.DB, fc controller.FlowController) *SubtaskService {
 	return &SubtaskService{
 		db: db,
+		fc: fc,
 	}
 }
 
@@ -319,3 +332,447 @@ func (s *SubtaskService) GetFlowTaskSubtask(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, resp)
 }
+
+// GetFlowTaskSubtaskTools is a function to return tool calls of the flow task subtask
+// @Summary Retrieve tool calls of the flow task subtask with the repeated calls grouped
+// @Tags Subtasks
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param taskID path int true "task id" minimum(0)
+// @Param subtaskID path int true "subtask id" minimum(0)
+// @Success 200 {object} response.successResp{data=subtaskToolcalls} "flow task subtask tool calls received successful"
+// @Failure 400 {object} response.errorResp "invalid subtask request data"
+// @Failure 403 {object} response.errorResp "getting flow task subtask tool calls not permitted"
+// @Failure 404 {object} response.errorResp "flow task subtask not found"
+// @Failure 500 {object} response.errorResp "internal error on getting flow task subtask tool calls"
+// @Router /flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID}/tools [get]
+func (s *SubtaskService) GetFlowTaskSubtaskTools(c *gin.Context) {
+	var (
+		err       error
+		flowID    uint64
+		taskID    uint64
+		subtaskID uint64
+		subtask   models.Subtask
+		resp      subtaskToolcalls
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if taskID, err = strconv.ParseUint(c.Param("taskID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing task id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if subtaskID, err = strconv.ParseUint(c.Param("subtaskID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing subtask id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "subtasks.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
+				Joins("INNER JOIN flows f ON f.id = t.flow_id").
+				Where("f.id = ? AND t.id = ?", flowID, taskID)
+		}
+	} else if slices.Contains(privs, "subtasks.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
+				Joins("INNER JOIN flows f ON f.id = t.flow_id").
+				Where("f.id = ? AND f.user_id = ? AND t.id = ?", flowID, uid, taskID)
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	err = s.db.Model(&subtask).
+		Scopes(scope).
+		Where("subtasks.id = ?", subtaskID).
+		Take(&subtask).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow task subtask by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrSubtasksNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	err = s.db.
+		Where("flow_id = ? AND task_id = ? AND subtask_id = ?", flowID, taskID, subtaskID).
+		Order("created_at ASC, id ASC").
+		Find(&resp.Toolcalls).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow task subtask tool calls")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := range resp.Toolcalls {
+		resp.Toolcalls[i].Args = redactToolcallArgs(resp.Toolcalls[i].Args)
+		if err = resp.Toolcalls[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating tool call data '%d'", resp.Toolcalls[i].ID)
+			response.Error(c, response.ErrSubtasksInvalidData, err)
+			return
+		}
+	}
+
+	resp.Grouped = groupToolcalls(resp.Toolcalls)
+	resp.Total = uint64(len(resp.Toolcalls))
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// CancelFlowTaskSubtask is a function to cancel flow task subtask by id without stopping the flow
+// @Summary Cancel flow task subtask by id
+// @Tags Subtasks
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param taskID path int true "task id" minimum(0)
+// @Param subtaskID path int true "subtask id" minimum(0)
+// @Success 200 {object} response.successResp{data=models.Subtask} "flow task subtask canceled successful"
+// @Failure 400 {object} response.errorResp "invalid subtask request data or subtask has already completed"
+// @Failure 403 {object} response.errorResp "canceling flow task subtask not permitted"
+// @Failure 404 {object} response.errorResp "flow task subtask not found"
+// @Failure 500 {object} response.errorResp "internal error on canceling flow task subtask"
+// @Router /flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID} [delete]
+func (s *SubtaskService) CancelFlowTaskSubtask(c *gin.Context) {
+	var (
+		err       error
+		flowID    uint64
+		taskID    uint64
+		subtaskID uint64
+		resp      models.Subtask
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if taskID, err = strconv.ParseUint(c.Param("taskID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing task id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if subtaskID, err = strconv.ParseUint(c.Param("subtaskID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing subtask id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "subtasks.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
+				Joins("INNER JOIN flows f ON f.id = t.flow_id").
+				Where("f.id = ? AND t.id = ?", flowID, taskID)
+		}
+	} else if slices.Contains(privs, "subtasks.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
+				Joins("INNER JOIN flows f ON f.id = t.flow_id").
+				Where("f.id = ? AND f.user_id = ? AND t.id = ?", flowID, uid, taskID)
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	err = s.db.Model(&resp).
+		Scopes(scope).
+		Where("subtasks.id = ?", subtaskID).
+		Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow task subtask by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrSubtasksNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	fw, err := s.fc.GetFlow(c, int64(flowID))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id in flow controller")
+		if errors.Is(err, controller.ErrFlowNotFound) {
+			response.Error(c, response.ErrSubtasksNotRunning, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = fw.CancelSubtask(c, int64(taskID), int64(subtaskID)); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error canceling flow task subtask")
+		switch {
+		case errors.Is(err, controller.ErrTaskNotFound), errors.Is(err, controller.ErrSubtaskNotFound):
+			response.Error(c, response.ErrSubtasksNotFound, err)
+		case errors.Is(err, controller.ErrSubtaskCompleted):
+			response.Error(c, response.ErrSubtasksAlreadyCompleted, err)
+		default:
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = s.db.Model(&resp).Where("id = ?", subtaskID).Take(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow task subtask by id")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// PrioritizeFlowTaskSubtask is a function to change the order of the planned flow task subtask
+// @Summary Change priority of the planned flow task subtask
+// @Description Subtasks with higher priority run first, empty priority moves the subtask to run next
+// @Tags Subtasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param taskID path int true "task id" minimum(0)
+// @Param subtaskID path int true "subtask id" minimum(0)
+// @Param json body models.PrioritizeSubtask true "subtask priority"
+// @Success 200 {object} response.successResp{data=models.Subtask} "flow task subtask priority changed successful"
+// @Failure 400 {object} response.errorResp "invalid subtask request data or subtask is not planned"
+// @Failure 403 {object} response.errorResp "changing flow task subtask priority not permitted"
+// @Failure 404 {object} response.errorResp "flow task subtask not found"
+// @Failure 500 {object} response.errorResp "internal error on changing flow task subtask priority"
+// @Router /flows/{flowID}/tasks/{taskID}/subtasks/{subtaskID}/priority [put]
+func (s *SubtaskService) PrioritizeFlowTaskSubtask(c *gin.Context) {
+	var (
+		err        error
+		flowID     uint64
+		taskID     uint64
+		subtaskID  uint64
+		prioritize models.PrioritizeSubtask
+		resp       models.Subtask
+	)
+
+	if err = c.ShouldBindJSON(&prioritize); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		respondBindError(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if err = prioritize.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating subtask priority")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if taskID, err = strconv.ParseUint(c.Param("taskID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing task id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	if subtaskID, err = strconv.ParseUint(c.Param("subtaskID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing subtask id")
+		response.Error(c, response.ErrSubtasksInvalidRequest, err)
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "subtasks.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
+				Joins("INNER JOIN flows f ON f.id = t.flow_id").
+				Where("f.id = ? AND t.id = ?", flowID, taskID)
+		}
+	} else if slices.Contains(privs, "subtasks.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
+				Joins("INNER JOIN flows f ON f.id = t.flow_id").
+				Where("f.id = ? AND f.user_id = ? AND t.id = ?", flowID, uid, taskID)
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	err = s.db.Model(&resp).
+		Scopes(scope).
+		Where("subtasks.id = ?", subtaskID).
+		Take(&resp).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow task subtask by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrSubtasksNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	fw, err := s.fc.GetFlow(c, int64(flowID))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting flow by id in flow controller")
+		if errors.Is(err, controller.ErrFlowNotFound) {
+			response.Error(c, response.ErrSubtasksNotRunning, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = fw.SetSubtaskPriority(c, int64(taskID), int64(subtaskID), prioritize.Priority); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error changing flow task subtask priority")
+		switch {
+		case errors.Is(err, controller.ErrTaskNotFound), errors.Is(err, controller.ErrSubtaskNotFound):
+			response.Error(c, response.ErrSubtasksNotFound, err)
+		case errors.Is(err, controller.ErrSubtaskNotPlanned):
+			response.Error(c, response.ErrSubtasksNotPlanned, err)
+		default:
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	if err = s.db.Model(&resp).Where("id = ?", subtaskID).Take(&resp).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow task subtask by id")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// sensitiveArgKeys are the parts of the tool call argument names which values are hidden,
+// the names are compared in lower case without separators, e.g. "API_Key" matches "apikey"
+var sensitiveArgKeys = []string{
+	"password", "passwd", "passphrase", "secret", "token", "apikey",
+	"authorization", "cookie", "credential", "privatekey", "sessionid",
+}
+
+var (
+	argNameReplacer      = strings.NewReplacer("_", "", "-", "")
+	urlCredentialsRegexp = regexp.MustCompile(`(://[^:/@\s]+):[^@/\s]+@`)
+	authHeaderRegexp     = regexp.MustCompile(`(?i)\b((?:proxy-)?authorization|cookie|x-api-key)(\s*[:=]\s*)[^"'\r\n]+`)
+)
+
+func isSensitiveArgKey(key string) bool {
+	key = argNameReplacer.Replace(strings.ToLower(key))
+	for _, part := range sensitiveArgKeys {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactToolcallArgs returns the copy of the arguments with hidden values of the sensitive fields,
+// credentials of URLs and auth headers inside the strings (e.g. terminal commands) are hidden too
+func redactToolcallArgs(args models.ToolcallArgs) models.ToolcallArgs {
+	if args == nil {
+		return nil
+	}
+
+	return models.ToolcallArgs(redactArgValue("", map[string]any(args)).(map[string]any))
+}
+
+func redactArgValue(key string, value any) any {
+	if key != "" && isSensitiveArgKey(key) {
+		return models.FlowSecretRedacted
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for k, item := range v {
+			redacted[k] = redactArgValue(k, item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = redactArgValue("", item)
+		}
+		return redacted
+	case string:
+		v = urlCredentialsRegexp.ReplaceAllString(v, "$1:"+models.FlowSecretRedacted+"@")
+		return authHeaderRegexp.ReplaceAllString(v, "${1}${2}"+models.FlowSecretRedacted)
+	default:
+		return v
+	}
+}
+
+// groupToolcalls groups the identical tool calls in the order of the first call,
+// the calls must be redacted before to compare them by the visible arguments
+func groupToolcalls(toolcalls []models.Toolcall) []models.ToolcallGroup {
+	groups := make([]models.ToolcallGroup, 0)
+	index := make(map[string]int)
+
+	for _, tc := range toolcalls {
+		// map keys are sorted by the encoder, so the same arguments have the same key
+		args, _ := json.Marshal(tc.Args)
+		key := tc.Name + "\x00" + string(args)
+
+		idx, ok := index[key]
+		if !ok {
+			idx = len(groups)
+			index[key] = idx
+			groups = append(groups, models.ToolcallGroup{
+				Name:          tc.Name,
+				Args:          tc.Args,
+				FirstCalledAt: tc.CreatedAt,
+				LastCalledAt:  tc.CreatedAt,
+			})
+		}
+
+		group := &groups[idx]
+		group.Count++
+		group.DurationSeconds += tc.DurationSeconds
+		switch tc.Status {
+		case models.ToolcallStatusFinished:
+			group.Finished++
+		case models.ToolcallStatusFailed:
+			group.Failed++
+		}
+		if tc.CreatedAt.Before(group.FirstCalledAt) {
+			group.FirstCalledAt = tc.CreatedAt
+		}
+		if tc.CreatedAt.After(group.LastCalledAt) {
+			group.LastCalledAt = tc.CreatedAt
+		}
+	}
+
+	return groups
+}
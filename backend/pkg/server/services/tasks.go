@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
@@ -37,6 +39,33 @@ var tasksSQLMappers = map[string]any{
 	"data":       "({{table}}.status || ' ' || {{table}}.title || ' ' || {{table}}.input || ' ' || {{table}}.result)",
 }
 
+const (
+	defaultTasksSearchSize = 20
+	// searchSnippetContext is the amount of characters around the match kept in the snippet
+	searchSnippetContext = 80
+
+	tasksSearchData    = "(t.title || ' ' || t.input || ' ' || t.result)"
+	subtasksSearchData = "(s.title || ' ' || s.description || ' ' || s.result)"
+)
+
+type tasksSearch struct {
+	Matches []models.TaskSearchMatch `json:"matches"`
+	Total   uint64                   `json:"total"`
+}
+
+// taskSearchRow is a row of the united tasks and subtasks search query
+type taskSearchRow struct {
+	Type      string
+	ID        uint64
+	TaskID    uint64
+	SubtaskID *uint64
+	FlowID    uint64
+	Title     string
+	Status    string
+	Content   string
+	UpdatedAt time.Time
+}
+
 type TaskService struct {
 	db *gorm.DB
 }
@@ -305,3 +334,164 @@ func (s *TaskService) GetFlowTaskGraph(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, resp)
 }
+
+// SearchTasks is a function to search tasks and subtasks across all available flows
+// @Summary Search tasks and subtasks by text across flows
+// @Tags Tasks
+// @Produce json
+// @Security BearerAuth
+// @Param request query models.TasksSearchQuery true "search text, flow filter and paging params"
+// @Success 200 {object} response.successResp{data=tasksSearch} "tasks search results received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "searching tasks not permitted"
+// @Failure 500 {object} response.errorResp "internal error on searching tasks"
+// @Router /tasks/search [get]
+func (s *TaskService) SearchTasks(c *gin.Context) {
+	var (
+		err   error
+		query models.TasksSearchQuery
+		rows  []taskSearchRow
+		resp  tasksSearch
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrTasksInvalidRequest, err)
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Size == 0 {
+		query.Size = defaultTasksSearchSize
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+
+	// flows conditions are shared by both parts of the search and must never be skipped
+	flowsCond, flowsArgs := "f.deleted_at IS NULL", []any{}
+	if slices.Contains(privs, "tasks.admin") {
+		// all flows are available
+	} else if slices.Contains(privs, "tasks.view") {
+		flowsCond += " AND f.user_id = ?"
+		flowsArgs = append(flowsArgs, uid)
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if query.FlowID != nil {
+		flowsCond += " AND f.id = ?"
+		flowsArgs = append(flowsArgs, *query.FlowID)
+	}
+
+	pattern := "%" + escapeLikePattern(strings.ToLower(query.Query)) + "%"
+
+	sqlQuery := `SELECT 'task' AS type, t.id AS id, t.id AS task_id, NULL AS subtask_id, f.id AS flow_id,
+			t.title AS title, t.status AS status, (t.input || ' ' || t.result) AS content, t.updated_at AS updated_at
+		FROM tasks t
+		INNER JOIN flows f ON f.id = t.flow_id
+		WHERE ` + flowsCond + ` AND LOWER(` + tasksSearchData + `) LIKE ? ESCAPE '\'`
+	args := append(slices.Clone(flowsArgs), pattern)
+
+	// subtasks follow the same visibility rules as in the task graph
+	isSubtasksAdmin := slices.Contains(privs, "subtasks.admin")
+	isSubtasksView := slices.Contains(privs, "subtasks.view")
+	if isSubtasksAdmin || isSubtasksView {
+		subtasksCond := flowsCond
+		switch {
+		case isSubtasksAdmin && !isSubtasksView:
+			subtasksCond += " AND f.user_id != ?"
+		case !isSubtasksAdmin && isSubtasksView:
+			subtasksCond += " AND f.user_id = ?"
+		}
+
+		sqlQuery += ` UNION ALL SELECT 'subtask' AS type, s.id AS id, s.task_id AS task_id, s.id AS subtask_id, f.id AS flow_id,
+			s.title AS title, s.status AS status, (s.description || ' ' || s.result) AS content, s.updated_at AS updated_at
+		FROM subtasks s
+		INNER JOIN tasks t ON t.id = s.task_id
+		INNER JOIN flows f ON f.id = t.flow_id
+		WHERE ` + subtasksCond + ` AND LOWER(` + subtasksSearchData + `) LIKE ? ESCAPE '\'`
+		args = append(args, flowsArgs...)
+		if isSubtasksAdmin != isSubtasksView {
+			args = append(args, uid)
+		}
+		args = append(args, pattern)
+	}
+
+	err = s.db.Raw("SELECT COUNT(*) FROM ("+sqlQuery+") matches", args...).Row().Scan(&resp.Total)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on counting tasks search results")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	offset := (query.Page - 1) * query.Size
+	err = s.db.Raw("SELECT * FROM ("+sqlQuery+") matches ORDER BY updated_at DESC, type ASC, id DESC LIMIT ? OFFSET ?",
+		append(args, query.Size, offset)...).Scan(&rows).Error
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on searching tasks")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	resp.Matches = make([]models.TaskSearchMatch, 0, len(rows))
+	for _, row := range rows {
+		match := models.TaskSearchMatch{
+			Type:      row.Type,
+			ID:        row.ID,
+			TaskID:    row.TaskID,
+			SubtaskID: row.SubtaskID,
+			FlowID:    row.FlowID,
+			Title:     row.Title,
+			Status:    row.Status,
+			Snippet:   searchSnippet(row.Content, query.Query),
+			UpdatedAt: row.UpdatedAt,
+		}
+		if err = match.Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating %s search match data '%d'", row.Type, row.ID)
+			response.Error(c, response.ErrTasksInvalidData, err)
+			return
+		}
+		resp.Matches = append(resp.Matches, match)
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// escapeLikePattern escapes wildcards of the LIKE operator to match the text literally
+func escapeLikePattern(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
+
+// searchSnippet returns a short single-line fragment of the text around the first match of the query,
+// the beginning of the text is returned if the query was matched in other fields
+func searchSnippet(text, query string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+	needle := []rune(strings.ToLower(strings.Join(strings.Fields(query), " ")))
+
+	start, end := 0, min(2*searchSnippetContext, len(runes))
+	if len(lower) == len(runes) && len(needle) != 0 {
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if slices.Equal(lower[i:i+len(needle)], needle) {
+				start = max(i-searchSnippetContext, 0)
+				end = min(i+len(needle)+searchSnippetContext, len(runes))
+				break
+			}
+		}
+	}
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+
+	return snippet
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTasksSearchTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE flows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			deleted_at DATETIME
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'created',
+			title TEXT NOT NULL DEFAULT 'untitled',
+			input TEXT NOT NULL,
+			result TEXT NOT NULL DEFAULT '',
+			flow_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec(`
+		CREATE TABLE subtasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL DEFAULT 'created',
+			title TEXT NOT NULL,
+			description TEXT NOT NULL,
+			result TEXT NOT NULL DEFAULT '',
+			task_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+
+	db.Exec("INSERT INTO flows (id, user_id, deleted_at) VALUES (1, 1, NULL), (2, 2, NULL), (3, 1, '2026-01-02 00:00:00')")
+	db.Exec(`INSERT INTO tasks (id, status, title, input, result, flow_id, updated_at) VALUES
+		(1, 'finished', 'Scan web server', 'Scan 10.0.0.1 for open ports', 'Found Apache 2.4.49 vulnerable to path traversal', 1, '2026-01-01 10:00:00'),
+		(2, 'finished', 'Check FTP', 'Check anonymous FTP login', 'Login is disabled', 1, '2026-01-01 11:00:00'),
+		(3, 'finished', 'Apache audit', 'Audit Apache configuration', '', 2, '2026-01-01 12:00:00'),
+		(4, 'finished', 'Apache in deleted flow', 'Deleted', '', 3, '2026-01-01 13:00:00')`)
+	db.Exec(`INSERT INTO subtasks (id, status, title, description, result, task_id, updated_at) VALUES
+		(1, 'finished', 'Run nmap', 'Run nmap against the host', 'Port 80 runs apache httpd', 1, '2026-01-01 10:30:00'),
+		(2, 'finished', 'Exploit path traversal', 'Use CVE-2021-41773 against Apache to read 100%_secret files', '', 3, '2026-01-01 12:30:00')`)
+
+	return db
+}
+
+func doSearchTasks(t *testing.T, db *gorm.DB, rawQuery string, privs []string) (*httptest.ResponseRecorder, tasksSearch) {
+	t.Helper()
+
+	service := NewTaskService(db)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(1))
+	c.Set("prm", privs)
+	c.Request, _ = http.NewRequest("GET", "/tasks/search?"+rawQuery, nil)
+
+	service.SearchTasks(c)
+
+	var resp struct {
+		Data tasksSearch `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp.Data
+}
+
+func TestSearchTasks(t *testing.T) {
+	db := setupTasksSearchTestDB(t)
+	defer db.Close()
+
+	w, resp := doSearchTasks(t, db, "q=APACHE", []string{"tasks.view", "subtasks.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, uint64(2), resp.Total)
+	require.Len(t, resp.Matches, 2)
+
+	// the most recently updated match goes first
+	subtask := resp.Matches[0]
+	assert.Equal(t, "subtask", subtask.Type)
+	assert.Equal(t, uint64(1), subtask.ID)
+	assert.Equal(t, uint64(1), subtask.TaskID)
+	require.NotNil(t, subtask.SubtaskID)
+	assert.Equal(t, uint64(1), *subtask.SubtaskID)
+	assert.Equal(t, uint64(1), subtask.FlowID)
+	assert.Contains(t, subtask.Snippet, "runs apache httpd")
+
+	task := resp.Matches[1]
+	assert.Equal(t, "task", task.Type)
+	assert.Equal(t, uint64(1), task.ID)
+	assert.Nil(t, task.SubtaskID)
+	assert.Equal(t, "Scan web server", task.Title)
+	assert.Contains(t, task.Snippet, "Apache 2.4.49")
+
+	w, resp = doSearchTasks(t, db, "q=apache", []string{"tasks.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, uint64(1), resp.Total)
+	assert.Equal(t, "task", resp.Matches[0].Type)
+}
+
+func TestSearchTasks_Scoping(t *testing.T) {
+	db := setupTasksSearchTestDB(t)
+	defer db.Close()
+
+	// deleted flows are never searched
+	w, resp := doSearchTasks(t, db, "q=apache", []string{"tasks.admin", "subtasks.admin", "subtasks.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(4), resp.Total)
+	for _, match := range resp.Matches {
+		assert.NotEqual(t, uint64(3), match.FlowID)
+	}
+
+	w, resp = doSearchTasks(t, db, "q=apache&flow_id=2", []string{"tasks.admin", "subtasks.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(2), resp.Total)
+	for _, match := range resp.Matches {
+		assert.Equal(t, uint64(2), match.FlowID)
+	}
+
+	w, resp = doSearchTasks(t, db, "q=apache&flow_id=2", []string{"tasks.view", "subtasks.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(0), resp.Total)
+	assert.Empty(t, resp.Matches)
+
+	w, _ = doSearchTasks(t, db, "q=apache", []string{"subtasks.view"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSearchTasks_Paging(t *testing.T) {
+	db := setupTasksSearchTestDB(t)
+	defer db.Close()
+
+	w, resp := doSearchTasks(t, db, "q=apache&page=2&pageSize=3", []string{"tasks.admin", "subtasks.admin", "subtasks.view"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(4), resp.Total)
+	require.Len(t, resp.Matches, 1)
+	assert.Equal(t, "task", resp.Matches[0].Type)
+	assert.Equal(t, uint64(1), resp.Matches[0].ID)
+
+	w, _ = doSearchTasks(t, db, "q=a", []string{"tasks.admin"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = doSearchTasks(t, db, "q=apache&pageSize=101", []string{"tasks.admin"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchTasks_LiteralWildcards(t *testing.T) {
+	db := setupTasksSearchTestDB(t)
+	defer db.Close()
+
+	w, resp := doSearchTasks(t, db, "q="+strings.ReplaceAll("100%_secret", "%", "%25"), []string{"tasks.admin", "subtasks.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, uint64(1), resp.Total)
+	assert.Equal(t, uint64(2), resp.Matches[0].ID)
+
+	w, resp = doSearchTasks(t, db, "q=%25%25", []string{"tasks.admin", "subtasks.admin"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, uint64(0), resp.Total)
+}
+
+func TestSearchSnippet(t *testing.T) {
+	text := strings.Repeat("a", 200) + " Found\n\nApache  2.4.49 " + strings.Repeat("b", 200)
+
+	snippet := searchSnippet(text, "apache 2.4.49")
+	assert.True(t, strings.HasPrefix(snippet, "..."))
+	assert.True(t, strings.HasSuffix(snippet, "..."))
+	assert.Contains(t, snippet, "Found Apache 2.4.49 ")
+	assert.Equal(t, 2*searchSnippetContext+len("apache 2.4.49")+2*len("..."), len(snippet))
+
+	assert.Equal(t, "short text", searchSnippet("short  text", "text"))
+	assert.Equal(t, strings.Repeat("c", 2*searchSnippetContext)+"...",
+		searchSnippet(strings.Repeat("c", 300), "missing"))
+	assert.Equal(t, "", searchSnippet("", "missing"))
+}
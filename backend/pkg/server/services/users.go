@@ -38,6 +38,44 @@ var usersSQLMappers = map[string]any{
 	"data":       "({{table}}.hash || ' ' || {{table}}.mail || ' ' || {{table}}.name || ' ' || {{table}}.status)",
 }
 
+// userCapabilities maps capabilities of the UI to privileges, any of them grants the capability
+var userCapabilities = map[string][]string{
+	"canViewFlows":           {"flows.view", "flows.admin"},
+	"canViewAllFlows":        {"flows.admin"},
+	"canCreateFlow":          {"flows.create"},
+	"canEditFlow":            {"flows.edit", "flows.admin"},
+	"canDeleteFlow":          {"flows.delete", "flows.admin"},
+	"canSubscribeFlows":      {"flows.subscribe"},
+	"canViewTasks":           {"tasks.view", "tasks.admin"},
+	"canViewSubtasks":        {"subtasks.view", "subtasks.admin"},
+	"canViewContainers":      {"containers.view", "containers.admin"},
+	"canViewAssistants":      {"assistants.view", "assistants.admin"},
+	"canCreateAssistant":     {"assistants.create"},
+	"canEditAssistant":       {"assistants.edit", "assistants.admin"},
+	"canDeleteAssistant":     {"assistants.delete", "assistants.admin"},
+	"canViewLogs":            {"msglogs.view", "msglogs.admin"},
+	"canViewTerminalLogs":    {"termlogs.view", "termlogs.admin"},
+	"canViewScreenshots":     {"screenshots.view", "screenshots.admin"},
+	"canDownloadScreenshots": {"screenshots.download"},
+	"canViewProviders":       {"providers.view", "settings.providers.view", "settings.providers.admin"},
+	"canEditProviders":       {"settings.providers.edit", "settings.providers.admin"},
+	"canViewPrompts":         {"prompts.view", "settings.prompts.view", "settings.prompts.admin"},
+	"canEditPrompts":         {"prompts.edit", "settings.prompts.edit", "settings.prompts.admin"},
+	"canManageTokens":        {"settings.tokens.create", "settings.tokens.admin"},
+	"canViewUsage":           {"usage.view", "usage.admin"},
+	"canViewAllUsage":        {"usage.admin"},
+	"canViewRoles":           {"roles.view"},
+	"canViewUsers":           {"users.view"},
+	"canCreateUser":          {"users.create"},
+	"canEditUser":            {"users.edit"},
+	"canDeleteUser":          {"users.delete"},
+	"canViewAudit":           {"audit.view"},
+	"canViewPricing":         {"settings.pricing.view"},
+	"canManagePricing":       {"settings.pricing.admin"},
+	"canViewCache":           {"settings.cache.view"},
+	"canManageCache":         {"settings.cache.admin"},
+}
+
 type UserService struct {
 	db        *gorm.DB
 	userCache *auth.UserCache
@@ -106,6 +144,57 @@ func (s *UserService) GetCurrentUser(c *gin.Context) {
 	response.Success(c, http.StatusOK, resp)
 }
 
+// GetCurrentUserPermissions is a function to return privileges of the current user and UI capabilities
+// @Summary Retrieve privileges and capabilities of the current user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.successResp{data=models.UserPermissions} "user permissions received successful"
+// @Failure 404 {object} response.errorResp "current user role not found"
+// @Failure 500 {object} response.errorResp "internal error on getting current user permissions"
+// @Router /me/permissions [get]
+func (s *UserService) GetCurrentUserPermissions(c *gin.Context) {
+	var (
+		err  error
+		role models.Role
+	)
+
+	rid := c.GetUint64("rid")
+	privs := c.GetStringSlice("prm")
+
+	if err = s.db.Take(&role, "id = ?", rid).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding role by role id")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.ErrGetUserModelsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	resp := models.UserPermissions{
+		UserID:       c.GetUint64("uid"),
+		RoleID:       role.ID,
+		RoleName:     role.Name,
+		Type:         models.UserType(c.GetString("tid")),
+		Privileges:   append([]string{}, privs...),
+		Capabilities: make(map[string]bool, len(userCapabilities)),
+	}
+	for capability, required := range userCapabilities {
+		resp.Capabilities[capability] = slices.ContainsFunc(required, func(priv string) bool {
+			return slices.Contains(privs, priv)
+		})
+	}
+
+	if err = resp.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating user permissions")
+		response.Error(c, response.ErrUsersInvalidData, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
 // ChangePasswordCurrentUser is a function to update account password
 // @Summary Update password for current user (account)
 // @Tags Users
@@ -131,7 +220,7 @@ func (s *UserService) ChangePasswordCurrentUser(c *gin.Context) {
 			err = form.Valid()
 		}
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrChangePasswordCurrentUserInvalidPassword, err)
+		respondBindError(c, response.ErrChangePasswordCurrentUserInvalidPassword, err)
 		return
 	}
 
@@ -356,7 +445,7 @@ func (s *UserService) CreateUser(c *gin.Context) {
 
 	if err = c.ShouldBindJSON(&user); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrUsersInvalidRequest, err)
+		respondBindError(c, response.ErrUsersInvalidRequest, err)
 		return
 	}
 
@@ -478,7 +567,7 @@ func (s *UserService) PatchUser(c *gin.Context) {
 
 	if err = c.ShouldBindJSON(&user); err != nil {
 		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
-		response.Error(c, response.ErrUsersInvalidRequest, err)
+		respondBindError(c, response.ErrUsersInvalidRequest, err)
 		return
 	} else if hash != user.Hash {
 		logger.FromContext(c).Errorf("mismatch user hash to requested one")
@@ -352,3 +352,47 @@ func TestCreateUser_DuplicateEmail(t *testing.T) {
 	db.Model(&models.User{}).Where("mail = ?", "duplicate@test.com").Count(&count)
 	assert.Equal(t, 1, count, "Should have only one user with this email")
 }
+
+func TestGetCurrentUserPermissions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service := NewUserService(db, auth.NewUserCache(db))
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("uid", uint64(5))
+	c.Set("rid", uint64(2))
+	c.Set("tid", "api")
+	c.Set("prm", []string{"flows.view", "flows.create", "tasks.admin"})
+	c.Request, _ = http.NewRequest("GET", "/me/permissions", nil)
+
+	service.GetCurrentUserPermissions(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Data models.UserPermissions `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, uint64(5), resp.Data.UserID)
+	assert.Equal(t, uint64(2), resp.Data.RoleID)
+	assert.Equal(t, "User", resp.Data.RoleName)
+	assert.Equal(t, models.UserTypeAPI, resp.Data.Type)
+	assert.Equal(t, []string{"flows.view", "flows.create", "tasks.admin"}, resp.Data.Privileges)
+
+	assert.Len(t, resp.Data.Capabilities, len(userCapabilities))
+	assert.True(t, resp.Data.Capabilities["canViewFlows"])
+	assert.True(t, resp.Data.Capabilities["canCreateFlow"])
+	assert.True(t, resp.Data.Capabilities["canViewTasks"])
+	assert.False(t, resp.Data.Capabilities["canViewAllFlows"])
+	assert.False(t, resp.Data.Capabilities["canDeleteFlow"])
+	assert.False(t, resp.Data.Capabilities["canViewSubtasks"])
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Set("rid", uint64(99))
+	c.Request, _ = http.NewRequest("GET", "/me/permissions", nil)
+	service.GetCurrentUserPermissions(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
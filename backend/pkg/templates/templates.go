@@ -23,6 +23,8 @@ var graphitiTemplates embed.FS
 
 var ErrTemplateNotFound = errors.New("template not found")
 
+var ErrInvalidToolCallIDTemplate = errors.New("invalid tool call ID template")
+
 type PromptType string
 
 const (
@@ -264,6 +266,7 @@ var PromptVariables = map[PromptType][]string{
 		"Task",
 		"Tasks",
 		"Subtasks",
+		"Attachments",
 	},
 	PromptTypeRefiner: {
 		"SubtaskPatchToolName",
@@ -367,6 +370,7 @@ var PromptVariables = map[PromptType][]string{
 		"CompletedSubtasks",
 		"Subtask",
 		"PlannedSubtasks",
+		"Attachments",
 	},
 	PromptTypeShortExecutionContext: {
 		"Task",
@@ -374,6 +378,7 @@ var PromptVariables = map[PromptType][]string{
 		"CompletedSubtasks",
 		"Subtask",
 		"PlannedSubtasks",
+		"Attachments",
 	},
 	PromptTypeImageChooser: {
 		"DefaultImage",
@@ -609,6 +614,56 @@ func (fp *flowPrompter) DumpTemplates() ([]byte, error) {
 	return blob, nil
 }
 
+type overridePrompter struct {
+	base      Prompter
+	overrides PromptsMap
+}
+
+// NewOverridePrompter returns the prompter which uses the overridden templates instead of the base ones,
+// the rest of the prompt types are taken from the base prompter
+func NewOverridePrompter(base Prompter, overrides PromptsMap) Prompter {
+	return &overridePrompter{base: base, overrides: overrides}
+}
+
+func (op *overridePrompter) GetTemplate(promptType PromptType) (string, error) {
+	if prompt, ok := op.overrides[promptType]; ok {
+		return prompt, nil
+	}
+
+	return op.base.GetTemplate(promptType)
+}
+
+func (op *overridePrompter) RenderTemplate(promptType PromptType, params any) (string, error) {
+	prompt, err := op.GetTemplate(promptType)
+	if err != nil {
+		return "", err
+	}
+
+	return RenderPrompt(string(promptType), prompt, params)
+}
+
+func (op *overridePrompter) DumpTemplates() ([]byte, error) {
+	blob, err := op.base.DumpTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	prompts := make(PromptsMap)
+	if err := json.Unmarshal(blob, &prompts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal templates: %w", err)
+	}
+	for promptType, prompt := range op.overrides {
+		prompts[promptType] = prompt
+	}
+
+	blob, err = json.Marshal(prompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal templates: %w", err)
+	}
+
+	return blob, nil
+}
+
 type defaultPrompter struct {
 }
 
@@ -965,6 +1020,45 @@ func ValidatePattern(pattern string, samples []PatternSample) error {
 	return nil
 }
 
+// samplePatternFunction is the function name which is used to render sample values of the pattern
+const samplePatternFunction = "get_number"
+
+// SampleFromPattern renders sample value of the pattern template to show it in messages
+// Example: "{f}:{r:1:d}" → "get_number:7"
+func SampleFromPattern(pattern string) string {
+	return GenerateFromPattern(pattern, samplePatternFunction)
+}
+
+// ValidateToolCallIDTemplate checks that the pattern template generates IDs which can be used
+// to match tool calls with their results, so it must contain at least one random part
+// and must not contain malformed placeholders which are silently rendered as literal text
+func ValidateToolCallIDTemplate(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("%w: template is empty", ErrInvalidToolCallIDTemplate)
+	}
+
+	hasRandom := false
+	for _, part := range parsePattern(pattern) {
+		switch {
+		case part.isRandom && part.length == 0:
+			return fmt.Errorf("%w: template '%s' contains random part of zero length, it generates '%s'",
+				ErrInvalidToolCallIDTemplate, pattern, SampleFromPattern(pattern))
+		case part.isRandom:
+			hasRandom = true
+		case !part.isFunction && strings.ContainsAny(part.literal, "{}"):
+			return fmt.Errorf("%w: template '%s' contains unknown placeholder in '%s', it generates '%s'",
+				ErrInvalidToolCallIDTemplate, pattern, part.literal, SampleFromPattern(pattern))
+		}
+	}
+
+	if !hasRandom {
+		return fmt.Errorf("%w: template '%s' doesn't contain random placeholder {r:LENGTH:CHARSET}, "+
+			"it generates the same '%s' for all calls", ErrInvalidToolCallIDTemplate, pattern, SampleFromPattern(pattern))
+	}
+
+	return nil
+}
+
 // buildCharClass builds a regex character class from a charset string
 func buildCharClass(charset string) string {
 	// Optimize for common charsets
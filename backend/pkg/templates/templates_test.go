@@ -1,6 +1,7 @@
 package templates_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -849,6 +850,58 @@ func TestPatternEdgeCases(t *testing.T) {
 }
 
 // TestQuestionExecutionMonitorPrompt tests the question_execution_monitor template
+func TestValidateToolCallIDTemplate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pattern  string
+		wantErr  bool
+		contains string
+	}{
+		{name: "anthropic", pattern: "toolu_{r:24:b}"},
+		{name: "openai", pattern: "call_{r:24:x}"},
+		{name: "glm", pattern: "call_-{r:19:d}"},
+		{name: "kimi_with_function", pattern: "{f}:{r:1:d}"},
+		{name: "empty", pattern: "", wantErr: true, contains: "empty"},
+		{name: "whitespace", pattern: "  ", wantErr: true, contains: "empty"},
+		{name: "only_literal", pattern: "call_fixed", wantErr: true, contains: "generates the same 'call_fixed'"},
+		{name: "only_function", pattern: "{f}", wantErr: true, contains: "generates the same 'get_number'"},
+		{name: "zero_length", pattern: "call_{r:0:x}", wantErr: true, contains: "zero length"},
+		{name: "unknown_charset", pattern: "call_{r:24:z}", wantErr: true, contains: "unknown placeholder in 'call_{r:24:z}'"},
+		{name: "unclosed_placeholder", pattern: "call_{r:24:x}_{r:4", wantErr: true, contains: "unknown placeholder in '_{r:4'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := templates.ValidateToolCallIDTemplate(tc.pattern)
+			if !tc.wantErr {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Expected error for pattern '%s'", tc.pattern)
+			}
+			if !errors.Is(err, templates.ErrInvalidToolCallIDTemplate) {
+				t.Errorf("Expected ErrInvalidToolCallIDTemplate, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Errorf("Expected error to contain '%s', got: %v", tc.contains, err)
+			}
+		})
+	}
+}
+
+func TestSampleFromPattern(t *testing.T) {
+	sample := templates.SampleFromPattern("{f}:{r:2:d}")
+	if err := templates.ValidatePattern("{f}:{r:2:d}", []templates.PatternSample{
+		{Value: sample, FunctionName: "get_number"},
+	}); err != nil {
+		t.Errorf("Sample '%s' doesn't match its pattern: %v", sample, err)
+	}
+}
+
 func TestQuestionExecutionMonitorPrompt(t *testing.T) {
 	defaultPrompts, err := templates.GetDefaultPrompts()
 	if err != nil {
@@ -1092,3 +1145,38 @@ func TestTaskAssignmentWrapperPrompt(t *testing.T) {
 		t.Error("Rendered template missing closing hint tag")
 	}
 }
+
+// TestOverridePrompter checks that overridden templates replace the base ones and the rest are kept
+func TestOverridePrompter(t *testing.T) {
+	base := templates.NewDefaultPrompter()
+	prompter := templates.NewOverridePrompter(base, templates.PromptsMap{
+		templates.PromptTypePrimaryAgent: "Custom instructions in {{.Lang}} for {{.DockerImage}}",
+	})
+
+	rendered, err := prompter.RenderTemplate(templates.PromptTypePrimaryAgent, map[string]any{
+		"Lang":        "English",
+		"DockerImage": "kalilinux/kali-rolling",
+	})
+	if err != nil {
+		t.Fatalf("Failed to render overridden template: %v", err)
+	}
+	if rendered != "Custom instructions in English for kalilinux/kali-rolling" {
+		t.Errorf("Unexpected rendered template: %q", rendered)
+	}
+
+	expected, err := base.GetTemplate(templates.PromptTypeAssistant)
+	if err != nil {
+		t.Fatalf("Failed to get base template: %v", err)
+	}
+	if actual, err := prompter.GetTemplate(templates.PromptTypeAssistant); err != nil || actual != expected {
+		t.Errorf("Expected base template for not overridden prompt type, got error: %v", err)
+	}
+
+	blob, err := prompter.DumpTemplates()
+	if err != nil {
+		t.Fatalf("Failed to dump templates: %v", err)
+	}
+	if !strings.Contains(string(blob), "Custom instructions in") {
+		t.Error("Expected dumped templates to contain the overridden template")
+	}
+}
@@ -231,6 +231,19 @@ func CreateDummyTemplateData() map[string]any {
 			},
 		},
 
+		"Attachments": []database.FlowAttachment{
+			{
+				ID:          1,
+				Name:        "scope.txt",
+				Size:        128,
+				ContentType: "text/plain",
+				Checksum:    "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+				FlowID:      100,
+				UserID:      1,
+				CreatedAt:   currentTime,
+			},
+		},
+
 		"MsgLogs": []database.Msglog{
 			{
 				ID:           1,
@@ -20,6 +20,61 @@ type FileAction struct {
 	Message string     `json:"message" jsonschema:"required,title=File action message" jsonschema_description:"Not so long message which explain what do you want to read or to write to the file and explain written content to send to the user in user's language only"`
 }
 
+type AttachmentsActionType string
+
+const (
+	ListAttachments AttachmentsActionType = "list"
+	ReadAttachment  AttachmentsActionType = "read"
+)
+
+type AttachmentsAction struct {
+	Action  AttachmentsActionType `json:"action" jsonschema:"required,enum=list,enum=read" jsonschema_description:"Action to perform with the files attached by the user to the flow. 'list' - Returns ids, names, sizes, content types and paths in the container of all attachments. 'read' - Returns the text content of the attachment by id"`
+	ID      Int64                 `json:"id" jsonschema:"type=integer" jsonschema_description:"ID of the attachment to read, it's required for the 'read' action"`
+	Offset  Int64                 `json:"offset" jsonschema:"type=integer" jsonschema_description:"Offset in bytes to read the large attachment by parts, the next offset is returned with the truncated content (default 0)"`
+	Message string                `json:"message" jsonschema:"required,title=Attachments action message" jsonschema_description:"Not so long message which explain what do you want to find in the attachments to send to the user in user's language only"`
+}
+
+type ArtifactsActionType string
+
+const (
+	PutArtifact   ArtifactsActionType = "put"
+	GetArtifact   ArtifactsActionType = "get"
+	ListArtifacts ArtifactsActionType = "list"
+)
+
+type ArtifactsAction struct {
+	Action      ArtifactsActionType `json:"action" jsonschema:"required,enum=put,enum=get,enum=list" jsonschema_description:"Action to perform with the flow artifacts. 'put' - Saves the content or the file from the container by the name, the existing artifact with the same name is overwritten. 'get' - Returns the text content of the artifact by the name or copies it to the path in the container. 'list' - Returns names, sizes, content types and references of all artifacts"`
+	Name        string              `json:"name" jsonschema_description:"Name of the artifact like 'nmap-10.0.0.5.xml' or 'exploit.py', it's required for the 'put' and 'get' actions"`
+	Content     string              `json:"content" jsonschema_description:"Text content of the artifact to save by the 'put' action, use the path to save the binary or large files"`
+	Path        string              `json:"path" jsonschema_description:"Absolute path of the file in the container, the 'put' action saves the file by the path and the 'get' action writes the artifact to the path"`
+	ContentType string              `json:"content_type" jsonschema_description:"MIME type of the artifact for the 'put' action, it's detected by the name and the content if empty"`
+	Offset      Int64               `json:"offset" jsonschema:"type=integer" jsonschema_description:"Offset in bytes to read the large artifact by parts, the next offset is returned with the truncated content (default 0)"`
+	Message     string              `json:"message" jsonschema:"required,title=Artifacts action message" jsonschema_description:"Not so long message which explain what do you want to save or to get from the artifacts to send to the user in user's language only"`
+}
+
+type OCRAction struct {
+	Artifact string `json:"artifact" jsonschema:"required" jsonschema_description:"Name, id or download reference of the image artifact to recognize, e.g. the screenshot saved by the 'headless_browser' tool or the image saved by the 'artifacts' tool"`
+	Message  string `json:"message" jsonschema:"required,title=OCR message" jsonschema_description:"Not so long message which explain what text do you want to read from the image and why to send to the user in user's language only"`
+}
+
+type FlowMemoryActionType string
+
+const (
+	StoreFlowMemory    FlowMemoryActionType = "store"
+	RetrieveFlowMemory FlowMemoryActionType = "retrieve"
+	SearchFlowMemory   FlowMemoryActionType = "search"
+)
+
+type FlowMemoryAction struct {
+	Action  FlowMemoryActionType `json:"action" jsonschema:"required,enum=store,enum=retrieve,enum=search" jsonschema_description:"Action to perform with the flow memory. 'store' - Saves the fact by the key, the existing fact with the same key is overwritten. 'retrieve' - Returns the fact by the key or the latest facts filtered by the tags if the key is empty. 'search' - Returns the facts which contain the query in the key, value or tags"`
+	Key     string               `json:"key" jsonschema_description:"Unique key of the fact inside the flow like 'target.10.0.0.5.open_ports' or 'creds.ssh.admin', it's required for the 'store' action"`
+	Value   string               `json:"value" jsonschema_description:"Text of the fact to store, it's required for the 'store' action"`
+	Tags    []string             `json:"tags,omitempty" jsonschema_description:"Tags of the fact to store or to filter the facts by for the 'retrieve' and 'search' actions, all listed tags must be present"`
+	Query   string               `json:"query" jsonschema_description:"Keyword or phrase to find in the key, value and tags of the facts, it's required for the 'search' action, the facts with the closest meaning are returned first if the semantic search is enabled"`
+	Limit   Int64                `json:"limit,omitempty" jsonschema:"type=integer" jsonschema_description:"Maximum number of facts to return for the 'retrieve' and 'search' actions (default 10 for the semantic search and 50 otherwise, max 50)"`
+	Message string               `json:"message" jsonschema:"required,title=Flow memory action message" jsonschema_description:"Not so long message which explain what do you want to store or to find in the flow memory to send to the user in user's language only"`
+}
+
 type BrowserAction string
 
 const (
@@ -34,6 +89,23 @@ type Browser struct {
 	Message string        `json:"message" jsonschema:"required,title=Task result message" jsonschema_description:"Not so long message which explain what do you want to get, what format do you want to get and why do you need this to send to the user in user's language only"`
 }
 
+type HeadlessBrowserActionType string
+
+const (
+	HeadlessNavigate   HeadlessBrowserActionType = "navigate"
+	HeadlessClick      HeadlessBrowserActionType = "click"
+	HeadlessExtract    HeadlessBrowserActionType = "extract"
+	HeadlessScreenshot HeadlessBrowserActionType = "screenshot"
+)
+
+type HeadlessBrowserAction struct {
+	Action   HeadlessBrowserActionType `json:"action" jsonschema:"required,enum=navigate,enum=click,enum=extract,enum=screenshot" jsonschema_description:"Action to perform in the headless browser after the page is opened and rendered. 'navigate' - Returns the rendered text and the links of the page. 'click' - Clicks the element by the selector and returns the text and the links of the resulting page. 'extract' - Returns the text and the links of the elements by the selector or of the whole page. 'screenshot' - Saves the screenshot of the page to the flow artifacts and returns its reference"`
+	URL      string                    `json:"url" jsonschema:"required" jsonschema_description:"URL of the page to open (e.g. 'https://example.com/app/#/login'), https is used if the scheme is omitted; every call opens the page in a new clean browser session"`
+	Selector string                    `json:"selector,omitempty" jsonschema_description:"CSS selector of the element to click for the 'click' action (required) or of the elements to extract for the 'extract' action (e.g. '#login', 'form button[type=submit]', 'table.results')"`
+	WaitMs   *Int64                    `json:"wait_ms,omitempty" jsonschema:"type=integer" jsonschema_description:"Time in milliseconds to wait for the scripts of the page after it's loaded and after the click (minimum 0; maximum 10000; default 1000)"`
+	Message  string                    `json:"message" jsonschema:"required,title=Headless browser message" jsonschema_description:"Not so long message which explain what do you want to get from the page and why do you need this to send to the user in user's language only"`
+}
+
 type SubtaskInfo struct {
 	Title       string `json:"title" jsonschema:"required,title=Subtask title" jsonschema_description:"Subtask title to show to the user which contains main goal of work result by this subtask"`
 	Description string `json:"description" jsonschema:"required,title=Subtask to complete" jsonschema_description:"Detailed description and instructions and rules and requirements what have to do in the subtask"`
@@ -121,14 +193,118 @@ type SearchResult struct {
 	Message string `json:"message" jsonschema:"required,title=Search result message" jsonschema_description:"Not so long message with the result and short answer to send to the user in user's language only"`
 }
 
-type SploitusAction struct {
-	Query       string `json:"query" jsonschema:"required" jsonschema_description:"Search query for Sploitus (e.g. 'ssh', 'apache 2.4', 'CVE-2021-44228'). Short and precise queries return the best results."`
-	ExploitType string `json:"exploit_type,omitempty" jsonschema:"enum=exploits,enum=tools" jsonschema_description:"What to search for: 'exploits' (default) for exploit code and PoCs, 'tools' for offensive security tools"`
-	Sort        string `json:"sort,omitempty" jsonschema:"enum=default,enum=date,enum=score" jsonschema_description:"Result ordering: 'default' (relevance), 'date' (newest first), 'score' (highest CVSS first)"`
-	MaxResults  Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of results to return (minimum 1; maximum 25; default 10)"`
+type TavilyAction struct {
+	Query       string `json:"query" jsonschema:"required" jsonschema_description:"Query to search in the tavily search engine. Short and exact query is much better for better search result in English"`
+	SearchDepth string `json:"search_depth,omitempty" jsonschema:"enum=basic,enum=advanced" jsonschema_description:"Search depth: 'advanced' (default) for the most relevant content from the web pages, 'basic' for the faster and cheaper search"`
+	MaxResults  Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of results to return (minimum 1; maximum 10; default 5)"`
 	Message     string `json:"message" jsonschema:"required,title=Search query message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
 }
 
+type SearxngAction struct {
+	Query      string `json:"query" jsonschema:"required" jsonschema_description:"Query to search in the searxng meta search engine. Short and exact query is much better for better search result in English"`
+	Category   string `json:"category,omitempty" jsonschema:"enum=general,enum=it,enum=science" jsonschema_description:"Search category: 'general' for web search, 'it' for software, repositories and IT Q&A sites, 'science' for papers and scientific publications; default is taken from the server settings"`
+	MaxResults Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of results to return (minimum 1; maximum 10; default 10)"`
+	Message    string `json:"message" jsonschema:"required,title=Search query message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type SploitusAction struct {
+	Query        string `json:"query" jsonschema:"required" jsonschema_description:"Search query for Sploitus (e.g. 'ssh', 'apache 2.4', 'CVE-2021-44228'). Short and precise queries return the best results."`
+	ExploitType  string `json:"exploit_type,omitempty" jsonschema:"enum=exploits,enum=tools,enum=all" jsonschema_description:"What to search for: 'exploits' (default) for exploit code and PoCs, 'tools' for offensive security tools, 'all' for both in separate sections"`
+	Sort         string `json:"sort,omitempty" jsonschema:"enum=default,enum=date,enum=score,enum=epss" jsonschema_description:"Result ordering: 'default' (relevance), 'date' (newest first), 'score' (highest CVSS first), 'epss' (highest EPSS exploitation probability of exploit CVEs first, requires EPSS enrichment)"`
+	Language     string `json:"language,omitempty" jsonschema_description:"Keep only exploits written in this language (e.g. 'python', 'ruby', 'bash'), case-insensitive; results without language are excluded when set"`
+	Platform     string `json:"platform,omitempty" jsonschema_description:"Keep only results published on this source platform (e.g. 'githubexploit', 'exploitdb', 'packetstorm'), case-insensitive; results without source are excluded when set"`
+	MaxResults   Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of results to return (minimum 1; maximum 25; default 10)"`
+	Continuation string `json:"continuation,omitempty" jsonschema_description:"Token of the next page from the previous result of the same search, all other arguments must be unchanged; not supported for 'all' exploit type"`
+	Message      string `json:"message" jsonschema:"required,title=Search query message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type GithubAction struct {
+	Query      string `json:"query" jsonschema:"required" jsonschema_description:"Search query for GitHub in GitHub search syntax (e.g. 'CVE-2021-44228', 'log4shell poc', 'apache struts exploit in:name,description'). Short and precise queries return the best results."`
+	SearchType string `json:"search_type,omitempty" jsonschema:"enum=repositories,enum=code" jsonschema_description:"What to search for: 'repositories' (default) to find PoC and exploit repositories, 'code' to find specific files with exploit code"`
+	Language   string `json:"language,omitempty" jsonschema_description:"Filter results by programming language (e.g. 'python', 'go', 'c', 'ruby'); empty means any language"`
+	Sort       string `json:"sort,omitempty" jsonschema:"enum=best-match,enum=stars,enum=updated" jsonschema_description:"Result ordering for repositories: 'best-match' (default), 'stars' (most starred first), 'updated' (recently updated first); code search always uses best-match"`
+	MaxResults Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of results to return (minimum 1; maximum 30; default 10)"`
+	Message    string `json:"message" jsonschema:"required,title=Search query message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type ExploitDBAction struct {
+	Query      string `json:"query" jsonschema:"required" jsonschema_description:"Search terms for the offline ExploitDB index matched against exploit titles, CVE and other codes (e.g. 'apache 2.4 rce', 'CVE-2021-41773', 'wordpress plugin sqli'). All terms must be present in the record, so use short queries with few terms."`
+	MaxResults Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of results to return (minimum 1; maximum 25; default 10)"`
+	Message    string `json:"message" jsonschema:"required,title=Search query message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type ExploitSearchAction struct {
+	Query      string `json:"query" jsonschema:"required" jsonschema_description:"Search query for public exploits and PoCs (e.g. 'CVE-2021-44228', 'apache 2.4.49', 'vsftpd backdoor'), it's sent to all configured exploit sources, so short and precise queries return the best results."`
+	MaxResults Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of distinct results to return from all sources together (minimum 1; maximum 30; default 15)"`
+	Message    string `json:"message" jsonschema:"required,title=Search query message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type VirusTotalAction struct {
+	Resource string `json:"resource" jsonschema:"required" jsonschema_description:"MD5, SHA-1 or SHA-256 hash of the file (e.g. from 'sha256sum' output) or full http(s) URL to check in VirusTotal"`
+	Message  string `json:"message" jsonschema:"required,title=Reputation check message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type WaybackAction struct {
+	URL        string `json:"url" jsonschema:"required" jsonschema_description:"URL or domain to get the capture history for (e.g. 'example.com', 'https://example.com/admin/'), wildcards are not supported, use match_type instead"`
+	MatchType  string `json:"match_type,omitempty" jsonschema:"enum=exact,enum=prefix,enum=host,enum=domain" jsonschema_description:"Which URLs to match: 'exact' (default) only the given URL, 'prefix' all URLs under the given path, 'host' all URLs of the host, 'domain' all URLs of the domain and its subdomains"`
+	From       string `json:"from,omitempty" jsonschema_description:"Start of the date range in form of YYYYMMDDhhmmss, any prefix of 4 or more digits is allowed (e.g. '2019', '201906'); empty means from the first capture"`
+	To         string `json:"to,omitempty" jsonschema_description:"End of the date range in form of YYYYMMDDhhmmss, any prefix of 4 or more digits is allowed (e.g. '2021', '20211231'); empty means up to the last capture"`
+	MaxResults Int64  `json:"max_results" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of timeline entries to return after collapsing near-identical captures (minimum 1; maximum 500; default 50)"`
+	Message    string `json:"message" jsonschema:"required,title=URL history message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type WhoisAction struct {
+	Target  string `json:"target" jsonschema:"required" jsonschema_description:"Domain name or IPv4/IPv6 address to lookup (e.g. 'example.com', '93.184.216.34'); URLs and subdomains are reduced to the registrable domain"`
+	Message string `json:"message" jsonschema:"required,title=WHOIS lookup message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type EPSSAction struct {
+	CVEs    []string `json:"cves" jsonschema:"required,minItems=1,maxItems=200" jsonschema_description:"List of CVE IDs to get EPSS scores for (e.g. ['CVE-2021-44228', 'CVE-2023-4966']), all of them are looked up in one request"`
+	Message string   `json:"message" jsonschema:"required,title=EPSS lookup message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type RobotsAction struct {
+	URL     string `json:"url" jsonschema:"required" jsonschema_description:"Base URL or host of the target site (e.g. 'example.com', 'https://example.com:8443'), path is ignored because robots.txt is defined only for the site root; https is used if the scheme is omitted"`
+	MaxURLs Int64  `json:"max_urls" jsonschema:"required,type=integer" jsonschema_description:"Maximum number of URLs from sitemaps to list (minimum 1; maximum 1000; default 100)"`
+	Message string `json:"message" jsonschema:"required,title=Robots discovery message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type HTTPHeadersAction struct {
+	URL             string `json:"url" jsonschema:"required" jsonschema_description:"Target URL to send the request to (e.g. 'https://example.com/login'), https is used if the scheme is omitted"`
+	Method          string `json:"method,omitempty" jsonschema:"enum=GET,enum=HEAD,enum=POST,enum=PUT,enum=DELETE,enum=PATCH,enum=OPTIONS,enum=TRACE" jsonschema_description:"HTTP method of the request without body (default GET); use OPTIONS to check allowed methods and CORS"`
+	FollowRedirects Bool   `json:"follow_redirects" jsonschema:"required,type=boolean" jsonschema_description:"True to follow redirects and analyze headers of the final response, false to analyze the first response as is"`
+	MaxRedirects    *Int64 `json:"max_redirects,omitempty" jsonschema:"type=integer" jsonschema_description:"Maximum number of redirects to follow if follow_redirects is true (minimum 1; maximum 10; default 5)"`
+	Message         string `json:"message" jsonschema:"required,title=HTTP headers analysis message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type TLSInspectAction struct {
+	Target     string `json:"target" jsonschema:"required" jsonschema_description:"Host with optional port or URL of the TLS service (e.g. 'example.com', '10.0.0.5:8443', 'https://example.com/login'); port 443 is used if it's omitted"`
+	ServerName string `json:"server_name,omitempty" jsonschema_description:"Server name to send in SNI and to verify the certificate against, use it for virtual hosts when the target is an IP address; defaults to the target host"`
+	Message    string `json:"message" jsonschema:"required,title=TLS inspection message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type PortScanAction struct {
+	Target       string `json:"target" jsonschema:"required" jsonschema_description:"Host name or IP address to scan (e.g. '10.0.0.5', 'app.example.com'), the host of the URL is used if URL is passed"`
+	Ports        string `json:"ports,omitempty" jsonschema_description:"Comma separated ports and ranges to scan (e.g. '22,80,443,8000-8100'); the most common ports are scanned if it's empty"`
+	Concurrency  *Int64 `json:"concurrency,omitempty" jsonschema:"type=integer" jsonschema_description:"Number of ports scanned in parallel (minimum 1; default 50), it's capped by the server limit"`
+	TimeoutMs    *Int64 `json:"timeout_ms,omitempty" jsonschema:"type=integer" jsonschema_description:"Connection timeout of a single port in milliseconds (minimum 100; maximum 10000; default 1000)"`
+	Continuation string `json:"continuation,omitempty" jsonschema_description:"Token of the next page from the previous result of the same scan, all other arguments must be unchanged; the target isn't scanned again for the next page"`
+	Message      string `json:"message" jsonschema:"required,title=Port scan message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
+type HTTPFuzzAction struct {
+	URL           string   `json:"url" jsonschema:"required" jsonschema_description:"Base URL to discover the content under (e.g. 'https://example.com/app/'), words are appended to its path; put the FUZZ keyword to place words in the other part of the URL (e.g. 'https://example.com/api/FUZZ.json'); https is used if the scheme is omitted"`
+	Words         []string `json:"words,omitempty" jsonschema_description:"Wordlist of the paths to request (e.g. ['admin', 'backup', '.git/HEAD']); the built-in list of the common paths is used if it's empty"`
+	Extensions    []string `json:"extensions,omitempty" jsonschema_description:"File extensions to try with every word in addition to the word itself (e.g. ['.php', '.bak'])"`
+	Method        string   `json:"method,omitempty" jsonschema:"enum=GET,enum=HEAD" jsonschema_description:"HTTP method of the requests (default GET); HEAD is faster but some servers answer it differently"`
+	ExcludeStatus string   `json:"exclude_status,omitempty" jsonschema_description:"Comma separated status codes to hide (e.g. '403,500'); 404 and the responses which look like the not found page of the site are always hidden"`
+	Concurrency   *Int64   `json:"concurrency,omitempty" jsonschema:"type=integer" jsonschema_description:"Number of parallel requests (minimum 1; default 10), it's capped by the server limit"`
+	Rate          *Int64   `json:"rate,omitempty" jsonschema:"type=integer" jsonschema_description:"Maximum requests per second to the host (minimum 1; default 20), it's capped by the server limit"`
+	MaxRequests   *Int64   `json:"max_requests,omitempty" jsonschema:"type=integer" jsonschema_description:"Maximum number of requests of the run (minimum 1), it's capped by the server limit which is also the default"`
+	Continuation  string   `json:"continuation,omitempty" jsonschema_description:"Token of the next page from the previous result of the same run, all other arguments must be unchanged; the requests aren't sent again for the next page"`
+	Message       string   `json:"message" jsonschema:"required,title=HTTP content discovery message" jsonschema_description:"Not so long message with the expected result and path to reach goal to send to the user in user's language only"`
+}
+
 type GraphitiSearchAction struct {
 	SearchType     string   `json:"search_type" jsonschema:"required,enum=temporal_window,enum=entity_relationships,enum=diverse_results,enum=episode_context,enum=successful_tools,enum=recent_context,enum=entity_by_label" jsonschema_description:"Type of search to perform: temporal_window (time-bounded search), entity_relationships (graph traversal from an entity), diverse_results (anti-redundancy search), episode_context (full agent reasoning and tool outputs), successful_tools (proven techniques), recent_context (latest findings), entity_by_label (type-specific entity search)"`
 	Query          string   `json:"query" jsonschema:"required" jsonschema_description:"Natural language query describing what to search for in English"`
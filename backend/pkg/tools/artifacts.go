@@ -0,0 +1,402 @@
+package tools
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	artifactsDirName           = "artifacts"
+	artifactMaxNameLength      = 255
+	artifactSniffLength        = 512
+	artifactDefaultContentType = "application/octet-stream"
+)
+
+// FlowArtifactsDir returns the storage directory of the flow artifacts on the server side
+func FlowArtifactsDir(cfg *config.Config, flowID int64) string {
+	dir := cfg.ArtifactsDir
+	if dir == "" {
+		dir = filepath.Join(cfg.DataDir, artifactsDirName)
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("flow-%d", flowID))
+}
+
+// FlowArtifactPath returns the path of the stored artifact content, it's named by the id
+// because the original name is controlled by the agent
+func FlowArtifactPath(cfg *config.Config, flowID, artifactID int64) string {
+	return filepath.Join(FlowArtifactsDir(cfg, flowID), strconv.FormatInt(artifactID, 10))
+}
+
+// FlowArtifactURL returns the API path to download the artifact content
+func FlowArtifactURL(flowID, artifactID int64) string {
+	return fmt.Sprintf("/api/v1/flows/%d/artifacts/%d/file", flowID, artifactID)
+}
+
+// artifacts keeps the outputs of the agents in the persistent storage which outlives the flow containers
+type artifacts struct {
+	flowID       int64
+	taskID       *int64
+	subtaskID    *int64
+	cfg          *config.Config
+	db           database.Querier
+	dockerClient docker.DockerClient
+}
+
+// NewArtifactsTool creates a new tool to save and read the flow artifacts, the docker client is optional
+// and it's used to exchange the artifacts with the files of the flow primary container
+func NewArtifactsTool(
+	flowID int64,
+	taskID, subtaskID *int64,
+	cfg *config.Config,
+	db database.Querier,
+	dockerClient docker.DockerClient,
+) Tool {
+	return &artifacts{
+		flowID:       flowID,
+		taskID:       taskID,
+		subtaskID:    subtaskID,
+		cfg:          cfg,
+		db:           db,
+		dockerClient: dockerClient,
+	}
+}
+
+func (a *artifacts) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !a.IsAvailable() {
+		return "", fmt.Errorf("artifacts are not available")
+	}
+
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(a.flowID, a.taskID, a.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	var action ArtifactsAction
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal artifacts action")
+		return "", fmt.Errorf("failed to unmarshal artifacts action: %w", err)
+	}
+
+	switch action.Action {
+	case PutArtifact:
+		result, err := a.put(ctx, action)
+		if err != nil {
+			logger.WithError(err).Error("failed to put flow artifact")
+			return fmt.Sprintf("failed to save artifact '%s': %v", action.Name, err), nil
+		}
+		return result, nil
+	case GetArtifact:
+		artifact, err := a.db.GetFlowArtifact(ctx, database.GetFlowArtifactParams{
+			FlowID: a.flowID,
+			Name:   action.Name,
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to get flow artifact")
+			return fmt.Sprintf("artifact '%s' not found, use the '%s' action to get the list of artifacts",
+				action.Name, ListArtifacts), nil
+		}
+		var result string
+		if action.Path != "" {
+			result, err = a.copyToContainer(ctx, artifact, action.Path)
+		} else {
+			result, err = a.read(artifact, action.Offset.Int64())
+		}
+		if err != nil {
+			logger.WithError(err).Error("failed to get flow artifact content")
+			return fmt.Sprintf("failed to get artifact '%s': %v", artifact.Name, err), nil
+		}
+		return result, nil
+	case ListArtifacts:
+		items, err := a.db.GetFlowArtifacts(ctx, a.flowID)
+		if err != nil {
+			logger.WithError(err).Error("failed to get flow artifacts")
+			return "", fmt.Errorf("failed to get flow artifacts: %w", err)
+		}
+		return formatArtifactsList(items), nil
+	default:
+		logger.Error("unknown artifacts action")
+		return "", fmt.Errorf("unknown artifacts action: %s", action.Action)
+	}
+}
+
+// put saves the artifact from the tool call content or from the file in the container
+func (a *artifacts) put(ctx context.Context, action ArtifactsAction) (string, error) {
+	name, err := artifactName(action.Name)
+	if err != nil {
+		return "", err
+	}
+
+	var artifact database.FlowArtifact
+	switch {
+	case action.Path != "" && action.Content != "":
+		return "", fmt.Errorf("only one of the content and the path must be set")
+	case action.Path != "":
+		artifact, err = a.putFromContainer(ctx, name, action.ContentType, action.Path)
+	default:
+		artifact, err = a.store(ctx, name, action.ContentType, strings.NewReader(action.Content))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("artifact '%s' (%s, %d bytes, sha256 %s) is saved with id %d, "+
+		"get it by the name or download it from %s", artifact.Name, artifact.ContentType, artifact.Size,
+		artifact.Checksum, artifact.ID, FlowArtifactURL(a.flowID, artifact.ID)), nil
+}
+
+func (a *artifacts) putFromContainer(
+	ctx context.Context,
+	name, contentType, containerPath string,
+) (database.FlowArtifact, error) {
+	if a.dockerClient == nil {
+		return database.FlowArtifact{}, fmt.Errorf("container files are not available, put the content instead")
+	}
+
+	reader, _, err := a.dockerClient.CopyFromContainer(ctx, PrimaryTerminalName(a.flowID), containerPath)
+	if err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to copy file from container: %w", err)
+	}
+	defer reader.Close()
+
+	tarReader := tar.NewReader(reader)
+	header, err := tarReader.Next()
+	if err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to read tar header: %w", err)
+	}
+	if header.Typeflag != tar.TypeReg {
+		return database.FlowArtifact{}, fmt.Errorf("'%s' is not a regular file", containerPath)
+	}
+	if header.Size > a.cfg.ArtifactsMaxSize {
+		return database.FlowArtifact{}, fmt.Errorf("file size %d exceeds the artifact limit of %d bytes",
+			header.Size, a.cfg.ArtifactsMaxSize)
+	}
+
+	return a.store(ctx, name, contentType, tarReader)
+}
+
+// store writes the content to the temporary file which gets the name by the artifact id after the upsert,
+// so the overwritten artifact is replaced at once and the failed one keeps the previous content
+func (a *artifacts) store(ctx context.Context, name, contentType string, r io.Reader) (database.FlowArtifact, error) {
+	dir := FlowArtifactsDir(a.cfg, a.flowID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".artifact-*")
+	if err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	buffered := bufio.NewReaderSize(io.LimitReader(r, a.cfg.ArtifactsMaxSize+1), artifactSniffLength)
+	head, _ := buffered.Peek(artifactSniffLength)
+	contentType = artifactContentType(name, contentType, head)
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), buffered)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to write artifact file: %w", err)
+	}
+	if size > a.cfg.ArtifactsMaxSize {
+		return database.FlowArtifact{}, fmt.Errorf("artifact exceeds the limit of %d bytes", a.cfg.ArtifactsMaxSize)
+	}
+
+	artifact, err := a.db.UpsertFlowArtifact(ctx, database.UpsertFlowArtifactParams{
+		Name:        name,
+		Size:        size,
+		ContentType: contentType,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		FlowID:      a.flowID,
+		TaskID:      database.Int64ToNullInt64(a.taskID),
+		SubtaskID:   database.Int64ToNullInt64(a.subtaskID),
+	})
+	if err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to save artifact: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), FlowArtifactPath(a.cfg, a.flowID, artifact.ID)); err != nil {
+		return database.FlowArtifact{}, fmt.Errorf("failed to store artifact file: %w", err)
+	}
+
+	return artifact, nil
+}
+
+// read returns the text chunk of the artifact from the offset, binary artifacts have to be copied
+// to the container to be processed by the terminal tools
+func (a *artifacts) read(artifact database.FlowArtifact, offset int64) (string, error) {
+	file, err := os.Open(FlowArtifactPath(a.cfg, a.flowID, artifact.ID))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if offset < 0 || offset > artifact.Size {
+		return fmt.Sprintf("offset %d is out of the artifact size %d bytes", offset, artifact.Size), nil
+	}
+
+	buf := make([]byte, attachmentReadChunkSize)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	chunk := trimIncompleteRune(buf[:n])
+
+	if !isTextContent(chunk) {
+		return fmt.Sprintf("artifact '%s' (%s, %d bytes) is a binary file, "+
+			"copy it to the container by the path to process it in the terminal", artifact.Name,
+			artifact.ContentType, artifact.Size), nil
+	}
+
+	var sb strings.Builder
+	next := offset + int64(len(chunk))
+	sb.WriteString(fmt.Sprintf("artifact '%s' bytes %d-%d of %d\n\n", artifact.Name, offset, next, artifact.Size))
+	sb.Write(chunk)
+	if next < artifact.Size {
+		sb.WriteString(fmt.Sprintf("\n\n[content is truncated, read the next part from offset %d]", next))
+	}
+
+	return sb.String(), nil
+}
+
+func (a *artifacts) copyToContainer(
+	ctx context.Context,
+	artifact database.FlowArtifact,
+	containerPath string,
+) (string, error) {
+	if a.dockerClient == nil {
+		return "", fmt.Errorf("container files are not available, read the content instead")
+	}
+	if !path.IsAbs(containerPath) || strings.HasSuffix(containerPath, "/") {
+		return "", fmt.Errorf("path '%s' must be an absolute path of the file", containerPath)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(a.writeArchive(writer, artifact, path.Base(containerPath)))
+	}()
+	defer reader.Close()
+
+	err := a.dockerClient.CopyToContainer(ctx, PrimaryTerminalName(a.flowID), path.Dir(containerPath), reader,
+		container.CopyToContainerOptions{AllowOverwriteDirWithFile: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy artifact to container: %w", err)
+	}
+
+	return fmt.Sprintf("artifact '%s' (%s, %d bytes) is written to %s", artifact.Name, artifact.ContentType,
+		artifact.Size, containerPath), nil
+}
+
+func (a *artifacts) writeArchive(w io.Writer, artifact database.FlowArtifact, filename string) error {
+	file, err := os.Open(FlowArtifactPath(a.cfg, a.flowID, artifact.ID))
+	if err != nil {
+		return fmt.Errorf("failed to open artifact %d: %w", artifact.ID, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat artifact %d: %w", artifact.ID, err)
+	}
+
+	tarWriter := tar.NewWriter(w)
+	err = tarWriter.WriteHeader(&tar.Header{
+		Name:    filename,
+		Mode:    0644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tar header: %w", err)
+	}
+
+	if _, err := io.Copy(tarWriter, file); err != nil {
+		return fmt.Errorf("failed to write artifact %d to tar: %w", artifact.ID, err)
+	}
+
+	return tarWriter.Close()
+}
+
+func (a *artifacts) IsAvailable() bool {
+	return a.cfg != nil && a.db != nil
+}
+
+func formatArtifactsList(items []database.FlowArtifact) string {
+	if len(items) == 0 {
+		return "no artifacts were saved in the flow"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Flow artifacts\n\n")
+	sb.WriteString("| ID | Name | Size | Content type | Checksum (sha256) | Reference |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("| %d | %s | %d | %s | %s | %s |\n",
+			item.ID, item.Name, item.Size, item.ContentType, item.Checksum, FlowArtifactURL(item.FlowID, item.ID)))
+	}
+
+	return sb.String()
+}
+
+// artifactName validates the name of the artifact which is only kept in the database
+// and used as the file name when the artifact is downloaded
+func artifactName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("artifact name is required")
+	case len(name) > artifactMaxNameLength:
+		return "", fmt.Errorf("artifact name is longer than %d bytes", artifactMaxNameLength)
+	case strings.ContainsAny(name, "/\\") || name == "." || name == "..":
+		return "", fmt.Errorf("artifact name '%s' must not contain path separators", name)
+	case strings.IndexFunc(name, unicode.IsControl) != -1:
+		return "", fmt.Errorf("artifact name contains control characters")
+	}
+
+	return name, nil
+}
+
+// artifactContentType returns the content type set by the agent if it's valid,
+// otherwise it's detected by the name extension and then by the content
+func artifactContentType(name, contentType string, head []byte) string {
+	if contentType != "" {
+		if _, _, err := mime.ParseMediaType(contentType); err == nil {
+			return contentType
+		}
+	}
+
+	if byExt := mime.TypeByExtension(filepath.Ext(name)); byExt != "" {
+		return byExt
+	}
+
+	if len(head) == 0 {
+		return artifactDefaultContentType
+	}
+
+	return http.DetectContentType(head)
+}
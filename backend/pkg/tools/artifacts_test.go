@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// artifactsMockQuerier keeps the artifacts in memory and emulates the flow_artifacts queries
+type artifactsMockQuerier struct {
+	database.Querier
+	items []database.FlowArtifact
+}
+
+func (m *artifactsMockQuerier) UpsertFlowArtifact(
+	_ context.Context, arg database.UpsertFlowArtifactParams,
+) (database.FlowArtifact, error) {
+	for i, item := range m.items {
+		if item.FlowID == arg.FlowID && item.Name == arg.Name {
+			item.Size, item.ContentType, item.Checksum = arg.Size, arg.ContentType, arg.Checksum
+			item.TaskID, item.SubtaskID = arg.TaskID, arg.SubtaskID
+			m.items[i] = item
+			return item, nil
+		}
+	}
+
+	item := database.FlowArtifact{
+		ID:          int64(len(m.items) + 1),
+		Name:        arg.Name,
+		Size:        arg.Size,
+		ContentType: arg.ContentType,
+		Checksum:    arg.Checksum,
+		FlowID:      arg.FlowID,
+		TaskID:      arg.TaskID,
+		SubtaskID:   arg.SubtaskID,
+	}
+	m.items = append(m.items, item)
+	return item, nil
+}
+
+func (m *artifactsMockQuerier) GetFlowArtifact(
+	_ context.Context, arg database.GetFlowArtifactParams,
+) (database.FlowArtifact, error) {
+	for _, item := range m.items {
+		if item.FlowID == arg.FlowID && item.Name == arg.Name {
+			return item, nil
+		}
+	}
+	return database.FlowArtifact{}, sql.ErrNoRows
+}
+
+func (m *artifactsMockQuerier) GetFlowArtifacts(_ context.Context, flowID int64) ([]database.FlowArtifact, error) {
+	var result []database.FlowArtifact
+	for _, item := range m.items {
+		if item.FlowID == flowID {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// artifactsMockDockerClient serves the container files from the map and records the copied archives
+type artifactsMockDockerClient struct {
+	docker.DockerClient
+	files  map[string][]byte
+	copied map[string][]byte
+}
+
+func (m *artifactsMockDockerClient) CopyFromContainer(
+	_ context.Context, _ string, path string,
+) (io.ReadCloser, container.PathStat, error) {
+	content, ok := m.files[path]
+	if !ok {
+		return nil, container.PathStat{}, os.ErrNotExist
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	_ = tw.WriteHeader(&tar.Header{Name: "file", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	_, _ = tw.Write(content)
+	_ = tw.Close()
+
+	return io.NopCloser(&buf), container.PathStat{Size: int64(len(content))}, nil
+}
+
+func (m *artifactsMockDockerClient) CopyToContainer(
+	_ context.Context, _ string, dir string, content io.Reader, _ container.CopyToContainerOptions,
+) error {
+	tr := tar.NewReader(content)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		m.copied[dir+"/"+header.Name] = data
+	}
+}
+
+func newTestArtifactsTool(t *testing.T, maxSize int64, dockerClient docker.DockerClient) (*artifacts, *artifactsMockQuerier) {
+	t.Helper()
+
+	db := &artifactsMockQuerier{}
+	cfg := &config.Config{ArtifactsDir: t.TempDir(), ArtifactsMaxSize: maxSize}
+	taskID := int64(3)
+	return NewArtifactsTool(1, &taskID, nil, cfg, db, dockerClient).(*artifacts), db
+}
+
+func handleArtifactsAction(t *testing.T, tool *artifacts, action ArtifactsAction) string {
+	t.Helper()
+
+	args, err := json.Marshal(action)
+	require.NoError(t, err)
+	result, err := tool.Handle(t.Context(), ArtifactsToolName, args)
+	require.NoError(t, err)
+	return result
+}
+
+func TestFlowArtifactsDir(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{DataDir: "/data"}
+	if dir := FlowArtifactsDir(cfg, 7); dir != "/data/artifacts/flow-7" {
+		t.Errorf("unexpected default dir %s", dir)
+	}
+
+	cfg.ArtifactsDir = "/storage"
+	if path := FlowArtifactPath(cfg, 7, 3); path != "/storage/flow-7/3" {
+		t.Errorf("unexpected artifact path %s", path)
+	}
+	if url := FlowArtifactURL(7, 3); url != "/api/v1/flows/7/artifacts/3/file" {
+		t.Errorf("unexpected artifact url %s", url)
+	}
+}
+
+func TestArtifactsPutGetRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tool, db := newTestArtifactsTool(t, 1024, nil)
+	content := `{"hosts":["10.0.0.5"],"ports":[22,443]}`
+
+	result := handleArtifactsAction(t, tool, ArtifactsAction{Action: PutArtifact, Name: "scan.json", Content: content})
+	assert.Contains(t, result, "is saved with id 1")
+	assert.Contains(t, result, "/api/v1/flows/1/artifacts/1/file")
+
+	require.Len(t, db.items, 1)
+	sum := sha256.Sum256([]byte(content))
+	assert.Equal(t, hex.EncodeToString(sum[:]), db.items[0].Checksum)
+	assert.Equal(t, int64(len(content)), db.items[0].Size)
+	assert.Equal(t, "application/json", db.items[0].ContentType)
+	assert.Equal(t, sql.NullInt64{Int64: 3, Valid: true}, db.items[0].TaskID)
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: GetArtifact, Name: "scan.json"})
+	assert.True(t, strings.HasSuffix(result, content), result)
+	assert.NotContains(t, result, "truncated")
+
+	// the artifact with the same name is overwritten in place
+	handleArtifactsAction(t, tool, ArtifactsAction{Action: PutArtifact, Name: "scan.json", Content: "{}"})
+	require.Len(t, db.items, 1)
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: GetArtifact, Name: "scan.json"})
+	assert.True(t, strings.HasSuffix(result, "\n\n{}"), result)
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: GetArtifact, Name: "missing.txt"})
+	assert.Contains(t, result, "not found")
+
+	entries, err := os.ReadDir(FlowArtifactsDir(tool.cfg, 1))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files must be removed")
+	assert.Equal(t, "1", entries[0].Name())
+}
+
+func TestArtifactsPutValidation(t *testing.T) {
+	t.Parallel()
+
+	tool, db := newTestArtifactsTool(t, 8, nil)
+
+	cases := map[string]struct {
+		action ArtifactsAction
+		want   string
+	}{
+		"too large":      {ArtifactsAction{Name: "big.txt", Content: "0123456789"}, "exceeds the limit of 8 bytes"},
+		"empty name":     {ArtifactsAction{Name: " ", Content: "data"}, "name is required"},
+		"path separator": {ArtifactsAction{Name: "../etc/passwd", Content: "data"}, "path separators"},
+		"control chars":  {ArtifactsAction{Name: "a\nb", Content: "data"}, "control characters"},
+		"content and path": {
+			ArtifactsAction{Name: "a.txt", Content: "data", Path: "/work/a.txt"}, "only one of the content and the path",
+		},
+		"no container": {ArtifactsAction{Name: "a.txt", Path: "/work/a.txt"}, "container files are not available"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.action.Action = PutArtifact
+			result := handleArtifactsAction(t, tool, tc.action)
+			assert.Contains(t, result, tc.want)
+		})
+	}
+
+	assert.Empty(t, db.items)
+}
+
+func TestArtifactsContainerRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	screenshot := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	client := &artifactsMockDockerClient{
+		files:  map[string][]byte{"/work/shot": screenshot},
+		copied: map[string][]byte{},
+	}
+	tool, db := newTestArtifactsTool(t, 1024, client)
+
+	result := handleArtifactsAction(t, tool, ArtifactsAction{Action: PutArtifact, Name: "login", Path: "/work/shot"})
+	assert.Contains(t, result, "is saved with id 1")
+	require.Len(t, db.items, 1)
+	assert.Equal(t, "image/png", db.items[0].ContentType)
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: GetArtifact, Name: "login"})
+	assert.Contains(t, result, "is a binary file")
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: GetArtifact, Name: "login", Path: "/tmp/login.png"})
+	assert.Contains(t, result, "is written to /tmp/login.png")
+	assert.Equal(t, screenshot, client.copied["/tmp/login.png"])
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: GetArtifact, Name: "login", Path: "relative.png"})
+	assert.Contains(t, result, "must be an absolute path")
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: PutArtifact, Name: "gone", Path: "/work/none"})
+	assert.Contains(t, result, "failed to copy file from container")
+}
+
+func TestArtifactsList(t *testing.T) {
+	t.Parallel()
+
+	tool, _ := newTestArtifactsTool(t, 1024, nil)
+
+	result := handleArtifactsAction(t, tool, ArtifactsAction{Action: ListArtifacts})
+	assert.Equal(t, "no artifacts were saved in the flow", result)
+
+	handleArtifactsAction(t, tool, ArtifactsAction{Action: PutArtifact, Name: "exploit.py", Content: "print(1)\n"})
+	handleArtifactsAction(t, tool, ArtifactsAction{Action: PutArtifact, Name: "notes", Content: "admin panel at /adm"})
+
+	result = handleArtifactsAction(t, tool, ArtifactsAction{Action: ListArtifacts})
+	assert.Contains(t, result, "| 1 | exploit.py | 9 |")
+	assert.Contains(t, result, "| 2 | notes | 19 | text/plain; charset=utf-8 |")
+	assert.Contains(t, result, "/api/v1/flows/1/artifacts/2/file")
+}
+
+func TestArtifactContentType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		name, contentType string
+		head              []byte
+		want              string
+	}{
+		"explicit":     {"report", "text/markdown", []byte("# Report"), "text/markdown"},
+		"invalid":      {"shot.png", "not a type;;", nil, "image/png"},
+		"by extension": {"shot.png", "", []byte("text"), "image/png"},
+		"by content":   {"dump", "", []byte("\x00\x01\x02"), "application/octet-stream"},
+		"empty":        {"empty", "", nil, "application/octet-stream"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, artifactContentType(tc.name, tc.contentType, tc.head))
+		})
+	}
+}
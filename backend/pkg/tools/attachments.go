@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/sirupsen/logrus"
+)
+
+// AttachmentsPathInContainer is the directory of the flow attachments inside the primary container
+const AttachmentsPathInContainer = docker.WorkFolderPathInContainer + "/attachments"
+
+const (
+	attachmentsDirName      = "attachments"
+	attachmentReadChunkSize = 32 * 1024
+)
+
+// FlowAttachmentsDir returns the storage directory of the flow attachments on the server side
+func FlowAttachmentsDir(cfg *config.Config, flowID int64) string {
+	dir := cfg.AttachmentsDir
+	if dir == "" {
+		dir = filepath.Join(cfg.DataDir, attachmentsDirName)
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("flow-%d", flowID))
+}
+
+// FlowAttachmentPath returns the path of the stored attachment content, it's named by the id
+// because the original name is controlled by the user
+func FlowAttachmentPath(cfg *config.Config, flowID, attachmentID int64) string {
+	return filepath.Join(FlowAttachmentsDir(cfg, flowID), strconv.FormatInt(attachmentID, 10))
+}
+
+// AttachmentPathInContainer returns the path of the attachment inside the primary container
+func AttachmentPathInContainer(name string) string {
+	return path.Join(AttachmentsPathInContainer, name)
+}
+
+// CopyAttachmentsToContainer puts the stored attachments to AttachmentsPathInContainer of the container,
+// the existing files with the same names are overwritten
+func CopyAttachmentsToContainer(
+	ctx context.Context,
+	cfg *config.Config,
+	dockerClient docker.DockerClient,
+	containerID string,
+	attachments []database.FlowAttachment,
+) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeAttachmentsArchive(writer, cfg, attachments))
+	}()
+	defer reader.Close()
+
+	err := dockerClient.CopyToContainer(ctx, containerID, docker.WorkFolderPathInContainer, reader,
+		container.CopyToContainerOptions{AllowOverwriteDirWithFile: true})
+	if err != nil {
+		return fmt.Errorf("failed to copy attachments to container: %w", err)
+	}
+
+	return nil
+}
+
+func writeAttachmentsArchive(w io.Writer, cfg *config.Config, attachments []database.FlowAttachment) error {
+	tarWriter := tar.NewWriter(w)
+
+	err := tarWriter.WriteHeader(&tar.Header{
+		Name:     attachmentsDirName + "/",
+		Typeflag: tar.TypeDir,
+		Mode:     0755,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tar header: %w", err)
+	}
+
+	for _, attachment := range attachments {
+		if err := writeAttachmentToArchive(tarWriter, cfg, attachment); err != nil {
+			return err
+		}
+	}
+
+	return tarWriter.Close()
+}
+
+func writeAttachmentToArchive(tarWriter *tar.Writer, cfg *config.Config, attachment database.FlowAttachment) error {
+	file, err := os.Open(FlowAttachmentPath(cfg, attachment.FlowID, attachment.ID))
+	if err != nil {
+		return fmt.Errorf("failed to open attachment %d: %w", attachment.ID, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat attachment %d: %w", attachment.ID, err)
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{
+		Name:    attachmentsDirName + "/" + attachment.Name,
+		Mode:    0644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tar header: %w", err)
+	}
+
+	if _, err := io.Copy(tarWriter, file); err != nil {
+		return fmt.Errorf("failed to write attachment %d to tar: %w", attachment.ID, err)
+	}
+
+	return nil
+}
+
+// attachments gives the agents access to the files uploaded by the user to the flow
+type attachments struct {
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	cfg       *config.Config
+	db        database.Querier
+}
+
+// NewAttachmentsTool creates a new tool to list and read the flow attachments
+func NewAttachmentsTool(
+	flowID int64,
+	taskID, subtaskID *int64,
+	cfg *config.Config,
+	db database.Querier,
+) Tool {
+	return &attachments{
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		cfg:       cfg,
+		db:        db,
+	}
+}
+
+func (a *attachments) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !a.IsAvailable() {
+		return "", fmt.Errorf("attachments are not available")
+	}
+
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(a.flowID, a.taskID, a.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	var action AttachmentsAction
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal attachments action")
+		return "", fmt.Errorf("failed to unmarshal attachments action: %w", err)
+	}
+
+	switch action.Action {
+	case ListAttachments:
+		items, err := a.db.GetFlowAttachments(ctx, a.flowID)
+		if err != nil {
+			logger.WithError(err).Error("failed to get flow attachments")
+			return "", fmt.Errorf("failed to get flow attachments: %w", err)
+		}
+		return formatAttachmentsList(items), nil
+	case ReadAttachment:
+		attachment, err := a.db.GetFlowAttachment(ctx, database.GetFlowAttachmentParams{
+			ID:     action.ID.Int64(),
+			FlowID: a.flowID,
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to get flow attachment")
+			return fmt.Sprintf("attachment %d not found, use the '%s' action to get the list of attachments",
+				action.ID.Int64(), ListAttachments), nil
+		}
+		result, err := a.read(attachment, action.Offset.Int64())
+		if err != nil {
+			logger.WithError(err).Error("failed to read flow attachment")
+			return fmt.Sprintf("failed to read attachment %d: %v", attachment.ID, err), nil
+		}
+		return result, nil
+	default:
+		logger.Error("unknown attachments action")
+		return "", fmt.Errorf("unknown attachments action: %s", action.Action)
+	}
+}
+
+// read returns the text chunk of the attachment from the offset, binary files are only referenced
+// by the path in the container to be processed by the terminal tools
+func (a *attachments) read(attachment database.FlowAttachment, offset int64) (string, error) {
+	file, err := os.Open(FlowAttachmentPath(a.cfg, a.flowID, attachment.ID))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if offset < 0 || offset > attachment.Size {
+		return fmt.Sprintf("offset %d is out of the attachment size %d bytes", offset, attachment.Size), nil
+	}
+
+	buf := make([]byte, attachmentReadChunkSize)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	chunk := trimIncompleteRune(buf[:n])
+
+	containerPath := AttachmentPathInContainer(attachment.Name)
+	if !isTextContent(chunk) {
+		return fmt.Sprintf("attachment '%s' (%s, %d bytes) is a binary file, "+
+			"process it in the terminal by the path %s", attachment.Name, attachment.ContentType,
+			attachment.Size, containerPath), nil
+	}
+
+	var sb strings.Builder
+	next := offset + int64(len(chunk))
+	sb.WriteString(fmt.Sprintf("attachment '%s' bytes %d-%d of %d, path in container %s\n\n",
+		attachment.Name, offset, next, attachment.Size, containerPath))
+	sb.Write(chunk)
+	if next < attachment.Size {
+		sb.WriteString(fmt.Sprintf("\n\n[content is truncated, read the next part from offset %d]", next))
+	}
+
+	return sb.String(), nil
+}
+
+func (a *attachments) IsAvailable() bool {
+	return a.cfg != nil && a.db != nil
+}
+
+func formatAttachmentsList(items []database.FlowAttachment) string {
+	if len(items) == 0 {
+		return "no files were attached to the flow"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Flow attachments\n\n")
+	sb.WriteString("| ID | Name | Size | Content type | Path in container |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("| %d | %s | %d | %s | %s |\n",
+			item.ID, item.Name, item.Size, item.ContentType, AttachmentPathInContainer(item.Name)))
+	}
+
+	return sb.String()
+}
+
+// trimIncompleteRune drops the tail of multi-byte character which was cut by the chunk size
+func trimIncompleteRune(chunk []byte) []byte {
+	for i := 0; i < utf8.UTFMax && i < len(chunk); i++ {
+		r, size := utf8.DecodeLastRune(chunk[:len(chunk)-i])
+		if r != utf8.RuneError || size > 1 {
+			return chunk[:len(chunk)-i]
+		}
+	}
+
+	return chunk
+}
+
+func isTextContent(chunk []byte) bool {
+	return utf8.Valid(chunk) && !strings.ContainsRune(string(chunk), 0)
+}
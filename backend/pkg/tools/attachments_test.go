@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func writeTestAttachment(t *testing.T, cfg *config.Config, attachment database.FlowAttachment, content []byte) {
+	t.Helper()
+
+	path := FlowAttachmentPath(cfg, attachment.FlowID, attachment.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create attachments dir: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("failed to write attachment: %v", err)
+	}
+}
+
+func TestFlowAttachmentsDir(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{DataDir: "/data"}
+	if dir := FlowAttachmentsDir(cfg, 7); dir != "/data/attachments/flow-7" {
+		t.Errorf("unexpected default dir %s", dir)
+	}
+
+	cfg.AttachmentsDir = "/storage"
+	if path := FlowAttachmentPath(cfg, 7, 3); path != "/storage/flow-7/3" {
+		t.Errorf("unexpected attachment path %s", path)
+	}
+}
+
+func TestAttachmentsRead(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{AttachmentsDir: t.TempDir()}
+	tool := &attachments{flowID: 1, cfg: cfg}
+
+	// the chunk boundary cuts the multi-byte character which is moved to the next chunk
+	content := []byte(strings.Repeat("a", attachmentReadChunkSize-1) + "ж" + "tail")
+	text := database.FlowAttachment{ID: 1, Name: "scope.txt", Size: int64(len(content)), FlowID: 1}
+	writeTestAttachment(t, cfg, text, content)
+
+	result, err := tool.read(text, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next := attachmentReadChunkSize - 1
+	if !strings.Contains(result, "read the next part from offset 32767") {
+		t.Errorf("expected truncation notice with offset %d, got tail %q", next, result[len(result)-80:])
+	}
+
+	result, err = tool.read(text, int64(next))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(result, "жtail") || strings.Contains(result, "truncated") {
+		t.Errorf("unexpected last chunk %q", result)
+	}
+
+	result, _ = tool.read(text, int64(len(content)+1))
+	if !strings.Contains(result, "out of the attachment size") {
+		t.Errorf("expected offset error, got %q", result)
+	}
+
+	binary := database.FlowAttachment{ID: 2, Name: "dump.bin", Size: 4, ContentType: "application/octet-stream", FlowID: 1}
+	writeTestAttachment(t, cfg, binary, []byte{0x7f, 0x45, 0x00, 0xff})
+
+	result, err = tool.read(binary, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "binary file") || !strings.Contains(result, "/work/attachments/dump.bin") {
+		t.Errorf("expected binary file reference, got %q", result)
+	}
+}
+
+func TestWriteAttachmentsArchive(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{AttachmentsDir: t.TempDir()}
+	items := []database.FlowAttachment{
+		{ID: 1, Name: "scope.txt", FlowID: 1},
+		{ID: 2, Name: "creds.csv", FlowID: 1},
+	}
+	writeTestAttachment(t, cfg, items[0], []byte("10.0.0.1"))
+	writeTestAttachment(t, cfg, items[1], []byte("admin,admin"))
+
+	var buf bytes.Buffer
+	if err := writeAttachmentsArchive(&buf, cfg, items); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files := map[string]string{}
+	reader := tar.NewReader(&buf)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read archive: %v", err)
+		}
+		data, _ := io.ReadAll(reader)
+		files[header.Name] = string(data)
+	}
+
+	expected := map[string]string{
+		"attachments/":          "",
+		"attachments/scope.txt": "10.0.0.1",
+		"attachments/creds.csv": "admin,admin",
+	}
+	for name, content := range expected {
+		if got, ok := files[name]; !ok || got != content {
+			t.Errorf("archive entry %s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestFormatAttachmentsList(t *testing.T) {
+	t.Parallel()
+
+	if result := formatAttachmentsList(nil); !strings.Contains(result, "no files") {
+		t.Errorf("unexpected empty list %q", result)
+	}
+
+	result := formatAttachmentsList([]database.FlowAttachment{
+		{ID: 3, Name: "scope.txt", Size: 8, ContentType: "text/plain"},
+	})
+	if !strings.Contains(result, "| 3 | scope.txt | 8 | text/plain | /work/attachments/scope.txt |") {
+		t.Errorf("unexpected list %q", result)
+	}
+}
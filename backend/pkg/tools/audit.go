@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"pentagi/pkg/database"
+)
+
+// writeFlowAuditLog stores the event of the flow in the audit log on behalf of the flow owner
+func writeFlowAuditLog(
+	ctx context.Context,
+	db database.Querier,
+	flowID int64,
+	action string,
+	changes, metadata map[string]any,
+) error {
+	flow, err := db.GetFlow(ctx, flowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow %d: %w", flowID, err)
+	}
+
+	changesBlob, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log changes: %w", err)
+	}
+
+	metadataBlob, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log metadata: %w", err)
+	}
+
+	_, err = db.CreateAuditLog(ctx, database.CreateAuditLogParams{
+		UserID:   flow.UserID,
+		Action:   action,
+		FlowID:   flowID,
+		Changes:  changesBlob,
+		Metadata: metadataBlob,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
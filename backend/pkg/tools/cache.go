@@ -0,0 +1,320 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCacheMaxEntries = 10000
+	redisCacheKeyPrefix    = "pentagi:tools"
+	redisCachePingTimeout  = 5 * time.Second
+	redisCacheScanCount    = 1000
+
+	// maxCacheKeyLength is the length after which the key is replaced by its hash
+	maxCacheKeyLength = 128
+)
+
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache is a shared key-value cache which tools use to store results of external requests,
+// every tool uses its own namespace to avoid keys collision
+type Cache interface {
+	Get(ctx context.Context, namespace, key string) ([]byte, error)
+	Set(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, namespace, key string) error
+	Clear(ctx context.Context, namespace string) error
+}
+
+var (
+	sharedCacheOnce sync.Once
+	sharedCache     Cache
+)
+
+// GetSharedCache returns process wide cache instance which is built from config on first call,
+// it uses Redis if TOOLS_CACHE_REDIS_URL is set and falls back to in-memory cache otherwise
+func GetSharedCache(cfg *config.Config) Cache {
+	sharedCacheOnce.Do(func() {
+		cache, err := NewCache(cfg)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to create tools cache, falling back to in-memory cache")
+			cache = NewMemoryCache(cfg.ToolsCacheMaxEntries)
+		}
+		sharedCache = cache
+	})
+
+	return sharedCache
+}
+
+// NewCache creates cache backend according to config
+func NewCache(cfg *config.Config) (Cache, error) {
+	if cfg == nil || cfg.ToolsCacheRedisURL == "" {
+		maxEntries := defaultCacheMaxEntries
+		if cfg != nil {
+			maxEntries = cfg.ToolsCacheMaxEntries
+		}
+		return NewMemoryCache(maxEntries), nil
+	}
+
+	opts, err := redis.ParseURL(cfg.ToolsCacheRedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCachePingTimeout)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return NewRedisCache(client), nil
+}
+
+// cacheKey builds namespaced key, long keys are hashed to keep storage keys bounded
+func cacheKey(namespace, key string) string {
+	if len(key) > maxCacheKeyLength {
+		sum := sha256.Sum256([]byte(key))
+		key = hex.EncodeToString(sum[:])
+	}
+
+	return namespace + ":" + key
+}
+
+// normalizeCacheKey joins key parts in case insensitive form
+func normalizeCacheKey(parts ...string) string {
+	normalized := make([]string, 0, len(parts))
+	for _, part := range parts {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(part)))
+	}
+
+	return strings.Join(normalized, "|")
+}
+
+// getCachedJSON reads value from the cache and decodes it into out, returns false on miss or any error
+func getCachedJSON(ctx context.Context, cache Cache, namespace, key string, out any) bool {
+	if cache == nil {
+		return false
+	}
+
+	data, err := cache.Get(ctx, namespace, key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			logrus.WithContext(ctx).WithError(err).WithField("namespace", namespace).Warn("failed to get value from cache")
+		}
+		return false
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("namespace", namespace).Warn("failed to decode cached value")
+		return false
+	}
+
+	return true
+}
+
+// setCachedJSON encodes value and stores it in the cache, errors are logged but not returned
+func setCachedJSON(ctx context.Context, cache Cache, namespace, key string, value any, ttl time.Duration) {
+	if cache == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("namespace", namespace).Warn("failed to encode value to cache")
+		return
+	}
+
+	if err := cache.Set(ctx, namespace, key, data, ttl); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("namespace", namespace).Warn("failed to set value to cache")
+	}
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryCache is in-memory LRU cache with per entry TTL
+type memoryCache struct {
+	mx         *sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	now        func() time.Time
+}
+
+// NewMemoryCache creates in-memory cache which keeps up to maxEntries values
+func NewMemoryCache(maxEntries int) Cache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+
+	return &memoryCache{
+		mx:         &sync.Mutex{},
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+func (mc *memoryCache) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	elem, ok := mc.entries[cacheKey(namespace, key)]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expiresAt.IsZero() && !mc.now().Before(entry.expiresAt) {
+		mc.remove(elem)
+		return nil, ErrCacheMiss
+	}
+
+	mc.lru.MoveToFront(elem)
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (mc *memoryCache) Set(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = mc.now().Add(ttl)
+	}
+
+	fullKey := cacheKey(namespace, key)
+	value = append([]byte(nil), value...)
+	if elem, ok := mc.entries[fullKey]; ok {
+		entry := elem.Value.(*memoryCacheEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		mc.lru.MoveToFront(elem)
+		return nil
+	}
+
+	mc.entries[fullKey] = mc.lru.PushFront(&memoryCacheEntry{
+		key:       fullKey,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for mc.lru.Len() > mc.maxEntries {
+		mc.remove(mc.lru.Back())
+	}
+
+	return nil
+}
+
+func (mc *memoryCache) Delete(ctx context.Context, namespace, key string) error {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	if elem, ok := mc.entries[cacheKey(namespace, key)]; ok {
+		mc.remove(elem)
+	}
+
+	return nil
+}
+
+func (mc *memoryCache) Clear(ctx context.Context, namespace string) error {
+	mc.mx.Lock()
+	defer mc.mx.Unlock()
+
+	prefix := cacheKey(namespace, "")
+	for key, elem := range mc.entries {
+		if strings.HasPrefix(key, prefix) {
+			mc.remove(elem)
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the element from the cache, it must be called under the lock
+func (mc *memoryCache) remove(elem *list.Element) {
+	entry := mc.lru.Remove(elem).(*memoryCacheEntry)
+	delete(mc.entries, entry.key)
+}
+
+// redisCache stores values in Redis to share them across instances
+type redisCache struct {
+	client redis.UniversalClient
+}
+
+// NewRedisCache creates cache on top of the Redis client
+func NewRedisCache(client redis.UniversalClient) Cache {
+	return &redisCache{client: client}
+}
+
+func (rc *redisCache) key(namespace, key string) string {
+	return redisCacheKeyPrefix + ":" + cacheKey(namespace, key)
+}
+
+func (rc *redisCache) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	data, err := rc.client.Get(ctx, rc.key(namespace, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get value from redis: %w", err)
+	}
+
+	return data, nil
+}
+
+func (rc *redisCache) Set(ctx context.Context, namespace, key string, value []byte, ttl time.Duration) error {
+	if err := rc.client.Set(ctx, rc.key(namespace, key), value, max(ttl, 0)).Err(); err != nil {
+		return fmt.Errorf("failed to set value to redis: %w", err)
+	}
+
+	return nil
+}
+
+func (rc *redisCache) Delete(ctx context.Context, namespace, key string) error {
+	if err := rc.client.Del(ctx, rc.key(namespace, key)).Err(); err != nil {
+		return fmt.Errorf("failed to delete value from redis: %w", err)
+	}
+
+	return nil
+}
+
+func (rc *redisCache) Clear(ctx context.Context, namespace string) error {
+	var cursor uint64
+	match := rc.key(namespace, "") + "*"
+	for {
+		keys, next, err := rc.client.Scan(ctx, cursor, match, redisCacheScanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan redis keys: %w", err)
+		}
+
+		if len(keys) != 0 {
+			if err := rc.client.Del(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("failed to delete values from redis: %w", err)
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+)
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	cache := NewMemoryCache(10)
+	ctx := t.Context()
+
+	if _, err := cache.Get(ctx, "ns", "key"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected cache miss, got %v", err)
+	}
+
+	if err := cache.Set(ctx, "ns", "key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+
+	got, err := cache.Get(ctx, "ns", "key")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if string(got) != "value" {
+		t.Errorf("expected 'value', got %q", got)
+	}
+
+	// namespaces are isolated
+	if _, err := cache.Get(ctx, "other", "key"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected cache miss for other namespace, got %v", err)
+	}
+
+	// returned value is a copy
+	got[0] = 'X'
+	if got, _ := cache.Get(ctx, "ns", "key"); string(got) != "value" {
+		t.Errorf("cached value was modified through returned slice: %q", got)
+	}
+
+	if err := cache.Delete(ctx, "ns", "key"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := cache.Get(ctx, "ns", "key"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected cache miss after delete, got %v", err)
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache(10).(*memoryCache)
+	cache.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	_ = cache.Set(ctx, "ns", "short", []byte("1"), time.Minute)
+	_ = cache.Set(ctx, "ns", "forever", []byte("2"), 0)
+
+	now = now.Add(time.Minute)
+
+	if _, err := cache.Get(ctx, "ns", "short"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected expired entry to be missed, got %v", err)
+	}
+	if _, err := cache.Get(ctx, "ns", "forever"); err != nil {
+		t.Errorf("expected entry without ttl to be kept, got %v", err)
+	}
+	if cache.lru.Len() != 1 {
+		t.Errorf("expected expired entry to be removed, got %d entries", cache.lru.Len())
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	ctx := t.Context()
+
+	_ = cache.Set(ctx, "ns", "a", []byte("a"), time.Minute)
+	_ = cache.Set(ctx, "ns", "b", []byte("b"), time.Minute)
+
+	// touch "a" so "b" becomes the least recently used entry
+	if _, err := cache.Get(ctx, "ns", "a"); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	_ = cache.Set(ctx, "ns", "c", []byte("c"), time.Minute)
+
+	if _, err := cache.Get(ctx, "ns", "b"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected least recently used entry to be evicted, got %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := cache.Get(ctx, "ns", key); err != nil {
+			t.Errorf("expected entry %q to be kept, got %v", key, err)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	if got := cacheKey("ns", "key"); got != "ns:key" {
+		t.Errorf("expected 'ns:key', got %q", got)
+	}
+
+	long := strings.Repeat("k", maxCacheKeyLength+1)
+	got := cacheKey("ns", long)
+	if len(got) != len("ns:")+64 || !strings.HasPrefix(got, "ns:") {
+		t.Errorf("expected hashed key, got %q", got)
+	}
+	if got != cacheKey("ns", long) {
+		t.Error("expected hashed key to be stable")
+	}
+
+	if normalizeCacheKey(" Exploits", "DATE ", " Nginx ") != "exploits|date|nginx" {
+		t.Errorf("unexpected normalized key %q", normalizeCacheKey(" Exploits", "DATE ", " Nginx "))
+	}
+}
+
+func TestCachedJSONHelpers(t *testing.T) {
+	cache := NewMemoryCache(10)
+	ctx := t.Context()
+
+	type payload struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	var out payload
+	if getCachedJSON(ctx, cache, "ns", "key", &out) {
+		t.Fatal("expected miss on empty cache")
+	}
+	if getCachedJSON(ctx, nil, "ns", "key", &out) {
+		t.Fatal("expected miss on nil cache")
+	}
+
+	setCachedJSON(ctx, cache, "ns", "key", payload{Name: "test", Count: 3}, time.Minute)
+	setCachedJSON(ctx, nil, "ns", "key", payload{Name: "ignored"}, time.Minute)
+
+	if !getCachedJSON(ctx, cache, "ns", "key", &out) {
+		t.Fatal("expected hit after set")
+	}
+	if out != (payload{Name: "test", Count: 3}) {
+		t.Errorf("unexpected cached payload %+v", out)
+	}
+
+	_ = cache.Set(ctx, "ns", "broken", []byte("{"), time.Minute)
+	if getCachedJSON(ctx, cache, "ns", "broken", &out) {
+		t.Error("expected miss on broken cached value")
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	cache, err := NewCache(&config.Config{ToolsCacheMaxEntries: 5})
+	if err != nil {
+		t.Fatalf("NewCache() unexpected error: %v", err)
+	}
+	if mc, ok := cache.(*memoryCache); !ok || mc.maxEntries != 5 {
+		t.Errorf("expected in-memory cache with 5 max entries, got %T", cache)
+	}
+
+	if _, err := NewCache(&config.Config{ToolsCacheRedisURL: "not-a-url"}); err == nil {
+		t.Error("expected error for invalid redis url")
+	}
+}
@@ -0,0 +1,916 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"pentagi/pkg/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	commandPolicyMaxRules    = 200
+	commandPolicyMaxRuleSize = 1024
+	commandPolicyAuditAction = "flow.command_denied"
+	shellMaxNestingDepth     = 16
+)
+
+var shellAssignmentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[[^]]*\])?\+?=`)
+
+// shellKeywords are skipped at the beginning of the command, the commands which start with
+// shellCompoundKeywords don't run anything by themselves and their substitutions are parsed separately
+var (
+	shellKeywords = []string{
+		"!", "{", "}", "if", "then", "else", "elif", "fi", "do", "done", "while", "until", "esac",
+	}
+	shellCompoundKeywords = []string{"for", "case", "select", "function"}
+	shellInterpreters     = []string{"sh", "bash", "zsh", "dash", "ksh", "ash", "mksh"}
+)
+
+// shellWrapper is the command which runs another command passed in its arguments
+type shellWrapper struct {
+	args     []string // options which take the separate argument
+	operands int      // operands before the wrapped command
+}
+
+var shellWrappers = map[string]shellWrapper{
+	"sudo": {args: []string{
+		"-u", "-g", "-C", "-D", "-h", "-p", "-r", "-t", "-T", "-U",
+		"--user", "--group", "--close-from", "--chdir", "--host", "--prompt", "--role", "--type",
+		"--command-timeout", "--other-user",
+	}},
+	"doas":         {args: []string{"-u", "-C"}},
+	"env":          {args: []string{"-u", "-C", "--unset", "--chdir"}},
+	"nohup":        {},
+	"nice":         {args: []string{"-n", "--adjustment"}},
+	"ionice":       {args: []string{"-c", "-n", "-p", "-P", "-u", "--class", "--classdata"}},
+	"timeout":      {args: []string{"-s", "-k", "--signal", "--kill-after"}, operands: 1},
+	"time":         {args: []string{"-f", "-o", "--format", "--output"}},
+	"command":      {},
+	"builtin":      {},
+	"exec":         {args: []string{"-a"}},
+	"setsid":       {},
+	"stdbuf":       {args: []string{"-i", "-o", "-e"}},
+	"busybox":      {},
+	"chroot":       {args: []string{"--userspec", "--groups"}, operands: 1},
+	"strace":       {args: []string{"-e", "-o", "-p", "-s", "-u", "-E", "-a", "-b", "-I", "-O", "-P", "-S", "-X"}},
+	"proxychains":  {args: []string{"-f"}},
+	"proxychains4": {args: []string{"-f"}},
+	"torsocks":     {args: []string{"-u", "-p", "-a", "-P"}},
+	"xargs": {args: []string{
+		"-a", "-d", "-E", "-I", "-L", "-n", "-P", "-s",
+		"--arg-file", "--delimiter", "--max-lines", "--max-args", "--max-procs", "--max-chars", "--process-slot-var",
+	}},
+}
+
+// CommandPolicy restricts the terminal commands of the flow, the rule is the command prefix which is matched
+// by the whole words like "git push" or the regular expression wrapped into slashes like /^curl .*169\.254/
+type CommandPolicy struct {
+	Allow []string `form:"allow,omitempty" json:"allow,omitempty" validate:"omitempty"`
+	Deny  []string `form:"deny,omitempty" json:"deny,omitempty" validate:"omitempty"`
+}
+
+// IsEmpty returns true if the policy permits all commands
+func (p CommandPolicy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// Valid is function to control input/output data
+func (p CommandPolicy) Valid() error {
+	if len(p.Allow)+len(p.Deny) > commandPolicyMaxRules {
+		return fmt.Errorf("command policy can't contain more than %d rules", commandPolicyMaxRules)
+	}
+	_, err := compileCommandPolicy("flow", p)
+	return err
+}
+
+func (p *CommandPolicy) Scan(input any) error {
+	switch v := input.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	case []byte:
+		return json.Unmarshal(v, p)
+	case json.RawMessage:
+		return json.Unmarshal(v, p)
+	}
+	return fmt.Errorf("unsupported type of input value to scan")
+}
+
+// commandRule is the compiled rule of the command policy
+type commandRule struct {
+	rule   string
+	re     *regexp.Regexp
+	prefix []string
+}
+
+func compileCommandRule(rule string) (commandRule, error) {
+	rule = strings.TrimSpace(rule)
+	if len(rule) > commandPolicyMaxRuleSize {
+		return commandRule{}, fmt.Errorf("command rule is longer than %d characters", commandPolicyMaxRuleSize)
+	}
+
+	if len(rule) > 2 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/") {
+		re, err := regexp.Compile(rule[1 : len(rule)-1])
+		if err != nil {
+			return commandRule{}, fmt.Errorf("invalid command rule '%s': %w", rule, err)
+		}
+		return commandRule{rule: rule, re: re}, nil
+	}
+
+	prefix := strings.Fields(rule)
+	if len(prefix) == 0 {
+		return commandRule{}, errors.New("command rule can't be empty")
+	}
+	// command name of the rule is normalized the same way as the name of the parsed command
+	prefix[0] = path.Base(prefix[0])
+
+	return commandRule{rule: rule, prefix: prefix}, nil
+}
+
+// match checks the parsed command, the word built from the expansions can't be compared
+// so it matches the deny rule and doesn't match the allow rule
+func (r commandRule) match(cmd shellCommand, deny bool) bool {
+	if r.re != nil {
+		return r.re.MatchString(cmd.String())
+	}
+
+	for idx, part := range r.prefix {
+		if idx >= len(cmd.words) {
+			return false
+		}
+		if cmd.words[idx].dynamic {
+			return deny
+		}
+		if cmd.words[idx].text != part {
+			return false
+		}
+	}
+
+	return true
+}
+
+// commandPolicyRules is the compiled command policy
+type commandPolicyRules struct {
+	scope string // global or flow, it's used in the denial reason
+	allow []commandRule
+	deny  []commandRule
+}
+
+func compileCommandPolicy(scope string, policy CommandPolicy) (commandPolicyRules, error) {
+	rules := commandPolicyRules{scope: scope}
+
+	for _, rule := range policy.Allow {
+		compiled, err := compileCommandRule(rule)
+		if err != nil {
+			return rules, fmt.Errorf("%s command policy allowlist: %w", scope, err)
+		}
+		rules.allow = append(rules.allow, compiled)
+	}
+
+	for _, rule := range policy.Deny {
+		compiled, err := compileCommandRule(rule)
+		if err != nil {
+			return rules, fmt.Errorf("%s command policy denylist: %w", scope, err)
+		}
+		rules.deny = append(rules.deny, compiled)
+	}
+
+	return rules, nil
+}
+
+// check returns the reason of the denial if any of the parsed commands isn't permitted by the policy,
+// regular expressions of the denylist are matched against the whole command line as well
+func (p commandPolicyRules) check(command string, cmds []shellCommand) string {
+	for _, rule := range p.deny {
+		if rule.re != nil && rule.re.MatchString(command) {
+			return fmt.Sprintf("command line matches the rule '%s' of the %s command policy denylist", rule.rule, p.scope)
+		}
+	}
+
+	for _, cmd := range cmds {
+		if cmd.words[0].dynamic {
+			return fmt.Sprintf("command name '%s' is built from the expansion and can't be checked against the %s command policy",
+				cmd.words[0].text, p.scope)
+		}
+
+		for _, rule := range p.deny {
+			if rule.match(cmd, true) {
+				return fmt.Sprintf("command '%s' matches the rule '%s' of the %s command policy denylist",
+					cmd, rule.rule, p.scope)
+			}
+		}
+
+		if len(p.allow) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(p.allow, func(rule commandRule) bool { return rule.match(cmd, false) }) {
+			allowed := make([]string, 0, len(p.allow))
+			for _, rule := range p.allow {
+				allowed = append(allowed, rule.rule)
+			}
+			return fmt.Sprintf("command '%s' doesn't match any rule of the %s command policy allowlist: %s",
+				cmd, p.scope, strings.Join(allowed, ", "))
+		}
+	}
+
+	return ""
+}
+
+// CommandGuard checks the terminal commands against the global policy from the config and the policy of the flow,
+// the command has to pass both of them so the flow policy can only narrow the global one
+type CommandGuard struct {
+	db       database.Querier
+	policies []commandPolicyRules
+}
+
+func NewCommandGuard(db database.Querier, global, flow CommandPolicy) (*CommandGuard, error) {
+	guard := &CommandGuard{db: db}
+
+	// global policy is checked first to report its rules when both policies deny the command
+	for _, item := range []struct {
+		scope  string
+		policy CommandPolicy
+	}{{"global", global}, {"flow", flow}} {
+		if item.policy.IsEmpty() {
+			continue
+		}
+		rules, err := compileCommandPolicy(item.scope, item.policy)
+		if err != nil {
+			return nil, err
+		}
+		guard.policies = append(guard.policies, rules)
+	}
+
+	return guard, nil
+}
+
+// IsEmpty returns true if the guard permits all commands
+func (g *CommandGuard) IsEmpty() bool {
+	return g == nil || len(g.policies) == 0
+}
+
+// Check returns the reason of the denial or an empty string if the command is permitted,
+// the command which can't be parsed is denied because its parts can't be checked
+func (g *CommandGuard) Check(command string) string {
+	if g.IsEmpty() {
+		return ""
+	}
+
+	cmds, err := parseShellCommands(command)
+	if err != nil {
+		return fmt.Sprintf("command can't be parsed to check it against the command policy: %v", err)
+	}
+
+	for _, policy := range g.policies {
+		if reason := policy.check(command, cmds); reason != "" {
+			return reason
+		}
+	}
+
+	return ""
+}
+
+// Audit stores the denied command in the audit log on behalf of the flow owner
+func (g *CommandGuard) Audit(ctx context.Context, flowID int64, taskID, subtaskID *int64, command, reason string) {
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(flowID, taskID, subtaskID, logrus.Fields{
+		"reason": reason,
+	}))
+	logger.Warn("terminal command denied by the command policy")
+
+	if g == nil || g.db == nil {
+		return
+	}
+
+	err := writeFlowAuditLog(ctx, g.db, flowID, commandPolicyAuditAction, map[string]any{
+		"command": map[string]any{"before": nil, "after": command},
+	}, map[string]any{
+		"reason":     reason,
+		"task_id":    taskID,
+		"subtask_id": subtaskID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed to store denied command in audit log")
+	}
+}
+
+// shellWord is the word of the parsed command, dynamic word contains the expansions
+// which are known only at runtime so its text is kept as is in the source
+type shellWord struct {
+	text    string
+	dynamic bool
+}
+
+// shellCommand is the simple command of the shell script without redirections and variable assignments
+type shellCommand struct {
+	words []shellWord
+}
+
+func (c shellCommand) String() string {
+	parts := make([]string, 0, len(c.words))
+	for _, word := range c.words {
+		parts = append(parts, word.text)
+	}
+	return strings.Join(parts, " ")
+}
+
+type shellHeredoc struct {
+	delim  string
+	strip  bool // <<- strips leading tabs of the body lines
+	quoted bool // body of the quoted delimiter isn't expanded
+}
+
+// shellParser splits the shell script into the simple commands the same way as POSIX shell does:
+// it walks through the quotes, the command substitutions, the nested shells and the command wrappers
+// so the command can't be hidden behind the chaining operators from the policy check
+type shellParser struct {
+	src   string
+	pos   int
+	depth int
+	cmds  *[]shellCommand
+
+	words    []shellWord
+	word     strings.Builder
+	inWord   bool
+	quoted   bool
+	dynamic  bool
+	redirect bool
+	heredoc  *shellHeredoc
+	heredocs []shellHeredoc
+}
+
+// parseShellCommands returns all simple commands of the script including the nested ones
+func parseShellCommands(script string) ([]shellCommand, error) {
+	var cmds []shellCommand
+
+	p := &shellParser{src: script, cmds: &cmds}
+	if err := p.parse(0); err != nil {
+		return nil, err
+	}
+
+	return cmds, nil
+}
+
+func (p *shellParser) child(src string, pos int) *shellParser {
+	return &shellParser{src: src, pos: pos, depth: p.depth + 1, cmds: p.cmds}
+}
+
+func (p *shellParser) peek(offset int) byte {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *shellParser) appendByte(c byte) {
+	p.word.WriteByte(c)
+	p.inWord = true
+}
+
+// appendExpansion adds the source text of the expansion to the current word
+func (p *shellParser) appendExpansion(start int) {
+	p.word.WriteString(p.src[start:p.pos])
+	p.inWord, p.dynamic = true, true
+}
+
+func (p *shellParser) endWord() {
+	if !p.inWord {
+		return
+	}
+
+	word := shellWord{text: p.word.String(), dynamic: p.dynamic}
+	quoted := p.quoted
+	p.word.Reset()
+	p.inWord, p.quoted, p.dynamic = false, false, false
+
+	switch {
+	case p.heredoc != nil:
+		p.heredoc.delim, p.heredoc.quoted = word.text, quoted
+		p.heredocs = append(p.heredocs, *p.heredoc)
+		p.heredoc, p.redirect = nil, false
+	case p.redirect:
+		p.redirect = false
+	default:
+		p.words = append(p.words, word)
+	}
+}
+
+func (p *shellParser) endCommand() error {
+	p.endWord()
+	p.redirect, p.heredoc = false, nil
+
+	words := p.words
+	p.words = nil
+
+	return p.addCommand(words)
+}
+
+// addCommand strips assignments, keywords and wrappers from the command and adds the commands
+// which it runs, the scripts passed to the nested shells are parsed recursively
+func (p *shellParser) addCommand(words []shellWord) error {
+	for {
+		for len(words) > 0 && (shellAssignmentRegex.MatchString(words[0].text) ||
+			(!words[0].dynamic && slices.Contains(shellKeywords, words[0].text))) {
+			words = words[1:]
+		}
+		if len(words) == 0 || (!words[0].dynamic && slices.Contains(shellCompoundKeywords, words[0].text)) {
+			return nil
+		}
+
+		if !words[0].dynamic && strings.Contains(words[0].text, "/") {
+			// the words are shared with the wrapper command which keeps the path in its arguments
+			words = slices.Clone(words)
+			words[0].text = path.Base(words[0].text)
+		}
+		*p.cmds = append(*p.cmds, shellCommand{words: words})
+
+		name := words[0].text
+		if words[0].dynamic {
+			return nil
+		}
+		if script, ok := shellScriptArg(name, words[1:]); ok {
+			return p.child(script, 0).parse(0)
+		}
+		if name == "find" {
+			return p.addFindCommands(words[1:])
+		}
+
+		wrapper, ok := shellWrappers[name]
+		if !ok {
+			return nil
+		}
+		words = skipShellOptions(words[1:], wrapper)
+	}
+}
+
+// addFindCommands adds the commands which are executed by find for the found files
+func (p *shellParser) addFindCommands(args []shellWord) error {
+	for idx := 0; idx < len(args); idx++ {
+		switch args[idx].text {
+		case "-exec", "-execdir", "-ok", "-okdir":
+			end := idx + 1
+			for end < len(args) && args[end].text != ";" && args[end].text != "+" {
+				end++
+			}
+			if err := p.addCommand(slices.Clone(args[idx+1 : end])); err != nil {
+				return err
+			}
+			idx = end
+		}
+	}
+
+	return nil
+}
+
+// shellScriptArg returns the script which the command passes to the shell
+func shellScriptArg(name string, args []shellWord) (string, bool) {
+	switch {
+	case name == "eval":
+		return joinShellWords(args), true
+	case name == "watch":
+		return joinShellWords(skipShellOptions(args, shellWrapper{args: []string{"-n", "--interval"}})), true
+	case name == "su":
+		for idx, arg := range args {
+			if (arg.text == "-c" || arg.text == "--command") && idx+1 < len(args) {
+				return args[idx+1].text, true
+			}
+			if script, ok := strings.CutPrefix(arg.text, "--command="); ok {
+				return script, true
+			}
+		}
+	case name == "env":
+		for idx, arg := range args {
+			if (arg.text == "-S" || arg.text == "--split-string") && idx+1 < len(args) {
+				return joinShellWords(args[idx+1:]), true
+			}
+			if script, ok := strings.CutPrefix(arg.text, "--split-string="); ok {
+				return script + " " + joinShellWords(args[idx+1:]), true
+			}
+		}
+	case slices.Contains(shellInterpreters, name):
+		var command bool
+		for idx := 0; idx < len(args); idx++ {
+			arg := args[idx].text
+			if arg == "--" {
+				idx++
+			} else if len(arg) > 1 && (arg[0] == '-' || arg[0] == '+') {
+				if !strings.HasPrefix(arg, "--") {
+					command = command || strings.Contains(arg[1:], "c")
+					// option -o takes the name of the shell option
+					if strings.HasSuffix(arg, "o") || strings.HasSuffix(arg, "O") {
+						idx++
+					}
+				}
+				continue
+			}
+			// the first operand is the script only with -c option, otherwise it's the script file
+			if command && idx < len(args) {
+				return args[idx].text, true
+			}
+			break
+		}
+	}
+
+	return "", false
+}
+
+func skipShellOptions(words []shellWord, wrapper shellWrapper) []shellWord {
+	for len(words) > 0 {
+		word := words[0].text
+		if word == "--" {
+			words = words[1:]
+			break
+		}
+		if len(word) < 2 || word[0] != '-' || words[0].dynamic {
+			break
+		}
+		words = words[1:]
+		if slices.Contains(wrapper.args, word) && len(words) > 0 {
+			words = words[1:]
+		}
+	}
+
+	for range wrapper.operands {
+		if len(words) == 0 {
+			break
+		}
+		words = words[1:]
+	}
+
+	return words
+}
+
+func joinShellWords(words []shellWord) string {
+	return shellCommand{words: words}.String()
+}
+
+// parse walks through the script till its end or the unmatched stop character
+func (p *shellParser) parse(stop byte) error {
+	if p.depth > shellMaxNestingDepth {
+		return errors.New("command is nested too deeply")
+	}
+
+	var parens int
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case ' ', '\t', '\r':
+			p.endWord()
+			p.pos++
+		case '\n':
+			if err := p.endCommand(); err != nil {
+				return err
+			}
+			p.pos++
+			if err := p.readHeredocs(); err != nil {
+				return err
+			}
+		case ';', '|':
+			if err := p.endCommand(); err != nil {
+				return err
+			}
+			p.pos++
+		case '&':
+			if p.peek(1) == '>' {
+				p.parseRedirect()
+				continue
+			}
+			if err := p.endCommand(); err != nil {
+				return err
+			}
+			p.pos++
+		case '(', ')':
+			if c == ')' && stop == ')' && parens == 0 {
+				return p.endCommand()
+			}
+			if err := p.endCommand(); err != nil {
+				return err
+			}
+			if c == '(' {
+				parens++
+			} else {
+				parens--
+			}
+			p.pos++
+		case '<', '>':
+			if p.peek(1) == '(' {
+				start := p.pos
+				p.pos += 2
+				if err := p.parseNested(); err != nil {
+					return err
+				}
+				p.appendExpansion(start)
+				continue
+			}
+			p.parseRedirect()
+		case '\\':
+			if next := p.peek(1); next != '\n' && next != 0 {
+				p.appendByte(next)
+				p.quoted = true
+			}
+			p.pos += 2
+		case '\'':
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return errors.New("unterminated single quote")
+			}
+			p.word.WriteString(p.src[p.pos+1 : p.pos+1+end])
+			p.inWord, p.quoted = true, true
+			p.pos += end + 2
+		case '"':
+			p.pos++
+			if err := p.parseDoubleQuoted(); err != nil {
+				return err
+			}
+		case '`':
+			if err := p.parseBackquote(); err != nil {
+				return err
+			}
+		case '$':
+			if err := p.parseDollar(false); err != nil {
+				return err
+			}
+		case '#':
+			if p.inWord {
+				p.appendByte(c)
+				p.pos++
+				continue
+			}
+			if end := strings.IndexByte(p.src[p.pos:], '\n'); end >= 0 {
+				p.pos += end
+			} else {
+				p.pos = len(p.src)
+			}
+		default:
+			p.appendByte(c)
+			p.pos++
+		}
+	}
+
+	if stop != 0 {
+		return errors.New("unterminated command substitution")
+	}
+
+	return p.endCommand()
+}
+
+// scan walks through the text where only expansions are processed like the heredoc body or the parameter expansion
+func (p *shellParser) scan(stop byte) error {
+	if p.depth > shellMaxNestingDepth {
+		return errors.New("command is nested too deeply")
+	}
+
+	var open byte
+	switch stop {
+	case ')':
+		open = '('
+	case '}':
+		open = '{'
+	}
+
+	var nesting int
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '\\':
+			p.pos += 2
+		case c == '$':
+			if err := p.parseDollar(true); err != nil {
+				return err
+			}
+		case c == '`':
+			if err := p.parseBackquote(); err != nil {
+				return err
+			}
+		case stop != 0 && c == open:
+			nesting++
+			p.pos++
+		case stop != 0 && c == stop:
+			if nesting == 0 {
+				return nil
+			}
+			nesting--
+			p.pos++
+		default:
+			p.pos++
+		}
+	}
+
+	if stop != 0 {
+		return errors.New("unterminated expansion")
+	}
+
+	return nil
+}
+
+// parseNested parses the command substitution or the process substitution till the closing parenthesis
+func (p *shellParser) parseNested() error {
+	child := p.child(p.src, p.pos)
+	if err := child.parse(')'); err != nil {
+		return err
+	}
+	p.pos = child.pos + 1
+
+	return nil
+}
+
+func (p *shellParser) parseDoubleQuoted() error {
+	p.inWord, p.quoted = true, true
+
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return nil
+		case '\\':
+			switch next := p.peek(1); next {
+			case '\n':
+				p.pos += 2
+			case '$', '`', '"', '\\':
+				p.appendByte(next)
+				p.pos += 2
+			default:
+				p.appendByte(c)
+				p.pos++
+			}
+		case '$':
+			if err := p.parseDollar(true); err != nil {
+				return err
+			}
+		case '`':
+			if err := p.parseBackquote(); err != nil {
+				return err
+			}
+		default:
+			p.appendByte(c)
+			p.pos++
+		}
+	}
+
+	return errors.New("unterminated double quote")
+}
+
+func (p *shellParser) parseBackquote() error {
+	start := p.pos
+	p.pos++
+
+	var body strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return errors.New("unterminated backquote")
+		}
+
+		c := p.src[p.pos]
+		if c == '`' {
+			p.pos++
+			break
+		}
+		if next := p.peek(1); c == '\\' && (next == '`' || next == '$' || next == '\\') {
+			body.WriteByte(next)
+			p.pos += 2
+			continue
+		}
+		body.WriteByte(c)
+		p.pos++
+	}
+
+	if err := p.child(body.String(), 0).parse(0); err != nil {
+		return err
+	}
+	p.appendExpansion(start)
+
+	return nil
+}
+
+func (p *shellParser) parseDollar(inDouble bool) error {
+	start := p.pos
+	p.pos++
+
+	switch c := p.peek(0); {
+	case c == '(' && p.peek(1) == '(':
+		child := p.child(p.src, p.pos+2)
+		if err := child.scan(')'); err != nil {
+			return err
+		}
+		p.pos = child.pos + 1
+		if p.peek(0) != ')' {
+			return errors.New("unterminated arithmetic expansion")
+		}
+		p.pos++
+	case c == '(':
+		p.pos++
+		if err := p.parseNested(); err != nil {
+			return err
+		}
+	case c == '{':
+		child := p.child(p.src, p.pos+1)
+		if err := child.scan('}'); err != nil {
+			return err
+		}
+		p.pos = child.pos + 1
+	case c == '\'' && !inDouble:
+		// ANSI-C quoting may encode any characters with escapes so the word is dynamic if it has them
+		end := p.pos + 1
+		for ; end < len(p.src) && p.src[end] != '\''; end++ {
+			if p.src[end] == '\\' {
+				p.dynamic = true
+				end++
+			}
+		}
+		if end >= len(p.src) {
+			return errors.New("unterminated single quote")
+		}
+		p.word.WriteString(p.src[p.pos+1 : end])
+		p.inWord, p.quoted = true, true
+		p.pos = end + 1
+		return nil
+	case c == '"' && !inDouble:
+		p.pos++
+		return p.parseDoubleQuoted()
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		for c = p.peek(0); c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'); c = p.peek(0) {
+			p.pos++
+		}
+	case c != 0 && strings.IndexByte("0123456789@*#?$!-", c) >= 0:
+		p.pos++
+	default:
+		p.appendByte('$')
+		return nil
+	}
+
+	p.appendExpansion(start)
+
+	return nil
+}
+
+// parseRedirect skips the redirection operator and marks the next word as its target,
+// the file descriptor number before the operator isn't the word of the command
+func (p *shellParser) parseRedirect() {
+	if p.inWord && !p.quoted && !p.dynamic && strings.Trim(p.word.String(), "0123456789") == "" {
+		p.word.Reset()
+		p.inWord = false
+	}
+	p.endWord()
+
+	if p.peek(0) == '&' {
+		p.pos++
+	}
+
+	op := p.peek(0)
+	p.pos++
+
+	switch next := p.peek(0); {
+	case next == op && op == '<':
+		p.pos++
+		switch p.peek(0) {
+		case '<':
+			p.pos++ // here-string
+		case '-':
+			p.pos++
+			p.heredoc = &shellHeredoc{strip: true}
+		default:
+			p.heredoc = &shellHeredoc{}
+		}
+	case next == op, next == '&', next == '|', next == '>' && op == '<':
+		p.pos++
+	}
+
+	p.redirect = true
+}
+
+// readHeredocs skips the bodies of the heredocs which start after the line of the command,
+// expansions of the body are parsed if its delimiter isn't quoted
+func (p *shellParser) readHeredocs() error {
+	heredocs := p.heredocs
+	p.heredocs = nil
+
+	for _, heredoc := range heredocs {
+		for p.pos < len(p.src) {
+			end := strings.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				end = len(p.src) - p.pos
+			}
+			line := p.src[p.pos : p.pos+end]
+			p.pos = min(p.pos+end+1, len(p.src))
+
+			if heredoc.strip {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == heredoc.delim {
+				break
+			}
+			if !heredoc.quoted {
+				if err := p.child(line, 0).scan(0); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/docker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commandPolicyMockQuerier returns the flow owner and records the audit logs
+type commandPolicyMockQuerier struct {
+	database.Querier
+	logs []database.CreateAuditLogParams
+}
+
+func (m *commandPolicyMockQuerier) GetFlow(_ context.Context, id int64) (database.Flow, error) {
+	return database.Flow{ID: id, UserID: 5}, nil
+}
+
+func (m *commandPolicyMockQuerier) CreateAuditLog(
+	_ context.Context, arg database.CreateAuditLogParams,
+) (database.AuditLog, error) {
+	m.logs = append(m.logs, arg)
+	return database.AuditLog{ID: int64(len(m.logs)), UserID: arg.UserID, Action: arg.Action, FlowID: arg.FlowID}, nil
+}
+
+// commandPolicyMockDockerClient counts the executed commands, the container is never running
+type commandPolicyMockDockerClient struct {
+	docker.DockerClient
+	calls int
+}
+
+func (m *commandPolicyMockDockerClient) IsContainerRunning(_ context.Context, _ string) (bool, error) {
+	m.calls++
+	return false, nil
+}
+
+// recordingTermLogProvider keeps the terminal log messages
+type recordingTermLogProvider struct {
+	msgs []string
+}
+
+func (m *recordingTermLogProvider) PutMsg(
+	_ context.Context, msgType database.TermlogType, msg string, _ int64, _, _ *int64,
+) (int64, error) {
+	m.msgs = append(m.msgs, string(msgType)+": "+msg)
+	return int64(len(m.msgs)), nil
+}
+
+func parsedCommands(t *testing.T, script string) []string {
+	t.Helper()
+
+	cmds, err := parseShellCommands(script)
+	require.NoError(t, err, script)
+
+	result := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		result = append(result, cmd.String())
+	}
+	return result
+}
+
+func TestParseShellCommands(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		script string
+		want   []string
+	}{
+		"simple":           {"nmap -sV 10.0.0.5", []string{"nmap -sV 10.0.0.5"}},
+		"chaining":         {"cd /work && ls -la; id || whoami & sleep 1", []string{"cd /work", "ls -la", "id", "whoami", "sleep 1"}},
+		"pipes":            {"cat a.txt | grep -i pass |& tee out", []string{"cat a.txt", "grep -i pass", "tee out"}},
+		"new lines":        {"echo a\necho b\\\n  c", []string{"echo a", "echo b c"}},
+		"quotes":           {`echo "a; rm -rf /" 'b && c' d\;e`, []string{"echo a; rm -rf / b && c d;e"}},
+		"quoted name":      {`r"m" -rf /tmp/x; 'r'\m x`, []string{"rm -rf /tmp/x", "rm x"}},
+		"command path":     {"/usr/bin/rm -f x", []string{"rm -f x"}},
+		"substitution":     {"echo $(rm -rf / ; id) `uname -a`", []string{"rm -rf /", "id", "uname -a", "echo $(rm -rf / ; id) `uname -a`"}},
+		"nested quotes":    {`echo "$(curl "http://a/$(whoami)")"`, []string{"whoami", `curl http://a/$(whoami)`, `echo $(curl "http://a/$(whoami)")`}},
+		"process subst":    {"diff <(ls a) >(wc -l)", []string{"ls a", "wc -l", "diff <(ls a) >(wc -l)"}},
+		"subshell":         {"(cd /tmp; rm x) && { id; }", []string{"cd /tmp", "rm x", "id"}},
+		"redirections":     {"nmap -oX out.xml host > log 2>&1 < in &> all", []string{"nmap -oX out.xml host"}},
+		"fd redirect":      {"ls 2>/dev/null >&2", []string{"ls"}},
+		"assignments":      {"FOO=bar BAZ=$(id) curl x", []string{"id", "curl x"}},
+		"parameter":        {"echo ${HOME:-$(whoami)} $((1 + $(id -u)))", []string{"whoami", "id -u", "echo ${HOME:-$(whoami)} $((1 + $(id -u)))"}},
+		"comment":          {"ls # ; rm -rf /\nid", []string{"ls", "id"}},
+		"wrappers":         {"sudo -u root timeout -s KILL 10 nice -n 5 env A=1 rm x", []string{"sudo -u root timeout -s KILL 10 nice -n 5 env A=1 rm x", "timeout -s KILL 10 nice -n 5 env A=1 rm x", "nice -n 5 env A=1 rm x", "env A=1 rm x", "rm x"}},
+		"xargs":            {"find . -name '*.tmp' | xargs -n 1 rm", []string{"find . -name *.tmp", "xargs -n 1 rm", "rm"}},
+		"find exec":        {`find / -name x -exec rm -f {} \; -execdir sh -c 'id' +`, []string{`find / -name x -exec rm -f {} ; -execdir sh -c id +`, "rm -f {}", "sh -c id", "id"}},
+		"sh -c":            {`bash -ec "curl x | sh" && sh script.sh`, []string{"bash -ec curl x | sh", "curl x", "sh", "sh script.sh"}},
+		"sh -o":            {`bash -o pipefail -c 'id'`, []string{"bash -o pipefail -c id", "id"}},
+		"eval":             {`eval "rm -rf" /`, []string{"eval rm -rf /", "rm -rf /"}},
+		"su and env -S":    {`su -c 'id' root; env -S 'rm x'`, []string{"su -c id root", "id", "env -S rm x", "rm x"}},
+		"heredoc":          {"cat > a.sh <<EOF\nrm -rf /\n$(id)\nEOF\nsh a.sh", []string{"cat", "id", "sh a.sh"}},
+		"quoted heredoc":   {"cat <<-'EOF' | sh\n\trm -rf / $(id)\n\tEOF\nls", []string{"cat", "sh", "ls"}},
+		"here-string":      {"grep x <<< \"$(id)\"", []string{"id", "grep x"}},
+		"keywords":         {"if true; then rm x; fi; for f in $(ls); do cat $f; done", []string{"true", "rm x", "ls", "cat $f"}},
+		"ansi-c quoting":   {`echo $'a\nb'`, []string{`echo a\nb`}},
+		"dynamic name":     {"$CMD -rf /", []string{"$CMD -rf /"}},
+		"empty":            {"  ;; \n", []string{}},
+		"function":         {"f() { rm -rf /; }; f", []string{"f", "rm -rf /", "f"}},
+		"background group": {"nohup ./run.sh > /dev/null 2>&1 &", []string{"nohup ./run.sh", "run.sh"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parsedCommands(t, tc.script))
+		})
+	}
+}
+
+func TestParseShellCommandsErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, script := range []string{
+		`echo 'unterminated`,
+		`echo "unterminated`,
+		"echo `id",
+		"echo $(id",
+		"echo ${HOME",
+		"echo $((1 + 2)",
+		"sh -c 'sh -c \"echo $'",
+	} {
+		_, err := parseShellCommands(script)
+		assert.Error(t, err, script)
+	}
+
+	// nested shells are limited by the depth
+	script := "id"
+	for range shellMaxNestingDepth + 2 {
+		script = "$(" + script + ")"
+	}
+	_, err := parseShellCommands(script)
+	assert.ErrorContains(t, err, "nested too deeply")
+}
+
+func TestCommandGuardCheck(t *testing.T) {
+	t.Parallel()
+
+	global := CommandPolicy{Deny: []string{"rm -rf", "/\\b169\\.254\\.169\\.254\\b/", "shutdown"}}
+	flow := CommandPolicy{
+		Allow: []string{"nmap", "curl", "echo", "cat", "grep", "git", "sudo", "ls", "find", "/^python3? [a-z_]+\\.py$/"},
+		Deny:  []string{"nmap -sU", "git push"},
+	}
+	guard, err := NewCommandGuard(nil, global, flow)
+	require.NoError(t, err)
+
+	allowed := []string{
+		"nmap -sV -p 80,443 10.0.0.5",
+		"curl -s http://10.0.0.5/ | grep -i admin",
+		"echo 'rm -rf /' > notes.txt",
+		"cat <<EOF > notes.txt\nshutdown now\nEOF",
+		"git log --oneline && git pushx",
+		"sudo nmap -sS 10.0.0.5",
+		"python3 exploit.py",
+		"ls # rm -rf /",
+		"",
+	}
+	for _, command := range allowed {
+		assert.Empty(t, guard.Check(command), command)
+	}
+
+	denied := map[string]string{
+		"nmap -sU 10.0.0.5":                  "matches the rule 'nmap -sU' of the flow command policy denylist",
+		"/usr/bin/nmap -sU 10.0.0.5":         "matches the rule 'nmap -sU' of the flow command policy denylist",
+		"rm -rf /":                           "matches the rule 'rm -rf' of the global command policy denylist",
+		"ls; rm -rf /":                       "command 'rm -rf /'",
+		"ls && rm -rf /":                     "command 'rm -rf /'",
+		"ls || rm -rf /":                     "command 'rm -rf /'",
+		"ls | rm -rf /":                      "command 'rm -rf /'",
+		"ls & rm -rf /":                      "command 'rm -rf /'",
+		"ls\nrm -rf /":                       "command 'rm -rf /'",
+		"echo $(rm -rf /)":                   "command 'rm -rf /'",
+		"echo `rm -rf /`":                    "command 'rm -rf /'",
+		"cat <(rm -rf /)":                    "command 'rm -rf /'",
+		"(rm -rf /)":                         "command 'rm -rf /'",
+		"sh -c 'rm -rf /'":                   "command 'rm -rf /'",
+		"sudo rm -rf /":                      "command 'rm -rf /'",
+		"\\rm -rf /":                         "command 'rm -rf /'",
+		"'r'\"m\" -rf /":                     "command 'rm -rf /'",
+		"X=1 rm -rf /":                       "command 'rm -rf /'",
+		"$CMD -rf /":                         "command name '$CMD' is built from the expansion",
+		"git $(echo push) origin":            "matches the rule 'git push' of the flow command policy denylist",
+		"curl http://169.254.169.254/latest": "matches the rule '/\\b169\\.254\\.169\\.254\\b/' of the global command policy denylist",
+		"python3 -c 'print(\"169.254.169.254\")'": "command line matches the rule",
+		"wget http://10.0.0.5/":                   "command 'wget http://10.0.0.5/' doesn't match any rule of the flow command policy allowlist: nmap, curl",
+		"python3 ../exploit.py":                   "doesn't match any rule of the flow command policy allowlist",
+		"echo 'unterminated":                      "can't be parsed",
+		"find / -exec reboot \\;":                 "command 'reboot' doesn't match any rule of the flow command policy allowlist",
+		"eval shutdown":                           "command 'shutdown' matches the rule 'shutdown' of the global command policy denylist",
+	}
+	for command, want := range denied {
+		assert.Contains(t, guard.Check(command), want, command)
+	}
+}
+
+func TestCommandGuardScopes(t *testing.T) {
+	t.Parallel()
+
+	// flow policy can't lift the global one
+	guard, err := NewCommandGuard(nil, CommandPolicy{Allow: []string{"nmap"}}, CommandPolicy{Allow: []string{"nmap", "curl"}})
+	require.NoError(t, err)
+	assert.Empty(t, guard.Check("nmap 10.0.0.5"))
+	assert.Contains(t, guard.Check("curl 10.0.0.5"), "global command policy allowlist")
+
+	// empty policies permit everything including the commands which can't be parsed
+	guard, err = NewCommandGuard(nil, CommandPolicy{}, CommandPolicy{})
+	require.NoError(t, err)
+	assert.True(t, guard.IsEmpty())
+	assert.Empty(t, guard.Check("echo 'unterminated"))
+	assert.Empty(t, (*CommandGuard)(nil).Check("rm -rf /"))
+}
+
+func TestCommandRules(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		rule    string
+		command string
+		want    bool
+	}{
+		"prefix by words":      {"git push", "git push origin main", true},
+		"prefix isn't partial": {"git push", "git pushx", false},
+		"prefix path":          {"/bin/rm", "rm x", true},
+		"extra spaces":         {"  git   push ", "git push", true},
+		"regex anchors":        {"/^nmap -sU/", "nmap -sV -sU", false},
+		"regex unanchored":     {"/-sU/", "nmap -sV -sU", true},
+		"regex whole line":     {"/^ls$/", "ls -la", false},
+		"regex special chars":  {`/curl .*\|/`, "curl x", false},
+		"slash is prefix rule": {"/", "ls", false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rule, err := compileCommandRule(tc.rule)
+			require.NoError(t, err)
+
+			cmds, err := parseShellCommands(tc.command)
+			require.NoError(t, err)
+			require.Len(t, cmds, 1)
+			assert.Equal(t, tc.want, rule.match(cmds[0], false))
+		})
+	}
+}
+
+func TestCommandPolicyValid(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, CommandPolicy{}.Valid())
+	assert.NoError(t, CommandPolicy{Allow: []string{"nmap", "/^curl -s/"}, Deny: []string{"rm"}}.Valid())
+	assert.ErrorContains(t, CommandPolicy{Deny: []string{"/[a-/"}}.Valid(), "invalid command rule")
+	assert.ErrorContains(t, CommandPolicy{Allow: []string{" "}}.Valid(), "can't be empty")
+	assert.ErrorContains(t, CommandPolicy{Deny: make([]string, commandPolicyMaxRules+1)}.Valid(), "more than")
+
+	var policy CommandPolicy
+	require.NoError(t, policy.Scan([]byte(`{"allow":["nmap"],"deny":["nmap -sU"]}`)))
+	assert.Equal(t, CommandPolicy{Allow: []string{"nmap"}, Deny: []string{"nmap -sU"}}, policy)
+}
+
+func TestTerminalDeniedCommand(t *testing.T) {
+	t.Parallel()
+
+	db := &commandPolicyMockQuerier{}
+	guard, err := NewCommandGuard(db, CommandPolicy{Deny: []string{"rm"}}, CommandPolicy{})
+	require.NoError(t, err)
+
+	client := &commandPolicyMockDockerClient{}
+	tlp := &recordingTermLogProvider{}
+	taskID := int64(3)
+	term := NewTerminalTool(7, &taskID, nil, 1, "container", client, tlp, nil, guard)
+
+	args, err := json.Marshal(TerminalAction{Input: "ls && rm -rf /work", Cwd: "/work", Timeout: 60})
+	require.NoError(t, err)
+	result, err := term.Handle(t.Context(), TerminalToolName, args)
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "command was denied by the terminal command policy")
+	assert.Contains(t, result, "command 'rm -rf /work' matches the rule 'rm' of the global command policy denylist")
+	assert.Zero(t, client.calls, "denied command must not be executed")
+
+	require.Len(t, tlp.msgs, 2)
+	assert.Contains(t, tlp.msgs[0], "stdin: ")
+	assert.Contains(t, tlp.msgs[0], "ls && rm -rf /work")
+	assert.Contains(t, tlp.msgs[1], "stderr: ")
+
+	require.Len(t, db.logs, 1)
+	assert.Equal(t, int64(5), db.logs[0].UserID)
+	assert.Equal(t, int64(7), db.logs[0].FlowID)
+	assert.Equal(t, "flow.command_denied", db.logs[0].Action)
+	assert.JSONEq(t, `{"command":{"before":null,"after":"ls && rm -rf /work"}}`, string(db.logs[0].Changes))
+
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(db.logs[0].Metadata, &metadata))
+	assert.Equal(t, float64(3), metadata["task_id"])
+	assert.Contains(t, metadata["reason"], "rm -rf /work")
+
+	// allowed command goes to the container
+	args, err = json.Marshal(TerminalAction{Input: "ls -la", Cwd: "/work", Timeout: 60})
+	require.NoError(t, err)
+	result, err = term.Handle(t.Context(), TerminalToolName, args)
+	require.NoError(t, err)
+	assert.Contains(t, result, "container is not running")
+	assert.Equal(t, 1, client.calls)
+}
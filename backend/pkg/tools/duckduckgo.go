@@ -239,7 +239,7 @@ func (d *duckduckgo) search(ctx context.Context, query string, maxResults int) (
 	}
 
 	// Format results in readable text format
-	return d.formatSearchResults(response.Results), nil
+	return d.formatSearchResults(response.Results, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
 }
 
 // buildFormData creates form data for DuckDuckGo POST request
@@ -506,17 +506,24 @@ func (d *duckduckgo) cleanText(text string) string {
 	return text
 }
 
-// formatSearchResults formats search results in a readable text format
-func (d *duckduckgo) formatSearchResults(results []searchResult) string {
+// formatSearchResults formats search results in a readable text format,
+// results which don't fit into the size limit are dropped
+func (d *duckduckgo) formatSearchResults(results []searchResult, sizeLimit int) string {
 	var builder strings.Builder
 
+	budget := NewResultBudget(sizeLimit, 0)
 	for i, result := range results {
-		builder.WriteString(fmt.Sprintf("# %d. %s\n\n", i+1, result.Title))
-		builder.WriteString(fmt.Sprintf("## URL\n%s\n\n", result.URL))
-		builder.WriteString(fmt.Sprintf("## Description\n\n%s\n\n", result.Description))
+		var item strings.Builder
+		if i > 0 {
+			item.WriteString("---\n\n")
+		}
+		item.WriteString(fmt.Sprintf("# %d. %s\n\n", i+1, result.Title))
+		item.WriteString(fmt.Sprintf("## URL\n%s\n\n", result.URL))
+		item.WriteString(fmt.Sprintf("## Description\n\n%s\n\n", result.Description))
 
-		if i < len(results)-1 {
-			builder.WriteString("---\n\n")
+		if !budget.Add(&builder, item.String()) {
+			builder.WriteString(budget.Notice(budget.Added(), len(results), "results"))
+			break
 		}
 	}
 
@@ -493,7 +493,7 @@ func TestDuckDuckGoFormatResults(t *testing.T) {
 	ddg := &duckduckgo{}
 
 	t.Run("empty results", func(t *testing.T) {
-		result := ddg.formatSearchResults([]searchResult{})
+		result := ddg.formatSearchResults([]searchResult{}, DefaultMaxResultSize)
 		if result != "" {
 			t.Errorf("expected empty string for no results, got %q", result)
 		}
@@ -507,7 +507,7 @@ func TestDuckDuckGoFormatResults(t *testing.T) {
 				Description: "Go is a programming language",
 			},
 		}
-		result := ddg.formatSearchResults(results)
+		result := ddg.formatSearchResults(results, DefaultMaxResultSize)
 
 		if !strings.Contains(result, "# 1. Go Programming") {
 			t.Error("result should contain numbered title")
@@ -528,7 +528,7 @@ func TestDuckDuckGoFormatResults(t *testing.T) {
 			{Title: "First", URL: "https://first.com", Description: "first desc"},
 			{Title: "Second", URL: "https://second.com", Description: "second desc"},
 		}
-		result := ddg.formatSearchResults(results)
+		result := ddg.formatSearchResults(results, DefaultMaxResultSize)
 
 		if !strings.Contains(result, "# 1. First") {
 			t.Error("result should contain first title")
@@ -540,6 +540,28 @@ func TestDuckDuckGoFormatResults(t *testing.T) {
 			t.Error("result should contain separator between results")
 		}
 	})
+
+	t.Run("size limit", func(t *testing.T) {
+		results := make([]searchResult, 10)
+		for i := range results {
+			results[i] = searchResult{
+				Title:       fmt.Sprintf("Result %d", i+1),
+				URL:         "https://example.com",
+				Description: strings.Repeat("x", 20*1024),
+			}
+		}
+		result := ddg.formatSearchResults(results, DefaultMaxResultSize)
+
+		if len(result) > DefaultMaxResultSize {
+			t.Errorf("result size %d exceeds limit %d", len(result), DefaultMaxResultSize)
+		}
+		if !strings.Contains(result, "# 3. Result 3") || strings.Contains(result, "# 4. Result 4") {
+			t.Error("result should contain only results which fit into the limit")
+		}
+		if !strings.Contains(result, "Showing 3 of 10 results") {
+			t.Error("result should contain truncation note")
+		}
+	})
 }
 
 func TestDuckDuckGoMaxResultsClamp(t *testing.T) {
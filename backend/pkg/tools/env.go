@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	flowSecretsKeyIterations = 210000 // OWASP 2023 recommendation
+	flowSecretsKeyLength     = 32     // 256 bits for AES-256-GCM
+	redactedSecretTemplate   = "[REDACTED:%s]"
+)
+
+var (
+	ErrInvalidFlowSecret    = errors.New("invalid flow secret")
+	ErrFlowSecretsKeyNotSet = errors.New("flow secrets key is not set")
+)
+
+var flowSecretsKeys sync.Map // cache of flow secrets keys per config key
+
+// FlowEnv contains environment variables of the flow terminal commands,
+// values of the secrets are decrypted and must never be logged or stored as is
+type FlowEnv struct {
+	Vars    map[string]string
+	Secrets map[string]string
+}
+
+// Environ returns variables and secrets in KEY=VALUE format to pass them to the exec process
+func (fe FlowEnv) Environ() []string {
+	env := make([]string, 0, len(fe.Vars)+len(fe.Secrets))
+	for name, value := range fe.Vars {
+		env = append(env, name+"="+value)
+	}
+	for name, value := range fe.Secrets {
+		env = append(env, name+"="+value)
+	}
+	slices.Sort(env)
+
+	return env
+}
+
+// Redact replaces values of the secrets in the text to keep them out of terminal logs and LLM context
+func (fe FlowEnv) Redact(text string) string {
+	names := make([]string, 0, len(fe.Secrets))
+	for name, value := range fe.Secrets {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+
+	// longer values first to avoid partial replacement when one secret contains another one
+	slices.SortFunc(names, func(a, b string) int {
+		return len(fe.Secrets[b]) - len(fe.Secrets[a])
+	})
+	for _, name := range names {
+		text = strings.ReplaceAll(text, fe.Secrets[name], fmt.Sprintf(redactedSecretTemplate, name))
+	}
+
+	return text
+}
+
+type FlowEnvProvider interface {
+	GetFlowEnv(ctx context.Context) (FlowEnv, error)
+}
+
+type flowEnvProvider struct {
+	db     database.Querier
+	cfg    *config.Config
+	flowID int64
+}
+
+// NewFlowEnvProvider returns provider which reads the flow environment from DB on every call,
+// so rotated secrets are applied to the next command without the flow restart
+func NewFlowEnvProvider(db database.Querier, cfg *config.Config, flowID int64) FlowEnvProvider {
+	return &flowEnvProvider{
+		db:     db,
+		cfg:    cfg,
+		flowID: flowID,
+	}
+}
+
+func (fep *flowEnvProvider) GetFlowEnv(ctx context.Context) (FlowEnv, error) {
+	rows, err := fep.db.GetFlowEnv(ctx, fep.flowID)
+	if err != nil {
+		return FlowEnv{}, fmt.Errorf("failed to get flow %d env: %w", fep.flowID, err)
+	}
+
+	env := FlowEnv{
+		Vars:    make(map[string]string),
+		Secrets: make(map[string]string),
+	}
+	for _, row := range rows {
+		if !row.Secret {
+			env.Vars[row.Name] = row.Value
+			continue
+		}
+
+		value, err := DecryptFlowSecret(fep.cfg, row.Value)
+		if err != nil {
+			return FlowEnv{}, fmt.Errorf("failed to decrypt flow %d secret '%s': %w", fep.flowID, row.Name, err)
+		}
+		env.Secrets[row.Name] = value
+	}
+
+	return env, nil
+}
+
+// EncryptFlowSecret encrypts the secret value to store it in DB
+func EncryptFlowSecret(cfg *config.Config, value string) (string, error) {
+	aead, err := getFlowSecretsCipher(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptFlowSecret decrypts the secret value which was stored in DB
+func DecryptFlowSecret(cfg *config.Config, value string) (string, error) {
+	aead, err := getFlowSecretsCipher(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidFlowSecret, err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: value is too short", ErrInvalidFlowSecret)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidFlowSecret, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// FlowSecretsEnabled checks that the key to encrypt the flow secrets is configured
+func FlowSecretsEnabled(cfg *config.Config) bool {
+	return flowSecretsSecret(cfg) != ""
+}
+
+func getFlowSecretsCipher(cfg *config.Config) (cipher.AEAD, error) {
+	key, err := getFlowSecretsKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return aead, nil
+}
+
+// flowSecretsSecret returns the configured key, the empty one means that the secrets can't be stored,
+// there is no fallback to the cookie signing salt because its default value is known to everyone
+func flowSecretsSecret(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+
+	return cfg.FlowSecretsKey
+}
+
+func getFlowSecretsKey(cfg *config.Config) ([]byte, error) {
+	secret := flowSecretsSecret(cfg)
+	if secret == "" {
+		return nil, ErrFlowSecretsKeyNotSet
+	}
+
+	if cached, ok := flowSecretsKeys.Load(secret); ok {
+		return cached.([]byte), nil
+	}
+
+	salt := []byte("pentagi.flow.secrets|" + secret)
+	key := pbkdf2.Key([]byte(secret), salt, flowSecretsKeyIterations, flowSecretsKeyLength, sha512.New)
+
+	actual, _ := flowSecretsKeys.LoadOrStore(secret, key)
+	return actual.([]byte), nil
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticFlowEnvProvider struct {
+	env FlowEnv
+}
+
+func (p *staticFlowEnvProvider) GetFlowEnv(_ context.Context) (FlowEnv, error) {
+	return p.env, nil
+}
+
+var _ FlowEnvProvider = (*staticFlowEnvProvider)(nil)
+
+// envCapturingMockDockerClient records exec options to check the injected environment
+type envCapturingMockDockerClient struct {
+	contextAwareMockDockerClient
+	execOptions container.ExecOptions
+}
+
+func (m *envCapturingMockDockerClient) ContainerExecCreate(
+	_ context.Context, _ string, options container.ExecOptions,
+) (container.ExecCreateResponse, error) {
+	m.execOptions = options
+	return m.execCreateResp, nil
+}
+
+func TestFlowSecretEncryptDecrypt(t *testing.T) {
+	cfg := &config.Config{FlowSecretsKey: "test-flow-secrets-key"}
+
+	encrypted, err := EncryptFlowSecret(cfg, "s3cr3t-value")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "s3cr3t-value")
+
+	other, err := EncryptFlowSecret(cfg, "s3cr3t-value")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other, "nonce must be random for each encryption")
+
+	decrypted, err := DecryptFlowSecret(cfg, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t-value", decrypted)
+
+	_, err = DecryptFlowSecret(&config.Config{FlowSecretsKey: "another-key"}, encrypted)
+	assert.True(t, errors.Is(err, ErrInvalidFlowSecret))
+
+	_, err = DecryptFlowSecret(cfg, "not-base64!")
+	assert.True(t, errors.Is(err, ErrInvalidFlowSecret))
+
+	_, err = DecryptFlowSecret(cfg, "")
+	assert.True(t, errors.Is(err, ErrInvalidFlowSecret))
+}
+
+func TestFlowSecretKeyNotSet(t *testing.T) {
+	for _, cfg := range []*config.Config{nil, {}, {CookieSigningSalt: "salt"}} {
+		assert.False(t, FlowSecretsEnabled(cfg))
+
+		_, err := EncryptFlowSecret(cfg, "value")
+		assert.ErrorIs(t, err, ErrFlowSecretsKeyNotSet)
+
+		_, err = DecryptFlowSecret(cfg, "dmFsdWU=")
+		assert.ErrorIs(t, err, ErrFlowSecretsKeyNotSet)
+	}
+
+	assert.True(t, FlowSecretsEnabled(&config.Config{FlowSecretsKey: "key"}))
+}
+
+func TestFlowEnvEnviron(t *testing.T) {
+	env := FlowEnv{
+		Vars:    map[string]string{"TARGET": "10.0.0.1", "MODE": "fast"},
+		Secrets: map[string]string{"API_TOKEN": "token"},
+	}
+
+	assert.Equal(t, []string{"API_TOKEN=token", "MODE=fast", "TARGET=10.0.0.1"}, env.Environ())
+	assert.Empty(t, FlowEnv{}.Environ())
+}
+
+func TestFlowEnvRedact(t *testing.T) {
+	env := FlowEnv{
+		Vars: map[string]string{"TARGET": "10.0.0.1"},
+		Secrets: map[string]string{
+			"SHORT": "abc",
+			"LONG":  "abc-def",
+			"EMPTY": "",
+		},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"no secrets", "scan 10.0.0.1", "scan 10.0.0.1"},
+		{"single secret", "token=abc", "token=[REDACTED:SHORT]"},
+		{"longest secret first", "key abc-def and abc", "key [REDACTED:LONG] and [REDACTED:SHORT]"},
+		{"empty text", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, env.Redact(tt.text))
+		})
+	}
+}
+
+func TestExecCommandInjectsAndRedactsFlowEnv(t *testing.T) {
+	mock := &envCapturingMockDockerClient{
+		contextAwareMockDockerClient: contextAwareMockDockerClient{
+			isRunning:      true,
+			execCreateResp: container.ExecCreateResponse{ID: "exec-env-test"},
+			attachOutput:   []byte("Authorization: Bearer tok-123"),
+			inspectResp:    container.ExecInspect{ExitCode: 0},
+		},
+	}
+
+	term := &terminal{
+		flowID:       1,
+		containerID:  1,
+		containerLID: "test-container",
+		dockerClient: mock,
+		tlp:          &contextTestTermLogProvider{},
+		env: &staticFlowEnvProvider{env: FlowEnv{
+			Vars:    map[string]string{"TARGET": "example.com"},
+			Secrets: map[string]string{"API_TOKEN": "tok-123"},
+		}},
+	}
+
+	output, err := term.ExecCommand(t.Context(), "/work", "curl -H \"Authorization: Bearer tok-123\" $TARGET", false, time.Minute)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"API_TOKEN=tok-123", "TARGET=example.com"}, mock.execOptions.Env)
+	assert.NotContains(t, output, "tok-123")
+	assert.Contains(t, output, "[REDACTED:API_TOKEN]")
+	assert.Contains(t, strings.Join(mock.execOptions.Cmd, " "), "tok-123", "command itself must be executed as is")
+}
@@ -0,0 +1,346 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	epssAPIURL         = "https://api.first.org/data/v1/epss"
+	epssRequestTimeout = 30 * time.Second
+	epssCacheNamespace = "epss"
+	// EPSS scores are recalculated once a day, so half a day keeps them fresh enough
+	epssCacheTTL = 12 * time.Hour
+	// epssBatchSize is the max number of CVEs sent in one request, it's the default page size of the API
+	epssBatchSize = 100
+	maxEPSSCVEs   = 200
+)
+
+var epssCVERegexp = regexp.MustCompile(`(?i)^CVE-\d{4}-\d{4,}$`)
+
+// epssScore is the probability of exploitation activity in the next 30 days and its percentile
+// among all scored CVEs, Date is empty if FIRST has no score for the CVE
+type epssScore struct {
+	CVE        string  `json:"cve"`
+	EPSS       float64 `json:"epss"`
+	Percentile float64 `json:"percentile"`
+	Date       string  `json:"date,omitempty"`
+}
+
+// Found returns true if FIRST has the score for the CVE
+func (s epssScore) Found() bool {
+	return s.Date != ""
+}
+
+func (s epssScore) String() string {
+	return fmt.Sprintf("%.2f%% (percentile %.1f%%, %s)", s.EPSS*100, s.Percentile*100, s.Date)
+}
+
+type epssResponse struct {
+	Status string `json:"status"`
+	Total  int    `json:"total"`
+	Data   []struct {
+		CVE        string `json:"cve"`
+		EPSS       string `json:"epss"`
+		Percentile string `json:"percentile"`
+		Date       string `json:"date"`
+	} `json:"data"`
+}
+
+// epssClient looks up EPSS scores in batches and keeps every score in the shared cache,
+// it's used by the EPSS tool and to annotate exploits of other tools
+type epssClient struct {
+	cfg   *config.Config
+	cache Cache
+}
+
+func newEPSSClient(cfg *config.Config, cache Cache) *epssClient {
+	return &epssClient{cfg: cfg, cache: cache}
+}
+
+// Lookup returns scores of the given CVEs by upper-cased CVE ID, CVEs unknown to FIRST are
+// returned with empty date; only CVEs which aren't cached are requested from the API
+func (c *epssClient) Lookup(ctx context.Context, cves []string) (map[string]epssScore, error) {
+	scores := make(map[string]epssScore, len(cves))
+
+	var missing []string
+	for _, cve := range normalizeEPSSCVEs(cves) {
+		var score epssScore
+		if getCachedJSON(ctx, c.cache, epssCacheNamespace, cve, &score) {
+			scores[cve] = score
+			continue
+		}
+		missing = append(missing, cve)
+	}
+
+	for batch := range slices.Chunk(missing, epssBatchSize) {
+		fetched, err := c.fetch(ctx, batch)
+		if err != nil {
+			return scores, err
+		}
+
+		for _, cve := range batch {
+			score, ok := fetched[cve]
+			if !ok {
+				score = epssScore{CVE: cve}
+			}
+			scores[cve] = score
+			setCachedJSON(ctx, c.cache, epssCacheNamespace, cve, score, epssCacheTTL)
+		}
+	}
+
+	return scores, nil
+}
+
+func (c *epssClient) fetch(ctx context.Context, cves []string) (map[string]epssScore, error) {
+	client, err := system.GetHTTPClient(c.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = epssRequestTimeout
+
+	params := url.Values{}
+	params.Set("cve", strings.Join(cves, ","))
+	params.Set("limit", strconv.Itoa(len(cves)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, epssAPIURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to EPSS API failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("EPSS API returned HTTP %d", resp.StatusCode)
+	}
+
+	return parseEPSSResponse(resp.Body)
+}
+
+// parseEPSSResponse converts the API response where numbers are sent as strings
+func parseEPSSResponse(r io.Reader) (map[string]epssScore, error) {
+	var apiResp epssResponse
+	if err := json.NewDecoder(r).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode EPSS response: %w", err)
+	}
+
+	if apiResp.Status != "" && !strings.EqualFold(apiResp.Status, "OK") {
+		return nil, fmt.Errorf("EPSS API returned status %s", apiResp.Status)
+	}
+
+	scores := make(map[string]epssScore, len(apiResp.Data))
+	for _, item := range apiResp.Data {
+		epss, err := strconv.ParseFloat(item.EPSS, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EPSS score of %s: %w", item.CVE, err)
+		}
+		percentile, err := strconv.ParseFloat(item.Percentile, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EPSS percentile of %s: %w", item.CVE, err)
+		}
+
+		cve := strings.ToUpper(item.CVE)
+		scores[cve] = epssScore{CVE: cve, EPSS: epss, Percentile: percentile, Date: item.Date}
+	}
+
+	return scores, nil
+}
+
+// normalizeEPSSCVEs upper-cases CVE IDs and removes duplicates keeping the order
+func normalizeEPSSCVEs(cves []string) []string {
+	result := make([]string, 0, len(cves))
+	for _, cve := range cves {
+		cve = strings.ToUpper(strings.TrimSpace(cve))
+		if cve != "" && !slices.Contains(result, cve) {
+			result = append(result, cve)
+		}
+	}
+
+	return result
+}
+
+// epss represents the EPSS enrichment tool which prioritizes CVEs by exploitation likelihood
+type epss struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	client    *epssClient
+}
+
+// NewEPSSTool creates a new EPSS enrichment tool instance
+func NewEPSSTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &epss{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		client:    newEPSSClient(cfg, cache),
+	}
+}
+
+// Handle processes an EPSS lookup request from an AI agent
+func (e *epss) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !e.IsAvailable() {
+		return "", fmt.Errorf("epss is not available")
+	}
+
+	var action EPSSAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(e.flowID, e.taskID, e.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal epss action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	cves := normalizeEPSSCVEs(action.CVEs)
+	if len(cves) == 0 {
+		return "failed to get EPSS scores: no CVE IDs are passed", nil
+	}
+	if len(cves) > maxEPSSCVEs {
+		return fmt.Sprintf("failed to get EPSS scores: too many CVE IDs (%d), pass up to %d", len(cves), maxEPSSCVEs), nil
+	}
+
+	var invalid []string
+	for _, cve := range cves {
+		if !epssCVERegexp.MatchString(cve) {
+			invalid = append(invalid, cve)
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Sprintf("failed to get EPSS scores: invalid CVE IDs: %s, use form of CVE-YYYY-NNNN",
+			strings.Join(invalid, ", ")), nil
+	}
+
+	query := strings.Join(cves, ",")
+	logger = logger.WithField("cves", query[:min(len(query), 1000)])
+
+	scores, err := e.client.Lookup(ctx, cves)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("epss lookup error swallowed"),
+			langfuse.WithEventInput(query),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name": EPSSToolName,
+				"engine":    "epss",
+				"cves":      cves,
+				"error":     err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to get EPSS scores")
+		return fmt.Sprintf("failed to get EPSS scores: %v", err), nil
+	}
+
+	result := formatEPSSScores(cves, scores, resultSizeLimitsFromContext(ctx).MaxResultSize)
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && e.slp != nil {
+		_, _ = e.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeEpss,
+			query,
+			result,
+			e.taskID,
+			e.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// formatEPSSScores renders the scores as markdown table ordered by EPSS from the most likely
+// exploited CVE, CVEs without score go last in the requested order, the table is capped by the size limit
+func formatEPSSScores(cves []string, scores map[string]epssScore, sizeLimit int) string {
+	ordered := slices.Clone(cves)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		sa, sb := scores[a], scores[b]
+		if sa.Found() != sb.Found() {
+			if sa.Found() {
+				return -1
+			}
+			return 1
+		}
+		switch {
+		case sa.EPSS > sb.EPSS:
+			return -1
+		case sa.EPSS < sb.EPSS:
+			return 1
+		}
+		return 0
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# EPSS Scores\n\n")
+	sb.WriteString("EPSS is the probability of exploitation activity in the next 30 days, " +
+		"percentile is the share of all CVEs with lower or equal score.\n\n")
+	sb.WriteString("| # | CVE | EPSS | Percentile | Date |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	var unknown []string
+	budget := NewResultBudget(sizeLimit, sb.Len())
+	for i, cve := range ordered {
+		score := scores[cve]
+		if !score.Found() {
+			unknown = append(unknown, cve)
+			continue
+		}
+
+		line := fmt.Sprintf("| %d | %s | %.2f%% | %.1f%% | %s |\n",
+			i+1, cve, score.EPSS*100, score.Percentile*100, score.Date)
+		if !budget.Add(&sb, line) {
+			sb.WriteString(budget.Notice(budget.Added(), len(ordered), "CVEs"))
+			return sb.String()
+		}
+	}
+
+	if len(unknown) > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Not scored by EPSS:** %s (unknown, rejected or too recent CVEs)\n",
+			strings.Join(unknown, ", ")))
+	}
+
+	return sb.String()
+}
+
+// IsAvailable returns true if the EPSS lookups are enabled
+func (e *epss) IsAvailable() bool {
+	return e.cfg != nil && e.cfg.EPSSEnabled
+}
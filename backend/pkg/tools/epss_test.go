@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func TestParseEPSSResponse(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "epss_result_batch.json"))
+	if err != nil {
+		t.Fatalf("failed to open testdata: %v", err)
+	}
+	defer file.Close()
+
+	scores, err := parseEPSSResponse(file)
+	if err != nil {
+		t.Fatalf("parseEPSSResponse() unexpected error: %v", err)
+	}
+
+	if len(scores) != 3 {
+		t.Fatalf("len(scores) = %d, want 3", len(scores))
+	}
+
+	got := scores["CVE-2021-44228"]
+	want := epssScore{CVE: "CVE-2021-44228", EPSS: 0.94433, Percentile: 0.99992, Date: "2026-10-14"}
+	if got != want {
+		t.Errorf("scores[CVE-2021-44228] = %+v, want %+v", got, want)
+	}
+	if !got.Found() {
+		t.Error("Found() = false for scored CVE")
+	}
+	if got.String() != "94.43% (percentile 100.0%, 2026-10-14)" {
+		t.Errorf("String() = %q", got.String())
+	}
+}
+
+func TestParseEPSSResponse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"invalid json", `{"data":`, "failed to decode EPSS response"},
+		{"bad status", `{"status":"error","data":[]}`, "EPSS API returned status error"},
+		{"bad score", `{"status":"OK","data":[{"cve":"CVE-2021-1","epss":"n/a","percentile":"0.1"}]}`, "invalid EPSS score"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseEPSSResponse(strings.NewReader(tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseEPSSResponse() error = %v, want to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatEPSSScores(t *testing.T) {
+	cves := []string{"CVE-2022-27225", "CVE-2026-99999", "CVE-2021-44228", "CVE-2019-16759"}
+	scores := map[string]epssScore{
+		"CVE-2022-27225": {CVE: "CVE-2022-27225", EPSS: 0.00319, Percentile: 0.71241, Date: "2026-10-14"},
+		"CVE-2026-99999": {CVE: "CVE-2026-99999"},
+		"CVE-2021-44228": {CVE: "CVE-2021-44228", EPSS: 0.94433, Percentile: 0.99992, Date: "2026-10-14"},
+		"CVE-2019-16759": {CVE: "CVE-2019-16759", EPSS: 0.94277, Percentile: 0.99951, Date: "2026-10-14"},
+	}
+
+	got := formatEPSSScores(cves, scores, DefaultMaxResultSize)
+
+	first := strings.Index(got, "| 1 | CVE-2021-44228 | 94.43% | 100.0% | 2026-10-14 |")
+	second := strings.Index(got, "| 2 | CVE-2019-16759 | 94.28% |")
+	third := strings.Index(got, "| 3 | CVE-2022-27225 | 0.32% | 71.2% |")
+	if first < 0 || second < first || third < second {
+		t.Errorf("scores are not ordered by EPSS:\n%s", got)
+	}
+	if !strings.Contains(got, "**Not scored by EPSS:** CVE-2026-99999") {
+		t.Errorf("result missing unscored CVE:\n%s", got)
+	}
+}
+
+func TestEPSSIsAvailable(t *testing.T) {
+	if NewEPSSTool(nil, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true with nil config")
+	}
+	if NewEPSSTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+	if !NewEPSSTool(&config.Config{EPSSEnabled: true}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = false when enabled")
+	}
+}
+
+func TestEPSSHandle(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "epss_result_batch.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	var requests []string
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/data/v1/epss", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("cve"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	proxy, err := newTestProxy("api.first.org", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	slp := &searchLogProviderMock{}
+	tool := NewEPSSTool(&config.Config{
+		EPSSEnabled:       true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, slp, NewMemoryCache(10))
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	args := `{"cves":["cve-2022-27225","CVE-2021-44228","CVE-2019-16759","CVE-2026-99999","CVE-2021-44228"],"message":"prioritize"}`
+	for range 2 {
+		got, err := tool.Handle(ctx, EPSSToolName, []byte(args))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "| 1 | CVE-2021-44228 | 94.43% |") {
+			t.Errorf("result missing top CVE:\n%s", got)
+		}
+		if !strings.Contains(got, "**Not scored by EPSS:** CVE-2026-99999") {
+			t.Errorf("result missing unscored CVE:\n%s", got)
+		}
+	}
+
+	wantRequest := "CVE-2022-27225,CVE-2021-44228,CVE-2019-16759,CVE-2026-99999"
+	if len(requests) != 1 || requests[0] != wantRequest {
+		t.Errorf("requests = %q, want one batch %q with the second result from cache", requests, wantRequest)
+	}
+	if slp.calls != 2 || slp.engine != database.SearchengineTypeEpss || slp.query != wantRequest {
+		t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+	}
+}
+
+func TestEPSSHandle_Validation(t *testing.T) {
+	tool := NewEPSSTool(&config.Config{EPSSEnabled: true}, 1, nil, nil, nil, NewMemoryCache(10))
+
+	tooMany := make([]string, maxEPSSCVEs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("%q", fmt.Sprintf("CVE-2024-%d", 10000+i))
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty list", `{"cves":[" "]}`, "no CVE IDs are passed"},
+		{"invalid id", `{"cves":["CVE-2021-44228","GHSA-jfh8-c2jp-5v3q"]}`, "invalid CVE IDs: GHSA-JFH8-C2JP-5V3Q"},
+		{"too many", `{"cves":[` + strings.Join(tooMany, ",") + `]}`, "too many CVE IDs (201)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.Handle(t.Context(), EPSSToolName, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.Handle(t.Context(), EPSSToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+}
+
+func TestSploitusEnrichEPSS(t *testing.T) {
+	ctx := t.Context()
+	cache := NewMemoryCache(10)
+	for _, score := range []epssScore{
+		{CVE: "CVE-2021-44228", EPSS: 0.94433, Percentile: 0.99992, Date: "2026-10-14"},
+		{CVE: "CVE-2021-45046", EPSS: 0.9435, Percentile: 0.9998, Date: "2026-10-14"},
+		{CVE: "CVE-2022-27225", EPSS: 0.00319, Percentile: 0.71241, Date: "2026-10-14"},
+		{CVE: "CVE-2026-99999"},
+	} {
+		setCachedJSON(ctx, cache, epssCacheNamespace, score.CVE, score, epssCacheTTL)
+	}
+
+	resp := sploitusResponse{
+		Exploits: []sploitusExploit{
+			{ID: "EDB-1", Title: "Generic upload bypass"},
+			{ID: "CVE-2022-27225", Title: "Gin-Vue-Admin upload"},
+			{ID: "GH-1", Title: "Log4Shell CVE-2021-45046 and cve-2021-44228 scanner"},
+			{ID: "CVE-2026-99999", Title: "Fresh bug"},
+		},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		sp := &sploitus{cfg: testSploitusConfig(), cache: cache}
+		got := sp.enrichEPSS(ctx, "test", resp, true)
+		for _, item := range got.Exploits {
+			if item.epss != nil {
+				t.Errorf("exploit %s annotated while EPSS is disabled", item.ID)
+			}
+		}
+	})
+
+	sp := &sploitus{cfg: &config.Config{SploitusEnabled: true, EPSSEnabled: true}, cache: cache}
+
+	t.Run("annotate", func(t *testing.T) {
+		got := sp.enrichEPSS(ctx, "test", resp, false)
+		if got.Exploits[0].ID != "EDB-1" || got.Exploits[0].epss != nil {
+			t.Errorf("exploit without CVE changed: %+v", got.Exploits[0])
+		}
+		if got.Exploits[2].epss == nil || got.Exploits[2].epss.CVE != "CVE-2021-44228" {
+			t.Errorf("exploit with two CVEs should get the highest score: %+v", got.Exploits[2].epss)
+		}
+		if got.Exploits[3].epss != nil {
+			t.Errorf("exploit with unscored CVE annotated: %+v", got.Exploits[3].epss)
+		}
+		if resp.Exploits[2].epss != nil {
+			t.Error("enrichEPSS() modified the source response")
+		}
+
+		formatted := formatSploitusExploit(1, got.Exploits[2], DefaultMaxSourceSize)
+		if !strings.Contains(formatted, "**EPSS:** CVE-2021-44228 94.43% (percentile 100.0%, 2026-10-14)") {
+			t.Errorf("formatted exploit missing EPSS:\n%s", formatted)
+		}
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		got := sp.enrichEPSS(ctx, "test", resp, true)
+		var ids []string
+		for _, item := range got.Exploits {
+			ids = append(ids, item.ID)
+		}
+		want := "GH-1,CVE-2022-27225,EDB-1,CVE-2026-99999"
+		if strings.Join(ids, ",") != want {
+			t.Errorf("order = %s, want %s", strings.Join(ids, ","), want)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -15,10 +16,12 @@ import (
 	"pentagi/pkg/observability/langfuse"
 	"pentagi/pkg/schema"
 
+	"github.com/sirupsen/logrus"
 	"github.com/vxcontrol/langchaingo/documentloaders"
 	"github.com/vxcontrol/langchaingo/llms"
 	"github.com/vxcontrol/langchaingo/textsplitter"
 	"github.com/vxcontrol/langchaingo/vectorstores/pgvector"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const DefaultResultSizeLimit = 16 * 1024 // 16 KB
@@ -143,6 +146,11 @@ type customExecutor struct {
 	handlers    map[string]ExecutorHandler
 	barriers    map[string]struct{}
 	summarizer  SummarizeHandler
+	limiter     *toolCallLimiter
+	scope       *ScopeGuard
+	timeouts    ToolTimeouts
+	resultSize  ResultSizeLimits
+	redactor    *SecretRedactor
 }
 
 func (ce *customExecutor) Tools() []llms.Tool {
@@ -243,6 +251,28 @@ func (ce *customExecutor) Execute(
 	streamID int64,
 	id, name, obsName, thinking string,
 	args json.RawMessage,
+) (string, error) {
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "tools.customExecutor.Execute")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("flow.id", ce.flowID),
+		attribute.String("tool.name", name),
+		attribute.String("tool.type", GetToolType(name).String()),
+		attribute.String("tool.call_id", id),
+	)
+
+	result, err := ce.execute(ctx, streamID, id, name, obsName, thinking, args)
+	obs.SetSpanError(span, err)
+
+	return result, err
+}
+
+func (ce *customExecutor) execute(
+	ctx context.Context,
+	streamID int64,
+	id, name, obsName, thinking string,
+	args json.RawMessage,
 ) (string, error) {
 	startTime := time.Now()
 
@@ -256,17 +286,39 @@ func (ce *customExecutor) Execute(
 		return fmt.Sprintf("failed to unmarshal '%s' tool call arguments: %v: fix it", name, err), nil
 	}
 
+	// the handler gets the original arguments, the redacted ones are logged and stored
+	logArgs := ce.redactor.RedactArgs(args)
+
+	// network tools can reach only the in-scope targets of the flow, the denied call doesn't consume the limits
+	if target, reason := ce.scope.checkToolCall(name, args); reason != "" {
+		ce.scope.Audit(ctx, ce.flowID, ce.taskID, ce.subtaskID, name, target, reason)
+		return ce.rejectToolCall(ctx, id, name, logArgs, reason, "denied", formatScopeDeniedToolCall(name, reason))
+	}
+	ctx = withScopeGuard(ctx, ce.scope)
+	ctx = withResultSizeLimits(ctx, ce.resultSize)
+
+	// barrier tools are never throttled to let the agent finish the task
+	if !ce.IsBarrierFunction(name) {
+		reason, err := ce.limiter.allow(ctx, ce.db, ce.flowID, name)
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			return ce.throttleToolCall(ctx, id, name, logArgs, reason)
+		}
+	}
+
 	// Create observation based on tool type
 	toolType := GetToolType(name)
 	var obsWrapper observationWrapper
 
 	switch toolType {
 	case EnvironmentToolType, SearchNetworkToolType, StoreAgentResultToolType, StoreVectorDbToolType:
-		obsWrapper = ce.createToolObservation(ctx, obsName, args)
+		obsWrapper = ce.createToolObservation(ctx, obsName, logArgs)
 	case AgentToolType:
-		obsWrapper = ce.createAgentObservation(ctx, obsName, args)
+		obsWrapper = ce.createAgentObservation(ctx, obsName, logArgs)
 	case BarrierToolType:
-		obsWrapper = ce.createSpanObservation(ctx, obsName, args)
+		obsWrapper = ce.createSpanObservation(ctx, obsName, logArgs)
 	case SearchVectorDbToolType:
 		// Skip - handlers create RETRIEVER internally
 		obsWrapper = &noopObservationWrapper{context: ctx}
@@ -279,7 +331,7 @@ func (ce *customExecutor) Execute(
 	ctx = obsWrapper.ctx()
 
 	var err error
-	msgID, msg := int64(0), ce.getMessage(args)
+	msgID, msg := int64(0), ce.redactor.Redact(ce.getMessage(args))
 	if strings.Trim(msg, " \t\n\r") != "" {
 		msgType := getMessageType(name)
 		msgID, err = ce.mlp.PutMsg(ctx, msgType, ce.taskID, ce.subtaskID, streamID, thinking, msg)
@@ -292,7 +344,7 @@ func (ce *customExecutor) Execute(
 		CallID:    id,
 		Status:    database.ToolcallStatusRunning,
 		Name:      name,
-		Args:      args,
+		Args:      logArgs,
 		FlowID:    ce.flowID,
 		TaskID:    database.Int64ToNullInt64(ce.taskID),
 		SubtaskID: database.Int64ToNullInt64(ce.subtaskID),
@@ -302,35 +354,36 @@ func (ce *customExecutor) Execute(
 		return "", fmt.Errorf("failed to create toolcall: %w", err)
 	}
 
-	wrapHandler := func(ctx context.Context, name string, args json.RawMessage) (string, database.MsglogResultFormat, error) {
+	// wrapHandler returns the result for the agent and the redacted one for the logs
+	wrapHandler := func(ctx context.Context, name string, args json.RawMessage) (string, string, database.MsglogResultFormat, error) {
 		resultFormat := getMessageResultFormat(name)
-		result, err := handler(ctx, name, args)
+		result, err := ce.callHandler(ctx, handler, name, args)
 		if err != nil {
 			durationDelta := time.Since(startTime).Seconds()
 			_, _ = ce.db.UpdateToolcallFailedResult(ctx, database.UpdateToolcallFailedResultParams{
-				Result:          fmt.Sprintf("failed to execute handler: %s", err.Error()),
+				Result:          ce.redactor.Redact(fmt.Sprintf("failed to execute handler: %s", err.Error())),
 				DurationSeconds: durationDelta,
 				ID:              tc.ID,
 			})
-			return "", resultFormat, fmt.Errorf("failed to execute handler: %w", err)
+			return "", "", resultFormat, fmt.Errorf("failed to execute handler: %w", err)
 		}
 
-		result = database.SanitizeUTF8(result)
+		result = ce.redactor.RedactResult(database.SanitizeUTF8(result))
 		allowSummarize := slices.Contains(allowedSummarizingToolsResult, name)
 		if ce.summarizer != nil && allowSummarize && len(result) > DefaultResultSizeLimit {
-			summarizePrompt, err := ce.getSummarizePrompt(name, string(args), result)
+			summarizePrompt, err := ce.getSummarizePrompt(name, string(logArgs), result)
 			if err != nil {
-				return "", resultFormat, fmt.Errorf("failed to get summarize prompt: %w", err)
+				return "", "", resultFormat, fmt.Errorf("failed to get summarize prompt: %w", err)
 			}
 			result, err = ce.summarizer(ctx, summarizePrompt)
 			if err != nil {
 				durationDelta := time.Since(startTime).Seconds()
 				_, _ = ce.db.UpdateToolcallFailedResult(ctx, database.UpdateToolcallFailedResultParams{
-					Result:          fmt.Sprintf("failed to summarize result: %s", err.Error()),
+					Result:          ce.redactor.Redact(fmt.Sprintf("failed to summarize result: %s", err.Error())),
 					DurationSeconds: durationDelta,
 					ID:              tc.ID,
 				})
-				return "", resultFormat, fmt.Errorf("failed to summarize result: %w", err)
+				return "", "", resultFormat, fmt.Errorf("failed to summarize result: %w", err)
 			}
 			resultFormat = database.MsglogResultFormatMarkdown
 		} else if allowSummarize && len(result) > DefaultResultSizeLimit*2 {
@@ -343,48 +396,139 @@ func (ce *customExecutor) Execute(
 			)
 		}
 
+		logResult := ce.redactor.Redact(result)
 		durationDelta := time.Since(startTime).Seconds()
 		_, err = ce.db.UpdateToolcallFinishedResult(ctx, database.UpdateToolcallFinishedResultParams{
-			Result:          result,
+			Result:          logResult,
 			DurationSeconds: durationDelta,
 			ID:              tc.ID,
 		})
 		if err != nil {
-			return "", resultFormat, fmt.Errorf("failed to update toolcall result: %w", err)
+			return "", "", resultFormat, fmt.Errorf("failed to update toolcall result: %w", err)
 		}
 
-		return result, resultFormat, nil
+		return result, logResult, resultFormat, nil
 	}
 
 	if msg == "" { // no arg message to log and execute handler immediately
-		result, _, err := wrapHandler(ctx, name, args)
-		obsWrapper.end(result, err, time.Since(startTime).Seconds())
+		result, logResult, _, err := wrapHandler(ctx, name, args)
+		obsWrapper.end(logResult, err, time.Since(startTime).Seconds())
 		return result, err
 	}
 
-	result, resultFormat, err := wrapHandler(ctx, name, args)
+	result, logResult, resultFormat, err := wrapHandler(ctx, name, args)
 	if err != nil {
-		obsWrapper.end(result, err, time.Since(startTime).Seconds())
+		obsWrapper.end(logResult, err, time.Since(startTime).Seconds())
 		return "", err
 	}
 
-	if err := ce.storeToolResult(ctx, name, result, args); err != nil {
-		obsWrapper.end(result, err, time.Since(startTime).Seconds())
+	if err := ce.storeToolResult(ctx, name, logResult, logArgs); err != nil {
+		obsWrapper.end(logResult, err, time.Since(startTime).Seconds())
 		return "", fmt.Errorf("failed to store tool result in long-term memory: %w", err)
 	}
 
 	if msgID != 0 {
-		if err := ce.mlp.UpdateMsgResult(ctx, msgID, streamID, result, resultFormat); err != nil {
-			obsWrapper.end(result, err, time.Since(startTime).Seconds())
+		if err := ce.mlp.UpdateMsgResult(ctx, msgID, streamID, logResult, resultFormat); err != nil {
+			obsWrapper.end(logResult, err, time.Since(startTime).Seconds())
 			return "", err
 		}
 	}
 
-	obsWrapper.end(result, nil, time.Since(startTime).Seconds())
+	obsWrapper.end(logResult, nil, time.Since(startTime).Seconds())
 
 	return result, nil
 }
 
+// callHandler executes the handler within the tool timeout, the expired timeout replaces the handler result
+// by the timeout error if the parent context is still alive, otherwise the parent error is returned as is
+func (ce *customExecutor) callHandler(
+	ctx context.Context,
+	handler ExecutorHandler,
+	name string,
+	args json.RawMessage,
+) (string, error) {
+	timeout := ce.timeouts.Get(name)
+	handlerCtx, cancel := withToolTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := handler(handlerCtx, name, args)
+	if timeout > 0 && ctx.Err() == nil && errors.Is(handlerCtx.Err(), context.DeadlineExceeded) {
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"flow_id":   ce.flowID,
+			"tool_name": name,
+			"timeout":   timeout.String(),
+		}).WithError(err).Warn("tool call is timed out")
+		return "", &ToolTimeoutError{Tool: name, Timeout: timeout}
+	}
+
+	return result, err
+}
+
+// throttleToolCall records the throttled call as failed one and returns the throttle message to the agent
+func (ce *customExecutor) throttleToolCall(
+	ctx context.Context,
+	id, name string,
+	args json.RawMessage,
+	reason string,
+) (string, error) {
+	return ce.rejectToolCall(ctx, id, name, args, reason, "throttled", formatThrottledToolCall(name, reason))
+}
+
+// rejectToolCall records the call which isn't executed as failed one and returns the response to the agent,
+// status is the kind of the rejection to mark the trace
+func (ce *customExecutor) rejectToolCall(
+	ctx context.Context,
+	id, name string,
+	args json.RawMessage,
+	reason, status, response string,
+) (string, error) {
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"flow_id":      ce.flowID,
+		"tool_name":    name,
+		"tool_call_id": id,
+		"reason":       reason,
+	}).Warnf("tool call %s", status)
+
+	obs.Observer.SpanFromContext(ctx).SetAttributes(attribute.Bool("tool."+status, true))
+
+	_, observation := obs.Observer.NewObservation(ctx)
+	observation.Event(
+		langfuse.WithEventName("tool call "+status),
+		langfuse.WithEventInput(args),
+		langfuse.WithEventMetadata(langfuse.Metadata{
+			"tool_call_id": id,
+			"tool_name":    name,
+			"reason":       reason,
+		}),
+		langfuse.WithEventStatus(status),
+		langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+		langfuse.WithEventOutput(response),
+	)
+
+	tc, err := ce.db.CreateToolcall(ctx, database.CreateToolcallParams{
+		CallID:    id,
+		Status:    database.ToolcallStatusRunning,
+		Name:      name,
+		Args:      args,
+		FlowID:    ce.flowID,
+		TaskID:    database.Int64ToNullInt64(ce.taskID),
+		SubtaskID: database.Int64ToNullInt64(ce.subtaskID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s toolcall: %w", status, err)
+	}
+
+	_, err = ce.db.UpdateToolcallFailedResult(ctx, database.UpdateToolcallFailedResultParams{
+		Result: response,
+		ID:     tc.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s toolcall result: %w", status, err)
+	}
+
+	return response, nil
+}
+
 func (ce *customExecutor) IsBarrierFunction(name string) bool {
 	_, ok := ce.barriers[name]
 	return ok
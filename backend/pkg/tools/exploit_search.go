@@ -0,0 +1,333 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultExploitSearchLimit = 15
+	maxExploitSearchLimit     = 30
+	// exploitSearchTimeout bounds the fan-out if the caller context has no earlier deadline
+	exploitSearchTimeout = 60 * time.Second
+
+	exploitSourceSploitus  = "sploitus"
+	exploitSourceGithub    = "github"
+	exploitSourceExploitDB = "exploitdb"
+)
+
+// exploitSourceResult holds hits of a single exploit source converted to the Sploitus records
+type exploitSourceResult struct {
+	name  string
+	total int
+	items []sploitusExploit
+	err   error
+}
+
+// exploitSource searches exploits in a single engine and returns up to limit hits
+type exploitSource struct {
+	name   string
+	search func(ctx context.Context, query string, limit int) exploitSourceResult
+}
+
+// exploitSearch fans out the query to all configured exploit sources and merges their results
+type exploitSearch struct {
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	sources   []exploitSource
+}
+
+// NewExploitSearchTool creates a new aggregate exploit search tool over Sploitus, GitHub and ExploitDB,
+// only available sources are used
+func NewExploitSearchTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	es := &exploitSearch{
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+	}
+
+	sp := &sploitus{cfg: cfg, flowID: flowID, taskID: taskID, subtaskID: subtaskID, slp: slp, cache: cache}
+	if sp.IsAvailable() {
+		es.sources = append(es.sources, exploitSource{name: exploitSourceSploitus, search: sp.exploitHits})
+	}
+
+	gh := &github{cfg: cfg, flowID: flowID, taskID: taskID, subtaskID: subtaskID, slp: slp, cache: cache}
+	if gh.IsAvailable() {
+		es.sources = append(es.sources, exploitSource{name: exploitSourceGithub, search: gh.exploitHits})
+	}
+
+	if cfg != nil {
+		edb := &exploitDB{flowID: flowID, taskID: taskID, subtaskID: subtaskID, slp: slp}
+		if edb.index = exploitDBIndexes.get(cfg.ExploitDBPath); edb.IsAvailable() {
+			es.sources = append(es.sources, exploitSource{name: exploitSourceExploitDB, search: edb.exploitHits})
+		}
+	}
+
+	return es
+}
+
+// Handle processes an aggregate exploit search request from an AI agent
+func (es *exploitSearch) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !es.IsAvailable() {
+		return "", fmt.Errorf("exploit search is not available")
+	}
+
+	var action ExploitSearchAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(es.flowID, es.taskID, es.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal exploit search action")
+		return "", fmt.Errorf("failed to unmarshal %s search action arguments: %w", name, err)
+	}
+
+	limit := action.MaxResults.Int()
+	if limit < 1 || limit > maxExploitSearchLimit {
+		limit = defaultExploitSearchLimit
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"query":   action.Query[:min(len(action.Query), 1000)],
+		"limit":   limit,
+		"sources": len(es.sources),
+	})
+
+	results := es.fanOut(ctx, action.Query, limit)
+
+	var errs []error
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.name, res.err))
+		}
+	}
+
+	if len(errs) == len(results) {
+		err := errors.Join(errs...)
+		observation.Event(
+			langfuse.WithEventName("exploit search error swallowed"),
+			langfuse.WithEventInput(action.Query),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name": ExploitSearchToolName,
+				"engine":    "exploit_search",
+				"query":     action.Query,
+				"limit":     limit,
+				"error":     err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to search exploits in all sources")
+		return fmt.Sprintf("failed to search exploits in all sources: %v", err), nil
+	}
+
+	if len(errs) > 0 {
+		logger.WithError(errors.Join(errs...)).Warn("some exploit sources failed")
+	}
+
+	result := formatExploitSearchResults(action.Query, limit, results, resultSizeLimitsFromContext(ctx))
+
+	if agentCtx, ok := GetAgentContext(ctx); ok {
+		_, _ = es.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeExploitSearch,
+			action.Query,
+			result,
+			es.taskID,
+			es.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// fanOut queries all sources concurrently, results keep the order of sources,
+// all sources use the same context so the deadline aborts pending requests
+func (es *exploitSearch) fanOut(ctx context.Context, query string, limit int) []exploitSourceResult {
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, exploitSearchTimeout))
+	defer cancel()
+
+	var wg sync.WaitGroup
+	results := make([]exploitSourceResult, len(es.sources))
+	for i, source := range es.sources {
+		wg.Add(1)
+		go func(i int, source exploitSource) {
+			defer wg.Done()
+
+			results[i] = source.search(ctx, query, limit)
+			results[i].name = source.name
+		}(i, source)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// IsAvailable returns true if at least one exploit source is configured
+func (es *exploitSearch) IsAvailable() bool {
+	return len(es.sources) > 0
+}
+
+// exploitHits returns Sploitus exploits as hits of the aggregate search
+func (s *sploitus) exploitHits(ctx context.Context, query string, limit int) exploitSourceResult {
+	resp, err := s.request(ctx, query, sploitusTypeExploits, sploitusDefaultSort, 0)
+	if err != nil {
+		return exploitSourceResult{err: err}
+	}
+
+	items := resp.Exploits[:min(len(resp.Exploits), limit)]
+	hits := make([]sploitusExploit, 0, len(items))
+	for _, item := range items {
+		item.origin = exploitSourceSploitus
+		hits = append(hits, item)
+	}
+
+	return exploitSourceResult{total: resp.ExploitsTotal, items: hits}
+}
+
+// exploitHits returns GitHub repositories as hits of the aggregate search
+func (g *github) exploitHits(ctx context.Context, query string, limit int) exploitSourceResult {
+	resp, err := g.lookup(ctx, query, githubTypeRepositories, "", githubSortBestMatch, min(limit, maxGithubLimit))
+	if err != nil {
+		return exploitSourceResult{err: err}
+	}
+
+	hits := make([]sploitusExploit, 0, len(resp.Repositories))
+	for _, repo := range resp.Repositories {
+		title := repo.FullName
+		if repo.Description != "" {
+			title += ": " + repo.Description
+		}
+
+		updated := repo.PushedAt
+		if updated == "" {
+			updated = repo.UpdatedAt
+		}
+
+		hits = append(hits, sploitusExploit{
+			ID:        repo.FullName,
+			Title:     truncateGithubText(title),
+			Type:      "repository",
+			Href:      repo.HTMLURL,
+			Published: githubDate(updated),
+			Language:  repo.Language,
+			origin:    exploitSourceGithub,
+		})
+	}
+
+	return exploitSourceResult{total: resp.TotalCount, items: hits}
+}
+
+// exploitHits returns ExploitDB records as hits of the aggregate search
+func (e *exploitDB) exploitHits(_ context.Context, query string, limit int) exploitSourceResult {
+	matches := e.index.search(query)
+
+	items := matches[:min(len(matches), limit)]
+	hits := make([]sploitusExploit, 0, len(items))
+	for _, entry := range items {
+		// CVE codes are kept in the title to collapse the record with its copies from other sources
+		title := entry.Title
+		if cves := sploitusCVERegexp.FindAllString(strings.Join(entry.Codes, " "), -1); len(cves) > 0 {
+			title += " [" + strings.Join(cves, ", ") + "]"
+		}
+
+		hits = append(hits, sploitusExploit{
+			ID:        "EDB-" + entry.ID,
+			Title:     title,
+			Type:      strings.Trim(entry.Platform+"/"+entry.Type, "/"),
+			Href:      exploitDBURL + entry.ID,
+			Published: entry.Published,
+			origin:    exploitSourceExploitDB,
+		})
+	}
+
+	return exploitSourceResult{total: len(matches), items: hits}
+}
+
+// mergeExploitHits interleaves hits of all sources in their rank order so the top results
+// of every source fit into the budget, then collapses duplicates across sources
+func mergeExploitHits(results []exploitSourceResult) sploitusResponse {
+	var resp sploitusResponse
+
+	for rank := 0; ; rank++ {
+		added := false
+		for _, res := range results {
+			if rank < len(res.items) {
+				resp.Exploits = append(resp.Exploits, res.items[rank])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+
+	for _, res := range results {
+		resp.ExploitsTotal += res.total
+	}
+
+	return dedupSploitusExploits(resp)
+}
+
+// formatExploitSearchResults renders merged hits of all sources with the Sploitus section helpers
+// keeping the output under the total size limit, failed sources are listed in the header
+func formatExploitSearchResults(query string, limit int, results []exploitSourceResult, limits ResultSizeLimits) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Exploit Search Results\n\n")
+	sb.WriteString(fmt.Sprintf("**Query:** `%s`  \n", query))
+
+	var sources, failures []string
+	for _, res := range results {
+		if res.err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%v)", res.name, res.err))
+			continue
+		}
+		sources = append(sources, fmt.Sprintf("%s (%d of %d matches)", res.name, len(res.items), res.total))
+	}
+	if len(sources) > 0 {
+		sb.WriteString(fmt.Sprintf("**Sources:** %s  \n", strings.Join(sources, ", ")))
+	}
+	if len(failures) > 0 {
+		sb.WriteString(fmt.Sprintf("**Failed sources:** %s  \n", strings.Join(failures, "; ")))
+	}
+
+	resp := mergeExploitHits(results)
+
+	var section strings.Builder
+	stats := writeSploitusSection(&section, sploitusTypeExploits, limit, resp.Exploits,
+		limits.MaxResultSize-sb.Len()-summaryLineBuffer, limits.MaxSourceSize)
+	stats.total, stats.received, stats.filtered = resp.ExploitsTotal, sploitusReceived(resp), resp.filtered
+
+	sb.WriteString(fmt.Sprintf("**Displaying:** %s\n\n", stats))
+	sb.WriteString("---\n\n")
+	sb.WriteString(section.String())
+
+	return sb.String()
+}
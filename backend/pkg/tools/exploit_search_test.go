@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+// stubExploitSource returns a source which yields count hits or the provided error
+func stubExploitSource(name string, count int, err error) exploitSource {
+	return exploitSource{
+		name: name,
+		search: func(_ context.Context, query string, limit int) exploitSourceResult {
+			if err != nil {
+				return exploitSourceResult{err: err}
+			}
+
+			var items []sploitusExploit
+			for i := 1; i <= min(count, limit); i++ {
+				items = append(items, sploitusExploit{
+					ID:     fmt.Sprintf("%s-%d", name, i),
+					Title:  fmt.Sprintf("%s exploit %d for %s", name, i, query),
+					Type:   name,
+					Href:   fmt.Sprintf("https://%s.example.com/%d", name, i),
+					origin: name,
+				})
+			}
+
+			return exploitSourceResult{total: count, items: items}
+		},
+	}
+}
+
+func TestExploitSearchHandle(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"exploits":[
+				{
+					"id":"PACKETSTORM:164418",
+					"title":"Apache HTTP Server 2.4.49 Path Traversal CVE-2021-41773",
+					"type":"packetstorm",
+					"href":"https://packetstormsecurity.com/files/164418/",
+					"score":7.5,
+					"published":"2021-10-06"
+				},
+				{
+					"id":"GITHUB:00001",
+					"title":"Apache 2.4.49 mass scanner",
+					"type":"githubexploit",
+					"href":"https://github.com/test/scanner",
+					"published":"2021-10-08"
+				}
+			],
+			"exploits_total":12
+		}`))
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	taskID := int64(10)
+	subtaskID := int64(20)
+	slp := &searchLogProviderMock{}
+
+	cfg := &config.Config{
+		SploitusEnabled:   true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+		ExploitDBPath:     exploitDBTestdataPath,
+	}
+
+	tool := NewExploitSearchTool(cfg, 1, &taskID, &subtaskID, slp, nil)
+	es, ok := tool.(*exploitSearch)
+	if !ok {
+		t.Fatalf("unexpected tool type %T", tool)
+	}
+	if len(es.sources) != 2 {
+		t.Fatalf("sources = %d, want 2 (sploitus and exploitdb)", len(es.sources))
+	}
+	es.sources = append(es.sources, stubExploitSource(exploitSourceGithub, 0, errors.New("rate limit exceeded")))
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	got, err := es.Handle(ctx, ExploitSearchToolName, []byte(`{"query":"CVE-2021-41773","max_results":10,"message":"m"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"# Exploit Search Results",
+		"**Query:** `CVE-2021-41773`",
+		"**Sources:** sploitus (2 of 12 matches), exploitdb (2 of 2 matches)",
+		"**Failed sources:** github (rate limit exceeded)",
+		"**URL:** https://packetstormsecurity.com/files/164418/",
+		"**Source:** sploitus",
+		"**Alternate sources:**",
+		"- exploitdb/multiple/webapps: https://www.exploit-db.com/exploits/50383",
+		"- exploitdb/multiple/webapps: https://www.exploit-db.com/exploits/50512",
+		"Apache 2.4.49 mass scanner",
+	}
+	for _, part := range expected {
+		if !strings.Contains(got, part) {
+			t.Errorf("result missing %q:\n%s", part, got)
+		}
+	}
+	// the ExploitDB records are collapsed into the Sploitus one with the higher score
+	if strings.Contains(got, "### 3. ") {
+		t.Errorf("duplicates across sources must be collapsed:\n%s", got)
+	}
+
+	if slp.calls != 1 {
+		t.Errorf("PutLog() calls = %d, want 1", slp.calls)
+	}
+	if slp.engine != database.SearchengineTypeExploitSearch {
+		t.Errorf("engine = %q, want %q", slp.engine, database.SearchengineTypeExploitSearch)
+	}
+	if slp.taskID == nil || *slp.taskID != taskID || slp.subtaskID == nil || *slp.subtaskID != subtaskID {
+		t.Errorf("unexpected task and subtask IDs: %v, %v", slp.taskID, slp.subtaskID)
+	}
+
+	if _, err := es.Handle(t.Context(), ExploitSearchToolName, []byte("{")); err == nil {
+		t.Error("expected error for invalid json")
+	}
+}
+
+func TestExploitSearchHandle_AllSourcesFailed(t *testing.T) {
+	slp := &searchLogProviderMock{}
+	es := &exploitSearch{
+		flowID: 1,
+		slp:    slp,
+		sources: []exploitSource{
+			stubExploitSource(exploitSourceSploitus, 0, errors.New("sploitus is down")),
+			stubExploitSource(exploitSourceGithub, 0, errors.New("bad credentials")),
+		},
+	}
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	got, err := es.Handle(ctx, ExploitSearchToolName, []byte(`{"query":"nginx","max_results":5}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	for _, part := range []string{"failed to search exploits in all sources", "sploitus is down", "bad credentials"} {
+		if !strings.Contains(got, part) {
+			t.Errorf("result missing %q: %q", part, got)
+		}
+	}
+	if slp.calls != 0 {
+		t.Errorf("PutLog() calls = %d, want 0", slp.calls)
+	}
+}
+
+func TestExploitSearchHandle_ResultBudget(t *testing.T) {
+	es := &exploitSearch{
+		flowID: 1,
+		slp:    &searchLogProviderMock{},
+		sources: []exploitSource{
+			stubExploitSource(exploitSourceSploitus, 10, nil),
+			stubExploitSource(exploitSourceGithub, 10, nil),
+		},
+	}
+
+	got, err := es.Handle(t.Context(), ExploitSearchToolName, []byte(`{"query":"nginx","max_results":3}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	// top hits of every source are interleaved so each source fits into the budget
+	expected := []string{
+		"### 1. sploitus exploit 1 for nginx",
+		"### 2. github exploit 1 for nginx",
+		"### 3. sploitus exploit 2 for nginx",
+	}
+	for _, part := range expected {
+		if !strings.Contains(got, part) {
+			t.Errorf("result missing %q:\n%s", part, got)
+		}
+	}
+	if strings.Contains(got, "### 4. ") {
+		t.Errorf("result must be limited to 3 items:\n%s", got)
+	}
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("result size %d exceeds limit %d", len(got), DefaultMaxResultSize)
+	}
+}
+
+func TestExploitSearchHandle_RespectsContextDeadline(t *testing.T) {
+	slow := exploitSource{
+		name: exploitSourceGithub,
+		search: func(ctx context.Context, _ string, _ int) exploitSourceResult {
+			<-ctx.Done()
+			return exploitSourceResult{err: ctx.Err()}
+		},
+	}
+	es := &exploitSearch{
+		flowID:  1,
+		slp:     &searchLogProviderMock{},
+		sources: []exploitSource{stubExploitSource(exploitSourceSploitus, 1, nil), slow},
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	got, err := es.Handle(ctx, ExploitSearchToolName, []byte(`{"query":"nginx","max_results":5}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Handle() took %v, the context deadline must abort slow sources", elapsed)
+	}
+
+	for _, part := range []string{"**Failed sources:** github (context deadline exceeded)", "sploitus exploit 1 for nginx"} {
+		if !strings.Contains(got, part) {
+			t.Errorf("result missing %q:\n%s", part, got)
+		}
+	}
+}
+
+func TestExploitSearchIsAvailable(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want bool
+	}{
+		{name: "nil config", cfg: nil, want: false},
+		{name: "no sources", cfg: &config.Config{}, want: false},
+		{name: "sploitus", cfg: &config.Config{SploitusEnabled: true}, want: true},
+		{name: "github", cfg: &config.Config{GithubSearchToken: "token"}, want: true},
+		{name: "exploitdb", cfg: &config.Config{ExploitDBPath: exploitDBTestdataPath}, want: true},
+		{name: "missing exploitdb file", cfg: &config.Config{ExploitDBPath: "testdata/missing.csv"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := NewExploitSearchTool(tt.cfg, 1, nil, nil, nil, nil)
+			if got := es.IsAvailable(); got != tt.want {
+				t.Errorf("IsAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	es := NewExploitSearchTool(&config.Config{}, 1, nil, nil, nil, nil)
+	if _, err := es.Handle(t.Context(), ExploitSearchToolName, []byte(`{"query":"nginx"}`)); err == nil {
+		t.Error("expected error when no source is available")
+	}
+}
@@ -0,0 +1,402 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	exploitDBURL          = "https://www.exploit-db.com/exploits/"
+	exploitDBRawURL       = "https://gitlab.com/exploit-database/exploitdb/-/raw/main/"
+	defaultExploitDBLimit = 10
+	maxExploitDBLimit     = 25
+)
+
+// exploitDBEntry is a single record of the exploitdb files_exploits.csv
+type exploitDBEntry struct {
+	ID        string
+	File      string
+	Title     string
+	Published string
+	Author    string
+	Type      string
+	Platform  string
+	Port      string
+	Verified  bool
+	Codes     []string
+
+	// search is the lowercased text which is matched against query terms
+	search string
+}
+
+// exploitDBIndex holds all records of the CSV ordered by publication date, newest first
+type exploitDBIndex struct {
+	entries []exploitDBEntry
+}
+
+// exploitDBStore keeps loaded indexes by CSV path because tools are created for each agent chain,
+// failed loads are stored as nil to avoid reading missing file on every tool creation
+type exploitDBStore struct {
+	mx      *sync.Mutex
+	indexes map[string]*exploitDBIndex
+}
+
+var exploitDBIndexes = &exploitDBStore{
+	mx:      &sync.Mutex{},
+	indexes: make(map[string]*exploitDBIndex),
+}
+
+// LoadExploitDB reads the ExploitDB CSV from the configured path into the in-memory index,
+// it's called at startup and returns the number of loaded records
+func LoadExploitDB(cfg *config.Config) (int, error) {
+	if cfg == nil || cfg.ExploitDBPath == "" {
+		return 0, nil
+	}
+
+	index, err := exploitDBIndexes.load(cfg.ExploitDBPath)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(index.entries), nil
+}
+
+func (s *exploitDBStore) load(path string) (*exploitDBIndex, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if index, ok := s.indexes[path]; ok {
+		if index == nil {
+			return nil, fmt.Errorf("exploitdb csv '%s' was not loaded", path)
+		}
+		return index, nil
+	}
+
+	index, err := loadExploitDBFile(path)
+	if err != nil {
+		s.indexes[path] = nil
+		return nil, err
+	}
+
+	s.indexes[path] = index
+	return index, nil
+}
+
+// get returns the loaded index or loads it on first use, nil means CSV is not available
+func (s *exploitDBStore) get(path string) *exploitDBIndex {
+	if path == "" {
+		return nil
+	}
+
+	index, _ := s.load(path)
+	return index
+}
+
+func loadExploitDBFile(path string) (*exploitDBIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exploitdb csv: %w", err)
+	}
+	defer file.Close()
+
+	return parseExploitDBCSV(file)
+}
+
+// parseExploitDBCSV builds the index from files_exploits.csv content,
+// columns are resolved by the header names to tolerate format changes
+func parseExploitDBCSV(r io.Reader) (*exploitDBIndex, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exploitdb csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"id", "file", "description"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("exploitdb csv doesn't have required column '%s'", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if idx, ok := columns[name]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	var entries []exploitDBEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exploitdb csv record: %w", err)
+		}
+
+		entry := exploitDBEntry{
+			ID:        field(record, "id"),
+			File:      field(record, "file"),
+			Title:     field(record, "description"),
+			Published: field(record, "date_published"),
+			Author:    field(record, "author"),
+			Type:      field(record, "type"),
+			Platform:  field(record, "platform"),
+			Port:      field(record, "port"),
+			Verified:  field(record, "verified") == "1",
+		}
+		if entry.ID == "" || entry.Title == "" {
+			continue
+		}
+		for _, code := range strings.Split(field(record, "codes"), ";") {
+			if code = strings.TrimSpace(code); code != "" {
+				entry.Codes = append(entry.Codes, code)
+			}
+		}
+
+		entry.search = strings.ToLower(strings.Join([]string{
+			entry.Title, strings.Join(entry.Codes, " "), field(record, "aliases"), "edb-" + entry.ID,
+		}, " "))
+		entries = append(entries, entry)
+	}
+
+	// ISO dates are sorted as strings, ties are ordered by the id for deterministic output
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Published != entries[j].Published {
+			return entries[i].Published > entries[j].Published
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	return &exploitDBIndex{entries: entries}, nil
+}
+
+// search returns all entries which contain every term of the query in the title, codes or aliases
+func (idx *exploitDBIndex) search(query string) []exploitDBEntry {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []exploitDBEntry
+	for _, entry := range idx.entries {
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(entry.search, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, entry)
+		}
+	}
+
+	return matches
+}
+
+// exploitDB represents the offline ExploitDB search tool working on the bundled CSV
+type exploitDB struct {
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	index     *exploitDBIndex
+}
+
+// NewExploitDBTool creates a new offline ExploitDB search tool instance
+func NewExploitDBTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+) Tool {
+	var index *exploitDBIndex
+	if cfg != nil {
+		index = exploitDBIndexes.get(cfg.ExploitDBPath)
+	}
+
+	return &exploitDB{
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		index:     index,
+	}
+}
+
+// Handle processes an ExploitDB search request from an AI agent
+func (e *exploitDB) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !e.IsAvailable() {
+		return "", fmt.Errorf("exploitdb is not available")
+	}
+
+	var action ExploitDBAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(e.flowID, e.taskID, e.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal exploitdb search action")
+		return "", fmt.Errorf("failed to unmarshal %s search action arguments: %w", name, err)
+	}
+
+	limit := action.MaxResults.Int()
+	if limit < 1 || limit > maxExploitDBLimit {
+		limit = defaultExploitDBLimit
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"query": action.Query[:min(len(action.Query), 1000)],
+		"limit": limit,
+	})
+
+	if strings.TrimSpace(action.Query) == "" {
+		err := errors.New("search query is empty")
+		observation.Event(
+			langfuse.WithEventName("exploitdb search error swallowed"),
+			langfuse.WithEventInput(action.Query),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name": ExploitDBToolName,
+				"engine":    "exploitdb",
+				"query":     action.Query,
+				"limit":     limit,
+				"error":     err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to search in ExploitDB")
+		return fmt.Sprintf("failed to search in ExploitDB: %v", err), nil
+	}
+
+	matches := e.index.search(action.Query)
+	result := formatExploitDBResults(action.Query, limit, matches, resultSizeLimitsFromContext(ctx).MaxResultSize)
+
+	if agentCtx, ok := GetAgentContext(ctx); ok {
+		_, _ = e.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeExploitdb,
+			action.Query,
+			result,
+			e.taskID,
+			e.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// IsAvailable returns true if the ExploitDB CSV was found and loaded
+func (e *exploitDB) IsAvailable() bool {
+	return e.index != nil
+}
+
+// formatExploitDBResults renders matched entries in the same layout as Sploitus results
+// keeping the output under the total size limit
+func formatExploitDBResults(query string, limit int, matches []exploitDBEntry, totalLimit int) string {
+	var sb strings.Builder
+
+	sb.WriteString("# ExploitDB Search Results\n\n")
+	sb.WriteString(fmt.Sprintf("**Query:** `%s`  \n", query))
+	sb.WriteString(fmt.Sprintf("**Total matches in ExploitDB:** %d  \n", len(matches)))
+
+	results := matches
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	if len(results) == 0 {
+		sb.WriteString("\n---\n\n")
+		sb.WriteString("No exploits were found for the given query.\n")
+		return sb.String()
+	}
+
+	var section strings.Builder
+	budget := NewResultBudget(totalLimit-sb.Len()-summaryLineBuffer, 0)
+	for i, entry := range results {
+		if !budget.Add(&section, formatExploitDBEntry(i+1, entry)) {
+			break
+		}
+	}
+	shown := budget.Added()
+
+	sb.WriteString(fmt.Sprintf("**Displaying:** %d of %d matches  \n", shown, len(matches)))
+	sb.WriteString("\n---\n\n")
+	sb.WriteString(fmt.Sprintf("## Exploits (showing up to %d)\n\n", len(results)))
+	sb.WriteString(section.String())
+
+	if shown < len(results) {
+		sb.WriteString(fmt.Sprintf(
+			"\n**Note:** Results truncated, showing %d of %d results (exceeded %s limit)\n",
+			shown, len(results), formatSizeLimit(totalLimit),
+		))
+	}
+
+	return sb.String()
+}
+
+// formatExploitDBEntry renders a single ExploitDB record
+func formatExploitDBEntry(idx int, entry exploitDBEntry) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %d. %s\n\n", idx, entry.Title))
+	sb.WriteString(fmt.Sprintf("**URL:** %s%s  \n", exploitDBURL, entry.ID))
+	sb.WriteString(fmt.Sprintf("**ID:** EDB-%s  \n", entry.ID))
+	if entry.File != "" {
+		sb.WriteString(fmt.Sprintf("**Path:** %s  \n", entry.File))
+		sb.WriteString(fmt.Sprintf("**Raw URL:** %s%s  \n", exploitDBRawURL, entry.File))
+	}
+	if entry.Platform != "" {
+		sb.WriteString(fmt.Sprintf("**Platform:** %s  \n", entry.Platform))
+	}
+	if entry.Type != "" {
+		sb.WriteString(fmt.Sprintf("**Type:** %s  \n", entry.Type))
+	}
+	if entry.Port != "" && entry.Port != "0" {
+		sb.WriteString(fmt.Sprintf("**Port:** %s  \n", entry.Port))
+	}
+	if entry.Published != "" {
+		sb.WriteString(fmt.Sprintf("**Published:** %s  \n", entry.Published))
+	}
+	if entry.Author != "" {
+		sb.WriteString(fmt.Sprintf("**Author:** %s  \n", entry.Author))
+	}
+	if entry.Verified {
+		sb.WriteString("**Verified:** yes  \n")
+	}
+	if len(entry.Codes) > 0 {
+		sb.WriteString(fmt.Sprintf("**Codes:** %s  \n", strings.Join(entry.Codes, ", ")))
+	}
+	sb.WriteString("\n---\n\n")
+
+	return sb.String()
+}
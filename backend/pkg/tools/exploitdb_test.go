@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+const exploitDBTestdataPath = "testdata/exploitdb_files_exploits.csv"
+
+func loadExploitDBTestIndex(t *testing.T) *exploitDBIndex {
+	t.Helper()
+
+	index, err := loadExploitDBFile(exploitDBTestdataPath)
+	if err != nil {
+		t.Fatalf("failed to load exploitdb testdata: %v", err)
+	}
+
+	return index
+}
+
+func TestExploitDBIndexSearch(t *testing.T) {
+	index := loadExploitDBTestIndex(t)
+	if len(index.entries) != 7 {
+		t.Fatalf("loaded entries = %d, want 7", len(index.entries))
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "apache", want: 4},
+		{query: "Apache 2.4.50", want: 2},
+		{query: "apache rce traversal", want: 2},
+		{query: "CVE-2021-41773", want: 2},
+		{query: "cve-2011-2523 metasploit", want: 1},
+		{query: "log4shell", want: 1},
+		{query: "edb-49757", want: 1},
+		{query: "vsftpd nginx", want: 0},
+		{query: "   ", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := len(index.search(tt.query)); got != tt.want {
+				t.Errorf("search(%q) hits = %d, want %d", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// results are ordered by publication date, newest first
+	matches := index.search("vsftpd")
+	if len(matches) != 2 || matches[0].ID != "49757" || matches[1].ID != "17491" {
+		t.Errorf("unexpected order of matches: %+v", matches)
+	}
+}
+
+func TestExploitDBParseCSV_Errors(t *testing.T) {
+	if _, err := parseExploitDBCSV(strings.NewReader("")); err == nil {
+		t.Error("expected error for empty csv")
+	}
+	if _, err := parseExploitDBCSV(strings.NewReader("id,title\n1,test\n")); err == nil {
+		t.Error("expected error for csv without required columns")
+	}
+	if _, err := loadExploitDBFile(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestExploitDBIsAvailable(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want bool
+	}{
+		{
+			name: "available with existing csv",
+			cfg:  &config.Config{ExploitDBPath: exploitDBTestdataPath},
+			want: true,
+		},
+		{
+			name: "unavailable with missing csv",
+			cfg:  &config.Config{ExploitDBPath: filepath.Join(t.TempDir(), "files_exploits.csv")},
+			want: false,
+		},
+		{
+			name: "unavailable without path",
+			cfg:  &config.Config{},
+			want: false,
+		},
+		{
+			name: "unavailable with nil config",
+			cfg:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edb := NewExploitDBTool(tt.cfg, 0, nil, nil, nil)
+			if got := edb.IsAvailable(); got != tt.want {
+				t.Errorf("IsAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadExploitDB(t *testing.T) {
+	count, err := LoadExploitDB(&config.Config{ExploitDBPath: exploitDBTestdataPath})
+	if err != nil {
+		t.Fatalf("LoadExploitDB() unexpected error: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("LoadExploitDB() count = %d, want 7", count)
+	}
+
+	if count, err := LoadExploitDB(&config.Config{}); err != nil || count != 0 {
+		t.Errorf("LoadExploitDB() without path = %d, %v, want 0, nil", count, err)
+	}
+
+	if _, err := LoadExploitDB(&config.Config{ExploitDBPath: filepath.Join(t.TempDir(), "x.csv")}); err == nil {
+		t.Error("expected error for missing csv")
+	}
+}
+
+func TestExploitDBHandle(t *testing.T) {
+	taskID := int64(10)
+	subtaskID := int64(20)
+	slp := &searchLogProviderMock{}
+
+	cfg := &config.Config{ExploitDBPath: exploitDBTestdataPath}
+	edb := NewExploitDBTool(cfg, 1, &taskID, &subtaskID, slp)
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	got, err := edb.Handle(ctx, ExploitDBToolName, []byte(`{"query":"apache 2.4.50","max_results":1,"message":"m"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"# ExploitDB Search Results",
+		"**Query:** `apache 2.4.50`",
+		"**Total matches in ExploitDB:** 2",
+		"**Displaying:** 1 of 2 matches",
+		"### 1. Apache HTTP Server 2.4.50 - Path Traversal & Remote Code Execution (RCE)",
+		"**URL:** https://www.exploit-db.com/exploits/50512",
+		"**ID:** EDB-50512",
+		"**Path:** exploits/multiple/webapps/50512.py",
+		"**Platform:** multiple",
+		"**Type:** webapps",
+		"**Published:** 2021-11-11",
+		"**Codes:** CVE-2021-42013, CVE-2021-41773",
+	}
+	for _, part := range expected {
+		if !strings.Contains(got, part) {
+			t.Errorf("result missing %q:\n%s", part, got)
+		}
+	}
+	if strings.Contains(got, "### 2. ") {
+		t.Errorf("result must be limited to 1 item:\n%s", got)
+	}
+
+	if slp.calls != 1 {
+		t.Errorf("PutLog() calls = %d, want 1", slp.calls)
+	}
+	if slp.engine != database.SearchengineTypeExploitdb {
+		t.Errorf("engine = %q, want %q", slp.engine, database.SearchengineTypeExploitdb)
+	}
+	if slp.taskID == nil || *slp.taskID != taskID || slp.subtaskID == nil || *slp.subtaskID != subtaskID {
+		t.Errorf("unexpected task and subtask IDs: %v, %v", slp.taskID, slp.subtaskID)
+	}
+
+	got, err = edb.Handle(t.Context(), ExploitDBToolName, []byte(`{"query":"nginx","max_results":5}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "No exploits were found") {
+		t.Errorf("result missing no results message: %q", got)
+	}
+
+	got, err = edb.Handle(t.Context(), ExploitDBToolName, []byte(`{"query":" ","max_results":5}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "failed to search in ExploitDB") {
+		t.Errorf("expected swallowed error for empty query: %q", got)
+	}
+
+	if _, err := edb.Handle(t.Context(), ExploitDBToolName, []byte("{")); err == nil {
+		t.Error("expected error for invalid json")
+	}
+}
+
+func TestExploitDBSizeLimits(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,file,description,date_published,type,platform,codes\n")
+	for i := 0; i < 2000; i++ {
+		codes := make([]string, 20)
+		for j := range codes {
+			codes[j] = fmt.Sprintf("CVE-2024-%05d", i*20+j)
+		}
+		sb.WriteString(fmt.Sprintf("%d,exploits/linux/remote/%d.py,Test Exploit %d %s,2024-01-01,remote,linux,%s\n",
+			i, i, i, strings.Repeat("X", 500), strings.Join(codes, ";")))
+	}
+
+	index, err := parseExploitDBCSV(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("parseExploitDBCSV() unexpected error: %v", err)
+	}
+
+	matches := index.search("test exploit")
+	if len(matches) != 2000 {
+		t.Fatalf("search hits = %d, want 2000", len(matches))
+	}
+
+	result := formatExploitDBResults("test exploit", 2000, matches, DefaultMaxResultSize)
+	if len(result) > DefaultMaxResultSize {
+		t.Errorf("result size %d exceeds 80 KB hard limit", len(result))
+	}
+	if !strings.Contains(result, "Results truncated") {
+		t.Error("expected truncation warning when hitting 80 KB limit")
+	}
+	if count := strings.Count(result, "### "); count >= 2000 {
+		t.Errorf("expected fewer results due to size limit, got %d", count)
+	}
+}
@@ -0,0 +1,482 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/providers/embeddings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	flowMemoryMaxKeyLength   = 255
+	flowMemoryMaxValueLength = 16 * 1024
+	flowMemoryMaxTags        = 16
+	flowMemoryMaxTagLength   = 64
+	flowMemoryMaxResults     = 50
+	flowMemorySemanticTopK   = 10
+	// flowMemoryMaxVectorScan limits the facts compared by the application without pgvector
+	flowMemoryMaxVectorScan = 1000
+)
+
+// FlowMemoryEmbedder embeds the flow memory facts for the semantic search, vectors are stored
+// with the model name to never compare vectors of the different models
+type FlowMemoryEmbedder struct {
+	embeddings.Embedder
+	Model      string
+	Dimensions int
+}
+
+// NewFlowMemoryEmbedder returns embedder for the flow memory semantic search or nil if it's disabled,
+// the embedder of the flow is used unless the separate provider or model is configured
+func NewFlowMemoryEmbedder(cfg *config.Config, embedder embeddings.Embedder) *FlowMemoryEmbedder {
+	if cfg == nil || !cfg.FlowMemorySemanticSearch {
+		return nil
+	}
+
+	provider, model := cfg.EmbeddingProvider, cfg.EmbeddingModel
+	if cfg.FlowMemoryEmbeddingProvider != "" || cfg.FlowMemoryEmbeddingModel != "" {
+		override := *cfg
+		if cfg.FlowMemoryEmbeddingProvider != "" {
+			override.EmbeddingProvider = cfg.FlowMemoryEmbeddingProvider
+		}
+		if cfg.FlowMemoryEmbeddingModel != "" {
+			override.EmbeddingModel = cfg.FlowMemoryEmbeddingModel
+		}
+
+		var err error
+		if embedder, err = embeddings.New(&override); err != nil {
+			logrus.WithError(err).Warn("failed to create flow memory embedder, semantic search is disabled")
+			return nil
+		}
+		provider, model = override.EmbeddingProvider, override.EmbeddingModel
+	}
+
+	if embedder == nil || !embedder.IsAvailable() {
+		return nil
+	}
+
+	return &FlowMemoryEmbedder{
+		Embedder:   embedder,
+		Model:      provider + "/" + model,
+		Dimensions: cfg.FlowMemoryEmbeddingDimensions,
+	}
+}
+
+func (e *FlowMemoryEmbedder) embed(ctx context.Context, text string, query bool) ([]float32, error) {
+	var vector []float32
+	if query {
+		result, err := e.EmbedQuery(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		vector = result
+	} else {
+		result, err := e.EmbedDocuments(ctx, []string{text})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed fact: %w", err)
+		}
+		if len(result) != 0 {
+			vector = result[0]
+		}
+	}
+
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("embedding model %s returned empty vector", e.Model)
+	}
+	if e.Dimensions > 0 && len(vector) != e.Dimensions {
+		return nil, fmt.Errorf("embedding model %s returned %d dimensions instead of %d",
+			e.Model, len(vector), e.Dimensions)
+	}
+
+	return vector, nil
+}
+
+// flowMemoryMatch is the fact found by the semantic search with its cosine similarity to the query
+type flowMemoryMatch struct {
+	database.FlowMemory
+	Similarity float32
+}
+
+// flowMemory keeps the structured facts found by the agents, every fact is identified by the key
+// inside the flow and the facts of the other flows are never visible
+type flowMemory struct {
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	db        database.Querier
+	embedder  *FlowMemoryEmbedder
+}
+
+// NewFlowMemoryTool creates a new tool to store, retrieve and search the flow facts,
+// the search is semantic if the embedder is set
+func NewFlowMemoryTool(
+	flowID int64,
+	taskID, subtaskID *int64,
+	db database.Querier,
+	embedder *FlowMemoryEmbedder,
+) Tool {
+	return &flowMemory{
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		db:        db,
+		embedder:  embedder,
+	}
+}
+
+func (fm *flowMemory) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !fm.IsAvailable() {
+		return "", fmt.Errorf("flow memory is not available")
+	}
+
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(fm.flowID, fm.taskID, fm.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	var action FlowMemoryAction
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal flow memory action")
+		return "", fmt.Errorf("failed to unmarshal flow memory action: %w", err)
+	}
+
+	tags, err := normalizeFlowMemoryTags(action.Tags)
+	if err != nil {
+		return err.Error(), nil
+	}
+	key := strings.TrimSpace(action.Key)
+	limit := int(action.Limit.Int64())
+	if limit <= 0 || limit > flowMemoryMaxResults {
+		limit = flowMemoryMaxResults
+	}
+
+	switch action.Action {
+	case StoreFlowMemory:
+		if err := validateFlowMemoryFact(key, action.Value); err != nil {
+			return err.Error(), nil
+		}
+		item, err := fm.db.UpsertFlowMemory(ctx, database.UpsertFlowMemoryParams{
+			Key:       key,
+			Value:     action.Value,
+			Tags:      tags,
+			FlowID:    fm.flowID,
+			TaskID:    database.Int64ToNullInt64(fm.taskID),
+			SubtaskID: database.Int64ToNullInt64(fm.subtaskID),
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to store flow memory")
+			return "", fmt.Errorf("failed to store flow memory: %w", err)
+		}
+		if err := fm.index(ctx, item); err != nil {
+			// the fact is still available for the keyword search
+			logger.WithError(err).Warn("failed to index flow memory for semantic search")
+		}
+		return fmt.Sprintf("fact '%s' is stored to the flow memory", item.Key), nil
+	case RetrieveFlowMemory:
+		if key != "" {
+			item, err := fm.db.GetFlowMemory(ctx, database.GetFlowMemoryParams{
+				FlowID: fm.flowID,
+				Key:    key,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Sprintf("fact '%s' not found, use the '%s' action to find the facts by keyword",
+					key, SearchFlowMemory), nil
+			} else if err != nil {
+				logger.WithError(err).Error("failed to get flow memory")
+				return "", fmt.Errorf("failed to get flow memory: %w", err)
+			}
+			return formatFlowMemories([]database.FlowMemory{item}, 0), nil
+		}
+		items, err := fm.db.GetFlowMemories(ctx, database.GetFlowMemoriesParams{
+			FlowID:  fm.flowID,
+			Tags:    tags,
+			MaxRows: int32(limit),
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to get flow memories")
+			return "", fmt.Errorf("failed to get flow memories: %w", err)
+		}
+		return formatFlowMemories(items, limit), nil
+	case SearchFlowMemory:
+		query := strings.TrimSpace(action.Query)
+		if query == "" {
+			return "query is required for the 'search' action", nil
+		}
+		if fm.embedder != nil {
+			topK := int(action.Limit.Int64())
+			if topK <= 0 || topK > flowMemoryMaxResults {
+				topK = flowMemorySemanticTopK
+			}
+			matches, err := fm.semanticSearch(ctx, query, tags, topK)
+			if err == nil {
+				return formatFlowMemoryMatches(matches), nil
+			}
+			logger.WithError(err).Warn("failed to search flow memories semantically, fallback to keyword search")
+		}
+		items, err := fm.db.SearchFlowMemories(ctx, database.SearchFlowMemoriesParams{
+			FlowID:  fm.flowID,
+			Tags:    tags,
+			Query:   query,
+			MaxRows: int32(limit),
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to search flow memories")
+			return "", fmt.Errorf("failed to search flow memories: %w", err)
+		}
+		return formatFlowMemories(items, limit), nil
+	default:
+		logger.Error("unknown flow memory action")
+		return "", fmt.Errorf("unknown flow memory action: %s", action.Action)
+	}
+}
+
+func (fm *flowMemory) IsAvailable() bool {
+	return fm.db != nil
+}
+
+// index stores the embedding of the fact, the previous embedding is removed on failure
+// because it belongs to the overwritten value
+func (fm *flowMemory) index(ctx context.Context, item database.FlowMemory) error {
+	if fm.embedder == nil {
+		return nil
+	}
+
+	vector, err := fm.embedder.embed(ctx, flowMemoryDocument(item), false)
+	if err == nil {
+		err = fm.db.UpsertFlowMemoryEmbedding(ctx, database.UpsertFlowMemoryEmbeddingParams{
+			MemoryID:   item.ID,
+			FlowID:     item.FlowID,
+			Model:      fm.embedder.Model,
+			Dimensions: int32(len(vector)),
+			Embedding:  vector,
+		})
+	}
+	if err != nil {
+		if derr := fm.db.DeleteFlowMemoryEmbedding(ctx, item.ID); derr != nil {
+			return errors.Join(err, derr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// semanticSearch returns topK facts nearest to the query, the facts are ordered by pgvector
+// if the extension is installed, otherwise the latest facts are compared in memory
+func (fm *flowMemory) semanticSearch(
+	ctx context.Context,
+	query string,
+	tags json.RawMessage,
+	topK int,
+) ([]flowMemoryMatch, error) {
+	vector, err := fm.embedder.embed(ctx, query, true)
+	if err != nil {
+		return nil, err
+	}
+
+	supported, err := fm.db.GetFlowMemoryVectorSupport(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pgvector support: %w", err)
+	}
+
+	if supported {
+		rows, err := fm.db.SearchFlowMemoriesByVector(ctx, database.SearchFlowMemoriesByVectorParams{
+			FlowID:     fm.flowID,
+			Embedding:  vector,
+			Tags:       tags,
+			Model:      fm.embedder.Model,
+			Dimensions: int32(len(vector)),
+			MaxRows:    int32(topK),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search flow memories by vector: %w", err)
+		}
+
+		matches := make([]flowMemoryMatch, 0, len(rows))
+		for _, row := range rows {
+			matches = append(matches, flowMemoryMatch{
+				FlowMemory: database.FlowMemory{
+					ID:        row.ID,
+					Key:       row.Key,
+					Value:     row.Value,
+					Tags:      row.Tags,
+					FlowID:    row.FlowID,
+					TaskID:    row.TaskID,
+					SubtaskID: row.SubtaskID,
+					CreatedAt: row.CreatedAt,
+					UpdatedAt: row.UpdatedAt,
+				},
+				Similarity: row.Similarity,
+			})
+		}
+		return matches, nil
+	}
+
+	rows, err := fm.db.GetFlowMemoriesEmbeddings(ctx, database.GetFlowMemoriesEmbeddingsParams{
+		FlowID:     fm.flowID,
+		Tags:       tags,
+		Model:      fm.embedder.Model,
+		Dimensions: int32(len(vector)),
+		MaxRows:    flowMemoryMaxVectorScan,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow memories embeddings: %w", err)
+	}
+
+	matches := make([]flowMemoryMatch, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, flowMemoryMatch{
+			FlowMemory: database.FlowMemory{
+				ID:        row.ID,
+				Key:       row.Key,
+				Value:     row.Value,
+				Tags:      row.Tags,
+				FlowID:    row.FlowID,
+				TaskID:    row.TaskID,
+				SubtaskID: row.SubtaskID,
+				CreatedAt: row.CreatedAt,
+				UpdatedAt: row.UpdatedAt,
+			},
+			Similarity: cosineSimilarity(vector, row.Embedding),
+		})
+	}
+	slices.SortStableFunc(matches, func(a, b flowMemoryMatch) int {
+		switch {
+		case a.Similarity > b.Similarity:
+			return -1
+		case a.Similarity < b.Similarity:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+
+	return matches, nil
+}
+
+// flowMemoryDocument returns the text of the fact to embed, it's the same data as the keyword search matches
+func flowMemoryDocument(item database.FlowMemory) string {
+	var tags []string
+	_ = json.Unmarshal(item.Tags, &tags)
+
+	return strings.Join([]string{item.Key, item.Value, strings.Join(tags, " ")}, "\n")
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+func validateFlowMemoryFact(key, value string) error {
+	switch {
+	case key == "":
+		return errors.New("key is required for the 'store' action")
+	case utf8.RuneCountInString(key) > flowMemoryMaxKeyLength:
+		return fmt.Errorf("key must be at most %d characters", flowMemoryMaxKeyLength)
+	case strings.TrimSpace(value) == "":
+		return errors.New("value is required for the 'store' action")
+	case len(value) > flowMemoryMaxValueLength:
+		return fmt.Errorf("value must be at most %d bytes, split it into several facts", flowMemoryMaxValueLength)
+	}
+
+	return nil
+}
+
+// normalizeFlowMemoryTags returns sorted unique lowercase tags as JSON array to store them
+// or to filter the facts by the JSONB containment
+func normalizeFlowMemoryTags(tags []string) (json.RawMessage, error) {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(result, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > flowMemoryMaxTagLength {
+			return nil, fmt.Errorf("tag '%s' must be at most %d characters", tag, flowMemoryMaxTagLength)
+		}
+		result = append(result, tag)
+	}
+	if len(result) > flowMemoryMaxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", flowMemoryMaxTags)
+	}
+	slices.Sort(result)
+
+	return json.Marshal(result)
+}
+
+// formatFlowMemories writes the facts as markdown, limit is used to notice the truncated result
+func formatFlowMemories(items []database.FlowMemory, limit int) string {
+	if len(items) == 0 {
+		return "no facts were found in the flow memory"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Flow memory\n\n")
+	for _, item := range items {
+		writeFlowMemory(&sb, item)
+		sb.WriteString(item.Value)
+		sb.WriteString("\n\n")
+	}
+	if limit > 0 && len(items) == limit {
+		sb.WriteString(fmt.Sprintf("[only the latest %d facts are shown, refine the query or the tags]\n", limit))
+	}
+
+	return sb.String()
+}
+
+func formatFlowMemoryMatches(matches []flowMemoryMatch) string {
+	if len(matches) == 0 {
+		return "no facts were found in the flow memory"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Flow memory semantic search\n\n")
+	for _, match := range matches {
+		writeFlowMemory(&sb, match.FlowMemory)
+		sb.WriteString(fmt.Sprintf("**Similarity:** %.3f\n\n", match.Similarity))
+		sb.WriteString(match.Value)
+		sb.WriteString("\n\n")
+	}
+
+	return sb.String()
+}
+
+func writeFlowMemory(sb *strings.Builder, item database.FlowMemory) {
+	var tags []string
+	_ = json.Unmarshal(item.Tags, &tags)
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", item.Key))
+	if len(tags) != 0 {
+		sb.WriteString(fmt.Sprintf("**Tags:** %s\n\n", strings.Join(tags, ", ")))
+	}
+	if item.UpdatedAt.Valid {
+		sb.WriteString(fmt.Sprintf("**Updated:** %s\n\n", item.UpdatedAt.Time.UTC().Format("2006-01-02 15:04:05")))
+	}
+}
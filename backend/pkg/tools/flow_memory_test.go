@@ -0,0 +1,615 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flowMemoryMockQuerier keeps the facts in memory and emulates the flow_memories queries
+type flowMemoryMockQuerier struct {
+	database.Querier
+	items []database.FlowMemory
+	now   time.Time
+}
+
+func (m *flowMemoryMockQuerier) UpsertFlowMemory(
+	_ context.Context, arg database.UpsertFlowMemoryParams,
+) (database.FlowMemory, error) {
+	m.now = m.now.Add(time.Second)
+	for i, item := range m.items {
+		if item.FlowID == arg.FlowID && item.Key == arg.Key {
+			item.Value, item.Tags = arg.Value, arg.Tags
+			item.TaskID, item.SubtaskID = arg.TaskID, arg.SubtaskID
+			item.UpdatedAt = sql.NullTime{Time: m.now, Valid: true}
+			m.items[i] = item
+			return item, nil
+		}
+	}
+
+	item := database.FlowMemory{
+		ID:        int64(len(m.items) + 1),
+		Key:       arg.Key,
+		Value:     arg.Value,
+		Tags:      arg.Tags,
+		FlowID:    arg.FlowID,
+		TaskID:    arg.TaskID,
+		SubtaskID: arg.SubtaskID,
+		CreatedAt: sql.NullTime{Time: m.now, Valid: true},
+		UpdatedAt: sql.NullTime{Time: m.now, Valid: true},
+	}
+	m.items = append(m.items, item)
+	return item, nil
+}
+
+func (m *flowMemoryMockQuerier) GetFlowMemory(
+	_ context.Context, arg database.GetFlowMemoryParams,
+) (database.FlowMemory, error) {
+	for _, item := range m.items {
+		if item.FlowID == arg.FlowID && item.Key == arg.Key {
+			return item, nil
+		}
+	}
+	return database.FlowMemory{}, sql.ErrNoRows
+}
+
+func (m *flowMemoryMockQuerier) GetFlowMemories(
+	ctx context.Context, arg database.GetFlowMemoriesParams,
+) ([]database.FlowMemory, error) {
+	return m.SearchFlowMemories(ctx, database.SearchFlowMemoriesParams{
+		FlowID:  arg.FlowID,
+		Tags:    arg.Tags,
+		MaxRows: arg.MaxRows,
+	})
+}
+
+func (m *flowMemoryMockQuerier) SearchFlowMemories(
+	_ context.Context, arg database.SearchFlowMemoriesParams,
+) ([]database.FlowMemory, error) {
+	var filter []string
+	if err := json.Unmarshal(arg.Tags, &filter); err != nil {
+		return nil, err
+	}
+
+	var result []database.FlowMemory
+	for i := len(m.items) - 1; i >= 0; i-- {
+		item := m.items[i]
+		var tags []string
+		_ = json.Unmarshal(item.Tags, &tags)
+
+		data := strings.ToLower(item.Key + " " + item.Value + " " + string(item.Tags))
+		if item.FlowID != arg.FlowID || !containsAllTags(tags, filter) ||
+			!strings.Contains(data, strings.ToLower(arg.Query)) {
+			continue
+		}
+		result = append(result, item)
+		if len(result) == int(arg.MaxRows) {
+			break
+		}
+	}
+	return result, nil
+}
+
+func containsAllTags(tags, filter []string) bool {
+	for _, tag := range filter {
+		found := false
+		for _, t := range tags {
+			found = found || t == tag
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func callFlowMemory(t *testing.T, tool Tool, action FlowMemoryAction) string {
+	t.Helper()
+
+	args, err := json.Marshal(action)
+	require.NoError(t, err)
+
+	result, err := tool.Handle(t.Context(), FlowMemoryToolName, args)
+	require.NoError(t, err)
+	return result
+}
+
+func TestFlowMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	taskID, subtaskID := int64(3), int64(5)
+	db := &flowMemoryMockQuerier{}
+	tool := NewFlowMemoryTool(1, &taskID, &subtaskID, db, nil)
+
+	result := callFlowMemory(t, tool, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    " creds.ssh.admin ",
+		Value:  "admin:Winter2024! works on 10.0.0.5",
+		Tags:   []string{"Creds", "ssh", " creds ", ""},
+	})
+	assert.Contains(t, result, "fact 'creds.ssh.admin' is stored")
+
+	require.Len(t, db.items, 1)
+	item := db.items[0]
+	assert.Equal(t, "creds.ssh.admin", item.Key)
+	assert.Equal(t, int64(1), item.FlowID)
+	assert.Equal(t, sql.NullInt64{Int64: 3, Valid: true}, item.TaskID)
+	assert.Equal(t, sql.NullInt64{Int64: 5, Valid: true}, item.SubtaskID)
+	assert.JSONEq(t, `["creds","ssh"]`, string(item.Tags))
+
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory, Key: "creds.ssh.admin"})
+	assert.Contains(t, result, "## creds.ssh.admin")
+	assert.Contains(t, result, "**Tags:** creds, ssh")
+	assert.Contains(t, result, "admin:Winter2024! works on 10.0.0.5")
+
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory, Key: "creds.ftp"})
+	assert.Contains(t, result, "fact 'creds.ftp' not found")
+}
+
+func TestFlowMemoryStoreValidation(t *testing.T) {
+	t.Parallel()
+
+	db := &flowMemoryMockQuerier{}
+	tool := NewFlowMemoryTool(1, nil, nil, db, nil)
+
+	tests := []struct {
+		name   string
+		action FlowMemoryAction
+		want   string
+	}{
+		{
+			name:   "empty key",
+			action: FlowMemoryAction{Action: StoreFlowMemory, Value: "value"},
+			want:   "key is required",
+		},
+		{
+			name:   "empty value",
+			action: FlowMemoryAction{Action: StoreFlowMemory, Key: "key", Value: "  "},
+			want:   "value is required",
+		},
+		{
+			name:   "long value",
+			action: FlowMemoryAction{Action: StoreFlowMemory, Key: "key", Value: strings.Repeat("a", flowMemoryMaxValueLength+1)},
+			want:   "split it into several facts",
+		},
+		{
+			name:   "long tag",
+			action: FlowMemoryAction{Action: StoreFlowMemory, Key: "key", Value: "value", Tags: []string{strings.Repeat("t", 65)}},
+			want:   "must be at most 64 characters",
+		},
+		{
+			name:   "empty search query",
+			action: FlowMemoryAction{Action: SearchFlowMemory, Query: " "},
+			want:   "query is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, callFlowMemory(t, tool, tt.action), tt.want)
+		})
+	}
+	assert.Empty(t, db.items)
+
+	_, err := tool.Handle(t.Context(), FlowMemoryToolName, json.RawMessage(`{"action":"drop"}`))
+	assert.ErrorContains(t, err, "unknown flow memory action")
+}
+
+func TestFlowMemoryOverwrite(t *testing.T) {
+	t.Parallel()
+
+	db := &flowMemoryMockQuerier{}
+	tool := NewFlowMemoryTool(1, nil, nil, db, nil)
+
+	callFlowMemory(t, tool, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "target.10.0.0.5.ports",
+		Value:  "22/tcp open",
+		Tags:   []string{"recon"},
+	})
+	callFlowMemory(t, tool, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "target.10.0.0.5.ports",
+		Value:  "22/tcp open, 443/tcp open",
+		Tags:   []string{"recon", "web"},
+	})
+
+	require.Len(t, db.items, 1, "fact with the same key must be overwritten")
+	assert.Equal(t, "22/tcp open, 443/tcp open", db.items[0].Value)
+	assert.JSONEq(t, `["recon","web"]`, string(db.items[0].Tags))
+
+	result := callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory, Key: "target.10.0.0.5.ports"})
+	assert.Contains(t, result, "22/tcp open, 443/tcp open")
+	assert.Equal(t, 1, strings.Count(result, "## target.10.0.0.5.ports"))
+}
+
+func TestFlowMemoryRetrieveByTags(t *testing.T) {
+	t.Parallel()
+
+	db := &flowMemoryMockQuerier{}
+	tool := NewFlowMemoryTool(1, nil, nil, db, nil)
+	otherFlow := NewFlowMemoryTool(2, nil, nil, db, nil)
+
+	facts := []FlowMemoryAction{
+		{Key: "creds.ssh.admin", Value: "admin:Winter2024!", Tags: []string{"creds", "ssh"}},
+		{Key: "creds.http.basic", Value: "operator:operator", Tags: []string{"creds", "web"}},
+		{Key: "vuln.cve-2021-41773", Value: "path traversal in Apache 2.4.49", Tags: []string{"vuln", "web"}},
+	}
+	for _, fact := range facts {
+		fact.Action = StoreFlowMemory
+		callFlowMemory(t, tool, fact)
+	}
+	callFlowMemory(t, otherFlow, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "creds.other.flow",
+		Value:  "must not leak",
+		Tags:   []string{"creds"},
+	})
+
+	result := callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory, Tags: []string{"CREDS"}})
+	assert.Contains(t, result, "## creds.ssh.admin")
+	assert.Contains(t, result, "## creds.http.basic")
+	assert.NotContains(t, result, "vuln.cve-2021-41773")
+	assert.NotContains(t, result, "must not leak")
+
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory, Tags: []string{"web", "creds"}})
+	assert.Contains(t, result, "## creds.http.basic")
+	assert.NotContains(t, result, "creds.ssh.admin")
+	assert.NotContains(t, result, "vuln.cve-2021-41773")
+
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory})
+	assert.Equal(t, 3, strings.Count(result, "## "))
+	assert.Less(t, strings.Index(result, "vuln.cve-2021-41773"), strings.Index(result, "creds.ssh.admin"),
+		"latest facts must be first")
+
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: RetrieveFlowMemory, Tags: []string{"smb"}})
+	assert.Equal(t, "no facts were found in the flow memory", result)
+}
+
+func TestFlowMemorySearch(t *testing.T) {
+	t.Parallel()
+
+	db := &flowMemoryMockQuerier{}
+	tool := NewFlowMemoryTool(1, nil, nil, db, nil)
+	otherFlow := NewFlowMemoryTool(2, nil, nil, db, nil)
+
+	callFlowMemory(t, tool, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "vuln.cve-2021-41773",
+		Value:  "Path traversal in Apache 2.4.49",
+		Tags:   []string{"vuln", "web"},
+	})
+	callFlowMemory(t, tool, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "target.10.0.0.5.ports",
+		Value:  "22/tcp open",
+		Tags:   []string{"recon"},
+	})
+	callFlowMemory(t, otherFlow, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "apache.other.flow",
+		Value:  "Apache on another target",
+	})
+
+	result := callFlowMemory(t, tool, FlowMemoryAction{Action: SearchFlowMemory, Query: "apache"})
+	assert.Contains(t, result, "## vuln.cve-2021-41773")
+	assert.NotContains(t, result, "target.10.0.0.5.ports")
+	assert.NotContains(t, result, "apache.other.flow")
+
+	// tags are matched by the query too
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: SearchFlowMemory, Query: "recon"})
+	assert.Contains(t, result, "## target.10.0.0.5.ports")
+
+	result = callFlowMemory(t, tool, FlowMemoryAction{Action: SearchFlowMemory, Query: "apache", Tags: []string{"recon"}})
+	assert.Equal(t, "no facts were found in the flow memory", result)
+}
+
+func TestNormalizeFlowMemoryTags(t *testing.T) {
+	t.Parallel()
+
+	tags, err := normalizeFlowMemoryTags(nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(tags), "empty filter must match all facts by JSONB containment")
+
+	tags, err = normalizeFlowMemoryTags([]string{"Web", "creds", " web", ""})
+	require.NoError(t, err)
+	assert.Equal(t, `["creds","web"]`, string(tags))
+
+	many := make([]string, flowMemoryMaxTags+1)
+	for i := range many {
+		many[i] = strings.Repeat("t", i+1)
+	}
+	_, err = normalizeFlowMemoryTags(many)
+	assert.ErrorContains(t, err, "at most 16 tags")
+}
+
+// fakeFlowMemoryEmbedder maps the words to the fixed concepts to get deterministic vectors
+// where the related words are close to each other
+type fakeFlowMemoryEmbedder struct {
+	queryErr error
+	queries  int
+}
+
+var fakeEmbedderConcepts = map[string]int{
+	"password": 0, "credentials": 0, "creds": 0, "login": 0, "admin:winter2024!": 0, "ssh": 0,
+	"apache": 1, "httpd": 1, "web": 1, "traversal": 1,
+	"ports": 2, "open": 2, "tcp": 2, "nmap": 2, "recon": 2,
+}
+
+func (e *fakeFlowMemoryEmbedder) vector(text string) []float32 {
+	vector := make([]float32, 4)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == '\n' || r == '.' || r == ','
+	}) {
+		if idx, ok := fakeEmbedderConcepts[word]; ok {
+			vector[idx]++
+		} else {
+			vector[3] += 0.1
+		}
+	}
+	return vector
+}
+
+func (e *fakeFlowMemoryEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, e.vector(text))
+	}
+	return vectors, nil
+}
+
+func (e *fakeFlowMemoryEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	e.queries++
+	if e.queryErr != nil {
+		return nil, e.queryErr
+	}
+	return e.vector(text), nil
+}
+
+func (e *fakeFlowMemoryEmbedder) IsAvailable() bool {
+	return true
+}
+
+// flowMemoryVectorMockQuerier emulates the embeddings table with or without pgvector
+type flowMemoryVectorMockQuerier struct {
+	flowMemoryMockQuerier
+	pgvector   bool
+	embeddings map[int64]database.FlowMemoryEmbedding
+}
+
+func newFlowMemoryVectorMockQuerier(pgvector bool) *flowMemoryVectorMockQuerier {
+	return &flowMemoryVectorMockQuerier{
+		pgvector:   pgvector,
+		embeddings: make(map[int64]database.FlowMemoryEmbedding),
+	}
+}
+
+func (m *flowMemoryVectorMockQuerier) UpsertFlowMemoryEmbedding(
+	_ context.Context, arg database.UpsertFlowMemoryEmbeddingParams,
+) error {
+	m.embeddings[arg.MemoryID] = database.FlowMemoryEmbedding{
+		MemoryID:   arg.MemoryID,
+		FlowID:     arg.FlowID,
+		Model:      arg.Model,
+		Dimensions: arg.Dimensions,
+		Embedding:  arg.Embedding,
+	}
+	return nil
+}
+
+func (m *flowMemoryVectorMockQuerier) DeleteFlowMemoryEmbedding(_ context.Context, memoryID int64) error {
+	delete(m.embeddings, memoryID)
+	return nil
+}
+
+func (m *flowMemoryVectorMockQuerier) GetFlowMemoryVectorSupport(_ context.Context) (bool, error) {
+	return m.pgvector, nil
+}
+
+func (m *flowMemoryVectorMockQuerier) GetFlowMemoriesEmbeddings(
+	ctx context.Context, arg database.GetFlowMemoriesEmbeddingsParams,
+) ([]database.GetFlowMemoriesEmbeddingsRow, error) {
+	if m.pgvector {
+		return nil, errors.New("embeddings are compared by pgvector")
+	}
+
+	items, err := m.GetFlowMemories(ctx, database.GetFlowMemoriesParams{
+		FlowID:  arg.FlowID,
+		Tags:    arg.Tags,
+		MaxRows: arg.MaxRows,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []database.GetFlowMemoriesEmbeddingsRow
+	for _, item := range items {
+		emb, ok := m.embeddings[item.ID]
+		if !ok || emb.Model != arg.Model || emb.Dimensions != arg.Dimensions {
+			continue
+		}
+		rows = append(rows, database.GetFlowMemoriesEmbeddingsRow{
+			ID: item.ID, Key: item.Key, Value: item.Value, Tags: item.Tags, FlowID: item.FlowID,
+			UpdatedAt: item.UpdatedAt, Embedding: emb.Embedding,
+		})
+	}
+	return rows, nil
+}
+
+func (m *flowMemoryVectorMockQuerier) SearchFlowMemoriesByVector(
+	ctx context.Context, arg database.SearchFlowMemoriesByVectorParams,
+) ([]database.SearchFlowMemoriesByVectorRow, error) {
+	if !m.pgvector {
+		return nil, errors.New(`type "vector" does not exist`)
+	}
+
+	m.pgvector = false
+	defer func() { m.pgvector = true }()
+	rows, err := m.GetFlowMemoriesEmbeddings(ctx, database.GetFlowMemoriesEmbeddingsParams{
+		FlowID:     arg.FlowID,
+		Tags:       arg.Tags,
+		Model:      arg.Model,
+		Dimensions: arg.Dimensions,
+		MaxRows:    flowMemoryMaxVectorScan,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]database.SearchFlowMemoriesByVectorRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, database.SearchFlowMemoriesByVectorRow{
+			ID: row.ID, Key: row.Key, Value: row.Value, Tags: row.Tags, FlowID: row.FlowID,
+			UpdatedAt: row.UpdatedAt, Similarity: cosineSimilarity(arg.Embedding, row.Embedding),
+		})
+	}
+	slices.SortStableFunc(result, func(a, b database.SearchFlowMemoriesByVectorRow) int {
+		return cmp.Compare(b.Similarity, a.Similarity)
+	})
+	if len(result) > int(arg.MaxRows) {
+		result = result[:arg.MaxRows]
+	}
+	return result, nil
+}
+
+func storeSemanticTestFacts(t *testing.T, db *flowMemoryVectorMockQuerier, embedder *FlowMemoryEmbedder) {
+	t.Helper()
+
+	tool := NewFlowMemoryTool(1, nil, nil, db, embedder)
+	facts := []FlowMemoryAction{
+		{Key: "creds.ssh.admin", Value: "admin:Winter2024!", Tags: []string{"ssh"}},
+		{Key: "vuln.cve-2021-41773", Value: "path traversal in httpd 2.4.49", Tags: []string{"web"}},
+		{Key: "target.10.0.0.5.ports", Value: "22/tcp open, 443/tcp open", Tags: []string{"recon"}},
+	}
+	for _, fact := range facts {
+		fact.Action = StoreFlowMemory
+		callFlowMemory(t, tool, fact)
+	}
+
+	other := NewFlowMemoryTool(2, nil, nil, db, embedder)
+	callFlowMemory(t, other, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "creds.other.flow",
+		Value:  "login of the other flow",
+	})
+}
+
+func TestFlowMemorySemanticSearch(t *testing.T) {
+	t.Parallel()
+
+	for name, pgvector := range map[string]bool{"pgvector": true, "in memory": false} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := newFlowMemoryVectorMockQuerier(pgvector)
+			embedder := &FlowMemoryEmbedder{Embedder: &fakeFlowMemoryEmbedder{}, Model: "fake/concepts"}
+			storeSemanticTestFacts(t, db, embedder)
+			require.Len(t, db.embeddings, 4)
+			assert.Equal(t, "fake/concepts", db.embeddings[1].Model)
+			assert.Equal(t, int32(4), db.embeddings[1].Dimensions)
+
+			tool := NewFlowMemoryTool(1, nil, nil, db, embedder)
+
+			// keyword search misses the fact which is related by the meaning only
+			keyword := NewFlowMemoryTool(1, nil, nil, db, nil)
+			result := callFlowMemory(t, keyword, FlowMemoryAction{Action: SearchFlowMemory, Query: "password"})
+			assert.Equal(t, "no facts were found in the flow memory", result)
+
+			result = callFlowMemory(t, tool, FlowMemoryAction{Action: SearchFlowMemory, Query: "password"})
+			assert.Contains(t, result, "# Flow memory semantic search")
+			assert.Contains(t, result, "**Similarity:** ")
+			assert.NotContains(t, result, "creds.other.flow")
+			assert.Equal(t, 3, strings.Count(result, "## "))
+			assert.True(t, strings.HasPrefix(result, "# Flow memory semantic search\n\n## creds.ssh.admin"), result)
+
+			result = callFlowMemory(t, tool, FlowMemoryAction{Action: SearchFlowMemory, Query: "apache", Limit: 1})
+			assert.Equal(t, 1, strings.Count(result, "## "))
+			assert.Contains(t, result, "## vuln.cve-2021-41773")
+
+			result = callFlowMemory(t, tool, FlowMemoryAction{
+				Action: SearchFlowMemory,
+				Query:  "password",
+				Tags:   []string{"recon"},
+			})
+			assert.Equal(t, 1, strings.Count(result, "## "))
+			assert.Contains(t, result, "## target.10.0.0.5.ports")
+		})
+	}
+}
+
+func TestFlowMemorySemanticSearchFallback(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeFlowMemoryEmbedder{}
+	db := newFlowMemoryVectorMockQuerier(false)
+	embedder := &FlowMemoryEmbedder{Embedder: fake, Model: "fake/concepts"}
+	storeSemanticTestFacts(t, db, embedder)
+
+	// failed query embedding falls back to the keyword search
+	fake.queryErr = errors.New("provider is unavailable")
+	tool := NewFlowMemoryTool(1, nil, nil, db, embedder)
+	result := callFlowMemory(t, tool, FlowMemoryAction{Action: SearchFlowMemory, Query: "httpd"})
+	assert.Equal(t, 1, fake.queries)
+	assert.Contains(t, result, "# Flow memory\n\n## vuln.cve-2021-41773")
+
+	// vectors of the other model are never compared with the query
+	fake.queryErr = nil
+	other := NewFlowMemoryTool(1, nil, nil, db, &FlowMemoryEmbedder{Embedder: fake, Model: "fake/other"})
+	result = callFlowMemory(t, other, FlowMemoryAction{Action: SearchFlowMemory, Query: "password"})
+	assert.Equal(t, "no facts were found in the flow memory", result)
+
+	// overwritten fact loses the stale embedding if the new value can't be embedded
+	strict := &FlowMemoryEmbedder{Embedder: fake, Model: "fake/concepts", Dimensions: 8}
+	tool = NewFlowMemoryTool(1, nil, nil, db, strict)
+	result = callFlowMemory(t, tool, FlowMemoryAction{
+		Action: StoreFlowMemory,
+		Key:    "creds.ssh.admin",
+		Value:  "root:toor",
+	})
+	assert.Contains(t, result, "is stored")
+	assert.NotContains(t, db.embeddings, int64(1))
+	assert.Len(t, db.embeddings, 3)
+}
+
+func TestNewFlowMemoryEmbedder(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeFlowMemoryEmbedder{}
+	cfg := &config.Config{
+		EmbeddingProvider:             "openai",
+		EmbeddingModel:                "text-embedding-3-small",
+		FlowMemoryEmbeddingDimensions: 1536,
+	}
+	assert.Nil(t, NewFlowMemoryEmbedder(cfg, fake), "semantic search is disabled by default")
+
+	cfg.FlowMemorySemanticSearch = true
+	embedder := NewFlowMemoryEmbedder(cfg, fake)
+	require.NotNil(t, embedder)
+	assert.Equal(t, "openai/text-embedding-3-small", embedder.Model)
+	assert.Equal(t, 1536, embedder.Dimensions)
+
+	cfg.FlowMemoryEmbeddingProvider = "none"
+	assert.Nil(t, NewFlowMemoryEmbedder(cfg, fake), "overridden provider is not available")
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 1.0, cosineSimilarity([]float32{1, 2, 3}, []float32{2, 4, 6}), 1e-6)
+	assert.InDelta(t, 0.0, cosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-6)
+	assert.InDelta(t, -1.0, cosineSimilarity([]float32{1, 1}, []float32{-1, -1}), 1e-6)
+	assert.Zero(t, cosineSimilarity([]float32{1, 2}, []float32{1, 2, 3}))
+	assert.Zero(t, cosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+}
@@ -0,0 +1,575 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	githubAPIURL         = "https://api.github.com"
+	githubRawURL         = "https://raw.githubusercontent.com"
+	githubAPIVersion     = "2022-11-28"
+	defaultGithubLimit   = 10
+	maxGithubLimit       = 30
+	githubRequestTimeout = 30 * time.Second
+	githubCacheNamespace = "github"
+	githubCacheTTL       = time.Hour
+
+	githubTypeRepositories = "repositories"
+	githubTypeCode         = "code"
+
+	githubSortBestMatch = "best-match"
+	githubSortStars     = "stars"
+	githubSortUpdated   = "updated"
+
+	// maxGithubDescriptionSize limits repository description and topics rendered per item
+	maxGithubDescriptionSize = 2 * 1024
+)
+
+// GithubRateLimitError is returned when GitHub API rate limit is exhausted,
+// requests are not sent until ResetAt to avoid secondary rate limits
+type GithubRateLimitError struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+func (e *GithubRateLimitError) Error() string {
+	if e.ResetAt.IsZero() {
+		return "GitHub API rate limit exceeded, please try again later"
+	}
+
+	return fmt.Sprintf("GitHub API rate limit exceeded (limit %d), resets at %s",
+		e.Limit, e.ResetAt.UTC().Format(time.RFC3339))
+}
+
+// githubRateLimiter keeps the rate limit state of GitHub API between tool instances
+// because tools are created for each agent chain but share the same token
+type githubRateLimiter struct {
+	mx      *sync.Mutex
+	limit   int
+	resetAt time.Time
+	now     func() time.Time
+}
+
+var githubLimiter = &githubRateLimiter{
+	mx:  &sync.Mutex{},
+	now: time.Now,
+}
+
+// check returns rate limit error if the previous response exhausted the limit and it wasn't reset yet
+func (rl *githubRateLimiter) check() error {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+
+	if rl.resetAt.IsZero() || !rl.now().Before(rl.resetAt) {
+		return nil
+	}
+
+	return &GithubRateLimitError{Limit: rl.limit, ResetAt: rl.resetAt}
+}
+
+// update stores rate limit state from the response headers and returns error if the limit is exceeded
+func (rl *githubRateLimiter) update(resp *http.Response) error {
+	rl.mx.Lock()
+	defer rl.mx.Unlock()
+
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	remaining, errRemaining := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+
+	var resetAt time.Time
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		resetAt = time.Unix(reset, 0)
+	}
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && retryAfter > 0 {
+		resetAt = rl.now().Add(time.Duration(retryAfter) * time.Second)
+	}
+
+	exhausted := errRemaining == nil && remaining == 0
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusForbidden && (exhausted || resp.Header.Get("Retry-After") != "")
+
+	if exhausted || limited {
+		rl.limit, rl.resetAt = limit, resetAt
+	} else {
+		rl.limit, rl.resetAt = limit, time.Time{}
+	}
+
+	if !limited {
+		return nil
+	}
+
+	return &GithubRateLimitError{Limit: limit, Remaining: max(remaining, 0), ResetAt: resetAt}
+}
+
+// github represents the GitHub search tool for repositories and code with exploits and PoCs
+type github struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewGithubTool creates a new GitHub search tool instance
+func NewGithubTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &github{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes a GitHub search request from an AI agent
+func (g *github) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !g.IsAvailable() {
+		return "", fmt.Errorf("github is not available")
+	}
+
+	var action GithubAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(g.flowID, g.taskID, g.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal github search action")
+		return "", fmt.Errorf("failed to unmarshal %s search action arguments: %w", name, err)
+	}
+
+	searchType := strings.ToLower(strings.TrimSpace(action.SearchType))
+	if searchType == "" {
+		searchType = githubTypeRepositories
+	}
+	if searchType != githubTypeRepositories && searchType != githubTypeCode {
+		logger.WithField("search_type", searchType).Error("unsupported github search type")
+		return "", fmt.Errorf("unsupported %s search type '%s'", name, searchType)
+	}
+
+	sort := strings.ToLower(strings.TrimSpace(action.Sort))
+	switch sort {
+	case githubSortBestMatch, githubSortStars, githubSortUpdated:
+	default:
+		sort = githubSortBestMatch
+	}
+
+	limit := action.MaxResults.Int()
+	if limit < 1 || limit > maxGithubLimit {
+		limit = defaultGithubLimit
+	}
+
+	language := strings.ToLower(strings.TrimSpace(action.Language))
+
+	logger = logger.WithFields(logrus.Fields{
+		"query":       action.Query[:min(len(action.Query), 1000)],
+		"search_type": searchType,
+		"language":    language,
+		"sort":        sort,
+		"limit":       limit,
+	})
+
+	result, err := g.search(ctx, action.Query, searchType, language, sort, limit)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("github search error swallowed"),
+			langfuse.WithEventInput(action.Query),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":   GithubToolName,
+				"engine":      "github",
+				"query":       action.Query,
+				"search_type": searchType,
+				"language":    language,
+				"sort":        sort,
+				"limit":       limit,
+				"error":       err.Error(),
+			}),
+		)
+
+		var rateLimitErr *GithubRateLimitError
+		if errors.As(err, &rateLimitErr) {
+			logger.WithError(err).Warn("github search rate limit exceeded")
+			return fmt.Sprintf("failed to search in GitHub: %v, use other search tools until then", err), nil
+		}
+
+		logger.WithError(err).Error("failed to search in GitHub")
+		return fmt.Sprintf("failed to search in GitHub: %v", err), nil
+	}
+
+	if agentCtx, ok := GetAgentContext(ctx); ok {
+		_, _ = g.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeGithub,
+			action.Query,
+			result,
+			g.taskID,
+			g.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// search returns formatted markdown result of the GitHub search,
+// successful responses are kept in the shared cache to save the rate limit
+func (g *github) search(ctx context.Context, query, searchType, language, sort string, limit int) (string, error) {
+	resp, err := g.lookup(ctx, query, searchType, language, sort, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return formatGithubResults(query, searchType, language, limit, resp, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
+}
+
+// lookup returns the GitHub search response from the shared cache or the API
+func (g *github) lookup(
+	ctx context.Context,
+	query, searchType, language, sort string,
+	limit int,
+) (githubSearchResponse, error) {
+	var resp githubSearchResponse
+
+	cacheKey := normalizeCacheKey(searchType, language, sort, strconv.Itoa(limit), query)
+	if getCachedJSON(ctx, g.cache, githubCacheNamespace, cacheKey, &resp) {
+		return resp, nil
+	}
+
+	resp, err := g.fetch(ctx, query, searchType, language, sort, limit)
+	if err != nil {
+		return resp, err
+	}
+
+	setCachedJSON(ctx, g.cache, githubCacheNamespace, cacheKey, resp, githubCacheTTL)
+
+	return resp, nil
+}
+
+// fetch performs the HTTP request to the GitHub search API
+func (g *github) fetch(
+	ctx context.Context,
+	query, searchType, language, sort string,
+	limit int,
+) (githubSearchResponse, error) {
+	var apiResp githubSearchResponse
+
+	if err := githubLimiter.check(); err != nil {
+		return apiResp, err
+	}
+
+	q := strings.TrimSpace(query)
+	if language != "" {
+		q += " language:" + language
+	}
+
+	params := url.Values{}
+	params.Set("q", q)
+	params.Set("per_page", strconv.Itoa(limit))
+	// code search supports only best match ordering
+	if searchType == githubTypeRepositories && sort != githubSortBestMatch {
+		params.Set("sort", sort)
+		params.Set("order", "desc")
+	}
+
+	apiURL := fmt.Sprintf("%s/search/%s?%s", githubAPIURL, searchType, params.Encode())
+
+	client, err := system.GetHTTPClient(g.cfg)
+	if err != nil {
+		return apiResp, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = githubRequestTimeout
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return apiResp, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.token())
+	req.Header.Set("X-GitHub-Api-Version", githubAPIVersion)
+	req.Header.Set("User-Agent", "PentAGI/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return apiResp, fmt.Errorf("request to GitHub failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := githubLimiter.update(resp); err != nil {
+		return apiResp, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return apiResp, fmt.Errorf("GitHub API returned HTTP %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return apiResp, fmt.Errorf("GitHub API returned HTTP %d", resp.StatusCode)
+	}
+
+	return parseGithubResponse(resp.Body, searchType)
+}
+
+// IsAvailable returns true if the GitHub token is configured
+func (g *github) IsAvailable() bool {
+	return g.token() != ""
+}
+
+func (g *github) token() string {
+	if g.cfg == nil {
+		return ""
+	}
+
+	return g.cfg.GithubSearchToken
+}
+
+// githubOwner is the owner of the repository in the GitHub API response
+type githubOwner struct {
+	Login string `json:"login"`
+}
+
+// githubRepository represents a repository item of the GitHub search API,
+// code search returns reduced repository objects without stars and dates
+type githubRepository struct {
+	FullName        string      `json:"full_name"`
+	HTMLURL         string      `json:"html_url"`
+	CloneURL        string      `json:"clone_url,omitempty"`
+	Description     string      `json:"description,omitempty"`
+	Language        string      `json:"language,omitempty"`
+	StargazersCount int         `json:"stargazers_count,omitempty"`
+	UpdatedAt       string      `json:"updated_at,omitempty"`
+	PushedAt        string      `json:"pushed_at,omitempty"`
+	DefaultBranch   string      `json:"default_branch,omitempty"`
+	Topics          []string    `json:"topics,omitempty"`
+	Archived        bool        `json:"archived,omitempty"`
+	Owner           githubOwner `json:"owner"`
+}
+
+// githubCodeItem represents a file item of the GitHub code search API
+type githubCodeItem struct {
+	Name       string           `json:"name"`
+	Path       string           `json:"path"`
+	SHA        string           `json:"sha"`
+	HTMLURL    string           `json:"html_url"`
+	Repository githubRepository `json:"repository"`
+}
+
+// githubSearchResponse holds decoded items of the search API according to the search type
+type githubSearchResponse struct {
+	TotalCount        int                `json:"total_count"`
+	IncompleteResults bool               `json:"incomplete_results"`
+	Repositories      []githubRepository `json:"repositories,omitempty"`
+	Code              []githubCodeItem   `json:"code,omitempty"`
+}
+
+// parseGithubResponse decodes the search API response body of the given search type
+func parseGithubResponse(body io.Reader, searchType string) (githubSearchResponse, error) {
+	var (
+		apiResp githubSearchResponse
+		raw     struct {
+			TotalCount        int             `json:"total_count"`
+			IncompleteResults bool            `json:"incomplete_results"`
+			Items             json.RawMessage `json:"items"`
+		}
+	)
+
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return apiResp, fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+
+	apiResp.TotalCount = raw.TotalCount
+	apiResp.IncompleteResults = raw.IncompleteResults
+	if len(raw.Items) == 0 {
+		return apiResp, nil
+	}
+
+	var err error
+	switch searchType {
+	case githubTypeCode:
+		err = json.Unmarshal(raw.Items, &apiResp.Code)
+	default:
+		err = json.Unmarshal(raw.Items, &apiResp.Repositories)
+	}
+	if err != nil {
+		return apiResp, fmt.Errorf("failed to decode GitHub response items: %w", err)
+	}
+
+	return apiResp, nil
+}
+
+// githubRawFileURL converts file html URL to the raw content URL,
+// e.g. https://github.com/o/r/blob/<ref>/a.py -> https://raw.githubusercontent.com/o/r/<ref>/a.py
+func githubRawFileURL(item githubCodeItem) string {
+	path, ok := strings.CutPrefix(item.HTMLURL, "https://github.com/")
+	if !ok {
+		return ""
+	}
+
+	repo, ref, ok := strings.Cut(path, "/blob/")
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s/%s", githubRawURL, repo, ref)
+}
+
+// githubDate shortens GitHub timestamps to the date
+func githubDate(timestamp string) string {
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		return t.UTC().Format(time.DateOnly)
+	}
+	return timestamp
+}
+
+// formatGithubResults converts GitHub search response into the markdown string
+// keeping it under the size limit
+func formatGithubResults(
+	query, searchType, language string,
+	limit int,
+	resp githubSearchResponse,
+	sizeLimit int,
+) string {
+	var sb strings.Builder
+
+	sb.WriteString("# GitHub Search Results\n\n")
+	sb.WriteString(fmt.Sprintf("**Query:** `%s`  \n", query))
+	sb.WriteString(fmt.Sprintf("**Type:** %s  \n", searchType))
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("**Language:** %s  \n", language))
+	}
+	sb.WriteString(fmt.Sprintf("**Total matches on GitHub:** %d  \n", resp.TotalCount))
+	if resp.IncompleteResults {
+		sb.WriteString("**Note:** GitHub search timed out, results may be incomplete  \n")
+	}
+	sb.WriteString("\n---\n\n")
+
+	var items []string
+	switch searchType {
+	case githubTypeCode:
+		for i, item := range resp.Code {
+			items = append(items, formatGithubCodeItem(i+1, item))
+		}
+	default:
+		for i, repo := range resp.Repositories {
+			items = append(items, formatGithubRepository(i+1, repo))
+		}
+	}
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	if len(items) == 0 {
+		sb.WriteString("No results were found for the given query.\n")
+		return sb.String()
+	}
+
+	budget := NewResultBudget(sizeLimit, sb.Len())
+	for _, item := range items {
+		if !budget.Add(&sb, item) {
+			sb.WriteString(fmt.Sprintf(
+				"\n**Note:** Results truncated, showing %d of %d results (exceeded %s limit)\n",
+				budget.Added(), len(items), formatSizeLimit(sizeLimit),
+			))
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+func formatGithubRepository(idx int, repo githubRepository) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %d. %s\n\n", idx, repo.FullName))
+	sb.WriteString(fmt.Sprintf("**URL:** %s  \n", repo.HTMLURL))
+	if repo.CloneURL != "" {
+		sb.WriteString(fmt.Sprintf("**Clone URL:** %s  \n", repo.CloneURL))
+	}
+	sb.WriteString(fmt.Sprintf("**Stars:** %d  \n", repo.StargazersCount))
+	if updated := repo.PushedAt; updated != "" || repo.UpdatedAt != "" {
+		if updated == "" {
+			updated = repo.UpdatedAt
+		}
+		sb.WriteString(fmt.Sprintf("**Last updated:** %s  \n", githubDate(updated)))
+	}
+	if repo.Language != "" {
+		sb.WriteString(fmt.Sprintf("**Language:** %s  \n", repo.Language))
+	}
+	if repo.Archived {
+		sb.WriteString("**Archived:** yes  \n")
+	}
+	if len(repo.Topics) > 0 {
+		sb.WriteString(fmt.Sprintf("**Topics:** %s  \n", truncateGithubText(strings.Join(repo.Topics, ", "))))
+	}
+	if repo.Description != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", truncateGithubText(repo.Description)))
+	}
+	sb.WriteString("\n---\n\n")
+
+	return sb.String()
+}
+
+func formatGithubCodeItem(idx int, item githubCodeItem) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %d. %s/%s\n\n", idx, item.Repository.FullName, item.Path))
+	sb.WriteString(fmt.Sprintf("**URL:** %s  \n", item.HTMLURL))
+	if rawURL := githubRawFileURL(item); rawURL != "" {
+		sb.WriteString(fmt.Sprintf("**Raw URL:** %s  \n", rawURL))
+	}
+	sb.WriteString(fmt.Sprintf("**Repository:** %s  \n", item.Repository.HTMLURL))
+	if item.Repository.StargazersCount > 0 {
+		sb.WriteString(fmt.Sprintf("**Stars:** %d  \n", item.Repository.StargazersCount))
+	}
+	if item.Repository.PushedAt != "" {
+		sb.WriteString(fmt.Sprintf("**Last updated:** %s  \n", githubDate(item.Repository.PushedAt)))
+	}
+	if item.Repository.Description != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", truncateGithubText(item.Repository.Description)))
+	}
+	sb.WriteString("\n---\n\n")
+
+	return sb.String()
+}
+
+func truncateGithubText(text string) string {
+	if len(text) <= maxGithubDescriptionSize {
+		return text
+	}
+	return CutRunes(text, maxGithubDescriptionSize) + "... [truncated]"
+}
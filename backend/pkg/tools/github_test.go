@@ -0,0 +1,485 @@
+package tools
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+// resetGithubLimiter isolates the shared rate limit state between tests
+func resetGithubLimiter(t *testing.T) {
+	t.Helper()
+
+	githubLimiter = &githubRateLimiter{mx: &sync.Mutex{}, now: time.Now}
+	t.Cleanup(func() {
+		githubLimiter = &githubRateLimiter{mx: &sync.Mutex{}, now: time.Now}
+	})
+}
+
+func readGithubTestdata(t *testing.T, name, searchType string) githubSearchResponse {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to open testdata: %v", err)
+	}
+	defer file.Close()
+
+	resp, err := parseGithubResponse(file, searchType)
+	if err != nil {
+		t.Fatalf("parseGithubResponse() unexpected error: %v", err)
+	}
+
+	return resp
+}
+
+func TestGithubHandle(t *testing.T) {
+	resetGithubLimiter(t)
+
+	body, err := os.ReadFile(filepath.Join("testdata", "github_result_repositories_log4shell.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	var seenRequest bool
+	var receivedAuth string
+	var receivedAPIVersion string
+	var receivedQuery string
+	var receivedSort string
+	var receivedOrder string
+	var receivedPerPage string
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search/repositories", func(w http.ResponseWriter, r *http.Request) {
+		seenRequest = true
+		receivedAuth = r.Header.Get("Authorization")
+		receivedAPIVersion = r.Header.Get("X-GitHub-Api-Version")
+		receivedQuery = r.URL.Query().Get("q")
+		receivedSort = r.URL.Query().Get("sort")
+		receivedOrder = r.URL.Query().Get("order")
+		receivedPerPage = r.URL.Query().Get("per_page")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "29")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	proxy, err := newTestProxy("api.github.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	flowID := int64(1)
+	taskID := int64(10)
+	subtaskID := int64(20)
+	slp := &searchLogProviderMock{}
+
+	cfg := &config.Config{
+		GithubSearchToken: "test-token",
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}
+
+	gh := NewGithubTool(cfg, flowID, &taskID, &subtaskID, slp, nil)
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	got, err := gh.Handle(
+		ctx,
+		GithubToolName,
+		[]byte(`{"query":"CVE-2021-44228","search_type":"repositories","language":"Python","sort":"stars","max_results":5}`),
+	)
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	if !seenRequest {
+		t.Fatal("request was not intercepted by proxy - mock handler was not called")
+	}
+	if receivedAuth != "Bearer test-token" {
+		t.Errorf("Authorization = %q, want Bearer test-token", receivedAuth)
+	}
+	if receivedAPIVersion != githubAPIVersion {
+		t.Errorf("X-GitHub-Api-Version = %q, want %q", receivedAPIVersion, githubAPIVersion)
+	}
+	if receivedQuery != "CVE-2021-44228 language:python" {
+		t.Errorf("q = %q, want query with language qualifier", receivedQuery)
+	}
+	if receivedSort != "stars" || receivedOrder != "desc" {
+		t.Errorf("sort = %q, order = %q, want stars desc", receivedSort, receivedOrder)
+	}
+	if receivedPerPage != "5" {
+		t.Errorf("per_page = %q, want 5", receivedPerPage)
+	}
+
+	if !strings.Contains(got, "# GitHub Search Results") {
+		t.Errorf("result missing '# GitHub Search Results' section: %q", got)
+	}
+	if !strings.Contains(got, "**Total matches on GitHub:** 1482") {
+		t.Errorf("result missing expected total: %q", got)
+	}
+	if !strings.Contains(got, "kozmer/log4j-shell-poc") {
+		t.Errorf("result missing expected repository: %q", got)
+	}
+
+	if slp.calls != 1 {
+		t.Errorf("PutLog() calls = %d, want 1", slp.calls)
+	}
+	if slp.engine != database.SearchengineTypeGithub {
+		t.Errorf("engine = %q, want %q", slp.engine, database.SearchengineTypeGithub)
+	}
+	if slp.query != "CVE-2021-44228" {
+		t.Errorf("logged query = %q, want %q", slp.query, "CVE-2021-44228")
+	}
+	if slp.taskID == nil || *slp.taskID != taskID {
+		t.Errorf("task ID = %v, want %d", slp.taskID, taskID)
+	}
+	if slp.subtaskID == nil || *slp.subtaskID != subtaskID {
+		t.Errorf("subtask ID = %v, want %d", slp.subtaskID, subtaskID)
+	}
+}
+
+func TestGithubIsAvailable(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want bool
+	}{
+		{
+			name: "available with token",
+			cfg:  &config.Config{GithubSearchToken: "token"},
+			want: true,
+		},
+		{
+			name: "unavailable without token",
+			cfg:  &config.Config{},
+			want: false,
+		},
+		{
+			name: "unavailable with nil config",
+			cfg:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh := NewGithubTool(tt.cfg, 0, nil, nil, nil, nil)
+			if got := gh.IsAvailable(); got != tt.want {
+				t.Errorf("IsAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGithubHandle_Validation(t *testing.T) {
+	gh := NewGithubTool(&config.Config{GithubSearchToken: "token"}, 1, nil, nil, &searchLogProviderMock{}, nil)
+
+	if _, err := gh.Handle(t.Context(), GithubToolName, []byte("{")); err == nil {
+		t.Error("expected error for invalid json")
+	}
+
+	_, err := gh.Handle(t.Context(), GithubToolName, []byte(`{"query":"test","search_type":"issues"}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("expected unsupported search type error, got %v", err)
+	}
+
+	unavailable := NewGithubTool(&config.Config{}, 1, nil, nil, nil, nil)
+	if _, err := unavailable.Handle(t.Context(), GithubToolName, []byte(`{"query":"test"}`)); err == nil {
+		t.Error("expected error when tool is not available")
+	}
+}
+
+func TestGithubHandle_RateLimit(t *testing.T) {
+	resetGithubLimiter(t)
+
+	resetAt := time.Now().Add(10 * time.Minute).Unix()
+
+	var requests int
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search/code", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt, 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+	})
+
+	proxy, err := newTestProxy("api.github.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	cfg := &config.Config{
+		GithubSearchToken: "test-token",
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}
+	slp := &searchLogProviderMock{}
+	gh := NewGithubTool(cfg, 1, nil, nil, slp, nil)
+
+	args := []byte(`{"query":"CVE-2024-3400 exploit","search_type":"code"}`)
+	for i := 0; i < 2; i++ {
+		got, err := gh.Handle(t.Context(), GithubToolName, args)
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "rate limit exceeded") {
+			t.Errorf("result missing rate limit message: %q", got)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 (requests must be blocked until limit reset)", requests)
+	}
+	if slp.calls != 0 {
+		t.Errorf("PutLog() calls = %d, want 0 for swallowed error", slp.calls)
+	}
+
+	err = githubLimiter.check()
+	rateLimitErr, ok := err.(*GithubRateLimitError)
+	if !ok {
+		t.Fatalf("check() error = %v, want *GithubRateLimitError", err)
+	}
+	if rateLimitErr.Limit != 10 || rateLimitErr.ResetAt.Unix() != resetAt {
+		t.Errorf("unexpected rate limit error: %+v", rateLimitErr)
+	}
+
+	githubLimiter.now = func() time.Time { return time.Unix(resetAt, 0) }
+	if err := githubLimiter.check(); err != nil {
+		t.Errorf("check() after reset unexpected error: %v", err)
+	}
+}
+
+func TestGithubRateLimiterUpdate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		status      int
+		headers     map[string]string
+		wantErr     bool
+		wantBlocked bool
+	}{
+		{
+			name:   "ok response with remaining quota",
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "30",
+				"X-RateLimit-Remaining": "12",
+			},
+		},
+		{
+			name:   "last allowed request blocks next ones",
+			status: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "30",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(now.Add(time.Minute).Unix(), 10),
+			},
+			wantBlocked: true,
+		},
+		{
+			name:    "secondary rate limit with retry after",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"Retry-After": "60"},
+			wantErr: true, wantBlocked: true,
+		},
+		{
+			name:    "too many requests",
+			status:  http.StatusTooManyRequests,
+			headers: map[string]string{"Retry-After": "5"},
+			wantErr: true, wantBlocked: true,
+		},
+		{
+			name:    "forbidden without rate limit headers",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "10"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := &githubRateLimiter{mx: &sync.Mutex{}, now: func() time.Time { return now }}
+
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+
+			err := rl.update(resp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if blocked := rl.check() != nil; blocked != tt.wantBlocked {
+				t.Errorf("check() blocked = %v, want %v", blocked, tt.wantBlocked)
+			}
+		})
+	}
+}
+
+func TestGithubParseResponse_Testdata(t *testing.T) {
+	t.Run("repositories", func(t *testing.T) {
+		resp := readGithubTestdata(t, "github_result_repositories_log4shell.json", githubTypeRepositories)
+		if resp.TotalCount != 1482 || len(resp.Repositories) != 3 {
+			t.Fatalf("unexpected response: total %d, items %d", resp.TotalCount, len(resp.Repositories))
+		}
+
+		result := formatGithubResults("log4shell", githubTypeRepositories, "", 10, resp, DefaultMaxResultSize)
+		expected := []string{
+			"### 1. kozmer/log4j-shell-poc",
+			"**Clone URL:** https://github.com/kozmer/log4j-shell-poc.git",
+			"**Stars:** 1817",
+			"**Last updated:** 2024-03-04",
+			"**Topics:** cve-2021-44228, log4j, log4shell, poc",
+			"**Archived:** yes",
+			// pushed_at is null, updated_at must be used instead
+			"**Last updated:** 2026-01-17",
+		}
+		for _, part := range expected {
+			if !strings.Contains(result, part) {
+				t.Errorf("result missing %q:\n%s", part, result)
+			}
+		}
+	})
+
+	t.Run("code", func(t *testing.T) {
+		resp := readGithubTestdata(t, "github_result_code_cve_2024_3400.json", githubTypeCode)
+		if resp.TotalCount != 57 || len(resp.Code) != 2 {
+			t.Fatalf("unexpected response: total %d, items %d", resp.TotalCount, len(resp.Code))
+		}
+
+		result := formatGithubResults("CVE-2024-3400", githubTypeCode, "python", 1, resp, DefaultMaxResultSize)
+		expected := []string{
+			"**Language:** python",
+			"results may be incomplete",
+			"### 1. h4x0r-dz/CVE-2024-3400/exploit.py",
+			"**Raw URL:** https://raw.githubusercontent.com/h4x0r-dz/CVE-2024-3400/" +
+				"7b2c4e1f9d3a6b8c0e1f2a3b4c5d6e7f8a9b0c1d/exploit.py",
+			"**Repository:** https://github.com/h4x0r-dz/CVE-2024-3400",
+		}
+		for _, part := range expected {
+			if !strings.Contains(result, part) {
+				t.Errorf("result missing %q:\n%s", part, result)
+			}
+		}
+		if strings.Contains(result, "### 2. ") {
+			t.Errorf("result must be limited to 1 item:\n%s", result)
+		}
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		resp, err := parseGithubResponse(strings.NewReader(`{"total_count":0,"items":[]}`), githubTypeCode)
+		if err != nil {
+			t.Fatalf("parseGithubResponse() unexpected error: %v", err)
+		}
+		result := formatGithubResults("nothing", githubTypeCode, "", 10, resp, DefaultMaxResultSize)
+		if !strings.Contains(result, "No results were found") {
+			t.Errorf("result missing no results message: %q", result)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := parseGithubResponse(strings.NewReader("{"), githubTypeRepositories); err == nil {
+			t.Error("expected decode error")
+		}
+	})
+}
+
+func TestGithubRawFileURL(t *testing.T) {
+	tests := []struct {
+		htmlURL string
+		want    string
+	}{
+		{
+			htmlURL: "https://github.com/o/r/blob/main/dir/a.py",
+			want:    "https://raw.githubusercontent.com/o/r/main/dir/a.py",
+		},
+		{
+			htmlURL: "https://github.com/o/r/tree/main/dir",
+			want:    "",
+		},
+		{
+			htmlURL: "https://example.com/o/r/blob/main/a.py",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := githubRawFileURL(githubCodeItem{HTMLURL: tt.htmlURL}); got != tt.want {
+			t.Errorf("githubRawFileURL(%q) = %q, want %q", tt.htmlURL, got, tt.want)
+		}
+	}
+}
+
+func TestGithubSizeLimits(t *testing.T) {
+	t.Run("description truncation at 2KB", func(t *testing.T) {
+		resp := githubSearchResponse{
+			TotalCount: 1,
+			Repositories: []githubRepository{
+				{
+					FullName:    "test/large",
+					HTMLURL:     "https://github.com/test/large",
+					Description: strings.Repeat("A", 10*1024),
+				},
+			},
+		}
+
+		result := formatGithubResults("test", githubTypeRepositories, "", 10, resp, DefaultMaxResultSize)
+		if !strings.Contains(result, "... [truncated]") {
+			t.Error("expected description truncation message for 10 KB description")
+		}
+		if len(result) > 4*1024 {
+			t.Errorf("result size %d must not contain full description", len(result))
+		}
+	})
+
+	t.Run("total size limit at 80KB", func(t *testing.T) {
+		repos := make([]githubRepository, maxGithubLimit)
+		for i := range repos {
+			repos[i] = githubRepository{
+				FullName:    fmt.Sprintf("test/repo-%d", i),
+				HTMLURL:     fmt.Sprintf("https://github.com/test/repo-%d", i),
+				Description: strings.Repeat("X", maxGithubDescriptionSize),
+				Topics:      []string{strings.Repeat("Y", maxGithubDescriptionSize)},
+			}
+		}
+
+		resp := githubSearchResponse{TotalCount: 1000, Repositories: repos}
+		result := formatGithubResults("test", githubTypeRepositories, "", maxGithubLimit, resp, DefaultMaxResultSize)
+
+		if len(result) > 80*1024 {
+			t.Errorf("result size %d exceeds 80 KB hard limit", len(result))
+		}
+		if !strings.Contains(result, "Results truncated") {
+			t.Error("expected truncation warning when hitting 80 KB limit")
+		}
+		if count := strings.Count(result, "### "); count >= maxGithubLimit {
+			t.Errorf("expected fewer than %d results due to size limit, got %d", maxGithubLimit, count)
+		}
+	})
+	t.Run("description truncation keeps UTF-8 runes", func(t *testing.T) {
+		result := truncateGithubText(strings.Repeat("я", maxGithubDescriptionSize))
+		if !utf8.ValidString(result) {
+			t.Errorf("truncated description must be valid UTF-8: %q", result[len(result)-20:])
+		}
+		if !strings.HasSuffix(result, "... [truncated]") {
+			t.Error("expected description truncation message")
+		}
+	})
+}
@@ -3,7 +3,10 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 
 	"pentagi/pkg/config"
@@ -14,11 +17,50 @@ import (
 
 	"github.com/sirupsen/logrus"
 	customsearch "google.golang.org/api/customsearch/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
 const googleMaxResults = 10
 
+// googleQuotaReasons are the error reasons of the Custom Search JSON API when the project quota is used up
+var googleQuotaReasons = []string{"dailyLimitExceeded", "quotaExceeded", "rateLimitExceeded", "userRateLimitExceeded"}
+
+// GoogleQuotaExceededError is returned when the Custom Search JSON API rejects the query
+// because the daily quota (100 free queries per day by default) of the API key is exhausted
+type GoogleQuotaExceededError struct {
+	Reason string
+	Err    error
+}
+
+func (e *GoogleQuotaExceededError) Error() string {
+	return fmt.Sprintf("google custom search quota exceeded (%s): %v", e.Reason, e.Err)
+}
+
+func (e *GoogleQuotaExceededError) Unwrap() error {
+	return e.Err
+}
+
+// asGoogleQuotaError converts the API error caused by the exhausted quota into GoogleQuotaExceededError,
+// other errors are returned as is
+func asGoogleQuotaError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	for _, item := range apiErr.Errors {
+		if slices.Contains(googleQuotaReasons, item.Reason) {
+			return &GoogleQuotaExceededError{Reason: item.Reason, Err: err}
+		}
+	}
+	if apiErr.Code == http.StatusTooManyRequests {
+		return &GoogleQuotaExceededError{Reason: http.StatusText(apiErr.Code), Err: err}
+	}
+
+	return err
+}
+
 type google struct {
 	cfg       *config.Config
 	flowID    int64
@@ -91,8 +133,14 @@ func (g *google) Handle(ctx context.Context, name string, args json.RawMessage)
 			}),
 		)
 
-		logger.WithError(err).Error("failed to search in google")
-		result = fmt.Sprintf("failed to search in google: %v", err)
+		var quotaErr *GoogleQuotaExceededError
+		if errors.As(err, &quotaErr) {
+			logger.WithError(err).Warn("google search quota is exceeded")
+			result = fmt.Sprintf("failed to search in google: %v, use other search tools until the quota is reset", err)
+		} else {
+			logger.WithError(err).Error("failed to search in google")
+			result = fmt.Sprintf("failed to search in google: %v", err)
+		}
 	}
 
 	if agentCtx, ok := GetAgentContext(ctx); ok {
@@ -114,18 +162,26 @@ func (g *google) Handle(ctx context.Context, name string, args json.RawMessage)
 func (g *google) search(ctx context.Context, svc *customsearch.Service, query string, numResults int64) (string, error) {
 	resp, err := svc.Cse.List().Context(ctx).Cx(g.cxKey()).Q(query).Lr(g.lrKey()).Num(numResults).Do()
 	if err != nil {
-		return "", fmt.Errorf("failed to do request: %w", err)
+		return "", fmt.Errorf("failed to do request: %w", asGoogleQuotaError(err))
 	}
 
-	return g.formatResults(resp), nil
+	return g.formatResults(resp, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
 }
 
-func (g *google) formatResults(res *customsearch.Search) string {
+// formatResults renders title, link and snippet of each item as markdown capped by sizeLimit
+func (g *google) formatResults(res *customsearch.Search, sizeLimit int) string {
 	var writer strings.Builder
+	budget := NewResultBudget(sizeLimit, 0)
 	for i, item := range res.Items {
-		writer.WriteString(fmt.Sprintf("# %d. %s\n\n", i+1, item.Title))
-		writer.WriteString(fmt.Sprintf("## URL\n%s\n\n", item.Link))
-		writer.WriteString(fmt.Sprintf("## Snippet\n\n%s\n\n", item.Snippet))
+		var entry strings.Builder
+		entry.WriteString(fmt.Sprintf("# %d. %s\n\n", i+1, item.Title))
+		entry.WriteString(fmt.Sprintf("## URL\n%s\n\n", item.Link))
+		entry.WriteString(fmt.Sprintf("## Snippet\n\n%s\n\n", item.Snippet))
+
+		if !budget.Add(&writer, entry.String()) {
+			writer.WriteString(budget.Notice(budget.Added(), len(res.Items), "results"))
+			break
+		}
 	}
 
 	return writer.String()
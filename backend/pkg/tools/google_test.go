@@ -2,7 +2,13 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -10,6 +16,7 @@ import (
 	"pentagi/pkg/database"
 
 	customsearch "google.golang.org/api/customsearch/v1"
+	"google.golang.org/api/googleapi"
 )
 
 const (
@@ -74,7 +81,7 @@ func TestGoogleFormatResults(t *testing.T) {
 
 	t.Run("empty results", func(t *testing.T) {
 		res := &customsearch.Search{Items: nil}
-		result := g.formatResults(res)
+		result := g.formatResults(res, DefaultMaxResultSize)
 		if result != "" {
 			t.Errorf("expected empty string for nil items, got %q", result)
 		}
@@ -90,7 +97,7 @@ func TestGoogleFormatResults(t *testing.T) {
 				},
 			},
 		}
-		result := g.formatResults(res)
+		result := g.formatResults(res, DefaultMaxResultSize)
 
 		if !strings.Contains(result, "# 1. Go Programming Language") {
 			t.Error("result should contain numbered title")
@@ -114,7 +121,7 @@ func TestGoogleFormatResults(t *testing.T) {
 				{Title: "Third", Link: "https://third.com", Snippet: "third snippet"},
 			},
 		}
-		result := g.formatResults(res)
+		result := g.formatResults(res, DefaultMaxResultSize)
 
 		if !strings.Contains(result, "# 1. First") {
 			t.Error("result should contain '# 1. First'")
@@ -137,7 +144,7 @@ func TestGoogleFormatResults(t *testing.T) {
 				},
 			},
 		}
-		result := g.formatResults(res)
+		result := g.formatResults(res, DefaultMaxResultSize)
 
 		if !strings.Contains(result, "Test & <Special> \"Characters\"") {
 			t.Error("title special characters should be preserved")
@@ -330,3 +337,145 @@ func TestGoogleConfigHelpers_NilConfig(t *testing.T) {
 		t.Errorf("lrKey() with nil config = %q, want empty", g.lrKey())
 	}
 }
+
+func TestGoogleFormatResults_Testdata(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "google_result_cse_nginx.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	var res customsearch.Search
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatalf("failed to decode testdata: %v", err)
+	}
+
+	g := &google{flowID: 1}
+	result := g.formatResults(&res, DefaultMaxResultSize)
+
+	expected := []string{
+		"# 1. Nginx Nginx 1.18.0 security vulnerabilities, CVEs - CVEdetails.com",
+		"## URL\nhttps://www.cvedetails.com/version/1092398/Nginx-Nginx-1.18.0.html",
+		"# 2. nginx security advisories",
+		"CVE-2021-23017. Not vulnerable: 1.21.0+, 1.20.1+.",
+		"# 3. CVE-2021-23017 : A security issue in nginx resolver was identified",
+		"## URL\nhttps://nvd.nist.gov/vuln/detail/CVE-2021-23017",
+	}
+	for _, want := range expected {
+		if !strings.Contains(result, want) {
+			t.Errorf("result missing %q:\n%s", want, result)
+		}
+	}
+
+	// plain title and snippet are rendered, html variants aren't
+	if strings.Contains(result, "<b>") {
+		t.Errorf("result must not contain html markup:\n%s", result)
+	}
+}
+
+func TestGoogleSizeLimits(t *testing.T) {
+	items := make([]*customsearch.Result, 100)
+	for i := range items {
+		items[i] = &customsearch.Result{
+			Title:   fmt.Sprintf("Test Result %d", i),
+			Link:    "https://example.com",
+			Snippet: strings.Repeat("X", 5000), // 5 KB each
+		}
+	}
+
+	g := &google{flowID: 1}
+	result := g.formatResults(&customsearch.Search{Items: items}, DefaultMaxResultSize)
+
+	if len(result) > 80*1024 {
+		t.Errorf("result size %d exceeds 80 KB hard limit", len(result))
+	}
+	if !strings.Contains(result, "output truncated, exceeded 80 KB limit") {
+		t.Error("expected truncation warning when hitting 80 KB limit")
+	}
+	if count := strings.Count(result, "## Snippet"); count >= 100 {
+		t.Errorf("expected fewer than 100 results due to size limit, got %d", count)
+	}
+}
+
+func TestGoogleHandle_Testdata(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		statusCode int
+		expected   string
+	}{
+		{"search results", "google_result_cse_nginx.json", http.StatusOK, "# 2. nginx security advisories"},
+		{"daily quota exceeded", "google_result_quota_exceeded.json", http.StatusTooManyRequests,
+			"google custom search quota exceeded (rateLimitExceeded)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatalf("failed to read testdata: %v", err)
+			}
+
+			var query url.Values
+			mockMux := http.NewServeMux()
+			mockMux.HandleFunc("/customsearch/v1", func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				w.Write(body)
+			})
+
+			proxy, err := newTestProxy("customsearch.googleapis.com", mockMux)
+			if err != nil {
+				t.Fatalf("failed to create proxy: %v", err)
+			}
+			defer proxy.Close()
+
+			cfg := testGoogleConfig()
+			cfg.ProxyURL = proxy.URL()
+			cfg.ExternalSSLCAPath = proxy.CACertPath()
+			slp := &searchLogProviderMock{}
+			g := NewGoogleTool(cfg, 1, nil, nil, slp)
+
+			ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+			result, err := g.Handle(ctx, GoogleToolName, []byte(`{"query":"nginx 1.18 vulnerabilities","max_results":3,"message":"m"}`))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Handle() = %q, want to contain %q", result, tt.expected)
+			}
+			if query.Get("cx") != testGoogleCXKey || query.Get("num") != "3" || query.Get("q") != "nginx 1.18 vulnerabilities" {
+				t.Errorf("unexpected request query: %v", query)
+			}
+			if slp.calls != 1 || slp.engine != database.SearchengineTypeGoogle {
+				t.Errorf("expected google search log, got %d calls of %q", slp.calls, slp.engine)
+			}
+		})
+	}
+}
+
+func TestAsGoogleQuotaError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantQuota bool
+	}{
+		{"daily limit", &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "dailyLimitExceeded"}}}, true},
+		{"too many requests", &googleapi.Error{Code: http.StatusTooManyRequests}, true},
+		{"invalid key", &googleapi.Error{Code: http.StatusBadRequest, Errors: []googleapi.ErrorItem{{Reason: "keyInvalid"}}}, false},
+		{"not api error", errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := asGoogleQuotaError(tt.err)
+			var quotaErr *GoogleQuotaExceededError
+			if got := errors.As(err, &quotaErr); got != tt.wantQuota {
+				t.Errorf("asGoogleQuotaError() = %v, want quota error %v", err, tt.wantQuota)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("asGoogleQuotaError() must keep the original error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// headlessBrowserTimeout limits the whole tool call including the page load, the waits and the screenshot
+	headlessBrowserTimeout     = 90 * time.Second
+	defaultHeadlessBrowserWait = time.Second
+	maxHeadlessBrowserWait     = 10 * time.Second
+	// headlessBrowserMaxTextLen is the max length of the rendered text in the output
+	headlessBrowserMaxTextLen = 40 * 1024
+	// headlessBrowserMaxLinks is the max number of the links in the output
+	headlessBrowserMaxLinks = 200
+)
+
+// headlessBrowser opens the pages in the isolated browser sessions
+type headlessBrowser interface {
+	// NewPage opens the blank page in the new browser context, the blocked function returns the reason
+	// to deny the load of the document from the URL or an empty string if the load is allowed
+	NewPage(ctx context.Context, blocked func(target string) string) (headlessPage, error)
+}
+
+// headlessPage is a single page of the headless browser
+type headlessPage interface {
+	// Navigate opens the URL and waits until the page is loaded
+	Navigate(ctx context.Context, target string) error
+	// Click clicks the first element by the CSS selector
+	Click(ctx context.Context, selector string) error
+	// State returns the rendered text and the links of the elements by the CSS selector or of the whole page
+	State(ctx context.Context, selector string) (headlessPageState, error)
+	// Screenshot returns the PNG image of the visible part of the page
+	Screenshot(ctx context.Context) ([]byte, error)
+	// Blocked returns the reasons of the denied document loads
+	Blocked() []string
+	Close() error
+}
+
+type headlessPageLink struct {
+	Text string `json:"text"`
+	Href string `json:"href"`
+}
+
+// headlessPageState is the rendered content of the page
+type headlessPageState struct {
+	URL   string             `json:"url"`
+	Title string             `json:"title"`
+	Text  string             `json:"text"`
+	Links []headlessPageLink `json:"links"`
+	// Matched is the number of the elements found by the selector
+	Matched int `json:"matched"`
+}
+
+// headlessBrowserReport is the result of the action on the page
+type headlessBrowserReport struct {
+	URL        string
+	Action     HeadlessBrowserActionType
+	Selector   string
+	State      headlessPageState
+	Blocked    []string
+	Screenshot *database.FlowArtifact
+	// OCR is the text recognized on the screenshot if the text recognition is enabled
+	OCR *headlessScreenshotOCR
+}
+
+type headlessScreenshotOCR struct {
+	Text string
+	Err  error
+}
+
+// headlessBrowserTool represents the headless browser tool which renders the pages with scripts
+type headlessBrowserTool struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	db        database.Querier
+	browser   headlessBrowser
+	ocr       ocrBackend
+}
+
+// NewHeadlessBrowserTool creates a new headless browser tool instance which uses the remote devtools
+// endpoint of the browser, the screenshots are saved to the flow artifacts
+func NewHeadlessBrowserTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	db database.Querier,
+) Tool {
+	tool := &headlessBrowserTool{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		db:        db,
+		ocr:       newOCRBackend(cfg),
+	}
+	if cfg != nil {
+		tool.browser = newCDPBrowser(cfg.HeadlessBrowserURL)
+	}
+
+	return tool
+}
+
+// Handle processes a headless browser request from an AI agent
+func (h *headlessBrowserTool) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !h.IsAvailable() {
+		return "", fmt.Errorf("headless_browser is not available")
+	}
+
+	var action HeadlessBrowserAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(h.flowID, h.taskID, h.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal headless browser action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	target, err := normalizeHTTPHeadersURL(action.URL)
+	if err != nil {
+		return fmt.Sprintf("failed to open page: %v", err), nil
+	}
+
+	action.Selector = strings.TrimSpace(action.Selector)
+	switch action.Action {
+	case HeadlessNavigate, HeadlessExtract, HeadlessScreenshot:
+	case HeadlessClick:
+		if action.Selector == "" {
+			return "failed to open page: selector is required for the click action", nil
+		}
+	default:
+		return fmt.Sprintf("failed to open page: unknown action '%s', use one of %s, %s, %s, %s", action.Action,
+			HeadlessNavigate, HeadlessClick, HeadlessExtract, HeadlessScreenshot), nil
+	}
+
+	wait := defaultHeadlessBrowserWait
+	if action.WaitMs != nil {
+		wait = min(max(time.Duration(action.WaitMs.Int64())*time.Millisecond, 0), maxHeadlessBrowserWait)
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"url":      target[:min(len(target), 1000)],
+		"action":   action.Action,
+		"selector": action.Selector,
+		"wait":     wait.String(),
+	})
+
+	report, err := h.run(ctx, target, action, wait)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("headless browser error swallowed"),
+			langfuse.WithEventInput(target),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name": HeadlessBrowserToolName,
+				"url":       target,
+				"action":    action.Action,
+				"selector":  action.Selector,
+				"error":     err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to run headless browser action")
+		return fmt.Sprintf("failed to %s page: %v", action.Action, err), nil
+	}
+
+	return formatHeadlessBrowserReport(h.flowID, report, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
+}
+
+// run opens the page in the new browser session, every document load including redirects
+// and frames is checked against the flow scope by the browser before it's sent
+func (h *headlessBrowserTool) run(
+	ctx context.Context,
+	target string,
+	action HeadlessBrowserAction,
+	wait time.Duration,
+) (headlessBrowserReport, error) {
+	report := headlessBrowserReport{URL: target, Action: action.Action, Selector: action.Selector}
+
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, headlessBrowserTimeout))
+	defer cancel()
+
+	guard := scopeGuardFromContext(ctx)
+	blocked := func(target string) string {
+		return headlessBrowserBlockedURL(guard, target)
+	}
+
+	page, err := h.browser.NewPage(ctx, blocked)
+	if err != nil {
+		return report, fmt.Errorf("failed to open browser page: %w", err)
+	}
+	defer page.Close()
+
+	if err := page.Navigate(ctx, target); err != nil {
+		if denied := page.Blocked(); len(denied) > 0 {
+			return report, fmt.Errorf("navigation is blocked: %s", strings.Join(denied, "; "))
+		}
+		return report, fmt.Errorf("failed to navigate to %s: %w", target, err)
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		return report, err
+	}
+
+	if action.Action == HeadlessClick {
+		if err := page.Click(ctx, action.Selector); err != nil {
+			return report, fmt.Errorf("failed to click '%s': %w", action.Selector, err)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return report, err
+		}
+	}
+
+	selector := ""
+	if action.Action == HeadlessExtract {
+		selector = action.Selector
+	}
+	report.State, err = page.State(ctx, selector)
+	if err != nil {
+		return report, fmt.Errorf("failed to get page content: %w", err)
+	}
+	report.Blocked = page.Blocked()
+
+	// the loads are blocked by the browser, this is the last line of defense if the page is shown anyway
+	if reason := blocked(report.State.URL); reason != "" {
+		return report, fmt.Errorf("page %s is blocked: %s", report.State.URL, reason)
+	}
+
+	if action.Action == HeadlessScreenshot {
+		image, err := page.Screenshot(ctx)
+		if err != nil {
+			return report, fmt.Errorf("failed to take screenshot: %w", err)
+		}
+
+		store := &artifacts{
+			flowID:    h.flowID,
+			taskID:    h.taskID,
+			subtaskID: h.subtaskID,
+			cfg:       h.cfg,
+			db:        h.db,
+		}
+		artifact, err := store.store(ctx, headlessScreenshotName(report.State.URL, time.Now()),
+			"image/png", bytes.NewReader(image))
+		if err != nil {
+			return report, fmt.Errorf("failed to save screenshot: %w", err)
+		}
+		report.Screenshot = &artifact
+
+		// the failed recognition doesn't fail the screenshot which is already saved
+		if h.ocr != nil {
+			text, err := recognizeImageText(ctx, h.ocr, image)
+			report.OCR = &headlessScreenshotOCR{Text: text, Err: err}
+		}
+	}
+
+	return report, nil
+}
+
+// IsAvailable returns true if the devtools endpoint is configured and the artifacts can be stored
+func (h *headlessBrowserTool) IsAvailable() bool {
+	return h.cfg != nil && h.cfg.HeadlessBrowserURL != "" && h.db != nil && h.browser != nil
+}
+
+// headlessBrowserBlockedURL returns the reason to deny the load of the document, the local and
+// internal schemes of the browser are denied because they give access to the browser host
+func headlessBrowserBlockedURL(guard *ScopeGuard, target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return fmt.Sprintf("invalid url '%s'", target)
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return guard.CheckURL(target)
+	case "about", "data", "blob":
+		return ""
+	default:
+		return fmt.Sprintf("url scheme '%s' isn't allowed", parsed.Scheme)
+	}
+}
+
+func headlessScreenshotName(target string, now time.Time) string {
+	host := "page"
+	if parsed, err := url.Parse(target); err == nil && parsed.Hostname() != "" {
+		host = strings.NewReplacer(":", "_", "[", "", "]", "").Replace(parsed.Hostname())
+	}
+
+	return fmt.Sprintf("screenshot-%s-%s.png", host, now.UTC().Format("20060102-150405.000"))
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// formatHeadlessBrowserReport renders the page state as markdown, the links are dropped
+// and the text is cut to fit the size limit
+func formatHeadlessBrowserReport(flowID int64, report headlessBrowserReport, sizeLimit int) string {
+	var sb strings.Builder
+	state := report.State
+
+	sb.WriteString("# Headless Browser\n\n")
+	sb.WriteString(fmt.Sprintf("**URL:** %s  \n", state.URL))
+	if state.URL != report.URL {
+		sb.WriteString(fmt.Sprintf("**Requested URL:** %s  \n", report.URL))
+	}
+	if state.Title != "" {
+		sb.WriteString(fmt.Sprintf("**Title:** %s  \n", state.Title))
+	}
+	sb.WriteString(fmt.Sprintf("**Action:** %s", report.Action))
+	if report.Selector != "" && report.Action != HeadlessNavigate && report.Action != HeadlessScreenshot {
+		sb.WriteString(fmt.Sprintf(" `%s`", report.Selector))
+	}
+	sb.WriteString("  \n")
+
+	for _, reason := range report.Blocked {
+		sb.WriteString(fmt.Sprintf("\n**Note:** blocked load, %s\n", reason))
+	}
+
+	if artifact := report.Screenshot; artifact != nil {
+		sb.WriteString(fmt.Sprintf("\n## Screenshot\n\nartifact '%s' (%s, %d bytes) is saved with id %d, "+
+			"download it from %s or copy it to the container by the '%s' tool\n", artifact.Name,
+			artifact.ContentType, artifact.Size, artifact.ID, FlowArtifactURL(flowID, artifact.ID), ArtifactsToolName))
+		if report.OCR != nil {
+			sb.WriteString("\n## Screenshot Text (OCR)\n\n")
+			if report.OCR.Err != nil {
+				sb.WriteString(fmt.Sprintf("failed to recognize text: %v\n", report.OCR.Err))
+			} else {
+				sb.WriteString(formatOCRText(report.OCR.Text, sizeLimit-sb.Len()-truncationMsgBuffer))
+			}
+		}
+		return sb.String()
+	}
+
+	if report.Action == HeadlessExtract && report.Selector != "" && state.Matched == 0 {
+		sb.WriteString(fmt.Sprintf("\nNo elements found by the selector `%s`.\n", report.Selector))
+		return sb.String()
+	}
+
+	links := state.Links
+	linksNote := ""
+	if len(links) > headlessBrowserMaxLinks {
+		linksNote = fmt.Sprintf("\n...and %d more links\n", len(links)-headlessBrowserMaxLinks)
+		links = links[:headlessBrowserMaxLinks]
+	}
+
+	var linksSection strings.Builder
+	if len(links) > 0 {
+		linksSection.WriteString(fmt.Sprintf("\n## Links (%d)\n\n", len(state.Links)))
+		for _, link := range links {
+			text := strings.Join(strings.Fields(link.Text), " ")
+			if text == "" {
+				text = link.Href
+			}
+			linksSection.WriteString(fmt.Sprintf("- [%s](%s)\n", text[:min(len(text), 200)], link.Href))
+		}
+		linksSection.WriteString(linksNote)
+	}
+
+	text := strings.TrimSpace(state.Text)
+	limit := max(min(headlessBrowserMaxTextLen, sizeLimit-sb.Len()-linksSection.Len()-truncationMsgBuffer), 0)
+	sb.WriteString("\n## Text\n\n")
+	switch {
+	case text == "":
+		sb.WriteString("The page has no visible text.\n")
+	case len(text) > limit:
+		sb.WriteString(TruncateWithNotice(text, limit, textTruncatedNotice))
+	default:
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	}
+
+	if sb.Len()+linksSection.Len() > sizeLimit {
+		return sb.String()
+	}
+	sb.WriteString(linksSection.String())
+
+	return sb.String()
+}
@@ -0,0 +1,557 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	cdpRequestTimeout = 10 * time.Second
+	cdpWriteTimeout   = 10 * time.Second
+	cdpCloseTimeout   = 5 * time.Second
+	// cdpLoadTimeout is how long the page is waited to be loaded, the page which is still
+	// loading the resources after it is returned as is
+	cdpLoadTimeout      = 20 * time.Second
+	cdpLoadPollInterval = 100 * time.Millisecond
+	cdpViewportWidth    = 1280
+	cdpViewportHeight   = 800
+)
+
+// cdpStateScript collects the text and the links of the elements by the selector or of the whole page
+const cdpStateScript = `((selector) => {
+	const roots = selector ? Array.from(document.querySelectorAll(selector)) : [document.body || document.documentElement];
+	const links = [];
+	const seen = new Set();
+	for (const root of roots) {
+		const anchors = root.matches("a[href]") ? [root] : [];
+		anchors.push(...root.querySelectorAll("a[href]"));
+		for (const a of anchors) {
+			if (!a.href || seen.has(a.href)) continue;
+			seen.add(a.href);
+			links.push({text: (a.innerText || a.textContent || "").trim(), href: a.href});
+		}
+	}
+	return {
+		url: location.href,
+		title: document.title,
+		text: roots.map((root) => root.innerText || root.textContent || "").join("\n\n"),
+		links: links,
+		matched: roots.length,
+	};
+})(%s)`
+
+// cdpClickScript clicks the first element by the selector and returns false if it isn't found
+const cdpClickScript = `((selector) => {
+	const el = document.querySelector(selector);
+	if (!el) return false;
+	el.scrollIntoView({block: "center"});
+	el.click();
+	return true;
+})(%s)`
+
+// cdpBrowser is the headless browser which is controlled by the Chrome DevTools Protocol
+// over the remote debugging endpoint, every page is opened in the new browser context
+// so the pages of the different calls don't share cookies and storage
+type cdpBrowser struct {
+	endpoint string
+}
+
+func newCDPBrowser(endpoint string) headlessBrowser {
+	if endpoint == "" {
+		return nil
+	}
+
+	return &cdpBrowser{endpoint: endpoint}
+}
+
+// resolve returns the websocket URL of the browser, the http endpoint is asked for it and the host
+// of the returned URL is replaced because the browser reports its own listen address
+func (b *cdpBrowser) resolve(ctx context.Context) (string, error) {
+	endpoint, err := url.Parse(b.endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid devtools endpoint: %w", err)
+	}
+
+	switch strings.ToLower(endpoint.Scheme) {
+	case "ws", "wss":
+		return endpoint.String(), nil
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("unsupported devtools endpoint scheme '%s'", endpoint.Scheme)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cdpRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.JoinPath("json", "version").String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create devtools request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get devtools version: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get devtools version: unexpected status %s", resp.Status)
+	}
+
+	var version struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("failed to decode devtools version: %w", err)
+	}
+
+	wsURL, err := url.Parse(version.WebSocketDebuggerURL)
+	if err != nil || wsURL.Path == "" {
+		return "", fmt.Errorf("invalid devtools websocket url '%s'", version.WebSocketDebuggerURL)
+	}
+	wsURL.Host = endpoint.Host
+	wsURL.Scheme = "ws"
+	if strings.EqualFold(endpoint.Scheme, "https") {
+		wsURL.Scheme = "wss"
+	}
+
+	return wsURL.String(), nil
+}
+
+func (b *cdpBrowser) NewPage(ctx context.Context, blocked func(target string) string) (headlessPage, error) {
+	wsURL, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to devtools: %w", err)
+	}
+
+	page := &cdpPage{blocked: blocked}
+	page.conn = newCDPConn(ws, page.handleEvent)
+
+	if err := page.open(ctx); err != nil {
+		page.Close()
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// cdpPage is the page target attached to the dedicated devtools connection
+type cdpPage struct {
+	conn             *cdpConn
+	blocked          func(target string) string
+	browserContextID string
+	sessionID        string
+
+	mx     sync.Mutex
+	denied []string
+}
+
+// open creates the page in the new browser context and intercepts the document requests to check them
+func (p *cdpPage) open(ctx context.Context) error {
+	var browserContext struct {
+		BrowserContextID string `json:"browserContextId"`
+	}
+	if err := p.conn.call(ctx, "", "Target.createBrowserContext", map[string]any{
+		"disposeOnDetach": true,
+	}, &browserContext); err != nil {
+		return fmt.Errorf("failed to create browser context: %w", err)
+	}
+	p.browserContextID = browserContext.BrowserContextID
+
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := p.conn.call(ctx, "", "Target.createTarget", map[string]any{
+		"url":              "about:blank",
+		"browserContextId": p.browserContextID,
+	}, &target); err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := p.conn.call(ctx, "", "Target.attachToTarget", map[string]any{
+		"targetId": target.TargetID,
+		"flatten":  true,
+	}, &session); err != nil {
+		return fmt.Errorf("failed to attach to page: %w", err)
+	}
+	p.sessionID = session.SessionID
+
+	steps := []struct {
+		method string
+		params map[string]any
+	}{
+		{"Page.enable", nil},
+		// frames are documents too, so every load of the page content is checked
+		{"Fetch.enable", map[string]any{
+			"patterns": []map[string]any{{"urlPattern": "*", "resourceType": "Document", "requestStage": "Request"}},
+		}},
+		{"Emulation.setDeviceMetricsOverride", map[string]any{
+			"width":             cdpViewportWidth,
+			"height":            cdpViewportHeight,
+			"deviceScaleFactor": 1,
+			"mobile":            false,
+		}},
+	}
+	for _, step := range steps {
+		if err := p.conn.call(ctx, p.sessionID, step.method, step.params, nil); err != nil {
+			return fmt.Errorf("failed to set up page: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// handleEvent is called from the read loop of the connection, so it mustn't wait for the responses
+func (p *cdpPage) handleEvent(msg cdpMessage) {
+	if msg.Method != "Fetch.requestPaused" {
+		return
+	}
+
+	var params struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL string `json:"url"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return
+	}
+
+	if reason := p.blocked(params.Request.URL); reason != "" {
+		p.mx.Lock()
+		p.denied = append(p.denied, fmt.Sprintf("%s: %s", params.Request.URL, reason))
+		p.mx.Unlock()
+
+		_ = p.conn.notify(msg.SessionID, "Fetch.failRequest", map[string]any{
+			"requestId":   params.RequestID,
+			"errorReason": "BlockedByClient",
+		})
+		return
+	}
+
+	_ = p.conn.notify(msg.SessionID, "Fetch.continueRequest", map[string]any{
+		"requestId": params.RequestID,
+	})
+}
+
+func (p *cdpPage) Navigate(ctx context.Context, target string) error {
+	var result struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.conn.call(ctx, p.sessionID, "Page.navigate", map[string]any{"url": target}, &result); err != nil {
+		return err
+	}
+	if result.ErrorText != "" {
+		return errors.New(result.ErrorText)
+	}
+
+	return p.waitLoad(ctx)
+}
+
+// waitLoad polls the state of the document because the load events are lost if the page is loaded
+// before the subscription, the timeout of the load isn't an error
+func (p *cdpPage) waitLoad(ctx context.Context) error {
+	loadCtx, cancel := context.WithTimeout(ctx, cdpLoadTimeout)
+	defer cancel()
+
+	for {
+		var state string
+		// the evaluation fails while the document is replaced by the navigation
+		if err := p.evaluate(loadCtx, "document.readyState", &state); err == nil && state == "complete" {
+			return nil
+		}
+
+		if err := sleepContext(loadCtx, cdpLoadPollInterval); err != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *cdpPage) Click(ctx context.Context, selector string) error {
+	encoded, err := json.Marshal(selector)
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	if err := p.evaluate(ctx, fmt.Sprintf(cdpClickScript, encoded), &found); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("element isn't found")
+	}
+
+	return nil
+}
+
+func (p *cdpPage) State(ctx context.Context, selector string) (headlessPageState, error) {
+	var state headlessPageState
+	if err := p.waitLoad(ctx); err != nil {
+		return state, err
+	}
+
+	encoded, err := json.Marshal(selector)
+	if err != nil {
+		return state, err
+	}
+	if err := p.evaluate(ctx, fmt.Sprintf(cdpStateScript, encoded), &state); err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
+
+func (p *cdpPage) Screenshot(ctx context.Context) ([]byte, error) {
+	var result struct {
+		Data string `json:"data"`
+	}
+	if err := p.conn.call(ctx, p.sessionID, "Page.captureScreenshot", map[string]any{
+		"format": "png",
+	}, &result); err != nil {
+		return nil, err
+	}
+
+	image, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return image, nil
+}
+
+func (p *cdpPage) Blocked() []string {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	return append([]string(nil), p.denied...)
+}
+
+// Close disposes the browser context with all its pages and closes the connection
+func (p *cdpPage) Close() error {
+	if p.browserContextID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), cdpCloseTimeout)
+		_ = p.conn.call(ctx, "", "Target.disposeBrowserContext", map[string]any{
+			"browserContextId": p.browserContextID,
+		}, nil)
+		cancel()
+	}
+
+	return p.conn.close()
+}
+
+// evaluate runs the expression in the page and decodes the returned value
+func (p *cdpPage) evaluate(ctx context.Context, expression string, value any) error {
+	var result struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception *struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	if err := p.conn.call(ctx, p.sessionID, "Runtime.evaluate", map[string]any{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	}, &result); err != nil {
+		return err
+	}
+
+	if details := result.ExceptionDetails; details != nil {
+		if details.Exception != nil && details.Exception.Description != "" {
+			return errors.New(details.Exception.Description)
+		}
+		return errors.New(details.Text)
+	}
+	if len(result.Result.Value) == 0 {
+		return fmt.Errorf("script returned no value")
+	}
+
+	return json.Unmarshal(result.Result.Value, value)
+}
+
+// cdpMessage is the command, the response or the event of the protocol
+type cdpMessage struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *cdpError       `json:"error,omitempty"`
+}
+
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+func (e *cdpError) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("%s (%d): %s", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// cdpConn matches the responses to the commands and passes the events to the handler
+type cdpConn struct {
+	ws      *websocket.Conn
+	onEvent func(cdpMessage)
+	writeMx sync.Mutex
+
+	mx      sync.Mutex
+	nextID  int64
+	pending map[int64]chan cdpMessage
+	err     error
+	done    chan struct{}
+}
+
+func newCDPConn(ws *websocket.Conn, onEvent func(cdpMessage)) *cdpConn {
+	conn := &cdpConn{
+		ws:      ws,
+		onEvent: onEvent,
+		pending: make(map[int64]chan cdpMessage),
+		done:    make(chan struct{}),
+	}
+	go conn.readLoop()
+
+	return conn
+}
+
+func (c *cdpConn) readLoop() {
+	defer close(c.done)
+
+	for {
+		var msg cdpMessage
+		if err := c.ws.ReadJSON(&msg); err != nil {
+			c.mx.Lock()
+			c.err = err
+			c.mx.Unlock()
+			return
+		}
+
+		if msg.ID == 0 {
+			if msg.Method != "" && c.onEvent != nil {
+				c.onEvent(msg)
+			}
+			continue
+		}
+
+		c.mx.Lock()
+		response, ok := c.pending[msg.ID]
+		delete(c.pending, msg.ID)
+		c.mx.Unlock()
+
+		if ok {
+			response <- msg
+		}
+	}
+}
+
+func (c *cdpConn) write(msg cdpMessage) error {
+	c.writeMx.Lock()
+	defer c.writeMx.Unlock()
+
+	_ = c.ws.SetWriteDeadline(time.Now().Add(cdpWriteTimeout))
+	return c.ws.WriteJSON(msg)
+}
+
+func (c *cdpConn) command(sessionID, method string, params any) (cdpMessage, error) {
+	msg := cdpMessage{SessionID: sessionID, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return msg, fmt.Errorf("failed to marshal %s params: %w", method, err)
+		}
+		msg.Params = raw
+	}
+
+	return msg, nil
+}
+
+// call sends the command and waits for its result
+func (c *cdpConn) call(ctx context.Context, sessionID, method string, params, result any) error {
+	msg, err := c.command(sessionID, method, params)
+	if err != nil {
+		return err
+	}
+
+	response := make(chan cdpMessage, 1)
+	c.mx.Lock()
+	c.nextID++
+	msg.ID = c.nextID
+	c.pending[msg.ID] = response
+	c.mx.Unlock()
+
+	defer func() {
+		c.mx.Lock()
+		delete(c.pending, msg.ID)
+		c.mx.Unlock()
+	}()
+
+	if err := c.write(msg); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		c.mx.Lock()
+		defer c.mx.Unlock()
+		return fmt.Errorf("devtools connection is closed: %v", c.err)
+	case resp := <-response:
+		if resp.Error != nil {
+			return fmt.Errorf("%s failed: %w", method, resp.Error)
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("failed to decode %s result: %w", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// notify sends the command without waiting for its result
+func (c *cdpConn) notify(sessionID, method string, params any) error {
+	msg, err := c.command(sessionID, method, params)
+	if err != nil {
+		return err
+	}
+
+	c.mx.Lock()
+	c.nextID++
+	msg.ID = c.nextID
+	c.mx.Unlock()
+
+	return c.write(msg)
+}
+
+func (c *cdpConn) close() error {
+	err := c.ws.Close()
+	<-c.done
+
+	return err
+}
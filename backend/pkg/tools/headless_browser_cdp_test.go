@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeDevtools emulates the browser endpoint, the navigation pauses the document request
+// and waits for the decision of the client like the Fetch domain does
+type fakeDevtools struct {
+	mx      sync.Mutex
+	methods []string
+}
+
+func (f *fakeDevtools) record(method string) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.methods = append(f.methods, method)
+}
+
+func (f *fakeDevtools) calls() []string {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return slices.Clone(f.methods)
+}
+
+func (f *fakeDevtools) serve(t *testing.T, ws *websocket.Conn) {
+	reply := func(msg cdpMessage, result any) {
+		raw, _ := json.Marshal(result)
+		if err := ws.WriteJSON(cdpMessage{ID: msg.ID, SessionID: msg.SessionID, Result: raw}); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}
+
+	var current string
+	for {
+		var msg cdpMessage
+		if err := ws.ReadJSON(&msg); err != nil {
+			return
+		}
+		f.record(msg.Method)
+
+		var params map[string]any
+		_ = json.Unmarshal(msg.Params, &params)
+
+		switch msg.Method {
+		case "Target.createBrowserContext":
+			reply(msg, map[string]any{"browserContextId": "context-1"})
+		case "Target.createTarget":
+			reply(msg, map[string]any{"targetId": "target-1"})
+		case "Target.attachToTarget":
+			reply(msg, map[string]any{"sessionId": "session-1"})
+		case "Page.navigate":
+			target, _ := params["url"].(string)
+			event, _ := json.Marshal(map[string]any{"requestId": "request-1", "request": map[string]any{"url": target}})
+			_ = ws.WriteJSON(cdpMessage{SessionID: msg.SessionID, Method: "Fetch.requestPaused", Params: event})
+
+			var decision cdpMessage
+			if err := ws.ReadJSON(&decision); err != nil {
+				return
+			}
+			f.record(decision.Method)
+			if decision.Method == "Fetch.failRequest" {
+				reply(msg, map[string]any{"frameId": "frame-1", "errorText": "net::ERR_BLOCKED_BY_CLIENT"})
+				continue
+			}
+			current = target
+			reply(msg, map[string]any{"frameId": "frame-1"})
+		case "Runtime.evaluate":
+			expression, _ := params["expression"].(string)
+			switch {
+			case expression == "document.readyState":
+				reply(msg, map[string]any{"result": map[string]any{"value": "complete"}})
+			case strings.Contains(expression, `"#broken"`):
+				reply(msg, map[string]any{"result": map[string]any{}, "exceptionDetails": map[string]any{
+					"text": "Uncaught", "exception": map[string]any{"description": "SyntaxError: '#broken' is not a valid selector"},
+				}})
+			case strings.Contains(expression, "el.click()"):
+				reply(msg, map[string]any{"result": map[string]any{"value": true}})
+			default:
+				reply(msg, map[string]any{"result": map[string]any{"value": map[string]any{
+					"url": current, "title": "Fake", "text": "rendered by scripts", "matched": 1,
+					"links": []map[string]any{{"text": "Home", "href": current}},
+				}}})
+			}
+		case "Page.captureScreenshot":
+			reply(msg, map[string]any{"data": base64.StdEncoding.EncodeToString([]byte("png-data"))})
+		case "Target.disposeBrowserContext":
+			reply(msg, map[string]any{})
+		default:
+			reply(msg, map[string]any{})
+		}
+	}
+}
+
+func newFakeDevtoolsServer(t *testing.T, devtools *fakeDevtools) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/version":
+			// the browser reports its own listen address which isn't reachable from the client
+			_, _ = w.Write([]byte(`{"Browser":"HeadlessChrome/145.0","webSocketDebuggerUrl":"ws://0.0.0.0:9222/devtools/browser/fake"}`))
+		case "/devtools/browser/fake":
+			ws, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("failed to upgrade: %v", err)
+				return
+			}
+			defer ws.Close()
+			devtools.serve(t, ws)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCDPBrowserResolve(t *testing.T) {
+	server := newFakeDevtoolsServer(t, &fakeDevtools{})
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  string
+	}{
+		{server.URL, "ws://" + host + "/devtools/browser/fake", ""},
+		{"ws://chrome:9222/devtools/browser/abc", "ws://chrome:9222/devtools/browser/abc", ""},
+		{server.URL + "/missing", "", "unexpected status 404"},
+		{"ftp://chrome:9222", "", "unsupported devtools endpoint scheme 'ftp'"},
+	}
+
+	for _, tt := range tests {
+		got, err := (&cdpBrowser{endpoint: tt.endpoint}).resolve(t.Context())
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolve(%q) error = %v, want %q", tt.endpoint, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolve(%q) = %q, %v, want %q", tt.endpoint, got, err, tt.want)
+		}
+	}
+
+	if newCDPBrowser("") != nil {
+		t.Error("newCDPBrowser() with empty endpoint isn't nil")
+	}
+}
+
+func TestCDPBrowserPage(t *testing.T) {
+	devtools := &fakeDevtools{}
+	server := newFakeDevtoolsServer(t, devtools)
+
+	blocked := func(target string) string {
+		if strings.Contains(target, "evil") {
+			return "domain 'evil.example' is out of the flow scope"
+		}
+		return ""
+	}
+
+	page, err := newCDPBrowser(server.URL).NewPage(t.Context(), blocked)
+	if err != nil {
+		t.Fatalf("NewPage() unexpected error: %v", err)
+	}
+
+	if err := page.Navigate(t.Context(), "http://evil.example/"); err == nil ||
+		err.Error() != "net::ERR_BLOCKED_BY_CLIENT" {
+		t.Errorf("Navigate() out of scope error = %v", err)
+	}
+	if got := page.Blocked(); len(got) != 1 || got[0] != "http://evil.example/: domain 'evil.example' is out of the flow scope" {
+		t.Errorf("Blocked() = %v", got)
+	}
+
+	if err := page.Navigate(t.Context(), "http://app.example/"); err != nil {
+		t.Fatalf("Navigate() unexpected error: %v", err)
+	}
+	if err := page.Click(t.Context(), "#login"); err != nil {
+		t.Errorf("Click() unexpected error: %v", err)
+	}
+	if err := page.Click(t.Context(), "#broken"); err == nil || !strings.Contains(err.Error(), "is not a valid selector") {
+		t.Errorf("Click() invalid selector error = %v", err)
+	}
+
+	state, err := page.State(t.Context(), "")
+	if err != nil {
+		t.Fatalf("State() unexpected error: %v", err)
+	}
+	if state.URL != "http://app.example/" || state.Title != "Fake" || state.Text != "rendered by scripts" ||
+		len(state.Links) != 1 || state.Links[0].Href != "http://app.example/" {
+		t.Errorf("State() = %+v", state)
+	}
+
+	image, err := page.Screenshot(t.Context())
+	if err != nil || string(image) != "png-data" {
+		t.Errorf("Screenshot() = %q, %v", image, err)
+	}
+
+	if err := page.Close(); err != nil {
+		t.Errorf("Close() unexpected error: %v", err)
+	}
+
+	wantOrder := []string{
+		"Target.createBrowserContext", "Target.createTarget", "Target.attachToTarget",
+		"Page.enable", "Fetch.enable", "Emulation.setDeviceMetricsOverride",
+		"Page.navigate", "Fetch.failRequest", "Page.navigate", "Fetch.continueRequest",
+	}
+	calls := devtools.calls()
+	if len(calls) < len(wantOrder) || !slices.Equal(calls[:len(wantOrder)], wantOrder) {
+		t.Errorf("calls = %v, want prefix %v", calls, wantOrder)
+	}
+	if calls[len(calls)-1] != "Target.disposeBrowserContext" {
+		t.Errorf("last call = %q, want the browser context to be disposed", calls[len(calls)-1])
+	}
+}
@@ -0,0 +1,409 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+)
+
+var (
+	stubTitleRegexp = regexp.MustCompile(`(?s)<title>(.*?)</title>`)
+	stubLinkRegexp  = regexp.MustCompile(`<a (?:id="([^"]*)" )?href="([^"]*)">(.*?)</a>`)
+	stubTagRegexp   = regexp.MustCompile(`<[^>]+>`)
+)
+
+// stubHeadlessBrowser renders the static pages without scripts, the click follows the link by the id
+// selector and the extract action supports the id selector only
+type stubHeadlessBrowser struct {
+	client *http.Client
+	pages  int
+}
+
+func (b *stubHeadlessBrowser) NewPage(_ context.Context, blocked func(target string) string) (headlessPage, error) {
+	b.pages++
+	page := &stubHeadlessPage{blocked: blocked}
+	page.client = &http.Client{
+		Transport: b.client.Transport,
+		CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+			return page.check(req.URL.String())
+		},
+	}
+
+	return page, nil
+}
+
+type stubHeadlessPage struct {
+	client  *http.Client
+	blocked func(target string) string
+	url     string
+	body    string
+	denied  []string
+	closed  bool
+}
+
+func (p *stubHeadlessPage) check(target string) error {
+	if reason := p.blocked(target); reason != "" {
+		p.denied = append(p.denied, fmt.Sprintf("%s: %s", target, reason))
+		return errors.New("net::ERR_BLOCKED_BY_CLIENT")
+	}
+	return nil
+}
+
+func (p *stubHeadlessPage) Navigate(ctx context.Context, target string) error {
+	if err := p.check(target); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	p.url, p.body = resp.Request.URL.String(), string(body)
+
+	return nil
+}
+
+func (p *stubHeadlessPage) Click(ctx context.Context, selector string) error {
+	for _, match := range stubLinkRegexp.FindAllStringSubmatch(p.body, -1) {
+		if "#"+match[1] == selector {
+			base, err := url.Parse(p.url)
+			if err != nil {
+				return err
+			}
+			next, err := base.Parse(match[2])
+			if err != nil {
+				return err
+			}
+			return p.Navigate(ctx, next.String())
+		}
+	}
+
+	return errors.New("element isn't found")
+}
+
+func (p *stubHeadlessPage) State(_ context.Context, selector string) (headlessPageState, error) {
+	state := headlessPageState{URL: p.url, Matched: 1}
+	if match := stubTitleRegexp.FindStringSubmatch(p.body); match != nil {
+		state.Title = match[1]
+	}
+
+	root := p.body
+	if selector != "" {
+		start := strings.Index(p.body, fmt.Sprintf(`id="%s"`, strings.TrimPrefix(selector, "#")))
+		if start == -1 {
+			state.Matched = 0
+			return state, nil
+		}
+		root = p.body[start:]
+		root = root[strings.Index(root, ">")+1 : strings.Index(root, "</div>")]
+	}
+
+	base, _ := url.Parse(p.url)
+	for _, match := range stubLinkRegexp.FindAllStringSubmatch(root, -1) {
+		href, _ := base.Parse(match[2])
+		state.Links = append(state.Links, headlessPageLink{Text: html.UnescapeString(match[3]), Href: href.String()})
+	}
+	root = stubTitleRegexp.ReplaceAllString(root, "")
+	state.Text = strings.Join(strings.Fields(html.UnescapeString(stubTagRegexp.ReplaceAllString(root, " "))), " ")
+
+	return state, nil
+}
+
+func (p *stubHeadlessPage) Screenshot(context.Context) ([]byte, error) {
+	return []byte("\x89PNG\r\n\x1a\n" + p.url), nil
+}
+
+func (p *stubHeadlessPage) Blocked() []string {
+	return p.denied
+}
+
+func (p *stubHeadlessPage) Close() error {
+	p.closed = true
+	return nil
+}
+
+func newHeadlessBrowserServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var external string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head><title>Example App</title></head><body>`+
+				`<h1>Welcome</h1><div id="menu"><a id="next" href="next">Next page</a> `+
+				`<a href="/docs">Docs &amp; API</a></div></body></html>`)
+		case "/next":
+			fmt.Fprint(w, `<html><head><title>Next</title></head><body><p>Second page</p></body></html>`)
+		case "/external":
+			http.Redirect(w, r, external, http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	external = strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/"
+
+	return server
+}
+
+func newTestHeadlessBrowserTool(t *testing.T, server *httptest.Server) (*headlessBrowserTool, *artifactsMockQuerier) {
+	t.Helper()
+
+	db := &artifactsMockQuerier{}
+	cfg := &config.Config{
+		HeadlessBrowserURL: "http://127.0.0.1:9222",
+		ArtifactsDir:       t.TempDir(),
+		ArtifactsMaxSize:   1024 * 1024,
+	}
+	tool := NewHeadlessBrowserTool(cfg, 1, nil, nil, db).(*headlessBrowserTool)
+	tool.browser = &stubHeadlessBrowser{client: server.Client()}
+
+	return tool, db
+}
+
+func handleHeadlessBrowser(t *testing.T, ctx context.Context, tool Tool, args string) string {
+	t.Helper()
+
+	got, err := tool.Handle(ctx, HeadlessBrowserToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	return got
+}
+
+func TestHeadlessBrowserHandle(t *testing.T) {
+	server := newHeadlessBrowserServer(t)
+	tool, _ := newTestHeadlessBrowserTool(t, server)
+
+	tests := []struct {
+		name  string
+		args  string
+		wants []string
+	}{
+		{
+			name: "navigate",
+			args: fmt.Sprintf(`{"action":"navigate","url":%q,"wait_ms":0}`, server.URL+"/"),
+			wants: []string{
+				"**URL:** " + server.URL + "/  \n",
+				"**Title:** Example App  \n",
+				"**Action:** navigate  \n",
+				"## Text\n\nWelcome Next page Docs & API\n",
+				"## Links (2)\n\n- [Next page](" + server.URL + "/next)\n- [Docs & API](" + server.URL + "/docs)\n",
+			},
+		},
+		{
+			name: "click",
+			args: fmt.Sprintf(`{"action":"click","url":%q,"selector":"#next","wait_ms":0}`, server.URL+"/"),
+			wants: []string{
+				"**URL:** " + server.URL + "/next  \n",
+				"**Requested URL:** " + server.URL + "/  \n",
+				"**Action:** click `#next`",
+				"Second page",
+			},
+		},
+		{
+			name: "extract",
+			args: fmt.Sprintf(`{"action":"extract","url":%q,"selector":"#menu","wait_ms":0}`, server.URL+"/"),
+			wants: []string{
+				"**Action:** extract `#menu`",
+				"## Text\n\nNext page Docs & API\n",
+				"## Links (2)",
+			},
+		},
+		{
+			name:  "extract not found",
+			args:  fmt.Sprintf(`{"action":"extract","url":%q,"selector":"#missing","wait_ms":0}`, server.URL+"/"),
+			wants: []string{"No elements found by the selector `#missing`."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertContainsAll(t, handleHeadlessBrowser(t, t.Context(), tool, tt.args), tt.wants...)
+		})
+	}
+
+	if pages := tool.browser.(*stubHeadlessBrowser).pages; pages != len(tests) {
+		t.Errorf("pages = %d, want a new page per call %d", pages, len(tests))
+	}
+}
+
+func TestHeadlessBrowserScreenshot(t *testing.T) {
+	server := newHeadlessBrowserServer(t)
+	tool, db := newTestHeadlessBrowserTool(t, server)
+
+	got := handleHeadlessBrowser(t, t.Context(), tool,
+		fmt.Sprintf(`{"action":"screenshot","url":%q,"wait_ms":0}`, server.URL+"/next"))
+
+	if len(db.items) != 1 {
+		t.Fatalf("artifacts = %d, want 1", len(db.items))
+	}
+	artifact := db.items[0]
+	if !strings.HasPrefix(artifact.Name, "screenshot-127.0.0.1-") || !strings.HasSuffix(artifact.Name, ".png") {
+		t.Errorf("artifact name = %q", artifact.Name)
+	}
+	if artifact.ContentType != "image/png" {
+		t.Errorf("artifact content type = %q, want image/png", artifact.ContentType)
+	}
+
+	content, err := os.ReadFile(FlowArtifactPath(tool.cfg, 1, artifact.ID))
+	if err != nil {
+		t.Fatalf("failed to read screenshot: %v", err)
+	}
+	if want := "\x89PNG\r\n\x1a\n" + server.URL + "/next"; string(content) != want {
+		t.Errorf("screenshot = %q, want %q", content, want)
+	}
+
+	assertContainsAll(t, got,
+		"**Title:** Next  \n",
+		"## Screenshot\n\nartifact '"+artifact.Name+"' (image/png",
+		FlowArtifactURL(1, artifact.ID),
+		"by the 'artifacts' tool",
+	)
+	if strings.Contains(got, "## Text") {
+		t.Errorf("screenshot result contains the page text:\n%s", got)
+	}
+}
+
+func TestHeadlessBrowserScope(t *testing.T) {
+	server := newHeadlessBrowserServer(t)
+	tool, _ := newTestHeadlessBrowserTool(t, server)
+
+	guard, err := NewScopeGuard(nil, FlowScope{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("NewScopeGuard() unexpected error: %v", err)
+	}
+	ctx := withScopeGuard(t.Context(), guard)
+
+	got := handleHeadlessBrowser(t, ctx, tool, fmt.Sprintf(`{"action":"navigate","url":%q,"wait_ms":0}`, server.URL+"/"))
+	assertContainsAll(t, got, "**Title:** Example App")
+
+	got = handleHeadlessBrowser(t, ctx, tool, fmt.Sprintf(`{"action":"navigate","url":%q}`, server.URL+"/external"))
+	assertContainsAll(t, got,
+		"failed to navigate page: navigation is blocked: http://localhost:",
+		"domain 'localhost' is out of the flow scope",
+	)
+}
+
+func TestHeadlessBrowserHandle_Errors(t *testing.T) {
+	server := newHeadlessBrowserServer(t)
+	tool, _ := newTestHeadlessBrowserTool(t, server)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty url", `{"action":"navigate","url":""}`, "failed to open page: url is empty"},
+		{"unsupported scheme", `{"action":"navigate","url":"file:///etc/passwd"}`, "unsupported url scheme 'file'"},
+		{"click without selector", `{"action":"click","url":"example.com"}`, "selector is required for the click action"},
+		{"unknown action", `{"action":"scroll","url":"example.com"}`, "unknown action 'scroll'"},
+		{"click not found", fmt.Sprintf(`{"action":"click","url":%q,"selector":"#nope","wait_ms":0}`, server.URL+"/"),
+			"failed to click page: failed to click '#nope': element isn't found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := handleHeadlessBrowser(t, t.Context(), tool, tt.args)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.Handle(t.Context(), HeadlessBrowserToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+
+	cfg := &config.Config{HeadlessBrowserURL: "http://chrome:9222"}
+	if NewHeadlessBrowserTool(&config.Config{}, 1, nil, nil, &artifactsMockQuerier{}).IsAvailable() {
+		t.Error("IsAvailable() = true without the endpoint")
+	}
+	if NewHeadlessBrowserTool(cfg, 1, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true without the database")
+	}
+	if !NewHeadlessBrowserTool(cfg, 1, nil, nil, &artifactsMockQuerier{}).IsAvailable() {
+		t.Error("IsAvailable() = false with the endpoint and the database")
+	}
+}
+
+func TestHeadlessBrowserBlockedURL(t *testing.T) {
+	guard, err := NewScopeGuard(nil, FlowScope{"10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("NewScopeGuard() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"http://10.0.0.5/login", ""},
+		{"about:blank", ""},
+		{"data:text/html,<p>hi</p>", ""},
+		{"blob:http://10.0.0.5/1b2c", ""},
+		{"https://10.0.1.5/", "IP address '10.0.1.5' is out of the flow scope"},
+		{"file:///etc/passwd", "url scheme 'file' isn't allowed"},
+		{"chrome://settings", "url scheme 'chrome' isn't allowed"},
+	}
+
+	for _, tt := range tests {
+		got := headlessBrowserBlockedURL(guard, tt.target)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("headlessBrowserBlockedURL(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+
+	if got := headlessBrowserBlockedURL(nil, "http://169.254.169.254/"); got != "" {
+		t.Errorf("headlessBrowserBlockedURL() without scope = %q, want allowed", got)
+	}
+}
+
+func TestFormatHeadlessBrowserReport_SizeLimit(t *testing.T) {
+	report := headlessBrowserReport{
+		URL:    "https://example.com/",
+		Action: HeadlessNavigate,
+		State: headlessPageState{
+			URL:  "https://example.com/",
+			Text: strings.Repeat("лорем ипсум ", 20000),
+		},
+	}
+	for i := range headlessBrowserMaxLinks + 50 {
+		report.State.Links = append(report.State.Links, headlessPageLink{
+			Text: fmt.Sprintf("link %d", i),
+			Href: fmt.Sprintf("https://example.com/%d", i),
+		})
+	}
+
+	got := formatHeadlessBrowserReport(1, report, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("result size %d exceeds limit %d", len(got), DefaultMaxResultSize)
+	}
+	assertContainsAll(t, got, "... [text truncated, exceeded ", "## Links (250)", "...and 50 more links")
+	if !strings.Contains(got, fmt.Sprintf("https://example.com/%d)", headlessBrowserMaxLinks-1)) ||
+		strings.Contains(got, fmt.Sprintf("https://example.com/%d)", headlessBrowserMaxLinks)) {
+		t.Errorf("links aren't limited to %d", headlessBrowserMaxLinks)
+	}
+}
@@ -0,0 +1,684 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// httpFuzzTimeout limits the whole run, the requests which aren't sent in time are reported as not sent
+	httpFuzzTimeout            = 3 * time.Minute
+	httpFuzzRequestTimeout     = 10 * time.Second
+	httpFuzzKeyword            = "FUZZ"
+	defaultHTTPFuzzMethod      = http.MethodGet
+	defaultHTTPFuzzConcurrency = 10
+	defaultHTTPFuzzRate        = 20
+	// httpFuzzMaxBodySize is how much of the body is read to measure the response size
+	httpFuzzMaxBodySize = 1024 * 1024
+	httpFuzzMaxWordLen  = 256
+	// httpFuzzRequestsLimit is used if the server limit isn't set
+	httpFuzzRequestsLimit = 100000
+)
+
+// httpFuzzCommonWords are requested if the wordlist isn't set
+var httpFuzzCommonWords = []string{
+	"admin", "administrator", "login", "signin", "logout", "register", "dashboard", "panel", "console", "manager",
+	"api", "api/v1", "api/v2", "graphql", "swagger", "swagger-ui", "swagger.json", "openapi.json", "api-docs",
+	"v1", "v2", "rest", "services", "ws", "rpc", "auth", "oauth", "sso", "account", "accounts", "user", "users",
+	"profile", "settings", "config", "configuration", "setup", "install", "upgrade", "debug", "test", "tests",
+	"dev", "staging", "old", "new", "backup", "backups", "bak", "tmp", "temp", "cache", "logs", "log", "files",
+	"uploads", "upload", "download", "downloads", "static", "assets", "media", "images", "img", "js", "css",
+	"public", "private", "internal", "data", "db", "database", "sql", "dump", "export", "import", "docs",
+	"documentation", "help", "status", "health", "healthz", "metrics", "info", "version", "server-status",
+	"server-info", "phpinfo.php", "info.php", "index.php", "wp-admin", "wp-login.php", "wp-content",
+	"wp-json", "xmlrpc.php", "phpmyadmin", "adminer.php", "cgi-bin", "webdav", "jenkins", "actuator",
+	"actuator/health", "actuator/env", "console/login", "manager/html", "portal", "cpanel", "webmail",
+	"robots.txt", "sitemap.xml", "crossdomain.xml", "security.txt", ".well-known/security.txt", "humans.txt",
+	".git/HEAD", ".git/config", ".svn/entries", ".hg", ".env", ".env.local", ".env.backup", ".htaccess",
+	".htpasswd", ".DS_Store", ".idea", ".vscode", "web.config", "config.php", "config.json", "config.yml",
+	"settings.py", "package.json", "composer.json", "Dockerfile", "docker-compose.yml", "backup.zip",
+	"backup.tar.gz", "backup.sql", "db.sql", "database.sql", "dump.sql", "site.zip", "www.zip", "id_rsa",
+	"error_log", "access.log", "trace.axd", "elmah.axd",
+}
+
+// httpFuzzResult is the response to the single candidate URL
+type httpFuzzResult struct {
+	URL         string
+	Path        string
+	Status      int
+	Size        int
+	ContentType string
+	Location    string
+	Err         error
+	Sent        bool
+}
+
+// httpFuzzReport is the result of the content discovery of the single base URL
+type httpFuzzReport struct {
+	URL          string
+	Method       string
+	Candidates   int
+	OverBudget   int
+	MaxRequests  int
+	Concurrency  int
+	Rate         int
+	Baseline     httpFuzzResult
+	Results      []httpFuzzResult
+	Duration     time.Duration
+	ThroughProxy bool
+}
+
+// httpFuzzTarget builds the candidate URLs from the base URL with or without the FUZZ keyword
+type httpFuzzTarget struct {
+	base     *url.URL
+	template string
+}
+
+// httpFuzzLimiter paces the requests to the host, it's shared by the parallel runs against the same host
+type httpFuzzLimiter struct {
+	mx    sync.Mutex
+	next  time.Time
+	users int
+}
+
+var (
+	httpFuzzLimitersMx sync.Mutex
+	httpFuzzLimiters   = make(map[string]*httpFuzzLimiter)
+)
+
+func acquireHTTPFuzzLimiter(host string) *httpFuzzLimiter {
+	httpFuzzLimitersMx.Lock()
+	defer httpFuzzLimitersMx.Unlock()
+
+	limiter, ok := httpFuzzLimiters[host]
+	if !ok {
+		limiter = &httpFuzzLimiter{}
+		httpFuzzLimiters[host] = limiter
+	}
+	limiter.users++
+
+	return limiter
+}
+
+func releaseHTTPFuzzLimiter(host string, limiter *httpFuzzLimiter) {
+	httpFuzzLimitersMx.Lock()
+	defer httpFuzzLimitersMx.Unlock()
+
+	limiter.users--
+	if limiter.users <= 0 {
+		delete(httpFuzzLimiters, host)
+	}
+}
+
+// wait reserves the next slot of the host and sleeps until it comes or the context is done
+func (l *httpFuzzLimiter) wait(ctx context.Context, interval time.Duration) error {
+	l.mx.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(interval)
+	l.mx.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// httpFuzz represents the HTTP content discovery tool
+type httpFuzz struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewHTTPFuzzTool creates a new HTTP content discovery tool instance
+func NewHTTPFuzzTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &httpFuzz{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes an HTTP content discovery request from an AI agent
+func (h *httpFuzz) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !h.IsAvailable() {
+		return "", fmt.Errorf("http_fuzz is not available")
+	}
+
+	var action HTTPFuzzAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(h.flowID, h.taskID, h.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal http fuzz action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	target, err := newHTTPFuzzTarget(action.URL)
+	if err != nil {
+		return fmt.Sprintf("failed to discover content: %v", err), nil
+	}
+
+	sizeLimit := resultSizeLimitsFromContext(ctx).MaxResultSize
+	if action.Continuation != "" {
+		paged, skip, err := loadPagedResult(ctx, h.cache, name, h.flowID, args, action.Continuation)
+		if err != nil {
+			logger.WithError(err).Warn("failed to load http fuzz page")
+			return fmt.Sprintf("failed to discover content: %v: repeat the run without continuation", err), nil
+		}
+
+		page, err := renderPagedResult(ctx, h.cache, name, h.flowID, args, paged, skip, sizeLimit)
+		if err != nil {
+			return "", fmt.Errorf("failed to render http fuzz page: %w", err)
+		}
+
+		return page.String(), nil
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(action.Method))
+	if method == "" {
+		method = defaultHTTPFuzzMethod
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return fmt.Sprintf("failed to discover content: unsupported method '%s', use GET or HEAD", method), nil
+	}
+
+	exclude, err := parseHTTPFuzzStatuses(action.ExcludeStatus)
+	if err != nil {
+		return fmt.Sprintf("failed to discover content: %v", err), nil
+	}
+
+	words := action.Words
+	if len(words) == 0 {
+		words = httpFuzzCommonWords
+	}
+	candidates := target.candidates(words, action.Extensions)
+	if len(candidates) == 0 {
+		return "failed to discover content: wordlist doesn't contain valid words", nil
+	}
+
+	maxRequests := httpFuzzRequestsLimit
+	if h.cfg.HTTPFuzzMaxRequests > 0 {
+		maxRequests = h.cfg.HTTPFuzzMaxRequests
+	}
+	if action.MaxRequests != nil && action.MaxRequests.Int() > 0 {
+		maxRequests = min(maxRequests, action.MaxRequests.Int())
+	}
+
+	concurrency := defaultHTTPFuzzConcurrency
+	if action.Concurrency != nil && action.Concurrency.Int() > 0 {
+		concurrency = action.Concurrency.Int()
+	}
+	if h.cfg.HTTPFuzzMaxConcurrency > 0 {
+		concurrency = min(concurrency, h.cfg.HTTPFuzzMaxConcurrency)
+	}
+
+	rate := defaultHTTPFuzzRate
+	if action.Rate != nil && action.Rate.Int() > 0 {
+		rate = action.Rate.Int()
+	}
+	if h.cfg.HTTPFuzzMaxRate > 0 {
+		rate = min(rate, h.cfg.HTTPFuzzMaxRate)
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"url":          target.String()[:min(len(target.String()), 1000)],
+		"method":       method,
+		"candidates":   len(candidates),
+		"max_requests": maxRequests,
+		"concurrency":  concurrency,
+		"rate":         rate,
+	})
+
+	report, err := h.fuzz(ctx, target, method, candidates, maxRequests, concurrency, rate)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("http fuzz error swallowed"),
+			langfuse.WithEventInput(target.String()),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":    HTTPFuzzToolName,
+				"engine":       "http_fuzz",
+				"url":          target.String(),
+				"method":       method,
+				"candidates":   len(candidates),
+				"max_requests": maxRequests,
+				"error":        err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to discover content")
+		return fmt.Sprintf("failed to discover content: %v", err), nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"sent":     report.sent(),
+		"duration": report.Duration.String(),
+	}).Debug("http content discovery finished")
+
+	page, err := renderPagedResult(ctx, h.cache, name, h.flowID, args, newHTTPFuzzPages(report, exclude), 0, sizeLimit)
+	if err != nil {
+		return "", fmt.Errorf("failed to render http fuzz report: %w", err)
+	}
+	result := page.String()
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && h.slp != nil {
+		_, _ = h.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeHttpFuzz,
+			method+" "+target.String(),
+			result,
+			h.taskID,
+			h.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// newHTTPFuzzTarget validates the base URL, https is used if the scheme is omitted,
+// the FUZZ keyword is allowed in the path and the query only to keep the host in the scope
+func newHTTPFuzzTarget(target string) (*httpFuzzTarget, error) {
+	normalized, err := normalizeHTTPHeadersURL(target)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url '%s': %w", normalized, err)
+	}
+	if strings.Contains(base.Host, httpFuzzKeyword) {
+		return nil, fmt.Errorf("%s keyword in the host isn't supported, only path and query can be fuzzed", httpFuzzKeyword)
+	}
+	base.Fragment = ""
+
+	fuzzTarget := &httpFuzzTarget{base: base}
+	if strings.Contains(base.String(), httpFuzzKeyword) {
+		fuzzTarget.template = base.String()
+	}
+
+	return fuzzTarget, nil
+}
+
+func (t *httpFuzzTarget) String() string {
+	return t.base.String()
+}
+
+// build returns the URL of the word which is appended to the base path or replaces the FUZZ keyword
+func (t *httpFuzzTarget) build(word string) string {
+	escaped := (&url.URL{Path: word}).EscapedPath()
+	if t.template != "" {
+		return strings.ReplaceAll(t.template, httpFuzzKeyword, escaped)
+	}
+
+	u := *t.base
+	u.Path = strings.TrimSuffix(t.base.Path, "/") + "/" + word
+	u.RawPath = strings.TrimSuffix(t.base.EscapedPath(), "/") + "/" + escaped
+
+	return u.String()
+}
+
+// candidates combines the words with the extensions to the unique URLs in the order of the wordlist,
+// the comments and the empty lines of the wordlist are skipped
+func (t *httpFuzzTarget) candidates(words, extensions []string) []string {
+	suffixes := []string{""}
+	for _, ext := range extensions {
+		ext = strings.TrimSpace(ext)
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !slices.Contains(suffixes, ext) {
+			suffixes = append(suffixes, ext)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(words)*len(suffixes))
+	candidates := make([]string, 0, len(words)*len(suffixes))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if t.template == "" {
+			word = strings.TrimLeft(word, "/")
+		}
+		if word == "" || strings.HasPrefix(word, "#") || len(word) > httpFuzzMaxWordLen {
+			continue
+		}
+
+		for _, suffix := range suffixes {
+			candidate := t.build(word + suffix)
+			if _, ok := seen[candidate]; !ok {
+				seen[candidate] = struct{}{}
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// parseHTTPFuzzStatuses parses the comma separated status codes to hide
+func parseHTTPFuzzStatuses(spec string) (map[int]struct{}, error) {
+	statuses := map[int]struct{}{http.StatusNotFound: {}}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		status, err := strconv.Atoi(part)
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("invalid status code '%s': status must be a number from 100 to 599", part)
+		}
+		statuses[status] = struct{}{}
+	}
+
+	return statuses, nil
+}
+
+// fuzz requests the not found page first to recognize it in the responses and then requests the candidates
+// with the limited number of parallel requests and the rate limit of the host, redirects aren't followed
+func (h *httpFuzz) fuzz(
+	ctx context.Context,
+	target *httpFuzzTarget,
+	method string,
+	candidates []string,
+	maxRequests, concurrency, rate int,
+) (httpFuzzReport, error) {
+	startTime := time.Now()
+	report := httpFuzzReport{
+		URL:          target.String(),
+		Method:       method,
+		Candidates:   len(candidates),
+		MaxRequests:  maxRequests,
+		Concurrency:  concurrency,
+		Rate:         rate,
+		ThroughProxy: h.cfg.ProxyURL != "",
+	}
+	if len(candidates) > maxRequests {
+		report.OverBudget = len(candidates) - maxRequests
+		candidates = candidates[:maxRequests]
+	}
+	report.Concurrency = min(concurrency, len(candidates))
+
+	client, err := system.GetHTTPClient(h.cfg)
+	if err != nil {
+		return report, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = httpFuzzRequestTimeout
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		transport.MaxIdleConnsPerHost = report.Concurrency
+		defer transport.CloseIdleConnections()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, httpFuzzTimeout))
+	defer cancel()
+
+	host := strings.ToLower(target.base.Host)
+	limiter := acquireHTTPFuzzLimiter(host)
+	defer releaseHTTPFuzzLimiter(host, limiter)
+	interval := time.Second / time.Duration(rate)
+
+	token := make([]byte, 8)
+	_, _ = rand.Read(token)
+	if err := limiter.wait(ctx, interval); err != nil {
+		return report, err
+	}
+	report.Baseline = h.do(ctx, client, method, target.build("pentagi-"+hex.EncodeToString(token)))
+	if report.Baseline.Err != nil {
+		return report, report.Baseline.Err
+	}
+
+	report.Results = make([]httpFuzzResult, len(candidates))
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for range report.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if limiter.wait(ctx, interval) != nil {
+					report.Results[idx] = httpFuzzResult{URL: candidates[idx]}
+					continue
+				}
+				report.Results[idx] = h.do(ctx, client, method, candidates[idx])
+			}
+		}()
+	}
+	for idx := range candidates {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	report.Duration = time.Since(startTime)
+
+	return report, nil
+}
+
+// do sends the single request and measures the response body, the request which isn't sent
+// because of the deadline of the run is marked as not sent
+func (h *httpFuzz) do(ctx context.Context, client *http.Client, method, target string) httpFuzzResult {
+	result := httpFuzzResult{URL: target, Path: target}
+	if parsed, err := url.Parse(target); err == nil {
+		result.Path = parsed.RequestURI()
+	}
+	if ctx.Err() != nil {
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create request: %w", err)
+		return result
+	}
+	req.Header.Set("User-Agent", httpHeadersUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// the request is interrupted by the deadline of the run, it isn't counted as failed
+		if ctx.Err() != nil {
+			return result
+		}
+		result.Sent = true
+		result.Err = fmt.Errorf("request to %s failed: %w", target, err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	size, _ := io.Copy(io.Discard, io.LimitReader(resp.Body, httpFuzzMaxBodySize))
+
+	result.Sent = true
+	result.Status = resp.StatusCode
+	result.Size = int(size)
+	result.ContentType = resp.Header.Get("Content-Type")
+	result.Location = resp.Header.Get("Location")
+
+	return result
+}
+
+// sent returns the number of the requests sent to the host without the not found page request
+func (r httpFuzzReport) sent() int {
+	var sent int
+	for _, result := range r.Results {
+		if result.Sent {
+			sent++
+		}
+	}
+	return sent
+}
+
+// isNotFound returns true if the response looks like the not found page of the site: the same status
+// and the same size of the body or the size which differs only by the reflected path
+func (r httpFuzzReport) isNotFound(result httpFuzzResult) bool {
+	if result.Status != r.Baseline.Status {
+		return false
+	}
+
+	reflected := r.Baseline.Size + len(result.URL) - len(r.Baseline.URL)
+	return result.Size == r.Baseline.Size || result.Size == reflected
+}
+
+// formatHTTPFuzzReport renders the discovered endpoints grouped by the status code as markdown
+// capped by sizeLimit
+func formatHTTPFuzzReport(report httpFuzzReport, exclude map[int]struct{}, sizeLimit int) string {
+	content, _ := newHTTPFuzzPages(report, exclude).page(0, sizeLimit)
+	return content
+}
+
+// newHTTPFuzzPages renders the summary of the run and the rows of the discovered endpoints grouped by status
+func newHTTPFuzzPages(report httpFuzzReport, exclude map[int]struct{}) pagedResult {
+	var (
+		found   = make(map[int][]httpFuzzResult)
+		failed  []httpFuzzResult
+		notSent int
+		hidden  int
+	)
+	for _, result := range report.Results {
+		switch {
+		case !result.Sent:
+			notSent++
+		case result.Err != nil:
+			failed = append(failed, result)
+		case report.isNotFound(result):
+			hidden++
+		default:
+			if _, ok := exclude[result.Status]; ok {
+				hidden++
+				continue
+			}
+			found[result.Status] = append(found[result.Status], result)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# HTTP Content Discovery\n\n")
+	sb.WriteString(fmt.Sprintf("**Base URL:** %s  \n", report.URL))
+	sb.WriteString(fmt.Sprintf("**Requests:** %d sent: %d hidden as not found or excluded, %d failed  \n",
+		report.sent(), hidden, len(failed)))
+	sb.WriteString(fmt.Sprintf("**Settings:** %s, %d parallel requests, %d requests per second  \n",
+		report.Method, report.Concurrency, report.Rate))
+	sb.WriteString(fmt.Sprintf("**Not found page:** %d, %d bytes  \n", report.Baseline.Status, report.Baseline.Size))
+	sb.WriteString(fmt.Sprintf("**Duration:** %s  \n", report.Duration.Round(time.Millisecond)))
+
+	if report.OverBudget > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Note:** the budget of %d requests is reached, %d of %d candidates weren't requested\n",
+			report.MaxRequests, report.OverBudget, report.Candidates))
+	}
+	if notSent > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Note:** the deadline is reached, %d candidates weren't requested\n", notSent))
+	}
+	if report.ThroughProxy {
+		sb.WriteString("\n**Note:** requests go through the configured proxy\n")
+	}
+	if len(failed) > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Note:** %d requests failed, e.g. %v\n", len(failed), failed[0].Err))
+	}
+
+	if len(found) == 0 {
+		sb.WriteString("\nNo endpoints found.\n")
+		return pagedResult{Header: sb.String(), Kind: "endpoints"}
+	}
+
+	statuses := make([]int, 0, len(found))
+	var total int
+	for status, results := range found {
+		statuses = append(statuses, status)
+		total += len(results)
+	}
+	slices.Sort(statuses)
+
+	sb.WriteString("\n## Discovered Endpoints\n")
+
+	paged := pagedResult{Header: sb.String(), Kind: "endpoints", Rows: make([]pagedRow, 0, total)}
+	for _, status := range statuses {
+		results := found[status]
+		slices.SortFunc(results, func(a, b httpFuzzResult) int {
+			return cmp.Compare(a.Path, b.Path)
+		})
+
+		section := fmt.Sprintf("\n### %d %s (%d)\n\n| Path | Size | Content-Type | Location |\n| --- | --- | --- | --- |\n",
+			status, http.StatusText(status), len(results))
+		for _, result := range results {
+			paged.Rows = append(paged.Rows, pagedRow{
+				Section: section,
+				Line: fmt.Sprintf("| %s | %d | %s | %s |\n",
+					escapeMarkdownCell(result.Path),
+					result.Size,
+					escapeMarkdownCell(cmp.Or(result.ContentType, "-")),
+					escapeMarkdownCell(cmp.Or(result.Location, "-")),
+				),
+			})
+		}
+	}
+
+	return paged
+}
+
+// IsAvailable returns true if the HTTP content discovery is enabled
+func (h *httpFuzz) IsAvailable() bool {
+	return h.cfg != nil && h.cfg.HTTPFuzzEnabled
+}
@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func testHTTPFuzzConfig() *config.Config {
+	return &config.Config{HTTPFuzzEnabled: true, HTTPFuzzMaxRequests: 1000, HTTPFuzzMaxConcurrency: 20, HTTPFuzzMaxRate: 1000}
+}
+
+func handleHTTPFuzz(t *testing.T, tool Tool, args string) string {
+	t.Helper()
+
+	got, err := tool.Handle(t.Context(), HTTPFuzzToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	return got
+}
+
+// newHTTPFuzzServer serves the known paths and counts all requests, unknown paths get 404
+func newHTTPFuzzServer(t *testing.T, requests *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/admin":
+			http.Redirect(w, r, "/admin/", http.StatusMovedPermanently)
+		case "/backup.zip":
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write([]byte("PK\x03\x04 archive"))
+		case "/secret":
+			http.Error(w, "forbidden", http.StatusForbidden)
+		case "/api/users.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"id":1}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHTTPFuzzHandle(t *testing.T) {
+	var requests atomic.Int64
+	server := newHTTPFuzzServer(t, &requests)
+
+	slp := &searchLogProviderMock{}
+	tool := NewHTTPFuzzTool(testHTTPFuzzConfig(), 1, nil, nil, slp, nil)
+
+	args := fmt.Sprintf(`{"url":%q,"words":["admin","/backup","secret","missing","# comment",""],"extensions":["zip"],"message":"discover"}`,
+		server.URL+"/")
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypePentester)
+	got, err := tool.Handle(ctx, HTTPFuzzToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	assertContainsAll(t, got,
+		"**Base URL:** "+server.URL+"/",
+		"**Requests:** 8 sent: 5 hidden as not found or excluded, 0 failed",
+		"**Settings:** GET, 8 parallel requests, 20 requests per second",
+		"**Not found page:** 404, 19 bytes",
+		"### 200 OK (1)",
+		"| /backup.zip | 12 | application/zip | - |",
+		"### 301 Moved Permanently (1)",
+		"| /admin | ",
+		" | /admin/ |",
+		"### 403 Forbidden (1)",
+		"| /secret | 10 | text/plain; charset=utf-8 | - |",
+	)
+	if strings.Contains(got, "/missing") || strings.Contains(got, "**Note:**") {
+		t.Errorf("unexpected not found endpoint or note:\n%s", got)
+	}
+	if strings.Index(got, "### 200") > strings.Index(got, "### 301") || strings.Index(got, "### 301") > strings.Index(got, "### 403") {
+		t.Errorf("endpoints aren't grouped in the order of the status codes:\n%s", got)
+	}
+	if n := requests.Load(); n != 9 {
+		t.Errorf("server got %d requests, want 8 candidates and the not found page", n)
+	}
+	if slp.calls != 1 || slp.engine != database.SearchengineTypeHttpFuzz || slp.query != "GET "+server.URL+"/" {
+		t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+	}
+}
+
+func TestHTTPFuzzHandle_KeywordAndExclude(t *testing.T) {
+	var requests atomic.Int64
+	server := newHTTPFuzzServer(t, &requests)
+	tool := NewHTTPFuzzTool(testHTTPFuzzConfig(), 1, nil, nil, nil, nil)
+
+	got := handleHTTPFuzz(t, tool, fmt.Sprintf(`{"url":%q,"words":["users","groups"]}`, server.URL+"/api/FUZZ.json"))
+	assertContainsAll(t, got, "### 200 OK (1)", "| /api/users.json | 10 | application/json | - |")
+
+	got = handleHTTPFuzz(t, tool, fmt.Sprintf(`{"url":%q,"words":["secret","backup.zip"],"exclude_status":"403"}`, server.URL))
+	assertContainsAll(t, got, "**Requests:** 2 sent: 1 hidden as not found or excluded", "| /backup.zip |")
+	if strings.Contains(got, "/secret") {
+		t.Errorf("excluded status is reported:\n%s", got)
+	}
+}
+
+func TestHTTPFuzzHandle_SoftNotFound(t *testing.T) {
+	// the site answers 200 to any path and reflects the path in the page
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			_, _ = w.Write([]byte("<form>login</form>"))
+			return
+		}
+		_, _ = fmt.Fprintf(w, "<h1>Page %s is not found</h1>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	tool := NewHTTPFuzzTool(testHTTPFuzzConfig(), 1, nil, nil, nil, nil)
+	got := handleHTTPFuzz(t, tool, fmt.Sprintf(`{"url":%q,"words":["a","login","very-long-missing-path"]}`, server.URL))
+
+	assertContainsAll(t, got,
+		"**Not found page:** 200, ",
+		"**Requests:** 3 sent: 2 hidden as not found or excluded",
+		"### 200 OK (1)",
+		"| /login | 18 |",
+	)
+}
+
+func TestHTTPFuzzHandle_Budget(t *testing.T) {
+	var requests atomic.Int64
+	server := newHTTPFuzzServer(t, &requests)
+
+	cfg := testHTTPFuzzConfig()
+	cfg.HTTPFuzzMaxRequests = 4
+	tool := NewHTTPFuzzTool(cfg, 1, nil, nil, nil, nil)
+
+	words := `["a","b","c","d","e","f","g","h","i","j"]`
+	got := handleHTTPFuzz(t, tool, fmt.Sprintf(`{"url":%q,"words":%s,"max_requests":3}`, server.URL, words))
+	assertContainsAll(t, got,
+		"**Requests:** 3 sent",
+		"**Note:** the budget of 3 requests is reached, 7 of 10 candidates weren't requested",
+	)
+	if n := requests.Load(); n != 4 {
+		t.Errorf("server got %d requests, want 3 candidates and the not found page", n)
+	}
+
+	// the server limit caps the budget requested by the agent
+	requests.Store(0)
+	got = handleHTTPFuzz(t, tool, fmt.Sprintf(`{"url":%q,"words":%s,"max_requests":100}`, server.URL, words))
+	assertContainsAll(t, got, "**Note:** the budget of 4 requests is reached, 6 of 10 candidates weren't requested")
+	if n := requests.Load(); n != 5 {
+		t.Errorf("server got %d requests, want 4 candidates and the not found page", n)
+	}
+}
+
+func TestHTTPFuzzHandle_RateLimit(t *testing.T) {
+	var (
+		mx    sync.Mutex
+		times []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		times = append(times, time.Now())
+		mx.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	// the server limit caps the rate requested by the agent
+	cfg := testHTTPFuzzConfig()
+	cfg.HTTPFuzzMaxRate = 10
+	tool := NewHTTPFuzzTool(cfg, 1, nil, nil, nil, nil)
+
+	got := handleHTTPFuzz(t, tool, fmt.Sprintf(`{"url":%q,"words":["a","b","c","d","e"],"concurrency":5,"rate":1000}`, server.URL))
+	assertContainsAll(t, got, "**Settings:** GET, 5 parallel requests, 10 requests per second", "No endpoints found.")
+
+	mx.Lock()
+	defer mx.Unlock()
+	if len(times) != 6 {
+		t.Fatalf("server got %d requests, want 6", len(times))
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+	if elapsed := times[len(times)-1].Sub(times[0]); elapsed < 450*time.Millisecond {
+		t.Errorf("6 requests with 10 requests per second took %v, want at least 500ms", elapsed)
+	}
+}
+
+func TestHTTPFuzzHandle_ContextDeadline(t *testing.T) {
+	var requests atomic.Int64
+	server := newHTTPFuzzServer(t, &requests)
+
+	cfg := testHTTPFuzzConfig()
+	cfg.HTTPFuzzMaxRate = 5
+	tool := NewHTTPFuzzTool(cfg, 1, nil, nil, nil, nil)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 500*time.Millisecond)
+	defer cancel()
+
+	words := make([]string, 20)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%d", i)
+	}
+	args := fmt.Sprintf(`{"url":%q,"words":["%s"]}`, server.URL, strings.Join(words, `","`))
+
+	start := time.Now()
+	got, err := tool.Handle(ctx, HTTPFuzzToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Handle() took %v, the context deadline isn't respected", elapsed)
+	}
+	assertContainsAll(t, got, "**Note:** the deadline is reached, ", "candidates weren't requested")
+	if n := requests.Load(); n >= 21 {
+		t.Errorf("server got %d requests, want the run to stop at the deadline", n)
+	}
+}
+
+func TestHTTPFuzzHandle_Errors(t *testing.T) {
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedURL := "http://" + closed.Addr().String()
+	closed.Close()
+
+	tool := NewHTTPFuzzTool(testHTTPFuzzConfig(), 1, nil, nil, nil, nil)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty url", `{"url":""}`, "failed to discover content: url is empty"},
+		{"unsupported scheme", `{"url":"ftp://example.com"}`, "unsupported url scheme 'ftp'"},
+		{"keyword in host", `{"url":"http://FUZZ.example.com/"}`, "FUZZ keyword in the host isn't supported"},
+		{"unsupported method", `{"url":"http://example.com","method":"POST"}`, "unsupported method 'POST'"},
+		{"invalid status", `{"url":"http://example.com","exclude_status":"403,abc"}`, "invalid status code 'abc'"},
+		{"empty wordlist", `{"url":"http://example.com","words":[" ","# comment"]}`, "wordlist doesn't contain valid words"},
+		{"unreachable", fmt.Sprintf(`{"url":%q,"words":["admin"]}`, closedURL), "failed to discover content: request to " + closedURL + "/pentagi-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := handleHTTPFuzz(t, tool, tt.args)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.Handle(t.Context(), HTTPFuzzToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+	if NewHTTPFuzzTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+}
+
+func TestHTTPFuzzTargetCandidates(t *testing.T) {
+	tests := []struct {
+		url        string
+		words      []string
+		extensions []string
+		want       []string
+	}{
+		{
+			"example.com/app?debug=1",
+			[]string{"admin", "/admin", "a b", "x/y"},
+			nil,
+			[]string{
+				"https://example.com/app/admin?debug=1",
+				"https://example.com/app/a%20b?debug=1",
+				"https://example.com/app/x/y?debug=1",
+			},
+		},
+		{
+			"http://example.com/",
+			[]string{"index", "# comment", ""},
+			[]string{"php", ".bak", " ", ".php"},
+			[]string{"http://example.com/index", "http://example.com/index.php", "http://example.com/index.bak"},
+		},
+		{
+			"http://example.com/files/FUZZ.txt?name=FUZZ",
+			[]string{"notes"},
+			nil,
+			[]string{"http://example.com/files/notes.txt?name=notes"},
+		},
+	}
+
+	for _, tt := range tests {
+		target, err := newHTTPFuzzTarget(tt.url)
+		if err != nil {
+			t.Fatalf("newHTTPFuzzTarget(%q) unexpected error: %v", tt.url, err)
+		}
+		if got := target.candidates(tt.words, tt.extensions); !slices.Equal(got, tt.want) {
+			t.Errorf("candidates(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPFuzzLimiterShared(t *testing.T) {
+	first := acquireHTTPFuzzLimiter("shared.example")
+	second := acquireHTTPFuzzLimiter("shared.example")
+	if first != second {
+		t.Fatal("parallel runs against the same host must share the limiter")
+	}
+
+	start := time.Now()
+	for range 3 {
+		if err := first.wait(t.Context(), 50*time.Millisecond); err != nil {
+			t.Fatalf("wait() unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 slots with 50ms interval took %v, want at least 100ms", elapsed)
+	}
+
+	releaseHTTPFuzzLimiter("shared.example", first)
+	releaseHTTPFuzzLimiter("shared.example", second)
+	if third := acquireHTTPFuzzLimiter("shared.example"); third == first {
+		t.Error("released limiter must be removed")
+	} else {
+		releaseHTTPFuzzLimiter("shared.example", third)
+	}
+}
+
+func TestFormatHTTPFuzzReport_SizeLimit(t *testing.T) {
+	report := httpFuzzReport{
+		URL:      "https://example.com/",
+		Method:   http.MethodGet,
+		Baseline: httpFuzzResult{Status: http.StatusNotFound, Size: 10},
+	}
+	for i := range 5000 {
+		report.Results = append(report.Results, httpFuzzResult{
+			Path:        fmt.Sprintf("/%s-%d", strings.Repeat("x", 40), i),
+			Status:      http.StatusOK,
+			Size:        100,
+			ContentType: "text/html",
+			Sent:        true,
+		})
+	}
+
+	got := formatHTTPFuzzReport(report, map[int]struct{}{http.StatusNotFound: {}}, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("report size = %d, want <= %d", len(got), DefaultMaxResultSize)
+	}
+	assertContainsAll(t, got, "### 200 OK (5000)", "of 5000 endpoints (output truncated, exceeded 80 KB limit)")
+}
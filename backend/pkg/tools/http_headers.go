@@ -0,0 +1,732 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	httpHeadersRequestTimeout = 30 * time.Second
+	httpHeadersUserAgent      = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/145.0.0.0 Safari/537.36"
+	defaultHTTPHeadersMethod  = http.MethodGet
+	defaultHTTPMaxRedirects   = 5
+	maxHTTPRedirects          = 10
+	// httpHeadersMaxValueLen is the max length of a single header value in the output
+	httpHeadersMaxValueLen = 1000
+	// httpHeadersMinHSTSMaxAge is the recommended minimum of HSTS max-age, 180 days
+	httpHeadersMinHSTSMaxAge = 180 * 24 * 60 * 60
+	// httpHeadersBodyDrainLimit is how much of the body is read to reuse the connection
+	httpHeadersBodyDrainLimit = 64 * 1024
+
+	severityHigh   = "high"
+	severityMedium = "medium"
+	severityLow    = "low"
+	severityInfo   = "info"
+
+	headerStatusPresent = "present"
+	headerStatusMissing = "missing"
+	headerStatusWeak    = "weak"
+)
+
+var httpHeadersMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodDelete,
+	http.MethodPatch,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// sensitiveCookieRegexp matches names of cookies which usually hold sessions or credentials
+var sensitiveCookieRegexp = regexp.MustCompile(`(?i)sess|sid|token|auth|jwt|csrf|xsrf|login|remember|secret|pass|key|credential|saml|oauth`)
+
+// sensitiveHeaders are response headers whose values are redacted in the output
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+	"X-Xsrf-Token",
+}
+
+// disclosureHeaders reveal the server software and often its version
+var disclosureHeaders = []string{
+	"Server",
+	"X-Powered-By",
+	"X-AspNet-Version",
+	"X-AspNetMvc-Version",
+	"X-Generator",
+	"X-Runtime",
+	"Via",
+}
+
+var headerVersionRegexp = regexp.MustCompile(`\d+\.\d+`)
+
+// httpHeaderFinding is the result of the check of a single security header
+type httpHeaderFinding struct {
+	Header   string
+	Status   string
+	Severity string
+	Note     string
+}
+
+// httpCookieFinding is a cookie set by the response with its redacted value and issues
+type httpCookieFinding struct {
+	Name     string
+	Value    string
+	Secure   bool
+	HttpOnly bool
+	SameSite string
+	Issues   []string
+}
+
+// httpRedirect is a single hop of the redirect chain
+type httpRedirect struct {
+	URL      string
+	Status   int
+	Location string
+}
+
+// httpHeadersReport is the analysis of the final response of the request
+type httpHeadersReport struct {
+	URL       string
+	Method    string
+	FinalURL  string
+	Status    string
+	Proto     string
+	Redirects []httpRedirect
+	// MaxRedirects is the limit of followed redirects, 0 if redirects aren't followed
+	MaxRedirects int
+	Stopped      bool
+	Headers      http.Header
+	Findings     []httpHeaderFinding
+	Cookies      []httpCookieFinding
+	// OutOfScope is the reason why the redirect to the target outside the flow scope isn't followed
+	OutOfScope string
+}
+
+// httpHeaders represents the HTTP security headers analysis tool
+type httpHeaders struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+}
+
+// NewHTTPHeadersTool creates a new HTTP security headers analysis tool instance
+func NewHTTPHeadersTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+) Tool {
+	return &httpHeaders{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+	}
+}
+
+// Handle processes an HTTP security headers analysis request from an AI agent
+func (h *httpHeaders) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !h.IsAvailable() {
+		return "", fmt.Errorf("http_headers is not available")
+	}
+
+	var action HTTPHeadersAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(h.flowID, h.taskID, h.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal http headers action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	target, err := normalizeHTTPHeadersURL(action.URL)
+	if err != nil {
+		return fmt.Sprintf("failed to analyze HTTP headers: %v", err), nil
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(action.Method))
+	if method == "" {
+		method = defaultHTTPHeadersMethod
+	}
+	if !slices.Contains(httpHeadersMethods, method) {
+		return fmt.Sprintf("failed to analyze HTTP headers: unsupported method '%s', use one of %s",
+			method, strings.Join(httpHeadersMethods, ", ")), nil
+	}
+
+	maxRedirects := 0
+	if action.FollowRedirects {
+		maxRedirects = defaultHTTPMaxRedirects
+		if action.MaxRedirects != nil && *action.MaxRedirects > 0 {
+			maxRedirects = min(action.MaxRedirects.Int(), maxHTTPRedirects)
+		}
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"url":           target[:min(len(target), 1000)],
+		"method":        method,
+		"max_redirects": maxRedirects,
+	})
+
+	report, err := h.analyze(ctx, target, method, maxRedirects)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("http headers analysis error swallowed"),
+			langfuse.WithEventInput(target),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":     HTTPHeadersToolName,
+				"engine":        "http_headers",
+				"url":           target,
+				"method":        method,
+				"max_redirects": maxRedirects,
+				"error":         err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to analyze HTTP headers")
+		return fmt.Sprintf("failed to analyze HTTP headers: %v", err), nil
+	}
+
+	result := formatHTTPHeadersReport(report, resultSizeLimitsFromContext(ctx).MaxResultSize)
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && h.slp != nil {
+		_, _ = h.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeHttpHeaders,
+			method+" "+target,
+			result,
+			h.taskID,
+			h.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// normalizeHTTPHeadersURL validates the target URL, https is used if the scheme is omitted
+func normalizeHTTPHeadersURL(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("url is empty")
+	}
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid url '%s': %w", target, err)
+	}
+
+	if scheme := strings.ToLower(parsed.Scheme); scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme '%s', use http or https", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid url '%s': host is empty", target)
+	}
+
+	return parsed.String(), nil
+}
+
+// analyze sends the request and follows redirects manually to keep every hop of the chain,
+// the certificate of the target isn't verified because test targets often use self-signed ones
+func (h *httpHeaders) analyze(ctx context.Context, target, method string, maxRedirects int) (httpHeadersReport, error) {
+	report := httpHeadersReport{URL: target, Method: method, MaxRedirects: maxRedirects}
+
+	client, err := system.GetHTTPClient(h.cfg)
+	if err != nil {
+		return report, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = httpHeadersRequestTimeout
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	current := target
+	for {
+		resp, err := h.do(ctx, client, method, current)
+		if err != nil {
+			return report, err
+		}
+
+		location := resp.Header.Get("Location")
+		isRedirect := resp.StatusCode >= 300 && resp.StatusCode < 400 && location != ""
+
+		var next *url.URL
+		if isRedirect && len(report.Redirects) < maxRedirects {
+			next, err = resp.Request.URL.Parse(location)
+			if err != nil {
+				return report, fmt.Errorf("invalid redirect location '%s': %w", location, err)
+			}
+			// the redirect to the out-of-scope target isn't followed, the redirect response is analyzed instead
+			if reason := scopeGuardFromContext(ctx).CheckURL(next.String()); reason != "" {
+				report.OutOfScope = fmt.Sprintf("redirect to %s isn't followed: %s", next, reason)
+			}
+		}
+
+		if next == nil || report.OutOfScope != "" {
+			report.FinalURL = current
+			report.Status = resp.Status
+			report.Proto = resp.Proto
+			report.Headers = resp.Header
+			report.Stopped = next == nil && isRedirect && maxRedirects > 0
+			report.Findings = analyzeSecurityHeaders(resp.Header, strings.HasPrefix(current, "https://"))
+			report.Cookies = analyzeCookies(resp.Cookies(), strings.HasPrefix(current, "https://"))
+			return report, nil
+		}
+
+		report.Redirects = append(report.Redirects, httpRedirect{
+			URL:      current,
+			Status:   resp.StatusCode,
+			Location: next.String(),
+		})
+
+		// the same rules as net/http client uses to change the method on redirects
+		switch resp.StatusCode {
+		case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther:
+			if method != http.MethodGet && method != http.MethodHead {
+				method = http.MethodGet
+			}
+		}
+		current = next.String()
+	}
+}
+
+func (h *httpHeaders) do(ctx context.Context, client *http.Client, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", httpHeadersUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, httpHeadersBodyDrainLimit))
+
+	return resp, nil
+}
+
+// analyzeSecurityHeaders checks presence and values of the security headers and reports
+// headers disclosing the server software
+func analyzeSecurityHeaders(headers http.Header, isHTTPS bool) []httpHeaderFinding {
+	var findings []httpHeaderFinding
+	csp := headers.Get("Content-Security-Policy")
+
+	if isHTTPS {
+		findings = append(findings, checkHSTS(headers.Get("Strict-Transport-Security")))
+	} else {
+		findings = append(findings, httpHeaderFinding{
+			Header:   "Strict-Transport-Security",
+			Status:   headerStatusMissing,
+			Severity: severityMedium,
+			Note:     "response is sent over plain HTTP, HSTS is applied only over HTTPS and traffic can be intercepted",
+		})
+	}
+
+	findings = append(findings, checkCSP(csp, headers.Get("Content-Security-Policy-Report-Only")))
+	findings = append(findings, checkFrameOptions(headers.Get("X-Frame-Options"), csp))
+
+	switch value := headers.Get("X-Content-Type-Options"); {
+	case value == "":
+		findings = append(findings, httpHeaderFinding{"X-Content-Type-Options", headerStatusMissing, severityLow,
+			"browsers may MIME-sniff responses, set to 'nosniff'"})
+	case !strings.EqualFold(strings.TrimSpace(value), "nosniff"):
+		findings = append(findings, httpHeaderFinding{"X-Content-Type-Options", headerStatusWeak, severityLow,
+			fmt.Sprintf("invalid value '%s', the only valid value is 'nosniff'", value)})
+	default:
+		findings = append(findings, httpHeaderFinding{"X-Content-Type-Options", headerStatusPresent, severityInfo, "nosniff"})
+	}
+
+	switch value := strings.ToLower(headers.Get("Referrer-Policy")); {
+	case value == "":
+		findings = append(findings, httpHeaderFinding{"Referrer-Policy", headerStatusMissing, severityLow,
+			"browser default is used, full URLs may leak to third parties on older browsers"})
+	case strings.Contains(value, "unsafe-url") || strings.Contains(value, "no-referrer-when-downgrade"):
+		findings = append(findings, httpHeaderFinding{"Referrer-Policy", headerStatusWeak, severityLow,
+			fmt.Sprintf("'%s' sends full URLs with query strings to other origins", value)})
+	default:
+		findings = append(findings, httpHeaderFinding{"Referrer-Policy", headerStatusPresent, severityInfo, value})
+	}
+
+	if value := headers.Get("Permissions-Policy"); value == "" {
+		findings = append(findings, httpHeaderFinding{"Permissions-Policy", headerStatusMissing, severityLow,
+			"browser features like camera, microphone and geolocation aren't restricted"})
+	} else {
+		findings = append(findings, httpHeaderFinding{"Permissions-Policy", headerStatusPresent, severityInfo, truncateHeaderValue(value)})
+	}
+
+	for _, name := range []string{"Cross-Origin-Opener-Policy", "Cross-Origin-Resource-Policy"} {
+		if value := headers.Get(name); value == "" {
+			findings = append(findings, httpHeaderFinding{name, headerStatusMissing, severityInfo,
+				"cross-origin isolation isn't configured"})
+		} else {
+			findings = append(findings, httpHeaderFinding{name, headerStatusPresent, severityInfo, value})
+		}
+	}
+
+	if finding, ok := checkCORS(headers); ok {
+		findings = append(findings, finding)
+	}
+
+	for _, name := range disclosureHeaders {
+		value := headers.Get(name)
+		if value == "" {
+			continue
+		}
+		severity, note := severityInfo, "reveals server software"
+		if headerVersionRegexp.MatchString(value) {
+			severity, note = severityLow, "reveals server software version, check it for known vulnerabilities"
+		}
+		findings = append(findings, httpHeaderFinding{name, headerStatusWeak, severity,
+			fmt.Sprintf("%s: '%s'", note, truncateHeaderValue(value))})
+	}
+
+	return findings
+}
+
+func checkHSTS(value string) httpHeaderFinding {
+	const name = "Strict-Transport-Security"
+	if value == "" {
+		return httpHeaderFinding{name, headerStatusMissing, severityHigh,
+			"browsers may connect over plain HTTP, enables SSL stripping attacks"}
+	}
+
+	var maxAge int64 = -1
+	for directive := range strings.SplitSeq(strings.ToLower(value), ";") {
+		key, val, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if key == "max-age" {
+			if age, err := strconv.ParseInt(strings.Trim(val, `" `), 10, 64); err == nil {
+				maxAge = age
+			}
+		}
+	}
+
+	switch {
+	case maxAge < 0:
+		return httpHeaderFinding{name, headerStatusWeak, severityMedium, fmt.Sprintf("max-age is missing or invalid: '%s'", value)}
+	case maxAge == 0:
+		return httpHeaderFinding{name, headerStatusWeak, severityMedium, "max-age=0 disables HSTS"}
+	case maxAge < httpHeadersMinHSTSMaxAge:
+		return httpHeaderFinding{name, headerStatusWeak, severityLow,
+			fmt.Sprintf("max-age=%d is shorter than recommended 180 days (%d)", maxAge, httpHeadersMinHSTSMaxAge)}
+	case !strings.Contains(strings.ToLower(value), "includesubdomains"):
+		return httpHeaderFinding{name, headerStatusPresent, severityInfo, value + " (subdomains aren't included)"}
+	}
+
+	return httpHeaderFinding{name, headerStatusPresent, severityInfo, value}
+}
+
+func checkCSP(csp, reportOnly string) httpHeaderFinding {
+	const name = "Content-Security-Policy"
+	if csp == "" {
+		if reportOnly != "" {
+			return httpHeaderFinding{name, headerStatusWeak, severityMedium,
+				"only Content-Security-Policy-Report-Only is set, the policy isn't enforced"}
+		}
+		return httpHeaderFinding{name, headerStatusMissing, severityMedium,
+			"no policy restricts scripts and other resources, XSS is easier to exploit"}
+	}
+
+	lower := strings.ToLower(csp)
+	var issues []string
+	if strings.Contains(lower, "'unsafe-inline'") {
+		issues = append(issues, "'unsafe-inline' allows inline scripts or styles")
+	}
+	if strings.Contains(lower, "'unsafe-eval'") {
+		issues = append(issues, "'unsafe-eval' allows eval()")
+	}
+	for directive := range strings.SplitSeq(lower, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) > 1 && slices.Contains(fields[1:], "*") {
+			issues = append(issues, fmt.Sprintf("wildcard source in '%s'", fields[0]))
+		}
+	}
+	if !strings.Contains(lower, "default-src") && !strings.Contains(lower, "script-src") {
+		issues = append(issues, "neither default-src nor script-src is set")
+	}
+
+	if len(issues) > 0 {
+		return httpHeaderFinding{name, headerStatusWeak, severityMedium,
+			strings.Join(issues, "; ") + ": " + truncateHeaderValue(csp)}
+	}
+
+	return httpHeaderFinding{name, headerStatusPresent, severityInfo, truncateHeaderValue(csp)}
+}
+
+func checkFrameOptions(value, csp string) httpHeaderFinding {
+	const name = "X-Frame-Options"
+	hasFrameAncestors := strings.Contains(strings.ToLower(csp), "frame-ancestors")
+
+	switch upper := strings.ToUpper(strings.TrimSpace(value)); {
+	case upper == "" && hasFrameAncestors:
+		return httpHeaderFinding{name, headerStatusMissing, severityInfo,
+			"framing is restricted by CSP frame-ancestors"}
+	case upper == "":
+		return httpHeaderFinding{name, headerStatusMissing, severityMedium,
+			"pages can be framed by other sites, enables clickjacking"}
+	case upper != "DENY" && upper != "SAMEORIGIN":
+		return httpHeaderFinding{name, headerStatusWeak, severityMedium,
+			fmt.Sprintf("'%s' isn't supported by modern browsers, use DENY or SAMEORIGIN", value)}
+	}
+
+	return httpHeaderFinding{name, headerStatusPresent, severityInfo, value}
+}
+
+// checkCORS reports the CORS policy only if the response has it
+func checkCORS(headers http.Header) (httpHeaderFinding, bool) {
+	const name = "Access-Control-Allow-Origin"
+	origin := strings.TrimSpace(headers.Get(name))
+	if origin == "" {
+		return httpHeaderFinding{}, false
+	}
+
+	credentials := strings.EqualFold(strings.TrimSpace(headers.Get("Access-Control-Allow-Credentials")), "true")
+	switch {
+	case origin == "*" && credentials:
+		return httpHeaderFinding{name, headerStatusWeak, severityHigh,
+			"wildcard origin with credentials, check if the origin is reflected for credentialed requests"}, true
+	case origin == "*":
+		return httpHeaderFinding{name, headerStatusWeak, severityLow,
+			"any origin can read responses, fine only for public resources"}, true
+	case origin == "null":
+		return httpHeaderFinding{name, headerStatusWeak, severityHigh,
+			"'null' origin is allowed, it can be sent from sandboxed iframes"}, true
+	case credentials:
+		return httpHeaderFinding{name, headerStatusPresent, severityInfo,
+			fmt.Sprintf("'%s' with credentials, check if arbitrary origins are reflected", origin)}, true
+	}
+
+	return httpHeaderFinding{name, headerStatusPresent, severityInfo, origin}, true
+}
+
+// analyzeCookies checks security attributes of cookies and redacts values of session-like ones
+func analyzeCookies(cookies []*http.Cookie, isHTTPS bool) []httpCookieFinding {
+	findings := make([]httpCookieFinding, 0, len(cookies))
+	for _, cookie := range cookies {
+		finding := httpCookieFinding{
+			Name:     cookie.Name,
+			Value:    redactCookieValue(cookie.Name, cookie.Value),
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+
+		switch cookie.SameSite {
+		case http.SameSiteLaxMode:
+			finding.SameSite = "Lax"
+		case http.SameSiteStrictMode:
+			finding.SameSite = "Strict"
+		case http.SameSiteNoneMode:
+			finding.SameSite = "None"
+		default:
+			finding.SameSite = "-"
+		}
+
+		sensitive := sensitiveCookieRegexp.MatchString(cookie.Name)
+		if !cookie.Secure && isHTTPS {
+			finding.Issues = append(finding.Issues, "no Secure flag, can be sent over plain HTTP")
+		}
+		if !cookie.HttpOnly && sensitive {
+			finding.Issues = append(finding.Issues, "no HttpOnly flag, readable by scripts")
+		}
+		if cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure {
+			finding.Issues = append(finding.Issues, "SameSite=None without Secure is rejected by browsers")
+		} else if cookie.SameSite == http.SameSiteNoneMode && sensitive {
+			finding.Issues = append(finding.Issues, "SameSite=None sends the cookie with cross-site requests")
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+// redactCookieValue hides values of session-like cookies and of long token-like values,
+// only the length and a short prefix is kept to compare cookies between requests
+func redactCookieValue(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	if !sensitiveCookieRegexp.MatchString(name) && len(value) < 32 {
+		return value
+	}
+
+	return redactHeaderValue(value)
+}
+
+func redactHeaderValue(value string) string {
+	prefix := ""
+	if len(value) >= 16 {
+		prefix = value[:4]
+	}
+
+	return fmt.Sprintf("%s***REDACTED (%d chars)***", prefix, len(value))
+}
+
+func truncateHeaderValue(value string) string {
+	if len(value) <= httpHeadersMaxValueLen {
+		return value
+	}
+
+	return CutRunes(value, httpHeadersMaxValueLen) + "...(truncated)"
+}
+
+// formatHTTPHeadersReport renders the findings, cookies and raw response headers as markdown
+// capped by sizeLimit; sensitive values are redacted
+func formatHTTPHeadersReport(report httpHeadersReport, sizeLimit int) string {
+	var sb strings.Builder
+	sb.WriteString("# HTTP Security Headers\n\n")
+	sb.WriteString(fmt.Sprintf("**Request:** %s %s  \n", report.Method, report.URL))
+	if report.FinalURL != report.URL {
+		sb.WriteString(fmt.Sprintf("**Final URL:** %s  \n", report.FinalURL))
+	}
+	sb.WriteString(fmt.Sprintf("**Status:** %s %s  \n", report.Proto, report.Status))
+
+	if len(report.Redirects) > 0 {
+		sb.WriteString("\n## Redirects\n\n")
+		for i, hop := range report.Redirects {
+			sb.WriteString(fmt.Sprintf("%d. %d %s -> %s\n", i+1, hop.Status, hop.URL, hop.Location))
+		}
+		if report.Stopped {
+			sb.WriteString(fmt.Sprintf("\n**Note:** redirect limit is reached, headers of the last redirect response "+
+				"are analyzed (max %d redirects)\n", report.MaxRedirects))
+		}
+	}
+	if report.OutOfScope != "" {
+		sb.WriteString(fmt.Sprintf("\n**Note:** %s, headers of the redirect response are analyzed\n", report.OutOfScope))
+	}
+
+	counts := make(map[string]int)
+	for _, finding := range report.Findings {
+		if finding.Status != headerStatusPresent && finding.Severity != severityInfo {
+			counts[finding.Severity]++
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n## Security Headers\n\n**Issues:** %d high, %d medium, %d low\n\n",
+		counts[severityHigh], counts[severityMedium], counts[severityLow]))
+	sb.WriteString("| Header | Status | Severity | Notes |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, finding := range report.Findings {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			finding.Header, finding.Status, finding.Severity, escapeMarkdownCell(finding.Note)))
+	}
+
+	if len(report.Cookies) > 0 {
+		sb.WriteString("\n## Cookies\n\n")
+		sb.WriteString("| Name | Value | Secure | HttpOnly | SameSite | Issues |\n")
+		sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, cookie := range report.Cookies {
+			issues := "-"
+			if len(cookie.Issues) > 0 {
+				issues = strings.Join(cookie.Issues, "; ")
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %t | %t | %s | %s |\n",
+				escapeMarkdownCell(cookie.Name), escapeMarkdownCell(truncateHeaderValue(cookie.Value)),
+				cookie.Secure, cookie.HttpOnly, cookie.SameSite, issues))
+		}
+	}
+
+	sb.WriteString("\n## Response Headers\n\n```\n")
+	names := make([]string, 0, len(report.Headers))
+	for name := range report.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var total int
+	for _, values := range report.Headers {
+		total += len(values)
+	}
+
+	budget := NewResultBudget(sizeLimit, sb.Len())
+	for _, name := range names {
+		for _, value := range report.Headers[name] {
+			switch {
+			case slices.Contains(sensitiveHeaders, name):
+				value = redactHeaderValue(value)
+			case name == "Set-Cookie":
+				value = redactSetCookie(value)
+			}
+
+			line := fmt.Sprintf("%s: %s\n", name, truncateHeaderValue(value))
+			if !budget.Add(&sb, line) {
+				sb.WriteString("```\n")
+				sb.WriteString(budget.Notice(budget.Added(), total, "response headers"))
+				return sb.String()
+			}
+		}
+	}
+	sb.WriteString("```\n")
+
+	return sb.String()
+}
+
+// redactSetCookie redacts the value of the raw Set-Cookie header keeping its attributes
+func redactSetCookie(header string) string {
+	pair, attrs, hasAttrs := strings.Cut(header, ";")
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok {
+		return header
+	}
+
+	result := strings.TrimSpace(name) + "=" + redactCookieValue(strings.TrimSpace(name), strings.TrimSpace(value))
+	if hasAttrs {
+		result += ";" + attrs
+	}
+
+	return result
+}
+
+func escapeMarkdownCell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ", "\r", " ").Replace(value)
+}
+
+// IsAvailable returns true if the HTTP security headers analysis tool is enabled
+func (h *httpHeaders) IsAvailable() bool {
+	return h.cfg != nil && h.cfg.HTTPHeadersEnabled
+}
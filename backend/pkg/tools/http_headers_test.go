@@ -0,0 +1,350 @@
+package tools
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func testHTTPHeadersTool(slp SearchLogProvider) Tool {
+	return NewHTTPHeadersTool(&config.Config{HTTPHeadersEnabled: true}, 1, nil, nil, slp)
+}
+
+func findHeaderFinding(t *testing.T, findings []httpHeaderFinding, header string) httpHeaderFinding {
+	t.Helper()
+
+	for _, finding := range findings {
+		if finding.Header == header {
+			return finding
+		}
+	}
+
+	t.Fatalf("finding for %s not found in %+v", header, findings)
+	return httpHeaderFinding{}
+}
+
+func TestAnalyzeSecurityHeaders(t *testing.T) {
+	t.Run("missing over https", func(t *testing.T) {
+		findings := analyzeSecurityHeaders(http.Header{}, true)
+
+		tests := []struct {
+			header   string
+			status   string
+			severity string
+		}{
+			{"Strict-Transport-Security", headerStatusMissing, severityHigh},
+			{"Content-Security-Policy", headerStatusMissing, severityMedium},
+			{"X-Frame-Options", headerStatusMissing, severityMedium},
+			{"X-Content-Type-Options", headerStatusMissing, severityLow},
+			{"Referrer-Policy", headerStatusMissing, severityLow},
+			{"Permissions-Policy", headerStatusMissing, severityLow},
+			{"Cross-Origin-Opener-Policy", headerStatusMissing, severityInfo},
+		}
+		for _, tt := range tests {
+			got := findHeaderFinding(t, findings, tt.header)
+			if got.Status != tt.status || got.Severity != tt.severity {
+				t.Errorf("%s = %s/%s, want %s/%s", tt.header, got.Status, got.Severity, tt.status, tt.severity)
+			}
+		}
+	})
+
+	t.Run("hardened", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
+		headers.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		headers.Set("X-Content-Type-Options", "nosniff")
+		headers.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		headers.Set("Permissions-Policy", "camera=()")
+		headers.Set("Cross-Origin-Opener-Policy", "same-origin")
+		headers.Set("Cross-Origin-Resource-Policy", "same-origin")
+
+		for _, finding := range analyzeSecurityHeaders(headers, true) {
+			if finding.Severity != severityInfo {
+				t.Errorf("unexpected finding %+v", finding)
+			}
+		}
+	})
+
+	t.Run("weak values", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("Strict-Transport-Security", "max-age=3600")
+		headers.Set("Content-Security-Policy", "script-src 'self' 'unsafe-inline' *")
+		headers.Set("X-Frame-Options", "ALLOW-FROM https://example.com")
+		headers.Set("X-Content-Type-Options", "sniff")
+		headers.Set("Referrer-Policy", "unsafe-url")
+		headers.Set("Access-Control-Allow-Origin", "*")
+		headers.Set("Access-Control-Allow-Credentials", "true")
+		headers.Set("Server", "nginx/1.18.0 (Ubuntu)")
+		headers.Set("X-Powered-By", "Express")
+
+		findings := analyzeSecurityHeaders(headers, true)
+		tests := []struct {
+			header   string
+			severity string
+			note     string
+		}{
+			{"Strict-Transport-Security", severityLow, "max-age=3600 is shorter than recommended"},
+			{"Content-Security-Policy", severityMedium, "'unsafe-inline' allows inline scripts or styles; wildcard source in 'script-src'"},
+			{"X-Frame-Options", severityMedium, "isn't supported by modern browsers"},
+			{"X-Content-Type-Options", severityLow, "invalid value 'sniff'"},
+			{"Referrer-Policy", severityLow, "sends full URLs"},
+			{"Access-Control-Allow-Origin", severityHigh, "wildcard origin with credentials"},
+			{"Server", severityLow, "reveals server software version"},
+			{"X-Powered-By", severityInfo, "reveals server software: 'Express'"},
+		}
+		for _, tt := range tests {
+			got := findHeaderFinding(t, findings, tt.header)
+			if got.Status != headerStatusWeak || got.Severity != tt.severity || !strings.Contains(got.Note, tt.note) {
+				t.Errorf("%s = %+v, want weak/%s with note %q", tt.header, got, tt.severity, tt.note)
+			}
+		}
+	})
+
+	t.Run("plain http", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("Content-Security-Policy-Report-Only", "default-src 'self'")
+
+		findings := analyzeSecurityHeaders(headers, false)
+		if got := findHeaderFinding(t, findings, "Strict-Transport-Security"); got.Severity != severityMedium ||
+			!strings.Contains(got.Note, "plain HTTP") {
+			t.Errorf("HSTS over http = %+v", got)
+		}
+		if got := findHeaderFinding(t, findings, "Content-Security-Policy"); got.Status != headerStatusWeak ||
+			!strings.Contains(got.Note, "isn't enforced") {
+			t.Errorf("report only CSP = %+v", got)
+		}
+	})
+}
+
+func TestAnalyzeCookies(t *testing.T) {
+	cookies := []*http.Cookie{
+		{Name: "PHPSESSID", Value: "k3j4h5g6f7d8s9a0q1w2e3r4t5", Path: "/"},
+		{Name: "theme", Value: "dark", Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode},
+		{Name: "tracking", Value: strings.Repeat("x", 40), Secure: true, SameSite: http.SameSiteNoneMode},
+		{Name: "auth_token", Value: "short", Secure: true, SameSite: http.SameSiteNoneMode},
+	}
+
+	findings := analyzeCookies(cookies, true)
+	if len(findings) != 4 {
+		t.Fatalf("len(findings) = %d, want 4", len(findings))
+	}
+
+	session := findings[0]
+	if session.Value != "k3j4***REDACTED (26 chars)***" {
+		t.Errorf("session cookie value = %q, want redacted", session.Value)
+	}
+	if got := strings.Join(session.Issues, "; "); !strings.Contains(got, "no Secure flag") || !strings.Contains(got, "no HttpOnly flag") {
+		t.Errorf("session cookie issues = %q", got)
+	}
+
+	if findings[1].Value != "dark" || len(findings[1].Issues) != 0 || findings[1].SameSite != "Lax" {
+		t.Errorf("plain cookie = %+v", findings[1])
+	}
+	if findings[2].Value != "xxxx***REDACTED (40 chars)***" {
+		t.Errorf("long cookie value = %q, want redacted", findings[2].Value)
+	}
+	if findings[3].Value != "***REDACTED (5 chars)***" {
+		t.Errorf("short sensitive cookie value = %q, want redacted without prefix", findings[3].Value)
+	}
+	if got := strings.Join(findings[3].Issues, "; "); !strings.Contains(got, "SameSite=None sends the cookie") {
+		t.Errorf("auth cookie issues = %q", got)
+	}
+}
+
+func TestHTTPHeadersHandle(t *testing.T) {
+	var methods []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/login", http.StatusMovedPermanently)
+		case "/login":
+			w.Header().Set("Server", "Apache/2.4.41")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("X-Api-Key", "0123456789abcdef0123456789abcdef")
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "s3cr3t-s3ss10n-v4lu3-th4t-l34ks", Path: "/", HttpOnly: true})
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("<html>login</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	slp := &searchLogProviderMock{}
+	tool := testHTTPHeadersTool(slp)
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypePentester)
+
+	got, err := tool.Handle(ctx, HTTPHeadersToolName,
+		[]byte(fmt.Sprintf(`{"url":%q,"method":"post","follow_redirects":true,"message":"check"}`, server.URL+"/old")))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"**Request:** POST " + server.URL + "/old",
+		"**Final URL:** " + server.URL + "/login",
+		"**Status:** HTTP/1.1 200 OK",
+		"1. 301 " + server.URL + "/old -> " + server.URL + "/login",
+		"| Strict-Transport-Security | missing | high |",
+		"| Server | weak | low | reveals server software version, check it for known vulnerabilities: 'Apache/2.4.41' |",
+		"| session_id | s3cr***REDACTED (31 chars)*** | false | true | - | no Secure flag, can be sent over plain HTTP |",
+		"Set-Cookie: session_id=s3cr***REDACTED (31 chars)***; Path=/; HttpOnly",
+		"X-Api-Key: 0123***REDACTED (32 chars)***",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "s3cr3t-s3ss10n") || strings.Contains(got, "0123456789abcdef") {
+		t.Errorf("result leaks sensitive values:\n%s", got)
+	}
+
+	// 301 changes POST to GET like browsers do
+	if strings.Join(methods, ",") != "POST,GET" {
+		t.Errorf("methods = %v, want [POST GET]", methods)
+	}
+	if slp.calls != 1 || slp.engine != database.SearchengineTypeHttpHeaders || slp.query != "POST "+server.URL+"/old" {
+		t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+	}
+}
+
+func TestHTTPHeadersHandle_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hop int
+		fmt.Sscanf(r.URL.Path, "/hop/%d", &hop)
+		http.Redirect(w, r, fmt.Sprintf("/hop/%d", hop+1), http.StatusFound)
+	}))
+	defer server.Close()
+
+	tool := testHTTPHeadersTool(nil)
+
+	tests := []struct {
+		name      string
+		args      string
+		redirects int
+		want      string
+	}{
+		{
+			name: "not followed",
+			args: `{"url":%q,"follow_redirects":false}`,
+			want: "**Status:** HTTP/1.1 302 Found",
+		},
+		{
+			name:      "default limit",
+			args:      `{"url":%q,"follow_redirects":true}`,
+			redirects: defaultHTTPMaxRedirects,
+			want:      "redirect limit is reached, headers of the last redirect response are analyzed (max 5 redirects)",
+		},
+		{
+			name:      "custom limit",
+			args:      `{"url":%q,"follow_redirects":true,"max_redirects":2}`,
+			redirects: 2,
+			want:      "**Final URL:** " + server.URL + "/hop/2",
+		},
+		{
+			name:      "limit is capped",
+			args:      `{"url":%q,"follow_redirects":true,"max_redirects":100}`,
+			redirects: maxHTTPRedirects,
+			want:      "(max 10 redirects)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.Handle(t.Context(), HTTPHeadersToolName, []byte(fmt.Sprintf(tt.args, server.URL+"/hop/0")))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("result missing %q:\n%s", tt.want, got)
+			}
+			if hops := strings.Count(got, " 302 "+server.URL); hops != tt.redirects {
+				t.Errorf("redirects = %d, want %d", hops, tt.redirects)
+			}
+			if got := strings.Contains(got, "**Final URL:**"); got != (tt.redirects > 0) {
+				t.Errorf("final URL shown = %t, want %t", got, tt.redirects > 0)
+			}
+		})
+	}
+}
+
+func TestHTTPHeadersHandle_Errors(t *testing.T) {
+	tool := testHTTPHeadersTool(nil)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty url", `{"url":" ","follow_redirects":false}`, "url is empty"},
+		{"bad scheme", `{"url":"ftp://example.com","follow_redirects":false}`, "unsupported url scheme 'ftp'"},
+		{"bad method", `{"url":"https://example.com","method":"CONNECT","follow_redirects":false}`, "unsupported method 'CONNECT'"},
+		{"unreachable", `{"url":"http://127.0.0.1:1/","follow_redirects":false}`, "request to http://127.0.0.1:1/ failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.Handle(t.Context(), HTTPHeadersToolName, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(got, "failed to analyze HTTP headers") || !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.Handle(t.Context(), HTTPHeadersToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+	if NewHTTPHeadersTool(&config.Config{}, 1, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+}
+
+func TestFormatHTTPHeadersReport_SizeLimit(t *testing.T) {
+	headers := http.Header{}
+	for i := range 200 {
+		headers.Set(fmt.Sprintf("X-Filler-%03d", i), strings.Repeat("v", 2000))
+	}
+
+	got := formatHTTPHeadersReport(httpHeadersReport{
+		URL:      "https://example.com",
+		FinalURL: "https://example.com",
+		Method:   http.MethodGet,
+		Status:   "200 OK",
+		Proto:    "HTTP/1.1",
+		Headers:  headers,
+	}, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("len(result) = %d, want <= %d", len(got), DefaultMaxResultSize)
+	}
+	if !strings.Contains(got, "response headers (output truncated, exceeded 80 KB limit)") {
+		t.Error("result missing truncation note")
+	}
+	if !strings.Contains(got, strings.Repeat("v", httpHeadersMaxValueLen)+"...(truncated)") {
+		t.Error("long header value isn't truncated")
+	}
+}
+
+func TestTruncateHeaderValue(t *testing.T) {
+	value := strings.Repeat("ü", httpHeadersMaxValueLen)
+	result := truncateHeaderValue(value)
+	if !utf8.ValidString(result) {
+		t.Errorf("truncated value must be valid UTF-8: %q", result[len(result)-20:])
+	}
+	if !strings.HasSuffix(result, "...(truncated)") {
+		t.Error("expected truncation suffix")
+	}
+
+	if short := "max-age=31536000"; truncateHeaderValue(short) != short {
+		t.Error("short value must be returned as is")
+	}
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pentagi/pkg/database"
+)
+
+const toolCallsWindow = time.Minute
+
+// ToolCallLimits limits tool calls of the flow, zero value of the field disables its limit
+type ToolCallLimits struct {
+	PerMinute int   // calls of the single tool per minute
+	MaxCalls  int64 // all tool calls of the flow
+}
+
+// IsEmpty returns true if no limits are set
+func (l ToolCallLimits) IsEmpty() bool {
+	return l.PerMinute <= 0 && l.MaxCalls <= 0
+}
+
+// toolCallLimiter is a sliding window limiter of the tool calls shared across all agents of the flow
+type toolCallLimiter struct {
+	mx     *sync.Mutex
+	limits ToolCallLimits
+	calls  map[string][]time.Time
+	total  int64
+	loaded bool
+	now    func() time.Time
+}
+
+func newToolCallLimiter(limits ToolCallLimits) *toolCallLimiter {
+	return &toolCallLimiter{
+		mx:     &sync.Mutex{},
+		limits: limits,
+		calls:  make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// allow registers the tool call and returns throttle reason if the call exceeds the limits,
+// the flow total is loaded from DB on the first call to keep the budget after the backend restart
+func (l *toolCallLimiter) allow(ctx context.Context, db database.Querier, flowID int64, name string) (string, error) {
+	if l == nil || l.limits.IsEmpty() {
+		return "", nil
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if !l.loaded && l.limits.MaxCalls > 0 && db != nil {
+		stats, err := db.GetFlowToolcallsStats(ctx, flowID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get flow %d toolcalls stats: %w", flowID, err)
+		}
+		l.total = stats.TotalCount
+	}
+	l.loaded = true
+
+	if l.limits.MaxCalls > 0 && l.total >= l.limits.MaxCalls {
+		return fmt.Sprintf("the flow has used all %d allowed tool calls", l.limits.MaxCalls), nil
+	}
+
+	now := l.now()
+	if l.limits.PerMinute > 0 {
+		calls := l.calls[name]
+		for len(calls) > 0 && now.Sub(calls[0]) >= toolCallsWindow {
+			calls = calls[1:]
+		}
+		l.calls[name] = calls
+
+		if len(calls) >= l.limits.PerMinute {
+			retryAfter := toolCallsWindow - now.Sub(calls[0])
+			return fmt.Sprintf("tool '%s' was called %d times during the last minute, the limit is %d, retry after %s",
+				name, len(calls), l.limits.PerMinute, retryAfter.Round(time.Second)), nil
+		}
+
+		l.calls[name] = append(calls, now)
+	}
+
+	l.total++
+
+	return "", nil
+}
+
+func formatThrottledToolCall(name, reason string) string {
+	return fmt.Sprintf("tool call '%s' is throttled: %s. "+
+		"Don't repeat the same call, change your strategy: use another tool, "+
+		"rely on the results you already have or finish the task with the current results", name, reason)
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+)
+
+type toolcallsStatsQuerier struct {
+	database.Querier
+	total int64
+	calls int
+}
+
+func (q *toolcallsStatsQuerier) GetFlowToolcallsStats(
+	ctx context.Context,
+	flowID int64,
+) (database.GetFlowToolcallsStatsRow, error) {
+	q.calls++
+	return database.GetFlowToolcallsStatsRow{TotalCount: q.total}, nil
+}
+
+func TestToolCallLimiterPerMinute(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newToolCallLimiter(ToolCallLimits{PerMinute: 2})
+	limiter.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	for i := 0; i < 2; i++ {
+		if reason, err := limiter.allow(ctx, nil, 1, "sploitus"); err != nil || reason != "" {
+			t.Fatalf("call %d: expected allowed, got reason %q, err %v", i, reason, err)
+		}
+	}
+
+	reason, err := limiter.allow(ctx, nil, 1, "sploitus")
+	if err != nil {
+		t.Fatalf("allow() unexpected error: %v", err)
+	}
+	if !strings.Contains(reason, "retry after 1m0s") {
+		t.Errorf("expected throttle reason with retry delay, got %q", reason)
+	}
+
+	// other tools have their own window
+	if reason, _ := limiter.allow(ctx, nil, 1, "search"); reason != "" {
+		t.Errorf("expected other tool to be allowed, got %q", reason)
+	}
+
+	now = now.Add(time.Minute)
+	if reason, _ := limiter.allow(ctx, nil, 1, "sploitus"); reason != "" {
+		t.Errorf("expected call to be allowed after the window, got %q", reason)
+	}
+}
+
+func TestToolCallLimiterMaxCalls(t *testing.T) {
+	db := &toolcallsStatsQuerier{total: 3}
+	limiter := newToolCallLimiter(ToolCallLimits{MaxCalls: 5})
+	ctx := t.Context()
+
+	for i := 0; i < 2; i++ {
+		if reason, err := limiter.allow(ctx, db, 1, "terminal"); err != nil || reason != "" {
+			t.Fatalf("call %d: expected allowed, got reason %q, err %v", i, reason, err)
+		}
+	}
+
+	reason, err := limiter.allow(ctx, db, 1, "browser")
+	if err != nil {
+		t.Fatalf("allow() unexpected error: %v", err)
+	}
+	if !strings.Contains(reason, "all 5 allowed tool calls") {
+		t.Errorf("expected budget reason, got %q", reason)
+	}
+	if db.calls != 1 {
+		t.Errorf("expected stats to be loaded once, got %d", db.calls)
+	}
+}
+
+func TestToolCallLimiterDisabled(t *testing.T) {
+	var limiter *toolCallLimiter
+	if reason, err := limiter.allow(t.Context(), nil, 1, "terminal"); err != nil || reason != "" {
+		t.Errorf("nil limiter must allow all calls, got reason %q, err %v", reason, err)
+	}
+
+	limiter = newToolCallLimiter(ToolCallLimits{})
+	for i := 0; i < 100; i++ {
+		if reason, _ := limiter.allow(t.Context(), nil, 1, "terminal"); reason != "" {
+			t.Fatalf("empty limits must allow all calls, got %q", reason)
+		}
+	}
+}
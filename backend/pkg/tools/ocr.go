@@ -0,0 +1,375 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	OCRBackendTesseract    = "tesseract"
+	OCRBackendGoogleVision = "google_vision"
+
+	ocrTimeout = 60 * time.Second
+	// ocrMaxTextLen is the max length of the recognized text in the output
+	ocrMaxTextLen = 16 * 1024
+	// ocrMaxErrorLen is the max length of the backend error output in the error message
+	ocrMaxErrorLen  = 500
+	googleVisionURL = "https://vision.googleapis.com/v1/images:annotate"
+	// googleVisionMaxResponseSize limits the response which has the boxes of every word besides the text
+	googleVisionMaxResponseSize = 32 * 1024 * 1024
+)
+
+// ocrArtifactRefRegexp matches the download reference of the artifact returned by the tools
+var ocrArtifactRefRegexp = regexp.MustCompile(`^/api/v1/flows/(\d+)/artifacts/(\d+)/file$`)
+
+// ocrBackend recognizes the text on the image
+type ocrBackend interface {
+	Name() string
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// newOCRBackend returns the configured backend or nil if it isn't available on the server
+func newOCRBackend(cfg *config.Config) ocrBackend {
+	if cfg == nil || !cfg.OCREnabled {
+		return nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.OCRBackend)) {
+	case "", OCRBackendTesseract:
+		path, err := exec.LookPath(cfg.OCRTesseractPath)
+		if err != nil {
+			return nil
+		}
+		return &tesseractOCR{path: path, languages: cfg.OCRTesseractLanguages}
+	case OCRBackendGoogleVision:
+		if cfg.OCRGoogleVisionAPIKey == "" {
+			return nil
+		}
+		return &googleVisionOCR{cfg: cfg, url: googleVisionURL, apiKey: cfg.OCRGoogleVisionAPIKey}
+	default:
+		return nil
+	}
+}
+
+// tesseractOCR runs the tesseract binary on the server which reads the image from stdin
+type tesseractOCR struct {
+	path      string
+	languages string
+}
+
+func (t *tesseractOCR) Name() string {
+	return OCRBackendTesseract
+}
+
+func (t *tesseractOCR) Recognize(ctx context.Context, image []byte) (string, error) {
+	args := []string{"stdin", "stdout"}
+	if languages := strings.TrimSpace(t.languages); languages != "" {
+		args = append(args, "-l", languages)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.path, args...)
+	cmd.Stdin = bytes.NewReader(image)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		output := strings.TrimSpace(stderr.String())
+		return "", fmt.Errorf("tesseract failed: %w: %s", err, output[:min(len(output), ocrMaxErrorLen)])
+	}
+
+	return stdout.String(), nil
+}
+
+// googleVisionOCR uses the text detection of the Google Cloud Vision API
+type googleVisionOCR struct {
+	cfg    *config.Config
+	url    string
+	apiKey string
+}
+
+type googleVisionResponse struct {
+	Responses []struct {
+		FullTextAnnotation *struct {
+			Text string `json:"text"`
+		} `json:"fullTextAnnotation"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"responses"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+func (g *googleVisionOCR) Name() string {
+	return OCRBackendGoogleVision
+}
+
+func (g *googleVisionOCR) Recognize(ctx context.Context, image []byte) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"requests": []map[string]any{{
+			"image":    map[string]any{"content": base64.StdEncoding.EncodeToString(image)},
+			"features": []map[string]any{{"type": "DOCUMENT_TEXT_DETECTION"}},
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal vision request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create vision request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-Api-Key", g.apiKey)
+
+	client, err := system.GetHTTPClient(g.cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send vision request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result googleVisionResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, googleVisionMaxResponseSize)).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode vision response (status %s): %w", resp.Status, err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("vision api error %d %s: %s", result.Error.Code, result.Error.Status, result.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected vision response status %s", resp.Status)
+	}
+	if len(result.Responses) == 0 {
+		return "", nil
+	}
+
+	response := result.Responses[0]
+	if response.Error != nil {
+		return "", fmt.Errorf("vision api error %d: %s", response.Error.Code, response.Error.Message)
+	}
+	if response.FullTextAnnotation == nil {
+		return "", nil
+	}
+
+	return response.FullTextAnnotation.Text, nil
+}
+
+// recognizeImageText runs the backend with the timeout and returns the normalized text,
+// the text is capped by the output formatting
+func recognizeImageText(ctx context.Context, backend ocrBackend, image []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, ocrTimeout))
+	defer cancel()
+
+	text, err := backend.Recognize(ctx, image)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\f\v")
+		// tesseract separates the blocks by the blank lines, the runs of them are collapsed
+		if line == "" && (len(normalized) == 0 || normalized[len(normalized)-1] == "") {
+			continue
+		}
+		normalized = append(normalized, line)
+	}
+	return strings.TrimSpace(strings.Join(normalized, "\n")), nil
+}
+
+// ocr represents the text recognition tool of the image artifacts
+type ocr struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	db        database.Querier
+	backend   ocrBackend
+}
+
+// NewOCRTool creates a new text recognition tool of the flow artifacts with the configured backend
+func NewOCRTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	db database.Querier,
+) Tool {
+	return &ocr{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		db:        db,
+		backend:   newOCRBackend(cfg),
+	}
+}
+
+// Handle processes a text recognition request from an AI agent
+func (o *ocr) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !o.IsAvailable() {
+		return "", fmt.Errorf("ocr is not available")
+	}
+
+	var action OCRAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(o.flowID, o.taskID, o.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal ocr action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	artifact, err := o.findArtifact(ctx, action.Artifact)
+	if err != nil {
+		logger.WithError(err).Error("failed to find image artifact")
+		return fmt.Sprintf("artifact '%s' not found, use the '%s' tool with the '%s' action to get the list of artifacts",
+			action.Artifact, ArtifactsToolName, ListArtifacts), nil
+	}
+	if !strings.HasPrefix(artifact.ContentType, "image/") {
+		return fmt.Sprintf("failed to recognize text: artifact '%s' isn't an image (%s)",
+			artifact.Name, artifact.ContentType), nil
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"artifact_id": artifact.ID,
+		"backend":     o.backend.Name(),
+	})
+
+	text, err := o.recognize(ctx, artifact)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("ocr error swallowed"),
+			langfuse.WithEventInput(artifact.Name),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":   OCRToolName,
+				"backend":     o.backend.Name(),
+				"artifact_id": artifact.ID,
+				"error":       err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to recognize text")
+		return fmt.Sprintf("failed to recognize text: %v", err), nil
+	}
+
+	sizeLimit := resultSizeLimitsFromContext(ctx).MaxResultSize
+	return formatOCRResult(artifact, o.backend.Name(), text, sizeLimit), nil
+}
+
+func (o *ocr) recognize(ctx context.Context, artifact database.FlowArtifact) (string, error) {
+	image, err := os.ReadFile(FlowArtifactPath(o.cfg, o.flowID, artifact.ID))
+	if err != nil {
+		return "", fmt.Errorf("failed to read artifact file: %w", err)
+	}
+
+	return recognizeImageText(ctx, o.backend, image)
+}
+
+// findArtifact looks up the artifact of the flow by the name, by the id or by the download reference
+func (o *ocr) findArtifact(ctx context.Context, ref string) (database.FlowArtifact, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return database.FlowArtifact{}, errors.New("artifact reference is empty")
+	}
+
+	artifact, err := o.db.GetFlowArtifact(ctx, database.GetFlowArtifactParams{FlowID: o.flowID, Name: ref})
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return artifact, err
+	}
+
+	idStr := ref
+	if match := ocrArtifactRefRegexp.FindStringSubmatch(ref); match != nil {
+		if match[1] != strconv.FormatInt(o.flowID, 10) {
+			return database.FlowArtifact{}, fmt.Errorf("artifact reference of another flow: %s", ref)
+		}
+		idStr = match[2]
+	}
+	id, parseErr := strconv.ParseInt(idStr, 10, 64)
+	if parseErr != nil {
+		return database.FlowArtifact{}, err
+	}
+
+	items, err := o.db.GetFlowArtifacts(ctx, o.flowID)
+	if err != nil {
+		return database.FlowArtifact{}, err
+	}
+	for _, item := range items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+
+	return database.FlowArtifact{}, sql.ErrNoRows
+}
+
+// IsAvailable returns true if the text recognition is enabled and the backend is available on the server
+func (o *ocr) IsAvailable() bool {
+	return o.cfg != nil && o.cfg.OCREnabled && o.db != nil && o.backend != nil
+}
+
+// formatOCRText returns the recognized text section which is shared with the screenshot output,
+// the text is capped by ocrMaxTextLen and by the limit which is left of the tool result
+func formatOCRText(text string, limit int) string {
+	if text == "" {
+		return "No text is recognized on the image.\n"
+	}
+
+	limit = max(min(ocrMaxTextLen, limit), 0)
+	if len(text) > limit {
+		return TruncateWithNotice(text, limit, textTruncatedNotice)
+	}
+
+	return text + "\n"
+}
+
+func formatOCRResult(artifact database.FlowArtifact, backend, text string, sizeLimit int) string {
+	var sb strings.Builder
+
+	sb.WriteString("# OCR\n\n")
+	sb.WriteString(fmt.Sprintf("**Artifact:** %s (%s, %d bytes, id %d)  \n",
+		artifact.Name, artifact.ContentType, artifact.Size, artifact.ID))
+	sb.WriteString(fmt.Sprintf("**Backend:** %s  \n", backend))
+	sb.WriteString("\n## Text\n\n")
+	sb.WriteString(formatOCRText(text, sizeLimit-sb.Len()-truncationMsgBuffer))
+
+	return sb.String()
+}
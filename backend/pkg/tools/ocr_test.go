@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+// ocrLoginFormText is the known text of the testdata/ocr_login_form.png fixture
+const ocrLoginFormText = "Sign in  \r\n\n\n\nEmail\nPassword   \n\nLog in\n"
+
+// ocrMockBackend recognizes only the images it knows
+type ocrMockBackend struct {
+	known map[string]string
+	err   error
+	calls int
+}
+
+func (m *ocrMockBackend) Name() string {
+	return "mock"
+}
+
+func (m *ocrMockBackend) Recognize(_ context.Context, image []byte) (string, error) {
+	m.calls++
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.known[string(image)], nil
+}
+
+func readOCRFixture(t *testing.T) []byte {
+	t.Helper()
+
+	image, err := os.ReadFile(filepath.Join("testdata", "ocr_login_form.png"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	return image
+}
+
+// newTestOCRTool creates the tool with the fixture saved as the artifact
+func newTestOCRTool(t *testing.T) (*ocr, *ocrMockBackend, database.FlowArtifact) {
+	t.Helper()
+
+	store, db := newTestArtifactsTool(t, 1024*1024, nil)
+	image := readOCRFixture(t)
+	artifact, err := store.store(t.Context(), "login.png", "", bytes.NewReader(image))
+	if err != nil {
+		t.Fatalf("failed to store fixture: %v", err)
+	}
+	if _, err := store.store(t.Context(), "notes.txt", "", strings.NewReader("admin:admin")); err != nil {
+		t.Fatalf("failed to store text artifact: %v", err)
+	}
+
+	cfg := *store.cfg
+	cfg.OCREnabled = true
+	backend := &ocrMockBackend{known: map[string]string{string(image): ocrLoginFormText}}
+	tool := NewOCRTool(&cfg, 1, nil, nil, db).(*ocr)
+	tool.backend = backend
+
+	return tool, backend, artifact
+}
+
+func handleOCR(t *testing.T, tool Tool, args string) string {
+	t.Helper()
+
+	got, err := tool.Handle(t.Context(), OCRToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	return got
+}
+
+func TestOCRHandle(t *testing.T) {
+	tool, backend, artifact := newTestOCRTool(t)
+
+	refs := []string{artifact.Name, fmt.Sprint(artifact.ID), FlowArtifactURL(1, artifact.ID)}
+	for _, ref := range refs {
+		t.Run(ref, func(t *testing.T) {
+			got := handleOCR(t, tool, fmt.Sprintf(`{"artifact":%q,"message":"read the form"}`, ref))
+			assertContainsAll(t, got,
+				fmt.Sprintf("**Artifact:** login.png (image/png, %d bytes, id %d)", artifact.Size, artifact.ID),
+				"**Backend:** mock",
+				"## Text\n\nSign in\n\nEmail\nPassword\n\nLog in\n",
+			)
+		})
+	}
+	if backend.calls != len(refs) {
+		t.Errorf("backend calls = %d, want %d", backend.calls, len(refs))
+	}
+}
+
+func TestOCRHandle_Errors(t *testing.T) {
+	tool, backend, _ := newTestOCRTool(t)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"unknown name", `{"artifact":"missing.png"}`, "artifact 'missing.png' not found, use the 'artifacts' tool"},
+		{"unknown id", `{"artifact":"42"}`, "artifact '42' not found"},
+		{"another flow", `{"artifact":"/api/v1/flows/2/artifacts/1/file"}`, "not found"},
+		{"not image", `{"artifact":"notes.txt"}`, "artifact 'notes.txt' isn't an image (text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handleOCR(t, tool, tt.args); !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+	if backend.calls != 0 {
+		t.Errorf("backend calls = %d, want 0", backend.calls)
+	}
+
+	backend.err = errors.New("backend is down")
+	if got := handleOCR(t, tool, `{"artifact":"login.png"}`); got != "failed to recognize text: backend is down" {
+		t.Errorf("Handle() = %q", got)
+	}
+
+	if _, err := tool.Handle(t.Context(), OCRToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+}
+
+func TestRecognizeImageText_Limit(t *testing.T) {
+	line := strings.Repeat("текст ", 10) + "\n"
+	backend := &ocrMockBackend{known: map[string]string{"big": strings.Repeat(line, 1000)}}
+
+	text, err := recognizeImageText(t.Context(), backend, []byte("big"))
+	if err != nil {
+		t.Fatalf("recognizeImageText() unexpected error: %v", err)
+	}
+	if len(text) <= ocrMaxTextLen {
+		t.Fatalf("text size = %d, want the whole text over %d", len(text), ocrMaxTextLen)
+	}
+
+	// the text is capped by ocrMaxTextLen and by the rest of the result limit
+	for _, limit := range []int{ocrMaxTextLen * 2, 4096} {
+		got := formatOCRText(text, limit)
+		want := fmt.Sprintf("[text truncated, exceeded %s limit]", formatSizeLimit(min(limit, ocrMaxTextLen)))
+		if !strings.HasPrefix(got, "текст") || !strings.Contains(got, want) || !utf8.ValidString(got) {
+			t.Errorf("formatOCRText(%d) has no notice %q", limit, want)
+		}
+		if len(got) > min(limit, ocrMaxTextLen)+len(want)+8 {
+			t.Errorf("formatOCRText(%d) size = %d", limit, len(got))
+		}
+	}
+
+	text, err = recognizeImageText(t.Context(), backend, []byte("blank"))
+	if err != nil || text != "" {
+		t.Errorf("recognizeImageText() = %q, %v", text, err)
+	}
+	if got := formatOCRText(text, ocrMaxTextLen); got != "No text is recognized on the image.\n" {
+		t.Errorf("formatOCRText() = %q", got)
+	}
+}
+
+func TestTesseractOCR(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "tesseract")
+	err := os.WriteFile(script, []byte("#!/bin/sh\n"+
+		"if [ \"$4\" = \"bad\" ]; then echo \"Failed loading language 'bad'\" >&2; exit 1; fi\n"+
+		"size=$(wc -c)\n"+
+		"printf 'args: %s\\nbytes: %s\\n' \"$*\" \"$size\"\n"), 0o755)
+	if err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	image := readOCRFixture(t)
+	backend := newOCRBackend(&config.Config{OCREnabled: true, OCRTesseractPath: script, OCRTesseractLanguages: "eng+rus"})
+	if backend == nil {
+		t.Fatal("newOCRBackend() = nil for tesseract")
+	}
+
+	got, err := backend.Recognize(t.Context(), image)
+	if err != nil {
+		t.Fatalf("Recognize() unexpected error: %v", err)
+	}
+	want := fmt.Sprintf("args: stdin stdout -l eng+rus\nbytes: %d\n", len(image))
+	if strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(want, " ", "") {
+		t.Errorf("Recognize() = %q, want %q", got, want)
+	}
+
+	backend = &tesseractOCR{path: script, languages: "bad"}
+	if _, err := backend.Recognize(t.Context(), image); err == nil ||
+		!strings.Contains(err.Error(), "Failed loading language 'bad'") {
+		t.Errorf("Recognize() error = %v", err)
+	}
+}
+
+func TestGoogleVisionOCR(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "ocr_google_vision_login.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	image := readOCRFixture(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Goog-Api-Key") != "vision-key" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"API key not valid","status":"PERMISSION_DENIED"}}`))
+			return
+		}
+
+		var body struct {
+			Requests []struct {
+				Image struct {
+					Content string `json:"content"`
+				} `json:"image"`
+			} `json:"requests"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Requests) != 1 {
+			t.Errorf("unexpected request body: %v", err)
+		}
+		if content, _ := base64.StdEncoding.DecodeString(body.Requests[0].Image.Content); !bytes.Equal(content, image) {
+			t.Error("request image isn't the fixture")
+		}
+		_, _ = w.Write(fixture)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{OCREnabled: true, OCRBackend: OCRBackendGoogleVision, OCRGoogleVisionAPIKey: "vision-key"}
+	backend, ok := newOCRBackend(cfg).(*googleVisionOCR)
+	if !ok {
+		t.Fatal("newOCRBackend() isn't google vision")
+	}
+	backend.url = server.URL
+
+	got, err := backend.Recognize(t.Context(), image)
+	if err != nil || got != "Sign in\nEmail\nPassword\nLog in\n" {
+		t.Errorf("Recognize() = %q, %v", got, err)
+	}
+
+	backend.apiKey = "wrong"
+	if _, err := backend.Recognize(t.Context(), image); err == nil ||
+		!strings.Contains(err.Error(), "vision api error 403 PERMISSION_DENIED: API key not valid") {
+		t.Errorf("Recognize() error = %v", err)
+	}
+}
+
+func TestNewOCRBackend(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{"nil config", nil},
+		{"disabled", &config.Config{OCRBackend: OCRBackendTesseract, OCRTesseractPath: "/bin/sh"}},
+		{"missing binary", &config.Config{OCREnabled: true, OCRTesseractPath: "/nonexistent/tesseract"}},
+		{"vision without key", &config.Config{OCREnabled: true, OCRBackend: OCRBackendGoogleVision}},
+		{"unknown backend", &config.Config{OCREnabled: true, OCRBackend: "easyocr"}},
+	}
+
+	for _, tt := range tests {
+		if backend := newOCRBackend(tt.cfg); backend != nil {
+			t.Errorf("%s: newOCRBackend() = %T, want nil", tt.name, backend)
+		}
+		if NewOCRTool(tt.cfg, 1, nil, nil, &artifactsMockQuerier{}).IsAvailable() {
+			t.Errorf("%s: IsAvailable() = true", tt.name)
+		}
+	}
+}
+
+func TestHeadlessBrowserScreenshotOCR(t *testing.T) {
+	server := newHeadlessBrowserServer(t)
+	tool, _ := newTestHeadlessBrowserTool(t, server)
+
+	screenshot := "\x89PNG\r\n\x1a\n" + server.URL + "/next"
+	backend := &ocrMockBackend{known: map[string]string{screenshot: ocrLoginFormText}}
+	tool.ocr = backend
+
+	args := fmt.Sprintf(`{"action":"screenshot","url":%q,"wait_ms":0}`, server.URL+"/next")
+	got := handleHeadlessBrowser(t, t.Context(), tool, args)
+	assertContainsAll(t, got, "## Screenshot\n\n", "## Screenshot Text (OCR)\n\nSign in\n\nEmail\nPassword\n\nLog in\n")
+
+	backend.err = errors.New("tesseract failed: exit status 1")
+	got = handleHeadlessBrowser(t, t.Context(), tool, args)
+	assertContainsAll(t, got, "artifact 'screenshot-127.0.0.1-", "failed to recognize text: tesseract failed: exit status 1")
+}
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// continuationArg is the argument of the paged tools which carries the token of the next page
+const continuationArg = "continuation"
+
+// continuationNotice is appended to the page which has the next one
+const continuationNotice = "\n\n**Continuation:** more results are available, repeat the call with the same arguments " +
+	"and `\"continuation\": \"%s\"` to get the next page\n"
+
+const (
+	// pagedResultsCacheNamespace keeps the rows of the scan results which don't fit the result size limit
+	pagedResultsCacheNamespace = "tools.pages"
+	// pagedResultsTTL is the time while the next pages of the scan result can be requested, the scan isn't
+	// repeated for them because it sends the same traffic to the target again
+	pagedResultsTTL = 30 * time.Minute
+)
+
+var (
+	ErrInvalidContinuation = errors.New("invalid continuation token")
+	ErrPagedResultExpired  = errors.New("result of the previous call is expired")
+)
+
+// ToolResult is the page of the tool result which is too large to be returned at once,
+// the agent requests the next page by the continuation token instead of getting one truncated blob
+type ToolResult struct {
+	Content      string
+	Continuation string // token of the next page, empty for the last page
+}
+
+// String renders the page content with the note how to get the next page
+func (r ToolResult) String() string {
+	if r.Continuation == "" {
+		return r.Content
+	}
+
+	return r.Content + fmt.Sprintf(continuationNotice, r.Continuation)
+}
+
+// continuationCursor is the position of the next page: offset in the source (e.g. API offset)
+// and count of the items of the source page which were already returned
+type continuationCursor struct {
+	Offset int `json:"o,omitempty"`
+	Skip   int `json:"s,omitempty"`
+}
+
+// continuationToken binds the cursor to the tool and its arguments, so the token isn't applied
+// to another search by mistake
+type continuationToken struct {
+	continuationCursor
+	Tool string `json:"t"`
+	Args string `json:"a"`
+}
+
+// encodeContinuation returns the opaque token of the next page of the tool call
+func encodeContinuation(name string, args json.RawMessage, cursor continuationCursor) (string, error) {
+	key, err := continuationArgsKey(args)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(continuationToken{continuationCursor: cursor, Tool: name, Args: key})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal continuation token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeContinuation returns the cursor of the token, empty token is the first page
+func decodeContinuation(name string, args json.RawMessage, token string) (continuationCursor, error) {
+	if token == "" {
+		return continuationCursor{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return continuationCursor{}, fmt.Errorf("%w: malformed token", ErrInvalidContinuation)
+	}
+
+	var ct continuationToken
+	if err := json.Unmarshal(data, &ct); err != nil {
+		return continuationCursor{}, fmt.Errorf("%w: malformed token", ErrInvalidContinuation)
+	}
+	if ct.Offset < 0 || ct.Skip < 0 {
+		return continuationCursor{}, fmt.Errorf("%w: negative position", ErrInvalidContinuation)
+	}
+	if ct.Tool != name {
+		return continuationCursor{}, fmt.Errorf("%w: token of the '%s' tool", ErrInvalidContinuation, ct.Tool)
+	}
+
+	key, err := continuationArgsKey(args)
+	if err != nil {
+		return continuationCursor{}, err
+	}
+	if ct.Args != key {
+		return continuationCursor{}, fmt.Errorf("%w: arguments are changed since the previous page", ErrInvalidContinuation)
+	}
+
+	return ct.continuationCursor, nil
+}
+
+// continuationArgsKey returns the short hash of the normalized arguments without the token itself
+func continuationArgsKey(args json.RawMessage) (string, error) {
+	var values map[string]any
+	if err := json.Unmarshal(args, &values); err != nil {
+		return "", fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+	delete(values, continuationArg)
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+
+	key, err := normalizeSearchArgs(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to normalize arguments: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// pagedRow is the line of the result table, the section is the header of the table which the row belongs to,
+// it's written before the first row of the section on every page
+type pagedRow struct {
+	Section string `json:"s,omitempty"`
+	Line    string `json:"l"`
+}
+
+// pagedResult is the rendered result of the scan tool which is split into pages by the rows,
+// it's kept in the cache by the tool call to return the next pages without repeating the scan
+type pagedResult struct {
+	Header string     `json:"h"`
+	Rows   []pagedRow `json:"r,omitempty"`
+	Kind   string     `json:"k"` // name of the rows in the truncation notice, e.g. "open ports"
+}
+
+// page renders the header and the rows from skip capped by sizeLimit, next is the first row
+// which didn't fit the page or zero if the rows are over
+func (pr pagedResult) page(skip, sizeLimit int) (content string, next int) {
+	var sb strings.Builder
+	sb.WriteString(pr.Header)
+
+	budget := NewResultBudget(sizeLimit, sb.Len())
+	section, shown := "", 0
+	for _, row := range pr.Rows[min(skip, len(pr.Rows)):] {
+		item := row.Line
+		if row.Section != section {
+			item = row.Section + row.Line
+		}
+		if !budget.Add(&sb, item) {
+			break
+		}
+		section = row.Section
+		shown++
+	}
+
+	if !budget.Truncated() {
+		return sb.String(), 0
+	}
+
+	// the row which is larger than the whole page is skipped to not request the same page again
+	sb.WriteString(budget.Notice(skip+shown, len(pr.Rows), pr.Kind))
+	return sb.String(), skip + max(shown, 1)
+}
+
+// pagedResultKey is the cache key of the rows of the tool call, the calls of the other flows don't share them
+func pagedResultKey(name string, flowID int64, args json.RawMessage) (string, error) {
+	key, err := continuationArgsKey(args)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%d:%s", name, flowID, key), nil
+}
+
+// renderPagedResult returns the page of the result from skip with the continuation token of the next page,
+// the rows are stored in the cache while the agent can request the next page
+func renderPagedResult(
+	ctx context.Context,
+	cache Cache,
+	name string,
+	flowID int64,
+	args json.RawMessage,
+	pr pagedResult,
+	skip, sizeLimit int,
+) (ToolResult, error) {
+	content, next := pr.page(skip, sizeLimit)
+	if next == 0 || next >= len(pr.Rows) || cache == nil {
+		return ToolResult{Content: content}, nil
+	}
+
+	key, err := pagedResultKey(name, flowID, args)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	if skip == 0 {
+		setCachedJSON(ctx, cache, pagedResultsCacheNamespace, key, pr, pagedResultsTTL)
+	}
+
+	token, err := encodeContinuation(name, args, continuationCursor{Skip: next})
+	if err != nil {
+		return ToolResult{}, err
+	}
+
+	return ToolResult{Content: content, Continuation: token}, nil
+}
+
+// loadPagedResult returns the rows stored by the previous call and the first row of the requested page
+func loadPagedResult(
+	ctx context.Context,
+	cache Cache,
+	name string,
+	flowID int64,
+	args json.RawMessage,
+	token string,
+) (pagedResult, int, error) {
+	cursor, err := decodeContinuation(name, args, token)
+	if err != nil {
+		return pagedResult{}, 0, err
+	}
+
+	key, err := pagedResultKey(name, flowID, args)
+	if err != nil {
+		return pagedResult{}, 0, err
+	}
+
+	var pr pagedResult
+	if !getCachedJSON(ctx, cache, pagedResultsCacheNamespace, key, &pr) {
+		return pagedResult{}, 0, ErrPagedResultExpired
+	}
+	if cursor.Skip >= len(pr.Rows) {
+		return pagedResult{}, 0, fmt.Errorf("%w: position is out of the result", ErrInvalidContinuation)
+	}
+
+	return pr, cursor.Skip, nil
+}
@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToolResultString(t *testing.T) {
+	if got := (ToolResult{Content: "page"}).String(); got != "page" {
+		t.Errorf("String() of the last page = %q", got)
+	}
+
+	got := ToolResult{Content: "page", Continuation: "abc"}.String()
+	if !strings.HasPrefix(got, "page\n\n**Continuation:**") || !strings.Contains(got, "`\"continuation\": \"abc\"`") {
+		t.Errorf("String() = %q, want the continuation note", got)
+	}
+}
+
+func TestContinuationToken(t *testing.T) {
+	args := json.RawMessage(`{"query":"nginx","max_results":5,"message":"first page"}`)
+	cursor := continuationCursor{Offset: 10, Skip: 4}
+
+	token, err := encodeContinuation(SploitusToolName, args, cursor)
+	if err != nil {
+		t.Fatalf("encodeContinuation() unexpected error: %v", err)
+	}
+
+	// the message and the token itself don't bind the page, the query is compared in normalized form
+	next := json.RawMessage(fmt.Sprintf(`{"query":" NGINX ","max_results":5,"message":"next page","continuation":%q}`, token))
+	got, err := decodeContinuation(SploitusToolName, next, token)
+	if err != nil || got != cursor {
+		t.Errorf("decodeContinuation() = %+v, %v, want %+v", got, err, cursor)
+	}
+
+	if got, err := decodeContinuation(SploitusToolName, args, ""); err != nil || got != (continuationCursor{}) {
+		t.Errorf("decodeContinuation() of the first page = %+v, %v", got, err)
+	}
+
+	tests := []struct {
+		name  string
+		tool  string
+		args  string
+		token string
+		want  string
+	}{
+		{"malformed", SploitusToolName, string(args), "!!!", "malformed token"},
+		{"not json", SploitusToolName, string(args), "bm90IGpzb24", "malformed token"},
+		{"another tool", GithubToolName, string(args), token, "token of the 'sploitus' tool"},
+		{"changed args", SploitusToolName, `{"query":"apache","max_results":5}`, token, "arguments are changed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeContinuation(tt.tool, json.RawMessage(tt.args), tt.token)
+			if !errors.Is(err, ErrInvalidContinuation) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("decodeContinuation() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagedResult(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	args := json.RawMessage(`{"target":"10.0.0.5","message":"scan"}`)
+
+	pr := pagedResult{Header: "# Report\n", Kind: "open ports"}
+	for i := range 30 {
+		section := "\n## First\n"
+		if i >= 15 {
+			section = "\n## Second\n"
+		}
+		pr.Rows = append(pr.Rows, pagedRow{Section: section, Line: fmt.Sprintf("| %02d |\n", i)})
+	}
+
+	page, err := renderPagedResult(ctx, cache, PortScanToolName, 1, args, pr, 0, truncationMsgBuffer+100)
+	if err != nil {
+		t.Fatalf("renderPagedResult() unexpected error: %v", err)
+	}
+	if page.Continuation == "" {
+		t.Fatal("renderPagedResult() of the truncated result has no continuation")
+	}
+
+	var seen []string
+	for page.Continuation != "" {
+		seen = append(seen, page.Content)
+		next := json.RawMessage(fmt.Sprintf(`{"target":"10.0.0.5","message":"next","continuation":%q}`, page.Continuation))
+		loaded, skip, err := loadPagedResult(ctx, cache, PortScanToolName, 1, next, page.Continuation)
+		if err != nil {
+			t.Fatalf("loadPagedResult() unexpected error: %v", err)
+		}
+		if page, err = renderPagedResult(ctx, cache, PortScanToolName, 1, next, loaded, skip, truncationMsgBuffer+100); err != nil {
+			t.Fatalf("renderPagedResult() unexpected error: %v", err)
+		}
+	}
+	seen = append(seen, page.Content)
+
+	// every row is shown once and every page repeats the header and the section of its first row
+	all := strings.Join(seen, "")
+	for i := range 30 {
+		if n := strings.Count(all, fmt.Sprintf("| %02d |", i)); n != 1 {
+			t.Errorf("row %d is shown %d times", i, n)
+		}
+	}
+	for i, content := range seen {
+		if !strings.HasPrefix(content, "# Report\n\n## ") {
+			t.Errorf("page %d = %q, want the header and the section first", i, content)
+		}
+	}
+
+	// the rows aren't shared with the other flows
+	if _, _, err := loadPagedResult(ctx, cache, PortScanToolName, 2, args, seen[0]); err == nil {
+		t.Error("loadPagedResult() of another flow expected error")
+	}
+	token, err := encodeContinuation(PortScanToolName, args, continuationCursor{Skip: 5})
+	if err != nil {
+		t.Fatalf("encodeContinuation() unexpected error: %v", err)
+	}
+	if _, _, err := loadPagedResult(ctx, cache, PortScanToolName, 2, args, token); !errors.Is(err, ErrPagedResultExpired) {
+		t.Errorf("loadPagedResult() of another flow error = %v, want %v", err, ErrPagedResultExpired)
+	}
+}
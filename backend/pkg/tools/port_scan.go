@@ -0,0 +1,546 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// portScanTimeout limits the whole scan, the ports which aren't probed in time are reported as skipped
+	portScanTimeout            = 2 * time.Minute
+	defaultPortScanConcurrency = 50
+	defaultPortScanPortTimeout = time.Second
+	minPortScanPortTimeout     = 100 * time.Millisecond
+	maxPortScanPortTimeout     = 10 * time.Second
+	// portScanBannerTimeout is how long the open port is read for the banner, it's capped by the port timeout
+	portScanBannerTimeout = 2 * time.Second
+	portScanMaxBannerSize = 512
+	portScanMaxBannerLen  = 120
+)
+
+// portScanCommonPorts are scanned if the ports aren't set, they are ordered by popularity
+// to keep the most useful ones when the list is cut by the ports limit
+var portScanCommonPorts = []int{
+	80, 443, 22, 21, 25, 3389, 110, 445, 139, 143, 53, 135, 3306, 8080, 23, 1723, 111, 995, 993, 5900,
+	1025, 587, 8888, 199, 1720, 465, 548, 113, 81, 6001, 10000, 514, 5060, 179, 1026, 2000, 8443, 8000,
+	32768, 554, 26, 1433, 49152, 2001, 515, 8008, 49154, 1027, 5666, 646, 5000, 5631, 631, 49153, 8081,
+	2049, 88, 79, 5800, 106, 2121, 1110, 49155, 6000, 513, 990, 5357, 427, 49156, 543, 544, 5101, 144,
+	7, 389, 8009, 3128, 444, 9999, 5009, 7070, 5190, 3000, 5432, 1900, 3986, 13, 1029, 9, 5051, 6646,
+	49157, 1028, 873, 1755, 2717, 4899, 9100, 119, 37, 6379, 27017, 9200, 11211, 5672, 9090, 2375, 6443,
+}
+
+// portScanServices are the well-known services of the ports to hint the agent
+var portScanServices = map[int]string{
+	7: "echo", 13: "daytime", 21: "ftp", 22: "ssh", 23: "telnet", 25: "smtp", 53: "dns", 79: "finger",
+	80: "http", 81: "http", 88: "kerberos", 110: "pop3", 111: "rpcbind", 113: "ident", 119: "nntp",
+	135: "msrpc", 139: "netbios-ssn", 143: "imap", 179: "bgp", 389: "ldap", 443: "https", 445: "smb",
+	465: "smtps", 513: "rlogin", 514: "rsh", 515: "printer", 548: "afp", 554: "rtsp", 587: "submission",
+	631: "ipp", 636: "ldaps", 873: "rsync", 990: "ftps", 993: "imaps", 995: "pop3s", 1433: "mssql",
+	1521: "oracle", 1723: "pptp", 1900: "upnp", 2049: "nfs", 2375: "docker", 3000: "http-alt",
+	3128: "squid-http", 3306: "mysql", 3389: "rdp", 5000: "upnp", 5060: "sip", 5432: "postgresql",
+	5672: "amqp", 5900: "vnc", 6379: "redis", 6443: "kubernetes-api", 8000: "http-alt", 8008: "http-alt",
+	8080: "http-proxy", 8081: "http-alt", 8443: "https-alt", 8888: "http-alt", 9090: "http-alt",
+	9100: "jetdirect", 9200: "elasticsearch", 10000: "webmin", 11211: "memcached", 27017: "mongodb",
+}
+
+type portScanState string
+
+const (
+	portScanStateOpen     portScanState = "open"
+	portScanStateClosed   portScanState = "closed"
+	portScanStateFiltered portScanState = "filtered"
+	portScanStateSkipped  portScanState = "skipped"
+)
+
+// portScanResult is the state of the single port, the banner is set only for the open ports
+type portScanResult struct {
+	Port   int
+	State  portScanState
+	Banner string
+}
+
+// portScanReport is the result of the scan of a single host
+type portScanReport struct {
+	Target       string
+	Address      string
+	Ports        int
+	Results      []portScanResult
+	Concurrency  int
+	Timeout      time.Duration
+	Duration     time.Duration
+	ThroughProxy bool
+}
+
+// count returns the number of the ports in the state
+func (r portScanReport) count(state portScanState) int {
+	var count int
+	for _, result := range r.Results {
+		if result.State == state {
+			count++
+		}
+	}
+	return count
+}
+
+// portScan represents the TCP connect port scan tool
+type portScan struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewPortScanTool creates a new TCP connect port scan tool instance
+func NewPortScanTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &portScan{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes a port scan request from an AI agent
+func (p *portScan) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !p.IsAvailable() {
+		return "", fmt.Errorf("port_scan is not available")
+	}
+
+	var action PortScanAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(p.flowID, p.taskID, p.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal port scan action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	host, err := normalizePortScanTarget(action.Target)
+	if err != nil {
+		return fmt.Sprintf("failed to scan ports: %v", err), nil
+	}
+
+	sizeLimit := resultSizeLimitsFromContext(ctx).MaxResultSize
+	if action.Continuation != "" {
+		paged, skip, err := loadPagedResult(ctx, p.cache, name, p.flowID, args, action.Continuation)
+		if err != nil {
+			logger.WithError(err).Warn("failed to load port scan page")
+			return fmt.Sprintf("failed to scan ports: %v: repeat the scan without continuation", err), nil
+		}
+
+		page, err := renderPagedResult(ctx, p.cache, name, p.flowID, args, paged, skip, sizeLimit)
+		if err != nil {
+			return "", fmt.Errorf("failed to render port scan page: %w", err)
+		}
+
+		return page.String(), nil
+	}
+
+	ports, err := parsePortScanPorts(action.Ports, p.cfg.PortScanMaxPorts)
+	if err != nil {
+		return fmt.Sprintf("failed to scan ports: %v", err), nil
+	}
+
+	concurrency := defaultPortScanConcurrency
+	if action.Concurrency != nil && action.Concurrency.Int() > 0 {
+		concurrency = action.Concurrency.Int()
+	}
+	if p.cfg.PortScanMaxConcurrency > 0 {
+		concurrency = min(concurrency, p.cfg.PortScanMaxConcurrency)
+	}
+	concurrency = min(concurrency, len(ports))
+
+	timeout := defaultPortScanPortTimeout
+	if action.TimeoutMs != nil && action.TimeoutMs.Int64() > 0 {
+		timeout = time.Duration(action.TimeoutMs.Int64()) * time.Millisecond
+	}
+	timeout = min(max(timeout, minPortScanPortTimeout), maxPortScanPortTimeout)
+
+	logger = logger.WithFields(logrus.Fields{
+		"host":        host,
+		"ports":       len(ports),
+		"concurrency": concurrency,
+		"timeout":     timeout.String(),
+	})
+
+	report, err := p.scan(ctx, host, ports, concurrency, timeout)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("port scan error swallowed"),
+			langfuse.WithEventInput(host),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name": PortScanToolName,
+				"engine":    "port_scan",
+				"host":      host,
+				"ports":     len(ports),
+				"error":     err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to scan ports")
+		return fmt.Sprintf("failed to scan ports: %v", err), nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"open":     report.count(portScanStateOpen),
+		"skipped":  report.count(portScanStateSkipped),
+		"duration": report.Duration.String(),
+	}).Debug("port scan finished")
+
+	page, err := renderPagedResult(ctx, p.cache, name, p.flowID, args, newPortScanPages(report), 0, sizeLimit)
+	if err != nil {
+		return "", fmt.Errorf("failed to render port scan report: %w", err)
+	}
+	result := page.String()
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && p.slp != nil {
+		_, _ = p.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypePortScan,
+			strings.TrimSpace(host+" "+action.Ports),
+			result,
+			p.taskID,
+			p.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// normalizePortScanTarget reduces the host, host:port or URL to the host, the ranges of
+// the addresses aren't supported to keep the scan of the single host within the ports limit
+func normalizePortScanTarget(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("target is empty, pass a host name or an IP address")
+	}
+
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid target url '%s': %w", target, err)
+		}
+		target = parsed.Hostname()
+	} else if host, _, err := net.SplitHostPort(target); err == nil {
+		target = host
+	}
+
+	target = strings.TrimSuffix(strings.ToLower(strings.Trim(target, "[]")), ".")
+	if target == "" {
+		return "", errors.New("target host is empty")
+	}
+	if strings.ContainsAny(target, "/ \\@") {
+		return "", fmt.Errorf("target '%s' isn't a single host, address ranges aren't supported",
+			target[:min(len(target), 200)])
+	}
+
+	return target, nil
+}
+
+// parsePortScanPorts expands the list of ports and ranges to the sorted unique ports, the common ports
+// are cut by the limit if the list is empty and the explicit list over the limit is rejected, the limit is checked
+// before the ranges are expanded so the overlapping ranges are counted in full
+func parsePortScanPorts(spec string, maxPorts int) ([]int, error) {
+	if maxPorts <= 0 || maxPorts > 65535 {
+		maxPorts = 65535
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		ports := slices.Clone(portScanCommonPorts[:min(maxPorts, len(portScanCommonPorts))])
+		slices.Sort(ports)
+		return ports, nil
+	}
+
+	type portRange struct{ from, to int }
+	var (
+		ranges []portRange
+		total  int
+	)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parsePortNumber(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parsePortNumber(to); err != nil {
+				return nil, err
+			}
+			if last < first {
+				return nil, fmt.Errorf("invalid port range '%s': start is greater than end", part)
+			}
+		}
+
+		ranges = append(ranges, portRange{first, last})
+		total += last - first + 1
+		if total > maxPorts {
+			return nil, fmt.Errorf("too many ports requested, the limit of a single scan is %d ports: "+
+				"narrow the ranges or split the scan", maxPorts)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no ports found in '%s'", spec)
+	}
+
+	seen := make(map[int]struct{}, total)
+	ports := make([]int, 0, total)
+	for _, r := range ranges {
+		for port := r.from; port <= r.to; port++ {
+			if _, ok := seen[port]; !ok {
+				seen[port] = struct{}{}
+				ports = append(ports, port)
+			}
+		}
+	}
+	slices.Sort(ports)
+
+	return ports, nil
+}
+
+func parsePortNumber(value string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port '%s': port must be a number from 1 to 65535", strings.TrimSpace(value))
+	}
+	return port, nil
+}
+
+// scan probes the ports with the limited number of parallel connections, the host name is resolved once
+// to probe the same address unless the connections go through the proxy which resolves the name itself
+func (p *portScan) scan(
+	ctx context.Context,
+	host string,
+	ports []int,
+	concurrency int,
+	timeout time.Duration,
+) (portScanReport, error) {
+	startTime := time.Now()
+	report := portScanReport{
+		Target:       host,
+		Address:      host,
+		Ports:        len(ports),
+		Results:      make([]portScanResult, len(ports)),
+		Concurrency:  concurrency,
+		Timeout:      timeout,
+		ThroughProxy: p.cfg.ProxyURL != "",
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, portScanTimeout))
+	defer cancel()
+
+	if _, err := netip.ParseAddr(host); err != nil && !report.ThroughProxy {
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return report, fmt.Errorf("failed to resolve %s: %w", host, err)
+		}
+		// IPv4 is preferred because the services are listened on it more often
+		slices.SortStableFunc(addrs, func(a, b netip.Addr) int {
+			if a.Unmap().Is4() == b.Unmap().Is4() {
+				return 0
+			} else if a.Unmap().Is4() {
+				return -1
+			}
+			return 1
+		})
+		report.Address = addrs[0].Unmap().String()
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				report.Results[idx] = p.probe(ctx, report.Address, ports[idx], timeout)
+			}
+		}()
+	}
+	for idx := range ports {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	report.Duration = time.Since(startTime)
+
+	return report, nil
+}
+
+// probe connects to the port and reads the banner which the service sends on connect,
+// the port isn't probed if the deadline of the scan is reached
+func (p *portScan) probe(ctx context.Context, address string, port int, timeout time.Duration) portScanResult {
+	result := portScanResult{Port: port, State: portScanStateSkipped}
+	if ctx.Err() != nil {
+		return result
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := dialTCP(dialCtx, p.cfg, net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case ctx.Err() != nil:
+			// the scan deadline is reached while the port was probed
+		case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+			result.State = portScanStateFiltered
+		default:
+			result.State = portScanStateClosed
+		}
+		return result
+	}
+	defer conn.Close()
+
+	result.State = portScanStateOpen
+
+	deadline := time.Now().Add(min(timeout, portScanBannerTimeout))
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	buf := make([]byte, portScanMaxBannerSize)
+	n, _ := conn.Read(buf)
+	result.Banner = sanitizePortScanBanner(buf[:n])
+
+	return result
+}
+
+// sanitizePortScanBanner keeps the printable part of the banner in a single line for the markdown table
+func sanitizePortScanBanner(data []byte) string {
+	var sb strings.Builder
+	space := false
+	for _, c := range data {
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			space = sb.Len() > 0
+			continue
+		case c < 0x20 || c > 0x7e:
+			c = '.'
+		case c == '|' || c == '`':
+			c = '\''
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteByte(c)
+	}
+
+	banner := sb.String()
+	if len(banner) > portScanMaxBannerLen {
+		banner = CutRunes(banner, portScanMaxBannerLen) + "..."
+	}
+
+	return banner
+}
+
+// formatPortScanReport renders the first page of the open ports as markdown table capped by sizeLimit
+func formatPortScanReport(report portScanReport, sizeLimit int) string {
+	content, _ := newPortScanPages(report).page(0, sizeLimit)
+	return content
+}
+
+// newPortScanPages renders the summary of the scan and the rows of the open ports table
+func newPortScanPages(report portScanReport) pagedResult {
+	open := report.count(portScanStateOpen)
+	skipped := report.count(portScanStateSkipped)
+
+	var sb strings.Builder
+	sb.WriteString("# Port Scan\n\n")
+	sb.WriteString(fmt.Sprintf("**Target:** %s  \n", report.Target))
+	if report.Address != report.Target {
+		sb.WriteString(fmt.Sprintf("**Address:** %s  \n", report.Address))
+	}
+	sb.WriteString(fmt.Sprintf("**Ports:** %d scanned: %d open, %d closed, %d filtered  \n",
+		report.Ports-skipped, open, report.count(portScanStateClosed), report.count(portScanStateFiltered)))
+	sb.WriteString(fmt.Sprintf("**Settings:** %d parallel connections, %s timeout per port  \n",
+		report.Concurrency, report.Timeout))
+	sb.WriteString(fmt.Sprintf("**Duration:** %s  \n", report.Duration.Round(time.Millisecond)))
+
+	if skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Note:** scan deadline is reached, %d ports weren't scanned\n", skipped))
+	}
+	if report.ThroughProxy {
+		sb.WriteString("\n**Note:** connections go through the proxy, so the ports reachable from the proxy " +
+			"are reported and the closed and filtered ports can't be distinguished\n")
+	}
+
+	if open == 0 {
+		sb.WriteString("\nNo open ports found.\n")
+		return pagedResult{Header: sb.String(), Kind: "open ports"}
+	}
+
+	paged := pagedResult{Kind: "open ports", Rows: make([]pagedRow, 0, open)}
+	section := "\n## Open Ports\n\n| Port | Service | Banner |\n| --- | --- | --- |\n"
+	for _, result := range report.Results {
+		if result.State != portScanStateOpen {
+			continue
+		}
+
+		service := portScanServices[result.Port]
+		if service == "" {
+			service = "unknown"
+		}
+		banner := "-"
+		if result.Banner != "" {
+			banner = "`" + result.Banner + "`"
+		}
+
+		paged.Rows = append(paged.Rows, pagedRow{
+			Section: section,
+			Line:    fmt.Sprintf("| %d/tcp | %s | %s |\n", result.Port, service, banner),
+		})
+	}
+	paged.Header = sb.String()
+
+	return paged
+}
+
+// IsAvailable returns true if the port scan is enabled
+func (p *portScan) IsAvailable() bool {
+	return p.cfg != nil && p.cfg.PortScanEnabled
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func testPortScanConfig() *config.Config {
+	return &config.Config{PortScanEnabled: true, PortScanMaxPorts: 1024, PortScanMaxConcurrency: 100}
+}
+
+// listenPortScan starts the listener which sends the banner to every connection, the empty banner keeps it silent
+func listenPortScan(t *testing.T, banner string) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if banner != "" {
+				_, _ = conn.Write([]byte(banner))
+			}
+			go func() {
+				time.Sleep(time.Second)
+				conn.Close()
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func closedPortScanPort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	return port
+}
+
+func TestPortScanHandle(t *testing.T) {
+	bannerPort := listenPortScan(t, "SSH-2.0-OpenSSH_9.6\r\n\x00|`\r\n")
+	silentPort := listenPortScan(t, "")
+	closedPort := closedPortScanPort(t)
+
+	slp := &searchLogProviderMock{}
+	tool := NewPortScanTool(testPortScanConfig(), 1, nil, nil, slp, nil)
+
+	ports := fmt.Sprintf("%d,%d,%d", bannerPort, silentPort, closedPort)
+	args := fmt.Sprintf(`{"target":"127.0.0.1","ports":%q,"timeout_ms":500,"message":"scan"}`, ports)
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypePentester)
+	got, err := tool.Handle(ctx, PortScanToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	assertContainsAll(t, got,
+		"**Target:** 127.0.0.1",
+		"**Ports:** 3 scanned: 2 open, 1 closed, 0 filtered",
+		"**Settings:** 3 parallel connections, 500ms timeout per port",
+		fmt.Sprintf("| %d/tcp | unknown | `SSH-2.0-OpenSSH_9.6 .''` |", bannerPort),
+		fmt.Sprintf("| %d/tcp | unknown | - |", silentPort),
+	)
+	if strings.Contains(got, fmt.Sprintf("| %d/tcp", closedPort)) {
+		t.Errorf("closed port %d is reported as open:\n%s", closedPort, got)
+	}
+	if strings.Contains(got, "**Address:**") || strings.Contains(got, "**Note:**") {
+		t.Errorf("unexpected address or note:\n%s", got)
+	}
+	if slp.calls != 1 || slp.engine != database.SearchengineTypePortScan || slp.query != "127.0.0.1 "+ports {
+		t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+	}
+}
+
+func TestPortScanHandle_ResolvesHost(t *testing.T) {
+	port := listenPortScan(t, "")
+	tool := NewPortScanTool(testPortScanConfig(), 1, nil, nil, nil, nil)
+
+	args := fmt.Sprintf(`{"target":"http://localhost:%d/","ports":"%d","timeout_ms":200}`, port, port)
+	got, err := tool.Handle(t.Context(), PortScanToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	assertContainsAll(t, got,
+		"**Target:** localhost",
+		"**Address:** 127.0.0.1",
+		"**Ports:** 1 scanned: 1 open, 0 closed, 0 filtered",
+	)
+}
+
+func TestPortScanHandle_Errors(t *testing.T) {
+	cfg := testPortScanConfig()
+	cfg.PortScanMaxPorts = 100
+	tool := NewPortScanTool(cfg, 1, nil, nil, nil, nil)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty target", `{"target":" "}`, "failed to scan ports: target is empty"},
+		{"address range", `{"target":"10.0.0.0/24"}`, "address ranges aren't supported"},
+		{"over budget", `{"target":"127.0.0.1","ports":"1-101"}`, "the limit of a single scan is 100 ports"},
+		{"over budget list", `{"target":"127.0.0.1","ports":"1-60,1000-1040"}`, "the limit of a single scan is 100 ports"},
+		{"invalid port", `{"target":"127.0.0.1","ports":"22,http"}`, "invalid port 'http'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.Handle(t.Context(), PortScanToolName, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.Handle(t.Context(), PortScanToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+	if NewPortScanTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+}
+
+func TestPortScanHandle_ContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	tool := NewPortScanTool(testPortScanConfig(), 1, nil, nil, nil, nil)
+	got, err := tool.Handle(ctx, PortScanToolName, []byte(`{"target":"127.0.0.1","ports":"1-200"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	assertContainsAll(t, got,
+		"**Ports:** 0 scanned: 0 open, 0 closed, 0 filtered",
+		"**Note:** scan deadline is reached, 200 ports weren't scanned",
+		"No open ports found.",
+	)
+}
+
+func TestParsePortScanPorts(t *testing.T) {
+	tests := []struct {
+		spec     string
+		maxPorts int
+		want     []int
+		wantErr  string
+	}{
+		{"22", 10, []int{22}, ""},
+		{" 443, 80,22 ,80", 10, []int{22, 80, 443}, ""},
+		{"8000-8003,8002", 5, []int{8000, 8001, 8002, 8003}, ""},
+		{"8000-8003,8002", 4, nil, "the limit of a single scan is 4 ports"},
+		{"65535", 0, []int{65535}, ""},
+		{"8000-8004", 4, nil, "the limit of a single scan is 4 ports"},
+		{"0", 10, nil, "invalid port '0'"},
+		{"70000", 10, nil, "invalid port '70000'"},
+		{"90-80", 100, nil, "start is greater than end"},
+		{"80-", 10, nil, "invalid port ''"},
+		{" , ", 10, nil, "no ports found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parsePortScanPorts(tt.spec, tt.maxPorts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parsePortScanPorts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePortScanPorts() unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parsePortScanPorts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	common, err := parsePortScanPorts("", 5)
+	if err != nil {
+		t.Fatalf("parsePortScanPorts() unexpected error: %v", err)
+	}
+	if !slices.Equal(common, []int{21, 22, 25, 80, 443}) {
+		t.Errorf("common ports cut by the limit = %v", common)
+	}
+	if all, _ := parsePortScanPorts("", 1024); len(all) != len(portScanCommonPorts) || !slices.IsSorted(all) {
+		t.Errorf("common ports = %d sorted %v, want %d", len(all), slices.IsSorted(all), len(portScanCommonPorts))
+	}
+}
+
+func TestNormalizePortScanTarget(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.5":                  "10.0.0.5",
+		"App.Example.com.":          "app.example.com",
+		"app.example.com:8443":      "app.example.com",
+		"https://app.example.com/x": "app.example.com",
+		"[2001:db8::1]:22":          "2001:db8::1",
+		"2001:db8::1":               "2001:db8::1",
+		"ssh://user@10.0.0.7:2222/": "10.0.0.7",
+	}
+
+	for target, want := range tests {
+		got, err := normalizePortScanTarget(target)
+		if err != nil {
+			t.Errorf("normalizePortScanTarget(%q) unexpected error: %v", target, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("normalizePortScanTarget(%q) = %q, want %q", target, got, want)
+		}
+	}
+
+	for _, target := range []string{"", "10.0.0.0/24", "a b", "user@host", "https:///path"} {
+		if _, err := normalizePortScanTarget(target); err == nil {
+			t.Errorf("normalizePortScanTarget(%q) expected error", target)
+		}
+	}
+}
+
+func TestFormatPortScanReport_SizeLimit(t *testing.T) {
+	report := portScanReport{Target: "10.0.0.5", Address: "10.0.0.5", Ports: 5000, Timeout: time.Second}
+	for port := 1; port <= report.Ports; port++ {
+		report.Results = append(report.Results, portScanResult{
+			Port:   port,
+			State:  portScanStateOpen,
+			Banner: strings.Repeat("x", portScanMaxBannerLen),
+		})
+	}
+
+	got := formatPortScanReport(report, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("report size = %d, want <= %d", len(got), DefaultMaxResultSize)
+	}
+	assertContainsAll(t, got, "**Ports:** 5000 scanned: 5000 open", "of 5000 open ports (output truncated, exceeded 80 KB limit)")
+}
@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"github.com/vxcontrol/cloud/anonymizer/patterns"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	highEntropySecretName = "High Entropy String"
+	highEntropyMinLength  = 32
+)
+
+// defaultRedactPatterns match the common formats of the secrets, the "replace" group (or the first one)
+// is replaced if the pattern has groups, otherwise the whole match is replaced
+var defaultRedactPatterns = []patterns.Pattern{
+	{Name: "AWS Access Key", Regex: `\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[0-9A-Z]{16}\b`},
+	{Name: "AWS Secret Key", Regex: `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<replace>[A-Za-z0-9/+=]{40})`},
+	{Name: "Private Key", Regex: `-----BEGIN[A-Z ]*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?(?:-----END[A-Z ]*PRIVATE KEY(?: BLOCK)?-----|\z)`},
+	{Name: "Bearer Token", Regex: `(?i)\bbearer\s+(?P<replace>[A-Za-z0-9\-._~+/]{8,}=*)`},
+	{Name: "Basic Auth", Regex: `(?i)\bauthorization["']?\s*[:=]\s*["']?basic\s+(?P<replace>[A-Za-z0-9+/]{4,}={0,2})`},
+	{Name: "JWT", Regex: `\beyJ[A-Za-z0-9_\-]{8,}\.eyJ[A-Za-z0-9_\-]{8,}\.[A-Za-z0-9_\-]{8,}`},
+	{Name: "GitHub Token", Regex: `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`},
+	{Name: "GitLab Token", Regex: `\bglpat-[A-Za-z0-9_\-]{20,}\b`},
+	{Name: "Slack Token", Regex: `\bxox[abposr]-[A-Za-z0-9\-]{10,}\b`},
+	{Name: "Google API Key", Regex: `\bAIza[0-9A-Za-z_\-]{35}\b`},
+	{Name: "LLM API Key", Regex: `\bsk-(?:proj-|ant-)?[A-Za-z0-9_\-]{20,}\b`},
+	{Name: "Stripe Key", Regex: `\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b`},
+	{Name: "URL Credentials", Regex: `[A-Za-z][A-Za-z0-9+.\-]*://[^:/@\s]+:(?P<replace>[^@/\s]+)@`},
+	{Name: "Password", Regex: `(?i)(?:\b|_)(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)["']?\s*[:=]\s*["']?(?P<replace>[^\s"'&,;]{4,})`},
+}
+
+// sensitiveArgRegexp matches the names of the tool call arguments which values are redacted as a whole
+var sensitiveArgRegexp = regexp.MustCompile(`(?i)^(?:password|passwd|passphrase|secret|token|api[_-]?key|` +
+	`access[_-]?token|auth[_-]?token|client[_-]?secret|private[_-]?key|authorization|cookie)$`)
+
+var highEntropyCandidateRegexp = regexp.MustCompile(`[A-Za-z0-9+_\-]{32,}={0,2}`)
+
+type redactPattern struct {
+	name  string
+	regex *regexp.Regexp
+	group int
+}
+
+// SecretRedactor masks the secrets in the tool arguments and results before they are logged, the results
+// returned to the agent are masked only if it's enabled explicitly because the agent may need the found
+// credentials, the secret is replaced by the marker with the pattern name, e.g. "[REDACTED:AWS Access Key]"
+type SecretRedactor struct {
+	patterns      []redactPattern
+	minEntropy    float64
+	redactResults bool
+}
+
+// NewSecretRedactor builds the redactor from the default patterns, the patterns of the file and the values
+// of the PentAGI secrets from the config, nil redactor is returned if the redaction is disabled
+func NewSecretRedactor(cfg *config.Config) (*SecretRedactor, error) {
+	if cfg == nil || !cfg.ToolRedactEnabled {
+		return nil, nil
+	}
+
+	pts := append([]patterns.Pattern{}, defaultRedactPatterns...)
+	if cfg.ToolRedactPatternsFile != "" {
+		data, err := os.ReadFile(cfg.ToolRedactPatternsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redaction patterns file: %w", err)
+		}
+
+		var filePatterns patterns.Patterns
+		if err := yaml.Unmarshal(data, &filePatterns); err != nil {
+			return nil, fmt.Errorf("failed to parse redaction patterns file: %w", err)
+		}
+		pts = append(pts, filePatterns.Patterns...)
+	}
+	pts = append(pts, cfg.GetSecretPatterns()...)
+
+	redactor, err := newSecretRedactor(pts, cfg.ToolRedactMinEntropy)
+	if err != nil {
+		return nil, err
+	}
+	redactor.redactResults = cfg.ToolRedactResults
+
+	return redactor, nil
+}
+
+func newSecretRedactor(pts []patterns.Pattern, minEntropy float64) (*SecretRedactor, error) {
+	redactor := &SecretRedactor{
+		patterns:   make([]redactPattern, 0, len(pts)),
+		minEntropy: minEntropy,
+	}
+
+	for _, pt := range pts {
+		regex, err := regexp.Compile(pt.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile redaction pattern '%s': %w", pt.Name, err)
+		}
+
+		group := regex.SubexpIndex("replace")
+		if group == -1 && regex.NumSubexp() > 0 {
+			group = 1
+		}
+		redactor.patterns = append(redactor.patterns, redactPattern{name: pt.Name, regex: regex, group: group})
+	}
+
+	return redactor, nil
+}
+
+// Redact replaces the secrets in the text, nil redactor returns the text as is
+func (r *SecretRedactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+
+	for _, pt := range r.patterns {
+		text = pt.replace(text)
+	}
+
+	if r.minEntropy > 0 {
+		marker := fmt.Sprintf(redactedSecretTemplate, highEntropySecretName)
+		text = highEntropyCandidateRegexp.ReplaceAllStringFunc(text, func(candidate string) string {
+			if isHighEntropySecret(candidate, r.minEntropy) {
+				return marker
+			}
+			return candidate
+		})
+	}
+
+	return text
+}
+
+// defaultSecretRedactor is built once from the default patterns for the texts outside of the flow tools
+var defaultSecretRedactor = sync.OnceValue(func() *SecretRedactor {
+	redactor, _ := newSecretRedactor(defaultRedactPatterns, 0)
+	return redactor
+})
+
+// RedactDefaultSecrets replaces the secrets matched by the default patterns, e.g. in upstream error messages
+func RedactDefaultSecrets(text string) string {
+	return defaultSecretRedactor().Redact(text)
+}
+
+// RedactResult replaces the secrets in the tool result which is returned to the agent if it's enabled
+func (r *SecretRedactor) RedactResult(text string) string {
+	if r == nil || !r.redactResults {
+		return text
+	}
+
+	return r.Redact(text)
+}
+
+// RedactArgs returns the copy of the tool call arguments with the redacted string values, values of the
+// sensitive arguments (e.g. "password") are replaced as a whole; invalid JSON is returned as is
+func (r *SecretRedactor) RedactArgs(args json.RawMessage) json.RawMessage {
+	if r == nil || len(args) == 0 {
+		return args
+	}
+
+	var values any
+	if err := json.Unmarshal(args, &values); err != nil {
+		return args
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.redactValue("", values)); err != nil {
+		return args
+	}
+
+	return json.RawMessage(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// WrapSearchLogProvider returns the provider which redacts the queries and results before they are logged
+func (r *SecretRedactor) WrapSearchLogProvider(slp SearchLogProvider) SearchLogProvider {
+	if r == nil || slp == nil {
+		return slp
+	}
+
+	return &redactedSearchLogProvider{slp: slp, redactor: r}
+}
+
+func (r *SecretRedactor) redactValue(key string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for k, item := range v {
+			redacted[k] = r.redactValue(k, item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(key, item)
+		}
+		return redacted
+	case string:
+		if v != "" && sensitiveArgRegexp.MatchString(key) {
+			return fmt.Sprintf(redactedSecretTemplate, key)
+		}
+		return r.Redact(v)
+	default:
+		return v
+	}
+}
+
+func (pt redactPattern) replace(text string) string {
+	matches := pt.regex.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var sb strings.Builder
+	marker := fmt.Sprintf(redactedSecretTemplate, pt.name)
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if pt.group > 0 && match[2*pt.group] >= 0 {
+			start, end = match[2*pt.group], match[2*pt.group+1]
+		}
+
+		sb.WriteString(text[last:start])
+		sb.WriteString(marker)
+		last = end
+	}
+	sb.WriteString(text[last:])
+
+	return sb.String()
+}
+
+// isHighEntropySecret checks the random looking token: it must mix upper and lower case letters and digits
+// and have Shannon entropy per character not less than the threshold, so hex hashes and words are kept
+func isHighEntropySecret(token string, minEntropy float64) bool {
+	token = strings.TrimRight(token, "=")
+	if len(token) < highEntropyMinLength {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	freq := make(map[rune]int, len(token))
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+		freq[c]++
+	}
+	if !hasUpper || !hasLower || !hasDigit {
+		return false
+	}
+
+	var entropy float64
+	size := float64(len(token))
+	for _, count := range freq {
+		p := float64(count) / size
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy >= minEntropy
+}
+
+type redactedSearchLogProvider struct {
+	slp      SearchLogProvider
+	redactor *SecretRedactor
+}
+
+func (p *redactedSearchLogProvider) PutLog(
+	ctx context.Context,
+	initiator database.MsgchainType,
+	executor database.MsgchainType,
+	engine database.SearchengineType,
+	query string,
+	result string,
+	taskID *int64,
+	subtaskID *int64,
+) (int64, error) {
+	return p.slp.PutLog(ctx, initiator, executor, engine,
+		p.redactor.Redact(query), p.redactor.Redact(result), taskID, subtaskID)
+}
@@ -21,6 +21,7 @@ const (
 	MemoristToolName          = "memorist"
 	MemoristResultToolName    = "memorist_result"
 	BrowserToolName           = "browser"
+	HeadlessBrowserToolName   = "headless_browser"
 	GoogleToolName            = "google"
 	DuckDuckGoToolName        = "duckduckgo"
 	TavilyToolName            = "tavily"
@@ -28,6 +29,18 @@ const (
 	PerplexityToolName        = "perplexity"
 	SearxngToolName           = "searxng"
 	SploitusToolName          = "sploitus"
+	GithubToolName            = "github"
+	ExploitDBToolName         = "exploitdb"
+	ExploitSearchToolName     = "exploit_search"
+	VirusTotalToolName        = "virustotal"
+	WaybackToolName           = "wayback"
+	WhoisToolName             = "whois"
+	EPSSToolName              = "epss"
+	RobotsToolName            = "robots"
+	HTTPHeadersToolName       = "http_headers"
+	TLSInspectToolName        = "tls_inspect"
+	PortScanToolName          = "port_scan"
+	HTTPFuzzToolName          = "http_fuzz"
 	SearchToolName            = "search"
 	SearchResultToolName      = "search_result"
 	EnricherResultToolName    = "enricher_result"
@@ -44,6 +57,10 @@ const (
 	SubtaskPatchToolName      = "subtask_patch"
 	TerminalToolName          = "terminal"
 	FileToolName              = "file"
+	AttachmentsToolName       = "attachments"
+	FlowMemoryToolName        = "flow_memory"
+	ArtifactsToolName         = "artifacts"
+	OCRToolName               = "ocr"
 )
 
 type ToolType int
@@ -101,6 +118,7 @@ var toolsTypeMapping = map[string]ToolType{
 	MemoristToolName:          AgentToolType,
 	MemoristResultToolName:    StoreAgentResultToolType,
 	BrowserToolName:           SearchNetworkToolType,
+	HeadlessBrowserToolName:   SearchNetworkToolType,
 	GoogleToolName:            SearchNetworkToolType,
 	DuckDuckGoToolName:        SearchNetworkToolType,
 	TavilyToolName:            SearchNetworkToolType,
@@ -108,6 +126,18 @@ var toolsTypeMapping = map[string]ToolType{
 	PerplexityToolName:        SearchNetworkToolType,
 	SearxngToolName:           SearchNetworkToolType,
 	SploitusToolName:          SearchNetworkToolType,
+	GithubToolName:            SearchNetworkToolType,
+	ExploitDBToolName:         SearchNetworkToolType,
+	ExploitSearchToolName:     SearchNetworkToolType,
+	VirusTotalToolName:        SearchNetworkToolType,
+	WaybackToolName:           SearchNetworkToolType,
+	WhoisToolName:             SearchNetworkToolType,
+	EPSSToolName:              SearchNetworkToolType,
+	RobotsToolName:            SearchNetworkToolType,
+	HTTPHeadersToolName:       SearchNetworkToolType,
+	TLSInspectToolName:        SearchNetworkToolType,
+	PortScanToolName:          SearchNetworkToolType,
+	HTTPFuzzToolName:          SearchNetworkToolType,
 	SearchToolName:            AgentToolType,
 	SearchResultToolName:      StoreAgentResultToolType,
 	EnricherResultToolName:    StoreAgentResultToolType,
@@ -124,6 +154,10 @@ var toolsTypeMapping = map[string]ToolType{
 	SubtaskPatchToolName:      StoreAgentResultToolType,
 	TerminalToolName:          EnvironmentToolType,
 	FileToolName:              EnvironmentToolType,
+	AttachmentsToolName:       EnvironmentToolType,
+	FlowMemoryToolName:        EnvironmentToolType,
+	ArtifactsToolName:         EnvironmentToolType,
+	OCRToolName:               EnvironmentToolType,
 }
 
 var reflector = &jsonschema.Reflector{
@@ -134,6 +168,7 @@ var reflector = &jsonschema.Reflector{
 var allowedSummarizingToolsResult = []string{
 	TerminalToolName,
 	BrowserToolName,
+	HeadlessBrowserToolName,
 }
 
 var allowedStoringInMemoryTools = []string{
@@ -147,6 +182,18 @@ var allowedStoringInMemoryTools = []string{
 	PerplexityToolName,
 	SearxngToolName,
 	SploitusToolName,
+	GithubToolName,
+	ExploitDBToolName,
+	ExploitSearchToolName,
+	VirusTotalToolName,
+	WaybackToolName,
+	WhoisToolName,
+	EPSSToolName,
+	RobotsToolName,
+	HTTPHeadersToolName,
+	TLSInspectToolName,
+	PortScanToolName,
+	HTTPFuzzToolName,
 	MaintenanceToolName,
 	CoderToolName,
 	PentesterToolName,
@@ -165,6 +212,30 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 		Description: "Modifies or reads local files",
 		Parameters:  reflector.Reflect(&FileAction{}),
 	},
+	AttachmentsToolName: {
+		Name: AttachmentsToolName,
+		Description: "Lists and reads the files attached by the user to the flow like target lists, scanner exports " +
+			"or scope documents, the files are also available in the container by the listed paths",
+		Parameters: reflector.Reflect(&AttachmentsAction{}),
+	},
+	FlowMemoryToolName: {
+		Name: FlowMemoryToolName,
+		Description: "Stores and finds the structured facts of the flow like discovered hosts, credentials or " +
+			"confirmed vulnerabilities by the key and tags, the facts are shared between all agents of the flow",
+		Parameters: reflector.Reflect(&FlowMemoryAction{}),
+	},
+	ArtifactsToolName: {
+		Name: ArtifactsToolName,
+		Description: "Saves and reads the persistent outputs of the flow like scan results, scripts or screenshots, " +
+			"the artifacts are kept after the container is removed and are available to the user",
+		Parameters: reflector.Reflect(&ArtifactsAction{}),
+	},
+	OCRToolName: {
+		Name: OCRToolName,
+		Description: "Recognizes the text on the image artifact like the screenshot of the page, " +
+			"use it to read the text which is rendered into the images and isn't available as the page text",
+		Parameters: reflector.Reflect(&OCRAction{}),
+	},
 	ReportResultToolName: {
 		Name:        ReportResultToolName,
 		Description: "Send the report result to the user with execution status and description",
@@ -200,6 +271,13 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 		Description: "Opens a browser to look for additional information from the web site",
 		Parameters:  reflector.Reflect(&Browser{}),
 	},
+	HeadlessBrowserToolName: {
+		Name: HeadlessBrowserToolName,
+		Description: "Opens the page in a real headless browser which runs the scripts of the page, use it for " +
+			"JavaScript-heavy sites and single page applications where the plain browser gets an empty page, " +
+			"to click the elements, to extract the rendered content and to take the screenshots",
+		Parameters: reflector.Reflect(&HeadlessBrowserAction{}),
+	},
 	GoogleToolName: {
 		Name: GoogleToolName,
 		Description: "Search in the google search engine, it's a fast query and the shortest content " +
@@ -216,7 +294,7 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 		Name: TavilyToolName,
 		Description: "Search in the tavily search engine, it's a more complex query and more detailed content " +
 			"with answer by query and detailed information from the web sites",
-		Parameters: reflector.Reflect(&SearchAction{}),
+		Parameters: reflector.Reflect(&TavilyAction{}),
 	},
 	TraversaalToolName: {
 		Name: TraversaalToolName,
@@ -235,7 +313,7 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 		Description: "Search in the searxng meta search engine, it's a privacy-focused search engine " +
 			"that aggregates results from multiple search engines with customizable categories, " +
 			"language settings, and safety filters",
-		Parameters: reflector.Reflect(&SearchAction{}),
+		Parameters: reflector.Reflect(&SearxngAction{}),
 	},
 	SploitusToolName: {
 		Name: SploitusToolName,
@@ -243,9 +321,106 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 			"proof-of-concept code, and offensive security tools. Sploitus indexes ExploitDB, Packet Storm, " +
 			"GitHub Security Advisories, and many other sources. Use this tool to find exploit code and PoCs " +
 			"for specific software, services, CVEs, or vulnerability classes (e.g. 'ssh', 'apache log4j', " +
-			"'CVE-2021-44228'). Returns exploit URLs, CVSS scores, CVE references, and publication dates.",
+			"'CVE-2021-44228'). Returns exploit URLs, CVSS scores, CVE references, and publication dates. " +
+			"Use exploit_type 'all' to get both exploits and tools in one call.",
 		Parameters: reflector.Reflect(&SploitusAction{}),
 	},
+	GithubToolName: {
+		Name: GithubToolName,
+		Description: "Search GitHub (https://github.com) for proof-of-concept code and exploits by CVE ID or " +
+			"keyword. Use search_type 'repositories' to find PoC repositories with stars and last update date, " +
+			"or 'code' to find specific exploit files with raw URLs which can be downloaded in the terminal. " +
+			"Supports filtering by programming language and sorting repositories by stars or update date.",
+		Parameters: reflector.Reflect(&GithubAction{}),
+	},
+	ExploitDBToolName: {
+		Name: ExploitDBToolName,
+		Description: "Search the local offline copy of ExploitDB (https://www.exploit-db.com) for public exploits " +
+			"by title keywords or CVE ID, it works without internet access. Returns EDB ID, file path in the " +
+			"exploitdb repository, platform, type and publication date for each exploit. All query terms must " +
+			"match, so prefer short queries like product name with version or a single CVE ID.",
+		Parameters: reflector.Reflect(&ExploitDBAction{}),
+	},
+	ExploitSearchToolName: {
+		Name: ExploitSearchToolName,
+		Description: "Search public exploits and PoCs in all configured exploit sources at once (Sploitus, " +
+			"GitHub and offline ExploitDB) by CVE ID or product name with version. Results are merged, " +
+			"duplicates across sources are collapsed with links to alternate sources, every hit is annotated " +
+			"with its source. Unavailable or failed sources are reported in the result without aborting the search.",
+		Parameters: reflector.Reflect(&ExploitSearchAction{}),
+	},
+	VirusTotalToolName: {
+		Name: VirusTotalToolName,
+		Description: "Check the reputation of a file by its MD5, SHA-1 or SHA-256 hash or of an URL in VirusTotal " +
+			"(https://www.virustotal.com). Returns detection ratio, verdicts of the engines which flagged the " +
+			"resource, threat label and first and last seen dates. Use it for suspicious binaries downloaded " +
+			"during the flow (compute the hash in the terminal first) and for suspicious links; files unknown " +
+			"to VirusTotal are reported as not found, which doesn't mean they are safe.",
+		Parameters: reflector.Reflect(&VirusTotalAction{}),
+	},
+	WaybackToolName: {
+		Name: WaybackToolName,
+		Description: "Get the capture history of an URL or a whole domain from the Wayback Machine " +
+			"(https://web.archive.org) for reconnaissance. Returns a timeline of snapshots with timestamp, " +
+			"HTTP status, MIME type and archive URL which can be opened to see the old content; consecutive " +
+			"near-identical captures are collapsed into one entry. Use match_type 'prefix' or 'domain' to " +
+			"discover forgotten paths, old endpoints and files, and the date range to focus on a period.",
+		Parameters: reflector.Reflect(&WaybackAction{}),
+	},
+	WhoisToolName: {
+		Name: WhoisToolName,
+		Description: "Lookup registration data of a domain or an IP address via RDAP with fallback to WHOIS " +
+			"for attribution and scoping. Returns registrant, registrar, creation, update and expiry dates, " +
+			"status, nameservers and abuse contacts for domains, and network range, CIDR, owner organization, " +
+			"country and abuse contacts for IP addresses. Personal data is often redacted by registries.",
+		Parameters: reflector.Reflect(&WhoisAction{}),
+	},
+	EPSSToolName: {
+		Name: EPSSToolName,
+		Description: "Get EPSS (Exploit Prediction Scoring System by FIRST) scores of one or more CVE IDs to " +
+			"prioritize found vulnerabilities by real-world exploitation likelihood. Returns probability of " +
+			"exploitation activity in the next 30 days and its percentile for each CVE ordered from the most " +
+			"likely exploited one. Pass all found CVEs in one call instead of calling the tool for each CVE.",
+		Parameters: reflector.Reflect(&EPSSAction{}),
+	},
+	RobotsToolName: {
+		Name: RobotsToolName,
+		Description: "Fetch robots.txt and sitemaps of the target site before crawling it. Returns disallow/allow " +
+			"rules and crawl delays per user agent, the list of sitemaps including nested sitemap index files " +
+			"and the flattened list of URLs found in sitemaps. Disallowed paths often point to admin panels, " +
+			"backups and other hidden content worth checking.",
+		Parameters: reflector.Reflect(&RobotsAction{}),
+	},
+	HTTPHeadersToolName: {
+		Name: HTTPHeadersToolName,
+		Description: "Send a request to the target URL and analyze its response headers. Returns present, weak and " +
+			"missing security headers (CSP, HSTS, X-Frame-Options, X-Content-Type-Options, Referrer-Policy, CORS and " +
+			"others) with severity notes, cookie flags, headers disclosing server software and the raw response " +
+			"headers with redacted session cookies. Can follow redirects to show the whole redirect chain.",
+		Parameters: reflector.Reflect(&HTTPHeadersAction{}),
+	},
+	TLSInspectToolName: {
+		Name: TLSInspectToolName,
+		Description: "Connect to a TLS service and inspect its configuration. Returns the negotiated protocol, cipher " +
+			"suite and ALPN, support of deprecated TLS 1.0/1.1, the certificate chain with subjects, issuers, " +
+			"validity, SANs and fingerprints, and flags expired, self-signed, untrusted or mismatched certificates, " +
+			"weak keys and weak cipher suites. Works with any TLS port, not only HTTPS.",
+		Parameters: reflector.Reflect(&TLSInspectAction{}),
+	},
+	PortScanToolName: {
+		Name: PortScanToolName,
+		Description: "Scan TCP ports of a single host with full connect probes and return the open ports with " +
+			"the guessed services and the banners which the services send on connect. Use it for the fast port " +
+			"discovery of the in-scope target, the number of ports of a single scan is limited by the server.",
+		Parameters: reflector.Reflect(&PortScanAction{}),
+	},
+	HTTPFuzzToolName: {
+		Name: HTTPFuzzToolName,
+		Description: "Discover the content of the web site by requesting the paths from the wordlist in parallel " +
+			"with the rate limit and return the found endpoints grouped by the status code. Use it to find hidden " +
+			"directories, files, backups and API endpoints of the in-scope target, the number of requests is limited by the server.",
+		Parameters: reflector.Reflect(&HTTPFuzzAction{}),
+	},
 	EnricherResultToolName: {
 		Name:        EnricherResultToolName,
 		Description: "Send the enriched user's question with additional information to the user",
@@ -378,13 +553,14 @@ func getMessageType(name string) database.MsglogType {
 	switch name {
 	case TerminalToolName:
 		return database.MsglogTypeTerminal
-	case FileToolName:
+	case FileToolName, AttachmentsToolName, ArtifactsToolName, OCRToolName:
 		return database.MsglogTypeFile
-	case BrowserToolName:
+	case BrowserToolName, HeadlessBrowserToolName:
 		return database.MsglogTypeBrowser
 	case MemoristToolName, SearchToolName, GoogleToolName, DuckDuckGoToolName, TavilyToolName, TraversaalToolName,
-		PerplexityToolName, SearxngToolName, SploitusToolName,
-		SearchGuideToolName, SearchAnswerToolName, SearchCodeToolName, SearchInMemoryToolName, GraphitiSearchToolName:
+		PerplexityToolName, SearxngToolName, SploitusToolName, GithubToolName, ExploitDBToolName, ExploitSearchToolName,
+		VirusTotalToolName, WaybackToolName, WhoisToolName, EPSSToolName, RobotsToolName, HTTPHeadersToolName, TLSInspectToolName, PortScanToolName, HTTPFuzzToolName, SearchGuideToolName, SearchAnswerToolName, SearchCodeToolName, SearchInMemoryToolName,
+		GraphitiSearchToolName:
 		return database.MsglogTypeSearch
 	case AdviceToolName:
 		return database.MsglogTypeAdvice
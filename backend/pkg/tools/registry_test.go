@@ -51,6 +51,19 @@ func TestGetToolType(t *testing.T) {
 		{name: "browser", toolName: BrowserToolName, want: SearchNetworkToolType},
 		{name: "perplexity", toolName: PerplexityToolName, want: SearchNetworkToolType},
 		{name: "sploitus", toolName: SploitusToolName, want: SearchNetworkToolType},
+		{name: "github", toolName: GithubToolName, want: SearchNetworkToolType},
+		{name: "exploitdb", toolName: ExploitDBToolName, want: SearchNetworkToolType},
+		{name: "exploit_search", toolName: ExploitSearchToolName, want: SearchNetworkToolType},
+		{name: "virustotal", toolName: VirusTotalToolName, want: SearchNetworkToolType},
+		{name: "wayback", toolName: WaybackToolName, want: SearchNetworkToolType},
+		{name: "whois", toolName: WhoisToolName, want: SearchNetworkToolType},
+		{name: "epss", toolName: EPSSToolName, want: SearchNetworkToolType},
+		{name: "robots", toolName: RobotsToolName, want: SearchNetworkToolType},
+		{name: "http_headers", toolName: HTTPHeadersToolName, want: SearchNetworkToolType},
+		{name: "tls_inspect", toolName: TLSInspectToolName, want: SearchNetworkToolType},
+		{name: "port_scan", toolName: PortScanToolName, want: SearchNetworkToolType},
+		{name: "http_fuzz", toolName: HTTPFuzzToolName, want: SearchNetworkToolType},
+		{name: "headless_browser", toolName: HeadlessBrowserToolName, want: SearchNetworkToolType},
 		{name: "search_in_memory", toolName: SearchInMemoryToolName, want: SearchVectorDbToolType},
 		{name: "graphiti_search", toolName: GraphitiSearchToolName, want: SearchVectorDbToolType},
 		{name: "search agent", toolName: SearchToolName, want: AgentToolType},
@@ -61,6 +74,9 @@ func TestGetToolType(t *testing.T) {
 		{name: "ask barrier", toolName: AskUserToolName, want: BarrierToolType},
 		{name: "code_result", toolName: CodeResultToolName, want: StoreAgentResultToolType},
 		{name: "store_guide", toolName: StoreGuideToolName, want: StoreVectorDbToolType},
+		{name: "flow_memory", toolName: FlowMemoryToolName, want: EnvironmentToolType},
+		{name: "artifacts", toolName: ArtifactsToolName, want: EnvironmentToolType},
+		{name: "ocr", toolName: OCRToolName, want: EnvironmentToolType},
 		{name: "unknown tool", toolName: "nonexistent_tool", want: NoneToolType},
 		{name: "empty string", toolName: "", want: NoneToolType},
 	}
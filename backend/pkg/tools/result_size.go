@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"pentagi/pkg/config"
+)
+
+const (
+	// DefaultMaxResultSize is the total output limit of the tools which render the results themselves
+	DefaultMaxResultSize = 80 * 1024
+	// DefaultMaxSourceSize is the limit of the single source field of the result, e.g. exploit code
+	DefaultMaxSourceSize = 50 * 1024
+
+	// minResultSize keeps the room for the result header and the truncation notes
+	minResultSize = 4 * 1024
+)
+
+type resultSizeLimitsContextKey struct{}
+
+// ResultSizeLimits caps the tool results of the flow, zero value of the field uses the default
+type ResultSizeLimits struct {
+	MaxResultSize int // bytes of the whole tool result
+	MaxSourceSize int // bytes of the single source field, it's never greater than the whole result
+}
+
+// resolve returns the limits with the defaults applied to the unset fields
+func (l ResultSizeLimits) resolve() ResultSizeLimits {
+	if l.MaxResultSize <= 0 {
+		l.MaxResultSize = DefaultMaxResultSize
+	}
+	l.MaxResultSize = max(l.MaxResultSize, minResultSize)
+
+	if l.MaxSourceSize <= 0 {
+		l.MaxSourceSize = DefaultMaxSourceSize
+	}
+	l.MaxSourceSize = min(l.MaxSourceSize, l.MaxResultSize)
+
+	return l
+}
+
+// GlobalResultSizeLimits returns the result limits which are set in the config for all flows
+func GlobalResultSizeLimits(cfg *config.Config) ResultSizeLimits {
+	if cfg == nil {
+		return ResultSizeLimits{}.resolve()
+	}
+
+	return ResultSizeLimits{
+		MaxResultSize: cfg.ResultMaxSize,
+		MaxSourceSize: cfg.ResultMaxSourceSize,
+	}.resolve()
+}
+
+// isDefault returns true if the resolved limits are the same as the defaults
+func (l ResultSizeLimits) isDefault() bool {
+	return l.resolve() == ResultSizeLimits{}.resolve()
+}
+
+// withResultSizeLimits returns the context which carries the result limits of the flow to the tools
+func withResultSizeLimits(ctx context.Context, limits ResultSizeLimits) context.Context {
+	return context.WithValue(ctx, resultSizeLimitsContextKey{}, limits.resolve())
+}
+
+// resultSizeLimitsFromContext returns the result limits of the flow or the defaults
+func resultSizeLimitsFromContext(ctx context.Context) ResultSizeLimits {
+	if limits, ok := ctx.Value(resultSizeLimitsContextKey{}).(ResultSizeLimits); ok {
+		return limits
+	}
+	return ResultSizeLimits{}.resolve()
+}
+
+// formatSizeLimit renders the limit for the truncation notes, e.g. "80 KB"
+func formatSizeLimit(size int) string {
+	if size%1024 == 0 {
+		return fmt.Sprintf("%d KB", size/1024)
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
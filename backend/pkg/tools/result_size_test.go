@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+)
+
+func TestResultSizeLimitsResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits ResultSizeLimits
+		want   ResultSizeLimits
+	}{
+		{"defaults", ResultSizeLimits{}, ResultSizeLimits{DefaultMaxResultSize, DefaultMaxSourceSize}},
+		{"raised", ResultSizeLimits{200 * 1024, 120 * 1024}, ResultSizeLimits{200 * 1024, 120 * 1024}},
+		{"lowered", ResultSizeLimits{8 * 1024, 2 * 1024}, ResultSizeLimits{8 * 1024, 2 * 1024}},
+		{"below minimum", ResultSizeLimits{100, 0}, ResultSizeLimits{minResultSize, minResultSize}},
+		{"source over total", ResultSizeLimits{16 * 1024, 0}, ResultSizeLimits{16 * 1024, 16 * 1024}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.resolve(); got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := GlobalResultSizeLimits(&config.Config{ResultMaxSize: 8192}); got != (ResultSizeLimits{8192, 8192}) {
+		t.Errorf("GlobalResultSizeLimits() = %+v", got)
+	}
+	if got := resultSizeLimitsFromContext(t.Context()); !got.isDefault() {
+		t.Errorf("resultSizeLimitsFromContext() without limits = %+v", got)
+	}
+	if got := formatSizeLimit(8192); got != "8 KB" {
+		t.Errorf("formatSizeLimit(8192) = %q", got)
+	}
+	if got := formatSizeLimit(5000); got != "5000 bytes" {
+		t.Errorf("formatSizeLimit(5000) = %q", got)
+	}
+}
+
+func newSizedSploitusResponse(count, sourceSize int) sploitusResponse {
+	resp := sploitusResponse{Exploits: make([]sploitusExploit, count), ExploitsTotal: count}
+	for i := range resp.Exploits {
+		resp.Exploits[i] = sploitusExploit{
+			ID:     fmt.Sprintf("TEST-%d", i),
+			Title:  fmt.Sprintf("Test Result %d", i),
+			Href:   "https://example.com",
+			Source: strings.Repeat("X", sourceSize),
+		}
+	}
+	return resp
+}
+
+func TestSploitusResultSizeLimits(t *testing.T) {
+	defaults := ResultSizeLimits{}.resolve()
+	many := newSizedSploitusResponse(100, 5000)
+	defaultCount := strings.Count(formatSploitusResults("test", sploitusTypeExploits, 100, many, defaults), "### ")
+
+	t.Run("raised", func(t *testing.T) {
+		limits := ResultSizeLimits{MaxResultSize: 200 * 1024, MaxSourceSize: 120 * 1024}
+
+		// the source over the default cap is returned as is
+		result := formatSploitusResults("test", sploitusTypeExploits, 1, newSizedSploitusResponse(1, 100*1024), limits)
+		if strings.Contains(result, "source truncated") || !strings.Contains(result, strings.Repeat("X", 100*1024)) {
+			t.Error("source under the raised limit is truncated")
+		}
+		if len(result) <= DefaultMaxResultSize || len(result) > limits.MaxResultSize {
+			t.Errorf("result size = %d, want in (%d, %d]", len(result), DefaultMaxResultSize, limits.MaxResultSize)
+		}
+
+		result = formatSploitusResults("test", sploitusTypeExploits, 100, many, limits)
+		if len(result) > limits.MaxResultSize {
+			t.Errorf("result size = %d exceeds %d", len(result), limits.MaxResultSize)
+		}
+		if count := strings.Count(result, "### "); count <= defaultCount {
+			t.Errorf("shown %d results, want more than %d of the default limit", count, defaultCount)
+		}
+	})
+
+	t.Run("lowered", func(t *testing.T) {
+		limits := ResultSizeLimits{MaxResultSize: 8 * 1024, MaxSourceSize: 2 * 1024}
+
+		result := formatSploitusResults("test", sploitusTypeExploits, 1, newSizedSploitusResponse(1, 4*1024), limits)
+		if !strings.Contains(result, "[source truncated, exceeded 2 KB limit]") {
+			t.Error("result missing the source truncation note of the lowered limit")
+		}
+		if strings.Contains(result, strings.Repeat("X", 2*1024+1)) {
+			t.Error("source is longer than the lowered limit")
+		}
+
+		result = formatSploitusResults("test", sploitusTypeExploits, 100, many, limits)
+		if len(result) > limits.MaxResultSize {
+			t.Errorf("result size = %d exceeds %d", len(result), limits.MaxResultSize)
+		}
+		if !strings.Contains(result, "Results truncated") {
+			t.Error("result missing the truncation warning")
+		}
+		if count := strings.Count(result, "### "); count == 0 || count >= defaultCount {
+			t.Errorf("shown %d results, want fewer than %d of the default limit", count, defaultCount)
+		}
+	})
+}
+
+func TestWaybackResultSizeLimits(t *testing.T) {
+	captures := make([]waybackCapture, maxWaybackLimit)
+	for i := range captures {
+		captures[i] = waybackCapture{
+			Timestamp:  "20200101000000",
+			Original:   fmt.Sprintf("https://example.com/%d/%s", i, strings.Repeat("a", 500)),
+			MimeType:   "text/html",
+			StatusCode: "200",
+			Digest:     strings.Repeat("D", 32),
+		}
+	}
+
+	for _, sizeLimit := range []int{8 * 1024, DefaultMaxResultSize, 200 * 1024} {
+		t.Run(formatSizeLimit(sizeLimit), func(t *testing.T) {
+			got := formatWaybackResults("example.com", waybackMatchPrefix, "", "", maxWaybackLimit, captures, sizeLimit)
+			if len(got) > sizeLimit {
+				t.Errorf("len(result) = %d, want <= %d", len(got), sizeLimit)
+			}
+			if !strings.Contains(got, fmt.Sprintf("exceeded %s limit", formatSizeLimit(sizeLimit))) {
+				t.Error("result missing the truncation note with the limit")
+			}
+		})
+	}
+}
+
+func TestExecuteResultSizeLimits(t *testing.T) {
+	var got ResultSizeLimits
+	ce := &customExecutor{
+		flowID:     1,
+		db:         &timeoutMockQuerier{},
+		resultSize: ResultSizeLimits{MaxResultSize: 16 * 1024},
+		handlers: map[string]ExecutorHandler{
+			WhoisToolName: func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+				got = resultSizeLimitsFromContext(ctx)
+				return "done", nil
+			},
+		},
+	}
+
+	if _, err := ce.Execute(t.Context(), 0, "call-1", WhoisToolName, "", "", json.RawMessage(`{"target":"example.org"}`)); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+	if want := (ResultSizeLimits{16 * 1024, 16 * 1024}); got != want {
+		t.Errorf("tool limits = %+v, want %+v", got, want)
+	}
+}
+
+func TestSearchCacheResultSizeLimits(t *testing.T) {
+	sc, _, _ := newTestSearchCache(t, &config.Config{SearchCacheTTL: 60})
+	h := &countingHandler{result: "results"}
+	handler := sc.Wrap(TavilyToolName, h.handle)
+	args := json.RawMessage(`{"query":"CVE-2024-1234 PoC","max_results":5}`)
+
+	lowered := withResultSizeLimits(t.Context(), ResultSizeLimits{MaxResultSize: 8 * 1024})
+	explicitDefault := withResultSizeLimits(t.Context(), ResultSizeLimits{})
+	for _, ctx := range []context.Context{t.Context(), explicitDefault, lowered, lowered} {
+		if _, err := handler(ctx, TavilyToolName, args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the flows with the default limits share the result, the flow with own limits has its own one
+	if h.calls != 2 {
+		t.Errorf("handler called %d times, want 2", h.calls)
+	}
+}
@@ -0,0 +1,595 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	robotsRequestTimeout  = 30 * time.Second
+	robotsCacheNamespace  = "robots"
+	robotsCacheTTL        = time.Hour
+	robotsUserAgent       = "PentAGI/1.0"
+	defaultRobotsURLLimit = 100
+	maxRobotsURLLimit     = 1000
+
+	// robotsMaxSitemapDepth is the max nesting level of sitemap index files, the sitemaps from
+	// robots.txt are on the level 0
+	robotsMaxSitemapDepth = 3
+	// robotsMaxSitemaps is the max number of sitemap files requested for one target
+	robotsMaxSitemaps = 25
+	// robotsMaxBodySize is the max size of robots.txt or a single uncompressed sitemap file
+	robotsMaxBodySize = 10 * 1024 * 1024
+	// robotsMaxRedirects is the same limit as the default redirect policy of net/http client
+	robotsMaxRedirects = 10
+)
+
+// robotsGroup is a group of rules of robots.txt for the listed user agents
+type robotsGroup struct {
+	UserAgents []string `json:"user_agents"`
+	Allow      []string `json:"allow,omitempty"`
+	Disallow   []string `json:"disallow,omitempty"`
+	CrawlDelay string   `json:"crawl_delay,omitempty"`
+}
+
+// robotsTxt is the parsed robots.txt file, sitemaps are global and don't belong to any group
+type robotsTxt struct {
+	Groups   []robotsGroup `json:"groups,omitempty"`
+	Sitemaps []string      `json:"sitemaps,omitempty"`
+}
+
+// sitemapEntry is a page of urlset or a nested sitemap of sitemapindex
+type sitemapEntry struct {
+	Loc     string `json:"loc" xml:"loc"`
+	LastMod string `json:"lastmod,omitempty" xml:"lastmod"`
+}
+
+// sitemapDocument is the parsed sitemap file, Index is true for sitemap index files
+// which contain only links to other sitemaps
+type sitemapDocument struct {
+	Index    bool
+	URLs     []sitemapEntry
+	Sitemaps []sitemapEntry
+}
+
+type sitemapXML struct {
+	XMLName  xml.Name
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// robotsSitemap is the result of requesting a single sitemap file
+type robotsSitemap struct {
+	URL     string `json:"url"`
+	Depth   int    `json:"depth"`
+	Index   bool   `json:"index,omitempty"`
+	Entries int    `json:"entries"`
+	Error   string `json:"error,omitempty"`
+}
+
+// robotsSummary is everything discovered for the target, it's kept in the shared cache
+type robotsSummary struct {
+	BaseURL      string          `json:"base_url"`
+	RobotsStatus int             `json:"robots_status"`
+	Robots       robotsTxt       `json:"robots"`
+	Sitemaps     []robotsSitemap `json:"sitemaps,omitempty"`
+	URLs         []sitemapEntry  `json:"urls,omitempty"`
+	TotalURLs    int             `json:"total_urls"`
+	Skipped      int             `json:"skipped_sitemaps,omitempty"`
+}
+
+// robots represents the robots.txt and sitemap discovery tool
+type robots struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewRobotsTool creates a new robots.txt and sitemap discovery tool instance
+func NewRobotsTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &robots{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes a robots.txt and sitemap discovery request from an AI agent
+func (r *robots) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !r.IsAvailable() {
+		return "", fmt.Errorf("robots is not available")
+	}
+
+	var action RobotsAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(r.flowID, r.taskID, r.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal robots action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	baseURL, err := normalizeRobotsBaseURL(action.URL)
+	if err != nil {
+		return fmt.Sprintf("failed to discover robots.txt and sitemaps: %v", err), nil
+	}
+
+	limit := action.MaxURLs.Int()
+	if limit < 1 || limit > maxRobotsURLLimit {
+		limit = defaultRobotsURLLimit
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"base_url": baseURL,
+		"limit":    limit,
+	})
+
+	summary, err := r.discover(ctx, baseURL)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("robots discovery error swallowed"),
+			langfuse.WithEventInput(baseURL),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name": RobotsToolName,
+				"engine":    "robots",
+				"base_url":  baseURL,
+				"limit":     limit,
+				"error":     err.Error(),
+			}),
+		)
+
+		logger.WithError(err).Error("failed to discover robots.txt and sitemaps")
+		return fmt.Sprintf("failed to discover robots.txt and sitemaps: %v", err), nil
+	}
+
+	result := formatRobotsSummary(summary, limit, resultSizeLimitsFromContext(ctx).MaxResultSize)
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && r.slp != nil {
+		_, _ = r.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeRobots,
+			baseURL,
+			result,
+			r.taskID,
+			r.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// normalizeRobotsBaseURL reduces the URL or host to scheme and host because robots.txt
+// is defined only for the root of the origin, https is used if the scheme is omitted
+func normalizeRobotsBaseURL(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("url is empty")
+	}
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid url '%s': %w", target, err)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme '%s', use http or https", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid url '%s': host is empty", target)
+	}
+
+	return scheme + "://" + strings.ToLower(parsed.Host), nil
+}
+
+// discover requests robots.txt and walks the sitemaps referenced by it or the default
+// /sitemap.xml if robots.txt has none; sitemap errors are reported in the summary only
+func (r *robots) discover(ctx context.Context, baseURL string) (robotsSummary, error) {
+	var summary robotsSummary
+	if getCachedJSON(ctx, r.cache, robotsCacheNamespace, baseURL, &summary) {
+		return summary, nil
+	}
+
+	client, err := system.GetHTTPClient(r.cfg)
+	if err != nil {
+		return summary, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = robotsRequestTimeout
+	if guard := scopeGuardFromContext(ctx); !guard.IsEmpty() {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= robotsMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", robotsMaxRedirects)
+			}
+			if reason := guard.CheckURL(req.URL.String()); reason != "" {
+				return errors.New(reason)
+			}
+			return nil
+		}
+	}
+
+	summary.BaseURL = baseURL
+	status, body, err := r.get(ctx, client, baseURL+"/robots.txt")
+	if err != nil {
+		return summary, err
+	}
+
+	summary.RobotsStatus = status
+	if status == http.StatusOK {
+		summary.Robots = parseRobotsTxt(bytes.NewReader(body))
+	}
+
+	type queued struct {
+		url   string
+		depth int
+	}
+
+	var queue []queued
+	for _, loc := range summary.Robots.Sitemaps {
+		queue = append(queue, queued{url: loc})
+	}
+	if len(queue) == 0 {
+		queue = append(queue, queued{url: baseURL + "/sitemap.xml"})
+	}
+
+	visited := make(map[string]struct{})
+	for len(queue) > 0 && len(summary.Sitemaps) < robotsMaxSitemaps {
+		item := queue[0]
+		queue = queue[1:]
+
+		if _, ok := visited[item.url]; ok {
+			continue
+		}
+		visited[item.url] = struct{}{}
+
+		sitemap := robotsSitemap{URL: item.url, Depth: item.depth}
+		doc, err := r.fetchSitemap(ctx, client, item.url)
+		if err != nil {
+			sitemap.Error = err.Error()
+			summary.Sitemaps = append(summary.Sitemaps, sitemap)
+			continue
+		}
+
+		sitemap.Index = doc.Index
+		if doc.Index {
+			sitemap.Entries = len(doc.Sitemaps)
+			if item.depth < robotsMaxSitemapDepth {
+				for _, nested := range doc.Sitemaps {
+					queue = append(queue, queued{url: nested.Loc, depth: item.depth + 1})
+				}
+			} else {
+				sitemap.Error = fmt.Sprintf("nested sitemaps are skipped, max depth %d is reached", robotsMaxSitemapDepth)
+			}
+		} else {
+			sitemap.Entries = len(doc.URLs)
+			summary.TotalURLs += len(doc.URLs)
+			free := maxRobotsURLLimit - len(summary.URLs)
+			summary.URLs = append(summary.URLs, doc.URLs[:min(free, len(doc.URLs))]...)
+		}
+
+		summary.Sitemaps = append(summary.Sitemaps, sitemap)
+	}
+	summary.Skipped = len(queue)
+
+	setCachedJSON(ctx, r.cache, robotsCacheNamespace, baseURL, summary, robotsCacheTTL)
+
+	return summary, nil
+}
+
+// get requests the resource and returns its status and body, only network errors are returned
+// as errors because missing robots.txt or sitemaps are valid results of the discovery
+func (r *robots) get(ctx context.Context, client *http.Client, target string) (int, []byte, error) {
+	// sitemaps can be hosted outside the flow scope
+	if reason := scopeGuardFromContext(ctx).CheckURL(target); reason != "" {
+		return 0, nil, errors.New(reason)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", robotsUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("request to %s failed: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil, nil
+	}
+
+	body, err := readRobotsBody(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+func (r *robots) fetchSitemap(ctx context.Context, client *http.Client, target string) (sitemapDocument, error) {
+	status, body, err := r.get(ctx, client, target)
+	if err != nil {
+		return sitemapDocument{}, err
+	}
+	if status != http.StatusOK {
+		return sitemapDocument{}, fmt.Errorf("HTTP %d", status)
+	}
+
+	return parseSitemap(bytes.NewReader(body))
+}
+
+func readRobotsBody(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, robotsMaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > robotsMaxBodySize {
+		return nil, fmt.Errorf("response exceeds %d MB", robotsMaxBodySize/1024/1024)
+	}
+
+	return body, nil
+}
+
+// parseRobotsTxt parses robots.txt by the rules of RFC 9309: consecutive user-agent lines
+// start a group, rules before the first user-agent are ignored and sitemaps are global
+func parseRobotsTxt(r io.Reader) robotsTxt {
+	var result robotsTxt
+	var group *robotsGroup
+	groupHasRules := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), robotsMaxBodySize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if group == nil || groupHasRules {
+				result.Groups = append(result.Groups, robotsGroup{})
+				group = &result.Groups[len(result.Groups)-1]
+				groupHasRules = false
+			}
+			if value != "" {
+				group.UserAgents = append(group.UserAgents, value)
+			}
+		case "allow", "disallow", "crawl-delay":
+			if group == nil {
+				continue
+			}
+			groupHasRules = true
+			switch {
+			case value == "":
+				// empty rule doesn't restrict anything
+			case key == "allow":
+				group.Allow = append(group.Allow, value)
+			case key == "disallow":
+				group.Disallow = append(group.Disallow, value)
+			default:
+				group.CrawlDelay = value
+			}
+		case "sitemap":
+			if value != "" && !slices.Contains(result.Sitemaps, value) {
+				result.Sitemaps = append(result.Sitemaps, value)
+			}
+		}
+	}
+
+	return result
+}
+
+// parseSitemap parses XML urlset or sitemapindex, gzipped files and plain text sitemaps
+// with one URL per line
+func parseSitemap(r io.Reader) (sitemapDocument, error) {
+	body, err := readRobotsBody(r)
+	if err != nil {
+		return sitemapDocument{}, err
+	}
+
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return sitemapDocument{}, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		defer gz.Close()
+
+		if body, err = readRobotsBody(gz); err != nil {
+			return sitemapDocument{}, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+	}
+
+	body = bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if len(body) == 0 {
+		return sitemapDocument{}, errors.New("sitemap is empty")
+	}
+
+	if body[0] != '<' {
+		var doc sitemapDocument
+		for line := range strings.Lines(string(body)) {
+			if loc := strings.TrimSpace(line); strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+				doc.URLs = append(doc.URLs, sitemapEntry{Loc: loc})
+			}
+		}
+		if len(doc.URLs) == 0 {
+			return sitemapDocument{}, errors.New("sitemap is neither XML nor a list of URLs")
+		}
+		return doc, nil
+	}
+
+	var parsed sitemapXML
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return sitemapDocument{}, fmt.Errorf("failed to decode sitemap: %w", err)
+	}
+
+	doc := sitemapDocument{}
+	switch parsed.XMLName.Local {
+	case "urlset":
+		doc.URLs = trimSitemapEntries(parsed.URLs)
+	case "sitemapindex":
+		doc.Index = true
+		doc.Sitemaps = trimSitemapEntries(parsed.Sitemaps)
+	default:
+		return sitemapDocument{}, fmt.Errorf("unexpected sitemap root element <%s>", parsed.XMLName.Local)
+	}
+
+	return doc, nil
+}
+
+func trimSitemapEntries(entries []sitemapEntry) []sitemapEntry {
+	result := make([]sitemapEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry.Loc = strings.TrimSpace(entry.Loc)
+		entry.LastMod = strings.TrimSpace(entry.LastMod)
+		if entry.Loc != "" {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// formatRobotsSummary renders robots.txt rules, requested sitemaps and the flattened list
+// of sitemap URLs capped by limit and sizeLimit
+func formatRobotsSummary(summary robotsSummary, limit, sizeLimit int) string {
+	var sb strings.Builder
+	sb.WriteString("# robots.txt and Sitemaps\n\n")
+	sb.WriteString(fmt.Sprintf("**Base URL:** %s  \n", summary.BaseURL))
+
+	sb.WriteString("\n## robots.txt\n\n")
+	switch {
+	case summary.RobotsStatus != http.StatusOK:
+		sb.WriteString(fmt.Sprintf("robots.txt is not available (HTTP %d)\n", summary.RobotsStatus))
+	case len(summary.Robots.Groups) == 0:
+		sb.WriteString("robots.txt has no rules\n")
+	default:
+		for _, group := range summary.Robots.Groups {
+			sb.WriteString(fmt.Sprintf("### User-agent: %s\n\n", strings.Join(group.UserAgents, ", ")))
+			if len(group.Disallow) == 0 && len(group.Allow) == 0 {
+				sb.WriteString("- no restrictions\n")
+			}
+			for _, path := range group.Disallow {
+				sb.WriteString(fmt.Sprintf("- Disallow: `%s`\n", path))
+			}
+			for _, path := range group.Allow {
+				sb.WriteString(fmt.Sprintf("- Allow: `%s`\n", path))
+			}
+			if group.CrawlDelay != "" {
+				sb.WriteString(fmt.Sprintf("- Crawl-delay: %s\n", group.CrawlDelay))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n## Sitemaps\n\n")
+	if len(summary.Robots.Sitemaps) == 0 {
+		sb.WriteString("robots.txt doesn't reference sitemaps, the default location was checked\n\n")
+	}
+	for _, sitemap := range summary.Sitemaps {
+		indent := strings.Repeat("  ", sitemap.Depth)
+		kind := "urlset"
+		if sitemap.Index {
+			kind = "index"
+		}
+		switch {
+		case sitemap.Error != "" && sitemap.Entries == 0:
+			sb.WriteString(fmt.Sprintf("%s- %s: %s\n", indent, sitemap.URL, sitemap.Error))
+		case sitemap.Error != "":
+			sb.WriteString(fmt.Sprintf("%s- %s (%s, %d entries): %s\n", indent, sitemap.URL, kind, sitemap.Entries, sitemap.Error))
+		default:
+			sb.WriteString(fmt.Sprintf("%s- %s (%s, %d entries)\n", indent, sitemap.URL, kind, sitemap.Entries))
+		}
+	}
+	if summary.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Note:** %d more sitemaps are skipped, only %d sitemap files are requested\n",
+			summary.Skipped, robotsMaxSitemaps))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Sitemap URLs (%d found)\n\n", summary.TotalURLs))
+	if len(summary.URLs) == 0 {
+		sb.WriteString("No URLs found in sitemaps\n")
+		return sb.String()
+	}
+
+	shown := min(limit, len(summary.URLs))
+	budget := NewResultBudget(sizeLimit, sb.Len())
+	for _, entry := range summary.URLs[:shown] {
+		line := fmt.Sprintf("- %s\n", entry.Loc)
+		if entry.LastMod != "" {
+			line = fmt.Sprintf("- %s (lastmod %s)\n", entry.Loc, entry.LastMod)
+		}
+
+		if !budget.Add(&sb, line) {
+			sb.WriteString(budget.Notice(budget.Added(), summary.TotalURLs, "URLs"))
+			return sb.String()
+		}
+	}
+
+	switch {
+	case shown < len(summary.URLs):
+		sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d URLs, increase max_urls (up to %d) to see more\n",
+			shown, summary.TotalURLs, maxRobotsURLLimit))
+	case shown < summary.TotalURLs:
+		sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d URLs, only first %d URLs of sitemaps are kept\n",
+			shown, summary.TotalURLs, maxRobotsURLLimit))
+	}
+
+	return sb.String()
+}
+
+// IsAvailable returns true if the robots.txt and sitemap discovery tool is enabled
+func (r *robots) IsAvailable() bool {
+	return r.cfg != nil && r.cfg.RobotsEnabled
+}
@@ -0,0 +1,338 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func readRobotsTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	return body
+}
+
+func TestParseRobotsTxt(t *testing.T) {
+	got := parseRobotsTxt(bytes.NewReader(readRobotsTestdata(t, "robots_example.txt")))
+
+	wantGroups := []robotsGroup{
+		{
+			UserAgents: []string{"Googlebot", "Bingbot"},
+			Allow:      []string{"/private/press/"},
+			Disallow:   []string{"/private/"},
+			CrawlDelay: "5",
+		},
+		{
+			UserAgents: []string{"*"},
+			Allow:      []string{"/admin/public/"},
+			Disallow:   []string{"/admin/", "/backup/"},
+		},
+		{
+			UserAgents: []string{"BadBot"},
+			Disallow:   []string{"/"},
+		},
+	}
+	if !reflect.DeepEqual(got.Groups, wantGroups) {
+		t.Errorf("Groups = %+v, want %+v", got.Groups, wantGroups)
+	}
+
+	wantSitemaps := []string{"https://example.com/sitemap_index.xml", "https://example.com/sitemap-news.txt"}
+	if !reflect.DeepEqual(got.Sitemaps, wantSitemaps) {
+		t.Errorf("Sitemaps = %q, want %q", got.Sitemaps, wantSitemaps)
+	}
+}
+
+func TestParseSitemap(t *testing.T) {
+	t.Run("index", func(t *testing.T) {
+		doc, err := parseSitemap(bytes.NewReader(readRobotsTestdata(t, "robots_sitemap_index.xml")))
+		if err != nil {
+			t.Fatalf("parseSitemap() unexpected error: %v", err)
+		}
+
+		want := []sitemapEntry{
+			{Loc: "https://example.com/sitemap-pages.xml", LastMod: "2026-10-01T08:00:00+00:00"},
+			{Loc: "https://example.com/sitemap-archive.xml"},
+		}
+		if !doc.Index || len(doc.URLs) != 0 || !reflect.DeepEqual(doc.Sitemaps, want) {
+			t.Errorf("parseSitemap() = %+v, want index with %+v", doc, want)
+		}
+	})
+
+	t.Run("urlset", func(t *testing.T) {
+		doc, err := parseSitemap(bytes.NewReader(readRobotsTestdata(t, "robots_sitemap_pages.xml")))
+		if err != nil {
+			t.Fatalf("parseSitemap() unexpected error: %v", err)
+		}
+
+		want := []sitemapEntry{
+			{Loc: "https://example.com/", LastMod: "2026-10-01"},
+			{Loc: "https://example.com/login"},
+			{Loc: "https://example.com/api/v2/docs", LastMod: "2026-09-15"},
+		}
+		if doc.Index || !reflect.DeepEqual(doc.URLs, want) {
+			t.Errorf("parseSitemap() = %+v, want urlset with %+v", doc, want)
+		}
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(readRobotsTestdata(t, "robots_sitemap_pages.xml"))
+		gz.Close()
+
+		doc, err := parseSitemap(&buf)
+		if err != nil {
+			t.Fatalf("parseSitemap() unexpected error: %v", err)
+		}
+		if len(doc.URLs) != 3 {
+			t.Errorf("len(URLs) = %d, want 3", len(doc.URLs))
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		doc, err := parseSitemap(strings.NewReader("https://example.com/news/1\n\n  https://example.com/news/2  \nnot a url\n"))
+		if err != nil {
+			t.Fatalf("parseSitemap() unexpected error: %v", err)
+		}
+		want := []sitemapEntry{{Loc: "https://example.com/news/1"}, {Loc: "https://example.com/news/2"}}
+		if !reflect.DeepEqual(doc.URLs, want) {
+			t.Errorf("URLs = %+v, want %+v", doc.URLs, want)
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", "  \n", "sitemap is empty"},
+		{"html", "<html><body>Not found</body></html>", "unexpected sitemap root element <html>"},
+		{"broken xml", "<urlset><url><loc>https://example.com/", "failed to decode sitemap"},
+		{"garbage", "Not found", "neither XML nor a list of URLs"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSitemap(strings.NewReader(tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseSitemap() error = %v, want to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRobotsBaseURL(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "example.com", want: "https://example.com"},
+		{input: " HTTP://Example.com:8080/admin/?q=1 ", want: "http://example.com:8080"},
+		{input: "https://example.com/robots.txt", want: "https://example.com"},
+		{input: "", wantErr: true},
+		{input: "ftp://example.com", wantErr: true},
+		{input: "https:///path", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := normalizeRobotsBaseURL(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeRobotsBaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeRobotsBaseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsIsAvailable(t *testing.T) {
+	if NewRobotsTool(nil, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true with nil config")
+	}
+	if NewRobotsTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+	if !NewRobotsTool(&config.Config{RobotsEnabled: true}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = false when enabled")
+	}
+}
+
+func newRobotsTestTool(t *testing.T, mux http.Handler, slp SearchLogProvider) Tool {
+	t.Helper()
+
+	proxy, err := newTestProxy("example.com", mux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	t.Cleanup(func() { proxy.Close() })
+
+	return NewRobotsTool(&config.Config{
+		RobotsEnabled:     true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, slp, NewMemoryCache(10))
+}
+
+func TestRobotsHandle(t *testing.T) {
+	var mx sync.Mutex
+	requests := make(map[string]int)
+	serve := func(body []byte) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mx.Lock()
+			requests[r.URL.Path]++
+			mx.Unlock()
+			w.WriteHeader(http.StatusOK)
+			w.Write(body)
+		}
+	}
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/robots.txt", serve(readRobotsTestdata(t, "robots_example.txt")))
+	mockMux.HandleFunc("/sitemap_index.xml", serve(readRobotsTestdata(t, "robots_sitemap_index.xml")))
+	mockMux.HandleFunc("/sitemap-pages.xml", serve(readRobotsTestdata(t, "robots_sitemap_pages.xml")))
+	mockMux.HandleFunc("/sitemap-news.txt", serve([]byte("https://example.com/news/1\nhttps://example.com/news/2\n")))
+	mockMux.HandleFunc("/sitemap-archive.xml", func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		requests[r.URL.Path]++
+		mx.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	slp := &searchLogProviderMock{}
+	tool := newRobotsTestTool(t, mockMux, slp)
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	for _, target := range []string{"example.com/some/page", "https://EXAMPLE.com"} {
+		got, err := tool.Handle(ctx, RobotsToolName, []byte(fmt.Sprintf(`{"url":%q,"max_urls":4,"message":"discover"}`, target)))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+
+		for _, want := range []string{
+			"**Base URL:** https://example.com",
+			"### User-agent: Googlebot, Bingbot",
+			"- Disallow: `/admin/`",
+			"- Allow: `/admin/public/`",
+			"- Crawl-delay: 5",
+			"- https://example.com/sitemap_index.xml (index, 2 entries)",
+			"  - https://example.com/sitemap-pages.xml (urlset, 3 entries)",
+			"  - https://example.com/sitemap-archive.xml: HTTP 404",
+			"- https://example.com/sitemap-news.txt (urlset, 2 entries)",
+			"## Sitemap URLs (5 found)",
+			"- https://example.com/ (lastmod 2026-10-01)",
+			"**Note:** Showing 4 of 5 URLs, increase max_urls",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("result missing %q:\n%s", want, got)
+			}
+		}
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+	for _, path := range []string{"/robots.txt", "/sitemap_index.xml", "/sitemap-pages.xml", "/sitemap-archive.xml", "/sitemap-news.txt"} {
+		if requests[path] != 1 {
+			t.Errorf("requests of %s = %d, want 1 with the second result from cache", path, requests[path])
+		}
+	}
+	if slp.calls != 2 || slp.engine != database.SearchengineTypeRobots || slp.query != "https://example.com" {
+		t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+	}
+}
+
+func TestRobotsHandle_NestedDepthLimit(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mockMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var level int
+		if r.URL.Path != "/sitemap.xml" {
+			if _, err := fmt.Sscanf(r.URL.Path, "/nested-%d.xml", &level); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `<sitemapindex><sitemap><loc>https://example.com/nested-%d.xml</loc></sitemap></sitemapindex>`, level+1)
+	})
+
+	tool := newRobotsTestTool(t, mockMux, nil)
+
+	got, err := tool.Handle(t.Context(), RobotsToolName, []byte(`{"url":"example.com","max_urls":10}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"robots.txt is not available (HTTP 404)",
+		"robots.txt doesn't reference sitemaps, the default location was checked",
+		"- https://example.com/sitemap.xml (index, 1 entries)",
+		"      - https://example.com/nested-3.xml (index, 1 entries): nested sitemaps are skipped, max depth 3 is reached",
+		"No URLs found in sitemaps",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "nested-4.xml") {
+		t.Errorf("sitemap deeper than the limit was requested:\n%s", got)
+	}
+}
+
+func TestRobotsHandle_Errors(t *testing.T) {
+	tool := NewRobotsTool(&config.Config{RobotsEnabled: true}, 1, nil, nil, nil, NewMemoryCache(10))
+
+	got, err := tool.Handle(t.Context(), RobotsToolName, []byte(`{"url":"ftp://example.com","max_urls":10}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "failed to discover robots.txt and sitemaps: unsupported url scheme") {
+		t.Errorf("Handle() = %q, want swallowed validation error", got)
+	}
+
+	if _, err := tool.Handle(t.Context(), RobotsToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+}
+
+func TestFormatRobotsSummary_SizeLimit(t *testing.T) {
+	summary := robotsSummary{BaseURL: "https://example.com", RobotsStatus: http.StatusOK}
+	for i := range maxRobotsURLLimit {
+		summary.URLs = append(summary.URLs, sitemapEntry{
+			Loc: fmt.Sprintf("https://example.com/%s/%d", strings.Repeat("a", 200), i),
+		})
+	}
+	summary.TotalURLs = 5000
+
+	got := formatRobotsSummary(summary, maxRobotsURLLimit, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("len(result) = %d, want <= %d", len(got), DefaultMaxResultSize)
+	}
+	if !strings.Contains(got, "URLs (output truncated, exceeded 80 KB limit)") {
+		t.Errorf("result missing truncation note")
+	}
+
+	summary.URLs = summary.URLs[:10]
+	got = formatRobotsSummary(summary, maxRobotsURLLimit, DefaultMaxResultSize)
+	if !strings.Contains(got, "Showing 10 of 5000 URLs, only first 1000 URLs of sitemaps are kept") {
+		t.Errorf("result missing collection cap note:\n%s", got[strings.LastIndex(got, "\n\n"):])
+	}
+}
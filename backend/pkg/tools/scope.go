@@ -0,0 +1,415 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"pentagi/pkg/database"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/idna"
+)
+
+const (
+	flowScopeMaxTargets      = 1000
+	flowScopeMaxReasonList   = 10
+	flowScopeAuditAction     = "flow.scope_denied"
+	flowScopeWildcardPrefix  = "*."
+	flowScopeMaxDomainLength = 253
+)
+
+var (
+	scopeDomainLabelRegex = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?$`)
+	// scopeNumericLabelRegex matches the last label of the host which browsers and some resolvers
+	// parse as the IPv4 address in the short or hex notation like 127.1 or 0x7f000001
+	scopeNumericLabelRegex = regexp.MustCompile(`^([0-9]+|0x[0-9a-f]*)$`)
+)
+
+// scopeTargetExtractors returns the hosts which the network tool reaches with the arguments of the call,
+// the tool which isn't listed here doesn't contact the targets directly and isn't restricted by the scope
+var scopeTargetExtractors = map[string]func(args json.RawMessage) ([]string, error){
+	BrowserToolName: func(args json.RawMessage) ([]string, error) {
+		var action Browser
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		host, err := scopeHostFromURL(action.Url)
+		return []string{host}, err
+	},
+	HTTPHeadersToolName: func(args json.RawMessage) ([]string, error) {
+		var action HTTPHeadersAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		target, err := normalizeHTTPHeadersURL(action.URL)
+		if err != nil {
+			return nil, err
+		}
+		host, err := scopeHostFromURL(target)
+		return []string{host}, err
+	},
+	HeadlessBrowserToolName: func(args json.RawMessage) ([]string, error) {
+		var action HeadlessBrowserAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		target, err := normalizeHTTPHeadersURL(action.URL)
+		if err != nil {
+			return nil, err
+		}
+		host, err := scopeHostFromURL(target)
+		return []string{host}, err
+	},
+	HTTPFuzzToolName: func(args json.RawMessage) ([]string, error) {
+		var action HTTPFuzzAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		target, err := newHTTPFuzzTarget(action.URL)
+		if err != nil {
+			return nil, err
+		}
+		return []string{target.base.Hostname()}, nil
+	},
+	PortScanToolName: func(args json.RawMessage) ([]string, error) {
+		var action PortScanAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		host, err := normalizePortScanTarget(action.Target)
+		return []string{host}, err
+	},
+	RobotsToolName: func(args json.RawMessage) ([]string, error) {
+		var action RobotsAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		target, err := normalizeRobotsBaseURL(action.URL)
+		if err != nil {
+			return nil, err
+		}
+		host, err := scopeHostFromURL(target)
+		return []string{host}, err
+	},
+	TLSInspectToolName: func(args json.RawMessage) ([]string, error) {
+		var action TLSInspectAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			return nil, err
+		}
+		_, host, err := normalizeTLSInspectTarget(action.Target)
+		return []string{host}, err
+	},
+}
+
+// FlowScope is the list of the targets which the network tools of the flow are allowed to reach: IP addresses,
+// CIDR ranges, domains and wildcard domains like "*.example.com" which match any subdomain but not the domain itself
+type FlowScope []string
+
+// IsEmpty returns true if the scope doesn't restrict the targets
+func (s FlowScope) IsEmpty() bool {
+	return len(s) == 0
+}
+
+// Valid is function to control input/output data
+func (s FlowScope) Valid() error {
+	if len(s) > flowScopeMaxTargets {
+		return fmt.Errorf("flow scope can't contain more than %d targets", flowScopeMaxTargets)
+	}
+	_, err := compileFlowScope(s)
+	return err
+}
+
+func (s *FlowScope) Scan(input any) error {
+	switch v := input.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case []byte:
+		return json.Unmarshal(v, s)
+	case json.RawMessage:
+		return json.Unmarshal(v, s)
+	}
+	return fmt.Errorf("unsupported type of input value to scan")
+}
+
+// flowScopeRules is the compiled flow scope
+type flowScopeRules struct {
+	targets   []string
+	prefixes  []netip.Prefix
+	domains   map[string]struct{}
+	wildcards []string // parent domains of the wildcards without the leading "*."
+}
+
+func compileFlowScope(scope FlowScope) (flowScopeRules, error) {
+	rules := flowScopeRules{
+		targets: make([]string, 0, len(scope)),
+		domains: make(map[string]struct{}),
+	}
+
+	for _, item := range scope {
+		target := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(item)), ".")
+		if target == "" {
+			return rules, errors.New("flow scope target can't be empty")
+		}
+		rules.targets = append(rules.targets, target)
+
+		if strings.Contains(target, "/") {
+			prefix, err := netip.ParsePrefix(target)
+			if err != nil {
+				return rules, fmt.Errorf("invalid flow scope CIDR '%s': %w", item, err)
+			}
+			rules.prefixes = append(rules.prefixes, prefix.Masked())
+			continue
+		}
+
+		if addr, err := netip.ParseAddr(strings.Trim(target, "[]")); err == nil {
+			if addr.Zone() != "" {
+				return rules, fmt.Errorf("invalid flow scope IP address '%s': zone isn't supported", item)
+			}
+			addr = addr.Unmap()
+			rules.prefixes = append(rules.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		domain, wildcard := strings.CutPrefix(target, flowScopeWildcardPrefix)
+		domain, err := normalizeScopeDomain(domain)
+		if err != nil {
+			return rules, fmt.Errorf("invalid flow scope target '%s': %w", item, err)
+		}
+		if wildcard {
+			rules.wildcards = append(rules.wildcards, domain)
+		} else {
+			rules.domains[domain] = struct{}{}
+		}
+	}
+
+	return rules, nil
+}
+
+// normalizeScopeDomain converts the domain to the ASCII form and checks its syntax,
+// the domains which look like IP addresses in the short notation are rejected
+func normalizeScopeDomain(domain string) (string, error) {
+	ascii, err := idna.Punycode.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("invalid domain name: %w", err)
+	}
+	if ascii == "" || len(ascii) > flowScopeMaxDomainLength {
+		return "", errors.New("domain name must be from 1 to 253 characters long")
+	}
+
+	labels := strings.Split(ascii, ".")
+	for _, label := range labels {
+		if !scopeDomainLabelRegex.MatchString(label) {
+			return "", fmt.Errorf("invalid domain name label '%s'", label)
+		}
+	}
+	if scopeNumericLabelRegex.MatchString(labels[len(labels)-1]) {
+		return "", errors.New("domain name can't end with the numeric label, use the IP address instead")
+	}
+
+	return ascii, nil
+}
+
+// check returns the reason of the denial or an empty string if the host is in the scope
+func (r flowScopeRules) check(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if host == "" {
+		return "target host is empty"
+	}
+
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		addr = addr.WithZone("").Unmap()
+		for _, prefix := range r.prefixes {
+			if prefix.Contains(addr) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("IP address '%s' is out of the flow scope (%s)", addr, r.describe())
+	}
+
+	domain, err := normalizeScopeDomain(host)
+	if err != nil {
+		return fmt.Sprintf("target '%s' can't be checked against the flow scope: %v", host, err)
+	}
+
+	if _, ok := r.domains[domain]; ok {
+		return ""
+	}
+	for _, parent := range r.wildcards {
+		if strings.HasSuffix(domain, "."+parent) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("domain '%s' is out of the flow scope (%s)", domain, r.describe())
+}
+
+// describe lists the scope targets for the agent to choose the allowed target
+func (r flowScopeRules) describe() string {
+	if len(r.targets) <= flowScopeMaxReasonList {
+		return "allowed targets: " + strings.Join(r.targets, ", ")
+	}
+
+	return fmt.Sprintf("allowed targets: %s and %d more", strings.Join(r.targets[:flowScopeMaxReasonList], ", "),
+		len(r.targets)-flowScopeMaxReasonList)
+}
+
+// scopeHostFromURL returns the host of the URL or the host with the optional port and path,
+// the port and the brackets of the IPv6 address are removed
+func scopeHostFromURL(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("url is empty")
+	}
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid url '%s': %w", target, err)
+	}
+	if parsed.Hostname() == "" {
+		return "", fmt.Errorf("invalid url '%s': host is empty", target)
+	}
+
+	return parsed.Hostname(), nil
+}
+
+// ScopeGuard restricts the targets of the network tools of the flow, the empty scope permits all targets
+type ScopeGuard struct {
+	db    database.Querier
+	rules *flowScopeRules
+}
+
+func NewScopeGuard(db database.Querier, scope FlowScope) (*ScopeGuard, error) {
+	guard := &ScopeGuard{db: db}
+	if scope.IsEmpty() {
+		return guard, nil
+	}
+
+	if len(scope) > flowScopeMaxTargets {
+		return nil, fmt.Errorf("flow scope can't contain more than %d targets", flowScopeMaxTargets)
+	}
+
+	rules, err := compileFlowScope(scope)
+	if err != nil {
+		return nil, err
+	}
+	guard.rules = &rules
+
+	return guard, nil
+}
+
+// IsEmpty returns true if the guard permits all targets
+func (g *ScopeGuard) IsEmpty() bool {
+	return g == nil || g.rules == nil
+}
+
+// CheckHost returns the reason of the denial or an empty string if the host is in the scope
+func (g *ScopeGuard) CheckHost(host string) string {
+	if g.IsEmpty() {
+		return ""
+	}
+
+	return g.rules.check(host)
+}
+
+// CheckURL returns the reason of the denial or an empty string if the host of the URL is in the scope
+func (g *ScopeGuard) CheckURL(target string) string {
+	if g.IsEmpty() {
+		return ""
+	}
+
+	host, err := scopeHostFromURL(target)
+	if err != nil {
+		return fmt.Sprintf("target can't be checked against the flow scope: %v", err)
+	}
+
+	return g.rules.check(host)
+}
+
+// checkToolCall returns the denied target and the reason of the denial if the network tool is called
+// with the out-of-scope target, the target which can't be extracted from the arguments is denied
+func (g *ScopeGuard) checkToolCall(name string, args json.RawMessage) (string, string) {
+	if g.IsEmpty() {
+		return "", ""
+	}
+
+	extract, ok := scopeTargetExtractors[name]
+	if !ok {
+		return "", ""
+	}
+
+	hosts, err := extract(args)
+	if err != nil {
+		return "", fmt.Sprintf("target can't be checked against the flow scope: %v", err)
+	}
+
+	for _, host := range hosts {
+		if reason := g.rules.check(host); reason != "" {
+			return host, reason
+		}
+	}
+
+	return "", ""
+}
+
+// Audit stores the out-of-scope tool call in the audit log on behalf of the flow owner
+func (g *ScopeGuard) Audit(
+	ctx context.Context,
+	flowID int64,
+	taskID, subtaskID *int64,
+	tool, target, reason string,
+) {
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(flowID, taskID, subtaskID, logrus.Fields{
+		"tool":   tool,
+		"target": target,
+		"reason": reason,
+	}))
+	logger.Warn("tool call denied by the flow scope")
+
+	if g == nil || g.db == nil {
+		return
+	}
+
+	err := writeFlowAuditLog(ctx, g.db, flowID, flowScopeAuditAction, map[string]any{
+		"target": map[string]any{"before": nil, "after": target},
+	}, map[string]any{
+		"tool":       tool,
+		"reason":     reason,
+		"task_id":    taskID,
+		"subtask_id": subtaskID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("failed to store out-of-scope tool call in audit log")
+	}
+}
+
+type scopeGuardContextKey struct{}
+
+// withScopeGuard passes the guard to the tools which reach the additional targets found in the responses
+// like redirect locations or sitemap links
+func withScopeGuard(ctx context.Context, guard *ScopeGuard) context.Context {
+	if guard.IsEmpty() {
+		return ctx
+	}
+
+	return context.WithValue(ctx, scopeGuardContextKey{}, guard)
+}
+
+func scopeGuardFromContext(ctx context.Context) *ScopeGuard {
+	guard, _ := ctx.Value(scopeGuardContextKey{}).(*ScopeGuard)
+	return guard
+}
+
+func formatScopeDeniedToolCall(name, reason string) string {
+	return fmt.Sprintf("tool call '%s' is denied: %s. "+
+		"The flow is restricted to the in-scope targets, don't try to reach this target with other tools, "+
+		"continue with the in-scope targets only or finish the task with the current results", name, reason)
+}
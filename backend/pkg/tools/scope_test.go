@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scopeMockQuerier records the audit logs and the tool calls of the executor
+type scopeMockQuerier struct {
+	commandPolicyMockQuerier
+	toolcalls []database.CreateToolcallParams
+	results   []string
+}
+
+func (m *scopeMockQuerier) CreateToolcall(
+	_ context.Context, arg database.CreateToolcallParams,
+) (database.Toolcall, error) {
+	m.toolcalls = append(m.toolcalls, arg)
+	return database.Toolcall{ID: int64(len(m.toolcalls)), CallID: arg.CallID, Name: arg.Name}, nil
+}
+
+func (m *scopeMockQuerier) UpdateToolcallFailedResult(
+	_ context.Context, arg database.UpdateToolcallFailedResultParams,
+) (database.Toolcall, error) {
+	m.results = append(m.results, arg.Result)
+	return database.Toolcall{ID: arg.ID, Status: database.ToolcallStatusFailed, Result: arg.Result}, nil
+}
+
+func TestScopeGuardCheckHost(t *testing.T) {
+	t.Parallel()
+
+	scope := FlowScope{"10.0.0.0/24", "192.168.1.10", "2001:db8::/64", "Example.com.", "*.test.local", "*.пример.рф"}
+	guard, err := NewScopeGuard(nil, scope)
+	require.NoError(t, err)
+
+	allowed := []string{
+		"10.0.0.0",
+		"10.0.0.255",
+		"192.168.1.10",
+		"::ffff:10.0.0.7",
+		"2001:db8::1",
+		"[2001:db8::ffff]",
+		"example.com",
+		"EXAMPLE.COM.",
+		"a.test.local",
+		"a.b.c.test.local",
+		"www.xn--e1afmkfd.xn--p1ai",
+		"www.пример.рф",
+	}
+	for _, host := range allowed {
+		assert.Empty(t, guard.CheckHost(host), host)
+	}
+
+	denied := map[string]string{
+		"10.0.1.0":                 "IP address '10.0.1.0' is out of the flow scope",
+		"9.255.255.255":            "IP address '9.255.255.255' is out of the flow scope",
+		"192.168.1.11":             "IP address '192.168.1.11' is out of the flow scope",
+		"2001:db8:0:1::1":          "IP address '2001:db8:0:1::1' is out of the flow scope",
+		"www.example.com":          "domain 'www.example.com' is out of the flow scope",
+		"test.local":               "domain 'test.local' is out of the flow scope",
+		"eviltest.local":           "domain 'eviltest.local' is out of the flow scope",
+		"example.com.evil.net":     "domain 'example.com.evil.net' is out of the flow scope",
+		"127.1":                    "can't end with the numeric label",
+		"0x0a000001":               "can't end with the numeric label",
+		"":                         "target host is empty",
+		"exa mple.com":             "can't be checked against the flow scope",
+		"metadata.google.internal": "allowed targets: 10.0.0.0/24, 192.168.1.10, 2001:db8::/64, example.com, *.test.local",
+	}
+	for host, want := range denied {
+		assert.Contains(t, guard.CheckHost(host), want, host)
+	}
+}
+
+func TestScopeGuardEmpty(t *testing.T) {
+	t.Parallel()
+
+	var nilGuard *ScopeGuard
+	assert.True(t, nilGuard.IsEmpty())
+	assert.Empty(t, nilGuard.CheckHost("10.0.0.1"))
+
+	guard, err := NewScopeGuard(nil, FlowScope{})
+	require.NoError(t, err)
+	assert.True(t, guard.IsEmpty())
+	assert.Empty(t, guard.CheckURL("http://169.254.169.254/latest/meta-data/"))
+
+	target, reason := guard.checkToolCall(TLSInspectToolName, json.RawMessage(`{"target":"anything.example"}`))
+	assert.Empty(t, target)
+	assert.Empty(t, reason)
+}
+
+func TestFlowScopeValid(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, FlowScope{"10.0.0.0/8", "::1", "example.com", "*.example.com", "_srv.example.com"}.Valid())
+	assert.NoError(t, FlowScope(nil).Valid())
+
+	invalid := map[string]FlowScope{
+		"empty target":         {" "},
+		"bad cidr":             {"10.0.0.0/33"},
+		"bad cidr address":     {"10.0.0/8"},
+		"zone":                 {"fe80::1%eth0"},
+		"bare wildcard":        {"*"},
+		"inner wildcard":       {"a.*.example.com"},
+		"wildcard without dot": {"*example.com"},
+		"numeric label":        {"10.0.0"},
+		"url":                  {"https://example.com"},
+		"long label":           {strings.Repeat("a", 64) + ".com"},
+		"too many":             make(FlowScope, flowScopeMaxTargets+1),
+	}
+	for name, scope := range invalid {
+		assert.Error(t, scope.Valid(), name)
+	}
+}
+
+func TestScopeGuardCheckToolCall(t *testing.T) {
+	t.Parallel()
+
+	guard, err := NewScopeGuard(nil, FlowScope{"10.0.0.0/24", "*.example.com"})
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		tool   string
+		args   string
+		target string
+		reason string
+	}{
+		"tls in scope":           {TLSInspectToolName, `{"target":"https://app.example.com:8443/login"}`, "", ""},
+		"tls out of scope":       {TLSInspectToolName, `{"target":"10.0.1.5:443"}`, "10.0.1.5", "is out of the flow scope"},
+		"http headers in scope":  {HTTPHeadersToolName, `{"url":"10.0.0.5:8080/admin"}`, "", ""},
+		"http headers apex":      {HTTPHeadersToolName, `{"url":"http://example.com/"}`, "example.com", "is out of the flow scope"},
+		"robots in scope":        {RobotsToolName, `{"url":"www.example.com"}`, "", ""},
+		"robots out of scope":    {RobotsToolName, `{"url":"https://example.org"}`, "example.org", "is out of the flow scope"},
+		"browser in scope":       {BrowserToolName, `{"url":"http://[::ffff:10.0.0.9]/","action":"markdown"}`, "", ""},
+		"browser out of scope":   {BrowserToolName, `{"url":"http://127.1/","action":"markdown"}`, "127.1", "numeric label"},
+		"port scan in scope":     {PortScanToolName, `{"target":"10.0.0.5","ports":"1-1024"}`, "", ""},
+		"port scan out of scope": {PortScanToolName, `{"target":"ssh://10.0.2.5:22"}`, "10.0.2.5", "is out of the flow scope"},
+		"http fuzz in scope":     {HTTPFuzzToolName, `{"url":"https://app.example.com/api/FUZZ"}`, "", ""},
+		"http fuzz out of scope": {HTTPFuzzToolName, `{"url":"http://10.0.3.1:8080/"}`, "10.0.3.1", "is out of the flow scope"},
+		"headless in scope":      {HeadlessBrowserToolName, `{"url":"app.example.com/#/login","action":"navigate"}`, "", ""},
+		"headless out of scope":  {HeadlessBrowserToolName, `{"url":"http://10.0.4.1/","action":"click"}`, "10.0.4.1", "is out of the flow scope"},
+		"passive tool":           {WhoisToolName, `{"target":"example.org"}`, "", ""},
+		"not network tool":       {TerminalToolName, `{"input":"curl http://example.org"}`, "", ""},
+		"invalid url":            {HTTPHeadersToolName, `{"url":"ftp://10.0.0.5/"}`, "", "can't be checked against the flow scope"},
+		"invalid arguments":      {TLSInspectToolName, `{"target":1}`, "", "can't be checked against the flow scope"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			target, reason := guard.checkToolCall(tc.tool, json.RawMessage(tc.args))
+			assert.Equal(t, tc.target, target)
+			if tc.reason == "" {
+				assert.Empty(t, reason)
+			} else {
+				assert.Contains(t, reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestExecuteOutOfScopeToolCall(t *testing.T) {
+	t.Parallel()
+
+	db := &scopeMockQuerier{}
+	guard, err := NewScopeGuard(db, FlowScope{"10.0.0.0/24"})
+	require.NoError(t, err)
+
+	var executed []string
+	taskID := int64(3)
+	ce := &customExecutor{
+		flowID: 1,
+		taskID: &taskID,
+		db:     db,
+		scope:  guard,
+		handlers: map[string]ExecutorHandler{
+			TLSInspectToolName: func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+				executed = append(executed, string(args))
+				return "", fmt.Errorf("stop after the scope check")
+			},
+		},
+	}
+
+	result, err := ce.Execute(t.Context(), 0, "call-1", TLSInspectToolName, "", "",
+		json.RawMessage(`{"target":"10.0.1.1:443","message":"check tls"}`))
+	require.NoError(t, err)
+	assert.Contains(t, result, "tool call 'tls_inspect' is denied: IP address '10.0.1.1' is out of the flow scope")
+	assert.Contains(t, result, "allowed targets: 10.0.0.0/24")
+	assert.Empty(t, executed, "out-of-scope call must not be executed")
+
+	require.Len(t, db.toolcalls, 1)
+	assert.Equal(t, "call-1", db.toolcalls[0].CallID)
+	require.Len(t, db.results, 1)
+	assert.Equal(t, result, db.results[0])
+
+	require.Len(t, db.logs, 1)
+	assert.Equal(t, flowScopeAuditAction, db.logs[0].Action)
+	assert.Equal(t, int64(5), db.logs[0].UserID)
+	assert.JSONEq(t, `{"target":{"before":null,"after":"10.0.1.1"}}`, string(db.logs[0].Changes))
+	var metadata map[string]any
+	require.NoError(t, json.Unmarshal(db.logs[0].Metadata, &metadata))
+	assert.Equal(t, TLSInspectToolName, metadata["tool"])
+	assert.Equal(t, float64(3), metadata["task_id"])
+
+	// the in-scope call reaches the handler which gets the guard to check the secondary targets
+	ce.handlers[TLSInspectToolName] = func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+		executed = append(executed, string(args))
+		assert.Same(t, guard, scopeGuardFromContext(ctx))
+		return "", fmt.Errorf("stop after the scope check")
+	}
+	_, err = ce.Execute(t.Context(), 0, "call-2", TLSInspectToolName, "", "", json.RawMessage(`{"target":"10.0.0.1"}`))
+	require.Error(t, err)
+	assert.Len(t, executed, 1)
+	assert.Len(t, db.logs, 1)
+}
+
+func TestHTTPHeadersOutOfScopeRedirect(t *testing.T) {
+	t.Parallel()
+
+	var external string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hop/0":
+			http.Redirect(w, r, "/hop/1", http.StatusFound)
+		case "/hop/1":
+			http.Redirect(w, r, external, http.StatusFound)
+		default:
+			t.Errorf("out-of-scope redirect is followed to %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	external = strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/hop/2"
+
+	guard, err := NewScopeGuard(nil, FlowScope{"127.0.0.1"})
+	require.NoError(t, err)
+
+	tool := testHTTPHeadersTool(nil)
+	args := fmt.Sprintf(`{"url":%q,"follow_redirects":true}`, server.URL+"/hop/0")
+	got, err := tool.Handle(withScopeGuard(t.Context(), guard), HTTPHeadersToolName, []byte(args))
+	require.NoError(t, err)
+
+	assert.Contains(t, got, "**Final URL:** "+server.URL+"/hop/1")
+	assert.Contains(t, got, "redirect to "+external+" isn't followed: domain 'localhost' is out of the flow scope")
+	assert.NotContains(t, got, "redirect limit is reached")
+}
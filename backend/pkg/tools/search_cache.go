@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pentagi/pkg/config"
+	obs "pentagi/pkg/observability"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+const searchCacheNamespacePrefix = "search:"
+
+// searchFailedResultPrefix marks the swallowed error which the search tools return as a result,
+// such results are never cached to let the agent retry the search
+const searchFailedResultPrefix = "failed to "
+
+// SearchCacheStats contains hit and miss counters of the search results cache for the single tool
+type SearchCacheStats struct {
+	Tool   string
+	TTL    time.Duration
+	Hits   int64
+	Misses int64
+}
+
+// SearchCache caches results of the search tools keyed by the tool name and its normalized arguments,
+// it's shared across all flows so the same recon query returns the stored result until the TTL expires
+type SearchCache interface {
+	Wrap(name string, handler ExecutorHandler) ExecutorHandler
+	Invalidate(ctx context.Context, name string) error
+	Stats() []SearchCacheStats
+}
+
+type searchCacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type searchCache struct {
+	cache    Cache
+	ttl      time.Duration
+	toolsTTL map[string]time.Duration
+	mx       *sync.Mutex
+	counters map[string]*searchCacheCounters
+	hits     otelmetric.Int64Counter
+	misses   otelmetric.Int64Counter
+}
+
+var (
+	sharedSearchCacheOnce sync.Once
+	sharedSearchCache     SearchCache
+)
+
+// GetSharedSearchCache returns process wide search results cache which is built on top of the shared tools cache
+func GetSharedSearchCache(cfg *config.Config) SearchCache {
+	sharedSearchCacheOnce.Do(func() {
+		sharedSearchCache = NewSearchCache(cfg, GetSharedCache(cfg))
+	})
+
+	return sharedSearchCache
+}
+
+// NewSearchCache creates search results cache with TTLs from config, nil cache disables caching
+func NewSearchCache(cfg *config.Config, cache Cache) SearchCache {
+	sc := &searchCache{
+		cache:    cache,
+		toolsTTL: make(map[string]time.Duration),
+		mx:       &sync.Mutex{},
+		counters: make(map[string]*searchCacheCounters),
+	}
+
+	if cfg != nil {
+		sc.ttl = time.Duration(cfg.SearchCacheTTL) * time.Second
+		for name, ttl := range cfg.SearchCacheToolsTTL {
+			sc.toolsTTL[name] = time.Duration(ttl) * time.Second
+		}
+	}
+
+	var err error
+	if sc.hits, err = obs.Observer.NewInt64Counter("tools.search_cache.hits"); err != nil {
+		logrus.WithError(err).Warn("failed to create search cache hits counter")
+	}
+	if sc.misses, err = obs.Observer.NewInt64Counter("tools.search_cache.misses"); err != nil {
+		logrus.WithError(err).Warn("failed to create search cache misses counter")
+	}
+
+	return sc
+}
+
+// Wrap returns the handler which serves repeated calls from the cache, the handler is returned as is
+// if caching is disabled for the tool
+func (sc *searchCache) Wrap(name string, handler ExecutorHandler) ExecutorHandler {
+	ttl := sc.toolTTL(name)
+	if sc.cache == nil || ttl <= 0 {
+		return handler
+	}
+
+	counters := sc.toolCounters(name)
+	namespace := searchCacheNamespace(name)
+
+	return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+		key, err := normalizeSearchArgs(args)
+		if err != nil {
+			return handler(ctx, name, args)
+		}
+		// the result is rendered with the size limits of the flow, the flows with own limits don't share it
+		if limits := resultSizeLimitsFromContext(ctx); !limits.isDefault() {
+			key += fmt.Sprintf(":%d:%d", limits.MaxResultSize, limits.MaxSourceSize)
+		}
+
+		var result string
+		if getCachedJSON(ctx, sc.cache, namespace, key, &result) {
+			counters.hits.Add(1)
+			sc.record(ctx, sc.hits, name)
+			return result, nil
+		}
+
+		counters.misses.Add(1)
+		sc.record(ctx, sc.misses, name)
+
+		result, err = handler(ctx, name, args)
+		if err != nil || result == "" || strings.HasPrefix(result, searchFailedResultPrefix) {
+			return result, err
+		}
+
+		setCachedJSON(ctx, sc.cache, namespace, key, result, ttl)
+
+		return result, nil
+	}
+}
+
+// Invalidate drops all cached results of the tool including its own cached API responses
+func (sc *searchCache) Invalidate(ctx context.Context, name string) error {
+	if sc.cache == nil {
+		return nil
+	}
+
+	if err := sc.cache.Clear(ctx, searchCacheNamespace(name)); err != nil {
+		return fmt.Errorf("failed to clear '%s' search results: %w", name, err)
+	}
+
+	// sploitus and github tools keep API responses in the namespace named after the tool
+	if err := sc.cache.Clear(ctx, name); err != nil {
+		return fmt.Errorf("failed to clear '%s' tool cache: %w", name, err)
+	}
+
+	return nil
+}
+
+func (sc *searchCache) Stats() []SearchCacheStats {
+	sc.mx.Lock()
+	defer sc.mx.Unlock()
+
+	stats := make([]SearchCacheStats, 0, len(sc.counters))
+	for _, name := range slices.Sorted(maps.Keys(sc.counters)) {
+		counters := sc.counters[name]
+		stats = append(stats, SearchCacheStats{
+			Tool:   name,
+			TTL:    sc.toolTTL(name),
+			Hits:   counters.hits.Load(),
+			Misses: counters.misses.Load(),
+		})
+	}
+
+	return stats
+}
+
+func (sc *searchCache) toolTTL(name string) time.Duration {
+	if ttl, ok := sc.toolsTTL[name]; ok {
+		return ttl
+	}
+
+	return sc.ttl
+}
+
+func (sc *searchCache) toolCounters(name string) *searchCacheCounters {
+	sc.mx.Lock()
+	defer sc.mx.Unlock()
+
+	counters, ok := sc.counters[name]
+	if !ok {
+		counters = &searchCacheCounters{}
+		sc.counters[name] = counters
+	}
+
+	return counters
+}
+
+func (sc *searchCache) record(ctx context.Context, counter otelmetric.Int64Counter, name string) {
+	if counter != nil {
+		counter.Add(ctx, 1, otelmetric.WithAttributes(attribute.String("tool", name)))
+	}
+}
+
+func searchCacheNamespace(name string) string {
+	return searchCacheNamespacePrefix + name
+}
+
+// searchQueryArgs are the arguments with the free text of the search, the search engines ignore its case
+// and extra spaces, the other arguments like URLs, hashes and tokens are compared as is
+var searchQueryArgs = []string{"query", "question"}
+
+// normalizeSearchArgs builds the cache key from the tool call arguments, the message for the user
+// doesn't change the result so it's dropped and the query text is compared in case insensitive form
+func normalizeSearchArgs(args json.RawMessage) (string, error) {
+	var values map[string]any
+	if err := json.Unmarshal(args, &values); err != nil {
+		return "", err
+	}
+	if values == nil {
+		return "", errors.New("search arguments must be an object")
+	}
+
+	delete(values, "message")
+
+	for _, name := range searchQueryArgs {
+		if query, ok := values[name].(string); ok {
+			values[name] = strings.ToLower(strings.Join(strings.Fields(query), " "))
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
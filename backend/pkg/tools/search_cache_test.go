@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+)
+
+type countingHandler struct {
+	calls  int
+	result string
+	err    error
+}
+
+func (h *countingHandler) handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	h.calls++
+	return h.result, h.err
+}
+
+func newTestSearchCache(t *testing.T, cfg *config.Config) (*searchCache, *memoryCache, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache(10).(*memoryCache)
+	cache.now = func() time.Time { return now }
+
+	return NewSearchCache(cfg, cache).(*searchCache), cache, &now
+}
+
+func TestSearchCacheHitAndMiss(t *testing.T) {
+	sc, _, _ := newTestSearchCache(t, &config.Config{SearchCacheTTL: 60})
+	h := &countingHandler{result: "results"}
+	handler := sc.Wrap(TavilyToolName, h.handle)
+	ctx := t.Context()
+
+	first := json.RawMessage(`{"query":"CVE-2024-1234 PoC","max_results":5,"message":"looking for PoC"}`)
+	same := json.RawMessage(`{"message":"another message","max_results":5,"query":"  cve-2024-1234   poc "}`)
+	other := json.RawMessage(`{"query":"CVE-2024-1234 PoC","max_results":10,"message":"looking for PoC"}`)
+
+	for _, args := range []json.RawMessage{first, same} {
+		result, err := handler(ctx, TavilyToolName, args)
+		if err != nil || result != "results" {
+			t.Fatalf("unexpected result %q, err %v", result, err)
+		}
+	}
+	if h.calls != 1 {
+		t.Errorf("expected normalized args to hit the cache, handler called %d times", h.calls)
+	}
+
+	if _, err := handler(ctx, TavilyToolName, other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.calls != 2 {
+		t.Errorf("expected different args to miss the cache, handler called %d times", h.calls)
+	}
+
+	stats := sc.Stats()
+	if len(stats) != 1 || stats[0].Tool != TavilyToolName || stats[0].Hits != 1 || stats[0].Misses != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats[0].TTL != time.Minute {
+		t.Errorf("expected TTL 1m, got %s", stats[0].TTL)
+	}
+}
+
+func TestNormalizeSearchArgs(t *testing.T) {
+	key, err := normalizeSearchArgs(json.RawMessage(`{"query":"  Apache  RCE ","url":"https://Example.com/Path","message":"msg"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"query":"apache rce","url":"https://Example.com/Path"}`; key != want {
+		t.Errorf("expected key %s, got %s", want, key)
+	}
+
+	upper, err := normalizeSearchArgs(json.RawMessage(`{"query":"test","continuation":"AbC"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lower, err := normalizeSearchArgs(json.RawMessage(`{"query":"test","continuation":"abc"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if upper == lower {
+		t.Errorf("expected tokens in different case to produce different keys, got %s", upper)
+	}
+
+	if _, err := normalizeSearchArgs(json.RawMessage(`null`)); err == nil {
+		t.Error("expected error for non-object arguments")
+	}
+}
+
+func TestSearchCacheExpiry(t *testing.T) {
+	sc, _, now := newTestSearchCache(t, &config.Config{
+		SearchCacheTTL:      3600,
+		SearchCacheToolsTTL: map[string]int{GoogleToolName: 60},
+	})
+	h := &countingHandler{result: "results"}
+	handler := sc.Wrap(GoogleToolName, h.handle)
+	ctx := t.Context()
+	args := json.RawMessage(`{"query":"nginx 1.18 exploit"}`)
+
+	_, _ = handler(ctx, GoogleToolName, args)
+	*now = now.Add(59 * time.Second)
+	_, _ = handler(ctx, GoogleToolName, args)
+	if h.calls != 1 {
+		t.Fatalf("expected cached result before the tool TTL, handler called %d times", h.calls)
+	}
+
+	*now = now.Add(time.Second)
+	_, _ = handler(ctx, GoogleToolName, args)
+	if h.calls != 2 {
+		t.Errorf("expected expired result to be fetched again, handler called %d times", h.calls)
+	}
+}
+
+func TestSearchCacheSkipsFailedResults(t *testing.T) {
+	sc, _, _ := newTestSearchCache(t, &config.Config{SearchCacheTTL: 60})
+	ctx := t.Context()
+	args := json.RawMessage(`{"query":"test"}`)
+
+	failed := &countingHandler{result: "failed to search in google: timeout"}
+	handler := sc.Wrap(GoogleToolName, failed.handle)
+	_, _ = handler(ctx, GoogleToolName, args)
+	_, _ = handler(ctx, GoogleToolName, args)
+	if failed.calls != 2 {
+		t.Errorf("expected failed result not to be cached, handler called %d times", failed.calls)
+	}
+
+	broken := &countingHandler{err: errors.New("boom")}
+	handler = sc.Wrap(DuckDuckGoToolName, broken.handle)
+	_, _ = handler(ctx, DuckDuckGoToolName, args)
+	if _, err := handler(ctx, DuckDuckGoToolName, args); err == nil {
+		t.Error("expected error to be returned")
+	}
+	if broken.calls != 2 {
+		t.Errorf("expected error not to be cached, handler called %d times", broken.calls)
+	}
+}
+
+func TestSearchCacheDisabled(t *testing.T) {
+	sc, _, _ := newTestSearchCache(t, &config.Config{
+		SearchCacheTTL:      60,
+		SearchCacheToolsTTL: map[string]int{SploitusToolName: 0},
+	})
+	h := &countingHandler{result: "results"}
+	handler := sc.Wrap(SploitusToolName, h.handle)
+	args := json.RawMessage(`{"query":"apache"}`)
+
+	_, _ = handler(t.Context(), SploitusToolName, args)
+	_, _ = handler(t.Context(), SploitusToolName, args)
+	if h.calls != 2 {
+		t.Errorf("expected caching to be disabled for the tool, handler called %d times", h.calls)
+	}
+}
+
+func TestSearchCacheInvalidate(t *testing.T) {
+	sc, cache, _ := newTestSearchCache(t, &config.Config{SearchCacheTTL: 60})
+	ctx := t.Context()
+	args := json.RawMessage(`{"query":"apache"}`)
+
+	google := &countingHandler{result: "google results"}
+	tavily := &countingHandler{result: "tavily results"}
+	googleHandler := sc.Wrap(GoogleToolName, google.handle)
+	tavilyHandler := sc.Wrap(TavilyToolName, tavily.handle)
+
+	_, _ = googleHandler(ctx, GoogleToolName, args)
+	_, _ = tavilyHandler(ctx, TavilyToolName, args)
+	_ = cache.Set(ctx, GoogleToolName, "api response", []byte("{}"), 0)
+
+	if err := sc.Invalidate(ctx, GoogleToolName); err != nil {
+		t.Fatalf("Invalidate() unexpected error: %v", err)
+	}
+
+	_, _ = googleHandler(ctx, GoogleToolName, args)
+	_, _ = tavilyHandler(ctx, TavilyToolName, args)
+	if google.calls != 2 {
+		t.Errorf("expected invalidated tool to be called again, got %d calls", google.calls)
+	}
+	if tavily.calls != 1 {
+		t.Errorf("expected other tool to keep cached results, got %d calls", tavily.calls)
+	}
+	if _, err := cache.Get(ctx, GoogleToolName, "api response"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expected tool namespace to be cleared, got %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// search log backends which are selected by SEARCH_LOG_BACKEND
+const (
+	// SearchLogBackendFlow writes the logs to the searchlogs table of the flow and publishes them to the UI
+	SearchLogBackendFlow = "flow"
+	// SearchLogBackendPostgres writes the logs of all flows to the tool_logs table in the background
+	SearchLogBackendPostgres = "postgres"
+	// SearchLogBackendNoop drops the logs
+	SearchLogBackendNoop = "noop"
+)
+
+const (
+	defaultSearchLogBufferSize = 1024
+	searchLogWriteTimeout      = 10 * time.Second
+)
+
+// NoopSearchLogProvider drops all search logs
+type NoopSearchLogProvider struct{}
+
+func (NoopSearchLogProvider) PutLog(
+	_ context.Context,
+	_ database.MsgchainType,
+	_ database.MsgchainType,
+	_ database.SearchengineType,
+	_ string,
+	_ string,
+	_ *int64,
+	_ *int64,
+) (int64, error) {
+	return 0, nil
+}
+
+// ToolLogWriter stores the logs of the postgres backend, database.Querier implements it
+type ToolLogWriter interface {
+	CreateToolLog(ctx context.Context, arg database.CreateToolLogParams) error
+}
+
+// PostgresSearchLog writes the search logs of all flows to the tool_logs table from the single goroutine,
+// the logs are buffered so the slow database can't stall the tools, the logs over the full buffer are dropped
+type PostgresSearchLog struct {
+	db      ToolLogWriter
+	logs    chan database.CreateToolLogParams
+	done    chan struct{}
+	mx      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewPostgresSearchLog starts the writer with the buffer of bufferSize logs, non-positive size uses the default one
+func NewPostgresSearchLog(db ToolLogWriter, bufferSize int) *PostgresSearchLog {
+	if bufferSize <= 0 {
+		bufferSize = defaultSearchLogBufferSize
+	}
+
+	psl := &PostgresSearchLog{
+		db:   db,
+		logs: make(chan database.CreateToolLogParams, bufferSize),
+		done: make(chan struct{}),
+	}
+	go psl.run()
+
+	return psl
+}
+
+// Provider returns the search log provider of the flow tools
+func (psl *PostgresSearchLog) Provider(flowID int64) SearchLogProvider {
+	return &postgresSearchLogProvider{psl: psl, flowID: flowID}
+}
+
+// Close stops accepting the logs and waits until the buffered ones are written or ctx is done
+func (psl *PostgresSearchLog) Close(ctx context.Context) error {
+	psl.mx.Lock()
+	if !psl.closed {
+		psl.closed = true
+		close(psl.logs)
+	}
+	psl.mx.Unlock()
+
+	select {
+	case <-psl.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to flush %d search logs: %w", len(psl.logs), ctx.Err())
+	}
+}
+
+// Dropped returns the count of the logs which weren't buffered because the buffer was full or the writer was closed
+func (psl *PostgresSearchLog) Dropped() int64 {
+	return psl.dropped.Load()
+}
+
+// Failed returns the count of the buffered logs which the database didn't accept
+func (psl *PostgresSearchLog) Failed() int64 {
+	return psl.failed.Load()
+}
+
+func (psl *PostgresSearchLog) put(log database.CreateToolLogParams) {
+	psl.mx.RLock()
+	defer psl.mx.RUnlock()
+
+	if psl.closed {
+		psl.dropped.Add(1)
+		return
+	}
+
+	select {
+	case psl.logs <- log:
+	default:
+		dropped := psl.dropped.Add(1)
+		logrus.WithFields(logrus.Fields{
+			"flow_id": log.FlowID,
+			"engine":  log.Engine,
+			"dropped": dropped,
+		}).Warn("search log buffer is full, the log is dropped")
+	}
+}
+
+func (psl *PostgresSearchLog) run() {
+	defer close(psl.done)
+
+	for log := range psl.logs {
+		ctx, cancel := context.WithTimeout(context.Background(), searchLogWriteTimeout)
+		err := psl.db.CreateToolLog(ctx, log)
+		cancel()
+
+		if err != nil {
+			psl.failed.Add(1)
+			logrus.WithError(err).WithField("flow_id", log.FlowID).Warn("failed to write search log")
+		}
+	}
+}
+
+type postgresSearchLogProvider struct {
+	psl    *PostgresSearchLog
+	flowID int64
+}
+
+// PutLog buffers the log and returns immediately, the id is always zero because the log isn't written yet
+func (p *postgresSearchLogProvider) PutLog(
+	_ context.Context,
+	initiator database.MsgchainType,
+	executor database.MsgchainType,
+	engine database.SearchengineType,
+	query string,
+	result string,
+	taskID *int64,
+	subtaskID *int64,
+) (int64, error) {
+	p.psl.put(database.CreateToolLogParams{
+		Initiator: initiator,
+		Executor:  executor,
+		Engine:    engine,
+		Query:     query,
+		Result:    result,
+		FlowID:    p.flowID,
+		TaskID:    database.Int64ToNullInt64(taskID),
+		SubtaskID: database.Int64ToNullInt64(subtaskID),
+	})
+
+	return 0, nil
+}
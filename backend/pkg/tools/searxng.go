@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,9 +21,13 @@ import (
 )
 
 const (
-	defaultSearxngTimeout = 30 * time.Second
+	defaultSearxngTimeout    = 30 * time.Second
+	defaultSearxngMaxResults = 10
 )
 
+// searxngCategories is the list of categories which agent can request explicitly
+var searxngCategories = []string{"general", "it", "science"}
+
 type searxng struct {
 	cfg        *config.Config
 	flowID     int64
@@ -58,7 +63,7 @@ func (s *searxng) Handle(ctx context.Context, name string, args json.RawMessage)
 		return "", fmt.Errorf("searxng is not available")
 	}
 
-	var action SearchAction
+	var action SearxngAction
 	ctx, observation := obs.Observer.NewObservation(ctx)
 	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(s.flowID, s.taskID, s.subtaskID, logrus.Fields{
 		"tool": name,
@@ -70,12 +75,23 @@ func (s *searxng) Handle(ctx context.Context, name string, args json.RawMessage)
 		return "", fmt.Errorf("failed to unmarshal %s search action arguments: %w", name, err)
 	}
 
+	categories := s.categories()
+	if action.Category != "" {
+		if !slices.Contains(searxngCategories, action.Category) {
+			logger.WithField("category", action.Category).Error("unsupported searxng category")
+			return "", fmt.Errorf("unsupported %s search category '%s', allowed: %s",
+				name, action.Category, strings.Join(searxngCategories, ", "))
+		}
+		categories = action.Category
+	}
+
 	logger = logger.WithFields(logrus.Fields{
 		"query":       action.Query[:min(len(action.Query), 1000)],
 		"max_results": action.MaxResults,
+		"categories":  categories,
 	})
 
-	result, err := s.search(ctx, action.Query, action.MaxResults.Int())
+	result, err := s.search(ctx, action.Query, categories, action.MaxResults.Int())
 	if err != nil {
 		observation.Event(
 			langfuse.WithEventName("search engine error swallowed"),
@@ -87,6 +103,7 @@ func (s *searxng) Handle(ctx context.Context, name string, args json.RawMessage)
 				"engine":      "searxng",
 				"query":       action.Query,
 				"max_results": action.MaxResults.Int(),
+				"categories":  categories,
 				"error":       err.Error(),
 			}),
 		)
@@ -111,7 +128,7 @@ func (s *searxng) Handle(ctx context.Context, name string, args json.RawMessage)
 	return result, nil
 }
 
-func (s *searxng) search(ctx context.Context, query string, maxResults int) (string, error) {
+func (s *searxng) search(ctx context.Context, query, categories string, maxResults int) (string, error) {
 	apiURL, err := url.Parse(s.baseURL())
 	if err != nil {
 		return "", fmt.Errorf("invalid searxng base URL: %w", err)
@@ -125,18 +142,17 @@ func (s *searxng) search(ctx context.Context, query string, maxResults int) (str
 	params.Add("q", query)
 	params.Add("format", "json")
 	params.Add("language", s.language())
-	params.Add("categories", s.categories())
+	params.Add("categories", categories)
 	params.Add("safesearch", s.safeSearch())
 
 	if timeRange := s.timeRange(); timeRange != "" {
 		params.Add("time_range", timeRange)
 	}
 
-	if maxResults > 0 {
-		params.Add("limit", strconv.Itoa(maxResults))
-	} else {
-		params.Add("limit", "10")
+	if maxResults < 1 || maxResults > defaultSearxngMaxResults {
+		maxResults = defaultSearxngMaxResults
 	}
+	params.Add("limit", strconv.Itoa(maxResults))
 
 	apiURL.RawQuery = params.Encode()
 
@@ -160,10 +176,15 @@ func (s *searxng) search(ctx context.Context, query string, maxResults int) (str
 	}
 	defer resp.Body.Close()
 
-	return s.parseHTTPResponse(resp, query)
+	return s.parseHTTPResponse(ctx, resp, query, maxResults)
 }
 
-func (s *searxng) parseHTTPResponse(resp *http.Response, query string) (string, error) {
+func (s *searxng) parseHTTPResponse(
+	ctx context.Context,
+	resp *http.Response,
+	query string,
+	maxResults int,
+) (string, error) {
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -173,10 +194,32 @@ func (s *searxng) parseHTTPResponse(resp *http.Response, query string) (string,
 		return "", fmt.Errorf("failed to decode response body: %w", err)
 	}
 
-	return s.formatResults(searxngResponse.Results, query), nil
+	results := sortSearxngResults(searxngResponse.Results)
+	if maxResults > 0 && len(results) > maxResults {
+		results = results[:maxResults]
+	}
+
+	return s.formatResults(results, query, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
 }
 
-func (s *searxng) formatResults(results []SearxngResult, query string) string {
+// sortSearxngResults orders results by score and then by URL to make output deterministic
+// because searxng merges answers of the engines in the order they have responded
+func sortSearxngResults(results []SearxngResult) []SearxngResult {
+	sorted := slices.Clone(results)
+	slices.SortStableFunc(sorted, func(a, b SearxngResult) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	return sorted
+}
+
+func (s *searxng) formatResults(results []SearxngResult, query string, sizeLimit int) string {
 	if len(results) == 0 {
 		return fmt.Sprintf("# No Results Found\n\nNo results were found for query: %s", query)
 	}
@@ -185,30 +228,37 @@ func (s *searxng) formatResults(results []SearxngResult, query string) string {
 	builder.WriteString(fmt.Sprintf("# Searxng Search Results\n\n## Query: %s\n\n", query))
 	builder.WriteString("Results from Searxng meta search engine (aggregated from multiple search engines):\n\n")
 
+	budget := NewResultBudget(sizeLimit, builder.Len())
 	for i, result := range results {
-		builder.WriteString(fmt.Sprintf("### %d. %s\n\n", i+1, result.Title))
+		var item strings.Builder
+		item.WriteString(fmt.Sprintf("### %d. %s\n\n", i+1, result.Title))
 
 		if result.URL != "" {
-			builder.WriteString(fmt.Sprintf("**URL:** [%s](%s)\n\n", result.URL, result.URL))
+			item.WriteString(fmt.Sprintf("**URL:** [%s](%s)\n\n", result.URL, result.URL))
 		}
 
 		if result.Content != "" {
-			builder.WriteString(fmt.Sprintf("**Content:** %s\n\n", result.Content))
+			item.WriteString(fmt.Sprintf("**Content:** %s\n\n", result.Content))
 		}
 
 		if result.Author != "" {
-			builder.WriteString(fmt.Sprintf("**Author:** %s\n\n", result.Author))
+			item.WriteString(fmt.Sprintf("**Author:** %s\n\n", result.Author))
 		}
 
 		if resultPublished := result.PublishedDate; resultPublished != "" {
-			builder.WriteString(fmt.Sprintf("**Published:** %s\n\n", resultPublished))
+			item.WriteString(fmt.Sprintf("**Published:** %s\n\n", resultPublished))
 		}
 
 		if result.Engine != "" {
-			builder.WriteString(fmt.Sprintf("**Source Engine:** %s\n\n", result.Engine))
+			item.WriteString(fmt.Sprintf("**Source Engine:** %s\n\n", result.Engine))
 		}
 
-		builder.WriteString("---\n\n")
+		item.WriteString("---\n\n")
+
+		if !budget.Add(&builder, item.String()) {
+			builder.WriteString(budget.Notice(budget.Added(), len(results), "results"))
+			break
+		}
 	}
 
 	return builder.String()
@@ -264,12 +314,13 @@ func (s *searxng) timeout() time.Duration {
 
 // SearxngResult represents a single result from Searxng
 type SearxngResult struct {
-	Title         string `json:"title"`
-	URL           string `json:"url"`
-	Content       string `json:"content"`
-	Author        string `json:"author"`
-	PublishedDate string `json:"publishedDate"`
-	Engine        string `json:"engine"`
+	Title         string  `json:"title"`
+	URL           string  `json:"url"`
+	Content       string  `json:"content"`
+	Author        string  `json:"author"`
+	PublishedDate string  `json:"publishedDate"`
+	Engine        string  `json:"engine"`
+	Score         float64 `json:"score"`
 }
 
 // SearxngResponse represents the response from Searxng API
@@ -1,9 +1,13 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -192,7 +196,7 @@ func TestSearxngParseHTTPResponse_StatusAndDecodeErrors(t *testing.T) {
 			StatusCode: http.StatusInternalServerError,
 			Body:       io.NopCloser(strings.NewReader("")),
 		}
-		_, err := sx.parseHTTPResponse(resp, "test query")
+		_, err := sx.parseHTTPResponse(t.Context(), resp, "test query", 10)
 		if err == nil || !strings.Contains(err.Error(), "unexpected status code") {
 			t.Fatalf("expected status code error, got: %v", err)
 		}
@@ -203,7 +207,7 @@ func TestSearxngParseHTTPResponse_StatusAndDecodeErrors(t *testing.T) {
 			StatusCode: http.StatusOK,
 			Body:       io.NopCloser(strings.NewReader("{invalid json")),
 		}
-		_, err := sx.parseHTTPResponse(resp, "test query")
+		_, err := sx.parseHTTPResponse(t.Context(), resp, "test query", 10)
 		if err == nil || !strings.Contains(err.Error(), "failed to decode response body") {
 			t.Fatalf("expected decode error, got: %v", err)
 		}
@@ -214,7 +218,7 @@ func TestSearxngParseHTTPResponse_StatusAndDecodeErrors(t *testing.T) {
 			StatusCode: http.StatusOK,
 			Body:       io.NopCloser(strings.NewReader(`{"query":"test","results":[{"title":"Title","url":"https://example.com","content":"Content"}]}`)),
 		}
-		result, err := sx.parseHTTPResponse(resp, "test")
+		result, err := sx.parseHTTPResponse(t.Context(), resp, "test", 10)
 		if err != nil {
 			t.Fatalf("parseHTTPResponse() unexpected error: %v", err)
 		}
@@ -230,7 +234,7 @@ func TestSearxngParseHTTPResponse_StatusAndDecodeErrors(t *testing.T) {
 func TestSearxngFormatResults_NoResults(t *testing.T) {
 	sx := &searxng{flowID: 1}
 
-	result := sx.formatResults([]SearxngResult{}, "test query")
+	result := sx.formatResults([]SearxngResult{}, "test query", DefaultMaxResultSize)
 	if !strings.Contains(result, "No Results Found") {
 		t.Errorf("result missing 'No Results Found': %q", result)
 	}
@@ -253,7 +257,7 @@ func TestSearxngFormatResults_WithResults(t *testing.T) {
 		},
 	}
 
-	result := sx.formatResults(results, "test query")
+	result := sx.formatResults(results, "test query", DefaultMaxResultSize)
 
 	if !strings.Contains(result, "# Searxng Search Results") {
 		t.Errorf("result missing header: %q", result)
@@ -412,3 +416,120 @@ func TestSearxngHandle_TimeRange(t *testing.T) {
 		t.Errorf("time_range = %q, want day", receivedTimeRange)
 	}
 }
+
+func TestSearxngParseHTTPResponse_Testdata(t *testing.T) {
+	sx := &searxng{flowID: 1}
+
+	body, err := os.ReadFile(filepath.Join("testdata", "searxng_result_nmap_scripts.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	parse := func(maxResults int) string {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}
+		result, err := sx.parseHTTPResponse(t.Context(), resp, "nmap nse scripts", maxResults)
+		if err != nil {
+			t.Fatalf("parseHTTPResponse() unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := parse(10)
+	order := []string{
+		"### 1. NSEDoc Reference Portal",
+		"### 2. Nmap Scripting Engine (NSE)",
+		"### 3. nmap/scripts at master",
+		"### 4. Nmap NSE Library - InfosecMatter",
+		"### 5. How to Use Nmap NSE Scripts",
+	}
+	last := -1
+	for _, part := range order {
+		idx := strings.Index(result, part)
+		if idx <= last {
+			t.Fatalf("unexpected position of %q in result:\n%s", part, result)
+		}
+		last = idx
+	}
+	if !strings.Contains(result, "**Author:** InfosecMatter") {
+		t.Error("result missing author")
+	}
+	if !strings.Contains(result, "**Published:** 2024-03-12T00:00:00") {
+		t.Error("result missing published date")
+	}
+
+	if again := parse(10); again != result {
+		t.Error("result must be deterministic")
+	}
+
+	limited := parse(2)
+	if !strings.Contains(limited, "### 2. ") || strings.Contains(limited, "### 3. ") {
+		t.Errorf("result must be limited to 2 items:\n%s", limited)
+	}
+}
+
+func TestSearxngFormatResults_SizeLimit(t *testing.T) {
+	sx := &searxng{flowID: 1}
+
+	results := make([]SearxngResult, 10)
+	for i := range results {
+		results[i] = SearxngResult{
+			Title:   fmt.Sprintf("Result %d", i+1),
+			URL:     "https://example.com",
+			Content: strings.Repeat("x", 20*1024),
+		}
+	}
+
+	result := sx.formatResults(results, "test query", DefaultMaxResultSize)
+	if len(result) > DefaultMaxResultSize {
+		t.Errorf("result size %d exceeds limit %d", len(result), DefaultMaxResultSize)
+	}
+	if !strings.Contains(result, "Showing 3 of 10 results") {
+		t.Errorf("result missing truncation note")
+	}
+}
+
+func TestSearxngHandle_Category(t *testing.T) {
+	var receivedCategories string
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		receivedCategories = r.URL.Query().Get("categories")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"query":"test","results":[]}`))
+	})
+
+	proxy, err := newTestProxy("searxng.example.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	cfg := &config.Config{
+		SearxngURL:        testSearxngURL,
+		SearxngCategories: "general",
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+		SearxngTimeout:    30,
+	}
+
+	sx := NewSearxngTool(cfg, 1, nil, nil, nil, nil)
+
+	_, err = sx.Handle(t.Context(), SearxngToolName,
+		[]byte(`{"query":"test","category":"science","max_results":5,"message":"m"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if receivedCategories != "science" {
+		t.Errorf("categories = %q, want science", receivedCategories)
+	}
+
+	_, err = sx.Handle(t.Context(), SearxngToolName,
+		[]byte(`{"query":"test","category":"images","max_results":5,"message":"m"}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("expected unsupported category error, got: %v", err)
+	}
+}
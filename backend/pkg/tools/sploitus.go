@@ -2,11 +2,17 @@ package tools
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,18 +25,30 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Retry delays of the rate limited requests, variables to make tests fast
+var (
+	sploitusRetryBaseDelay = time.Second
+	sploitusRetryMaxDelay  = 30 * time.Second
+)
+
 const (
 	sploitusAPIURL         = "https://sploitus.com/search"
 	sploitusDefaultSort    = "default"
+	sploitusSortEPSS       = "epss" // local sort of exploits by EPSS score of their CVEs
 	defaultSploitusLimit   = 10
 	maxSploitusLimit       = 25
-	defaultSploitusType    = "exploits"
+	defaultSploitusType    = sploitusTypeExploits
 	sploitusRequestTimeout = 30 * time.Second
+	sploitusCacheNamespace = "sploitus"
+	sploitusCacheTTL       = time.Hour
+
+	sploitusTypeExploits = "exploits"
+	sploitusTypeTools    = "tools"
+	sploitusTypeAll      = "all" // queries both exploits and tools
 
-	// Hard limits to prevent memory overflow and excessive response sizes
-	maxSourceSize       = 50 * 1024 // 50 KB max per source field
-	maxTotalResultSize  = 80 * 1024 // 80 KB total output limit
-	truncationMsgBuffer = 500       // Reserve space for truncation message
+	// Size limits of the output are set by ResultSizeLimits of the flow
+	truncationMsgBuffer = 500 // Reserve space for truncation message, see ResultBudget
+	summaryLineBuffer   = 512 // Reserve space for displaying summary line
 )
 
 // sploitus represents the Sploitus exploit search tool
@@ -40,6 +58,7 @@ type sploitus struct {
 	taskID    *int64
 	subtaskID *int64
 	slp       SearchLogProvider
+	cache     Cache
 }
 
 // NewSploitusTool creates a new Sploitus search tool instance
@@ -48,6 +67,7 @@ func NewSploitusTool(
 	flowID int64,
 	taskID, subtaskID *int64,
 	slp SearchLogProvider,
+	cache Cache,
 ) Tool {
 	return &sploitus{
 		cfg:       cfg,
@@ -55,6 +75,7 @@ func NewSploitusTool(
 		taskID:    taskID,
 		subtaskID: subtaskID,
 		slp:       slp,
+		cache:     cache,
 	}
 }
 
@@ -94,14 +115,33 @@ func (s *sploitus) Handle(ctx context.Context, name string, args json.RawMessage
 		limit = defaultSploitusLimit
 	}
 
+	filters := newSploitusFilters(action)
+
+	if action.Continuation != "" && exploitType == sploitusTypeAll {
+		return fmt.Sprintf("failed to search in Sploitus: continuation isn't supported for '%s' exploit type, "+
+			"search exploits and tools separately to get the next pages", sploitusTypeAll), nil
+	}
+	cursor, err := decodeContinuation(name, args, action.Continuation)
+	if err != nil {
+		logger.WithError(err).Warn("failed to decode sploitus continuation")
+		return fmt.Sprintf("failed to search in Sploitus: %v: repeat the search without continuation", err), nil
+	}
+
 	logger = logger.WithFields(logrus.Fields{
 		"query":        action.Query[:min(len(action.Query), 1000)],
 		"exploit_type": exploitType,
 		"sort":         sort,
 		"limit":        limit,
+		"language":     action.Language,
+		"platform":     action.Platform,
+		"offset":       cursor.Offset,
+		"skip":         cursor.Skip,
 	})
 
-	result, err := s.search(ctx, action.Query, exploitType, sort, limit)
+	page, err := s.search(ctx, action.Query, exploitType, sort, limit, filters, cursor)
+	if err == nil && page.next != nil {
+		page.Continuation, err = encodeContinuation(name, args, *page.next)
+	}
 	if err != nil {
 		observation.Event(
 			langfuse.WithEventName("sploitus search error swallowed"),
@@ -115,14 +155,23 @@ func (s *sploitus) Handle(ctx context.Context, name string, args json.RawMessage
 				"exploit_type": exploitType,
 				"sort":         sort,
 				"limit":        limit,
+				"language":     action.Language,
+				"platform":     action.Platform,
 				"error":        err.Error(),
 			}),
 		)
 
+		var reqErr *SploitusRequestError
+		if errors.As(err, &reqErr) && reqErr.Temporary() {
+			logger.WithError(err).Warn("sploitus search is temporarily unavailable")
+			return fmt.Sprintf("failed to search in Sploitus: %v, use other search tools until then", err), nil
+		}
+
 		logger.WithError(err).Error("failed to search in Sploitus")
 		return fmt.Sprintf("failed to search in Sploitus: %v", err), nil
 	}
 
+	result := page.String()
 	if agentCtx, ok := GetAgentContext(ctx); ok {
 		_, _ = s.slp.PutLog(
 			ctx,
@@ -139,31 +188,187 @@ func (s *sploitus) Handle(ctx context.Context, name string, args json.RawMessage
 	return result, nil
 }
 
-// search calls the Sploitus API and returns a formatted markdown result string
-func (s *sploitus) search(ctx context.Context, query, exploitType, sort string, limit int) (string, error) {
+// sploitusPage is the formatted result of the search and the cursor of the next page if there is one
+type sploitusPage struct {
+	ToolResult
+	next *continuationCursor
+}
+
+// search calls the Sploitus API and returns a formatted markdown result page starting from the cursor,
+// local filters are applied to the received items before the limit
+func (s *sploitus) search(
+	ctx context.Context,
+	query, exploitType, sort string,
+	limit int,
+	filters []sploitusFilter,
+	cursor continuationCursor,
+) (sploitusPage, error) {
+	// the API doesn't know about EPSS, so relevance order is requested and exploits are sorted locally
+	apiSort := sort
+	if sort == sploitusSortEPSS {
+		apiSort = sploitusDefaultSort
+	}
+
+	if exploitType != sploitusTypeAll {
+		apiResp, err := s.request(ctx, query, exploitType, apiSort, cursor.Offset)
+		if err != nil {
+			return sploitusPage{}, err
+		}
+
+		received := len(apiResp.Exploits)
+		apiResp = s.postprocess(apiResp, filters)
+		if exploitType == sploitusTypeExploits {
+			apiResp = s.enrichEPSS(ctx, query, apiResp, sort == sploitusSortEPSS)
+		}
+		apiResp.Exploits = apiResp.Exploits[min(cursor.Skip, len(apiResp.Exploits)):]
+
+		content, stats := formatSploitusPage(query, exploitType, limit, apiResp, resultSizeLimitsFromContext(ctx))
+		page := sploitusPage{ToolResult: ToolResult{Content: content}}
+		switch {
+		case stats.shown == 0:
+			// nothing fits the page, so the next page can't move forward
+		case stats.shown < len(apiResp.Exploits):
+			page.next = &continuationCursor{Offset: cursor.Offset, Skip: cursor.Skip + stats.shown}
+		case received > 0 && cursor.Offset+received < apiResp.ExploitsTotal:
+			page.next = &continuationCursor{Offset: cursor.Offset + received}
+		}
+
+		return page, nil
+	}
+
+	exploitsResp, exploitsErr := s.request(ctx, query, sploitusTypeExploits, apiSort, 0)
+	toolsResp, toolsErr := s.request(ctx, query, sploitusTypeTools, apiSort, 0)
+	exploitsResp = s.postprocess(exploitsResp, filters)
+	toolsResp = s.postprocess(toolsResp, filters)
+	if exploitsErr == nil {
+		exploitsResp = s.enrichEPSS(ctx, query, exploitsResp, sort == sploitusSortEPSS)
+	}
+	if exploitsErr != nil && toolsErr != nil {
+		return sploitusPage{}, fmt.Errorf("both exploits and tools searches failed: %w", errors.Join(exploitsErr, toolsErr))
+	}
+
+	content := formatSploitusCombinedResults(query, limit,
+		sploitusSection{resp: exploitsResp, err: exploitsErr},
+		sploitusSection{resp: toolsResp, err: toolsErr},
+		resultSizeLimitsFromContext(ctx),
+	)
+
+	return sploitusPage{ToolResult: ToolResult{Content: content}}, nil
+}
+
+// postprocess applies local filters and de-duplication to the received items before the limit,
+// so the agent gets up to limit distinct results
+func (s *sploitus) postprocess(resp sploitusResponse, filters []sploitusFilter) sploitusResponse {
+	resp = applySploitusFilters(resp, filters)
+	if s.dedupEnabled() {
+		resp = dedupSploitusExploits(resp)
+	}
+
+	return resp
+}
+
+// request performs a single Sploitus API call for the given search type and offset,
+// successful responses are kept in the shared cache to avoid repeated calls
+func (s *sploitus) request(ctx context.Context, query, exploitType, sort string, offset int) (sploitusResponse, error) {
+	var apiResp sploitusResponse
+
+	cacheKey := normalizeCacheKey(exploitType, sort, query)
+	if offset > 0 {
+		cacheKey = normalizeCacheKey(exploitType, sort, query, strconv.Itoa(offset))
+	}
+	if getCachedJSON(ctx, s.cache, sploitusCacheNamespace, cacheKey, &apiResp) {
+		return apiResp, nil
+	}
+
+	apiResp, err := s.fetch(ctx, query, exploitType, sort, offset)
+	if err != nil {
+		return apiResp, err
+	}
+
+	setCachedJSON(ctx, s.cache, sploitusCacheNamespace, cacheKey, apiResp, sploitusCacheTTL)
+
+	return apiResp, nil
+}
+
+// fetch performs the HTTP request to the Sploitus API retrying rate limited and server error
+// responses with exponential backoff until the configured number of attempts is reached
+func (s *sploitus) fetch(ctx context.Context, query, exploitType, sort string, offset int) (sploitusResponse, error) {
+	var apiResp sploitusResponse
+
 	reqBody := sploitusRequest{
 		Query:  query,
 		Type:   exploitType,
 		Sort:   sort,
 		Title:  false, // search only for titles
-		Offset: 0,
+		Offset: offset,
 	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
+		return apiResp, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	client, err := system.GetHTTPClient(s.cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to create http client: %w", err)
+		return apiResp, fmt.Errorf("failed to create http client: %w", err)
 	}
 
 	client.Timeout = sploitusRequestTimeout
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sploitusAPIURL, bytes.NewReader(bodyBytes))
+	maxAttempts := s.maxAttempts()
+	for attempt := 1; ; attempt++ {
+		resp, err := s.doRequest(ctx, client, query, bodyBytes)
+		if err != nil {
+			return apiResp, err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+				return apiResp, fmt.Errorf("failed to decode Sploitus response: %w", err)
+			}
+			return apiResp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		resp.Body.Close()
+
+		reqErr := &SploitusRequestError{StatusCode: resp.StatusCode, Attempts: attempt, RetryAfter: retryAfter}
+		if !reqErr.Temporary() || attempt >= maxAttempts {
+			return apiResp, reqErr
+		}
+
+		delay := retryAfter
+		if delay <= 0 {
+			delay = sploitusBackoff(attempt)
+		}
+
+		// don't wait if the context expires before the next attempt
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return apiResp, reqErr
+		}
+
+		s.logRetry(ctx, query, fmt.Sprintf(
+			"Sploitus API returned HTTP %d, retry attempt %d of %d in %s",
+			resp.StatusCode, attempt+1, maxAttempts, delay.Round(time.Millisecond),
+		))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			reqErr.Err = ctx.Err()
+			return apiResp, reqErr
+		case <-timer.C:
+		}
+	}
+}
+
+// doRequest sends a single request to the Sploitus API
+func (s *sploitus) doRequest(ctx context.Context, client *http.Client, query string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sploitusAPIURL, bytes.NewReader(body))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Build referer with query to mimic browser behavior
@@ -186,25 +391,126 @@ func (s *sploitus) search(ctx context.Context, query, exploitType, sort string,
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request to Sploitus failed: %w", err)
+		return nil, fmt.Errorf("request to Sploitus failed: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Sploitus API returns 499 when rate limit is temporarily exceeded
-	if resp.StatusCode == 499 || resp.StatusCode == 422 {
-		return "", fmt.Errorf("Sploitus API rate limit exceeded (HTTP %d), please try again later", resp.StatusCode)
+	return resp, nil
+}
+
+// logRetry writes the retry attempt to the search log to make it visible in the flow
+func (s *sploitus) logRetry(ctx context.Context, query, message string) {
+	logrus.WithContext(ctx).WithFields(enrichLogrusFields(s.flowID, s.taskID, s.subtaskID, logrus.Fields{
+		"tool":  SploitusToolName,
+		"query": query[:min(len(query), 1000)],
+	})).Warn(message)
+
+	if s.slp == nil {
+		return
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Sploitus API returned HTTP %d", resp.StatusCode)
+	if agentCtx, ok := GetAgentContext(ctx); ok {
+		_, _ = s.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeSploitus,
+			query,
+			message,
+			s.taskID,
+			s.subtaskID,
+		)
 	}
+}
 
-	var apiResp sploitusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("failed to decode Sploitus response: %w", err)
+func (s *sploitus) maxAttempts() int {
+	if s.cfg == nil {
+		return 1
+	}
+
+	return max(s.cfg.SploitusMaxAttempts, 1)
+}
+
+// sploitusBackoff returns exponential delay before the next attempt with jitter in range [d/2, d)
+// to spread retries of concurrent agents
+func sploitusBackoff(attempt int) time.Duration {
+	delay := sploitusRetryMaxDelay
+	if attempt < 16 {
+		delay = min(sploitusRetryBaseDelay<<(attempt-1), sploitusRetryMaxDelay)
 	}
 
-	return formatSploitusResults(query, exploitType, limit, apiResp), nil
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + rand.N(half)
+}
+
+// parseRetryAfter converts Retry-After header value in seconds or HTTP date format to the delay
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
+
+// SploitusRequestError is returned when the Sploitus API responds with an unsuccessful status,
+// unlike decode errors temporary failures may succeed if the search is repeated later
+type SploitusRequestError struct {
+	StatusCode int
+	Attempts   int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *SploitusRequestError) Error() string {
+	var reason string
+	if isSploitusRateLimitStatus(e.StatusCode) {
+		reason = fmt.Sprintf("Sploitus API rate limit exceeded (HTTP %d)", e.StatusCode)
+	} else {
+		reason = fmt.Sprintf("Sploitus API returned HTTP %d", e.StatusCode)
+	}
+
+	if e.Attempts > 1 {
+		reason += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+	if e.Err != nil {
+		reason += fmt.Sprintf(": %v", e.Err)
+	}
+	if e.Temporary() || isSploitusRateLimitStatus(e.StatusCode) {
+		reason += ", please try again later"
+	}
+
+	return reason
+}
+
+func (e *SploitusRequestError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary returns true if the request failed because of rate limit or server side error
+func (e *SploitusRequestError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == 499 || e.StatusCode >= 500
+}
+
+// isSploitusRateLimitStatus reports statuses which Sploitus API returns when rate limit is exceeded
+func isSploitusRateLimitStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, 499, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
 }
 
 // IsAvailable returns true if the Sploitus tool is enabled and configured
@@ -216,6 +522,69 @@ func (s *sploitus) enabled() bool {
 	return s.cfg != nil && s.cfg.SploitusEnabled
 }
 
+func (s *sploitus) dedupEnabled() bool {
+	return s.cfg != nil && s.cfg.SploitusDedupEnabled
+}
+
+func (s *sploitus) epssEnabled() bool {
+	return s.cfg != nil && s.cfg.EPSSEnabled
+}
+
+// enrichEPSS annotates exploits with the highest EPSS score of their CVEs and sorts them by it
+// if requested, the annotation is optional, so lookup errors don't fail the search
+func (s *sploitus) enrichEPSS(ctx context.Context, query string, resp sploitusResponse, sortByEPSS bool) sploitusResponse {
+	if !s.epssEnabled() || len(resp.Exploits) == 0 {
+		return resp
+	}
+
+	var cves []string
+	itemCVEs := make([][]string, len(resp.Exploits))
+	for i, item := range resp.Exploits {
+		for _, cve := range sploitusCVERegexp.FindAllString(item.Title+" "+item.ID, -1) {
+			itemCVEs[i] = append(itemCVEs[i], strings.ToUpper(cve))
+		}
+		cves = append(cves, itemCVEs[i]...)
+	}
+
+	if len(cves) == 0 {
+		return resp
+	}
+
+	scores, err := newEPSSClient(s.cfg, s.cache).Lookup(ctx, cves)
+	if err != nil {
+		logrus.WithContext(ctx).WithFields(enrichLogrusFields(s.flowID, s.taskID, s.subtaskID, logrus.Fields{
+			"tool":  SploitusToolName,
+			"query": query[:min(len(query), 1000)],
+		})).WithError(err).Warn("failed to get EPSS scores of exploits")
+	}
+
+	exploits := slices.Clone(resp.Exploits)
+	for i := range exploits {
+		for _, cve := range itemCVEs[i] {
+			if score, ok := scores[cve]; ok && score.Found() && (exploits[i].epss == nil || score.EPSS > exploits[i].epss.EPSS) {
+				exploits[i].epss = &score
+			}
+		}
+	}
+
+	if sortByEPSS {
+		slices.SortStableFunc(exploits, func(a, b sploitusExploit) int {
+			switch {
+			case a.epss == nil && b.epss == nil:
+				return 0
+			case a.epss == nil:
+				return 1
+			case b.epss == nil:
+				return -1
+			}
+			return cmp.Compare(b.epss.EPSS, a.epss.EPSS)
+		})
+	}
+
+	resp.Exploits = exploits
+	return resp
+}
+
 // sploitusRequest is the JSON body sent to the Sploitus search API
 type sploitusRequest struct {
 	Query  string `json:"query"`
@@ -237,151 +606,494 @@ type sploitusExploit struct {
 	Published string  `json:"published,omitempty"` // Publication date, only for exploits
 	Source    string  `json:"source,omitempty"`    // Source code/description, only for exploits
 	Language  string  `json:"language,omitempty"`  // Programming language, only for exploits
+
+	// alternates holds links to duplicates of this record from other sources
+	alternates []sploitusAlternate
+	// origin is the search engine of the record when results of several engines are merged
+	origin string
+	// epss is the highest EPSS score of the record CVEs when EPSS enrichment is enabled
+	epss *epssScore
+}
+
+// sploitusAlternate is a link to the duplicate record which was collapsed by de-duplication
+type sploitusAlternate struct {
+	source string
+	href   string
 }
 
 // sploitusResponse is the top-level JSON response from the Sploitus API
 type sploitusResponse struct {
 	Exploits      []sploitusExploit `json:"exploits"`
 	ExploitsTotal int               `json:"exploits_total"`
+
+	// filtered records items removed from Exploits by local filters in order of application
+	filtered []sploitusFilterStat
+	// filters describes active local filters to show them in the result header
+	filters []string
+}
+
+// sploitusFilterStat holds the number of items removed by a single local filter
+type sploitusFilterStat struct {
+	name    string
+	removed int
+}
+
+// sploitusDisplayStats reconciles upstream total matches with the number of items shown:
+// received = filtered + overLimit + truncated + shown
+type sploitusDisplayStats struct {
+	total     int
+	received  int
+	filtered  []sploitusFilterStat
+	overLimit int
+	truncated int
+	shown     int
+}
+
+// sploitusSection holds the outcome of a single search type used in the combined mode
+type sploitusSection struct {
+	resp sploitusResponse
+	err  error
 }
 
 // formatSploitusResults converts a sploitusResponse into a human-readable markdown string
-func formatSploitusResults(query, exploitType string, limit int, resp sploitusResponse) string {
+// which is capped by the result size limits of the flow
+func formatSploitusResults(
+	query, exploitType string,
+	limit int,
+	resp sploitusResponse,
+	limits ResultSizeLimits,
+) string {
+	result, _ := formatSploitusPage(query, exploitType, limit, resp, limits)
+	return result
+}
+
+// formatSploitusPage renders the results like formatSploitusResults and returns the display stats
+// to find the position of the next page
+func formatSploitusPage(
+	query, exploitType string,
+	limit int,
+	resp sploitusResponse,
+	limits ResultSizeLimits,
+) (string, sploitusDisplayStats) {
 	var sb strings.Builder
 
 	sb.WriteString("# Sploitus Search Results\n\n")
 	sb.WriteString(fmt.Sprintf("**Query:** `%s`  \n", query))
 	sb.WriteString(fmt.Sprintf("**Type:** %s  \n", exploitType))
-	sb.WriteString(fmt.Sprintf("**Total matches on Sploitus:** %d\n\n", resp.ExploitsTotal))
+	if len(resp.filters) > 0 {
+		sb.WriteString(fmt.Sprintf("**Filters:** %s  \n", strings.Join(resp.filters, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("**Total matches on Sploitus:** %d  \n", resp.ExploitsTotal))
+
+	var section strings.Builder
+	stats := writeSploitusSection(&section, exploitType, limit, resp.Exploits,
+		limits.MaxResultSize-sb.Len()-summaryLineBuffer, limits.MaxSourceSize)
+	stats.total, stats.received, stats.filtered = resp.ExploitsTotal, sploitusReceived(resp), resp.filtered
+
+	sb.WriteString(fmt.Sprintf("**Displaying:** %s\n\n", stats))
 	sb.WriteString("---\n\n")
+	sb.WriteString(section.String())
 
-	// Ensure limit is positive
-	if limit < 1 {
-		limit = defaultSploitusLimit
+	return sb.String(), stats
+}
+
+// formatSploitusCombinedResults renders exploits and tools as separate sections of one result,
+// each section gets up to limit items and an equal share of the total size budget
+func formatSploitusCombinedResults(
+	query string,
+	limit int,
+	exploits, tools sploitusSection,
+	limits ResultSizeLimits,
+) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Sploitus Search Results\n\n")
+	sb.WriteString(fmt.Sprintf("**Query:** `%s`  \n", query))
+	sb.WriteString(fmt.Sprintf("**Type:** %s  \n", sploitusTypeAll))
+	// both sections are filtered by the same filters, but one of them may have failed
+	filters := exploits.resp.filters
+	if len(filters) == 0 {
+		filters = tools.resp.filters
+	}
+	if len(filters) > 0 {
+		sb.WriteString(fmt.Sprintf("**Filters:** %s  \n", strings.Join(filters, ", ")))
 	}
+	sb.WriteString(fmt.Sprintf("**Total exploit matches on Sploitus:** %d  \n", exploits.resp.ExploitsTotal))
+	sb.WriteString(fmt.Sprintf("**Total tool matches on Sploitus:** %d  \n", tools.resp.ExploitsTotal))
 
-	results := resp.Exploits
-	if len(results) > limit {
-		results = results[:limit]
+	sectionBudget := (limits.MaxResultSize - sb.Len() - 2*summaryLineBuffer) / 2
+
+	var body strings.Builder
+	for _, section := range []struct {
+		exploitType string
+		sploitusSection
+	}{
+		{sploitusTypeExploits, exploits},
+		{sploitusTypeTools, tools},
+	} {
+		if section.err != nil {
+			body.WriteString(fmt.Sprintf("## %s\n\n", sploitusSectionTitle(section.exploitType)))
+			body.WriteString(fmt.Sprintf("Failed to search %s: %v\n\n", section.exploitType, section.err))
+			continue
+		}
+
+		var sectionBuilder strings.Builder
+		stats := writeSploitusSection(&sectionBuilder, section.exploitType, limit, section.resp.Exploits,
+			sectionBudget, limits.MaxSourceSize)
+		stats.total, stats.received = section.resp.ExploitsTotal, sploitusReceived(section.resp)
+		stats.filtered = section.resp.filtered
+
+		sb.WriteString(fmt.Sprintf("**Displaying %s:** %s  \n", section.exploitType, stats))
+		body.WriteString(sectionBuilder.String())
+		body.WriteString("\n")
 	}
 
-	if len(results) == 0 {
-		switch strings.ToLower(exploitType) {
-		case "tools":
-			sb.WriteString("No security tools were found for the given query.\n")
-		default:
-			sb.WriteString("No exploits were found for the given query.\n")
+	sb.WriteString("\n---\n\n")
+	sb.WriteString(body.String())
+
+	return sb.String()
+}
+
+// sploitusFilter is a local filter of the received items, items without the filtered field
+// don't match the active filter
+type sploitusFilter struct {
+	name  string
+	value string
+	match func(item sploitusExploit) bool
+}
+
+// newSploitusFilters builds local filters from the action, empty values don't add a filter
+func newSploitusFilters(action SploitusAction) []sploitusFilter {
+	var filters []sploitusFilter
+
+	if language := strings.TrimSpace(action.Language); language != "" {
+		filters = append(filters, sploitusFilter{
+			name:  "language",
+			value: language,
+			match: func(item sploitusExploit) bool {
+				return strings.EqualFold(strings.TrimSpace(item.Language), language)
+			},
+		})
+	}
+
+	if platform := strings.TrimSpace(action.Platform); platform != "" {
+		filters = append(filters, sploitusFilter{
+			name:  "platform",
+			value: platform,
+			match: func(item sploitusExploit) bool {
+				return strings.EqualFold(strings.TrimSpace(item.Type), platform)
+			},
+		})
+	}
+
+	return filters
+}
+
+// applySploitusFilters removes items which don't match filters and records the number
+// of removed items for each filter in order of application
+func applySploitusFilters(resp sploitusResponse, filters []sploitusFilter) sploitusResponse {
+	if len(filters) == 0 {
+		return resp
+	}
+
+	items := resp.Exploits
+	for _, filter := range filters {
+		kept := make([]sploitusExploit, 0, len(items))
+		for _, item := range items {
+			if filter.match(item) {
+				kept = append(kept, item)
+			}
 		}
-		return sb.String()
+
+		resp.filtered = append(resp.filtered, sploitusFilterStat{filter.name, len(items) - len(kept)})
+		resp.filters = append(resp.filters, fmt.Sprintf("%s=%s", filter.name, filter.value))
+		items = kept
+	}
+	resp.Exploits = items
+
+	return resp
+}
+
+var (
+	sploitusCVERegexp       = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+	sploitusTitleCleanupExp = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// sploitusDedupKeys returns keys which identify the same exploit: CVE IDs from the title and ID
+// and the normalized title, records sharing any key are duplicates
+func sploitusDedupKeys(item sploitusExploit) []string {
+	var keys []string
+	for _, cve := range sploitusCVERegexp.FindAllString(item.Title+" "+item.ID, -1) {
+		keys = append(keys, "cve:"+strings.ToUpper(cve))
 	}
 
-	// Track total size to enforce hard limit
-	currentSize := len(sb.String())
-	actualShown := 0
-	truncatedBySize := false
+	title := strings.TrimSpace(sploitusTitleCleanupExp.ReplaceAllString(strings.ToLower(item.Title), " "))
+	if title != "" {
+		keys = append(keys, "title:"+title)
+	}
 
-	switch strings.ToLower(exploitType) {
-	case "tools":
-		sb.WriteString(fmt.Sprintf("## Security Tools (showing up to %d)\n\n", len(results)))
-		currentSize = len(sb.String())
+	return keys
+}
+
+// sploitusPreferred reports whether candidate should replace current as the group representative:
+// higher score wins, then the most recent publication date
+func sploitusPreferred(candidate, current sploitusExploit) bool {
+	if candidate.Score != current.Score {
+		return candidate.Score > current.Score
+	}
+	return candidate.Published > current.Published
+}
 
-		for i, item := range results {
-			// Check if we're approaching the size limit (reserve space for truncation message)
-			if currentSize >= maxTotalResultSize-truncationMsgBuffer {
-				truncatedBySize = true
+// dedupSploitusExploits collapses records sharing the same CVE or normalized title into one,
+// links of the collapsed records are kept as alternates, groups keep the order of the first record
+func dedupSploitusExploits(resp sploitusResponse) sploitusResponse {
+	type group struct {
+		best    sploitusExploit
+		members []sploitusExploit
+	}
+
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, item := range resp.Exploits {
+		keys := sploitusDedupKeys(item)
+
+		var grp *group
+		for _, key := range keys {
+			if grp = byKey[key]; grp != nil {
 				break
 			}
+		}
+		if grp == nil {
+			grp = &group{best: item}
+			groups = append(groups, grp)
+		} else if sploitusPreferred(item, grp.best) {
+			grp.best = item
+		}
+		grp.members = append(grp.members, item)
 
-			var itemBuilder strings.Builder
-			itemBuilder.WriteString(fmt.Sprintf("### %d. %s\n\n", i+1, item.Title))
-			if item.Href != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**URL:** %s  \n", item.Href))
+		for _, key := range keys {
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = grp
 			}
-			if item.Download != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**Download:** %s  \n", item.Download))
-			}
-			if item.Type != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**Source Type:** %s  \n", item.Type))
-			}
-			if item.ID != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**ID:** %s  \n", item.ID))
-			}
-			itemBuilder.WriteString("\n---\n\n")
+		}
+	}
 
-			itemContent := itemBuilder.String()
-			// Check if adding this item would exceed limit (with buffer for truncation msg)
-			if currentSize+len(itemContent) > maxTotalResultSize-truncationMsgBuffer {
-				truncatedBySize = true
-				break
+	items := make([]sploitusExploit, 0, len(groups))
+	for _, grp := range groups {
+		best := grp.best
+		best.alternates = nil
+
+		seen := map[string]struct{}{best.Href: {}}
+		for _, member := range grp.members {
+			if _, ok := seen[member.Href]; ok || member.Href == "" {
+				continue
 			}
+			seen[member.Href] = struct{}{}
+			best.alternates = append(best.alternates, sploitusAlternate{
+				source: sploitusSourceLabel(member),
+				href:   member.Href,
+			})
+		}
+
+		items = append(items, best)
+	}
+
+	resp.filtered = append(resp.filtered, sploitusFilterStat{"dedup", len(resp.Exploits) - len(items)})
+	resp.Exploits = items
+
+	return resp
+}
 
-			sb.WriteString(itemContent)
-			currentSize += len(itemContent)
-			actualShown++
+// sploitusSourceLabel returns the source type of the record prefixed by the origin engine if it's known
+func sploitusSourceLabel(item sploitusExploit) string {
+	switch {
+	case item.origin == "":
+		return item.Type
+	case item.Type == "":
+		return item.origin
+	default:
+		return item.origin + "/" + item.Type
+	}
+}
+
+// writeSploitusAlternates renders links to the collapsed duplicates of the record
+func writeSploitusAlternates(sb *strings.Builder, alternates []sploitusAlternate) {
+	if len(alternates) == 0 {
+		return
+	}
+
+	sb.WriteString("**Alternate sources:**\n")
+	for _, alt := range alternates {
+		if alt.source != "" {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", alt.source, alt.href))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s\n", alt.href))
 		}
+	}
+}
 
-	default: // "exploits" or anything else
-		sb.WriteString(fmt.Sprintf("## Exploits (showing up to %d)\n\n", len(results)))
-		currentSize = len(sb.String())
+// sploitusReceived returns the number of items received from the API before local filtering
+func sploitusReceived(resp sploitusResponse) int {
+	received := len(resp.Exploits)
+	for _, stat := range resp.filtered {
+		received += stat.removed
+	}
+	return received
+}
 
-		for i, item := range results {
-			// Check if we're approaching the size limit (reserve space for truncation message)
-			if currentSize >= maxTotalResultSize-truncationMsgBuffer {
-				truncatedBySize = true
-				break
-			}
+// String renders the summary line, e.g. "3 of 80 matches after filters (received 10: ...)"
+func (ds sploitusDisplayStats) String() string {
+	var (
+		removed int
+		details []string
+	)
+	for _, stat := range ds.filtered {
+		removed += stat.removed
+		details = append(details, fmt.Sprintf("%s: %d", stat.name, stat.removed))
+	}
 
-			var itemBuilder strings.Builder
-			itemBuilder.WriteString(fmt.Sprintf("### %d. %s\n\n", i+1, item.Title))
-			if item.Href != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**URL:** %s  \n", item.Href))
-			}
-			if item.Score > 0 {
-				itemBuilder.WriteString(fmt.Sprintf("**CVSS Score:** %.1f  \n", item.Score))
-			}
-			if item.Type != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**Type:** %s  \n", item.Type))
-			}
-			if item.Published != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**Published:** %s  \n", item.Published))
-			}
-			if item.ID != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**ID:** %s  \n", item.ID))
-			}
-			if item.Language != "" {
-				itemBuilder.WriteString(fmt.Sprintf("**Language:** %s  \n", item.Language))
-			}
+	filtered := fmt.Sprintf("%d removed by filters", removed)
+	if len(details) > 0 {
+		filtered += fmt.Sprintf(" [%s]", strings.Join(details, ", "))
+	}
 
-			// Truncate source if it's too large (hard limit: 50 KB)
-			if item.Source != "" {
-				sourcePreview := item.Source
-				if len(sourcePreview) > maxSourceSize {
-					sourcePreview = sourcePreview[:maxSourceSize] + "\n... [source truncated, exceeded 50 KB limit]"
-				}
-				itemBuilder.WriteString(fmt.Sprintf("\n**Source Preview:**\n```\n%s\n```\n", sourcePreview))
-			}
-			itemBuilder.WriteString("\n---\n\n")
+	return fmt.Sprintf(
+		"%d of %d matches after filters (received %d: %s, %d over limit, %d truncated by size)",
+		ds.shown, max(ds.total, ds.received), ds.received, filtered, ds.overLimit, ds.truncated,
+	)
+}
 
-			itemContent := itemBuilder.String()
-			// Check if adding this item would exceed limit (with buffer for truncation msg)
-			if currentSize+len(itemContent) > maxTotalResultSize-truncationMsgBuffer {
-				truncatedBySize = true
-				break
-			}
+// sploitusSectionTitle returns the markdown section title for the search type
+func sploitusSectionTitle(exploitType string) string {
+	if strings.ToLower(exploitType) == sploitusTypeTools {
+		return "Security Tools"
+	}
+	return "Exploits"
+}
+
+// writeSploitusSection renders up to limit items of the given type into sb
+// while keeping the whole builder content under sizeLimit bytes and each source under sourceLimit bytes
+func writeSploitusSection(
+	sb *strings.Builder,
+	exploitType string,
+	limit int,
+	items []sploitusExploit,
+	sizeLimit int,
+	sourceLimit int,
+) sploitusDisplayStats {
+	// Ensure limit is positive
+	if limit < 1 {
+		limit = defaultSploitusLimit
+	}
+
+	var stats sploitusDisplayStats
+
+	results := items
+	if len(results) > limit {
+		stats.overLimit = len(results) - limit
+		results = results[:limit]
+	}
+
+	isTools := strings.ToLower(exploitType) == sploitusTypeTools
+
+	if len(results) == 0 {
+		if isTools {
+			sb.WriteString("No security tools were found for the given query.\n")
+		} else {
+			sb.WriteString("No exploits were found for the given query.\n")
+		}
+		return stats
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s (showing up to %d)\n\n", sploitusSectionTitle(exploitType), len(results)))
 
-			sb.WriteString(itemContent)
-			currentSize += len(itemContent)
-			actualShown++
+	budget := NewResultBudget(sizeLimit, sb.Len())
+	for i, item := range results {
+		var itemContent string
+		if isTools {
+			itemContent = formatSploitusTool(i+1, item)
+		} else {
+			itemContent = formatSploitusExploit(i+1, item, sourceLimit)
+		}
+
+		if !budget.Add(sb, itemContent) {
+			break
 		}
 	}
 
 	// Add warning if results were truncated due to size limit
-	if truncatedBySize {
+	if budget.Truncated() {
 		sb.WriteString(fmt.Sprintf(
 			"\n\n**⚠️ Note:** Results truncated after %d items due to %d bytes size limit. Total shown: %d of %d available.\n",
-			actualShown, maxTotalResultSize, actualShown, len(results),
+			budget.Added(), sizeLimit, budget.Added(), len(results),
 		))
 	}
 
-	return sb.String()
+	stats.shown = budget.Added()
+	stats.truncated = len(results) - budget.Added()
+
+	return stats
+}
+
+// formatSploitusTool renders a single security tool record
+func formatSploitusTool(idx int, item sploitusExploit) string {
+	var itemBuilder strings.Builder
+
+	itemBuilder.WriteString(fmt.Sprintf("### %d. %s\n\n", idx, item.Title))
+	if item.Href != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**URL:** %s  \n", item.Href))
+	}
+	if item.Download != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**Download:** %s  \n", item.Download))
+	}
+	if item.Type != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**Source Type:** %s  \n", item.Type))
+	}
+	if item.ID != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**ID:** %s  \n", item.ID))
+	}
+	writeSploitusAlternates(&itemBuilder, item.alternates)
+	itemBuilder.WriteString("\n---\n\n")
+
+	return itemBuilder.String()
+}
+
+// formatSploitusExploit renders a single exploit record, the source is cut to sourceLimit bytes
+func formatSploitusExploit(idx int, item sploitusExploit, sourceLimit int) string {
+	var itemBuilder strings.Builder
+
+	itemBuilder.WriteString(fmt.Sprintf("### %d. %s\n\n", idx, item.Title))
+	if item.Href != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**URL:** %s  \n", item.Href))
+	}
+	if item.origin != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**Source:** %s  \n", item.origin))
+	}
+	if item.Score > 0 {
+		itemBuilder.WriteString(fmt.Sprintf("**CVSS Score:** %.1f  \n", item.Score))
+	}
+	if item.epss != nil {
+		itemBuilder.WriteString(fmt.Sprintf("**EPSS:** %s %s  \n", item.epss.CVE, item.epss))
+	}
+	if item.Type != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**Type:** %s  \n", item.Type))
+	}
+	if item.Published != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**Published:** %s  \n", item.Published))
+	}
+	if item.ID != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**ID:** %s  \n", item.ID))
+	}
+	if item.Language != "" {
+		itemBuilder.WriteString(fmt.Sprintf("**Language:** %s  \n", item.Language))
+	}
+	writeSploitusAlternates(&itemBuilder, item.alternates)
+
+	if item.Source != "" {
+		sourcePreview := TruncateWithNotice(item.Source, sourceLimit, sourceTruncatedNotice)
+		itemBuilder.WriteString(fmt.Sprintf("\n**Source Preview:**\n```\n%s\n```\n", sourcePreview))
+	}
+	itemBuilder.WriteString("\n---\n\n")
+
+	return itemBuilder.String()
 }
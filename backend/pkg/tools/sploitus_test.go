@@ -1,11 +1,19 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"pentagi/pkg/config"
 	"pentagi/pkg/database"
@@ -77,7 +85,7 @@ func TestSploitusHandle(t *testing.T) {
 		ExternalSSLCAPath: proxy.CACertPath(),
 	}
 
-	sp := NewSploitusTool(cfg, flowID, &taskID, &subtaskID, slp)
+	sp := NewSploitusTool(cfg, flowID, &taskID, &subtaskID, slp, nil)
 
 	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
 	got, err := sp.Handle(
@@ -308,6 +316,7 @@ func TestSploitusFormatResults(t *testing.T) {
 		limit       int
 		response    sploitusResponse
 		expected    []string
+		unexpected  []string
 	}{
 		{
 			name:        "exploits formatting",
@@ -390,6 +399,32 @@ func TestSploitusFormatResults(t *testing.T) {
 				"**Download:** https://github.com/tool2",
 			},
 		},
+		{
+			name:        "language filter",
+			query:       "CVE-2026",
+			exploitType: "exploits",
+			limit:       5,
+			response: applySploitusFilters(sploitusResponse{
+				Exploits: []sploitusExploit{
+					{ID: "TEST-001", Title: "Python Exploit", Type: "githubexploit", Language: "python"},
+					{ID: "TEST-002", Title: "Ruby Exploit", Type: "githubexploit", Language: "RUBY"},
+					{ID: "TEST-003", Title: "Unknown Language Exploit", Type: "packetstorm"},
+					{ID: "TEST-004", Title: "Another Python Exploit", Type: "exploitdb", Language: "PYTHON"},
+				},
+				ExploitsTotal: 40,
+			}, newSploitusFilters(SploitusAction{Language: "Python"})),
+			expected: []string{
+				"**Filters:** language=Python",
+				"**Total matches on Sploitus:** 40",
+				"2 of 40 matches after filters (received 4: 2 removed by filters [language: 2]",
+				"### 1. Python Exploit",
+				"### 2. Another Python Exploit",
+			},
+			unexpected: []string{
+				"Ruby Exploit",
+				"Unknown Language Exploit",
+			},
+		},
 		{
 			name:        "empty results",
 			query:       "nonexistent",
@@ -409,13 +444,18 @@ func TestSploitusFormatResults(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatSploitusResults(tt.query, tt.exploitType, tt.limit, tt.response)
+			result := formatSploitusResults(tt.query, tt.exploitType, tt.limit, tt.response, ResultSizeLimits{}.resolve())
 
 			for _, expectedStr := range tt.expected {
 				if !strings.Contains(result, expectedStr) {
 					t.Errorf("expected result to contain %q\nGot:\n%s", expectedStr, result)
 				}
 			}
+			for _, unexpectedStr := range tt.unexpected {
+				if strings.Contains(result, unexpectedStr) {
+					t.Errorf("expected result not to contain %q\nGot:\n%s", unexpectedStr, result)
+				}
+			}
 		})
 	}
 }
@@ -482,7 +522,7 @@ func TestSploitusSizeLimits(t *testing.T) {
 			ExploitsTotal: 1,
 		}
 
-		result := formatSploitusResults("test", "exploits", 10, resp)
+		result := formatSploitusResults("test", "exploits", 10, resp, ResultSizeLimits{}.resolve())
 
 		// Check that source was truncated
 		if !strings.Contains(result, "source truncated, exceeded 50 KB limit") {
@@ -512,7 +552,7 @@ func TestSploitusSizeLimits(t *testing.T) {
 			ExploitsTotal: 100,
 		}
 
-		result := formatSploitusResults("test", "exploits", 100, resp)
+		result := formatSploitusResults("test", "exploits", 100, resp, ResultSizeLimits{}.resolve())
 
 		// Result should be under 80 KB
 		if len(result) > 80*1024 {
@@ -562,7 +602,7 @@ func TestSploitusMaxResultsClamp(t *testing.T) {
 				}
 			}
 
-			result := formatSploitusResults("test", "exploits", tt.maxResults, resp)
+			result := formatSploitusResults("test", "exploits", tt.maxResults, resp, ResultSizeLimits{}.resolve())
 
 			// Count how many results are shown (### is used for each result title)
 			count := strings.Count(result, "### ")
@@ -573,3 +613,960 @@ func TestSploitusMaxResultsClamp(t *testing.T) {
 		})
 	}
 }
+
+var sploitusSummaryRegex = regexp.MustCompile(
+	`(\d+) of (\d+) matches after filters \(received (\d+): (\d+) removed by filters(?: \[[^\]]*\])?, (\d+) over limit, (\d+) truncated by size\)`,
+)
+
+// parseSploitusSummary extracts shown, total, received, filtered, over limit and truncated counts
+func parseSploitusSummary(t *testing.T, line string) [6]int {
+	t.Helper()
+
+	m := sploitusSummaryRegex.FindStringSubmatch(line)
+	if m == nil {
+		t.Fatalf("summary line not found in:\n%s", line)
+	}
+
+	var counts [6]int
+	for i := range counts {
+		counts[i], _ = strconv.Atoi(m[i+1])
+	}
+	return counts
+}
+
+func TestSploitusDisplaySummary(t *testing.T) {
+	makeResp := func(n, total, sourceSize int) sploitusResponse {
+		resp := sploitusResponse{Exploits: make([]sploitusExploit, n), ExploitsTotal: total}
+		for i := range resp.Exploits {
+			resp.Exploits[i] = sploitusExploit{
+				ID:     fmt.Sprintf("TEST-%d", i),
+				Title:  fmt.Sprintf("Test %d", i),
+				Href:   "https://example.com",
+				Source: strings.Repeat("X", sourceSize),
+			}
+		}
+		return resp
+	}
+
+	filteredResp := makeResp(4, 50, 0)
+	filteredResp.filtered = []sploitusFilterStat{{"score", 3}, {"dedup", 2}}
+
+	tests := []struct {
+		name      string
+		limit     int
+		resp      sploitusResponse
+		shown     int
+		total     int
+		received  int
+		filtered  int
+		overLimit int
+		contains  string
+	}{
+		{
+			name:      "limit cuts received items",
+			limit:     5,
+			resp:      makeResp(10, 120, 0),
+			shown:     5,
+			total:     120,
+			received:  10,
+			overLimit: 5,
+		},
+		{
+			name:     "local filters are accounted",
+			limit:    10,
+			resp:     filteredResp,
+			shown:    4,
+			total:    50,
+			received: 9,
+			filtered: 5,
+			contains: "5 removed by filters [score: 3, dedup: 2]",
+		},
+		{
+			name:     "total smaller than received is normalized",
+			limit:    10,
+			resp:     makeResp(3, 0, 0),
+			shown:    3,
+			total:    3,
+			received: 3,
+		},
+		{
+			name:     "empty results",
+			limit:    10,
+			resp:     makeResp(0, 0, 0),
+			contains: "0 of 0 matches after filters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := formatSploitusResults("test", sploitusTypeExploits, tt.limit, tt.resp, ResultSizeLimits{}.resolve())
+			counts := parseSploitusSummary(t, result)
+
+			want := [6]int{tt.shown, tt.total, tt.received, tt.filtered, tt.overLimit, 0}
+			if counts != want {
+				t.Errorf("expected counts %v, got %v\n%s", want, counts, result)
+			}
+			if got := strings.Count(result, "### "); got != counts[0] {
+				t.Errorf("summary reports %d shown items, rendered %d", counts[0], got)
+			}
+			if tt.contains != "" && !strings.Contains(result, tt.contains) {
+				t.Errorf("expected result to contain %q\nGot:\n%s", tt.contains, result)
+			}
+		})
+	}
+
+	t.Run("counts reconcile with size truncation", func(t *testing.T) {
+		resp := makeResp(25, 300, 5000)
+		resp.filtered = []sploitusFilterStat{{"language", 4}}
+
+		result := formatSploitusResults("test", sploitusTypeExploits, 20, resp, ResultSizeLimits{}.resolve())
+		counts := parseSploitusSummary(t, result)
+		shown, received, filtered, overLimit, truncated := counts[0], counts[2], counts[3], counts[4], counts[5]
+
+		if truncated == 0 {
+			t.Fatalf("expected size truncation, got %v", counts)
+		}
+		if received != filtered+overLimit+truncated+shown {
+			t.Errorf("counts don't reconcile: %v", counts)
+		}
+		if got := strings.Count(result, "### "); got != shown {
+			t.Errorf("summary reports %d shown items, rendered %d", shown, got)
+		}
+		if len(result) > DefaultMaxResultSize {
+			t.Errorf("result size %d exceeds %d bytes limit", len(result), DefaultMaxResultSize)
+		}
+	})
+
+	t.Run("combined mode has summary per section", func(t *testing.T) {
+		exploits := sploitusSection{resp: makeResp(8, 80, 0)}
+		tools := sploitusSection{resp: makeResp(2, 2, 0)}
+
+		result := formatSploitusCombinedResults("nginx", 3, exploits, tools, ResultSizeLimits{}.resolve())
+
+		for _, expected := range []string{
+			"**Displaying exploits:** 3 of 80 matches after filters (received 8: 0 removed by filters, 5 over limit, 0 truncated by size)",
+			"**Displaying tools:** 2 of 2 matches after filters (received 2: 0 removed by filters, 0 over limit, 0 truncated by size)",
+		} {
+			if !strings.Contains(result, expected) {
+				t.Errorf("expected result to contain %q\nGot:\n%s", expected, result)
+			}
+		}
+	})
+
+	t.Run("combined mode skips summary of failed section", func(t *testing.T) {
+		exploits := sploitusSection{resp: makeResp(1, 1, 0)}
+		tools := sploitusSection{err: fmt.Errorf("Sploitus API returned HTTP 500")}
+
+		result := formatSploitusCombinedResults("nginx", 3, exploits, tools, ResultSizeLimits{}.resolve())
+
+		if strings.Contains(result, "**Displaying tools:**") {
+			t.Errorf("expected no summary for failed section, got:\n%s", result)
+		}
+		if !strings.Contains(result, "**Displaying exploits:** 1 of 1") {
+			t.Errorf("expected exploits summary, got:\n%s", result)
+		}
+	})
+}
+
+func TestSploitusFormatCombinedResults(t *testing.T) {
+	makeItems := func(prefix string, n int) []sploitusExploit {
+		items := make([]sploitusExploit, n)
+		for i := range items {
+			items[i] = sploitusExploit{
+				ID:    fmt.Sprintf("%s-%d", prefix, i),
+				Title: fmt.Sprintf("%s %d", prefix, i),
+				Href:  "https://example.com",
+			}
+		}
+		return items
+	}
+
+	t.Run("separate sections with per-section limit", func(t *testing.T) {
+		exploits := sploitusSection{resp: sploitusResponse{Exploits: makeItems("Exploit", 8), ExploitsTotal: 80}}
+		tools := sploitusSection{resp: sploitusResponse{Exploits: makeItems("Tool", 8), ExploitsTotal: 40}}
+
+		result := formatSploitusCombinedResults("nginx", 3, exploits, tools, ResultSizeLimits{}.resolve())
+
+		for _, expectedStr := range []string{
+			"# Sploitus Search Results",
+			"**Type:** all",
+			"**Total exploit matches on Sploitus:** 80",
+			"**Total tool matches on Sploitus:** 40",
+			"## Exploits (showing up to 3)",
+			"## Security Tools (showing up to 3)",
+		} {
+			if !strings.Contains(result, expectedStr) {
+				t.Errorf("expected result to contain %q\nGot:\n%s", expectedStr, result)
+			}
+		}
+
+		exploitsIdx := strings.Index(result, "## Exploits")
+		toolsIdx := strings.Index(result, "## Security Tools")
+		if exploitsIdx < 0 || toolsIdx < 0 || exploitsIdx > toolsIdx {
+			t.Fatalf("expected exploits section before tools section")
+		}
+
+		if count := strings.Count(result[exploitsIdx:toolsIdx], "### "); count != 3 {
+			t.Errorf("expected 3 exploits, got %d", count)
+		}
+		if count := strings.Count(result[toolsIdx:], "### "); count != 3 {
+			t.Errorf("expected 3 tools, got %d", count)
+		}
+	})
+
+	t.Run("size budget is shared fairly", func(t *testing.T) {
+		items := makeItems("Exploit", 25)
+		for i := range items {
+			items[i].Source = strings.Repeat("X", 5000)
+		}
+		exploits := sploitusSection{resp: sploitusResponse{Exploits: items, ExploitsTotal: 25}}
+		tools := sploitusSection{resp: sploitusResponse{Exploits: makeItems("Tool", 5), ExploitsTotal: 5}}
+
+		result := formatSploitusCombinedResults("test", 25, exploits, tools, ResultSizeLimits{}.resolve())
+
+		if len(result) > DefaultMaxResultSize {
+			t.Errorf("result size %d exceeds %d bytes limit", len(result), DefaultMaxResultSize)
+		}
+		if !strings.Contains(result, "Results truncated") {
+			t.Error("expected truncation warning for oversized exploits section")
+		}
+
+		toolsIdx := strings.Index(result, "## Security Tools")
+		if toolsIdx < 0 {
+			t.Fatalf("tools section was starved by exploits section:\n%s", result)
+		}
+		if count := strings.Count(result[toolsIdx:], "### "); count != 5 {
+			t.Errorf("expected all 5 tools to be shown, got %d", count)
+		}
+	})
+
+	t.Run("failed section is reported", func(t *testing.T) {
+		exploits := sploitusSection{resp: sploitusResponse{Exploits: makeItems("Exploit", 2), ExploitsTotal: 2}}
+		tools := sploitusSection{err: fmt.Errorf("Sploitus API returned HTTP 500")}
+
+		result := formatSploitusCombinedResults("test", 10, exploits, tools, ResultSizeLimits{}.resolve())
+
+		if !strings.Contains(result, "## Exploits (showing up to 2)") {
+			t.Errorf("expected exploits section, got:\n%s", result)
+		}
+		if !strings.Contains(result, "Failed to search tools: Sploitus API returned HTTP 500") {
+			t.Errorf("expected tools failure note, got:\n%s", result)
+		}
+	})
+
+	t.Run("empty sections", func(t *testing.T) {
+		result := formatSploitusCombinedResults("none", 10, sploitusSection{}, sploitusSection{}, ResultSizeLimits{}.resolve())
+
+		if !strings.Contains(result, "No exploits were found") {
+			t.Errorf("expected empty exploits note, got:\n%s", result)
+		}
+		if !strings.Contains(result, "No security tools were found") {
+			t.Errorf("expected empty tools note, got:\n%s", result)
+		}
+	})
+}
+
+func TestSploitusHandle_AllType(t *testing.T) {
+	var receivedTypes []string
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if strings.Contains(string(body), `"type":"tools"`) {
+			receivedTypes = append(receivedTypes, "tools")
+			w.Write([]byte(`{"exploits":[{"id":"T1","title":"Nginx Scanner","href":"https://example.com/t"}],"exploits_total":7}`))
+			return
+		}
+
+		receivedTypes = append(receivedTypes, "exploits")
+		w.Write([]byte(`{"exploits":[{"id":"E1","title":"Nginx RCE","href":"https://example.com/e"}],"exploits_total":3}`))
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	sp := &sploitus{
+		flowID: 1,
+		cfg: &config.Config{
+			SploitusEnabled:   true,
+			ProxyURL:          proxy.URL(),
+			ExternalSSLCAPath: proxy.CACertPath(),
+		},
+	}
+
+	got, err := sp.Handle(t.Context(), SploitusToolName, []byte(`{"query":"nginx","exploit_type":"all","max_results":5}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	if strings.Join(receivedTypes, ",") != "exploits,tools" {
+		t.Errorf("expected exploits and tools requests, got %v", receivedTypes)
+	}
+	for _, expectedStr := range []string{"Nginx RCE", "Nginx Scanner", "## Exploits", "## Security Tools"} {
+		if !strings.Contains(got, expectedStr) {
+			t.Errorf("expected result to contain %q\nGot:\n%s", expectedStr, got)
+		}
+	}
+}
+
+func TestSploitusHandle_UsesCache(t *testing.T) {
+	var requests int
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"exploits":[{"id":"E1","title":"Nginx RCE","href":"https://example.com/e"}],"exploits_total":1}`))
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	sp := &sploitus{
+		flowID: 1,
+		cfg: &config.Config{
+			SploitusEnabled:   true,
+			ProxyURL:          proxy.URL(),
+			ExternalSSLCAPath: proxy.CACertPath(),
+		},
+		cache: NewMemoryCache(10),
+	}
+
+	for _, args := range []string{
+		`{"query":"nginx","exploit_type":"exploits","max_results":5}`,
+		`{"query":" NGINX ","exploit_type":"exploits","max_results":2}`,
+	} {
+		got, err := sp.Handle(t.Context(), SploitusToolName, []byte(args))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "Nginx RCE") {
+			t.Errorf("expected result to contain exploit, got:\n%s", got)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 API request with cache, got %d", requests)
+	}
+
+	if _, err := sp.Handle(t.Context(), SploitusToolName, []byte(`{"query":"nginx","exploit_type":"tools"}`)); err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected new API request for other exploit type, got %d requests", requests)
+	}
+}
+
+// setSploitusRetryDelays makes retries fast for tests
+func setSploitusRetryDelays(t *testing.T, base, maxDelay time.Duration) {
+	t.Helper()
+
+	prevBase, prevMax := sploitusRetryBaseDelay, sploitusRetryMaxDelay
+	sploitusRetryBaseDelay, sploitusRetryMaxDelay = base, maxDelay
+	t.Cleanup(func() {
+		sploitusRetryBaseDelay, sploitusRetryMaxDelay = prevBase, prevMax
+	})
+}
+
+func TestSploitusHandle_RetryBackoff(t *testing.T) {
+	setSploitusRetryDelays(t, time.Millisecond, 10*time.Millisecond)
+
+	var requests int
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"exploits":[{"id":"E1","title":"Nginx RCE","href":"https://example.com/e"}],"exploits_total":1}`))
+		}
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	slp := &searchLogProviderMock{}
+	sp := NewSploitusTool(&config.Config{
+		SploitusEnabled:     true,
+		SploitusMaxAttempts: 3,
+		ProxyURL:            proxy.URL(),
+		ExternalSSLCAPath:   proxy.CACertPath(),
+	}, 1, nil, nil, slp, nil)
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	got, err := sp.Handle(ctx, SploitusToolName, []byte(`{"query":"nginx","exploit_type":"exploits"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "Nginx RCE") {
+		t.Errorf("expected result after retries, got:\n%s", got)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+
+	// two retry attempts and the final result
+	if slp.calls != 3 {
+		t.Errorf("PutLog() calls = %d, want 3", slp.calls)
+	}
+	if slp.engine != database.SearchengineTypeSploitus {
+		t.Errorf("engine = %q, want %q", slp.engine, database.SearchengineTypeSploitus)
+	}
+}
+
+func TestSploitusFetch_RetryErrors(t *testing.T) {
+	setSploitusRetryDelays(t, time.Millisecond, 10*time.Millisecond)
+
+	tests := []struct {
+		name          string
+		status        int
+		retryAfter    string
+		body          string
+		maxAttempts   int
+		wantRequests  int
+		wantTemporary bool
+		wantTyped     bool
+	}{
+		{
+			name:   "server error exhausts attempts",
+			status: http.StatusBadGateway, maxAttempts: 3,
+			wantRequests: 3, wantTemporary: true, wantTyped: true,
+		},
+		{
+			name:   "rate limit 499 with retry after",
+			status: 499, retryAfter: "0", maxAttempts: 2,
+			wantRequests: 2, wantTemporary: true, wantTyped: true,
+		},
+		{
+			name:   "rate limit 422 is not retried",
+			status: http.StatusUnprocessableEntity, maxAttempts: 3,
+			wantRequests: 1, wantTyped: true,
+		},
+		{
+			name:   "client error is not retried",
+			status: http.StatusForbidden, maxAttempts: 3,
+			wantRequests: 1, wantTyped: true,
+		},
+		{
+			name:   "zero attempts means single request",
+			status: http.StatusInternalServerError, maxAttempts: 0,
+			wantRequests: 1, wantTemporary: true, wantTyped: true,
+		},
+		{
+			name:   "decode error is not a request error",
+			status: http.StatusOK, body: "{", maxAttempts: 3,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			mockMux := http.NewServeMux()
+			mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			proxy, err := newTestProxy("sploitus.com", mockMux)
+			if err != nil {
+				t.Fatalf("failed to create proxy: %v", err)
+			}
+			defer proxy.Close()
+
+			sp := &sploitus{
+				flowID: 1,
+				cfg: &config.Config{
+					SploitusEnabled:     true,
+					SploitusMaxAttempts: tt.maxAttempts,
+					ProxyURL:            proxy.URL(),
+					ExternalSSLCAPath:   proxy.CACertPath(),
+				},
+			}
+
+			_, err = sp.fetch(t.Context(), "test", sploitusTypeExploits, sploitusDefaultSort, 0)
+			if err == nil {
+				t.Fatal("fetch() expected error")
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+
+			var reqErr *SploitusRequestError
+			if typed := errors.As(err, &reqErr); typed != tt.wantTyped {
+				t.Fatalf("errors.As(%v) = %v, want %v", err, typed, tt.wantTyped)
+			}
+			if !tt.wantTyped {
+				return
+			}
+			if reqErr.StatusCode != tt.status || reqErr.Attempts != tt.wantRequests {
+				t.Errorf("unexpected request error: %+v", reqErr)
+			}
+			if reqErr.Temporary() != tt.wantTemporary {
+				t.Errorf("Temporary() = %v, want %v", reqErr.Temporary(), tt.wantTemporary)
+			}
+		})
+	}
+}
+
+func TestSploitusFetch_RespectsContextDeadline(t *testing.T) {
+	var requests int
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	sp := &sploitus{
+		flowID: 1,
+		cfg: &config.Config{
+			SploitusEnabled:     true,
+			SploitusMaxAttempts: 5,
+			ProxyURL:            proxy.URL(),
+			ExternalSSLCAPath:   proxy.CACertPath(),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = sp.fetch(ctx, "test", sploitusTypeExploits, sploitusDefaultSort, 0)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("fetch() took %s, must abort before waiting beyond the deadline", elapsed)
+	}
+
+	var reqErr *SploitusRequestError
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("fetch() error = %v, want rate limit request error", err)
+	}
+	if reqErr.RetryAfter != 120*time.Second {
+		t.Errorf("RetryAfter = %s, want 2m0s", reqErr.RetryAfter)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+
+	// cancellation during backoff must stop waiting
+	cancelCtx, cancelNow := context.WithCancel(t.Context())
+	time.AfterFunc(50*time.Millisecond, cancelNow)
+
+	requests = 0
+	start = time.Now()
+	_, err = (&sploitus{
+		flowID: 1,
+		cfg: &config.Config{
+			SploitusEnabled:     true,
+			SploitusMaxAttempts: 2,
+			ProxyURL:            proxy.URL(),
+			ExternalSSLCAPath:   proxy.CACertPath(),
+		},
+	}).fetch(cancelCtx, "test", sploitusTypeExploits, sploitusDefaultSort, 0)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("fetch() took %s after context cancellation", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &reqErr) {
+		t.Errorf("fetch() error = %v, want canceled request error", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "5", want: 5 * time.Second},
+		{value: "-3", want: 0},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSploitusBackoff(t *testing.T) {
+	setSploitusRetryDelays(t, time.Second, 30*time.Second)
+
+	for attempt, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		10: 30 * time.Second,
+		64: 30 * time.Second,
+	} {
+		for i := 0; i < 20; i++ {
+			delay := sploitusBackoff(attempt)
+			if delay < want/2 || delay >= want {
+				t.Errorf("sploitusBackoff(%d) = %s, want in [%s, %s)", attempt, delay, want/2, want)
+			}
+		}
+	}
+}
+
+func TestSploitusFilters(t *testing.T) {
+	resp := sploitusResponse{
+		Exploits: []sploitusExploit{
+			{ID: "E1", Type: "githubexploit", Language: "python"},
+			{ID: "E2", Type: "exploitdb", Language: "Python"},
+			{ID: "E3", Type: "GithubExploit", Language: "bash"},
+			{ID: "E4", Language: "python"},
+			{ID: "E5", Type: "githubexploit"},
+		},
+		ExploitsTotal: 5,
+	}
+
+	tests := []struct {
+		name     string
+		action   SploitusAction
+		wantIDs  []string
+		filtered []sploitusFilterStat
+	}{
+		{
+			name:    "no filters",
+			wantIDs: []string{"E1", "E2", "E3", "E4", "E5"},
+		},
+		{
+			name:     "language is case-insensitive",
+			action:   SploitusAction{Language: " PYTHON "},
+			wantIDs:  []string{"E1", "E2", "E4"},
+			filtered: []sploitusFilterStat{{"language", 2}},
+		},
+		{
+			name:     "platform matches source type",
+			action:   SploitusAction{Platform: "githubexploit"},
+			wantIDs:  []string{"E1", "E3", "E5"},
+			filtered: []sploitusFilterStat{{"platform", 2}},
+		},
+		{
+			name:     "filters are composed",
+			action:   SploitusAction{Language: "python", Platform: "githubexploit"},
+			wantIDs:  []string{"E1"},
+			filtered: []sploitusFilterStat{{"language", 2}, {"platform", 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applySploitusFilters(resp, newSploitusFilters(tt.action))
+
+			var ids []string
+			for _, item := range got.Exploits {
+				ids = append(ids, item.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if fmt.Sprint(got.filtered) != fmt.Sprint(tt.filtered) {
+				t.Errorf("filtered = %v, want %v", got.filtered, tt.filtered)
+			}
+			if sploitusReceived(got) != len(resp.Exploits) {
+				t.Errorf("received = %d, want %d", sploitusReceived(got), len(resp.Exploits))
+			}
+		})
+	}
+
+	if len(resp.Exploits) != 5 || resp.filtered != nil {
+		t.Error("source response must not be modified")
+	}
+}
+
+func TestSploitusDedup_Testdata(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "sploitus_result_duplicates.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	var resp sploitusResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode testdata: %v", err)
+	}
+
+	deduped := dedupSploitusExploits(resp)
+	if len(deduped.Exploits) != 3 {
+		for _, item := range deduped.Exploits {
+			t.Logf("%s: %s", item.ID, item.Title)
+		}
+		t.Fatalf("deduped items = %d, want 3", len(deduped.Exploits))
+	}
+
+	// CVE-2021-41773 group keeps the highest score and then the most recent entry
+	first := deduped.Exploits[0]
+	if first.ID != "B1D7A1A0-E1F5-5D35-8E67-EE3C1D9F4B0D" {
+		t.Errorf("first group representative = %s, want githubexploit entry", first.ID)
+	}
+	if len(first.alternates) != 3 {
+		t.Errorf("first group alternates = %d, want 3", len(first.alternates))
+	}
+
+	// normalized titles are equal, the most recent entry wins with the same score
+	second := deduped.Exploits[1]
+	if second.ID != "1337DAY-ID-36952" || len(second.alternates) != 1 {
+		t.Errorf("unexpected second group: %s with %d alternates", second.ID, len(second.alternates))
+	}
+	if deduped.Exploits[2].ID != "MSF:EXPLOIT-MULTI-HTTP-APACHE_NORMALIZE_PATH_RCE-" {
+		t.Errorf("distinct exploit must be kept, got %s", deduped.Exploits[2].ID)
+	}
+
+	result := formatSploitusResults("CVE-2021-41773", sploitusTypeExploits, 2, deduped, ResultSizeLimits{}.resolve())
+	expected := []string{
+		"2 of 7 matches after filters (received 7: 4 removed by filters [dedup: 4], 1 over limit, 0 truncated by size)",
+		"### 1. Exploit for CVE-2021-41773",
+		"**Alternate sources:**",
+		"- exploitdb: https://www.exploit-db.com/exploits/50383",
+		"- packetstorm: https://packetstormsecurity.com/files/164418/",
+		"### 2. apache http server 2.4.50 - remote code execution!",
+		"- packetstorm: https://packetstormsecurity.com/files/164501/",
+	}
+	for _, part := range expected {
+		if !strings.Contains(result, part) {
+			t.Errorf("result missing %q:\n%s", part, result)
+		}
+	}
+}
+
+func TestSploitusHandle_DedupBeforeLimit(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "sploitus_result_duplicates.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	for _, tt := range []struct {
+		name  string
+		dedup bool
+		want  int
+	}{
+		{name: "dedup enabled", dedup: true, want: 3},
+		{name: "dedup disabled", dedup: false, want: 5},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := NewSploitusTool(&config.Config{
+				SploitusEnabled:      true,
+				SploitusDedupEnabled: tt.dedup,
+				ProxyURL:             proxy.URL(),
+				ExternalSSLCAPath:    proxy.CACertPath(),
+			}, 1, nil, nil, &searchLogProviderMock{}, nil)
+
+			got, err := sp.Handle(t.Context(), SploitusToolName, []byte(`{"query":"apache 2.4.49","max_results":5}`))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if count := strings.Count(got, "### "); count != tt.want {
+				t.Errorf("rendered items = %d, want %d\n%s", count, tt.want, got)
+			}
+			if tt.dedup != strings.Contains(got, "**Alternate sources:**") {
+				t.Errorf("unexpected alternate sources presence with dedup=%v:\n%s", tt.dedup, got)
+			}
+		})
+	}
+}
+
+var sploitusContinuationRegex = regexp.MustCompile("`\"continuation\": \"([^\"]+)\"`")
+
+// newSploitusPagedServer serves total exploits by pages of pageSize items at the requested offset
+func newSploitusPagedServer(t *testing.T, total, pageSize, sourceSize int, offsets *[]int) *testProxy {
+	t.Helper()
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		var req sploitusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		*offsets = append(*offsets, req.Offset)
+
+		resp := sploitusResponse{ExploitsTotal: total}
+		for i := req.Offset; i < min(req.Offset+pageSize, total); i++ {
+			resp.Exploits = append(resp.Exploits, sploitusExploit{
+				ID:     fmt.Sprintf("EX-%02d", i),
+				Title:  fmt.Sprintf("Exploit %d", i),
+				Href:   fmt.Sprintf("https://example.com/%d", i),
+				Source: strings.Repeat("X", sourceSize),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	proxy, err := newTestProxy("sploitus.com", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	t.Cleanup(func() { _ = proxy.Close() })
+
+	return proxy
+}
+
+// collectSploitusPages requests the pages by the continuation tokens until the last one
+// and returns the IDs of the shown exploits in order and the count of the pages
+func collectSploitusPages(t *testing.T, ctx context.Context, tool Tool, args string) ([]string, int) {
+	t.Helper()
+
+	var ids []string
+	token := ""
+	for pages := 1; pages <= 50; pages++ {
+		callArgs := args
+		if token != "" {
+			callArgs = strings.TrimSuffix(args, "}") + fmt.Sprintf(`,"continuation":%q}`, token)
+		}
+
+		got, err := tool.Handle(ctx, SploitusToolName, []byte(callArgs))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		for _, m := range regexp.MustCompile(`\*\*ID:\*\* (EX-\d+)`).FindAllStringSubmatch(got, -1) {
+			ids = append(ids, m[1])
+		}
+
+		m := sploitusContinuationRegex.FindStringSubmatch(got)
+		if m == nil {
+			return ids, pages
+		}
+		token = m[1]
+	}
+
+	t.Fatal("continuation doesn't reach the last page")
+	return nil, 0
+}
+
+func TestSploitusHandle_Continuation(t *testing.T) {
+	var want []string
+	for i := range 25 {
+		want = append(want, fmt.Sprintf("EX-%02d", i))
+	}
+
+	t.Run("by limit", func(t *testing.T) {
+		var offsets []int
+		proxy := newSploitusPagedServer(t, 25, 10, 10, &offsets)
+		sp := NewSploitusTool(&config.Config{
+			SploitusEnabled:   true,
+			ProxyURL:          proxy.URL(),
+			ExternalSSLCAPath: proxy.CACertPath(),
+		}, 1, nil, nil, nil, NewMemoryCache(10))
+
+		ids, pages := collectSploitusPages(t, t.Context(), sp, `{"query":"nginx","max_results":4,"message":"find"}`)
+		if strings.Join(ids, ",") != strings.Join(want, ",") {
+			t.Errorf("shown exploits = %v, want all of them once in order", ids)
+		}
+		// every API page of 10 items is split into 4, 4 and 2 items and the last one into 4 and 1
+		if pages != 8 {
+			t.Errorf("pages = %d, want 8", pages)
+		}
+		if fmt.Sprint(offsets) != "[0 10 20]" {
+			t.Errorf("API offsets = %v, want each API page requested once", offsets)
+		}
+	})
+
+	t.Run("by size", func(t *testing.T) {
+		var offsets []int
+		proxy := newSploitusPagedServer(t, 25, 10, 3*1024, &offsets)
+		sp := NewSploitusTool(&config.Config{
+			SploitusEnabled:   true,
+			ProxyURL:          proxy.URL(),
+			ExternalSSLCAPath: proxy.CACertPath(),
+		}, 1, nil, nil, nil, NewMemoryCache(10))
+
+		ctx := withResultSizeLimits(t.Context(), ResultSizeLimits{MaxResultSize: 8 * 1024})
+		ids, pages := collectSploitusPages(t, ctx, sp, `{"query":"nginx","max_results":25,"message":"find"}`)
+		if strings.Join(ids, ",") != strings.Join(want, ",") {
+			t.Errorf("shown exploits = %v, want all of them once in order", ids)
+		}
+		if pages <= 3 {
+			t.Errorf("pages = %d, want the API pages to be split by the size limit", pages)
+		}
+	})
+}
+
+func TestSploitusHandle_ContinuationErrors(t *testing.T) {
+	var offsets []int
+	proxy := newSploitusPagedServer(t, 25, 10, 10, &offsets)
+	sp := NewSploitusTool(&config.Config{
+		SploitusEnabled:   true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, nil, nil)
+
+	got, err := sp.Handle(t.Context(), SploitusToolName, []byte(`{"query":"nginx","max_results":5}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	m := sploitusContinuationRegex.FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("first page has no continuation:\n%s", got)
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"changed query", `{"query":"apache","max_results":5,"continuation":"` + m[1] + `"}`, "arguments are changed since the previous page"},
+		{"malformed", `{"query":"nginx","max_results":5,"continuation":"???"}`, "invalid continuation token: malformed token"},
+		{"all type", `{"query":"nginx","exploit_type":"all","max_results":5,"continuation":"` + m[1] + `"}`, "continuation isn't supported for 'all' exploit type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sp.Handle(t.Context(), SploitusToolName, []byte(tt.args))
+			if err != nil || !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	if len(offsets) != 1 {
+		t.Errorf("API requests = %d, want the rejected calls not to reach the API", len(offsets))
+	}
+}
@@ -22,6 +22,14 @@ const tavilyURL = "https://api.tavily.com/search"
 
 const maxRawContentLength = 3000
 
+const (
+	defaultTavilyMaxResults   = 5
+	maxTavilyMaxResults       = 10
+	tavilySearchDepthBasic    = "basic"
+	tavilySearchDepthAdvanced = "advanced"
+	defaultTavilySearchDepth  = tavilySearchDepthAdvanced
+)
+
 type tavilyRequest struct {
 	ApiKey            string   `json:"api_key"`
 	Query             string   `json:"query"`
@@ -81,7 +89,7 @@ func (t *tavily) Handle(ctx context.Context, name string, args json.RawMessage)
 		return "", fmt.Errorf("tavily is not available")
 	}
 
-	var action SearchAction
+	var action TavilyAction
 	ctx, observation := obs.Observer.NewObservation(ctx)
 	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(t.flowID, t.taskID, t.subtaskID, logrus.Fields{
 		"tool": name,
@@ -93,12 +101,23 @@ func (t *tavily) Handle(ctx context.Context, name string, args json.RawMessage)
 		return "", fmt.Errorf("failed to unmarshal %s search action arguments: %w", name, err)
 	}
 
+	searchDepth := strings.ToLower(strings.TrimSpace(action.SearchDepth))
+	if searchDepth != tavilySearchDepthBasic && searchDepth != tavilySearchDepthAdvanced {
+		searchDepth = defaultTavilySearchDepth
+	}
+
+	maxResults := action.MaxResults.Int()
+	if maxResults < 1 || maxResults > maxTavilyMaxResults {
+		maxResults = defaultTavilyMaxResults
+	}
+
 	logger = logger.WithFields(logrus.Fields{
-		"query":       action.Query[:min(len(action.Query), 1000)],
-		"max_results": action.MaxResults,
+		"query":        action.Query[:min(len(action.Query), 1000)],
+		"search_depth": searchDepth,
+		"max_results":  maxResults,
 	})
 
-	result, err := t.search(ctx, action.Query, action.MaxResults.Int())
+	result, err := t.search(ctx, action.Query, searchDepth, maxResults)
 	if err != nil {
 		observation.Event(
 			langfuse.WithEventName("search engine error swallowed"),
@@ -106,11 +125,12 @@ func (t *tavily) Handle(ctx context.Context, name string, args json.RawMessage)
 			langfuse.WithEventStatus(err.Error()),
 			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
 			langfuse.WithEventMetadata(langfuse.Metadata{
-				"tool_name":   TavilyToolName,
-				"engine":      "tavily",
-				"query":       action.Query,
-				"max_results": action.MaxResults.Int(),
-				"error":       err.Error(),
+				"tool_name":    TavilyToolName,
+				"engine":       "tavily",
+				"query":        action.Query,
+				"search_depth": searchDepth,
+				"max_results":  maxResults,
+				"error":        err.Error(),
 			}),
 		)
 
@@ -134,7 +154,7 @@ func (t *tavily) Handle(ctx context.Context, name string, args json.RawMessage)
 	return result, nil
 }
 
-func (t *tavily) search(ctx context.Context, query string, maxResults int) (string, error) {
+func (t *tavily) search(ctx context.Context, query, searchDepth string, maxResults int) (string, error) {
 	client, err := system.GetHTTPClient(t.cfg)
 	if err != nil {
 		return "", fmt.Errorf("failed to create http client: %w", err)
@@ -144,7 +164,7 @@ func (t *tavily) search(ctx context.Context, query string, maxResults int) (stri
 		Query:             query,
 		ApiKey:            t.apiKey(),
 		Topic:             "general",
-		SearchDepth:       "advanced",
+		SearchDepth:       searchDepth,
 		IncludeImages:     false,
 		IncludeAnswer:     true,
 		IncludeRawContent: true,
@@ -205,39 +225,65 @@ func (t *tavily) parseHTTPResponse(ctx context.Context, resp *http.Response) (st
 	}
 }
 
+// buildTavilyResult formats the answer and the links of the response as markdown, the answer is written
+// only when tavily returned it and the whole output is capped by the result size limits of the flow
 func (t *tavily) buildTavilyResult(ctx context.Context, result *tavilySearchResult) string {
+	limits := resultSizeLimitsFromContext(ctx)
+
 	var writer strings.Builder
-	writer.WriteString("# Answer\n\n")
-	writer.WriteString(result.Answer)
-	writer.WriteString("\n\n# Links\n\n")
+	if answer := strings.TrimSpace(result.Answer); answer != "" {
+		writer.WriteString("# Answer\n\n")
+		writer.WriteString(TruncateWithNotice(answer, limits.MaxSourceSize, textTruncatedNotice))
+		writer.WriteString("\n\n")
+	}
+	writer.WriteString("# Links\n\n")
+
+	results := result.Results
+	if len(results) == 0 {
+		writer.WriteString("No results found\n\n")
+	}
+
+	budget := NewResultBudget(limits.MaxResultSize, writer.Len())
+	for i, result := range results {
+		var item strings.Builder
+		item.WriteString(fmt.Sprintf("## %d. %s\n\n", i+1, result.Title))
+		item.WriteString(fmt.Sprintf("* URL %s\n", result.URL))
+		item.WriteString(fmt.Sprintf("* Match score %3.3f\n\n", result.Score))
+		item.WriteString(fmt.Sprintf("### Short content\n\n%s\n\n", result.Content))
+
+		if !budget.Add(&writer, item.String()) {
+			writer.WriteString(budget.Notice(budget.Added(), len(results), "results"))
+			return writer.String()
+		}
+	}
 
 	isRawContentExists := false
-	for i, result := range result.Results {
-		writer.WriteString(fmt.Sprintf("## %d. %s\n\n", i+1, result.Title))
-		writer.WriteString(fmt.Sprintf("* URL %s\n", result.URL))
-		writer.WriteString(fmt.Sprintf("* Match score %3.3f\n\n", result.Score))
-		writer.WriteString(fmt.Sprintf("### Short content\n\n%s\n\n", result.Content))
+	for _, result := range results {
 		if result.RawContent != nil {
 			isRawContentExists = true
+			break
 		}
 	}
 
+	var content string
 	if isRawContentExists && t.summarizer != nil {
 		summarizePrompt, err := t.getSummarizePrompt(result.Query, result)
 		if err != nil {
-			writer.WriteString(t.getRawContentFromResults(result.Results))
+			content = t.getRawContentFromResults(results)
 		} else {
 			summarizedContents, err := t.summarizer(ctx, summarizePrompt)
 			if err != nil {
-				writer.WriteString(t.getRawContentFromResults(result.Results))
+				content = t.getRawContentFromResults(results)
 			} else {
-				writer.WriteString(fmt.Sprintf("### Summarized Content\n\n%s\n\n", summarizedContents))
+				content = fmt.Sprintf("### Summarized Content\n\n%s\n\n", summarizedContents)
 			}
 		}
 	} else {
-		writer.WriteString(t.getRawContentFromResults(result.Results))
+		content = t.getRawContentFromResults(results)
 	}
 
+	budget.AddTruncated(&writer, content, contentTruncatedNotice)
+
 	return writer.String()
 }
 
@@ -1,9 +1,14 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -434,3 +439,182 @@ func TestTavilyHandle_ValidationAndSwallowedError(t *testing.T) {
 		}
 	})
 }
+
+func TestTavilyParseHTTPResponse_Testdata(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name: "answer with raw content",
+			file: "tavily_result_log4shell.json",
+			expected: []string{
+				"# Answer\n\nCVE-2021-44228 (Log4Shell) is a remote code execution vulnerability",
+				"# Links",
+				"## 1. NVD - CVE-2021-44228",
+				"* URL https://nvd.nist.gov/vuln/detail/CVE-2021-44228",
+				"* Match score 0.985",
+				"## 2. Log4Shell: RCE 0-day exploit found in log4j 2 - LunaSec",
+				"## 3. kozmer/log4j-shell-poc - GitHub",
+				"### Raw content for 1. NVD - CVE-2021-44228",
+				"### Raw content for 3. kozmer/log4j-shell-poc - GitHub",
+			},
+			unexpected: []string{
+				"### Raw content for 2.",
+				"No results found",
+			},
+		},
+		{
+			name: "no answer",
+			file: "tavily_result_no_answer.json",
+			expected: []string{
+				"# Links",
+				"## 1. smb-vuln-ms17-010 NSE script - Nmap Scripting Engine documentation",
+				"* URL https://nmap.org/nsedoc/scripts/smb-vuln-ms17-010.html",
+				"## 2. Scanning for EternalBlue with Nmap",
+				"nmap -p445 --script smb-vuln-ms17-010 <target>",
+			},
+			unexpected: []string{
+				"# Answer",
+				"### Raw content for",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatalf("failed to read testdata: %v", err)
+			}
+
+			tav := &tavily{flowID: 1}
+			result, err := tav.parseHTTPResponse(t.Context(), &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			})
+			if err != nil {
+				t.Fatalf("parseHTTPResponse() unexpected error: %v", err)
+			}
+
+			for _, want := range tt.expected {
+				if !strings.Contains(result, want) {
+					t.Errorf("result missing %q:\n%s", want, result)
+				}
+			}
+			for _, unwanted := range tt.unexpected {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("result unexpectedly contains %q:\n%s", unwanted, result)
+				}
+			}
+		})
+	}
+}
+
+func TestTavilySizeLimits(t *testing.T) {
+	t.Run("total size limit at 80KB", func(t *testing.T) {
+		results := make([]tavilyResult, 100)
+		for i := range results {
+			results[i] = tavilyResult{
+				Title:   fmt.Sprintf("Test Result %d", i),
+				URL:     "https://example.com",
+				Content: strings.Repeat("X", 5000), // 5 KB each
+				Score:   0.5,
+			}
+		}
+
+		tav := &tavily{}
+		result := tav.buildTavilyResult(t.Context(), &tavilySearchResult{Query: "test", Results: results})
+
+		if len(result) > 80*1024 {
+			t.Errorf("result size %d exceeds 80 KB hard limit", len(result))
+		}
+		if !strings.Contains(result, "output truncated, exceeded 80 KB limit") {
+			t.Error("expected truncation warning when hitting 80 KB limit")
+		}
+		if count := strings.Count(result, "### Short content"); count >= 100 {
+			t.Errorf("expected fewer than 100 results due to size limit, got %d", count)
+		}
+	})
+
+	t.Run("summarized content is cut by the remaining budget", func(t *testing.T) {
+		raw := "raw content"
+		tav := &tavily{
+			summarizer: func(ctx context.Context, prompt string) (string, error) {
+				return strings.Repeat("S", 100*1024), nil
+			},
+		}
+		result := tav.buildTavilyResult(t.Context(), &tavilySearchResult{
+			Answer:  "answer",
+			Query:   "test",
+			Results: []tavilyResult{{Title: "Title", URL: "https://example.com", Content: "c", RawContent: &raw}},
+		})
+
+		if len(result) > 80*1024 {
+			t.Errorf("result size %d exceeds 80 KB hard limit", len(result))
+		}
+		if !strings.Contains(result, "content truncated, exceeded 80 KB limit") {
+			t.Error("expected content truncation message for 100 KB summary")
+		}
+		if !strings.Contains(result, "## 1. Title") {
+			t.Error("links must be kept when the content is truncated")
+		}
+	})
+}
+
+func TestTavilyHandle_SearchParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      string
+		wantDepth string
+		wantMax   int
+	}{
+		{"basic depth", `{"query":"q","search_depth":"basic","max_results":3,"message":"m"}`, "basic", 3},
+		{"default depth", `{"query":"q","max_results":10,"message":"m"}`, "advanced", 10},
+		{"unknown depth", `{"query":"q","search_depth":"deep","max_results":5,"message":"m"}`, "advanced", 5},
+		{"too many results", `{"query":"q","max_results":50,"message":"m"}`, "advanced", defaultTavilyMaxResults},
+		{"zero results", `{"query":"q","max_results":0,"message":"m"}`, "advanced", defaultTavilyMaxResults},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received tavilyRequest
+			mockMux := http.NewServeMux()
+			mockMux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"query":"q","results":[]}`))
+			})
+
+			proxy, err := newTestProxy("api.tavily.com", mockMux)
+			if err != nil {
+				t.Fatalf("failed to create proxy: %v", err)
+			}
+			defer proxy.Close()
+
+			tav := NewTavilyTool(&config.Config{
+				TavilyAPIKey:      testTavilyAPIKey,
+				ProxyURL:          proxy.URL(),
+				ExternalSSLCAPath: proxy.CACertPath(),
+			}, 1, nil, nil, &searchLogProviderMock{}, nil)
+
+			result, err := tav.Handle(t.Context(), TavilyToolName, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.Contains(result, "No results found") {
+				t.Errorf("Handle() = %q, expected empty results message", result)
+			}
+			if received.SearchDepth != tt.wantDepth {
+				t.Errorf("search_depth = %q, want %q", received.SearchDepth, tt.wantDepth)
+			}
+			if received.MaxResults != tt.wantMax {
+				t.Errorf("max_results = %d, want %d", received.MaxResults, tt.wantMax)
+			}
+		})
+	}
+}
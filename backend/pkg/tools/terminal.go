@@ -18,6 +18,7 @@ import (
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -39,6 +40,8 @@ type terminal struct {
 	containerLID string
 	dockerClient docker.DockerClient
 	tlp          TermLogProvider
+	env          FlowEnvProvider
+	guard        *CommandGuard
 }
 
 func NewTerminalTool(
@@ -47,6 +50,8 @@ func NewTerminalTool(
 	containerID int64, containerLID string,
 	dockerClient docker.DockerClient,
 	tlp TermLogProvider,
+	env FlowEnvProvider,
+	guard *CommandGuard,
 ) Tool {
 	return &terminal{
 		flowID:       flowID,
@@ -56,6 +61,8 @@ func NewTerminalTool(
 		containerLID: containerLID,
 		dockerClient: dockerClient,
 		tlp:          tlp,
+		env:          env,
+		guard:        guard,
 	}
 }
 
@@ -99,6 +106,9 @@ func (t *terminal) Handle(ctx context.Context, name string, args json.RawMessage
 			logger.WithError(err).Error("failed to unmarshal terminal action")
 			return "", fmt.Errorf("failed to unmarshal terminal action: %w", err)
 		}
+		if reason := t.guard.Check(action.Input); reason != "" {
+			return t.denyCommand(ctx, action.Cwd, action.Input, reason)
+		}
 		timeout := time.Duration(action.Timeout)*time.Second + defaultExtraExecTimeout
 		result, err := t.ExecCommand(ctx, action.Cwd, action.Input, action.Detach.Bool(), timeout)
 		return t.wrapCommandResult(ctx, args, name, result, err)
@@ -130,11 +140,65 @@ func (t *terminal) Handle(ctx context.Context, name string, args json.RawMessage
 	}
 }
 
+// denyCommand logs the command rejected by the command policy and returns the reason to the agent
+func (t *terminal) denyCommand(ctx context.Context, cwd, command, reason string) (string, error) {
+	env, err := t.getEnv(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if cwd == "" {
+		cwd = docker.WorkFolderPathInContainer
+	}
+
+	command, reason = env.Redact(command), env.Redact(reason)
+	t.guard.Audit(ctx, t.flowID, t.taskID, t.subtaskID, command, reason)
+
+	formattedCommand := FormatTerminalInput(cwd, command)
+	_, err = t.tlp.PutMsg(ctx, database.TermlogTypeStdin, formattedCommand, t.containerID, t.taskID, t.subtaskID)
+	if err != nil {
+		return "", fmt.Errorf("failed to put terminal log (stdin): %w", err)
+	}
+
+	result := fmt.Sprintf("command was denied by the terminal command policy and wasn't executed: %s", reason)
+	formattedResult := FormatTerminalSystemOutput(result)
+	_, err = t.tlp.PutMsg(ctx, database.TermlogTypeStderr, formattedResult, t.containerID, t.taskID, t.subtaskID)
+	if err != nil {
+		return "", fmt.Errorf("failed to put terminal log (stderr): %w", err)
+	}
+
+	return result, nil
+}
+
 func (t *terminal) ExecCommand(
 	ctx context.Context,
 	cwd, command string,
 	detach bool,
 	timeout time.Duration,
+) (string, error) {
+	// the command isn't added to the span attributes because it may contain secrets
+	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindClient, "tools.terminal.ExecCommand")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("flow.id", t.flowID),
+		attribute.Int64("container.id", t.containerID),
+		attribute.String("container.cwd", cwd),
+		attribute.Bool("command.detach", detach),
+		attribute.Int64("command.timeout_ms", timeout.Milliseconds()),
+	)
+
+	output, err := t.execCommand(ctx, cwd, command, detach, timeout)
+	obs.SetSpanError(span, err)
+
+	return output, err
+}
+
+func (t *terminal) execCommand(
+	ctx context.Context,
+	cwd, command string,
+	detach bool,
+	timeout time.Duration,
 ) (string, error) {
 	containerName := PrimaryTerminalName(t.flowID)
 
@@ -158,7 +222,12 @@ func (t *terminal) ExecCommand(
 		cwd = docker.WorkFolderPathInContainer
 	}
 
-	formattedCommand := FormatTerminalInput(cwd, command)
+	env, err := t.getEnv(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	formattedCommand := env.Redact(FormatTerminalInput(cwd, command))
 	_, err = t.tlp.PutMsg(ctx, database.TermlogTypeStdin, formattedCommand, t.containerID, t.taskID, t.subtaskID)
 	if err != nil {
 		return "", fmt.Errorf("failed to put terminal log (stdin): %w", err)
@@ -173,6 +242,7 @@ func (t *terminal) ExecCommand(
 		AttachStdout: true,
 		AttachStderr: true,
 		WorkingDir:   cwd,
+		Env:          env.Environ(),
 		Tty:          true,
 	})
 	if err != nil {
@@ -184,7 +254,7 @@ func (t *terminal) ExecCommand(
 		detachedCtx := context.WithoutCancel(ctx)
 
 		go func() {
-			output, err := t.getExecResult(detachedCtx, createResp.ID, timeout)
+			output, err := t.getExecResult(detachedCtx, createResp.ID, timeout, env)
 			resultChan <- execResult{output: output, err: err}
 		}()
 
@@ -202,11 +272,25 @@ func (t *terminal) ExecCommand(
 		}
 	}
 
-	return t.getExecResult(ctx, createResp.ID, timeout)
+	return t.getExecResult(ctx, createResp.ID, timeout, env)
 }
 
-func (t *terminal) getExecResult(ctx context.Context, id string, timeout time.Duration) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+// getEnv returns the flow environment which is injected into the commands, it's empty if there is no provider
+func (t *terminal) getEnv(ctx context.Context) (FlowEnv, error) {
+	if t.env == nil {
+		return FlowEnv{}, nil
+	}
+
+	env, err := t.env.GetFlowEnv(ctx)
+	if err != nil {
+		return FlowEnv{}, fmt.Errorf("failed to get flow environment: %w", err)
+	}
+
+	return env, nil
+}
+
+func (t *terminal) getExecResult(ctx context.Context, id string, timeout time.Duration, env FlowEnv) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, timeout))
 	defer cancel()
 
 	// attach to the exec process
@@ -238,7 +322,7 @@ func (t *terminal) getExecResult(ctx context.Context, id string, timeout time.Du
 		// Wait for the copy goroutine to finish
 		<-errChan
 
-		result := fmt.Sprintf("temporary output: %s", dst.String())
+		result := fmt.Sprintf("temporary output: %s", env.Redact(dst.String()))
 		return "", fmt.Errorf("timeout value is too low, use greater value if you need so: %w: %s", ctx.Err(), result)
 	}
 
@@ -248,7 +332,7 @@ func (t *terminal) getExecResult(ctx context.Context, id string, timeout time.Du
 		return "", fmt.Errorf("failed to inspect exec process: %w", err)
 	}
 
-	results := dst.String()
+	results := env.Redact(dst.String())
 	formattedResults := FormatTerminalSystemOutput(results)
 	_, err = t.tlp.PutMsg(ctx, database.TermlogTypeStdout, formattedResults, t.containerID, t.taskID, t.subtaskID)
 	if err != nil {
@@ -331,7 +415,12 @@ func (t *terminal) ReadFile(ctx context.Context, flowID int64, path string) (str
 		}
 	}
 
-	content := buffer.String()
+	env, err := t.getEnv(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	content := env.Redact(buffer.String())
 	formattedContent := FormatTerminalSystemOutput(content)
 	_, err = t.tlp.PutMsg(ctx, database.TermlogTypeStdout, formattedContent, t.containerID, t.taskID, t.subtaskID)
 	if err != nil {
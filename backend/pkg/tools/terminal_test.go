@@ -100,6 +100,12 @@ func (m *contextAwareMockDockerClient) CopyFromContainer(_ context.Context, _ st
 }
 func (m *contextAwareMockDockerClient) Cleanup(_ context.Context) error { return nil }
 func (m *contextAwareMockDockerClient) GetDefaultImage() string         { return "test-image" }
+func (m *contextAwareMockDockerClient) CommitContainer(_ context.Context, _ string, _ string) (string, error) {
+	return "", nil
+}
+func (m *contextAwareMockDockerClient) RemoveImage(_ context.Context, _ string) error { return nil }
+func (m *contextAwareMockDockerClient) ReapContainers(_ context.Context) error        { return nil }
+func (m *contextAwareMockDockerClient) Ping(_ context.Context) error                  { return nil }
 
 var _ docker.DockerClient = (*contextAwareMockDockerClient)(nil)
 
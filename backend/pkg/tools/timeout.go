@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pentagi/pkg/config"
+)
+
+// toolTimeoutReserve is the part of the dispatch deadline which the tools with own budget keep
+// to report the partial results before the tool call is timed out
+const toolTimeoutReserve = 5 * time.Second
+
+// defaultToolTimeouts limits the execution of the single tool call, the tools out of the list
+// are limited only by the flow context: agents and barriers run their own chains, the terminal
+// has the timeout argument of the command
+var defaultToolTimeouts = map[string]time.Duration{
+	FileToolName:            5 * time.Minute,
+	AttachmentsToolName:     5 * time.Minute,
+	ArtifactsToolName:       5 * time.Minute,
+	FlowMemoryToolName:      time.Minute,
+	OCRToolName:             2 * time.Minute,
+	BrowserToolName:         3 * time.Minute,
+	HeadlessBrowserToolName: 3 * time.Minute,
+	GoogleToolName:          2 * time.Minute,
+	DuckDuckGoToolName:      2 * time.Minute,
+	TavilyToolName:          3 * time.Minute,
+	TraversaalToolName:      2 * time.Minute,
+	PerplexityToolName:      3 * time.Minute,
+	SearxngToolName:         2 * time.Minute,
+	SploitusToolName:        2 * time.Minute,
+	GithubToolName:          2 * time.Minute,
+	ExploitDBToolName:       2 * time.Minute,
+	ExploitSearchToolName:   2 * time.Minute,
+	VirusTotalToolName:      2 * time.Minute,
+	WaybackToolName:         2 * time.Minute,
+	WhoisToolName:           time.Minute,
+	EPSSToolName:            time.Minute,
+	RobotsToolName:          time.Minute,
+	HTTPHeadersToolName:     time.Minute,
+	TLSInspectToolName:      time.Minute,
+	PortScanToolName:        3 * time.Minute,
+	HTTPFuzzToolName:        4 * time.Minute,
+	SearchInMemoryToolName:  2 * time.Minute,
+	SearchGuideToolName:     2 * time.Minute,
+	StoreGuideToolName:      2 * time.Minute,
+	SearchAnswerToolName:    2 * time.Minute,
+	StoreAnswerToolName:     2 * time.Minute,
+	SearchCodeToolName:      2 * time.Minute,
+	StoreCodeToolName:       2 * time.Minute,
+	GraphitiSearchToolName:  2 * time.Minute,
+}
+
+// ToolTimeouts is the execution limit of the single call per tool name, zero or missing value
+// means the call is limited only by the flow context
+type ToolTimeouts map[string]time.Duration
+
+// NewToolTimeouts returns the default timeouts with the overrides of the config in seconds
+func NewToolTimeouts(cfg *config.Config) ToolTimeouts {
+	timeouts := make(ToolTimeouts, len(defaultToolTimeouts))
+	for name, timeout := range defaultToolTimeouts {
+		timeouts[name] = timeout
+	}
+
+	if cfg != nil {
+		for name, seconds := range cfg.ToolTimeouts {
+			if seconds <= 0 {
+				delete(timeouts, name)
+				continue
+			}
+			timeouts[name] = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return timeouts
+}
+
+// Get returns the timeout of the tool, zero means no limit
+func (t ToolTimeouts) Get(name string) time.Duration {
+	return t[name]
+}
+
+// ToolTimeoutError is returned by the executor when the tool call isn't finished in its own timeout
+// while the flow context is still alive
+type ToolTimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+}
+
+func (e *ToolTimeoutError) Error() string {
+	return fmt.Sprintf("tool '%s' is timed out after %s", e.Tool, e.Timeout)
+}
+
+func (e *ToolTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// withToolTimeout wraps the context by the tool timeout, the parent deadline wins if it's earlier
+func withToolTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// toolBudget caps the own budget of the tool by the remaining time of the context
+// leaving the reserve to report the partial results before the deadline
+func toolBudget(ctx context.Context, budget time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return budget
+	}
+
+	remaining := time.Until(deadline)
+	remaining -= min(toolTimeoutReserve, remaining/10)
+	if remaining < budget {
+		return max(remaining, 0)
+	}
+
+	return budget
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+// timeoutMockQuerier records the finished tool calls in addition to the failed ones
+type timeoutMockQuerier struct {
+	scopeMockQuerier
+	finished []string
+}
+
+func (m *timeoutMockQuerier) UpdateToolcallFinishedResult(
+	_ context.Context, arg database.UpdateToolcallFinishedResultParams,
+) (database.Toolcall, error) {
+	m.finished = append(m.finished, arg.Result)
+	return database.Toolcall{ID: arg.ID, Status: database.ToolcallStatusFinished, Result: arg.Result}, nil
+}
+
+// slowHandler waits for the context or the delay and reports the partial result like the network tools do
+func slowHandler(delay time.Duration) ExecutorHandler {
+	return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "failed to fetch: " + ctx.Err().Error(), nil
+		case <-time.After(delay):
+			return "done", nil
+		}
+	}
+}
+
+func newTimeoutTestExecutor(timeouts ToolTimeouts, delay time.Duration) (*customExecutor, *timeoutMockQuerier) {
+	db := &timeoutMockQuerier{}
+	return &customExecutor{
+		flowID:   1,
+		db:       db,
+		timeouts: timeouts,
+		handlers: map[string]ExecutorHandler{
+			WhoisToolName:   slowHandler(delay),
+			BrowserToolName: slowHandler(delay),
+		},
+	}, db
+}
+
+func TestExecuteToolTimeout(t *testing.T) {
+	ce, db := newTimeoutTestExecutor(ToolTimeouts{WhoisToolName: 50 * time.Millisecond}, 5*time.Second)
+
+	start := time.Now()
+	_, err := ce.Execute(t.Context(), 0, "call-1", WhoisToolName, "", "", json.RawMessage(`{"target":"example.org"}`))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Execute() took %s, want to be cut by the tool timeout", elapsed)
+	}
+
+	var timeoutErr *ToolTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Execute() error = %v, want ToolTimeoutError", err)
+	}
+	if timeoutErr.Tool != WhoisToolName || timeoutErr.Timeout != 50*time.Millisecond {
+		t.Errorf("ToolTimeoutError = %+v", timeoutErr)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("ToolTimeoutError must wrap context.DeadlineExceeded")
+	}
+	if len(db.results) != 1 || db.results[0] != "failed to execute handler: tool 'whois' is timed out after 50ms" {
+		t.Errorf("failed toolcall results = %q", db.results)
+	}
+	if len(db.finished) != 0 {
+		t.Errorf("timed out call is stored as finished: %q", db.finished)
+	}
+}
+
+func TestExecuteToolTimeoutPerTool(t *testing.T) {
+	ce, db := newTimeoutTestExecutor(ToolTimeouts{WhoisToolName: 50 * time.Millisecond}, 200*time.Millisecond)
+
+	// the tool without timeout is limited only by the flow context
+	result, err := ce.Execute(t.Context(), 0, "call-1", BrowserToolName, "", "", json.RawMessage(`{"url":"example.org"}`))
+	if err != nil || result != "done" {
+		t.Errorf("Execute(browser) = %q, %v, want to finish", result, err)
+	}
+
+	// the tool which finishes in its timeout isn't affected
+	ce.timeouts[WhoisToolName] = time.Second
+	result, err = ce.Execute(t.Context(), 0, "call-2", WhoisToolName, "", "", json.RawMessage(`{"target":"example.org"}`))
+	if err != nil || result != "done" {
+		t.Errorf("Execute(whois) = %q, %v, want to finish", result, err)
+	}
+	if len(db.finished) != 2 || len(db.results) != 0 {
+		t.Errorf("finished = %q, failed = %q", db.finished, db.results)
+	}
+}
+
+func TestExecuteParentDeadline(t *testing.T) {
+	ce, _ := newTimeoutTestExecutor(ToolTimeouts{WhoisToolName: time.Minute}, 5*time.Second)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	// the earlier flow deadline wins and it isn't reported as the tool timeout
+	start := time.Now()
+	_, err := ce.Execute(ctx, 0, "call-1", WhoisToolName, "", "", json.RawMessage(`{"target":"example.org"}`))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Execute() took %s, want to be cut by the parent deadline", elapsed)
+	}
+
+	var timeoutErr *ToolTimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Errorf("Execute() error = %v, want the parent context result", err)
+	}
+}
+
+func TestNewToolTimeouts(t *testing.T) {
+	timeouts := NewToolTimeouts(nil)
+	if got := timeouts.Get(WhoisToolName); got != time.Minute {
+		t.Errorf("default whois timeout = %s", got)
+	}
+	for _, name := range []string{TerminalToolName, PentesterToolName, FinalyToolName} {
+		if got := timeouts.Get(name); got != 0 {
+			t.Errorf("%s timeout = %s, want no limit", name, got)
+		}
+	}
+
+	timeouts = NewToolTimeouts(&config.Config{ToolTimeouts: map[string]int{
+		BrowserToolName:  300,
+		TerminalToolName: 1800,
+		HTTPFuzzToolName: 0,
+	}})
+	if got := timeouts.Get(BrowserToolName); got != 5*time.Minute {
+		t.Errorf("browser timeout = %s, want 5m0s", got)
+	}
+	if got := timeouts.Get(TerminalToolName); got != 30*time.Minute {
+		t.Errorf("terminal timeout = %s, want 30m0s", got)
+	}
+	if got := timeouts.Get(HTTPFuzzToolName); got != 0 {
+		t.Errorf("http_fuzz timeout = %s, want disabled", got)
+	}
+	if got := defaultToolTimeouts[HTTPFuzzToolName]; got != 4*time.Minute {
+		t.Errorf("override changed the defaults: %s", got)
+	}
+}
+
+func TestToolBudget(t *testing.T) {
+	if got := toolBudget(t.Context(), time.Minute); got != time.Minute {
+		t.Errorf("toolBudget() without deadline = %s", got)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+	if got := toolBudget(ctx, 10*time.Second); got != 10*time.Second {
+		t.Errorf("toolBudget() within deadline = %s", got)
+	}
+	if got := toolBudget(ctx, 2*time.Minute); got > 27*time.Second || got < 26*time.Second {
+		t.Errorf("toolBudget() = %s, want the deadline minus the tenth", got)
+	}
+
+	ctx, cancel = context.WithTimeout(t.Context(), 2*time.Minute)
+	defer cancel()
+	if got := toolBudget(ctx, 5*time.Minute); got > 115*time.Second || got < 114*time.Second {
+		t.Errorf("toolBudget() = %s, want the deadline minus the reserve", got)
+	}
+
+	ctx, cancel = context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+	defer cancel()
+	if got := toolBudget(ctx, time.Minute); got != 0 {
+		t.Errorf("toolBudget() after deadline = %s, want 0", got)
+	}
+}
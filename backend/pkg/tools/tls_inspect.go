@@ -0,0 +1,555 @@
+package tools
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tlsInspectTimeout     = 20 * time.Second
+	defaultTLSInspectPort = "443"
+	// tlsInspectExpiryWarning is how long before the expiration the certificate is flagged
+	tlsInspectExpiryWarning = 30 * 24 * time.Hour
+	// tlsInspectMaxSANs is the max number of SANs of a single certificate in the output
+	tlsInspectMaxSANs = 100
+)
+
+// tlsLegacyVersions are probed with separate handshakes because the main handshake
+// negotiates only the best version supported by both sides
+var tlsLegacyVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11}
+
+// tlsFinding is a single issue of the TLS configuration or the certificate chain
+type tlsFinding struct {
+	Severity string
+	Message  string
+}
+
+// tlsInspectReport is the result of the inspection of a single TLS endpoint
+type tlsInspectReport struct {
+	Address      string
+	ServerName   string
+	Version      uint16
+	CipherSuite  uint16
+	ALPN         string
+	Legacy       map[uint16]bool
+	Chain        []*x509.Certificate
+	VerifyError  error
+	Findings     []tlsFinding
+	InspectedAt  time.Time
+	ThroughProxy bool
+}
+
+// TLSInspectError is returned when the TCP connection or the TLS handshake fails
+type TLSInspectError struct {
+	Address string
+	Stage   string
+	Err     error
+}
+
+func (e *TLSInspectError) Error() string {
+	return fmt.Sprintf("%s with %s failed: %v", e.Stage, e.Address, e.Err)
+}
+
+func (e *TLSInspectError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout returns true if the connection or the handshake didn't finish in time
+func (e *TLSInspectError) Timeout() bool {
+	var netErr net.Error
+	return errors.Is(e.Err, context.DeadlineExceeded) || (errors.As(e.Err, &netErr) && netErr.Timeout())
+}
+
+// tlsInspect represents the TLS configuration and certificate inspection tool
+type tlsInspect struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+}
+
+// NewTLSInspectTool creates a new TLS configuration and certificate inspection tool instance
+func NewTLSInspectTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+) Tool {
+	return &tlsInspect{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+	}
+}
+
+// Handle processes a TLS inspection request from an AI agent
+func (t *tlsInspect) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !t.IsAvailable() {
+		return "", fmt.Errorf("tls_inspect is not available")
+	}
+
+	var action TLSInspectAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(t.flowID, t.taskID, t.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal tls inspect action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	address, host, err := normalizeTLSInspectTarget(action.Target)
+	if err != nil {
+		return fmt.Sprintf("failed to inspect TLS: %v", err), nil
+	}
+
+	serverName := strings.TrimSpace(action.ServerName)
+	if serverName == "" && net.ParseIP(host) == nil {
+		serverName = host
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"address":     address,
+		"server_name": serverName,
+	})
+
+	report, err := t.inspect(ctx, address, serverName)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("tls inspection error swallowed"),
+			langfuse.WithEventInput(address),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":   TLSInspectToolName,
+				"engine":      "tls_inspect",
+				"address":     address,
+				"server_name": serverName,
+				"error":       err.Error(),
+			}),
+		)
+
+		var inspectErr *TLSInspectError
+		if errors.As(err, &inspectErr) && inspectErr.Timeout() {
+			logger.WithError(err).Warn("tls inspection timed out")
+			return fmt.Sprintf("failed to inspect TLS: %v, the port may be filtered or the service is too slow", err), nil
+		}
+
+		logger.WithError(err).Error("failed to inspect TLS")
+		return fmt.Sprintf("failed to inspect TLS: %v", err), nil
+	}
+
+	result := formatTLSInspectReport(report, resultSizeLimitsFromContext(ctx).MaxResultSize)
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && t.slp != nil {
+		_, _ = t.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeTlsInspect,
+			address,
+			result,
+			t.taskID,
+			t.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// normalizeTLSInspectTarget converts host, host:port or URL to the dial address and the host,
+// port 443 is used if it's omitted
+func normalizeTLSInspectTarget(target string) (string, string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", "", errors.New("target is empty")
+	}
+
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid target url '%s': %w", target, err)
+		}
+		target = parsed.Host
+	} else if idx := strings.Index(target, "/"); idx >= 0 {
+		target = target[:idx]
+	}
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = strings.Trim(target, "[]"), defaultTLSInspectPort
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("invalid target '%s': host is empty", target)
+	}
+	if num, err := strconv.Atoi(port); err != nil || num < 1 || num > 65535 {
+		return "", "", fmt.Errorf("invalid target '%s': port must be a number from 1 to 65535", target)
+	}
+
+	return net.JoinHostPort(strings.ToLower(host), port), strings.ToLower(host), nil
+}
+
+// inspect performs the handshake which accepts any certificate to get the chain, verifies
+// the chain separately and probes legacy protocol versions with additional handshakes
+func (t *tlsInspect) inspect(ctx context.Context, address, serverName string) (tlsInspectReport, error) {
+	report := tlsInspectReport{
+		Address:      address,
+		ServerName:   serverName,
+		Legacy:       make(map[uint16]bool),
+		InspectedAt:  time.Now(),
+		ThroughProxy: t.cfg != nil && t.cfg.ProxyURL != "",
+	}
+
+	// all versions and cipher suites are offered to inspect servers with legacy configuration,
+	// the server still chooses the best one it supports
+	state, err := t.handshake(ctx, address, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2", "http/1.1"},
+		MinVersion:         tls.VersionTLS10,
+		CipherSuites:       allTLSCipherSuites(),
+	})
+	if err != nil {
+		return report, err
+	}
+
+	report.Version = state.Version
+	report.CipherSuite = state.CipherSuite
+	report.ALPN = state.NegotiatedProtocol
+	report.Chain = state.PeerCertificates
+
+	if len(report.Chain) == 0 {
+		return report, &TLSInspectError{Address: address, Stage: "TLS handshake", Err: errors.New("server sent no certificates")}
+	}
+
+	roots, err := system.GetSystemCertPool(t.cfg)
+	if err != nil {
+		return report, fmt.Errorf("failed to load trusted CA certificates: %w", err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range report.Chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	verifyHost := serverName
+	if verifyHost == "" {
+		verifyHost, _, _ = net.SplitHostPort(address)
+	}
+	_, report.VerifyError = report.Chain[0].Verify(x509.VerifyOptions{
+		DNSName:       verifyHost,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   report.InspectedAt,
+	})
+
+	if report.Version > tls.VersionTLS11 {
+		for _, version := range tlsLegacyVersions {
+			if ctx.Err() != nil {
+				break
+			}
+			_, err := t.handshake(ctx, address, &tls.Config{
+				ServerName:         serverName,
+				InsecureSkipVerify: true,
+				MinVersion:         version,
+				MaxVersion:         version,
+				CipherSuites:       allTLSCipherSuites(),
+			})
+			report.Legacy[version] = err == nil
+		}
+	} else {
+		report.Legacy[report.Version] = true
+	}
+
+	report.Findings = analyzeTLS(report)
+
+	return report, nil
+}
+
+// handshake connects to the address and performs the TLS handshake within the tool timeout
+// and the deadline of the context
+func (t *tlsInspect) handshake(ctx context.Context, address string, tlsConfig *tls.Config) (tls.ConnectionState, error) {
+	ctx, cancel := context.WithTimeout(ctx, toolBudget(ctx, tlsInspectTimeout))
+	defer cancel()
+
+	conn, err := dialTCP(ctx, t.cfg, address)
+	if err != nil {
+		return tls.ConnectionState{}, &TLSInspectError{Address: address, Stage: "connection", Err: err}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return tls.ConnectionState{}, &TLSInspectError{Address: address, Stage: "TLS handshake", Err: err}
+	}
+
+	return tlsConn.ConnectionState(), nil
+}
+
+func allTLSCipherSuites() []uint16 {
+	var ids []uint16
+	for _, suite := range slices.Concat(tls.CipherSuites(), tls.InsecureCipherSuites()) {
+		ids = append(ids, suite.ID)
+	}
+
+	return ids
+}
+
+// analyzeTLS flags outdated protocols, weak cipher suites and problems of the certificates
+func analyzeTLS(report tlsInspectReport) []tlsFinding {
+	var findings []tlsFinding
+	add := func(severity, format string, args ...any) {
+		findings = append(findings, tlsFinding{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if report.Version < tls.VersionTLS12 {
+		add(severityHigh, "negotiated protocol %s is deprecated (RFC 8996)", tls.VersionName(report.Version))
+	}
+	for _, version := range tlsLegacyVersions {
+		if report.Legacy[version] && version != report.Version {
+			add(severityMedium, "deprecated protocol %s is still supported", tls.VersionName(version))
+		}
+	}
+
+	suite := tls.CipherSuiteName(report.CipherSuite)
+	switch {
+	case slices.ContainsFunc(tls.InsecureCipherSuites(), func(s *tls.CipherSuite) bool { return s.ID == report.CipherSuite }):
+		add(severityHigh, "negotiated cipher suite %s is insecure", suite)
+	case report.Version < tls.VersionTLS13 && !strings.Contains(suite, "_ECDHE_") && !strings.Contains(suite, "_DHE_"):
+		add(severityMedium, "negotiated cipher suite %s has no forward secrecy (RSA key exchange)", suite)
+	case strings.Contains(suite, "_CBC_"):
+		add(severityLow, "negotiated cipher suite %s uses CBC mode, prefer AEAD ciphers", suite)
+	}
+
+	leaf := report.Chain[0]
+	selfSigned := len(report.Chain) == 1 && isSelfSignedCert(leaf)
+
+	var hostErr x509.HostnameError
+	var authErr x509.UnknownAuthorityError
+	switch {
+	case report.VerifyError == nil && selfSigned:
+		add(severityInfo, "certificate is self-signed but trusted by the configured CA certificates")
+	case report.VerifyError == nil:
+	case errors.As(report.VerifyError, &hostErr):
+		add(severityHigh, "certificate doesn't match the host: %v", report.VerifyError)
+	case selfSigned:
+		add(severityHigh, "certificate is self-signed and not trusted")
+	case errors.As(report.VerifyError, &authErr):
+		add(severityHigh, "certificate is signed by unknown authority, the chain may be incomplete or the CA is private")
+	default:
+		add(severityHigh, "certificate chain verification failed: %v", report.VerifyError)
+	}
+
+	for idx, cert := range report.Chain {
+		label := certLabel(idx, cert)
+		switch {
+		case report.InspectedAt.After(cert.NotAfter):
+			add(severityHigh, "%s expired on %s", label, cert.NotAfter.UTC().Format(time.DateOnly))
+		case report.InspectedAt.Before(cert.NotBefore):
+			add(severityHigh, "%s isn't valid before %s", label, cert.NotBefore.UTC().Format(time.DateOnly))
+		case cert.NotAfter.Sub(report.InspectedAt) < tlsInspectExpiryWarning:
+			add(severityMedium, "%s expires in %d days", label, int(cert.NotAfter.Sub(report.InspectedAt).Hours()/24))
+		}
+
+		if bits, weak := weakPublicKey(cert); weak {
+			add(severityHigh, "%s has weak %s key of %d bits", label, cert.PublicKeyAlgorithm, bits)
+		}
+
+		// signatures of self-signed roots aren't checked by clients
+		if !isSelfSignedCert(cert) || idx == 0 {
+			switch cert.SignatureAlgorithm {
+			case x509.MD2WithRSA, x509.MD5WithRSA:
+				add(severityHigh, "%s is signed with broken algorithm %s", label, cert.SignatureAlgorithm)
+			case x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+				add(severityMedium, "%s is signed with deprecated algorithm %s", label, cert.SignatureAlgorithm)
+			}
+		}
+	}
+
+	return findings
+}
+
+func isSelfSignedCert(cert *x509.Certificate) bool {
+	return slices.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+func weakPublicKey(cert *x509.Certificate) (int, bool) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen(), key.N.BitLen() < 2048
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize, key.Curve.Params().BitSize < 256
+	}
+
+	return 0, false
+}
+
+func publicKeyDescription(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d bits", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA %s", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+
+	return cert.PublicKeyAlgorithm.String()
+}
+
+func certLabel(idx int, cert *x509.Certificate) string {
+	name := cert.Subject.CommonName
+	if name == "" {
+		name = cert.Subject.String()
+	}
+
+	return fmt.Sprintf("certificate #%d (%s)", idx, name)
+}
+
+// formatTLSInspectReport renders the negotiated parameters, findings and the certificate chain
+// as markdown capped by sizeLimit
+func formatTLSInspectReport(report tlsInspectReport, sizeLimit int) string {
+	var sb strings.Builder
+	sb.WriteString("# TLS Inspection\n\n")
+	sb.WriteString(fmt.Sprintf("**Target:** %s  \n", report.Address))
+	if report.ServerName != "" {
+		sb.WriteString(fmt.Sprintf("**SNI:** %s  \n", report.ServerName))
+	}
+	sb.WriteString(fmt.Sprintf("**Protocol:** %s  \n", tls.VersionName(report.Version)))
+	sb.WriteString(fmt.Sprintf("**Cipher suite:** %s  \n", tls.CipherSuiteName(report.CipherSuite)))
+	if report.ALPN != "" {
+		sb.WriteString(fmt.Sprintf("**ALPN:** %s  \n", report.ALPN))
+	}
+
+	var legacy []string
+	for _, version := range tlsLegacyVersions {
+		if supported, ok := report.Legacy[version]; ok {
+			state := "not supported"
+			if supported {
+				state = "supported"
+			}
+			legacy = append(legacy, fmt.Sprintf("%s %s", tls.VersionName(version), state))
+		}
+	}
+	if len(legacy) > 0 {
+		sb.WriteString(fmt.Sprintf("**Legacy protocols:** %s  \n", strings.Join(legacy, ", ")))
+	}
+
+	trust := "trusted"
+	if report.VerifyError != nil {
+		trust = "not trusted: " + report.VerifyError.Error()
+	}
+	sb.WriteString(fmt.Sprintf("**Chain:** %s  \n", trust))
+	if report.ThroughProxy {
+		sb.WriteString("**Note:** the connection goes through the configured proxy, an intercepting proxy " +
+			"presents its own certificate  \n")
+	}
+
+	sb.WriteString("\n## Findings\n\n")
+	if len(report.Findings) == 0 {
+		sb.WriteString("No issues found\n")
+	}
+	for _, finding := range report.Findings {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", finding.Severity, finding.Message))
+	}
+
+	sb.WriteString("\n## Certificate Chain\n")
+	for idx, cert := range report.Chain {
+		var cb strings.Builder
+		role := "intermediate"
+		switch {
+		case idx == 0:
+			role = "leaf"
+		case isSelfSignedCert(cert):
+			role = "root"
+		}
+
+		fingerprint := sha256.Sum256(cert.Raw)
+		cb.WriteString(fmt.Sprintf("\n### #%d %s\n\n", idx, role))
+		cb.WriteString(fmt.Sprintf("- **Subject:** %s\n", cert.Subject))
+		cb.WriteString(fmt.Sprintf("- **Issuer:** %s\n", cert.Issuer))
+		cb.WriteString(fmt.Sprintf("- **Valid:** %s - %s (%s)\n",
+			cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339),
+			certValidity(cert, report.InspectedAt)))
+		cb.WriteString(fmt.Sprintf("- **Serial:** %s\n", cert.SerialNumber.Text(16)))
+		cb.WriteString(fmt.Sprintf("- **Key:** %s\n", publicKeyDescription(cert)))
+		cb.WriteString(fmt.Sprintf("- **Signature:** %s\n", cert.SignatureAlgorithm))
+		cb.WriteString(fmt.Sprintf("- **SHA-256 fingerprint:** %s\n", hex.EncodeToString(fingerprint[:])))
+		if cert.IsCA {
+			cb.WriteString("- **CA:** true\n")
+		}
+
+		var sans []string
+		sans = append(sans, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		sans = append(sans, cert.EmailAddresses...)
+		for _, uri := range cert.URIs {
+			sans = append(sans, uri.String())
+		}
+		if len(sans) > tlsInspectMaxSANs {
+			sans = append(sans[:tlsInspectMaxSANs], fmt.Sprintf("... and %d more", len(sans)-tlsInspectMaxSANs))
+		}
+		if len(sans) > 0 {
+			cb.WriteString(fmt.Sprintf("- **SANs:** %s\n", strings.Join(sans, ", ")))
+		}
+
+		if sb.Len()+cb.Len() > sizeLimit-truncationMsgBuffer {
+			sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d certificates (output truncated, exceeded %s limit)\n",
+				idx, len(report.Chain), formatSizeLimit(sizeLimit)))
+			return sb.String()
+		}
+		sb.WriteString(cb.String())
+	}
+
+	return sb.String()
+}
+
+func certValidity(cert *x509.Certificate, now time.Time) string {
+	switch {
+	case now.After(cert.NotAfter):
+		return fmt.Sprintf("expired %d days ago", int(now.Sub(cert.NotAfter).Hours()/24))
+	case now.Before(cert.NotBefore):
+		return "not valid yet"
+	}
+
+	return fmt.Sprintf("%d days left", int(cert.NotAfter.Sub(now).Hours()/24))
+}
+
+// IsAvailable returns true if the TLS inspection tool is enabled
+func (t *tlsInspect) IsAvailable() bool {
+	return t.cfg != nil && t.cfg.TLSInspectEnabled
+}
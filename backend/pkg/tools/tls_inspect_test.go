@@ -0,0 +1,417 @@
+package tools
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func testTLSInspectConfig() *config.Config {
+	return &config.Config{TLSInspectEnabled: true}
+}
+
+func handleTLSInspect(t *testing.T, tool Tool, args string) string {
+	t.Helper()
+
+	got, err := tool.Handle(t.Context(), TLSInspectToolName, []byte(args))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	return got
+}
+
+func assertContainsAll(t *testing.T, got string, wants ...string) {
+	t.Helper()
+
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+}
+
+// newTestCert creates a certificate signed by the parent or self-signed if parent is nil
+func newTestCert(t *testing.T, tmpl *x509.Certificate, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	if tmpl.SerialNumber == nil {
+		tmpl.SerialNumber = big.NewInt(1)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	return cert
+}
+
+func TestNormalizeTLSInspectTarget(t *testing.T) {
+	tests := []struct {
+		input   string
+		address string
+		host    string
+		wantErr bool
+	}{
+		{input: "Example.com", address: "example.com:443", host: "example.com"},
+		{input: "example.com:8443", address: "example.com:8443", host: "example.com"},
+		{input: "https://example.com:9443/login?x=1", address: "example.com:9443", host: "example.com"},
+		{input: "https://example.com/login", address: "example.com:443", host: "example.com"},
+		{input: "example.com/path", address: "example.com:443", host: "example.com"},
+		{input: "10.0.0.5", address: "10.0.0.5:443", host: "10.0.0.5"},
+		{input: "[2001:db8::1]:993", address: "[2001:db8::1]:993", host: "2001:db8::1"},
+		{input: "2001:db8::1", address: "[2001:db8::1]:443", host: "2001:db8::1"},
+		{input: " ", wantErr: true},
+		{input: "example.com:0", wantErr: true},
+		{input: "example.com:https", wantErr: true},
+		{input: ":443", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			address, host, err := normalizeTLSInspectTarget(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeTLSInspectTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if address != tt.address || host != tt.host {
+				t.Errorf("normalizeTLSInspectTarget() = %q, %q, want %q, %q", address, host, tt.address, tt.host)
+			}
+		})
+	}
+}
+
+func TestTLSInspectHandle(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	address := server.Listener.Addr().String()
+	leaf := server.Certificate()
+
+	t.Run("untrusted self-signed", func(t *testing.T) {
+		slp := &searchLogProviderMock{}
+		tool := NewTLSInspectTool(testTLSInspectConfig(), 1, nil, nil, slp)
+
+		ctx := PutAgentContext(t.Context(), database.MsgchainTypePentester)
+		got, err := tool.Handle(ctx, TLSInspectToolName, []byte(fmt.Sprintf(`{"target":%q,"message":"inspect"}`, address)))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+
+		assertContainsAll(t, got,
+			"**Target:** "+address,
+			"**Protocol:** TLS 1.3",
+			"**Cipher suite:** TLS_",
+			"**ALPN:** http/1.1",
+			"**Legacy protocols:** TLS 1.0 not supported, TLS 1.1 not supported",
+			"**Chain:** not trusted: x509: certificate signed by unknown authority",
+			"- **high:** certificate is self-signed and not trusted",
+			"### #0 leaf",
+			"- **Issuer:** "+leaf.Issuer.String(),
+			"- **SANs:** example.com, *.example.com, 127.0.0.1, ::1",
+			"- **CA:** true",
+		)
+		if strings.Contains(got, "**SNI:**") {
+			t.Errorf("SNI must not be sent for IP address:\n%s", got)
+		}
+		if slp.calls != 1 || slp.engine != database.SearchengineTypeTlsInspect || slp.query != address {
+			t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+		}
+	})
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw}), 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	trustedCfg := testTLSInspectConfig()
+	trustedCfg.ExternalSSLCAPath = caPath
+	tool := NewTLSInspectTool(trustedCfg, 1, nil, nil, nil)
+
+	t.Run("trusted by configured CA", func(t *testing.T) {
+		got := handleTLSInspect(t, tool, fmt.Sprintf(`{"target":%q,"server_name":"example.com"}`, address))
+		assertContainsAll(t, got,
+			"**SNI:** example.com",
+			"**Chain:** trusted",
+			"- **info:** certificate is self-signed but trusted by the configured CA certificates",
+		)
+	})
+
+	t.Run("host mismatch", func(t *testing.T) {
+		got := handleTLSInspect(t, tool, fmt.Sprintf(`{"target":%q,"server_name":"other.test"}`, address))
+		assertContainsAll(t, got, "- **high:** certificate doesn't match the host")
+	})
+}
+
+func TestTLSInspectHandle_LegacyAndWeakCipher(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS10,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_CBC_SHA},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	tool := NewTLSInspectTool(testTLSInspectConfig(), 1, nil, nil, nil)
+	got := handleTLSInspect(t, tool, fmt.Sprintf(`{"target":%q}`, server.Listener.Addr().String()))
+
+	assertContainsAll(t, got,
+		"**Protocol:** TLS 1.2",
+		"**Cipher suite:** TLS_RSA_WITH_AES_128_CBC_SHA",
+		"**Legacy protocols:** TLS 1.0 supported, TLS 1.1 supported",
+		"- **medium:** deprecated protocol TLS 1.0 is still supported",
+		"- **medium:** deprecated protocol TLS 1.1 is still supported",
+		"- **high:** negotiated cipher suite TLS_RSA_WITH_AES_128_CBC_SHA is insecure",
+	)
+}
+
+func TestTLSInspectHandle_ThroughProxy(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// only the mock domain is intercepted, the test server is reached through the CONNECT tunnel
+	proxy, err := newTestProxy("example.invalid", http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	cfg := testTLSInspectConfig()
+	cfg.ProxyURL = proxy.URL()
+	tool := NewTLSInspectTool(cfg, 1, nil, nil, nil)
+
+	got := handleTLSInspect(t, tool, fmt.Sprintf(`{"target":%q}`, server.Listener.Addr().String()))
+	assertContainsAll(t, got,
+		"**Protocol:** TLS 1.3",
+		"**Note:** the connection goes through the configured proxy",
+		"- **SANs:** example.com, *.example.com, 127.0.0.1, ::1",
+	)
+}
+
+func TestTLSInspectHandle_Errors(t *testing.T) {
+	plain := httptest.NewServer(http.NotFoundHandler())
+	defer plain.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	tool := NewTLSInspectTool(testTLSInspectConfig(), 1, nil, nil, nil)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty target", `{"target":""}`, "failed to inspect TLS: target is empty"},
+		{"invalid port", `{"target":"example.com:99999"}`, "port must be a number from 1 to 65535"},
+		{"connection refused", fmt.Sprintf(`{"target":%q}`, closedAddress), "failed to inspect TLS: connection with " + closedAddress + " failed"},
+		{"not a tls service", fmt.Sprintf(`{"target":%q}`, plain.Listener.Addr().String()), "failed to inspect TLS: TLS handshake with " + plain.Listener.Addr().String() + " failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := handleTLSInspect(t, tool, tt.args)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Handle() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.Handle(t.Context(), TLSInspectToolName, []byte(`{`)); err == nil {
+		t.Error("Handle() expected unmarshal error")
+	}
+	if NewTLSInspectTool(&config.Config{}, 1, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+}
+
+func TestTLSInspectHandle_ContextDeadline(t *testing.T) {
+	// the listener accepts connections but never answers the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 300*time.Millisecond)
+	defer cancel()
+
+	tool := NewTLSInspectTool(testTLSInspectConfig(), 1, nil, nil, nil)
+
+	start := time.Now()
+	got, err := tool.Handle(ctx, TLSInspectToolName, []byte(fmt.Sprintf(`{"target":%q}`, listener.Addr().String())))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Handle() took %v, the context deadline isn't respected", elapsed)
+	}
+	if !strings.Contains(got, "the port may be filtered or the service is too slow") {
+		t.Errorf("Handle() = %q, want timeout message", got)
+	}
+}
+
+func TestAnalyzeTLS_Certificates(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             now.AddDate(-5, 0, 0),
+		NotAfter:              now.AddDate(5, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, caKey, nil, nil)
+
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		leaf  *x509.Certificate
+		wants []string
+	}{
+		{
+			name: "expired",
+			leaf: &x509.Certificate{
+				Subject:   pkix.Name{CommonName: "expired.test"},
+				NotBefore: now.AddDate(-2, 0, 0),
+				NotAfter:  now.AddDate(0, 0, -3),
+			},
+			wants: []string{"high: certificate #0 (expired.test) expired on 2026-10-12"},
+		},
+		{
+			name: "expires soon",
+			leaf: &x509.Certificate{
+				Subject:   pkix.Name{CommonName: "soon.test"},
+				NotBefore: now.AddDate(0, -2, 0),
+				NotAfter:  now.Add(10*24*time.Hour + time.Hour),
+			},
+			wants: []string{"medium: certificate #0 (soon.test) expires in 10 days"},
+		},
+		{
+			name: "not valid yet",
+			leaf: &x509.Certificate{
+				Subject:   pkix.Name{CommonName: "future.test"},
+				NotBefore: now.AddDate(0, 1, 0),
+				NotAfter:  now.AddDate(1, 0, 0),
+			},
+			wants: []string{"high: certificate #0 (future.test) isn't valid before 2026-11-15"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf := newTestCert(t, tt.leaf, weakKey, ca, caKey)
+			findings := analyzeTLS(tlsInspectReport{
+				Version:     tls.VersionTLS13,
+				CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+				Legacy:      map[uint16]bool{},
+				Chain:       []*x509.Certificate{leaf, ca},
+				VerifyError: x509.UnknownAuthorityError{Cert: leaf},
+				InspectedAt: now,
+			})
+
+			var lines []string
+			for _, finding := range findings {
+				lines = append(lines, finding.Severity+": "+finding.Message)
+			}
+			got := strings.Join(lines, "\n")
+
+			wants := append(tt.wants,
+				"high: certificate #0 ("+tt.leaf.Subject.CommonName+") has weak RSA key of 1024 bits",
+				"high: certificate is signed by unknown authority",
+			)
+			assertContainsAll(t, got, wants...)
+			if strings.Contains(got, "Test Root CA") {
+				t.Errorf("valid root certificate is flagged:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestFormatTLSInspectReport_SizeLimit(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "many-sans.test"},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().AddDate(1, 0, 0),
+	}
+	for i := range 150 {
+		tmpl.DNSNames = append(tmpl.DNSNames, fmt.Sprintf("host-%03d.%s.test", i, strings.Repeat("a", 50)))
+	}
+	cert := newTestCert(t, tmpl, key, nil, nil)
+
+	chain := make([]*x509.Certificate, 20)
+	for i := range chain {
+		chain[i] = cert
+	}
+
+	got := formatTLSInspectReport(tlsInspectReport{
+		Address:     "many-sans.test:443",
+		Version:     tls.VersionTLS13,
+		CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+		Chain:       chain,
+		InspectedAt: time.Now(),
+	}, DefaultMaxResultSize)
+
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("len(result) = %d, want <= %d", len(got), DefaultMaxResultSize)
+	}
+	assertContainsAll(t, got,
+		"... and 50 more",
+		"certificates (output truncated, exceeded 80 KB limit)",
+	)
+}
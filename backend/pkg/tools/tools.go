@@ -48,11 +48,11 @@ type DisableFunction struct {
 }
 
 type ExternalFunction struct {
-	Name    string        `form:"name" json:"name" validate:"required"`
+	Name    string        `form:"name" json:"name" validate:"required,max=64"`
 	URL     string        `form:"url" json:"url" validate:"required,url" example:"https://example.com/api/v1/function"`
 	Timeout *int64        `form:"timeout,omitempty" json:"timeout,omitempty" validate:"omitempty,min=1" example:"60"`
 	Context []string      `form:"context,omitempty" json:"context,omitempty" validate:"omitempty,dive,oneof=agent adviser coder searcher generator memorist enricher reporter assistant,required"`
-	Schema  schema.Schema `form:"schema" json:"schema" validate:"required" swaggertype:"object"`
+	Schema  schema.Schema `form:"schema" json:"schema" validate:"required,valid" swaggertype:"object"`
 }
 
 type FunctionInfo struct {
@@ -143,13 +143,24 @@ type flowToolsExecutor struct {
 	db             database.Querier
 	cfg            *config.Config
 	store          *pgvector.Store
+	memoryEmbedder *FlowMemoryEmbedder
 	graphitiClient *graphiti.Client
 	image          string
+	limits         docker.ContainerLimits
 	docker         docker.DockerClient
 	primaryID      int64
 	primaryLID     string
 	functions      *Functions
+	env            FlowEnvProvider
 	replacer       anonymizer.Replacer
+	cache          Cache
+	searchCache    SearchCache
+	limiter        *toolCallLimiter
+	commandGuard   *CommandGuard
+	scopeGuard     *ScopeGuard
+	timeouts       ToolTimeouts
+	resultSize     ResultSizeLimits
+	redactor       *SecretRedactor
 
 	definitions map[string]llms.FunctionDefinition
 	handlers    map[string]ExecutorHandler
@@ -277,6 +288,11 @@ type ReporterExecutorConfig struct {
 type FlowToolsExecutor interface {
 	SetFlowID(flowID int64)
 	SetImage(image string)
+	SetContainerLimits(limits docker.ContainerLimits)
+	SetToolCallLimits(limits ToolCallLimits)
+	SetCommandPolicy(policy CommandPolicy) error
+	SetFlowScope(scope FlowScope) error
+	SetResultSizeLimits(limits ResultSizeLimits)
 	SetEmbedder(embedder embeddings.Embedder)
 	SetFunctions(functions *Functions)
 	SetScreenshotProvider(sp ScreenshotProvider)
@@ -289,6 +305,7 @@ type FlowToolsExecutor interface {
 
 	Prepare(ctx context.Context) error
 	Release(ctx context.Context) error
+	Detach(ctx context.Context) error
 	GetCustomExecutor(cfg CustomExecutorConfig) (ContextToolsExecutor, error)
 	GetAssistantExecutor(cfg AssistantExecutorConfig) (ContextToolsExecutor, error)
 	GetPrimaryExecutor(cfg PrimaryExecutorConfig) (ContextToolsExecutor, error)
@@ -323,27 +340,90 @@ func NewFlowToolsExecutor(
 		return nil, fmt.Errorf("failed to create replacer: %v", err)
 	}
 
+	commandGuard, err := NewCommandGuard(db, GlobalCommandPolicy(cfg), CommandPolicy{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create command guard: %w", err)
+	}
+
+	redactor, err := NewSecretRedactor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret redactor: %w", err)
+	}
+
 	return &flowToolsExecutor{
-		db:          db,
-		docker:      docker,
-		functions:   functions,
-		replacer:    replacer,
-		cfg:         cfg,
-		flowID:      flowID,
-		definitions: make(map[string]llms.FunctionDefinition),
-		handlers:    make(map[string]ExecutorHandler),
+		db:           db,
+		docker:       docker,
+		functions:    functions,
+		env:          NewFlowEnvProvider(db, cfg, flowID),
+		replacer:     replacer,
+		cache:        GetSharedCache(cfg),
+		searchCache:  GetSharedSearchCache(cfg),
+		limits:       defaultContainerLimits(cfg),
+		commandGuard: commandGuard,
+		timeouts:     NewToolTimeouts(cfg),
+		resultSize:   GlobalResultSizeLimits(cfg),
+		redactor:     redactor,
+		cfg:          cfg,
+		flowID:       flowID,
+		definitions:  make(map[string]llms.FunctionDefinition),
+		handlers:     make(map[string]ExecutorHandler),
 	}, nil
 }
 
+// defaultContainerLimits is used in the constructor where docker argument shadows the package name
+func defaultContainerLimits(cfg *config.Config) docker.ContainerLimits {
+	return docker.DefaultContainerLimits(cfg)
+}
+
 func (fte *flowToolsExecutor) SetFlowID(flowID int64) {
 	fte.flowID = flowID
+	fte.env = NewFlowEnvProvider(fte.db, fte.cfg, flowID)
 }
 
 func (fte *flowToolsExecutor) SetImage(image string) {
 	fte.image = image
 }
 
+func (fte *flowToolsExecutor) SetContainerLimits(limits docker.ContainerLimits) {
+	fte.limits = limits
+}
+
+func (fte *flowToolsExecutor) SetToolCallLimits(limits ToolCallLimits) {
+	fte.limiter = newToolCallLimiter(limits)
+}
+
+func (fte *flowToolsExecutor) SetCommandPolicy(policy CommandPolicy) error {
+	commandGuard, err := NewCommandGuard(fte.db, GlobalCommandPolicy(fte.cfg), policy)
+	if err != nil {
+		return fmt.Errorf("failed to create command guard: %w", err)
+	}
+
+	fte.commandGuard = commandGuard
+	return nil
+}
+
+func (fte *flowToolsExecutor) SetFlowScope(scope FlowScope) error {
+	scopeGuard, err := NewScopeGuard(fte.db, scope)
+	if err != nil {
+		return fmt.Errorf("failed to create scope guard: %w", err)
+	}
+
+	fte.scopeGuard = scopeGuard
+	return nil
+}
+
+func (fte *flowToolsExecutor) SetResultSizeLimits(limits ResultSizeLimits) {
+	fte.resultSize = limits.resolve()
+}
+
+// GlobalCommandPolicy returns the terminal command policy which is set in the config for all flows
+func GlobalCommandPolicy(cfg *config.Config) CommandPolicy {
+	return CommandPolicy{Allow: cfg.TerminalCommandAllow, Deny: cfg.TerminalCommandDeny}
+}
+
 func (fte *flowToolsExecutor) SetEmbedder(embedder embeddings.Embedder) {
+	fte.memoryEmbedder = NewFlowMemoryEmbedder(fte.cfg, embedder)
+
 	if !embedder.IsAvailable() {
 		return
 	}
@@ -379,7 +459,7 @@ func (fte *flowToolsExecutor) SetMsgLogProvider(mlp MsgLogProvider) {
 }
 
 func (fte *flowToolsExecutor) SetSearchLogProvider(slp SearchLogProvider) {
-	fte.slp = slp
+	fte.slp = fte.redactor.WrapSearchLogProvider(slp)
 }
 
 func (fte *flowToolsExecutor) SetTermLogProvider(tlp TermLogProvider) {
@@ -400,8 +480,13 @@ func (fte *flowToolsExecutor) Prepare(ctx context.Context) error {
 		case database.ContainerStatusRunning:
 			fte.primaryID = cnt.ID
 			fte.primaryLID = cnt.LocalID.String
+			fte.copyAttachments(ctx)
 			return nil
 		default:
+			// keep resource limits of the previous container when it's spawned again
+			if limits := docker.ContainerLimitsFromDB(cnt); limits != (docker.ContainerLimits{}) {
+				fte.limits = limits
+			}
 			fte.docker.DeleteContainer(ctx, cnt.LocalID.String, cnt.ID)
 		}
 	}
@@ -411,6 +496,11 @@ func (fte *flowToolsExecutor) Prepare(ctx context.Context) error {
 		capAdd = append(capAdd, "NET_ADMIN")
 	}
 
+	hostConfig := &container.HostConfig{
+		CapAdd: capAdd,
+	}
+	fte.limits.Apply(hostConfig)
+
 	containerName := PrimaryTerminalName(fte.flowID)
 	cnt, err := fte.docker.SpawnContainer(
 		ctx,
@@ -421,9 +511,7 @@ func (fte *flowToolsExecutor) Prepare(ctx context.Context) error {
 			Image:      fte.image,
 			Entrypoint: []string{"tail", "-f", "/dev/null"},
 		},
-		&container.HostConfig{
-			CapAdd: capAdd,
-		},
+		hostConfig,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to spawn container '%s': %w", containerName, err)
@@ -431,10 +519,26 @@ func (fte *flowToolsExecutor) Prepare(ctx context.Context) error {
 
 	fte.primaryID = cnt.ID
 	fte.primaryLID = cnt.LocalID.String
+	fte.copyAttachments(ctx)
 
 	return nil
 }
 
+// copyAttachments puts the flow attachments to the primary container, the failure doesn't stop the flow
+// because the attachments are still available for the agents through the attachments tool
+func (fte *flowToolsExecutor) copyAttachments(ctx context.Context) {
+	attachments, err := fte.db.GetFlowAttachments(ctx, fte.flowID)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("failed to get flow attachments")
+		return
+	}
+
+	err = CopyAttachmentsToContainer(ctx, fte.cfg, fte.docker, fte.primaryLID, attachments)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("failed to copy flow attachments to container")
+	}
+}
+
 func (fte *flowToolsExecutor) Release(ctx context.Context) error {
 	if fte.store != nil {
 		fte.store.Close()
@@ -449,6 +553,16 @@ func (fte *flowToolsExecutor) Release(ctx context.Context) error {
 	return nil
 }
 
+// Detach releases the executor resources but keeps the flow containers, they are reattached
+// when the flow is loaded again or removed by the purge of the deleted flow
+func (fte *flowToolsExecutor) Detach(ctx context.Context) error {
+	if fte.store != nil {
+		fte.store.Close()
+	}
+
+	return nil
+}
+
 func (fte *flowToolsExecutor) GetCustomExecutor(cfg CustomExecutorConfig) (ContextToolsExecutor, error) {
 	if len(cfg.Definitions) != len(cfg.Handlers) {
 		return nil, fmt.Errorf("definitions and handlers must have the same length")
@@ -479,6 +593,11 @@ func (fte *flowToolsExecutor) GetCustomExecutor(cfg CustomExecutorConfig) (Conte
 
 	return &customExecutor{
 		flowID:      fte.flowID,
+		limiter:     fte.limiter,
+		scope:       fte.scopeGuard,
+		timeouts:    fte.timeouts,
+		resultSize:  fte.resultSize,
+		redactor:    fte.redactor,
 		taskID:      cfg.TaskID,
 		subtaskID:   cfg.SubtaskID,
 		mlp:         fte.mlp,
@@ -528,6 +647,8 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	definitions := []llms.FunctionDefinition{
@@ -617,7 +738,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		)
 		if google.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[GoogleToolName])
-			handlers[GoogleToolName] = google.Handle
+			handlers[GoogleToolName] = fte.searchCache.Wrap(GoogleToolName, google.Handle)
 		}
 
 		duckduckgo := NewDuckDuckGoTool(
@@ -627,7 +748,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		)
 		if duckduckgo.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[DuckDuckGoToolName])
-			handlers[DuckDuckGoToolName] = duckduckgo.Handle
+			handlers[DuckDuckGoToolName] = fte.searchCache.Wrap(DuckDuckGoToolName, duckduckgo.Handle)
 		}
 
 		tavily := NewTavilyTool(
@@ -638,7 +759,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		)
 		if tavily.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[TavilyToolName])
-			handlers[TavilyToolName] = tavily.Handle
+			handlers[TavilyToolName] = fte.searchCache.Wrap(TavilyToolName, tavily.Handle)
 		}
 
 		traversaal := NewTraversaalTool(
@@ -648,7 +769,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		)
 		if traversaal.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[TraversaalToolName])
-			handlers[TraversaalToolName] = traversaal.Handle
+			handlers[TraversaalToolName] = fte.searchCache.Wrap(TraversaalToolName, traversaal.Handle)
 		}
 
 		perplexity := NewPerplexityTool(
@@ -659,7 +780,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		)
 		if perplexity.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[PerplexityToolName])
-			handlers[PerplexityToolName] = perplexity.Handle
+			handlers[PerplexityToolName] = fte.searchCache.Wrap(PerplexityToolName, perplexity.Handle)
 		}
 
 		searxng := NewSearxngTool(
@@ -670,22 +791,191 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		)
 		if searxng.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[SearxngToolName])
-			handlers[SearxngToolName] = searxng.Handle
+			handlers[SearxngToolName] = fte.searchCache.Wrap(SearxngToolName, searxng.Handle)
 		}
 
 		sploitus := NewSploitusTool(
 			fte.cfg,
 			fte.flowID, nil, nil,
 			fte.slp,
+			fte.cache,
 		)
 		if sploitus.IsAvailable() {
 			definitions = append(definitions, registryDefinitions[SploitusToolName])
-			handlers[SploitusToolName] = sploitus.Handle
+			handlers[SploitusToolName] = fte.searchCache.Wrap(SploitusToolName, sploitus.Handle)
 		}
+
+		github := NewGithubTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if github.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[GithubToolName])
+			handlers[GithubToolName] = fte.searchCache.Wrap(GithubToolName, github.Handle)
+		}
+
+		exploitDB := NewExploitDBTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+		)
+		if exploitDB.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[ExploitDBToolName])
+			handlers[ExploitDBToolName] = exploitDB.Handle
+		}
+
+		exploitSearch := NewExploitSearchTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if exploitSearch.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[ExploitSearchToolName])
+			handlers[ExploitSearchToolName] = fte.searchCache.Wrap(ExploitSearchToolName, exploitSearch.Handle)
+		}
+
+		virusTotal := NewVirusTotalTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if virusTotal.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[VirusTotalToolName])
+			handlers[VirusTotalToolName] = virusTotal.Handle
+		}
+
+		wayback := NewWaybackTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if wayback.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[WaybackToolName])
+			handlers[WaybackToolName] = fte.searchCache.Wrap(WaybackToolName, wayback.Handle)
+		}
+
+		whois := NewWhoisTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if whois.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[WhoisToolName])
+			handlers[WhoisToolName] = whois.Handle
+		}
+
+		epss := NewEPSSTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if epss.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[EPSSToolName])
+			handlers[EPSSToolName] = epss.Handle
+		}
+
+		robots := NewRobotsTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if robots.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[RobotsToolName])
+			handlers[RobotsToolName] = robots.Handle
+		}
+
+		httpHeaders := NewHTTPHeadersTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+		)
+		if httpHeaders.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[HTTPHeadersToolName])
+			handlers[HTTPHeadersToolName] = httpHeaders.Handle
+		}
+
+		tlsInspect := NewTLSInspectTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+		)
+		if tlsInspect.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[TLSInspectToolName])
+			handlers[TLSInspectToolName] = tlsInspect.Handle
+		}
+
+		portScan := NewPortScanTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if portScan.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[PortScanToolName])
+			handlers[PortScanToolName] = portScan.Handle
+		}
+
+		httpFuzz := NewHTTPFuzzTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.slp,
+			fte.cache,
+		)
+		if httpFuzz.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[HTTPFuzzToolName])
+			handlers[HTTPFuzzToolName] = httpFuzz.Handle
+		}
+
+		headlessBrowser := NewHeadlessBrowserTool(
+			fte.cfg,
+			fte.flowID, nil, nil,
+			fte.db,
+		)
+		if headlessBrowser.IsAvailable() {
+			definitions = append(definitions, registryDefinitions[HeadlessBrowserToolName])
+			handlers[HeadlessBrowserToolName] = headlessBrowser.Handle
+		}
+	}
+
+	attachments := NewAttachmentsTool(fte.flowID, nil, nil, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		definitions = append(definitions, registryDefinitions[AttachmentsToolName])
+		handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, nil, nil, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		definitions = append(definitions, registryDefinitions[FlowMemoryToolName])
+		handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, nil, nil, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		definitions = append(definitions, registryDefinitions[ArtifactsToolName])
+		handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, nil, nil, fte.db)
+	if ocr.IsAvailable() {
+		definitions = append(definitions, registryDefinitions[OCRToolName])
+		handlers[OCRToolName] = ocr.Handle
 	}
 
 	ce := &customExecutor{
 		flowID:      fte.flowID,
+		limiter:     fte.limiter,
+		scope:       fte.scopeGuard,
+		timeouts:    fte.timeouts,
+		resultSize:  fte.resultSize,
+		redactor:    fte.redactor,
 		mlp:         fte.mlp,
 		vslp:        fte.vslp,
 		db:          fte.db,
@@ -729,13 +1019,18 @@ func (fte *flowToolsExecutor) GetPrimaryExecutor(cfg PrimaryExecutorConfig) (Con
 	}
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    &cfg.TaskID,
-		subtaskID: &cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     &cfg.TaskID,
+		subtaskID:  &cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[FinalyToolName],
 			registryDefinitions[AdviceToolName],
@@ -766,6 +1061,30 @@ func (fte *flowToolsExecutor) GetPrimaryExecutor(cfg PrimaryExecutorConfig) (Con
 		ce.barriers[AskUserToolName] = struct{}{}
 	}
 
+	attachments := NewAttachmentsTool(fte.flowID, &cfg.TaskID, &cfg.SubtaskID, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[AttachmentsToolName])
+		ce.handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, &cfg.TaskID, &cfg.SubtaskID, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[FlowMemoryToolName])
+		ce.handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, &cfg.TaskID, &cfg.SubtaskID, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ArtifactsToolName])
+		ce.handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, &cfg.TaskID, &cfg.SubtaskID, fte.db)
+	if ocr.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[OCRToolName])
+		ce.handlers[OCRToolName] = ocr.Handle
+	}
+
 	return ce, nil
 }
 
@@ -799,16 +1118,23 @@ func (fte *flowToolsExecutor) GetInstallerExecutor(cfg InstallerExecutorConfig)
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    cfg.TaskID,
-		subtaskID: cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     cfg.TaskID,
+		subtaskID:  cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[MaintenanceResultToolName],
 			registryDefinitions[AdviceToolName],
@@ -860,6 +1186,30 @@ func (fte *flowToolsExecutor) GetInstallerExecutor(cfg InstallerExecutorConfig)
 		ce.handlers[SearchGuideToolName] = guide.Handle
 	}
 
+	attachments := NewAttachmentsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[AttachmentsToolName])
+		ce.handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[FlowMemoryToolName])
+		ce.handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ArtifactsToolName])
+		ce.handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.db)
+	if ocr.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[OCRToolName])
+		ce.handlers[OCRToolName] = ocr.Handle
+	}
+
 	return ce, nil
 }
 
@@ -885,13 +1235,18 @@ func (fte *flowToolsExecutor) GetCoderExecutor(cfg CoderExecutorConfig) (Context
 	}
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    cfg.TaskID,
-		subtaskID: cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     cfg.TaskID,
+		subtaskID:  cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[CodeResultToolName],
 			registryDefinitions[AdviceToolName],
@@ -952,6 +1307,30 @@ func (fte *flowToolsExecutor) GetCoderExecutor(cfg CoderExecutorConfig) (Context
 		ce.handlers[GraphitiSearchToolName] = graphitiSearch.Handle
 	}
 
+	attachments := NewAttachmentsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[AttachmentsToolName])
+		ce.handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[FlowMemoryToolName])
+		ce.handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ArtifactsToolName])
+		ce.handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.db)
+	if ocr.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[OCRToolName])
+		ce.handlers[OCRToolName] = ocr.Handle
+	}
+
 	return ce, nil
 }
 
@@ -993,16 +1372,23 @@ func (fte *flowToolsExecutor) GetPentesterExecutor(cfg PentesterExecutorConfig)
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    cfg.TaskID,
-		subtaskID: cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     cfg.TaskID,
+		subtaskID:  cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[HackResultToolName],
 			registryDefinitions[AdviceToolName],
@@ -1075,10 +1461,200 @@ func (fte *flowToolsExecutor) GetPentesterExecutor(cfg PentesterExecutorConfig)
 		cfg.TaskID,
 		cfg.SubtaskID,
 		fte.slp,
+		fte.cache,
 	)
 	if sploitus.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[SploitusToolName])
-		ce.handlers[SploitusToolName] = sploitus.Handle
+		ce.handlers[SploitusToolName] = fte.searchCache.Wrap(SploitusToolName, sploitus.Handle)
+	}
+
+	github := NewGithubTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if github.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[GithubToolName])
+		ce.handlers[GithubToolName] = fte.searchCache.Wrap(GithubToolName, github.Handle)
+	}
+
+	exploitDB := NewExploitDBTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+	)
+	if exploitDB.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ExploitDBToolName])
+		ce.handlers[ExploitDBToolName] = exploitDB.Handle
+	}
+
+	exploitSearch := NewExploitSearchTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if exploitSearch.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ExploitSearchToolName])
+		ce.handlers[ExploitSearchToolName] = fte.searchCache.Wrap(ExploitSearchToolName, exploitSearch.Handle)
+	}
+
+	virusTotal := NewVirusTotalTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if virusTotal.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[VirusTotalToolName])
+		ce.handlers[VirusTotalToolName] = virusTotal.Handle
+	}
+
+	wayback := NewWaybackTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if wayback.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[WaybackToolName])
+		ce.handlers[WaybackToolName] = fte.searchCache.Wrap(WaybackToolName, wayback.Handle)
+	}
+
+	whois := NewWhoisTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if whois.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[WhoisToolName])
+		ce.handlers[WhoisToolName] = whois.Handle
+	}
+
+	epss := NewEPSSTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if epss.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[EPSSToolName])
+		ce.handlers[EPSSToolName] = epss.Handle
+	}
+
+	robots := NewRobotsTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if robots.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[RobotsToolName])
+		ce.handlers[RobotsToolName] = robots.Handle
+	}
+
+	httpHeaders := NewHTTPHeadersTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+	)
+	if httpHeaders.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[HTTPHeadersToolName])
+		ce.handlers[HTTPHeadersToolName] = httpHeaders.Handle
+	}
+
+	tlsInspect := NewTLSInspectTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+	)
+	if tlsInspect.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[TLSInspectToolName])
+		ce.handlers[TLSInspectToolName] = tlsInspect.Handle
+	}
+
+	portScan := NewPortScanTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if portScan.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[PortScanToolName])
+		ce.handlers[PortScanToolName] = portScan.Handle
+	}
+
+	httpFuzz := NewHTTPFuzzTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if httpFuzz.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[HTTPFuzzToolName])
+		ce.handlers[HTTPFuzzToolName] = httpFuzz.Handle
+	}
+
+	headlessBrowser := NewHeadlessBrowserTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.db,
+	)
+	if headlessBrowser.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[HeadlessBrowserToolName])
+		ce.handlers[HeadlessBrowserToolName] = headlessBrowser.Handle
+	}
+
+	attachments := NewAttachmentsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[AttachmentsToolName])
+		ce.handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[FlowMemoryToolName])
+		ce.handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ArtifactsToolName])
+		ce.handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.db)
+	if ocr.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[OCRToolName])
+		ce.handlers[OCRToolName] = ocr.Handle
 	}
 
 	return ce, nil
@@ -1094,13 +1670,18 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	}
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    cfg.TaskID,
-		subtaskID: cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     cfg.TaskID,
+		subtaskID:  cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[SearchResultToolName],
 			registryDefinitions[MemoristToolName],
@@ -1129,6 +1710,18 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 		ce.handlers[BrowserToolName] = browser.Handle
 	}
 
+	headlessBrowser := NewHeadlessBrowserTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.db,
+	)
+	if headlessBrowser.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[HeadlessBrowserToolName])
+		ce.handlers[HeadlessBrowserToolName] = headlessBrowser.Handle
+	}
+
 	google := NewGoogleTool(
 		fte.cfg,
 		fte.flowID,
@@ -1138,7 +1731,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	)
 	if google.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[GoogleToolName])
-		ce.handlers[GoogleToolName] = google.Handle
+		ce.handlers[GoogleToolName] = fte.searchCache.Wrap(GoogleToolName, google.Handle)
 	}
 
 	duckduckgo := NewDuckDuckGoTool(
@@ -1150,7 +1743,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	)
 	if duckduckgo.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[DuckDuckGoToolName])
-		ce.handlers[DuckDuckGoToolName] = duckduckgo.Handle
+		ce.handlers[DuckDuckGoToolName] = fte.searchCache.Wrap(DuckDuckGoToolName, duckduckgo.Handle)
 	}
 
 	tavily := NewTavilyTool(
@@ -1163,7 +1756,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	)
 	if tavily.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[TavilyToolName])
-		ce.handlers[TavilyToolName] = tavily.Handle
+		ce.handlers[TavilyToolName] = fte.searchCache.Wrap(TavilyToolName, tavily.Handle)
 	}
 
 	traversaal := NewTraversaalTool(
@@ -1175,7 +1768,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	)
 	if traversaal.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[TraversaalToolName])
-		ce.handlers[TraversaalToolName] = traversaal.Handle
+		ce.handlers[TraversaalToolName] = fte.searchCache.Wrap(TraversaalToolName, traversaal.Handle)
 	}
 
 	perplexity := NewPerplexityTool(
@@ -1188,7 +1781,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	)
 	if perplexity.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[PerplexityToolName])
-		ce.handlers[PerplexityToolName] = perplexity.Handle
+		ce.handlers[PerplexityToolName] = fte.searchCache.Wrap(PerplexityToolName, perplexity.Handle)
 	}
 
 	searxng := NewSearxngTool(
@@ -1201,7 +1794,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 	)
 	if searxng.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[SearxngToolName])
-		ce.handlers[SearxngToolName] = searxng.Handle
+		ce.handlers[SearxngToolName] = fte.searchCache.Wrap(SearxngToolName, searxng.Handle)
 	}
 
 	sploitus := NewSploitusTool(
@@ -1210,10 +1803,138 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 		cfg.TaskID,
 		cfg.SubtaskID,
 		fte.slp,
+		fte.cache,
 	)
 	if sploitus.IsAvailable() {
 		ce.definitions = append(ce.definitions, registryDefinitions[SploitusToolName])
-		ce.handlers[SploitusToolName] = sploitus.Handle
+		ce.handlers[SploitusToolName] = fte.searchCache.Wrap(SploitusToolName, sploitus.Handle)
+	}
+
+	github := NewGithubTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if github.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[GithubToolName])
+		ce.handlers[GithubToolName] = fte.searchCache.Wrap(GithubToolName, github.Handle)
+	}
+
+	exploitDB := NewExploitDBTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+	)
+	if exploitDB.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ExploitDBToolName])
+		ce.handlers[ExploitDBToolName] = exploitDB.Handle
+	}
+
+	exploitSearch := NewExploitSearchTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if exploitSearch.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ExploitSearchToolName])
+		ce.handlers[ExploitSearchToolName] = fte.searchCache.Wrap(ExploitSearchToolName, exploitSearch.Handle)
+	}
+
+	virusTotal := NewVirusTotalTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if virusTotal.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[VirusTotalToolName])
+		ce.handlers[VirusTotalToolName] = virusTotal.Handle
+	}
+
+	wayback := NewWaybackTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if wayback.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[WaybackToolName])
+		ce.handlers[WaybackToolName] = fte.searchCache.Wrap(WaybackToolName, wayback.Handle)
+	}
+
+	whois := NewWhoisTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if whois.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[WhoisToolName])
+		ce.handlers[WhoisToolName] = whois.Handle
+	}
+
+	epss := NewEPSSTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if epss.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[EPSSToolName])
+		ce.handlers[EPSSToolName] = epss.Handle
+	}
+
+	robots := NewRobotsTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+		fte.cache,
+	)
+	if robots.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[RobotsToolName])
+		ce.handlers[RobotsToolName] = robots.Handle
+	}
+
+	httpHeaders := NewHTTPHeadersTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+	)
+	if httpHeaders.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[HTTPHeadersToolName])
+		ce.handlers[HTTPHeadersToolName] = httpHeaders.Handle
+	}
+
+	tlsInspect := NewTLSInspectTool(
+		fte.cfg,
+		fte.flowID,
+		cfg.TaskID,
+		cfg.SubtaskID,
+		fte.slp,
+	)
+	if tlsInspect.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[TLSInspectToolName])
+		ce.handlers[TLSInspectToolName] = tlsInspect.Handle
 	}
 
 	search := NewSearchTool(
@@ -1256,15 +1977,22 @@ func (fte *flowToolsExecutor) GetGeneratorExecutor(cfg GeneratorExecutorConfig)
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	ce := &customExecutor{
-		flowID: fte.flowID,
-		taskID: &cfg.TaskID,
-		mlp:    fte.mlp,
-		vslp:   fte.vslp,
-		db:     fte.db,
-		store:  fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     &cfg.TaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[MemoristToolName],
 			registryDefinitions[SearchToolName],
@@ -1296,6 +2024,30 @@ func (fte *flowToolsExecutor) GetGeneratorExecutor(cfg GeneratorExecutorConfig)
 		ce.handlers[BrowserToolName] = browser.Handle
 	}
 
+	attachments := NewAttachmentsTool(fte.flowID, &cfg.TaskID, nil, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[AttachmentsToolName])
+		ce.handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, &cfg.TaskID, nil, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[FlowMemoryToolName])
+		ce.handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, &cfg.TaskID, nil, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ArtifactsToolName])
+		ce.handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, &cfg.TaskID, nil, fte.db)
+	if ocr.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[OCRToolName])
+		ce.handlers[OCRToolName] = ocr.Handle
+	}
+
 	return ce, nil
 }
 
@@ -1321,15 +2073,22 @@ func (fte *flowToolsExecutor) GetRefinerExecutor(cfg RefinerExecutorConfig) (Con
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	ce := &customExecutor{
-		flowID: fte.flowID,
-		taskID: &cfg.TaskID,
-		mlp:    fte.mlp,
-		vslp:   fte.vslp,
-		db:     fte.db,
-		store:  fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     &cfg.TaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[MemoristToolName],
 			registryDefinitions[SearchToolName],
@@ -1361,6 +2120,30 @@ func (fte *flowToolsExecutor) GetRefinerExecutor(cfg RefinerExecutorConfig) (Con
 		ce.handlers[BrowserToolName] = browser.Handle
 	}
 
+	attachments := NewAttachmentsTool(fte.flowID, &cfg.TaskID, nil, fte.cfg, fte.db)
+	if attachments.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[AttachmentsToolName])
+		ce.handlers[AttachmentsToolName] = attachments.Handle
+	}
+
+	flowMemory := NewFlowMemoryTool(fte.flowID, &cfg.TaskID, nil, fte.db, fte.memoryEmbedder)
+	if flowMemory.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[FlowMemoryToolName])
+		ce.handlers[FlowMemoryToolName] = flowMemory.Handle
+	}
+
+	artifacts := NewArtifactsTool(fte.flowID, &cfg.TaskID, nil, fte.cfg, fte.db, fte.docker)
+	if artifacts.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ArtifactsToolName])
+		ce.handlers[ArtifactsToolName] = artifacts.Handle
+	}
+
+	ocr := NewOCRTool(fte.cfg, fte.flowID, &cfg.TaskID, nil, fte.db)
+	if ocr.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[OCRToolName])
+		ce.handlers[OCRToolName] = ocr.Handle
+	}
+
 	return ce, nil
 }
 
@@ -1382,16 +2165,23 @@ func (fte *flowToolsExecutor) GetMemoristExecutor(cfg MemoristExecutorConfig) (C
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    cfg.TaskID,
-		subtaskID: cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     cfg.TaskID,
+		subtaskID:  cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[MemoristResultToolName],
 			registryDefinitions[TerminalToolName],
@@ -1450,16 +2240,23 @@ func (fte *flowToolsExecutor) GetEnricherExecutor(cfg EnricherExecutorConfig) (C
 		container.LocalID.String,
 		fte.docker,
 		fte.tlp,
+		fte.env,
+		fte.commandGuard,
 	)
 
 	ce := &customExecutor{
-		flowID:    fte.flowID,
-		taskID:    cfg.TaskID,
-		subtaskID: cfg.SubtaskID,
-		mlp:       fte.mlp,
-		vslp:      fte.vslp,
-		db:        fte.db,
-		store:     fte.store,
+		flowID:     fte.flowID,
+		limiter:    fte.limiter,
+		scope:      fte.scopeGuard,
+		timeouts:   fte.timeouts,
+		resultSize: fte.resultSize,
+		redactor:   fte.redactor,
+		taskID:     cfg.TaskID,
+		subtaskID:  cfg.SubtaskID,
+		mlp:        fte.mlp,
+		vslp:       fte.vslp,
+		db:         fte.db,
+		store:      fte.store,
 		definitions: []llms.FunctionDefinition{
 			registryDefinitions[EnricherResultToolName],
 			registryDefinitions[TerminalToolName],
@@ -1521,6 +2318,11 @@ func (fte *flowToolsExecutor) GetReporterExecutor(cfg ReporterExecutorConfig) (C
 
 	return &customExecutor{
 		flowID:      fte.flowID,
+		limiter:     fte.limiter,
+		scope:       fte.scopeGuard,
+		timeouts:    fte.timeouts,
+		resultSize:  fte.resultSize,
+		redactor:    fte.redactor,
 		taskID:      cfg.TaskID,
 		subtaskID:   cfg.SubtaskID,
 		mlp:         fte.mlp,
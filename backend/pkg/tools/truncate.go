@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// sourceTruncatedNotice is appended to the source field of the item which is cut by the source limit
+	sourceTruncatedNotice = "\n... [source truncated, exceeded %s limit]"
+	// contentTruncatedNotice is appended to the fetched content which is cut by the rest of the result limit
+	contentTruncatedNotice = "\n\n... [content truncated, exceeded %s limit]\n"
+	// textTruncatedNotice is appended to the rendered or recognized text which is cut by the text limit
+	textTruncatedNotice = "\n\n... [text truncated, exceeded %s limit]\n"
+	// itemsTruncatedNotice closes the list of the items which is cut by ResultBudget
+	itemsTruncatedNotice = "\n**Note:** Showing %d of %d %s (output truncated, exceeded %s limit)\n"
+)
+
+// TruncateWithNotice cuts the content to limit bytes without breaking UTF-8 runes and appends the notice,
+// noticeFmt gets the limit as the only argument, e.g. "80 KB"; the content within the limit is returned as is
+func TruncateWithNotice(content string, limit int, noticeFmt string) string {
+	if limit < 0 || len(content) <= limit {
+		return content
+	}
+
+	return CutRunes(content, limit) + fmt.Sprintf(noticeFmt, formatSizeLimit(limit))
+}
+
+// CutRunes cuts the content to at most limit bytes without breaking UTF-8 runes
+func CutRunes(content string, limit int) string {
+	if len(content) <= limit {
+		return content
+	}
+
+	cut := max(limit, 0)
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+
+	return content[:cut]
+}
+
+// ResultBudget accumulates the items of the tool result until the total size cap is reached,
+// the room for the truncation notice is reserved so the notice never exceeds the cap
+type ResultBudget struct {
+	size      int
+	limit     int
+	used      int
+	added     int
+	truncated bool
+}
+
+// NewResultBudget creates the budget of limit bytes, used is the size of the content written before the items
+func NewResultBudget(limit, used int) *ResultBudget {
+	return &ResultBudget{size: limit, limit: limit - truncationMsgBuffer, used: used}
+}
+
+// Add writes the item to sb if it fits the budget, once an item doesn't fit the budget is closed
+// and all next items are rejected to keep the order of the results
+func (b *ResultBudget) Add(sb *strings.Builder, item string) bool {
+	if b.truncated || b.used >= b.limit || b.used+len(item) > b.limit {
+		b.truncated = true
+		return false
+	}
+
+	sb.WriteString(item)
+	b.used += len(item)
+	b.added++
+
+	return true
+}
+
+// AddTruncated writes the content to sb cutting it to the rest of the budget, the cut content ends with
+// the notice which gets the whole limit as the only argument, the budget is closed after the cut
+func (b *ResultBudget) AddTruncated(sb *strings.Builder, content, noticeFmt string) bool {
+	if !b.truncated && b.used+len(content) <= b.limit {
+		return b.Add(sb, content)
+	}
+
+	cut := CutRunes(content, b.limit-b.used)
+	if b.truncated {
+		cut = ""
+	}
+	sb.WriteString(cut)
+	sb.WriteString(fmt.Sprintf(noticeFmt, formatSizeLimit(b.size)))
+	b.used += len(cut)
+	b.truncated = true
+
+	return false
+}
+
+// Added returns the count of the items which fit the budget
+func (b *ResultBudget) Added() int {
+	return b.added
+}
+
+// Truncated returns true if at least one item was rejected by the budget
+func (b *ResultBudget) Truncated() bool {
+	return b.truncated
+}
+
+// Notice returns the truncation note of the list, shown and total are the counts of the items named by kind,
+// e.g. "results", which can differ from Added if the budget also takes the section headers
+func (b *ResultBudget) Notice(shown, total int, kind string) string {
+	return fmt.Sprintf(itemsTruncatedNotice, shown, total, kind, formatSizeLimit(b.size))
+}
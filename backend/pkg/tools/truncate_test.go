@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateWithNotice(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    string
+	}{
+		{"under limit", "abc", 4, "abc"},
+		{"exactly at limit", "abcd", 4, "abcd"},
+		{"one byte over limit", "abcde", 4, "abcd\n... [source truncated, exceeded 4 bytes limit]"},
+		{"empty content", "", 0, ""},
+		{"rune on the boundary", "abcпривет", 4, "abc\n... [source truncated, exceeded 4 bytes limit]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateWithNotice(tt.content, tt.limit, sourceTruncatedNotice); got != tt.want {
+				t.Errorf("TruncateWithNotice() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got := TruncateWithNotice(strings.Repeat("ж", 1024), 1024, sourceTruncatedNotice)
+	if !strings.HasSuffix(got, "[source truncated, exceeded 1 KB limit]") || !utf8.ValidString(got) {
+		t.Errorf("TruncateWithNotice() = %q, want valid UTF-8 with KB notice", got)
+	}
+}
+
+func TestResultBudget(t *testing.T) {
+	const header = "# Header\n"
+	limit := len(header) + truncationMsgBuffer + 10
+
+	t.Run("exactly at limit", func(t *testing.T) {
+		var sb strings.Builder
+		sb.WriteString(header)
+		budget := NewResultBudget(limit, sb.Len())
+
+		for _, item := range []string{"12345", "67890"} {
+			if !budget.Add(&sb, item) {
+				t.Fatalf("Add(%q) = false, want the item which fills the budget exactly", item)
+			}
+		}
+		if budget.Truncated() || budget.Added() != 2 {
+			t.Errorf("Added() = %d, Truncated() = %v", budget.Added(), budget.Truncated())
+		}
+
+		// the full budget rejects even the empty item
+		if budget.Add(&sb, "") || !budget.Truncated() {
+			t.Error("Add() to the full budget = true")
+		}
+		if sb.String() != header+"1234567890" {
+			t.Errorf("content = %q", sb.String())
+		}
+	})
+
+	t.Run("one byte over limit", func(t *testing.T) {
+		var sb strings.Builder
+		budget := NewResultBudget(limit, len(header))
+
+		if !budget.Add(&sb, "1234") || budget.Add(&sb, "5678901") {
+			t.Fatal("unexpected Add() results around the limit")
+		}
+		// the closed budget keeps the order of the results and doesn't take the smaller items
+		if budget.Add(&sb, "5") {
+			t.Error("Add() after the rejected item = true")
+		}
+		if !budget.Truncated() || budget.Added() != 1 || sb.String() != "1234" {
+			t.Errorf("Added() = %d, Truncated() = %v, content = %q", budget.Added(), budget.Truncated(), sb.String())
+		}
+	})
+
+	t.Run("no room for items", func(t *testing.T) {
+		var sb strings.Builder
+		budget := NewResultBudget(truncationMsgBuffer, 0)
+		if budget.Add(&sb, "x") || !budget.Truncated() || sb.Len() != 0 {
+			t.Error("Add() to the budget without room = true")
+		}
+	})
+
+	t.Run("notice", func(t *testing.T) {
+		budget := NewResultBudget(2048, 0)
+		want := "\n**Note:** Showing 3 of 7 results (output truncated, exceeded 2 KB limit)\n"
+		if got := budget.Notice(3, 7, "results"); got != want {
+			t.Errorf("Notice() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestResultBudgetAddTruncated(t *testing.T) {
+	limit := truncationMsgBuffer + 5
+	notice := "\n[cut, %s limit]"
+
+	t.Run("content fits", func(t *testing.T) {
+		var sb strings.Builder
+		budget := NewResultBudget(limit, 0)
+		if !budget.AddTruncated(&sb, "abc", notice) || budget.Truncated() || sb.String() != "abc" {
+			t.Errorf("AddTruncated() content = %q, Truncated() = %v", sb.String(), budget.Truncated())
+		}
+	})
+
+	t.Run("content is cut by the rest of the budget", func(t *testing.T) {
+		var sb strings.Builder
+		budget := NewResultBudget(limit, 0)
+		budget.Add(&sb, "ab")
+		if budget.AddTruncated(&sb, "впереди", notice) || !budget.Truncated() {
+			t.Fatal("AddTruncated() over the budget = true")
+		}
+		// the rune on the boundary isn't broken, the notice names the whole limit
+		want := fmt.Sprintf("abв\n[cut, %d bytes limit]", limit)
+		if got := sb.String(); got != want || !utf8.ValidString(got) {
+			t.Errorf("content = %q, want %q", got, want)
+		}
+		if budget.Add(&sb, "x") {
+			t.Error("Add() after the cut = true")
+		}
+	})
+}
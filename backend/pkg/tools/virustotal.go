@@ -0,0 +1,586 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Retry delays of the rate limited requests, the public API allows 4 requests per minute,
+// variables to make tests fast
+var (
+	virustotalRetryBaseDelay = 15 * time.Second
+	virustotalRetryMaxDelay  = time.Minute
+)
+
+const (
+	virustotalAPIURL         = "https://www.virustotal.com/api/v3"
+	virustotalGUIURL         = "https://www.virustotal.com/gui"
+	virustotalRequestTimeout = 30 * time.Second
+	virustotalMaxAttempts    = 3
+	virustotalCacheNamespace = "virustotal"
+	virustotalCacheTTL       = time.Hour
+
+	virustotalTypeFile = "file"
+	virustotalTypeURL  = "url"
+
+	// maxVirustotalVerdicts limits the number of engines listed with their own verdict,
+	// the rest is reported by the categories counters only
+	maxVirustotalVerdicts = 50
+)
+
+var virustotalHashRegex = regexp.MustCompile(`^(?:[a-fA-F0-9]{32}|[a-fA-F0-9]{40}|[a-fA-F0-9]{64})$`)
+
+// virustotalDetectedCategories are the engine categories which are counted as detections
+var virustotalDetectedCategories = []string{"malicious", "suspicious"}
+
+type virustotalResponse struct {
+	Data struct {
+		ID         string               `json:"id"`
+		Type       string               `json:"type"`
+		Attributes virustotalAttributes `json:"attributes"`
+	} `json:"data"`
+}
+
+type virustotalAttributes struct {
+	// file attributes
+	MeaningfulName  string   `json:"meaningful_name"`
+	TypeDescription string   `json:"type_description"`
+	Size            int64    `json:"size"`
+	SHA256          string   `json:"sha256"`
+	MD5             string   `json:"md5"`
+	Names           []string `json:"names"`
+	// url attributes
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	FinalURL string `json:"last_final_url"`
+	// common attributes
+	FirstSubmissionDate int64                             `json:"first_submission_date"`
+	LastAnalysisDate    int64                             `json:"last_analysis_date"`
+	LastSubmissionDate  int64                             `json:"last_submission_date"`
+	TimesSubmitted      int                               `json:"times_submitted"`
+	Reputation          int                               `json:"reputation"`
+	Tags                []string                          `json:"tags"`
+	LastAnalysisStats   map[string]int                    `json:"last_analysis_stats"`
+	LastAnalysisResults map[string]virustotalEngineResult `json:"last_analysis_results"`
+	PopularThreat       *virustotalPopularThreatLabel     `json:"popular_threat_classification,omitempty"`
+}
+
+type virustotalEngineResult struct {
+	Category   string `json:"category"`
+	EngineName string `json:"engine_name"`
+	Result     string `json:"result"`
+}
+
+type virustotalPopularThreatLabel struct {
+	SuggestedThreatLabel string `json:"suggested_threat_label"`
+}
+
+type virustotalErrorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// VirusTotalRequestError is returned when the VirusTotal API responds with an unsuccessful status,
+// rate limited requests are retried and the error is returned after the last attempt
+type VirusTotalRequestError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Attempts   int
+	Err        error
+}
+
+func (e *VirusTotalRequestError) Error() string {
+	var reason string
+	if e.RateLimited() {
+		reason = fmt.Sprintf("VirusTotal API quota exceeded (HTTP %d)", e.StatusCode)
+	} else {
+		reason = fmt.Sprintf("VirusTotal API returned HTTP %d", e.StatusCode)
+	}
+
+	if e.Code != "" {
+		reason += fmt.Sprintf(" %s", e.Code)
+	}
+	if e.Message != "" {
+		reason += fmt.Sprintf(": %s", e.Message)
+	}
+	if e.Attempts > 1 {
+		reason += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+	if e.Err != nil {
+		reason += fmt.Sprintf(": %v", e.Err)
+	}
+	if e.RateLimited() || e.StatusCode >= 500 {
+		reason += ", please try again later"
+	}
+
+	return reason
+}
+
+func (e *VirusTotalRequestError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimited returns true if the request was rejected by the per-minute or daily quota of the API key
+func (e *VirusTotalRequestError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Code == "QuotaExceededError"
+}
+
+// Temporary returns true if the request may succeed when it's repeated later
+func (e *VirusTotalRequestError) Temporary() bool {
+	return e.RateLimited() || e.StatusCode >= 500
+}
+
+// errVirustotalNotFound is returned when VirusTotal has no report for the resource
+var errVirustotalNotFound = errors.New("resource is not found in VirusTotal")
+
+// virustotal represents the VirusTotal file hash and URL reputation tool
+type virustotal struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewVirusTotalTool creates a new VirusTotal reputation tool instance
+func NewVirusTotalTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &virustotal{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes a VirusTotal reputation request from an AI agent
+func (v *virustotal) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !v.IsAvailable() {
+		return "", fmt.Errorf("virustotal is not available")
+	}
+
+	var action VirusTotalAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(v.flowID, v.taskID, v.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal virustotal action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	resource := strings.TrimSpace(action.Resource)
+	resourceType, resourceID, err := parseVirustotalResource(resource)
+	if err != nil {
+		logger.WithError(err).Warn("invalid virustotal resource")
+		return fmt.Sprintf("failed to check in VirusTotal: %v", err), nil
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"resource":      resource[:min(len(resource), 1000)],
+		"resource_type": resourceType,
+	})
+
+	result, err := v.check(ctx, resource, resourceType, resourceID)
+	switch {
+	case errors.Is(err, errVirustotalNotFound):
+		result = formatVirustotalNotFound(resource, resourceType)
+	case err != nil:
+		observation.Event(
+			langfuse.WithEventName("virustotal check error swallowed"),
+			langfuse.WithEventInput(resource),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":     VirusTotalToolName,
+				"engine":        "virustotal",
+				"resource":      resource,
+				"resource_type": resourceType,
+				"error":         err.Error(),
+			}),
+		)
+
+		var reqErr *VirusTotalRequestError
+		if errors.As(err, &reqErr) && reqErr.RateLimited() {
+			logger.WithError(err).Warn("virustotal quota is exceeded")
+			return fmt.Sprintf("failed to check in VirusTotal: %v, use other tools until then", err), nil
+		}
+
+		logger.WithError(err).Error("failed to check in VirusTotal")
+		return fmt.Sprintf("failed to check in VirusTotal: %v", err), nil
+	}
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && v.slp != nil {
+		_, _ = v.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeVirustotal,
+			resource,
+			result,
+			v.taskID,
+			v.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// parseVirustotalResource detects whether the resource is a file hash (MD5, SHA-1 or SHA-256)
+// or an URL and returns the identifier of the VirusTotal object
+func parseVirustotalResource(resource string) (string, string, error) {
+	if resource == "" {
+		return "", "", errors.New("resource is empty, pass a file hash or an URL")
+	}
+
+	if virustotalHashRegex.MatchString(resource) {
+		return virustotalTypeFile, strings.ToLower(resource), nil
+	}
+
+	if u, err := url.Parse(resource); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		// VirusTotal identifies URLs by the unpadded base64url encoding of the URL
+		return virustotalTypeURL, base64.RawURLEncoding.EncodeToString([]byte(resource)), nil
+	}
+
+	return "", "", fmt.Errorf("resource '%s' is neither MD5, SHA-1 or SHA-256 hash nor http(s) URL",
+		resource[:min(len(resource), 200)])
+}
+
+// check returns the formatted report of the resource, successful responses are kept in the shared cache
+// because the public API quota is small and reports don't change often
+func (v *virustotal) check(ctx context.Context, resource, resourceType, resourceID string) (string, error) {
+	var apiResp virustotalResponse
+
+	cacheKey := normalizeCacheKey(resourceType, resourceID)
+	if !getCachedJSON(ctx, v.cache, virustotalCacheNamespace, cacheKey, &apiResp) {
+		var err error
+		if apiResp, err = v.fetch(ctx, resource, resourceType, resourceID); err != nil {
+			return "", err
+		}
+
+		setCachedJSON(ctx, v.cache, virustotalCacheNamespace, cacheKey, apiResp, virustotalCacheTTL)
+	}
+
+	return formatVirustotalReport(resource, resourceType, apiResp, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
+}
+
+// fetch requests the object report retrying rate limited and server error responses with backoff
+func (v *virustotal) fetch(ctx context.Context, resource, resourceType, resourceID string) (virustotalResponse, error) {
+	var apiResp virustotalResponse
+
+	client, err := system.GetHTTPClient(v.cfg)
+	if err != nil {
+		return apiResp, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = virustotalRequestTimeout
+
+	endpoint := fmt.Sprintf("%s/files/%s", virustotalAPIURL, resourceID)
+	if resourceType == virustotalTypeURL {
+		endpoint = fmt.Sprintf("%s/urls/%s", virustotalAPIURL, resourceID)
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return apiResp, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("x-apikey", v.apiKey())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return apiResp, fmt.Errorf("request to VirusTotal failed: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+				return apiResp, fmt.Errorf("failed to decode VirusTotal response: %w", err)
+			}
+			return apiResp, nil
+		}
+
+		var errResp virustotalErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound || errResp.Error.Code == "NotFoundError" {
+			return apiResp, errVirustotalNotFound
+		}
+
+		reqErr := &VirusTotalRequestError{
+			StatusCode: resp.StatusCode,
+			Code:       errResp.Error.Code,
+			Message:    errResp.Error.Message,
+			Attempts:   attempt,
+		}
+		if !reqErr.Temporary() || attempt >= virustotalMaxAttempts {
+			return apiResp, reqErr
+		}
+
+		delay := retryAfter
+		if delay <= 0 {
+			delay = virustotalBackoff(attempt)
+		}
+
+		// don't wait if the context expires before the next attempt
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return apiResp, reqErr
+		}
+
+		v.logRetry(ctx, resource, fmt.Sprintf(
+			"VirusTotal API returned HTTP %d, retry attempt %d of %d in %s",
+			resp.StatusCode, attempt+1, virustotalMaxAttempts, delay.Round(time.Millisecond),
+		))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			reqErr.Err = ctx.Err()
+			return apiResp, reqErr
+		case <-timer.C:
+		}
+	}
+}
+
+func (v *virustotal) logRetry(ctx context.Context, resource, message string) {
+	logrus.WithContext(ctx).WithFields(enrichLogrusFields(v.flowID, v.taskID, v.subtaskID, logrus.Fields{
+		"tool":     VirusTotalToolName,
+		"resource": resource[:min(len(resource), 1000)],
+	})).Warn(message)
+
+	if v.slp == nil {
+		return
+	}
+
+	if agentCtx, ok := GetAgentContext(ctx); ok {
+		_, _ = v.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeVirustotal,
+			resource,
+			message,
+			v.taskID,
+			v.subtaskID,
+		)
+	}
+}
+
+// virustotalBackoff returns exponential delay before the next attempt with jitter in range [d/2, d)
+func virustotalBackoff(attempt int) time.Duration {
+	delay := virustotalRetryMaxDelay
+	if attempt < 16 {
+		delay = min(virustotalRetryBaseDelay<<(attempt-1), virustotalRetryMaxDelay)
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + rand.N(half)
+}
+
+func formatVirustotalNotFound(resource, resourceType string) string {
+	var sb strings.Builder
+	sb.WriteString("# VirusTotal Report\n\n")
+	sb.WriteString(fmt.Sprintf("**Resource:** `%s` (%s)\n\n", resource, resourceType))
+	if resourceType == virustotalTypeFile {
+		sb.WriteString("The file was not found in VirusTotal: it was never submitted for analysis, " +
+			"so there is no reputation data. It doesn't mean the file is safe, " +
+			"uncommon and targeted samples are often unknown.\n")
+	} else {
+		sb.WriteString("The URL was not found in VirusTotal: it was never submitted for analysis, " +
+			"so there is no reputation data.\n")
+	}
+
+	return sb.String()
+}
+
+// formatVirustotalReport renders the detection ratio, first and last seen dates and engine verdicts
+// as markdown, detections are listed first and the output is capped by sizeLimit
+func formatVirustotalReport(resource, resourceType string, resp virustotalResponse, sizeLimit int) string {
+	attrs := resp.Data.Attributes
+
+	var sb strings.Builder
+	sb.WriteString("# VirusTotal Report\n\n")
+	sb.WriteString(fmt.Sprintf("**Resource:** `%s` (%s)  \n", resource, resourceType))
+
+	detected, total := 0, 0
+	for category, count := range attrs.LastAnalysisStats {
+		if slices.Contains(virustotalDetectedCategories, category) {
+			detected += count
+		}
+		if category != "type-unsupported" && category != "failure" && category != "timeout" {
+			total += count
+		}
+	}
+	sb.WriteString(fmt.Sprintf("**Detection ratio:** %d/%d  \n", detected, total))
+
+	categories := make([]string, 0, len(attrs.LastAnalysisStats))
+	for category := range attrs.LastAnalysisStats {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+	stats := make([]string, 0, len(categories))
+	for _, category := range categories {
+		stats = append(stats, fmt.Sprintf("%s %d", category, attrs.LastAnalysisStats[category]))
+	}
+	if len(stats) > 0 {
+		sb.WriteString(fmt.Sprintf("**Categories:** %s  \n", strings.Join(stats, ", ")))
+	}
+
+	if attrs.PopularThreat != nil && attrs.PopularThreat.SuggestedThreatLabel != "" {
+		sb.WriteString(fmt.Sprintf("**Threat label:** %s  \n", attrs.PopularThreat.SuggestedThreatLabel))
+	}
+
+	switch resourceType {
+	case virustotalTypeFile:
+		if attrs.MeaningfulName != "" {
+			sb.WriteString(fmt.Sprintf("**Name:** %s  \n", attrs.MeaningfulName))
+		}
+		if attrs.TypeDescription != "" {
+			sb.WriteString(fmt.Sprintf("**Type:** %s  \n", attrs.TypeDescription))
+		}
+		if attrs.Size > 0 {
+			sb.WriteString(fmt.Sprintf("**Size:** %d bytes  \n", attrs.Size))
+		}
+		if attrs.SHA256 != "" {
+			sb.WriteString(fmt.Sprintf("**SHA-256:** %s  \n", attrs.SHA256))
+		}
+	case virustotalTypeURL:
+		if attrs.URL != "" {
+			sb.WriteString(fmt.Sprintf("**URL:** %s  \n", attrs.URL))
+		}
+		if attrs.FinalURL != "" && attrs.FinalURL != attrs.URL {
+			sb.WriteString(fmt.Sprintf("**Final URL:** %s  \n", attrs.FinalURL))
+		}
+		if attrs.Title != "" {
+			sb.WriteString(fmt.Sprintf("**Title:** %s  \n", attrs.Title))
+		}
+	}
+
+	if date := formatVirustotalDate(attrs.FirstSubmissionDate); date != "" {
+		sb.WriteString(fmt.Sprintf("**First seen:** %s  \n", date))
+	}
+	if date := formatVirustotalDate(attrs.LastSubmissionDate); date != "" {
+		sb.WriteString(fmt.Sprintf("**Last seen:** %s  \n", date))
+	}
+	if date := formatVirustotalDate(attrs.LastAnalysisDate); date != "" {
+		sb.WriteString(fmt.Sprintf("**Last analysis:** %s  \n", date))
+	}
+	if attrs.TimesSubmitted > 0 {
+		sb.WriteString(fmt.Sprintf("**Times submitted:** %d  \n", attrs.TimesSubmitted))
+	}
+	sb.WriteString(fmt.Sprintf("**Community reputation:** %d  \n", attrs.Reputation))
+	if len(attrs.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("**Tags:** %s  \n", strings.Join(attrs.Tags, ", ")))
+	}
+
+	guiPath := "file"
+	if resourceType == virustotalTypeURL {
+		guiPath = "url"
+	}
+	sb.WriteString(fmt.Sprintf("**Report:** %s/%s/%s\n\n", virustotalGUIURL, guiPath, resp.Data.ID))
+
+	sb.WriteString(formatVirustotalVerdicts(attrs.LastAnalysisResults, sizeLimit-sb.Len()))
+
+	return sb.String()
+}
+
+// formatVirustotalVerdicts lists the engines which detected the resource ordered by the engine name,
+// engines with clean verdicts are only counted to keep the output short
+func formatVirustotalVerdicts(results map[string]virustotalEngineResult, sizeLimit int) string {
+	engines := make([]string, 0, len(results))
+	for engine, result := range results {
+		if slices.Contains(virustotalDetectedCategories, result.Category) {
+			engines = append(engines, engine)
+		}
+	}
+	slices.SortFunc(engines, func(a, b string) int {
+		// malicious verdicts go before suspicious ones
+		if ca, cb := results[a].Category, results[b].Category; ca != cb {
+			return strings.Compare(ca, cb)
+		}
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+
+	var sb strings.Builder
+	sb.WriteString("## Engine verdicts\n\n")
+	if len(engines) == 0 {
+		sb.WriteString("No engine flagged the resource as malicious or suspicious.\n")
+		return sb.String()
+	}
+
+	for i, engine := range engines {
+		result := results[engine]
+		line := fmt.Sprintf("- **%s:** %s\n", engine, result.Category)
+		if result.Result != "" {
+			line = fmt.Sprintf("- **%s:** %s (%s)\n", engine, result.Result, result.Category)
+		}
+
+		if i >= maxVirustotalVerdicts || sb.Len()+len(line) > sizeLimit-truncationMsgBuffer {
+			sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d detections (output truncated)\n", i, len(engines)))
+			break
+		}
+		sb.WriteString(line)
+	}
+
+	return sb.String()
+}
+
+func formatVirustotalDate(timestamp int64) string {
+	if timestamp <= 0 {
+		return ""
+	}
+
+	return time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+}
+
+// IsAvailable returns true if the VirusTotal API key is configured
+func (v *virustotal) IsAvailable() bool {
+	return v.apiKey() != ""
+}
+
+func (v *virustotal) apiKey() string {
+	if v.cfg == nil {
+		return ""
+	}
+
+	return v.cfg.VirusTotalAPIKey
+}
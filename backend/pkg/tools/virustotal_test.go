@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+const virustotalTestSHA256 = "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"
+
+// setVirustotalRetryDelays makes retries fast for tests
+func setVirustotalRetryDelays(t *testing.T, base, maxDelay time.Duration) {
+	t.Helper()
+
+	prevBase, prevMax := virustotalRetryBaseDelay, virustotalRetryMaxDelay
+	virustotalRetryBaseDelay, virustotalRetryMaxDelay = base, maxDelay
+	t.Cleanup(func() {
+		virustotalRetryBaseDelay, virustotalRetryMaxDelay = prevBase, prevMax
+	})
+}
+
+func readVirustotalTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	return body
+}
+
+func newVirustotalTestTool(t *testing.T, mux *http.ServeMux, slp SearchLogProvider, cache Cache) Tool {
+	t.Helper()
+
+	proxy, err := newTestProxy("www.virustotal.com", mux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	t.Cleanup(func() { proxy.Close() })
+
+	return NewVirusTotalTool(&config.Config{
+		VirusTotalAPIKey:  "test-key",
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, slp, cache)
+}
+
+func TestVirustotalFormatReport_Testdata(t *testing.T) {
+	var resp virustotalResponse
+	if err := json.Unmarshal(readVirustotalTestdata(t, "virustotal_result_file.json"), &resp); err != nil {
+		t.Fatalf("failed to parse testdata: %v", err)
+	}
+
+	got := formatVirustotalReport(virustotalTestSHA256, virustotalTypeFile, resp, DefaultMaxResultSize)
+
+	for _, want := range []string{
+		"# VirusTotal Report",
+		"**Detection ratio:** 5/7",
+		"**Categories:** failure 0, harmless 0, malicious 4, suspicious 1, timeout 1, type-unsupported 1, undetected 2",
+		"**Threat label:** virus.eicar/test",
+		"**Name:** eicar.com",
+		"**Size:** 68 bytes",
+		"**First seen:** 2006-05-22T12:42:02Z",
+		"**Last seen:** 2025-10-09T08:53:20Z",
+		"**Last analysis:** 2025-10-09T06:06:40Z",
+		"**Community reputation:** 3621",
+		"**Report:** https://www.virustotal.com/gui/file/" + virustotalTestSHA256,
+		"- **Kaspersky:** EICAR-Test-File (malicious)",
+		"- **Jiangmin:** suspicious\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+
+	// malicious verdicts go first ordered by engine name, clean engines aren't listed
+	bitdefender := strings.Index(got, "**BitDefender:**")
+	kaspersky := strings.Index(got, "**Kaspersky:**")
+	jiangmin := strings.Index(got, "**Jiangmin:**")
+	if bitdefender >= kaspersky || kaspersky >= jiangmin {
+		t.Errorf("unexpected verdicts order:\n%s", got)
+	}
+	for _, engine := range []string{"Baidu", "Zoner", "Avast-Mobile", "Paloalto"} {
+		if strings.Contains(got, engine) {
+			t.Errorf("report should not list %s verdict:\n%s", engine, got)
+		}
+	}
+}
+
+func TestVirustotalSizeLimits(t *testing.T) {
+	var resp virustotalResponse
+	resp.Data.ID = virustotalTestSHA256
+	resp.Data.Attributes.LastAnalysisResults = make(map[string]virustotalEngineResult)
+	for i := range 2 * maxVirustotalVerdicts {
+		engine := fmt.Sprintf("Engine%03d", i)
+		resp.Data.Attributes.LastAnalysisResults[engine] = virustotalEngineResult{
+			Category: "malicious",
+			Result:   strings.Repeat("Trojan.Generic.", 200),
+		}
+	}
+
+	got := formatVirustotalReport(virustotalTestSHA256, virustotalTypeFile, resp, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("report size = %d, want at most %d", len(got), DefaultMaxResultSize)
+	}
+	if !strings.Contains(got, "detections (output truncated)") {
+		t.Errorf("report missing truncation note")
+	}
+
+	resp.Data.Attributes.LastAnalysisResults = map[string]virustotalEngineResult{}
+	for i := range 2 * maxVirustotalVerdicts {
+		resp.Data.Attributes.LastAnalysisResults[fmt.Sprintf("Engine%03d", i)] = virustotalEngineResult{
+			Category: "malicious",
+			Result:   "Trojan",
+		}
+	}
+	got = formatVirustotalReport(virustotalTestSHA256, virustotalTypeFile, resp, DefaultMaxResultSize)
+	want := fmt.Sprintf("Showing %d of %d detections", maxVirustotalVerdicts, 2*maxVirustotalVerdicts)
+	if !strings.Contains(got, want) {
+		t.Errorf("report missing %q:\n%s", want, got)
+	}
+}
+
+func TestParseVirustotalResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		wantType string
+		wantID   string
+		wantErr  bool
+	}{
+		{"md5", "44D88612FEA8A8F36DE82E1278ABB02F", virustotalTypeFile, "44d88612fea8a8f36de82e1278abb02f", false},
+		{"sha1", "3395856ce81f2b7382dee72602f798b642f14140", virustotalTypeFile, "3395856ce81f2b7382dee72602f798b642f14140", false},
+		{"sha256", virustotalTestSHA256, virustotalTypeFile, virustotalTestSHA256, false},
+		{"url", "http://example.com/payload.exe", virustotalTypeURL,
+			base64.RawURLEncoding.EncodeToString([]byte("http://example.com/payload.exe")), false},
+		{"empty", "", "", "", true},
+		{"short hash", "44d88612fea8a8f3", "", "", true},
+		{"not hex", strings.Repeat("z", 64), "", "", true},
+		{"ftp url", "ftp://example.com/file", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotID, err := parseVirustotalResource(tt.resource)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVirustotalResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotType != tt.wantType || gotID != tt.wantID {
+				t.Errorf("parseVirustotalResource() = (%q, %q), want (%q, %q)", gotType, gotID, tt.wantType, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestVirustotalIsAvailable(t *testing.T) {
+	if NewVirusTotalTool(nil, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true with nil config")
+	}
+	if NewVirusTotalTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true without API key")
+	}
+	if !NewVirusTotalTool(&config.Config{VirusTotalAPIKey: "key"}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = false with API key")
+	}
+}
+
+func TestVirustotalHandle(t *testing.T) {
+	body := readVirustotalTestdata(t, "virustotal_result_file.json")
+
+	var requests int
+	var receivedKey string
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/api/v3/files/"+virustotalTestSHA256, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		receivedKey = r.Header.Get("x-apikey")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	slp := &searchLogProviderMock{}
+	vt := newVirustotalTestTool(t, mockMux, slp, NewMemoryCache(10))
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	args := []byte(fmt.Sprintf(`{"resource":"%s","message":"check"}`, strings.ToUpper(virustotalTestSHA256)))
+	for range 2 {
+		got, err := vt.Handle(ctx, VirusTotalToolName, args)
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "**Detection ratio:** 5/7") {
+			t.Errorf("result missing detection ratio:\n%s", got)
+		}
+	}
+
+	if receivedKey != "test-key" {
+		t.Errorf("x-apikey = %q, want test-key", receivedKey)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 with the second result from cache", requests)
+	}
+	if slp.calls != 2 {
+		t.Errorf("PutLog() calls = %d, want 2", slp.calls)
+	}
+	if slp.engine != database.SearchengineTypeVirustotal {
+		t.Errorf("engine = %q, want %q", slp.engine, database.SearchengineTypeVirustotal)
+	}
+}
+
+func TestVirustotalHandle_NotFound(t *testing.T) {
+	body := readVirustotalTestdata(t, "virustotal_result_not_found.json")
+	target := "http://example.com/payload.exe"
+
+	var requests int
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/api/v3/urls/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		id := strings.TrimPrefix(r.URL.Path, "/api/v3/urls/")
+		if want := base64.RawURLEncoding.EncodeToString([]byte(target)); id != want {
+			t.Errorf("url id = %q, want %q", id, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(body)
+	})
+
+	vt := newVirustotalTestTool(t, mockMux, &searchLogProviderMock{}, NewMemoryCache(10))
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	args := []byte(fmt.Sprintf(`{"resource":"%s","message":"check"}`, target))
+	for range 2 {
+		got, err := vt.Handle(ctx, VirusTotalToolName, args)
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "The URL was not found in VirusTotal") {
+			t.Errorf("result missing not found message:\n%s", got)
+		}
+	}
+
+	// not found results aren't cached, the resource may be submitted later
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestVirustotalHandle_RateLimit(t *testing.T) {
+	setVirustotalRetryDelays(t, time.Millisecond, 10*time.Millisecond)
+
+	body := readVirustotalTestdata(t, "virustotal_result_file.json")
+
+	var requests int
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/api/v3/files/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"code":"QuotaExceededError","message":"Quota exceeded"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	slp := &searchLogProviderMock{}
+	vt := newVirustotalTestTool(t, mockMux, slp, nil)
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	args := []byte(fmt.Sprintf(`{"resource":"%s","message":"check"}`, virustotalTestSHA256))
+	got, err := vt.Handle(ctx, VirusTotalToolName, args)
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "**Detection ratio:** 5/7") {
+		t.Errorf("expected report after retry, got:\n%s", got)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+	// the retry attempt and the final result
+	if slp.calls != 2 {
+		t.Errorf("PutLog() calls = %d, want 2", slp.calls)
+	}
+
+	// quota is still exceeded after the last attempt
+	requests = -virustotalMaxAttempts
+	mockMux.HandleFunc("/api/v3/files/44d88612fea8a8f36de82e1278abb02f", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"code":"QuotaExceededError","message":"Quota exceeded"}}`))
+	})
+
+	got, err = vt.Handle(ctx, VirusTotalToolName, []byte(`{"resource":"44d88612fea8a8f36de82e1278abb02f","message":"check"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d, want %d attempts", requests+virustotalMaxAttempts, virustotalMaxAttempts)
+	}
+	for _, want := range []string{"VirusTotal API quota exceeded (HTTP 429)", "after 3 attempts", "use other tools until then"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q: %s", want, got)
+		}
+	}
+}
+
+func TestVirustotalRequestError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *VirusTotalRequestError
+		wantTemporary bool
+		wantLimited   bool
+	}{
+		{"rate limited", &VirusTotalRequestError{StatusCode: http.StatusTooManyRequests}, true, true},
+		{"quota code", &VirusTotalRequestError{StatusCode: http.StatusForbidden, Code: "QuotaExceededError"}, true, true},
+		{"server error", &VirusTotalRequestError{StatusCode: http.StatusBadGateway}, true, false},
+		{"wrong key", &VirusTotalRequestError{StatusCode: http.StatusUnauthorized, Code: "WrongCredentialsError"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Temporary(); got != tt.wantTemporary {
+				t.Errorf("Temporary() = %v, want %v", got, tt.wantTemporary)
+			}
+			if got := tt.err.RateLimited(); got != tt.wantLimited {
+				t.Errorf("RateLimited() = %v, want %v", got, tt.wantLimited)
+			}
+		})
+	}
+}
@@ -0,0 +1,448 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	waybackCDXURL          = "https://web.archive.org/cdx/search/cdx"
+	waybackArchiveURL      = "https://web.archive.org/web"
+	waybackRequestTimeout  = 60 * time.Second
+	waybackCacheNamespace  = "wayback"
+	waybackCacheTTL        = time.Hour
+	defaultWaybackLimit    = 50
+	maxWaybackLimit        = 500
+	defaultWaybackMatch    = waybackMatchExact
+	waybackTimestampLayout = "20060102150405"
+
+	// waybackFetchLimit is the number of captures requested from the CDX API, it's bigger
+	// than the results limit because near-identical captures are collapsed locally
+	waybackFetchLimit = 2000
+
+	// waybackSimilarLengthRatio is the max relative difference of the compressed length of
+	// two captures with the same status and mimetype to treat them as near-identical
+	waybackSimilarLengthRatio = 0.02
+
+	waybackMatchExact  = "exact"
+	waybackMatchPrefix = "prefix"
+	waybackMatchHost   = "host"
+	waybackMatchDomain = "domain"
+)
+
+// waybackCDXFields are the requested columns of the CDX index
+var waybackCDXFields = []string{"timestamp", "original", "statuscode", "mimetype", "digest", "length"}
+
+var waybackDateRegex = regexp.MustCompile(`^\d{4,14}$`)
+
+// waybackCapture is a single snapshot of the URL from the CDX index, consecutive near-identical
+// captures are collapsed into one with the last timestamp and the number of captures
+type waybackCapture struct {
+	Timestamp     string `json:"timestamp"`
+	LastTimestamp string `json:"last_timestamp,omitempty"`
+	Original      string `json:"original"`
+	StatusCode    string `json:"statuscode"`
+	MimeType      string `json:"mimetype"`
+	Digest        string `json:"digest"`
+	Length        int64  `json:"length"`
+	Count         int    `json:"count"`
+}
+
+// WaybackRequestError is returned when the CDX API responds with an unsuccessful status
+type WaybackRequestError struct {
+	StatusCode int
+}
+
+func (e *WaybackRequestError) Error() string {
+	return fmt.Sprintf("Wayback Machine CDX API returned HTTP %d", e.StatusCode)
+}
+
+// Temporary returns true if the request may succeed when it's repeated later,
+// the Wayback Machine often responds with rate limits and overloaded backends
+func (e *WaybackRequestError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// wayback represents the Wayback Machine URL history tool
+type wayback struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewWaybackTool creates a new Wayback Machine URL history tool instance
+func NewWaybackTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &wayback{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes a Wayback Machine URL history request from an AI agent
+func (w *wayback) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !w.IsAvailable() {
+		return "", fmt.Errorf("wayback is not available")
+	}
+
+	var action WaybackAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(w.flowID, w.taskID, w.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal wayback action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	target := strings.TrimSpace(action.URL)
+	if target == "" {
+		return "failed to get URL history from the Wayback Machine: url is empty", nil
+	}
+
+	matchType := strings.ToLower(strings.TrimSpace(action.MatchType))
+	switch matchType {
+	case "":
+		matchType = defaultWaybackMatch
+	case waybackMatchExact, waybackMatchPrefix, waybackMatchHost, waybackMatchDomain:
+	default:
+		return fmt.Sprintf("failed to get URL history from the Wayback Machine: unknown match type '%s'", matchType), nil
+	}
+
+	from, to := strings.TrimSpace(action.From), strings.TrimSpace(action.To)
+	for _, date := range []string{from, to} {
+		if date != "" && !waybackDateRegex.MatchString(date) {
+			return fmt.Sprintf("failed to get URL history from the Wayback Machine: invalid date '%s', "+
+				"use 4 to 14 digits in form of YYYYMMDDhhmmss (e.g. '2021', '202103', '20210315')", date), nil
+		}
+	}
+
+	limit := action.MaxResults.Int()
+	if limit < 1 || limit > maxWaybackLimit {
+		limit = defaultWaybackLimit
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"url":        target[:min(len(target), 1000)],
+		"match_type": matchType,
+		"from":       from,
+		"to":         to,
+		"limit":      limit,
+	})
+
+	result, err := w.history(ctx, target, matchType, from, to, limit)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("wayback history error swallowed"),
+			langfuse.WithEventInput(target),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":  WaybackToolName,
+				"engine":     "wayback",
+				"url":        target,
+				"match_type": matchType,
+				"from":       from,
+				"to":         to,
+				"limit":      limit,
+				"error":      err.Error(),
+			}),
+		)
+
+		var reqErr *WaybackRequestError
+		if errors.As(err, &reqErr) && reqErr.Temporary() {
+			logger.WithError(err).Warn("wayback machine is temporarily unavailable")
+			return fmt.Sprintf("failed to get URL history from the Wayback Machine: %v, "+
+				"try again later or use other search tools", err), nil
+		}
+
+		logger.WithError(err).Error("failed to get URL history from the Wayback Machine")
+		return fmt.Sprintf("failed to get URL history from the Wayback Machine: %v", err), nil
+	}
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && w.slp != nil {
+		_, _ = w.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeWayback,
+			target,
+			result,
+			w.taskID,
+			w.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// history requests the captures from the CDX API and formats the collapsed timeline,
+// the parsed captures are kept in the shared cache before the limit is applied
+func (w *wayback) history(ctx context.Context, target, matchType, from, to string, limit int) (string, error) {
+	var captures []waybackCapture
+
+	cacheKey := normalizeCacheKey(target, matchType, from, to)
+	if !getCachedJSON(ctx, w.cache, waybackCacheNamespace, cacheKey, &captures) {
+		var err error
+		if captures, err = w.fetch(ctx, target, matchType, from, to); err != nil {
+			return "", err
+		}
+
+		setCachedJSON(ctx, w.cache, waybackCacheNamespace, cacheKey, captures, waybackCacheTTL)
+	}
+
+	return formatWaybackResults(target, matchType, from, to, limit, captures, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
+}
+
+func (w *wayback) fetch(ctx context.Context, target, matchType, from, to string) ([]waybackCapture, error) {
+	client, err := system.GetHTTPClient(w.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = waybackRequestTimeout
+
+	params := url.Values{}
+	params.Set("url", target)
+	params.Set("output", "json")
+	params.Set("matchType", matchType)
+	params.Set("fl", strings.Join(waybackCDXFields, ","))
+	params.Set("limit", strconv.Itoa(waybackFetchLimit))
+	if from != "" {
+		params.Set("from", from)
+	}
+	if to != "" {
+		params.Set("to", to)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, waybackCDXURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to Wayback Machine failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &WaybackRequestError{StatusCode: resp.StatusCode}
+	}
+
+	captures, err := parseWaybackCDX(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return collapseWaybackCaptures(captures), nil
+}
+
+// parseWaybackCDX parses the JSON output of the CDX API, it's a list of rows where the first one
+// contains the field names; the body is empty if the URL has no captures
+func parseWaybackCDX(r io.Reader) ([]waybackCapture, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Wayback Machine response: %w", err)
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil, nil
+	}
+
+	var rows [][]string
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode Wayback Machine response: %w", err)
+	}
+
+	if len(rows) < 2 {
+		return nil, nil
+	}
+
+	fields := make(map[string]int, len(rows[0]))
+	for idx, name := range rows[0] {
+		fields[name] = idx
+	}
+
+	get := func(row []string, name string) string {
+		if idx, ok := fields[name]; ok && idx < len(row) {
+			return row[idx]
+		}
+		return ""
+	}
+
+	captures := make([]waybackCapture, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		length, _ := strconv.ParseInt(get(row, "length"), 10, 64)
+		captures = append(captures, waybackCapture{
+			Timestamp:  get(row, "timestamp"),
+			Original:   get(row, "original"),
+			StatusCode: get(row, "statuscode"),
+			MimeType:   get(row, "mimetype"),
+			Digest:     get(row, "digest"),
+			Length:     length,
+			Count:      1,
+		})
+	}
+
+	return captures, nil
+}
+
+// collapseWaybackCaptures merges consecutive captures of the same URL which have the same content
+// digest or the same status, mimetype and almost the same length (e.g. pages with a dynamic date)
+func collapseWaybackCaptures(captures []waybackCapture) []waybackCapture {
+	result := make([]waybackCapture, 0, len(captures))
+	for _, capture := range captures {
+		if len(result) > 0 {
+			last := &result[len(result)-1]
+			if isSimilarWaybackCapture(*last, capture) {
+				last.LastTimestamp = capture.Timestamp
+				last.Count += capture.Count
+				continue
+			}
+		}
+
+		result = append(result, capture)
+	}
+
+	return result
+}
+
+func isSimilarWaybackCapture(a, b waybackCapture) bool {
+	if a.Original != b.Original {
+		return false
+	}
+
+	if a.Digest != "" && a.Digest == b.Digest {
+		return true
+	}
+
+	if a.StatusCode != b.StatusCode || a.MimeType != b.MimeType || a.Length <= 0 || b.Length <= 0 {
+		return false
+	}
+
+	diff := float64(max(a.Length, b.Length)-min(a.Length, b.Length)) / float64(max(a.Length, b.Length))
+	return diff <= waybackSimilarLengthRatio
+}
+
+// formatWaybackResults renders the timeline of captures as markdown table capped by sizeLimit
+func formatWaybackResults(
+	target, matchType, from, to string,
+	limit int,
+	captures []waybackCapture,
+	sizeLimit int,
+) string {
+	var sb strings.Builder
+	sb.WriteString("# Wayback Machine URL History\n\n")
+	sb.WriteString(fmt.Sprintf("**URL:** `%s`  \n", target))
+	sb.WriteString(fmt.Sprintf("**Match type:** %s  \n", matchType))
+	if from != "" || to != "" {
+		sb.WriteString(fmt.Sprintf("**Date range:** %s - %s  \n", formatWaybackDate(from), formatWaybackDate(to)))
+	}
+
+	if len(captures) == 0 {
+		sb.WriteString("\nNo captures found in the Wayback Machine\n")
+		return sb.String()
+	}
+
+	total := 0
+	for _, capture := range captures {
+		total += capture.Count
+	}
+	sb.WriteString(fmt.Sprintf("**Captures:** %d (%d after collapsing near-identical consecutive captures)  \n",
+		total, len(captures)))
+	if total >= waybackFetchLimit {
+		sb.WriteString(fmt.Sprintf("**Note:** only first %d captures were requested, narrow the date range "+
+			"to see later ones  \n", waybackFetchLimit))
+	}
+
+	sb.WriteString("\n| First capture | Last capture | Count | Status | MIME type | URL | Archive |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+
+	shown := min(limit, len(captures))
+	for i, capture := range captures[:shown] {
+		last := "-"
+		if capture.LastTimestamp != "" {
+			last = formatWaybackDate(capture.LastTimestamp)
+		}
+		status := capture.StatusCode
+		if status == "" || status == "-" {
+			status = "n/a"
+		}
+		archive := fmt.Sprintf("%s/%s/%s", waybackArchiveURL, capture.Timestamp, capture.Original)
+		line := fmt.Sprintf("| %s | %s | %d | %s | %s | %s | %s |\n",
+			formatWaybackDate(capture.Timestamp), last, capture.Count, status,
+			escapeWaybackCell(capture.MimeType), escapeWaybackCell(capture.Original), archive)
+
+		if sb.Len()+len(line) > sizeLimit-truncationMsgBuffer {
+			shown = i
+			sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d entries (output truncated, exceeded %s limit)\n",
+				i, len(captures), formatSizeLimit(sizeLimit)))
+			return sb.String()
+		}
+		sb.WriteString(line)
+	}
+
+	if shown < len(captures) {
+		sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d entries, increase max_results or narrow the date range\n",
+			shown, len(captures)))
+	}
+
+	return sb.String()
+}
+
+// formatWaybackDate converts CDX timestamp (full or partial YYYYMMDDhhmmss) to readable form
+func formatWaybackDate(timestamp string) string {
+	if timestamp == "" {
+		return "*"
+	}
+
+	if len(timestamp) == len(waybackTimestampLayout) {
+		if t, err := time.Parse(waybackTimestampLayout, timestamp); err == nil {
+			return t.Format("2006-01-02 15:04:05")
+		}
+	}
+
+	return timestamp
+}
+
+func escapeWaybackCell(value string) string {
+	return strings.NewReplacer("|", "%7C", "\n", " ").Replace(value)
+}
+
+// IsAvailable returns true if the Wayback Machine tool is enabled
+func (w *wayback) IsAvailable() bool {
+	return w.cfg != nil && w.cfg.WaybackEnabled
+}
@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func readWaybackTestdata(t *testing.T, name string) []waybackCapture {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to open testdata: %v", err)
+	}
+	defer file.Close()
+
+	captures, err := parseWaybackCDX(file)
+	if err != nil {
+		t.Fatalf("parseWaybackCDX() unexpected error: %v", err)
+	}
+
+	return captures
+}
+
+func TestWaybackParseCDX_Testdata(t *testing.T) {
+	captures := readWaybackTestdata(t, "wayback_result_cdx.json")
+	if len(captures) != 9 {
+		t.Fatalf("parsed captures = %d, want 9", len(captures))
+	}
+
+	first := captures[0]
+	if first.Timestamp != "20150311054510" || first.Original != "http://example.com/" ||
+		first.StatusCode != "200" || first.MimeType != "text/html" || first.Length != 1270 || first.Count != 1 {
+		t.Errorf("unexpected first capture: %+v", first)
+	}
+
+	collapsed := collapseWaybackCaptures(captures)
+	// identical digests, similar size with the same status, revisit record of the same content and the rest
+	wantCounts := []int{3, 2, 2, 1, 1}
+	if len(collapsed) != len(wantCounts) {
+		t.Fatalf("collapsed captures = %d, want %d: %+v", len(collapsed), len(wantCounts), collapsed)
+	}
+	for i, want := range wantCounts {
+		if collapsed[i].Count != want {
+			t.Errorf("collapsed[%d].Count = %d, want %d", i, collapsed[i].Count, want)
+		}
+	}
+	if collapsed[0].LastTimestamp != "20150817210000" {
+		t.Errorf("collapsed[0].LastTimestamp = %q, want 20150817210000", collapsed[0].LastTimestamp)
+	}
+
+	got := formatWaybackResults("example.com", waybackMatchExact, "2015", "", 50, collapsed, DefaultMaxResultSize)
+	for _, want := range []string{
+		"# Wayback Machine URL History",
+		"**Date range:** 2015 - *",
+		"**Captures:** 9 (5 after collapsing near-identical consecutive captures)",
+		"| 2015-03-11 05:45:10 | 2015-08-17 21:00:00 | 3 | 200 | text/html | http://example.com/ | " +
+			"https://web.archive.org/web/20150311054510/http://example.com/ |",
+		"| 2017-03-04 05:06:07 | 2018-04-05 06:07:08 | 2 | 200 | text/html |",
+		"| 2019-05-06 07:08:09 | - | 1 | 404 |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+
+	got = formatWaybackResults("example.com", waybackMatchExact, "", "", 2, collapsed, DefaultMaxResultSize)
+	if !strings.Contains(got, "**Note:** Showing 2 of 5 entries") {
+		t.Errorf("result missing limit note:\n%s", got)
+	}
+}
+
+func TestWaybackParseCDX_Empty(t *testing.T) {
+	for _, body := range []string{"", "\n", "[]", `[["timestamp","original"]]`} {
+		captures, err := parseWaybackCDX(strings.NewReader(body))
+		if err != nil {
+			t.Errorf("parseWaybackCDX(%q) unexpected error: %v", body, err)
+		}
+		if len(captures) != 0 {
+			t.Errorf("parseWaybackCDX(%q) = %d captures, want 0", body, len(captures))
+		}
+	}
+
+	if _, err := parseWaybackCDX(strings.NewReader("<html>error</html>")); err == nil {
+		t.Error("parseWaybackCDX() expected error for non JSON body")
+	}
+
+	got := formatWaybackResults("example.com", waybackMatchExact, "", "", 10, nil, DefaultMaxResultSize)
+	if !strings.Contains(got, "No captures found in the Wayback Machine") {
+		t.Errorf("result missing empty message:\n%s", got)
+	}
+}
+
+func TestWaybackSizeLimits(t *testing.T) {
+	captures := make([]waybackCapture, 0, maxWaybackLimit)
+	for i := range maxWaybackLimit {
+		captures = append(captures, waybackCapture{
+			Timestamp:  fmt.Sprintf("2020%010d", i),
+			Original:   fmt.Sprintf("http://example.com/%d/%s", i, strings.Repeat("a", 500)),
+			StatusCode: "200",
+			MimeType:   "text/html",
+			Count:      1,
+		})
+	}
+
+	got := formatWaybackResults("example.com", waybackMatchPrefix, "", "", maxWaybackLimit, captures, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("result size = %d, want at most %d", len(got), DefaultMaxResultSize)
+	}
+	if !strings.Contains(got, "output truncated, exceeded 80 KB limit") {
+		t.Errorf("result missing truncation note")
+	}
+}
+
+func TestWaybackIsAvailable(t *testing.T) {
+	if NewWaybackTool(nil, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true with nil config")
+	}
+	if NewWaybackTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+	if !NewWaybackTool(&config.Config{WaybackEnabled: true}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = false when enabled")
+	}
+}
+
+func TestWaybackHandle(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "wayback_result_cdx.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	var requests int
+	var receivedQuery map[string]string
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/cdx/search/cdx", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		receivedQuery = map[string]string{}
+		for key := range r.URL.Query() {
+			receivedQuery[key] = r.URL.Query().Get(key)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	proxy, err := newTestProxy("web.archive.org", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	slp := &searchLogProviderMock{}
+	wb := NewWaybackTool(&config.Config{
+		WaybackEnabled:    true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, slp, NewMemoryCache(10))
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	for _, limit := range []int{50, 2} {
+		args := fmt.Sprintf(`{"url":"example.com","match_type":"Prefix","from":"2015","to":"2020","max_results":%d}`, limit)
+		got, err := wb.Handle(ctx, WaybackToolName, []byte(args))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "**Captures:** 9 (5 after collapsing") {
+			t.Errorf("result missing captures summary:\n%s", got)
+		}
+	}
+
+	// the second call with the other limit uses cached captures
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+	for key, want := range map[string]string{
+		"url": "example.com", "matchType": "prefix", "from": "2015", "to": "2020", "output": "json",
+	} {
+		if receivedQuery[key] != want {
+			t.Errorf("query %s = %q, want %q", key, receivedQuery[key], want)
+		}
+	}
+	if slp.calls != 2 || slp.engine != database.SearchengineTypeWayback {
+		t.Errorf("PutLog() calls = %d with engine %q, want 2 with %q", slp.calls, slp.engine, database.SearchengineTypeWayback)
+	}
+}
+
+func TestWaybackHandle_ValidationAndErrors(t *testing.T) {
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/cdx/search/cdx", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	proxy, err := newTestProxy("web.archive.org", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	wb := NewWaybackTool(&config.Config{
+		WaybackEnabled:    true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, &searchLogProviderMock{}, nil)
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"empty url", `{"url":" "}`, "url is empty"},
+		{"bad match type", `{"url":"example.com","match_type":"regex"}`, "unknown match type 'regex'"},
+		{"bad date", `{"url":"example.com","from":"2019-01-01"}`, "invalid date '2019-01-01'"},
+		{"unavailable", `{"url":"example.com"}`, "HTTP 503, try again later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wb.Handle(t.Context(), WaybackToolName, []byte(tt.args))
+			if err != nil {
+				t.Fatalf("Handle() unexpected error: %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("result = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,853 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/system"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/publicsuffix"
+)
+
+// whoisIANAServer is the root WHOIS server which refers to the TLD and RIR servers,
+// variable to make tests independent of the network
+var whoisIANAServer = "whois.iana.org:43"
+
+const (
+	// rdapBootstrapURL redirects to the authoritative RDAP server of the TLD or the IP registry
+	rdapBootstrapURL      = "https://rdap.org"
+	whoisRequestTimeout   = 30 * time.Second
+	whoisCacheNamespace   = "whois"
+	whoisCacheTTL         = time.Hour
+	whoisMaxRetryAfter    = 10 * time.Second
+	maxWhoisResponseSize  = 1024 * 1024 // 1 MB max of the raw RDAP or WHOIS response
+	maxWhoisRawOutputSize = 20 * 1024   // 20 KB max of the raw WHOIS text in the result
+	maxWhoisReferrals     = 2
+
+	whoisTypeDomain = "domain"
+	whoisTypeIP     = "ip"
+)
+
+// WhoisRequestError is returned when the RDAP server responds with an unsuccessful status
+type WhoisRequestError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *WhoisRequestError) Error() string {
+	if e.RateLimited() {
+		return fmt.Sprintf("RDAP server %s rate limited the request (HTTP %d)", e.URL, e.StatusCode)
+	}
+
+	return fmt.Sprintf("RDAP server %s returned HTTP %d", e.URL, e.StatusCode)
+}
+
+// RateLimited returns true if the registry server rejected the request by its rate limit
+func (e *WhoisRequestError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Temporary returns true if the request may succeed when it's repeated later
+func (e *WhoisRequestError) Temporary() bool {
+	return e.RateLimited() || e.StatusCode >= 500
+}
+
+// errRDAPNotFound is returned when RDAP has no record or no server for the target
+var errRDAPNotFound = errors.New("object is not found in RDAP")
+
+// rdapObject is the subset of RDAP domain, IP network and entity object classes (RFC 9083)
+type rdapObject struct {
+	ObjectClassName string           `json:"objectClassName"`
+	Handle          string           `json:"handle"`
+	LDHName         string           `json:"ldhName"`
+	UnicodeName     string           `json:"unicodeName"`
+	Name            string           `json:"name"`
+	Type            string           `json:"type"`
+	Country         string           `json:"country"`
+	StartAddress    string           `json:"startAddress"`
+	EndAddress      string           `json:"endAddress"`
+	ParentHandle    string           `json:"parentHandle"`
+	CIDRs           []rdapCIDR       `json:"cidr0_cidrs"`
+	Status          []string         `json:"status"`
+	Events          []rdapEvent      `json:"events"`
+	Nameservers     []rdapNameserver `json:"nameservers"`
+	Entities        []rdapEntity     `json:"entities"`
+	SecureDNS       *struct {
+		DelegationSigned bool `json:"delegationSigned"`
+	} `json:"secureDNS,omitempty"`
+}
+
+type rdapCIDR struct {
+	V4Prefix string `json:"v4prefix"`
+	V6Prefix string `json:"v6prefix"`
+	Length   int    `json:"length"`
+}
+
+type rdapEvent struct {
+	EventAction string `json:"eventAction"`
+	EventDate   string `json:"eventDate"`
+}
+
+type rdapNameserver struct {
+	LDHName     string `json:"ldhName"`
+	UnicodeName string `json:"unicodeName"`
+}
+
+type rdapEntity struct {
+	Handle     string          `json:"handle"`
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+	PublicIDs  []rdapPublicID  `json:"publicIds"`
+	Entities   []rdapEntity    `json:"entities"`
+}
+
+type rdapPublicID struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+}
+
+// whoisContact is the normalized contact of the RDAP entity or the WHOIS record
+type whoisContact struct {
+	Roles   []string `json:"roles"`
+	Handle  string   `json:"handle,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Org     string   `json:"org,omitempty"`
+	Email   string   `json:"email,omitempty"`
+	Phone   string   `json:"phone,omitempty"`
+	Country string   `json:"country,omitempty"`
+	IANAID  string   `json:"iana_id,omitempty"`
+}
+
+// whoisRecord is the normalized lookup result of both RDAP and WHOIS responses
+type whoisRecord struct {
+	Source      string         `json:"source"`
+	Server      string         `json:"server"`
+	Name        string         `json:"name,omitempty"`
+	Handle      string         `json:"handle,omitempty"`
+	Status      []string       `json:"status,omitempty"`
+	Created     string         `json:"created,omitempty"`
+	Updated     string         `json:"updated,omitempty"`
+	Expires     string         `json:"expires,omitempty"`
+	Nameservers []string       `json:"nameservers,omitempty"`
+	DNSSEC      string         `json:"dnssec,omitempty"`
+	Range       string         `json:"range,omitempty"`
+	CIDRs       []string       `json:"cidrs,omitempty"`
+	NetType     string         `json:"net_type,omitempty"`
+	Country     string         `json:"country,omitempty"`
+	Parent      string         `json:"parent,omitempty"`
+	Contacts    []whoisContact `json:"contacts,omitempty"`
+	Raw         string         `json:"raw,omitempty"`
+}
+
+// whois represents the RDAP and WHOIS lookup tool for domains and IP addresses
+type whois struct {
+	cfg       *config.Config
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	slp       SearchLogProvider
+	cache     Cache
+}
+
+// NewWhoisTool creates a new RDAP and WHOIS lookup tool instance
+func NewWhoisTool(
+	cfg *config.Config,
+	flowID int64,
+	taskID, subtaskID *int64,
+	slp SearchLogProvider,
+	cache Cache,
+) Tool {
+	return &whois{
+		cfg:       cfg,
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		slp:       slp,
+		cache:     cache,
+	}
+}
+
+// Handle processes a WHOIS lookup request from an AI agent
+func (w *whois) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !w.IsAvailable() {
+		return "", fmt.Errorf("whois is not available")
+	}
+
+	var action WhoisAction
+	ctx, observation := obs.Observer.NewObservation(ctx)
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(w.flowID, w.taskID, w.subtaskID, logrus.Fields{
+		"tool": name,
+		"args": string(args),
+	}))
+
+	if err := json.Unmarshal(args, &action); err != nil {
+		logger.WithError(err).Error("failed to unmarshal whois action")
+		return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+	}
+
+	target, targetType, err := normalizeWhoisTarget(action.Target)
+	if err != nil {
+		logger.WithError(err).Warn("invalid whois target")
+		return fmt.Sprintf("failed to lookup WHOIS: %v", err), nil
+	}
+
+	logger = logger.WithFields(logrus.Fields{
+		"target":      target,
+		"target_type": targetType,
+	})
+
+	result, err := w.lookup(ctx, target, targetType)
+	if err != nil {
+		observation.Event(
+			langfuse.WithEventName("whois lookup error swallowed"),
+			langfuse.WithEventInput(target),
+			langfuse.WithEventStatus(err.Error()),
+			langfuse.WithEventLevel(langfuse.ObservationLevelWarning),
+			langfuse.WithEventMetadata(langfuse.Metadata{
+				"tool_name":   WhoisToolName,
+				"engine":      "whois",
+				"target":      target,
+				"target_type": targetType,
+				"error":       err.Error(),
+			}),
+		)
+
+		var reqErr *WhoisRequestError
+		if errors.As(err, &reqErr) && reqErr.Temporary() {
+			logger.WithError(err).Warn("whois lookup is temporarily unavailable")
+			return fmt.Sprintf("failed to lookup WHOIS: %v, try again later", err), nil
+		}
+
+		logger.WithError(err).Error("failed to lookup WHOIS")
+		return fmt.Sprintf("failed to lookup WHOIS: %v", err), nil
+	}
+
+	if agentCtx, ok := GetAgentContext(ctx); ok && w.slp != nil {
+		_, _ = w.slp.PutLog(
+			ctx,
+			agentCtx.ParentAgentType,
+			agentCtx.CurrentAgentType,
+			database.SearchengineTypeWhois,
+			target,
+			result,
+			w.taskID,
+			w.subtaskID,
+		)
+	}
+
+	return result, nil
+}
+
+// normalizeWhoisTarget accepts IP address, domain or URL and returns the IP address or
+// the registrable domain because registries don't know about subdomains
+func normalizeWhoisTarget(value string) (string, string, error) {
+	target := strings.TrimSpace(value)
+	if target == "" {
+		return "", "", errors.New("target is empty, pass a domain or an IP address")
+	}
+
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+			target = u.Hostname()
+		}
+	}
+	target = strings.TrimSuffix(strings.ToLower(strings.Trim(target, "[]")), ".")
+
+	if ip := net.ParseIP(target); ip != nil {
+		return ip.String(), whoisTypeIP, nil
+	}
+
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		target = host
+	}
+
+	if !strings.Contains(target, ".") || strings.ContainsAny(target, " /\\@") {
+		return "", "", fmt.Errorf("target '%s' is neither a domain nor an IP address", target[:min(len(target), 200)])
+	}
+
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(target); err == nil {
+		target = domain
+	}
+
+	return target, whoisTypeDomain, nil
+}
+
+// lookup queries RDAP and falls back to WHOIS if RDAP has no record, no server for the TLD
+// or the registry server is rate limited or unavailable
+func (w *whois) lookup(ctx context.Context, target, targetType string) (string, error) {
+	var record whoisRecord
+
+	cacheKey := normalizeCacheKey(targetType, target)
+	if getCachedJSON(ctx, w.cache, whoisCacheNamespace, cacheKey, &record) {
+		return formatWhoisRecord(target, targetType, record, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
+	}
+
+	record, rdapErr := w.rdap(ctx, target, targetType)
+	if rdapErr != nil {
+		var whoisErr error
+		record, whoisErr = w.whois(ctx, target)
+		if whoisErr != nil {
+			if errors.Is(rdapErr, errRDAPNotFound) {
+				return fmt.Sprintf("# WHOIS Lookup\n\n**Target:** `%s` (%s)\n\n"+
+					"No registration data found neither in RDAP nor in WHOIS (%v)\n", target, targetType, whoisErr), nil
+			}
+			return "", errors.Join(rdapErr, whoisErr)
+		}
+
+		w.logFallback(ctx, target, rdapErr)
+	}
+
+	setCachedJSON(ctx, w.cache, whoisCacheNamespace, cacheKey, record, whoisCacheTTL)
+
+	return formatWhoisRecord(target, targetType, record, resultSizeLimitsFromContext(ctx).MaxResultSize), nil
+}
+
+func (w *whois) logFallback(ctx context.Context, target string, rdapErr error) {
+	logrus.WithContext(ctx).WithFields(enrichLogrusFields(w.flowID, w.taskID, w.subtaskID, logrus.Fields{
+		"tool":   WhoisToolName,
+		"target": target,
+	})).WithError(rdapErr).Info("RDAP lookup failed, WHOIS is used instead")
+}
+
+// rdap requests the registration data from the bootstrap server which redirects to the registry,
+// the request is repeated once if the registry is rate limited with short Retry-After
+func (w *whois) rdap(ctx context.Context, target, targetType string) (whoisRecord, error) {
+	client, err := system.GetHTTPClient(w.cfg)
+	if err != nil {
+		return whoisRecord{}, fmt.Errorf("failed to create http client: %w", err)
+	}
+
+	client.Timeout = whoisRequestTimeout
+
+	endpoint := fmt.Sprintf("%s/%s/%s", rdapBootstrapURL, targetType, url.PathEscape(target))
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return whoisRecord{}, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/rdap+json, application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return whoisRecord{}, fmt.Errorf("request to RDAP failed: %w", err)
+		}
+
+		server := resp.Request.URL.String()
+		if resp.StatusCode == http.StatusOK {
+			defer resp.Body.Close()
+			return parseRDAPResponse(io.LimitReader(resp.Body, maxWhoisResponseSize), server)
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return whoisRecord{}, errRDAPNotFound
+		}
+
+		reqErr := &WhoisRequestError{StatusCode: resp.StatusCode, URL: server}
+		if !reqErr.RateLimited() || attempt > 1 || retryAfter <= 0 || retryAfter > whoisMaxRetryAfter {
+			return whoisRecord{}, reqErr
+		}
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return whoisRecord{}, reqErr
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRDAPResponse normalizes RDAP domain and IP network responses, registries fill different
+// fields, so events, nameservers and contacts are collected from all known places
+func parseRDAPResponse(r io.Reader, server string) (whoisRecord, error) {
+	var obj rdapObject
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return whoisRecord{}, fmt.Errorf("failed to decode RDAP response: %w", err)
+	}
+
+	record := whoisRecord{
+		Source:  "RDAP",
+		Server:  server,
+		Handle:  obj.Handle,
+		Status:  obj.Status,
+		NetType: obj.Type,
+		Country: obj.Country,
+		Parent:  obj.ParentHandle,
+	}
+
+	// registries return domain names in different case, IP networks have only the name
+	ldhName := strings.ToLower(obj.LDHName)
+	switch {
+	case obj.UnicodeName != "" && !strings.EqualFold(obj.UnicodeName, ldhName):
+		record.Name = fmt.Sprintf("%s (%s)", obj.UnicodeName, ldhName)
+	case ldhName != "":
+		record.Name = ldhName
+	default:
+		record.Name = obj.Name
+	}
+
+	for _, event := range obj.Events {
+		date := formatRDAPDate(event.EventDate)
+		switch strings.ToLower(event.EventAction) {
+		case "registration":
+			record.Created = date
+		case "expiration":
+			record.Expires = date
+		case "last changed":
+			record.Updated = date
+		}
+	}
+
+	for _, ns := range obj.Nameservers {
+		name := ns.LDHName
+		if name == "" {
+			name = ns.UnicodeName
+		}
+		if name = strings.TrimSuffix(strings.ToLower(name), "."); name != "" && !slices.Contains(record.Nameservers, name) {
+			record.Nameservers = append(record.Nameservers, name)
+		}
+	}
+
+	if obj.SecureDNS != nil {
+		record.DNSSEC = "unsigned"
+		if obj.SecureDNS.DelegationSigned {
+			record.DNSSEC = "signed"
+		}
+	}
+
+	if obj.StartAddress != "" || obj.EndAddress != "" {
+		record.Range = fmt.Sprintf("%s - %s", obj.StartAddress, obj.EndAddress)
+	}
+	for _, cidr := range obj.CIDRs {
+		prefix := cidr.V4Prefix
+		if prefix == "" {
+			prefix = cidr.V6Prefix
+		}
+		if prefix != "" {
+			record.CIDRs = append(record.CIDRs, fmt.Sprintf("%s/%d", prefix, cidr.Length))
+		}
+	}
+
+	record.Contacts = collectRDAPContacts(obj.Entities)
+
+	return record, nil
+}
+
+// collectRDAPContacts flattens nested entities, e.g. abuse contact is usually nested in the registrar
+func collectRDAPContacts(entities []rdapEntity) []whoisContact {
+	var contacts []whoisContact
+	for _, entity := range entities {
+		contact := parseVCard(entity.VCardArray)
+		contact.Handle = entity.Handle
+		for _, role := range entity.Roles {
+			contact.Roles = append(contact.Roles, strings.ToLower(role))
+		}
+		for _, id := range entity.PublicIDs {
+			if strings.Contains(strings.ToLower(id.Type), "iana") {
+				contact.IANAID = id.Identifier
+			}
+		}
+
+		contacts = append(contacts, contact)
+		contacts = append(contacts, collectRDAPContacts(entity.Entities)...)
+	}
+
+	return contacts
+}
+
+// parseVCard extracts the contact from jCard (RFC 7095), properties are lists in form of
+// [name, params, type, value...] where the value may be a string or a list of strings
+func parseVCard(raw json.RawMessage) whoisContact {
+	var contact whoisContact
+
+	var vcard []json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &vcard) != nil || len(vcard) < 2 {
+		return contact
+	}
+
+	var properties [][]json.RawMessage
+	if json.Unmarshal(vcard[1], &properties) != nil {
+		return contact
+	}
+
+	for _, property := range properties {
+		if len(property) < 4 {
+			continue
+		}
+
+		var name string
+		if json.Unmarshal(property[0], &name) != nil {
+			continue
+		}
+
+		value := jCardValue(property[3])
+		switch strings.ToLower(name) {
+		case "fn":
+			contact.Name = value
+		case "org":
+			contact.Org = value
+		case "email":
+			contact.Email = value
+		case "tel":
+			if contact.Phone == "" {
+				contact.Phone = strings.TrimPrefix(value, "tel:")
+			}
+		case "adr":
+			var params struct {
+				CC string `json:"cc"`
+			}
+			_ = json.Unmarshal(property[1], &params)
+
+			var parts []string
+			if json.Unmarshal(property[3], &parts) == nil && len(parts) == 7 && parts[6] != "" {
+				contact.Country = parts[6]
+			} else if params.CC != "" {
+				contact.Country = params.CC
+			}
+		}
+	}
+
+	return contact
+}
+
+// jCardValue joins the structured value (e.g. org with units) into the single string
+func jCardValue(raw json.RawMessage) string {
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return strings.TrimSpace(value)
+	}
+
+	var values []any
+	if json.Unmarshal(raw, &values) != nil {
+		return ""
+	}
+
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			parts = append(parts, strings.TrimSpace(s))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func formatRDAPDate(value string) string {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC().Format("2006-01-02")
+	}
+
+	return value
+}
+
+// whois queries the IANA server and follows the referral to the TLD or RIR server
+func (w *whois) whois(ctx context.Context, target string) (whoisRecord, error) {
+	server := whoisIANAServer
+	response, err := w.queryWhois(ctx, server, target)
+	if err != nil {
+		return whoisRecord{}, err
+	}
+
+	for range maxWhoisReferrals {
+		refer := parseWhoisReferral(response)
+		if refer == "" || refer == server {
+			break
+		}
+
+		referResponse, err := w.queryWhois(ctx, refer, target)
+		if err != nil {
+			return whoisRecord{}, err
+		}
+		server, response = refer, referResponse
+	}
+
+	record := parseWhoisResponse(response)
+	record.Server = strings.TrimSuffix(server, ":43")
+
+	// servers respond with free-form "no match" text or nothing when the record is not found
+	if record.Name == "" && record.Range == "" && record.Created == "" &&
+		len(record.Nameservers) == 0 && len(record.Contacts) == 0 {
+		return whoisRecord{}, fmt.Errorf("WHOIS server %s has no record", record.Server)
+	}
+
+	return record, nil
+}
+
+// queryWhois sends the query to the WHOIS server (RFC 3912) directly or through the CONNECT
+// tunnel of the configured HTTP proxy
+func (w *whois) queryWhois(ctx context.Context, server, query string) (string, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "43")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, whoisRequestTimeout)
+	defer cancel()
+
+	conn, err := dialTCP(ctx, w.cfg, server)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to WHOIS server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", fmt.Errorf("failed to send query to WHOIS server %s: %w", server, err)
+	}
+
+	response, err := io.ReadAll(io.LimitReader(conn, maxWhoisResponseSize))
+	if err != nil && len(response) == 0 {
+		return "", fmt.Errorf("failed to read response of WHOIS server %s: %w", server, err)
+	}
+
+	return string(response), nil
+}
+
+// parseWhoisReferral returns the next server from the IANA ("refer:") or registry ("Registrar WHOIS Server:") response
+func parseWhoisReferral(response string) string {
+	for _, line := range strings.Split(response, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "refer", "whois", "registrar whois server", "referralserver":
+			value = strings.TrimSpace(value)
+			value = strings.TrimPrefix(value, "whois://")
+			value = strings.TrimPrefix(value, "rwhois://")
+			if value != "" && !strings.ContainsAny(value, " /") {
+				return strings.ToLower(value)
+			}
+		}
+	}
+
+	return ""
+}
+
+// parseWhoisResponse extracts the common fields of the free-form WHOIS response,
+// keys differ between registries, so all known spellings are matched
+func parseWhoisResponse(response string) whoisRecord {
+	record := whoisRecord{Source: "WHOIS"}
+	contacts := map[string]*whoisContact{}
+	contact := func(role string) *whoisContact {
+		if c, ok := contacts[role]; ok {
+			return c
+		}
+		c := &whoisContact{Roles: []string{role}}
+		contacts[role] = c
+		return c
+	}
+
+	for _, line := range strings.Split(response, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || strings.HasPrefix(key, "%") || strings.HasPrefix(key, "#") {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		switch key {
+		case "domain name", "domain":
+			record.Name = strings.ToLower(value)
+		case "netname":
+			record.Name = value
+		case "registry domain id", "nethandle":
+			record.Handle = value
+		case "domain status", "status":
+			if status, _, _ := strings.Cut(value, " "); !slices.Contains(record.Status, status) {
+				record.Status = append(record.Status, status)
+			}
+		case "creation date", "created", "registered", "regdate", "registration time":
+			if record.Created == "" {
+				record.Created = formatRDAPDate(value)
+			}
+		case "updated date", "last-modified", "changed", "updated", "last updated":
+			record.Updated = formatRDAPDate(value)
+		case "registry expiry date", "registrar registration expiration date", "expiry date",
+			"expiration date", "paid-till", "expires":
+			if record.Expires == "" {
+				record.Expires = formatRDAPDate(value)
+			}
+		case "name server", "nserver", "nameserver":
+			ns, _, _ := strings.Cut(strings.ToLower(value), " ")
+			if ns = strings.TrimSuffix(ns, "."); !slices.Contains(record.Nameservers, ns) {
+				record.Nameservers = append(record.Nameservers, ns)
+			}
+		case "dnssec":
+			record.DNSSEC = value
+		case "inetnum", "inet6num", "netrange":
+			record.Range = value
+		case "cidr", "route":
+			record.CIDRs = append(record.CIDRs, value)
+		case "country":
+			if record.Country == "" {
+				record.Country = value
+			}
+		case "registrar":
+			contact("registrar").Name = value
+		case "registrar iana id":
+			contact("registrar").IANAID = value
+		case "registrar abuse contact email", "orgabuseemail", "abuse-mailbox":
+			contact("abuse").Email = value
+		case "registrar abuse contact phone", "orgabusephone":
+			contact("abuse").Phone = value
+		case "registrant name":
+			contact("registrant").Name = value
+		case "registrant organization", "org-name", "orgname", "organization":
+			contact("registrant").Org = value
+		case "registrant email":
+			contact("registrant").Email = value
+		case "registrant country":
+			contact("registrant").Country = value
+		}
+	}
+
+	for _, role := range []string{"registrant", "registrar", "abuse"} {
+		if c, ok := contacts[role]; ok {
+			record.Contacts = append(record.Contacts, *c)
+		}
+	}
+
+	record.Raw = strings.TrimSpace(response)
+
+	return record
+}
+
+// formatWhoisRecord renders the normalized record as markdown capped by sizeLimit
+func formatWhoisRecord(target, targetType string, record whoisRecord, sizeLimit int) string {
+	var sb strings.Builder
+	sb.WriteString("# WHOIS Lookup\n\n")
+	sb.WriteString(fmt.Sprintf("**Target:** `%s` (%s)  \n", target, targetType))
+	sb.WriteString(fmt.Sprintf("**Source:** %s (%s)  \n", record.Source, record.Server))
+
+	field := func(name, value string) {
+		if value != "" {
+			sb.WriteString(fmt.Sprintf("**%s:** %s  \n", name, value))
+		}
+	}
+
+	field("Name", record.Name)
+	field("Handle", record.Handle)
+	field("Range", record.Range)
+	field("CIDR", strings.Join(record.CIDRs, ", "))
+	field("Network type", record.NetType)
+	field("Parent", record.Parent)
+	field("Country", record.Country)
+	field("Status", strings.Join(record.Status, ", "))
+	field("Created", record.Created)
+	field("Updated", record.Updated)
+	field("Expires", record.Expires)
+	field("DNSSEC", record.DNSSEC)
+
+	roleContacts := func(role string) []whoisContact {
+		var result []whoisContact
+		for _, contact := range record.Contacts {
+			if slices.Contains(contact.Roles, role) {
+				result = append(result, contact)
+			}
+		}
+		return result
+	}
+
+	for _, role := range []string{"registrant", "registrar", "abuse"} {
+		for _, contact := range roleContacts(role) {
+			field(strings.ToUpper(role[:1])+role[1:], formatWhoisContact(contact))
+		}
+	}
+
+	if len(record.Nameservers) > 0 {
+		sb.WriteString("\n## Nameservers\n\n")
+		for _, ns := range record.Nameservers {
+			sb.WriteString(fmt.Sprintf("- %s\n", ns))
+		}
+	}
+
+	var others []whoisContact
+	for _, contact := range record.Contacts {
+		if !slices.ContainsFunc(contact.Roles, func(role string) bool {
+			return role == "registrant" || role == "registrar" || role == "abuse"
+		}) {
+			others = append(others, contact)
+		}
+	}
+	if len(others) > 0 {
+		sb.WriteString("\n## Other contacts\n\n")
+		for i, contact := range others {
+			line := fmt.Sprintf("- **%s:** %s\n", strings.Join(contact.Roles, ", "), formatWhoisContact(contact))
+			if sb.Len()+len(line) > sizeLimit-truncationMsgBuffer {
+				sb.WriteString(fmt.Sprintf("\n**Note:** Showing %d of %d contacts (output truncated)\n", i, len(others)))
+				break
+			}
+			sb.WriteString(line)
+		}
+	}
+
+	if record.Raw != "" {
+		raw := record.Raw
+		rawLimit := min(maxWhoisRawOutputSize, sizeLimit-sb.Len()-truncationMsgBuffer)
+		if len(raw) > rawLimit {
+			raw = raw[:max(rawLimit, 0)] + "\n[response truncated]"
+		}
+		sb.WriteString("\n## Raw WHOIS response\n\n```\n")
+		sb.WriteString(raw)
+		sb.WriteString("\n```\n")
+	}
+
+	return sb.String()
+}
+
+func formatWhoisContact(contact whoisContact) string {
+	var parts []string
+	for _, part := range []string{contact.Name, contact.Org} {
+		if part != "" && !slices.Contains(parts, part) {
+			parts = append(parts, part)
+		}
+	}
+	if contact.IANAID != "" {
+		parts = append(parts, fmt.Sprintf("IANA ID %s", contact.IANAID))
+	}
+	if contact.Email != "" {
+		parts = append(parts, contact.Email)
+	}
+	if contact.Phone != "" {
+		parts = append(parts, contact.Phone)
+	}
+	if contact.Country != "" {
+		parts = append(parts, contact.Country)
+	}
+	if len(parts) == 0 && contact.Handle != "" {
+		parts = append(parts, contact.Handle)
+	}
+	if len(parts) == 0 {
+		return "*(redacted)*"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// IsAvailable returns true if the WHOIS lookup tool is enabled
+func (w *whois) IsAvailable() bool {
+	return w.cfg != nil && w.cfg.WhoisEnabled
+}
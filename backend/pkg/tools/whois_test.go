@@ -0,0 +1,382 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+func readRDAPTestdata(t *testing.T, name string) whoisRecord {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to open testdata: %v", err)
+	}
+	defer file.Close()
+
+	record, err := parseRDAPResponse(file, "https://rdap.example.com")
+	if err != nil {
+		t.Fatalf("parseRDAPResponse() unexpected error: %v", err)
+	}
+
+	return record
+}
+
+// newWhoisTestServer starts a WHOIS server which responds with the same text to any query
+func newWhoisTestServer(t *testing.T, response string) (string, func() []string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mx sync.Mutex
+	var queries []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			query, _ := bufio.NewReader(conn).ReadString('\n')
+			mx.Lock()
+			queries = append(queries, strings.TrimSpace(query))
+			mx.Unlock()
+			conn.Write([]byte(response))
+			conn.Close()
+		}
+	}()
+
+	return listener.Addr().String(), func() []string {
+		mx.Lock()
+		defer mx.Unlock()
+		return slices.Clone(queries)
+	}
+}
+
+func setWhoisIANAServer(t *testing.T, address string) {
+	t.Helper()
+
+	prev := whoisIANAServer
+	whoisIANAServer = address
+	t.Cleanup(func() {
+		whoisIANAServer = prev
+	})
+}
+
+func TestParseRDAPResponse_Domain(t *testing.T) {
+	record := readRDAPTestdata(t, "whois_result_rdap_domain.json")
+
+	if record.Name != "example.com" || record.Handle != "2336799_DOMAIN_COM-VRSN" {
+		t.Errorf("name = %q, handle = %q", record.Name, record.Handle)
+	}
+	if record.Created != "1995-08-14" || record.Expires != "2026-08-13" || record.Updated != "2025-08-14" {
+		t.Errorf("dates = %q, %q, %q", record.Created, record.Expires, record.Updated)
+	}
+	if record.DNSSEC != "signed" {
+		t.Errorf("DNSSEC = %q, want signed", record.DNSSEC)
+	}
+	if got := strings.Join(record.Nameservers, ","); got != "a.iana-servers.net,b.iana-servers.net" {
+		t.Errorf("nameservers = %q", got)
+	}
+	if len(record.Contacts) != 4 {
+		t.Fatalf("contacts = %d, want 4 with nested abuse contact: %+v", len(record.Contacts), record.Contacts)
+	}
+
+	got := formatWhoisRecord("example.com", whoisTypeDomain, record, DefaultMaxResultSize)
+	for _, want := range []string{
+		"**Target:** `example.com` (domain)",
+		"**Source:** RDAP (https://rdap.example.com)",
+		"**Status:** client delete prohibited, client transfer prohibited, client update prohibited",
+		"**Created:** 1995-08-14",
+		"**Expires:** 2026-08-13",
+		"**Registrant:** Internet Assigned Numbers Authority, IANA, US",
+		"**Registrar:** RESERVED-Internet Assigned Numbers Authority, IANA ID 376",
+		"**Abuse:** abuse@iana.org, +1.3103015800",
+		"- a.iana-servers.net\n- b.iana-servers.net\n",
+		"- **technical:** Tech Support, tech@iana.org, US",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseRDAPResponse_IP(t *testing.T) {
+	record := readRDAPTestdata(t, "whois_result_rdap_ip.json")
+
+	got := formatWhoisRecord("93.184.216.34", whoisTypeIP, record, DefaultMaxResultSize)
+	for _, want := range []string{
+		"**Target:** `93.184.216.34` (ip)",
+		"**Name:** EDGECAST-NETBLK-03",
+		"**Handle:** NET-93-184-208-0-1",
+		"**Range:** 93.184.208.0 - 93.184.223.255",
+		"**CIDR:** 93.184.208.0/20",
+		"**Network type:** DIRECT ALLOCATION",
+		"**Parent:** NET-93-0-0-0-0",
+		"**Country:** US",
+		"**Created:** 2008-06-02",
+		"**Updated:** 2012-06-22",
+		"**Registrant:** Edgecast Inc.",
+		"**Abuse:** EdgeCast Abuse, abuse@edgecast.com, +1-310-302-7050",
+		"- **noc, technical:** EdgeCast NOC, noc@edgecast.com",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "## Nameservers") || strings.Contains(got, "Raw WHOIS") {
+		t.Errorf("unexpected sections in IP result:\n%s", got)
+	}
+}
+
+func TestParseWhoisResponse(t *testing.T) {
+	response := strings.Join([]string{
+		"% comment line: ignored",
+		"   Domain Name: EXAMPLE.ORG",
+		"   Registry Domain ID: 2d2e1cd4d4d2",
+		"   Updated Date: 2025-01-10T19:34:06Z",
+		"   Creation Date: 1995-04-30T04:00:00Z",
+		"   Registry Expiry Date: 2027-05-01T04:00:00Z",
+		"   Registrar: Example Registrar, Inc.",
+		"   Registrar IANA ID: 292",
+		"   Registrar Abuse Contact Email: abuse@registrar.example",
+		"   Registrar Abuse Contact Phone: +1.5555550100",
+		"   Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited",
+		"   Registrant Organization: Example Org",
+		"   Registrant Country: US",
+		"   Name Server: NS1.EXAMPLE.NET",
+		"   Name Server: ns2.example.net.",
+		"   DNSSEC: unsigned",
+	}, "\n")
+
+	record := parseWhoisResponse(response)
+	got := formatWhoisRecord("example.org", whoisTypeDomain, record, DefaultMaxResultSize)
+	for _, want := range []string{
+		"**Source:** WHOIS",
+		"**Name:** example.org",
+		"**Status:** clientTransferProhibited",
+		"**Created:** 1995-04-30",
+		"**Updated:** 2025-01-10",
+		"**Expires:** 2027-05-01",
+		"**DNSSEC:** unsigned",
+		"**Registrant:** Example Org, US",
+		"**Registrar:** Example Registrar, Inc., IANA ID 292",
+		"**Abuse:** abuse@registrar.example, +1.5555550100",
+		"- ns1.example.net\n- ns2.example.net\n",
+		"## Raw WHOIS response",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+
+	if refer := parseWhoisReferral("domain:       ORG\nrefer:        whois.publicinterestregistry.org\n"); refer != "whois.publicinterestregistry.org" {
+		t.Errorf("parseWhoisReferral() = %q", refer)
+	}
+	if refer := parseWhoisReferral(response); refer != "" {
+		t.Errorf("parseWhoisReferral() = %q, want empty", refer)
+	}
+}
+
+func TestNormalizeWhoisTarget(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     string
+		wantType string
+		wantErr  bool
+	}{
+		{"Example.COM.", "example.com", whoisTypeDomain, false},
+		{"https://www.shop.example.co.uk:8443/login", "example.co.uk", whoisTypeDomain, false},
+		{"mail.example.com:25", "example.com", whoisTypeDomain, false},
+		{" 93.184.216.34 ", "93.184.216.34", whoisTypeIP, false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]", "2606:2800:220:1:248:1893:25c8:1946", whoisTypeIP, false},
+		{"", "", "", true},
+		{"localhost", "", "", true},
+		{"user@example.com", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, gotType, err := normalizeWhoisTarget(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeWhoisTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || gotType != tt.wantType {
+				t.Errorf("normalizeWhoisTarget() = (%q, %q), want (%q, %q)", got, gotType, tt.want, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestWhoisSizeLimits(t *testing.T) {
+	record := whoisRecord{
+		Source: "WHOIS",
+		Server: "whois.example.com",
+		Raw:    strings.Repeat("Remarks: long registry notice\n", 10000),
+	}
+	for i := range 5000 {
+		record.Contacts = append(record.Contacts, whoisContact{
+			Roles: []string{"technical"},
+			Name:  fmt.Sprintf("Contact %d %s", i, strings.Repeat("x", 100)),
+		})
+	}
+
+	got := formatWhoisRecord("example.com", whoisTypeDomain, record, DefaultMaxResultSize)
+	if len(got) > DefaultMaxResultSize {
+		t.Errorf("result size = %d, want at most %d", len(got), DefaultMaxResultSize)
+	}
+	if !strings.Contains(got, "contacts (output truncated)") {
+		t.Errorf("result missing contacts truncation note")
+	}
+
+	record.Contacts = nil
+	got = formatWhoisRecord("example.com", whoisTypeDomain, record, DefaultMaxResultSize)
+	if len(got) > maxWhoisRawOutputSize+1024 || !strings.Contains(got, "[response truncated]") {
+		t.Errorf("raw response is not truncated, result size = %d", len(got))
+	}
+}
+
+func TestWhoisIsAvailable(t *testing.T) {
+	if NewWhoisTool(nil, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true with nil config")
+	}
+	if NewWhoisTool(&config.Config{}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = true when disabled")
+	}
+	if !NewWhoisTool(&config.Config{WhoisEnabled: true}, 1, nil, nil, nil, nil).IsAvailable() {
+		t.Error("IsAvailable() = false when enabled")
+	}
+}
+
+func TestWhoisHandle(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "whois_result_rdap_domain.json"))
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	var requests int
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/domain/example.com", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	})
+
+	proxy, err := newTestProxy("rdap.org", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	slp := &searchLogProviderMock{}
+	wh := NewWhoisTool(&config.Config{
+		WhoisEnabled:      true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, slp, NewMemoryCache(10))
+
+	ctx := PutAgentContext(t.Context(), database.MsgchainTypeSearcher)
+	for _, target := range []string{"www.example.com", "https://EXAMPLE.com/login"} {
+		got, err := wh.Handle(ctx, WhoisToolName, []byte(fmt.Sprintf(`{"target":%q,"message":"lookup"}`, target)))
+		if err != nil {
+			t.Fatalf("Handle() unexpected error: %v", err)
+		}
+		if !strings.Contains(got, "**Registrar:** RESERVED-Internet Assigned Numbers Authority, IANA ID 376") {
+			t.Errorf("result missing registrar:\n%s", got)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 with the second result from cache", requests)
+	}
+	if slp.calls != 2 || slp.engine != database.SearchengineTypeWhois || slp.query != "example.com" {
+		t.Errorf("PutLog() calls = %d, engine = %q, query = %q", slp.calls, slp.engine, slp.query)
+	}
+}
+
+func TestWhoisHandle_FallbackToWhois(t *testing.T) {
+	registryAddress, registryQueries := newWhoisTestServer(t, strings.Join([]string{
+		"Domain Name: EXAMPLE.XYZ",
+		"Creation Date: 2016-02-01T00:00:00Z",
+		"Registrar: Fallback Registrar",
+		"Name Server: ns1.example.xyz",
+	}, "\n"))
+	ianaAddress, ianaQueries := newWhoisTestServer(t, fmt.Sprintf("domain: XYZ\nrefer:  %s\n", registryAddress))
+	setWhoisIANAServer(t, ianaAddress)
+
+	mockMux := http.NewServeMux()
+	mockMux.HandleFunc("/domain/example.xyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	mockMux.HandleFunc("/domain/unknown.xyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	proxy, err := newTestProxy("rdap.org", mockMux)
+	if err != nil {
+		t.Fatalf("failed to create proxy: %v", err)
+	}
+	defer proxy.Close()
+
+	// WHOIS connections are tunneled through the same proxy
+	wh := NewWhoisTool(&config.Config{
+		WhoisEnabled:      true,
+		ProxyURL:          proxy.URL(),
+		ExternalSSLCAPath: proxy.CACertPath(),
+	}, 1, nil, nil, &searchLogProviderMock{}, nil)
+
+	got, err := wh.Handle(t.Context(), WhoisToolName, []byte(`{"target":"example.xyz","message":"lookup"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"**Source:** WHOIS (127.0.0.1:",
+		"**Created:** 2016-02-01",
+		"**Registrar:** Fallback Registrar",
+		"- ns1.example.xyz",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("result missing %q:\n%s", want, got)
+		}
+	}
+	if iana, registry := ianaQueries(), registryQueries(); len(iana) != 1 || iana[0] != "example.xyz" || len(registry) != 1 {
+		t.Errorf("unexpected WHOIS queries: iana %v, registry %v", iana, registry)
+	}
+
+	// record is unknown to both RDAP and WHOIS
+	setWhoisIANAServer(t, "127.0.0.1:1")
+	got, err = wh.Handle(t.Context(), WhoisToolName, []byte(`{"target":"unknown.xyz","message":"lookup"}`))
+	if err != nil {
+		t.Fatalf("Handle() unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "No registration data found") {
+		t.Errorf("result missing not found message:\n%s", got)
+	}
+}
+
+func TestWhoisRequestError(t *testing.T) {
+	limited := &WhoisRequestError{StatusCode: http.StatusTooManyRequests, URL: "https://rdap.example.com"}
+	if !limited.Temporary() || !limited.RateLimited() || !strings.Contains(limited.Error(), "rate limited") {
+		t.Errorf("unexpected rate limited error: %v", limited)
+	}
+
+	forbidden := &WhoisRequestError{StatusCode: http.StatusForbidden, URL: "https://rdap.example.com"}
+	if forbidden.Temporary() || forbidden.RateLimited() {
+		t.Errorf("forbidden error should not be temporary: %v", forbidden)
+	}
+}
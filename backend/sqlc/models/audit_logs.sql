@@ -0,0 +1,11 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+  user_id,
+  action,
+  flow_id,
+  changes,
+  metadata
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
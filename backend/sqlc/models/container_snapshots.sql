@@ -0,0 +1,25 @@
+-- name: GetFlowContainerSnapshots :many
+SELECT
+  cs.*
+FROM container_snapshots cs
+WHERE cs.flow_id = $1
+ORDER BY cs.created_at ASC;
+
+-- name: GetSnapshotImageUsageCount :one
+SELECT
+  COUNT(c.id)::bigint AS usage_count
+FROM containers c
+WHERE c.image = $1 AND c.flow_id <> $2 AND c.status <> 'deleted';
+
+-- name: CreateContainerSnapshot :one
+INSERT INTO container_snapshots (
+  image, flow_id, container_id, user_id
+)
+VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: DeleteContainerSnapshot :exec
+DELETE FROM container_snapshots
+WHERE id = $1;
@@ -31,6 +31,13 @@ INNER JOIN flows f ON c.flow_id = f.id
 WHERE c.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY c.created_at DESC;
 
+-- name: GetFlowContainersUnscoped :many
+SELECT
+  c.*
+FROM containers c
+WHERE c.flow_id = $1
+ORDER BY c.created_at DESC;
+
 -- name: GetFlowPrimaryContainer :one
 SELECT
   c.*
@@ -51,10 +58,10 @@ ORDER BY c.created_at DESC;
 
 -- name: CreateContainer :one
 INSERT INTO containers (
-  type, name, image, status, flow_id, local_id, local_dir
+  type, name, image, status, flow_id, local_id, local_dir, cpu_limit, memory_limit, pids_limit
 )
 VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
 ON CONFLICT ON CONSTRAINT containers_local_id_unique
 DO UPDATE SET
@@ -63,7 +70,10 @@ DO UPDATE SET
   image = EXCLUDED.image,
   status = EXCLUDED.status,
   flow_id = EXCLUDED.flow_id,
-  local_dir = EXCLUDED.local_dir
+  local_dir = EXCLUDED.local_dir,
+  cpu_limit = EXCLUDED.cpu_limit,
+  memory_limit = EXCLUDED.memory_limit,
+  pids_limit = EXCLUDED.pids_limit
 RETURNING *;
 
 -- name: UpdateContainerStatusLocalID :one
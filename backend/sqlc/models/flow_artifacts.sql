@@ -0,0 +1,33 @@
+-- name: GetFlowArtifacts :many
+SELECT
+  fa.*
+FROM flow_artifacts fa
+WHERE fa.flow_id = $1
+ORDER BY fa.created_at ASC, fa.id ASC;
+
+-- name: GetFlowArtifact :one
+SELECT
+  fa.*
+FROM flow_artifacts fa
+WHERE fa.flow_id = $1 AND fa.name = $2;
+
+-- name: UpsertFlowArtifact :one
+INSERT INTO flow_artifacts (
+  name,
+  size,
+  content_type,
+  checksum,
+  flow_id,
+  task_id,
+  subtask_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (flow_id, name) DO UPDATE
+SET size = EXCLUDED.size, content_type = EXCLUDED.content_type, checksum = EXCLUDED.checksum,
+  task_id = EXCLUDED.task_id, subtask_id = EXCLUDED.subtask_id
+RETURNING *;
+
+-- name: DeleteFlowArtifacts :exec
+DELETE FROM flow_artifacts
+WHERE flow_id = $1;
@@ -0,0 +1,16 @@
+-- name: GetFlowAttachments :many
+SELECT
+  fa.*
+FROM flow_attachments fa
+WHERE fa.flow_id = $1
+ORDER BY fa.created_at ASC, fa.id ASC;
+
+-- name: GetFlowAttachment :one
+SELECT
+  fa.*
+FROM flow_attachments fa
+WHERE fa.id = $1 AND fa.flow_id = $2;
+
+-- name: DeleteFlowAttachments :exec
+DELETE FROM flow_attachments
+WHERE flow_id = $1;
@@ -0,0 +1,35 @@
+-- name: GetFlowEnv :many
+SELECT
+  fe.*
+FROM flow_env fe
+WHERE fe.flow_id = $1
+ORDER BY fe.name ASC;
+
+-- name: UpsertFlowEnv :one
+INSERT INTO flow_env (
+  flow_id,
+  name,
+  value,
+  secret
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (flow_id, name) DO UPDATE
+SET value = EXCLUDED.value, secret = EXCLUDED.secret
+RETURNING *;
+
+-- name: ReplaceFlowSecrets :exec
+WITH deleted AS (
+  DELETE FROM flow_env
+  WHERE flow_id = @flow_id AND secret = true AND NOT (name = ANY(@names::TEXT[]))
+)
+INSERT INTO flow_env (
+  flow_id,
+  name,
+  value,
+  secret
+)
+SELECT @flow_id, s.name, s.value, true
+FROM UNNEST(@names::TEXT[], @secret_values::TEXT[]) AS s(name, value)
+ON CONFLICT (flow_id, name) DO UPDATE
+SET value = EXCLUDED.value, secret = EXCLUDED.secret;
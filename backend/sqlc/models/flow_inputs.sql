@@ -0,0 +1,25 @@
+-- name: GetFlowInputs :many
+SELECT
+  fi.*
+FROM flow_inputs fi
+WHERE fi.flow_id = $1
+ORDER BY fi.id ASC;
+
+-- name: CreateFlowInput :one
+INSERT INTO flow_inputs (
+  input, flow_id
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: UpdateFlowInputStatus :one
+UPDATE flow_inputs
+SET status = $1, error = $2, processed_at = CURRENT_TIMESTAMP
+WHERE id = $3
+RETURNING *;
+
+-- name: CancelFlowQueuedInputs :exec
+UPDATE flow_inputs
+SET status = 'canceled', error = $1, processed_at = CURRENT_TIMESTAMP
+WHERE flow_id = $2 AND status = 'queued';
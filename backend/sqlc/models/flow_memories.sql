@@ -0,0 +1,81 @@
+-- name: GetFlowMemory :one
+SELECT
+  fm.*
+FROM flow_memories fm
+WHERE fm.flow_id = $1 AND fm.key = $2;
+
+-- name: GetFlowMemories :many
+SELECT
+  fm.*
+FROM flow_memories fm
+WHERE fm.flow_id = $1 AND fm.tags @> @tags::JSONB
+ORDER BY fm.updated_at DESC, fm.id DESC
+LIMIT @max_rows::INT;
+
+-- name: SearchFlowMemories :many
+SELECT
+  fm.*
+FROM flow_memories fm
+WHERE fm.flow_id = $1 AND fm.tags @> @tags::JSONB
+  AND LOWER(fm.key || ' ' || fm.value || ' ' || fm.tags::TEXT) LIKE '%' || LOWER(@query::TEXT) || '%'
+ORDER BY fm.updated_at DESC, fm.id DESC
+LIMIT @max_rows::INT;
+
+-- name: UpsertFlowMemory :one
+INSERT INTO flow_memories (
+  key,
+  value,
+  tags,
+  flow_id,
+  task_id,
+  subtask_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (flow_id, key) DO UPDATE
+SET value = EXCLUDED.value, tags = EXCLUDED.tags, task_id = EXCLUDED.task_id, subtask_id = EXCLUDED.subtask_id
+RETURNING *;
+
+-- name: UpsertFlowMemoryEmbedding :exec
+INSERT INTO flow_memory_embeddings (
+  memory_id,
+  flow_id,
+  model,
+  dimensions,
+  embedding
+) VALUES (
+  $1, $2, $3, $4, @embedding::REAL[]
+)
+ON CONFLICT (memory_id) DO UPDATE
+SET model = EXCLUDED.model, dimensions = EXCLUDED.dimensions, embedding = EXCLUDED.embedding;
+
+-- name: DeleteFlowMemoryEmbedding :exec
+DELETE FROM flow_memory_embeddings
+WHERE memory_id = $1;
+
+-- name: GetFlowMemoryVectorSupport :one
+SELECT EXISTS (
+  SELECT 1
+  FROM information_schema.columns
+  WHERE table_name = 'flow_memory_embeddings' AND column_name = 'embedding' AND udt_name = 'vector'
+)::BOOLEAN AS supported;
+
+-- name: GetFlowMemoriesEmbeddings :many
+SELECT
+  fm.*,
+  fme.embedding::REAL[] AS embedding
+FROM flow_memories fm
+INNER JOIN flow_memory_embeddings fme ON fme.memory_id = fm.id
+WHERE fm.flow_id = $1 AND fm.tags @> @tags::JSONB AND fme.model = @model AND fme.dimensions = @dimensions
+ORDER BY fm.updated_at DESC, fm.id DESC
+LIMIT @max_rows::INT;
+
+-- name: SearchFlowMemoriesByVector :many
+SELECT
+  fm.*,
+  (1 - (fme.embedding <=> @embedding::REAL[]::vector))::REAL AS similarity
+FROM flow_memories fm
+INNER JOIN flow_memory_embeddings fme ON fme.memory_id = fm.id
+WHERE fm.flow_id = $1 AND fm.tags @> @tags::JSONB AND fme.model = @model AND fme.dimensions = @dimensions
+ORDER BY fme.embedding <=> @embedding::REAL[]::vector
+LIMIT @max_rows::INT;
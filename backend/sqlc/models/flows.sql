@@ -5,6 +5,27 @@ FROM flows f
 WHERE f.deleted_at IS NULL
 ORDER BY f.created_at DESC;
 
+-- name: GetDeletedFlowsToPurge :many
+SELECT
+  f.*
+FROM flows f
+WHERE f.deleted_at IS NOT NULL
+  AND f.deleted_at < NOW() - (sqlc.arg(retention_seconds)::bigint * INTERVAL '1 second')
+  AND (
+    EXISTS (SELECT 1 FROM containers c WHERE c.flow_id = f.id AND c.status <> 'deleted')
+    OR EXISTS (SELECT 1 FROM container_snapshots cs WHERE cs.flow_id = f.id)
+    OR EXISTS (SELECT 1 FROM flow_attachments fa WHERE fa.flow_id = f.id)
+    OR EXISTS (SELECT 1 FROM flow_artifacts fr WHERE fr.flow_id = f.id)
+  )
+ORDER BY f.deleted_at ASC;
+
+-- name: GetRetainedFlowIDs :many
+SELECT
+  f.id
+FROM flows f
+WHERE f.deleted_at IS NULL
+  OR EXISTS (SELECT 1 FROM containers c WHERE c.flow_id = f.id AND c.status <> 'deleted');
+
 -- name: GetUserFlows :many
 SELECT
   f.*
@@ -26,12 +47,20 @@ FROM flows f
 INNER JOIN users u ON f.user_id = u.id
 WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL;
 
+-- name: GetUserRunningFlowsCount :one
+SELECT
+  COUNT(f.id)::bigint AS running_flows_count
+FROM flows f
+WHERE f.user_id = $1 AND f.status = 'running' AND f.deleted_at IS NULL;
+
 -- name: CreateFlow :one
 INSERT INTO flows (
-  title, status, model, model_provider_name, model_provider_type, language, tool_call_id_template, functions, user_id
+  title, status, model, model_provider_name, model_provider_type, language, tool_call_id_template, functions, user_id, fallback_providers,
+  budget_max_cost, budget_max_tokens, tool_calls_per_minute, tool_calls_max, response_cache, provider_timeout, system_prompt,
+  plan_max_subtasks, plan_max_depth, command_policy, scope, result_max_size, result_max_source_size
 )
 VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 )
 RETURNING *;
 
@@ -41,6 +70,12 @@ SET title = $1, model = $2, language = $3, tool_call_id_template = $4, functions
 WHERE id = $7
 RETURNING *;
 
+-- name: UpdateFlowProvider :one
+UPDATE flows
+SET model_provider_name = $1, model_provider_type = $2, model = $3
+WHERE id = $4
+RETURNING *;
+
 -- name: UpdateFlowStatus :one
 UPDATE flows
 SET status = $1
@@ -59,6 +94,54 @@ SET language = $1
 WHERE id = $2
 RETURNING *;
 
+-- name: UpdateFlowMetadata :one
+UPDATE flows
+SET
+  title = COALESCE(sqlc.narg(title), title),
+  language = COALESCE(sqlc.narg(language), language),
+  description = COALESCE(sqlc.narg(description), description)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateFlowBudget :one
+UPDATE flows
+SET budget_max_cost = $1, budget_max_tokens = $2, stop_reason = NULL
+WHERE id = $3
+RETURNING *;
+
+-- name: UpdateFlowFinishReason :one
+UPDATE flows
+SET finish_reason = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdateFlowLastError :one
+UPDATE flows
+SET
+  last_error_count = CASE WHEN last_error = $1 THEN last_error_count + 1 ELSE 1 END,
+  last_error = $1,
+  last_error_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING *;
+
+-- name: ResetFlowLastError :one
+UPDATE flows
+SET last_error = NULL, last_error_count = 0, last_error_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateFlowPendingQuestion :one
+UPDATE flows
+SET awaiting_input = $1, pending_question = $2
+WHERE id = $3
+RETURNING *;
+
+-- name: UpdateFlowStopReason :one
+UPDATE flows
+SET stop_reason = $1
+WHERE id = $2
+RETURNING *;
+
 -- name: UpdateFlowToolCallIDTemplate :one
 UPDATE flows
 SET tool_call_id_template = $1
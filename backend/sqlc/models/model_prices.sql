@@ -0,0 +1,6 @@
+-- name: GetModelPrices :many
+SELECT
+  mp.*
+FROM model_prices mp
+WHERE mp.provider = $1 AND mp.model = $2
+ORDER BY mp.effective_from DESC;
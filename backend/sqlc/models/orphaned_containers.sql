@@ -0,0 +1,26 @@
+-- name: GetOrphanedContainers :many
+SELECT
+  oc.*
+FROM orphaned_containers oc
+ORDER BY oc.created_at ASC;
+
+-- name: CreateOrphanedContainer :one
+INSERT INTO orphaned_containers (
+  local_id, flow_id, container_id, last_error
+)
+VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (local_id) DO UPDATE SET
+  last_error = EXCLUDED.last_error
+RETURNING *;
+
+-- name: UpdateOrphanedContainerAttempt :one
+UPDATE orphaned_containers
+SET attempts = attempts + 1, last_error = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: DeleteOrphanedContainer :exec
+DELETE FROM orphaned_containers
+WHERE id = $1;
@@ -52,7 +52,7 @@ FROM subtasks s
 INNER JOIN tasks t ON s.task_id = t.id
 INNER JOIN flows f ON t.flow_id = f.id
 WHERE s.task_id = $1 AND (s.status = 'created' OR s.status = 'waiting') AND f.deleted_at IS NULL
-ORDER BY s.id ASC;
+ORDER BY (s.status = 'waiting') DESC, s.priority DESC, s.id ASC;
 
 -- name: GetTaskCompletedSubtasks :many
 SELECT
@@ -112,6 +112,22 @@ SET status = 'failed', result = $1
 WHERE id = $2
 RETURNING *;
 
+-- name: UpdateSubtaskPriority :one
+UPDATE subtasks
+SET priority = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: BumpSubtaskPriority :one
+UPDATE subtasks
+SET priority = (
+  SELECT COALESCE(MAX(ps.priority), 0) + 1
+  FROM subtasks ps
+  WHERE ps.task_id = subtasks.task_id
+)
+WHERE id = $1
+RETURNING *;
+
 -- name: UpdateSubtaskContext :one
 UPDATE subtasks
 SET context = $1
@@ -0,0 +1,14 @@
+-- name: CreateToolLog :exec
+INSERT INTO tool_logs (
+  initiator,
+  executor,
+  engine,
+  query,
+  result,
+  flow_id,
+  task_id,
+  subtask_id
+)
+VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+);